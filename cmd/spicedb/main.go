@@ -12,6 +12,7 @@ import (
 
 	log "github.com/authzed/spicedb/internal/logging"
 	"github.com/authzed/spicedb/pkg/cmd"
+	"github.com/authzed/spicedb/pkg/cmd/datastore"
 	cmdutil "github.com/authzed/spicedb/pkg/cmd/server"
 	"github.com/authzed/spicedb/pkg/cmd/testserver"
 	_ "github.com/authzed/spicedb/pkg/runtime"
@@ -37,6 +38,7 @@ func main() {
 		return errParsing
 	})
 	cmd.RegisterRootFlags(rootCmd)
+	cmd.RegisterVersionFlag(rootCmd)
 
 	// Add a version command
 	versionCmd := cmd.NewVersionCommand(rootCmd.Use)
@@ -52,6 +54,20 @@ func main() {
 	cmd.RegisterDatastoreRootFlags(datastoreCmd)
 	rootCmd.AddCommand(datastoreCmd)
 
+	// Add backup and restore commands
+	var backupDatastoreConfig, restoreDatastoreConfig datastore.Config
+	backupCmd := cmd.NewBackupCommand(rootCmd.Use, &backupDatastoreConfig)
+	if err := cmd.RegisterBackupFlags(backupCmd, &backupDatastoreConfig); err != nil {
+		log.Fatal().Err(err).Msg("failed to register backup flags")
+	}
+	rootCmd.AddCommand(backupCmd)
+
+	restoreCmd := cmd.NewRestoreCommand(rootCmd.Use, &restoreDatastoreConfig)
+	if err := cmd.RegisterRestoreFlags(restoreCmd, &restoreDatastoreConfig); err != nil {
+		log.Fatal().Err(err).Msg("failed to register restore flags")
+	}
+	rootCmd.AddCommand(restoreCmd)
+
 	// Add deprecated head command
 	headCmd := cmd.NewHeadCommand(rootCmd.Use)
 	cmd.RegisterHeadFlags(headCmd)
@@ -74,6 +90,13 @@ func main() {
 	}
 	rootCmd.AddCommand(serveCmd)
 
+	doctorConfig := cmdutil.NewConfigWithOptionsAndDefaults()
+	doctorCmd := cmd.NewDoctorCommand(rootCmd.Use, doctorConfig)
+	if err := cmd.RegisterDoctorFlags(doctorCmd, doctorConfig); err != nil {
+		log.Fatal().Err(err).Msg("failed to register doctor flags")
+	}
+	rootCmd.AddCommand(doctorCmd)
+
 	devtoolsCmd := cmd.NewDevtoolsCommand(rootCmd.Use)
 	cmd.RegisterDevtoolsFlags(devtoolsCmd)
 	rootCmd.AddCommand(devtoolsCmd)
@@ -82,6 +105,56 @@ func main() {
 	testingCmd := cmd.NewTestingCommand(rootCmd.Use, &testServerConfig)
 	cmd.RegisterTestingFlags(testingCmd, &testServerConfig)
 	rootCmd.AddCommand(testingCmd)
+
+	validateCmd := cmd.NewValidateCommand(rootCmd.Use)
+	cmd.RegisterValidateFlags(validateCmd)
+	rootCmd.AddCommand(validateCmd)
+
+	lintCmd := cmd.NewLintCommand(rootCmd.Use)
+	cmd.RegisterLintFlags(lintCmd)
+	rootCmd.AddCommand(lintCmd)
+
+	importCmd := cmd.NewImportCommand(rootCmd.Use)
+	cmd.RegisterImportFlags(importCmd)
+	rootCmd.AddCommand(importCmd)
+
+	docsCmd := cmd.NewDocsCommand(rootCmd.Use)
+	cmd.RegisterDocsFlags(docsCmd)
+	rootCmd.AddCommand(docsCmd)
+
+	// Add client commands
+	checkCmd := cmd.NewCheckCommand(rootCmd.Use)
+	cmd.RegisterCheckFlags(checkCmd)
+	rootCmd.AddCommand(checkCmd)
+
+	readCmd := cmd.NewReadCommand(rootCmd.Use)
+	cmd.RegisterClientFlags(readCmd)
+	rootCmd.AddCommand(readCmd)
+
+	expandCmd := cmd.NewExpandCommand(rootCmd.Use)
+	cmd.RegisterClientFlags(expandCmd)
+	rootCmd.AddCommand(expandCmd)
+
+	exportGraphCmd := cmd.NewExportGraphCommand(rootCmd.Use)
+	cmd.RegisterExportGraphFlags(exportGraphCmd)
+	rootCmd.AddCommand(exportGraphCmd)
+
+	directorySyncCmd := cmd.NewDirectorySyncCommand(rootCmd.Use)
+	cmd.RegisterDirectorySyncFlags(directorySyncCmd)
+	rootCmd.AddCommand(directorySyncCmd)
+
+	rollbackCmd := cmd.NewRollbackCommand(rootCmd.Use)
+	cmd.RegisterRollbackFlags(rollbackCmd)
+	rootCmd.AddCommand(rollbackCmd)
+
+	perfCmd := cmd.NewPerfCommand(rootCmd.Use)
+	cmd.RegisterPerfFlags(perfCmd)
+	rootCmd.AddCommand(perfCmd)
+
+	fixturesCmd := cmd.NewFixturesCommand(rootCmd.Use)
+	cmd.RegisterFixturesFlags(fixturesCmd)
+	rootCmd.AddCommand(fixturesCmd)
+
 	if err := rootCmd.Execute(); err != nil {
 		if !errors.Is(err, errParsing) {
 			log.Err(err).Msg("terminated with errors")
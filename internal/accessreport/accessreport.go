@@ -0,0 +1,505 @@
+// Package accessreport generates reports of the permissions of all subjects on the resources
+// of a type, or of all the resources of a type a subject can access, as asynchronous jobs
+// which write the report to object storage.
+package accessreport
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jzelinskie/stringz"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/authzed/spicedb/internal/backup"
+	"github.com/authzed/spicedb/internal/dispatch"
+	log "github.com/authzed/spicedb/internal/logging"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/namespace"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/genutil/mapz"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const (
+	// lookupChunkSize is the number of resources whose subjects are looked up per dispatch.
+	lookupChunkSize = 100
+
+	// maxFinishedJobs is the number of finished jobs whose status is kept.
+	maxFinishedJobs = 100
+
+	objectPrefix = "spicedb-access-report-"
+)
+
+var reportsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "access_report",
+	Name:      "reports_total",
+	Help:      "total number of access reports generated, by kind and result",
+}, []string{"kind", "result"})
+
+// Kind is the kind of an access report.
+type Kind string
+
+const (
+	// KindSubjects reports the subjects with the permission on each resource of the type.
+	KindSubjects Kind = "subjects"
+
+	// KindResources reports the resources of the type on which the subject has the permission.
+	KindResources Kind = "resources"
+)
+
+// Status is the status of an access report job.
+type Status string
+
+const (
+	StatusRunning   Status = "running"
+	StatusSucceeded Status = "succeeded"
+	StatusFailed    Status = "failed"
+)
+
+// ErrTooManyRunning is returned when a report is requested while the maximum number of jobs
+// are running.
+var ErrTooManyRunning = errors.New("too many access reports are running; retry once one has finished")
+
+// invalidRequestError is returned for requests which cannot be reported on.
+type invalidRequestError struct {
+	error
+}
+
+func (err invalidRequestError) Unwrap() error {
+	return err.error
+}
+
+// Request is the request of an access report.
+type Request struct {
+	Kind         Kind   `json:"kind"`
+	ResourceType string `json:"resource_type"`
+	Permission   string `json:"permission"`
+	SubjectType  string `json:"subject_type"`
+
+	// SubjectRelation is the relation of the subjects, if they are subject sets.
+	SubjectRelation string `json:"subject_relation,omitempty"`
+
+	// SubjectID is the subject whose resources are reported, for reports of KindResources.
+	SubjectID string `json:"subject_id,omitempty"`
+
+	Format Format `json:"format"`
+}
+
+// Job is the status of an access report job.
+type Job struct {
+	ID      string  `json:"id"`
+	Request Request `json:"request"`
+	Status  Status  `json:"status"`
+
+	// Revision is the revision at which the report is computed.
+	Revision string `json:"revision,omitempty"`
+
+	// Object is the name of the object in the bucket to which the report is written.
+	Object string `json:"object"`
+
+	// Rows is the number of rows written so far.
+	Rows int `json:"rows"`
+
+	Error       string     `json:"error,omitempty"`
+	StartedAt   time.Time  `json:"started_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// Manager runs access report jobs, keeping the status of the most recent ones. Jobs and their
+// status are local to the node on which they were requested.
+type Manager struct {
+	bucket     backup.Bucket
+	ds         datastore.Datastore
+	dispatcher dispatch.Dispatcher
+	maxDepth   uint32
+	maxRunning int
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	mu       sync.Mutex
+	jobs     map[string]*Job
+	finished []string
+	running  int
+}
+
+// NewManager creates a manager running at most maxRunning jobs at a time, which write their
+// reports to the bucket.
+func NewManager(bucket backup.Bucket, ds datastore.Datastore, dispatcher dispatch.Dispatcher, maxDepth uint32, maxRunning int) (*Manager, error) {
+	if maxRunning <= 0 {
+		return nil, errors.New("maximum number of running access reports must be positive")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Manager{
+		bucket:     bucket,
+		ds:         ds,
+		dispatcher: dispatcher,
+		maxDepth:   maxDepth,
+		maxRunning: maxRunning,
+		ctx:        ctx,
+		cancel:     cancel,
+		jobs:       map[string]*Job{},
+	}, nil
+}
+
+// Close cancels the running jobs, waits for them to end and closes the bucket.
+func (m *Manager) Close() error {
+	m.cancel()
+	m.wg.Wait()
+	return m.bucket.Close()
+}
+
+// Start validates the request and starts a job generating its report.
+func (m *Manager) Start(ctx context.Context, req Request) (Job, error) {
+	if err := req.validate(); err != nil {
+		return Job{}, invalidRequestError{err}
+	}
+
+	revision, err := m.ds.HeadRevision(ctx)
+	if err != nil {
+		return Job{}, fmt.Errorf("failed to read head revision: %w", err)
+	}
+
+	if err := namespace.CheckNamespaceAndRelations(ctx, []namespace.TypeAndRelationToCheck{
+		{NamespaceName: req.ResourceType, RelationName: req.Permission, AllowEllipsis: false},
+		{NamespaceName: req.SubjectType, RelationName: stringz.DefaultEmpty(req.SubjectRelation, tuple.Ellipsis), AllowEllipsis: true},
+	}, m.ds.SnapshotReader(revision)); err != nil {
+		return Job{}, invalidRequestError{err}
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.running >= m.maxRunning {
+		return Job{}, ErrTooManyRunning
+	}
+
+	id := uuid.NewString()
+	job := &Job{
+		ID:        id,
+		Request:   req,
+		Status:    StatusRunning,
+		Revision:  revision.String(),
+		Object:    objectPrefix + id + "." + string(req.Format),
+		StartedAt: time.Now().UTC(),
+	}
+	m.jobs[id] = job
+	m.running++
+
+	m.wg.Add(1)
+	go m.run(job, revision)
+	return *job, nil
+}
+
+// Job returns the status of the job, if it is running or among the most recently finished.
+func (m *Manager) Job(id string) (Job, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	job, ok := m.jobs[id]
+	if !ok {
+		return Job{}, false
+	}
+	return *job, true
+}
+
+// Jobs returns the status of the running and most recently finished jobs, most recent first.
+func (m *Manager) Jobs() []Job {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	jobs := make([]Job, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		jobs = append(jobs, *job)
+	}
+	sort.Slice(jobs, func(i, j int) bool { return jobs[i].StartedAt.After(jobs[j].StartedAt) })
+	return jobs
+}
+
+func (m *Manager) run(job *Job, revision datastore.Revision) {
+	defer m.wg.Done()
+
+	logger := log.With().Str("job", job.ID).Str("kind", string(job.Request.Kind)).Logger()
+	logger.Info().Str("revision", revision.String()).Str("object", job.Object).Msg("generating access report")
+
+	err := m.generate(job, revision)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	completedAt := time.Now().UTC()
+	job.CompletedAt = &completedAt
+	job.Status = StatusSucceeded
+	if err != nil {
+		job.Status = StatusFailed
+		job.Error = err.Error()
+		logger.Warn().Err(err).Msg("access report failed")
+	} else {
+		logger.Info().Int("rows", job.Rows).Msg("access report generated")
+	}
+	reportsCounter.WithLabelValues(string(job.Request.Kind), string(job.Status)).Inc()
+
+	m.running--
+	m.finished = append(m.finished, job.ID)
+	if len(m.finished) > maxFinishedJobs {
+		delete(m.jobs, m.finished[0])
+		m.finished = m.finished[1:]
+	}
+}
+
+// generate computes the report at the revision, uploading its rows to the bucket as they are
+// computed.
+func (m *Manager) generate(job *Job, revision datastore.Revision) error {
+	// Reports of large datasets can outlive the GC window.
+	if pinner := datastore.UnwrapAs[datastore.RevisionPinningDatastore](m.ds); pinner != nil {
+		defer pinner.PinRevision(revision)()
+	}
+
+	// The dispatcher reads relationships from the datastore in the context.
+	ctx, cancel := context.WithCancel(datastoremw.ContextWithDatastore(m.ctx, m.ds))
+	defer cancel()
+
+	pr, pw := io.Pipe()
+	uploaded := make(chan error, 1)
+	go func() {
+		err := m.bucket.Upload(ctx, job.Object, pr)
+		_ = pr.CloseWithError(err)
+		uploaded <- err
+	}()
+
+	w, err := newRowWriter(job.Request.Format, pw)
+	if err != nil {
+		_ = pw.CloseWithError(err)
+		<-uploaded
+		return err
+	}
+
+	write := func(rows []Row) error {
+		for _, row := range rows {
+			if err := w.Write(row); err != nil {
+				return err
+			}
+		}
+
+		m.mu.Lock()
+		job.Rows += len(rows)
+		m.mu.Unlock()
+		return nil
+	}
+
+	switch job.Request.Kind {
+	case KindSubjects:
+		err = m.reportSubjects(ctx, job.Request, revision, write)
+	case KindResources:
+		err = m.reportResources(ctx, job.Request, revision, write)
+	}
+	if err == nil {
+		err = w.Flush()
+	}
+
+	// A failed report is not completed, so that a partial one is never mistaken for it.
+	if err != nil {
+		cancel()
+		_ = pw.CloseWithError(err)
+		<-uploaded
+		return err
+	}
+
+	_ = pw.Close()
+	if err := <-uploaded; err != nil {
+		return fmt.Errorf("failed to upload access report: %w", err)
+	}
+	return nil
+}
+
+func (m *Manager) resolverMeta(revision datastore.Revision) (*v1.ResolverMeta, error) {
+	bf, err := v1.NewTraversalBloomFilter(uint(m.maxDepth))
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.ResolverMeta{
+		AtRevision:     revision.String(),
+		DepthRemaining: m.maxDepth,
+		TraversalBloom: bf,
+	}, nil
+}
+
+// reportSubjects writes the subjects with the permission on each resource of the type, in
+// chunks of resources ordered by ID.
+func (m *Manager) reportSubjects(ctx context.Context, req Request, revision datastore.Revision, write func([]Row) error) error {
+	resourceIDs, err := resourcesOfType(ctx, m.ds.SnapshotReader(revision), req.ResourceType)
+	if err != nil {
+		return err
+	}
+
+	for start := 0; start < len(resourceIDs); start += lookupChunkSize {
+		chunk := resourceIDs[start:min(start+lookupChunkSize, len(resourceIDs))]
+
+		metadata, err := m.resolverMeta(revision)
+		if err != nil {
+			return err
+		}
+
+		stream := dispatch.NewCollectingDispatchStream[*v1.DispatchLookupSubjectsResponse](ctx)
+		err = m.dispatcher.DispatchLookupSubjects(&v1.DispatchLookupSubjectsRequest{
+			Metadata: metadata,
+			ResourceRelation: &core.RelationReference{
+				Namespace: req.ResourceType,
+				Relation:  req.Permission,
+			},
+			ResourceIds: chunk,
+			SubjectRelation: &core.RelationReference{
+				Namespace: req.SubjectType,
+				Relation:  stringz.DefaultEmpty(req.SubjectRelation, tuple.Ellipsis),
+			},
+		}, stream)
+		if err != nil {
+			return fmt.Errorf("error looking up subjects of `%s`: %w", tuple.JoinRelRef(req.ResourceType, req.Permission), err)
+		}
+
+		// A subject found both conditionally and unconditionally is reported as unconditional.
+		found := map[string]map[string]Row{}
+		for _, result := range stream.Results() {
+			for resourceID, subjects := range result.FoundSubjectsByResourceId {
+				if found[resourceID] == nil {
+					found[resourceID] = map[string]Row{}
+				}
+
+				for _, subject := range subjects.FoundSubjects {
+					row := req.row(resourceID, subject.SubjectId)
+					if subject.CaveatExpression != nil {
+						row.Permissionship = PermissionshipConditional
+					}
+					for _, excluded := range subject.ExcludedSubjects {
+						row.ExcludedSubjectIDs = append(row.ExcludedSubjectIDs, excluded.SubjectId)
+					}
+					sort.Strings(row.ExcludedSubjectIDs)
+
+					if existing, ok := found[resourceID][subject.SubjectId]; !ok || existing.Permissionship == PermissionshipConditional {
+						found[resourceID][subject.SubjectId] = row
+					}
+				}
+			}
+		}
+
+		rows := make([]Row, 0, len(found))
+		for _, subjects := range found {
+			for _, row := range subjects {
+				rows = append(rows, row)
+			}
+		}
+		sortRows(rows)
+		if err := write(rows); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reportResources writes the resources of the type on which the subject has the permission,
+// ordered by ID.
+func (m *Manager) reportResources(ctx context.Context, req Request, revision datastore.Revision, write func([]Row) error) error {
+	metadata, err := m.resolverMeta(revision)
+	if err != nil {
+		return err
+	}
+
+	// A resource found both conditionally and unconditionally is reported as unconditional.
+	found := map[string]Row{}
+	stream := dispatch.NewHandlingDispatchStream(ctx, func(result *v1.DispatchLookupResourcesResponse) error {
+		resourceID := result.ResolvedResource.ResourceId
+		row := req.row(resourceID, req.SubjectID)
+		if result.ResolvedResource.Permissionship == v1.ResolvedResource_CONDITIONALLY_HAS_PERMISSION {
+			row.Permissionship = PermissionshipConditional
+		}
+
+		if existing, ok := found[resourceID]; !ok || existing.Permissionship == PermissionshipConditional {
+			found[resourceID] = row
+		}
+		return nil
+	})
+
+	err = m.dispatcher.DispatchLookupResources(&v1.DispatchLookupResourcesRequest{
+		Metadata: metadata,
+		ObjectRelation: &core.RelationReference{
+			Namespace: req.ResourceType,
+			Relation:  req.Permission,
+		},
+		Subject: &core.ObjectAndRelation{
+			Namespace: req.SubjectType,
+			ObjectId:  req.SubjectID,
+			Relation:  stringz.DefaultEmpty(req.SubjectRelation, tuple.Ellipsis),
+		},
+	}, stream)
+	if err != nil {
+		return fmt.Errorf("error looking up resources of `%s`: %w", tuple.JoinRelRef(req.ResourceType, req.Permission), err)
+	}
+
+	rows := make([]Row, 0, len(found))
+	for _, row := range found {
+		rows = append(rows, row)
+	}
+	sortRows(rows)
+	return write(rows)
+}
+
+// resourcesOfType returns the IDs of the resources of the type with any relationship, ordered.
+func resourcesOfType(ctx context.Context, reader datastore.Reader, resourceType string) ([]string, error) {
+	it, err := reader.QueryRelationships(ctx, datastore.RelationshipsFilter{ResourceType: resourceType})
+	if err != nil {
+		return nil, fmt.Errorf("error reading relationships of `%s`: %w", resourceType, err)
+	}
+	defer it.Close()
+
+	resourceIDs := mapz.NewSet[string]()
+	for rel := it.Next(); rel != nil; rel = it.Next() {
+		resourceIDs.Add(rel.ResourceAndRelation.ObjectId)
+	}
+	if it.Err() != nil {
+		return nil, fmt.Errorf("error reading relationships of `%s`: %w", resourceType, it.Err())
+	}
+
+	ids := resourceIDs.AsSlice()
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func (req Request) validate() error {
+	switch {
+	case req.ResourceType == "" || req.Permission == "" || req.SubjectType == "":
+		return errors.New("resource_type, permission and subject_type are required")
+	case req.Kind == KindResources && req.SubjectID == "":
+		return errors.New("subject_id is required for reports of the resources of a subject")
+	case req.Kind == KindSubjects && req.SubjectID != "":
+		return errors.New("subject_id cannot be given for reports of the subjects of resources")
+	case req.Kind != KindSubjects && req.Kind != KindResources:
+		return fmt.Errorf("unknown report kind `%s`: expected `%s` or `%s`", req.Kind, KindSubjects, KindResources)
+	case req.Format != FormatCSV && req.Format != FormatJSONL:
+		return fmt.Errorf("unknown report format `%s`: expected `%s` or `%s`", req.Format, FormatCSV, FormatJSONL)
+	}
+	return nil
+}
+
+func (req Request) row(resourceID, subjectID string) Row {
+	return Row{
+		ResourceType:    req.ResourceType,
+		ResourceID:      resourceID,
+		Permission:      req.Permission,
+		SubjectType:     req.SubjectType,
+		SubjectID:       subjectID,
+		SubjectRelation: req.SubjectRelation,
+		Permissionship:  PermissionshipHas,
+	}
+}
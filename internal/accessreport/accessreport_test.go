@@ -0,0 +1,170 @@
+package accessreport
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/backup"
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/dispatch/graph"
+	"github.com/authzed/spicedb/internal/testfixtures"
+)
+
+func newTestManager(t *testing.T, maxRunning int) (*Manager, backup.Bucket) {
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+	ds, _ := testfixtures.StandardDatastoreWithData(rawDS, require.New(t))
+
+	bucket, err := backup.OpenBucket(context.Background(), "file://"+t.TempDir())
+	require.NoError(t, err)
+
+	m, err := NewManager(bucket, ds, graph.NewLocalOnlyDispatcher(10), 50, maxRunning)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, m.Close()) })
+	return m, bucket
+}
+
+func waitForJob(t *testing.T, m *Manager, id string) Job {
+	var job Job
+	require.Eventually(t, func() bool {
+		var ok bool
+		job, ok = m.Job(id)
+		require.True(t, ok)
+		return job.Status != StatusRunning
+	}, 10*time.Second, 10*time.Millisecond)
+	return job
+}
+
+func download(t *testing.T, bucket backup.Bucket, name string) string {
+	r, err := bucket.Download(context.Background(), name)
+	require.NoError(t, err)
+	defer r.Close()
+
+	contents, err := io.ReadAll(r)
+	require.NoError(t, err)
+	return string(contents)
+}
+
+func TestResourcesReport(t *testing.T) {
+	m, bucket := newTestManager(t, 1)
+
+	job, err := m.Start(context.Background(), Request{
+		Kind:         KindResources,
+		ResourceType: "document",
+		Permission:   "view",
+		SubjectType:  "user",
+		SubjectID:    "owner",
+		Format:       FormatJSONL,
+	})
+	require.NoError(t, err)
+	require.Equal(t, StatusRunning, job.Status)
+
+	job = waitForJob(t, m, job.ID)
+	require.Equal(t, StatusSucceeded, job.Status, job.Error)
+	require.Equal(t, 3, job.Rows)
+	require.NotNil(t, job.CompletedAt)
+
+	var resourceIDs []string
+	scanner := bufio.NewScanner(strings.NewReader(download(t, bucket, job.Object)))
+	for scanner.Scan() {
+		var row Row
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &row))
+		require.Equal(t, PermissionshipHas, row.Permissionship)
+		require.Equal(t, "owner", row.SubjectID)
+		resourceIDs = append(resourceIDs, row.ResourceID)
+	}
+	require.Equal(t, []string{"companyplan", "masterplan", "ownerplan"}, resourceIDs)
+}
+
+func TestSubjectsReport(t *testing.T) {
+	m, bucket := newTestManager(t, 1)
+
+	job, err := m.Start(context.Background(), Request{
+		Kind:         KindSubjects,
+		ResourceType: "document",
+		Permission:   "view",
+		SubjectType:  "user",
+		Format:       FormatCSV,
+	})
+	require.NoError(t, err)
+
+	job = waitForJob(t, m, job.ID)
+	require.Equal(t, StatusSucceeded, job.Status, job.Error)
+
+	records, err := csv.NewReader(strings.NewReader(download(t, bucket, job.Object))).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, csvHeader, records[0])
+	require.Len(t, records, job.Rows+1)
+	require.Contains(t, records, []string{"document", "masterplan", "view", "user", "eng_lead", "", "has_permission", ""})
+	require.Contains(t, records, []string{"document", "masterplan", "view", "user", "auditor", "", "has_permission", ""})
+	require.NotContains(t, records, []string{"document", "masterplan", "view", "user", "villain", "", "has_permission", ""})
+
+	// Rows are ordered by resource.
+	for i := 2; i < len(records); i++ {
+		require.LessOrEqual(t, records[i-1][1], records[i][1])
+	}
+}
+
+func TestInvalidReports(t *testing.T) {
+	m, _ := newTestManager(t, 1)
+
+	for _, req := range []Request{
+		{Kind: "everything", ResourceType: "document", Permission: "view", SubjectType: "user", Format: FormatCSV},
+		{Kind: KindResources, ResourceType: "document", Permission: "view", SubjectType: "user", Format: FormatCSV},
+		{Kind: KindSubjects, ResourceType: "document", Permission: "view", SubjectType: "user", SubjectID: "owner", Format: FormatCSV},
+		{Kind: KindSubjects, ResourceType: "document", Permission: "view", SubjectType: "user", Format: "xlsx"},
+		{Kind: KindSubjects, ResourceType: "document", Permission: "unknown", SubjectType: "user", Format: FormatCSV},
+	} {
+		_, err := m.Start(context.Background(), req)
+		require.ErrorAs(t, err, &invalidRequestError{})
+	}
+}
+
+func TestReportHandlers(t *testing.T) {
+	m, _ := newTestManager(t, 1)
+	mux := http.NewServeMux()
+	m.RegisterHandlers(mux)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/debug/access-reports", strings.NewReader(body)))
+		return recorder
+	}
+
+	recorder := post(`{"kind": "resources", "resource_type": "document", "permission": "view", "subject_type": "user", "subject_id": "owner", "format": "csv"}`)
+	require.Equal(t, http.StatusAccepted, recorder.Code)
+
+	var job Job
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &job))
+	waitForJob(t, m, job.ID)
+
+	recorder = httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/access-reports/"+job.ID, nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &job))
+	require.Equal(t, StatusSucceeded, job.Status)
+
+	recorder = httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/access-reports", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	var jobs []Job
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &jobs))
+	require.Len(t, jobs, 1)
+
+	recorder = httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/access-reports/unknown", nil))
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+
+	require.Equal(t, http.StatusBadRequest, post(`{"kind": "resources"}`).Code)
+	require.Equal(t, http.StatusBadRequest, post(`not json`).Code)
+}
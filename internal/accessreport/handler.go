@@ -0,0 +1,84 @@
+package accessreport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+const handlerPath = "/debug/access-reports"
+
+// RegisterHandlers adds the access report endpoints to the mux:
+//
+//   - POST /debug/access-reports starts a job generating the report of the JSON Request in
+//     the body, returning the status of the job;
+//   - GET /debug/access-reports returns the status of the running and recent jobs;
+//   - GET /debug/access-reports/<id> returns the status of the job, which is polled until it
+//     has succeeded and the report is in the bucket, or has failed.
+//
+// A nil manager registers nothing.
+func (m *Manager) RegisterHandlers(mux *http.ServeMux) {
+	if m == nil {
+		return
+	}
+
+	mux.HandleFunc(handlerPath, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, m.Jobs())
+
+		case http.MethodPost:
+			var req Request
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, fmt.Sprintf("invalid access report request: %s", err), http.StatusBadRequest)
+				return
+			}
+
+			job, err := m.Start(r.Context(), req)
+			var invalid invalidRequestError
+			switch {
+			case errors.Is(err, ErrTooManyRunning):
+				http.Error(w, err.Error(), http.StatusTooManyRequests)
+			case errors.As(err, &invalid):
+				http.Error(w, fmt.Sprintf("invalid access report request: %s", err), http.StatusBadRequest)
+			case err != nil:
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+			default:
+				writeJSON(w, http.StatusAccepted, job)
+			}
+
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	mux.HandleFunc(handlerPath+"/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", "GET")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		job, ok := m.Job(strings.TrimPrefix(r.URL.Path, handlerPath+"/"))
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		writeJSON(w, http.StatusOK, job)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	encoded, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "%s\n", encoded)
+}
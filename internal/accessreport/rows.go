@@ -0,0 +1,109 @@
+package accessreport
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Format is the format in which a report is written.
+type Format string
+
+const (
+	// FormatCSV writes a header row followed by a row per permission, with the IDs of the
+	// subjects excluded from wildcards separated by spaces.
+	FormatCSV Format = "csv"
+
+	// FormatJSONL writes a JSON object per permission, per line.
+	FormatJSONL Format = "jsonl"
+)
+
+// Permissionship is whether a subject has a permission, or only does depending on the context
+// of its caveats, which is not known when generating a report.
+type Permissionship string
+
+const (
+	PermissionshipHas         Permissionship = "has_permission"
+	PermissionshipConditional Permissionship = "conditional_permission"
+)
+
+// Row is the permission of a subject on a resource. A subject ID of `*` is the wildcard of
+// all subjects of the type, but those excluded.
+type Row struct {
+	ResourceType       string         `json:"resource_type"`
+	ResourceID         string         `json:"resource_id"`
+	Permission         string         `json:"permission"`
+	SubjectType        string         `json:"subject_type"`
+	SubjectID          string         `json:"subject_id"`
+	SubjectRelation    string         `json:"subject_relation,omitempty"`
+	Permissionship     Permissionship `json:"permissionship"`
+	ExcludedSubjectIDs []string       `json:"excluded_subject_ids,omitempty"`
+}
+
+var csvHeader = []string{"resource_type", "resource_id", "permission", "subject_type", "subject_id", "subject_relation", "permissionship", "excluded_subject_ids"}
+
+func (r Row) csvRecord() []string {
+	return []string{r.ResourceType, r.ResourceID, r.Permission, r.SubjectType, r.SubjectID, r.SubjectRelation, string(r.Permissionship), strings.Join(r.ExcludedSubjectIDs, " ")}
+}
+
+func sortRows(rows []Row) {
+	sort.Slice(rows, func(i, j int) bool {
+		if rows[i].ResourceID != rows[j].ResourceID {
+			return rows[i].ResourceID < rows[j].ResourceID
+		}
+		return rows[i].SubjectID < rows[j].SubjectID
+	})
+}
+
+type rowWriter interface {
+	Write(row Row) error
+	Flush() error
+}
+
+func newRowWriter(format Format, w io.Writer) (rowWriter, error) {
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		if err := cw.Write(csvHeader); err != nil {
+			return nil, err
+		}
+		return &csvRowWriter{cw}, nil
+
+	case FormatJSONL:
+		bw := bufio.NewWriter(w)
+		return &jsonlRowWriter{bw, json.NewEncoder(bw)}, nil
+
+	default:
+		return nil, fmt.Errorf("unknown report format `%s`", format)
+	}
+}
+
+type csvRowWriter struct {
+	w *csv.Writer
+}
+
+func (cw *csvRowWriter) Write(row Row) error {
+	return cw.w.Write(row.csvRecord())
+}
+
+func (cw *csvRowWriter) Flush() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
+
+type jsonlRowWriter struct {
+	w       *bufio.Writer
+	encoder *json.Encoder
+}
+
+func (jw *jsonlRowWriter) Write(row Row) error {
+	return jw.encoder.Encode(row)
+}
+
+func (jw *jsonlRowWriter) Flush() error {
+	return jw.w.Flush()
+}
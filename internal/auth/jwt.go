@@ -0,0 +1,382 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	grpcauth "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/auth"
+	"golang.org/x/exp/maps"
+	"golang.org/x/exp/slices"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// MethodsClaim is the JWT claim listing the API methods a token may call. Entries may
+	// be a method name (`CheckPermission`), a service name (`authzed.api.v1.PermissionsService`)
+	// or a full method name (`authzed.api.v1.PermissionsService/CheckPermission`).
+	MethodsClaim = "spicedb_methods"
+
+	// NamespacesClaim is the JWT claim listing the object definitions a token may access.
+	NamespacesClaim = "spicedb_namespaces"
+
+	// TenantClaim is the JWT claim naming the tenant whose schema and relationships a token
+	// may access, when tenant isolation is enabled.
+	TenantClaim = "spicedb_tenant"
+
+	clockSkewLeeway        = 1 * time.Minute
+	minJWKSRefreshInterval = 1 * time.Minute
+	jwksFetchTimeout       = 10 * time.Second
+)
+
+// JWTConfig configures the validation of JWTs used to authenticate API requests.
+type JWTConfig struct {
+	// Issuer is the expected `iss` claim of tokens.
+	Issuer string
+
+	// JWKSURL is the URL of the issuer's JSON Web Key Set. If empty, it is discovered
+	// from the issuer's OpenID Connect configuration.
+	JWKSURL string
+
+	// Audience, if non-empty, must be contained in the `aud` claim of tokens.
+	Audience string
+
+	// HTTPClient is used to fetch the issuer's keys. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// JWTValidator validates JWTs signed by the keys of a configured issuer.
+type JWTValidator struct {
+	config  JWTConfig
+	jwksURL string
+	client  *http.Client
+
+	mu        sync.RWMutex
+	keys      map[string]jose.JSONWebKey
+	lastFetch time.Time
+}
+
+// NewJWTValidator creates a validator for the configured issuer, fetching its current keys.
+func NewJWTValidator(ctx context.Context, config JWTConfig) (*JWTValidator, error) {
+	if config.Issuer == "" {
+		return nil, errors.New("JWT issuer must not be empty")
+	}
+
+	client := config.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	v := &JWTValidator{
+		config:  config,
+		jwksURL: config.JWKSURL,
+		client:  client,
+	}
+
+	if v.jwksURL == "" {
+		jwksURL, err := v.discoverJWKSURL(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to discover JWKS URL for issuer %s: %w", config.Issuer, err)
+		}
+		v.jwksURL = jwksURL
+	}
+
+	if err := v.refreshKeys(ctx); err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS from %s: %w", v.jwksURL, err)
+	}
+	return v, nil
+}
+
+// Validate verifies the signature and standard claims of the token, returning the scope
+// granted to it.
+func (v *JWTValidator) Validate(ctx context.Context, token string) (*TokenScope, error) {
+	if strings.Count(token, ".") != 2 {
+		return nil, errors.New("malformed JWT")
+	}
+
+	signed, err := jose.ParseSigned(token)
+	if err != nil {
+		return nil, fmt.Errorf("malformed JWT: %w", err)
+	}
+	if len(signed.Signatures) != 1 {
+		return nil, errors.New("malformed JWT: expected a single signature")
+	}
+	header := signed.Signatures[0].Header
+
+	key, err := v.keyFor(ctx, header.KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkKeyAlgorithm(header.Algorithm, key); err != nil {
+		return nil, err
+	}
+
+	payload, err := signed.Verify(key.Key)
+	if err != nil {
+		return nil, errors.New("invalid JWT signature")
+	}
+
+	var claims jwtClaims
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, fmt.Errorf("malformed JWT claims: %w", err)
+	}
+
+	if err := v.validateClaims(claims, time.Now()); err != nil {
+		return nil, err
+	}
+
+	return &TokenScope{Subject: claims.Subject, Methods: claims.Methods, Namespaces: claims.Namespaces, Tenant: claims.Tenant}, nil
+}
+
+var (
+	// rsaAlgorithms are the JWT algorithms of RSA keys.
+	rsaAlgorithms = []jose.SignatureAlgorithm{jose.RS256, jose.RS384, jose.RS512, jose.PS256, jose.PS384, jose.PS512}
+
+	// ecdsaAlgorithms are the JWT algorithms of the elliptic curves, each of which may only
+	// sign with the hash of its size.
+	ecdsaAlgorithms = map[string]jose.SignatureAlgorithm{
+		"P-256": jose.ES256,
+		"P-384": jose.ES384,
+		"P-521": jose.ES512,
+	}
+)
+
+// checkKeyAlgorithm checks that the algorithm of a token is one of those of its signing key,
+// so that a token cannot be verified with a key of another type or curve than that with
+// which it claims to be signed, nor with another algorithm than that pinned by the key.
+func checkKeyAlgorithm(algorithm string, key jose.JSONWebKey) error {
+	alg := jose.SignatureAlgorithm(algorithm)
+	if !slices.Contains(rsaAlgorithms, alg) && !slices.Contains(maps.Values(ecdsaAlgorithms), alg) {
+		return fmt.Errorf("unsupported JWT algorithm `%s`", algorithm)
+	}
+
+	var matches bool
+	switch publicKey := key.Key.(type) {
+	case *rsa.PublicKey:
+		matches = slices.Contains(rsaAlgorithms, alg)
+	case *ecdsa.PublicKey:
+		matches = ecdsaAlgorithms[publicKey.Curve.Params().Name] == alg
+	}
+
+	if !matches || (key.Algorithm != "" && key.Algorithm != algorithm) {
+		return fmt.Errorf("JWT algorithm `%s` does not match signing key", algorithm)
+	}
+	return nil
+}
+
+type jwtClaims struct {
+	Issuer     string   `json:"iss"`
+	Subject    string   `json:"sub"`
+	Audience   audience `json:"aud"`
+	ExpiresAt  *float64 `json:"exp"`
+	NotBefore  *float64 `json:"nbf"`
+	Methods    []string `json:"spicedb_methods"`
+	Namespaces []string `json:"spicedb_namespaces"`
+	Tenant     string   `json:"spicedb_tenant"`
+}
+
+// audience is the `aud` claim, which may be either a single string or a list of strings.
+type audience []string
+
+func (a *audience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = audience{single}
+		return nil
+	}
+
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return fmt.Errorf("aud must be a string or a list of strings")
+	}
+	*a = multiple
+	return nil
+}
+
+func (v *JWTValidator) validateClaims(claims jwtClaims, now time.Time) error {
+	if claims.Issuer != v.config.Issuer {
+		return fmt.Errorf("unexpected JWT issuer `%s`", claims.Issuer)
+	}
+
+	if claims.ExpiresAt == nil {
+		return errors.New("JWT is missing an expiration")
+	}
+	if now.After(unixTime(*claims.ExpiresAt).Add(clockSkewLeeway)) {
+		return errors.New("JWT has expired")
+	}
+	if claims.NotBefore != nil && now.Add(clockSkewLeeway).Before(unixTime(*claims.NotBefore)) {
+		return errors.New("JWT is not yet valid")
+	}
+
+	if v.config.Audience != "" {
+		found := false
+		for _, aud := range claims.Audience {
+			if aud == v.config.Audience {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return errors.New("JWT is not intended for this audience")
+		}
+	}
+
+	return nil
+}
+
+func unixTime(seconds float64) time.Time {
+	return time.Unix(0, int64(seconds*float64(time.Second)))
+}
+
+// keyFor returns the key with the given ID, refreshing the key set if the key is unknown,
+// as happens when the issuer rotates its keys.
+func (v *JWTValidator) keyFor(ctx context.Context, keyID string) (jose.JSONWebKey, error) {
+	if key, ok := v.lookupKey(keyID); ok {
+		return key, nil
+	}
+
+	v.mu.RLock()
+	canRefresh := time.Since(v.lastFetch) >= minJWKSRefreshInterval
+	v.mu.RUnlock()
+
+	if canRefresh {
+		if err := v.refreshKeys(ctx); err != nil {
+			return jose.JSONWebKey{}, fmt.Errorf("failed to refresh JWKS: %w", err)
+		}
+		if key, ok := v.lookupKey(keyID); ok {
+			return key, nil
+		}
+	}
+
+	return jose.JSONWebKey{}, fmt.Errorf("unknown JWT signing key `%s`", keyID)
+}
+
+func (v *JWTValidator) lookupKey(keyID string) (jose.JSONWebKey, bool) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	if keyID == "" && len(v.keys) == 1 {
+		for _, key := range v.keys {
+			return key, true
+		}
+	}
+
+	key, ok := v.keys[keyID]
+	return key, ok
+}
+
+func (v *JWTValidator) discoverJWKSURL(ctx context.Context) (string, error) {
+	var discovery struct {
+		JWKSURI string `json:"jwks_uri"`
+	}
+	if err := v.getJSON(ctx, strings.TrimSuffix(v.config.Issuer, "/")+"/.well-known/openid-configuration", &discovery); err != nil {
+		return "", err
+	}
+	if discovery.JWKSURI == "" {
+		return "", errors.New("OpenID configuration is missing jwks_uri")
+	}
+	return discovery.JWKSURI, nil
+}
+
+func (v *JWTValidator) refreshKeys(ctx context.Context) error {
+	var keySet struct {
+		Keys []json.RawMessage `json:"keys"`
+	}
+	if err := v.getJSON(ctx, v.jwksURL, &keySet); err != nil {
+		return err
+	}
+
+	keys := make(map[string]jose.JSONWebKey, len(keySet.Keys))
+	for _, raw := range keySet.Keys {
+		// Keys of types which cannot sign tokens, such as symmetric keys, are skipped rather
+		// than failing the whole set.
+		var described struct {
+			KeyType string `json:"kty"`
+			KeyID   string `json:"kid"`
+		}
+		if err := json.Unmarshal(raw, &described); err != nil {
+			return fmt.Errorf("invalid key: %w", err)
+		}
+		if described.KeyType != "RSA" && described.KeyType != "EC" {
+			continue
+		}
+
+		var jwk jose.JSONWebKey
+		if err := json.Unmarshal(raw, &jwk); err != nil {
+			return fmt.Errorf("invalid key `%s`: %w", described.KeyID, err)
+		}
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		if !jwk.IsPublic() || !jwk.Valid() {
+			return fmt.Errorf("invalid key `%s`: not a valid public key", jwk.KeyID)
+		}
+		keys[jwk.KeyID] = jwk
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.keys = keys
+	v.lastFetch = time.Now()
+	return nil
+}
+
+func (v *JWTValidator) getJSON(ctx context.Context, url string, into any) error {
+	ctx, cancel := context.WithTimeout(ctx, jwksFetchTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d fetching %s", resp.StatusCode, url)
+	}
+	return json.NewDecoder(resp.Body).Decode(into)
+}
+
+// RequirePresharedKeyOrJWT requires that gRPC requests have a Bearer Token value which is
+// either equivalent to one of the preshared key(s) returned by the function or a JWT
+// accepted by the validator. Requests authenticated with a JWT carry the token's scope in their context.
+func RequirePresharedKeyOrJWT(presharedKeys func() []string, validator *JWTValidator) grpcauth.AuthFunc {
+	return func(ctx context.Context) (context.Context, error) {
+		token, err := grpcauth.AuthFromMD(ctx, "bearer")
+		if err != nil {
+			return nil, status.Errorf(codes.Unauthenticated, errInvalidToken+": %s", err.Error())
+		}
+
+		if token == "" {
+			return nil, status.Errorf(codes.Unauthenticated, "missing token")
+		}
+
+		for _, presharedKey := range presharedKeys() {
+			if match := subtle.ConstantTimeCompare([]byte(presharedKey), []byte(token)); match == 1 {
+				return ctx, nil
+			}
+		}
+
+		scope, err := validator.Validate(ctx, token)
+		if err != nil {
+			return nil, status.Errorf(codes.Unauthenticated, errInvalidToken+": %s", err.Error())
+		}
+		return ContextWithScope(ctx, scope), nil
+	}
+}
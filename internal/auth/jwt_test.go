@@ -0,0 +1,337 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/authzed/grpcutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+type testIssuer struct {
+	server *httptest.Server
+	keys   map[string]crypto.Signer
+}
+
+func newTestIssuer(t *testing.T) *testIssuer {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	issuer := &testIssuer{keys: map[string]crypto.Signer{"rsa": rsaKey}}
+
+	jwks := []map[string]string{{
+		"kty": "RSA",
+		"kid": "rsa",
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+	}}
+	for kid, curve := range map[string]elliptic.Curve{"ec": elliptic.P256(), "ec384": elliptic.P384(), "ec521": elliptic.P521()} {
+		ecKey, err := ecdsa.GenerateKey(curve, rand.Reader)
+		require.NoError(t, err)
+		issuer.keys[kid] = ecKey
+
+		size := (curve.Params().BitSize + 7) / 8
+		jwks = append(jwks, map[string]string{
+			"kty": "EC",
+			"kid": kid,
+			"crv": curve.Params().Name,
+			"x":   base64.RawURLEncoding.EncodeToString(ecKey.X.FillBytes(make([]byte, size))),
+			"y":   base64.RawURLEncoding.EncodeToString(ecKey.Y.FillBytes(make([]byte, size))),
+		})
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]string{"jwks_uri": issuer.server.URL + "/keys"})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"keys": jwks})
+	})
+
+	issuer.server = httptest.NewServer(mux)
+	t.Cleanup(issuer.server.Close)
+	return issuer
+}
+
+// sign signs the claims with the key of the ID, or the RSA key for unknown IDs, using the
+// hash of the algorithm whatever the key, as a forged token would.
+func (ti *testIssuer) sign(t *testing.T, alg, kid string, claims map[string]any) string {
+	header, err := json.Marshal(map[string]string{"alg": alg, "kid": kid, "typ": "JWT"})
+	require.NoError(t, err)
+	payload, err := json.Marshal(claims)
+	require.NoError(t, err)
+
+	signed := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload)
+
+	hash := map[string]crypto.Hash{"256": crypto.SHA256, "384": crypto.SHA384, "512": crypto.SHA512}[alg[min(2, len(alg)):]]
+	key, ok := ti.keys[kid]
+	if !ok {
+		key = ti.keys["rsa"]
+	}
+
+	signature := []byte("unsigned")
+	switch key := key.(type) {
+	case *rsa.PrivateKey:
+		if hash == 0 || strings.HasPrefix(alg, "ES") {
+			break
+		}
+		digest := hashOf(hash, signed)
+		if strings.HasPrefix(alg, "PS") {
+			signature, err = rsa.SignPSS(rand.Reader, key, hash, digest, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash})
+		} else {
+			signature, err = rsa.SignPKCS1v15(rand.Reader, key, hash, digest)
+		}
+		require.NoError(t, err)
+
+	case *ecdsa.PrivateKey:
+		if hash == 0 || !strings.HasPrefix(alg, "ES") {
+			break
+		}
+		r, s, err := ecdsa.Sign(rand.Reader, key, hashOf(hash, signed))
+		require.NoError(t, err)
+		size := (key.Curve.Params().BitSize + 7) / 8
+		signature = append(r.FillBytes(make([]byte, size)), s.FillBytes(make([]byte, size))...)
+	}
+
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func hashOf(hash crypto.Hash, data string) []byte {
+	hasher := hash.New()
+	hasher.Write([]byte(data))
+	return hasher.Sum(nil)
+}
+
+func TestJWTValidator(t *testing.T) {
+	issuer := newTestIssuer(t)
+	validator, err := NewJWTValidator(context.Background(), JWTConfig{
+		Issuer:   issuer.server.URL,
+		Audience: "spicedb",
+	})
+	require.NoError(t, err)
+
+	validClaims := func() map[string]any {
+		return map[string]any{
+			"iss": issuer.server.URL,
+			"aud": []string{"other", "spicedb"},
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}
+	}
+
+	withClaim := func(key string, value any) map[string]any {
+		claims := validClaims()
+		claims[key] = value
+		return claims
+	}
+
+	// Swap the claims of a validly signed token for those of another.
+	signedParts := strings.Split(issuer.sign(t, "RS256", "rsa", validClaims()), ".")
+	otherParts := strings.Split(issuer.sign(t, "RS256", "rsa", withClaim(MethodsClaim, []string{"CheckPermission"})), ".")
+	tamperedToken := signedParts[0] + "." + otherParts[1] + "." + signedParts[2]
+
+	testCases := []struct {
+		name          string
+		token         string
+		expectedScope *TokenScope
+		expectedError string
+	}{
+		{
+			"valid RSA token",
+			issuer.sign(t, "RS256", "rsa", validClaims()),
+			&TokenScope{},
+			"",
+		},
+		{
+			"valid EC token",
+			issuer.sign(t, "ES256", "ec", validClaims()),
+			&TokenScope{},
+			"",
+		},
+		{
+			"scoped token",
+			issuer.sign(t, "RS256", "rsa", withClaim(MethodsClaim, []string{"CheckPermission"})),
+			&TokenScope{Methods: []string{"CheckPermission"}},
+			"",
+		},
+		{
+			"tenant token",
+			issuer.sign(t, "RS256", "rsa", withClaim(TenantClaim, "acme")),
+			&TokenScope{Tenant: "acme"},
+			"",
+		},
+		{
+			"single audience",
+			issuer.sign(t, "RS256", "rsa", withClaim("aud", "spicedb")),
+			&TokenScope{},
+			"",
+		},
+		{
+			"wrong audience",
+			issuer.sign(t, "RS256", "rsa", withClaim("aud", "other")),
+			nil,
+			"not intended for this audience",
+		},
+		{
+			"wrong issuer",
+			issuer.sign(t, "RS256", "rsa", withClaim("iss", "https://example.com")),
+			nil,
+			"unexpected JWT issuer",
+		},
+		{
+			"expired",
+			issuer.sign(t, "RS256", "rsa", withClaim("exp", time.Now().Add(-time.Hour).Unix())),
+			nil,
+			"JWT has expired",
+		},
+		{
+			"missing expiration",
+			issuer.sign(t, "RS256", "rsa", withClaim("exp", nil)),
+			nil,
+			"missing an expiration",
+		},
+		{
+			"not yet valid",
+			issuer.sign(t, "RS256", "rsa", withClaim("nbf", time.Now().Add(time.Hour).Unix())),
+			nil,
+			"not yet valid",
+		},
+		{
+			"key does not match algorithm",
+			issuer.sign(t, "RS256", "ec", validClaims()),
+			nil,
+			"does not match signing key",
+		},
+		{
+			"unknown key",
+			issuer.sign(t, "RS256", "unknown", validClaims()),
+			nil,
+			"unknown JWT signing key",
+		},
+		{
+			"unsigned token",
+			issuer.sign(t, "none", "rsa", validClaims()),
+			nil,
+			"unsupported JWT algorithm",
+		},
+		{
+			"tampered claims",
+			tamperedToken,
+			nil,
+			"invalid JWT signature",
+		},
+		{
+			"malformed token",
+			"not.a-jwt",
+			nil,
+			"malformed JWT",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			scope, err := validator.Validate(context.Background(), tc.token)
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expectedScope, scope)
+		})
+	}
+}
+
+func TestRequirePresharedKeyOrJWT(t *testing.T) {
+	issuer := newTestIssuer(t)
+	validator, err := NewJWTValidator(context.Background(), JWTConfig{
+		Issuer:  issuer.server.URL,
+		JWKSURL: issuer.server.URL + "/keys",
+	})
+	require.NoError(t, err)
+
+	f := RequirePresharedKeyOrJWT(func() []string { return []string{"somekey"} }, validator)
+
+	ctx, err := f(withTokenMetadata("bearer somekey"))
+	require.NoError(t, err)
+	_, scoped := ScopeFromContext(ctx)
+	require.False(t, scoped)
+
+	token := issuer.sign(t, "RS256", "rsa", map[string]any{
+		"iss":           issuer.server.URL,
+		"sub":           "some-service",
+		"exp":           time.Now().Add(time.Hour).Unix(),
+		NamespacesClaim: []string{"document"},
+	})
+	ctx, err = f(withTokenMetadata("bearer " + token))
+	require.NoError(t, err)
+	scope, scoped := ScopeFromContext(ctx)
+	require.True(t, scoped)
+	require.Equal(t, "some-service", scope.Subject)
+	require.Equal(t, []string{"document"}, scope.Namespaces)
+
+	_, err = f(withTokenMetadata("bearer otherkey"))
+	grpcutil.RequireStatus(t, codes.Unauthenticated, err)
+
+	_, err = f(context.Background())
+	grpcutil.RequireStatus(t, codes.Unauthenticated, err)
+}
+
+func TestJWTAlgorithmMustMatchKey(t *testing.T) {
+	issuer := newTestIssuer(t)
+	validator, err := NewJWTValidator(context.Background(), JWTConfig{Issuer: issuer.server.URL})
+	require.NoError(t, err)
+
+	claims := map[string]any{"iss": issuer.server.URL, "exp": time.Now().Add(time.Hour).Unix()}
+
+	testCases := []struct {
+		alg           string
+		kid           string
+		expectedError string
+	}{
+		{"RS256", "rsa", ""},
+		{"RS512", "rsa", ""},
+		{"PS256", "rsa", ""},
+		{"PS384", "rsa", ""},
+		{"ES256", "ec", ""},
+		{"ES384", "ec384", ""},
+		{"ES512", "ec521", ""},
+		{"ES256", "ec384", "does not match signing key"},
+		{"ES256", "ec521", "does not match signing key"},
+		{"ES384", "ec", "does not match signing key"},
+		{"ES384", "ec521", "does not match signing key"},
+		{"ES512", "ec384", "does not match signing key"},
+		{"ES256", "rsa", "does not match signing key"},
+		{"PS256", "ec", "does not match signing key"},
+		{"RS384", "ec384", "does not match signing key"},
+		{"HS256", "rsa", "unsupported JWT algorithm"},
+		{"EdDSA", "ec", "unsupported JWT algorithm"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.alg+" with "+tc.kid, func(t *testing.T) {
+			_, err := validator.Validate(context.Background(), issuer.sign(t, tc.alg, tc.kid, claims))
+			if tc.expectedError == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
@@ -29,6 +29,13 @@ func MustRequirePresharedKey(presharedKeys []string) grpcauth.AuthFunc {
 		}
 	}
 
+	return RequirePresharedKeyFunc(func() []string { return presharedKeys })
+}
+
+// RequirePresharedKeyFunc requires that gRPC requests have a Bearer Token value
+// equivalent to one of the preshared key(s) returned by the function, which is called on
+// each request so that the keys can be rotated.
+func RequirePresharedKeyFunc(presharedKeys func() []string) grpcauth.AuthFunc {
 	return func(ctx context.Context) (context.Context, error) {
 		token, err := grpcauth.AuthFromMD(ctx, "bearer")
 		if err != nil {
@@ -39,7 +46,7 @@ func MustRequirePresharedKey(presharedKeys []string) grpcauth.AuthFunc {
 			return nil, status.Errorf(codes.Unauthenticated, errMissingPresharedKey)
 		}
 
-		for _, presharedKey := range presharedKeys {
+		for _, presharedKey := range presharedKeys() {
 			if match := subtle.ConstantTimeCompare([]byte(presharedKey), []byte(token)); match == 1 {
 				return ctx, nil
 			}
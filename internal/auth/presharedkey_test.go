@@ -46,6 +46,20 @@ func TestPresharedKeys(t *testing.T) {
 	}
 }
 
+func TestRotatedPresharedKeys(t *testing.T) {
+	keys := []string{"one"}
+	f := RequirePresharedKeyFunc(func() []string { return keys })
+
+	_, err := f(withTokenMetadata("bearer one"))
+	require.NoError(t, err)
+
+	keys = []string{"two"}
+	_, err = f(withTokenMetadata("bearer one"))
+	grpcutil.RequireStatus(t, codes.PermissionDenied, err)
+	_, err = f(withTokenMetadata("bearer two"))
+	require.NoError(t, err)
+}
+
 func withTokenMetadata(authzHeader string) context.Context {
 	md := metadata.Pairs("authorization", authzHeader)
 	return metautils.MD(md).ToIncoming(context.Background())
@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+
+	grpcauth "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/auth"
+	"google.golang.org/grpc/peer"
+
+	"github.com/authzed/spicedb/pkg/middleware/clientidentity"
+)
+
+// PrincipalFromContext returns the key identifying the caller: its verified client
+// certificate, the name of its scoped preshared key, the subject of its JWT, a digest of its
+// bearer token or, failing those, its IP address.
+func PrincipalFromContext(ctx context.Context) string {
+	if identity, ok := clientidentity.FromContext(ctx); ok {
+		return "cert:" + identity.String()
+	}
+
+	if scope, ok := ScopeFromContext(ctx); ok {
+		if scope.KeyName != "" {
+			return "key:" + scope.KeyName
+		}
+		if scope.Subject != "" {
+			return "sub:" + scope.Subject
+		}
+	}
+
+	if token, err := grpcauth.AuthFromMD(ctx, "bearer"); err == nil && token != "" {
+		digest := sha256.Sum256([]byte(token))
+		return "token:" + hex.EncodeToString(digest[:8])
+	}
+
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		host, _, err := net.SplitHostPort(p.Addr.String())
+		if err != nil {
+			host = p.Addr.String()
+		}
+		return "ip:" + host
+	}
+
+	return "unknown"
+}
@@ -0,0 +1,29 @@
+package auth
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+func TestPrincipalFromContext(t *testing.T) {
+	require.Equal(t, "unknown", PrincipalFromContext(context.Background()))
+
+	ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP("10.0.0.1"), Port: 1234}})
+	require.Equal(t, "ip:10.0.0.1", PrincipalFromContext(ctx))
+
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", "bearer somekey"))
+	principal := PrincipalFromContext(ctx)
+	require.Contains(t, principal, "token:")
+	require.NotContains(t, principal, "somekey")
+
+	ctx = ContextWithScope(ctx, &TokenScope{Subject: "some-service"})
+	require.Equal(t, "sub:some-service", PrincipalFromContext(ctx))
+
+	ctx = ContextWithScope(ctx, &TokenScope{KeyName: "frontend"})
+	require.Equal(t, "key:frontend", PrincipalFromContext(ctx))
+}
@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/authzed/grpcutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+const checkPermissionMethod = "/authzed.api.v1.PermissionsService/CheckPermission"
+
+func TestScopeMethods(t *testing.T) {
+	testCases := []struct {
+		name      string
+		methods   []string
+		fullName  string
+		isAllowed bool
+	}{
+		{"unrestricted", nil, checkPermissionMethod, true},
+		{"method name", []string{"CheckPermission"}, checkPermissionMethod, true},
+		{"service name", []string{"authzed.api.v1.PermissionsService"}, checkPermissionMethod, true},
+		{"full method name", []string{"authzed.api.v1.PermissionsService/CheckPermission"}, checkPermissionMethod, true},
+		{"full method name with slash", []string{checkPermissionMethod}, checkPermissionMethod, true},
+		{"other method", []string{"CheckPermission"}, "/authzed.api.v1.PermissionsService/WriteRelationships", false},
+		{"other service", []string{"authzed.api.v1.PermissionsService"}, "/authzed.api.v1.SchemaService/WriteSchema", false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := (&TokenScope{Methods: tc.methods}).checkMethod(tc.fullName)
+			if tc.isAllowed {
+				require.NoError(t, err)
+			} else {
+				grpcutil.RequireStatus(t, codes.PermissionDenied, err)
+			}
+		})
+	}
+}
+
+func TestScopeNamespaces(t *testing.T) {
+	scope := &TokenScope{Namespaces: []string{"document", "user"}}
+
+	testCases := []struct {
+		name      string
+		request   any
+		isAllowed bool
+	}{
+		{
+			"check within scope",
+			&v1.CheckPermissionRequest{
+				Resource:   &v1.ObjectReference{ObjectType: "document", ObjectId: "doc"},
+				Permission: "view",
+				Subject:    &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "tom"}},
+			},
+			true,
+		},
+		{
+			"check with subject outside scope",
+			&v1.CheckPermissionRequest{
+				Resource:   &v1.ObjectReference{ObjectType: "document", ObjectId: "doc"},
+				Permission: "view",
+				Subject:    &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "group", ObjectId: "eng"}, OptionalRelation: "member"},
+			},
+			false,
+		},
+		{
+			"write with relationship outside scope",
+			&v1.WriteRelationshipsRequest{
+				Updates: []*v1.RelationshipUpdate{
+					{
+						Operation: v1.RelationshipUpdate_OPERATION_TOUCH,
+						Relationship: &v1.Relationship{
+							Resource: &v1.ObjectReference{ObjectType: "document", ObjectId: "doc"},
+							Relation: "viewer",
+							Subject:  &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "tom"}},
+						},
+					},
+					{
+						Operation: v1.RelationshipUpdate_OPERATION_TOUCH,
+						Relationship: &v1.Relationship{
+							Resource: &v1.ObjectReference{ObjectType: "folder", ObjectId: "root"},
+							Relation: "viewer",
+							Subject:  &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "tom"}},
+						},
+					},
+				},
+			},
+			false,
+		},
+		{
+			"read relationships within scope",
+			&v1.ReadRelationshipsRequest{RelationshipFilter: &v1.RelationshipFilter{ResourceType: "document"}},
+			true,
+		},
+		{
+			"lookup resources outside scope",
+			&v1.LookupResourcesRequest{
+				ResourceObjectType: "folder",
+				Permission:         "view",
+				Subject:            &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "tom"}},
+			},
+			false,
+		},
+		{
+			"watch within scope",
+			&v1.WatchRequest{OptionalObjectTypes: []string{"document"}},
+			true,
+		},
+		{
+			"watch of all types",
+			&v1.WatchRequest{},
+			false,
+		},
+		{
+			"schema write",
+			&v1.WriteSchemaRequest{Schema: "definition document {}"},
+			false,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := scope.checkRequest(tc.request)
+			if tc.isAllowed {
+				require.NoError(t, err)
+			} else {
+				grpcutil.RequireStatus(t, codes.PermissionDenied, err)
+			}
+
+			require.NoError(t, (&TokenScope{}).checkRequest(tc.request))
+		})
+	}
+}
+
+func TestScopeUnaryServerInterceptor(t *testing.T) {
+	interceptor := ScopeUnaryServerInterceptor()
+	info := &grpc.UnaryServerInfo{FullMethod: checkPermissionMethod}
+	handler := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+	req := &v1.CheckPermissionRequest{
+		Resource: &v1.ObjectReference{ObjectType: "document", ObjectId: "doc"},
+		Subject:  &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "tom"}},
+	}
+
+	resp, err := interceptor(context.Background(), req, info, handler)
+	require.NoError(t, err)
+	require.Equal(t, "ok", resp)
+
+	ctx := ContextWithScope(context.Background(), &TokenScope{Methods: []string{"CheckPermission"}, Namespaces: []string{"document", "user"}})
+	resp, err = interceptor(ctx, req, info, handler)
+	require.NoError(t, err)
+	require.Equal(t, "ok", resp)
+
+	ctx = ContextWithScope(context.Background(), &TokenScope{Methods: []string{"WriteRelationships"}})
+	_, err = interceptor(ctx, req, info, handler)
+	grpcutil.RequireStatus(t, codes.PermissionDenied, err)
+
+	ctx = ContextWithScope(context.Background(), &TokenScope{Namespaces: []string{"document"}})
+	_, err = interceptor(ctx, req, info, handler)
+	grpcutil.RequireStatus(t, codes.PermissionDenied, err)
+}
@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/authzed/grpcutil"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+var (
+	frontendKey = ScopedKey{Name: "frontend", Key: "frontendkey", Methods: []string{"CheckPermission"}}
+	syncKey     = ScopedKey{Name: "sync", Key: "synckey", Methods: []string{"WriteRelationships"}, Namespaces: []string{"document"}}
+)
+
+func TestValidateScopedKeys(t *testing.T) {
+	require.NoError(t, ValidateScopedKeys(nil))
+	require.NoError(t, ValidateScopedKeys([]ScopedKey{frontendKey, syncKey}))
+
+	for expectedError, keys := range map[string][]ScopedKey{
+		"must have a name":          {{Key: "somekey"}},
+		"duplicate":                 {frontendKey, {Name: "frontend", Key: "otherkey"}},
+		"`empty` is empty":          {{Name: "empty"}},
+		"reuses the key of another": {frontendKey, {Name: "other", Key: frontendKey.Key}},
+	} {
+		require.ErrorContains(t, ValidateScopedKeys(keys), expectedError)
+	}
+}
+
+func TestRequireScopedKeyOr(t *testing.T) {
+	f := RequireScopedKeyOr([]ScopedKey{frontendKey, syncKey}, MustRequirePresharedKey([]string{"adminkey"}))
+
+	before := testutil.ToFloat64(scopedKeyRequestsCounter.WithLabelValues("sync"))
+	ctx, err := f(withTokenMetadata("bearer synckey"))
+	require.NoError(t, err)
+	scope, ok := ScopeFromContext(ctx)
+	require.True(t, ok)
+	require.Equal(t, "sync", scope.KeyName)
+	require.Equal(t, syncKey.Methods, scope.Methods)
+	require.Equal(t, syncKey.Namespaces, scope.Namespaces)
+	require.Equal(t, "key:sync", PrincipalFromContext(ctx))
+	require.Equal(t, before+1, testutil.ToFloat64(scopedKeyRequestsCounter.WithLabelValues("sync")))
+
+	// Other keys are authenticated by the fallback, without restriction.
+	ctx, err = f(withTokenMetadata("bearer adminkey"))
+	require.NoError(t, err)
+	_, ok = ScopeFromContext(ctx)
+	require.False(t, ok)
+
+	_, err = f(withTokenMetadata("bearer unknownkey"))
+	grpcutil.RequireStatus(t, codes.PermissionDenied, err)
+
+	_, err = f(context.Background())
+	grpcutil.RequireStatus(t, codes.Unauthenticated, err)
+}
+
+func TestScopedKeyDenied(t *testing.T) {
+	f := RequireScopedKeyOr([]ScopedKey{frontendKey}, MustRequirePresharedKey([]string{"adminkey"}))
+	ctx, err := f(withTokenMetadata("bearer frontendkey"))
+	require.NoError(t, err)
+
+	interceptor := ScopeUnaryServerInterceptor()
+	handler := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+
+	before := testutil.ToFloat64(scopedKeyDeniedCounter.WithLabelValues("frontend"))
+	_, err = interceptor(ctx, &v1.CheckPermissionRequest{}, &grpc.UnaryServerInfo{FullMethod: checkPermissionMethod}, handler)
+	require.NoError(t, err)
+
+	_, err = interceptor(ctx, &v1.WriteRelationshipsRequest{}, &grpc.UnaryServerInfo{FullMethod: "/authzed.api.v1.PermissionsService/WriteRelationships"}, handler)
+	grpcutil.RequireStatus(t, codes.PermissionDenied, err)
+	require.Equal(t, before+1, testutil.ToFloat64(scopedKeyDeniedCounter.WithLabelValues("frontend")))
+}
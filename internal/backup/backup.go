@@ -0,0 +1,362 @@
+// Package backup implements a file format holding a consistent export of the schema and
+// relationships of a datastore, which can be restored into any datastore engine.
+//
+// A backup is a header line, holding the schema and the revision at which it was taken
+// as JSON, followed by a line for each relationship in its string form. It may be
+// compressed with gzip and encrypted, in which case the compressed backup is encrypted.
+package backup
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"github.com/authzed/spicedb/internal/relationships"
+	"github.com/authzed/spicedb/internal/services/shared"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/generator"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// FormatVersion is the version of the backup format written.
+const FormatVersion = 1
+
+// pageSize is the number of relationships read from, or written to, the datastore at once.
+const pageSize = 1_000
+
+// Header is the first line of a backup.
+type Header struct {
+	Version  int    `json:"version"`
+	Revision string `json:"revision"`
+	Schema   string `json:"schema"`
+}
+
+// Options control how a backup is written or read.
+type Options struct {
+	// Compress compresses the backup with gzip. Compressed backups are detected when read.
+	Compress bool
+
+	// EncryptionKey, if not empty, encrypts the backup with the key, or decrypts it when read.
+	EncryptionKey []byte
+}
+
+// Stats describe what was written or restored.
+type Stats struct {
+	Revision      string
+	Relationships uint64
+}
+
+// Write writes a backup of the schema and relationships of the datastore, as of its head
+// revision, to the writer.
+func Write(ctx context.Context, ds datastore.Datastore, w io.Writer, opts Options) (Stats, error) {
+	revision, err := ds.HeadRevision(ctx)
+	if err != nil {
+		return Stats{}, fmt.Errorf("failed to read head revision: %w", err)
+	}
+
+	// Backups of large datasets can outlive the GC window.
+	if pinner := datastore.UnwrapAs[datastore.RevisionPinningDatastore](ds); pinner != nil {
+		defer pinner.PinRevision(revision)()
+	}
+	reader := ds.SnapshotReader(revision)
+
+	schema, namespaces, err := readSchema(ctx, reader)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	out, closeOut, err := wrapWriter(w, opts)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	buffered := bufio.NewWriter(out)
+	if err := json.NewEncoder(buffered).Encode(Header{Version: FormatVersion, Revision: revision.String(), Schema: schema}); err != nil {
+		return Stats{}, fmt.Errorf("failed to write backup header: %w", err)
+	}
+
+	stats := Stats{Revision: revision.String()}
+	for _, namespace := range namespaces {
+		limit := uint64(pageSize)
+		var cursor options.Cursor
+		for {
+			it, err := reader.QueryRelationships(ctx, datastore.RelationshipsFilter{ResourceType: namespace},
+				options.WithLimit(&limit),
+				options.WithAfter(cursor),
+				options.WithSort(options.ByResource),
+			)
+			if err != nil {
+				return Stats{}, fmt.Errorf("failed to read relationships: %w", err)
+			}
+
+			var read uint64
+			for rel := it.Next(); rel != nil; rel = it.Next() {
+				line, err := tuple.String(rel)
+				if err != nil {
+					it.Close()
+					return Stats{}, fmt.Errorf("failed to write relationship: %w", err)
+				}
+				if _, err := buffered.WriteString(line + "\n"); err != nil {
+					it.Close()
+					return Stats{}, fmt.Errorf("failed to write relationship: %w", err)
+				}
+				cursor = rel.CloneVT()
+				read++
+			}
+			if it.Err() != nil {
+				it.Close()
+				return Stats{}, fmt.Errorf("failed to read relationships: %w", it.Err())
+			}
+			it.Close()
+
+			stats.Relationships += read
+			if read < limit {
+				break
+			}
+		}
+	}
+
+	if err := buffered.Flush(); err != nil {
+		return Stats{}, fmt.Errorf("failed to write backup: %w", err)
+	}
+	if err := closeOut(); err != nil {
+		return Stats{}, fmt.Errorf("failed to write backup: %w", err)
+	}
+	return stats, nil
+}
+
+// readSchema returns the schema of the reader, and the names of its object definitions in a
+// stable order.
+func readSchema(ctx context.Context, reader datastore.Reader) (string, []string, error) {
+	nsDefs, err := reader.ListAllNamespaces(ctx)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read schema: %w", err)
+	}
+
+	caveatDefs, err := reader.ListAllCaveats(ctx)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to read schema: %w", err)
+	}
+
+	definitions := make([]compiler.SchemaDefinition, 0, len(nsDefs)+len(caveatDefs))
+	for _, caveatDef := range caveatDefs {
+		definitions = append(definitions, caveatDef.Definition)
+	}
+
+	namespaces := make([]string, 0, len(nsDefs))
+	for _, nsDef := range nsDefs {
+		definitions = append(definitions, nsDef.Definition)
+		namespaces = append(namespaces, nsDef.Definition.Name)
+	}
+	slices.Sort(namespaces)
+
+	schema, _, err := generator.GenerateSchema(definitions)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to generate schema: %w", err)
+	}
+	return schema, namespaces, nil
+}
+
+// Restore writes the schema and relationships of the backup read from the reader into the
+// datastore, which must not have a schema.
+func Restore(ctx context.Context, ds datastore.Datastore, r io.Reader, opts Options) (Stats, error) {
+	in, err := wrapReader(r, opts)
+	if err != nil {
+		return Stats{}, err
+	}
+
+	lines := bufio.NewScanner(in)
+	lines.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	if !lines.Scan() {
+		if lines.Err() != nil {
+			return Stats{}, fmt.Errorf("failed to read backup header: %w", lines.Err())
+		}
+		return Stats{}, errors.New("backup is empty")
+	}
+
+	var header Header
+	if err := json.Unmarshal(lines.Bytes(), &header); err != nil {
+		return Stats{}, fmt.Errorf("failed to read backup header: %w", err)
+	}
+	if header.Version != FormatVersion {
+		return Stats{}, fmt.Errorf("unsupported backup version %d", header.Version)
+	}
+
+	if err := restoreSchema(ctx, ds, header.Schema); err != nil {
+		return Stats{}, err
+	}
+
+	stats := Stats{Revision: header.Revision}
+	batch := make([]*core.RelationTuple, 0, pageSize)
+	for lines.Scan() {
+		line := lines.Text()
+		rel := tuple.Parse(line)
+		if rel == nil {
+			return stats, fmt.Errorf("invalid relationship in backup: `%s`", line)
+		}
+		batch = append(batch, rel)
+
+		if len(batch) == pageSize {
+			if err := writeBatch(ctx, ds, batch); err != nil {
+				return stats, err
+			}
+			stats.Relationships += uint64(len(batch))
+			batch = batch[:0]
+		}
+	}
+	if lines.Err() != nil {
+		return stats, fmt.Errorf("failed to read backup: %w", lines.Err())
+	}
+
+	if len(batch) > 0 {
+		if err := writeBatch(ctx, ds, batch); err != nil {
+			return stats, err
+		}
+		stats.Relationships += uint64(len(batch))
+	}
+	return stats, nil
+}
+
+func restoreSchema(ctx context.Context, ds datastore.Datastore, schema string) error {
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source("backup"),
+		SchemaString: schema,
+	}, compiler.AllowUnprefixedObjectType())
+	if err != nil {
+		return fmt.Errorf("failed to compile schema of backup: %w", err)
+	}
+
+	validated, err := shared.ValidateSchemaChanges(ctx, compiled, false)
+	if err != nil {
+		return fmt.Errorf("failed to validate schema of backup: %w", err)
+	}
+
+	_, err = ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		existing, err := rwt.ListAllNamespaces(ctx)
+		if err != nil {
+			return err
+		}
+		if len(existing) > 0 {
+			return errors.New("backups can only be restored into a datastore without a schema")
+		}
+
+		_, err = shared.ApplySchemaChanges(ctx, rwt, validated)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to restore schema: %w", err)
+	}
+	return nil
+}
+
+func writeBatch(ctx context.Context, ds datastore.Datastore, batch []*core.RelationTuple) error {
+	_, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		// Backups may have been edited, so their relationships are checked against the schema
+		// which was restored.
+		if err := relationships.ValidateRelationshipsForCreateOrTouch(ctx, rwt, batch); err != nil {
+			return err
+		}
+
+		_, err := rwt.BulkLoad(ctx, &sliceSource{rels: batch})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to restore relationships: %w", err)
+	}
+	return nil
+}
+
+// sliceSource is a bulk load source of the relationships of a slice.
+type sliceSource struct {
+	rels []*core.RelationTuple
+	next int
+}
+
+func (ss *sliceSource) Next(_ context.Context) (*core.RelationTuple, error) {
+	if ss.next >= len(ss.rels) {
+		return nil, nil
+	}
+	ss.next++
+	return ss.rels[ss.next-1], nil
+}
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// wrapWriter returns a writer which compresses and encrypts according to the options, and
+// a function which must be called once everything has been written.
+func wrapWriter(w io.Writer, opts Options) (io.Writer, func() error, error) {
+	closers := []func() error{}
+	if len(opts.EncryptionKey) > 0 {
+		encrypted, err := newEncryptingWriter(w, opts.EncryptionKey)
+		if err != nil {
+			return nil, nil, err
+		}
+		w = encrypted
+		closers = append(closers, encrypted.Close)
+	}
+
+	if opts.Compress {
+		compressed := gzip.NewWriter(w)
+		w = compressed
+		closers = append(closers, compressed.Close)
+	}
+
+	return w, func() error {
+		// The innermost writer, which was created last, is closed first.
+		for i := len(closers) - 1; i >= 0; i-- {
+			if err := closers[i](); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, nil
+}
+
+// wrapReader returns a reader which decrypts and decompresses the backup, as needed.
+func wrapReader(r io.Reader, opts Options) (io.Reader, error) {
+	buffered := bufio.NewReader(r)
+	magic, err := buffered.Peek(len(encryptionMagic))
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("failed to read backup: %w", err)
+	}
+
+	r = buffered
+	if bytes.Equal(magic, []byte(encryptionMagic)) {
+		if len(opts.EncryptionKey) == 0 {
+			return nil, errors.New("backup is encrypted, but no encryption key was given")
+		}
+
+		decrypted, err := newDecryptingReader(buffered, opts.EncryptionKey)
+		if err != nil {
+			return nil, err
+		}
+
+		buffered = bufio.NewReader(decrypted)
+		r = buffered
+		magic, err = buffered.Peek(len(gzipMagic))
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to read backup: %w", err)
+		}
+	} else if len(opts.EncryptionKey) > 0 {
+		return nil, errors.New("an encryption key was given, but the backup is not encrypted")
+	}
+
+	if bytes.HasPrefix(magic, gzipMagic) {
+		decompressed, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decompress backup: %w", err)
+		}
+		return decompressed, nil
+	}
+	return r, nil
+}
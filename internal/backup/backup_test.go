@@ -0,0 +1,130 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const testSchema = `caveat only_on_tuesday(day_of_week string) {
+  day_of_week == 'tuesday'
+}
+
+definition user {}
+
+definition document {
+  relation viewer: user | user with only_on_tuesday
+  permission view = viewer
+}`
+
+// testBackup returns an uncompressed, unencrypted backup with more relationships than are
+// read from the datastore at once.
+func testBackup(t *testing.T) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, json.NewEncoder(&buf).Encode(Header{Version: FormatVersion, Revision: "1", Schema: testSchema}))
+	buf.WriteString("document:caveated#viewer@user:tom[only_on_tuesday:{\"day_of_week\":\"tuesday\"}]\n")
+	for i := 0; i < pageSize+500; i++ {
+		fmt.Fprintf(&buf, "document:doc%d#viewer@user:user%d\n", i, i)
+	}
+	return buf.Bytes()
+}
+
+func newDatastore(t *testing.T) datastore.Datastore {
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+	t.Cleanup(func() { ds.Close() })
+	return ds
+}
+
+func allRelationships(t *testing.T, ds datastore.Datastore) []string {
+	revision, err := ds.HeadRevision(context.Background())
+	require.NoError(t, err)
+
+	it, err := ds.SnapshotReader(revision).QueryRelationships(context.Background(), datastore.RelationshipsFilter{ResourceType: "document"})
+	require.NoError(t, err)
+	defer it.Close()
+
+	var rels []string
+	for rel := it.Next(); rel != nil; rel = it.Next() {
+		rels = append(rels, tuple.MustString(rel))
+	}
+	require.NoError(t, it.Err())
+	sort.Strings(rels)
+	return rels
+}
+
+func TestBackupAndRestore(t *testing.T) {
+	source := newDatastore(t)
+	stats, err := Restore(context.Background(), source, bytes.NewReader(testBackup(t)), Options{})
+	require.NoError(t, err)
+	require.Equal(t, uint64(pageSize+501), stats.Relationships)
+
+	for _, tc := range []struct {
+		name string
+		opts Options
+	}{
+		{"plain", Options{}},
+		{"compressed", Options{Compress: true}},
+		{"encrypted", Options{EncryptionKey: []byte("some secret")}},
+		{"compressed and encrypted", Options{Compress: true, EncryptionKey: []byte("some secret")}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var backup bytes.Buffer
+			written, err := Write(context.Background(), source, &backup, tc.opts)
+			require.NoError(t, err)
+			require.Equal(t, uint64(pageSize+501), written.Relationships)
+
+			target := newDatastore(t)
+			restored, err := Restore(context.Background(), target, bytes.NewReader(backup.Bytes()), tc.opts)
+			require.NoError(t, err)
+			require.Equal(t, written, restored)
+			require.Equal(t, allRelationships(t, source), allRelationships(t, target))
+
+			// Backups cannot be restored over an existing schema.
+			_, err = Restore(context.Background(), target, bytes.NewReader(backup.Bytes()), tc.opts)
+			require.ErrorContains(t, err, "without a schema")
+		})
+	}
+}
+
+func TestRestoreEncryptionErrors(t *testing.T) {
+	source := newDatastore(t)
+	_, err := Restore(context.Background(), source, bytes.NewReader(testBackup(t)), Options{})
+	require.NoError(t, err)
+
+	var backup bytes.Buffer
+	_, err = Write(context.Background(), source, &backup, Options{EncryptionKey: []byte("some secret")})
+	require.NoError(t, err)
+
+	_, err = Restore(context.Background(), newDatastore(t), bytes.NewReader(backup.Bytes()), Options{})
+	require.ErrorContains(t, err, "no encryption key was given")
+
+	_, err = Restore(context.Background(), newDatastore(t), bytes.NewReader(backup.Bytes()), Options{EncryptionKey: []byte("wrong secret")})
+	require.ErrorContains(t, err, "the key is wrong")
+
+	truncated := backup.Bytes()[:backup.Len()/2]
+	_, err = Restore(context.Background(), newDatastore(t), bytes.NewReader(truncated), Options{EncryptionKey: []byte("some secret")})
+	require.Error(t, err)
+
+	_, err = Restore(context.Background(), newDatastore(t), strings.NewReader(string(testBackup(t))), Options{EncryptionKey: []byte("some secret")})
+	require.ErrorContains(t, err, "not encrypted")
+}
+
+func TestRestoreInvalidRelationship(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, json.NewEncoder(&buf).Encode(Header{Version: FormatVersion, Revision: "1", Schema: testSchema}))
+	buf.WriteString("document:doc#viewer@document:other\n")
+
+	_, err := Restore(context.Background(), newDatastore(t), &buf, Options{})
+	require.ErrorContains(t, err, "cannot write relationship `document:doc#viewer@document:other`")
+}
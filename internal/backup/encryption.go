@@ -0,0 +1,213 @@
+package backup
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ReadEncryptionKeyFile reads the encryption key from the file at the path, ignoring
+// surrounding whitespace.
+func ReadEncryptionKeyFile(path string) ([]byte, error) {
+	key, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read encryption key file: %w", err)
+	}
+
+	key = bytes.TrimSpace(key)
+	if len(key) == 0 {
+		return nil, fmt.Errorf("encryption key file %s is empty", path)
+	}
+	return key, nil
+}
+
+// encryptionMagic starts each encrypted backup.
+const encryptionMagic = "SPICEDB-BACKUP-AES-GCM-1\n"
+
+const (
+	// chunkSize is the maximum size of the plaintext of each encrypted chunk.
+	chunkSize = 64 * 1024
+
+	// lastChunkFlag is set in the length of the final chunk, so that a truncated backup is
+	// detected rather than silently restored in part.
+	lastChunkFlag = 1 << 31
+)
+
+// An encrypted backup is the magic, a random nonce prefix, and a sequence of chunks, each
+// the length of its ciphertext followed by the plaintext sealed with AES-256-GCM. The nonce
+// of each chunk is the prefix followed by the index of the chunk, and whether the chunk is
+// the last one is authenticated as additional data.
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	// The key may be given as a passphrase of any length, so it is hashed to the size of
+	// an AES-256 key.
+	hashed := sha256.Sum256(key)
+	block, err := aes.NewCipher(hashed[:])
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+func chunkNonce(prefix []byte, index uint64) []byte {
+	nonce := make([]byte, len(prefix)+8)
+	copy(nonce, prefix)
+	binary.BigEndian.PutUint64(nonce[len(prefix):], index)
+	return nonce
+}
+
+func lastChunkData(last bool) []byte {
+	if last {
+		return []byte{1}
+	}
+	return []byte{0}
+}
+
+type encryptingWriter struct {
+	w      io.Writer
+	aead   cipher.AEAD
+	prefix []byte
+	index  uint64
+	buf    []byte
+}
+
+func newEncryptingWriter(w io.Writer, key []byte) (*encryptingWriter, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := make([]byte, aead.NonceSize()-8)
+	if _, err := rand.Read(prefix); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %w", err)
+	}
+
+	if _, err := io.WriteString(w, encryptionMagic); err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(prefix); err != nil {
+		return nil, err
+	}
+
+	return &encryptingWriter{w: w, aead: aead, prefix: prefix, buf: make([]byte, 0, chunkSize)}, nil
+}
+
+func (ew *encryptingWriter) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		n := min(chunkSize-len(ew.buf), len(p))
+		ew.buf = append(ew.buf, p[:n]...)
+		p = p[n:]
+		written += n
+
+		// A full chunk is only written once more data follows, so that the last chunk is
+		// known to be the last when it is written.
+		if len(ew.buf) == chunkSize && len(p) > 0 {
+			if err := ew.writeChunk(false); err != nil {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+func (ew *encryptingWriter) writeChunk(last bool) error {
+	sealed := ew.aead.Seal(nil, chunkNonce(ew.prefix, ew.index), ew.buf, lastChunkData(last))
+	ew.index++
+	ew.buf = ew.buf[:0]
+
+	length := uint32(len(sealed))
+	if last {
+		length |= lastChunkFlag
+	}
+	if err := binary.Write(ew.w, binary.BigEndian, length); err != nil {
+		return err
+	}
+	_, err := ew.w.Write(sealed)
+	return err
+}
+
+func (ew *encryptingWriter) Close() error {
+	if len(ew.buf) == chunkSize {
+		if err := ew.writeChunk(false); err != nil {
+			return err
+		}
+	}
+	return ew.writeChunk(true)
+}
+
+type decryptingReader struct {
+	r      io.Reader
+	aead   cipher.AEAD
+	prefix []byte
+	index  uint64
+	buf    []byte
+	last   bool
+}
+
+func newDecryptingReader(r io.Reader, key []byte) (*decryptingReader, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	header := make([]byte, len(encryptionMagic)+aead.NonceSize()-8)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, fmt.Errorf("failed to read encrypted backup: %w", err)
+	}
+
+	return &decryptingReader{r: r, aead: aead, prefix: header[len(encryptionMagic):]}, nil
+}
+
+func (dr *decryptingReader) Read(p []byte) (int, error) {
+	for len(dr.buf) == 0 {
+		if dr.last {
+			return 0, io.EOF
+		}
+		if err := dr.readChunk(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, dr.buf)
+	dr.buf = dr.buf[n:]
+	return n, nil
+}
+
+func (dr *decryptingReader) readChunk() error {
+	var length uint32
+	if err := binary.Read(dr.r, binary.BigEndian, &length); err != nil {
+		if errors.Is(err, io.EOF) {
+			return errors.New("encrypted backup is truncated")
+		}
+		return fmt.Errorf("failed to read encrypted backup: %w", err)
+	}
+
+	last := length&lastChunkFlag != 0
+	length &^= lastChunkFlag
+	if length > chunkSize+uint32(dr.aead.Overhead()) {
+		return errors.New("encrypted backup is corrupt")
+	}
+
+	sealed := make([]byte, length)
+	if _, err := io.ReadFull(dr.r, sealed); err != nil {
+		return fmt.Errorf("failed to read encrypted backup: %w", err)
+	}
+
+	opened, err := dr.aead.Open(nil, chunkNonce(dr.prefix, dr.index), sealed, lastChunkData(last))
+	if err != nil {
+		return errors.New("failed to decrypt backup: the key is wrong or the backup is corrupt")
+	}
+
+	dr.index++
+	dr.buf = opened
+	dr.last = last
+	return nil
+}
@@ -0,0 +1,266 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+const (
+	// objectPrefix and objectSuffix delimit the names of the backups written by the
+	// scheduler, which are named after the time they were started so that they sort
+	// chronologically.
+	objectPrefix = "spicedb-backup-"
+	objectSuffix = ".backup"
+
+	// checksumSuffix is the suffix of the object holding the hex-encoded SHA-256 checksum of
+	// the backup of the same name.
+	checksumSuffix = ".sha256"
+
+	objectTimeFormat = "20060102T150405Z"
+)
+
+var scheduledBackupsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "backup",
+	Name:      "scheduled_backups_total",
+	Help:      "total number of scheduled backups, by result",
+}, []string{"result"})
+
+// Scheduler periodically writes backups of the datastore to a bucket, keeping only the most
+// recent ones.
+type Scheduler struct {
+	bucket    Bucket
+	interval  time.Duration
+	retention int
+	opts      Options
+}
+
+// NewScheduler creates a scheduler writing a backup to the bucket every interval, and
+// deleting all but the given number of most recent backups after each one.
+func NewScheduler(bucket Bucket, interval time.Duration, retention int, opts Options) (*Scheduler, error) {
+	if interval <= 0 {
+		return nil, errors.New("backup interval must be positive")
+	}
+	if retention <= 0 {
+		return nil, errors.New("backup retention must be positive")
+	}
+	return &Scheduler{bucket: bucket, interval: interval, retention: retention, opts: opts}, nil
+}
+
+// Close closes the bucket.
+func (s *Scheduler) Close() error {
+	return s.bucket.Close()
+}
+
+// Run writes backups until the context is canceled. Failed backups are logged and retried at
+// the next interval. It may be run again once it returns, until the scheduler is closed.
+func (s *Scheduler) Run(ctx context.Context, ds datastore.Datastore) error {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Ctx(ctx).Info().Msg("shutting down backup scheduler")
+			return nil
+
+		case <-ticker.C:
+			name, stats, err := s.backup(ctx, ds, time.Now())
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				scheduledBackupsCounter.WithLabelValues("error").Inc()
+				log.Ctx(ctx).Error().Err(err).Msg("scheduled backup failed")
+				continue
+			}
+
+			scheduledBackupsCounter.WithLabelValues("success").Inc()
+			log.Ctx(ctx).Info().
+				Str("name", name).
+				Str("revision", stats.Revision).
+				Uint64("relationships", stats.Relationships).
+				Msg("scheduled backup completed")
+
+			if err := s.prune(ctx); err != nil {
+				log.Ctx(ctx).Warn().Err(err).Msg("failed to delete expired backups")
+			}
+		}
+	}
+}
+
+// backup streams a backup to the bucket, followed by its checksum, returning its name.
+func (s *Scheduler) backup(ctx context.Context, ds datastore.Datastore, now time.Time) (string, Stats, error) {
+	name := objectPrefix + now.UTC().Format(objectTimeFormat) + objectSuffix
+
+	checksum, stats, err := Upload(ctx, ds, s.bucket, name, s.opts)
+	if err != nil {
+		return "", Stats{}, err
+	}
+	return name, stats, UploadChecksum(ctx, s.bucket, name, checksum)
+}
+
+// prune deletes the backups beyond the retention, oldest first.
+func (s *Scheduler) prune(ctx context.Context) error {
+	names, err := s.bucket.List(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	var backups []string
+	for _, name := range names {
+		if strings.HasPrefix(name, objectPrefix) && strings.HasSuffix(name, objectSuffix) {
+			backups = append(backups, name)
+		}
+	}
+	if len(backups) <= s.retention {
+		return nil
+	}
+
+	for _, name := range backups[:len(backups)-s.retention] {
+		if err := s.bucket.Delete(ctx, name); err != nil {
+			return fmt.Errorf("failed to delete backup %s: %w", name, err)
+		}
+		// Backups uploaded without a checksum are deleted regardless.
+		_ = s.bucket.Delete(ctx, name+checksumSuffix)
+	}
+	return nil
+}
+
+// Upload streams a backup of the datastore to the object of the given name in the bucket,
+// returning its hex-encoded SHA-256 checksum.
+func Upload(ctx context.Context, ds datastore.Datastore, bucket Bucket, name string, opts Options) (string, Stats, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	pr, pw := io.Pipe()
+	hash := sha256.New()
+
+	type result struct {
+		stats Stats
+		err   error
+	}
+	written := make(chan result, 1)
+	go func() {
+		stats, err := Write(ctx, ds, io.MultiWriter(pw, hash), opts)
+		_ = pw.CloseWithError(err)
+		written <- result{stats, err}
+	}()
+
+	err := bucket.Upload(ctx, name, pr)
+	// Unblock the writer if the upload stopped reading early.
+	_ = pr.CloseWithError(errors.New("upload ended"))
+	cancel()
+
+	// A failure to write the backup also fails the upload, with the same error.
+	res := <-written
+	if err != nil {
+		return "", Stats{}, fmt.Errorf("failed to upload backup %s: %w", name, err)
+	}
+	if res.err != nil {
+		return "", Stats{}, res.err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), res.stats, nil
+}
+
+// UploadChecksum uploads the checksum of the backup of the given name alongside it, so that
+// it is verified when downloaded.
+func UploadChecksum(ctx context.Context, bucket Bucket, name string, checksum string) error {
+	if err := bucket.Upload(ctx, name+checksumSuffix, strings.NewReader(checksum+"\n")); err != nil {
+		return fmt.Errorf("failed to upload checksum of backup %s: %w", name, err)
+	}
+	return nil
+}
+
+// Download downloads the backup of the given name from the bucket to a temporary file, and
+// verifies its checksum, if any, before it is restored. The caller must close and remove the
+// returned file.
+func Download(ctx context.Context, bucket Bucket, name string) (*os.File, error) {
+	expected, err := readChecksum(ctx, bucket, name)
+	if err != nil {
+		return nil, err
+	}
+
+	in, err := bucket.Download(ctx, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download backup %s: %w", name, err)
+	}
+	defer in.Close()
+
+	file, err := os.CreateTemp("", "spicedb-restore-*")
+	if err != nil {
+		return nil, fmt.Errorf("failed to download backup %s: %w", name, err)
+	}
+
+	hash := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(file, hash), in); err != nil {
+		removeFile(file)
+		return nil, fmt.Errorf("failed to download backup %s: %w", name, err)
+	}
+
+	if expected != "" {
+		if actual := hex.EncodeToString(hash.Sum(nil)); actual != expected {
+			removeFile(file)
+			return nil, fmt.Errorf("checksum of backup %s does not match: expected %s, found %s", name, expected, actual)
+		}
+	} else {
+		log.Ctx(ctx).Warn().Str("name", name).Msg("backup has no checksum; restoring without verification")
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		removeFile(file)
+		return nil, fmt.Errorf("failed to download backup %s: %w", name, err)
+	}
+	return file, nil
+}
+
+// readChecksum returns the checksum uploaded alongside the backup, or an empty string if the
+// bucket has none.
+func readChecksum(ctx context.Context, bucket Bucket, name string) (string, error) {
+	names, err := bucket.List(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to list backups: %w", err)
+	}
+
+	found := false
+	for _, listed := range names {
+		if listed == name+checksumSuffix {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return "", nil
+	}
+
+	in, err := bucket.Download(ctx, name+checksumSuffix)
+	if err != nil {
+		return "", fmt.Errorf("failed to download checksum of backup %s: %w", name, err)
+	}
+	defer in.Close()
+
+	contents, err := io.ReadAll(in)
+	if err != nil {
+		return "", fmt.Errorf("failed to download checksum of backup %s: %w", name, err)
+	}
+	return string(bytes.TrimSpace(contents)), nil
+}
+
+func removeFile(file *os.File) {
+	_ = file.Close()
+	_ = os.Remove(file.Name())
+}
@@ -0,0 +1,90 @@
+package backup
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenBucket(t *testing.T) {
+	for _, bucketURL := range []string{"ftp://bucket", "s3:///prefix", "gs://", "not a url\x7f"} {
+		_, err := OpenBucket(context.Background(), bucketURL)
+		require.Error(t, err, bucketURL)
+	}
+
+	bucketURL, name, err := SplitObjectURL("s3://bucket/prefix/spicedb-backup-20240102T030405Z.backup?region=us-east-1")
+	require.NoError(t, err)
+	require.Equal(t, "s3://bucket/prefix?region=us-east-1", bucketURL)
+	require.Equal(t, "spicedb-backup-20240102T030405Z.backup", name)
+
+	_, _, err = SplitObjectURL("gs://bucket/prefix/")
+	require.ErrorContains(t, err, "does not name an object")
+
+	require.True(t, IsBucketURL("gs://bucket/prefix"))
+	require.False(t, IsBucketURL("/tmp/backup"))
+}
+
+func TestScheduledBackups(t *testing.T) {
+	ctx := context.Background()
+	source := newDatastore(t)
+	_, err := Restore(ctx, source, bytes.NewReader(testBackup(t)), Options{})
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	bucket, err := OpenBucket(ctx, "file://"+dir)
+	require.NoError(t, err)
+
+	opts := Options{Compress: true, EncryptionKey: []byte("some secret")}
+	scheduler, err := NewScheduler(bucket, time.Hour, 2, opts)
+	require.NoError(t, err)
+
+	start := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	for i := 0; i < 3; i++ {
+		_, stats, err := scheduler.backup(ctx, source, start.Add(time.Duration(i)*time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, uint64(pageSize+501), stats.Relationships)
+	}
+	require.NoError(t, scheduler.prune(ctx))
+
+	// Only the most recent backups are kept, each with its checksum.
+	names, err := bucket.List(ctx)
+	require.NoError(t, err)
+	require.Equal(t, []string{
+		"spicedb-backup-20240102T040405Z.backup",
+		"spicedb-backup-20240102T040405Z.backup.sha256",
+		"spicedb-backup-20240102T050405Z.backup",
+		"spicedb-backup-20240102T050405Z.backup.sha256",
+	}, names)
+
+	file, err := Download(ctx, bucket, "spicedb-backup-20240102T050405Z.backup")
+	require.NoError(t, err)
+	defer removeFile(file)
+
+	restored := newDatastore(t)
+	_, err = Restore(ctx, restored, file, opts)
+	require.NoError(t, err)
+	require.Equal(t, allRelationships(t, source), allRelationships(t, restored))
+
+	// Corrupted backups are not restored.
+	path := filepath.Join(dir, "spicedb-backup-20240102T040405Z.backup")
+	contents, err := os.ReadFile(path)
+	require.NoError(t, err)
+	contents[len(contents)/2] ^= 0xff
+	require.NoError(t, os.WriteFile(path, contents, 0o600))
+
+	_, err = Download(ctx, bucket, "spicedb-backup-20240102T040405Z.backup")
+	require.ErrorContains(t, err, "checksum of backup spicedb-backup-20240102T040405Z.backup does not match")
+}
+
+func TestNewScheduler(t *testing.T) {
+	_, err := NewScheduler(&fileBucket{}, 0, 1, Options{})
+	require.ErrorContains(t, err, "interval must be positive")
+
+	_, err = NewScheduler(&fileBucket{}, time.Hour, 0, Options{})
+	require.ErrorContains(t, err, "retention must be positive")
+}
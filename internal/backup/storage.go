@@ -0,0 +1,306 @@
+package backup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	gcs "cloud.google.com/go/storage"
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"google.golang.org/api/iterator"
+)
+
+// Bucket is a location in object storage to which backups are written.
+type Bucket interface {
+	// Upload writes the object of the given name with the contents read from the reader.
+	Upload(ctx context.Context, name string, r io.Reader) error
+
+	// Download opens the object of the given name.
+	Download(ctx context.Context, name string) (io.ReadCloser, error)
+
+	// List returns the names of the objects in the bucket, in lexical order.
+	List(ctx context.Context) ([]string, error)
+
+	// Delete deletes the object of the given name.
+	Delete(ctx context.Context, name string) error
+
+	// Close releases the resources of the bucket.
+	Close() error
+}
+
+// OpenBucket opens the bucket at the URL, which is one of:
+//
+//   - `s3://<bucket>/<prefix>`, with the optional `region` and `endpoint` query parameters,
+//     the latter for S3-compatible storage, and credentials from the standard AWS sources;
+//   - `gs://<bucket>/<prefix>`, with Google application default credentials;
+//   - `file:///<directory>`.
+func OpenBucket(ctx context.Context, bucketURL string) (Bucket, error) {
+	parsed, err := url.Parse(bucketURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid backup bucket URL `%s`: %w", bucketURL, err)
+	}
+	prefix := strings.Trim(parsed.Path, "/")
+
+	switch parsed.Scheme {
+	case "s3":
+		if parsed.Host == "" {
+			return nil, fmt.Errorf("missing bucket in backup bucket URL `%s`", bucketURL)
+		}
+		return newS3Bucket(parsed.Host, prefix, parsed.Query())
+
+	case "gs":
+		if parsed.Host == "" {
+			return nil, fmt.Errorf("missing bucket in backup bucket URL `%s`", bucketURL)
+		}
+		client, err := gcs.NewClient(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create GCS client: %w", err)
+		}
+		return &gcsBucket{client: client, bucket: client.Bucket(parsed.Host), prefix: prefix}, nil
+
+	case "file":
+		if err := os.MkdirAll(parsed.Path, 0o700); err != nil {
+			return nil, fmt.Errorf("failed to create backup directory: %w", err)
+		}
+		return &fileBucket{dir: parsed.Path}, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported backup bucket URL `%s`: expected s3://, gs:// or file://", bucketURL)
+	}
+}
+
+// IsBucketURL returns whether the location is the URL of a bucket, or of an object within
+// one, rather than a local path.
+func IsBucketURL(location string) bool {
+	for _, scheme := range []string{"s3://", "gs://", "file://"} {
+		if strings.HasPrefix(location, scheme) {
+			return true
+		}
+	}
+	return false
+}
+
+// SplitObjectURL splits the URL of an object into the URL of the bucket holding it and the
+// name of the object, keeping any query parameters with the bucket.
+func SplitObjectURL(objectURL string) (string, string, error) {
+	parsed, err := url.Parse(objectURL)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid backup URL `%s`: %w", objectURL, err)
+	}
+
+	name := path.Base(parsed.Path)
+	if name == "/" || name == "." || strings.HasSuffix(parsed.Path, "/") {
+		return "", "", fmt.Errorf("backup URL `%s` does not name an object", objectURL)
+	}
+	parsed.Path = path.Dir(parsed.Path)
+	return parsed.String(), name, nil
+}
+
+type s3Bucket struct {
+	client   *s3.S3
+	uploader *s3manager.Uploader
+	bucket   string
+	prefix   string
+}
+
+func newS3Bucket(bucket, prefix string, query url.Values) (*s3Bucket, error) {
+	config := aws.NewConfig()
+	if region := query.Get("region"); region != "" {
+		config = config.WithRegion(region)
+	}
+	if endpoint := query.Get("endpoint"); endpoint != "" {
+		config = config.WithEndpoint(endpoint).WithS3ForcePathStyle(true)
+	}
+
+	sess, err := session.NewSessionWithOptions(session.Options{
+		Config:            *config,
+		SharedConfigState: session.SharedConfigEnable,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create S3 session: %w", err)
+	}
+
+	return &s3Bucket{
+		client:   s3.New(sess),
+		uploader: s3manager.NewUploader(sess),
+		bucket:   bucket,
+		prefix:   prefix,
+	}, nil
+}
+
+func (sb *s3Bucket) key(name string) string {
+	return path.Join(sb.prefix, name)
+}
+
+func (sb *s3Bucket) Upload(ctx context.Context, name string, r io.Reader) error {
+	_, err := sb.uploader.UploadWithContext(ctx, &s3manager.UploadInput{
+		Bucket: aws.String(sb.bucket),
+		Key:    aws.String(sb.key(name)),
+		Body:   r,
+	})
+	return err
+}
+
+func (sb *s3Bucket) Download(ctx context.Context, name string) (io.ReadCloser, error) {
+	out, err := sb.client.GetObjectWithContext(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(sb.bucket),
+		Key:    aws.String(sb.key(name)),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return out.Body, nil
+}
+
+func (sb *s3Bucket) List(ctx context.Context) ([]string, error) {
+	prefix := ""
+	if sb.prefix != "" {
+		prefix = sb.prefix + "/"
+	}
+
+	var names []string
+	err := sb.client.ListObjectsV2PagesWithContext(ctx, &s3.ListObjectsV2Input{
+		Bucket:    aws.String(sb.bucket),
+		Prefix:    aws.String(prefix),
+		Delimiter: aws.String("/"),
+	}, func(page *s3.ListObjectsV2Output, _ bool) bool {
+		for _, object := range page.Contents {
+			names = append(names, strings.TrimPrefix(aws.StringValue(object.Key), prefix))
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (sb *s3Bucket) Delete(ctx context.Context, name string) error {
+	_, err := sb.client.DeleteObjectWithContext(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(sb.bucket),
+		Key:    aws.String(sb.key(name)),
+	})
+	return err
+}
+
+func (sb *s3Bucket) Close() error {
+	return nil
+}
+
+type gcsBucket struct {
+	client *gcs.Client
+	bucket *gcs.BucketHandle
+	prefix string
+}
+
+func (gb *gcsBucket) object(name string) *gcs.ObjectHandle {
+	return gb.bucket.Object(path.Join(gb.prefix, name))
+}
+
+func (gb *gcsBucket) Upload(ctx context.Context, name string, r io.Reader) error {
+	w := gb.object(name).NewWriter(ctx)
+	if _, err := io.Copy(w, r); err != nil {
+		_ = w.CloseWithError(err)
+		return err
+	}
+	return w.Close()
+}
+
+func (gb *gcsBucket) Download(ctx context.Context, name string) (io.ReadCloser, error) {
+	return gb.object(name).NewReader(ctx)
+}
+
+func (gb *gcsBucket) List(ctx context.Context) ([]string, error) {
+	prefix := ""
+	if gb.prefix != "" {
+		prefix = gb.prefix + "/"
+	}
+
+	var names []string
+	it := gb.bucket.Objects(ctx, &gcs.Query{Prefix: prefix, Delimiter: "/"})
+	for {
+		attrs, err := it.Next()
+		if errors.Is(err, iterator.Done) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if attrs.Name == "" {
+			// Prefixes of nested objects are listed without names.
+			continue
+		}
+		names = append(names, strings.TrimPrefix(attrs.Name, prefix))
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (gb *gcsBucket) Delete(ctx context.Context, name string) error {
+	return gb.object(name).Delete(ctx)
+}
+
+func (gb *gcsBucket) Close() error {
+	return gb.client.Close()
+}
+
+type fileBucket struct {
+	dir string
+}
+
+func (fb *fileBucket) Upload(_ context.Context, name string, r io.Reader) error {
+	// The object is written to a temporary file first, so that partial uploads are never
+	// visible under the name.
+	temp, err := os.CreateTemp(fb.dir, "."+name+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+
+	if _, err := io.Copy(temp, r); err != nil {
+		_ = temp.Close()
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(temp.Name(), filepath.Join(fb.dir, name))
+}
+
+func (fb *fileBucket) Download(_ context.Context, name string) (io.ReadCloser, error) {
+	return os.Open(filepath.Join(fb.dir, name))
+}
+
+func (fb *fileBucket) List(_ context.Context) ([]string, error) {
+	entries, err := os.ReadDir(fb.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, entry := range entries {
+		if entry.Type().IsRegular() && !strings.HasPrefix(entry.Name(), ".") {
+			names = append(names, entry.Name())
+		}
+	}
+	return names, nil
+}
+
+func (fb *fileBucket) Delete(_ context.Context, name string) error {
+	return os.Remove(filepath.Join(fb.dir, name))
+}
+
+func (fb *fileBucket) Close() error {
+	return nil
+}
@@ -0,0 +1,344 @@
+// Package changefeed implements an exporter which publishes every change to the relationships
+// and the schema in the datastore to a message broker, such as Kafka, so that downstream
+// systems can follow permission changes without polling the Watch API.
+package changefeed
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/authzed/spicedb/internal/datastore/revisions"
+	"github.com/authzed/spicedb/internal/leaderelection"
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/generator"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+var (
+	publishedEventsCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "spicedb",
+		Subsystem: "changefeed",
+		Name:      "published_events_total",
+		Help:      "total number of changefeed events acknowledged by the broker",
+	})
+
+	restartsCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "spicedb",
+		Subsystem: "changefeed",
+		Name:      "restarts_total",
+		Help:      "total number of times the changefeed exporter restarted from its last checkpoint after failing",
+	})
+
+	backlogGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "spicedb",
+		Subsystem: "changefeed",
+		Name:      "backlog_revisions",
+		Help:      "number of revisions received from the datastore and waiting to be published, as of the last publish",
+	})
+
+	lagGauge = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "spicedb",
+		Subsystem: "changefeed",
+		Name:      "lag_seconds",
+		Help:      "time between the commit of the last published revision and its publishing, for datastores whose revisions are timestamped",
+	})
+)
+
+const (
+	maxRetryInterval = time.Minute
+
+	// schemaEventKey is the key of events of schema changes, so that they are ordered
+	// amongst themselves.
+	schemaEventKey = "schema"
+)
+
+// Message is a message published to the broker.
+type Message struct {
+	Key   []byte
+	Value []byte
+}
+
+// Publisher publishes messages to a broker.
+type Publisher interface {
+	// Publish publishes the messages, in order, and returns once the broker has
+	// acknowledged all of them.
+	Publish(ctx context.Context, messages []Message) error
+
+	// Close closes the connection to the broker.
+	Close()
+}
+
+// Event is the JSON value of each message, describing either the update of a relationship
+// or a change to the schema.
+type Event struct {
+	// Revision is the revision at which the change was made, usable as a ZedToken-style
+	// cursor by consumers.
+	Revision string `json:"revision"`
+
+	// Metadata is the caller-supplied metadata of the transaction which made the change,
+	// if supported by the datastore.
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// RelationshipUpdate is the update of a relationship, in the JSON form of the v1 API's
+	// RelationshipUpdate.
+	RelationshipUpdate json.RawMessage `json:"relationshipUpdate,omitempty"`
+
+	// Labels are the labels of the relationship updated, if any and if supported by the
+	// datastore.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// SchemaChange is a change to the schema.
+	SchemaChange *SchemaChange `json:"schemaChange,omitempty"`
+}
+
+// SchemaChange is the change to the schema made at a revision.
+type SchemaChange struct {
+	// ChangedDefinitions are the object definitions and caveats added or changed, with their
+	// new schema text.
+	ChangedDefinitions map[string]string `json:"changedDefinitions,omitempty"`
+
+	// DeletedDefinitions are the names of the object definitions which were deleted.
+	DeletedDefinitions []string `json:"deletedDefinitions,omitempty"`
+
+	// DeletedCaveats are the names of the caveats which were deleted.
+	DeletedCaveats []string `json:"deletedCaveats,omitempty"`
+}
+
+// Exporter publishes the changes in the datastore with at-least-once delivery: the revision
+// of the last change acknowledged by the broker is periodically checkpointed in the
+// datastore, and the exporter resumes from it when restarted. Changes made after the
+// checkpoint may therefore be published more than once.
+type Exporter struct {
+	publisher          Publisher
+	name               string
+	checkpointInterval time.Duration
+}
+
+// NewExporter creates an exporter publishing changes with the publisher. The name identifies
+// the checkpoint of the exporter, so that exporters to different destinations each keep
+// their own.
+func NewExporter(publisher Publisher, name string, checkpointInterval time.Duration) (*Exporter, error) {
+	if name == "" {
+		return nil, errors.New("changefeed name must not be empty")
+	}
+	if checkpointInterval <= 0 {
+		return nil, errors.New("changefeed checkpoint interval must be positive")
+	}
+
+	return &Exporter{
+		publisher:          publisher,
+		name:               name,
+		checkpointInterval: checkpointInterval,
+	}, nil
+}
+
+// Close closes the connection to the broker.
+func (e *Exporter) Close() {
+	e.publisher.Close()
+}
+
+// Run publishes the changes in the datastore until the context is canceled, restarting from
+// the last checkpoint when watching the datastore or publishing fails. It may be run again
+// once it returns, until the exporter is closed.
+func (e *Exporter) Run(ctx context.Context, ds datastore.Datastore) error {
+	backoffInterval := backoff.NewExponentialBackOff()
+	backoffInterval.MaxInterval = maxRetryInterval
+	backoffInterval.MaxElapsedTime = 0
+	backoffInterval.Reset()
+
+	for {
+		err := e.export(ctx, ds, backoffInterval.Reset)
+		if ctx.Err() != nil {
+			log.Ctx(ctx).Info().Msg("shutting down changefeed exporter")
+			return nil
+		}
+
+		restartsCounter.Inc()
+		nextAttempt := backoffInterval.NextBackOff()
+		log.Ctx(ctx).Warn().Err(err).Dur("next-attempt-in", nextAttempt).Msg("changefeed exporter failed; restarting from the last checkpoint")
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(nextAttempt):
+		}
+	}
+}
+
+// export publishes changes from the last checkpoint until an error occurs, calling onStarted
+// once the datastore is being watched.
+func (e *Exporter) export(ctx context.Context, ds datastore.Datastore, onStarted func()) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	checkpoint, err := readCheckpoint(ctx, ds, e.name)
+	if err != nil {
+		return err
+	}
+
+	if checkpoint == nil {
+		// Without a checkpoint, the changefeed starts from now.
+		checkpoint, err = ds.HeadRevision(ctx)
+		if err != nil {
+			return fmt.Errorf("error reading head revision: %w", err)
+		}
+		if err := writeCheckpoint(ctx, ds, e.name, checkpoint); err != nil {
+			return err
+		}
+	}
+
+	changes, errs := ds.Watch(ctx, checkpoint, datastore.WatchOptions{
+		Content: datastore.WatchRelationships | datastore.WatchSchema,
+	})
+	onStarted()
+	log.Ctx(ctx).Info().Str("revision", checkpoint.String()).Str("name", e.name).Msg("changefeed exporter started")
+
+	ticker := time.NewTicker(e.checkpointInterval)
+	defer ticker.Stop()
+
+	exported := checkpoint
+	for {
+		select {
+		case <-ctx.Done():
+			return e.finalCheckpoint(ctx, ds, checkpoint, exported)
+
+		case <-ticker.C:
+			if exported.Equal(checkpoint) {
+				continue
+			}
+			if err := writeCheckpoint(ctx, ds, e.name, exported); err != nil {
+				return err
+			}
+			checkpoint = exported
+
+		case change, ok := <-changes:
+			if !ok {
+				return errors.New("watch closed")
+			}
+
+			messages, err := messagesForChange(change)
+			if err != nil {
+				return err
+			}
+			if len(messages) == 0 {
+				continue
+			}
+
+			if err := e.publisher.Publish(ctx, messages); err != nil {
+				return fmt.Errorf("error publishing changes at revision %s: %w", change.Revision, err)
+			}
+			publishedEventsCounter.Add(float64(len(messages)))
+			backlogGauge.Set(float64(len(changes)))
+			if lag, ok := revisions.Age(change.Revision); ok {
+				lagGauge.Set(lag.Seconds())
+			}
+			exported = change.Revision
+
+		case err := <-errs:
+			return fmt.Errorf("error watching the datastore: %w", err)
+		}
+	}
+}
+
+// finalCheckpoint checkpoints the last exported revision when the exporter is shut down, so
+// that the changes published since the last checkpoint are not published again.
+func (e *Exporter) finalCheckpoint(ctx context.Context, ds datastore.Datastore, checkpoint, exported datastore.Revision) error {
+	if exported.Equal(checkpoint) {
+		return ctx.Err()
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 5*time.Second)
+	defer cancel()
+	return writeCheckpoint(ctx, ds, e.name, exported)
+}
+
+// messagesForChange returns the messages of the changes at a revision, in the order they are
+// published: the schema change first, as relationships may depend on it, then the update of
+// each relationship, keyed by its resource.
+func messagesForChange(change *datastore.RevisionChanges) ([]Message, error) {
+	revision := change.Revision.String()
+	var messages []Message
+
+	if len(change.ChangedDefinitions) > 0 || len(change.DeletedNamespaces) > 0 || len(change.DeletedCaveats) > 0 {
+		schemaChange := &SchemaChange{
+			DeletedDefinitions: change.DeletedNamespaces,
+			DeletedCaveats:     change.DeletedCaveats,
+		}
+
+		if len(change.ChangedDefinitions) > 0 {
+			schemaChange.ChangedDefinitions = make(map[string]string, len(change.ChangedDefinitions))
+		}
+		for _, def := range change.ChangedDefinitions {
+			source, err := definitionSource(def)
+			if err != nil {
+				return nil, err
+			}
+			schemaChange.ChangedDefinitions[def.GetName()] = source
+		}
+
+		message, err := newMessage(schemaEventKey, Event{Revision: revision, Metadata: change.Metadata, SchemaChange: schemaChange})
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+
+	for _, update := range change.RelationshipChanges {
+		if namespace := update.Tuple.ResourceAndRelation.Namespace; namespace == checkpointNamespace || namespace == leaderelection.LeaseNamespace {
+			continue
+		}
+
+		marshaled, err := protojson.Marshal(tuple.UpdateToRelationshipUpdate(update))
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling relationship update: %w", err)
+		}
+
+		resource := update.Tuple.ResourceAndRelation
+		message, err := newMessage(resource.Namespace+":"+resource.ObjectId, Event{
+			Revision:           revision,
+			Metadata:           change.Metadata,
+			RelationshipUpdate: marshaled,
+			Labels:             tuple.LabelsToMap(update.Tuple.Labels),
+		})
+		if err != nil {
+			return nil, err
+		}
+		messages = append(messages, message)
+	}
+	return messages, nil
+}
+
+func newMessage(key string, event Event) (Message, error) {
+	value, err := json.Marshal(event)
+	if err != nil {
+		return Message{}, fmt.Errorf("error marshaling changefeed event: %w", err)
+	}
+	return Message{Key: []byte(key), Value: value}, nil
+}
+
+func definitionSource(def datastore.SchemaDefinition) (string, error) {
+	var source string
+	var err error
+	switch def := def.(type) {
+	case *core.NamespaceDefinition:
+		source, _, err = generator.GenerateSource(def)
+	case *core.CaveatDefinition:
+		source, _, err = generator.GenerateCaveatSource(def)
+	default:
+		return "", fmt.Errorf("unknown schema definition type %T", def)
+	}
+	if err != nil {
+		return "", fmt.Errorf("error generating schema of `%s`: %w", def.GetName(), err)
+	}
+	return source, nil
+}
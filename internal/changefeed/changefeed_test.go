@@ -0,0 +1,165 @@
+package changefeed
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/pkg/datastore"
+	ns "github.com/authzed/spicedb/pkg/namespace"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+type fakePublisher struct {
+	sync.Mutex
+	messages []Message
+}
+
+func (p *fakePublisher) Publish(_ context.Context, messages []Message) error {
+	p.Lock()
+	defer p.Unlock()
+	p.messages = append(p.messages, messages...)
+	return nil
+}
+
+func (p *fakePublisher) Close() {}
+
+func (p *fakePublisher) events(t *testing.T) map[string][]Event {
+	p.Lock()
+	defer p.Unlock()
+
+	events := map[string][]Event{}
+	for _, message := range p.messages {
+		var event Event
+		require.NoError(t, json.Unmarshal(message.Value, &event))
+		events[string(message.Key)] = append(events[string(message.Key)], event)
+	}
+	return events
+}
+
+func TestNewExporter(t *testing.T) {
+	_, err := NewExporter(&fakePublisher{}, "", time.Second)
+	require.ErrorContains(t, err, "name must not be empty")
+
+	_, err = NewExporter(&fakePublisher{}, "search", 0)
+	require.ErrorContains(t, err, "checkpoint interval must be positive")
+}
+
+func TestExporterPublishesChanges(t *testing.T) {
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Changes made before the exporter first starts are not published.
+	_, err = common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE, tuple.MustParse("document:old#viewer@user:tom"))
+	require.NoError(t, err)
+
+	publisher := &fakePublisher{}
+	exporter, err := NewExporter(publisher, "search", 10*time.Millisecond)
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		done <- exporter.Run(ctx, ds)
+	}()
+
+	// The checkpoint is written once the exporter starts.
+	require.Eventually(t, func() bool {
+		checkpoint, err := readCheckpoint(ctx, ds, "search")
+		return err == nil && checkpoint != nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	_, err = ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteNamespaces(ctx, ns.Namespace("document", ns.MustRelation("viewer", nil)))
+	})
+	require.NoError(t, err)
+
+	labeled := tuple.MustParse("document:first#viewer@user:tom")
+	labeled.Labels = tuple.LabelsFromMap(map[string]string{"source": "crm"})
+	revision, err := common.WriteTuples(ctx, ds, core.RelationTupleUpdate_TOUCH, labeled)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		events := publisher.events(t)
+		return len(events["schema"]) == 1 && len(events["document:first"]) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	events := publisher.events(t)
+	require.Len(t, events, 2)
+	require.Contains(t, events["schema"][0].SchemaChange.ChangedDefinitions["document"], "definition document")
+
+	update := events["document:first"][0]
+	require.Equal(t, revision.String(), update.Revision)
+	require.JSONEq(t, `{
+		"operation": "OPERATION_TOUCH",
+		"relationship": {
+			"resource": {"objectType": "document", "objectId": "first"},
+			"relation": "viewer",
+			"subject": {"object": {"objectType": "user", "objectId": "tom"}}
+		}
+	}`, string(update.RelationshipUpdate))
+	require.Equal(t, map[string]string{"source": "crm"}, update.Labels)
+
+	// The revision of the last published change is checkpointed, without the checkpoint
+	// itself being published.
+	require.Eventually(t, func() bool {
+		checkpoint, err := readCheckpoint(ctx, ds, "search")
+		return err == nil && checkpoint.Equal(revision)
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Len(t, publisher.events(t), 2)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestExporterResumesFromCheckpoint(t *testing.T) {
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	checkpoint, err := ds.HeadRevision(ctx)
+	require.NoError(t, err)
+	require.NoError(t, writeCheckpoint(ctx, ds, "search", checkpoint))
+
+	// Changes made while the exporter is stopped are published once it restarts.
+	_, err = common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE, tuple.MustParse("document:missed#viewer@user:tom"))
+	require.NoError(t, err)
+	_, err = common.WriteTuples(ctx, ds, core.RelationTupleUpdate_DELETE, tuple.MustParse("document:missed#viewer@user:tom"))
+	require.NoError(t, err)
+
+	publisher := &fakePublisher{}
+	exporter, err := NewExporter(publisher, "search", time.Minute)
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		done <- exporter.Run(ctx, ds)
+	}()
+
+	require.Eventually(t, func() bool {
+		return len(publisher.events(t)["document:missed"]) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	events := publisher.events(t)["document:missed"]
+	require.Contains(t, string(events[0].RelationshipUpdate), "OPERATION_TOUCH")
+	require.Contains(t, string(events[1].RelationshipUpdate), "OPERATION_DELETE")
+
+	// The last published change is checkpointed when the exporter shuts down.
+	cancel()
+	require.NoError(t, <-done)
+
+	stored, err := readCheckpoint(context.Background(), ds, "search")
+	require.NoError(t, err)
+	require.Equal(t, events[1].Revision, stored.String())
+}
@@ -0,0 +1,94 @@
+package changefeed
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const (
+	// checkpointNamespace is the reserved resource type of the relationships holding the
+	// checkpoints of exporters, which are not part of the schema and are not published.
+	checkpointNamespace = "spicedb_changefeed/checkpoint"
+
+	checkpointRelation = "revision"
+
+	// revisionNamespace is the reserved subject type of the checkpoint relationships, whose
+	// IDs are the hex-encoded revisions, as revisions may contain characters which are not
+	// allowed in object IDs.
+	revisionNamespace = "spicedb_changefeed/revision"
+)
+
+// readCheckpoint returns the revision checkpointed by the exporter of the given name, or nil
+// if it has none.
+func readCheckpoint(ctx context.Context, ds datastore.Datastore, name string) (datastore.Revision, error) {
+	headRevision, err := ds.HeadRevision(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error reading head revision: %w", err)
+	}
+
+	it, err := ds.SnapshotReader(headRevision).QueryRelationships(ctx, datastore.RelationshipsFilter{
+		ResourceType:             checkpointNamespace,
+		OptionalResourceIds:      []string{name},
+		OptionalResourceRelation: checkpointRelation,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading changefeed checkpoint: %w", err)
+	}
+	defer it.Close()
+
+	rel := it.Next()
+	if it.Err() != nil {
+		return nil, fmt.Errorf("error reading changefeed checkpoint: %w", it.Err())
+	}
+	if rel == nil {
+		return nil, nil
+	}
+
+	serialized, err := hex.DecodeString(rel.Subject.ObjectId)
+	if err != nil {
+		return nil, fmt.Errorf("invalid changefeed checkpoint `%s`: %w", rel.Subject.ObjectId, err)
+	}
+
+	revision, err := ds.RevisionFromString(string(serialized))
+	if err != nil {
+		return nil, fmt.Errorf("invalid changefeed checkpoint `%s`: %w", serialized, err)
+	}
+	return revision, nil
+}
+
+// writeCheckpoint replaces the revision checkpointed by the exporter of the given name.
+func writeCheckpoint(ctx context.Context, ds datastore.Datastore, name string, revision datastore.Revision) error {
+	_, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		if _, err := rwt.DeleteRelationships(ctx, &v1.RelationshipFilter{
+			ResourceType:       checkpointNamespace,
+			OptionalResourceId: name,
+			OptionalRelation:   checkpointRelation,
+		}); err != nil {
+			return err
+		}
+
+		return rwt.WriteRelationships(ctx, []*core.RelationTupleUpdate{tuple.Create(&core.RelationTuple{
+			ResourceAndRelation: &core.ObjectAndRelation{
+				Namespace: checkpointNamespace,
+				ObjectId:  name,
+				Relation:  checkpointRelation,
+			},
+			Subject: &core.ObjectAndRelation{
+				Namespace: revisionNamespace,
+				ObjectId:  hex.EncodeToString([]byte(revision.String())),
+				Relation:  tuple.Ellipsis,
+			},
+		})})
+	})
+	if err != nil {
+		return fmt.Errorf("error writing changefeed checkpoint: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package changefeed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/twmb/franz-go/pkg/kgo"
+)
+
+// KafkaPublisher publishes messages to a Kafka topic. Messages are acknowledged by all
+// in-sync replicas, and the idempotent producer keeps retries from duplicating them.
+type KafkaPublisher struct {
+	client *kgo.Client
+}
+
+// NewKafkaPublisher creates a publisher to the topic of the Kafka cluster with the given
+// seed brokers.
+func NewKafkaPublisher(brokers []string, topic string) (*KafkaPublisher, error) {
+	if len(brokers) == 0 {
+		return nil, errors.New("at least one Kafka broker is required")
+	}
+	if topic == "" {
+		return nil, errors.New("a Kafka topic is required")
+	}
+
+	client, err := kgo.NewClient(
+		kgo.SeedBrokers(brokers...),
+		kgo.DefaultProduceTopic(topic),
+		kgo.RequiredAcks(kgo.AllISRAcks()),
+		kgo.ClientID("spicedb-changefeed"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Kafka client: %w", err)
+	}
+	return &KafkaPublisher{client: client}, nil
+}
+
+// Publish implements Publisher.
+func (p *KafkaPublisher) Publish(ctx context.Context, messages []Message) error {
+	records := make([]*kgo.Record, 0, len(messages))
+	for _, message := range messages {
+		records = append(records, &kgo.Record{Key: message.Key, Value: message.Value})
+	}
+	return p.client.ProduceSync(ctx, records...).FirstErr()
+}
+
+// Close implements Publisher.
+func (p *KafkaPublisher) Close() {
+	p.client.Close()
+}
+
+var _ Publisher = (*KafkaPublisher)(nil)
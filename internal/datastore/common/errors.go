@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -18,6 +19,10 @@ import (
 	"github.com/authzed/spicedb/pkg/tuple"
 )
 
+// serializationRetryDelay is the delay suggested to clients before retrying an operation which
+// failed due to a serialization error.
+const serializationRetryDelay = 50 * time.Millisecond
+
 // SerializationError is returned when there's been a serialization
 // error while performing a datastore operation
 type SerializationError struct {
@@ -32,6 +37,7 @@ func (err SerializationError) GRPCStatus() *status.Status {
 			v1.ErrorReason_ERROR_REASON_SERIALIZATION_FAILURE,
 			map[string]string{},
 		),
+		spiceerrors.ForRetry(serializationRetryDelay),
 	)
 }
 
@@ -3,6 +3,7 @@ package common
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
@@ -36,6 +37,13 @@ var (
 		Help:      "The number of stale transactions deleted by the datastore garbage collection.",
 	})
 
+	gcNamespaceRelationshipsCounter = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "spicedb",
+		Subsystem: "datastore",
+		Name:      "gc_namespace_relationships_total",
+		Help:      "The number of stale relationships deleted by the datastore garbage collection from the namespaces with a GC window of their own.",
+	}, []string{"namespace"})
+
 	gcNamespacesCounter = prometheus.NewCounter(prometheus.CounterOpts{
 		Namespace: "spicedb",
 		Subsystem: "datastore",
@@ -58,6 +66,7 @@ func RegisterGCMetrics() error {
 	for _, metric := range []prometheus.Collector{
 		gcDurationHistogram,
 		gcRelationshipsCounter,
+		gcNamespaceRelationshipsCounter,
 		gcTransactionsCounter,
 		gcNamespacesCounter,
 		gcFailureCounter,
@@ -81,6 +90,27 @@ type GarbageCollector interface {
 	Now(context.Context) (time.Time, error)
 	TxIDBefore(context.Context, time.Time) (datastore.Revision, error)
 	DeleteBeforeTx(ctx context.Context, txID datastore.Revision) (DeletionCounts, error)
+
+	// GCWindowOverrides returns the GC windows of the namespaces whose relationships are kept
+	// for longer or shorter than the GC window of the datastore. DeleteBeforeTx does not delete
+	// the relationships of these namespaces.
+	GCWindowOverrides() map[string]time.Duration
+
+	// DeleteRelationshipsBeforeTx deletes the relationships selected by the filter which were
+	// already dead at the transaction ID.
+	DeleteRelationshipsBeforeTx(ctx context.Context, txID datastore.Revision, filter NamespaceFilter) (int64, error)
+
+	// OldestPinnedRevision returns the oldest revision still being read by a long-running read,
+	// such as an export, or datastore.NoRevision if there is none. Garbage collection keeps the
+	// data visible at it, even beyond the GC window.
+	OldestPinnedRevision() datastore.Revision
+}
+
+// NamespaceFilter selects relationships by namespace: those of the namespaces or, if Exclude
+// is set, those of all other namespaces.
+type NamespaceFilter struct {
+	Namespaces []string
+	Exclude    bool
 }
 
 // DeletionCounts tracks the amount of deletions that occurred when calling
@@ -178,14 +208,34 @@ func RunGarbageCollection(gc GarbageCollector, window, timeout time.Duration) er
 		return fmt.Errorf("error retrieving now: %w", err)
 	}
 
-	watermark, err := gc.TxIDBefore(ctx, now.Add(-1*window))
+	// Transactions are kept for as long as the longest GC window, so that the watermarks of
+	// all windows can be found.
+	overrides := gc.GCWindowOverrides()
+	longestWindow := window
+	for _, namespaceWindow := range overrides {
+		longestWindow = max(longestWindow, namespaceWindow)
+	}
+
+	watermark, err := gc.TxIDBefore(ctx, now.Add(-1*longestWindow))
 	if err != nil {
 		return fmt.Errorf("error retrieving watermark: %w", err)
 	}
+	watermark = heldBack(ctx, gc, watermark)
 
-	collected, err := gc.DeleteBeforeTx(ctx, watermark)
-	if err != nil {
-		return fmt.Errorf("error deleting in gc: %w", err)
+	var collected DeletionCounts
+	if watermark != datastore.NoRevision {
+		collected, err = gc.DeleteBeforeTx(ctx, watermark)
+		if err != nil {
+			return fmt.Errorf("error deleting in gc: %w", err)
+		}
+	}
+
+	if len(overrides) > 0 {
+		overridden, err := deleteWithWindowOverrides(ctx, gc, now, window, overrides)
+		collected.Relationships += overridden
+		if err != nil {
+			return err
+		}
 	}
 
 	collectionDuration := time.Since(startTime)
@@ -203,3 +253,60 @@ func RunGarbageCollection(gc GarbageCollector, window, timeout time.Duration) er
 	gc.MarkGCCompleted()
 	return nil
 }
+
+// deleteWithWindowOverrides deletes the dead relationships of the namespaces with a GC window of
+// their own, and those of all other namespaces, each beyond its window.
+func deleteWithWindowOverrides(ctx context.Context, gc GarbageCollector, now time.Time, window time.Duration, overrides map[string]time.Duration) (int64, error) {
+	namespaces := make([]string, 0, len(overrides))
+	for namespace := range overrides {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+
+	var deleted int64
+	deleteBefore := func(window time.Duration, filter NamespaceFilter) (int64, error) {
+		watermark, err := gc.TxIDBefore(ctx, now.Add(-1*window))
+		if err != nil {
+			return 0, fmt.Errorf("error retrieving watermark: %w", err)
+		}
+		watermark = heldBack(ctx, gc, watermark)
+		if watermark == datastore.NoRevision {
+			return 0, nil
+		}
+
+		count, err := gc.DeleteRelationshipsBeforeTx(ctx, watermark, filter)
+		if err != nil {
+			return 0, fmt.Errorf("error deleting relationships in gc: %w", err)
+		}
+		deleted += count
+		return count, nil
+	}
+
+	if _, err := deleteBefore(window, NamespaceFilter{Namespaces: namespaces, Exclude: true}); err != nil {
+		return deleted, err
+	}
+
+	for _, namespace := range namespaces {
+		count, err := deleteBefore(overrides[namespace], NamespaceFilter{Namespaces: []string{namespace}})
+		if err != nil {
+			return deleted, err
+		}
+		gcNamespaceRelationshipsCounter.WithLabelValues(namespace).Add(float64(count))
+	}
+	return deleted, nil
+}
+
+// heldBack returns the oldest pinned revision instead of the watermark if it is older, so that
+// the data still being read at it is not collected.
+func heldBack(ctx context.Context, gc GarbageCollector, watermark datastore.Revision) datastore.Revision {
+	pinned := gc.OldestPinnedRevision()
+	if watermark == datastore.NoRevision || pinned == datastore.NoRevision || !pinned.LessThan(watermark) {
+		return watermark
+	}
+
+	log.Ctx(ctx).Info().
+		Stringer("watermark", watermark).
+		Stringer("pinned", pinned).
+		Msg("garbage collection held back by a pinned revision")
+	return pinned
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/authzed/spicedb/pkg/datastore"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	promclient "github.com/prometheus/client_model/go"
 	"github.com/stretchr/testify/require"
 )
@@ -19,10 +20,20 @@ import (
 // Fake garbage collector that returns a new incremented revision each time
 // TxIDBefore is called.
 type fakeGC struct {
+	RevisionPins
+
 	lastRevision uint64
 	deleter      gcDeleter
 	metrics      gcMetrics
 	lock         sync.RWMutex
+
+	overrides            map[string]time.Duration
+	deletedRelationships []deletedRelationships
+}
+
+type deletedRelationships struct {
+	revision uint64
+	filter   NamespaceFilter
 }
 
 type gcMetrics struct {
@@ -71,6 +82,19 @@ func (gc *fakeGC) DeleteBeforeTx(_ context.Context, rev datastore.Revision) (Del
 	return gc.deleter.DeleteBeforeTx(int64(revInt))
 }
 
+func (gc *fakeGC) GCWindowOverrides() map[string]time.Duration {
+	return gc.overrides
+}
+
+func (gc *fakeGC) DeleteRelationshipsBeforeTx(_ context.Context, rev datastore.Revision, filter NamespaceFilter) (int64, error) {
+	gc.lock.Lock()
+	defer gc.lock.Unlock()
+
+	revInt := rev.(revisions.TransactionIDRevision).TransactionID()
+	gc.deletedRelationships = append(gc.deletedRelationships, deletedRelationships{revInt, filter})
+	return 1, nil
+}
+
 func (gc *fakeGC) HasGCRun() bool {
 	gc.lock.Lock()
 	defer gc.lock.Unlock()
@@ -200,3 +224,54 @@ func TestGCFailureBackoffReset(t *testing.T) {
 	// the GC enough time to run.
 	require.Greater(t, gc.GetMetrics().markedCompleteCount, 20, "Next interval was not reset with backoff")
 }
+
+func TestGCWindowOverrides(t *testing.T) {
+	gc := newFakeGC(revisionErrorDeleter{})
+	gc.overrides = map[string]time.Duration{
+		"session": time.Hour,
+		"audit":   90 * 24 * time.Hour,
+	}
+
+	before := testutil.ToFloat64(gcNamespaceRelationshipsCounter.WithLabelValues("session"))
+	require.NoError(t, RunGarbageCollection(&gc, 24*time.Hour, time.Minute))
+
+	// Transactions are collected at the watermark of the longest window, then the relationships
+	// of each window at their own.
+	require.Equal(t, 1, gc.GetMetrics().deleteBeforeTxCount)
+	require.Equal(t, []deletedRelationships{
+		{2, NamespaceFilter{Namespaces: []string{"audit", "session"}, Exclude: true}},
+		{3, NamespaceFilter{Namespaces: []string{"audit"}}},
+		{4, NamespaceFilter{Namespaces: []string{"session"}}},
+	}, gc.deletedRelationships)
+	require.Equal(t, before+1, testutil.ToFloat64(gcNamespaceRelationshipsCounter.WithLabelValues("session")))
+}
+
+func TestGCHeldBackByPinnedRevision(t *testing.T) {
+	gc := newFakeGC(revisionErrorDeleter{})
+	gc.overrides = map[string]time.Duration{"session": time.Hour}
+	gc.lastRevision = 10
+
+	unpinOlder := gc.PinRevision(revisions.NewForTransactionID(3))
+	unpinNewer := gc.PinRevision(revisions.NewForTransactionID(5))
+	require.NoError(t, RunGarbageCollection(&gc, 24*time.Hour, time.Minute))
+
+	// Every watermark past the oldest pinned revision is held back to it.
+	require.Equal(t, []deletedRelationships{
+		{3, NamespaceFilter{Namespaces: []string{"session"}, Exclude: true}},
+		{3, NamespaceFilter{Namespaces: []string{"session"}}},
+	}, gc.deletedRelationships)
+
+	unpinOlder()
+	unpinOlder()
+	require.Equal(t, revisions.NewForTransactionID(5), gc.OldestPinnedRevision())
+
+	unpinNewer()
+	require.Equal(t, datastore.NoRevision, gc.OldestPinnedRevision())
+
+	gc.deletedRelationships = nil
+	require.NoError(t, RunGarbageCollection(&gc, 24*time.Hour, time.Minute))
+	require.Equal(t, []deletedRelationships{
+		{15, NamespaceFilter{Namespaces: []string{"session"}, Exclude: true}},
+		{16, NamespaceFilter{Namespaces: []string{"session"}}},
+	}, gc.deletedRelationships)
+}
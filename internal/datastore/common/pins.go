@@ -0,0 +1,50 @@
+package common
+
+import (
+	"sync"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+// RevisionPins tracks the revisions pinned by long-running reads, whose data garbage
+// collection must keep. The zero value has no pins.
+type RevisionPins struct {
+	mu   sync.Mutex
+	next uint64
+	pins map[uint64]datastore.Revision
+}
+
+// PinRevision keeps the data visible at the revision from being garbage collected until the
+// returned function is called. Unpinning more than once has no further effect.
+func (p *RevisionPins) PinRevision(revision datastore.Revision) func() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.pins == nil {
+		p.pins = map[uint64]datastore.Revision{}
+	}
+	id := p.next
+	p.next++
+	p.pins[id] = revision
+
+	return func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		delete(p.pins, id)
+	}
+}
+
+// OldestPinnedRevision returns the oldest of the pinned revisions, or datastore.NoRevision if
+// none is pinned.
+func (p *RevisionPins) OldestPinnedRevision() datastore.Revision {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	oldest := datastore.NoRevision
+	for _, revision := range p.pins {
+		if oldest == datastore.NoRevision || revision.LessThan(oldest) {
+			oldest = revision
+		}
+	}
+	return oldest
+}
@@ -0,0 +1,117 @@
+package common
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/grpc"
+
+	log "github.com/authzed/spicedb/internal/logging"
+)
+
+// planLoggedOperations are the substrings of the names of the API and dispatch methods whose
+// queries have their plans logged: those looking up or expanding relationships, whose reverse
+// queries and fan out are the most sensitive to the indexes chosen.
+var planLoggedOperations = []string{"Lookup", "Expand", "ReachableResources"}
+
+// ExplainFunc returns the plan the database chooses to execute the rendered query.
+type ExplainFunc func(ctx context.Context, sql string, args []any) (string, error)
+
+// QueryPlanLogger logs the plans chosen by the database for the relationship queries of lookup
+// and expand operations, so that the relations whose queries are slow can be diagnosed. The
+// plan of each shape of query, which is its SQL and the types and relations it selects, is
+// logged at most once per interval.
+type QueryPlanLogger struct {
+	explain  ExplainFunc
+	interval time.Duration
+	now      func() time.Time
+
+	mu     sync.Mutex
+	logged map[string]time.Time
+}
+
+// NewQueryPlanLogger creates a logger of query plans, which are explained by the given
+// function.
+func NewQueryPlanLogger(explain ExplainFunc, interval time.Duration) *QueryPlanLogger {
+	return &QueryPlanLogger{
+		explain:  explain,
+		interval: interval,
+		now:      time.Now,
+		logged:   make(map[string]time.Time),
+	}
+}
+
+// logPlan logs the plan of the query, if it is made for a lookup or expand operation and its
+// shape has not been logged within the interval. Failures to explain the query are logged,
+// and do not fail the query.
+func (l *QueryPlanLogger) logPlan(ctx context.Context, query SchemaQueryFilterer, sql string, args []any) {
+	operation, ok := grpc.Method(ctx)
+	if !ok || !planLogged(operation) {
+		return
+	}
+
+	relations := queriedRelations(query.tracerAttributes)
+	key := sql + "\x00" + strings.Join(relations, ",")
+	if !l.shouldLog(key) {
+		return
+	}
+
+	plan, err := l.explain(ctx, sql, args)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Str("operation", operation).Str("sql", sql).Msg("failed to explain query")
+		return
+	}
+
+	log.Ctx(ctx).Info().
+		Str("operation", operation).
+		Strs("relations", relations).
+		Str("sql", sql).
+		Str("plan", plan).
+		Msg("query plan")
+}
+
+func (l *QueryPlanLogger) shouldLog(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if last, ok := l.logged[key]; ok && now.Sub(last) < l.interval {
+		return false
+	}
+
+	// Shapes whose plans were logged long enough ago are forgotten, so that the shapes
+	// remembered are bounded by those queried within an interval.
+	for existing, last := range l.logged {
+		if now.Sub(last) >= l.interval {
+			delete(l.logged, existing)
+		}
+	}
+	l.logged[key] = now
+	return true
+}
+
+func planLogged(operation string) bool {
+	for _, logged := range planLoggedOperations {
+		if strings.Contains(operation, logged) {
+			return true
+		}
+	}
+	return false
+}
+
+// queriedRelations returns the types, relations and caveats selected by a query, such as
+// `subNamespaceName=user`, without the object IDs, which do not change its shape.
+func queriedRelations(attributes []attribute.KeyValue) []string {
+	relations := make([]string, 0, len(attributes))
+	for _, attr := range attributes {
+		switch attr.Key {
+		case ObjNamespaceNameKey, ObjRelationNameKey, SubNamespaceNameKey, SubRelationNameKey, CaveatNameKey:
+			name := string(attr.Key)
+			relations = append(relations, name[strings.LastIndex(name, "/")+1:]+"="+attr.Value.AsString())
+		}
+	}
+	return relations
+}
@@ -0,0 +1,110 @@
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/attribute"
+	"google.golang.org/grpc"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+// methodStream names the method of the request whose queries are made.
+type methodStream struct {
+	grpc.ServerTransportStream
+	method string
+}
+
+func (s methodStream) Method() string {
+	return s.method
+}
+
+func TestQueryPlanLogger(t *testing.T) {
+	var explained []string
+	explain := func(_ context.Context, sql string, _ []any) (string, error) {
+		explained = append(explained, sql)
+		return "Index Scan using ix_relation_tuple_by_subject_tuned", nil
+	}
+
+	now := time.Now()
+	logger := NewQueryPlanLogger(explain, time.Minute)
+	logger.now = func() time.Time { return now }
+
+	schema := NewSchemaInformation("ns", "object_id", "relation", "subject_ns", "subject_object_id", "subject_relation", "caveat", TupleComparison)
+	filterer := NewSchemaQueryFilterer(schema, sq.Select("*"))
+	reverse := func(subjectType, subjectID string) SchemaQueryFilterer {
+		return filterer.FilterToResourceType("document").FilterToRelation("viewer").MustFilterWithSubjectsSelectors(datastore.SubjectsSelector{
+			OptionalSubjectType: subjectType,
+			OptionalSubjectIds:  []string{subjectID},
+		})
+	}
+
+	logPlan := func(ctx context.Context, query SchemaQueryFilterer) {
+		sql, args, err := query.queryBuilder.ToSql()
+		require.NoError(t, err)
+		logger.logPlan(ctx, query, sql, args)
+	}
+
+	lookup := grpc.NewContextWithServerTransportStream(context.Background(), methodStream{method: "/authzed.api.v1.PermissionsService/LookupResources"})
+	check := grpc.NewContextWithServerTransportStream(context.Background(), methodStream{method: "/authzed.api.v1.PermissionsService/CheckPermission"})
+
+	// Queries made outside of lookups and expands are not explained.
+	logPlan(context.Background(), reverse("user", "tom"))
+	logPlan(check, reverse("user", "tom"))
+	require.Empty(t, explained)
+
+	// Queries of the same shape are explained once per interval, whatever their object IDs.
+	logPlan(lookup, reverse("user", "tom"))
+	logPlan(lookup, reverse("user", "fred"))
+	require.Len(t, explained, 1)
+
+	// Queries of other relations are explained separately, even with the same SQL.
+	logPlan(lookup, reverse("team", "tom"))
+	require.Len(t, explained, 2)
+	require.Equal(t, explained[0], explained[1])
+
+	now = now.Add(time.Minute)
+	logPlan(lookup, reverse("user", "tom"))
+	require.Len(t, explained, 3)
+	require.Len(t, logger.logged, 1, "shapes logged before the interval are forgotten")
+}
+
+func TestQueryPlanLoggerExplainFailure(t *testing.T) {
+	calls := 0
+	logger := NewQueryPlanLogger(func(context.Context, string, []any) (string, error) {
+		calls++
+		return "", errors.New("permission denied")
+	}, time.Minute)
+
+	schema := NewSchemaInformation("ns", "object_id", "relation", "subject_ns", "subject_object_id", "subject_relation", "caveat", TupleComparison)
+	query := NewSchemaQueryFilterer(schema, sq.Select("*")).FilterToResourceType("document")
+	expand := grpc.NewContextWithServerTransportStream(context.Background(), methodStream{method: "/dispatch.v1.DispatchService/DispatchExpand"})
+
+	logger.logPlan(expand, query, "SELECT * WHERE ns = ?", []any{"document"})
+	logger.logPlan(expand, query, "SELECT * WHERE ns = ?", []any{"document"})
+	require.Equal(t, 1, calls)
+}
+
+func TestQueriedRelations(t *testing.T) {
+	attributes := []attribute.KeyValue{
+		ObjNamespaceNameKey.String("document"),
+		ObjIDKey.String("readme"),
+		ObjRelationNameKey.String("viewer"),
+		SubNamespaceNameKey.String("group"),
+		SubObjectIDKey.String("eng"),
+		SubRelationNameKey.String("member"),
+		CaveatNameKey.String("on_weekdays"),
+	}
+	require.Equal(t, []string{
+		"objNamespaceName=document",
+		"objRelationName=viewer",
+		"subNamespaceName=group",
+		"subRelationName=member",
+		"caveatName=on_weekdays",
+	}, queriedRelations(attributes))
+}
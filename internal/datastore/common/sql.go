@@ -2,6 +2,7 @@ package common
 
 import (
 	"context"
+	"errors"
 	"math"
 	"strings"
 
@@ -305,6 +306,28 @@ func (sqf SchemaQueryFilterer) FilterToResourceIDs(resourceIds []string) (Schema
 	return sqf, nil
 }
 
+// FilterWithResourceIDPrefix returns new SchemaQueryFilterer that is limited to resources whose ID
+// starts with the specified prefix.
+func (sqf SchemaQueryFilterer) FilterWithResourceIDPrefix(prefix string) (SchemaQueryFilterer, error) {
+	if strings.Contains(prefix, "%") {
+		return sqf, spiceerrors.MustBugf("prefix cannot contain the percent sign")
+	}
+	if prefix == "" {
+		return sqf, spiceerrors.MustBugf("prefix cannot be empty")
+	}
+
+	// NOTE: `_` is a valid character in object IDs but is a single-character wildcard in LIKE
+	// expressions, so it must be escaped.
+	escapedPrefix := strings.ReplaceAll(prefix, `\`, `\\`)
+	escapedPrefix = strings.ReplaceAll(escapedPrefix, "_", `\_`)
+
+	sqf.queryBuilder = sqf.queryBuilder.Where(sq.Like{sqf.schema.colObjectID: escapedPrefix + "%"})
+
+	// NOTE: we do *not* record the use of the resource ID column here, because it is not used
+	// statically and thus is necessary for sorting operations.
+	return sqf, nil
+}
+
 // FilterToRelation returns a new SchemaQueryFilterer that is limited to resources with the
 // specified relation.
 func (sqf SchemaQueryFilterer) FilterToRelation(relation string) SchemaQueryFilterer {
@@ -325,12 +348,21 @@ func (sqf SchemaQueryFilterer) MustFilterWithRelationshipsFilter(filter datastor
 }
 
 func (sqf SchemaQueryFilterer) FilterWithRelationshipsFilter(filter datastore.RelationshipsFilter) (SchemaQueryFilterer, error) {
+	// The SQL datastores do not store the labels of relationships, so they cannot be filtered by.
+	if len(filter.OptionalLabels) > 0 {
+		return sqf, errors.New("filtering relationships by label is not supported by this datastore")
+	}
+
 	sqf = sqf.FilterToResourceType(filter.ResourceType)
 
 	if filter.OptionalResourceRelation != "" {
 		sqf = sqf.FilterToRelation(filter.OptionalResourceRelation)
 	}
 
+	if len(filter.OptionalResourceIds) > 0 && filter.OptionalResourceIDPrefix != "" {
+		return sqf, errors.New("cannot filter by both resource IDs and ID prefix")
+	}
+
 	if len(filter.OptionalResourceIds) > 0 {
 		usqf, err := sqf.FilterToResourceIDs(filter.OptionalResourceIds)
 		if err != nil {
@@ -339,6 +371,14 @@ func (sqf SchemaQueryFilterer) FilterWithRelationshipsFilter(filter datastore.Re
 		sqf = usqf
 	}
 
+	if len(filter.OptionalResourceIDPrefix) > 0 {
+		usqf, err := sqf.FilterWithResourceIDPrefix(filter.OptionalResourceIDPrefix)
+		if err != nil {
+			return sqf, err
+		}
+		sqf = usqf
+	}
+
 	if len(filter.OptionalSubjectsSelectors) > 0 {
 		usqf, err := sqf.FilterWithSubjectsSelectors(filter.OptionalSubjectsSelectors...)
 		if err != nil {
@@ -486,6 +526,9 @@ func (sqf SchemaQueryFilterer) limit(limit uint64) SchemaQueryFilterer {
 // QueryExecutor is a tuple query runner shared by SQL implementations of the datastore.
 type QueryExecutor struct {
 	Executor ExecuteQueryFunc
+
+	// PlanLogger, if set, logs the plans of the queries of lookup and expand operations.
+	PlanLogger *QueryPlanLogger
 }
 
 // ExecuteQuery executes the query.
@@ -517,6 +560,10 @@ func (tqs QueryExecutor) ExecuteQuery(
 		return nil, err
 	}
 
+	if tqs.PlanLogger != nil {
+		tqs.PlanLogger.logPlan(ctx, toExecute, sql, args)
+	}
+
 	queryTuples, err := tqs.Executor(ctx, sql, args)
 	if err != nil {
 		return nil, err
@@ -55,6 +55,19 @@ func TestSchemaQueryFilterer(t *testing.T) {
 				"object_id": 2,
 			},
 		},
+		{
+			"resource ID prefix filter",
+			func(filterer SchemaQueryFilterer) SchemaQueryFilterer {
+				updated, err := filterer.FilterWithResourceIDPrefix("some_prefix")
+				if err != nil {
+					panic(err)
+				}
+				return updated
+			},
+			"SELECT * WHERE object_id LIKE ?",
+			[]any{"some\\_prefix%"},
+			map[string]int{},
+		},
 		{
 			"resource type filter",
 			func(filterer SchemaQueryFilterer) SchemaQueryFilterer {
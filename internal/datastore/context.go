@@ -16,6 +16,9 @@ import (
 // context between grpc and the datastore to prevent context cancellation from
 // killing database connections that should otherwise go back to the connection
 // pool.
+//
+// The deadline of the context is retained, so that queries do not keep running
+// once the caller has given up on them.
 func SeparateContextWithTracing(ctx context.Context) context.Context {
 	span := trace.SpanFromContext(ctx)
 	ctxWithObservability := trace.ContextWithSpan(context.Background(), span)
@@ -25,14 +28,23 @@ func SeparateContextWithTracing(ctx context.Context) context.Context {
 		ctxWithObservability = loggerFromContext.WithContext(ctxWithObservability)
 	}
 
-	return ctxWithObservability
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return ctxWithObservability
+	}
+
+	// The separated context outlives the call which created it, such as when it is
+	// held by a relationship iterator, so it is only released once its deadline passes.
+	ctxWithDeadline, cancel := context.WithDeadline(ctxWithObservability, deadline)
+	context.AfterFunc(ctxWithDeadline, cancel)
+	return ctxWithDeadline
 }
 
-// NewSeparatingContextDatastoreProxy severs any timeouts in the context being
-// passed to the datastore and only retains tracing metadata.
+// NewSeparatingContextDatastoreProxy severs cancellation of the context being
+// passed to the datastore and only retains tracing metadata and the deadline.
 //
 // This is useful for datastores that do not want to close connections when a
-// cancel or deadline occurs.
+// caller cancels, while still not running queries past the deadline of the call.
 func NewSeparatingContextDatastoreProxy(d datastore.Datastore) datastore.Datastore {
 	return &ctxProxy{d}
 }
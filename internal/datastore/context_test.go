@@ -0,0 +1,38 @@
+package datastore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSeparateContextWithTracing(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	separated := SeparateContextWithTracing(ctx)
+	cancel()
+
+	// Cancellation of the parent context is not propagated.
+	require.NoError(t, separated.Err())
+	_, ok := separated.Deadline()
+	require.False(t, ok)
+}
+
+func TestSeparateContextWithTracingRetainsDeadline(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	separated := SeparateContextWithTracing(ctx)
+	expectedDeadline, _ := ctx.Deadline()
+	deadline, ok := separated.Deadline()
+	require.True(t, ok)
+	require.Equal(t, expectedDeadline, deadline)
+
+	select {
+	case <-separated.Done():
+		require.ErrorIs(t, separated.Err(), context.DeadlineExceeded)
+	case <-time.After(time.Second):
+		require.Fail(t, "the separated context was not canceled at the deadline")
+	}
+}
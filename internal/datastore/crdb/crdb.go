@@ -65,6 +65,10 @@ const (
 	colCaveatContextName = "caveat_name"
 	colCaveatContext     = "caveat_context"
 
+	colIntegrityKeyID     = "integrity_key_id"
+	colIntegrityHash      = "integrity_hash"
+	colIntegrityTimestamp = "integrity_timestamp"
+
 	errUnableToInstantiate = "unable to instantiate datastore"
 	errRevision            = "unable to find revision: %w"
 
@@ -277,7 +281,7 @@ type crdbDatastore struct {
 
 func (cds *crdbDatastore) SnapshotReader(rev datastore.Revision) datastore.Reader {
 	executor := common.QueryExecutor{
-		Executor: pgxcommon.NewPGXExecutor(cds.readPool),
+		Executor: pgxcommon.NewPGXExecutorWithIntegrity(cds.readPool),
 	}
 
 	fromBuilder := func(query sq.SelectBuilder, fromStr string) sq.SelectBuilder {
@@ -295,6 +299,9 @@ func (cds *crdbDatastore) ReadWriteTx(
 	var commitTimestamp datastore.Revision
 
 	config := options.NewRWTOptionsWithOptions(opts...)
+	if len(config.Metadata) > 0 {
+		return datastore.NoRevision, datastore.NewTransactionMetadataUnsupportedErr(Engine)
+	}
 	if config.DisableRetries {
 		ctx = context.WithValue(ctx, pool.CtxDisableRetries, true)
 	}
@@ -302,7 +309,7 @@ func (cds *crdbDatastore) ReadWriteTx(
 	err := cds.writePool.BeginFunc(ctx, func(tx pgx.Tx) error {
 		querier := pgxcommon.QuerierFuncsFor(tx)
 		executor := common.QueryExecutor{
-			Executor: pgxcommon.NewPGXExecutor(querier),
+			Executor: pgxcommon.NewPGXExecutorWithIntegrity(querier),
 		}
 
 		rwt := &crdbReadWriteTXN{
@@ -371,9 +378,7 @@ func (cds *crdbDatastore) ReadyState(ctx context.Context) (datastore.ReadyState,
 		return datastore.ReadyState{}, err
 	}
 
-	// TODO(jschorr): Remove the check for the older migration once we are confident
-	// that all users have migrated past it.
-	if version != headMigration && version != "add-caveats" {
+	if version != headMigration {
 		return datastore.ReadyState{
 			Message: fmt.Sprintf(
 				"datastore is not migrated: currently at revision `%s`, but requires `%s`. Please run `spicedb migrate`.",
@@ -440,7 +445,9 @@ func (cds *crdbDatastore) Features(ctx context.Context) (*datastore.Features, er
 }
 
 func (cds *crdbDatastore) features(ctx context.Context) (*datastore.Features, error) {
-	var features datastore.Features
+	features := datastore.Features{
+		IntegrityData: datastore.Feature{Enabled: true},
+	}
 
 	head, err := cds.HeadRevision(ctx)
 	if err != nil {
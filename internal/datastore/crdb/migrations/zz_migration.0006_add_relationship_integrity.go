@@ -0,0 +1,28 @@
+package migrations
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const (
+	addRelationshipIntegrity = `ALTER TABLE relation_tuple
+		ADD COLUMN integrity_key_id VARCHAR,
+		ADD COLUMN integrity_hash BYTEA,
+		ADD COLUMN integrity_timestamp TIMESTAMP WITHOUT TIME ZONE;`
+)
+
+func init() {
+	err := CRDBMigrations.Register("add-relationship-integrity", "remove-stats-table", addRelationshipIntegrityFunc, noAtomicMigration)
+	if err != nil {
+		panic("failed to register migration: " + err.Error())
+	}
+}
+
+func addRelationshipIntegrityFunc(ctx context.Context, conn *pgx.Conn) error {
+	if _, err := conn.Exec(ctx, addRelationshipIntegrity); err != nil {
+		return err
+	}
+	return nil
+}
@@ -34,6 +34,9 @@ var (
 		colUsersetRelation,
 		colCaveatContextName,
 		colCaveatContext,
+		colIntegrityKeyID,
+		colIntegrityHash,
+		colIntegrityTimestamp,
 	)
 
 	schema = common.NewSchemaInformation(
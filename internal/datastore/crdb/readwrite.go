@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
@@ -48,7 +49,7 @@ type crdbReadWriteTXN struct {
 
 var (
 	upsertTupleSuffix = fmt.Sprintf(
-		"ON CONFLICT (%s,%s,%s,%s,%s,%s) DO UPDATE SET %s = now(), %s = excluded.%s, %s = excluded.%s WHERE (relation_tuple.%s <> excluded.%s OR relation_tuple.%s <> excluded.%s)",
+		"ON CONFLICT (%s,%s,%s,%s,%s,%s) DO UPDATE SET %s = now(), %s = excluded.%s, %s = excluded.%s, %s = excluded.%s, %s = excluded.%s, %s = excluded.%s WHERE (relation_tuple.%s <> excluded.%s OR relation_tuple.%s <> excluded.%s OR relation_tuple.%s IS DISTINCT FROM excluded.%s)",
 		colNamespace,
 		colObjectID,
 		colRelation,
@@ -60,10 +61,18 @@ var (
 		colCaveatContextName,
 		colCaveatContext,
 		colCaveatContext,
+		colIntegrityKeyID,
+		colIntegrityKeyID,
+		colIntegrityHash,
+		colIntegrityHash,
+		colIntegrityTimestamp,
+		colIntegrityTimestamp,
 		colCaveatContextName,
 		colCaveatContextName,
 		colCaveatContext,
 		colCaveatContext,
+		colIntegrityHash,
+		colIntegrityHash,
 	)
 
 	queryWriteTuple = psql.Insert(tableTuple).Columns(
@@ -75,6 +84,9 @@ var (
 		colUsersetRelation,
 		colCaveatContextName,
 		colCaveatContext,
+		colIntegrityKeyID,
+		colIntegrityHash,
+		colIntegrityTimestamp,
 	)
 
 	queryTouchTuple = queryWriteTuple.Suffix(upsertTupleSuffix)
@@ -112,6 +124,16 @@ func (rwt *crdbReadWriteTXN) WriteRelationships(ctx context.Context, mutations [
 			caveatContext = rel.Caveat.Context.AsMap()
 		}
 
+		var integrityKeyID *string
+		var integrityHash []byte
+		var integrityTimestamp *time.Time
+		if rel.Integrity != nil {
+			hashedAt := rel.Integrity.HashedAt.AsTime()
+			integrityKeyID = &rel.Integrity.KeyId
+			integrityHash = rel.Integrity.Hash
+			integrityTimestamp = &hashedAt
+		}
+
 		rwt.addOverlapKey(rel.ResourceAndRelation.Namespace)
 		rwt.addOverlapKey(rel.Subject.Namespace)
 
@@ -127,6 +149,9 @@ func (rwt *crdbReadWriteTXN) WriteRelationships(ctx context.Context, mutations [
 				rel.Subject.Relation,
 				caveatName,
 				caveatContext,
+				integrityKeyID,
+				integrityHash,
+				integrityTimestamp,
 			)
 			bulkTouchCount++
 		case core.RelationTupleUpdate_CREATE:
@@ -140,6 +165,9 @@ func (rwt *crdbReadWriteTXN) WriteRelationships(ctx context.Context, mutations [
 				rel.Subject.Relation,
 				caveatName,
 				caveatContext,
+				integrityKeyID,
+				integrityHash,
+				integrityTimestamp,
 			)
 			bulkWriteCount++
 		case core.RelationTupleUpdate_DELETE:
@@ -331,10 +359,13 @@ var copyCols = []string{
 	colUsersetRelation,
 	colCaveatContextName,
 	colCaveatContext,
+	colIntegrityKeyID,
+	colIntegrityHash,
+	colIntegrityTimestamp,
 }
 
 func (rwt *crdbReadWriteTXN) BulkLoad(ctx context.Context, iter datastore.BulkWriteRelationshipSource) (uint64, error) {
-	return pgxcommon.BulkLoad(ctx, rwt.tx, tableTuple, copyCols, iter)
+	return pgxcommon.BulkLoadWithIntegrity(ctx, rwt.tx, tableTuple, copyCols, iter)
 }
 
 var _ datastore.ReadWriteTransaction = &crdbReadWriteTXN{}
@@ -1,6 +1,8 @@
 package memdb
 
 import (
+	"time"
+
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
@@ -9,6 +11,10 @@ import (
 	"github.com/authzed/spicedb/pkg/spiceerrors"
 )
 
+// maxRetriesRetryDelay is the delay suggested to clients before retrying a write which exhausted
+// its retries, giving the concurrent writes time to complete.
+const maxRetriesRetryDelay = 100 * time.Millisecond
+
 // ErrSerializationMaxRetriesReached occurs when a write request has reached its maximum number
 // of retries due to serialization errors.
 type ErrSerializationMaxRetriesReached struct {
@@ -33,5 +39,6 @@ func (err ErrSerializationMaxRetriesReached) GRPCStatus() *status.Status {
 				"details": "too many updates were made to the in-memory datastore at once; this datastore has limited write throughput capability",
 			},
 		),
+		spiceerrors.ForRetry(maxRetriesRetryDelay),
 	)
 }
@@ -33,6 +33,18 @@ var errSerialization = errors.New("serialization error")
 // interval high enough that it will never run.
 const DisableGC = time.Duration(math.MaxInt64)
 
+// Option configures the in-memory datastore.
+type Option func(*memdbDatastore)
+
+// MaxClockSkew sets how far the clock of the server may be behind the latest revision, such as
+// after being moved back, before writes are rejected. Writes made while it is behind by less are
+// given revisions just after the latest one. A value of 0 never rejects writes.
+func MaxClockSkew(skew time.Duration) Option {
+	return func(mdb *memdbDatastore) {
+		mdb.maxClockSkew = skew
+	}
+}
+
 // NewMemdbDatastore creates a new Datastore compliant datastore backed by memdb.
 //
 // If the watchBufferLength value of 0 is set then a default value of 128 will be used.
@@ -40,6 +52,7 @@ func NewMemdbDatastore(
 	watchBufferLength uint16,
 	revisionQuantization,
 	gcWindow time.Duration,
+	options ...Option,
 ) (datastore.Datastore, error) {
 	if revisionQuantization > gcWindow {
 		return nil, errors.New("gc window must be larger than quantization interval")
@@ -59,7 +72,7 @@ func NewMemdbDatastore(
 	}
 
 	uniqueID := uuid.NewString()
-	return &memdbDatastore{
+	mdb := &memdbDatastore{
 		CommonDecoder: revisions.CommonDecoder{
 			Kind: revisions.Timestamp,
 		},
@@ -76,7 +89,11 @@ func NewMemdbDatastore(
 		watchBufferLength:       watchBufferLength,
 		watchBufferWriteTimeout: 100 * time.Millisecond,
 		uniqueID:                uniqueID,
-	}, nil
+	}
+	for _, option := range options {
+		option(mdb)
+	}
+	return mdb, nil
 }
 
 type memdbDatastore struct {
@@ -91,6 +108,7 @@ type memdbDatastore struct {
 	quantizationPeriod      int64
 	watchBufferLength       uint16
 	watchBufferWriteTimeout time.Duration
+	maxClockSkew            time.Duration
 	uniqueID                string
 }
 
@@ -171,7 +189,11 @@ func (mdb *memdbDatastore) ReadWriteTx(
 			return tx, err
 		}
 
-		newRevision := mdb.newRevisionID()
+		newRevision, err := mdb.newRevisionID()
+		if err != nil {
+			return datastore.NoRevision, err
+		}
+
 		rwt := &memdbReadWriteTx{memdbReader{&sync.Mutex{}, txSrc, nil}, newRevision}
 		if err := f(ctx, rwt); err != nil {
 			mdb.Lock()
@@ -260,6 +282,7 @@ func (mdb *memdbDatastore) ReadWriteTx(
 			} else if len(changes) == 1 {
 				rc = changes[0]
 			}
+			rc.Metadata = config.Metadata
 
 			change := &changelog{
 				revisionNanos: newRevision.TimestampNanoSec(),
@@ -297,7 +320,11 @@ func (mdb *memdbDatastore) ReadyState(_ context.Context) (datastore.ReadyState,
 }
 
 func (mdb *memdbDatastore) Features(_ context.Context) (*datastore.Features, error) {
-	return &datastore.Features{Watch: datastore.Feature{Enabled: true}}, nil
+	return &datastore.Features{
+		Watch:              datastore.Feature{Enabled: true},
+		IntegrityData:      datastore.Feature{Enabled: true},
+		RelationshipLabels: datastore.Feature{Enabled: true},
+	}, nil
 }
 
 func (mdb *memdbDatastore) Close() error {
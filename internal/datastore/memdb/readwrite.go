@@ -3,6 +3,7 @@ package memdb
 import (
 	"context"
 	"fmt"
+	"slices"
 
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/hashicorp/go-memdb"
@@ -45,6 +46,8 @@ func (rwt *memdbReadWriteTx) write(tx *memdb.Txn, mutations ...*core.RelationTup
 			mutation.Tuple.Subject.ObjectId,
 			mutation.Tuple.Subject.Relation,
 			rwt.toCaveatReference(mutation),
+			rwt.toIntegrity(mutation),
+			mutation.Tuple.Labels,
 		}
 
 		found, err := tx.First(
@@ -85,7 +88,7 @@ func (rwt *memdbReadWriteTx) write(tx *memdb.Txn, mutations ...*core.RelationTup
 				if err != nil {
 					return err
 				}
-				if tuple.MustString(rt) == tuple.MustString(mutation.Tuple) {
+				if tuple.MustString(rt) == tuple.MustString(mutation.Tuple) && slices.Equal(rt.Labels, mutation.Tuple.Labels) {
 					continue
 				}
 			}
@@ -118,6 +121,18 @@ func (rwt *memdbReadWriteTx) toCaveatReference(mutation *core.RelationTupleUpdat
 	return cr
 }
 
+func (rwt *memdbReadWriteTx) toIntegrity(mutation *core.RelationTupleUpdate) *relationshipIntegrity {
+	var ri *relationshipIntegrity
+	if mutation.Tuple.Integrity != nil {
+		ri = &relationshipIntegrity{
+			keyID:     mutation.Tuple.Integrity.KeyId,
+			hash:      mutation.Tuple.Integrity.Hash,
+			timestamp: mutation.Tuple.Integrity.HashedAt.AsTime(),
+		}
+	}
+	return ri
+}
+
 func (rwt *memdbReadWriteTx) DeleteRelationships(_ context.Context, filter *v1.RelationshipFilter, opts ...options.DeleteOptionsOption) (bool, error) {
 	rwt.mustLock()
 	defer rwt.Unlock()
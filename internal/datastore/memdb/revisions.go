@@ -3,24 +3,38 @@ package memdb
 import (
 	"context"
 	"fmt"
+	"sort"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
 	"github.com/authzed/spicedb/internal/datastore/revisions"
 	"github.com/authzed/spicedb/pkg/datastore"
 )
 
+var clockRegressionsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "datastore",
+	Name:      "memdb_clock_regressions_total",
+	Help:      "Count of the writes to the in-memory datastore made while the clock was behind its latest revision, by whether the revision was adjusted past it or the write rejected",
+}, []string{"result"})
+
 var ParseRevisionString = revisions.RevisionParser(revisions.Timestamp)
 
 func nowRevision() revisions.TimestampRevision {
 	return revisions.NewForTime(time.Now().UTC())
 }
 
-func (mdb *memdbDatastore) newRevisionID() revisions.TimestampRevision {
+func (mdb *memdbDatastore) newRevisionID() (revisions.TimestampRevision, error) {
 	mdb.Lock()
 	defer mdb.Unlock()
 
 	existing := mdb.revisions[len(mdb.revisions)-1].revision
 	created := nowRevision()
+	if created.GreaterThan(existing) {
+		return created, nil
+	}
 
 	// NOTE: The time.Now().UTC() only appears to have *microsecond* level
 	// precision on macOS Monterey in Go 1.19.1. This means that HeadRevision
@@ -32,11 +46,20 @@ func (mdb *memdbDatastore) newRevisionID() revisions.TimestampRevision {
 	// See: https://github.com/golang/go/issues/22037 which appeared to fix
 	// this in Go 1.9.2, but there appears to have been a reversion with either
 	// the new version of macOS or Go.
-	if created.Equal(existing) {
-		return revisions.NewForTimestamp(created.TimestampNanoSec() + 1)
+	//
+	// The clock may also have been moved back, such as by NTP, in which case the revision is
+	// placed just after the head so that revisions remain ordered, unless the clock moved back
+	// further than the maximum skew.
+	regression := time.Duration(existing.TimestampNanoSec() - created.TimestampNanoSec())
+	if regression > 0 {
+		if mdb.maxClockSkew > 0 && regression > mdb.maxClockSkew {
+			clockRegressionsCounter.WithLabelValues("rejected").Inc()
+			return revisions.TimestampRevision(0), datastore.NewClockSkewErr("the clock of the server is behind the latest revision of the in-memory datastore", regression, mdb.maxClockSkew)
+		}
+		clockRegressionsCounter.WithLabelValues("adjusted").Inc()
 	}
 
-	return created
+	return revisions.NewForTimestamp(existing.TimestampNanoSec() + 1), nil
 }
 
 func (mdb *memdbDatastore) HeadRevision(_ context.Context) (datastore.Revision, error) {
@@ -73,6 +96,16 @@ func (mdb *memdbDatastore) OptimizedRevision(_ context.Context) (datastore.Revis
 	return revisions.NewForTimestamp(now.TimestampNanoSec() - now.TimestampNanoSec()%mdb.quantizationPeriod), nil
 }
 
+// OptimizedRevisionValidThrough returns the end of the quantization period starting at the
+// revision, if it is the start of one.
+func (mdb *memdbDatastore) OptimizedRevisionValidThrough(revision datastore.Revision) (time.Time, bool) {
+	rev, ok := revision.(revisions.TimestampRevision)
+	if !ok || mdb.quantizationPeriod <= 0 || rev.TimestampNanoSec()%mdb.quantizationPeriod != 0 {
+		return time.Time{}, false
+	}
+	return time.Unix(0, rev.TimestampNanoSec()+mdb.quantizationPeriod), true
+}
+
 func (mdb *memdbDatastore) CheckRevision(_ context.Context, dr datastore.Revision) error {
 	mdb.RLock()
 	defer mdb.RUnlock()
@@ -83,6 +116,46 @@ func (mdb *memdbDatastore) CheckRevision(_ context.Context, dr datastore.Revisio
 	return mdb.checkRevisionLocalCallerMustLock(dr)
 }
 
+func (mdb *memdbDatastore) RevisionAtTime(_ context.Context, at time.Time) (datastore.Revision, error) {
+	mdb.RLock()
+	defer mdb.RUnlock()
+	if mdb.db == nil {
+		return nil, fmt.Errorf("datastore has been closed")
+	}
+
+	// Snapshots hold the state following their revision, so the revision at the time is that of
+	// the latest snapshot taken at or before it.
+	atRevision := revisions.NewForTime(at.UTC())
+	index := sort.Search(len(mdb.revisions), func(i int) bool {
+		return mdb.revisions[i].revision.GreaterThan(atRevision)
+	})
+	if index == 0 {
+		return nil, datastore.NewInvalidRevisionErr(atRevision, datastore.RevisionStale)
+	}
+
+	revision := mdb.revisions[index-1].revision
+	if err := mdb.checkRevisionLocalCallerMustLock(revision); err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+func (mdb *memdbDatastore) EarliestRevision(_ context.Context) (datastore.Revision, error) {
+	mdb.RLock()
+	defer mdb.RUnlock()
+	if mdb.db == nil {
+		return nil, fmt.Errorf("datastore has been closed")
+	}
+
+	now := nowRevision()
+	for _, snapshot := range mdb.revisions {
+		if !mdb.revisionOutsideGCWindow(now, snapshot.revision) {
+			return snapshot.revision, nil
+		}
+	}
+	return mdb.headRevisionNoLock(), nil
+}
+
 func (mdb *memdbDatastore) checkRevisionLocalCallerMustLock(dr datastore.Revision) error {
 	now := nowRevision()
 
@@ -6,6 +6,10 @@ import (
 	"time"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/revisions"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 )
 
 func TestHeadRevision(t *testing.T) {
@@ -26,6 +30,103 @@ func TestHeadRevision(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestRevisionAtTime(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	ds, err := NewMemdbDatastore(0, 0, time.Hour)
+	require.NoError(err)
+
+	writeNamespace := func(name string) datastore.Revision {
+		rev, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+			return rwt.WriteNamespaces(ctx, &core.NamespaceDefinition{Name: name})
+		})
+		require.NoError(err)
+		return rev
+	}
+
+	first := writeNamespace("first")
+	time.Sleep(time.Millisecond)
+	between := time.Now()
+	time.Sleep(time.Millisecond)
+	writeNamespace("second")
+
+	rev, err := ds.(datastore.PointInTimeDatastore).RevisionAtTime(ctx, between)
+	require.NoError(err)
+	require.True(first.Equal(rev))
+
+	reader := ds.SnapshotReader(rev)
+	_, _, err = reader.ReadNamespaceByName(ctx, "first")
+	require.NoError(err)
+	_, _, err = reader.ReadNamespaceByName(ctx, "second")
+	require.ErrorAs(err, &datastore.ErrNamespaceNotFound{})
+
+	_, err = ds.(datastore.PointInTimeDatastore).RevisionAtTime(ctx, between.Add(-time.Hour))
+	require.ErrorAs(err, &datastore.ErrInvalidRevision{})
+}
+
+func TestEarliestRevision(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	ds, err := NewMemdbDatastore(0, 0, 500*time.Millisecond)
+	require.NoError(err)
+
+	initial, err := ds.(datastore.PointInTimeDatastore).EarliestRevision(ctx)
+	require.NoError(err)
+	require.NoError(ds.CheckRevision(ctx, initial))
+
+	time.Sleep(550 * time.Millisecond)
+
+	written, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteNamespaces(ctx, &core.NamespaceDefinition{Name: "document"})
+	})
+	require.NoError(err)
+
+	// The initial revision has fallen outside of the GC window.
+	earliest, err := ds.(datastore.PointInTimeDatastore).EarliestRevision(ctx)
+	require.NoError(err)
+	require.True(written.Equal(earliest))
+	require.Error(ds.CheckRevision(ctx, initial))
+}
+
+func TestClockRegression(t *testing.T) {
+	require := require.New(t)
+	ctx := context.Background()
+
+	ds, err := NewMemdbDatastore(0, 0, time.Hour, MaxClockSkew(time.Minute))
+	require.NoError(err)
+	mdb := ds.(*memdbDatastore)
+
+	writeNamespace := func(name string) (datastore.Revision, error) {
+		return ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+			return rwt.WriteNamespaces(ctx, &core.NamespaceDefinition{Name: name})
+		})
+	}
+
+	// Simulate the clock having moved back by placing the head revision ahead of it.
+	setHeadAhead := func(ahead time.Duration) revisions.TimestampRevision {
+		mdb.Lock()
+		defer mdb.Unlock()
+		head := revisions.NewForTime(time.Now().Add(ahead))
+		mdb.revisions = append(mdb.revisions, snapshot{revision: head, db: mdb.db})
+		return head
+	}
+
+	// Within the maximum skew, the write is ordered after the head.
+	head := setHeadAhead(10 * time.Second)
+	rev, err := writeNamespace("first")
+	require.NoError(err)
+	require.True(rev.GreaterThan(head))
+
+	// Beyond it, the write is rejected.
+	setHeadAhead(time.Hour)
+	_, err = writeNamespace("second")
+	var skewErr datastore.ErrClockSkew
+	require.ErrorAs(err, &skewErr)
+	require.Greater(skewErr.Skew(), time.Minute)
+}
+
 func (mdb *memdbDatastore) ExampleRetryableError() error {
 	return errSerialization
 }
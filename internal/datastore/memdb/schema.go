@@ -1,14 +1,18 @@
 package memdb
 
 import (
+	"time"
+
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/hashicorp/go-memdb"
 	"github.com/jzelinskie/stringz"
 	"github.com/rs/zerolog"
 	"google.golang.org/protobuf/types/known/structpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/authzed/spicedb/pkg/datastore"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
 )
 
 const (
@@ -42,6 +46,25 @@ type relationship struct {
 	subjectObjectID  string
 	subjectRelation  string
 	caveat           *contextualizedCaveat
+	integrity        *relationshipIntegrity
+	labels           []string
+}
+
+type relationshipIntegrity struct {
+	keyID     string
+	hash      []byte
+	timestamp time.Time
+}
+
+func (ri *relationshipIntegrity) RelationshipIntegrity() *core.RelationshipIntegrity {
+	if ri == nil {
+		return nil
+	}
+	return &core.RelationshipIntegrity{
+		KeyId:    ri.keyID,
+		Hash:     ri.hash,
+		HashedAt: timestamppb.New(ri.timestamp),
+	}
 }
 
 type contextualizedCaveat struct {
@@ -72,6 +95,10 @@ func (r relationship) String() string {
 	return r.namespace + ":" + r.resourceID + "#" + r.relation + "@" + r.subjectNamespace + ":" + r.subjectObjectID + "#" + r.subjectRelation + caveat
 }
 
+func (r relationship) hasLabels(labels map[string]string) bool {
+	return tuple.HasLabels(r.labels, labels)
+}
+
 func (r relationship) MarshalZerologObject(e *zerolog.Event) {
 	e.Str("rel", r.String())
 }
@@ -109,7 +136,9 @@ func (r relationship) RelationTuple() (*core.RelationTuple, error) {
 			ObjectId:  r.subjectObjectID,
 			Relation:  r.subjectRelation,
 		},
-		Caveat: cr,
+		Caveat:    cr,
+		Integrity: r.integrity.RelationshipIntegrity(),
+		Labels:    r.labels,
 	}, nil
 }
 
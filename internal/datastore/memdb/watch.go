@@ -110,7 +110,13 @@ func (mdb *memdbDatastore) loadChanges(_ context.Context, currentTxn int64, opti
 	for changeRaw := it.Next(); changeRaw != nil; changeRaw = it.Next() {
 		change := changeRaw.(*changelog)
 
-		if options.Content&datastore.WatchRelationships == datastore.WatchRelationships && len(change.changes.RelationshipChanges) > 0 {
+		// The changes of a revision are sent once, even if they change both relationships and
+		// the schema.
+		changesRelationships := options.Content&datastore.WatchRelationships == datastore.WatchRelationships &&
+			len(change.changes.RelationshipChanges) > 0
+		changesSchema := options.Content&datastore.WatchSchema == datastore.WatchSchema &&
+			(len(change.changes.ChangedDefinitions) > 0 || len(change.changes.DeletedCaveats) > 0 || len(change.changes.DeletedNamespaces) > 0)
+		if changesRelationships || changesSchema {
 			changes = append(changes, &change.changes)
 		}
 
@@ -121,11 +127,6 @@ func (mdb *memdbDatastore) loadChanges(_ context.Context, currentTxn int64, opti
 			})
 		}
 
-		if options.Content&datastore.WatchSchema == datastore.WatchSchema &&
-			len(change.changes.ChangedDefinitions) > 0 || len(change.changes.DeletedCaveats) > 0 || len(change.changes.DeletedNamespaces) > 0 {
-			changes = append(changes, &change.changes)
-		}
-
 		lastRevision = change.revisionNanos
 	}
 
@@ -48,6 +48,7 @@ const (
 	colCaveatDefinition = "definition"
 	colCaveatName       = "caveat_name"
 	colCaveatContext    = "caveat_context"
+	colMetadata         = "metadata"
 
 	errUnableToInstantiate = "unable to instantiate datastore: %w"
 	liveDeletedTxnID       = uint64(math.MaxInt64)
@@ -166,6 +167,11 @@ func newMySQLDatastore(ctx context.Context, uri string, options ...Option) (*Dat
 		return nil, fmt.Errorf("NewMySQLDatastore: %w", err)
 	}
 
+	createTxnWithMetadata, _, err := sb.Insert(driver.RelationTupleTransaction()).Columns(colMetadata).Values(nil).ToSql()
+	if err != nil {
+		return nil, fmt.Errorf("NewMySQLDatastore: %w", err)
+	}
+
 	// used for seeding the initial relation_tuple_transaction. using INSERT IGNORE on a known
 	// ID value makes this idempotent (i.e. safe to execute concurrently).
 	createBaseTxn := fmt.Sprintf("INSERT IGNORE INTO %s (id, timestamp) VALUES (1, FROM_UNIXTIME(1))", driver.RelationTupleTransaction())
@@ -196,26 +202,37 @@ func newMySQLDatastore(ctx context.Context, uri string, options ...Option) (*Dat
 		-1*config.gcWindow.Seconds(),
 	)
 
+	earliestTransactionQuery := fmt.Sprintf(
+		queryEarliestTransaction,
+		colID,
+		driver.RelationTupleTransaction(),
+		colTimestamp,
+		-1*config.gcWindow.Seconds(),
+	)
+
 	store := &Datastore{
-		db:                      db,
-		driver:                  driver,
-		url:                     uri,
-		revisionQuantization:    config.revisionQuantization,
-		gcWindow:                config.gcWindow,
-		gcInterval:              config.gcInterval,
-		gcTimeout:               config.gcMaxOperationTime,
-		gcCtx:                   gcCtx,
-		cancelGc:                cancelGc,
-		watchBufferLength:       config.watchBufferLength,
-		watchBufferWriteTimeout: config.watchBufferWriteTimeout,
-		optimizedRevisionQuery:  revisionQuery,
-		validTransactionQuery:   validTransactionQuery,
-		createTxn:               createTxn,
-		createBaseTxn:           createBaseTxn,
-		QueryBuilder:            queryBuilder,
-		readTxOptions:           &sql.TxOptions{Isolation: sql.LevelSerializable, ReadOnly: true},
-		maxRetries:              config.maxRetries,
-		analyzeBeforeStats:      config.analyzeBeforeStats,
+		db:                       db,
+		driver:                   driver,
+		url:                      uri,
+		revisionQuantization:     config.revisionQuantization,
+		gcWindow:                 config.gcWindow,
+		gcWindowOverrides:        config.gcWindowOverrides,
+		gcInterval:               config.gcInterval,
+		gcTimeout:                config.gcMaxOperationTime,
+		gcCtx:                    gcCtx,
+		cancelGc:                 cancelGc,
+		watchBufferLength:        config.watchBufferLength,
+		watchBufferWriteTimeout:  config.watchBufferWriteTimeout,
+		optimizedRevisionQuery:   revisionQuery,
+		validTransactionQuery:    validTransactionQuery,
+		earliestTransactionQuery: earliestTransactionQuery,
+		createTxn:                createTxn,
+		createTxnWithMetadata:    createTxnWithMetadata,
+		createBaseTxn:            createBaseTxn,
+		QueryBuilder:             queryBuilder,
+		readTxOptions:            &sql.TxOptions{Isolation: sql.LevelSerializable, ReadOnly: true},
+		maxRetries:               config.maxRetries,
+		analyzeBeforeStats:       config.analyzeBeforeStats,
 		CachedOptimizedRevisions: revisions.NewCachedOptimizedRevisions(
 			maxRevisionStaleness,
 		),
@@ -226,6 +243,10 @@ func newMySQLDatastore(ctx context.Context, uri string, options ...Option) (*Dat
 
 	store.SetOptimizedRevisionFunc(store.optimizedRevisionFunc)
 
+	if config.queryPlanLogInterval > 0 {
+		store.queryPlanLogger = common.NewQueryPlanLogger(explainQuery(db), config.queryPlanLogInterval)
+	}
+
 	ctx, cancel := context.WithTimeout(context.Background(), seedingTimeout)
 	defer cancel()
 	err = store.seedDatabase(ctx)
@@ -263,7 +284,8 @@ func (mds *Datastore) SnapshotReader(rev datastore.Revision) datastore.Reader {
 	}
 
 	executor := common.QueryExecutor{
-		Executor: newMySQLExecutor(mds.db),
+		Executor:   newMySQLExecutor(mds.db),
+		PlanLogger: mds.queryPlanLogger,
 	}
 
 	return &mysqlReader{
@@ -289,7 +311,7 @@ func (mds *Datastore) ReadWriteTx(
 	for i := uint8(0); i <= mds.maxRetries; i++ {
 		var newTxnID uint64
 		if err = migrations.BeginTxFunc(ctx, mds.db, &sql.TxOptions{Isolation: sql.LevelSerializable}, func(tx *sql.Tx) error {
-			newTxnID, err = mds.createNewTransaction(ctx, tx)
+			newTxnID, err = mds.createNewTransaction(ctx, tx, config.Metadata)
 			if err != nil {
 				return fmt.Errorf("unable to create new txn ID: %w", err)
 			}
@@ -299,7 +321,8 @@ func (mds *Datastore) ReadWriteTx(
 			}
 
 			executor := common.QueryExecutor{
-				Executor: newMySQLExecutor(tx),
+				Executor:   newMySQLExecutor(tx),
+				PlanLogger: mds.queryPlanLogger,
 			}
 
 			rwt := &mysqlReadWriteTXN{
@@ -356,6 +379,18 @@ type querier interface {
 	QueryContext(context.Context, string, ...interface{}) (*sql.Rows, error)
 }
 
+// explainQuery returns the function explaining the queries of the database, as the plan MySQL
+// chooses in JSON, without executing them.
+func explainQuery(db *sql.DB) common.ExplainFunc {
+	return func(ctx context.Context, sqlQuery string, args []any) (string, error) {
+		var plan string
+		if err := db.QueryRowContext(ctx, "EXPLAIN FORMAT=JSON "+sqlQuery, args...).Scan(&plan); err != nil {
+			return "", err
+		}
+		return plan, nil
+	}
+}
+
 func newMySQLExecutor(tx querier) common.ExecuteQueryFunc {
 	// This implementation does not create a transaction because it's redundant for single statements, and it avoids
 	// the network overhead and reduce contention on the connection pool. From MySQL docs:
@@ -431,25 +466,30 @@ type Datastore struct {
 
 	revisionQuantization    time.Duration
 	gcWindow                time.Duration
+	gcWindowOverrides       map[string]time.Duration
 	gcInterval              time.Duration
 	gcTimeout               time.Duration
 	watchBufferLength       uint16
 	watchBufferWriteTimeout time.Duration
 	maxRetries              uint8
+	queryPlanLogger         *common.QueryPlanLogger
 
-	optimizedRevisionQuery string
-	validTransactionQuery  string
+	optimizedRevisionQuery   string
+	validTransactionQuery    string
+	earliestTransactionQuery string
 
 	gcGroup  *errgroup.Group
 	gcCtx    context.Context
 	cancelGc context.CancelFunc
 	gcHasRun atomic.Bool
 
-	createTxn     string
-	createBaseTxn string
+	createTxn             string
+	createTxnWithMetadata string
+	createBaseTxn         string
 
 	*QueryBuilder
 	*revisions.CachedOptimizedRevisions
+	common.RevisionPins
 	revisions.CommonDecoder
 }
 
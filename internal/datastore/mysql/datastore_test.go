@@ -21,6 +21,7 @@ import (
 	"github.com/authzed/spicedb/internal/testfixtures"
 	testdatastore "github.com/authzed/spicedb/internal/testserver/datastore"
 	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
 	"github.com/authzed/spicedb/pkg/datastore/test"
 	"github.com/authzed/spicedb/pkg/migrate"
 	"github.com/authzed/spicedb/pkg/namespace"
@@ -121,6 +122,7 @@ func additionalMySQLTests(t *testing.T, b testdatastore.RunningEngineForTest) {
 	t.Run("EmptyGarbageCollection", createDatastoreTest(b, EmptyGarbageCollectionTest, defaultOptions...))
 	t.Run("NoRelationshipsGarbageCollection", createDatastoreTest(b, NoRelationshipsGarbageCollectionTest, defaultOptions...))
 	t.Run("TransactionTimestamps", createDatastoreTest(b, TransactionTimestampsTest, defaultOptions...))
+	t.Run("TransactionMetadata", createDatastoreTest(b, TransactionMetadataTest, defaultOptions...))
 	t.Run("QuantizedRevisions", func(t *testing.T) {
 		QuantizedRevisionTest(t, b)
 	})
@@ -639,7 +641,7 @@ func TransactionTimestampsTest(t *testing.T, ds datastore.Datastore) {
 	// Transaction timestamp should not be stored in system time zone
 	tx, err := db.BeginTx(ctx, nil)
 	req.NoError(err)
-	txID, err := ds.(*Datastore).createNewTransaction(ctx, tx)
+	txID, err := ds.(*Datastore).createNewTransaction(ctx, tx, nil)
 	req.NoError(err)
 	err = tx.Commit()
 	req.NoError(err)
@@ -658,6 +660,38 @@ func TransactionTimestampsTest(t *testing.T, ds datastore.Datastore) {
 	req.Equal(revisions.NewForTransactionID(txID), revision)
 }
 
+func TransactionMetadataTest(t *testing.T, ds datastore.Datastore) {
+	req := require.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	startRevision, err := ds.HeadRevision(ctx)
+	req.NoError(err)
+
+	changes, errchan := ds.Watch(ctx, startRevision, datastore.WatchJustRelationships())
+
+	write := func(rel string, opts ...options.RWTOptionsOption) {
+		_, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+			return rwt.WriteRelationships(ctx, []*corev1.RelationTupleUpdate{tuple.Touch(tuple.MustParse(rel))})
+		}, opts...)
+		req.NoError(err)
+	}
+	write("resource:foo#reader@user:tom", options.SetMetadata(map[string]string{"actor": "alice"}))
+	write("resource:foo#reader@user:fred")
+
+	for _, expected := range []map[string]string{{"actor": "alice"}, nil} {
+		select {
+		case change := <-changes:
+			req.Equal(expected, change.Metadata)
+		case err := <-errchan:
+			req.FailNow("unexpected watch error", err)
+		case <-time.After(5 * time.Second):
+			req.FailNow("timed out waiting for change")
+		}
+	}
+}
+
 func TestMySQLMigrations(t *testing.T) {
 	req := require.New(t)
 
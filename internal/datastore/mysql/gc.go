@@ -6,6 +6,7 @@ import (
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
+	"golang.org/x/exp/maps"
 
 	"github.com/authzed/spicedb/internal/datastore/common"
 	"github.com/authzed/spicedb/internal/datastore/revisions"
@@ -72,8 +73,12 @@ func (mds *Datastore) DeleteBeforeTx(
 	ctx context.Context,
 	txID datastore.Revision,
 ) (removed common.DeletionCounts, err error) {
-	// Delete any relationship rows with deleted_transaction <= the transaction ID.
-	removed.Relationships, err = mds.batchDelete(ctx, mds.driver.RelationTuple(), sq.LtOrEq{colDeletedTxn: txID})
+	// Delete any relationship rows with deleted_transaction <= the transaction ID, except those
+	// of the namespaces with a GC window of their own.
+	removed.Relationships, err = mds.DeleteRelationshipsBeforeTx(ctx, txID, common.NamespaceFilter{
+		Namespaces: maps.Keys(mds.gcWindowOverrides),
+		Exclude:    true,
+	})
 	if err != nil {
 		return
 	}
@@ -92,6 +97,22 @@ func (mds *Datastore) DeleteBeforeTx(
 	return
 }
 
+func (mds *Datastore) GCWindowOverrides() map[string]time.Duration {
+	return mds.gcWindowOverrides
+}
+
+func (mds *Datastore) DeleteRelationshipsBeforeTx(ctx context.Context, txID datastore.Revision, filter common.NamespaceFilter) (int64, error) {
+	relationshipsFilter := sq.And{sq.LtOrEq{colDeletedTxn: txID}}
+	switch {
+	case !filter.Exclude:
+		relationshipsFilter = append(relationshipsFilter, sq.Eq{colNamespace: filter.Namespaces})
+	case len(filter.Namespaces) > 0:
+		relationshipsFilter = append(relationshipsFilter, sq.NotEq{colNamespace: filter.Namespaces})
+	}
+
+	return mds.batchDelete(ctx, mds.driver.RelationTuple(), relationshipsFilter)
+}
+
 // - query was reworked to make it compatible with Vitess
 // - API differences with PSQL driver
 func (mds *Datastore) batchDelete(ctx context.Context, tableName string, filter sqlFilter) (int64, error) {
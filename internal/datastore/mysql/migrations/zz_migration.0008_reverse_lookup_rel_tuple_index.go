@@ -0,0 +1,20 @@
+package migrations
+
+import "fmt"
+
+// addReverseLookupIndex indexes the reverse queries of lookups, which select the relationships
+// of subjects of a type and relation to resources of a type and relation, filtered to those
+// alive at a transaction.
+func addReverseLookupIndex(t *tables) string {
+	return fmt.Sprintf(`CREATE INDEX ix_relation_tuple_by_subject_tuned
+    ON %s (userset_namespace, userset_relation, userset_object_id, namespace, relation, deleted_transaction);`, t.RelationTuple(),
+	)
+}
+
+func init() {
+	mustRegisterMigration("reverse_lookup_relation_tuple_index", "watch_api_relation_tuple_index", noNonatomicMigration,
+		newStatementBatch(
+			addReverseLookupIndex,
+		).execute,
+	)
+}
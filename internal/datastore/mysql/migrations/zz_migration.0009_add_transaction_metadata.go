@@ -0,0 +1,19 @@
+package migrations
+
+import "fmt"
+
+// addTransactionMetadata stores the metadata written by callers with their transactions, so that
+// Watch can return it with their changes.
+func addTransactionMetadata(t *tables) string {
+	return fmt.Sprintf(`ALTER TABLE %s ADD COLUMN metadata JSON NULL;`,
+		t.RelationTupleTransaction(),
+	)
+}
+
+func init() {
+	mustRegisterMigration("add_transaction_metadata", "reverse_lookup_relation_tuple_index", noNonatomicMigration,
+		newStatementBatch(
+			addTransactionMetadata,
+		).execute,
+	)
+}
@@ -26,6 +26,7 @@ const (
 type mysqlOptions struct {
 	revisionQuantization        time.Duration
 	gcWindow                    time.Duration
+	gcWindowOverrides           map[string]time.Duration
 	gcInterval                  time.Duration
 	gcMaxOperationTime          time.Duration
 	maxRevisionStalenessPercent float64
@@ -40,6 +41,7 @@ type mysqlOptions struct {
 	maxRetries                  uint8
 	lockWaitTimeoutSeconds      *uint8
 	gcEnabled                   bool
+	queryPlanLogInterval        time.Duration
 }
 
 // Option provides the facility to configure how clients within the
@@ -76,6 +78,17 @@ func generateConfig(options []Option) (mysqlOptions, error) {
 		)
 	}
 
+	for namespace, window := range computed.gcWindowOverrides {
+		if computed.revisionQuantization >= window {
+			return computed, fmt.Errorf(
+				"revision quantization interval (%s) must be less than GC window of namespace %s (%s)",
+				computed.revisionQuantization,
+				namespace,
+				window,
+			)
+		}
+	}
+
 	return computed, nil
 }
 
@@ -126,6 +139,17 @@ func GCWindow(window time.Duration) Option {
 	}
 }
 
+// GCWindowOverrides are the GC windows of namespaces whose deleted relationships are kept for
+// longer or shorter than the GC window. Revisions older than the GC window remain invalid, and
+// reads at revisions older than the window of a namespace may miss its deleted relationships.
+//
+// This value defaults to no overrides.
+func GCWindowOverrides(overrides map[string]time.Duration) Option {
+	return func(mo *mysqlOptions) {
+		mo.gcWindowOverrides = overrides
+	}
+}
+
 // GCInterval is the interval at which garbage collection will occur.
 //
 // This value defaults to 3 minutes.
@@ -227,6 +251,17 @@ func GCEnabled(isGCEnabled bool) Option {
 	}
 }
 
+// QueryPlanLogInterval enables logging the plans chosen by MySQL for the relationship queries
+// of lookup and expand operations, logging the plan of each shape of query at most once per
+// interval.
+//
+// Disabled by default.
+func QueryPlanLogInterval(interval time.Duration) Option {
+	return func(mo *mysqlOptions) {
+		mo.queryPlanLogInterval = interval
+	}
+}
+
 // GCMaxOperationTime is the maximum operation time of a garbage collection
 // pass before it times out.
 //
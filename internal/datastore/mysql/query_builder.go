@@ -12,6 +12,8 @@ type QueryBuilder struct {
 	GetLastRevision  sq.SelectBuilder
 	GetRevisionRange sq.SelectBuilder
 
+	QueryTransactionMetadataQuery sq.SelectBuilder
+
 	WriteNamespaceQuery        sq.InsertBuilder
 	ReadNamespaceQuery         sq.SelectBuilder
 	DeleteNamespaceQuery       sq.UpdateBuilder
@@ -39,6 +41,7 @@ func NewQueryBuilder(driver *migrations.MySQLDriver) *QueryBuilder {
 	// transaction builders
 	builder.GetLastRevision = getLastRevision(driver.RelationTupleTransaction())
 	builder.GetRevisionRange = getRevisionRange(driver.RelationTupleTransaction())
+	builder.QueryTransactionMetadataQuery = queryTransactionMetadata(driver.RelationTupleTransaction())
 
 	// namespace builders
 	builder.WriteNamespaceQuery = writeNamespace(driver.Namespace())
@@ -92,6 +95,10 @@ func getRevisionRange(tableTransaction string) sq.SelectBuilder {
 	return sb.Select("MIN(id)", "MAX(id)").From(tableTransaction)
 }
 
+func queryTransactionMetadata(tableTransaction string) sq.SelectBuilder {
+	return sb.Select(colID, colMetadata).From(tableTransaction).Where(sq.NotEq{colMetadata: nil})
+}
+
 func writeNamespace(tableNamespace string) sq.InsertBuilder {
 	return sb.Insert(tableNamespace).Columns(
 		colNamespace,
@@ -3,10 +3,13 @@ package mysql
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
 
+	sq "github.com/Masterminds/squirrel"
+
 	"github.com/authzed/spicedb/internal/datastore/revisions"
 	"github.com/authzed/spicedb/pkg/datastore"
 )
@@ -61,6 +64,23 @@ const (
 			SELECT MAX(%[1]s)
 			FROM   %[2]s
 		) as unknown;`
+
+	// queryEarliestTransaction will return the ID of the earliest transaction within the garbage
+	// collection window, or of the current head transaction if there is none.
+	//
+	//   %[1] Name of id column
+	//   %[2] Relationship tuple transaction table
+	//   %[3] Name of timestamp column
+	//   %[4] Inverse of GC window (in seconds)
+	queryEarliestTransaction = `
+		SELECT COALESCE((
+			SELECT MIN(%[1]s)
+			FROM   %[2]s
+			WHERE  %[3]s >= TIMESTAMPADD(SECOND, %.6[4]f, UTC_TIMESTAMP(6))
+		),(
+			SELECT MAX(%[1]s)
+			FROM   %[2]s
+		)) as earliest;`
 )
 
 func (mds *Datastore) optimizedRevisionFunc(ctx context.Context) (datastore.Revision, time.Duration, error) {
@@ -113,6 +133,48 @@ func (mds *Datastore) CheckRevision(ctx context.Context, revision datastore.Revi
 	return nil
 }
 
+func (mds *Datastore) RevisionAtTime(ctx context.Context, at time.Time) (datastore.Revision, error) {
+	ctx, span := tracer.Start(ctx, "RevisionAtTime")
+	defer span.End()
+
+	query, args, err := mds.GetLastRevision.Where(sq.LtOrEq{colTimestamp: at.UTC()}).ToSql()
+	if err != nil {
+		return datastore.NoRevision, fmt.Errorf(errRevision, err)
+	}
+
+	var value sql.NullInt64
+	if err := mds.db.QueryRowContext(ctx, query, args...).Scan(&value); err != nil {
+		return datastore.NoRevision, fmt.Errorf(errRevision, err)
+	}
+
+	if !value.Valid {
+		return datastore.NoRevision, datastore.NewInvalidRevisionErr(datastore.NoRevision, datastore.RevisionStale)
+	}
+
+	revision := revisions.NewForTransactionID(uint64(value.Int64))
+	if err := mds.CheckRevision(ctx, revision); err != nil {
+		return datastore.NoRevision, err
+	}
+
+	return revision, nil
+}
+
+func (mds *Datastore) EarliestRevision(ctx context.Context) (datastore.Revision, error) {
+	ctx, span := tracer.Start(ctx, "EarliestRevision")
+	defer span.End()
+
+	var value sql.NullInt64
+	if err := mds.db.QueryRowContext(ctx, mds.earliestTransactionQuery).Scan(&value); err != nil {
+		return datastore.NoRevision, fmt.Errorf(errRevision, err)
+	}
+
+	if !value.Valid {
+		return datastore.NoRevision, nil
+	}
+
+	return revisions.NewForTransactionID(uint64(value.Int64)), nil
+}
+
 func (mds *Datastore) loadRevision(ctx context.Context) (uint64, error) {
 	// slightly changed to support no revisions at all, needed for runtime seeding of first transaction
 	ctx, span := tracer.Start(ctx, "loadRevision")
@@ -156,16 +218,22 @@ func (mds *Datastore) checkValidTransaction(ctx context.Context, revisionTx uint
 	return freshEnough.Bool, unknown.Bool, nil
 }
 
-func (mds *Datastore) createNewTransaction(ctx context.Context, tx *sql.Tx) (newTxnID uint64, err error) {
+func (mds *Datastore) createNewTransaction(ctx context.Context, tx *sql.Tx, metadata map[string]string) (newTxnID uint64, err error) {
 	ctx, span := tracer.Start(ctx, "createNewTransaction")
 	defer span.End()
 
 	createQuery := mds.createTxn
-	if err != nil {
-		return 0, fmt.Errorf("createNewTransaction: %w", err)
+	var args []any
+	if len(metadata) > 0 {
+		encoded, err := json.Marshal(metadata)
+		if err != nil {
+			return 0, fmt.Errorf("createNewTransaction: unable to encode metadata: %w", err)
+		}
+		createQuery = mds.createTxnWithMetadata
+		args = append(args, string(encoded))
 	}
 
-	result, err := tx.ExecContext(ctx, createQuery)
+	result, err := tx.ExecContext(ctx, createQuery, args...)
 	if err != nil {
 		return 0, fmt.Errorf("createNewTransaction: %w", err)
 	}
@@ -2,7 +2,9 @@ package mysql
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
 
 	"github.com/authzed/spicedb/internal/datastore/common"
@@ -197,5 +199,55 @@ func (mds *Datastore) loadChanges(
 	}
 
 	changes = stagedChanges.AsRevisionChanges(revisions.TransactionIDKeyLessThanFunc)
+	if len(changes) == 0 {
+		return
+	}
+
+	err = mds.loadTransactionMetadata(ctx, afterRevision, newRevision, changes)
 	return
 }
+
+// loadTransactionMetadata attaches the metadata written with the transactions of the changes.
+func (mds *Datastore) loadTransactionMetadata(ctx context.Context, afterRevision, newRevision uint64, changes []datastore.RevisionChanges) error {
+	sql, args, err := mds.QueryTransactionMetadataQuery.Where(sq.And{
+		sq.Gt{colID: afterRevision},
+		sq.LtOrEq{colID: newRevision},
+	}).ToSql()
+	if err != nil {
+		return err
+	}
+
+	rows, err := mds.db.QueryContext(ctx, sql, args...)
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			err = datastore.NewWatchCanceledErr()
+		}
+		return err
+	}
+	defer common.LogOnError(ctx, rows.Close)
+
+	metadataByTxn := make(map[uint64]map[string]string)
+	for rows.Next() {
+		var txnID uint64
+		var encoded []byte
+		if err := rows.Scan(&txnID, &encoded); err != nil {
+			return err
+		}
+
+		var metadata map[string]string
+		if err := json.Unmarshal(encoded, &metadata); err != nil {
+			return fmt.Errorf("unable to decode metadata of transaction %d: %w", txnID, err)
+		}
+		metadataByTxn[txnID] = metadata
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range changes {
+		if metadata, ok := metadataByTxn[changes[i].Revision.(revisions.TransactionIDRevision).TransactionID()]; ok {
+			changes[i].Metadata = metadata
+		}
+	}
+	return nil
+}
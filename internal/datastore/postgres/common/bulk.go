@@ -14,9 +14,10 @@ type tupleSourceAdapter struct {
 	source datastore.BulkWriteRelationshipSource
 	ctx    context.Context
 
-	current      *core.RelationTuple
-	err          error
-	valuesBuffer []any
+	current       *core.RelationTuple
+	err           error
+	valuesBuffer  []any
+	withIntegrity bool
 }
 
 // Next returns true if there is another row and makes the next row data
@@ -46,6 +47,23 @@ func (tg *tupleSourceAdapter) Values() ([]any, error) {
 	tg.valuesBuffer[6] = caveatName
 	tg.valuesBuffer[7] = caveatContext
 
+	if tg.withIntegrity {
+		var integrityKeyID sql.NullString
+		var integrityHash []byte
+		var integrityTimestamp sql.NullTime
+		if tg.current.Integrity != nil {
+			integrityKeyID.String = tg.current.Integrity.KeyId
+			integrityKeyID.Valid = true
+			integrityHash = tg.current.Integrity.Hash
+			integrityTimestamp.Time = tg.current.Integrity.HashedAt.AsTime()
+			integrityTimestamp.Valid = true
+		}
+
+		tg.valuesBuffer[8] = integrityKeyID
+		tg.valuesBuffer[9] = integrityHash
+		tg.valuesBuffer[10] = integrityTimestamp
+	}
+
 	return tg.valuesBuffer, nil
 }
 
@@ -61,11 +79,35 @@ func BulkLoad(
 	tupleTableName string,
 	colNames []string,
 	iter datastore.BulkWriteRelationshipSource,
+) (uint64, error) {
+	return bulkLoad(ctx, tx, tupleTableName, colNames, iter, false)
+}
+
+// BulkLoadWithIntegrity is BulkLoad for tuple tables storing integrity data, whose
+// colNames must end with the integrity key ID, hash and timestamp columns.
+func BulkLoadWithIntegrity(
+	ctx context.Context,
+	tx pgx.Tx,
+	tupleTableName string,
+	colNames []string,
+	iter datastore.BulkWriteRelationshipSource,
+) (uint64, error) {
+	return bulkLoad(ctx, tx, tupleTableName, colNames, iter, true)
+}
+
+func bulkLoad(
+	ctx context.Context,
+	tx pgx.Tx,
+	tupleTableName string,
+	colNames []string,
+	iter datastore.BulkWriteRelationshipSource,
+	withIntegrity bool,
 ) (uint64, error) {
 	adapter := &tupleSourceAdapter{
-		source:       iter,
-		ctx:          ctx,
-		valuesBuffer: make([]any, 8),
+		source:        iter,
+		ctx:           ctx,
+		valuesBuffer:  make([]any, len(colNames)),
+		withIntegrity: withIntegrity,
 	}
 	copied, err := tx.CopyFrom(ctx, pgx.Identifier{tupleTableName}, colNames, adapter)
 	return uint64(copied), err
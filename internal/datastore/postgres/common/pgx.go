@@ -16,6 +16,7 @@ import (
 	"github.com/jackc/pgx/v5/tracelog"
 	"go.opentelemetry.io/otel/attribute"
 	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/protobuf/types/known/timestamppb"
 
 	"github.com/authzed/spicedb/internal/datastore/common"
 	log "github.com/authzed/spicedb/internal/logging"
@@ -28,12 +29,21 @@ const errUnableToQueryTuples = "unable to query tuples: %w"
 func NewPGXExecutor(querier DBFuncQuerier) common.ExecuteQueryFunc {
 	return func(ctx context.Context, sql string, args []any) ([]*corev1.RelationTuple, error) {
 		span := trace.SpanFromContext(ctx)
-		return queryTuples(ctx, sql, args, span, querier)
+		return queryTuples(ctx, sql, args, span, querier, false)
+	}
+}
+
+// NewPGXExecutorWithIntegrity creates an executor that uses the pgx library to make the specified
+// queries, which must select the integrity key ID, hash and timestamp of each tuple after its caveat.
+func NewPGXExecutorWithIntegrity(querier DBFuncQuerier) common.ExecuteQueryFunc {
+	return func(ctx context.Context, sql string, args []any) ([]*corev1.RelationTuple, error) {
+		span := trace.SpanFromContext(ctx)
+		return queryTuples(ctx, sql, args, span, querier, true)
 	}
 }
 
 // queryTuples queries tuples for the given query and transaction.
-func queryTuples(ctx context.Context, sqlStatement string, args []any, span trace.Span, tx DBFuncQuerier) ([]*corev1.RelationTuple, error) {
+func queryTuples(ctx context.Context, sqlStatement string, args []any, span trace.Span, tx DBFuncQuerier, withIntegrity bool) ([]*corev1.RelationTuple, error) {
 	var tuples []*corev1.RelationTuple
 	err := tx.QueryFunc(ctx, func(ctx context.Context, rows pgx.Rows) error {
 		span.AddEvent("Query issued to database")
@@ -45,7 +55,7 @@ func queryTuples(ctx context.Context, sqlStatement string, args []any, span trac
 			}
 			var caveatName sql.NullString
 			var caveatCtx map[string]any
-			err := rows.Scan(
+			dest := []any{
 				&nextTuple.ResourceAndRelation.Namespace,
 				&nextTuple.ResourceAndRelation.ObjectId,
 				&nextTuple.ResourceAndRelation.Relation,
@@ -54,11 +64,28 @@ func queryTuples(ctx context.Context, sqlStatement string, args []any, span trac
 				&nextTuple.Subject.Relation,
 				&caveatName,
 				&caveatCtx,
-			)
-			if err != nil {
+			}
+
+			var integrityKeyID sql.NullString
+			var integrityHash []byte
+			var integrityTimestamp sql.NullTime
+			if withIntegrity {
+				dest = append(dest, &integrityKeyID, &integrityHash, &integrityTimestamp)
+			}
+
+			if err := rows.Scan(dest...); err != nil {
 				return fmt.Errorf(errUnableToQueryTuples, fmt.Errorf("scan err: %w", err))
 			}
 
+			if integrityKeyID.Valid {
+				nextTuple.Integrity = &corev1.RelationshipIntegrity{
+					KeyId:    integrityKeyID.String,
+					Hash:     integrityHash,
+					HashedAt: timestamppb.New(integrityTimestamp.Time),
+				}
+			}
+
+			var err error
 			nextTuple.Caveat, err = common.ContextualizedCaveatFrom(caveatName.String, caveatCtx)
 			if err != nil {
 				return fmt.Errorf(errUnableToQueryTuples, fmt.Errorf("unable to fetch caveat context: %w", err))
@@ -2,13 +2,17 @@ package postgres
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 	"time"
 
 	sq "github.com/Masterminds/squirrel"
+	"github.com/jackc/pgx/v5"
+	"golang.org/x/exp/maps"
 
 	"github.com/authzed/spicedb/internal/datastore/common"
+	log "github.com/authzed/spicedb/internal/logging"
 	"github.com/authzed/spicedb/pkg/datastore"
 )
 
@@ -72,6 +76,10 @@ func (pgd *pgDatastore) TxIDBefore(ctx context.Context, before time.Time) (datas
 	var snapshot pgSnapshot
 	err = pgd.readPool.QueryRow(ctx, sql, args...).Scan(&value, &snapshot)
 	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			log.Ctx(ctx).Debug().Time("before", before).Msg("no stale transactions found in the datastore")
+			return datastore.NoRevision, nil
+		}
 		return datastore.NoRevision, err
 	}
 
@@ -84,13 +92,12 @@ func (pgd *pgDatastore) DeleteBeforeTx(ctx context.Context, txID datastore.Revis
 	minTxAlive := newXid8(revision.snapshot.xmin)
 	removed := common.DeletionCounts{}
 	var err error
-	// Delete any relationship rows that were already dead when this transaction started
-	removed.Relationships, err = pgd.batchDelete(
-		ctx,
-		tableTuple,
-		relationTuplePKCols,
-		sq.Lt{colDeletedXid: minTxAlive},
-	)
+	// Delete any relationship rows that were already dead when this transaction started, except
+	// those of the namespaces with a GC window of their own.
+	removed.Relationships, err = pgd.deleteRelationshipsBeforeXid(ctx, minTxAlive, common.NamespaceFilter{
+		Namespaces: maps.Keys(pgd.gcWindowOverrides),
+		Exclude:    true,
+	})
 	if err != nil {
 		return removed, fmt.Errorf("failed to GC relationships table: %w", err)
 	}
@@ -123,6 +130,31 @@ func (pgd *pgDatastore) DeleteBeforeTx(ctx context.Context, txID datastore.Revis
 	return removed, err
 }
 
+func (pgd *pgDatastore) GCWindowOverrides() map[string]time.Duration {
+	return pgd.gcWindowOverrides
+}
+
+func (pgd *pgDatastore) DeleteRelationshipsBeforeTx(ctx context.Context, txID datastore.Revision, filter common.NamespaceFilter) (int64, error) {
+	revision := txID.(postgresRevision)
+	deleted, err := pgd.deleteRelationshipsBeforeXid(ctx, newXid8(revision.snapshot.xmin), filter)
+	if err != nil {
+		return deleted, fmt.Errorf("failed to GC relationships table: %w", err)
+	}
+	return deleted, nil
+}
+
+func (pgd *pgDatastore) deleteRelationshipsBeforeXid(ctx context.Context, minTxAlive xid8, filter common.NamespaceFilter) (int64, error) {
+	relationshipsFilter := sq.And{sq.Lt{colDeletedXid: minTxAlive}}
+	switch {
+	case !filter.Exclude:
+		relationshipsFilter = append(relationshipsFilter, sq.Eq{colNamespace: filter.Namespaces})
+	case len(filter.Namespaces) > 0:
+		relationshipsFilter = append(relationshipsFilter, sq.NotEq{colNamespace: filter.Namespaces})
+	}
+
+	return pgd.batchDelete(ctx, tableTuple, relationTuplePKCols, relationshipsFilter)
+}
+
 func (pgd *pgDatastore) batchDelete(
 	ctx context.Context,
 	tableName string,
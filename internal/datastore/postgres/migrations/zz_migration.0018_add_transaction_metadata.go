@@ -0,0 +1,24 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+const addTransactionMetadataColumn = `ALTER TABLE relation_tuple_transaction
+	ADD COLUMN IF NOT EXISTS metadata JSONB NOT NULL DEFAULT '{}';`
+
+func init() {
+	if err := DatabaseMigrations.Register("add-metadata-to-transaction-table", "add-rel-by-alive-resource-relation-subject",
+		noNonatomicMigration,
+		func(ctx context.Context, tx pgx.Tx) error {
+			if _, err := tx.Exec(ctx, addTransactionMetadataColumn); err != nil {
+				return fmt.Errorf("failed to add metadata column to transaction table: %w", err)
+			}
+			return nil
+		}); err != nil {
+		panic("failed to register migration: " + err.Error())
+	}
+}
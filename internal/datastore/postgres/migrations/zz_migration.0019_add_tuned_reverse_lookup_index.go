@@ -0,0 +1,29 @@
+package migrations
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// createTunedReverseLookupIndex covers the reverse queries of lookups, which select the
+// relationships of subjects of a type and relation to resources of a type and relation, so
+// that they can be answered from the index alone at any revision.
+const createTunedReverseLookupIndex = `CREATE INDEX CONCURRENTLY
+	IF NOT EXISTS ix_relation_tuple_by_subject_tuned
+	ON relation_tuple (userset_namespace, userset_relation, userset_object_id, namespace, relation)
+	INCLUDE (object_id, caveat_name, caveat_context, created_xid, deleted_xid);`
+
+func init() {
+	if err := DatabaseMigrations.Register("add-tuned-reverse-lookup-index", "add-metadata-to-transaction-table",
+		func(ctx context.Context, conn *pgx.Conn) error {
+			if _, err := conn.Exec(ctx, createTunedReverseLookupIndex); err != nil {
+				return fmt.Errorf("failed to create tuned index for reverse lookups: %w", err)
+			}
+			return nil
+		},
+		noTxMigration); err != nil {
+		panic("failed to register migration: " + err.Error())
+	}
+}
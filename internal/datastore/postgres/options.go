@@ -16,10 +16,13 @@ type postgresOptions struct {
 	watchBufferWriteTimeout time.Duration
 	revisionQuantization    time.Duration
 	gcWindow                time.Duration
+	gcWindowOverrides       map[string]time.Duration
 	gcInterval              time.Duration
 	gcMaxOperationTime      time.Duration
 	maxRetries              uint8
 
+	queryPlanLogInterval time.Duration
+
 	enablePrometheusStats   bool
 	analyzeBeforeStatistics bool
 	gcEnabled               bool
@@ -92,6 +95,17 @@ func generateConfig(options []Option) (postgresOptions, error) {
 		)
 	}
 
+	for namespace, window := range computed.gcWindowOverrides {
+		if computed.revisionQuantization >= window {
+			return computed, fmt.Errorf(
+				"revision quantization interval (%s) must be less than GC window of namespace %s (%s)",
+				computed.revisionQuantization,
+				namespace,
+				window,
+			)
+		}
+	}
+
 	if _, ok := migrationPhases[computed.migrationPhase]; !ok {
 		return computed, fmt.Errorf("unknown migration phase: %s", computed.migrationPhase)
 	}
@@ -262,6 +276,15 @@ func GCWindow(window time.Duration) Option {
 	return func(po *postgresOptions) { po.gcWindow = window }
 }
 
+// GCWindowOverrides are the GC windows of namespaces whose deleted relationships are kept for
+// longer or shorter than the GC window. Revisions older than the GC window remain invalid, and
+// reads at revisions older than the window of a namespace may miss its deleted relationships.
+//
+// This value defaults to no overrides.
+func GCWindowOverrides(overrides map[string]time.Duration) Option {
+	return func(po *postgresOptions) { po.gcWindowOverrides = overrides }
+}
+
 // GCInterval is the the interval at which garbage collection will occur.
 //
 // This value defaults to 3 minutes.
@@ -277,6 +300,15 @@ func GCMaxOperationTime(time time.Duration) Option {
 	return func(po *postgresOptions) { po.gcMaxOperationTime = time }
 }
 
+// QueryPlanLogInterval enables logging the plans chosen by Postgres for the relationship
+// queries of lookup and expand operations, logging the plan of each shape of query at most
+// once per interval.
+//
+// Disabled by default.
+func QueryPlanLogInterval(interval time.Duration) Option {
+	return func(po *postgresOptions) { po.queryPlanLogInterval = interval }
+}
+
 // MaxRetries is the maximum number of times a retriable transaction will be
 // client-side retried.
 // Default: 10
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -50,6 +51,7 @@ const (
 	colCreatedXid        = "created_xid"
 	colDeletedXid        = "deleted_xid"
 	colSnapshot          = "snapshot"
+	colMetadata          = "metadata"
 	colObjectID          = "object_id"
 	colRelation          = "relation"
 	colUsersetNamespace  = "userset_namespace"
@@ -98,6 +100,14 @@ var (
 		colSnapshot,
 	)
 
+	createTxnWithMetadata = fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES ($1) RETURNING %s, %s",
+		tableTransaction,
+		colMetadata,
+		colXID,
+		colSnapshot,
+	)
+
 	getNow = psql.Select("NOW()")
 
 	tracer = otel.Tracer("spicedb/internal/datastore/postgres")
@@ -252,6 +262,7 @@ func newPostgresDatastore(
 		optimizedRevisionQuery:  revisionQuery,
 		validTransactionQuery:   validTransactionQuery,
 		gcWindow:                config.gcWindow,
+		gcWindowOverrides:       config.gcWindowOverrides,
 		gcInterval:              config.gcInterval,
 		gcTimeout:               config.gcMaxOperationTime,
 		analyzeBeforeStatistics: config.analyzeBeforeStatistics,
@@ -264,6 +275,10 @@ func newPostgresDatastore(
 
 	datastore.SetOptimizedRevisionFunc(datastore.optimizedRevisionFunc)
 
+	if config.queryPlanLogInterval > 0 {
+		datastore.queryPlanLogger = common.NewQueryPlanLogger(explainQuery(datastore.readPool), config.queryPlanLogInterval)
+	}
+
 	// Start a goroutine for garbage collection.
 	if datastore.gcInterval > 0*time.Minute && config.gcEnabled {
 		datastore.gcGroup, datastore.gcCtx = errgroup.WithContext(datastore.gcCtx)
@@ -285,6 +300,7 @@ func newPostgresDatastore(
 
 type pgDatastore struct {
 	*revisions.CachedOptimizedRevisions
+	common.RevisionPins
 
 	dburl                   string
 	readPool, writePool     pgxcommon.ConnPooler
@@ -293,12 +309,14 @@ type pgDatastore struct {
 	optimizedRevisionQuery  string
 	validTransactionQuery   string
 	gcWindow                time.Duration
+	gcWindowOverrides       map[string]time.Duration
 	gcInterval              time.Duration
 	gcTimeout               time.Duration
 	analyzeBeforeStatistics bool
 	readTxOptions           pgx.TxOptions
 	maxRetries              uint8
 	watchEnabled            bool
+	queryPlanLogger         *common.QueryPlanLogger
 
 	gcGroup  *errgroup.Group
 	gcCtx    context.Context
@@ -311,7 +329,8 @@ func (pgd *pgDatastore) SnapshotReader(revRaw datastore.Revision) datastore.Read
 
 	queryFuncs := pgxcommon.QuerierFuncsFor(pgd.readPool)
 	executor := common.QueryExecutor{
-		Executor: pgxcommon.NewPGXExecutor(queryFuncs),
+		Executor:   pgxcommon.NewPGXExecutor(queryFuncs),
+		PlanLogger: pgd.queryPlanLogger,
 	}
 
 	return &pgReader{
@@ -336,14 +355,15 @@ func (pgd *pgDatastore) ReadWriteTx(
 		var newSnapshot pgSnapshot
 		err = wrapError(pgx.BeginTxFunc(ctx, pgd.writePool, pgx.TxOptions{IsoLevel: pgx.Serializable}, func(tx pgx.Tx) error {
 			var err error
-			newXID, newSnapshot, err = createNewTransaction(ctx, tx)
+			newXID, newSnapshot, err = createNewTransaction(ctx, tx, config.Metadata)
 			if err != nil {
 				return err
 			}
 
 			queryFuncs := pgxcommon.QuerierFuncsFor(pgd.readPool)
 			executor := common.QueryExecutor{
-				Executor: pgxcommon.NewPGXExecutor(queryFuncs),
+				Executor:   pgxcommon.NewPGXExecutor(queryFuncs),
+				PlanLogger: pgd.queryPlanLogger,
 			}
 
 			rwt := &pgReadWriteTXN{
@@ -473,6 +493,35 @@ func (pgd *pgDatastore) RepairOperations() []datastore.RepairOperation {
 	}
 }
 
+// changelogGapQuery returns the query counting the relationships whose transaction, in the
+// given column, is missing from the transactions table, among those since the oldest
+// transaction it retains. The ID marking relationships as live is not a transaction.
+func changelogGapQuery(column string) string {
+	return fmt.Sprintf(
+		`SELECT count(*) FROM %[1]s WHERE %[1]s.%[3]s != $1 AND %[1]s.%[3]s >= (SELECT min(%[4]s) FROM %[2]s) AND NOT EXISTS (SELECT 1 FROM %[2]s WHERE %[2]s.%[4]s = %[1]s.%[3]s)`,
+		tableTuple,
+		tableTransaction,
+		column,
+		colXID,
+	)
+}
+
+// CheckChangelog returns a description of each gap found in the transactions table, which
+// records the changes returned by Watch.
+func (pgd *pgDatastore) CheckChangelog(ctx context.Context) ([]string, error) {
+	var gaps []string
+	for _, column := range []string{colCreatedXid, colDeletedXid} {
+		var count int64
+		if err := pgd.readPool.QueryRow(ctx, changelogGapQuery(column), liveDeletedTxnID).Scan(&count); err != nil {
+			return nil, fmt.Errorf("failed to check changelog: %w", err)
+		}
+		if count > 0 {
+			gaps = append(gaps, fmt.Sprintf("%d relationships have a %s missing from the %s table", count, column, tableTransaction))
+		}
+	}
+	return gaps, nil
+}
+
 func wrapError(err error) error {
 	// If a unique constraint violation is returned, then its likely that the cause
 	// was an existing relationship given as a CREATE.
@@ -586,6 +635,31 @@ func buildLivingObjectFilterForRevision(revision postgresRevision) queryFilterer
 	}
 }
 
+// explainQuery returns the function explaining the queries of the pool, as the lines of the
+// plan Postgres chooses, without executing them.
+func explainQuery(pool pgxcommon.ConnPooler) common.ExplainFunc {
+	return func(ctx context.Context, sql string, args []any) (string, error) {
+		rows, err := pool.Query(ctx, "EXPLAIN "+sql, args...)
+		if err != nil {
+			return "", err
+		}
+		defer rows.Close()
+
+		var lines []string
+		for rows.Next() {
+			var line string
+			if err := rows.Scan(&line); err != nil {
+				return "", err
+			}
+			lines = append(lines, line)
+		}
+		if err := rows.Err(); err != nil {
+			return "", err
+		}
+		return strings.Join(lines, "\n"), nil
+	}
+}
+
 func currentlyLivingObjects(original sq.SelectBuilder) sq.SelectBuilder {
 	return original.Where(sq.Eq{colDeletedXid: liveDeletedTxnID})
 }
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	sq "github.com/Masterminds/squirrel"
 	"github.com/jackc/pgx/v5"
 
 	"github.com/authzed/spicedb/pkg/datastore"
@@ -124,6 +125,49 @@ func (pgd *pgDatastore) CheckRevision(ctx context.Context, revisionRaw datastore
 	return nil
 }
 
+func (pgd *pgDatastore) RevisionAtTime(ctx context.Context, at time.Time) (datastore.Revision, error) {
+	ctx, span := tracer.Start(ctx, "RevisionAtTime")
+	defer span.End()
+
+	// RelationTupleTransaction is not timezone aware -- explicitly use UTC.
+	sql, args, err := getRevision.Where(sq.LtOrEq{colTimestamp: at.UTC()}).ToSql()
+	if err != nil {
+		return datastore.NoRevision, fmt.Errorf(errRevision, err)
+	}
+
+	var xid xid8
+	var snapshot pgSnapshot
+	if err := pgd.readPool.QueryRow(ctx, sql, args...).Scan(&xid, &snapshot); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return datastore.NoRevision, datastore.NewInvalidRevisionErr(datastore.NoRevision, datastore.RevisionStale)
+		}
+		return datastore.NoRevision, fmt.Errorf(errRevision, err)
+	}
+
+	// The snapshot of the transaction is taken as it began, so it must be marked complete to
+	// include the transaction's own changes.
+	revision := postgresRevision{snapshot.markComplete(xid.Uint64)}
+	if err := pgd.CheckRevision(ctx, revision); err != nil {
+		return datastore.NoRevision, err
+	}
+
+	return revision, nil
+}
+
+func (pgd *pgDatastore) EarliestRevision(ctx context.Context) (datastore.Revision, error) {
+	ctx, span := tracer.Start(ctx, "EarliestRevision")
+	defer span.End()
+
+	var minXid xid8
+	var minSnapshot, currentSnapshot pgSnapshot
+	if err := pgd.readPool.QueryRow(ctx, pgd.validTransactionQuery).
+		Scan(&minXid, &minSnapshot, &currentSnapshot); err != nil {
+		return datastore.NoRevision, fmt.Errorf(errRevision, err)
+	}
+
+	return postgresRevision{minSnapshot.markComplete(minXid.Uint64)}, nil
+}
+
 // RevisionFromString reverses the encoding process performed by MarshalBinary and String.
 func (pgd *pgDatastore) RevisionFromString(revisionStr string) (datastore.Revision, error) {
 	return ParseRevisionString(revisionStr)
@@ -234,11 +278,16 @@ func parseRevisionDecimal(revisionStr string) (datastore.Revision, error) {
 	}}, nil
 }
 
-func createNewTransaction(ctx context.Context, tx pgx.Tx) (newXID xid8, newSnapshot pgSnapshot, err error) {
+func createNewTransaction(ctx context.Context, tx pgx.Tx, metadata map[string]string) (newXID xid8, newSnapshot pgSnapshot, err error) {
 	ctx, span := tracer.Start(ctx, "createNewTransaction")
 	defer span.End()
 
-	cterr := tx.QueryRow(ctx, createTxn).Scan(&newXID, &newSnapshot)
+	row := tx.QueryRow(ctx, createTxn)
+	if len(metadata) > 0 {
+		row = tx.QueryRow(ctx, createTxnWithMetadata, metadata) // PGX driver serializes the map to JSONB
+	}
+
+	cterr := row.Scan(&newXID, &newSnapshot)
 	if cterr != nil {
 		err = fmt.Errorf("error when trying to create a new transaction: %w", cterr)
 	}
@@ -22,7 +22,8 @@ const (
 
 type revisionWithXid struct {
 	postgresRevision
-	tx xid8
+	tx       xid8
+	metadata map[string]string
 }
 
 var (
@@ -30,10 +31,10 @@ var (
 	// xid8 is one of the last ~2 billion transaction IDs generated. We should be garbage
 	// collecting these transactions long before we get to that point.
 	newRevisionsQuery = fmt.Sprintf(`
-	SELECT %[1]s, %[2]s FROM %[3]s
+	SELECT %[1]s, %[2]s, %[4]s FROM %[3]s
 	WHERE %[1]s >= pg_snapshot_xmax($1) OR (
 		%[1]s >= pg_snapshot_xmin($1) AND NOT pg_visible_in_snapshot(%[1]s, $1)
-	) ORDER BY pg_xact_commit_timestamp(%[1]s::xid), %[1]s;`, colXID, colSnapshot, tableTransaction)
+	) ORDER BY pg_xact_commit_timestamp(%[1]s::xid), %[1]s;`, colXID, colSnapshot, tableTransaction, colMetadata)
 
 	queryChangedTuples = psql.Select(
 		colNamespace,
@@ -200,13 +201,15 @@ func (pgd *pgDatastore) getNewRevisions(ctx context.Context, afterTX postgresRev
 		for rows.Next() {
 			var nextXID xid8
 			var nextSnapshot pgSnapshot
-			if err := rows.Scan(&nextXID, &nextSnapshot); err != nil {
+			var metadata map[string]string
+			if err := rows.Scan(&nextXID, &nextSnapshot, &metadata); err != nil {
 				return fmt.Errorf("unable to decode new revision: %w", err)
 			}
 
 			ids = append(ids, revisionWithXid{
 				postgresRevision{nextSnapshot.markComplete(nextXID.Uint64)},
 				nextXID,
+				metadata,
 			})
 		}
 		if rows.Err() != nil {
@@ -267,6 +270,13 @@ func (pgd *pgDatastore) loadChanges(ctx context.Context, revisions []revisionWit
 	reconciledChanges := tracked.AsRevisionChanges(func(lhs, rhs uint64) bool {
 		return filter[lhs] < filter[rhs]
 	})
+
+	// Attach any metadata written with the transactions.
+	for i := range reconciledChanges {
+		if rev, ok := reconciledChanges[i].Revision.(revisionWithXid); ok && len(rev.metadata) > 0 {
+			reconciledChanges[i].Metadata = rev.metadata
+		}
+	}
 	return reconciledChanges, nil
 }
 
@@ -0,0 +1,297 @@
+package proxy
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/spiceerrors"
+)
+
+var circuitBreakerStateGauge = promauto.NewGauge(prometheus.GaugeOpts{
+	Namespace: "spicedb",
+	Subsystem: "datastore",
+	Name:      "circuit_breaker_state",
+	Help:      "State of the datastore circuit breaker: 0 when closed, 1 when open and 2 when half-open",
+})
+
+var circuitBreakerRejectedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "datastore",
+	Name:      "circuit_breaker_rejected_total",
+	Help:      "Count of the datastore operations failed fast while the circuit breaker was open, by operation",
+}, []string{"operation"})
+
+var circuitBreakerStaleRevisionsCounter = promauto.NewCounter(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "datastore",
+	Name:      "circuit_breaker_stale_revisions_total",
+	Help:      "Count of the revision requests answered with the last known revision while the circuit breaker was open",
+})
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+func (s circuitState) String() string {
+	switch s {
+	case circuitOpen:
+		return "open"
+	case circuitHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// CircuitBreakerPolicy configures when the datastore is considered unavailable, such as during
+// the failover of its primary, and how requests are served until it recovers.
+type CircuitBreakerPolicy struct {
+	// FailureThreshold is the number of consecutive operations failing with a connection error
+	// after which the circuit opens and operations fail fast.
+	FailureThreshold uint16
+
+	// OpenDuration is how long the circuit stays open before a single operation is let through
+	// to probe whether the datastore has recovered.
+	OpenDuration time.Duration
+
+	// ServeStaleReads, if true, answers revision requests with the last revision known while
+	// the circuit is open, so that results cached at that revision continue to be served, and
+	// keeps the datastore reported as ready in a degraded state.
+	ServeStaleReads bool
+}
+
+// NewCircuitBreakingDatastoreProxy creates a proxy which stops sending operations to a datastore
+// whose connections are failing, returning an Unavailable error to callers instead of waiting on
+// each connection attempt, until a probe operation succeeds. The connection pools of the
+// datastore drivers reconnect on their own once the datastore is reachable again.
+func NewCircuitBreakingDatastoreProxy(d datastore.Datastore, policy CircuitBreakerPolicy) (datastore.Datastore, error) {
+	if policy.FailureThreshold == 0 {
+		return nil, errors.New("invalid circuit breaker failure threshold: must be positive")
+	}
+	if policy.OpenDuration <= 0 {
+		return nil, errors.New("invalid circuit breaker open duration: must be positive")
+	}
+
+	circuitBreakerStateGauge.Set(float64(circuitClosed))
+	return &circuitBreakingProxy{
+		Datastore: d,
+		policy:    policy,
+		now:       time.Now,
+	}, nil
+}
+
+type circuitBreakingProxy struct {
+	datastore.Datastore
+	policy CircuitBreakerPolicy
+
+	mu           sync.Mutex
+	state        circuitState
+	failures     uint16
+	openedAt     time.Time
+	lastRevision datastore.Revision
+
+	now func() time.Time
+}
+
+func (p *circuitBreakingProxy) ReadWriteTx(ctx context.Context, f datastore.TxUserFunc, opts ...options.RWTOptionsOption) (datastore.Revision, error) {
+	return withCircuitBreaker(ctx, p, "ReadWriteTx", func() (datastore.Revision, error) {
+		return p.Datastore.ReadWriteTx(ctx, f, opts...)
+	})
+}
+
+func (p *circuitBreakingProxy) OptimizedRevision(ctx context.Context) (datastore.Revision, error) {
+	return p.revision(ctx, "OptimizedRevision", p.Datastore.OptimizedRevision)
+}
+
+func (p *circuitBreakingProxy) HeadRevision(ctx context.Context) (datastore.Revision, error) {
+	return p.revision(ctx, "HeadRevision", p.Datastore.HeadRevision)
+}
+
+func (p *circuitBreakingProxy) revision(ctx context.Context, operation string, fn func(context.Context) (datastore.Revision, error)) (datastore.Revision, error) {
+	rev, err := withCircuitBreaker(ctx, p, operation, func() (datastore.Revision, error) {
+		return fn(ctx)
+	})
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if err == nil {
+		p.lastRevision = rev
+		return rev, nil
+	}
+	if p.policy.ServeStaleReads && p.state != circuitClosed && p.lastRevision != nil {
+		circuitBreakerStaleRevisionsCounter.Inc()
+		return p.lastRevision, nil
+	}
+	return rev, err
+}
+
+func (p *circuitBreakingProxy) SnapshotReader(rev datastore.Revision) datastore.Reader {
+	return &circuitBreakingReader{Reader: p.Datastore.SnapshotReader(rev), p: p}
+}
+
+// ReadyState reports the datastore as unavailable while the circuit is not closed, or as ready
+// in a degraded state if stale reads are served, without waiting on the datastore.
+func (p *circuitBreakingProxy) ReadyState(ctx context.Context) (datastore.ReadyState, error) {
+	p.mu.Lock()
+	state := p.state
+	p.mu.Unlock()
+
+	if state == circuitClosed {
+		return p.Datastore.ReadyState(ctx)
+	}
+
+	if p.policy.ServeStaleReads {
+		return datastore.ReadyState{
+			Message: "degraded: datastore connections are failing; serving reads at the last known revision",
+			IsReady: true,
+		}, nil
+	}
+	return datastore.ReadyState{
+		Message: "datastore connections are failing; circuit breaker is " + state.String(),
+		IsReady: false,
+	}, nil
+}
+
+func (p *circuitBreakingProxy) Unwrap() datastore.Datastore {
+	return p.Datastore
+}
+
+type circuitBreakingReader struct {
+	datastore.Reader
+	p *circuitBreakingProxy
+}
+
+func (r *circuitBreakingReader) QueryRelationships(ctx context.Context, filter datastore.RelationshipsFilter, opts ...options.QueryOptionsOption) (datastore.RelationshipIterator, error) {
+	return withCircuitBreaker(ctx, r.p, "QueryRelationships", func() (datastore.RelationshipIterator, error) {
+		return r.Reader.QueryRelationships(ctx, filter, opts...)
+	})
+}
+
+func (r *circuitBreakingReader) ReverseQueryRelationships(ctx context.Context, subjectsFilter datastore.SubjectsFilter, opts ...options.ReverseQueryOptionsOption) (datastore.RelationshipIterator, error) {
+	return withCircuitBreaker(ctx, r.p, "ReverseQueryRelationships", func() (datastore.RelationshipIterator, error) {
+		return r.Reader.ReverseQueryRelationships(ctx, subjectsFilter, opts...)
+	})
+}
+
+func (r *circuitBreakingReader) ReadNamespaceByName(ctx context.Context, nsName string) (*core.NamespaceDefinition, datastore.Revision, error) {
+	var lastWritten datastore.Revision
+	ns, err := withCircuitBreaker(ctx, r.p, "ReadNamespaceByName", func() (*core.NamespaceDefinition, error) {
+		ns, rev, err := r.Reader.ReadNamespaceByName(ctx, nsName)
+		lastWritten = rev
+		return ns, err
+	})
+	return ns, lastWritten, err
+}
+
+func withCircuitBreaker[T any](ctx context.Context, p *circuitBreakingProxy, operation string, fn func() (T, error)) (T, error) {
+	if err := p.allow(); err != nil {
+		circuitBreakerRejectedCounter.WithLabelValues(operation).Inc()
+		var empty T
+		return empty, err
+	}
+
+	result, err := fn()
+	p.record(ctx, err)
+	return result, err
+}
+
+// allow returns an error if the circuit is open, or half-open with its probe in flight.
+func (p *circuitBreakingProxy) allow() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch p.state {
+	case circuitOpen:
+		remaining := p.policy.OpenDuration - p.now().Sub(p.openedAt)
+		if remaining <= 0 {
+			p.setState(circuitHalfOpen)
+			return nil
+		}
+		return spiceerrors.WithCodeAndDetailsAsError(
+			errors.New("datastore is unavailable: its connections are failing"),
+			codes.Unavailable,
+			spiceerrors.ForRetry(remaining),
+		)
+
+	case circuitHalfOpen:
+		return spiceerrors.WithCodeAndDetailsAsError(
+			errors.New("datastore is unavailable: waiting for it to recover"),
+			codes.Unavailable,
+			spiceerrors.ForRetry(p.policy.OpenDuration),
+		)
+
+	default:
+		return nil
+	}
+}
+
+// record updates the state of the circuit with the result of an operation.
+func (p *circuitBreakingProxy) record(ctx context.Context, err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	switch {
+	case err != nil && (errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)):
+		// The operation says nothing about the datastore; let another operation probe it.
+		if p.state == circuitHalfOpen {
+			p.setState(circuitOpen)
+		}
+
+	case err != nil && isConnectionFailure(err):
+		p.failures++
+		if p.state == circuitHalfOpen || p.failures >= p.policy.FailureThreshold {
+			if p.state == circuitClosed {
+				log.Ctx(ctx).Warn().Err(err).Uint16("failures", p.failures).Msg("datastore connections are failing; opening circuit breaker")
+			}
+			p.openedAt = p.now()
+			p.setState(circuitOpen)
+		}
+
+	default:
+		p.failures = 0
+		if p.state != circuitClosed {
+			log.Ctx(ctx).Info().Msg("datastore has recovered; closing circuit breaker")
+			p.setState(circuitClosed)
+		}
+	}
+}
+
+func (p *circuitBreakingProxy) setState(state circuitState) {
+	p.state = state
+	circuitBreakerStateGauge.Set(float64(state))
+}
+
+// isConnectionFailure returns whether the error indicates that the datastore could not be
+// reached, rather than that the operation itself failed.
+func isConnectionFailure(err error) bool {
+	if errors.Is(err, driver.ErrBadConn) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	if s, ok := status.FromError(err); ok {
+		return s.Code() == codes.Unavailable
+	}
+	return false
+}
@@ -0,0 +1,146 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/datastore/proxy/proxy_test"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+)
+
+var (
+	errConnection            = status.Error(codes.Unavailable, "connection refused")
+	testCircuitBreakerPolicy = CircuitBreakerPolicy{
+		FailureThreshold: 3,
+		OpenDuration:     10 * time.Second,
+	}
+)
+
+// newTestCircuitBreakingProxy creates a circuit breaking proxy whose clock is advanced by the
+// test, through the returned pointer.
+func newTestCircuitBreakingProxy(t *testing.T, delegate datastore.Datastore, policy CircuitBreakerPolicy) (*circuitBreakingProxy, *time.Time) {
+	ds, err := NewCircuitBreakingDatastoreProxy(delegate, policy)
+	require.NoError(t, err)
+
+	proxy := ds.(*circuitBreakingProxy)
+	now := time.Now()
+	proxy.now = func() time.Time { return now }
+	return proxy, &now
+}
+
+func TestNewCircuitBreakingDatastoreProxy(t *testing.T) {
+	_, err := NewCircuitBreakingDatastoreProxy(&proxy_test.MockDatastore{}, CircuitBreakerPolicy{OpenDuration: time.Second})
+	require.Error(t, err)
+
+	_, err = NewCircuitBreakingDatastoreProxy(&proxy_test.MockDatastore{}, CircuitBreakerPolicy{FailureThreshold: 1})
+	require.Error(t, err)
+}
+
+func TestCircuitBreakerOpensAndRecovers(t *testing.T) {
+	delegate := &proxy_test.MockDatastore{}
+	delegate.On("HeadRevision").Return(datastore.NoRevision, errConnection).Times(3)
+
+	ds, now := newTestCircuitBreakingProxy(t, delegate, testCircuitBreakerPolicy)
+	before := testutil.ToFloat64(circuitBreakerRejectedCounter.WithLabelValues("HeadRevision"))
+
+	for i := 0; i < 3; i++ {
+		_, err := ds.HeadRevision(context.Background())
+		require.ErrorIs(t, err, errConnection)
+	}
+
+	// Once open, operations fail fast without reaching the datastore.
+	_, err := ds.HeadRevision(context.Background())
+	require.Equal(t, codes.Unavailable, status.Code(err))
+	require.NotErrorIs(t, err, errConnection)
+	require.Equal(t, before+1, testutil.ToFloat64(circuitBreakerRejectedCounter.WithLabelValues("HeadRevision")))
+
+	state, err := ds.ReadyState(context.Background())
+	require.NoError(t, err)
+	require.False(t, state.IsReady)
+
+	// After the open duration, a failing probe opens the circuit again.
+	*now = now.Add(testCircuitBreakerPolicy.OpenDuration)
+	delegate.On("HeadRevision").Return(datastore.NoRevision, errConnection).Once()
+	_, err = ds.HeadRevision(context.Background())
+	require.ErrorIs(t, err, errConnection)
+	require.Equal(t, circuitOpen, ds.state)
+
+	// A successful probe closes it.
+	*now = now.Add(testCircuitBreakerPolicy.OpenDuration)
+	delegate.On("HeadRevision").Return(expectedRevision, nil).Once()
+	rev, err := ds.HeadRevision(context.Background())
+	require.NoError(t, err)
+	require.True(t, expectedRevision.Equal(rev))
+	require.Equal(t, circuitClosed, ds.state)
+	delegate.AssertExpectations(t)
+}
+
+func TestCircuitBreakerIgnoresOtherErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		err  error
+	}{
+		{"serialization", errSerialization},
+		{"other error", errors.New("invalid relationship")},
+		{"canceled", context.Canceled},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			delegate := &proxy_test.MockDatastore{}
+			delegate.On("ReadWriteTx", []options.RWTOptionsOption(nil)).Return(&proxy_test.MockReadWriteTransaction{}, datastore.NoRevision, tc.err)
+
+			ds, _ := newTestCircuitBreakingProxy(t, delegate, testCircuitBreakerPolicy)
+			for i := 0; i < 5; i++ {
+				_, err := ds.ReadWriteTx(context.Background(), noopTx)
+				require.ErrorIs(t, err, tc.err)
+			}
+			require.Equal(t, circuitClosed, ds.state)
+		})
+	}
+}
+
+func TestCircuitBreakerServesStaleReads(t *testing.T) {
+	policy := testCircuitBreakerPolicy
+	policy.FailureThreshold = 1
+	policy.ServeStaleReads = true
+
+	delegate := &proxy_test.MockDatastore{}
+	delegate.On("OptimizedRevision").Return(expectedRevision, nil).Once()
+	delegate.On("OptimizedRevision").Return(datastore.NoRevision, errConnection).Once()
+
+	reader := &proxy_test.MockReader{}
+	delegate.On("SnapshotReader", expectedRevision).Return(reader)
+
+	ds, _ := newTestCircuitBreakingProxy(t, delegate, policy)
+
+	rev, err := ds.OptimizedRevision(context.Background())
+	require.NoError(t, err)
+	require.True(t, expectedRevision.Equal(rev))
+
+	// The failure opens the circuit, and the last known revision is served instead.
+	rev, err = ds.OptimizedRevision(context.Background())
+	require.NoError(t, err)
+	require.True(t, expectedRevision.Equal(rev))
+
+	rev, err = ds.OptimizedRevision(context.Background())
+	require.NoError(t, err)
+	require.True(t, expectedRevision.Equal(rev))
+
+	state, err := ds.ReadyState(context.Background())
+	require.NoError(t, err)
+	require.True(t, state.IsReady)
+	require.Contains(t, state.Message, "degraded")
+
+	// Reads which miss the caches still fail fast.
+	_, err = ds.SnapshotReader(rev).QueryRelationships(context.Background(), datastore.RelationshipsFilter{ResourceType: "document"})
+	require.Equal(t, codes.Unavailable, status.Code(err))
+	delegate.AssertExpectations(t)
+	reader.AssertExpectations(t)
+}
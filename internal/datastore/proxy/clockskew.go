@@ -0,0 +1,120 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+)
+
+var clockSkewGauge = promauto.NewGauge(prometheus.GaugeOpts{
+	Namespace: "spicedb",
+	Subsystem: "datastore",
+	Name:      "clock_skew_seconds",
+	Help:      "Difference between the clock of the datastore and that of the server when last measured, positive when the datastore is ahead",
+})
+
+var clockSkewRejectedCounter = promauto.NewCounter(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "datastore",
+	Name:      "clock_skew_rejected_writes_total",
+	Help:      "Count of the writes rejected because the clocks of the server and datastore differed by more than the maximum skew",
+})
+
+// DatastoreClock is implemented by datastores which can report the current time of the
+// database.
+type DatastoreClock interface {
+	Now(ctx context.Context) (time.Time, error)
+}
+
+// NewClockSkewGuardingDatastoreProxy creates a proxy which measures the skew between the clock
+// of the server and that of the datastore, at most once per interval, and rejects writes while
+// it exceeds the maximum. The revisions of such datastores are quantized and garbage collected by
+// the clock of the datastore, while the server computes their validity and expiration by its
+// own, so a large skew can order cached and written revisions inconsistently.
+func NewClockSkewGuardingDatastoreProxy(d datastore.Datastore, maxSkew, interval time.Duration) (datastore.Datastore, error) {
+	if maxSkew <= 0 {
+		return nil, errors.New("invalid maximum clock skew: must be positive")
+	}
+	if interval <= 0 {
+		return nil, errors.New("invalid clock skew measurement interval: must be positive")
+	}
+
+	clock := datastore.UnwrapAs[DatastoreClock](d)
+	if clock == nil {
+		return nil, errors.New("datastore does not report its clock")
+	}
+
+	return &clockSkewGuardingProxy{
+		Datastore: d,
+		clock:     clock,
+		maxSkew:   maxSkew,
+		interval:  interval,
+		now:       time.Now,
+	}, nil
+}
+
+type clockSkewGuardingProxy struct {
+	datastore.Datastore
+	clock    DatastoreClock
+	maxSkew  time.Duration
+	interval time.Duration
+
+	mu         sync.Mutex
+	measuredAt time.Time
+	skew       time.Duration
+
+	now func() time.Time
+}
+
+func (p *clockSkewGuardingProxy) ReadWriteTx(ctx context.Context, f datastore.TxUserFunc, opts ...options.RWTOptionsOption) (datastore.Revision, error) {
+	skew, err := p.measureSkew(ctx)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("could not measure the clock skew of the datastore; using the last measurement")
+	}
+
+	if skew.Abs() > p.maxSkew {
+		clockSkewRejectedCounter.Inc()
+		return datastore.NoRevision, datastore.NewClockSkewErr("the clock of the server differs from that of the datastore", skew.Abs(), p.maxSkew)
+	}
+	return p.Datastore.ReadWriteTx(ctx, f, opts...)
+}
+
+// measureSkew returns the skew of the clock of the datastore, measuring it if the last
+// measurement is older than the interval. If it cannot be measured, the last measurement is
+// returned along with the error, and it is not measured again until the interval has passed.
+func (p *clockSkewGuardingProxy) measureSkew(ctx context.Context) (time.Duration, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.measuredAt.IsZero() && p.now().Sub(p.measuredAt) < p.interval {
+		return p.skew, nil
+	}
+
+	sent := p.now()
+	datastoreNow, err := p.clock.Now(ctx)
+	received := p.now()
+	p.measuredAt = received
+	if err != nil {
+		return p.skew, err
+	}
+
+	// The clock of the datastore is compared with the midpoint of the round trip.
+	p.skew = datastoreNow.Sub(sent.Add(received.Sub(sent) / 2))
+	clockSkewGauge.Set(p.skew.Seconds())
+	if p.skew.Abs() > p.maxSkew {
+		log.Ctx(ctx).Warn().Stringer("skew", p.skew).Stringer("maxSkew", p.maxSkew).Msg("the clocks of the server and datastore differ by more than the maximum skew; rejecting writes until they are synchronized")
+	}
+	return p.skew, nil
+}
+
+func (p *clockSkewGuardingProxy) Unwrap() datastore.Datastore {
+	return p.Datastore
+}
@@ -0,0 +1,79 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/proxy/proxy_test"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+)
+
+// clockDatastore is a mock datastore whose clock is ahead of that of the server by the skew.
+type clockDatastore struct {
+	*proxy_test.MockDatastore
+	now  *time.Time
+	skew time.Duration
+	err  error
+}
+
+func (cd *clockDatastore) Now(_ context.Context) (time.Time, error) {
+	return cd.now.Add(cd.skew), cd.err
+}
+
+func TestNewClockSkewGuardingDatastoreProxy(t *testing.T) {
+	_, err := NewClockSkewGuardingDatastoreProxy(&clockDatastore{MockDatastore: &proxy_test.MockDatastore{}}, 0, time.Second)
+	require.Error(t, err)
+
+	_, err = NewClockSkewGuardingDatastoreProxy(&clockDatastore{MockDatastore: &proxy_test.MockDatastore{}}, time.Second, 0)
+	require.Error(t, err)
+
+	_, err = NewClockSkewGuardingDatastoreProxy(&proxy_test.MockDatastore{}, time.Second, time.Second)
+	require.Error(t, err)
+}
+
+func TestClockSkewGuardRejectsWrites(t *testing.T) {
+	now := time.Now()
+	delegate := &clockDatastore{MockDatastore: &proxy_test.MockDatastore{}, now: &now}
+	delegate.On("ReadWriteTx", []options.RWTOptionsOption(nil)).Return(&proxy_test.MockReadWriteTransaction{}, expectedRevision, nil)
+
+	ds, err := NewClockSkewGuardingDatastoreProxy(delegate, time.Second, time.Hour)
+	require.NoError(t, err)
+	proxy := ds.(*clockSkewGuardingProxy)
+	proxy.now = func() time.Time { return now }
+
+	rev, err := ds.ReadWriteTx(context.Background(), noopTx)
+	require.NoError(t, err)
+	require.True(t, expectedRevision.Equal(rev))
+
+	// The skew is only measured again once the interval has passed.
+	delegate.skew = time.Minute
+	_, err = ds.ReadWriteTx(context.Background(), noopTx)
+	require.NoError(t, err)
+
+	now = now.Add(time.Hour)
+	before := testutil.ToFloat64(clockSkewRejectedCounter)
+	_, err = ds.ReadWriteTx(context.Background(), noopTx)
+	var skewErr datastore.ErrClockSkew
+	require.ErrorAs(t, err, &skewErr)
+	require.Equal(t, before+1, testutil.ToFloat64(clockSkewRejectedCounter))
+	require.Equal(t, time.Minute.Seconds(), testutil.ToFloat64(clockSkewGauge))
+
+	// If the clock cannot be read, the last measurement is used.
+	now = now.Add(time.Hour)
+	delegate.skew = 0
+	delegate.err = errors.New("connection refused")
+	_, err = ds.ReadWriteTx(context.Background(), noopTx)
+	require.ErrorAs(t, err, &skewErr)
+
+	now = now.Add(time.Hour)
+	delegate.err = nil
+	_, err = ds.ReadWriteTx(context.Background(), noopTx)
+	require.NoError(t, err)
+	delegate.AssertNumberOfCalls(t, "ReadWriteTx", 3)
+}
@@ -2,6 +2,7 @@ package proxy
 
 import (
 	"context"
+	"time"
 
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/prometheus/client_golang/prometheus"
@@ -11,6 +12,7 @@ import (
 	"go.opentelemetry.io/otel/trace"
 
 	"github.com/authzed/spicedb/internal/datastore/common"
+	log "github.com/authzed/spicedb/internal/logging"
 	"github.com/authzed/spicedb/pkg/datastore"
 	"github.com/authzed/spicedb/pkg/datastore/options"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
@@ -35,6 +37,7 @@ var (
 		Help:      "response latency for a database query",
 	}, []string{
 		"operation",
+		"engine",
 	})
 )
 
@@ -60,16 +63,21 @@ func filterToAttributes(filter *v1.RelationshipFilter) []attribute.KeyValue {
 }
 
 // NewObservableDatastoreProxy creates a new datastore proxy which adds tracing
-// and metrics to the datastore.
-func NewObservableDatastoreProxy(d datastore.Datastore) datastore.Datastore {
-	return &observableProxy{delegate: d}
+// and metrics, labeled with the datastore engine, to the datastore. Operations
+// taking longer than the slow query threshold are logged; a threshold of zero
+// disables such logging.
+func NewObservableDatastoreProxy(d datastore.Datastore, engine string, slowQueryThreshold time.Duration) datastore.Datastore {
+	return &observableProxy{delegate: d, observer: &observer{engine, slowQueryThreshold}}
 }
 
-type observableProxy struct{ delegate datastore.Datastore }
+type observableProxy struct {
+	delegate datastore.Datastore
+	observer *observer
+}
 
 func (p *observableProxy) SnapshotReader(rev datastore.Revision) datastore.Reader {
 	delegateReader := p.delegate.SnapshotReader(rev)
-	return &observableReader{delegateReader}
+	return &observableReader{delegateReader, p.observer}
 }
 
 func (p *observableProxy) ReadWriteTx(
@@ -78,19 +86,19 @@ func (p *observableProxy) ReadWriteTx(
 	opts ...options.RWTOptionsOption,
 ) (datastore.Revision, error) {
 	return p.delegate.ReadWriteTx(ctx, func(ctx context.Context, delegateRWT datastore.ReadWriteTransaction) error {
-		return f(ctx, &observableRWT{&observableReader{delegateRWT}, delegateRWT})
+		return f(ctx, &observableRWT{&observableReader{delegateRWT, p.observer}, delegateRWT})
 	}, opts...)
 }
 
 func (p *observableProxy) OptimizedRevision(ctx context.Context) (datastore.Revision, error) {
-	ctx, closer := observe(ctx, "OptimizedRevision")
+	ctx, closer := p.observer.observe(ctx, "OptimizedRevision")
 	defer closer()
 
 	return p.delegate.OptimizedRevision(ctx)
 }
 
 func (p *observableProxy) CheckRevision(ctx context.Context, revision datastore.Revision) error {
-	ctx, closer := observe(ctx, "CheckRevision", trace.WithAttributes(
+	ctx, closer := p.observer.observe(ctx, "CheckRevision", trace.WithAttributes(
 		attribute.String("revision", revision.String()),
 	))
 	defer closer()
@@ -99,7 +107,7 @@ func (p *observableProxy) CheckRevision(ctx context.Context, revision datastore.
 }
 
 func (p *observableProxy) HeadRevision(ctx context.Context) (datastore.Revision, error) {
-	ctx, closer := observe(ctx, "HeadRevision")
+	ctx, closer := p.observer.observe(ctx, "HeadRevision")
 	defer closer()
 
 	return p.delegate.HeadRevision(ctx)
@@ -114,14 +122,14 @@ func (p *observableProxy) Watch(ctx context.Context, afterRevision datastore.Rev
 }
 
 func (p *observableProxy) Features(ctx context.Context) (*datastore.Features, error) {
-	ctx, closer := observe(ctx, "Features")
+	ctx, closer := p.observer.observe(ctx, "Features")
 	defer closer()
 
 	return p.delegate.Features(ctx)
 }
 
 func (p *observableProxy) Statistics(ctx context.Context) (datastore.Stats, error) {
-	ctx, closer := observe(ctx, "Statistics")
+	ctx, closer := p.observer.observe(ctx, "Statistics")
 	defer closer()
 
 	return p.delegate.Statistics(ctx)
@@ -132,7 +140,7 @@ func (p *observableProxy) Unwrap() datastore.Datastore {
 }
 
 func (p *observableProxy) ReadyState(ctx context.Context) (datastore.ReadyState, error) {
-	ctx, closer := observe(ctx, "ReadyState")
+	ctx, closer := p.observer.observe(ctx, "ReadyState")
 	defer closer()
 
 	return p.delegate.ReadyState(ctx)
@@ -140,10 +148,13 @@ func (p *observableProxy) ReadyState(ctx context.Context) (datastore.ReadyState,
 
 func (p *observableProxy) Close() error { return p.delegate.Close() }
 
-type observableReader struct{ delegate datastore.Reader }
+type observableReader struct {
+	delegate datastore.Reader
+	observer *observer
+}
 
 func (r *observableReader) ReadCaveatByName(ctx context.Context, name string) (*core.CaveatDefinition, datastore.Revision, error) {
-	ctx, closer := observe(ctx, "ReadCaveatByName", trace.WithAttributes(
+	ctx, closer := r.observer.observe(ctx, "ReadCaveatByName", trace.WithAttributes(
 		attribute.String("name", name),
 	))
 	defer closer()
@@ -152,7 +163,7 @@ func (r *observableReader) ReadCaveatByName(ctx context.Context, name string) (*
 }
 
 func (r *observableReader) LookupCaveatsWithNames(ctx context.Context, caveatNames []string) ([]datastore.RevisionedCaveat, error) {
-	ctx, closer := observe(ctx, "LookupCaveatsWithNames", trace.WithAttributes(
+	ctx, closer := r.observer.observe(ctx, "LookupCaveatsWithNames", trace.WithAttributes(
 		attribute.StringSlice("names", caveatNames),
 	))
 	defer closer()
@@ -161,21 +172,21 @@ func (r *observableReader) LookupCaveatsWithNames(ctx context.Context, caveatNam
 }
 
 func (r *observableReader) ListAllCaveats(ctx context.Context) ([]datastore.RevisionedCaveat, error) {
-	ctx, closer := observe(ctx, "ListAllCaveats")
+	ctx, closer := r.observer.observe(ctx, "ListAllCaveats")
 	defer closer()
 
 	return r.delegate.ListAllCaveats(ctx)
 }
 
 func (r *observableReader) ListAllNamespaces(ctx context.Context) ([]datastore.RevisionedNamespace, error) {
-	ctx, closer := observe(ctx, "ListAllNamespaces")
+	ctx, closer := r.observer.observe(ctx, "ListAllNamespaces")
 	defer closer()
 
 	return r.delegate.ListAllNamespaces(ctx)
 }
 
 func (r *observableReader) LookupNamespacesWithNames(ctx context.Context, nsNames []string) ([]datastore.RevisionedNamespace, error) {
-	ctx, closer := observe(ctx, "LookupNamespacesWithNames", trace.WithAttributes(
+	ctx, closer := r.observer.observe(ctx, "LookupNamespacesWithNames", trace.WithAttributes(
 		attribute.StringSlice("names", nsNames),
 	))
 	defer closer()
@@ -184,7 +195,7 @@ func (r *observableReader) LookupNamespacesWithNames(ctx context.Context, nsName
 }
 
 func (r *observableReader) ReadNamespaceByName(ctx context.Context, nsName string) (*core.NamespaceDefinition, datastore.Revision, error) {
-	ctx, closer := observe(ctx, "ReadNamespaceByName", trace.WithAttributes(
+	ctx, closer := r.observer.observe(ctx, "ReadNamespaceByName", trace.WithAttributes(
 		attribute.String("name", nsName),
 	))
 	defer closer()
@@ -193,9 +204,10 @@ func (r *observableReader) ReadNamespaceByName(ctx context.Context, nsName strin
 }
 
 func (r *observableReader) QueryRelationships(ctx context.Context, filter datastore.RelationshipsFilter, options ...options.QueryOptionsOption) (datastore.RelationshipIterator, error) {
-	ctx, closer := observe(ctx, "QueryRelationships", trace.WithAttributes(
+	ctx, closer := r.observer.observe(ctx, "QueryRelationships", trace.WithAttributes(
 		attribute.String("resourceType", filter.ResourceType),
 		attribute.String("resourceRelation", filter.OptionalResourceRelation),
+		attribute.String("resourceIDPrefix", filter.OptionalResourceIDPrefix),
 		attribute.String("caveatName", filter.OptionalCaveatName),
 	))
 
@@ -231,7 +243,7 @@ func (i *observableRelationshipIterator) Close() {
 }
 
 func (r *observableReader) ReverseQueryRelationships(ctx context.Context, subjectFilter datastore.SubjectsFilter, options ...options.ReverseQueryOptionsOption) (datastore.RelationshipIterator, error) {
-	ctx, closer := observe(ctx, "ReverseQueryRelationships")
+	ctx, closer := r.observer.observe(ctx, "ReverseQueryRelationships")
 	iterator, err := r.delegate.ReverseQueryRelationships(ctx, subjectFilter, options...)
 	if err != nil {
 		return iterator, err
@@ -250,7 +262,7 @@ func (rwt *observableRWT) WriteCaveats(ctx context.Context, caveats []*core.Cave
 		caveatNames = append(caveatNames, caveat.Name)
 	}
 
-	ctx, closer := observe(ctx, "WriteCaveats", trace.WithAttributes(
+	ctx, closer := rwt.observer.observe(ctx, "WriteCaveats", trace.WithAttributes(
 		attribute.StringSlice("names", caveatNames),
 	))
 	defer closer()
@@ -259,7 +271,7 @@ func (rwt *observableRWT) WriteCaveats(ctx context.Context, caveats []*core.Cave
 }
 
 func (rwt *observableRWT) DeleteCaveats(ctx context.Context, names []string) error {
-	ctx, closer := observe(ctx, "DeleteCaveats", trace.WithAttributes(
+	ctx, closer := rwt.observer.observe(ctx, "DeleteCaveats", trace.WithAttributes(
 		attribute.StringSlice("names", names),
 	))
 	defer closer()
@@ -268,7 +280,7 @@ func (rwt *observableRWT) DeleteCaveats(ctx context.Context, names []string) err
 }
 
 func (rwt *observableRWT) WriteRelationships(ctx context.Context, mutations []*core.RelationTupleUpdate) error {
-	ctx, closer := observe(ctx, "WriteRelationships", trace.WithAttributes(
+	ctx, closer := rwt.observer.observe(ctx, "WriteRelationships", trace.WithAttributes(
 		attribute.Int("mutations", len(mutations)),
 	))
 	defer closer()
@@ -282,7 +294,7 @@ func (rwt *observableRWT) WriteNamespaces(ctx context.Context, newConfigs ...*co
 		nsNames = append(nsNames, ns.Name)
 	}
 
-	ctx, closer := observe(ctx, "WriteNamespaces", trace.WithAttributes(
+	ctx, closer := rwt.observer.observe(ctx, "WriteNamespaces", trace.WithAttributes(
 		attribute.StringSlice("names", nsNames),
 	))
 	defer closer()
@@ -291,7 +303,7 @@ func (rwt *observableRWT) WriteNamespaces(ctx context.Context, newConfigs ...*co
 }
 
 func (rwt *observableRWT) DeleteNamespaces(ctx context.Context, nsNames ...string) error {
-	ctx, closer := observe(ctx, "DeleteNamespaces", trace.WithAttributes(
+	ctx, closer := rwt.observer.observe(ctx, "DeleteNamespaces", trace.WithAttributes(
 		attribute.StringSlice("names", nsNames),
 	))
 	defer closer()
@@ -300,24 +312,29 @@ func (rwt *observableRWT) DeleteNamespaces(ctx context.Context, nsNames ...strin
 }
 
 func (rwt *observableRWT) DeleteRelationships(ctx context.Context, filter *v1.RelationshipFilter, options ...options.DeleteOptionsOption) (bool, error) {
-	ctx, closer := observe(ctx, "DeleteRelationships", trace.WithAttributes(
+	ctx, closer := rwt.observer.observe(ctx, "DeleteRelationships", trace.WithAttributes(
 		filterToAttributes(filter)...,
 	))
 	defer closer()
 
-	return rwt.delegate.DeleteRelationships(ctx, filter)
+	return rwt.delegate.DeleteRelationships(ctx, filter, options...)
 }
 
 func (rwt *observableRWT) BulkLoad(ctx context.Context, iter datastore.BulkWriteRelationshipSource) (uint64, error) {
-	ctx, closer := observe(ctx, "BulkLoad")
+	ctx, closer := rwt.observer.observe(ctx, "BulkLoad")
 	defer closer()
 
 	return rwt.delegate.BulkLoad(ctx, iter)
 }
 
-func observe(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, func()) {
+type observer struct {
+	engine             string
+	slowQueryThreshold time.Duration
+}
+
+func (o *observer) observe(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, func()) {
 	ctx, span := tracer.Start(ctx, name, opts...)
-	timer := prometheus.NewTimer(queryLatency.WithLabelValues(name))
+	timer := prometheus.NewTimer(queryLatency.WithLabelValues(name, o.engine))
 	closed := false
 
 	return ctx, func() {
@@ -326,8 +343,21 @@ func observe(ctx context.Context, name string, opts ...trace.SpanStartOption) (c
 		}
 
 		closed = true
-		timer.ObserveDuration()
+		duration := timer.ObserveDuration()
 		span.End()
+
+		if o.slowQueryThreshold > 0 && duration > o.slowQueryThreshold {
+			event := log.Ctx(ctx).Warn().
+				Str("operation", name).
+				Str("engine", o.engine).
+				Dur("duration", duration).
+				Dur("threshold", o.slowQueryThreshold)
+			config := trace.NewSpanStartConfig(opts...)
+			for _, attr := range config.Attributes() {
+				event = event.Str(string(attr.Key), attr.Value.Emit())
+			}
+			event.Msg("slow datastore query")
+		}
 	}
 }
 
@@ -2,6 +2,7 @@ package proxy_test
 
 import (
 	"context"
+	"time"
 
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/stretchr/testify/mock"
@@ -50,6 +51,16 @@ func (dm *MockDatastore) CheckRevision(_ context.Context, revision datastore.Rev
 	return args.Error(0)
 }
 
+func (dm *MockDatastore) RevisionAtTime(_ context.Context, at time.Time) (datastore.Revision, error) {
+	args := dm.Called(at)
+	return args.Get(0).(datastore.Revision), args.Error(1)
+}
+
+func (dm *MockDatastore) EarliestRevision(_ context.Context) (datastore.Revision, error) {
+	args := dm.Called()
+	return args.Get(0).(datastore.Revision), args.Error(1)
+}
+
 func (dm *MockDatastore) RevisionFromString(s string) (datastore.Revision, error) {
 	args := dm.Called(s)
 	return args.Get(0).(datastore.Revision), args.Error(1)
@@ -2,6 +2,7 @@ package proxy
 
 import (
 	"context"
+	"time"
 
 	"github.com/authzed/spicedb/pkg/datastore"
 	"github.com/authzed/spicedb/pkg/datastore/options"
@@ -19,6 +20,24 @@ func NewReadonlyDatastore(delegate datastore.Datastore) datastore.Datastore {
 	return roDatastore{Datastore: delegate}
 }
 
+// RevisionAtTime and EarliestRevision are implemented by the proxy, rather than by unwrapping it,
+// so that the delegate is never exposed to callers which could write to it.
+func (rd roDatastore) RevisionAtTime(ctx context.Context, at time.Time) (datastore.Revision, error) {
+	pit := datastore.UnwrapAs[datastore.PointInTimeDatastore](rd.Datastore)
+	if pit == nil {
+		return datastore.NoRevision, datastore.NewPointInTimeUnsupportedErr()
+	}
+	return pit.RevisionAtTime(ctx, at)
+}
+
+func (rd roDatastore) EarliestRevision(ctx context.Context) (datastore.Revision, error) {
+	pit := datastore.UnwrapAs[datastore.PointInTimeDatastore](rd.Datastore)
+	if pit == nil {
+		return datastore.NoRevision, datastore.NewPointInTimeUnsupportedErr()
+	}
+	return pit.EarliestRevision(ctx)
+}
+
 func (rd roDatastore) ReadWriteTx(
 	context.Context,
 	datastore.TxUserFunc,
@@ -0,0 +1,326 @@
+package proxy
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const (
+	integrityFailureMissing     = "missing"
+	integrityFailureUnknownKey  = "unknown_key"
+	integrityFailureExpiredKey  = "expired_key"
+	integrityFailureInvalidHash = "invalid_hash"
+)
+
+var integrityFailureCount = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "datastore",
+	Name:      "relationship_integrity_failures_total",
+	Help:      "total number of relationships read from the datastore which failed integrity verification",
+}, []string{"reason"})
+
+// KeyConfig is a key with which the integrity of relationships is computed and verified.
+type KeyConfig struct {
+	// ID identifies the key in the integrity data of the relationships hashed with it, and so
+	// must never be reused for another key.
+	ID string
+
+	// Bytes is the secret of the key.
+	Bytes []byte
+
+	// ExpiredAt is the time at which the key was replaced as the current key, if it has been.
+	// Relationships hashed with an expired key remain valid only if they were hashed before it
+	// expired.
+	ExpiredAt *time.Time
+}
+
+// NewRelationshipIntegrityProxy creates a proxy which stores an HMAC of each relationship written,
+// computed with the current key, alongside the relationship, and verifies it for each relationship
+// read, so that relationships written or modified in the datastore other than by SpiceDB are
+// reported as ErrRelationshipIntegrity errors rather than used. Keys which have been replaced
+// must be given as expired keys until every relationship hashed with them has been rewritten.
+//
+// Watch is not verified, and the datastore must support storing integrity data.
+func NewRelationshipIntegrityProxy(ctx context.Context, ds datastore.Datastore, currentKey KeyConfig, expiredKeys []KeyConfig) (datastore.Datastore, error) {
+	if currentKey.ID == "" {
+		return nil, errors.New("the current relationship integrity key must have an ID")
+	}
+	if len(currentKey.Bytes) == 0 {
+		return nil, fmt.Errorf("relationship integrity key `%s` is empty", currentKey.ID)
+	}
+	if currentKey.ExpiredAt != nil {
+		return nil, fmt.Errorf("the current relationship integrity key `%s` cannot be expired", currentKey.ID)
+	}
+
+	keysByID := map[string]KeyConfig{currentKey.ID: currentKey}
+	for _, key := range expiredKeys {
+		if key.ID == "" {
+			return nil, errors.New("expired relationship integrity keys must have an ID")
+		}
+		if _, ok := keysByID[key.ID]; ok {
+			return nil, fmt.Errorf("duplicate relationship integrity key ID `%s`", key.ID)
+		}
+		if len(key.Bytes) == 0 {
+			return nil, fmt.Errorf("relationship integrity key `%s` is empty", key.ID)
+		}
+		if key.ExpiredAt == nil {
+			return nil, fmt.Errorf("expired relationship integrity key `%s` must have an expiration time", key.ID)
+		}
+		keysByID[key.ID] = key
+	}
+
+	features, err := ds.Features(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("unable to determine datastore features: %w", err)
+	}
+	if !features.IntegrityData.Enabled {
+		return nil, errors.New("the datastore does not support relationship integrity")
+	}
+
+	return &relationshipIntegrityProxy{
+		Datastore:  ds,
+		currentKey: currentKey,
+		keysByID:   keysByID,
+		now:        time.Now,
+	}, nil
+}
+
+type relationshipIntegrityProxy struct {
+	datastore.Datastore
+	currentKey KeyConfig
+	keysByID   map[string]KeyConfig
+	now        func() time.Time
+}
+
+func (p *relationshipIntegrityProxy) SnapshotReader(rev datastore.Revision) datastore.Reader {
+	return &integrityReader{p.Datastore.SnapshotReader(rev), p}
+}
+
+func (p *relationshipIntegrityProxy) ReadWriteTx(
+	ctx context.Context,
+	f datastore.TxUserFunc,
+	opts ...options.RWTOptionsOption,
+) (datastore.Revision, error) {
+	return p.Datastore.ReadWriteTx(ctx, func(ctx context.Context, delegateRWT datastore.ReadWriteTransaction) error {
+		return f(ctx, &integrityRWT{&integrityReader{delegateRWT, p}, delegateRWT})
+	}, opts...)
+}
+
+func (p *relationshipIntegrityProxy) Unwrap() datastore.Datastore {
+	return p.Datastore
+}
+
+// withIntegrity returns a copy of the relationship with its integrity computed with the current key.
+func (p *relationshipIntegrityProxy) withIntegrity(tpl *core.RelationTuple) (*core.RelationTuple, error) {
+	hash, err := computeIntegrityHash(p.currentKey, tpl)
+	if err != nil {
+		return nil, err
+	}
+
+	hashed := tpl.CloneVT()
+	hashed.Integrity = &core.RelationshipIntegrity{
+		KeyId:    p.currentKey.ID,
+		Hash:     hash,
+		HashedAt: timestamppb.New(p.now()),
+	}
+	return hashed, nil
+}
+
+// verify returns the reason the relationship fails integrity verification, if it does.
+func (p *relationshipIntegrityProxy) verify(tpl *core.RelationTuple) (string, error) {
+	if tpl.Integrity == nil {
+		return integrityFailureMissing, nil
+	}
+
+	key, ok := p.keysByID[tpl.Integrity.KeyId]
+	if !ok {
+		return integrityFailureUnknownKey, nil
+	}
+
+	if key.ExpiredAt != nil && tpl.Integrity.HashedAt.AsTime().After(*key.ExpiredAt) {
+		return integrityFailureExpiredKey, nil
+	}
+
+	expected, err := computeIntegrityHash(key, tpl)
+	if err != nil {
+		return "", err
+	}
+	if !hmac.Equal(expected, tpl.Integrity.Hash) {
+		return integrityFailureInvalidHash, nil
+	}
+	return "", nil
+}
+
+// computeIntegrityHash computes the HMAC of the resource, subject and caveat of the relationship.
+// The caveat context is hashed in its deterministic binary encoding, since its JSON encoding is
+// not stable.
+func computeIntegrityHash(key KeyConfig, tpl *core.RelationTuple) ([]byte, error) {
+	mac := hmac.New(sha256.New, key.Bytes)
+	mac.Write([]byte(tuple.StringWithoutCaveat(tpl)))
+
+	if tpl.Caveat != nil && tpl.Caveat.CaveatName != "" {
+		contextBytes, err := proto.MarshalOptions{Deterministic: true}.Marshal(tpl.Caveat.Context)
+		if err != nil {
+			return nil, fmt.Errorf("unable to encode caveat context: %w", err)
+		}
+
+		mac.Write([]byte{0})
+		mac.Write([]byte(tpl.Caveat.CaveatName))
+		mac.Write([]byte{0})
+		mac.Write(contextBytes)
+	}
+
+	return mac.Sum(nil), nil
+}
+
+type integrityReader struct {
+	datastore.Reader
+	proxy *relationshipIntegrityProxy
+}
+
+func (r *integrityReader) QueryRelationships(ctx context.Context, filter datastore.RelationshipsFilter, options ...options.QueryOptionsOption) (datastore.RelationshipIterator, error) {
+	iterator, err := r.Reader.QueryRelationships(ctx, filter, options...)
+	if err != nil {
+		return iterator, err
+	}
+	return &integrityIterator{ctx: ctx, delegate: iterator, proxy: r.proxy}, nil
+}
+
+func (r *integrityReader) ReverseQueryRelationships(ctx context.Context, subjectFilter datastore.SubjectsFilter, options ...options.ReverseQueryOptionsOption) (datastore.RelationshipIterator, error) {
+	iterator, err := r.Reader.ReverseQueryRelationships(ctx, subjectFilter, options...)
+	if err != nil {
+		return iterator, err
+	}
+	return &integrityIterator{ctx: ctx, delegate: iterator, proxy: r.proxy}, nil
+}
+
+// integrityIterator verifies each relationship before returning it, ending the iteration with an
+// error at the first relationship which fails verification.
+type integrityIterator struct {
+	ctx      context.Context
+	delegate datastore.RelationshipIterator
+	proxy    *relationshipIntegrityProxy
+	err      error
+}
+
+func (i *integrityIterator) Next() *core.RelationTuple {
+	if i.err != nil {
+		return nil
+	}
+
+	next := i.delegate.Next()
+	if next == nil {
+		return nil
+	}
+
+	reason, err := i.proxy.verify(next)
+	if err != nil {
+		i.err = err
+		return nil
+	}
+	if reason != "" {
+		integrityFailureCount.WithLabelValues(reason).Inc()
+		log.Ctx(i.ctx).Error().
+			Str("relationship", tuple.StringWithoutCaveat(next)).
+			Str("reason", reason).
+			Msg("relationship failed integrity verification")
+		i.err = datastore.NewRelationshipIntegrityErr(tuple.StringWithoutCaveat(next), reason)
+		return nil
+	}
+	return next
+}
+
+func (i *integrityIterator) Err() error {
+	if i.err != nil {
+		return i.err
+	}
+	return i.delegate.Err()
+}
+
+func (i *integrityIterator) Cursor() (options.Cursor, error) { return i.delegate.Cursor() }
+
+func (i *integrityIterator) Close() { i.delegate.Close() }
+
+type integrityRWT struct {
+	*integrityReader
+	delegate datastore.ReadWriteTransaction
+}
+
+func (rwt *integrityRWT) WriteRelationships(ctx context.Context, mutations []*core.RelationTupleUpdate) error {
+	hashed := make([]*core.RelationTupleUpdate, 0, len(mutations))
+	for _, mutation := range mutations {
+		if mutation.Operation == core.RelationTupleUpdate_DELETE {
+			hashed = append(hashed, mutation)
+			continue
+		}
+
+		tpl, err := rwt.proxy.withIntegrity(mutation.Tuple)
+		if err != nil {
+			return err
+		}
+		hashed = append(hashed, &core.RelationTupleUpdate{Operation: mutation.Operation, Tuple: tpl})
+	}
+
+	return rwt.delegate.WriteRelationships(ctx, hashed)
+}
+
+func (rwt *integrityRWT) BulkLoad(ctx context.Context, iter datastore.BulkWriteRelationshipSource) (uint64, error) {
+	return rwt.delegate.BulkLoad(ctx, &integritySource{iter, rwt.proxy})
+}
+
+func (rwt *integrityRWT) WriteNamespaces(ctx context.Context, newConfigs ...*core.NamespaceDefinition) error {
+	return rwt.delegate.WriteNamespaces(ctx, newConfigs...)
+}
+
+func (rwt *integrityRWT) DeleteNamespaces(ctx context.Context, nsNames ...string) error {
+	return rwt.delegate.DeleteNamespaces(ctx, nsNames...)
+}
+
+func (rwt *integrityRWT) WriteCaveats(ctx context.Context, caveats []*core.CaveatDefinition) error {
+	return rwt.delegate.WriteCaveats(ctx, caveats)
+}
+
+func (rwt *integrityRWT) DeleteCaveats(ctx context.Context, names []string) error {
+	return rwt.delegate.DeleteCaveats(ctx, names)
+}
+
+func (rwt *integrityRWT) DeleteRelationships(ctx context.Context, filter *v1.RelationshipFilter, options ...options.DeleteOptionsOption) (bool, error) {
+	return rwt.delegate.DeleteRelationships(ctx, filter, options...)
+}
+
+// integritySource computes the integrity of each relationship loaded in bulk.
+type integritySource struct {
+	delegate datastore.BulkWriteRelationshipSource
+	proxy    *relationshipIntegrityProxy
+}
+
+func (s *integritySource) Next(ctx context.Context) (*core.RelationTuple, error) {
+	next, err := s.delegate.Next(ctx)
+	if err != nil || next == nil {
+		return next, err
+	}
+	return s.proxy.withIntegrity(next)
+}
+
+var (
+	_ datastore.Datastore            = (*relationshipIntegrityProxy)(nil)
+	_ datastore.Reader               = (*integrityReader)(nil)
+	_ datastore.ReadWriteTransaction = (*integrityRWT)(nil)
+	_ datastore.RelationshipIterator = (*integrityIterator)(nil)
+)
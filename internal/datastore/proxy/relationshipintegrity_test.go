@@ -0,0 +1,259 @@
+package proxy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+var (
+	integrityKey      = KeyConfig{ID: "current", Bytes: []byte("current key secret")}
+	otherIntegrityKey = KeyConfig{ID: "other", Bytes: []byte("other key secret")}
+)
+
+func newIntegrityProxy(t *testing.T, ds datastore.Datastore, currentKey KeyConfig, expiredKeys ...KeyConfig) datastore.Datastore {
+	pds, err := NewRelationshipIntegrityProxy(context.Background(), ds, currentKey, expiredKeys)
+	require.NoError(t, err)
+	return pds
+}
+
+func writeRelationships(t *testing.T, ds datastore.Datastore, op core.RelationTupleUpdate_Operation, rels ...string) datastore.Revision {
+	updates := make([]*core.RelationTupleUpdate, 0, len(rels))
+	for _, rel := range rels {
+		updates = append(updates, &core.RelationTupleUpdate{Operation: op, Tuple: tuple.MustParse(rel)})
+	}
+
+	rev, err := ds.ReadWriteTx(context.Background(), func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteRelationships(ctx, updates)
+	})
+	require.NoError(t, err)
+	return rev
+}
+
+func readRelationships(ds datastore.Datastore, rev datastore.Revision) ([]*core.RelationTuple, error) {
+	iter, err := ds.SnapshotReader(rev).QueryRelationships(context.Background(), datastore.RelationshipsFilter{ResourceType: "document"})
+	if err != nil {
+		return nil, err
+	}
+	defer iter.Close()
+
+	var rels []*core.RelationTuple
+	for rel := iter.Next(); rel != nil; rel = iter.Next() {
+		rels = append(rels, rel)
+	}
+	return rels, iter.Err()
+}
+
+func TestRelationshipIntegrity(t *testing.T) {
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+	ds := newIntegrityProxy(t, rawDS, integrityKey)
+
+	writeRelationships(t, ds, core.RelationTupleUpdate_CREATE, "document:first#viewer@user:tom")
+	rev := writeRelationships(t, ds, core.RelationTupleUpdate_TOUCH,
+		"document:first#viewer@user:tom",
+		`document:second#viewer@user:sarah[somecaveat:{"someparam": 42, "other": "value"}]`,
+	)
+
+	rels, err := readRelationships(ds, rev)
+	require.NoError(t, err)
+	require.Len(t, rels, 2)
+	for _, rel := range rels {
+		require.Equal(t, integrityKey.ID, rel.Integrity.KeyId)
+		require.NotEmpty(t, rel.Integrity.Hash)
+		require.WithinDuration(t, time.Now(), rel.Integrity.HashedAt.AsTime(), time.Minute)
+	}
+
+	iter, err := ds.SnapshotReader(rev).ReverseQueryRelationships(context.Background(), datastore.SubjectsFilter{
+		SubjectType:        "user",
+		OptionalSubjectIds: []string{"sarah"},
+	})
+	require.NoError(t, err)
+	rel := iter.Next()
+	require.NotNil(t, rel)
+	require.Equal(t, "somecaveat", rel.Caveat.CaveatName)
+	require.Nil(t, iter.Next())
+	require.NoError(t, iter.Err())
+	iter.Close()
+
+	// Relationships written other than through the proxy fail verification.
+	before := testutil.ToFloat64(integrityFailureCount.WithLabelValues(integrityFailureMissing))
+	rev = writeRelationships(t, rawDS, core.RelationTupleUpdate_CREATE, "document:third#viewer@user:fred")
+	_, err = readRelationships(ds, rev)
+	require.ErrorAs(t, err, &datastore.ErrRelationshipIntegrity{})
+	require.ErrorContains(t, err, "document:third#viewer@user:fred")
+	require.Equal(t, before+1, testutil.ToFloat64(integrityFailureCount.WithLabelValues(integrityFailureMissing)))
+}
+
+func TestRelationshipIntegrityVerification(t *testing.T) {
+	forgedKey := KeyConfig{ID: integrityKey.ID, Bytes: []byte("forged secret")}
+
+	tcs := []struct {
+		name          string
+		writeKey      KeyConfig
+		readKey       KeyConfig
+		expiredKeys   func(hashedAt time.Time) []KeyConfig
+		expectedError string
+	}{
+		{
+			name:     "current key",
+			writeKey: integrityKey,
+			readKey:  integrityKey,
+		},
+		{
+			name:          "forged hash",
+			writeKey:      forgedKey,
+			readKey:       integrityKey,
+			expectedError: integrityFailureInvalidHash,
+		},
+		{
+			name:          "unknown key",
+			writeKey:      otherIntegrityKey,
+			readKey:       integrityKey,
+			expectedError: integrityFailureUnknownKey,
+		},
+		{
+			name:     "hashed before the key expired",
+			writeKey: otherIntegrityKey,
+			readKey:  integrityKey,
+			expiredKeys: func(hashedAt time.Time) []KeyConfig {
+				expiredAt := hashedAt.Add(time.Minute)
+				return []KeyConfig{{ID: otherIntegrityKey.ID, Bytes: otherIntegrityKey.Bytes, ExpiredAt: &expiredAt}}
+			},
+		},
+		{
+			name:     "hashed after the key expired",
+			writeKey: otherIntegrityKey,
+			readKey:  integrityKey,
+			expiredKeys: func(hashedAt time.Time) []KeyConfig {
+				expiredAt := hashedAt.Add(-time.Minute)
+				return []KeyConfig{{ID: otherIntegrityKey.ID, Bytes: otherIntegrityKey.Bytes, ExpiredAt: &expiredAt}}
+			},
+			expectedError: integrityFailureExpiredKey,
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+			require.NoError(t, err)
+
+			hashedAt := time.Now()
+			rev := writeRelationships(t, newIntegrityProxy(t, rawDS, tc.writeKey), core.RelationTupleUpdate_CREATE, "document:first#viewer@user:tom")
+
+			var expiredKeys []KeyConfig
+			if tc.expiredKeys != nil {
+				expiredKeys = tc.expiredKeys(hashedAt)
+			}
+
+			before := testutil.ToFloat64(integrityFailureCount.WithLabelValues(tc.expectedError))
+			rels, err := readRelationships(newIntegrityProxy(t, rawDS, tc.readKey, expiredKeys...), rev)
+			if tc.expectedError == "" {
+				require.NoError(t, err)
+				require.Len(t, rels, 1)
+				return
+			}
+
+			require.ErrorAs(t, err, &datastore.ErrRelationshipIntegrity{})
+			require.ErrorContains(t, err, tc.expectedError)
+			require.Empty(t, rels)
+			require.Equal(t, before+1, testutil.ToFloat64(integrityFailureCount.WithLabelValues(tc.expectedError)))
+		})
+	}
+}
+
+func TestRelationshipIntegrityCaveatContext(t *testing.T) {
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+	ds := newIntegrityProxy(t, rawDS, integrityKey)
+
+	rev := writeRelationships(t, ds, core.RelationTupleUpdate_CREATE, `document:first#viewer@user:tom[somecaveat:{"someparam": 42}]`)
+
+	// Changing the caveat context without recomputing the hash is detected.
+	hashed, err := readRelationships(ds, rev)
+	require.NoError(t, err)
+	tampered := tuple.MustParse(`document:first#viewer@user:tom[somecaveat:{"someparam": 43}]`)
+	tampered.Integrity = hashed[0].Integrity
+	rev, err = rawDS.ReadWriteTx(context.Background(), func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteRelationships(ctx, []*core.RelationTupleUpdate{tuple.Touch(tampered)})
+	})
+	require.NoError(t, err)
+
+	_, err = readRelationships(ds, rev)
+	require.ErrorAs(t, err, &datastore.ErrRelationshipIntegrity{})
+	require.ErrorContains(t, err, integrityFailureInvalidHash)
+}
+
+type sliceRelationshipSource []*core.RelationTuple
+
+func (s *sliceRelationshipSource) Next(_ context.Context) (*core.RelationTuple, error) {
+	if len(*s) == 0 {
+		return nil, nil
+	}
+	next := (*s)[0]
+	*s = (*s)[1:]
+	return next, nil
+}
+
+func TestRelationshipIntegrityBulkLoad(t *testing.T) {
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+	ds := newIntegrityProxy(t, rawDS, integrityKey)
+
+	source := sliceRelationshipSource{
+		tuple.MustParse("document:first#viewer@user:tom"),
+		tuple.MustParse("document:second#viewer@user:sarah"),
+	}
+	rev, err := ds.ReadWriteTx(context.Background(), func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		loaded, err := rwt.BulkLoad(ctx, &source)
+		require.Equal(t, uint64(2), loaded)
+		return err
+	})
+	require.NoError(t, err)
+
+	rels, err := readRelationships(ds, rev)
+	require.NoError(t, err)
+	require.Len(t, rels, 2)
+}
+
+func TestRelationshipIntegrityConfig(t *testing.T) {
+	expiredAt := time.Now()
+
+	tcs := []struct {
+		name          string
+		currentKey    KeyConfig
+		expiredKeys   []KeyConfig
+		expectedError string
+	}{
+		{"valid", integrityKey, []KeyConfig{{ID: "other", Bytes: []byte("secret"), ExpiredAt: &expiredAt}}, ""},
+		{"missing current key ID", KeyConfig{Bytes: []byte("secret")}, nil, "must have an ID"},
+		{"empty current key", KeyConfig{ID: "current"}, nil, "is empty"},
+		{"expired current key", KeyConfig{ID: "current", Bytes: []byte("secret"), ExpiredAt: &expiredAt}, nil, "cannot be expired"},
+		{"duplicate key ID", integrityKey, []KeyConfig{{ID: "current", Bytes: []byte("secret"), ExpiredAt: &expiredAt}}, "duplicate"},
+		{"expired key without expiration", integrityKey, []KeyConfig{{ID: "other", Bytes: []byte("secret")}}, "must have an expiration time"},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+			require.NoError(t, err)
+
+			_, err = NewRelationshipIntegrityProxy(context.Background(), rawDS, tc.currentKey, tc.expiredKeys)
+			if tc.expectedError == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
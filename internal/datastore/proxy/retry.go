@@ -0,0 +1,210 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+)
+
+var retriesCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "datastore",
+	Name:      "retries_total",
+	Help:      "Count of the datastore operations retried after a retriable error, by operation",
+}, []string{"operation"})
+
+var retriesExhaustedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "datastore",
+	Name:      "retries_exhausted_total",
+	Help:      "Count of the datastore operations which failed with a retriable error without being retried again, by reason",
+}, []string{"reason"})
+
+const (
+	exhaustedMaxRetries = "max_retries"
+	exhaustedBudget     = "budget"
+
+	// maxRetryTokens is the number of retries which may be made in a burst of failures, before
+	// the budget only allows retries in proportion to the operations which succeed.
+	maxRetryTokens = 10
+)
+
+// RetryPolicy configures the retries of the datastore operations which fail with a retriable
+// error.
+type RetryPolicy struct {
+	// MaxRetries is the maximum number of times an operation is retried.
+	MaxRetries uint8
+
+	// InitialBackoff is the maximum delay before the first retry, which doubles on each
+	// subsequent retry, up to MaxBackoff. The actual delay is chosen at random below it.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+
+	// BudgetRatio is the number of retries allowed for each operation which succeeds, so that
+	// retries cannot overload a datastore which is failing most operations.
+	BudgetRatio float64
+}
+
+// NewRetryingDatastoreProxy creates a proxy which retries read-write transactions and revision
+// requests which fail with a serialization failure or a transient connection error, after
+// the retries of the datastore engine itself, so that they do not surface to API callers.
+func NewRetryingDatastoreProxy(d datastore.Datastore, policy RetryPolicy) (datastore.Datastore, error) {
+	if policy.InitialBackoff <= 0 || policy.MaxBackoff < policy.InitialBackoff {
+		return nil, errors.New("invalid retry backoff: initial backoff must be positive and no greater than the maximum backoff")
+	}
+	if policy.BudgetRatio < 0 {
+		return nil, errors.New("invalid retry budget ratio: must not be negative")
+	}
+
+	return &retryingProxy{
+		Datastore: d,
+		policy:    policy,
+		tokens:    maxRetryTokens,
+		sleep:     sleepWithContext,
+	}, nil
+}
+
+type retryingProxy struct {
+	datastore.Datastore
+	policy RetryPolicy
+
+	mu     sync.Mutex
+	tokens float64
+
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+func (p *retryingProxy) ReadWriteTx(ctx context.Context, f datastore.TxUserFunc, opts ...options.RWTOptionsOption) (datastore.Revision, error) {
+	if options.NewRWTOptionsWithOptions(opts...).DisableRetries {
+		return p.Datastore.ReadWriteTx(ctx, f, opts...)
+	}
+
+	return withRetries(ctx, p, "ReadWriteTx", func() (datastore.Revision, error) {
+		return p.Datastore.ReadWriteTx(ctx, f, opts...)
+	})
+}
+
+func (p *retryingProxy) OptimizedRevision(ctx context.Context) (datastore.Revision, error) {
+	return withRetries(ctx, p, "OptimizedRevision", func() (datastore.Revision, error) {
+		return p.Datastore.OptimizedRevision(ctx)
+	})
+}
+
+func (p *retryingProxy) HeadRevision(ctx context.Context) (datastore.Revision, error) {
+	return withRetries(ctx, p, "HeadRevision", func() (datastore.Revision, error) {
+		return p.Datastore.HeadRevision(ctx)
+	})
+}
+
+func (p *retryingProxy) Unwrap() datastore.Datastore {
+	return p.Datastore
+}
+
+func withRetries[T any](ctx context.Context, p *retryingProxy, operation string, fn func() (T, error)) (T, error) {
+	for retries := uint8(0); ; retries++ {
+		result, err := fn()
+		if err == nil {
+			p.succeeded()
+			return result, nil
+		}
+		if !isRetriable(err) || ctx.Err() != nil {
+			return result, err
+		}
+
+		if retries >= p.policy.MaxRetries {
+			retriesExhaustedCounter.WithLabelValues(exhaustedMaxRetries).Inc()
+			return result, err
+		}
+		if !p.takeToken() {
+			retriesExhaustedCounter.WithLabelValues(exhaustedBudget).Inc()
+			return result, err
+		}
+
+		retriesCounter.WithLabelValues(operation).Inc()
+		log.Ctx(ctx).Debug().Err(err).Str("operation", operation).Uint8("retries", retries).Msg("retrying datastore operation")
+		if sleepErr := p.sleep(ctx, p.backoff(retries)); sleepErr != nil {
+			return result, err
+		}
+	}
+}
+
+// backoff returns the delay before the given retry, with full jitter.
+func (p *retryingProxy) backoff(retries uint8) time.Duration {
+	limit := p.policy.InitialBackoff
+	for i := uint8(0); i < retries && limit < p.policy.MaxBackoff; i++ {
+		limit *= 2
+	}
+	limit = min(limit, p.policy.MaxBackoff)
+
+	// nolint:gosec
+	// G404 use of non cryptographically secure random number generator is not concern here,
+	// as this is only used to spread retries over time
+	return time.Duration(rand.Int63n(int64(limit) + 1))
+}
+
+func (p *retryingProxy) succeeded() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.tokens = min(p.tokens+p.policy.BudgetRatio, maxRetryTokens)
+}
+
+func (p *retryingProxy) takeToken() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.tokens < 1 {
+		return false
+	}
+	p.tokens--
+	return true
+}
+
+// isRetriable returns whether the error is a serialization failure or a transient connection
+// error, which may not occur again when the operation is retried.
+func isRetriable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+
+	var serializationErr common.SerializationError
+	if errors.As(err, &serializationErr) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	if s, ok := status.FromError(err); ok {
+		switch s.Code() {
+		case codes.Aborted, codes.Unavailable:
+			return true
+		}
+	}
+	return false
+}
+
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
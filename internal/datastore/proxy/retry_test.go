@@ -0,0 +1,194 @@
+package proxy
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/internal/datastore/proxy/proxy_test"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+)
+
+var (
+	errSerialization = common.NewSerializationError(errors.New("could not serialize access"))
+	testRetryPolicy  = RetryPolicy{
+		MaxRetries:     3,
+		InitialBackoff: 10 * time.Millisecond,
+		MaxBackoff:     50 * time.Millisecond,
+		BudgetRatio:    0.1,
+	}
+)
+
+// newTestRetryingProxy creates a retrying proxy which records its backoffs instead of sleeping.
+func newTestRetryingProxy(t *testing.T, delegate datastore.Datastore, policy RetryPolicy) (*retryingProxy, *[]time.Duration) {
+	ds, err := NewRetryingDatastoreProxy(delegate, policy)
+	require.NoError(t, err)
+
+	proxy := ds.(*retryingProxy)
+	var backoffs []time.Duration
+	proxy.sleep = func(_ context.Context, d time.Duration) error {
+		backoffs = append(backoffs, d)
+		return nil
+	}
+	return proxy, &backoffs
+}
+
+func noopTx(context.Context, datastore.ReadWriteTransaction) error { return nil }
+
+func TestNewRetryingDatastoreProxy(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		policy RetryPolicy
+	}{
+		{"no initial backoff", RetryPolicy{MaxRetries: 1, MaxBackoff: time.Second}},
+		{"max backoff below initial backoff", RetryPolicy{MaxRetries: 1, InitialBackoff: time.Second, MaxBackoff: time.Millisecond}},
+		{"negative budget ratio", RetryPolicy{MaxRetries: 1, InitialBackoff: time.Millisecond, MaxBackoff: time.Second, BudgetRatio: -1}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewRetryingDatastoreProxy(&proxy_test.MockDatastore{}, tc.policy)
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestRetryReadWriteTx(t *testing.T) {
+	delegate := &proxy_test.MockDatastore{}
+	delegate.On("ReadWriteTx", []options.RWTOptionsOption(nil)).Return(&proxy_test.MockReadWriteTransaction{}, datastore.NoRevision, errSerialization).Twice()
+	delegate.On("ReadWriteTx", []options.RWTOptionsOption(nil)).Return(&proxy_test.MockReadWriteTransaction{}, expectedRevision, nil).Once()
+
+	ds, backoffs := newTestRetryingProxy(t, delegate, testRetryPolicy)
+	before := testutil.ToFloat64(retriesCounter.WithLabelValues("ReadWriteTx"))
+
+	rev, err := ds.ReadWriteTx(context.Background(), noopTx)
+	require.NoError(t, err)
+	require.True(t, expectedRevision.Equal(rev))
+	require.Len(t, *backoffs, 2)
+	require.Equal(t, before+2, testutil.ToFloat64(retriesCounter.WithLabelValues("ReadWriteTx")))
+	delegate.AssertExpectations(t)
+}
+
+func TestRetryRevisions(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "connection reset")
+
+	delegate := &proxy_test.MockDatastore{}
+	delegate.On("HeadRevision").Return(datastore.NoRevision, unavailable).Once()
+	delegate.On("HeadRevision").Return(expectedRevision, nil).Once()
+	delegate.On("OptimizedRevision").Return(datastore.NoRevision, unavailable).Once()
+	delegate.On("OptimizedRevision").Return(expectedRevision, nil).Once()
+
+	ds, _ := newTestRetryingProxy(t, delegate, testRetryPolicy)
+
+	rev, err := ds.HeadRevision(context.Background())
+	require.NoError(t, err)
+	require.True(t, expectedRevision.Equal(rev))
+
+	rev, err = ds.OptimizedRevision(context.Background())
+	require.NoError(t, err)
+	require.True(t, expectedRevision.Equal(rev))
+	delegate.AssertExpectations(t)
+}
+
+func TestNoRetryOfNonRetriableErrors(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		err  error
+	}{
+		{"other error", errors.New("invalid relationship")},
+		{"canceled", context.Canceled},
+		{"deadline exceeded", context.DeadlineExceeded},
+		{"invalid argument", status.Error(codes.InvalidArgument, "invalid")},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			delegate := &proxy_test.MockDatastore{}
+			delegate.On("HeadRevision").Return(datastore.NoRevision, tc.err).Once()
+
+			ds, backoffs := newTestRetryingProxy(t, delegate, testRetryPolicy)
+			_, err := ds.HeadRevision(context.Background())
+			require.ErrorIs(t, err, tc.err)
+			require.Empty(t, *backoffs)
+			delegate.AssertExpectations(t)
+		})
+	}
+}
+
+func TestNoRetryWhenDisabled(t *testing.T) {
+	opts := []options.RWTOptionsOption{options.WithDisableRetries(true)}
+
+	delegate := &proxy_test.MockDatastore{}
+	delegate.On("ReadWriteTx", opts).Return(&proxy_test.MockReadWriteTransaction{}, datastore.NoRevision, errSerialization).Once()
+
+	ds, backoffs := newTestRetryingProxy(t, delegate, testRetryPolicy)
+	_, err := ds.ReadWriteTx(context.Background(), noopTx, opts...)
+	require.ErrorIs(t, err, errSerialization)
+	require.Empty(t, *backoffs)
+	delegate.AssertExpectations(t)
+}
+
+func TestRetryMaxRetries(t *testing.T) {
+	delegate := &proxy_test.MockDatastore{}
+	delegate.On("HeadRevision").Return(datastore.NoRevision, errSerialization).Times(4)
+
+	ds, backoffs := newTestRetryingProxy(t, delegate, testRetryPolicy)
+	before := testutil.ToFloat64(retriesExhaustedCounter.WithLabelValues(exhaustedMaxRetries))
+
+	_, err := ds.HeadRevision(context.Background())
+	require.ErrorIs(t, err, errSerialization)
+	require.Len(t, *backoffs, 3)
+	for i, backoff := range *backoffs {
+		require.LessOrEqual(t, backoff, min(testRetryPolicy.InitialBackoff<<i, testRetryPolicy.MaxBackoff))
+	}
+	require.Equal(t, before+1, testutil.ToFloat64(retriesExhaustedCounter.WithLabelValues(exhaustedMaxRetries)))
+	delegate.AssertExpectations(t)
+}
+
+func TestRetryBudget(t *testing.T) {
+	delegate := &proxy_test.MockDatastore{}
+	delegate.On("HeadRevision").Return(datastore.NoRevision, errSerialization)
+
+	ds, backoffs := newTestRetryingProxy(t, delegate, testRetryPolicy)
+	before := testutil.ToFloat64(retriesExhaustedCounter.WithLabelValues(exhaustedBudget))
+
+	// A burst of failures spends the budget, after which failures are no longer retried.
+	for i := 0; i < 5; i++ {
+		_, err := ds.HeadRevision(context.Background())
+		require.ErrorIs(t, err, errSerialization)
+	}
+	require.Len(t, *backoffs, maxRetryTokens)
+	require.Equal(t, before+2, testutil.ToFloat64(retriesExhaustedCounter.WithLabelValues(exhaustedBudget)))
+
+	// Successful operations refill the budget.
+	ds.Datastore = &proxy_test.MockDatastore{}
+	ds.Datastore.(*proxy_test.MockDatastore).On("HeadRevision").Return(expectedRevision, nil)
+	for i := 0; i < 15; i++ {
+		_, err := ds.HeadRevision(context.Background())
+		require.NoError(t, err)
+	}
+	require.True(t, ds.takeToken())
+	require.False(t, ds.takeToken())
+}
+
+func TestRetryStopsWhenContextCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	delegate := &proxy_test.MockDatastore{}
+	delegate.On("HeadRevision").Return(datastore.NoRevision, errSerialization).Once()
+
+	ds, err := NewRetryingDatastoreProxy(delegate, testRetryPolicy)
+	require.NoError(t, err)
+	ds.(*retryingProxy).sleep = func(ctx context.Context, d time.Duration) error {
+		cancel()
+		return sleepWithContext(ctx, time.Minute)
+	}
+
+	_, err = ds.HeadRevision(ctx)
+	require.ErrorIs(t, err, errSerialization)
+	delegate.AssertExpectations(t)
+}
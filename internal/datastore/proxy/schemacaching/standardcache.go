@@ -25,6 +25,10 @@ type definitionCachingProxy struct {
 	readGroup singleflight.Group
 }
 
+func (p *definitionCachingProxy) Unwrap() datastore.Datastore {
+	return p.Datastore
+}
+
 func (p *definitionCachingProxy) Close() error {
 	p.c.Close()
 	return p.Datastore.Close()
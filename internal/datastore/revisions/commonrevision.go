@@ -1,6 +1,8 @@
 package revisions
 
 import (
+	"time"
+
 	"github.com/authzed/spicedb/pkg/datastore"
 	"github.com/authzed/spicedb/pkg/spiceerrors"
 )
@@ -78,6 +80,16 @@ type WithTimestampRevision interface {
 	ConstructForTimestamp(timestampNanoSec int64) WithTimestampRevision
 }
 
+// Age returns the time elapsed since the revision was committed, if the revision provides the
+// timestamp at which it was.
+func Age(revision datastore.Revision) (time.Duration, bool) {
+	withTimestamp, ok := revision.(WithTimestampRevision)
+	if !ok {
+		return 0, false
+	}
+	return time.Since(time.Unix(0, withTimestamp.TimestampNanoSec())), true
+}
+
 // WithIntegerRepresentation is an interface that can be implemented by a revision to
 // provide an integer representation of the revision.
 type WithIntegerRepresentation interface {
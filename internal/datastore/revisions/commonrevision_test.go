@@ -3,6 +3,7 @@ package revisions
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -246,3 +247,12 @@ func TestHLCRevisionParsing(t *testing.T) {
 		})
 	}
 }
+
+func TestAge(t *testing.T) {
+	age, ok := Age(NewForTime(time.Now().Add(-time.Minute)))
+	require.True(t, ok)
+	require.InDelta(t, time.Minute.Seconds(), age.Seconds(), 5)
+
+	_, ok = Age(NewForTransactionID(42))
+	require.False(t, ok)
+}
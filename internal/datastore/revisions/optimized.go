@@ -97,6 +97,28 @@ func (cor *CachedOptimizedRevisions) OptimizedRevision(ctx context.Context) (dat
 	return newQuantizedRevision.(datastore.Revision), err
 }
 
+// OptimizedRevisionValidity is implemented by datastores which can report until when a
+// revision they returned as their optimized revision remains so, such that reads made with
+// minimal latency until then are answered at that revision.
+type OptimizedRevisionValidity interface {
+	OptimizedRevisionValidThrough(revision datastore.Revision) (time.Time, bool)
+}
+
+// OptimizedRevisionValidThrough returns until when the revision remains the optimized
+// revision, if it is a recent optimized revision. The revision may still be returned for up to
+// the maximum revision staleness afterward.
+func (cor *CachedOptimizedRevisions) OptimizedRevisionValidThrough(revision datastore.Revision) (time.Time, bool) {
+	cor.Lock()
+	defer cor.Unlock()
+
+	for i := len(cor.candidates) - 1; i >= 0; i-- {
+		if cor.candidates[i].revision.Equal(revision) {
+			return cor.candidates[i].validThrough, true
+		}
+	}
+	return time.Time{}, false
+}
+
 // CachedOptimizedRevisions does caching and deduplication for requests for optimized revisions.
 type CachedOptimizedRevisions struct {
 	sync.Mutex
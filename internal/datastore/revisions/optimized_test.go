@@ -224,3 +224,29 @@ func TestSingleFlightError(t *testing.T) {
 	req.Error(err)
 	mock.AssertExpectations(t)
 }
+
+func TestOptimizedRevisionValidThrough(t *testing.T) {
+	require := require.New(t)
+
+	or := NewCachedOptimizedRevisions(0)
+	mockTime := clock.NewMock()
+	or.clockFn = mockTime
+	mock := trackingRevisionFunction{}
+	or.SetOptimizedRevisionFunc(mock.optimizedRevisionFunc)
+	mock.On("optimizedRevisionFunc").Return(one, 10*time.Millisecond, nil).Once()
+
+	_, ok := or.OptimizedRevisionValidThrough(one)
+	require.False(ok)
+
+	revision, err := or.OptimizedRevision(context.Background())
+	require.NoError(err)
+	require.True(one.Equal(revision))
+
+	validThrough, ok := or.OptimizedRevisionValidThrough(one)
+	require.True(ok)
+	require.Equal(mockTime.Now().Add(10*time.Millisecond), validThrough)
+
+	_, ok = or.OptimizedRevisionValidThrough(two)
+	require.False(ok)
+	mock.AssertExpectations(t)
+}
@@ -123,3 +123,39 @@ func (rcr *RemoteClockRevisions) CheckRevision(ctx context.Context, dsRevision d
 
 	return nil
 }
+
+// EarliestRevision returns the revision at the start of the GC window.
+func (rcr *RemoteClockRevisions) EarliestRevision(ctx context.Context) (datastore.Revision, error) {
+	now, err := rcr.nowFunc(ctx)
+	if err != nil {
+		return datastore.NoRevision, err
+	}
+
+	nowTS, ok := now.(WithTimestampRevision)
+	if !ok {
+		return datastore.NoRevision, spiceerrors.MustBugf("expected with-timestamp revision, got %T", now)
+	}
+
+	return nowTS.ConstructForTimestamp(nowTS.TimestampNanoSec() - rcr.gcWindowNanos), nil
+}
+
+// RevisionAtTime returns the revision of the datastore at the given time. As revisions of the
+// datastore are read from its clock, a revision exists for every point in time.
+func (rcr *RemoteClockRevisions) RevisionAtTime(ctx context.Context, at time.Time) (datastore.Revision, error) {
+	now, err := rcr.nowFunc(ctx)
+	if err != nil {
+		return datastore.NoRevision, err
+	}
+
+	nowTS, ok := now.(WithTimestampRevision)
+	if !ok {
+		return datastore.NoRevision, spiceerrors.MustBugf("expected with-timestamp revision, got %T", now)
+	}
+
+	revision := nowTS.ConstructForTimestamp(at.UnixNano())
+	if err := rcr.CheckRevision(ctx, revision); err != nil {
+		return datastore.NoRevision, err
+	}
+
+	return revision, nil
+}
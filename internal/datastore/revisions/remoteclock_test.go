@@ -182,3 +182,23 @@ func TestRemoteClockStalenessBeyondGC(t *testing.T) {
 	err = rcr.CheckRevision(context.Background(), newOptimized)
 	require.NoError(t, err)
 }
+
+func TestRemoteClockRevisionAtTime(t *testing.T) {
+	require := require.New(t)
+
+	rcr := NewRemoteClockRevisions(1*time.Hour, 0, 0, 0)
+	rcr.SetNowFunc(func(ctx context.Context) (datastore.Revision, error) {
+		return NewHLCForTime(time.Unix(12345, 0)), nil
+	})
+
+	rev, err := rcr.RevisionAtTime(context.Background(), time.Unix(12000, 0))
+	require.NoError(err)
+	require.Equal(NewHLCForTime(time.Unix(12000, 0)), rev)
+
+	_, err = rcr.RevisionAtTime(context.Background(), time.Unix(5000, 0))
+	require.ErrorAs(err, &datastore.ErrInvalidRevision{})
+
+	earliest, err := rcr.EarliestRevision(context.Background())
+	require.NoError(err)
+	require.Equal(NewHLCForTime(time.Unix(12345-3600, 0)), earliest)
+}
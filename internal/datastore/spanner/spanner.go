@@ -207,6 +207,9 @@ func (sd spannerDatastore) SnapshotReader(revisionRaw datastore.Revision) datast
 
 func (sd spannerDatastore) ReadWriteTx(ctx context.Context, fn datastore.TxUserFunc, opts ...options.RWTOptionsOption) (datastore.Revision, error) {
 	config := options.NewRWTOptionsWithOptions(opts...)
+	if len(config.Metadata) > 0 {
+		return datastore.NoRevision, datastore.NewTransactionMetadataUnsupportedErr(Engine)
+	}
 
 	ctx, span := tracer.Start(ctx, "ReadWriteTx")
 	defer span.End()
@@ -0,0 +1,175 @@
+// Package directorysync reconciles the group memberships of a directory, such as the SCIM
+// API of an identity provider or a CSV or JSONL export, into relationships, so that the
+// groups of the directory can be used in permissions.
+package directorysync
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// Membership is the membership of a user, or of a nested group, in a group.
+type Membership struct {
+	Group         string
+	Member        string
+	MemberIsGroup bool
+}
+
+// Config configures how memberships are reconciled into relationships.
+type Config struct {
+	// GroupType is the object type of groups.
+	GroupType string
+
+	// Relation is the relation of groups to their members. Nested groups are members
+	// through this relation.
+	Relation string
+
+	// UserType is the object type of users.
+	UserType string
+
+	// BatchSize is the maximum number of updates written per request.
+	BatchSize int
+
+	// DryRun computes the changes without writing them.
+	DryRun bool
+}
+
+// Result is the outcome of a reconciliation.
+type Result struct {
+	// Added and Removed are the relationships written and deleted, or which would have
+	// been in a dry run.
+	Added   []*v1.Relationship
+	Removed []*v1.Relationship
+
+	// Skipped describes the memberships which could not be converted to relationships.
+	Skipped []string
+}
+
+// Reconcile makes the relationships of the group type and relation match the memberships:
+// relationships are written for memberships which are missing, and relationships without a
+// membership are deleted. Relationships of the group type and relation with other subject
+// types, wildcards or caveats are left alone.
+func Reconcile(ctx context.Context, client v1.PermissionsServiceClient, memberships []Membership, config Config) (*Result, error) {
+	if config.GroupType == "" || config.Relation == "" || config.UserType == "" {
+		return nil, errors.New("a group type, relation and user type are required")
+	}
+	if config.BatchSize <= 0 {
+		return nil, errors.New("batch size must be positive")
+	}
+
+	result := &Result{}
+	desired := map[string]*v1.Relationship{}
+	for _, membership := range memberships {
+		rel := config.relationship(membership)
+		if err := tuple.ValidateResourceID(membership.Group); err != nil {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("membership `%s`: invalid group id: %s", tuple.MustStringRelationship(rel), err))
+			continue
+		}
+		// Members are validated as resource IDs, which excludes the public wildcard.
+		if err := tuple.ValidateResourceID(membership.Member); err != nil {
+			result.Skipped = append(result.Skipped, fmt.Sprintf("membership `%s`: invalid member id: %s", tuple.MustStringRelationship(rel), err))
+			continue
+		}
+		desired[tuple.MustStringRelationship(rel)] = rel
+	}
+
+	existing, err := config.readExisting(ctx, client)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, rel := range desired {
+		if _, ok := existing[key]; !ok {
+			result.Added = append(result.Added, rel)
+		}
+	}
+	for key, rel := range existing {
+		if _, ok := desired[key]; !ok {
+			result.Removed = append(result.Removed, rel)
+		}
+	}
+	sortRelationships(result.Added)
+	sortRelationships(result.Removed)
+
+	if config.DryRun {
+		return result, nil
+	}
+
+	var updates []*v1.RelationshipUpdate
+	for _, rel := range result.Added {
+		updates = append(updates, &v1.RelationshipUpdate{Operation: v1.RelationshipUpdate_OPERATION_TOUCH, Relationship: rel})
+	}
+	for _, rel := range result.Removed {
+		updates = append(updates, &v1.RelationshipUpdate{Operation: v1.RelationshipUpdate_OPERATION_DELETE, Relationship: rel})
+	}
+	for start := 0; start < len(updates); start += config.BatchSize {
+		batch := updates[start:min(start+config.BatchSize, len(updates))]
+		if _, err := client.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{Updates: batch}); err != nil {
+			return nil, fmt.Errorf("failed to write memberships: %w", err)
+		}
+	}
+	return result, nil
+}
+
+func (config Config) relationship(membership Membership) *v1.Relationship {
+	subject := &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: config.UserType, ObjectId: membership.Member}}
+	if membership.MemberIsGroup {
+		subject = &v1.SubjectReference{
+			Object:           &v1.ObjectReference{ObjectType: config.GroupType, ObjectId: membership.Member},
+			OptionalRelation: config.Relation,
+		}
+	}
+
+	return &v1.Relationship{
+		Resource: &v1.ObjectReference{ObjectType: config.GroupType, ObjectId: membership.Group},
+		Relation: config.Relation,
+		Subject:  subject,
+	}
+}
+
+// readExisting returns the relationships managed by the reconciliation, keyed by their
+// string form.
+func (config Config) readExisting(ctx context.Context, client v1.PermissionsServiceClient) (map[string]*v1.Relationship, error) {
+	stream, err := client.ReadRelationships(ctx, &v1.ReadRelationshipsRequest{
+		Consistency: &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+		RelationshipFilter: &v1.RelationshipFilter{
+			ResourceType:     config.GroupType,
+			OptionalRelation: config.Relation,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read memberships: %w", err)
+	}
+
+	existing := map[string]*v1.Relationship{}
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return existing, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read memberships: %w", err)
+		}
+
+		subject := resp.Relationship.Subject
+		isUser := subject.Object.ObjectType == config.UserType && subject.OptionalRelation == "" && subject.Object.ObjectId != tuple.PublicWildcard
+		isGroup := subject.Object.ObjectType == config.GroupType && subject.OptionalRelation == config.Relation
+		if (!isUser && !isGroup) || resp.Relationship.OptionalCaveat != nil {
+			continue
+		}
+		existing[tuple.MustStringRelationship(resp.Relationship)] = resp.Relationship
+	}
+}
+
+func sortRelationships(rels []*v1.Relationship) {
+	sort.Slice(rels, func(i, j int) bool {
+		return tuple.MustStringRelationship(rels[i]) < tuple.MustStringRelationship(rels[j])
+	})
+}
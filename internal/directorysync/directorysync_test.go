@@ -0,0 +1,167 @@
+package directorysync
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const schema = `
+definition user {}
+definition serviceaccount {}
+definition group {
+	relation member: user | group#member | serviceaccount
+}`
+
+func withMemberships(ds datastore.Datastore, require *require.Assertions) (datastore.Datastore, datastore.Revision) {
+	return testfixtures.DatastoreFromSchemaAndTestRelationships(ds, schema, []*core.RelationTuple{
+		tuple.MustParse("group:eng#member@user:anne"),
+		tuple.MustParse("group:eng#member@user:carl"),
+		tuple.MustParse("group:eng#member@serviceaccount:ci"),
+	}, require)
+}
+
+func TestReconcile(t *testing.T) {
+	require := require.New(t)
+	conn, cleanup, _, _ := testserver.NewTestServer(require, 0, memdb.DisableGC, false, withMemberships)
+	t.Cleanup(cleanup)
+	client := v1.NewPermissionsServiceClient(conn)
+
+	memberships, err := ReadCSV(strings.NewReader("group,member\neng,anne\neng,beth\nall,group:eng\nall,bad{id}\n"))
+	require.NoError(err)
+
+	config := Config{GroupType: "group", Relation: "member", UserType: "user", BatchSize: 1, DryRun: true}
+	result, err := Reconcile(context.Background(), client, memberships, config)
+	require.NoError(err)
+	require.Equal([]string{"group:all#member@group:eng#member", "group:eng#member@user:beth"}, relationshipStrings(result.Added))
+	require.Equal([]string{"group:eng#member@user:carl"}, relationshipStrings(result.Removed))
+	require.Len(result.Skipped, 1)
+	require.Contains(result.Skipped[0], "membership `group:all#member@user:bad{id}`: invalid member id")
+
+	// A dry run writes nothing.
+	require.Equal([]string{"group:eng#member@serviceaccount:ci", "group:eng#member@user:anne", "group:eng#member@user:carl"}, readAll(t, client))
+
+	config.DryRun = false
+	_, err = Reconcile(context.Background(), client, memberships, config)
+	require.NoError(err)
+
+	// Relationships of other subject types are left alone.
+	require.Equal([]string{
+		"group:all#member@group:eng#member",
+		"group:eng#member@serviceaccount:ci",
+		"group:eng#member@user:anne",
+		"group:eng#member@user:beth",
+	}, readAll(t, client))
+
+	result, err = Reconcile(context.Background(), client, memberships, config)
+	require.NoError(err)
+	require.Empty(result.Added)
+	require.Empty(result.Removed)
+}
+
+func TestReadJSONL(t *testing.T) {
+	memberships, err := ReadJSONL(strings.NewReader(`{"group": "eng", "member": "anne"}
+
+{"group": "all", "member": "group:eng"}
+`))
+	require.NoError(t, err)
+	require.Equal(t, []Membership{
+		{Group: "eng", Member: "anne"},
+		{Group: "all", Member: "eng", MemberIsGroup: true},
+	}, memberships)
+
+	_, err = ReadJSONL(strings.NewReader(`{"group": "eng"}`))
+	require.ErrorContains(t, err, "line 1: a group and member are required")
+}
+
+func TestReadSCIM(t *testing.T) {
+	resources := map[string][]map[string]any{
+		"/scim/v2/Users": {
+			{"id": "anne", "active": true},
+			{"id": "beth", "active": false},
+			{"id": "carl"},
+		},
+		"/scim/v2/Groups": {
+			{"id": "eng", "members": []map[string]any{
+				{"value": "anne", "type": "User"},
+				{"value": "beth", "type": "User"},
+			}},
+			{"id": "all", "members": []map[string]any{
+				{"value": "carl", "type": "User"},
+				{"value": "eng", "$ref": "https://idp.example.com/scim/v2/Groups/eng"},
+			}},
+		},
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer some-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		// Pages hold a single resource, to exercise paging.
+		all := resources[r.URL.Path]
+		startIndex, _ := strconv.Atoi(r.URL.Query().Get("startIndex"))
+		var page []map[string]any
+		if startIndex <= len(all) {
+			page = all[startIndex-1 : startIndex]
+		}
+		require.NoError(t, json.NewEncoder(w).Encode(map[string]any{"totalResults": len(all), "Resources": page}))
+	}))
+	defer server.Close()
+
+	memberships, err := ReadSCIM(context.Background(), server.Client(), server.URL+"/scim/v2/", "some-token")
+	require.NoError(t, err)
+	require.Equal(t, []Membership{
+		{Group: "eng", Member: "anne"},
+		{Group: "all", Member: "carl"},
+		{Group: "all", Member: "eng", MemberIsGroup: true},
+	}, memberships)
+
+	_, err = ReadSCIM(context.Background(), server.Client(), server.URL+"/scim/v2", "wrong-token")
+	require.ErrorContains(t, err, "failed to list SCIM Users: unexpected status 401 Unauthorized")
+}
+
+func relationshipStrings(rels []*v1.Relationship) []string {
+	strs := make([]string, 0, len(rels))
+	for _, rel := range rels {
+		strs = append(strs, tuple.MustStringRelationship(rel))
+	}
+	return strs
+}
+
+func readAll(t *testing.T, client v1.PermissionsServiceClient) []string {
+	stream, err := client.ReadRelationships(context.Background(), &v1.ReadRelationshipsRequest{
+		Consistency:        &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+		RelationshipFilter: &v1.RelationshipFilter{ResourceType: "group"},
+	})
+	require.NoError(t, err)
+
+	var rels []*v1.Relationship
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		require.NoError(t, err)
+		rels = append(rels, resp.Relationship)
+	}
+	sortRelationships(rels)
+	return relationshipStrings(rels)
+}
@@ -0,0 +1,199 @@
+package directorysync
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// groupPrefix marks members of CSV and JSONL feeds which are nested groups.
+const groupPrefix = "group:"
+
+// scimPageSize is the number of resources requested per page from SCIM APIs.
+const scimPageSize = 100
+
+// ReadCSV reads memberships from CSV rows of `group,member`, with an optional header row.
+// Members prefixed with `group:` are nested groups; other members are users.
+func ReadCSV(r io.Reader) ([]Membership, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+	reader.Comment = '#'
+
+	var memberships []Membership
+	for row := 1; ; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return memberships, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CSV: %w", err)
+		}
+		if row == 1 && record[0] == "group" && record[1] == "member" {
+			continue
+		}
+
+		membership, err := newMembership(record[0], record[1])
+		if err != nil {
+			return nil, fmt.Errorf("row %d: %w", row, err)
+		}
+		memberships = append(memberships, membership)
+	}
+}
+
+// ReadJSONL reads memberships from lines of JSON objects with `group` and `member` fields.
+// Members prefixed with `group:` are nested groups; other members are users.
+func ReadJSONL(r io.Reader) ([]Membership, error) {
+	var memberships []Membership
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		if strings.TrimSpace(scanner.Text()) == "" {
+			continue
+		}
+
+		var entry struct {
+			Group  string `json:"group"`
+			Member string `json:"member"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		membership, err := newMembership(entry.Group, entry.Member)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		memberships = append(memberships, membership)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read JSONL: %w", err)
+	}
+	return memberships, nil
+}
+
+func newMembership(group, member string) (Membership, error) {
+	group = strings.TrimSpace(group)
+	member = strings.TrimSpace(member)
+	if group == "" || member == "" {
+		return Membership{}, errors.New("a group and member are required")
+	}
+
+	if nested, ok := strings.CutPrefix(member, groupPrefix); ok {
+		return Membership{Group: group, Member: nested, MemberIsGroup: true}, nil
+	}
+	return Membership{Group: group, Member: member}, nil
+}
+
+// ReadSCIM reads the memberships of the groups of a SCIM 2.0 API, identifying users and
+// groups by their SCIM `id`. Users which are not active are left out of their groups.
+func ReadSCIM(ctx context.Context, client *http.Client, baseURL, token string) ([]Membership, error) {
+	type member struct {
+		Value string `json:"value"`
+		Type  string `json:"type"`
+		Ref   string `json:"$ref"`
+	}
+	type resource struct {
+		ID      string   `json:"id"`
+		Active  *bool    `json:"active"`
+		Members []member `json:"members"`
+	}
+
+	inactive := map[string]struct{}{}
+	err := listSCIM(ctx, client, baseURL, token, "Users", func(raw json.RawMessage) error {
+		var user resource
+		if err := json.Unmarshal(raw, &user); err != nil {
+			return err
+		}
+		if user.Active != nil && !*user.Active {
+			inactive[user.ID] = struct{}{}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var memberships []Membership
+	err = listSCIM(ctx, client, baseURL, token, "Groups", func(raw json.RawMessage) error {
+		var group resource
+		if err := json.Unmarshal(raw, &group); err != nil {
+			return err
+		}
+
+		for _, m := range group.Members {
+			isGroup := m.Type == "Group" || (m.Type == "" && strings.Contains(m.Ref, "/Groups/"))
+			if _, ok := inactive[m.Value]; ok && !isGroup {
+				continue
+			}
+			memberships = append(memberships, Membership{Group: group.ID, Member: m.Value, MemberIsGroup: isGroup})
+		}
+		return nil
+	})
+	return memberships, err
+}
+
+// listSCIM calls the function with each resource of the endpoint, requesting pages until all
+// the resources have been listed.
+func listSCIM(ctx context.Context, client *http.Client, baseURL, token, endpoint string, fn func(json.RawMessage) error) error {
+	for startIndex := 1; ; {
+		query := url.Values{}
+		query.Set("startIndex", strconv.Itoa(startIndex))
+		query.Set("count", strconv.Itoa(scimPageSize))
+		pageURL := strings.TrimSuffix(baseURL, "/") + "/" + endpoint + "?" + query.Encode()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Accept", "application/scim+json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+
+		page, err := func() (*scimListResponse, error) {
+			resp, err := client.Do(req)
+			if err != nil {
+				return nil, err
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != http.StatusOK {
+				_, _ = io.Copy(io.Discard, resp.Body)
+				return nil, fmt.Errorf("unexpected status %s", resp.Status)
+			}
+
+			var page scimListResponse
+			if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+				return nil, err
+			}
+			return &page, nil
+		}()
+		if err != nil {
+			return fmt.Errorf("failed to list SCIM %s: %w", endpoint, err)
+		}
+
+		for _, raw := range page.Resources {
+			if err := fn(raw); err != nil {
+				return fmt.Errorf("failed to decode SCIM %s: %w", endpoint, err)
+			}
+		}
+
+		startIndex += len(page.Resources)
+		if len(page.Resources) == 0 || startIndex > page.TotalResults {
+			return nil
+		}
+	}
+}
+
+type scimListResponse struct {
+	TotalResults int               `json:"totalResults"`
+	Resources    []json.RawMessage `json:"Resources"`
+}
@@ -0,0 +1,522 @@
+// Package balancer implements the consistent hashring gRPC balancer used to route dispatches
+// to the members of the dispatch cluster.
+//
+// It extends the balancer of github.com/authzed/consistent, whose name and service config it
+// shares, so that membership changes disturb cache locality as little as possible:
+//
+//   - Loads are bounded: a request is routed to the first member along the hashring whose
+//     in-flight requests are below a multiple of the average, so that hot keys spill over to
+//     their neighbors rather than overloading their owner.
+//   - After a membership change, requests for keys which moved to a new owner are sent to
+//     their previous owner, whose cache is warm, and also to the new owner to warm its cache,
+//     for the duration of a dual dispatch window. See UnaryDualDispatchInterceptor.
+//
+// The fraction of the keyspace moved by each membership change is recorded as a metric.
+package balancer
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/authzed/consistent"
+	"github.com/authzed/consistent/hashring"
+	"github.com/benbjohnson/clock"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/balancer/base"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/grpclog"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/serviceconfig"
+)
+
+// BalancerName is the name of the balancer, which is that of the balancer it extends so that
+// the service configs of either can be used interchangeably.
+const BalancerName = consistent.BalancerName
+
+// keyspaceSamples is the number of keys sampled to estimate the fraction of the keyspace moved
+// by a membership change.
+const keyspaceSamples = 1024
+
+var (
+	keyspaceMovedRatio = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "spicedb",
+		Subsystem: "dispatch",
+		Name:      "hashring_keyspace_moved_ratio",
+		Help:      "Fraction of the keyspace of the dispatch hashring which changed owner on each membership change.",
+		Buckets:   []float64{0, .05, .1, .2, .3, .4, .5, .6, .8, 1},
+	})
+
+	boundedLoadPicks = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "spicedb",
+		Subsystem: "dispatch",
+		Name:      "hashring_bounded_load_picks_total",
+		Help:      "Total number of dispatches routed away from the owner of their key because it was at its load bound.",
+	})
+)
+
+// BalancerConfig exposes the configurable aspects of the balancer.
+//
+// This type is meant to be used with `grpc.WithDefaultServiceConfig()` through the
+// `ServiceConfigJSON()` method.
+type BalancerConfig struct {
+	serviceconfig.LoadBalancingConfig `json:"-"`
+
+	// ReplicationFactor is the number of virtual nodes of each member in the hashring.
+	ReplicationFactor uint16 `json:"replicationFactor,omitempty"`
+
+	// Spread is the number of members, starting with the owner of a key, among which a request
+	// for the key is randomly routed.
+	Spread uint8 `json:"spread,omitempty"`
+
+	// LoadFactor bounds the in-flight requests of each member to this multiple of the average
+	// across members. Zero disables the bound.
+	LoadFactor float64 `json:"loadFactor,omitempty"`
+
+	// DualDispatchWindow is how long after a membership change requests for keys which moved
+	// are also sent to their previous owner. Zero disables dual dispatch.
+	DualDispatchWindow time.Duration `json:"dualDispatchWindow,omitempty"`
+}
+
+// ServiceConfigJSON encodes the config into the gRPC Service Config JSON format.
+func (c *BalancerConfig) ServiceConfigJSON() (string, error) {
+	type wrapper struct {
+		Config []map[string]*BalancerConfig `json:"loadBalancingConfig"`
+	}
+
+	j, err := json.Marshal(wrapper{Config: []map[string]*BalancerConfig{{BalancerName: c}}})
+	if err != nil {
+		return "", err
+	}
+	return string(j), nil
+}
+
+var logger = grpclog.Component("consistenthashring")
+
+// NewBuilder returns a gRPC balancer builder which routes requests according to a hashring
+// hashed with hashfn.
+func NewBuilder(hashfn hashring.HashFunc) consistent.Builder {
+	return &builder{hashfn: hashfn, clock: clock.New()}
+}
+
+type builder struct {
+	hashfn hashring.HashFunc
+	clock  clock.Clock
+}
+
+func (b *builder) Name() string { return BalancerName }
+
+func (b *builder) Build(cc balancer.ClientConn, _ balancer.BuildOptions) balancer.Balancer {
+	return &ringBalancer{
+		cc:       cc,
+		subConns: resolver.NewAddressMap(),
+		scStates: make(map[balancer.SubConn]connectivity.State),
+		csEvltr:  &balancer.ConnectivityStateEvaluator{},
+		state:    connectivity.Connecting,
+		hasher:   b.hashfn,
+		clock:    b.clock,
+		loads:    &loadTracker{inFlight: map[string]int{}},
+		picker:   base.NewErrPicker(balancer.ErrNoSubConnAvailable),
+	}
+}
+
+func (b *builder) ParseConfig(js json.RawMessage) (serviceconfig.LoadBalancingConfig, error) {
+	var lbCfg BalancerConfig
+	if err := json.Unmarshal(js, &lbCfg); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal hashring balancer config: %s, error: %w", string(js), err)
+	}
+
+	if lbCfg.ReplicationFactor == 0 {
+		lbCfg.ReplicationFactor = consistent.DefaultReplicationFactor
+	}
+	if lbCfg.Spread == 0 {
+		lbCfg.Spread = consistent.DefaultSpread
+	}
+	if lbCfg.LoadFactor != 0 && lbCfg.LoadFactor < 1 {
+		return nil, fmt.Errorf("hashring balancer load factor must be at least 1, got %v", lbCfg.LoadFactor)
+	}
+
+	return &lbCfg, nil
+}
+
+type subConnMember struct {
+	balancer.SubConn
+	key string
+}
+
+// Key implements hashring.Member.
+func (s subConnMember) Key() string { return s.key }
+
+// ringBalancer is the balancer of the upstream, whose structure follows that of the balancer
+// it extends. The hashring is rebuilt on each membership change, so that the previous one can
+// be kept for the dual dispatch window.
+type ringBalancer struct {
+	state    connectivity.State
+	cc       balancer.ClientConn
+	picker   balancer.Picker
+	csEvltr  *balancer.ConnectivityStateEvaluator
+	subConns *resolver.AddressMap
+	scStates map[balancer.SubConn]connectivity.State
+
+	config    *BalancerConfig
+	hasher    hashring.HashFunc
+	clock     clock.Clock
+	loads     *loadTracker
+	ring      *hashring.Ring
+	previous  *hashring.Ring
+	changedAt time.Time
+
+	resolverErr error // the last error reported by the resolver; cleared on successful resolution
+	connErr     error // the last connection error; cleared upon leaving TransientFailure
+}
+
+var _ balancer.Balancer = (*ringBalancer)(nil)
+
+func (b *ringBalancer) ResolverError(err error) {
+	b.resolverErr = err
+	if b.subConns.Len() == 0 {
+		b.state = connectivity.TransientFailure
+		b.picker = base.NewErrPicker(errors.Join(b.connErr, b.resolverErr))
+	}
+
+	if b.state != connectivity.TransientFailure {
+		// The picker will not change since the balancer does not currently report an error.
+		return
+	}
+
+	b.cc.UpdateState(balancer.State{ConnectivityState: b.state, Picker: b.picker})
+}
+
+// UpdateClientConnState is called when the addresses or service config change. The hashring
+// is rebuilt if either changed, and a new picker is generated.
+func (b *ringBalancer) UpdateClientConnState(s balancer.ClientConnState) error {
+	b.resolverErr = nil
+
+	changed := false
+	if s.BalancerConfig != nil {
+		config := s.BalancerConfig.(*BalancerConfig)
+		changed = b.config == nil || config.ReplicationFactor != b.config.ReplicationFactor
+		b.config = config
+	}
+
+	// If there's no config yet, the balancer hasn't yet parsed an initial service config.
+	if b.config == nil {
+		b.picker = base.NewErrPicker(errors.Join(b.connErr, b.resolverErr))
+		b.cc.UpdateState(balancer.State{ConnectivityState: b.state, Picker: b.picker})
+		return fmt.Errorf("no hashring configured")
+	}
+
+	addrsSet := resolver.NewAddressMap()
+	for _, addr := range s.ResolverState.Addresses {
+		addrsSet.Set(addr, nil)
+
+		if _, ok := b.subConns.Get(addr); !ok {
+			sc, err := b.cc.NewSubConn([]resolver.Address{addr}, balancer.NewSubConnOptions{HealthCheckEnabled: false})
+			if err != nil {
+				logger.Warningf("failed to create new SubConn: %v", err)
+				continue
+			}
+
+			b.subConns.Set(addr, sc)
+			b.scStates[sc] = connectivity.Idle
+			b.csEvltr.RecordTransition(connectivity.Shutdown, connectivity.Idle)
+			sc.Connect()
+			changed = true
+		}
+	}
+
+	for _, addr := range b.subConns.Keys() {
+		if _, ok := addrsSet.Get(addr); !ok {
+			sci, _ := b.subConns.Get(addr)
+			b.cc.RemoveSubConn(sci.(balancer.SubConn)) // nolint: staticcheck
+			b.subConns.Delete(addr)
+			// The state of the SubConn is kept in scStates until it becomes Shutdown.
+			changed = true
+		}
+	}
+
+	if changed {
+		if err := b.rebuildRing(); err != nil {
+			return err
+		}
+	}
+
+	// If the resolver produced no addresses, return an error so the ClientConn re-resolves.
+	if len(s.ResolverState.Addresses) == 0 {
+		b.ResolverError(errors.New("produced zero addresses"))
+		return balancer.ErrBadResolverState
+	}
+
+	b.updatePicker()
+	b.cc.UpdateState(balancer.State{ConnectivityState: b.state, Picker: b.picker})
+	return nil
+}
+
+// rebuildRing replaces the hashring with one of the current members, keeping the previous one
+// for the dual dispatch window, and records the fraction of the keyspace which moved.
+func (b *ringBalancer) rebuildRing() error {
+	ring := hashring.MustNew(b.hasher, b.config.ReplicationFactor)
+	for _, addr := range b.subConns.Keys() {
+		sc, _ := b.subConns.Get(addr)
+		if err := ring.Add(subConnMember{SubConn: sc.(balancer.SubConn), key: addr.ServerName + addr.Addr}); err != nil {
+			return fmt.Errorf("couldn't add to hashring: %w", err)
+		}
+	}
+
+	if b.ring != nil && len(b.ring.Members()) > 0 && len(ring.Members()) > 0 {
+		keyspaceMovedRatio.Observe(keyspaceMoved(b.ring, ring))
+	}
+
+	b.previous = b.ring
+	b.ring = ring
+	b.changedAt = b.clock.Now()
+	return nil
+}
+
+func (b *ringBalancer) updatePicker() {
+	if b.state == connectivity.TransientFailure {
+		b.picker = base.NewErrPicker(errors.Join(b.connErr, b.resolverErr))
+		return
+	}
+
+	p := &picker{
+		ring:       b.ring,
+		members:    membersByKey(b.ring),
+		spread:     b.config.Spread,
+		loadFactor: b.config.LoadFactor,
+		loads:      b.loads,
+	}
+	if b.previous != nil && b.config.DualDispatchWindow > 0 {
+		p.previous = b.previous
+		p.dualDispatchUntil = b.changedAt.Add(b.config.DualDispatchWindow)
+		p.clock = b.clock
+	}
+	b.picker = p
+}
+
+// UpdateSubConnState is called when the state of a SubConn changes, which can affect the
+// overall state of the balancer. Idle SubConns are reconnected.
+func (b *ringBalancer) UpdateSubConnState(sc balancer.SubConn, state balancer.SubConnState) {
+	s := state.ConnectivityState
+	oldS, ok := b.scStates[sc]
+	if !ok {
+		return
+	}
+
+	if oldS == connectivity.TransientFailure && (s == connectivity.Connecting || s == connectivity.Idle) {
+		// Once a SubConn enters TRANSIENT_FAILURE, ignore subsequent IDLE or CONNECTING
+		// transitions, so the aggregated state isn't always CONNECTING when many backends are
+		// down.
+		if s == connectivity.Idle {
+			sc.Connect()
+		}
+		return
+	}
+
+	b.scStates[sc] = s
+
+	switch s {
+	case connectivity.Idle:
+		sc.Connect()
+	case connectivity.Shutdown:
+		delete(b.scStates, sc)
+	case connectivity.TransientFailure:
+		b.connErr = state.ConnectionError
+	}
+
+	b.state = b.csEvltr.RecordTransition(oldS, s)
+	b.cc.UpdateState(balancer.State{ConnectivityState: b.state, Picker: b.picker})
+}
+
+func (b *ringBalancer) Close() {}
+
+// keyspaceMoved estimates the fraction of the keyspace whose owner differs between the two
+// hashrings, by sampling keys.
+func keyspaceMoved(before, after *hashring.Ring) float64 {
+	moved := 0
+	key := make([]byte, 8)
+	for i := uint64(0); i < keyspaceSamples; i++ {
+		binary.BigEndian.PutUint64(key, i)
+		if ownerKey(before, key) != ownerKey(after, key) {
+			moved++
+		}
+	}
+	return float64(moved) / keyspaceSamples
+}
+
+func ownerKey(ring *hashring.Ring, key []byte) string {
+	members, err := ring.FindN(key, 1)
+	if err != nil {
+		return ""
+	}
+	return members[0].Key()
+}
+
+func membersByKey(ring *hashring.Ring) map[string]subConnMember {
+	members := map[string]subConnMember{}
+	for _, member := range ring.Members() {
+		members[member.Key()] = member.(subConnMember)
+	}
+	return members
+}
+
+// loadTracker counts the in-flight requests of each member, across the pickers of a balancer.
+type loadTracker struct {
+	sync.Mutex
+	inFlight map[string]int
+	total    int
+}
+
+// bound returns the maximum number of in-flight requests a member may have to be routed
+// another, given the number of members.
+func (l *loadTracker) bound(loadFactor float64, members int) int {
+	l.Lock()
+	defer l.Unlock()
+	return int(math.Ceil(loadFactor * float64(l.total+1) / float64(members)))
+}
+
+func (l *loadTracker) load(key string) int {
+	l.Lock()
+	defer l.Unlock()
+	return l.inFlight[key]
+}
+
+func (l *loadTracker) start(key string) {
+	l.Lock()
+	defer l.Unlock()
+	l.inFlight[key]++
+	l.total++
+}
+
+func (l *loadTracker) finish(key string) {
+	l.Lock()
+	defer l.Unlock()
+	l.inFlight[key]--
+	l.total--
+	if l.inFlight[key] == 0 {
+		delete(l.inFlight, key)
+	}
+}
+
+type picker struct {
+	ring       *hashring.Ring
+	members    map[string]subConnMember
+	spread     uint8
+	loadFactor float64
+	loads      *loadTracker
+
+	previous          *hashring.Ring
+	dualDispatchUntil time.Time
+	clock             clock.Clock
+}
+
+var _ balancer.Picker = (*picker)(nil)
+
+// Pick returns the SubConn to which to send a request, based on the key stored in the
+// request's context at consistent.CtxKey.
+//
+// During the dual dispatch window, a request for a key which moved is sent to its previous
+// owner if it is still a member, and the dual dispatch is recorded for the interceptor to
+// also send the request to the new owner.
+func (p *picker) Pick(info balancer.PickInfo) (balancer.PickResult, error) {
+	key := info.Ctx.Value(consistent.CtxKey).([]byte)
+
+	if p.previous != nil {
+		if dual, ok := info.Ctx.Value(dualDispatchCtxKey).(*dualDispatch); ok && p.clock.Now().Before(p.dualDispatchUntil) {
+			if member, ok := p.previousOwner(key); ok {
+				dual.moved.Store(true)
+				return p.result(member), nil
+			}
+		}
+	}
+
+	member, err := p.pick(key)
+	if err != nil {
+		return balancer.PickResult{}, err
+	}
+	return p.result(member), nil
+}
+
+// previousOwner returns the owner of the key in the previous hashring if it differs from its
+// owner in the current one, but is still a member.
+func (p *picker) previousOwner(key []byte) (subConnMember, bool) {
+	previous, err := p.previous.FindN(key, 1)
+	if err != nil {
+		return subConnMember{}, false
+	}
+	current, err := p.ring.FindN(key, 1)
+	if err != nil {
+		return subConnMember{}, false
+	}
+
+	if previous[0].Key() == current[0].Key() {
+		return subConnMember{}, false
+	}
+
+	// The member is looked up in the current hashring, as its SubConn may have been recreated.
+	owner, ok := p.members[previous[0].Key()]
+	return owner, ok
+}
+
+// pick returns the member to which to route the key: randomly among the first spread members
+// along the hashring from the key which are below the load bound.
+func (p *picker) pick(key []byte) (subConnMember, error) {
+	if len(p.members) == 0 {
+		return subConnMember{}, balancer.ErrNoSubConnAvailable
+	}
+
+	spread := min(int(p.spread), len(p.members))
+	if p.loadFactor == 0 {
+		members, err := p.ring.FindN(key, uint8(spread))
+		if err != nil {
+			return subConnMember{}, err
+		}
+		return members[rand.Intn(len(members))].(subConnMember), nil // nolint: gosec
+	}
+
+	members, err := p.ring.FindN(key, uint8(min(len(p.members), math.MaxUint8)))
+	if err != nil {
+		return subConnMember{}, err
+	}
+
+	bound := p.loads.bound(p.loadFactor, len(p.members))
+	eligible := make([]hashring.Member, 0, spread)
+	skipped := false
+	for i, member := range members {
+		if p.loads.load(member.Key()) >= bound {
+			skipped = skipped || i < spread
+			continue
+		}
+		eligible = append(eligible, member)
+		if len(eligible) == spread {
+			break
+		}
+	}
+
+	// As the bound is above the average load, there is always a member below it, unless loads
+	// changed concurrently.
+	if len(eligible) == 0 {
+		eligible = members[:spread]
+		skipped = false
+	}
+
+	if skipped {
+		boundedLoadPicks.Inc()
+	}
+	return eligible[rand.Intn(len(eligible))].(subConnMember), nil // nolint: gosec
+}
+
+func (p *picker) result(member subConnMember) balancer.PickResult {
+	p.loads.start(member.Key())
+	return balancer.PickResult{
+		SubConn: member.SubConn,
+		Done:    func(balancer.DoneInfo) { p.loads.finish(member.Key()) },
+	}
+}
@@ -0,0 +1,199 @@
+package balancer
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/authzed/consistent"
+	"github.com/authzed/consistent/hashring"
+	"github.com/benbjohnson/clock"
+	"github.com/cespare/xxhash/v2"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/balancer"
+)
+
+type fakeSubConn struct {
+	balancer.SubConn
+	name string
+}
+
+func newRing(t *testing.T, names ...string) *hashring.Ring {
+	ring := hashring.MustNew(xxhash.Sum64, 100)
+	for _, name := range names {
+		require.NoError(t, ring.Add(subConnMember{SubConn: &fakeSubConn{name: name}, key: name}))
+	}
+	return ring
+}
+
+func newPicker(ring *hashring.Ring, loadFactor float64) *picker {
+	return &picker{
+		ring:       ring,
+		members:    membersByKey(ring),
+		spread:     1,
+		loadFactor: loadFactor,
+		loads:      &loadTracker{inFlight: map[string]int{}},
+	}
+}
+
+func pickInfo(ctx context.Context, key string) balancer.PickInfo {
+	return balancer.PickInfo{Ctx: context.WithValue(ctx, consistent.CtxKey, []byte(key))}
+}
+
+func picked(result balancer.PickResult) string {
+	return result.SubConn.(*fakeSubConn).name
+}
+
+func TestPickerBoundsLoad(t *testing.T) {
+	p := newPicker(newRing(t, "a", "b", "c", "d"), 1.25)
+
+	owner, err := p.Pick(pickInfo(context.Background(), "somekey"))
+	require.NoError(t, err)
+
+	// Requests for the same key go to its owner until it reaches the bound, of 1.25 times the
+	// average load, then spill over to other members.
+	results := []balancer.PickResult{owner}
+	pickedOwner := 1
+	for i := 0; i < 20; i++ {
+		result, err := p.Pick(pickInfo(context.Background(), "somekey"))
+		require.NoError(t, err)
+		results = append(results, result)
+		if picked(result) == picked(owner) {
+			pickedOwner++
+		}
+	}
+	require.Less(t, pickedOwner, len(results))
+	require.LessOrEqual(t, p.loads.load(picked(owner)), p.loads.bound(1.25, 4))
+
+	for _, result := range results {
+		result.Done(balancer.DoneInfo{})
+	}
+	require.Empty(t, p.loads.inFlight)
+	require.Zero(t, p.loads.total)
+
+	// Once the load is gone, requests return to the owner.
+	result, err := p.Pick(pickInfo(context.Background(), "somekey"))
+	require.NoError(t, err)
+	require.Equal(t, picked(owner), picked(result))
+}
+
+func TestPickerUnboundedLoad(t *testing.T) {
+	p := newPicker(newRing(t, "a", "b", "c"), 0)
+
+	owner, err := p.Pick(pickInfo(context.Background(), "somekey"))
+	require.NoError(t, err)
+	for i := 0; i < 20; i++ {
+		result, err := p.Pick(pickInfo(context.Background(), "somekey"))
+		require.NoError(t, err)
+		require.Equal(t, picked(owner), picked(result))
+	}
+}
+
+func TestPickerDualDispatchWindow(t *testing.T) {
+	previous := newRing(t, "a", "b", "c")
+	current := newRing(t, "a", "b", "c", "d")
+
+	// Find a key which moved to the new member.
+	var key string
+	for i := 0; ; i++ {
+		key = fmt.Sprintf("key%d", i)
+		if ownerKey(current, []byte(key)) == "d" {
+			break
+		}
+	}
+	previousOwner := ownerKey(previous, []byte(key))
+
+	mockClock := clock.NewMock()
+	p := newPicker(current, 0)
+	p.previous = previous
+	p.dualDispatchUntil = mockClock.Now().Add(30 * time.Second)
+	p.clock = mockClock
+
+	// Within the window, the request goes to the previous owner, and the dual dispatch is
+	// recorded.
+	dual := &dualDispatch{}
+	result, err := p.Pick(pickInfo(context.WithValue(context.Background(), dualDispatchCtxKey, dual), key))
+	require.NoError(t, err)
+	require.Equal(t, previousOwner, picked(result))
+	require.True(t, dual.moved.Load())
+
+	// The request sent to warm the new owner goes to it.
+	result, err = p.Pick(pickInfo(context.Background(), key))
+	require.NoError(t, err)
+	require.Equal(t, "d", picked(result))
+
+	// Keys which did not move are not dual dispatched.
+	for i := 0; ; i++ {
+		unmoved := fmt.Sprintf("key%d", i)
+		if ownerKey(current, []byte(unmoved)) != ownerKey(previous, []byte(unmoved)) {
+			continue
+		}
+
+		dual := &dualDispatch{}
+		_, err := p.Pick(pickInfo(context.WithValue(context.Background(), dualDispatchCtxKey, dual), unmoved))
+		require.NoError(t, err)
+		require.False(t, dual.moved.Load())
+		break
+	}
+
+	// After the window, the request only goes to the new owner.
+	mockClock.Add(31 * time.Second)
+	dual = &dualDispatch{}
+	result, err = p.Pick(pickInfo(context.WithValue(context.Background(), dualDispatchCtxKey, dual), key))
+	require.NoError(t, err)
+	require.Equal(t, "d", picked(result))
+	require.False(t, dual.moved.Load())
+}
+
+func TestPickerDualDispatchRemovedOwner(t *testing.T) {
+	previous := newRing(t, "a", "b", "c")
+	current := newRing(t, "a", "b")
+
+	var key string
+	for i := 0; ; i++ {
+		key = fmt.Sprintf("key%d", i)
+		if ownerKey(previous, []byte(key)) == "c" {
+			break
+		}
+	}
+
+	mockClock := clock.NewMock()
+	p := newPicker(current, 0)
+	p.previous = previous
+	p.dualDispatchUntil = mockClock.Now().Add(30 * time.Second)
+	p.clock = mockClock
+
+	// The previous owner is no longer a member, so the request only goes to the new owner.
+	dual := &dualDispatch{}
+	result, err := p.Pick(pickInfo(context.WithValue(context.Background(), dualDispatchCtxKey, dual), key))
+	require.NoError(t, err)
+	require.Equal(t, ownerKey(current, []byte(key)), picked(result))
+	require.False(t, dual.moved.Load())
+}
+
+func TestKeyspaceMoved(t *testing.T) {
+	require.Zero(t, keyspaceMoved(newRing(t, "a", "b", "c"), newRing(t, "a", "b", "c")))
+
+	// Adding a fourth member moves about a quarter of the keyspace.
+	moved := keyspaceMoved(newRing(t, "a", "b", "c"), newRing(t, "a", "b", "c", "d"))
+	require.InDelta(t, 0.25, moved, 0.1)
+
+	require.Equal(t, float64(1), keyspaceMoved(newRing(t, "a"), newRing(t, "b")))
+}
+
+func TestParseConfig(t *testing.T) {
+	b := NewBuilder(xxhash.Sum64)
+
+	config, err := b.ParseConfig([]byte(`{"loadFactor": 1.5, "dualDispatchWindow": 1000000000}`))
+	require.NoError(t, err)
+	require.Equal(t, &BalancerConfig{
+		ReplicationFactor:  consistent.DefaultReplicationFactor,
+		Spread:             consistent.DefaultSpread,
+		LoadFactor:         1.5,
+		DualDispatchWindow: time.Second,
+	}, config)
+
+	_, err = b.ParseConfig([]byte(`{"loadFactor": 0.5}`))
+	require.ErrorContains(t, err, "load factor must be at least 1")
+}
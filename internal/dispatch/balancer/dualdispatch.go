@@ -0,0 +1,66 @@
+package balancer
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+type ctxKey string
+
+// dualDispatchCtxKey is the context key of the *dualDispatch in which the picker records that a
+// request was sent to the previous owner of its key.
+const dualDispatchCtxKey ctxKey = "dualDispatch"
+
+// defaultWarmingTimeout is the timeout of the request sent to the new owner of a key, if the
+// original request had no deadline.
+const defaultWarmingTimeout = 10 * time.Second
+
+var dualDispatchCount = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "dispatch",
+	Name:      "hashring_dual_dispatches_total",
+	Help:      "Total number of dispatches sent to both the previous and new owners of their key after a membership change.",
+}, []string{"method"})
+
+type dualDispatch struct {
+	moved atomic.Bool
+}
+
+// UnaryDualDispatchInterceptor enables dual dispatch of unary requests. During the dual
+// dispatch window following a membership change, the balancer sends a request for a key which
+// moved to its previous owner, whose cache is warm. The interceptor then sends the request to
+// the new owner in the background, to warm its cache, discarding the response.
+//
+// Streaming requests are only sent to the new owner.
+func UnaryDualDispatchInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	dual := &dualDispatch{}
+	err := invoker(context.WithValue(ctx, dualDispatchCtxKey, dual), method, req, reply, cc, opts...)
+	if !dual.moved.Load() {
+		return err
+	}
+
+	replyMessage, ok := reply.(proto.Message)
+	if !ok {
+		return err
+	}
+
+	dualDispatchCount.WithLabelValues(method).Inc()
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(defaultWarmingTimeout)
+	}
+	warmCtx, cancel := context.WithDeadline(context.WithoutCancel(ctx), deadline)
+	go func() {
+		defer cancel()
+		_ = invoker(warmCtx, method, req, replyMessage.ProtoReflect().New().Interface(), cc, opts...)
+	}()
+
+	return err
+}
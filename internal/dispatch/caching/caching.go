@@ -24,6 +24,18 @@ const (
 	errCachingInitialization = "error initializing caching dispatcher: %w"
 
 	prometheusNamespace = "spicedb"
+
+	// maxCachedStreamSize is the maximum size of the results of a streaming dispatch which are
+	// held in memory to be cached once the stream completes. Streams whose results are larger,
+	// such as those of lookups over large namespaces, are not cached, rather than being buffered
+	// in full.
+	maxCachedStreamSize = 16 * humanize.MiByte
+)
+
+// The outcomes of cached check results, as recorded by the per-outcome check metrics.
+const (
+	checkOutcomeAllowed = "allowed"
+	checkOutcomeDenied  = "denied"
 )
 
 // Dispatcher is a dispatcher with cacheInst-in caching.
@@ -32,14 +44,21 @@ type Dispatcher struct {
 	c          cache.Cache
 	keyHandler keys.Handler
 
+	// deniedCheckCache, if set, holds the results of checks for which any resource was denied,
+	// rather than c, so that they can be given a smaller size and shorter lifetime.
+	deniedCheckCache cache.Cache
+
 	checkTotalCounter                  prometheus.Counter
 	checkFromCacheCounter              prometheus.Counter
+	checkFromCacheByOutcomeCounter     *prometheus.CounterVec
+	checkComputedByOutcomeCounter      *prometheus.CounterVec
 	reachableResourcesTotalCounter     prometheus.Counter
 	reachableResourcesFromCacheCounter prometheus.Counter
 	lookupResourcesTotalCounter        prometheus.Counter
 	lookupResourcesFromCacheCounter    prometheus.Counter
 	lookupSubjectsTotalCounter         prometheus.Counter
 	lookupSubjectsFromCacheCounter     prometheus.Counter
+	streamTooLargeToCacheCounter       prometheus.Counter
 }
 
 func DispatchTestCache(t testing.TB) cache.Cache {
@@ -68,6 +87,18 @@ func NewCachingDispatcher(cacheInst cache.Cache, metricsEnabled bool, prometheus
 		Subsystem: prometheusSubsystem,
 		Name:      "check_from_cache_total",
 	})
+	checkFromCacheByOutcomeCounter := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: prometheusNamespace,
+		Subsystem: prometheusSubsystem,
+		Name:      "check_from_cache_by_outcome_total",
+		Help:      "Number of checks served from the cache, by whether any resource was `denied` or all were `allowed`.",
+	}, []string{"outcome"})
+	checkComputedByOutcomeCounter := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: prometheusNamespace,
+		Subsystem: prometheusSubsystem,
+		Name:      "check_computed_by_outcome_total",
+		Help:      "Number of checks computed rather than served from the cache, by whether any resource was `denied` or all were `allowed`.",
+	}, []string{"outcome"})
 
 	lookupResourcesTotalCounter := prometheus.NewCounter(prometheus.CounterOpts{
 		Namespace: prometheusNamespace,
@@ -102,6 +133,12 @@ func NewCachingDispatcher(cacheInst cache.Cache, metricsEnabled bool, prometheus
 		Name:      "lookup_subjects_from_cache_total",
 	})
 
+	streamTooLargeToCacheCounter := prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: prometheusNamespace,
+		Subsystem: prometheusSubsystem,
+		Name:      "stream_too_large_to_cache_total",
+	})
+
 	if metricsEnabled && prometheusSubsystem != "" {
 		err := prometheus.Register(checkTotalCounter)
 		if err != nil {
@@ -111,6 +148,14 @@ func NewCachingDispatcher(cacheInst cache.Cache, metricsEnabled bool, prometheus
 		if err != nil {
 			return nil, fmt.Errorf(errCachingInitialization, err)
 		}
+		err = prometheus.Register(checkFromCacheByOutcomeCounter)
+		if err != nil {
+			return nil, fmt.Errorf(errCachingInitialization, err)
+		}
+		err = prometheus.Register(checkComputedByOutcomeCounter)
+		if err != nil {
+			return nil, fmt.Errorf(errCachingInitialization, err)
+		}
 		err = prometheus.Register(lookupResourcesTotalCounter)
 		if err != nil {
 			return nil, fmt.Errorf(errCachingInitialization, err)
@@ -135,6 +180,10 @@ func NewCachingDispatcher(cacheInst cache.Cache, metricsEnabled bool, prometheus
 		if err != nil {
 			return nil, fmt.Errorf(errCachingInitialization, err)
 		}
+		err = prometheus.Register(streamTooLargeToCacheCounter)
+		if err != nil {
+			return nil, fmt.Errorf(errCachingInitialization, err)
+		}
 	}
 
 	if keyHandler == nil {
@@ -147,12 +196,15 @@ func NewCachingDispatcher(cacheInst cache.Cache, metricsEnabled bool, prometheus
 		keyHandler:                         keyHandler,
 		checkTotalCounter:                  checkTotalCounter,
 		checkFromCacheCounter:              checkFromCacheCounter,
+		checkFromCacheByOutcomeCounter:     checkFromCacheByOutcomeCounter,
+		checkComputedByOutcomeCounter:      checkComputedByOutcomeCounter,
 		reachableResourcesTotalCounter:     reachableResourcesTotalCounter,
 		reachableResourcesFromCacheCounter: reachableResourcesFromCacheCounter,
 		lookupResourcesTotalCounter:        lookupResourcesTotalCounter,
 		lookupResourcesFromCacheCounter:    lookupResourcesFromCacheCounter,
 		lookupSubjectsTotalCounter:         lookupSubjectsTotalCounter,
 		lookupSubjectsFromCacheCounter:     lookupSubjectsFromCacheCounter,
+		streamTooLargeToCacheCounter:       streamTooLargeToCacheCounter,
 	}, nil
 }
 
@@ -161,6 +213,25 @@ func (cd *Dispatcher) SetDelegate(delegate dispatch.Dispatcher) {
 	cd.d = delegate
 }
 
+// SetDeniedCheckCache sets the cache holding the results of checks for which any resource was
+// denied, which are otherwise held in the cache of the dispatcher along with all others. Stale
+// denials are more harmful than stale grants, so they can be given a separate size and lifetime.
+func (cd *Dispatcher) SetDeniedCheckCache(deniedCheckCache cache.Cache) {
+	cd.deniedCheckCache = deniedCheckCache
+}
+
+// checkOutcome returns whether any resource of the check was denied, or all were allowed,
+// including those allowed conditionally on caveats.
+func checkOutcome(req *v1.DispatchCheckRequest, resp *v1.DispatchCheckResponse) string {
+	for _, resourceID := range req.ResourceIds {
+		result, ok := resp.ResultsByResourceId[resourceID]
+		if !ok || (result.Membership != v1.ResourceCheckResult_MEMBER && result.Membership != v1.ResourceCheckResult_CAVEATED_MEMBER) {
+			return checkOutcomeDenied
+		}
+	}
+	return checkOutcomeAllowed
+}
+
 // DispatchCheck implements dispatch.Check interface
 func (cd *Dispatcher) DispatchCheck(ctx context.Context, req *v1.DispatchCheckRequest) (*v1.DispatchCheckResponse, error) {
 	cd.checkTotalCounter.Inc()
@@ -172,7 +243,11 @@ func (cd *Dispatcher) DispatchCheck(ctx context.Context, req *v1.DispatchCheckRe
 
 	// Disable caching when debugging is enabled.
 	span := trace.SpanFromContext(ctx)
-	if cachedResultRaw, found := cd.c.Get(requestKey); found {
+	cachedResultRaw, found := cd.c.Get(requestKey)
+	if !found && cd.deniedCheckCache != nil {
+		cachedResultRaw, found = cd.deniedCheckCache.Get(requestKey)
+	}
+	if found {
 		var response v1.DispatchCheckResponse
 		if err := response.UnmarshalVT(cachedResultRaw.([]byte)); err != nil {
 			return &v1.DispatchCheckResponse{Metadata: &v1.ResponseMeta{}}, err
@@ -180,6 +255,7 @@ func (cd *Dispatcher) DispatchCheck(ctx context.Context, req *v1.DispatchCheckRe
 
 		if req.Metadata.DepthRemaining >= response.Metadata.DepthRequired {
 			cd.checkFromCacheCounter.Inc()
+			cd.checkFromCacheByOutcomeCounter.WithLabelValues(checkOutcome(req, &response)).Inc()
 			// If debugging is requested, add the req and the response to the trace.
 			if req.Debug == v1.DispatchCheckRequest_ENABLE_BASIC_DEBUGGING {
 				response.Metadata.DebugInfo = &v1.DebugInformation{
@@ -200,6 +276,9 @@ func (cd *Dispatcher) DispatchCheck(ctx context.Context, req *v1.DispatchCheckRe
 
 	// We only want to cache the result if there was no error
 	if err == nil {
+		outcome := checkOutcome(req, computed)
+		cd.checkComputedByOutcomeCounter.WithLabelValues(outcome).Inc()
+
 		adjustedComputed := computed.CloneVT()
 		adjustedComputed.Metadata.CachedDispatchCount = adjustedComputed.Metadata.DispatchCount
 		adjustedComputed.Metadata.DispatchCount = 0
@@ -210,7 +289,11 @@ func (cd *Dispatcher) DispatchCheck(ctx context.Context, req *v1.DispatchCheckRe
 			return &v1.DispatchCheckResponse{Metadata: &v1.ResponseMeta{}}, err
 		}
 
-		cd.c.Set(requestKey, adjustedBytes, sliceSize(adjustedBytes))
+		target := cd.c
+		if outcome == checkOutcomeDenied && cd.deniedCheckCache != nil {
+			target = cd.deniedCheckCache
+		}
+		target.Set(requestKey, adjustedBytes, sliceSize(adjustedBytes))
 	}
 
 	// Return both the computed and err in ALL cases: computed contains resolved
@@ -248,10 +331,7 @@ func (cd *Dispatcher) DispatchReachableResources(req *v1.DispatchReachableResour
 		return nil
 	}
 
-	var (
-		mu             sync.Mutex
-		toCacheResults [][]byte
-	)
+	toCache := cd.newStreamResultsBuffer()
 	wrapped := &dispatch.WrappedDispatchStream[*v1.DispatchReachableResourcesResponse]{
 		Stream: stream,
 		Ctx:    stream.Context(),
@@ -266,9 +346,7 @@ func (cd *Dispatcher) DispatchReachableResources(req *v1.DispatchReachableResour
 				return nil, false, err
 			}
 
-			mu.Lock()
-			toCacheResults = append(toCacheResults, adjustedBytes)
-			mu.Unlock()
+			toCache.add(adjustedBytes)
 
 			return result, true, nil
 		},
@@ -278,13 +356,58 @@ func (cd *Dispatcher) DispatchReachableResources(req *v1.DispatchReachableResour
 		return err
 	}
 
-	var size int64
-	for _, slice := range toCacheResults {
-		size += sliceSize(slice)
+	toCache.store(requestKey)
+	return nil
+}
+
+// streamResultsBuffer holds the results of a streaming dispatch, to be cached once the stream
+// completes, up to a maximum size.
+type streamResultsBuffer struct {
+	cd    *Dispatcher
+	limit int64
+
+	mu       sync.Mutex
+	results  [][]byte
+	size     int64
+	tooLarge bool
+}
+
+func (cd *Dispatcher) newStreamResultsBuffer() *streamResultsBuffer {
+	limit := int64(maxCachedStreamSize)
+	if resizable, ok := cd.c.(cache.Resizable); ok && resizable.MaxCost() < limit {
+		// Entries larger than the cache itself would be rejected when stored.
+		limit = resizable.MaxCost()
 	}
+	return &streamResultsBuffer{cd: cd, limit: limit}
+}
 
-	cd.c.Set(requestKey, toCacheResults, size)
-	return nil
+func (b *streamResultsBuffer) add(result []byte) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.tooLarge {
+		return
+	}
+
+	b.size += sliceSize(result)
+	if b.size > b.limit {
+		b.tooLarge = true
+		b.results = nil
+		b.cd.streamTooLargeToCacheCounter.Inc()
+		return
+	}
+	b.results = append(b.results, result)
+}
+
+// store caches the buffered results under the key, unless they grew too large to be held.
+func (b *streamResultsBuffer) store(key any) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.tooLarge {
+		return
+	}
+	b.cd.c.Set(key, b.results, b.size)
 }
 
 func sliceSize(xs []byte) int64 {
@@ -318,10 +441,7 @@ func (cd *Dispatcher) DispatchLookupResources(req *v1.DispatchLookupResourcesReq
 		return nil
 	}
 
-	var (
-		mu             sync.Mutex
-		toCacheResults [][]byte
-	)
+	toCache := cd.newStreamResultsBuffer()
 	wrapped := &dispatch.WrappedDispatchStream[*v1.DispatchLookupResourcesResponse]{
 		Stream: stream,
 		Ctx:    stream.Context(),
@@ -336,9 +456,7 @@ func (cd *Dispatcher) DispatchLookupResources(req *v1.DispatchLookupResourcesReq
 				return &v1.DispatchLookupResourcesResponse{Metadata: &v1.ResponseMeta{}}, false, err
 			}
 
-			mu.Lock()
-			toCacheResults = append(toCacheResults, adjustedBytes)
-			mu.Unlock()
+			toCache.add(adjustedBytes)
 
 			return result, true, nil
 		},
@@ -348,12 +466,7 @@ func (cd *Dispatcher) DispatchLookupResources(req *v1.DispatchLookupResourcesReq
 		return err
 	}
 
-	var size int64
-	for _, slice := range toCacheResults {
-		size += sliceSize(slice)
-	}
-
-	cd.c.Set(requestKey, toCacheResults, size)
+	toCache.store(requestKey)
 	return nil
 }
 
@@ -383,10 +496,7 @@ func (cd *Dispatcher) DispatchLookupSubjects(req *v1.DispatchLookupSubjectsReque
 		return nil
 	}
 
-	var (
-		mu             sync.Mutex
-		toCacheResults [][]byte
-	)
+	toCache := cd.newStreamResultsBuffer()
 	wrapped := &dispatch.WrappedDispatchStream[*v1.DispatchLookupSubjectsResponse]{
 		Stream: stream,
 		Ctx:    stream.Context(),
@@ -401,9 +511,7 @@ func (cd *Dispatcher) DispatchLookupSubjects(req *v1.DispatchLookupSubjectsReque
 				return &v1.DispatchLookupSubjectsResponse{Metadata: &v1.ResponseMeta{}}, false, err
 			}
 
-			mu.Lock()
-			toCacheResults = append(toCacheResults, adjustedBytes)
-			mu.Unlock()
+			toCache.add(adjustedBytes)
 
 			return result, true, nil
 		},
@@ -413,18 +521,15 @@ func (cd *Dispatcher) DispatchLookupSubjects(req *v1.DispatchLookupSubjectsReque
 		return err
 	}
 
-	var size int64
-	for _, slice := range toCacheResults {
-		size += sliceSize(slice)
-	}
-
-	cd.c.Set(requestKey, toCacheResults, size)
+	toCache.store(requestKey)
 	return nil
 }
 
 func (cd *Dispatcher) Close() error {
 	prometheus.Unregister(cd.checkTotalCounter)
 	prometheus.Unregister(cd.checkFromCacheCounter)
+	prometheus.Unregister(cd.checkFromCacheByOutcomeCounter)
+	prometheus.Unregister(cd.checkComputedByOutcomeCounter)
 	prometheus.Unregister(cd.reachableResourcesTotalCounter)
 	prometheus.Unregister(cd.reachableResourcesFromCacheCounter)
 	prometheus.Unregister(cd.lookupResourcesTotalCounter)
@@ -434,6 +539,9 @@ func (cd *Dispatcher) Close() error {
 	if cache := cd.c; cache != nil {
 		cache.Close()
 	}
+	if cache := cd.deniedCheckCache; cache != nil {
+		cache.Close()
+	}
 
 	return nil
 }
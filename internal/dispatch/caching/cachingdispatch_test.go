@@ -2,9 +2,13 @@ package caching
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -146,6 +150,141 @@ func TestMaxDepthCaching(t *testing.T) {
 	}
 }
 
+func TestStreamTooLargeToCache(t *testing.T) {
+	testCases := []struct {
+		name             string
+		resultCount      int
+		expectedDispatch int
+		expectedTooLarge float64
+	}{
+		{"small stream is cached", 10, 1, 0},
+		{"large stream is not cached", 2000, 2, 2},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			require := require.New(t)
+
+			delegate := &lookupResourcesDelegate{delegateDispatchMock: delegateDispatchMock{&mock.Mock{}}, resultCount: tc.resultCount}
+			dispatcher, err := NewCachingDispatcher(DispatchTestCache(t), false, "", nil)
+			require.NoError(err)
+			dispatcher.SetDelegate(delegate)
+			defer dispatcher.Close()
+
+			req := &v1.DispatchLookupResourcesRequest{
+				ObjectRelation: RR("document", "view"),
+				Subject:        tuple.ParseSubjectONR("user:tom#..."),
+				Metadata: &v1.ResolverMeta{
+					AtRevision:     decimal.Zero.String(),
+					DepthRemaining: 50,
+				},
+			}
+
+			for i := 0; i < 2; i++ {
+				stream := dispatch.NewCollectingDispatchStream[*v1.DispatchLookupResourcesResponse](context.Background())
+				require.NoError(dispatcher.DispatchLookupResources(req, stream))
+				require.Len(stream.Results(), tc.resultCount)
+
+				// Let the cache converge before the next request.
+				time.Sleep(10 * time.Millisecond)
+			}
+
+			require.Equal(tc.expectedDispatch, delegate.dispatchCount)
+			require.Equal(tc.expectedTooLarge, testutil.ToFloat64(dispatcher.streamTooLargeToCacheCounter))
+		})
+	}
+}
+
+func TestDeniedCheckCache(t *testing.T) {
+	require := require.New(t)
+
+	delegate := delegateDispatchMock{&mock.Mock{}}
+	dispatcher, err := NewCachingDispatcher(DispatchTestCache(t), false, "", nil)
+	require.NoError(err)
+	dispatcher.SetDelegate(delegate)
+	deniedCache := DispatchTestCache(t)
+	dispatcher.SetDeniedCheckCache(deniedCache)
+	defer dispatcher.Close()
+
+	checkRequest := func(resourceID string) *v1.DispatchCheckRequest {
+		return &v1.DispatchCheckRequest{
+			ResourceRelation: RR("document", "read"),
+			ResourceIds:      []string{resourceID},
+			Subject:          tuple.ParseSubjectONR("user:tom#..."),
+			Metadata: &v1.ResolverMeta{
+				AtRevision:     decimal.Zero.String(),
+				DepthRemaining: 50,
+			},
+		}
+	}
+	for resourceID, membership := range map[string]v1.ResourceCheckResult_Membership{
+		"allowed":   v1.ResourceCheckResult_MEMBER,
+		"caveated":  v1.ResourceCheckResult_CAVEATED_MEMBER,
+		"denied":    v1.ResourceCheckResult_NOT_MEMBER,
+		"no-result": v1.ResourceCheckResult_UNKNOWN,
+	} {
+		results := map[string]*v1.ResourceCheckResult{}
+		if membership != v1.ResourceCheckResult_UNKNOWN {
+			results[resourceID] = &v1.ResourceCheckResult{Membership: membership}
+		}
+		delegate.On("DispatchCheck", checkRequest(resourceID)).Return(&v1.DispatchCheckResponse{
+			ResultsByResourceId: results,
+			Metadata:            &v1.ResponseMeta{DispatchCount: 1, DepthRequired: 1},
+		}, nil).Times(1)
+	}
+
+	resourceIDs := []string{"allowed", "caveated", "denied", "no-result"}
+	for i := 0; i < 2; i++ {
+		for _, resourceID := range resourceIDs {
+			_, err := dispatcher.DispatchCheck(context.Background(), checkRequest(resourceID))
+			require.NoError(err)
+		}
+
+		// Let the caches converge before the next requests.
+		time.Sleep(10 * time.Millisecond)
+	}
+	delegate.AssertExpectations(t)
+
+	// Denied results are held only in the denied check cache.
+	for _, resourceID := range resourceIDs {
+		key, err := dispatcher.keyHandler.CheckCacheKey(context.Background(), checkRequest(resourceID))
+		require.NoError(err)
+
+		_, inCache := dispatcher.c.Get(key)
+		_, inDeniedCache := deniedCache.Get(key)
+		denied := resourceID == "denied" || resourceID == "no-result"
+		require.Equal(!denied, inCache, resourceID)
+		require.Equal(denied, inDeniedCache, resourceID)
+	}
+
+	for _, counter := range []*prometheus.CounterVec{dispatcher.checkComputedByOutcomeCounter, dispatcher.checkFromCacheByOutcomeCounter} {
+		require.Equal(2.0, testutil.ToFloat64(counter.WithLabelValues(checkOutcomeAllowed)))
+		require.Equal(2.0, testutil.ToFloat64(counter.WithLabelValues(checkOutcomeDenied)))
+	}
+}
+
+// lookupResourcesDelegate publishes the given number of results, each of about 1KiB, for every
+// dispatched LookupResources.
+type lookupResourcesDelegate struct {
+	delegateDispatchMock
+	resultCount   int
+	dispatchCount int
+}
+
+func (lrd *lookupResourcesDelegate) DispatchLookupResources(_ *v1.DispatchLookupResourcesRequest, stream dispatch.LookupResourcesStream) error {
+	lrd.dispatchCount++
+	for i := 0; i < lrd.resultCount; i++ {
+		if err := stream.Publish(&v1.DispatchLookupResourcesResponse{
+			ResolvedResource: &v1.ResolvedResource{ResourceId: fmt.Sprintf("%d-%s", i, strings.Repeat("x", 1024))},
+			Metadata:         &v1.ResponseMeta{DispatchCount: 1},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 type delegateDispatchMock struct {
 	*mock.Mock
 }
@@ -1,13 +1,18 @@
 package cluster
 
 import (
+	"context"
 	"time"
 
 	"github.com/authzed/spicedb/internal/dispatch"
 	"github.com/authzed/spicedb/internal/dispatch/caching"
 	"github.com/authzed/spicedb/internal/dispatch/graph"
 	"github.com/authzed/spicedb/internal/dispatch/keys"
+	"github.com/authzed/spicedb/internal/dispatch/singleflight"
+	maingraph "github.com/authzed/spicedb/internal/graph"
+	"github.com/authzed/spicedb/internal/graph/groupindex"
 	"github.com/authzed/spicedb/pkg/cache"
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
 )
 
 // Option is a function-style option for configuring a combined Dispatcher.
@@ -17,8 +22,12 @@ type optionState struct {
 	metricsEnabled        bool
 	prometheusSubsystem   string
 	cache                 cache.Cache
+	deniedCheckCache      cache.Cache
 	concurrencyLimits     graph.ConcurrencyLimits
+	groupIndex            *groupindex.Index
+	traversalLimits       maingraph.CheckTraversalLimits
 	remoteDispatchTimeout time.Duration
+	cacheMaxStaleness     time.Duration
 }
 
 // MetricsEnabled enables issuing prometheus metrics
@@ -42,6 +51,14 @@ func Cache(c cache.Cache) Option {
 	}
 }
 
+// DeniedCheckCache sets the cache holding the results of checks for which any resource was
+// denied, which are otherwise held in the cache of the remote dispatcher.
+func DeniedCheckCache(c cache.Cache) Option {
+	return func(state *optionState) {
+		state.deniedCheckCache = c
+	}
+}
+
 // ConcurrencyLimits sets the max number of goroutines per operation
 func ConcurrencyLimits(limits graph.ConcurrencyLimits) Option {
 	return func(state *optionState) {
@@ -49,6 +66,20 @@ func ConcurrencyLimits(limits graph.ConcurrencyLimits) Option {
 	}
 }
 
+// GroupIndex sets the optional index consulted for checks of nested group relations.
+func GroupIndex(index *groupindex.Index) Option {
+	return func(state *optionState) {
+		state.groupIndex = index
+	}
+}
+
+// CheckTraversalLimits sets the limits on the traversal of each check entering the dispatcher.
+func CheckTraversalLimits(limits maingraph.CheckTraversalLimits) Option {
+	return func(state *optionState) {
+		state.traversalLimits = limits
+	}
+}
+
 // RemoteDispatchTimeout sets the maximum timeout for a remote dispatch.
 // Defaults to 60s (as defined in the remote dispatcher).
 func RemoteDispatchTimeout(remoteDispatchTimeout time.Duration) Option {
@@ -57,6 +88,14 @@ func RemoteDispatchTimeout(remoteDispatchTimeout time.Duration) Option {
 	}
 }
 
+// CacheMaxStaleness sets the longest time for which a cached result is used, as
+// required when results depend on federated clusters. Zero disables the bound.
+func CacheMaxStaleness(maxStaleness time.Duration) Option {
+	return func(state *optionState) {
+		state.cacheMaxStaleness = maxStaleness
+	}
+}
+
 // NewClusterDispatcher takes a dispatcher (such as one created by
 // combined.NewDispatcher) and returns a cluster dispatcher suitable for use as
 // the dispatcher for the dispatch grpc server.
@@ -66,16 +105,50 @@ func NewClusterDispatcher(dispatch dispatch.Dispatcher, options ...Option) (disp
 		fn(&opts)
 	}
 
-	clusterDispatch := graph.NewDispatcher(dispatch, opts.concurrencyLimits)
+	// Identical subproblems are routed to the same node by the hashring, so concurrent
+	// requests for them from across the cluster share a single evaluation.
+	clusterDispatch := graph.NewDispatcherWithCheckTraversalLimits(dispatch, opts.concurrencyLimits, opts.groupIndex, opts.traversalLimits)
+	clusterDispatch = singleflight.New(clusterDispatch, receivedKeyHandler{&keys.CanonicalKeyHandler{}})
 
 	if opts.prometheusSubsystem == "" {
 		opts.prometheusSubsystem = "dispatch"
 	}
 
-	cachingClusterDispatch, err := caching.NewCachingDispatcher(opts.cache, opts.metricsEnabled, opts.prometheusSubsystem, &keys.CanonicalKeyHandler{})
+	cacheKeyHandler := &keys.BoundedStalenessKeyHandler{Handler: &keys.CanonicalKeyHandler{}, MaxStaleness: opts.cacheMaxStaleness}
+	cachingClusterDispatch, err := caching.NewCachingDispatcher(opts.cache, opts.metricsEnabled, opts.prometheusSubsystem, cacheKeyHandler)
 	if err != nil {
 		return nil, err
 	}
+	if opts.deniedCheckCache != nil {
+		cachingClusterDispatch.SetDeniedCheckCache(opts.deniedCheckCache)
+	}
 	cachingClusterDispatch.SetDelegate(clusterDispatch)
 	return cachingClusterDispatch, nil
 }
+
+// receivedKeyPrefix distinguishes the dispatch keys of subproblems received from peers.
+var receivedKeyPrefix = []byte("received/")
+
+// receivedKeyHandler computes dispatch keys for subproblems received from peers which differ
+// from those of the same subproblems dispatched to peers. The sending peer records the key of
+// the subproblem in the traversal bloom of the request, so that a singleflight keyed on it
+// would take every received request for a loop. A subproblem received again while it is
+// evaluated, which is a true loop, still finds its received key in the bloom.
+type receivedKeyHandler struct {
+	keys.Handler
+}
+
+func (h receivedKeyHandler) CheckDispatchKey(ctx context.Context, req *v1.DispatchCheckRequest) ([]byte, error) {
+	return received(h.Handler.CheckDispatchKey(ctx, req))
+}
+
+func (h receivedKeyHandler) ExpandDispatchKey(ctx context.Context, req *v1.DispatchExpandRequest) ([]byte, error) {
+	return received(h.Handler.ExpandDispatchKey(ctx, req))
+}
+
+func received(key []byte, err error) ([]byte, error) {
+	if err != nil {
+		return nil, err
+	}
+	return append(append([]byte{}, receivedKeyPrefix...), key...), nil
+}
@@ -0,0 +1,109 @@
+package cluster
+
+import (
+	"context"
+	"encoding/hex"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/dispatch"
+	"github.com/authzed/spicedb/internal/dispatch/graph"
+	"github.com/authzed/spicedb/internal/dispatch/keys"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// countingDispatcher answers the subproblems dispatched while evaluating a check, slowly
+// enough for concurrent checks to overlap, and without members, so that each evaluation
+// dispatches all of them.
+type countingDispatcher struct {
+	dispatch.Dispatcher
+	called atomic.Uint64
+}
+
+func (d *countingDispatcher) DispatchCheck(context.Context, *v1.DispatchCheckRequest) (*v1.DispatchCheckResponse, error) {
+	time.Sleep(100 * time.Millisecond)
+	d.called.Add(1)
+	return &v1.DispatchCheckResponse{Metadata: &v1.ResponseMeta{DispatchCount: 1}}, nil
+}
+
+func TestReceivedChecksAreSingleflighted(t *testing.T) {
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+	ds, revision := testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, `
+		definition user {}
+
+		definition document {
+			relation viewer: user
+			relation editor: user
+			permission view = viewer + editor
+		}
+	`, nil, require.New(t))
+	ctx := datastoremw.ContextWithDatastore(context.Background(), ds)
+
+	// receive dispatches the check concurrently, as received from peers, whose bloom
+	// records the dispatch key of the check as the sending peer does.
+	receive := func(t *testing.T, d dispatch.Dispatcher, bloomKey func([]byte) []byte) {
+		req := &v1.DispatchCheckRequest{
+			ResourceRelation: tuple.RelationReference("document", "view"),
+			ResourceIds:      []string{"readme"},
+			Subject:          tuple.ObjectAndRelation("user", "tom", tuple.Ellipsis),
+			ResultsSetting:   v1.DispatchCheckRequest_ALLOW_SINGLE_RESULT,
+			Metadata: &v1.ResolverMeta{
+				AtRevision:     revision.String(),
+				DepthRemaining: 50,
+				TraversalBloom: v1.MustNewTraversalBloomFilter(50),
+			},
+		}
+		key, err := (&keys.CanonicalKeyHandler{}).CheckDispatchKey(ctx, req)
+		require.NoError(t, err)
+		_, err = req.Metadata.RecordTraversal(hex.EncodeToString(bloomKey(key)))
+		require.NoError(t, err)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 2; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				resp, err := d.DispatchCheck(ctx, req.CloneVT())
+				require.NoError(t, err)
+				require.Empty(t, resp.ResultsByResourceId)
+			}()
+		}
+		wg.Wait()
+	}
+
+	newDispatcher := func(t *testing.T) (dispatch.Dispatcher, *countingDispatcher) {
+		delegate := &countingDispatcher{}
+		d, err := NewClusterDispatcher(delegate, ConcurrencyLimits(graph.SharedConcurrencyLimits(10)))
+		require.NoError(t, err)
+		return d, delegate
+	}
+
+	t.Run("shared", func(t *testing.T) {
+		d, delegate := newDispatcher(t)
+		receive(t, d, func(key []byte) []byte { return key })
+
+		// A single evaluation dispatches the viewer and editor subproblems.
+		require.Equal(t, uint64(2), delegate.called.Load())
+	})
+
+	t.Run("loop", func(t *testing.T) {
+		// A check received again while it is evaluated is not singleflighted, which would
+		// deadlock it.
+		d, delegate := newDispatcher(t)
+		receive(t, d, func(key []byte) []byte {
+			received, err := received(key, nil)
+			require.NoError(t, err)
+			return received
+		})
+		require.Equal(t, uint64(4), delegate.called.Load())
+	})
+}
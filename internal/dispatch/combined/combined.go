@@ -3,22 +3,30 @@
 package combined
 
 import (
+	"crypto/tls"
 	"fmt"
 	"time"
 
 	"github.com/authzed/grpcutil"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 
 	"github.com/authzed/spicedb/internal/dispatch"
 	"github.com/authzed/spicedb/internal/dispatch/caching"
+	"github.com/authzed/spicedb/internal/dispatch/fallback"
+	"github.com/authzed/spicedb/internal/dispatch/federation"
 	"github.com/authzed/spicedb/internal/dispatch/graph"
+	"github.com/authzed/spicedb/internal/dispatch/hedging"
 	"github.com/authzed/spicedb/internal/dispatch/keys"
 	"github.com/authzed/spicedb/internal/dispatch/remote"
 	"github.com/authzed/spicedb/internal/dispatch/singleflight"
+	maingraph "github.com/authzed/spicedb/internal/graph"
+	"github.com/authzed/spicedb/internal/graph/groupindex"
 	log "github.com/authzed/spicedb/internal/logging"
 	"github.com/authzed/spicedb/pkg/cache"
 	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/x509util"
 )
 
 // Option is a function-style option for configuring a combined Dispatcher.
@@ -29,13 +37,29 @@ type optionState struct {
 	prometheusSubsystem    string
 	upstreamAddr           string
 	upstreamCAPath         string
+	upstreamCertPath       string
+	upstreamKeyPath        string
 	grpcPresharedKey       string
 	grpcDialOpts           []grpc.DialOption
 	cache                  cache.Cache
+	deniedCheckCache       cache.Cache
 	concurrencyLimits      graph.ConcurrencyLimits
+	groupIndex             *groupindex.Index
+	traversalLimits        maingraph.CheckTraversalLimits
 	remoteDispatchTimeout  time.Duration
 	secondaryUpstreamAddrs map[string]string
 	secondaryUpstreamExprs map[string]string
+	localFallback          bool
+	hedging                *HedgingConfig
+	federation             *federation.Config
+}
+
+// HedgingConfig configures the hedging of requests to the cluster dispatching
+// upstream.
+type HedgingConfig struct {
+	InitialSlowValue time.Duration
+	MaxRequests      uint64
+	Quantile         float64
 }
 
 // MetricsEnabled enables issuing prometheus metrics
@@ -67,6 +91,15 @@ func UpstreamCAPath(path string) Option {
 	}
 }
 
+// UpstreamClientCert sets the optional certificate and key presented to the
+// cluster dispatching upstream, for use when it requires mutual TLS.
+func UpstreamClientCert(certPath, keyPath string) Option {
+	return func(state *optionState) {
+		state.upstreamCertPath = certPath
+		state.upstreamKeyPath = keyPath
+	}
+}
+
 // SecondaryUpstreamAddrs sets a named map of upstream addresses for secondary
 // dispatching.
 func SecondaryUpstreamAddrs(addrs map[string]string) Option {
@@ -84,6 +117,32 @@ func SecondaryUpstreamExprs(addrs map[string]string) Option {
 	}
 }
 
+// LocalFallback sets whether requests are evaluated locally when the
+// cluster dispatching upstream is unavailable.
+func LocalFallback(enabled bool) Option {
+	return func(state *optionState) {
+		state.localFallback = enabled
+	}
+}
+
+// Hedging enables evaluating requests locally when the cluster dispatching
+// upstream is slower than the configured quantile of its historical latency to
+// answer them. A nil config disables hedging.
+func Hedging(config *HedgingConfig) Option {
+	return func(state *optionState) {
+		state.hedging = config
+	}
+}
+
+// Federation enables delegating checks of remote namespaces to the clusters storing
+// their relationships. Cached results are used for no longer than its MaxStaleness. A
+// nil config disables federation.
+func Federation(config *federation.Config) Option {
+	return func(state *optionState) {
+		state.federation = config
+	}
+}
+
 // GrpcPresharedKey sets the preshared key used to authenticate for optional
 // cluster dispatching.
 func GrpcPresharedKey(key string) Option {
@@ -107,6 +166,14 @@ func Cache(c cache.Cache) Option {
 	}
 }
 
+// DeniedCheckCache sets the cache holding the results of checks for which any resource was
+// denied, which are otherwise held in the cache of the dispatcher.
+func DeniedCheckCache(c cache.Cache) Option {
+	return func(state *optionState) {
+		state.deniedCheckCache = c
+	}
+}
+
 // ConcurrencyLimits sets the max number of goroutines per operation
 func ConcurrencyLimits(limits graph.ConcurrencyLimits) Option {
 	return func(state *optionState) {
@@ -114,6 +181,20 @@ func ConcurrencyLimits(limits graph.ConcurrencyLimits) Option {
 	}
 }
 
+// GroupIndex sets the optional index consulted for checks of nested group relations.
+func GroupIndex(index *groupindex.Index) Option {
+	return func(state *optionState) {
+		state.groupIndex = index
+	}
+}
+
+// CheckTraversalLimits sets the limits on the traversal of each check entering the dispatcher.
+func CheckTraversalLimits(limits maingraph.CheckTraversalLimits) Option {
+	return func(state *optionState) {
+		state.traversalLimits = limits
+	}
+}
+
 // RemoteDispatchTimeout sets the maximum timeout for a remote dispatch.
 // Defaults to 60s (as defined in the remote dispatcher).
 func RemoteDispatchTimeout(remoteDispatchTimeout time.Duration) Option {
@@ -135,24 +216,41 @@ func NewDispatcher(options ...Option) (dispatch.Dispatcher, error) {
 		opts.prometheusSubsystem = "dispatch_client"
 	}
 
-	cachingRedispatch, err := caching.NewCachingDispatcher(opts.cache, opts.metricsEnabled, opts.prometheusSubsystem, &keys.CanonicalKeyHandler{})
+	var cacheKeyHandler keys.Handler = &keys.CanonicalKeyHandler{}
+	if opts.federation != nil {
+		cacheKeyHandler = &keys.BoundedStalenessKeyHandler{Handler: cacheKeyHandler, MaxStaleness: opts.federation.MaxStaleness}
+	}
+
+	cachingRedispatch, err := caching.NewCachingDispatcher(opts.cache, opts.metricsEnabled, opts.prometheusSubsystem, cacheKeyHandler)
 	if err != nil {
 		return nil, err
 	}
+	if opts.deniedCheckCache != nil {
+		cachingRedispatch.SetDeniedCheckCache(opts.deniedCheckCache)
+	}
 
-	redispatch := graph.NewDispatcher(cachingRedispatch, opts.concurrencyLimits)
+	redispatch := graph.NewDispatcherWithCheckTraversalLimits(cachingRedispatch, opts.concurrencyLimits, opts.groupIndex, opts.traversalLimits)
 	redispatch = singleflight.New(redispatch, &keys.CanonicalKeyHandler{})
+	localDispatch := redispatch
 
 	// If an upstream is specified, create a cluster dispatcher.
 	if opts.upstreamAddr != "" {
-		if opts.upstreamCAPath != "" {
+		switch {
+		case opts.upstreamCertPath != "" || opts.upstreamKeyPath != "":
+			clientCertOpt, err := withClientCert(opts.upstreamCAPath, opts.upstreamCertPath, opts.upstreamKeyPath)
+			if err != nil {
+				return nil, err
+			}
+			opts.grpcDialOpts = append(opts.grpcDialOpts, clientCertOpt)
+			opts.grpcDialOpts = append(opts.grpcDialOpts, grpcutil.WithBearerToken(opts.grpcPresharedKey))
+		case opts.upstreamCAPath != "":
 			customCertOpt, err := grpcutil.WithCustomCerts(grpcutil.VerifyCA, opts.upstreamCAPath)
 			if err != nil {
 				return nil, err
 			}
 			opts.grpcDialOpts = append(opts.grpcDialOpts, customCertOpt)
 			opts.grpcDialOpts = append(opts.grpcDialOpts, grpcutil.WithBearerToken(opts.grpcPresharedKey))
-		} else {
+		default:
 			opts.grpcDialOpts = append(opts.grpcDialOpts, grpcutil.WithInsecureBearerToken(opts.grpcPresharedKey))
 			opts.grpcDialOpts = append(opts.grpcDialOpts, grpc.WithTransportCredentials(insecure.NewCredentials()))
 		}
@@ -189,10 +287,57 @@ func NewDispatcher(options ...Option) (dispatch.Dispatcher, error) {
 			KeyHandler:             &keys.CanonicalKeyHandler{},
 			DispatchOverallTimeout: opts.remoteDispatchTimeout,
 		}, secondaryClients, secondaryExprs)
+
+		if opts.hedging != nil {
+			redispatch, err = hedging.NewDispatcher(redispatch, localDispatch, opts.hedging.InitialSlowValue, opts.hedging.MaxRequests, opts.hedging.Quantile)
+			if err != nil {
+				return nil, fmt.Errorf("error configuring dispatch hedging: %w", err)
+			}
+		}
 		redispatch = singleflight.New(redispatch, &keys.CanonicalKeyHandler{})
+
+		if opts.localFallback {
+			redispatch = fallback.NewDispatcher(redispatch, localDispatch)
+		}
+	}
+
+	// Checks of remote namespaces are delegated by this node rather than dispatched to
+	// its peers.
+	if opts.federation != nil {
+		redispatch, err = federation.NewDispatcher(redispatch, *opts.federation)
+		if err != nil {
+			return nil, fmt.Errorf("error configuring federation: %w", err)
+		}
 	}
 
 	cachingRedispatch.SetDelegate(redispatch)
 
 	return cachingRedispatch, nil
 }
+
+// withClientCert returns a DialOption for TLS which presents the given client certificate,
+// verifying the server against the CA at caPath or, if empty, the system roots.
+func withClientCert(caPath, certPath, keyPath string) (grpc.DialOption, error) {
+	if certPath == "" || keyPath == "" {
+		return nil, fmt.Errorf("both an upstream client certificate and key must be provided")
+	}
+
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load upstream client certificate: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if caPath != "" {
+		pool, err := x509util.CustomCertPool(caPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load upstream CA: %w", err)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	return grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)), nil
+}
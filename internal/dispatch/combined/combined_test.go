@@ -2,11 +2,16 @@ package combined
 
 import (
 	"context"
+	"net"
 	"testing"
+	"time"
 
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
 
 	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/dispatch/federation"
 	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
 	"github.com/authzed/spicedb/internal/testfixtures"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
@@ -56,5 +61,91 @@ func TestCombinedRecursiveCall(t *testing.T) {
 		},
 	})
 	require.Error(t, err)
-	require.ErrorContains(t, err, "max depth exceeded")
+	require.ErrorContains(t, err, "max depth exceeded: cycle detected: resource:someresource#viewer@user:fred -> resource:someresource#viewer@user:fred")
+}
+
+func TestUpstreamClientCertRequiresKey(t *testing.T) {
+	_, err := NewDispatcher(
+		UpstreamAddr("localhost:50053"),
+		UpstreamClientCert("/path/to/cert.pem", ""),
+	)
+	require.ErrorContains(t, err, "both an upstream client certificate and key must be provided")
+}
+
+// fakeCluster is a federated cluster in which only alice is a member of any group.
+type fakeCluster struct {
+	v1.UnimplementedExperimentalServiceServer
+}
+
+func (fakeCluster) BulkCheckPermission(_ context.Context, req *v1.BulkCheckPermissionRequest) (*v1.BulkCheckPermissionResponse, error) {
+	resp := &v1.BulkCheckPermissionResponse{}
+	for _, item := range req.Items {
+		permissionship := v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION
+		if item.Subject.Object.ObjectId == "alice" {
+			permissionship = v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION
+		}
+		resp.Pairs = append(resp.Pairs, &v1.BulkCheckPermissionPair{
+			Request:  item,
+			Response: &v1.BulkCheckPermissionPair_Item{Item: &v1.BulkCheckPermissionResponseItem{Permissionship: permissionship}},
+		})
+	}
+	return resp, nil
+}
+
+func TestCombinedFederatedCheck(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	v1.RegisterExperimentalServiceServer(server, fakeCluster{})
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	dispatcher, err := NewDispatcher(Federation(&federation.Config{
+		RemoteNamespaces: map[string]string{"bu2/group": listener.Addr().String()},
+		Insecure:         true,
+		MaxStaleness:     time.Minute,
+	}))
+	require.NoError(t, err)
+	t.Cleanup(func() { dispatcher.Close() })
+
+	ctx := datastoremw.ContextWithHandle(context.Background())
+
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	ds, revision := testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, `
+		definition user {}
+
+		definition bu2/group {
+			relation member: user
+		}
+
+		definition document {
+			relation viewer: bu2/group#member | user
+			permission view = viewer
+		}
+	`, []*core.RelationTuple{
+		tuple.MustParse("document:readme#viewer@bu2/group:eng#member"),
+	}, require.New(t))
+
+	require.NoError(t, datastoremw.SetInContext(ctx, ds))
+
+	check := func(subject string) bool {
+		resp, err := dispatcher.DispatchCheck(ctx, &dispatchv1.DispatchCheckRequest{
+			ResourceRelation: tuple.RelationReference("document", "view"),
+			ResourceIds:      []string{"readme"},
+			Subject:          tuple.ParseSubjectONR(subject),
+			ResultsSetting:   dispatchv1.DispatchCheckRequest_REQUIRE_ALL_RESULTS,
+			Metadata: &dispatchv1.ResolverMeta{
+				AtRevision:     revision.String(),
+				DepthRemaining: 50,
+			},
+		})
+		require.NoError(t, err)
+		return resp.ResultsByResourceId["readme"].GetMembership() == dispatchv1.ResourceCheckResult_MEMBER
+	}
+
+	// Membership of the group is checked with the cluster storing it.
+	require.True(t, check("user:alice"))
+	require.False(t, check("user:bob"))
 }
@@ -0,0 +1,64 @@
+package discovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// consulMembership discovers members from the healthy instances of a service in the
+// Consul catalog.
+type consulMembership struct {
+	client   *http.Client
+	endpoint string
+	service  string
+}
+
+type consulServiceEntry struct {
+	Node struct {
+		Address string
+	}
+	Service struct {
+		Address string
+		Port    int
+	}
+}
+
+func (m *consulMembership) Members(ctx context.Context) ([]string, error) {
+	endpoint := strings.TrimSuffix(m.endpoint, "/") + "/v1/health/service/" + url.PathEscape(m.service) + "?passing=true"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query consul for service `%s`: %w", m.service, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status querying consul for service `%s`: %s", m.service, resp.Status)
+	}
+
+	var entries []consulServiceEntry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("failed to decode consul response: %w", err)
+	}
+
+	members := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		// The service address defaults to that of its node when not set.
+		host := entry.Service.Address
+		if host == "" {
+			host = entry.Node.Address
+		}
+		members = append(members, net.JoinHostPort(host, strconv.Itoa(entry.Service.Port)))
+	}
+	return members, nil
+}
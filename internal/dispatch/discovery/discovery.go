@@ -0,0 +1,177 @@
+// Package discovery implements discovery of the members of the dispatch cluster
+// from DNS SRV records, the Consul catalog or an etcd key prefix, exposed to gRPC
+// as a resolver so that the hashring follows membership as it changes.
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc/resolver"
+
+	log "github.com/authzed/spicedb/internal/logging"
+)
+
+// Scheme is the gRPC target scheme handled by the resolver returned from NewResolverBuilder.
+const Scheme = "discovery"
+
+// DefaultRefreshInterval is how often membership is refreshed if no interval is configured.
+const DefaultRefreshInterval = 10 * time.Second
+
+const (
+	BackendDNSSRV = "dns-srv"
+	BackendConsul = "consul"
+	BackendEtcd   = "etcd"
+)
+
+// Backends are the names of the supported discovery backends.
+var Backends = []string{BackendDNSSRV, BackendConsul, BackendEtcd}
+
+// Membership discovers the members of the dispatch cluster.
+type Membership interface {
+	// Members returns the addresses, as `host:port`, of the current members of the cluster.
+	Members(ctx context.Context) ([]string, error)
+}
+
+// Config configures the discovery of cluster members.
+type Config struct {
+	// Backend is the name of the backend used to discover members; one of Backends.
+	Backend string
+
+	// Target identifies the members to the backend: the SRV record name, the Consul service
+	// name or the etcd key prefix.
+	Target string
+
+	// Endpoint is the HTTP address of the Consul agent or etcd server. Unused for DNS SRV.
+	Endpoint string
+
+	// HTTPClient is used to query Consul and etcd. Defaults to http.DefaultClient.
+	HTTPClient *http.Client
+}
+
+// NewMembership returns the Membership for the configured backend.
+func NewMembership(config Config) (Membership, error) {
+	if config.Target == "" {
+		return nil, fmt.Errorf("a target must be provided for %s discovery", config.Backend)
+	}
+
+	client := config.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+
+	switch config.Backend {
+	case BackendDNSSRV:
+		return &dnsSRVMembership{name: config.Target}, nil
+	case BackendConsul:
+		if config.Endpoint == "" {
+			return nil, fmt.Errorf("an endpoint must be provided for consul discovery")
+		}
+		return &consulMembership{client: client, endpoint: config.Endpoint, service: config.Target}, nil
+	case BackendEtcd:
+		if config.Endpoint == "" {
+			return nil, fmt.Errorf("an endpoint must be provided for etcd discovery")
+		}
+		return &etcdMembership{client: client, endpoint: config.Endpoint, prefix: config.Target}, nil
+	default:
+		return nil, fmt.Errorf("unknown discovery backend `%s`; must be one of %v", config.Backend, Backends)
+	}
+}
+
+// NewResolverBuilder returns a gRPC resolver builder for the Scheme, which resolves a target to
+// the current members of the cluster, refreshing them at the given interval.
+func NewResolverBuilder(membership Membership, refreshInterval time.Duration) resolver.Builder {
+	if refreshInterval <= 0 {
+		refreshInterval = DefaultRefreshInterval
+	}
+	return &resolverBuilder{membership: membership, refreshInterval: refreshInterval}
+}
+
+type resolverBuilder struct {
+	membership      Membership
+	refreshInterval time.Duration
+}
+
+func (b *resolverBuilder) Scheme() string {
+	return Scheme
+}
+
+func (b *resolverBuilder) Build(_ resolver.Target, cc resolver.ClientConn, _ resolver.BuildOptions) (resolver.Resolver, error) {
+	ctx, cancel := context.WithCancel(context.Background())
+	r := &membershipResolver{
+		membership:      b.membership,
+		refreshInterval: b.refreshInterval,
+		cc:              cc,
+		cancel:          cancel,
+		resolveNow:      make(chan struct{}, 1),
+	}
+
+	r.wg.Add(1)
+	go r.watch(ctx)
+	return r, nil
+}
+
+type membershipResolver struct {
+	membership      Membership
+	refreshInterval time.Duration
+	cc              resolver.ClientConn
+	cancel          context.CancelFunc
+	resolveNow      chan struct{}
+	wg              sync.WaitGroup
+}
+
+func (r *membershipResolver) watch(ctx context.Context) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.refreshInterval)
+	defer ticker.Stop()
+
+	for {
+		r.refresh(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.resolveNow:
+		}
+	}
+}
+
+func (r *membershipResolver) refresh(ctx context.Context) {
+	members, err := r.membership.Members(ctx)
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		log.Ctx(ctx).Warn().Err(err).Msg("failed to discover dispatch cluster members")
+		r.cc.ReportError(err)
+		return
+	}
+
+	sort.Strings(members)
+	addresses := make([]resolver.Address, 0, len(members))
+	for _, member := range members {
+		addresses = append(addresses, resolver.Address{Addr: member})
+	}
+
+	if err := r.cc.UpdateState(resolver.State{Addresses: addresses}); err != nil {
+		log.Ctx(ctx).Debug().Err(err).Int("members", len(members)).Msg("dispatch cluster membership update was not accepted")
+	}
+}
+
+func (r *membershipResolver) ResolveNow(resolver.ResolveNowOptions) {
+	select {
+	case r.resolveNow <- struct{}{}:
+	default:
+	}
+}
+
+func (r *membershipResolver) Close() {
+	r.cancel()
+	r.wg.Wait()
+}
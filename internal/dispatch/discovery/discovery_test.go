@@ -0,0 +1,169 @@
+package discovery
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/resolver"
+)
+
+func TestNewMembership(t *testing.T) {
+	_, err := NewMembership(Config{Backend: BackendDNSSRV})
+	require.ErrorContains(t, err, "a target must be provided")
+
+	_, err = NewMembership(Config{Backend: BackendConsul, Target: "spicedb"})
+	require.ErrorContains(t, err, "an endpoint must be provided")
+
+	_, err = NewMembership(Config{Backend: "zookeeper", Target: "spicedb"})
+	require.ErrorContains(t, err, "unknown discovery backend")
+
+	membership, err := NewMembership(Config{Backend: BackendEtcd, Target: "/spicedb/", Endpoint: "http://localhost:2379"})
+	require.NoError(t, err)
+	require.IsType(t, &etcdMembership{}, membership)
+}
+
+func TestConsulMembership(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v1/health/service/spicedb", r.URL.Path)
+		require.Equal(t, "true", r.URL.Query().Get("passing"))
+		_, _ = w.Write([]byte(`[
+			{"Node": {"Address": "10.0.0.1"}, "Service": {"Address": "", "Port": 50053}},
+			{"Node": {"Address": "10.0.0.2"}, "Service": {"Address": "10.1.0.2", "Port": 50054}}
+		]`))
+	}))
+	defer server.Close()
+
+	membership, err := NewMembership(Config{Backend: BackendConsul, Target: "spicedb", Endpoint: server.URL})
+	require.NoError(t, err)
+
+	members, err := membership.Members(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.0.0.1:50053", "10.1.0.2:50054"}, members)
+}
+
+func TestEtcdMembership(t *testing.T) {
+	encode := func(s string) string { return base64.StdEncoding.EncodeToString([]byte(s)) }
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "/v3/kv/range", r.URL.Path)
+
+		var req etcdRangeRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		require.Equal(t, encode("/spicedb/"), req.Key)
+		require.Equal(t, encode("/spicedb0"), req.RangeEnd)
+
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"kvs": []map[string]string{
+				{"key": encode("/spicedb/a"), "value": encode("10.0.0.1:50053")},
+				{"key": encode("/spicedb/b"), "value": encode("10.0.0.2:50053\n")},
+			},
+		})
+	}))
+	defer server.Close()
+
+	membership, err := NewMembership(Config{Backend: BackendEtcd, Target: "/spicedb/", Endpoint: server.URL})
+	require.NoError(t, err)
+
+	members, err := membership.Members(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, []string{"10.0.0.1:50053", "10.0.0.2:50053"}, members)
+}
+
+func TestPrefixRangeEnd(t *testing.T) {
+	require.Equal(t, []byte("/spicedb0"), prefixRangeEnd([]byte("/spicedb/")))
+	require.Equal(t, []byte("b"), prefixRangeEnd([]byte{'a', 0xff}))
+	require.Equal(t, []byte{0}, prefixRangeEnd([]byte{0xff, 0xff}))
+}
+
+func TestResolver(t *testing.T) {
+	membership := &fakeMembership{members: []string{"10.0.0.2:50053", "10.0.0.1:50053"}}
+	cc := &fakeClientConn{}
+
+	r, err := NewResolverBuilder(membership, time.Hour).Build(resolver.Target{}, cc, resolver.BuildOptions{})
+	require.NoError(t, err)
+	defer r.Close()
+
+	require.Eventually(t, func() bool {
+		return len(cc.addresses()) == 2
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, []string{"10.0.0.1:50053", "10.0.0.2:50053"}, cc.addresses())
+
+	// Membership changes are picked up when resolution is requested.
+	membership.set([]string{"10.0.0.3:50053"}, nil)
+	r.ResolveNow(resolver.ResolveNowOptions{})
+	require.Eventually(t, func() bool {
+		addresses := cc.addresses()
+		return len(addresses) == 1 && addresses[0] == "10.0.0.3:50053"
+	}, time.Second, 10*time.Millisecond)
+
+	// Failures are reported without discarding the last known members.
+	membership.set(nil, errors.New("unavailable"))
+	r.ResolveNow(resolver.ResolveNowOptions{})
+	require.Eventually(t, func() bool {
+		return cc.reportedError() != nil
+	}, time.Second, 10*time.Millisecond)
+	require.Equal(t, []string{"10.0.0.3:50053"}, cc.addresses())
+}
+
+type fakeMembership struct {
+	sync.Mutex
+	members []string
+	err     error
+}
+
+func (m *fakeMembership) set(members []string, err error) {
+	m.Lock()
+	defer m.Unlock()
+	m.members, m.err = members, err
+}
+
+func (m *fakeMembership) Members(_ context.Context) ([]string, error) {
+	m.Lock()
+	defer m.Unlock()
+	return append([]string(nil), m.members...), m.err
+}
+
+type fakeClientConn struct {
+	resolver.ClientConn
+
+	sync.Mutex
+	state resolver.State
+	err   error
+}
+
+func (cc *fakeClientConn) UpdateState(state resolver.State) error {
+	cc.Lock()
+	defer cc.Unlock()
+	cc.state = state
+	return nil
+}
+
+func (cc *fakeClientConn) ReportError(err error) {
+	cc.Lock()
+	defer cc.Unlock()
+	cc.err = err
+}
+
+func (cc *fakeClientConn) addresses() []string {
+	cc.Lock()
+	defer cc.Unlock()
+	addresses := make([]string, 0, len(cc.state.Addresses))
+	for _, address := range cc.state.Addresses {
+		addresses = append(addresses, address.Addr)
+	}
+	return addresses
+}
+
+func (cc *fakeClientConn) reportedError() error {
+	cc.Lock()
+	defer cc.Unlock()
+	return cc.err
+}
@@ -0,0 +1,35 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// dnsSRVMembership discovers members from the SRV records of a name, such as
+// `_dispatch._tcp.spicedb.example.com`.
+type dnsSRVMembership struct {
+	name     string
+	resolver *net.Resolver
+}
+
+func (m *dnsSRVMembership) Members(ctx context.Context) ([]string, error) {
+	resolver := m.resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	_, records, err := resolver.LookupSRV(ctx, "", "", m.name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up SRV records for `%s`: %w", m.name, err)
+	}
+
+	members := make([]string, 0, len(records))
+	for _, record := range records {
+		host := strings.TrimSuffix(record.Target, ".")
+		members = append(members, net.JoinHostPort(host, strconv.Itoa(int(record.Port))))
+	}
+	return members, nil
+}
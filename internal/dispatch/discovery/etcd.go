@@ -0,0 +1,88 @@
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// etcdMembership discovers members from the values of the keys under a prefix in etcd,
+// each of which is the address of a member, using the etcd v3 JSON gateway.
+type etcdMembership struct {
+	client   *http.Client
+	endpoint string
+	prefix   string
+}
+
+type etcdRangeRequest struct {
+	Key      string `json:"key"`
+	RangeEnd string `json:"range_end"`
+}
+
+type etcdRangeResponse struct {
+	Kvs []struct {
+		Value string `json:"value"`
+	} `json:"kvs"`
+}
+
+func (m *etcdMembership) Members(ctx context.Context) ([]string, error) {
+	body, err := json.Marshal(etcdRangeRequest{
+		Key:      base64.StdEncoding.EncodeToString([]byte(m.prefix)),
+		RangeEnd: base64.StdEncoding.EncodeToString(prefixRangeEnd([]byte(m.prefix))),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint := strings.TrimSuffix(m.endpoint, "/") + "/v3/kv/range"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := m.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query etcd for prefix `%s`: %w", m.prefix, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status querying etcd for prefix `%s`: %s", m.prefix, resp.Status)
+	}
+
+	var decoded etcdRangeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("failed to decode etcd response: %w", err)
+	}
+
+	members := make([]string, 0, len(decoded.Kvs))
+	for _, kv := range decoded.Kvs {
+		value, err := base64.StdEncoding.DecodeString(kv.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode etcd value: %w", err)
+		}
+		if member := strings.TrimSpace(string(value)); member != "" {
+			members = append(members, member)
+		}
+	}
+	return members, nil
+}
+
+// prefixRangeEnd returns the end of the range of keys beginning with the prefix.
+func prefixRangeEnd(prefix []byte) []byte {
+	end := bytes.Clone(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+
+	// The prefix is all 0xff bytes, so the range extends to the end of the keyspace.
+	return []byte{0}
+}
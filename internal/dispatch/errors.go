@@ -2,6 +2,7 @@ package dispatch
 
 import (
 	"fmt"
+	"strings"
 )
 
 // MaxDepthExceededError is an error returned when the maximum depth for dispatching has been exceeded.
@@ -19,3 +20,28 @@ func NewMaxDepthExceededError(req DispatchableRequest) error {
 		req,
 	}
 }
+
+// CycleDetectedError is a MaxDepthExceededError for a request found to depend upon itself.
+type CycleDetectedError struct {
+	MaxDepthExceededError
+
+	// Path is the chain of requests forming the cycle, beginning and ending with the same
+	// resource.
+	Path []string
+}
+
+// NewCycleDetectedError creates a new CycleDetectedError.
+func NewCycleDetectedError(req DispatchableRequest, path []string) error {
+	return CycleDetectedError{
+		MaxDepthExceededError{
+			fmt.Errorf("max depth exceeded: cycle detected: %s: this indicates a recursive data dependency", strings.Join(path, " -> ")),
+			req,
+		},
+		path,
+	}
+}
+
+// Unwrap returns the MaxDepthExceededError.
+func (err CycleDetectedError) Unwrap() error {
+	return err.MaxDepthExceededError
+}
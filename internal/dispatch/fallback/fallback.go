@@ -0,0 +1,114 @@
+// Package fallback implements a dispatcher that evaluates requests locally when
+// the peers to which they would be dispatched are unavailable.
+package fallback
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/dispatch"
+	log "github.com/authzed/spicedb/internal/logging"
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+)
+
+var fallbackCount = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "dispatch",
+	Name:      "local_fallback_total",
+	Help:      "total number of dispatch requests evaluated locally because no peer was available",
+}, []string{"method"})
+
+// NewDispatcher returns a dispatcher which sends requests to the primary dispatcher,
+// evaluating them with the local dispatcher instead if the primary reports that it is
+// unavailable. Streaming requests fall back only if no results have been published.
+func NewDispatcher(primary, local dispatch.Dispatcher) dispatch.Dispatcher {
+	return &Dispatcher{primary: primary, local: local}
+}
+
+type Dispatcher struct {
+	primary dispatch.Dispatcher
+	local   dispatch.Dispatcher
+}
+
+func shouldFallback(method string, err error) bool {
+	if status.Code(err) != codes.Unavailable {
+		return false
+	}
+
+	log.Debug().Err(err).Str("method", method).Msg("dispatch peer unavailable; evaluating locally")
+	fallbackCount.WithLabelValues(method).Inc()
+	return true
+}
+
+func (d *Dispatcher) DispatchCheck(ctx context.Context, req *v1.DispatchCheckRequest) (*v1.DispatchCheckResponse, error) {
+	resp, err := d.primary.DispatchCheck(ctx, req)
+	if shouldFallback("DispatchCheck", err) {
+		return d.local.DispatchCheck(ctx, req)
+	}
+	return resp, err
+}
+
+func (d *Dispatcher) DispatchExpand(ctx context.Context, req *v1.DispatchExpandRequest) (*v1.DispatchExpandResponse, error) {
+	resp, err := d.primary.DispatchExpand(ctx, req)
+	if shouldFallback("DispatchExpand", err) {
+		return d.local.DispatchExpand(ctx, req)
+	}
+	return resp, err
+}
+
+func (d *Dispatcher) DispatchReachableResources(req *v1.DispatchReachableResourcesRequest, stream dispatch.ReachableResourcesStream) error {
+	counting := dispatch.NewCountingDispatchStream(stream)
+	err := d.primary.DispatchReachableResources(req, counting)
+	if counting.PublishedCount() == 0 && shouldFallback("DispatchReachableResources", err) {
+		return d.local.DispatchReachableResources(req, stream)
+	}
+	return err
+}
+
+func (d *Dispatcher) DispatchLookupResources(req *v1.DispatchLookupResourcesRequest, stream dispatch.LookupResourcesStream) error {
+	counting := dispatch.NewCountingDispatchStream(stream)
+	err := d.primary.DispatchLookupResources(req, counting)
+	if counting.PublishedCount() == 0 && shouldFallback("DispatchLookupResources", err) {
+		return d.local.DispatchLookupResources(req, stream)
+	}
+	return err
+}
+
+func (d *Dispatcher) DispatchLookupSubjects(req *v1.DispatchLookupSubjectsRequest, stream dispatch.LookupSubjectsStream) error {
+	counting := dispatch.NewCountingDispatchStream(stream)
+	err := d.primary.DispatchLookupSubjects(req, counting)
+	if counting.PublishedCount() == 0 && shouldFallback("DispatchLookupSubjects", err) {
+		return d.local.DispatchLookupSubjects(req, stream)
+	}
+	return err
+}
+
+func (d *Dispatcher) Close() error {
+	return d.primary.Close()
+}
+
+// ReadyState returns ready when either the primary dispatcher is ready or requests can
+// be evaluated locally in its place.
+func (d *Dispatcher) ReadyState() dispatch.ReadyState {
+	state := d.primary.ReadyState()
+	if state.IsReady {
+		return state
+	}
+
+	localState := d.local.ReadyState()
+	if !localState.IsReady {
+		return localState
+	}
+
+	return dispatch.ReadyState{
+		IsReady: true,
+		Message: "dispatching locally: " + state.Message,
+	}
+}
+
+// Always verify that we implement the interface
+var _ dispatch.Dispatcher = &Dispatcher{}
@@ -0,0 +1,145 @@
+package fallback
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/dispatch"
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+)
+
+func TestFallbackOnUnavailable(t *testing.T) {
+	testCases := []struct {
+		name             string
+		primaryErr       error
+		expectedFallback bool
+	}{
+		{"success", nil, false},
+		{"unavailable", status.Error(codes.Unavailable, "no peers"), true},
+		{"other grpc error", status.Error(codes.InvalidArgument, "bad request"), false},
+		{"non-grpc error", errors.New("some error"), false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			primary := &fakeDispatcher{err: tc.primaryErr, ready: true}
+			local := &fakeDispatcher{ready: true}
+			disp := NewDispatcher(primary, local)
+
+			_, err := disp.DispatchCheck(context.Background(), &v1.DispatchCheckRequest{})
+			if tc.expectedFallback {
+				require.NoError(t, err)
+			} else {
+				require.Equal(t, tc.primaryErr, err)
+			}
+
+			_, err = disp.DispatchExpand(context.Background(), &v1.DispatchExpandRequest{})
+			if tc.expectedFallback {
+				require.NoError(t, err)
+			} else {
+				require.Equal(t, tc.primaryErr, err)
+			}
+
+			require.Equal(t, 2, primary.calls)
+			if tc.expectedFallback {
+				require.Equal(t, 2, local.calls)
+			} else {
+				require.Equal(t, 0, local.calls)
+			}
+		})
+	}
+}
+
+func TestStreamingFallback(t *testing.T) {
+	unavailable := status.Error(codes.Unavailable, "no peers")
+
+	// Nothing was published by the primary, so the request is evaluated locally.
+	primary := &fakeDispatcher{err: unavailable}
+	local := &fakeDispatcher{publish: 2}
+	stream := dispatch.NewCollectingDispatchStream[*v1.DispatchLookupSubjectsResponse](context.Background())
+	err := NewDispatcher(primary, local).DispatchLookupSubjects(&v1.DispatchLookupSubjectsRequest{}, stream)
+	require.NoError(t, err)
+	require.Len(t, stream.Results(), 2)
+
+	// Results were published before the primary failed, so falling back could duplicate them.
+	primary = &fakeDispatcher{err: unavailable, publish: 1}
+	local = &fakeDispatcher{publish: 2}
+	stream = dispatch.NewCollectingDispatchStream[*v1.DispatchLookupSubjectsResponse](context.Background())
+	err = NewDispatcher(primary, local).DispatchLookupSubjects(&v1.DispatchLookupSubjectsRequest{}, stream)
+	require.Equal(t, unavailable, err)
+	require.Len(t, stream.Results(), 1)
+	require.Equal(t, 0, local.calls)
+}
+
+func TestReadyState(t *testing.T) {
+	disp := NewDispatcher(&fakeDispatcher{ready: true}, &fakeDispatcher{ready: true})
+	require.True(t, disp.ReadyState().IsReady)
+
+	disp = NewDispatcher(&fakeDispatcher{message: "no peers"}, &fakeDispatcher{ready: true})
+	state := disp.ReadyState()
+	require.True(t, state.IsReady)
+	require.Contains(t, state.Message, "no peers")
+
+	disp = NewDispatcher(&fakeDispatcher{message: "no peers"}, &fakeDispatcher{message: "not ready"})
+	state = disp.ReadyState()
+	require.False(t, state.IsReady)
+	require.Equal(t, "not ready", state.Message)
+}
+
+type fakeDispatcher struct {
+	err     error
+	publish int
+	ready   bool
+	message string
+	calls   int
+}
+
+func (f *fakeDispatcher) DispatchCheck(_ context.Context, _ *v1.DispatchCheckRequest) (*v1.DispatchCheckResponse, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &v1.DispatchCheckResponse{}, nil
+}
+
+func (f *fakeDispatcher) DispatchExpand(_ context.Context, _ *v1.DispatchExpandRequest) (*v1.DispatchExpandResponse, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &v1.DispatchExpandResponse{}, nil
+}
+
+func (f *fakeDispatcher) DispatchReachableResources(_ *v1.DispatchReachableResourcesRequest, _ dispatch.ReachableResourcesStream) error {
+	f.calls++
+	return f.err
+}
+
+func (f *fakeDispatcher) DispatchLookupResources(_ *v1.DispatchLookupResourcesRequest, _ dispatch.LookupResourcesStream) error {
+	f.calls++
+	return f.err
+}
+
+func (f *fakeDispatcher) DispatchLookupSubjects(_ *v1.DispatchLookupSubjectsRequest, stream dispatch.LookupSubjectsStream) error {
+	f.calls++
+	for i := 0; i < f.publish; i++ {
+		if err := stream.Publish(&v1.DispatchLookupSubjectsResponse{}); err != nil {
+			return err
+		}
+	}
+	return f.err
+}
+
+func (f *fakeDispatcher) Close() error {
+	return nil
+}
+
+func (f *fakeDispatcher) ReadyState() dispatch.ReadyState {
+	return dispatch.ReadyState{IsReady: f.ready, Message: f.message}
+}
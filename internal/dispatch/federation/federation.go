@@ -0,0 +1,276 @@
+// Package federation implements a dispatcher that delegates checks of remote
+// namespaces, whose relationships are stored by other SpiceDB clusters, to the
+// APIs of those clusters.
+package federation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/authzed/grpcutil"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/dispatch"
+	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// DefaultTimeout is the timeout of checks delegated to a remote cluster if none is configured.
+const DefaultTimeout = 5 * time.Second
+
+var federatedCheckCount = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "dispatch",
+	Name:      "federated_check_total",
+	Help:      "total number of checks of remote namespaces delegated to other clusters",
+}, []string{"namespace", "outcome"})
+
+// Config configures the clusters to which checks of remote namespaces are delegated.
+type Config struct {
+	// RemoteNamespaces maps each remote namespace to the endpoint of the cluster storing
+	// its relationships.
+	RemoteNamespaces map[string]string
+
+	// Token is the bearer token with which requests are authenticated to the clusters, if
+	// any.
+	Token string
+
+	// Insecure disables TLS for the connections to the clusters.
+	Insecure bool
+
+	// CAPath is the path of the certificate authority of the clusters. If empty, the
+	// system roots are used.
+	CAPath string
+
+	// MaxStaleness is the longest time for which a delegated check is cached.
+	MaxStaleness time.Duration
+
+	// Timeout is the timeout of each delegated check.
+	Timeout time.Duration
+}
+
+// NewDispatcher returns a dispatcher which delegates checks of the configured remote
+// namespaces to their clusters, and sends all other requests to the delegate.
+func NewDispatcher(delegate dispatch.Dispatcher, config Config, dialOpts ...grpc.DialOption) (*Dispatcher, error) {
+	if config.Insecure {
+		dialOpts = append(dialOpts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if config.Token != "" {
+			dialOpts = append(dialOpts, grpcutil.WithInsecureBearerToken(config.Token))
+		}
+	} else {
+		certs, err := grpcutil.WithSystemCerts(grpcutil.VerifyCA)
+		if config.CAPath != "" {
+			certs, err = grpcutil.WithCustomCerts(grpcutil.VerifyCA, config.CAPath)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to load federation certificates: %w", err)
+		}
+		dialOpts = append(dialOpts, certs)
+		if config.Token != "" {
+			dialOpts = append(dialOpts, grpcutil.WithBearerToken(config.Token))
+		}
+	}
+
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	d := &Dispatcher{
+		delegate: delegate,
+		remotes:  make(map[string]v1.ExperimentalServiceClient, len(config.RemoteNamespaces)),
+		timeout:  timeout,
+	}
+
+	// Namespaces stored by the same cluster share its connection.
+	clients := make(map[string]v1.ExperimentalServiceClient, len(config.RemoteNamespaces))
+	for namespace, endpoint := range config.RemoteNamespaces {
+		client, ok := clients[endpoint]
+		if !ok {
+			conn, err := grpc.Dial(endpoint, dialOpts...)
+			if err != nil {
+				_ = d.closeConns()
+				return nil, fmt.Errorf("failed to dial federated cluster `%s` of namespace `%s`: %w", endpoint, namespace, err)
+			}
+			d.conns = append(d.conns, conn)
+
+			client = v1.NewExperimentalServiceClient(conn)
+			clients[endpoint] = client
+		}
+		d.remotes[namespace] = client
+	}
+
+	return d, nil
+}
+
+// Dispatcher delegates checks of remote namespaces to the clusters storing their
+// relationships.
+type Dispatcher struct {
+	delegate dispatch.Dispatcher
+	remotes  map[string]v1.ExperimentalServiceClient
+	conns    []*grpc.ClientConn
+	timeout  time.Duration
+}
+
+func unsupported(method, namespace string) error {
+	return status.Errorf(codes.FailedPrecondition, "%s is not supported for remote namespace `%s`; only checks are delegated to federated clusters", method, namespace)
+}
+
+func (d *Dispatcher) DispatchCheck(ctx context.Context, req *dispatchv1.DispatchCheckRequest) (*dispatchv1.DispatchCheckResponse, error) {
+	client, ok := d.remotes[req.ResourceRelation.Namespace]
+	if !ok {
+		return d.delegate.DispatchCheck(ctx, req)
+	}
+
+	if err := dispatch.CheckDepth(ctx, req); err != nil {
+		return &dispatchv1.DispatchCheckResponse{Metadata: emptyMetadata}, err
+	}
+
+	results, err := d.checkRemote(ctx, client, req)
+	outcome := "success"
+	if err != nil {
+		outcome = "error"
+	}
+	federatedCheckCount.WithLabelValues(req.ResourceRelation.Namespace, outcome).Inc()
+	if err != nil {
+		return &dispatchv1.DispatchCheckResponse{Metadata: emptyMetadata}, err
+	}
+
+	return &dispatchv1.DispatchCheckResponse{
+		Metadata:            &dispatchv1.ResponseMeta{DispatchCount: 1, DepthRequired: 1},
+		ResultsByResourceId: results,
+	}, nil
+}
+
+// checkRemote checks the permission of the subject on the resources with the cluster
+// storing their relationships, returning the results of those of which it is a member.
+// Remote checks are fully consistent, so that the staleness of their results is bounded
+// by that of the cache of this cluster.
+func (d *Dispatcher) checkRemote(ctx context.Context, client v1.ExperimentalServiceClient, req *dispatchv1.DispatchCheckRequest) (map[string]*dispatchv1.ResourceCheckResult, error) {
+	subject := &v1.SubjectReference{
+		Object: &v1.ObjectReference{ObjectType: req.Subject.Namespace, ObjectId: req.Subject.ObjectId},
+	}
+	if req.Subject.Relation != tuple.Ellipsis {
+		subject.OptionalRelation = req.Subject.Relation
+	}
+
+	bulk := &v1.BulkCheckPermissionRequest{
+		Consistency: &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+		Items:       make([]*v1.BulkCheckPermissionRequestItem, 0, len(req.ResourceIds)),
+	}
+	for _, resourceID := range req.ResourceIds {
+		bulk.Items = append(bulk.Items, &v1.BulkCheckPermissionRequestItem{
+			Resource:   &v1.ObjectReference{ObjectType: req.ResourceRelation.Namespace, ObjectId: resourceID},
+			Permission: req.ResourceRelation.Relation,
+			Subject:    subject,
+		})
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, d.timeout)
+	defer cancel()
+
+	resp, err := client.BulkCheckPermission(ctx, bulk)
+	if err != nil {
+		return nil, remoteError(req.ResourceRelation.Namespace, err)
+	}
+
+	results := make(map[string]*dispatchv1.ResourceCheckResult, len(resp.Pairs))
+	for _, pair := range resp.Pairs {
+		resourceID := pair.Request.GetResource().GetObjectId()
+		switch response := pair.Response.(type) {
+		case *v1.BulkCheckPermissionPair_Error:
+			return nil, remoteError(req.ResourceRelation.Namespace, status.ErrorProto(response.Error))
+
+		case *v1.BulkCheckPermissionPair_Item:
+			switch response.Item.Permissionship {
+			case v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION:
+				results[resourceID] = &dispatchv1.ResourceCheckResult{Membership: dispatchv1.ResourceCheckResult_MEMBER}
+
+			case v1.CheckPermissionResponse_PERMISSIONSHIP_CONDITIONAL_PERMISSION:
+				// The caveats of another cluster cannot be evaluated by this one.
+				return nil, status.Errorf(codes.FailedPrecondition, "check of remote namespace `%s` is conditional on caveats of its cluster, which are not supported", req.ResourceRelation.Namespace)
+			}
+		}
+	}
+	return results, nil
+}
+
+// remoteError wraps the error of a delegated check, reporting a cluster which did not answer
+// in time as unavailable.
+func remoteError(namespace string, err error) error {
+	code := status.Code(err)
+	if errors.Is(err, context.DeadlineExceeded) || code == codes.DeadlineExceeded || code == codes.Unavailable {
+		return status.Errorf(codes.Unavailable, "federated cluster of namespace `%s` is unavailable: %s", namespace, err)
+	}
+	return fmt.Errorf("failed to check remote namespace `%s`: %w", namespace, err)
+}
+
+func (d *Dispatcher) DispatchExpand(ctx context.Context, req *dispatchv1.DispatchExpandRequest) (*dispatchv1.DispatchExpandResponse, error) {
+	if _, ok := d.remotes[req.ResourceAndRelation.Namespace]; ok {
+		return &dispatchv1.DispatchExpandResponse{Metadata: emptyMetadata}, unsupported("expand", req.ResourceAndRelation.Namespace)
+	}
+	return d.delegate.DispatchExpand(ctx, req)
+}
+
+func (d *Dispatcher) DispatchReachableResources(req *dispatchv1.DispatchReachableResourcesRequest, stream dispatch.ReachableResourcesStream) error {
+	if err := d.checkLocal("lookup", req.ResourceRelation.Namespace, req.SubjectRelation.Namespace); err != nil {
+		return err
+	}
+	return d.delegate.DispatchReachableResources(req, stream)
+}
+
+func (d *Dispatcher) DispatchLookupResources(req *dispatchv1.DispatchLookupResourcesRequest, stream dispatch.LookupResourcesStream) error {
+	if err := d.checkLocal("lookup", req.ObjectRelation.Namespace, req.Subject.Namespace); err != nil {
+		return err
+	}
+	return d.delegate.DispatchLookupResources(req, stream)
+}
+
+func (d *Dispatcher) DispatchLookupSubjects(req *dispatchv1.DispatchLookupSubjectsRequest, stream dispatch.LookupSubjectsStream) error {
+	if err := d.checkLocal("lookup", req.ResourceRelation.Namespace, req.SubjectRelation.Namespace); err != nil {
+		return err
+	}
+	return d.delegate.DispatchLookupSubjects(req, stream)
+}
+
+// checkLocal returns an error if any of the namespaces is remote, as lookups would miss the
+// relationships stored by their clusters.
+func (d *Dispatcher) checkLocal(method string, namespaces ...string) error {
+	for _, namespace := range namespaces {
+		if _, ok := d.remotes[namespace]; ok {
+			return unsupported(method, namespace)
+		}
+	}
+	return nil
+}
+
+func (d *Dispatcher) closeConns() error {
+	var errs []error
+	for _, conn := range d.conns {
+		if err := conn.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (d *Dispatcher) Close() error {
+	return errors.Join(d.closeConns(), d.delegate.Close())
+}
+
+func (d *Dispatcher) ReadyState() dispatch.ReadyState {
+	return d.delegate.ReadyState()
+}
+
+var emptyMetadata = &dispatchv1.ResponseMeta{}
+
+// Always verify that we implement the interface
+var _ dispatch.Dispatcher = &Dispatcher{}
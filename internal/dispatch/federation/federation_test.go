@@ -0,0 +1,217 @@
+package federation
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/authzed/spicedb/internal/dispatch"
+	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// fakeCluster answers checks from the permissions it was given, in the form
+// `resource#permission@subject`.
+type fakeCluster struct {
+	v1.UnimplementedExperimentalServiceServer
+
+	permissions map[string]v1.CheckPermissionResponse_Permissionship
+	err         error
+
+	sync.Mutex
+	requests       []*v1.BulkCheckPermissionRequest
+	authorizations []string
+}
+
+func (fc *fakeCluster) BulkCheckPermission(ctx context.Context, req *v1.BulkCheckPermissionRequest) (*v1.BulkCheckPermissionResponse, error) {
+	fc.Lock()
+	defer fc.Unlock()
+	fc.requests = append(fc.requests, req)
+	md, _ := metadata.FromIncomingContext(ctx)
+	fc.authorizations = append(fc.authorizations, md.Get("authorization")...)
+
+	if fc.err != nil {
+		return nil, fc.err
+	}
+
+	resp := &v1.BulkCheckPermissionResponse{}
+	for _, item := range req.Items {
+		subject := tuple.StringObjectRef(item.Subject.Object)
+		if item.Subject.OptionalRelation != "" {
+			subject += "#" + item.Subject.OptionalRelation
+		}
+
+		permissionship, ok := fc.permissions[tuple.StringObjectRef(item.Resource)+"#"+item.Permission+"@"+subject]
+		if !ok {
+			permissionship = v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION
+		}
+		resp.Pairs = append(resp.Pairs, &v1.BulkCheckPermissionPair{
+			Request: item,
+			Response: &v1.BulkCheckPermissionPair_Item{Item: &v1.BulkCheckPermissionResponseItem{
+				Permissionship: permissionship,
+			}},
+		})
+	}
+	return resp, nil
+}
+
+func newTestDispatcher(t *testing.T, cluster *fakeCluster, delegate dispatch.Dispatcher) *Dispatcher {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer()
+	v1.RegisterExperimentalServiceServer(server, cluster)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	d, err := NewDispatcher(delegate, Config{
+		RemoteNamespaces: map[string]string{"bu2/group": "bufnet", "bu2/team": "bufnet"},
+		Token:            "sometoken",
+		Insecure:         true,
+	}, grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+		return listener.DialContext(ctx)
+	}))
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = d.Close() })
+
+	// Namespaces of the same cluster share its connection.
+	require.Len(t, d.conns, 1)
+	return d
+}
+
+func checkRequest(namespace, relation string, resourceIDs []string, subject string) *dispatchv1.DispatchCheckRequest {
+	return &dispatchv1.DispatchCheckRequest{
+		ResourceRelation: tuple.RelationReference(namespace, relation),
+		ResourceIds:      resourceIDs,
+		Subject:          tuple.ParseSubjectONR(subject),
+		Metadata:         &dispatchv1.ResolverMeta{AtRevision: "1", DepthRemaining: 50},
+	}
+}
+
+func TestDelegatedCheck(t *testing.T) {
+	cluster := &fakeCluster{permissions: map[string]v1.CheckPermissionResponse_Permissionship{
+		"bu2/group:eng#member@user:alice":             v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION,
+		"bu2/group:sales#member@bu2/team:west#member": v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION,
+	}}
+	delegate := &fakeDispatcher{}
+	d := newTestDispatcher(t, cluster, delegate)
+
+	resp, err := d.DispatchCheck(context.Background(), checkRequest("bu2/group", "member", []string{"eng", "sales"}, "user:alice"))
+	require.NoError(t, err)
+	require.Equal(t, map[string]*dispatchv1.ResourceCheckResult{
+		"eng": {Membership: dispatchv1.ResourceCheckResult_MEMBER},
+	}, resp.ResultsByResourceId)
+	require.Equal(t, uint32(1), resp.Metadata.DispatchCount)
+	require.Equal(t, uint32(1), resp.Metadata.DepthRequired)
+
+	// Subject sets are checked with their relation.
+	resp, err = d.DispatchCheck(context.Background(), checkRequest("bu2/group", "member", []string{"eng", "sales"}, "bu2/team:west#member"))
+	require.NoError(t, err)
+	require.Len(t, resp.ResultsByResourceId, 1)
+	require.Contains(t, resp.ResultsByResourceId, "sales")
+
+	require.Len(t, cluster.requests, 2)
+	require.NotNil(t, cluster.requests[0].Consistency.GetFullyConsistent())
+	require.Equal(t, []string{"Bearer sometoken", "Bearer sometoken"}, cluster.authorizations)
+
+	// Checks of local namespaces are not delegated.
+	_, err = d.DispatchCheck(context.Background(), checkRequest("document", "view", []string{"readme"}, "user:alice"))
+	require.NoError(t, err)
+	require.Equal(t, 1, delegate.calls)
+	require.Len(t, cluster.requests, 2)
+}
+
+func TestDelegatedCheckErrors(t *testing.T) {
+	cluster := &fakeCluster{permissions: map[string]v1.CheckPermissionResponse_Permissionship{
+		"bu2/group:eng#member@user:alice": v1.CheckPermissionResponse_PERMISSIONSHIP_CONDITIONAL_PERMISSION,
+	}}
+	d := newTestDispatcher(t, cluster, &fakeDispatcher{})
+
+	_, err := d.DispatchCheck(context.Background(), checkRequest("bu2/group", "member", []string{"eng"}, "user:alice"))
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	require.ErrorContains(t, err, "conditional on caveats")
+
+	cluster.err = status.Error(codes.Unavailable, "down for maintenance")
+	_, err = d.DispatchCheck(context.Background(), checkRequest("bu2/group", "member", []string{"eng"}, "user:alice"))
+	require.Equal(t, codes.Unavailable, status.Code(err))
+	require.ErrorContains(t, err, "federated cluster of namespace `bu2/group` is unavailable")
+
+	cluster.err = status.Error(codes.PermissionDenied, "invalid token")
+	_, err = d.DispatchCheck(context.Background(), checkRequest("bu2/group", "member", []string{"eng"}, "user:alice"))
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// The depth limit applies to delegated checks.
+	req := checkRequest("bu2/group", "member", []string{"eng"}, "user:alice")
+	req.Metadata.DepthRemaining = 0
+	_, err = d.DispatchCheck(context.Background(), req)
+	require.Error(t, err)
+}
+
+func TestRemoteNamespacesNotLookedUp(t *testing.T) {
+	delegate := &fakeDispatcher{}
+	d := newTestDispatcher(t, &fakeCluster{}, delegate)
+
+	_, err := d.DispatchExpand(context.Background(), &dispatchv1.DispatchExpandRequest{
+		ResourceAndRelation: tuple.ParseONR("bu2/group:eng#member"),
+	})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	stream := dispatch.NewCollectingDispatchStream[*dispatchv1.DispatchLookupSubjectsResponse](context.Background())
+	err = d.DispatchLookupSubjects(&dispatchv1.DispatchLookupSubjectsRequest{
+		ResourceRelation: tuple.RelationReference("document", "view"),
+		SubjectRelation:  tuple.RelationReference("bu2/group", "member"),
+	}, stream)
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	require.ErrorContains(t, err, "lookup is not supported for remote namespace `bu2/group`")
+
+	err = d.DispatchLookupSubjects(&dispatchv1.DispatchLookupSubjectsRequest{
+		ResourceRelation: tuple.RelationReference("document", "view"),
+		SubjectRelation:  tuple.RelationReference("user", "..."),
+	}, stream)
+	require.NoError(t, err)
+	require.Equal(t, 1, delegate.calls)
+}
+
+type fakeDispatcher struct {
+	calls int
+}
+
+func (f *fakeDispatcher) DispatchCheck(_ context.Context, _ *dispatchv1.DispatchCheckRequest) (*dispatchv1.DispatchCheckResponse, error) {
+	f.calls++
+	return &dispatchv1.DispatchCheckResponse{}, nil
+}
+
+func (f *fakeDispatcher) DispatchExpand(_ context.Context, _ *dispatchv1.DispatchExpandRequest) (*dispatchv1.DispatchExpandResponse, error) {
+	f.calls++
+	return &dispatchv1.DispatchExpandResponse{}, nil
+}
+
+func (f *fakeDispatcher) DispatchReachableResources(_ *dispatchv1.DispatchReachableResourcesRequest, _ dispatch.ReachableResourcesStream) error {
+	f.calls++
+	return nil
+}
+
+func (f *fakeDispatcher) DispatchLookupResources(_ *dispatchv1.DispatchLookupResourcesRequest, _ dispatch.LookupResourcesStream) error {
+	f.calls++
+	return nil
+}
+
+func (f *fakeDispatcher) DispatchLookupSubjects(_ *dispatchv1.DispatchLookupSubjectsRequest, _ dispatch.LookupSubjectsStream) error {
+	f.calls++
+	return nil
+}
+
+func (f *fakeDispatcher) Close() error {
+	return nil
+}
+
+func (f *fakeDispatcher) ReadyState() dispatch.ReadyState {
+	return dispatch.ReadyState{IsReady: true}
+}
@@ -5,9 +5,12 @@ import (
 	"fmt"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/authzed/grpcutil"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/goleak"
+	"google.golang.org/grpc/codes"
 
 	"github.com/authzed/spicedb/internal/datastore/common"
 	"github.com/authzed/spicedb/internal/datastore/memdb"
@@ -15,6 +18,7 @@ import (
 	"github.com/authzed/spicedb/internal/dispatch/caching"
 	"github.com/authzed/spicedb/internal/dispatch/keys"
 	"github.com/authzed/spicedb/internal/graph"
+	"github.com/authzed/spicedb/internal/graph/groupindex"
 	log "github.com/authzed/spicedb/internal/logging"
 	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
 	"github.com/authzed/spicedb/internal/testfixtures"
@@ -186,6 +190,130 @@ func TestMaxDepth(t *testing.T) {
 	require.Error(err)
 }
 
+func TestCycleDetection(t *testing.T) {
+	ctx, dispatcher, revision := newLocalDispatcherWithSchemaAndRels(t, `
+		definition user {}
+
+		definition group {
+			relation member: user | group#member
+		}
+	`, []*core.RelationTuple{
+		tuple.MustParse("group:first#member@group:second#member"),
+		tuple.MustParse("group:second#member@group:first#member"),
+	})
+
+	_, err := dispatcher.DispatchCheck(ctx, &v1.DispatchCheckRequest{
+		ResourceRelation: RR("group", "member"),
+		ResourceIds:      []string{"first"},
+		ResultsSetting:   v1.DispatchCheckRequest_ALLOW_SINGLE_RESULT,
+		Subject:          ONR("user", "tom", graph.Ellipsis),
+		Metadata: &v1.ResolverMeta{
+			AtRevision:     revision.String(),
+			DepthRemaining: 50,
+		},
+	})
+
+	var cycleErr dispatch.CycleDetectedError
+	require.ErrorAs(t, err, &cycleErr)
+	require.Equal(t, []string{
+		"group:first#member@user:tom",
+		"group:second#member@user:tom",
+		"group:first#member@user:tom",
+	}, cycleErr.Path)
+}
+
+func TestCheckWithGroupIndex(t *testing.T) {
+	rels := []*core.RelationTuple{tuple.MustParse("group:level12#member@user:tom")}
+	for i := 0; i < 12; i++ {
+		rels = append(rels, tuple.MustParse(fmt.Sprintf("group:level%d#member@group:level%d#member", i, i+1)))
+	}
+
+	ctx, _, revision := newLocalDispatcherWithSchemaAndRels(t, `
+		definition user {}
+
+		definition group {
+			relation member: user | group#member
+		}
+	`, rels)
+
+	idx, err := groupindex.NewIndex([]string{"group#member"}, time.Minute)
+	require.NoError(t, err)
+
+	indexCtx, cancel := context.WithCancel(ctx)
+	done := make(chan error)
+	go func() {
+		done <- idx.Run(indexCtx, datastoremw.MustFromContext(ctx))
+	}()
+	defer func() {
+		cancel()
+		require.NoError(t, <-done)
+	}()
+
+	require.Eventually(t, func() bool {
+		_, ok := idx.Members(RR("group", "member"), []string{"level0"}, ONR("user", "tom", graph.Ellipsis), revision)
+		return ok
+	}, 5*time.Second, 10*time.Millisecond)
+
+	dispatcher := NewDispatcherWithGroupIndex(NewLocalOnlyDispatcher(10), SharedConcurrencyLimits(10), idx)
+
+	// The nesting is deeper than the depth remaining, so the check can only be answered by the index.
+	resp, err := dispatcher.DispatchCheck(ctx, &v1.DispatchCheckRequest{
+		ResourceRelation: RR("group", "member"),
+		ResourceIds:      []string{"level0", "unrelated"},
+		ResultsSetting:   v1.DispatchCheckRequest_REQUIRE_ALL_RESULTS,
+		Subject:          ONR("user", "tom", graph.Ellipsis),
+		Metadata: &v1.ResolverMeta{
+			AtRevision:     revision.String(),
+			DepthRemaining: 5,
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.ResultsByResourceId, 1)
+	require.Equal(t, v1.ResourceCheckResult_MEMBER, resp.ResultsByResourceId["level0"].Membership)
+}
+
+func TestCheckTraversalLimits(t *testing.T) {
+	rels := []*core.RelationTuple{tuple.MustParse("group:level5#member@user:tom")}
+	for i := 0; i < 5; i++ {
+		rels = append(rels, tuple.MustParse(fmt.Sprintf("group:level%d#member@group:level%d#member", i, i+1)))
+	}
+
+	ctx, _, revision := newLocalDispatcherWithSchemaAndRels(t, `
+		definition user {}
+
+		definition group {
+			relation member: user | group#member
+		}
+	`, rels)
+
+	check := func(limits graph.CheckTraversalLimits) (*v1.DispatchCheckResponse, error) {
+		dispatcher := NewDispatcherWithCheckTraversalLimits(NewLocalOnlyDispatcher(10), SharedConcurrencyLimits(10), nil, limits)
+		return dispatcher.DispatchCheck(ctx, &v1.DispatchCheckRequest{
+			ResourceRelation: RR("group", "member"),
+			ResourceIds:      []string{"level0"},
+			ResultsSetting:   v1.DispatchCheckRequest_ALLOW_SINGLE_RESULT,
+			Subject:          ONR("user", "tom", graph.Ellipsis),
+			Metadata: &v1.ResolverMeta{
+				AtRevision:     revision.String(),
+				DepthRemaining: 50,
+			},
+		})
+	}
+
+	resp, err := check(graph.CheckTraversalLimits{MaxRelationships: 100, MaxSubproblems: 100})
+	require.NoError(t, err)
+	require.Equal(t, v1.ResourceCheckResult_MEMBER, resp.ResultsByResourceId["level0"].Membership)
+
+	// Each level of the hierarchy is a subproblem, which reads two relationships.
+	_, err = check(graph.CheckTraversalLimits{MaxSubproblems: 3})
+	grpcutil.RequireStatus(t, codes.ResourceExhausted, err)
+	require.ErrorContains(t, err, "maximum of 3 subproblems")
+
+	_, err = check(graph.CheckTraversalLimits{MaxRelationships: 3})
+	grpcutil.RequireStatus(t, codes.ResourceExhausted, err)
+	require.ErrorContains(t, err, "maximum of 3 relationships")
+}
+
 func TestCheckMetadata(t *testing.T) {
 	type expected struct {
 		relation              string
@@ -0,0 +1,103 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/authzed/spicedb/internal/dispatch"
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// maxPathResourceIDs is the maximum number of resource IDs shown for each step of a cycle path.
+const maxPathResourceIDs = 3
+
+type checkPathKey struct{}
+
+// checkPath is a step in the chain of check requests that led to the current request.
+type checkPath struct {
+	parent *checkPath
+	req    *v1.DispatchCheckRequest
+}
+
+// withCheckPath returns a context recording the check request as the latest step in the
+// path of requests being resolved. The path does not extend across dispatches to other nodes.
+func withCheckPath(ctx context.Context, req *v1.DispatchCheckRequest) context.Context {
+	parent, _ := ctx.Value(checkPathKey{}).(*checkPath)
+	return context.WithValue(ctx, checkPathKey{}, &checkPath{parent: parent, req: req})
+}
+
+// maxDepthError returns the error for a check request which has exhausted its depth. If
+// one of its resources was already being checked for the same subject further up the path,
+// the error is a CycleDetectedError identifying the shortest such cycle.
+//
+// Cycles are only reported once the depth is exhausted, as a cycle in the data is not an
+// error if the check can be answered by another branch first.
+func maxDepthError(ctx context.Context, req *v1.DispatchCheckRequest, depthErr error) error {
+	if !errors.As(depthErr, &dispatch.MaxDepthExceededError{}) {
+		return depthErr
+	}
+
+	current, _ := ctx.Value(checkPathKey{}).(*checkPath)
+
+	var resourceIDs map[string]struct{}
+	for ancestor := current; ancestor != nil; ancestor = ancestor.parent {
+		if !sameRelationAndSubject(ancestor.req, req) {
+			continue
+		}
+
+		if resourceIDs == nil {
+			resourceIDs = make(map[string]struct{}, len(req.ResourceIds))
+			for _, resourceID := range req.ResourceIds {
+				resourceIDs[resourceID] = struct{}{}
+			}
+		}
+
+		for _, resourceID := range ancestor.req.ResourceIds {
+			if _, ok := resourceIDs[resourceID]; ok {
+				return dispatch.NewCycleDetectedError(req, cyclePath(current, ancestor, req, resourceID))
+			}
+		}
+	}
+
+	return depthErr
+}
+
+func sameRelationAndSubject(first, second *v1.DispatchCheckRequest) bool {
+	return first.ResourceRelation.Namespace == second.ResourceRelation.Namespace &&
+		first.ResourceRelation.Relation == second.ResourceRelation.Relation &&
+		first.Subject.Namespace == second.Subject.Namespace &&
+		first.Subject.ObjectId == second.Subject.ObjectId &&
+		first.Subject.Relation == second.Subject.Relation
+}
+
+// cyclePath returns the steps from the ancestor to the request, which both check the repeated resource.
+func cyclePath(current, ancestor *checkPath, req *v1.DispatchCheckRequest, resourceID string) []string {
+	steps := []string{describeCheck(req, []string{resourceID})}
+	for step := current; step != ancestor; step = step.parent {
+		steps = append(steps, describeCheck(step.req, step.req.ResourceIds))
+	}
+	steps = append(steps, describeCheck(ancestor.req, []string{resourceID}))
+
+	// Reverse, so the path runs from the ancestor to the request.
+	for i, j := 0, len(steps)-1; i < j; i, j = i+1, j-1 {
+		steps[i], steps[j] = steps[j], steps[i]
+	}
+	return steps
+}
+
+func describeCheck(req *v1.DispatchCheckRequest, resourceIDs []string) string {
+	ids := resourceIDs
+	if len(ids) > maxPathResourceIDs {
+		ids = append(ids[:maxPathResourceIDs:maxPathResourceIDs], "...")
+	}
+
+	idString := strings.Join(ids, ",")
+	if len(resourceIDs) > 1 {
+		idString = "{" + idString + "}"
+	}
+
+	return fmt.Sprintf("%s:%s#%s@%s", req.ResourceRelation.Namespace, idString, req.ResourceRelation.Relation, tuple.StringONR(req.Subject))
+}
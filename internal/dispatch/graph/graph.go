@@ -14,8 +14,10 @@ import (
 
 	"github.com/authzed/spicedb/internal/dispatch"
 	"github.com/authzed/spicedb/internal/graph"
+	"github.com/authzed/spicedb/internal/graph/groupindex"
 	log "github.com/authzed/spicedb/internal/logging"
 	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/taskrunner"
 	"github.com/authzed/spicedb/pkg/datastore"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
@@ -34,6 +36,10 @@ type ConcurrencyLimits struct {
 	ReachableResources uint16 `debugmap:"visible"`
 	LookupResources    uint16 `debugmap:"visible"`
 	LookupSubjects     uint16 `debugmap:"visible"`
+
+	// CheckPerNode bounds the goroutines created across all the check requests being
+	// resolved by a dispatcher, beyond the first for each set operation. Zero places no bound.
+	CheckPerNode uint32 `debugmap:"visible"`
 }
 
 const defaultConcurrencyLimit = 50
@@ -49,6 +55,7 @@ func (cl ConcurrencyLimits) MarshalZerologObject(e *zerolog.Event) {
 	e.Uint16("concurrency-limit-lookup-resources", cl.LookupResources)
 	e.Uint16("concurrency-limit-lookup-subjects", cl.LookupSubjects)
 	e.Uint16("concurrency-limit-reachable-resources", cl.ReachableResources)
+	e.Uint32("concurrency-limit-check-permission-per-node", cl.CheckPerNode)
 }
 
 func limitsOrDefaults(limits ConcurrencyLimits, overallDefaultLimit uint16) ConcurrencyLimits {
@@ -89,7 +96,7 @@ func NewLocalOnlyDispatcherWithLimits(concurrencyLimits ConcurrencyLimits) dispa
 
 	concurrencyLimits = limitsOrDefaults(concurrencyLimits, defaultConcurrencyLimit)
 
-	d.checker = graph.NewConcurrentChecker(d, concurrencyLimits.Check)
+	d.checker = graph.NewConcurrentChecker(d, concurrencyLimits.Check, taskrunner.NewSharedLimit(concurrencyLimits.CheckPerNode), nil)
 	d.expander = graph.NewConcurrentExpander(d)
 	d.reachableResourcesHandler = graph.NewCursoredReachableResources(d, concurrencyLimits.ReachableResources)
 	d.lookupResourcesHandler = graph.NewCursoredLookupResources(d, d, concurrencyLimits.LookupResources)
@@ -101,9 +108,22 @@ func NewLocalOnlyDispatcherWithLimits(concurrencyLimits ConcurrencyLimits) dispa
 // NewDispatcher creates a dispatcher that consults with the graph and redispatches subproblems to
 // the provided redispatcher.
 func NewDispatcher(redispatcher dispatch.Dispatcher, concurrencyLimits ConcurrencyLimits) dispatch.Dispatcher {
+	return NewDispatcherWithGroupIndex(redispatcher, concurrencyLimits, nil)
+}
+
+// NewDispatcherWithGroupIndex creates a dispatcher that consults with the graph and redispatches
+// subproblems to the provided redispatcher, answering checks of the relations in the group index
+// from the index where it can.
+func NewDispatcherWithGroupIndex(redispatcher dispatch.Dispatcher, concurrencyLimits ConcurrencyLimits, groupIndex *groupindex.Index) dispatch.Dispatcher {
+	return NewDispatcherWithCheckTraversalLimits(redispatcher, concurrencyLimits, groupIndex, graph.CheckTraversalLimits{})
+}
+
+// NewDispatcherWithCheckTraversalLimits creates a dispatcher as NewDispatcherWithGroupIndex does,
+// which aborts checks entering it whose traversal exceeds the limits.
+func NewDispatcherWithCheckTraversalLimits(redispatcher dispatch.Dispatcher, concurrencyLimits ConcurrencyLimits, groupIndex *groupindex.Index, traversalLimits graph.CheckTraversalLimits) dispatch.Dispatcher {
 	concurrencyLimits = limitsOrDefaults(concurrencyLimits, defaultConcurrencyLimit)
 
-	checker := graph.NewConcurrentChecker(redispatcher, concurrencyLimits.Check)
+	checker := graph.NewConcurrentChecker(redispatcher, concurrencyLimits.Check, taskrunner.NewSharedLimit(concurrencyLimits.CheckPerNode), groupIndex)
 	expander := graph.NewConcurrentExpander(redispatcher)
 	reachableResourcesHandler := graph.NewCursoredReachableResources(redispatcher, concurrencyLimits.ReachableResources)
 	lookupResourcesHandler := graph.NewCursoredLookupResources(redispatcher, redispatcher, concurrencyLimits.LookupResources)
@@ -115,6 +135,7 @@ func NewDispatcher(redispatcher dispatch.Dispatcher, concurrencyLimits Concurren
 		reachableResourcesHandler: reachableResourcesHandler,
 		lookupResourcesHandler:    lookupResourcesHandler,
 		lookupSubjectsHandler:     lookupSubjectsHandler,
+		traversalLimits:           traversalLimits,
 	}
 }
 
@@ -124,6 +145,7 @@ type localDispatcher struct {
 	reachableResourcesHandler *graph.CursoredReachableResources
 	lookupResourcesHandler    *graph.CursoredLookupResources
 	lookupSubjectsHandler     *graph.ConcurrentLookupSubjects
+	traversalLimits           graph.CheckTraversalLimits
 }
 
 func (ld *localDispatcher) loadNamespace(ctx context.Context, nsName string, revision datastore.Revision) (*core.NamespaceDefinition, error) {
@@ -176,7 +198,7 @@ func (ld *localDispatcher) DispatchCheck(ctx context.Context, req *v1.DispatchCh
 				Metadata: &v1.ResponseMeta{
 					DispatchCount: 0,
 				},
-			}, rewriteError(ctx, err)
+			}, rewriteError(ctx, maxDepthError(ctx, req, err))
 		}
 
 		// NOTE: we return debug information here to ensure tooling can see the cycle.
@@ -189,7 +211,14 @@ func (ld *localDispatcher) DispatchCheck(ctx context.Context, req *v1.DispatchCh
 					},
 				},
 			},
-		}, rewriteError(ctx, err)
+		}, rewriteError(ctx, maxDepthError(ctx, req, err))
+	}
+
+	ctx = withCheckPath(ctx, req)
+
+	ctx = graph.ContextWithCheckTraversalLimits(ctx, ld.traversalLimits)
+	if err := graph.AddCheckSubproblem(ctx); err != nil {
+		return &v1.DispatchCheckResponse{Metadata: emptyMetadata}, rewriteError(ctx, err)
 	}
 
 	revision, err := ld.parseRevision(ctx, req.Metadata.AtRevision)
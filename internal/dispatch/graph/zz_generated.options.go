@@ -34,6 +34,7 @@ func (c *ConcurrencyLimits) ToOption() ConcurrencyLimitsOption {
 		to.ReachableResources = c.ReachableResources
 		to.LookupResources = c.LookupResources
 		to.LookupSubjects = c.LookupSubjects
+		to.CheckPerNode = c.CheckPerNode
 	}
 }
 
@@ -44,6 +45,7 @@ func (c ConcurrencyLimits) DebugMap() map[string]any {
 	debugMap["ReachableResources"] = helpers.DebugValue(c.ReachableResources, false)
 	debugMap["LookupResources"] = helpers.DebugValue(c.LookupResources, false)
 	debugMap["LookupSubjects"] = helpers.DebugValue(c.LookupSubjects, false)
+	debugMap["CheckPerNode"] = helpers.DebugValue(c.CheckPerNode, false)
 	return debugMap
 }
 
@@ -90,3 +92,10 @@ func WithLookupSubjects(lookupSubjects uint16) ConcurrencyLimitsOption {
 		c.LookupSubjects = lookupSubjects
 	}
 }
+
+// WithCheckPerNode returns an option that can set CheckPerNode on a ConcurrencyLimits
+func WithCheckPerNode(checkPerNode uint32) ConcurrencyLimitsOption {
+	return func(c *ConcurrencyLimits) {
+		c.CheckPerNode = checkPerNode
+	}
+}
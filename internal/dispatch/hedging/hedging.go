@@ -0,0 +1,234 @@
+// Package hedging implements a dispatcher that hedges slow requests to the dispatch
+// cluster by also evaluating them with a secondary dispatcher.
+package hedging
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/influxdata/tdigest"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/authzed/spicedb/internal/dispatch"
+	log "github.com/authzed/spicedb/internal/logging"
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+)
+
+var hedgeableCount = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "dispatch",
+	Name:      "hedgeable_requests_total",
+	Help:      "total number of dispatch requests which are eligible for hedging",
+}, []string{"method"})
+
+var hedgedCount = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "dispatch",
+	Name:      "hedged_requests_total",
+	Help:      "total number of dispatch requests which have been hedged",
+}, []string{"method"})
+
+var wastedCount = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "dispatch",
+	Name:      "hedged_requests_wasted_total",
+	Help:      "total number of hedged dispatch requests for which the original request answered first",
+}, []string{"method"})
+
+const (
+	minMaxRequestsThreshold   = 1000
+	defaultTDigestCompression = float64(1000)
+)
+
+// NewDispatcher returns a dispatcher which sends check and expand requests to the primary
+// dispatcher and, if it has not answered once the given quantile of its historical latency
+// has elapsed, also to the secondary dispatcher, returning whichever answers first. Other
+// requests are only sent to the primary dispatcher.
+func NewDispatcher(
+	primary, secondary dispatch.Dispatcher,
+	initialSlowRequestThreshold time.Duration,
+	maxSampleCount uint64,
+	hedgingQuantile float64,
+) (dispatch.Dispatcher, error) {
+	return newDispatcherWithTimeSource(primary, secondary, initialSlowRequestThreshold, maxSampleCount, hedgingQuantile, clock.New())
+}
+
+func newDispatcherWithTimeSource(
+	primary, secondary dispatch.Dispatcher,
+	initialSlowRequestThreshold time.Duration,
+	maxSampleCount uint64,
+	hedgingQuantile float64,
+	timeSource clock.Clock,
+) (dispatch.Dispatcher, error) {
+	if initialSlowRequestThreshold < 0 {
+		return nil, fmt.Errorf("initial slow request threshold negative")
+	}
+
+	if maxSampleCount < minMaxRequestsThreshold {
+		return nil, fmt.Errorf("maxSampleCount must be >=%d", minMaxRequestsThreshold)
+	}
+
+	if hedgingQuantile <= 0.0 || hedgingQuantile >= 1.0 {
+		return nil, fmt.Errorf("hedgingQuantile must be in the range (0.0-1.0) exclusive")
+	}
+
+	return &Dispatcher{
+		primary:       primary,
+		secondary:     secondary,
+		checkLatency:  newLatencyTracker(initialSlowRequestThreshold, maxSampleCount, hedgingQuantile),
+		expandLatency: newLatencyTracker(initialSlowRequestThreshold, maxSampleCount, hedgingQuantile),
+		timeSource:    timeSource,
+	}, nil
+}
+
+type Dispatcher struct {
+	primary   dispatch.Dispatcher
+	secondary dispatch.Dispatcher
+
+	checkLatency  *latencyTracker
+	expandLatency *latencyTracker
+	timeSource    clock.Clock
+}
+
+func (d *Dispatcher) DispatchCheck(ctx context.Context, req *v1.DispatchCheckRequest) (*v1.DispatchCheckResponse, error) {
+	return hedge(ctx, d, "DispatchCheck", d.checkLatency, func(ctx context.Context, disp dispatch.Dispatcher) (*v1.DispatchCheckResponse, error) {
+		return disp.DispatchCheck(ctx, req)
+	})
+}
+
+func (d *Dispatcher) DispatchExpand(ctx context.Context, req *v1.DispatchExpandRequest) (*v1.DispatchExpandResponse, error) {
+	return hedge(ctx, d, "DispatchExpand", d.expandLatency, func(ctx context.Context, disp dispatch.Dispatcher) (*v1.DispatchExpandResponse, error) {
+		return disp.DispatchExpand(ctx, req)
+	})
+}
+
+func (d *Dispatcher) DispatchReachableResources(req *v1.DispatchReachableResourcesRequest, stream dispatch.ReachableResourcesStream) error {
+	return d.primary.DispatchReachableResources(req, stream)
+}
+
+func (d *Dispatcher) DispatchLookupResources(req *v1.DispatchLookupResourcesRequest, stream dispatch.LookupResourcesStream) error {
+	return d.primary.DispatchLookupResources(req, stream)
+}
+
+func (d *Dispatcher) DispatchLookupSubjects(req *v1.DispatchLookupSubjectsRequest, stream dispatch.LookupSubjectsStream) error {
+	return d.primary.DispatchLookupSubjects(req, stream)
+}
+
+func (d *Dispatcher) Close() error {
+	return d.primary.Close()
+}
+
+func (d *Dispatcher) ReadyState() dispatch.ReadyState {
+	return d.primary.ReadyState()
+}
+
+type hedgedResult[S any] struct {
+	resp   S
+	err    error
+	hedged bool
+}
+
+func hedge[S any](
+	ctx context.Context,
+	d *Dispatcher,
+	method string,
+	latency *latencyTracker,
+	handler func(context.Context, dispatch.Dispatcher) (S, error),
+) (S, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	hedgeableCount.WithLabelValues(method).Inc()
+
+	// Buffered so that the request which does not answer first does not block.
+	results := make(chan hedgedResult[S], 2)
+	start := d.timeSource.Now()
+	go func() {
+		resp, err := handler(ctx, d.primary)
+		results <- hedgedResult[S]{resp: resp, err: err}
+	}()
+
+	slowRequestThreshold := latency.threshold()
+	timer := d.timeSource.Timer(slowRequestThreshold)
+	defer timer.Stop()
+
+	var result hedgedResult[S]
+	select {
+	case result = <-results:
+	case <-timer.C:
+		log.Ctx(ctx).Debug().Str("method", method).Dur("after", slowRequestThreshold).Msg("sending hedged dispatch request")
+		hedgedCount.WithLabelValues(method).Inc()
+
+		go func() {
+			resp, err := handler(ctx, d.secondary)
+			results <- hedgedResult[S]{resp: resp, err: err, hedged: true}
+		}()
+
+		result = <-results
+		if !result.hedged {
+			wastedCount.WithLabelValues(method).Inc()
+		}
+	}
+
+	latency.add(ctx, d.timeSource.Since(start))
+	return result.resp, result.err
+}
+
+// latencyTracker tracks the historical latency of requests to determine when a request is slow.
+type latencyTracker struct {
+	lock           sync.Mutex
+	digests        []*tdigest.TDigest
+	maxSampleCount uint64
+	quantile       float64
+}
+
+func newLatencyTracker(initialSlowRequestThreshold time.Duration, maxSampleCount uint64, quantile float64) *latencyTracker {
+	digests := []*tdigest.TDigest{
+		tdigest.NewWithCompression(defaultTDigestCompression),
+		tdigest.NewWithCompression(defaultTDigestCompression),
+	}
+
+	// We pre-load the first digest with the initial slow request threshold at a weight
+	// such that we have reasonable data for our first request and so the other digest
+	// will be out of phase with this one, meaning when the first digest gets to
+	// maxSampleCount, the other digest will already be 50% warmed up.
+	digests[0].Add(initialSlowRequestThreshold.Seconds(), float64(maxSampleCount)/2)
+
+	return &latencyTracker{
+		digests:        digests,
+		maxSampleCount: maxSampleCount,
+		quantile:       quantile,
+	}
+}
+
+func (lt *latencyTracker) threshold() time.Duration {
+	lt.lock.Lock()
+	defer lt.lock.Unlock()
+	return time.Duration(lt.digests[0].Quantile(lt.quantile) * float64(time.Second))
+}
+
+func (lt *latencyTracker) add(ctx context.Context, duration time.Duration) {
+	lt.lock.Lock()
+	defer lt.lock.Unlock()
+
+	// Swap the current active digest if it has too many samples
+	if lt.digests[0].Count() >= float64(lt.maxSampleCount) {
+		log.Ctx(ctx).Trace().Float64("count", lt.digests[0].Count()).Msg("switching to next dispatch hedging digest")
+		exhausted := lt.digests[0]
+		lt.digests = lt.digests[1:]
+		exhausted.Reset()
+		lt.digests = append(lt.digests, exhausted)
+	}
+
+	durSeconds := duration.Seconds()
+	for _, digest := range lt.digests {
+		digest.Add(durSeconds, 1)
+	}
+}
+
+// Always verify that we implement the interface
+var _ dispatch.Dispatcher = &Dispatcher{}
@@ -0,0 +1,120 @@
+package hedging
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/benbjohnson/clock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/dispatch"
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+)
+
+const slowRequestThreshold = 10 * time.Millisecond
+
+func TestNewDispatcherValidation(t *testing.T) {
+	_, err := NewDispatcher(nil, nil, -1, 1000, 0.95)
+	require.ErrorContains(t, err, "initial slow request threshold negative")
+
+	_, err = NewDispatcher(nil, nil, slowRequestThreshold, 10, 0.95)
+	require.ErrorContains(t, err, "maxSampleCount must be")
+
+	_, err = NewDispatcher(nil, nil, slowRequestThreshold, 1000, 1)
+	require.ErrorContains(t, err, "hedgingQuantile must be")
+
+	_, err = NewDispatcher(nil, nil, slowRequestThreshold, 1000, 0.95)
+	require.NoError(t, err)
+}
+
+func TestFastPrimaryIsNotHedged(t *testing.T) {
+	mockTime := clock.NewMock()
+	primary := &fakeDispatcher{result: 1}
+	secondary := &fakeDispatcher{result: 2}
+
+	disp, err := newDispatcherWithTimeSource(primary, secondary, slowRequestThreshold, 1000, 0.95, mockTime)
+	require.NoError(t, err)
+
+	resp, err := disp.DispatchCheck(context.Background(), &v1.DispatchCheckRequest{})
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), resp.Metadata.DispatchCount)
+	require.Equal(t, 0, int(secondary.calls.Load()))
+}
+
+func TestSlowPrimaryIsHedged(t *testing.T) {
+	mockTime := clock.NewMock()
+	primary := &fakeDispatcher{result: 1, block: true}
+	secondary := &fakeDispatcher{result: 2}
+
+	disp, err := newDispatcherWithTimeSource(primary, secondary, slowRequestThreshold, 1000, 0.95, mockTime)
+	require.NoError(t, err)
+
+	done := autoAdvance(mockTime, slowRequestThreshold)
+	defer close(done)
+
+	resp, err := disp.DispatchCheck(context.Background(), &v1.DispatchCheckRequest{})
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), resp.Metadata.DispatchCount)
+	require.Equal(t, 1, int(secondary.calls.Load()))
+
+	// The slower primary request is canceled once the hedged request answers.
+	require.Eventually(t, func() bool {
+		return int(primary.canceled.Load()) == 1
+	}, time.Second, time.Millisecond)
+}
+
+func TestStreamingIsNotHedged(t *testing.T) {
+	primary := &fakeDispatcher{}
+	secondary := &fakeDispatcher{}
+
+	disp, err := NewDispatcher(primary, secondary, slowRequestThreshold, 1000, 0.95)
+	require.NoError(t, err)
+
+	err = disp.DispatchLookupSubjects(&v1.DispatchLookupSubjectsRequest{}, nil)
+	require.NoError(t, err)
+	require.Equal(t, 1, int(primary.calls.Load()))
+	require.Equal(t, 0, int(secondary.calls.Load()))
+}
+
+// autoAdvance advances the mock clock until done is closed.
+func autoAdvance(mockTime *clock.Mock, step time.Duration) chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case <-time.After(time.Millisecond):
+				mockTime.Add(step)
+			}
+		}
+	}()
+	return done
+}
+
+type fakeDispatcher struct {
+	dispatch.Dispatcher
+
+	result uint32
+	block  bool
+
+	calls    atomic.Int32
+	canceled atomic.Int32
+}
+
+func (fd *fakeDispatcher) DispatchCheck(ctx context.Context, _ *v1.DispatchCheckRequest) (*v1.DispatchCheckResponse, error) {
+	fd.calls.Add(1)
+	if fd.block {
+		<-ctx.Done()
+		fd.canceled.Add(1)
+		return nil, ctx.Err()
+	}
+	return &v1.DispatchCheckResponse{Metadata: &v1.ResponseMeta{DispatchCount: fd.result}}, nil
+}
+
+func (fd *fakeDispatcher) DispatchLookupSubjects(_ *v1.DispatchLookupSubjectsRequest, _ dispatch.LookupSubjectsStream) error {
+	fd.calls.Add(1)
+	return nil
+}
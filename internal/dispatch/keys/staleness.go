@@ -0,0 +1,65 @@
+package keys
+
+import (
+	"context"
+	"time"
+
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+)
+
+// epochMultiplier spreads consecutive epochs across the bits of the cache key.
+const epochMultiplier = 0x9E3779B97F4A7C15
+
+// BoundedStalenessKeyHandler is a key handler whose cache keys change every MaxStaleness, so
+// that cached results are not used for longer than that even when the revision at which
+// they are requested does not change. This is required when results depend on data not
+// covered by the revision, such as that of federated clusters. Dispatch keys are unchanged.
+type BoundedStalenessKeyHandler struct {
+	Handler
+
+	// MaxStaleness is the longest time for which a cached result is used. Zero disables
+	// the bound.
+	MaxStaleness time.Duration
+
+	now func() time.Time
+}
+
+// withEpoch returns the key combined with the current epoch of MaxStaleness.
+func (b *BoundedStalenessKeyHandler) withEpoch(key DispatchCacheKey, err error) (DispatchCacheKey, error) {
+	if err != nil || b.MaxStaleness <= 0 {
+		return key, err
+	}
+
+	now := time.Now
+	if b.now != nil {
+		now = b.now
+	}
+
+	mixed := uint64(now().UnixNano()/int64(b.MaxStaleness)) * epochMultiplier
+	return DispatchCacheKey{
+		stableSum:          key.stableSum ^ mixed,
+		processSpecificSum: key.processSpecificSum ^ mixed,
+	}, nil
+}
+
+func (b *BoundedStalenessKeyHandler) CheckCacheKey(ctx context.Context, req *v1.DispatchCheckRequest) (DispatchCacheKey, error) {
+	return b.withEpoch(b.Handler.CheckCacheKey(ctx, req))
+}
+
+func (b *BoundedStalenessKeyHandler) LookupResourcesCacheKey(ctx context.Context, req *v1.DispatchLookupResourcesRequest) (DispatchCacheKey, error) {
+	return b.withEpoch(b.Handler.LookupResourcesCacheKey(ctx, req))
+}
+
+func (b *BoundedStalenessKeyHandler) LookupSubjectsCacheKey(ctx context.Context, req *v1.DispatchLookupSubjectsRequest) (DispatchCacheKey, error) {
+	return b.withEpoch(b.Handler.LookupSubjectsCacheKey(ctx, req))
+}
+
+func (b *BoundedStalenessKeyHandler) ExpandCacheKey(ctx context.Context, req *v1.DispatchExpandRequest) (DispatchCacheKey, error) {
+	return b.withEpoch(b.Handler.ExpandCacheKey(ctx, req))
+}
+
+func (b *BoundedStalenessKeyHandler) ReachableResourcesCacheKey(ctx context.Context, req *v1.DispatchReachableResourcesRequest) (DispatchCacheKey, error) {
+	return b.withEpoch(b.Handler.ReachableResourcesCacheKey(ctx, req))
+}
+
+var _ Handler = &BoundedStalenessKeyHandler{}
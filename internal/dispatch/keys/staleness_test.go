@@ -0,0 +1,60 @@
+package keys
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+)
+
+func TestBoundedStalenessKeys(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	handler := &BoundedStalenessKeyHandler{
+		Handler:      &DirectKeyHandler{},
+		MaxStaleness: 10 * time.Second,
+		now:          func() time.Time { return now },
+	}
+
+	req := &v1.DispatchCheckRequest{
+		ResourceRelation: RR("document", "view"),
+		ResourceIds:      []string{"foo"},
+		Subject:          ONR("user", "tom", "..."),
+		Metadata:         &v1.ResolverMeta{AtRevision: "1234"},
+	}
+
+	ctx := context.Background()
+	first, err := handler.CheckCacheKey(ctx, req)
+	require.NoError(t, err)
+
+	unbounded, err := handler.Handler.CheckCacheKey(ctx, req)
+	require.NoError(t, err)
+	require.NotEqual(t, unbounded, first)
+
+	// Keys are the same within the bound.
+	now = now.Add(9 * time.Second)
+	second, err := handler.CheckCacheKey(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, first, second)
+
+	// And change once it has passed.
+	now = now.Add(time.Second)
+	third, err := handler.CheckCacheKey(ctx, req)
+	require.NoError(t, err)
+	require.NotEqual(t, first, third)
+
+	// Dispatch keys never change, so that requests are routed to the same peers.
+	dispatchKey, err := handler.CheckDispatchKey(ctx, req)
+	require.NoError(t, err)
+	unboundedDispatchKey, err := handler.Handler.CheckDispatchKey(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, unboundedDispatchKey, dispatchKey)
+
+	// A zero bound leaves keys unchanged.
+	handler.MaxStaleness = 0
+	unchanged, err := handler.CheckCacheKey(ctx, req)
+	require.NoError(t, err)
+	require.Equal(t, unbounded, unchanged)
+}
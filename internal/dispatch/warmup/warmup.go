@@ -0,0 +1,338 @@
+// Package warmup implements a dispatcher that warms the caches of a newly started node,
+// before it reports itself ready, by replaying the subproblems most frequently seen by
+// the previous instance.
+package warmup
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/authzed/spicedb/internal/dispatch"
+	log "github.com/authzed/spicedb/internal/logging"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/pkg/datastore"
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// Config configures the warm-up of a node's caches.
+type Config struct {
+	// File is the path to which the most frequent subproblems are persisted, and from
+	// which they are replayed on startup. If empty, only namespaces are preloaded.
+	File string
+
+	// MaxSubproblems is the maximum number of subproblems persisted and replayed.
+	MaxSubproblems int
+
+	// SampleRate is the fraction of check subproblems sampled to find the most frequent.
+	SampleRate float64
+
+	// SaveInterval is how often the sampled subproblems are persisted.
+	SaveInterval time.Duration
+
+	// Timeout bounds the warm-up, after which the node reports ready regardless.
+	Timeout time.Duration
+
+	// MaxDepth is the depth with which replayed subproblems are dispatched.
+	MaxDepth uint32
+}
+
+// NewDispatcher returns a dispatcher which reports itself not ready until Run has warmed
+// the caches, and which samples the check subproblems it is sent so that they can be
+// replayed by the next instance.
+func NewDispatcher(delegate dispatch.Dispatcher, config Config) *Dispatcher {
+	return &Dispatcher{
+		delegate: delegate,
+		config:   config,
+		sampled:  map[string]*sample{},
+	}
+}
+
+type Dispatcher struct {
+	delegate dispatch.Dispatcher
+	config   Config
+	warmed   atomic.Bool
+
+	lock    sync.Mutex
+	sampled map[string]*sample
+}
+
+type sample struct {
+	key   string
+	req   *v1.DispatchCheckRequest
+	count uint64
+}
+
+func (d *Dispatcher) DispatchCheck(ctx context.Context, req *v1.DispatchCheckRequest) (*v1.DispatchCheckResponse, error) {
+	// nolint:gosec
+	// G404 use of non cryptographically secure random number generator is not a concern here,
+	// as it is only used to sample requests.
+	if d.config.File != "" && rand.Float64() < d.config.SampleRate {
+		d.sample(req)
+	}
+	return d.delegate.DispatchCheck(ctx, req)
+}
+
+func (d *Dispatcher) DispatchExpand(ctx context.Context, req *v1.DispatchExpandRequest) (*v1.DispatchExpandResponse, error) {
+	return d.delegate.DispatchExpand(ctx, req)
+}
+
+func (d *Dispatcher) DispatchReachableResources(req *v1.DispatchReachableResourcesRequest, stream dispatch.ReachableResourcesStream) error {
+	return d.delegate.DispatchReachableResources(req, stream)
+}
+
+func (d *Dispatcher) DispatchLookupResources(req *v1.DispatchLookupResourcesRequest, stream dispatch.LookupResourcesStream) error {
+	return d.delegate.DispatchLookupResources(req, stream)
+}
+
+func (d *Dispatcher) DispatchLookupSubjects(req *v1.DispatchLookupSubjectsRequest, stream dispatch.LookupSubjectsStream) error {
+	return d.delegate.DispatchLookupSubjects(req, stream)
+}
+
+func (d *Dispatcher) Close() error {
+	return d.delegate.Close()
+}
+
+func (d *Dispatcher) ReadyState() dispatch.ReadyState {
+	if !d.warmed.Load() {
+		return dispatch.ReadyState{Message: "warming caches", IsReady: false}
+	}
+	return d.delegate.ReadyState()
+}
+
+// sample counts the subproblem, keyed without its revision and depth so that the same
+// subproblem is counted across revisions.
+func (d *Dispatcher) sample(req *v1.DispatchCheckRequest) {
+	resourceIDs := slices.Clone(req.ResourceIds)
+	slices.Sort(resourceIDs)
+	key := tuple.StringRR(req.ResourceRelation) + ":" + strings.Join(resourceIDs, ",") + "@" + tuple.StringONR(req.Subject)
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if existing, ok := d.sampled[key]; ok {
+		existing.count++
+		return
+	}
+
+	// Bound the memory used for sampling by discarding the least frequent subproblems.
+	if len(d.sampled) >= 10*d.config.MaxSubproblems {
+		d.retainMostFrequent(d.config.MaxSubproblems)
+	}
+
+	d.sampled[key] = &sample{
+		key: key,
+		req: &v1.DispatchCheckRequest{
+			ResourceRelation: req.ResourceRelation,
+			ResourceIds:      req.ResourceIds,
+			Subject:          req.Subject,
+			ResultsSetting:   req.ResultsSetting,
+		},
+		count: 1,
+	}
+}
+
+// retainMostFrequent discards all but the given number of most frequently sampled
+// subproblems, and returns those retained in order of frequency. Must be called with the
+// lock held.
+func (d *Dispatcher) retainMostFrequent(count int) []*v1.DispatchCheckRequest {
+	samples := make([]*sample, 0, len(d.sampled))
+	for _, s := range d.sampled {
+		samples = append(samples, s)
+	}
+	slices.SortFunc(samples, func(a, b *sample) int {
+		switch {
+		case a.count > b.count:
+			return -1
+		case a.count < b.count:
+			return 1
+		default:
+			return 0
+		}
+	})
+
+	if len(samples) <= count {
+		return requestsOf(samples)
+	}
+
+	for _, s := range samples[count:] {
+		delete(d.sampled, s.key)
+	}
+	return requestsOf(samples[:count])
+}
+
+func requestsOf(samples []*sample) []*v1.DispatchCheckRequest {
+	reqs := make([]*v1.DispatchCheckRequest, 0, len(samples))
+	for _, s := range samples {
+		reqs = append(reqs, s.req)
+	}
+	return reqs
+}
+
+// Run warms the caches and marks the dispatcher ready, then persists the most frequently
+// sampled subproblems periodically and once the context is canceled.
+func (d *Dispatcher) Run(ctx context.Context, ds datastore.Datastore) error {
+	start := time.Now()
+	if err := d.warm(ctx, ds); err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("error warming caches")
+	}
+	d.warmed.Store(true)
+	log.Ctx(ctx).Info().Dur("duration", time.Since(start)).Msg("finished warming caches")
+
+	if d.config.File == "" {
+		return nil
+	}
+
+	ticker := time.NewTicker(d.config.SaveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := d.save(); err != nil {
+				log.Ctx(ctx).Warn().Err(err).Msg("error persisting sampled subproblems")
+			}
+			return nil
+
+		case <-ticker.C:
+			if err := d.save(); err != nil {
+				log.Ctx(ctx).Warn().Err(err).Msg("error persisting sampled subproblems")
+			}
+		}
+	}
+}
+
+// warm preloads the namespace definitions and replays the persisted subproblems at the
+// current optimized revision, which is that used by requests made shortly after.
+func (d *Dispatcher) warm(ctx context.Context, ds datastore.Datastore) error {
+	ctx, cancel := context.WithTimeout(ctx, d.config.Timeout)
+	defer cancel()
+
+	revision, err := ds.OptimizedRevision(ctx)
+	if err != nil {
+		return fmt.Errorf("error reading revision: %w", err)
+	}
+
+	// Namespaces are listed and then looked up by name, as only lookups by name are cached.
+	reader := ds.SnapshotReader(revision)
+	namespaces, err := reader.ListAllNamespaces(ctx)
+	if err != nil {
+		return fmt.Errorf("error preloading namespaces: %w", err)
+	}
+
+	names := make([]string, 0, len(namespaces))
+	for _, namespace := range namespaces {
+		names = append(names, namespace.Definition.Name)
+	}
+	if _, err := reader.LookupNamespacesWithNames(ctx, names); err != nil {
+		return fmt.Errorf("error preloading namespaces: %w", err)
+	}
+	log.Ctx(ctx).Info().Int("count", len(names)).Msg("preloaded namespaces")
+
+	if d.config.File == "" {
+		return nil
+	}
+
+	reqs, err := load(d.config.File, d.config.MaxSubproblems)
+	if err != nil {
+		return err
+	}
+
+	ctx = datastoremw.ContextWithDatastore(ctx, ds)
+	failed := 0
+	for _, req := range reqs {
+		req.Metadata = &v1.ResolverMeta{
+			AtRevision:     revision.String(),
+			DepthRemaining: d.config.MaxDepth,
+		}
+
+		if _, err := d.delegate.DispatchCheck(ctx, req); err != nil {
+			if ctx.Err() != nil {
+				return fmt.Errorf("replayed %d of %d subproblems: %w", len(reqs)-failed, len(reqs), ctx.Err())
+			}
+			failed++
+		}
+	}
+
+	log.Ctx(ctx).Info().Int("count", len(reqs)).Int("failed", failed).Msg("replayed subproblems")
+	return nil
+}
+
+// save persists the most frequently sampled subproblems, one per line, replacing the file
+// atomically so that a concurrently starting instance never reads a partial file.
+func (d *Dispatcher) save() error {
+	d.lock.Lock()
+	reqs := d.retainMostFrequent(d.config.MaxSubproblems)
+	d.lock.Unlock()
+
+	if len(reqs) == 0 {
+		return nil
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(d.config.File), filepath.Base(d.config.File)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	writer := bufio.NewWriter(tmp)
+	for _, req := range reqs {
+		line, err := protojson.Marshal(req)
+		if err != nil {
+			_ = tmp.Close()
+			return err
+		}
+		_, _ = writer.Write(line)
+		_ = writer.WriteByte('\n')
+	}
+
+	if err := writer.Flush(); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), d.config.File)
+}
+
+// load reads up to the maximum number of persisted subproblems.
+func load(path string, maxSubproblems int) ([]*v1.DispatchCheckRequest, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("error reading persisted subproblems: %w", err)
+	}
+	defer file.Close()
+
+	var reqs []*v1.DispatchCheckRequest
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(nil, 1024*1024)
+	for scanner.Scan() && len(reqs) < maxSubproblems {
+		req := &v1.DispatchCheckRequest{}
+		if err := protojson.Unmarshal(scanner.Bytes(), req); err != nil {
+			return nil, fmt.Errorf("error parsing persisted subproblems: %w", err)
+		}
+		reqs = append(reqs, req)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading persisted subproblems: %w", err)
+	}
+	return reqs, nil
+}
+
+// Always verify that we implement the interface
+var _ dispatch.Dispatcher = &Dispatcher{}
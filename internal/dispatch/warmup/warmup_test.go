@@ -0,0 +1,132 @@
+package warmup
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/dispatch"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func checkRequest(resourceID, subject string) *v1.DispatchCheckRequest {
+	return &v1.DispatchCheckRequest{
+		ResourceRelation: &core.RelationReference{Namespace: "document", Relation: "view"},
+		ResourceIds:      []string{resourceID},
+		Subject:          tuple.ParseSubjectONR(subject),
+		Metadata:         &v1.ResolverMeta{AtRevision: "1", DepthRemaining: 10},
+	}
+}
+
+func TestWarmup(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "subproblems")
+	config := Config{
+		File:           file,
+		MaxSubproblems: 2,
+		SampleRate:     1,
+		SaveInterval:   time.Hour,
+		Timeout:        time.Minute,
+		MaxDepth:       50,
+	}
+
+	// Subproblems are sampled and the most frequent persisted.
+	previous := NewDispatcher(&fakeDispatcher{}, config)
+	for _, req := range []*v1.DispatchCheckRequest{
+		checkRequest("first", "user:tom"),
+		checkRequest("second", "user:tom"),
+		checkRequest("second", "user:tom"),
+		checkRequest("third", "user:tom"),
+		checkRequest("third", "user:tom"),
+		checkRequest("third", "user:tom"),
+	} {
+		_, err := previous.DispatchCheck(context.Background(), req)
+		require.NoError(t, err)
+	}
+	require.NoError(t, previous.save())
+
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	delegate := &fakeDispatcher{}
+	d := NewDispatcher(delegate, config)
+	require.False(t, d.ReadyState().IsReady)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- d.Run(ctx, ds)
+	}()
+
+	// The persisted subproblems are replayed, most frequent first, before the dispatcher is ready.
+	require.Eventually(t, func() bool {
+		return d.ReadyState().IsReady
+	}, 5*time.Second, 10*time.Millisecond)
+
+	replayed := delegate.received()
+	require.Len(t, replayed, 2)
+	require.Equal(t, []string{"third"}, replayed[0].ResourceIds)
+	require.Equal(t, []string{"second"}, replayed[1].ResourceIds)
+	require.Equal(t, uint32(50), replayed[0].Metadata.DepthRemaining)
+	require.NotEqual(t, "1", replayed[0].Metadata.AtRevision)
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestWarmupWithoutPersistedSubproblems(t *testing.T) {
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	delegate := &fakeDispatcher{}
+	d := NewDispatcher(delegate, Config{
+		File:           filepath.Join(t.TempDir(), "missing"),
+		MaxSubproblems: 10,
+		SaveInterval:   time.Hour,
+		Timeout:        time.Minute,
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- d.Run(ctx, ds)
+	}()
+
+	require.Eventually(t, func() bool {
+		return d.ReadyState().IsReady
+	}, 5*time.Second, 10*time.Millisecond)
+	require.Empty(t, delegate.received())
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+type fakeDispatcher struct {
+	dispatch.Dispatcher
+
+	lock sync.Mutex
+	reqs []*v1.DispatchCheckRequest
+}
+
+func (fd *fakeDispatcher) DispatchCheck(_ context.Context, req *v1.DispatchCheckRequest) (*v1.DispatchCheckResponse, error) {
+	fd.lock.Lock()
+	defer fd.lock.Unlock()
+	fd.reqs = append(fd.reqs, req)
+	return &v1.DispatchCheckResponse{}, nil
+}
+
+func (fd *fakeDispatcher) ReadyState() dispatch.ReadyState {
+	return dispatch.ReadyState{IsReady: true}
+}
+
+func (fd *fakeDispatcher) received() []*v1.DispatchCheckRequest {
+	fd.lock.Lock()
+	defer fd.lock.Unlock()
+	return fd.reqs
+}
@@ -0,0 +1,268 @@
+// Package fixtures generates randomized relationships for a schema, of a configurable size and
+// shape, which are the same for the same seed, so that large datasets can be reproduced to
+// test query plans and the behavior of caches.
+package fixtures
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	authzed "github.com/authzed/authzed-go/v1"
+	yamlv3 "gopkg.in/yaml.v3"
+
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// writeBatchSize is the number of relationships written at once to a server.
+const writeBatchSize = 500
+
+// Options configure the shape of the relationships generated.
+type Options struct {
+	// Objects is the number of objects of each definition, unless given by ObjectsByType.
+	Objects int
+
+	// ObjectsByType is the number of objects of the definitions it names.
+	ObjectsByType map[string]int
+
+	// Fanout is the mean number of subjects of each relation of each object, unless given by
+	// FanoutByRelation. The number of each object is chosen uniformly between zero and twice
+	// the mean.
+	Fanout int
+
+	// FanoutByRelation is the mean number of subjects of the relations it names, such as
+	// `document#viewer`.
+	FanoutByRelation map[string]int
+
+	// Skew is the exponent of the Zipf distribution with which subjects are chosen, so that a
+	// few subjects, such as popular groups, are related to many objects. Skews of at most one
+	// choose subjects uniformly.
+	Skew float64
+
+	// WildcardRate is the probability that an object has a public wildcard subject for each
+	// relation allowing one.
+	WildcardRate float64
+
+	// Seed seeds the choice of subjects.
+	Seed int64
+}
+
+func (o Options) objects(definition string) int {
+	if count, ok := o.ObjectsByType[definition]; ok {
+		return count
+	}
+	return o.Objects
+}
+
+func (o Options) fanout(definition, relation string) int {
+	if fanout, ok := o.FanoutByRelation[definition+"#"+relation]; ok {
+		return fanout
+	}
+	return o.Fanout
+}
+
+// Fixtures are a schema and relationships generated for it.
+type Fixtures struct {
+	Schema        string
+	Relationships []*core.RelationTuple
+}
+
+// Generate generates relationships for the schema. Objects are identified by their index
+// among the objects of their definition, such as `document:42`.
+//
+// A subject which is itself the resource of relationships, such as a group or a parent
+// folder, always has a lower index than the object to which it is related, so that the
+// relationships form no cycles, which checks could not resolve.
+func Generate(schema string, opts Options) (*Fixtures, error) {
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source("fixtures"),
+		SchemaString: schema,
+	}, compiler.AllowUnprefixedObjectType())
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+
+	for name, count := range opts.ObjectsByType {
+		if count < 0 {
+			return nil, fmt.Errorf("invalid number of objects of `%s`: %d", name, count)
+		}
+	}
+	for name, fanout := range opts.FanoutByRelation {
+		if fanout < 0 {
+			return nil, fmt.Errorf("invalid fanout of `%s`: %d", name, fanout)
+		}
+	}
+	if opts.Objects < 0 || opts.Fanout < 0 {
+		return nil, fmt.Errorf("the number of objects and the fanout must not be negative")
+	}
+	if opts.WildcardRate < 0 || opts.WildcardRate > 1 {
+		return nil, fmt.Errorf("invalid wildcard rate %v: must be between 0 and 1", opts.WildcardRate)
+	}
+
+	// Definitions with relations are the resources of relationships, and so must not be
+	// related in cycles.
+	hasRelations := make(map[string]bool, len(compiled.ObjectDefinitions))
+	for _, def := range compiled.ObjectDefinitions {
+		for _, rel := range def.Relation {
+			if hasRelationships(rel) {
+				hasRelations[def.Name] = true
+			}
+		}
+	}
+
+	g := &generator{opts: opts, rnd: rand.New(rand.NewSource(opts.Seed)), hasRelations: hasRelations}
+	for _, def := range compiled.ObjectDefinitions {
+		for _, rel := range def.Relation {
+			if hasRelationships(rel) {
+				g.relation(def.Name, rel)
+			}
+		}
+	}
+
+	return &Fixtures{Schema: schema, Relationships: g.rels}, nil
+}
+
+// hasRelationships returns whether the relation can have relationships, which permissions
+// cannot.
+func hasRelationships(rel *core.Relation) bool {
+	return rel.UsersetRewrite == nil && len(rel.GetTypeInformation().GetAllowedDirectRelations()) > 0
+}
+
+type generator struct {
+	opts         Options
+	rnd          *rand.Rand
+	hasRelations map[string]bool
+	rels         []*core.RelationTuple
+}
+
+func (g *generator) relation(definition string, rel *core.Relation) {
+	var allowed, wildcards []*core.AllowedRelation
+	for _, allowedRelation := range rel.TypeInformation.AllowedDirectRelations {
+		if allowedRelation.GetPublicWildcard() != nil {
+			wildcards = append(wildcards, allowedRelation)
+			continue
+		}
+		allowed = append(allowed, allowedRelation)
+	}
+
+	fanout := g.opts.fanout(definition, rel.Name)
+	for resourceID := 0; resourceID < g.opts.objects(definition); resourceID++ {
+		resource := &core.ObjectAndRelation{Namespace: definition, ObjectId: strconv.Itoa(resourceID), Relation: rel.Name}
+
+		for _, wildcard := range wildcards {
+			if g.rnd.Float64() < g.opts.WildcardRate {
+				g.add(resource, wildcard, tuple.PublicWildcard)
+			}
+		}
+
+		if len(allowed) == 0 || fanout == 0 {
+			continue
+		}
+
+		seen := map[string]struct{}{}
+		for count := g.rnd.Intn(2*fanout + 1); count > 0; count-- {
+			subjectType := allowed[g.rnd.Intn(len(allowed))]
+			subjectID, ok := g.subjectID(subjectType.Namespace, resourceID)
+			if !ok {
+				continue
+			}
+
+			key := subjectType.Namespace + ":" + subjectID + "#" + subjectType.GetRelation()
+			if _, ok := seen[key]; ok {
+				continue
+			}
+			seen[key] = struct{}{}
+			g.add(resource, subjectType, subjectID)
+		}
+	}
+}
+
+// subjectID chooses a subject of the definition for the object of the given index, if there
+// is one.
+func (g *generator) subjectID(subjectType string, resourceID int) (string, bool) {
+	count := g.opts.objects(subjectType)
+	if g.hasRelations[subjectType] {
+		// Subjects which are resources of relationships are chosen among the objects before
+		// the resource, so that the relationships form no cycles.
+		count = min(count, resourceID)
+	}
+	if count <= 0 {
+		return "", false
+	}
+
+	if g.opts.Skew <= 1 {
+		return strconv.Itoa(g.rnd.Intn(count)), true
+	}
+
+	// The most popular subjects are those with the lowest indexes, such as the folders near
+	// the root of a hierarchy.
+	index := rand.NewZipf(g.rnd, g.opts.Skew, 1, uint64(count-1)).Uint64()
+	return strconv.FormatUint(index, 10), true
+}
+
+func (g *generator) add(resource *core.ObjectAndRelation, subjectType *core.AllowedRelation, subjectID string) {
+	relation := subjectType.GetRelation()
+	if relation == "" {
+		relation = tuple.Ellipsis
+	}
+
+	rel := &core.RelationTuple{
+		ResourceAndRelation: resource,
+		Subject:             &core.ObjectAndRelation{Namespace: subjectType.Namespace, ObjectId: subjectID, Relation: relation},
+	}
+	if subjectType.RequiredCaveat != nil {
+		rel.Caveat = &core.ContextualizedCaveat{CaveatName: subjectType.RequiredCaveat.CaveatName}
+	}
+	g.rels = append(g.rels, rel)
+}
+
+// ValidationFile returns the fixtures as a validation file, which can be loaded with
+// `--datastore-bootstrap-files` or checked with the validate command.
+func (f *Fixtures) ValidationFile() ([]byte, error) {
+	lines := make([]string, 0, len(f.Relationships))
+	for _, rel := range f.Relationships {
+		line, err := tuple.String(rel)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+
+	return yamlv3.Marshal(struct {
+		Schema        string `yaml:"schema"`
+		Relationships string `yaml:"relationships"`
+	}{
+		Schema:        strings.TrimSpace(f.Schema),
+		Relationships: strings.Join(lines, "\n"),
+	})
+}
+
+// Write writes the schema of the fixtures to the server, replacing its schema, and then their
+// relationships, calling progress with the number written after each batch.
+func (f *Fixtures) Write(ctx context.Context, client *authzed.Client, progress func(written int)) error {
+	if _, err := client.WriteSchema(ctx, &v1.WriteSchemaRequest{Schema: f.Schema}); err != nil {
+		return fmt.Errorf("failed to write schema: %w", err)
+	}
+
+	for start := 0; start < len(f.Relationships); start += writeBatchSize {
+		end := min(start+writeBatchSize, len(f.Relationships))
+		updates := make([]*v1.RelationshipUpdate, 0, end-start)
+		for _, rel := range f.Relationships[start:end] {
+			updates = append(updates, &v1.RelationshipUpdate{Operation: v1.RelationshipUpdate_OPERATION_TOUCH, Relationship: tuple.MustToRelationship(rel)})
+		}
+
+		if _, err := client.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{Updates: updates}); err != nil {
+			return fmt.Errorf("failed to write relationships: %w", err)
+		}
+		if progress != nil {
+			progress(end)
+		}
+	}
+	return nil
+}
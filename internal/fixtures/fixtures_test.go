@@ -0,0 +1,146 @@
+package fixtures
+
+import (
+	"context"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/development"
+	devinterface "github.com/authzed/spicedb/pkg/proto/developer/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const testSchema = `
+caveat only_on_weekdays(day string) {
+	day != "saturday" && day != "sunday"
+}
+
+definition user {}
+
+definition group {
+	relation member: user | group#member
+}
+
+definition folder {
+	relation parent: folder
+	relation viewer: user | user:* | group#member
+	permission view = viewer + parent->view
+}
+
+definition document {
+	relation folder: folder
+	relation viewer: user | user with only_on_weekdays
+	permission view = viewer + folder->view
+}`
+
+func generate(t *testing.T, opts Options) *Fixtures {
+	t.Helper()
+	generated, err := Generate(testSchema, opts)
+	require.NoError(t, err)
+	return generated
+}
+
+func TestGenerateIsDeterministic(t *testing.T) {
+	opts := Options{Objects: 50, Fanout: 3, Skew: 1.5, WildcardRate: 0.1, Seed: 42}
+
+	first, err := generate(t, opts).ValidationFile()
+	require.NoError(t, err)
+	second, err := generate(t, opts).ValidationFile()
+	require.NoError(t, err)
+	require.Equal(t, string(first), string(second))
+
+	opts.Seed = 43
+	other, err := generate(t, opts).ValidationFile()
+	require.NoError(t, err)
+	require.NotEqual(t, string(first), string(other))
+}
+
+func TestGenerateShape(t *testing.T) {
+	generated := generate(t, Options{
+		Objects:          20,
+		ObjectsByType:    map[string]int{"document": 100, "group": 0},
+		Fanout:           2,
+		FanoutByRelation: map[string]int{"document#folder": 1},
+		WildcardRate:     1,
+		Seed:             1,
+	})
+
+	resources := map[string]int{}
+	caveated := 0
+	for _, rel := range generated.Relationships {
+		resource, subject := rel.ResourceAndRelation, rel.Subject
+		resources[resource.Namespace]++
+
+		resourceID, err := strconv.Atoi(resource.ObjectId)
+		require.NoError(t, err)
+		if resource.Namespace == "document" {
+			require.Less(t, resourceID, 100)
+		} else {
+			require.Less(t, resourceID, 20)
+		}
+
+		switch {
+		case subject.ObjectId == tuple.PublicWildcard:
+			require.Equal(t, "folder#viewer", resource.Namespace+"#"+resource.Relation)
+
+		case subject.Namespace == "folder":
+			// Subjects which are resources themselves precede their objects, so that the
+			// relationships form no cycles.
+			subjectID, err := strconv.Atoi(subject.ObjectId)
+			require.NoError(t, err)
+			require.Less(t, subjectID, min(resourceID, 20))
+
+		default:
+			require.Equal(t, "user", subject.Namespace)
+			require.Equal(t, tuple.Ellipsis, subject.Relation)
+		}
+
+		if rel.Caveat != nil {
+			require.Equal(t, "only_on_weekdays", rel.Caveat.CaveatName)
+			caveated++
+		}
+	}
+
+	require.Zero(t, resources["group"])
+	require.Zero(t, resources["user"])
+	require.NotZero(t, resources["folder"])
+	require.NotZero(t, resources["document"])
+	require.NotZero(t, caveated)
+}
+
+func TestGeneratedRelationshipsAreValid(t *testing.T) {
+	generated := generate(t, Options{Objects: 30, Fanout: 4, Skew: 2, WildcardRate: 0.2, Seed: 7})
+
+	devContext, devErrs, err := development.NewDevContext(context.Background(), &devinterface.RequestContext{
+		Schema:        generated.Schema,
+		Relationships: generated.Relationships,
+	})
+	require.NoError(t, err)
+	require.Nil(t, devErrs)
+	devContext.Dispose()
+}
+
+func TestGenerateErrors(t *testing.T) {
+	tcs := []struct {
+		name          string
+		schema        string
+		opts          Options
+		expectedError string
+	}{
+		{"invalid schema", "definition user {", Options{}, "invalid schema"},
+		{"negative objects", testSchema, Options{Objects: -1}, "must not be negative"},
+		{"negative objects of type", testSchema, Options{ObjectsByType: map[string]int{"user": -1}}, "invalid number of objects of `user`"},
+		{"negative fanout of relation", testSchema, Options{FanoutByRelation: map[string]int{"group#member": -1}}, "invalid fanout of `group#member`"},
+		{"invalid wildcard rate", testSchema, Options{WildcardRate: 2}, "invalid wildcard rate"},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Generate(tc.schema, tc.opts)
+			require.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
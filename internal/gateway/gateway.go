@@ -4,9 +4,11 @@ package gateway
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 
 	"github.com/authzed/authzed-go/proto"
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
@@ -20,6 +22,7 @@ import (
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/propagation"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/metadata"
@@ -33,8 +36,10 @@ var histogram = promauto.NewHistogramVec(prometheus.HistogramOpts{
 }, []string{"method"})
 
 // NewHandler creates an REST gateway HTTP CloserHandler with the provided upstream
-// configuration.
-func NewHandler(ctx context.Context, upstreamAddr, upstreamTLSCertPath string) (*CloserHandler, error) {
+// configuration. The upstream is dialed over TLS if upstreamTLS is set or a certificate path
+// is given, as when the upstream's certificate is held in memory rather than on disk. If the
+// upstream requires client certificates, upstreamClientCert returns the one presented to it.
+func NewHandler(ctx context.Context, upstreamAddr, upstreamTLSCertPath string, upstreamTLS bool, upstreamClientCert func(*tls.CertificateRequestInfo) (*tls.Certificate, error)) (*CloserHandler, error) {
 	if upstreamAddr == "" {
 		return nil, fmt.Errorf("upstreamAddr must not be empty")
 	}
@@ -43,14 +48,28 @@ func NewHandler(ctx context.Context, upstreamAddr, upstreamTLSCertPath string) (
 		grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()),   // nolint: staticcheck
 		grpc.WithStreamInterceptor(otelgrpc.StreamClientInterceptor()), // nolint: staticcheck
 	}
-	if upstreamTLSCertPath == "" {
-		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
-	} else {
+	switch {
+	case upstreamClientCert != nil:
+		// As with the other cases, the upstream's certificate is not verified.
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
+			GetClientCertificate: upstreamClientCert,
+			InsecureSkipVerify:   true, // nolint:gosec
+			MinVersion:           tls.VersionTLS12,
+		})))
+	case upstreamTLSCertPath != "":
 		certsOpt, err := grpcutil.WithCustomCerts(grpcutil.SkipVerifyCA, upstreamTLSCertPath)
 		if err != nil {
 			return nil, err
 		}
 		opts = append(opts, certsOpt)
+	case upstreamTLS:
+		certsOpt, err := grpcutil.WithSystemCerts(grpcutil.SkipVerifyCA)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, certsOpt)
+	default:
+		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
 	}
 
 	healthConn, err := grpc.DialContext(ctx, upstreamAddr, opts...)
@@ -58,7 +77,11 @@ func NewHandler(ctx context.Context, upstreamAddr, upstreamTLSCertPath string) (
 		return nil, err
 	}
 
-	gwMux := runtime.NewServeMux(runtime.WithMetadata(OtelAnnotator), runtime.WithHealthzEndpoint(healthpb.NewHealthClient(healthConn)))
+	gwMux := runtime.NewServeMux(
+		runtime.WithMetadata(OtelAnnotator),
+		runtime.WithIncomingHeaderMatcher(HeaderMatcher),
+		runtime.WithHealthzEndpoint(healthpb.NewHealthClient(healthConn)),
+	)
 	schemaConn, err := registerHandler(ctx, gwMux, upstreamAddr, opts, v1.RegisterSchemaServiceHandler)
 	if err != nil {
 		return nil, err
@@ -158,3 +181,18 @@ func OtelAnnotator(ctx context.Context, r *http.Request) metadata.MD {
 	otelgrpc.Inject(ctx, &metadataCopy, defaultOtelOpts...)
 	return metadataCopy
 }
+
+// spicedbHeaderPrefix is the prefix of the SpiceDB-specific request metadata keys, such as
+// those requesting debug information or attaching transaction metadata.
+const spicedbHeaderPrefix = "io.spicedb."
+
+// HeaderMatcher forwards SpiceDB-specific HTTP headers (those prefixed with `io.spicedb.`) to
+// the upstream gRPC server as request metadata, in addition to the headers forwarded by default.
+func HeaderMatcher(key string) (string, bool) {
+	lowered := strings.ToLower(key)
+	if strings.HasPrefix(lowered, spicedbHeaderPrefix) {
+		return lowered, true
+	}
+
+	return runtime.DefaultHeaderMatcher(key)
+}
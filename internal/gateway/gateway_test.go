@@ -2,15 +2,34 @@ package gateway
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
 	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/goleak"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/authzed/spicedb/pkg/cmd/util"
 )
 
 func TestOtelForwarding(t *testing.T) {
@@ -44,10 +63,33 @@ func TestOtelForwarding(t *testing.T) {
 	require.Equal(t, traceID, spanCtx.TraceID())
 }
 
+func TestHeaderMatcher(t *testing.T) {
+	tcs := []struct {
+		header      string
+		expectedKey string
+		expectedOk  bool
+	}{
+		{"Io.spicedb.requestdebuginfo", "io.spicedb.requestdebuginfo", true},
+		{http.CanonicalHeaderKey("io.spicedb.txmetadata.actor"), "io.spicedb.txmetadata.actor", true},
+		{"Grpc-Metadata-Foo", "Foo", true},
+		{"Authorization", "grpcgateway-Authorization", true},
+		{"X-Unrelated", "", false},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.header, func(t *testing.T) {
+			key, ok := HeaderMatcher(tc.header)
+			require.Equal(t, tc.expectedOk, ok)
+			require.Equal(t, tc.expectedKey, key)
+		})
+	}
+}
+
 func TestCloseConnections(t *testing.T) {
 	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
 
-	gatewayHandler, err := NewHandler(context.Background(), "192.0.2.0:4321", "")
+	gatewayHandler, err := NewHandler(context.Background(), "192.0.2.0:4321", "", false, nil)
 	require.NoError(t, err)
 	// 4 conns for permission+schema+watch+experimental services, 1 for health check
 	require.Len(t, gatewayHandler.closers, 5)
@@ -55,3 +97,93 @@ func TestCloseConnections(t *testing.T) {
 	// if connections are not closed, goleak would detect it
 	require.NoError(t, gatewayHandler.Close())
 }
+
+func TestMutualTLSUpstream(t *testing.T) {
+	caPath, certPath, keyPath := writeTestCerts(t, t.TempDir())
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	config := &util.GRPCServerConfig{
+		Address:         addr,
+		Network:         "tcp",
+		Enabled:         true,
+		TLSCertPath:     certPath,
+		TLSKeyPath:      keyPath,
+		MutualTLSCAPath: caPath,
+	}
+	srv, err := config.Complete(zerolog.InfoLevel, func(server *grpc.Server) {
+		healthpb.RegisterHealthServer(server, health.NewServer())
+	})
+	require.NoError(t, err)
+	t.Cleanup(srv.GracefulStop)
+
+	go func() {
+		_ = srv.Listen(context.Background())()
+	}()
+
+	healthz := func(clientCert func(*tls.CertificateRequestInfo) (*tls.Certificate, error)) int {
+		handler, err := NewHandler(context.Background(), config.DialTarget(), certPath, true, clientCert)
+		require.NoError(t, err)
+		defer handler.Close()
+
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+		return recorder.Code
+	}
+
+	// The gateway authenticates with the server's own certificate.
+	clientCert, err := config.ClientCertificate()
+	require.NoError(t, err)
+	require.NotNil(t, clientCert)
+	require.Equal(t, http.StatusOK, healthz(clientCert))
+
+	// Without it, the upstream rejects the gateway's connection.
+	require.NotEqual(t, http.StatusOK, healthz(nil))
+}
+
+// writeTestCerts writes a CA and a certificate it issued, for both serving and client
+// authentication, returning their paths and that of the certificate's key.
+func writeTestCerts(t *testing.T, dir string) (string, string, string) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ca := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "testCA"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, ca, ca, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	cert := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "spicedb"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, cert, ca, &key.PublicKey, caKey)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	writePEM := func(name, blockType string, der []byte) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+		return path
+	}
+
+	return writePEM("ca.crt", "CERTIFICATE", caDER),
+		writePEM("tls.crt", "CERTIFICATE", certDER),
+		writePEM("tls.key", "EC PRIVATE KEY", keyDER)
+}
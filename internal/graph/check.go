@@ -13,6 +13,7 @@ import (
 	"google.golang.org/protobuf/types/known/durationpb"
 
 	"github.com/authzed/spicedb/internal/dispatch"
+	"github.com/authzed/spicedb/internal/graph/groupindex"
 	log "github.com/authzed/spicedb/internal/logging"
 	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
 	"github.com/authzed/spicedb/internal/namespace"
@@ -47,9 +48,11 @@ func init() {
 	prometheus.MustRegister(dispatchChunkCountHistogram)
 }
 
-// NewConcurrentChecker creates an instance of ConcurrentChecker.
-func NewConcurrentChecker(d dispatch.Check, concurrencyLimit uint16) *ConcurrentChecker {
-	return &ConcurrentChecker{d, concurrencyLimit}
+// NewConcurrentChecker creates an instance of ConcurrentChecker. The goroutines created for
+// each request are bounded by the concurrencyLimit and, if not nil, those across all requests
+// by the nodeLimit. If not nil, the groupIndex is consulted for the relations it indexes.
+func NewConcurrentChecker(d dispatch.Check, concurrencyLimit uint16, nodeLimit *taskrunner.SharedLimit, groupIndex *groupindex.Index) *ConcurrentChecker {
+	return &ConcurrentChecker{d, concurrencyLimit, nodeLimit, groupIndex}
 }
 
 // ConcurrentChecker exposes a method to perform Check requests, and delegates subproblems to the
@@ -57,6 +60,8 @@ func NewConcurrentChecker(d dispatch.Check, concurrencyLimit uint16) *Concurrent
 type ConcurrentChecker struct {
 	d                dispatch.Check
 	concurrencyLimit uint16
+	nodeLimit        *taskrunner.SharedLimit
+	groupIndex       *groupindex.Index
 }
 
 // ValidatedCheckRequest represents a request after it has been validated and parsed for internal
@@ -90,6 +95,9 @@ type currentRequestContext struct {
 
 	// maxDispatchCount is the maximum number of resource IDs that can be specified in each dispatch.
 	maxDispatchCount uint16
+
+	// nodeLimit bounds the goroutines created across all requests, if not nil.
+	nodeLimit *taskrunner.SharedLimit
 }
 
 // Check performs a check request with the provided request and context
@@ -189,6 +197,7 @@ func (cc *ConcurrentChecker) checkInternal(ctx context.Context, req ValidatedChe
 		filteredResourceIDs: filteredResourcesIds,
 		resultsSetting:      resultsSetting,
 		maxDispatchCount:    maxDispatchChunkSize,
+		nodeLimit:           cc.nodeLimit,
 	}
 
 	if req.Debug == v1.DispatchCheckRequest_ENABLE_TRACE_DEBUGGING {
@@ -196,6 +205,17 @@ func (cc *ConcurrentChecker) checkInternal(ctx context.Context, req ValidatedChe
 	}
 
 	if relation.UsersetRewrite == nil {
+		// Debug traces are not served from the group index, so that they show how the check is resolved.
+		if cc.groupIndex != nil && req.Debug == v1.DispatchCheckRequest_NO_DEBUG {
+			if members, ok := cc.groupIndex.Members(req.ResourceRelation, filteredResourcesIds, req.Subject, req.Revision); ok {
+				foundResources := NewMembershipSet()
+				for _, resourceID := range members {
+					foundResources.AddDirectMember(resourceID, nil)
+				}
+				return combineResultWithFoundResources(checkResultsForMembership(foundResources, emptyMetadata), membershipSet)
+			}
+		}
+
 		return combineResultWithFoundResources(cc.checkDirect(ctx, crc, relation), membershipSet)
 	}
 
@@ -299,10 +319,16 @@ func (cc *ConcurrentChecker) checkDirect(ctx context.Context, crc currentRequest
 		queryCount += 1.0
 
 		// Find the matching subject(s).
+		stats := checkStatsFromContext(ctx)
+		budget := checkBudgetFromContext(ctx)
 		for tpl := it.Next(); tpl != nil; tpl = it.Next() {
 			if it.Err() != nil {
 				return checkResultError(NewCheckFailureErr(it.Err()), emptyMetadata)
 			}
+			stats.addRelationshipScanned()
+			if err := budget.addRelationship(); err != nil {
+				return checkResultError(err, emptyMetadata)
+			}
 
 			// If the subject of the relationship matches the target subject, then we've found
 			// a result.
@@ -367,10 +393,16 @@ func (cc *ConcurrentChecker) checkDirect(ctx context.Context, crc currentRequest
 	subjectsToDispatch := tuple.NewONRByTypeSet()
 	relationshipsBySubjectONR := mapz.NewMultiMap[string, *core.RelationTuple]()
 
+	stats := checkStatsFromContext(ctx)
+	budget := checkBudgetFromContext(ctx)
 	for tpl := it.Next(); tpl != nil; tpl = it.Next() {
 		if it.Err() != nil {
 			return checkResultError(NewCheckFailureErr(it.Err()), emptyMetadata)
 		}
+		stats.addRelationshipScanned()
+		if err := budget.addRelationship(); err != nil {
+			return checkResultError(err, emptyMetadata)
+		}
 
 		// Add the subject as an object over which to dispatch.
 		if tpl.Subject.Relation == Ellipsis {
@@ -593,10 +625,16 @@ func (cc *ConcurrentChecker) checkTupleToUserset(ctx context.Context, crc curren
 
 	subjectsToDispatch := tuple.NewONRByTypeSet()
 	relationshipsBySubjectONR := mapz.NewMultiMap[string, *core.RelationTuple]()
+	stats := checkStatsFromContext(ctx)
+	budget := checkBudgetFromContext(ctx)
 	for tpl := it.Next(); tpl != nil; tpl = it.Next() {
 		if it.Err() != nil {
 			return checkResultError(NewCheckFailureErr(it.Err()), emptyMetadata)
 		}
+		stats.addRelationshipScanned()
+		if err := budget.addRelationship(); err != nil {
+			return checkResultError(err, emptyMetadata)
+		}
 
 		subjectsToDispatch.Add(tpl.Subject)
 		relationshipsBySubjectONR.Add(tuple.StringONR(tpl.Subject), tpl)
@@ -719,6 +757,7 @@ func all[T any](
 		filteredResourceIDs: crc.filteredResourceIDs,
 		resultsSetting:      v1.DispatchCheckRequest_REQUIRE_ALL_RESULTS,
 		maxDispatchCount:    crc.maxDispatchCount,
+		nodeLimit:           crc.nodeLimit,
 	}, children, handler, resultChan, concurrencyLimit)
 	defer cancelFn()
 
@@ -779,6 +818,7 @@ func difference[T any](
 		filteredResourceIDs: crc.filteredResourceIDs,
 		resultsSetting:      v1.DispatchCheckRequest_REQUIRE_ALL_RESULTS,
 		maxDispatchCount:    crc.maxDispatchCount,
+		nodeLimit:           crc.nodeLimit,
 	}, children[1:], handler, othersChan, concurrencyLimit-1)
 	defer cancelFn()
 
@@ -834,7 +874,7 @@ func dispatchAllAsync[T any](
 	resultChan chan<- CheckResult,
 	concurrencyLimit uint16,
 ) {
-	tr := taskrunner.NewPreloadedTaskRunner(ctx, concurrencyLimit, len(children))
+	tr := taskrunner.NewPreloadedTaskRunnerWithSharedLimit(ctx, concurrencyLimit, crc.nodeLimit, len(children))
 	for _, currentChild := range children {
 		currentChild := currentChild
 		tr.Add(func(ctx context.Context) error {
@@ -0,0 +1,85 @@
+package graph
+
+import (
+	"context"
+	"sync/atomic"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var traversalLimitExceededCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "check",
+	Name:      "traversal_limit_exceeded_total",
+	Help:      "total number of checks aborted because their traversal exceeded a limit, by the limit exceeded",
+}, []string{"limit"})
+
+// CheckTraversalLimits bounds the traversal of a single check, so that pathological schemas or
+// data cannot consume the resources of the cluster. The traversal is counted from where the
+// check enters a dispatcher: a check received from another node is counted separately from the
+// check which dispatched it.
+type CheckTraversalLimits struct {
+	// MaxRelationships is the maximum number of relationships a check may read from the
+	// datastore. Zero places no bound.
+	MaxRelationships uint64
+
+	// MaxSubproblems is the maximum number of subproblems a check may resolve. Zero places no
+	// bound.
+	MaxSubproblems uint64
+}
+
+func (l CheckTraversalLimits) bounded() bool {
+	return l.MaxRelationships > 0 || l.MaxSubproblems > 0
+}
+
+type checkBudgetKey struct{}
+
+type checkBudget struct {
+	limits        CheckTraversalLimits
+	relationships atomic.Uint64
+	subproblems   atomic.Uint64
+}
+
+// ContextWithCheckTraversalLimits returns a context in which the traversal of the check is
+// bounded by the limits, unless it is already bounded by those of the check it is part of.
+func ContextWithCheckTraversalLimits(ctx context.Context, limits CheckTraversalLimits) context.Context {
+	if !limits.bounded() || checkBudgetFromContext(ctx) != nil {
+		return ctx
+	}
+	return context.WithValue(ctx, checkBudgetKey{}, &checkBudget{limits: limits})
+}
+
+func checkBudgetFromContext(ctx context.Context) *checkBudget {
+	budget, _ := ctx.Value(checkBudgetKey{}).(*checkBudget)
+	return budget
+}
+
+// AddCheckSubproblem counts a subproblem resolved by the check, returning an error if it
+// exceeds the maximum.
+func AddCheckSubproblem(ctx context.Context) error {
+	budget := checkBudgetFromContext(ctx)
+	if budget == nil || budget.limits.MaxSubproblems == 0 {
+		return nil
+	}
+
+	if budget.subproblems.Add(1) > budget.limits.MaxSubproblems {
+		traversalLimitExceededCounter.WithLabelValues("subproblems").Inc()
+		return NewTraversalLimitExceededErr("subproblems", budget.limits.MaxSubproblems)
+	}
+	return nil
+}
+
+// addRelationship counts a relationship read by the check, returning an error if it exceeds
+// the maximum.
+func (b *checkBudget) addRelationship() error {
+	if b == nil || b.limits.MaxRelationships == 0 {
+		return nil
+	}
+
+	if b.relationships.Add(1) > b.limits.MaxRelationships {
+		traversalLimitExceededCounter.WithLabelValues("relationships").Inc()
+		return NewTraversalLimitExceededErr("relationships", b.limits.MaxRelationships)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package graph
+
+import (
+	"context"
+	"sync/atomic"
+)
+
+type checkStatsKey struct{}
+
+// CheckStats collects statistics about the resolution of a check which are not carried by the
+// dispatch response metadata. Only the subproblems resolved on this node are counted, as the
+// statistics are not sent along with the subproblems dispatched to other nodes.
+type CheckStats struct {
+	relationshipsScanned atomic.Uint64
+}
+
+// ContextWithCheckStats returns a context in which the statistics of the checks resolved with
+// it are collected into the returned stats.
+func ContextWithCheckStats(ctx context.Context) (context.Context, *CheckStats) {
+	stats := &CheckStats{}
+	return context.WithValue(ctx, checkStatsKey{}, stats), stats
+}
+
+func checkStatsFromContext(ctx context.Context) *CheckStats {
+	stats, _ := ctx.Value(checkStatsKey{}).(*CheckStats)
+	return stats
+}
+
+// RelationshipsScanned returns the number of relationships read from the datastore.
+func (s *CheckStats) RelationshipsScanned() uint64 {
+	return s.relationshipsScanned.Load()
+}
+
+func (s *CheckStats) addRelationshipScanned() {
+	if s != nil {
+		s.relationshipsScanned.Add(1)
+	}
+}
@@ -9,7 +9,8 @@ import (
 	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
 )
 
-// branchContext returns a context disconnected from the parent context, but populated with the datastore.
+// branchContext returns a context disconnected from the parent context, but populated with the datastore
+// and bounded by the deadline of the parent context.
 // Also returns a function for canceling the newly created context, without canceling the parent context.
 func branchContext(ctx context.Context) (context.Context, func(cancelErr error)) {
 	// Add tracing to the context.
@@ -26,5 +27,15 @@ func branchContext(ctx context.Context) (context.Context, func(cancelErr error))
 		detachedContext = loggerFromContext.WithContext(detachedContext)
 	}
 
-	return context.WithCancelCause(detachedContext)
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return context.WithCancelCause(detachedContext)
+	}
+
+	detachedContext, cancelDeadline := context.WithDeadline(detachedContext, deadline)
+	branchedContext, cancel := context.WithCancelCause(detachedContext)
+	return branchedContext, func(cancelErr error) {
+		cancel(cancelErr)
+		cancelDeadline()
+	}
 }
@@ -3,8 +3,10 @@ package graph
 import (
 	"errors"
 	"fmt"
+	"strconv"
 
 	"github.com/rs/zerolog"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 
@@ -198,3 +200,36 @@ func (err ErrInvalidCursor) GRPCStatus() *status.Status {
 		),
 	)
 }
+
+// ErrTraversalLimitExceeded occurs when the traversal of a check exceeds one of its
+// CheckTraversalLimits.
+type ErrTraversalLimitExceeded struct {
+	error
+	limit   string
+	maximum uint64
+}
+
+// NewTraversalLimitExceededErr constructs a new traversal limit exceeded error.
+func NewTraversalLimitExceededErr(limit string, maximum uint64) error {
+	return ErrTraversalLimitExceeded{
+		error:   fmt.Errorf("the check has exceeded the maximum of %d %s which may be traversed by a single check: this usually indicates a schema or data with a very wide fan-out", maximum, limit),
+		limit:   limit,
+		maximum: maximum,
+	}
+}
+
+// GRPCStatus implements retrieving the gRPC status for the error.
+func (err ErrTraversalLimitExceeded) GRPCStatus() *status.Status {
+	return spiceerrors.WithCodeAndDetails(
+		err,
+		codes.ResourceExhausted,
+		&errdetails.ErrorInfo{
+			Reason: "ERROR_REASON_CHECK_TRAVERSAL_LIMIT_EXCEEDED",
+			Domain: spiceerrors.Domain,
+			Metadata: map[string]string{
+				"limit":   err.limit,
+				"maximum": strconv.FormatUint(err.maximum, 10),
+			},
+		},
+	)
+}
@@ -0,0 +1,328 @@
+// Package groupindex implements an index of the flattened membership of nested groups,
+// maintained from the datastore's changes, that answers checks of deeply nested group
+// relations without dispatching through every level of the hierarchy.
+package groupindex
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+var lookupsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "check",
+	Name:      "group_index_lookups_total",
+	Help:      "total number of checks that consulted the group index, by whether the index could answer them",
+}, []string{"result"})
+
+const (
+	resultHit         = "hit"
+	resultStale       = "stale"
+	resultUnsupported = "unsupported"
+)
+
+// Index holds the flattened membership of the groups of a set of relations. A relation can
+// be indexed when its membership is defined entirely by its relationships: subjects are
+// either terminal or members of the same relation on another group. Groups with caveated
+// relationships or other subject relations are not answered by the index.
+type Index struct {
+	maxStaleness time.Duration
+	now          func() time.Time
+
+	lock      sync.Mutex
+	ready     bool
+	revision  datastore.Revision
+	history   []revisionAt
+	relations map[string]*relationIndex
+}
+
+// revisionAt records when the index reached a revision.
+type revisionAt struct {
+	at       time.Time
+	revision datastore.Revision
+}
+
+// NewIndex returns an index of the given relations, each in the form `namespace#relation`.
+// The index answers checks at revisions that it reached within the maximum staleness, so
+// answers may reflect relationship changes applied up to that long after the revision.
+func NewIndex(relations []string, maxStaleness time.Duration) (*Index, error) {
+	if len(relations) == 0 {
+		return nil, fmt.Errorf("at least one relation must be indexed")
+	}
+
+	if maxStaleness <= 0 {
+		return nil, fmt.Errorf("maximum staleness must be positive")
+	}
+
+	indexed := make(map[string]*relationIndex, len(relations))
+	for _, relation := range relations {
+		namespace, relationName, ok := strings.Cut(relation, "#")
+		if !ok || namespace == "" || relationName == "" || relationName == tuple.Ellipsis {
+			return nil, fmt.Errorf("invalid relation `%s` to index; must be of the form `namespace#relation`", relation)
+		}
+		indexed[tuple.JoinRelRef(namespace, relationName)] = newRelationIndex()
+	}
+
+	return &Index{
+		maxStaleness: maxStaleness,
+		now:          time.Now,
+		relations:    indexed,
+	}, nil
+}
+
+// Members returns those of the resources that the terminal subject is a member of, via
+// the given relation, as of a revision at most the maximum staleness newer than that
+// given. If the index cannot answer, false is returned and the check must be evaluated
+// without it.
+func (idx *Index) Members(relation *core.RelationReference, resourceIDs []string, subject *core.ObjectAndRelation, revision datastore.Revision) ([]string, bool) {
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+
+	ri, ok := idx.relations[tuple.StringRR(relation)]
+	if !ok || subject.Relation != tuple.Ellipsis {
+		return nil, false
+	}
+
+	if !idx.isFresh(revision) {
+		lookupsCounter.WithLabelValues(resultStale).Inc()
+		return nil, false
+	}
+
+	subjectKey := tuple.StringONR(subject)
+	wildcardKey := tuple.JoinObjectRef(subject.Namespace, tuple.PublicWildcard)
+
+	members := make([]string, 0, len(resourceIDs))
+	for _, resourceID := range resourceIDs {
+		flattened := ri.flatten(resourceID)
+		if flattened.opaque {
+			lookupsCounter.WithLabelValues(resultUnsupported).Inc()
+			return nil, false
+		}
+
+		if _, ok := flattened.subjects[subjectKey]; ok {
+			members = append(members, resourceID)
+		} else if _, ok := flattened.subjects[wildcardKey]; ok {
+			members = append(members, resourceID)
+		}
+	}
+
+	lookupsCounter.WithLabelValues(resultHit).Inc()
+	return members, true
+}
+
+// isFresh returns whether the index can answer for the revision: it must have applied all
+// changes up to the revision, and have applied those since within the maximum staleness.
+func (idx *Index) isFresh(revision datastore.Revision) bool {
+	if !idx.ready || revision.GreaterThan(idx.revision) {
+		return false
+	}
+
+	idx.pruneHistory()
+	return !revision.LessThan(idx.history[0].revision)
+}
+
+// pruneHistory removes the revisions reached before the maximum staleness, other than the
+// latest of those, which is the oldest revision the index can answer for.
+func (idx *Index) pruneHistory() {
+	cutoff := idx.now().Add(-idx.maxStaleness)
+	for len(idx.history) > 1 && !idx.history[1].at.After(cutoff) {
+		idx.history = idx.history[1:]
+	}
+}
+
+// reset replaces the contents of the index with the given relationships, as of the revision.
+func (idx *Index) reset(relationships map[string][]*core.RelationTuple, revision datastore.Revision) {
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+
+	for relation := range idx.relations {
+		ri := newRelationIndex()
+		for _, rel := range relationships[relation] {
+			ri.add(rel)
+		}
+		idx.relations[relation] = ri
+	}
+
+	idx.ready = true
+	idx.revision = revision
+	idx.history = []revisionAt{{at: idx.now(), revision: revision}}
+}
+
+// apply updates the index with the changes made at the revision.
+func (idx *Index) apply(updates []*core.RelationTupleUpdate, revision datastore.Revision) {
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+
+	for _, update := range updates {
+		ri, ok := idx.relations[tuple.JoinRelRef(update.Tuple.ResourceAndRelation.Namespace, update.Tuple.ResourceAndRelation.Relation)]
+		if !ok {
+			continue
+		}
+
+		ri.remove(update.Tuple)
+		if update.Operation != core.RelationTupleUpdate_DELETE {
+			ri.add(update.Tuple)
+		}
+	}
+
+	idx.revision = revision
+	idx.history = append(idx.history, revisionAt{at: idx.now(), revision: revision})
+	idx.pruneHistory()
+}
+
+// setUnavailable stops the index from answering checks until it is next reset.
+func (idx *Index) setUnavailable() {
+	idx.lock.Lock()
+	defer idx.lock.Unlock()
+	idx.ready = false
+}
+
+type stringSet map[string]struct{}
+
+// relationIndex holds the groups of a single relation.
+type relationIndex struct {
+	// direct holds the terminal subjects of each group.
+	direct map[string]stringSet
+
+	// children and parents hold the groups nested in, and containing, each group.
+	children map[string]stringSet
+	parents  map[string]stringSet
+
+	// opaque holds the subjects of each group whose membership cannot be indexed.
+	opaque map[string]stringSet
+
+	// flattened caches the flattened membership of groups, and is invalidated for a group
+	// and those containing it whenever it changes.
+	flattened map[string]flattenedGroup
+}
+
+type flattenedGroup struct {
+	subjects stringSet
+	opaque   bool
+}
+
+func newRelationIndex() *relationIndex {
+	return &relationIndex{
+		direct:    map[string]stringSet{},
+		children:  map[string]stringSet{},
+		parents:   map[string]stringSet{},
+		opaque:    map[string]stringSet{},
+		flattened: map[string]flattenedGroup{},
+	}
+}
+
+func (ri *relationIndex) add(rel *core.RelationTuple) {
+	groupID := rel.ResourceAndRelation.ObjectId
+	ri.invalidate(groupID)
+
+	switch {
+	case rel.Caveat != nil && rel.Caveat.CaveatName != "":
+		addToSet(ri.opaque, groupID, tuple.StringONR(rel.Subject))
+
+	case rel.Subject.Relation == tuple.Ellipsis:
+		addToSet(ri.direct, groupID, tuple.StringONR(rel.Subject))
+
+	case rel.Subject.Namespace == rel.ResourceAndRelation.Namespace && rel.Subject.Relation == rel.ResourceAndRelation.Relation:
+		addToSet(ri.children, groupID, rel.Subject.ObjectId)
+		addToSet(ri.parents, rel.Subject.ObjectId, groupID)
+
+	default:
+		addToSet(ri.opaque, groupID, tuple.StringONR(rel.Subject))
+	}
+}
+
+func (ri *relationIndex) remove(rel *core.RelationTuple) {
+	groupID := rel.ResourceAndRelation.ObjectId
+	ri.invalidate(groupID)
+
+	subjectKey := tuple.StringONR(rel.Subject)
+	removeFromSet(ri.direct, groupID, subjectKey)
+	removeFromSet(ri.opaque, groupID, subjectKey)
+
+	if rel.Subject.Namespace == rel.ResourceAndRelation.Namespace && rel.Subject.Relation == rel.ResourceAndRelation.Relation {
+		removeFromSet(ri.children, groupID, rel.Subject.ObjectId)
+		removeFromSet(ri.parents, rel.Subject.ObjectId, groupID)
+	}
+}
+
+// invalidate removes the cached flattened membership of the group and all groups containing it.
+func (ri *relationIndex) invalidate(groupID string) {
+	visited := stringSet{groupID: {}}
+	toVisit := []string{groupID}
+	for len(toVisit) > 0 {
+		current := toVisit[len(toVisit)-1]
+		toVisit = toVisit[:len(toVisit)-1]
+
+		delete(ri.flattened, current)
+		for parentID := range ri.parents[current] {
+			if _, ok := visited[parentID]; !ok {
+				visited[parentID] = struct{}{}
+				toVisit = append(toVisit, parentID)
+			}
+		}
+	}
+}
+
+// flatten returns the terminal subjects of the group and all groups nested within it.
+func (ri *relationIndex) flatten(groupID string) flattenedGroup {
+	if cached, ok := ri.flattened[groupID]; ok {
+		return cached
+	}
+
+	flattened := flattenedGroup{subjects: stringSet{}}
+	visited := stringSet{groupID: {}}
+	toVisit := []string{groupID}
+	for len(toVisit) > 0 {
+		current := toVisit[len(toVisit)-1]
+		toVisit = toVisit[:len(toVisit)-1]
+
+		if len(ri.opaque[current]) > 0 {
+			flattened = flattenedGroup{opaque: true}
+			break
+		}
+
+		for subject := range ri.direct[current] {
+			flattened.subjects[subject] = struct{}{}
+		}
+
+		for childID := range ri.children[current] {
+			if _, ok := visited[childID]; !ok {
+				visited[childID] = struct{}{}
+				toVisit = append(toVisit, childID)
+			}
+		}
+	}
+
+	ri.flattened[groupID] = flattened
+	return flattened
+}
+
+func addToSet(sets map[string]stringSet, key, value string) {
+	set, ok := sets[key]
+	if !ok {
+		set = stringSet{}
+		sets[key] = set
+	}
+	set[value] = struct{}{}
+}
+
+func removeFromSet(sets map[string]stringSet, key, value string) {
+	set, ok := sets[key]
+	if !ok {
+		return
+	}
+
+	delete(set, value)
+	if len(set) == 0 {
+		delete(sets, key)
+	}
+}
@@ -0,0 +1,187 @@
+package groupindex
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/datastore/revisions"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+var (
+	groupMember = &core.RelationReference{Namespace: "group", Relation: "member"}
+	alice       = tuple.ParseSubjectONR("user:alice")
+	bob         = tuple.ParseSubjectONR("user:bob")
+)
+
+func TestNewIndex(t *testing.T) {
+	_, err := NewIndex(nil, time.Second)
+	require.ErrorContains(t, err, "at least one relation")
+
+	_, err = NewIndex([]string{"group#member"}, 0)
+	require.ErrorContains(t, err, "maximum staleness must be positive")
+
+	_, err = NewIndex([]string{"group"}, time.Second)
+	require.ErrorContains(t, err, "invalid relation `group`")
+
+	_, err = NewIndex([]string{"group#member"}, time.Second)
+	require.NoError(t, err)
+}
+
+func TestMembers(t *testing.T) {
+	idx, err := NewIndex([]string{"group#member"}, time.Minute)
+	require.NoError(t, err)
+
+	revision := revisions.NewForTransactionID(1)
+
+	// The index cannot answer until it has been loaded.
+	_, ok := idx.Members(groupMember, []string{"root"}, alice, revision)
+	require.False(t, ok)
+
+	idx.reset(map[string][]*core.RelationTuple{
+		"group#member": {
+			tuple.MustParse("group:root#member@group:middle#member"),
+			tuple.MustParse("group:middle#member@group:leaf#member"),
+			tuple.MustParse("group:leaf#member@user:alice"),
+			tuple.MustParse("group:other#member@user:bob"),
+			tuple.MustParse("group:public#member@user:*"),
+			tuple.MustParse("group:cyclic#member@group:cyclic#member"),
+		},
+	}, revision)
+
+	members, ok := idx.Members(groupMember, []string{"root", "middle", "other", "public", "cyclic", "missing"}, alice, revision)
+	require.True(t, ok)
+	require.Equal(t, []string{"root", "middle", "public"}, members)
+
+	// Removing a nested membership is reflected in all groups containing it.
+	idx.apply([]*core.RelationTupleUpdate{
+		tuple.Delete(tuple.MustParse("group:middle#member@group:leaf#member")),
+		tuple.Create(tuple.MustParse("group:leaf#member@group:other#member")),
+	}, revisions.NewForTransactionID(2))
+
+	members, ok = idx.Members(groupMember, []string{"root", "middle", "leaf"}, alice, revisions.NewForTransactionID(2))
+	require.True(t, ok)
+	require.Equal(t, []string{"leaf"}, members)
+
+	members, ok = idx.Members(groupMember, []string{"root", "leaf"}, bob, revisions.NewForTransactionID(2))
+	require.True(t, ok)
+	require.Equal(t, []string{"leaf"}, members)
+
+	// Relations which are not indexed, and non-terminal subjects, are not answered.
+	_, ok = idx.Members(&core.RelationReference{Namespace: "group", Relation: "admin"}, []string{"root"}, alice, revision)
+	require.False(t, ok)
+
+	_, ok = idx.Members(groupMember, []string{"root"}, tuple.ParseSubjectONR("group:leaf#member"), revision)
+	require.False(t, ok)
+}
+
+func TestMembersUnsupportedRelationships(t *testing.T) {
+	idx, err := NewIndex([]string{"group#member"}, time.Minute)
+	require.NoError(t, err)
+
+	revision := revisions.NewForTransactionID(1)
+	idx.reset(map[string][]*core.RelationTuple{
+		"group#member": {
+			tuple.MustParse("group:root#member@group:caveated#member"),
+			tuple.MustParse("group:caveated#member@user:alice[somecaveat]"),
+			tuple.MustParse("group:team#member@team:engineering#member"),
+			tuple.MustParse("group:plain#member@user:alice"),
+		},
+	}, revision)
+
+	// Groups whose membership includes caveated or other relationships are not answered.
+	_, ok := idx.Members(groupMember, []string{"root"}, alice, revision)
+	require.False(t, ok)
+
+	_, ok = idx.Members(groupMember, []string{"team"}, alice, revision)
+	require.False(t, ok)
+
+	members, ok := idx.Members(groupMember, []string{"plain"}, alice, revision)
+	require.True(t, ok)
+	require.Equal(t, []string{"plain"}, members)
+
+	// Once the caveated relationship is removed, the group is answered again.
+	idx.apply([]*core.RelationTupleUpdate{
+		tuple.Delete(tuple.MustParse("group:caveated#member@user:alice[somecaveat]")),
+	}, revisions.NewForTransactionID(2))
+
+	members, ok = idx.Members(groupMember, []string{"root"}, alice, revisions.NewForTransactionID(2))
+	require.True(t, ok)
+	require.Empty(t, members)
+}
+
+func TestMembersStaleness(t *testing.T) {
+	idx, err := NewIndex([]string{"group#member"}, time.Minute)
+	require.NoError(t, err)
+
+	now := time.Now()
+	idx.now = func() time.Time { return now }
+
+	idx.reset(map[string][]*core.RelationTuple{}, revisions.NewForTransactionID(10))
+
+	// Revisions newer than the index, or older than when it was loaded, are not answered.
+	_, ok := idx.Members(groupMember, []string{"root"}, alice, revisions.NewForTransactionID(11))
+	require.False(t, ok)
+
+	_, ok = idx.Members(groupMember, []string{"root"}, alice, revisions.NewForTransactionID(9))
+	require.False(t, ok)
+
+	now = now.Add(30 * time.Second)
+	idx.apply(nil, revisions.NewForTransactionID(20))
+
+	_, ok = idx.Members(groupMember, []string{"root"}, alice, revisions.NewForTransactionID(10))
+	require.True(t, ok)
+
+	_, ok = idx.Members(groupMember, []string{"root"}, alice, revisions.NewForTransactionID(15))
+	require.True(t, ok)
+
+	// Once the revision was reached longer ago than the maximum staleness, older revisions
+	// are no longer answered.
+	now = now.Add(time.Minute)
+	_, ok = idx.Members(groupMember, []string{"root"}, alice, revisions.NewForTransactionID(15))
+	require.False(t, ok)
+
+	_, ok = idx.Members(groupMember, []string{"root"}, alice, revisions.NewForTransactionID(20))
+	require.True(t, ok)
+}
+
+func TestRun(t *testing.T) {
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err = common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE,
+		tuple.MustParse("group:root#member@group:leaf#member"),
+	)
+	require.NoError(t, err)
+
+	idx, err := NewIndex([]string{"group#member"}, time.Minute)
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		done <- idx.Run(ctx, ds)
+	}()
+
+	revision, err := common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE,
+		tuple.MustParse("group:leaf#member@user:alice"),
+	)
+	require.NoError(t, err)
+
+	// Changes made after the index is loaded are applied from the watch.
+	require.Eventually(t, func() bool {
+		members, ok := idx.Members(groupMember, []string{"root"}, alice, revision)
+		return ok && len(members) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+}
@@ -0,0 +1,108 @@
+package groupindex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const maxRetryInterval = time.Minute
+
+// Run builds the index from the relationships in the datastore and keeps it up to date
+// with their changes until the context is canceled. If the index cannot be built or falls
+// behind, it stops answering checks and is rebuilt.
+func (idx *Index) Run(ctx context.Context, ds datastore.Datastore) error {
+	backoffInterval := backoff.NewExponentialBackOff()
+	backoffInterval.MaxInterval = maxRetryInterval
+	backoffInterval.MaxElapsedTime = 0
+	backoffInterval.Reset()
+
+	for {
+		err := idx.sync(ctx, ds, backoffInterval.Reset)
+		idx.setUnavailable()
+		if ctx.Err() != nil {
+			log.Ctx(ctx).Info().Msg("shutting down group index")
+			return nil
+		}
+
+		nextAttempt := backoffInterval.NextBackOff()
+		log.Ctx(ctx).Warn().Err(err).Dur("next-attempt-in", nextAttempt).Msg("group index is unavailable; rebuilding")
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(nextAttempt):
+		}
+	}
+}
+
+// sync loads the index and applies changes to it until an error occurs, calling onReady
+// once it has been loaded.
+func (idx *Index) sync(ctx context.Context, ds datastore.Datastore, onReady func()) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	headRevision, err := ds.HeadRevision(ctx)
+	if err != nil {
+		return fmt.Errorf("error reading head revision: %w", err)
+	}
+
+	relationships := make(map[string][]*core.RelationTuple, len(idx.relations))
+	relationshipCount := 0
+	reader := ds.SnapshotReader(headRevision)
+	for relation := range idx.relations {
+		namespace, relationName := tuple.MustSplitRelRef(relation)
+		it, err := reader.QueryRelationships(ctx, datastore.RelationshipsFilter{
+			ResourceType:             namespace,
+			OptionalResourceRelation: relationName,
+		})
+		if err != nil {
+			return fmt.Errorf("error reading relationships of `%s`: %w", relation, err)
+		}
+
+		for rel := it.Next(); rel != nil; rel = it.Next() {
+			relationships[relation] = append(relationships[relation], rel)
+			relationshipCount++
+		}
+		if it.Err() != nil {
+			it.Close()
+			return fmt.Errorf("error reading relationships of `%s`: %w", relation, it.Err())
+		}
+		it.Close()
+	}
+
+	idx.reset(relationships, headRevision)
+	onReady()
+	log.Ctx(ctx).Info().Str("revision", headRevision.String()).Int("relationships", relationshipCount).Msg("group index loaded")
+
+	changes, errs := ds.Watch(ctx, headRevision, datastore.WatchOptions{
+		Content: datastore.WatchRelationships | datastore.WatchCheckpoints,
+
+		// Checkpoints keep the index able to answer for new revisions while there are no changes.
+		CheckpointInterval: idx.maxStaleness / 2,
+	})
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case change, ok := <-changes:
+			if !ok {
+				return errors.New("watch closed")
+			}
+			idx.apply(change.RelationshipChanges, change.Revision)
+
+		case err := <-errs:
+			return fmt.Errorf("error watching relationships: %w", err)
+		}
+	}
+}
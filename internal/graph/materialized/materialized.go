@@ -0,0 +1,231 @@
+// Package materialized implements materialized permission sets: the flattened members of
+// designated permissions, maintained from the datastore's changes, that answer checks of
+// permissions with a high fan-out with a single lookup instead of dispatching through the
+// schema.
+package materialized
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/authzed/spicedb/internal/dispatch"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+var (
+	lookupsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "spicedb",
+		Subsystem: "check",
+		Name:      "materialized_permission_lookups_total",
+		Help:      "total number of checks that consulted a materialized permission set, by whether it could answer them",
+	}, []string{"result"})
+
+	recomputedCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "spicedb",
+		Subsystem: "check",
+		Name:      "materialized_permission_recomputed_resources_total",
+		Help:      "total number of resources whose materialized members were recomputed after a change",
+	})
+)
+
+const (
+	resultHit         = "hit"
+	resultStale       = "stale"
+	resultUnsupported = "unsupported"
+)
+
+// Sets holds the materialized members of a set of permissions. The members of each resource
+// are computed by dispatching a lookup of its subjects, and recomputed whenever a relationship
+// from which the resource's permission may be reached changes. Resources with caveated members,
+// or with a wildcard from which subjects are excluded, are not answered by the sets.
+type Sets struct {
+	dispatcher   dispatch.Dispatcher
+	maxDepth     uint32
+	maxStaleness time.Duration
+	now          func() time.Time
+
+	lock        sync.Mutex
+	ready       bool
+	revision    datastore.Revision
+	history     []revisionAt
+	permissions map[string]*permissionSet
+}
+
+// revisionAt records when the sets reached a revision.
+type revisionAt struct {
+	at       time.Time
+	revision datastore.Revision
+}
+
+// permissionSet holds the members of a single type of subject of a permission, by resource.
+type permissionSet struct {
+	resourceType string
+	permission   string
+	subjectType  string
+	members      map[string]memberSet
+}
+
+type stringSet map[string]struct{}
+
+// memberSet holds the members of the permission on a resource.
+type memberSet struct {
+	subjects stringSet
+	wildcard bool
+	opaque   bool
+}
+
+// NewSets returns the materialized sets of the given permissions, each in the form
+// `resource_type#permission@subject_type`, computed with the dispatcher. The sets answer
+// checks at revisions that they reached within the maximum staleness, so answers may reflect
+// relationship changes applied up to that long after the revision.
+func NewSets(permissions []string, dispatcher dispatch.Dispatcher, maxDepth uint32, maxStaleness time.Duration) (*Sets, error) {
+	if len(permissions) == 0 {
+		return nil, fmt.Errorf("at least one permission must be materialized")
+	}
+
+	if maxStaleness <= 0 {
+		return nil, fmt.Errorf("maximum staleness must be positive")
+	}
+
+	sets := make(map[string]*permissionSet, len(permissions))
+	for _, permission := range permissions {
+		relRef, subjectType, ok := strings.Cut(permission, "@")
+		if !ok || subjectType == "" || strings.Contains(subjectType, "#") {
+			return nil, fmt.Errorf("invalid permission `%s` to materialize; must be of the form `resource_type#permission@subject_type`", permission)
+		}
+
+		resourceType, permissionName, ok := strings.Cut(relRef, "#")
+		if !ok || resourceType == "" || permissionName == "" || permissionName == tuple.Ellipsis {
+			return nil, fmt.Errorf("invalid permission `%s` to materialize; must be of the form `resource_type#permission@subject_type`", permission)
+		}
+
+		sets[permission] = &permissionSet{
+			resourceType: resourceType,
+			permission:   permissionName,
+			subjectType:  subjectType,
+			members:      map[string]memberSet{},
+		}
+	}
+
+	return &Sets{
+		dispatcher:   dispatcher,
+		maxDepth:     maxDepth,
+		maxStaleness: maxStaleness,
+		now:          time.Now,
+		permissions:  sets,
+	}, nil
+}
+
+// MaxStaleness returns the maximum time by which the changes reflected in an answer may be
+// newer than the revision of the check.
+func (s *Sets) MaxStaleness() time.Duration {
+	return s.maxStaleness
+}
+
+// Check returns whether the terminal subject has the permission on the resource, and the
+// revision as of which the answer was computed, which is at most the maximum staleness newer
+// than that given. If the sets cannot answer, false is returned as the last value and the
+// check must be evaluated without them.
+func (s *Sets) Check(resource *core.ObjectAndRelation, subject *core.ObjectAndRelation, revision datastore.Revision) (bool, datastore.Revision, bool) {
+	if s == nil || subject.Relation != tuple.Ellipsis {
+		return false, datastore.NoRevision, false
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	ps, ok := s.permissions[tuple.JoinRelRef(resource.Namespace, resource.Relation)+"@"+subject.Namespace]
+	if !ok {
+		return false, datastore.NoRevision, false
+	}
+
+	if !s.isFresh(revision) {
+		lookupsCounter.WithLabelValues(resultStale).Inc()
+		return false, datastore.NoRevision, false
+	}
+
+	members := ps.members[resource.ObjectId]
+	if members.opaque {
+		lookupsCounter.WithLabelValues(resultUnsupported).Inc()
+		return false, datastore.NoRevision, false
+	}
+
+	lookupsCounter.WithLabelValues(resultHit).Inc()
+	if _, ok := members.subjects[subject.ObjectId]; ok {
+		return true, s.revision, true
+	}
+	return members.wildcard, s.revision, true
+}
+
+// isFresh returns whether the sets can answer for the revision: they must have applied all
+// changes up to the revision, and have applied those since within the maximum staleness.
+func (s *Sets) isFresh(revision datastore.Revision) bool {
+	if !s.ready || revision.GreaterThan(s.revision) {
+		return false
+	}
+
+	s.pruneHistory()
+	return !revision.LessThan(s.history[0].revision)
+}
+
+// pruneHistory removes the revisions reached before the maximum staleness, other than the
+// latest of those, which is the oldest revision the sets can answer for.
+func (s *Sets) pruneHistory() {
+	cutoff := s.now().Add(-s.maxStaleness)
+	for len(s.history) > 1 && !s.history[1].at.After(cutoff) {
+		s.history = s.history[1:]
+	}
+}
+
+// reset replaces the members of every permission with those given, as of the revision.
+func (s *Sets) reset(members map[string]map[string]memberSet, revision datastore.Revision) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for key, ps := range s.permissions {
+		ps.members = members[key]
+		if ps.members == nil {
+			ps.members = map[string]memberSet{}
+		}
+	}
+
+	s.ready = true
+	s.revision = revision
+	s.history = []revisionAt{{at: s.now(), revision: revision}}
+}
+
+// apply replaces the members of the recomputed resources of each permission, as of the
+// revision. Resources without members are removed.
+func (s *Sets) apply(recomputed map[string]map[string]memberSet, revision datastore.Revision) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for key, resources := range recomputed {
+		ps := s.permissions[key]
+		for resourceID, members := range resources {
+			if len(members.subjects) == 0 && !members.wildcard && !members.opaque {
+				delete(ps.members, resourceID)
+				continue
+			}
+			ps.members[resourceID] = members
+		}
+	}
+
+	s.revision = revision
+	s.history = append(s.history, revisionAt{at: s.now(), revision: revision})
+	s.pruneHistory()
+}
+
+// setUnavailable stops the sets from answering checks until they are next reset.
+func (s *Sets) setUnavailable() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.ready = false
+}
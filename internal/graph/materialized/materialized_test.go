@@ -0,0 +1,223 @@
+package materialized
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/datastore/revisions"
+	"github.com/authzed/spicedb/internal/dispatch/graph"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+var (
+	alice = tuple.ParseSubjectONR("user:alice")
+	bob   = tuple.ParseSubjectONR("user:bob")
+)
+
+func docView(id string) *core.ObjectAndRelation {
+	return tuple.ParseONR("document:" + id + "#view")
+}
+
+func TestNewSets(t *testing.T) {
+	_, err := NewSets(nil, nil, 50, time.Second)
+	require.ErrorContains(t, err, "at least one permission")
+
+	_, err = NewSets([]string{"document#view@user"}, nil, 50, 0)
+	require.ErrorContains(t, err, "maximum staleness must be positive")
+
+	_, err = NewSets([]string{"document#view"}, nil, 50, time.Second)
+	require.ErrorContains(t, err, "invalid permission `document#view`")
+
+	_, err = NewSets([]string{"document@user"}, nil, 50, time.Second)
+	require.ErrorContains(t, err, "invalid permission `document@user`")
+
+	_, err = NewSets([]string{"document#view@user#member"}, nil, 50, time.Second)
+	require.ErrorContains(t, err, "invalid permission `document#view@user#member`")
+
+	_, err = NewSets([]string{"document#view@user"}, nil, 50, time.Second)
+	require.NoError(t, err)
+}
+
+func TestCheck(t *testing.T) {
+	sets, err := NewSets([]string{"document#view@user"}, nil, 50, time.Minute)
+	require.NoError(t, err)
+
+	revision := revisions.NewForTransactionID(1)
+
+	// The sets cannot answer until they have been computed.
+	_, _, ok := sets.Check(docView("first"), alice, revision)
+	require.False(t, ok)
+
+	sets.reset(map[string]map[string]memberSet{
+		"document#view@user": {
+			"first":    {subjects: stringSet{"alice": {}}},
+			"public":   {subjects: stringSet{}, wildcard: true},
+			"caveated": {subjects: stringSet{"alice": {}}, opaque: true},
+		},
+	}, revision)
+
+	allowed, checkedAt, ok := sets.Check(docView("first"), alice, revision)
+	require.True(t, ok)
+	require.True(t, allowed)
+	require.True(t, checkedAt.Equal(revision))
+
+	allowed, _, ok = sets.Check(docView("first"), bob, revision)
+	require.True(t, ok)
+	require.False(t, allowed)
+
+	allowed, _, ok = sets.Check(docView("public"), bob, revision)
+	require.True(t, ok)
+	require.True(t, allowed)
+
+	// Resources without members are known to grant nothing.
+	allowed, _, ok = sets.Check(docView("missing"), alice, revision)
+	require.True(t, ok)
+	require.False(t, allowed)
+
+	// Caveated members, permissions which are not materialized and non-terminal subjects are
+	// not answered.
+	_, _, ok = sets.Check(docView("caveated"), alice, revision)
+	require.False(t, ok)
+
+	_, _, ok = sets.Check(tuple.ParseONR("document:first#edit"), alice, revision)
+	require.False(t, ok)
+
+	_, _, ok = sets.Check(docView("first"), tuple.ParseSubjectONR("group:admins#member"), revision)
+	require.False(t, ok)
+
+	// Recomputed resources replace their members, and those left without any are removed.
+	sets.apply(map[string]map[string]memberSet{
+		"document#view@user": {
+			"first":  {subjects: stringSet{"bob": {}}},
+			"public": {},
+		},
+	}, revisions.NewForTransactionID(2))
+
+	allowed, _, ok = sets.Check(docView("first"), bob, revisions.NewForTransactionID(2))
+	require.True(t, ok)
+	require.True(t, allowed)
+
+	allowed, _, ok = sets.Check(docView("public"), bob, revisions.NewForTransactionID(2))
+	require.True(t, ok)
+	require.False(t, allowed)
+
+	var nilSets *Sets
+	_, _, ok = nilSets.Check(docView("first"), alice, revision)
+	require.False(t, ok)
+}
+
+func TestCheckStaleness(t *testing.T) {
+	sets, err := NewSets([]string{"document#view@user"}, nil, 50, time.Minute)
+	require.NoError(t, err)
+
+	now := time.Now()
+	sets.now = func() time.Time { return now }
+
+	sets.reset(nil, revisions.NewForTransactionID(10))
+
+	// Revisions newer than the sets, or older than when they were computed, are not answered.
+	_, _, ok := sets.Check(docView("first"), alice, revisions.NewForTransactionID(11))
+	require.False(t, ok)
+
+	_, _, ok = sets.Check(docView("first"), alice, revisions.NewForTransactionID(9))
+	require.False(t, ok)
+
+	now = now.Add(30 * time.Second)
+	sets.apply(nil, revisions.NewForTransactionID(20))
+
+	_, checkedAt, ok := sets.Check(docView("first"), alice, revisions.NewForTransactionID(15))
+	require.True(t, ok)
+	require.True(t, checkedAt.Equal(revisions.NewForTransactionID(20)))
+
+	// Once the revision was reached longer ago than the maximum staleness, older revisions
+	// are no longer answered.
+	now = now.Add(time.Minute)
+	_, _, ok = sets.Check(docView("first"), alice, revisions.NewForTransactionID(15))
+	require.False(t, ok)
+
+	_, _, ok = sets.Check(docView("first"), alice, revisions.NewForTransactionID(20))
+	require.True(t, ok)
+}
+
+func TestRun(t *testing.T) {
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	ds, _ := testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, `
+		definition user {}
+
+		definition group {
+			relation member: user | group#member
+		}
+
+		definition folder {
+			relation viewer: group#member
+		}
+
+		definition document {
+			relation parent: folder
+			relation viewer: user | user:*
+			permission view = viewer + parent->viewer
+		}`,
+		[]*core.RelationTuple{
+			tuple.MustParse("document:first#parent@folder:shared"),
+			tuple.MustParse("folder:shared#viewer@group:eng#member"),
+			tuple.MustParse("group:eng#member@group:backend#member"),
+			tuple.MustParse("document:public#viewer@user:*"),
+		}, require.New(t))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	sets, err := NewSets([]string{"document#view@user"}, graph.NewLocalOnlyDispatcher(10), 50, time.Minute)
+	require.NoError(t, err)
+
+	done := make(chan error)
+	go func() {
+		done <- sets.Run(ctx, ds)
+	}()
+
+	var computedAt datastore.Revision
+	require.Eventually(t, func() bool {
+		sets.lock.Lock()
+		defer sets.lock.Unlock()
+		computedAt = sets.revision
+		return sets.ready
+	}, 5*time.Second, 10*time.Millisecond)
+
+	allowed, _, ok := sets.Check(docView("public"), alice, computedAt)
+	require.True(t, ok)
+	require.True(t, allowed)
+
+	// A change to a nested group is reflected in the documents reached through it.
+	revision, err := common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE,
+		tuple.MustParse("group:backend#member@user:alice"),
+	)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		allowed, _, ok := sets.Check(docView("first"), alice, revision)
+		return ok && allowed
+	}, 5*time.Second, 10*time.Millisecond)
+
+	revision, err = common.WriteTuples(ctx, ds, core.RelationTupleUpdate_DELETE,
+		tuple.MustParse("folder:shared#viewer@group:eng#member"),
+	)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		allowed, _, ok := sets.Check(docView("first"), alice, revision)
+		return ok && !allowed
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+}
@@ -0,0 +1,303 @@
+package materialized
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+
+	"github.com/authzed/spicedb/internal/dispatch"
+	log "github.com/authzed/spicedb/internal/logging"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/genutil/mapz"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	v1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const (
+	maxRetryInterval = time.Minute
+
+	// lookupChunkSize is the number of resources whose members are looked up per dispatch.
+	lookupChunkSize = 100
+)
+
+// Run computes the sets from the relationships in the datastore and keeps them up to date
+// with their changes until the context is canceled. If the sets cannot be computed, fall
+// behind or the schema changes, they stop answering checks and are recomputed.
+func (s *Sets) Run(ctx context.Context, ds datastore.Datastore) error {
+	backoffInterval := backoff.NewExponentialBackOff()
+	backoffInterval.MaxInterval = maxRetryInterval
+	backoffInterval.MaxElapsedTime = 0
+	backoffInterval.Reset()
+
+	for {
+		err := s.sync(ctx, ds, backoffInterval.Reset)
+		s.setUnavailable()
+		if ctx.Err() != nil {
+			log.Ctx(ctx).Info().Msg("shutting down materialized permissions")
+			return nil
+		}
+
+		nextAttempt := backoffInterval.NextBackOff()
+		log.Ctx(ctx).Warn().Err(err).Dur("next-attempt-in", nextAttempt).Msg("materialized permissions are unavailable; recomputing")
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(nextAttempt):
+		}
+	}
+}
+
+// sync computes the sets and applies changes to them until an error occurs, calling onReady
+// once they have been computed.
+func (s *Sets) sync(ctx context.Context, ds datastore.Datastore, onReady func()) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// The dispatcher reads relationships from the datastore in the context.
+	ctx = datastoremw.ContextWithDatastore(ctx, ds)
+
+	headRevision, err := ds.HeadRevision(ctx)
+	if err != nil {
+		return fmt.Errorf("error reading head revision: %w", err)
+	}
+
+	members := make(map[string]map[string]memberSet, len(s.permissions))
+	resourceCount := 0
+	for key, ps := range s.permissions {
+		resourceIDs, err := resourcesOfType(ctx, ds.SnapshotReader(headRevision), ps.resourceType)
+		if err != nil {
+			return err
+		}
+
+		members[key], err = s.lookupMembers(ctx, ps, resourceIDs, headRevision)
+		if err != nil {
+			return err
+		}
+		resourceCount += len(members[key])
+	}
+
+	s.reset(members, headRevision)
+	onReady()
+	log.Ctx(ctx).Info().Str("revision", headRevision.String()).Int("resources", resourceCount).Msg("materialized permissions computed")
+
+	changes, errs := ds.Watch(ctx, headRevision, datastore.WatchOptions{
+		Content: datastore.WatchRelationships | datastore.WatchSchema | datastore.WatchCheckpoints,
+
+		// Checkpoints keep the sets able to answer for new revisions while there are no changes.
+		CheckpointInterval: s.maxStaleness / 2,
+	})
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case change, ok := <-changes:
+			if !ok {
+				return errors.New("watch closed")
+			}
+
+			if len(change.ChangedDefinitions) > 0 || len(change.DeletedNamespaces) > 0 || len(change.DeletedCaveats) > 0 {
+				return errors.New("schema changed")
+			}
+
+			recomputed, err := s.recompute(ctx, ds, change.RelationshipChanges, change.Revision)
+			if err != nil {
+				return err
+			}
+			s.apply(recomputed, change.Revision)
+
+		case err := <-errs:
+			return fmt.Errorf("error watching relationships: %w", err)
+		}
+	}
+}
+
+// recompute returns the members, as of the revision, of the resources of each permission whose
+// members may have been changed by the relationship updates.
+func (s *Sets) recompute(ctx context.Context, ds datastore.Datastore, updates []*core.RelationTupleUpdate, revision datastore.Revision) (map[string]map[string]memberSet, error) {
+	if len(updates) == 0 {
+		return nil, nil
+	}
+
+	// A relationship may change the permissions reached from any relation or permission of
+	// its resource, including those reached through it by an arrow.
+	changedResources := map[string]*mapz.Set[string]{}
+	for _, update := range updates {
+		resource := update.Tuple.ResourceAndRelation
+		if _, ok := changedResources[resource.Namespace]; !ok {
+			changedResources[resource.Namespace] = mapz.NewSet[string]()
+		}
+		changedResources[resource.Namespace].Add(resource.ObjectId)
+	}
+
+	reader := ds.SnapshotReader(revision)
+	recomputed := make(map[string]map[string]memberSet, len(s.permissions))
+	for key, ps := range s.permissions {
+		affected := mapz.NewSet[string]()
+		for resourceType, changedIDs := range changedResources {
+			resourceIDs := changedIDs.AsSlice()
+			if resourceType == ps.resourceType {
+				affected.Extend(resourceIDs)
+			}
+
+			def, _, err := reader.ReadNamespaceByName(ctx, resourceType)
+			if err != nil {
+				if errors.As(err, &datastore.ErrNamespaceNotFound{}) {
+					continue
+				}
+				return nil, fmt.Errorf("error reading definition of `%s`: %w", resourceType, err)
+			}
+
+			for _, relation := range def.Relation {
+				reachable, err := s.reachableResources(ctx, ps, resourceType, relation.Name, resourceIDs, revision)
+				if err != nil {
+					return nil, err
+				}
+				affected.Extend(reachable)
+			}
+		}
+
+		if affected.IsEmpty() {
+			continue
+		}
+
+		resourceMembers, err := s.lookupMembers(ctx, ps, affected.AsSlice(), revision)
+		if err != nil {
+			return nil, err
+		}
+
+		// Resources without members after the changes must be removed.
+		for _, resourceID := range affected.AsSlice() {
+			if _, ok := resourceMembers[resourceID]; !ok {
+				resourceMembers[resourceID] = memberSet{}
+			}
+		}
+		recomputedCounter.Add(float64(affected.Len()))
+		recomputed[key] = resourceMembers
+	}
+	return recomputed, nil
+}
+
+// reachableResources returns the resources whose permission may be reached from the relation of
+// the given resources.
+func (s *Sets) reachableResources(ctx context.Context, ps *permissionSet, resourceType, relation string, resourceIDs []string, revision datastore.Revision) ([]string, error) {
+	metadata, err := s.resolverMeta(revision)
+	if err != nil {
+		return nil, err
+	}
+
+	stream := dispatch.NewCollectingDispatchStream[*v1.DispatchReachableResourcesResponse](ctx)
+	err = s.dispatcher.DispatchReachableResources(&v1.DispatchReachableResourcesRequest{
+		Metadata: metadata,
+		ResourceRelation: &core.RelationReference{
+			Namespace: ps.resourceType,
+			Relation:  ps.permission,
+		},
+		SubjectRelation: &core.RelationReference{
+			Namespace: resourceType,
+			Relation:  relation,
+		},
+		SubjectIds: resourceIDs,
+	}, stream)
+	if err != nil {
+		return nil, fmt.Errorf("error finding resources reachable from `%s`: %w", tuple.JoinRelRef(resourceType, relation), err)
+	}
+
+	reachable := make([]string, 0, len(stream.Results()))
+	for _, result := range stream.Results() {
+		reachable = append(reachable, result.Resource.ResourceId)
+	}
+	return reachable, nil
+}
+
+// lookupMembers returns the members of the permission on those of the resources that have any.
+func (s *Sets) lookupMembers(ctx context.Context, ps *permissionSet, resourceIDs []string, revision datastore.Revision) (map[string]memberSet, error) {
+	members := make(map[string]memberSet, len(resourceIDs))
+	for start := 0; start < len(resourceIDs); start += lookupChunkSize {
+		chunk := resourceIDs[start:min(start+lookupChunkSize, len(resourceIDs))]
+
+		metadata, err := s.resolverMeta(revision)
+		if err != nil {
+			return nil, err
+		}
+
+		stream := dispatch.NewCollectingDispatchStream[*v1.DispatchLookupSubjectsResponse](ctx)
+		err = s.dispatcher.DispatchLookupSubjects(&v1.DispatchLookupSubjectsRequest{
+			Metadata: metadata,
+			ResourceRelation: &core.RelationReference{
+				Namespace: ps.resourceType,
+				Relation:  ps.permission,
+			},
+			ResourceIds: chunk,
+			SubjectRelation: &core.RelationReference{
+				Namespace: ps.subjectType,
+				Relation:  tuple.Ellipsis,
+			},
+		}, stream)
+		if err != nil {
+			return nil, fmt.Errorf("error looking up members of `%s`: %w", tuple.JoinRelRef(ps.resourceType, ps.permission), err)
+		}
+
+		for _, result := range stream.Results() {
+			for resourceID, found := range result.FoundSubjectsByResourceId {
+				resourceMembers := members[resourceID]
+				if resourceMembers.subjects == nil {
+					resourceMembers.subjects = stringSet{}
+				}
+
+				for _, subject := range found.FoundSubjects {
+					switch {
+					case subject.CaveatExpression != nil:
+						resourceMembers.opaque = true
+					case subject.SubjectId == tuple.PublicWildcard && len(subject.ExcludedSubjects) > 0:
+						resourceMembers.opaque = true
+					case subject.SubjectId == tuple.PublicWildcard:
+						resourceMembers.wildcard = true
+					default:
+						resourceMembers.subjects[subject.SubjectId] = struct{}{}
+					}
+				}
+				members[resourceID] = resourceMembers
+			}
+		}
+	}
+	return members, nil
+}
+
+func (s *Sets) resolverMeta(revision datastore.Revision) (*v1.ResolverMeta, error) {
+	bf, err := v1.NewTraversalBloomFilter(uint(s.maxDepth))
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.ResolverMeta{
+		AtRevision:     revision.String(),
+		DepthRemaining: s.maxDepth,
+		TraversalBloom: bf,
+	}, nil
+}
+
+// resourcesOfType returns the IDs of the resources of the type with any relationship.
+func resourcesOfType(ctx context.Context, reader datastore.Reader, resourceType string) ([]string, error) {
+	it, err := reader.QueryRelationships(ctx, datastore.RelationshipsFilter{ResourceType: resourceType})
+	if err != nil {
+		return nil, fmt.Errorf("error reading relationships of `%s`: %w", resourceType, err)
+	}
+	defer it.Close()
+
+	resourceIDs := mapz.NewSet[string]()
+	for rel := it.Next(); rel != nil; rel = it.Next() {
+		resourceIDs.Add(rel.ResourceAndRelation.ObjectId)
+	}
+	if it.Err() != nil {
+		return nil, fmt.Errorf("error reading relationships of `%s`: %w", resourceType, it.Err())
+	}
+	return resourceIDs.AsSlice(), nil
+}
@@ -0,0 +1,405 @@
+// Package grpcweb implements an HTTP server that translates gRPC-Web requests,
+// such as those made by browser clients, into calls against an upstream SpiceDB
+// gRPC server.
+package grpcweb
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/authzed/grpcutil"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+const (
+	contentTypeGRPCWeb      = "application/grpc-web"
+	contentTypeGRPCWebProto = "application/grpc-web+proto"
+
+	frameHeaderLength      = 5
+	dataFrameFlag     byte = 0x00
+	trailerFrameFlag  byte = 0x80
+
+	// maxRequestMessageSize matches the default maximum received message size of a gRPC server.
+	maxRequestMessageSize = 4 * 1024 * 1024
+)
+
+// AllowedRequestHeaders are the headers which browser clients must be allowed to send
+// when making gRPC-Web requests across origins.
+var AllowedRequestHeaders = []string{"Authorization", "Content-Type", "X-Grpc-Web", "X-User-Agent", "Grpc-Timeout"}
+
+// ExposedResponseHeaders are the headers which browser clients must be allowed to read
+// from gRPC-Web responses across origins.
+var ExposedResponseHeaders = []string{"Grpc-Status", "Grpc-Message", "Grpc-Status-Details-Bin"}
+
+// ignoredHeaders are the HTTP request headers that are not forwarded as gRPC metadata.
+var ignoredHeaders = map[string]struct{}{
+	"accept":            {},
+	"accept-encoding":   {},
+	"accept-language":   {},
+	"connection":        {},
+	"content-length":    {},
+	"content-type":      {},
+	"cookie":            {},
+	"grpc-timeout":      {},
+	"host":              {},
+	"keep-alive":        {},
+	"origin":            {},
+	"referer":           {},
+	"te":                {},
+	"transfer-encoding": {},
+	"upgrade":           {},
+	"x-grpc-web":        {},
+}
+
+// Handler is an http.Handler which proxies gRPC-Web requests to an upstream gRPC server
+// and an io.Closer which closes the upstream connection.
+type Handler struct {
+	conn *grpc.ClientConn
+}
+
+// NewHandler creates a gRPC-Web Handler with the provided upstream configuration. The
+// upstream is dialed over TLS if upstreamTLS is set or a certificate path is given, as when
+// the upstream's certificate is held in memory rather than on disk. If the upstream requires
+// client certificates, upstreamClientCert returns the one presented to it.
+func NewHandler(ctx context.Context, upstreamAddr, upstreamTLSCertPath string, upstreamTLS bool, upstreamClientCert func(*tls.CertificateRequestInfo) (*tls.Certificate, error)) (*Handler, error) {
+	if upstreamAddr == "" {
+		return nil, fmt.Errorf("upstreamAddr must not be empty")
+	}
+
+	var opts []grpc.DialOption
+	switch {
+	case upstreamClientCert != nil:
+		// As with the other cases, the upstream's certificate is not verified.
+		opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{
+			GetClientCertificate: upstreamClientCert,
+			InsecureSkipVerify:   true, // nolint:gosec
+			MinVersion:           tls.VersionTLS12,
+		})))
+	case upstreamTLSCertPath != "":
+		certsOpt, err := grpcutil.WithCustomCerts(grpcutil.SkipVerifyCA, upstreamTLSCertPath)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, certsOpt)
+	case upstreamTLS:
+		certsOpt, err := grpcutil.WithSystemCerts(grpcutil.SkipVerifyCA)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, certsOpt)
+	default:
+		opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	}
+
+	conn, err := grpc.DialContext(ctx, upstreamAddr, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	return newHandler(conn), nil
+}
+
+func newHandler(conn *grpc.ClientConn) *Handler {
+	return &Handler{conn: conn}
+}
+
+// Close closes the connection to the upstream gRPC server.
+func (h *Handler) Close() error {
+	return h.conn.Close()
+}
+
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "gRPC-Web requests must use POST", http.StatusMethodNotAllowed)
+		return
+	}
+
+	contentType := strings.ToLower(r.Header.Get("Content-Type"))
+	if contentType != contentTypeGRPCWeb && contentType != contentTypeGRPCWebProto {
+		http.Error(w, fmt.Sprintf("unsupported content type `%s`", contentType), http.StatusUnsupportedMediaType)
+		return
+	}
+
+	ctx := r.Context()
+	if timeoutValue := r.Header.Get("Grpc-Timeout"); timeoutValue != "" {
+		timeout, err := parseTimeout(timeoutValue)
+		if err != nil {
+			writeStatusOnly(w, status.Newf(codes.InvalidArgument, "invalid grpc-timeout: %s", err))
+			return
+		}
+
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	md, err := metadataFromHeaders(r.Header)
+	if err != nil {
+		writeStatusOnly(w, status.New(codes.InvalidArgument, err.Error()))
+		return
+	}
+	ctx = metadata.NewOutgoingContext(ctx, md)
+
+	messages, err := readFrames(r.Body)
+	if err != nil {
+		writeStatusOnly(w, status.Convert(err))
+		return
+	}
+
+	stream, err := h.conn.NewStream(
+		ctx,
+		&grpc.StreamDesc{ServerStreams: true, ClientStreams: true},
+		r.URL.Path,
+		grpc.ForceCodec(rawCodec{}),
+	)
+	if err != nil {
+		writeStatusOnly(w, status.Convert(err))
+		return
+	}
+
+	for _, msg := range messages {
+		// An error here indicates the stream has terminated; the status is returned by RecvMsg.
+		if err := stream.SendMsg(msg); err != nil {
+			break
+		}
+	}
+	_ = stream.CloseSend()
+
+	flusher, _ := w.(http.Flusher)
+	headersWritten := false
+	writeHeaders := func() {
+		header, _ := stream.Header()
+		writeMetadata(w.Header(), header)
+		w.Header().Set("Content-Type", contentTypeGRPCWebProto)
+		w.WriteHeader(http.StatusOK)
+		headersWritten = true
+	}
+
+	var finalStatus *status.Status
+	for {
+		var msg []byte
+		if err := stream.RecvMsg(&msg); err != nil {
+			if errors.Is(err, io.EOF) {
+				finalStatus = status.New(codes.OK, "")
+			} else {
+				finalStatus = status.Convert(err)
+			}
+			break
+		}
+
+		if !headersWritten {
+			writeHeaders()
+		}
+
+		if _, err := w.Write(frame(dataFrameFlag, msg)); err != nil {
+			return
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	if !headersWritten {
+		writeHeaders()
+	}
+
+	_, _ = w.Write(frame(trailerFrameFlag, trailerBytes(finalStatus, stream.Trailer())))
+}
+
+// writeStatusOnly writes a response containing only the given status, for requests that
+// failed before reaching the upstream server.
+func writeStatusOnly(w http.ResponseWriter, st *status.Status) {
+	w.Header().Set("Content-Type", contentTypeGRPCWebProto)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(frame(trailerFrameFlag, trailerBytes(st, nil)))
+}
+
+// metadataFromHeaders returns the gRPC metadata to forward for the given HTTP request headers.
+func metadataFromHeaders(headers http.Header) (metadata.MD, error) {
+	md := metadata.MD{}
+	for key, values := range headers {
+		lowered := strings.ToLower(key)
+		if _, ok := ignoredHeaders[lowered]; ok || strings.HasPrefix(lowered, "sec-") {
+			continue
+		}
+
+		for _, value := range values {
+			if strings.HasSuffix(lowered, "-bin") {
+				decoded, err := decodeBinaryHeader(value)
+				if err != nil {
+					return nil, fmt.Errorf("invalid binary header `%s`: %w", lowered, err)
+				}
+				value = string(decoded)
+			}
+			md.Append(lowered, value)
+		}
+	}
+	return md, nil
+}
+
+func writeMetadata(headers http.Header, md metadata.MD) {
+	for key, values := range md {
+		for _, value := range values {
+			if strings.HasSuffix(key, "-bin") {
+				value = base64.RawStdEncoding.EncodeToString([]byte(value))
+			}
+			headers.Add(key, value)
+		}
+	}
+}
+
+func decodeBinaryHeader(value string) ([]byte, error) {
+	if len(value)%4 == 0 {
+		return base64.StdEncoding.DecodeString(value)
+	}
+	return base64.RawStdEncoding.DecodeString(value)
+}
+
+// readFrames reads the length-prefixed messages found in a gRPC-Web request body.
+func readFrames(body io.Reader) ([][]byte, error) {
+	var messages [][]byte
+	header := make([]byte, frameHeaderLength)
+	for {
+		if _, err := io.ReadFull(body, header); err != nil {
+			if errors.Is(err, io.EOF) {
+				return messages, nil
+			}
+			return nil, status.Errorf(codes.InvalidArgument, "malformed gRPC-Web request frame: %s", err)
+		}
+
+		if header[0] != dataFrameFlag {
+			return nil, status.Errorf(codes.Unimplemented, "compressed gRPC-Web requests are not supported")
+		}
+
+		length := binary.BigEndian.Uint32(header[1:])
+		if length > maxRequestMessageSize {
+			return nil, status.Errorf(codes.ResourceExhausted, "request message larger than max (%d vs. %d)", length, maxRequestMessageSize)
+		}
+
+		msg := make([]byte, length)
+		if _, err := io.ReadFull(body, msg); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "malformed gRPC-Web request frame: %s", err)
+		}
+		messages = append(messages, msg)
+	}
+}
+
+func frame(flag byte, payload []byte) []byte {
+	framed := make([]byte, frameHeaderLength+len(payload))
+	framed[0] = flag
+	binary.BigEndian.PutUint32(framed[1:frameHeaderLength], uint32(len(payload)))
+	copy(framed[frameHeaderLength:], payload)
+	return framed
+}
+
+// trailerBytes returns the contents of the trailer frame for the given status and trailer metadata.
+func trailerBytes(st *status.Status, trailer metadata.MD) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "grpc-status: %d\r\n", st.Code())
+	if st.Message() != "" {
+		fmt.Fprintf(&buf, "grpc-message: %s\r\n", encodeGrpcMessage(st.Message()))
+	}
+
+	if p := st.Proto(); p != nil && len(p.Details) > 0 {
+		if encoded, err := proto.Marshal(p); err == nil {
+			fmt.Fprintf(&buf, "grpc-status-details-bin: %s\r\n", base64.RawStdEncoding.EncodeToString(encoded))
+		}
+	}
+
+	for key, values := range trailer {
+		for _, value := range values {
+			if strings.HasSuffix(key, "-bin") {
+				value = base64.RawStdEncoding.EncodeToString([]byte(value))
+			}
+			fmt.Fprintf(&buf, "%s: %s\r\n", key, value)
+		}
+	}
+	return buf.Bytes()
+}
+
+// encodeGrpcMessage percent-encodes the status message, as required by the gRPC protocol.
+func encodeGrpcMessage(msg string) string {
+	var sb strings.Builder
+	for i := 0; i < len(msg); i++ {
+		c := msg[i]
+		if c >= ' ' && c <= '~' && c != '%' {
+			sb.WriteByte(c)
+		} else {
+			fmt.Fprintf(&sb, "%%%02X", c)
+		}
+	}
+	return sb.String()
+}
+
+// parseTimeout parses a grpc-timeout header value, such as `10S` or `500m`.
+func parseTimeout(value string) (time.Duration, error) {
+	if len(value) < 2 || len(value) > 9 {
+		return 0, fmt.Errorf("malformed timeout `%s`", value)
+	}
+
+	amount, err := strconv.ParseInt(value[:len(value)-1], 10, 64)
+	if err != nil || amount < 0 {
+		return 0, fmt.Errorf("malformed timeout `%s`", value)
+	}
+
+	var unit time.Duration
+	switch value[len(value)-1] {
+	case 'H':
+		unit = time.Hour
+	case 'M':
+		unit = time.Minute
+	case 'S':
+		unit = time.Second
+	case 'm':
+		unit = time.Millisecond
+	case 'u':
+		unit = time.Microsecond
+	case 'n':
+		unit = time.Nanosecond
+	default:
+		return 0, fmt.Errorf("unknown timeout unit in `%s`", value)
+	}
+
+	return time.Duration(amount) * unit, nil
+}
+
+// rawCodec passes already-serialized protobuf messages through unchanged.
+type rawCodec struct{}
+
+func (rawCodec) Marshal(v any) ([]byte, error) {
+	msg, ok := v.([]byte)
+	if !ok {
+		return nil, fmt.Errorf("unexpected message type %T", v)
+	}
+	return msg, nil
+}
+
+func (rawCodec) Unmarshal(data []byte, v any) error {
+	msg, ok := v.(*[]byte)
+	if !ok {
+		return fmt.Errorf("unexpected message type %T", v)
+	}
+	*msg = append((*msg)[:0], data...)
+	return nil
+}
+
+// Name returns the name of the proto codec, so that the upstream server decodes messages as protobuf.
+func (rawCodec) Name() string {
+	return "proto"
+}
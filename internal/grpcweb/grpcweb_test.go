@@ -0,0 +1,188 @@
+package grpcweb
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestHandler(t *testing.T) {
+	listener := bufconn.Listen(humanize.MiByte)
+	s := grpc.NewServer()
+
+	healthSrv := health.NewServer()
+	healthSrv.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(s, healthSrv)
+
+	go func() {
+		// Ignore any errors
+		_ = s.Serve(listener)
+	}()
+
+	conn, err := grpc.DialContext(
+		context.Background(),
+		"",
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) {
+			return listener.Dial()
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithBlock(),
+	)
+	require.NoError(t, err)
+
+	handler := newHandler(conn)
+	t.Cleanup(func() {
+		handler.Close()
+		listener.Close()
+		s.Stop()
+	})
+
+	testCases := []struct {
+		name             string
+		path             string
+		request          proto.Message
+		expectedStatus   codes.Code
+		expectedResponse *healthpb.HealthCheckResponse
+	}{
+		{
+			"unary call",
+			"/grpc.health.v1.Health/Check",
+			&healthpb.HealthCheckRequest{},
+			codes.OK,
+			&healthpb.HealthCheckResponse{Status: healthpb.HealthCheckResponse_SERVING},
+		},
+		{
+			"unknown service",
+			"/grpc.health.v1.Health/Check",
+			&healthpb.HealthCheckRequest{Service: "unknown"},
+			codes.NotFound,
+			nil,
+		},
+		{
+			"unknown method",
+			"/grpc.health.v1.Health/Unknown",
+			&healthpb.HealthCheckRequest{},
+			codes.Unimplemented,
+			nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			encoded, err := proto.Marshal(tc.request)
+			require.NoError(t, err)
+
+			req := httptest.NewRequest(http.MethodPost, tc.path, bytes.NewReader(frame(dataFrameFlag, encoded)))
+			req.Header.Set("Content-Type", contentTypeGRPCWebProto)
+			req.Header.Set("Grpc-Timeout", "10S")
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+			require.Equal(t, http.StatusOK, recorder.Code)
+			require.Equal(t, contentTypeGRPCWebProto, recorder.Header().Get("Content-Type"))
+
+			messages, trailer := parseResponse(t, recorder.Body)
+			require.Contains(t, string(trailer), "grpc-status: "+strconv.Itoa(int(tc.expectedStatus))+"\r\n")
+
+			if tc.expectedResponse == nil {
+				require.Empty(t, messages)
+				return
+			}
+
+			require.Len(t, messages, 1)
+			resp := &healthpb.HealthCheckResponse{}
+			require.NoError(t, proto.Unmarshal(messages[0], resp))
+			require.True(t, proto.Equal(tc.expectedResponse, resp))
+		})
+	}
+}
+
+func TestHandlerRejectsInvalidRequests(t *testing.T) {
+	handler := &Handler{}
+
+	req := httptest.NewRequest(http.MethodGet, "/grpc.health.v1.Health/Check", nil)
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+
+	req = httptest.NewRequest(http.MethodPost, "/grpc.health.v1.Health/Check", nil)
+	req.Header.Set("Content-Type", "application/json")
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusUnsupportedMediaType, recorder.Code)
+}
+
+func TestParseTimeout(t *testing.T) {
+	testCases := []struct {
+		value         string
+		expected      time.Duration
+		expectedError bool
+	}{
+		{"1H", time.Hour, false},
+		{"2M", 2 * time.Minute, false},
+		{"10S", 10 * time.Second, false},
+		{"500m", 500 * time.Millisecond, false},
+		{"100u", 100 * time.Microsecond, false},
+		{"5n", 5 * time.Nanosecond, false},
+		{"S", 0, true},
+		{"10", 0, true},
+		{"10X", 0, true},
+		{"-1S", 0, true},
+		{"123456789S", 0, true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.value, func(t *testing.T) {
+			timeout, err := parseTimeout(tc.value)
+			if tc.expectedError {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, timeout)
+		})
+	}
+}
+
+func TestEncodeGrpcMessage(t *testing.T) {
+	require.Equal(t, "hello world", encodeGrpcMessage("hello world"))
+	require.Equal(t, "100%25 done%0A", encodeGrpcMessage("100% done\n"))
+}
+
+func parseResponse(t *testing.T, body io.Reader) ([][]byte, []byte) {
+	var messages [][]byte
+	header := make([]byte, frameHeaderLength)
+	for {
+		_, err := io.ReadFull(body, header)
+		require.NoError(t, err, "missing trailer frame")
+
+		payload := make([]byte, binary.BigEndian.Uint32(header[1:]))
+		_, err = io.ReadFull(body, payload)
+		require.NoError(t, err)
+
+		if header[0] == trailerFrameFlag {
+			return messages, payload
+		}
+		messages = append(messages, payload)
+	}
+}
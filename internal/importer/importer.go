@@ -0,0 +1,94 @@
+// Package importer converts the models and relationships of other Zanzibar-style systems,
+// such as OpenFGA, into SpiceDB schemas and relationships, reporting what cannot be
+// converted.
+package importer
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/generator"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+	"github.com/authzed/spicedb/pkg/schemautil"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// Issue is a construct which was converted differently than written, or not at all.
+type Issue struct {
+	// Location identifies the construct, such as `type document, relation viewer`.
+	Location string
+
+	// Message describes how the construct was converted, or why it could not be.
+	Message string
+}
+
+func (i Issue) String() string {
+	return i.Location + ": " + i.Message
+}
+
+// Result is a converted schema and its relationships.
+type Result struct {
+	Schema        string
+	Relationships []*core.RelationTuple
+	Issues        []Issue
+}
+
+func (r *Result) addIssue(location, format string, args ...any) {
+	r.Issues = append(r.Issues, Issue{Location: location, Message: fmt.Sprintf(format, args...)})
+}
+
+// ValidationFile returns the result as a validation file, which can be loaded with
+// `--datastore-bootstrap-files` or checked with the validate command.
+func (r *Result) ValidationFile() ([]byte, error) {
+	lines := make([]string, 0, len(r.Relationships))
+	for _, rel := range r.Relationships {
+		line, err := tuple.String(rel)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+
+	return yamlv3.Marshal(struct {
+		Schema        string `yaml:"schema"`
+		Relationships string `yaml:"relationships"`
+	}{
+		Schema:        r.Schema,
+		Relationships: strings.Join(lines, "\n"),
+	})
+}
+
+var identifierRegex = regexp.MustCompile(`^[a-z][a-z0-9_]{1,62}[a-z0-9]$`)
+
+// validIdentifier returns whether the name can be used for a definition, relation, permission
+// or caveat in a SpiceDB schema.
+func validIdentifier(name string) bool {
+	return identifierRegex.MatchString(name)
+}
+
+// finishSchema compiles and validates the converted schema, returning it in canonical form.
+func finishSchema(ctx context.Context, source string) (string, error) {
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source("converted"),
+		SchemaString: source,
+	}, compiler.AllowUnprefixedObjectType())
+	if err != nil {
+		return source, fmt.Errorf("converted schema is invalid: %w", err)
+	}
+
+	if _, err := schemautil.ValidateSchemaChanges(ctx, compiled, false); err != nil {
+		return source, fmt.Errorf("converted schema is invalid: %w", err)
+	}
+
+	schema, _, err := generator.GenerateSchema(compiled.OrderedDefinitions)
+	if err != nil {
+		return source, fmt.Errorf("failed to generate converted schema: %w", err)
+	}
+	return schema, nil
+}
@@ -0,0 +1,268 @@
+package importer
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/validationfile"
+)
+
+const openFGAModel = `model
+  schema 1.1
+
+type user
+
+type group
+  relations
+    define member: [user, group#member]
+
+type folder
+  relations
+    define owner: [user]
+    define viewer: [user, user:*] or owner
+
+# Documents inherit the viewers of their folders.
+type document
+  relations
+    define parent: [folder]
+    define writer: [user with office_hours]
+    define viewer: [user, group#member] or writer or viewer from parent
+    define blocked: [user]
+    define can_view: viewer but not blocked
+
+condition office_hours(current_hour: int, allowed: list<int>) {
+  current_hour in allowed
+}
+`
+
+func TestConvertOpenFGA(t *testing.T) {
+	store := `name: Test
+model: |
+` + indent(openFGAModel) + `
+tuples:
+  - user: user:anne
+    relation: viewer
+    object: document:readme
+  - user: group:eng#member
+    relation: viewer
+    object: document:readme
+  - user: user:beth
+    relation: writer
+    object: document:readme
+    condition:
+      name: office_hours
+      context:
+        allowed: [9, 10, 11]
+  - user: user:*
+    relation: viewer
+    object: folder:public
+  - user: user:anne
+    relation: can_view
+    object: document:readme
+  - user: user:anne
+    relation: editor
+    object: document:readme
+  - user: user:anne
+    relation: viewer
+    object: "document:{bad}"
+`
+
+	result, err := ConvertOpenFGA(context.Background(), []byte(store), "")
+	require.NoError(t, err)
+
+	require.Equal(t, `caveat office_hours(allowed list<int>, current_hour int) {
+	current_hour in allowed
+}
+
+definition user {}
+
+definition group {
+	relation member: user | group#member
+}
+
+definition folder {
+	relation owner: user
+	relation viewer_direct: user | user:*
+	permission viewer = viewer_direct + owner
+}
+
+definition document {
+	relation parent: folder
+	relation writer: user with office_hours
+	relation viewer_direct: user | group#member
+	permission viewer = viewer_direct + writer + parent->viewer
+	relation blocked: user
+	permission can_view = viewer - blocked
+}`, result.Schema)
+
+	var rels []string
+	for _, rel := range result.Relationships {
+		rels = append(rels, tuple.StringWithoutCaveat(rel))
+	}
+	require.Equal(t, []string{
+		"document:readme#viewer_direct@user:anne",
+		"document:readme#viewer_direct@group:eng#member",
+		"document:readme#writer@user:beth",
+		"folder:public#viewer_direct@user:*",
+	}, rels)
+
+	// The context is compared as a map, as its JSON encoding varies in whitespace.
+	caveat := result.Relationships[2].Caveat
+	require.Equal(t, "office_hours", caveat.CaveatName)
+	require.Equal(t, map[string]any{"allowed": []any{9.0, 10.0, 11.0}}, caveat.Context.AsMap())
+
+	var issues []string
+	for _, issue := range result.Issues {
+		issues = append(issues, issue.String())
+	}
+	require.Equal(t, []string{
+		"type folder, relation viewer: relation mixes direct types with rewrites, so it is converted to a permission; relationships are written to the new relation `viewer_direct`",
+		"type document, relation viewer: relation mixes direct types with rewrites, so it is converted to a permission; relationships are written to the new relation `viewer_direct`",
+		"tuple `document:readme#can_view@user:anne`: relation `can_view` has no direct types; the tuple was skipped",
+		"tuple `document:readme#editor@user:anne`: relation `editor` is not in the model; the tuple was skipped",
+		"tuple `document:{bad}#viewer@user:anne`: invalid resource id; must match ([a-zA-Z0-9/_|\\-=+]{1,}); the tuple was skipped",
+	}, issues)
+
+	// The result can be loaded as a validation file.
+	contents, err := result.ValidationFile()
+	require.NoError(t, err)
+
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+	populated, _, err := validationfile.PopulateFromFilesContents(context.Background(), ds, map[string][]byte{"converted": contents})
+	require.NoError(t, err)
+	require.Len(t, populated.Tuples, 4)
+}
+
+func TestConvertOpenFGAFiles(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "model.fga"), []byte(openFGAModel), 0o600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tuples.json"), []byte(`[{"user": "user:anne", "relation": "owner", "object": "folder:root"}]`), 0o600))
+
+	result, err := ConvertOpenFGA(context.Background(), []byte("model_file: model.fga\ntuple_file: tuples.json\n"), dir)
+	require.NoError(t, err)
+	require.Len(t, result.Relationships, 1)
+	require.Equal(t, "folder:root#owner@user:anne", tuple.MustString(result.Relationships[0]))
+}
+
+func TestConvertOpenFGAUnsupported(t *testing.T) {
+	for _, tc := range []struct {
+		name          string
+		store         string
+		expectedError string
+		expectedIssue string
+	}{
+		{
+			name:          "no model",
+			store:         "tuples: []",
+			expectedError: "OpenFGA store has no model",
+		},
+		{
+			name:          "JSON model",
+			store:         `model: '{"schema_version": "1.1"}'`,
+			expectedError: "JSON authorization models are not supported",
+		},
+		{
+			name:          "schema 1.0",
+			store:         "model: |\n  model\n    schema 1.0\n  type user\n",
+			expectedError: "unsupported OpenFGA schema version 1.0",
+		},
+		{
+			name:          "modules",
+			store:         "model: |\n  module documents\n  type user\n",
+			expectedIssue: "line 1: modular models are not supported",
+		},
+		{
+			name:          "invalid type name",
+			store:         "model: |\n  model\n    schema 1.1\n  type u\n",
+			expectedError: "converted schema is invalid",
+			expectedIssue: "line 3: type `u` is not a valid SpiceDB definition name",
+		},
+		{
+			name:          "invalid relation",
+			store:         "model: |\n  model\n    schema 1.1\n  type user\n    relations\n      define friend: [user] or from\n",
+			expectedIssue: "type user, relation friend: unexpected `from`; the relation was skipped",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := ConvertOpenFGA(context.Background(), []byte(tc.store), "")
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+
+			if tc.expectedIssue != "" {
+				require.NotNil(t, result)
+				var issues []string
+				for _, issue := range result.Issues {
+					issues = append(issues, issue.String())
+				}
+				require.Contains(t, strings.Join(issues, "\n"), tc.expectedIssue)
+			}
+		})
+	}
+}
+
+func TestConvertTuples(t *testing.T) {
+	dump := `# exported tuples
+document:readme#viewer@user:anne
+document:readme#viewer@group:eng#member
+document:readme#parent@folder:root
+folder:root#viewer@user:*
+group:eng#member@user:beth
+
+document:readme#editor@team:eng#member
+document:readme#viewer@user:carl[some_caveat]
+not a tuple
+`
+
+	result, err := ConvertTuples(context.Background(), strings.NewReader(dump))
+	require.NoError(t, err)
+
+	require.Equal(t, `definition document {
+	relation parent: folder
+	relation viewer: group#member | user
+}
+
+definition folder {
+	relation viewer: user:*
+}
+
+definition group {
+	relation member: user
+}
+
+definition team {}
+
+definition user {}`, result.Schema)
+	require.Len(t, result.Relationships, 5)
+
+	var issues []string
+	for _, issue := range result.Issues {
+		issues = append(issues, issue.String())
+	}
+	require.Equal(t, []string{
+		"line 9: caveat `some_caveat` cannot be inferred from tuples; the tuple was skipped",
+		"line 10: invalid tuple `not a tuple` was skipped",
+		"tuple `document:readme#editor@team:eng#member`: subject relation `team#member` has no tuples, so its type cannot be inferred; the tuple was skipped",
+		"schema: permissions cannot be inferred from tuples; the schema only contains relations",
+	}, issues)
+}
+
+func indent(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = "  " + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
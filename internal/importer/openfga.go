@@ -0,0 +1,466 @@
+package importer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"google.golang.org/protobuf/types/known/structpb"
+	yamlv3 "gopkg.in/yaml.v3"
+
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// openFGAStore is the store file written by `fga store export` and read by `fga store import`.
+type openFGAStore struct {
+	Model     string         `yaml:"model"`
+	ModelFile string         `yaml:"model_file"`
+	Tuples    []openFGATuple `yaml:"tuples"`
+	TupleFile string         `yaml:"tuple_file"`
+}
+
+type openFGATuple struct {
+	User      string `yaml:"user"`
+	Relation  string `yaml:"relation"`
+	Object    string `yaml:"object"`
+	Condition *struct {
+		Name    string         `yaml:"name"`
+		Context map[string]any `yaml:"context"`
+	} `yaml:"condition"`
+}
+
+func (t openFGATuple) String() string {
+	return fmt.Sprintf("tuple `%s#%s@%s`", t.Object, t.Relation, t.User)
+}
+
+// ConvertOpenFGA converts an OpenFGA store file, with a model in the DSL of schema 1.1, into
+// a SpiceDB schema and relationships. Model and tuple files referenced by the store are read
+// relative to baseDir.
+//
+// Each OpenFGA type becomes a definition. Relations defined only by direct types become
+// relations, and those defined only by rewrites become permissions. Relations defined by both
+// are split into a relation holding the direct types, named after the relation with a
+// `_direct` suffix, and a permission of the original name, with the relationships of the
+// relation written to the former. Conditions become caveats.
+func ConvertOpenFGA(ctx context.Context, contents []byte, baseDir string) (*Result, error) {
+	var store openFGAStore
+	if err := yamlv3.Unmarshal(contents, &store); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenFGA store: %w", err)
+	}
+
+	model := store.Model
+	if store.ModelFile != "" {
+		modelContents, err := os.ReadFile(filepath.Join(baseDir, store.ModelFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read model file: %w", err)
+		}
+		model = string(modelContents)
+	}
+	if strings.TrimSpace(model) == "" {
+		return nil, errors.New("OpenFGA store has no model")
+	}
+	if strings.HasPrefix(strings.TrimSpace(model), "{") {
+		return nil, errors.New("JSON authorization models are not supported; export the model in the DSL with `fga model get --format fga`")
+	}
+
+	tuples := store.Tuples
+	if store.TupleFile != "" {
+		tupleContents, err := os.ReadFile(filepath.Join(baseDir, store.TupleFile))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tuple file: %w", err)
+		}
+		var fileTuples []openFGATuple
+		if err := yamlv3.Unmarshal(tupleContents, &fileTuples); err != nil {
+			return nil, fmt.Errorf("failed to parse tuple file: %w", err)
+		}
+		tuples = append(tuples, fileTuples...)
+	}
+
+	result := &Result{}
+	types, conditions, err := parseOpenFGAModel(model, result)
+	if err != nil {
+		return nil, err
+	}
+
+	schema, schemaErr := finishSchema(ctx, generateOpenFGASchema(types, conditions, result))
+	result.Schema = schema
+
+	byName := make(map[string]*fgaType, len(types))
+	for _, t := range types {
+		byName[t.name] = t
+	}
+	for _, t := range tuples {
+		if rel := convertOpenFGATuple(t, byName, result); rel != nil {
+			result.Relationships = append(result.Relationships, rel)
+		}
+	}
+
+	return result, schemaErr
+}
+
+type fgaType struct {
+	name      string
+	relations []*fgaRelation
+}
+
+func (t *fgaType) relation(name string) *fgaRelation {
+	for _, r := range t.relations {
+		if r.name == name {
+			return r
+		}
+	}
+	return nil
+}
+
+type fgaRelation struct {
+	name string
+
+	// direct are the direct types of the relation, converted to allowed subject types.
+	direct []string
+
+	// expression is the definition of the relation, in which the direct types, if any, are
+	// represented by directToken.
+	expression []string
+
+	// directName is the name of the relation holding the direct types, if any.
+	directName string
+}
+
+// isDirectOnly returns whether the relation is defined only by its direct types.
+func (r *fgaRelation) isDirectOnly() bool {
+	return len(r.expression) == 1 && r.expression[0] == directToken
+}
+
+type fgaCondition struct {
+	name       string
+	parameters []string
+	expression string
+}
+
+const directToken = "[direct]"
+
+var (
+	commentRegex   = regexp.MustCompile(`(^|\s)#.*$`)
+	conditionRegex = regexp.MustCompile(`^condition\s+([A-Za-z0-9_]+)\s*\(([^)]*)\)\s*\{(.*)$`)
+)
+
+// parseOpenFGAModel parses a model in the OpenFGA DSL, reporting constructs which cannot be
+// converted.
+func parseOpenFGAModel(model string, result *Result) ([]*fgaType, []*fgaCondition, error) {
+	var types []*fgaType
+	var conditions []*fgaCondition
+	var current *fgaType
+
+	lines := strings.Split(model, "\n")
+	for i := 0; i < len(lines); i++ {
+		line := strings.TrimSpace(commentRegex.ReplaceAllString(lines[i], ""))
+		location := fmt.Sprintf("line %d", i+1)
+
+		switch {
+		case line == "" || line == "model" || line == "relations":
+
+		case strings.HasPrefix(line, "schema "):
+			if version := strings.TrimSpace(strings.TrimPrefix(line, "schema ")); version != "1.1" {
+				return nil, nil, fmt.Errorf("unsupported OpenFGA schema version %s: only 1.1 is supported", version)
+			}
+
+		case strings.HasPrefix(line, "module ") || strings.HasPrefix(line, "extend type "):
+			result.addIssue(location, "modular models are not supported; combine the modules with `fga model write` and export the combined model")
+			current = nil
+
+		case strings.HasPrefix(line, "type "):
+			current = &fgaType{name: strings.TrimSpace(strings.TrimPrefix(line, "type "))}
+			if !validIdentifier(current.name) {
+				result.addIssue(location, "type `%s` is not a valid SpiceDB definition name", current.name)
+			}
+			types = append(types, current)
+
+		case strings.HasPrefix(line, "define "):
+			if current == nil {
+				result.addIssue(location, "relation outside of a type was skipped")
+				continue
+			}
+			name, definition, ok := strings.Cut(strings.TrimPrefix(line, "define "), ":")
+			name = strings.TrimSpace(name)
+			if !ok {
+				result.addIssue(location, "relation `%s` has no definition and was skipped", name)
+				continue
+			}
+			relation, err := parseOpenFGARelation(name, definition)
+			if err != nil {
+				result.addIssue(fmt.Sprintf("type %s, relation %s", current.name, name), "%s; the relation was skipped", err)
+				continue
+			}
+			current.relations = append(current.relations, relation)
+
+		case strings.HasPrefix(line, "condition "):
+			condition, end, err := parseOpenFGACondition(lines, i)
+			if err != nil {
+				return nil, nil, fmt.Errorf("%s: %w", location, err)
+			}
+			conditions = append(conditions, condition)
+			i = end
+
+		default:
+			result.addIssue(location, "unrecognized statement `%s` was skipped", line)
+		}
+	}
+
+	return types, conditions, nil
+}
+
+// parseOpenFGACondition parses the condition starting at the given line, returning it with the
+// index of its last line.
+func parseOpenFGACondition(lines []string, start int) (*fgaCondition, int, error) {
+	groups := conditionRegex.FindStringSubmatch(strings.TrimSpace(lines[start]))
+	if groups == nil {
+		return nil, 0, errors.New("invalid condition")
+	}
+
+	condition := &fgaCondition{name: groups[1]}
+	for _, parameter := range strings.Split(groups[2], ",") {
+		name, paramType, ok := strings.Cut(parameter, ":")
+		if !ok {
+			return nil, 0, fmt.Errorf("invalid parameter `%s` of condition `%s`", strings.TrimSpace(parameter), condition.name)
+		}
+		condition.parameters = append(condition.parameters, strings.TrimSpace(name)+" "+strings.TrimSpace(paramType))
+	}
+
+	// The body ends at the brace closing the one opening it, which may be on the same line.
+	body := []string{groups[3]}
+	depth := 1
+	for end := start; ; {
+		for _, text := range body[len(body)-1] {
+			switch text {
+			case '{':
+				depth++
+			case '}':
+				depth--
+			}
+		}
+		if depth <= 0 {
+			expression := strings.TrimSpace(strings.Join(body, "\n"))
+			condition.expression = strings.TrimSpace(strings.TrimSuffix(expression, "}"))
+			return condition, end, nil
+		}
+
+		end++
+		if end >= len(lines) {
+			return nil, 0, fmt.Errorf("condition `%s` is not closed", condition.name)
+		}
+		body = append(body, lines[end])
+	}
+}
+
+// parseOpenFGARelation converts the definition of a relation into the tokens of a SpiceDB
+// expression: `or`, `and` and `but not` become `+`, `&` and `-`, and `X from Y` becomes
+// `Y->X`.
+func parseOpenFGARelation(name, definition string) (*fgaRelation, error) {
+	tokens, err := tokenizeOpenFGA(definition)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, errors.New("empty definition")
+	}
+
+	relation := &fgaRelation{name: name}
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		switch {
+		case strings.HasPrefix(token, "["):
+			if relation.direct != nil {
+				return nil, errors.New("direct types are listed more than once")
+			}
+			for _, direct := range strings.Split(strings.Trim(token, "[]"), ",") {
+				relation.direct = append(relation.direct, strings.Join(strings.Fields(direct), " "))
+			}
+			relation.expression = append(relation.expression, directToken)
+
+		case token == "or":
+			relation.expression = append(relation.expression, "+")
+
+		case token == "and":
+			relation.expression = append(relation.expression, "&")
+
+		case token == "but":
+			if i+1 >= len(tokens) || tokens[i+1] != "not" {
+				return nil, errors.New("expected `not` after `but`")
+			}
+			i++
+
+			// `but not` applies to everything before it, which may be a union or
+			// intersection.
+			if len(relation.expression) > 1 {
+				relation.expression = append(append([]string{"("}, relation.expression...), ")")
+			}
+			relation.expression = append(relation.expression, "-")
+
+		case token == "(" || token == ")":
+			relation.expression = append(relation.expression, token)
+
+		case i+2 < len(tokens) && tokens[i+1] == "from":
+			relation.expression = append(relation.expression, tokens[i+2]+"->"+token)
+			i += 2
+
+		case token == "from" || token == "not":
+			return nil, fmt.Errorf("unexpected `%s`", token)
+
+		default:
+			relation.expression = append(relation.expression, token)
+		}
+	}
+	return relation, nil
+}
+
+func tokenizeOpenFGA(definition string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(definition); {
+		switch c := definition[i]; {
+		case c == ' ' || c == '\t':
+			i++
+
+		case c == '(' || c == ')':
+			tokens = append(tokens, string(c))
+			i++
+
+		case c == '[':
+			end := strings.IndexByte(definition[i:], ']')
+			if end < 0 {
+				return nil, errors.New("unclosed direct types")
+			}
+			tokens = append(tokens, definition[i:i+end+1])
+			i += end + 1
+
+		default:
+			end := strings.IndexAny(definition[i:], " \t()[")
+			if end < 0 {
+				end = len(definition) - i
+			}
+			tokens = append(tokens, definition[i:i+end])
+			i += end
+		}
+	}
+	return tokens, nil
+}
+
+// generateOpenFGASchema generates the SpiceDB schema of the parsed model, naming the direct
+// relations of mixed relations.
+func generateOpenFGASchema(types []*fgaType, conditions []*fgaCondition, result *Result) string {
+	var sb strings.Builder
+	for _, condition := range conditions {
+		fmt.Fprintf(&sb, "caveat %s(%s) {\n\t%s\n}\n\n", condition.name, strings.Join(condition.parameters, ", "), condition.expression)
+	}
+
+	for _, t := range types {
+		for _, relation := range t.relations {
+			switch {
+			case relation.isDirectOnly():
+				relation.directName = relation.name
+
+			case relation.direct != nil:
+				relation.directName = relation.name + "_direct"
+				for suffix := 2; t.relation(relation.directName) != nil; suffix++ {
+					relation.directName = fmt.Sprintf("%s_direct%d", relation.name, suffix)
+				}
+				result.addIssue(fmt.Sprintf("type %s, relation %s", t.name, relation.name),
+					"relation mixes direct types with rewrites, so it is converted to a permission; relationships are written to the new relation `%s`", relation.directName)
+			}
+		}
+
+		fmt.Fprintf(&sb, "definition %s {\n", t.name)
+		for _, relation := range t.relations {
+			if relation.directName != "" {
+				fmt.Fprintf(&sb, "\trelation %s: %s\n", relation.directName, strings.Join(relation.direct, " | "))
+			}
+			if relation.isDirectOnly() {
+				continue
+			}
+
+			expression := make([]string, 0, len(relation.expression))
+			for _, token := range relation.expression {
+				switch {
+				case token == directToken:
+					token = relation.directName
+
+				case strings.Contains(token, "->"):
+					// Tuplesets are direct relations in OpenFGA, so arrows walk their
+					// relationships.
+					tupleset, computed, _ := strings.Cut(token, "->")
+					if r := t.relation(tupleset); r != nil && r.directName != "" {
+						token = r.directName + "->" + computed
+					}
+				}
+				expression = append(expression, token)
+			}
+			fmt.Fprintf(&sb, "\tpermission %s = %s\n", relation.name, strings.ReplaceAll(strings.ReplaceAll(strings.Join(expression, " "), "( ", "("), " )", ")"))
+		}
+		sb.WriteString("}\n\n")
+	}
+	return sb.String()
+}
+
+// convertOpenFGATuple converts the tuple into a relationship, or reports why it cannot be
+// converted and returns nil.
+func convertOpenFGATuple(t openFGATuple, types map[string]*fgaType, result *Result) *core.RelationTuple {
+	resourceType, resourceID, ok := strings.Cut(t.Object, ":")
+	if !ok {
+		result.addIssue(t.String(), "invalid object; the tuple was skipped")
+		return nil
+	}
+	subject, subjectRelation, _ := strings.Cut(t.User, "#")
+	subjectType, subjectID, ok := strings.Cut(subject, ":")
+	if !ok {
+		result.addIssue(t.String(), "invalid user; the tuple was skipped")
+		return nil
+	}
+	if subjectRelation == "" {
+		subjectRelation = tuple.Ellipsis
+	}
+
+	resourceDef, ok := types[resourceType]
+	if !ok {
+		result.addIssue(t.String(), "type `%s` is not in the model; the tuple was skipped", resourceType)
+		return nil
+	}
+	relation := resourceDef.relation(t.Relation)
+	if relation == nil {
+		result.addIssue(t.String(), "relation `%s` is not in the model; the tuple was skipped", t.Relation)
+		return nil
+	}
+	if relation.directName == "" {
+		result.addIssue(t.String(), "relation `%s` has no direct types; the tuple was skipped", t.Relation)
+		return nil
+	}
+
+	if err := tuple.ValidateResourceID(resourceID); err != nil {
+		result.addIssue(t.String(), "%s; the tuple was skipped", err)
+		return nil
+	}
+	if err := tuple.ValidateSubjectID(subjectID); err != nil {
+		result.addIssue(t.String(), "%s; the tuple was skipped", err)
+		return nil
+	}
+
+	rel := &core.RelationTuple{
+		ResourceAndRelation: tuple.ObjectAndRelation(resourceType, resourceID, relation.directName),
+		Subject:             tuple.ObjectAndRelation(subjectType, subjectID, subjectRelation),
+	}
+	if t.Condition != nil {
+		rel.Caveat = &core.ContextualizedCaveat{CaveatName: t.Condition.Name}
+		if len(t.Condition.Context) > 0 {
+			context, err := structpb.NewStruct(t.Condition.Context)
+			if err != nil {
+				result.addIssue(t.String(), "invalid condition context: %s; the tuple was skipped", err)
+				return nil
+			}
+			rel.Caveat.Context = context
+		}
+	}
+	return rel
+}
@@ -0,0 +1,113 @@
+package importer
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"golang.org/x/exp/maps"
+
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// ConvertTuples converts a dump of Zanzibar-style tuples, one `type:id#relation@type:id` per
+// line, into relationships, inferring a schema with a relation for each resource relation
+// found, allowing the subject types found for it. Empty lines and lines starting with `#`
+// are skipped.
+//
+// Permissions cannot be inferred from tuples, so the schema must be extended with them before
+// it is used.
+func ConvertTuples(ctx context.Context, r io.Reader) (*Result, error) {
+	result := &Result{}
+
+	var rels []*core.RelationTuple
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		location := fmt.Sprintf("line %d", lineNumber)
+		rel := tuple.Parse(line)
+		if rel == nil {
+			result.addIssue(location, "invalid tuple `%s` was skipped", line)
+			continue
+		}
+		if rel.Caveat != nil {
+			result.addIssue(location, "caveat `%s` cannot be inferred from tuples; the tuple was skipped", rel.Caveat.CaveatName)
+			continue
+		}
+		rels = append(rels, rel)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read tuples: %w", err)
+	}
+
+	// definitions maps each type to its relations, each with its allowed subject types.
+	definitions := map[string]map[string]map[string]struct{}{}
+	definition := func(name string) map[string]map[string]struct{} {
+		if _, ok := definitions[name]; !ok {
+			definitions[name] = map[string]map[string]struct{}{}
+		}
+		return definitions[name]
+	}
+	for _, rel := range rels {
+		definition(rel.Subject.Namespace)
+		relations := definition(rel.ResourceAndRelation.Namespace)
+		if _, ok := relations[rel.ResourceAndRelation.Relation]; !ok {
+			relations[rel.ResourceAndRelation.Relation] = map[string]struct{}{}
+		}
+	}
+
+	for _, rel := range rels {
+		subject := rel.Subject
+		if subject.Relation != tuple.Ellipsis {
+			if _, ok := definitions[subject.Namespace][subject.Relation]; !ok {
+				result.addIssue(fmt.Sprintf("tuple `%s`", tuple.MustString(rel)),
+					"subject relation `%s#%s` has no tuples, so its type cannot be inferred; the tuple was skipped", subject.Namespace, subject.Relation)
+				continue
+			}
+		}
+
+		subjectType := subject.Namespace
+		switch {
+		case subject.ObjectId == tuple.PublicWildcard:
+			subjectType += ":*"
+		case subject.Relation != tuple.Ellipsis:
+			subjectType += "#" + subject.Relation
+		}
+		definitions[rel.ResourceAndRelation.Namespace][rel.ResourceAndRelation.Relation][subjectType] = struct{}{}
+		result.Relationships = append(result.Relationships, rel)
+	}
+
+	var sb strings.Builder
+	for _, name := range sortedKeys(definitions) {
+		fmt.Fprintf(&sb, "definition %s {\n", name)
+		for _, relation := range sortedKeys(definitions[name]) {
+			subjectTypes := sortedKeys(definitions[name][relation])
+			if len(subjectTypes) == 0 {
+				continue
+			}
+			fmt.Fprintf(&sb, "\trelation %s: %s\n", relation, strings.Join(subjectTypes, " | "))
+		}
+		sb.WriteString("}\n\n")
+	}
+
+	result.addIssue("schema", "permissions cannot be inferred from tuples; the schema only contains relations")
+
+	schema, err := finishSchema(ctx, sb.String())
+	result.Schema = schema
+	return result, err
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := maps.Keys(m)
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,323 @@
+// Package integrity checks the relationships stored in a datastore against its schema and for
+// duplicate rows, and the changelog of the datastore for gaps, optionally fixing the
+// relationships found in batched transactions.
+//
+// Relationships are read at the head revision when the check starts, so those written while it
+// runs are not checked. Relationships whose resource and subject are both of definitions which
+// no longer exist cannot be found. Gaps in the changelog are reported but cannot be fixed.
+package integrity
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// Kind is the kind of a problem found by a check.
+type Kind string
+
+const (
+	// OrphanedRelationship is a relationship referring to an object definition or relation
+	// which does not exist in the schema.
+	OrphanedRelationship Kind = "orphaned-relationship"
+
+	// DuplicateRelationship is a relationship stored more than once.
+	DuplicateRelationship Kind = "duplicate-relationship"
+
+	// ChangelogGap is a change missing from the changelog of the datastore.
+	ChangelogGap Kind = "changelog-gap"
+)
+
+// Finding is a problem found by a check.
+type Finding struct {
+	Kind Kind
+
+	// Relationship is the relationship with the problem, if it concerns a relationship.
+	Relationship *core.RelationTuple
+
+	// Reason describes the problem.
+	Reason string
+}
+
+// Config configures a check.
+type Config struct {
+	// Fix, if set, deletes orphaned relationships and rewrites duplicated relationships once.
+	Fix bool
+
+	// BatchSize is the maximum number of relationships read per query, and fixed per
+	// transaction.
+	BatchSize int
+
+	// OnFinding, if set, is called with each problem as it is found.
+	OnFinding func(Finding)
+}
+
+// Result is the outcome of a check.
+type Result struct {
+	// Scanned is the number of relationships checked.
+	Scanned int
+
+	// Findings are the problems found.
+	Findings []Finding
+
+	// Fixed is the number of relationships fixed.
+	Fixed int
+}
+
+// Check checks the relationships and changelog of the datastore, fixing the relationships
+// found if configured to.
+func Check(ctx context.Context, ds datastore.Datastore, config Config) (*Result, error) {
+	if config.BatchSize <= 0 {
+		return nil, errors.New("batch size must be positive")
+	}
+
+	headRevision, err := ds.HeadRevision(ctx)
+	if err != nil {
+		return nil, err
+	}
+	reader := ds.SnapshotReader(headRevision)
+
+	namespaces, err := reader.ListAllNamespaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &checker{
+		config: config,
+		defs:   make(map[string]*core.NamespaceDefinition, len(namespaces)),
+		result: &Result{},
+	}
+	names := make([]string, 0, len(namespaces))
+	for _, ns := range namespaces {
+		c.defs[ns.Definition.Name] = ns.Definition
+		names = append(names, ns.Definition.Name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if err := c.scanResources(ctx, reader, name); err != nil {
+			return nil, fmt.Errorf("failed to check relationships of `%s`: %w", name, err)
+		}
+	}
+
+	// Relationships whose resource is of a definition which no longer exists cannot be read by
+	// their resource, so they are found by their subject instead.
+	for _, name := range names {
+		if err := c.scanSubjects(ctx, reader, name); err != nil {
+			return nil, fmt.Errorf("failed to check relationships with subjects of `%s`: %w", name, err)
+		}
+	}
+
+	if changelog := datastore.UnwrapAs[datastore.ChangelogCheckableDatastore](ds); changelog != nil {
+		gaps, err := changelog.CheckChangelog(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, gap := range gaps {
+			c.found(Finding{Kind: ChangelogGap, Reason: gap})
+		}
+	}
+
+	if config.Fix {
+		if err := c.fix(ctx, ds); err != nil {
+			return nil, err
+		}
+	}
+
+	return c.result, nil
+}
+
+type checker struct {
+	config Config
+	defs   map[string]*core.NamespaceDefinition
+	result *Result
+
+	orphaned   []*core.RelationTuple
+	duplicated []*core.RelationTuple
+}
+
+func (c *checker) found(finding Finding) {
+	c.result.Findings = append(c.result.Findings, finding)
+	if c.config.OnFinding != nil {
+		c.config.OnFinding(finding)
+	}
+}
+
+// scanResources checks the relationships whose resource is of the definition. They are read in
+// order, so that duplicates are adjacent.
+func (c *checker) scanResources(ctx context.Context, reader datastore.Reader, name string) error {
+	limit := uint64(c.config.BatchSize)
+	filter := datastore.RelationshipsFilter{ResourceType: name}
+
+	var previous *core.RelationTuple
+	var cursor options.Cursor
+	for {
+		it, err := reader.QueryRelationships(ctx, filter,
+			options.WithSort(options.ByResource),
+			options.WithAfter(cursor),
+			options.WithLimit(&limit),
+		)
+		if err != nil {
+			return err
+		}
+		rels, err := collect(it)
+		if err != nil {
+			return err
+		}
+
+		for _, rel := range rels {
+			c.result.Scanned++
+			if previous != nil && tuple.StringWithoutCaveat(previous) == tuple.StringWithoutCaveat(rel) {
+				c.found(Finding{Kind: DuplicateRelationship, Relationship: rel, Reason: "relationship is stored more than once"})
+
+				// Orphaned relationships are deleted along with their duplicates.
+				if c.orphanReason(rel) == "" && (len(c.duplicated) == 0 || c.duplicated[len(c.duplicated)-1] != previous) {
+					c.duplicated = append(c.duplicated, previous)
+				}
+				continue
+			}
+			previous = rel
+
+			if reason := c.orphanReason(rel); reason != "" {
+				c.orphaned = append(c.orphaned, rel)
+				c.found(Finding{Kind: OrphanedRelationship, Relationship: rel, Reason: reason})
+			}
+		}
+
+		if len(rels) < c.config.BatchSize {
+			return nil
+		}
+		cursor = rels[len(rels)-1]
+	}
+}
+
+// scanSubjects checks the relationships whose subject is of the definition and whose resource
+// is of a definition which does not exist; the others are checked by scanResources.
+func (c *checker) scanSubjects(ctx context.Context, reader datastore.Reader, name string) error {
+	limit := uint64(c.config.BatchSize)
+	filter := datastore.SubjectsFilter{SubjectType: name}
+
+	var cursor options.Cursor
+	for {
+		it, err := reader.ReverseQueryRelationships(ctx, filter,
+			options.WithSortForReverse(options.ByResource),
+			options.WithAfterForReverse(cursor),
+			options.WithLimitForReverse(&limit),
+		)
+		if err != nil {
+			return err
+		}
+		rels, err := collect(it)
+		if err != nil {
+			return err
+		}
+
+		for _, rel := range rels {
+			if _, ok := c.defs[rel.ResourceAndRelation.Namespace]; ok {
+				continue
+			}
+			c.result.Scanned++
+			c.orphaned = append(c.orphaned, rel)
+			c.found(Finding{Kind: OrphanedRelationship, Relationship: rel, Reason: c.orphanReason(rel)})
+		}
+
+		if len(rels) < c.config.BatchSize {
+			return nil
+		}
+		cursor = rels[len(rels)-1]
+	}
+}
+
+// orphanReason returns why the relationship refers to something which does not exist in the
+// schema, or empty if it does not.
+func (c *checker) orphanReason(rel *core.RelationTuple) string {
+	resource, subject := rel.ResourceAndRelation, rel.Subject
+
+	def, ok := c.defs[resource.Namespace]
+	if !ok {
+		return fmt.Sprintf("object definition `%s` not found", resource.Namespace)
+	}
+	relation := findRelation(def, resource.Relation)
+	switch {
+	case relation == nil:
+		return fmt.Sprintf("relation `%s` not found under definition `%s`", resource.Relation, resource.Namespace)
+	case relation.UsersetRewrite != nil:
+		return fmt.Sprintf("`%s` is a permission under definition `%s`, which cannot have relationships", resource.Relation, resource.Namespace)
+	}
+
+	subjectDef, ok := c.defs[subject.Namespace]
+	if !ok {
+		return fmt.Sprintf("subject definition `%s` not found", subject.Namespace)
+	}
+	if subject.Relation != tuple.Ellipsis && findRelation(subjectDef, subject.Relation) == nil {
+		return fmt.Sprintf("subject relation `%s` not found under definition `%s`", subject.Relation, subject.Namespace)
+	}
+	return ""
+}
+
+// fix deletes the orphaned relationships and rewrites each duplicated relationship once, in
+// batched transactions.
+func (c *checker) fix(ctx context.Context, ds datastore.Datastore) error {
+	for start := 0; start < len(c.orphaned); start += c.config.BatchSize {
+		batch := c.orphaned[start:min(start+c.config.BatchSize, len(c.orphaned))]
+
+		updates := make([]*core.RelationTupleUpdate, 0, len(batch))
+		for _, rel := range batch {
+			updates = append(updates, tuple.Delete(rel))
+		}
+		if _, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+			return rwt.WriteRelationships(ctx, updates)
+		}); err != nil {
+			return fmt.Errorf("failed to delete orphaned relationships after fixing %d: %w", c.result.Fixed, err)
+		}
+		c.result.Fixed += len(batch)
+	}
+
+	for start := 0; start < len(c.duplicated); start += c.config.BatchSize {
+		batch := c.duplicated[start:min(start+c.config.BatchSize, len(c.duplicated))]
+
+		// Deleting a relationship deletes each of its rows, after which it is written once.
+		deletes := make([]*core.RelationTupleUpdate, 0, len(batch))
+		creates := make([]*core.RelationTupleUpdate, 0, len(batch))
+		for _, rel := range batch {
+			deletes = append(deletes, tuple.Delete(rel))
+			creates = append(creates, tuple.Create(rel))
+		}
+		if _, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+			if err := rwt.WriteRelationships(ctx, deletes); err != nil {
+				return err
+			}
+			return rwt.WriteRelationships(ctx, creates)
+		}); err != nil {
+			return fmt.Errorf("failed to rewrite duplicated relationships after fixing %d: %w", c.result.Fixed, err)
+		}
+		c.result.Fixed += len(batch)
+	}
+
+	return nil
+}
+
+func collect(it datastore.RelationshipIterator) ([]*core.RelationTuple, error) {
+	defer it.Close()
+
+	var rels []*core.RelationTuple
+	for rel := it.Next(); rel != nil; rel = it.Next() {
+		rels = append(rels, rel)
+	}
+	return rels, it.Err()
+}
+
+func findRelation(def *core.NamespaceDefinition, name string) *core.Relation {
+	for _, rel := range def.Relation {
+		if rel.Name == name {
+			return rel
+		}
+	}
+	return nil
+}
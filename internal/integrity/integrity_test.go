@@ -0,0 +1,127 @@
+package integrity
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const schema = `
+definition user {}
+
+definition team {
+	relation member: user | team#member
+}
+
+definition document {
+	relation reader: user | team#member
+	permission view = reader
+}`
+
+// duplicatingDatastore returns a relationship twice when read by its resource, as a datastore
+// which stored it twice would.
+type duplicatingDatastore struct {
+	datastore.Datastore
+	duplicated string
+}
+
+func (dd duplicatingDatastore) SnapshotReader(revision datastore.Revision) datastore.Reader {
+	return duplicatingReader{dd.Datastore.SnapshotReader(revision), dd.duplicated}
+}
+
+type duplicatingReader struct {
+	datastore.Reader
+	duplicated string
+}
+
+func (dr duplicatingReader) QueryRelationships(ctx context.Context, filter datastore.RelationshipsFilter, opts ...options.QueryOptionsOption) (datastore.RelationshipIterator, error) {
+	it, err := dr.Reader.QueryRelationships(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+	rels, err := collect(it)
+	if err != nil {
+		return nil, err
+	}
+
+	var duplicated []*core.RelationTuple
+	for _, rel := range rels {
+		duplicated = append(duplicated, rel)
+		if tuple.MustString(rel) == dr.duplicated {
+			duplicated = append(duplicated, rel.CloneVT())
+		}
+	}
+	return common.NewSliceRelationshipIterator(duplicated, options.ByResource), nil
+}
+
+func TestCheck(t *testing.T) {
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+	t.Cleanup(func() { rawDS.Close() })
+
+	ds, _ := testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, schema, []*core.RelationTuple{
+		tuple.MustParse("team:eng#member@user:alice"),
+		tuple.MustParse("document:plan#reader@team:eng#member"),
+		tuple.MustParse("document:plan#reader@user:bob"),
+	}, require.New(t))
+
+	orphaned := []string{
+		"document:plan#view@user:carol",
+		"document:plan#editor@user:carol",
+		"document:plan#reader@team:eng#lead",
+		"legacy:old#reader@user:dave",
+	}
+	_, err = rawDS.ReadWriteTx(context.Background(), func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		var updates []*core.RelationTupleUpdate
+		for _, rel := range orphaned {
+			updates = append(updates, tuple.Create(tuple.MustParse(rel)))
+		}
+		return rwt.WriteRelationships(ctx, updates)
+	})
+	require.NoError(t, err)
+
+	var found []string
+	check := func(ds datastore.Datastore, fix bool) *Result {
+		found = nil
+		result, err := Check(context.Background(), ds, Config{
+			Fix:       fix,
+			BatchSize: 2,
+			OnFinding: func(finding Finding) {
+				found = append(found, string(finding.Kind)+" "+tuple.MustString(finding.Relationship))
+			},
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	duplicating := duplicatingDatastore{ds, "team:eng#member@user:alice"}
+	result := check(duplicating, false)
+	require.Equal(t, 8, result.Scanned)
+	require.Zero(t, result.Fixed)
+	require.ElementsMatch(t, []string{
+		"orphaned-relationship document:plan#view@user:carol",
+		"orphaned-relationship document:plan#editor@user:carol",
+		"orphaned-relationship document:plan#reader@team:eng#lead",
+		"orphaned-relationship legacy:old#reader@user:dave",
+		"duplicate-relationship team:eng#member@user:alice",
+	}, found)
+
+	result = check(duplicating, true)
+	require.Equal(t, 5, result.Fixed)
+
+	result = check(ds, false)
+	require.Empty(t, found)
+	require.Equal(t, 3, result.Scanned)
+
+	_, err = Check(context.Background(), ds, Config{})
+	require.Error(t, err)
+}
@@ -0,0 +1,117 @@
+package introspection
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/authzed/spicedb/pkg/cache"
+)
+
+// Cache is the state of a cache created with metrics.
+type Cache struct {
+	Name string `json:"name"`
+
+	// MaxCost is the maximum cost of the cache, in bytes, if known.
+	MaxCost int64 `json:"maxCost,omitempty"`
+
+	Entries     uint64  `json:"entries"`
+	Cost        uint64  `json:"cost"`
+	Hits        uint64  `json:"hits"`
+	Misses      uint64  `json:"misses"`
+	HitRate     float64 `json:"hitRate"`
+	KeysEvicted uint64  `json:"keysEvicted"`
+}
+
+// Caches returns the state of the caches created with metrics, ordered by name.
+func Caches() []Cache {
+	registered := cache.Registered()
+	states := make([]Cache, 0, len(registered))
+	for name, c := range registered {
+		metrics := c.GetMetrics()
+		state := Cache{
+			Name:        name,
+			Entries:     cache.Entries(metrics),
+			Hits:        metrics.Hits(),
+			Misses:      metrics.Misses(),
+			KeysEvicted: metrics.KeysEvicted(),
+		}
+		if added, evicted := metrics.CostAdded(), metrics.CostEvicted(); added > evicted {
+			state.Cost = added - evicted
+		}
+		if lookups := state.Hits + state.Misses; lookups > 0 {
+			state.HitRate = float64(state.Hits) / float64(lookups)
+		}
+		if resizable, ok := c.(cache.Resizable); ok {
+			state.MaxCost = resizable.MaxCost()
+		}
+		states = append(states, state)
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Name < states[j].Name })
+	return states
+}
+
+// RegisterHandlers adds the introspection endpoints to the mux:
+//
+//   - GET /debug/dispatch reports the members of the dispatch hashrings and their health.
+//   - GET /debug/caches reports the size and hit rate of each cache.
+//   - POST /debug/caches/flush?cache=<name> removes all entries from the named cache or,
+//     given `key` parameters, only the entries for those keys. Keys can only be given for
+//     caches keyed by strings, such as the namespace cache, whose keys are
+//     `n:<definition>@<revision>` and `c:<caveat>@<revision>`; entries of the dispatch
+//     caches are keyed by a hash of the request, so those caches can only be flushed as a
+//     whole.
+func RegisterHandlers(mux *http.ServeMux) {
+	mux.HandleFunc("/debug/dispatch", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"rings": Rings()})
+	})
+	mux.HandleFunc("/debug/caches", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"caches": Caches()})
+	})
+	mux.HandleFunc("/debug/caches/flush", flushHandler)
+}
+
+func flushHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "caches can only be flushed with POST", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := r.URL.Query().Get("cache")
+	c, ok := cache.Registered()[name]
+	if !ok {
+		http.Error(w, fmt.Sprintf("unknown cache `%s`", name), http.StatusNotFound)
+		return
+	}
+
+	flushable, ok := c.(cache.Flushable)
+	if !ok {
+		http.Error(w, fmt.Sprintf("cache `%s` cannot be flushed", name), http.StatusBadRequest)
+		return
+	}
+
+	keys := r.URL.Query()["key"]
+	if len(keys) == 0 {
+		flushable.Clear()
+		fmt.Fprintf(w, "flushed cache `%s`\n", name)
+		return
+	}
+
+	for _, key := range keys {
+		flushable.Del(key)
+	}
+	fmt.Fprintf(w, "flushed %d keys from cache `%s`\n", len(keys), name)
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	encoded, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	fmt.Fprintf(w, "%s\n", encoded)
+}
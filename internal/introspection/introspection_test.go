@@ -0,0 +1,158 @@
+package introspection
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/authzed/consistent"
+	"github.com/cespare/xxhash/v2"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/resolver"
+	"google.golang.org/grpc/resolver/manual"
+
+	"github.com/authzed/spicedb/pkg/cache"
+)
+
+func init() {
+	balancer.Register(NewBalancerBuilder(consistent.NewBuilder(xxhash.Sum64)))
+}
+
+func ringFor(target string) (Ring, bool) {
+	for _, ring := range Rings() {
+		if ring.Target == target {
+			return ring, true
+		}
+	}
+	return Ring{}, false
+}
+
+func TestRings(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	// A closed listener leaves an address to which connections are refused.
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	require.NoError(t, closed.Close())
+
+	r := manual.NewBuilderWithScheme("introspection")
+	r.InitialState(resolver.State{Addresses: []resolver.Address{
+		{Addr: listener.Addr().String()},
+		{Addr: closed.Addr().String()},
+	}})
+
+	conn, err := grpc.Dial("introspection:///upstream",
+		grpc.WithResolvers(r),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithDefaultServiceConfig((&consistent.BalancerConfig{ReplicationFactor: 100, Spread: 1}).MustServiceConfigJSON()),
+	)
+	require.NoError(t, err)
+	conn.Connect()
+
+	require.Eventually(t, func() bool {
+		ring, ok := ringFor("introspection:///upstream")
+		return ok && len(ring.Members) == 2 &&
+			ring.Members[0].State != ring.Members[1].State &&
+			(ring.Members[0].State == connectivity.TransientFailure.String() || ring.Members[1].State == connectivity.TransientFailure.String())
+	}, 5*time.Second, 10*time.Millisecond)
+
+	ring, _ := ringFor("introspection:///upstream")
+	for _, member := range ring.Members {
+		if member.Address == listener.Addr().String() {
+			require.Equal(t, connectivity.Ready.String(), member.State)
+			require.Empty(t, member.Error)
+		} else {
+			require.Equal(t, connectivity.TransientFailure.String(), member.State)
+			require.NotEmpty(t, member.Error)
+		}
+	}
+
+	// Members removed by the resolver leave the ring.
+	r.UpdateState(resolver.State{Addresses: []resolver.Address{{Addr: listener.Addr().String()}}})
+	require.Eventually(t, func() bool {
+		ring, _ := ringFor("introspection:///upstream")
+		return len(ring.Members) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// The ring is no longer reported once its connection is closed.
+	require.NoError(t, conn.Close())
+	require.Eventually(t, func() bool {
+		_, ok := ringFor("introspection:///upstream")
+		return !ok
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestCacheHandlers(t *testing.T) {
+	c, err := cache.NewCacheWithMetrics("introspection_test", &cache.Config{NumCounters: 1000, MaxCost: 1 << 20})
+	require.NoError(t, err)
+	t.Cleanup(c.Close)
+
+	c.Set("n:document@1", "document", 10)
+	c.Set("n:user@1", "user", 10)
+	c.Wait()
+	_, found := c.Get("n:document@1")
+	require.True(t, found)
+
+	mux := http.NewServeMux()
+	RegisterHandlers(mux)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	resp, err := http.Get(server.URL + "/debug/caches")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	var caches struct{ Caches []Cache }
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&caches))
+	require.Len(t, caches.Caches, 1)
+	state := caches.Caches[0]
+	require.Equal(t, "introspection_test", state.Name)
+	require.Equal(t, int64(1<<20), state.MaxCost)
+	require.Equal(t, uint64(2), state.Entries)
+	require.Equal(t, uint64(1), state.Hits)
+	require.Equal(t, 1.0, state.HitRate)
+
+	// The cost includes the internal overhead of each entry.
+	require.GreaterOrEqual(t, state.Cost, uint64(20))
+
+	resp, err = http.Get(server.URL + "/debug/caches/flush?cache=introspection_test")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+
+	resp, err = http.Post(server.URL+"/debug/caches/flush?cache=unknown", "", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+
+	resp, err = http.Post(server.URL+"/debug/caches/flush?cache=introspection_test&key=n:document@1", "", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	_, found = c.Get("n:document@1")
+	require.False(t, found)
+	_, found = c.Get("n:user@1")
+	require.True(t, found)
+
+	resp, err = http.Post(server.URL+"/debug/caches/flush?cache=introspection_test", "", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	_, found = c.Get("n:user@1")
+	require.False(t, found)
+
+	resp, err = http.Get(server.URL + "/debug/dispatch")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, "application/json", resp.Header.Get("Content-Type"))
+}
@@ -0,0 +1,176 @@
+// Package introspection reports the membership and health of the dispatch hashring and the
+// state of the caches, and lets operators flush caches, through endpoints of the metrics
+// server.
+package introspection
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/authzed/consistent"
+	"google.golang.org/grpc/balancer"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/resolver"
+)
+
+// Ring is the state of the hashring of a connection to a dispatch upstream.
+type Ring struct {
+	// Target is the address to which the connection was dialed.
+	Target string `json:"target"`
+
+	// Members are the peers in the hashring, ordered by address.
+	Members []Member `json:"members"`
+}
+
+// Member is a peer in a hashring.
+type Member struct {
+	Address string `json:"address"`
+
+	// State is the connectivity of the connection to the peer, such as READY or
+	// TRANSIENT_FAILURE.
+	State string `json:"state"`
+
+	// Error is the last error connecting to the peer, if it is in TRANSIENT_FAILURE.
+	Error string `json:"error,omitempty"`
+}
+
+var (
+	ringsLock sync.Mutex
+	rings     = map[*trackedRing]struct{}{}
+)
+
+// Rings returns the state of the hashrings of the open connections to dispatch upstreams,
+// ordered by target.
+func Rings() []Ring {
+	ringsLock.Lock()
+	tracked := make([]*trackedRing, 0, len(rings))
+	for ring := range rings {
+		tracked = append(tracked, ring)
+	}
+	ringsLock.Unlock()
+
+	states := make([]Ring, 0, len(tracked))
+	for _, ring := range tracked {
+		states = append(states, ring.state())
+	}
+	sort.Slice(states, func(i, j int) bool { return states[i].Target < states[j].Target })
+	return states
+}
+
+// NewBalancerBuilder returns a builder of the balancers built by builder, which records the
+// members of each hashring and the connectivity to them, as reported by Rings.
+func NewBalancerBuilder(builder consistent.Builder) consistent.Builder {
+	return &trackingBuilder{builder}
+}
+
+type trackingBuilder struct {
+	consistent.Builder
+}
+
+func (b *trackingBuilder) Build(cc balancer.ClientConn, opts balancer.BuildOptions) balancer.Balancer {
+	ring := &trackedRing{target: opts.Target.String(), members: map[balancer.SubConn]*Member{}}
+
+	ringsLock.Lock()
+	rings[ring] = struct{}{}
+	ringsLock.Unlock()
+
+	return &trackingBalancer{b.Builder.Build(&trackingClientConn{cc, ring}, opts), ring}
+}
+
+// trackedRing is the membership of a hashring, keyed by the SubConn of each member.
+type trackedRing struct {
+	sync.Mutex
+	target  string
+	members map[balancer.SubConn]*Member
+}
+
+func (r *trackedRing) state() Ring {
+	r.Lock()
+	defer r.Unlock()
+
+	members := make([]Member, 0, len(r.members))
+	for _, member := range r.members {
+		members = append(members, *member)
+	}
+	sort.Slice(members, func(i, j int) bool { return members[i].Address < members[j].Address })
+	return Ring{Target: r.target, Members: members}
+}
+
+func (r *trackedRing) add(sc balancer.SubConn, addrs []resolver.Address) {
+	var address string
+	if len(addrs) > 0 {
+		address = addrs[0].ServerName + addrs[0].Addr
+	}
+
+	r.Lock()
+	defer r.Unlock()
+	r.members[sc] = &Member{Address: address, State: connectivity.Idle.String()}
+}
+
+func (r *trackedRing) remove(sc balancer.SubConn) {
+	r.Lock()
+	defer r.Unlock()
+	delete(r.members, sc)
+}
+
+func (r *trackedRing) update(sc balancer.SubConn, state balancer.SubConnState) {
+	r.Lock()
+	defer r.Unlock()
+
+	member, ok := r.members[sc]
+	if !ok {
+		return
+	}
+
+	if state.ConnectivityState == connectivity.Shutdown {
+		delete(r.members, sc)
+		return
+	}
+
+	member.State = state.ConnectivityState.String()
+	member.Error = ""
+	if state.ConnectivityState == connectivity.TransientFailure && state.ConnectionError != nil {
+		member.Error = state.ConnectionError.Error()
+	}
+}
+
+// trackingClientConn records the SubConns created and removed by the balancer, which are
+// the members of its hashring.
+type trackingClientConn struct {
+	balancer.ClientConn
+	ring *trackedRing
+}
+
+func (cc *trackingClientConn) NewSubConn(addrs []resolver.Address, opts balancer.NewSubConnOptions) (balancer.SubConn, error) {
+	sc, err := cc.ClientConn.NewSubConn(addrs, opts)
+	if err != nil {
+		return nil, err
+	}
+	cc.ring.add(sc, addrs)
+	return sc, nil
+}
+
+func (cc *trackingClientConn) RemoveSubConn(sc balancer.SubConn) {
+	cc.ring.remove(sc)
+	cc.ClientConn.RemoveSubConn(sc) // nolint: staticcheck
+}
+
+// trackingBalancer records the connectivity of the members of the hashring, and stops
+// reporting the hashring once closed.
+type trackingBalancer struct {
+	balancer.Balancer
+	ring *trackedRing
+}
+
+func (b *trackingBalancer) UpdateSubConnState(sc balancer.SubConn, state balancer.SubConnState) {
+	b.ring.update(sc, state)
+	b.Balancer.UpdateSubConnState(sc, state)
+}
+
+func (b *trackingBalancer) Close() {
+	ringsLock.Lock()
+	delete(rings, b.ring)
+	ringsLock.Unlock()
+
+	b.Balancer.Close()
+}
@@ -0,0 +1,332 @@
+// Package leaderelection elects one of the nodes sharing a datastore to run the background
+// jobs which must not run concurrently across a cluster, such as garbage collection, backups
+// and the changefeed.
+package leaderelection
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const (
+	// LeaseNamespace is the reserved resource type of the relationships holding the leases,
+	// which are not part of the schema and are not published by the changefeed.
+	LeaseNamespace = "spicedb_leaderelection/lease"
+
+	leaseRelation = "holder"
+
+	// holderNamespace is the reserved subject type of the lease relationships, whose IDs are
+	// the hex-encoded name of the node holding the lease and the time, in Unix milliseconds,
+	// at which it expires.
+	holderNamespace = "spicedb_leaderelection/holder"
+
+	// releaseTimeout is the maximum time spent releasing the lease when shutting down.
+	releaseTimeout = 5 * time.Second
+)
+
+var (
+	isLeaderGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "spicedb",
+		Subsystem: "leaderelection",
+		Name:      "is_leader",
+		Help:      "Whether the node holds the lease, and so runs the cluster-singleton background jobs",
+	}, []string{"lease", "node"})
+
+	leadershipChangesCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "spicedb",
+		Subsystem: "leaderelection",
+		Name:      "leadership_changes_total",
+		Help:      "Count of the times the node acquired or lost the lease",
+	}, []string{"lease", "node"})
+)
+
+// Elector holds a lease in the datastore while it can, renewing it a few times per lease
+// duration, and runs jobs only while it holds it. When the node holding the lease stops, or
+// can no longer reach the datastore, its lease expires and another node acquires it.
+type Elector struct {
+	lease         string
+	node          string
+	leaseDuration time.Duration
+	now           func() time.Time
+
+	mu      sync.Mutex
+	leading bool
+	expiry  time.Time
+	changed chan struct{}
+}
+
+// NewElector creates an elector for the lease of the given name, held by the node of the
+// given name, which must be unique across the cluster.
+func NewElector(lease, node string, leaseDuration time.Duration) (*Elector, error) {
+	if lease == "" {
+		return nil, errors.New("lease name must not be empty")
+	}
+	if node == "" {
+		return nil, errors.New("node name must not be empty")
+	}
+	if leaseDuration <= 0 {
+		return nil, errors.New("lease duration must be positive")
+	}
+
+	isLeaderGauge.WithLabelValues(lease, node).Set(0)
+	return &Elector{
+		lease:         lease,
+		node:          node,
+		leaseDuration: leaseDuration,
+		now:           time.Now,
+		changed:       make(chan struct{}),
+	}, nil
+}
+
+// IsLeader returns whether the node currently holds the lease.
+func (e *Elector) IsLeader() bool {
+	leading, _ := e.state()
+	return leading
+}
+
+func (e *Elector) state() (bool, <-chan struct{}) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.leading, e.changed
+}
+
+func (e *Elector) setLeading(ctx context.Context, leading bool, expiry time.Time) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.expiry = expiry
+	if e.leading == leading {
+		return
+	}
+
+	e.leading = leading
+	close(e.changed)
+	e.changed = make(chan struct{})
+
+	leadershipChangesCounter.WithLabelValues(e.lease, e.node).Inc()
+	if leading {
+		isLeaderGauge.WithLabelValues(e.lease, e.node).Set(1)
+		log.Ctx(ctx).Info().Str("lease", e.lease).Str("node", e.node).Msg("acquired leadership")
+	} else {
+		isLeaderGauge.WithLabelValues(e.lease, e.node).Set(0)
+		log.Ctx(ctx).Info().Str("lease", e.lease).Str("node", e.node).Msg("lost leadership")
+	}
+}
+
+// renewInterval is the interval at which the lease is acquired or renewed, leaving time for
+// a couple of failed renewals before it expires.
+func (e *Elector) renewInterval() time.Duration {
+	return e.leaseDuration / 3
+}
+
+// Run acquires and renews the lease until the context is canceled, at which point the lease
+// is released so that another node can acquire it without waiting for it to expire.
+func (e *Elector) Run(ctx context.Context, ds datastore.Datastore) error {
+	ticker := time.NewTicker(e.renewInterval())
+	defer ticker.Stop()
+
+	for {
+		acquired, expiry, err := e.tryAcquire(ctx, ds)
+		if err == nil {
+			e.setLeading(ctx, acquired, expiry)
+		} else if ctx.Err() == nil {
+			log.Ctx(ctx).Warn().Err(err).Str("lease", e.lease).Msg("failed to renew lease")
+
+			// Step down before the lease expires, as another node may then acquire it.
+			if e.IsLeader() && !e.now().Add(e.renewInterval()).Before(e.currentExpiry()) {
+				e.setLeading(ctx, false, time.Time{})
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			e.release(ds)
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (e *Elector) currentExpiry() time.Time {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.expiry
+}
+
+// RunWhileLeader runs the job whenever the node holds the lease, canceling its context when
+// the lease is lost, until the given context is canceled. An error returned by the job while
+// the node holds the lease is returned.
+func (e *Elector) RunWhileLeader(ctx context.Context, name string, job func(context.Context) error) error {
+	for {
+		leading, changed := e.state()
+		if !leading {
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-changed:
+				continue
+			}
+		}
+
+		log.Ctx(ctx).Info().Str("job", name).Msg("starting job as leader")
+		jobCtx, cancel := context.WithCancel(ctx)
+		done := make(chan error, 1)
+		go func() {
+			done <- job(jobCtx)
+		}()
+
+		select {
+		case err := <-done:
+			cancel()
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+
+		case <-changed:
+			log.Ctx(ctx).Info().Str("job", name).Msg("stopping job after losing leadership")
+			cancel()
+			if err := <-done; err != nil && !errors.Is(err, context.Canceled) {
+				log.Ctx(ctx).Warn().Err(err).Str("job", name).Msg("job failed when stopped")
+			}
+			if ctx.Err() != nil {
+				return nil
+			}
+		}
+	}
+}
+
+// tryAcquire acquires or renews the lease, unless it is held by another node and has not
+// expired, returning whether it is held and until when.
+func (e *Elector) tryAcquire(ctx context.Context, ds datastore.Datastore) (bool, time.Time, error) {
+	var (
+		acquired bool
+		expiry   time.Time
+	)
+	_, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		acquired = false
+		now := e.now()
+
+		holder, holderExpiry, err := readLease(ctx, rwt, e.lease)
+		if err != nil {
+			return err
+		}
+		if holder != "" && holder != e.node && now.Before(holderExpiry) {
+			return nil
+		}
+
+		expiry = now.Add(e.leaseDuration)
+		if err := writeLease(ctx, rwt, e.lease, e.node, expiry); err != nil {
+			return err
+		}
+		acquired = true
+		return nil
+	})
+	if err != nil {
+		return false, time.Time{}, fmt.Errorf("error acquiring lease: %w", err)
+	}
+	return acquired, expiry, nil
+}
+
+// release deletes the lease if it is held by the node.
+func (e *Elector) release(ds datastore.Datastore) {
+	leading, _ := e.state()
+	if !leading {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancel()
+
+	_, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		holder, _, err := readLease(ctx, rwt, e.lease)
+		if err != nil || holder != e.node {
+			return err
+		}
+		_, err = rwt.DeleteRelationships(ctx, &v1.RelationshipFilter{
+			ResourceType:       LeaseNamespace,
+			OptionalResourceId: e.lease,
+			OptionalRelation:   leaseRelation,
+		})
+		return err
+	})
+	if err != nil {
+		log.Warn().Err(err).Str("lease", e.lease).Msg("failed to release lease")
+	}
+	e.setLeading(ctx, false, time.Time{})
+}
+
+// readLease returns the node holding the lease of the given name and when it expires, or an
+// empty node if it is not held.
+func readLease(ctx context.Context, reader datastore.Reader, lease string) (string, time.Time, error) {
+	it, err := reader.QueryRelationships(ctx, datastore.RelationshipsFilter{
+		ResourceType:             LeaseNamespace,
+		OptionalResourceIds:      []string{lease},
+		OptionalResourceRelation: leaseRelation,
+	})
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error reading lease: %w", err)
+	}
+	defer it.Close()
+
+	rel := it.Next()
+	if it.Err() != nil {
+		return "", time.Time{}, fmt.Errorf("error reading lease: %w", it.Err())
+	}
+	if rel == nil {
+		return "", time.Time{}, nil
+	}
+
+	encodedNode, encodedExpiry, ok := strings.Cut(rel.Subject.ObjectId, "-")
+	if !ok {
+		return "", time.Time{}, fmt.Errorf("invalid lease `%s`", rel.Subject.ObjectId)
+	}
+	node, err := hex.DecodeString(encodedNode)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("invalid lease `%s`: %w", rel.Subject.ObjectId, err)
+	}
+	expiryMillis, err := strconv.ParseInt(encodedExpiry, 10, 64)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("invalid lease `%s`: %w", rel.Subject.ObjectId, err)
+	}
+	return string(node), time.UnixMilli(expiryMillis), nil
+}
+
+// writeLease replaces the holder of the lease of the given name.
+func writeLease(ctx context.Context, rwt datastore.ReadWriteTransaction, lease, node string, expiry time.Time) error {
+	if _, err := rwt.DeleteRelationships(ctx, &v1.RelationshipFilter{
+		ResourceType:       LeaseNamespace,
+		OptionalResourceId: lease,
+		OptionalRelation:   leaseRelation,
+	}); err != nil {
+		return err
+	}
+
+	return rwt.WriteRelationships(ctx, []*core.RelationTupleUpdate{tuple.Create(&core.RelationTuple{
+		ResourceAndRelation: &core.ObjectAndRelation{
+			Namespace: LeaseNamespace,
+			ObjectId:  lease,
+			Relation:  leaseRelation,
+		},
+		Subject: &core.ObjectAndRelation{
+			Namespace: holderNamespace,
+			ObjectId:  hex.EncodeToString([]byte(node)) + "-" + strconv.FormatInt(expiry.UnixMilli(), 10),
+			Relation:  tuple.Ellipsis,
+		},
+	})})
+}
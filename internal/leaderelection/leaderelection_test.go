@@ -0,0 +1,161 @@
+package leaderelection
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+const testLease = "background-jobs"
+
+func newTestDatastore(t *testing.T) datastore.Datastore {
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+	t.Cleanup(func() { ds.Close() })
+	return ds
+}
+
+// newTestElector creates an elector whose clock is set by the returned function.
+func newTestElector(t *testing.T, node string) (*Elector, func(time.Time)) {
+	elector, err := NewElector(testLease, node, time.Minute)
+	require.NoError(t, err)
+
+	now := time.Now()
+	elector.now = func() time.Time { return now }
+	return elector, func(t time.Time) { now = t }
+}
+
+func TestNewElector(t *testing.T) {
+	_, err := NewElector("", "node", time.Minute)
+	require.ErrorContains(t, err, "lease name")
+
+	_, err = NewElector(testLease, "", time.Minute)
+	require.ErrorContains(t, err, "node name")
+
+	_, err = NewElector(testLease, "node", 0)
+	require.ErrorContains(t, err, "lease duration")
+}
+
+func TestTryAcquire(t *testing.T) {
+	ctx := context.Background()
+	ds := newTestDatastore(t)
+
+	first, setFirstNow := newTestElector(t, "first.example.com")
+	second, setSecondNow := newTestElector(t, "second.example.com")
+
+	acquired, expiry, err := first.tryAcquire(ctx, ds)
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	acquired, _, err = second.tryAcquire(ctx, ds)
+	require.NoError(t, err)
+	require.False(t, acquired)
+
+	// The holder renews its lease.
+	renewedAt := time.Now().Add(30 * time.Second)
+	setFirstNow(renewedAt)
+	acquired, renewedExpiry, err := first.tryAcquire(ctx, ds)
+	require.NoError(t, err)
+	require.True(t, acquired)
+	require.True(t, renewedExpiry.After(expiry))
+
+	headRevision, err := ds.HeadRevision(ctx)
+	require.NoError(t, err)
+	holder, holderExpiry, err := readLease(ctx, ds.SnapshotReader(headRevision), testLease)
+	require.NoError(t, err)
+	require.Equal(t, "first.example.com", holder)
+	require.Equal(t, renewedExpiry.UnixMilli(), holderExpiry.UnixMilli())
+
+	// Once the lease expires, another node acquires it.
+	setSecondNow(renewedExpiry.Add(time.Second))
+	acquired, _, err = second.tryAcquire(ctx, ds)
+	require.NoError(t, err)
+	require.True(t, acquired)
+
+	acquired, _, err = first.tryAcquire(ctx, ds)
+	require.NoError(t, err)
+	require.False(t, acquired)
+}
+
+func TestRunReleasesLease(t *testing.T) {
+	ds := newTestDatastore(t)
+	first, _ := newTestElector(t, "first")
+	second, _ := newTestElector(t, "second")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- first.Run(ctx, ds) }()
+
+	require.Eventually(t, first.IsLeader, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, 1.0, testutil.ToFloat64(isLeaderGauge.WithLabelValues(testLease, "first")))
+
+	acquired, _, err := second.tryAcquire(context.Background(), ds)
+	require.NoError(t, err)
+	require.False(t, acquired)
+
+	cancel()
+	require.NoError(t, <-done)
+	require.False(t, first.IsLeader())
+	require.Equal(t, 0.0, testutil.ToFloat64(isLeaderGauge.WithLabelValues(testLease, "first")))
+
+	// The lease was released, so the other node acquires it without waiting for it to expire.
+	acquired, _, err = second.tryAcquire(context.Background(), ds)
+	require.NoError(t, err)
+	require.True(t, acquired)
+}
+
+func TestRunWhileLeader(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	elector, _ := newTestElector(t, "node")
+	started := make(chan struct{}, 2)
+	stopped := make(chan struct{}, 2)
+	done := make(chan error, 1)
+	go func() {
+		done <- elector.RunWhileLeader(ctx, "test", func(ctx context.Context) error {
+			started <- struct{}{}
+			<-ctx.Done()
+			stopped <- struct{}{}
+			return ctx.Err()
+		})
+	}()
+
+	// The job only starts once the node is the leader.
+	select {
+	case <-started:
+		require.Fail(t, "job started before leadership was acquired")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	elector.setLeading(ctx, true, time.Now().Add(time.Minute))
+	<-started
+
+	// It is stopped when leadership is lost, and restarted when reacquired.
+	elector.setLeading(ctx, false, time.Time{})
+	<-stopped
+
+	elector.setLeading(ctx, true, time.Now().Add(time.Minute))
+	<-started
+
+	cancel()
+	<-stopped
+	require.NoError(t, <-done)
+}
+
+func TestRunWhileLeaderJobError(t *testing.T) {
+	elector, _ := newTestElector(t, "node")
+	elector.setLeading(context.Background(), true, time.Now().Add(time.Minute))
+
+	err := elector.RunWhileLeader(context.Background(), "test", func(ctx context.Context) error {
+		return errors.New("job failed")
+	})
+	require.ErrorContains(t, err, "job failed")
+}
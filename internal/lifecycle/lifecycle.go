@@ -0,0 +1,208 @@
+// Package lifecycle starts and stops the subsystems of a server, such as its listeners,
+// dispatchers, caches and background jobs, each registered as a hook. Subsystems are stopped
+// one at a time in the reverse order of their registration, so that each stops before those
+// registered earlier, upon which it may depend, with the progress of the shutdown logged and
+// each subsystem given a bounded time to stop.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-multierror"
+
+	log "github.com/authzed/spicedb/internal/logging"
+)
+
+// DefaultStopTimeout is the time given to each subsystem to stop by default.
+const DefaultStopTimeout = 30 * time.Second
+
+// Hook starts and stops a subsystem.
+type Hook struct {
+	// Name names the subsystem in logs.
+	Name string
+
+	// Start, if given, runs the subsystem until its context is canceled or it is stopped. An
+	// error returned before the server shuts down shuts it down.
+	Start func(ctx context.Context) error
+
+	// Stop, if given, stops the subsystem and releases its resources. It is called once the
+	// context of Start is canceled, after which Start must return.
+	Stop func(ctx context.Context) error
+
+	// StopTimeout bounds the time given to the subsystem to stop, after which the shutdown
+	// moves on to the next subsystem. Zero uses the timeout of the manager, and a negative
+	// timeout waits for the subsystem to stop without bound.
+	StopTimeout time.Duration
+}
+
+type hook struct {
+	Hook
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Manager runs the hooks registered with it.
+type Manager struct {
+	stopTimeout time.Duration
+
+	mu      sync.Mutex
+	hooks   []*hook
+	started bool
+	stopped bool
+}
+
+// NewManager creates a manager giving each subsystem the given time to stop, unless its hook
+// has its own timeout. A timeout of zero waits for subsystems to stop without bound.
+func NewManager(stopTimeout time.Duration) *Manager {
+	return &Manager{stopTimeout: stopTimeout}
+}
+
+// Add registers the hook, whose subsystem is stopped before those of the hooks registered
+// before it.
+func (m *Manager) Add(h Hook) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.hooks = append(m.hooks, &hook{Hook: h})
+}
+
+// AddWithError registers a hook which only stops a subsystem, by calling stop.
+func (m *Manager) AddWithError(name string, stop func() error) {
+	m.Add(Hook{Name: name, Stop: func(context.Context) error { return stop() }})
+}
+
+// AddWithoutError registers a hook which only stops a subsystem, by calling stop.
+func (m *Manager) AddWithoutError(name string, stop func()) {
+	m.Add(Hook{Name: name, Stop: func(context.Context) error {
+		stop()
+		return nil
+	}})
+}
+
+// Run starts the subsystems of the hooks, in the order in which they were registered, then
+// stops them all once the context is canceled or a subsystem fails. It returns the error of
+// any subsystem which failed, or could not be stopped.
+func (m *Manager) Run(ctx context.Context) error {
+	m.mu.Lock()
+	if m.started || m.stopped {
+		m.mu.Unlock()
+		return errors.New("lifecycle manager has already run")
+	}
+	m.started = true
+	hooks := m.hooks
+	m.mu.Unlock()
+
+	// Subsystems are stopped by canceling their own context in turn, rather than all at once
+	// with that of the server.
+	hookCtx := context.WithoutCancel(ctx)
+	failed := make(chan error, len(hooks))
+	for _, h := range hooks {
+		if h.Start == nil {
+			continue
+		}
+
+		var startCtx context.Context
+		startCtx, h.cancel = context.WithCancel(hookCtx)
+		h.done = make(chan struct{})
+		go func(h *hook) {
+			defer close(h.done)
+			if err := h.Start(startCtx); err != nil && !errors.Is(err, context.Canceled) {
+				log.Ctx(ctx).Error().Err(err).Str("subsystem", h.Name).Msg("subsystem failed")
+				failed <- err
+			}
+		}(h)
+	}
+	log.Ctx(ctx).Info().Int("subsystems", len(hooks)).Msg("started subsystems")
+
+	var err error
+	select {
+	case <-ctx.Done():
+		log.Ctx(ctx).Info().Msg("shutting down")
+	case err = <-failed:
+		log.Ctx(ctx).Warn().Msg("shutting down after a subsystem failed")
+	}
+
+	if stopErr := m.Stop(hookCtx); stopErr != nil {
+		err = multierror.Append(err, stopErr)
+	}
+	for {
+		select {
+		case failedErr := <-failed:
+			err = multierror.Append(err, failedErr)
+		default:
+			return err
+		}
+	}
+}
+
+// Stop stops the subsystems of the hooks, in the reverse order in which they were registered,
+// whether or not they were started. It returns the errors of the subsystems which could not be
+// stopped, and does nothing if called again.
+func (m *Manager) Stop(ctx context.Context) error {
+	m.mu.Lock()
+	if m.stopped {
+		m.mu.Unlock()
+		return nil
+	}
+	m.stopped = true
+	hooks := m.hooks
+	m.mu.Unlock()
+
+	var err error
+	for i := len(hooks) - 1; i >= 0; i-- {
+		h := hooks[i]
+		logger := log.Ctx(ctx).With().Str("subsystem", h.Name).Int("remaining", i).Logger()
+		logger.Info().Msg("stopping subsystem")
+
+		start := time.Now()
+		if stopErr := m.stop(ctx, h); stopErr != nil {
+			logger.Warn().Err(stopErr).Dur("duration", time.Since(start)).Msg("failed to stop subsystem")
+			err = multierror.Append(err, fmt.Errorf("failed to stop %s: %w", h.Name, stopErr))
+			continue
+		}
+		logger.Info().Dur("duration", time.Since(start)).Msg("stopped subsystem")
+	}
+	log.Ctx(ctx).Info().Msg("stopped all subsystems")
+	return err
+}
+
+func (m *Manager) stop(ctx context.Context, h *hook) error {
+	timeout := h.StopTimeout
+	if timeout == 0 {
+		timeout = m.stopTimeout
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	timedOut := fmt.Errorf("timed out after %s", timeout)
+
+	if h.cancel != nil {
+		h.cancel()
+	}
+
+	var err error
+	if h.Stop != nil {
+		stopped := make(chan error, 1)
+		go func() { stopped <- h.Stop(ctx) }()
+		select {
+		case err = <-stopped:
+		case <-ctx.Done():
+			return timedOut
+		}
+	}
+
+	if h.done != nil {
+		select {
+		case <-h.done:
+		case <-ctx.Done():
+			return timedOut
+		}
+	}
+	return err
+}
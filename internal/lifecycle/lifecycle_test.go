@@ -0,0 +1,140 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type recorder struct {
+	sync.Mutex
+	events []string
+}
+
+func (r *recorder) record(event string) {
+	r.Lock()
+	defer r.Unlock()
+	r.events = append(r.events, event)
+}
+
+func (r *recorder) recorded() []string {
+	r.Lock()
+	defer r.Unlock()
+	return append([]string(nil), r.events...)
+}
+
+// job returns a hook running until it is canceled.
+func job(r *recorder, name string, started *sync.WaitGroup) Hook {
+	started.Add(1)
+	return Hook{
+		Name: name,
+		Start: func(ctx context.Context) error {
+			started.Done()
+			<-ctx.Done()
+			r.record("canceled " + name)
+			return ctx.Err()
+		},
+	}
+}
+
+// server returns a hook running until it is stopped.
+func server(r *recorder, name string, started *sync.WaitGroup) Hook {
+	started.Add(1)
+	stopped := make(chan struct{})
+	return Hook{
+		Name: name,
+		Start: func(context.Context) error {
+			started.Done()
+			<-stopped
+			r.record("returned " + name)
+			return nil
+		},
+		Stop: func(context.Context) error {
+			r.record("stopping " + name)
+			close(stopped)
+			return nil
+		},
+	}
+}
+
+func TestRunStopsInReverseOrder(t *testing.T) {
+	r := &recorder{}
+	var started sync.WaitGroup
+
+	m := NewManager(time.Second)
+	m.AddWithoutError("datastore", func() { r.record("closed datastore") })
+	m.Add(server(r, "api", &started))
+	m.Add(job(r, "gc", &started))
+	m.AddWithError("cache", func() error {
+		r.record("closed cache")
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- m.Run(ctx) }()
+
+	started.Wait()
+	cancel()
+	require.NoError(t, <-done)
+	require.Equal(t, []string{
+		"closed cache",
+		"canceled gc",
+		"stopping api",
+		"returned api",
+		"closed datastore",
+	}, r.recorded())
+
+	require.Error(t, m.Run(context.Background()))
+}
+
+func TestRunShutsDownOnFailure(t *testing.T) {
+	r := &recorder{}
+	var started sync.WaitGroup
+
+	m := NewManager(time.Second)
+	m.Add(job(r, "gc", &started))
+	m.Add(Hook{Name: "failing", Start: func(context.Context) error {
+		started.Wait()
+		return errors.New("failed")
+	}})
+
+	require.EqualError(t, m.Run(context.Background()), "failed")
+	require.Equal(t, []string{"canceled gc"}, r.recorded())
+}
+
+func TestStopTimeout(t *testing.T) {
+	r := &recorder{}
+	blocked := make(chan struct{})
+	defer close(blocked)
+
+	m := NewManager(10 * time.Millisecond)
+	m.AddWithoutError("datastore", func() { r.record("closed datastore") })
+	m.AddWithoutError("stuck", func() { <-blocked })
+	m.Add(Hook{Name: "unbounded", StopTimeout: -1, Stop: func(context.Context) error {
+		time.Sleep(20 * time.Millisecond)
+		r.record("stopped unbounded")
+		return nil
+	}})
+
+	err := m.Stop(context.Background())
+	require.ErrorContains(t, err, "failed to stop stuck: timed out after 10ms")
+	require.Equal(t, []string{"stopped unbounded", "closed datastore"}, r.recorded())
+
+	// Stopping again does nothing.
+	require.NoError(t, m.Stop(context.Background()))
+}
+
+func TestStopErrors(t *testing.T) {
+	m := NewManager(0)
+	m.AddWithError("first", func() error { return errors.New("first failed") })
+	m.AddWithError("second", func() error { return errors.New("second failed") })
+
+	err := m.Stop(context.Background())
+	require.ErrorContains(t, err, "failed to stop second: second failed")
+	require.ErrorContains(t, err, "failed to stop first: first failed")
+}
@@ -0,0 +1,273 @@
+// Package admission rejects writes of relationships which are not admitted by the policies
+// configured by the operator, CEL expressions over the caller and each relationship being
+// written or deleted, so that guardrails such as which callers may write the relationships of
+// a namespace are enforced by the server rather than by each client.
+package admission
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/authzed/cel-go/cel"
+	"github.com/authzed/cel-go/common"
+	"github.com/authzed/cel-go/common/types"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"github.com/authzed/spicedb/internal/auth"
+	"github.com/authzed/spicedb/pkg/spiceerrors"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+var rejectedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "middleware",
+	Name:      "admission_rejected_total",
+	Help:      "Count of the writes rejected by an admission policy",
+}, []string{"policy"})
+
+const reasonRejectedByPolicy = "ERROR_REASON_WRITE_REJECTED_BY_POLICY"
+
+// maxEvaluationCost bounds the cost of evaluating a policy against a single relationship.
+const maxEvaluationCost = 1000
+
+// Policy is a CEL expression which must evaluate to true for each relationship written or
+// deleted by a request for the request to be admitted. It is given the variables:
+//
+//   - `caller`, a map of the `principal` identifying the caller (as recorded in audit
+//     records, e.g. `key:provisioning`), and the `subject`, scoped preshared `key` name and
+//     `tenant` of its token, which are empty if not known;
+//   - `method`, the name of the API method called, e.g. `WriteRelationships`;
+//   - `mutation`, a map of the `operation` (`CREATE`, `TOUCH` or `DELETE`), `resource_type`,
+//     `resource_id`, `relation`, `subject_type`, `subject_id`, `subject_relation` and
+//     `caveat` of the relationship. Deletions by filter are checked once, against the
+//     fields of the filter, with those it does not set left empty.
+//
+// For example, `mutation.resource_type != "organization" || caller.principal ==
+// "key:provisioning"` only admits writes of organizations by the provisioning service.
+type Policy struct {
+	// Name identifies the policy in errors and metrics.
+	Name string `json:"name"`
+
+	// Expression is the CEL expression admitting each relationship.
+	Expression string `json:"expression"`
+
+	// Message, if set, is returned to callers whose writes the policy rejects.
+	Message string `json:"message"`
+}
+
+type compiledPolicy struct {
+	Policy
+	program cel.Program
+}
+
+// Policies are the admission policies of the server.
+type Policies struct {
+	policies []compiledPolicy
+}
+
+// NewPolicies compiles the policies, or returns nil if there are none.
+func NewPolicies(policies []Policy) (*Policies, error) {
+	if len(policies) == 0 {
+		return nil, nil
+	}
+
+	env, err := cel.NewEnv(
+		cel.Variable("caller", cel.MapType(cel.StringType, cel.StringType)),
+		cel.Variable("method", cel.StringType),
+		cel.Variable("mutation", cel.MapType(cel.StringType, cel.StringType)),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("unable to initialize admission policy environment: %w", err)
+	}
+
+	compiled := make([]compiledPolicy, 0, len(policies))
+	names := map[string]struct{}{}
+	for _, policy := range policies {
+		if policy.Name == "" {
+			return nil, errors.New("admission policies must be named")
+		}
+		if _, ok := names[policy.Name]; ok {
+			return nil, fmt.Errorf("duplicate admission policy `%s`", policy.Name)
+		}
+		names[policy.Name] = struct{}{}
+
+		ast, issues := env.CompileSource(common.NewStringSource(policy.Expression, policy.Name))
+		if issues != nil && issues.Err() != nil {
+			return nil, fmt.Errorf("invalid admission policy `%s`: %w", policy.Name, issues.Err())
+		}
+		if !ast.OutputType().IsExactType(cel.BoolType) {
+			return nil, fmt.Errorf("admission policy `%s` must result in a bool value: found `%s`", policy.Name, ast.OutputType())
+		}
+
+		program, err := env.Program(ast, cel.CostLimit(maxEvaluationCost))
+		if err != nil {
+			return nil, fmt.Errorf("invalid admission policy `%s`: %w", policy.Name, err)
+		}
+		compiled = append(compiled, compiledPolicy{Policy: policy, program: program})
+	}
+	return &Policies{policies: compiled}, nil
+}
+
+// mutation is a relationship, or filter of relationships, being written or deleted.
+type mutation struct {
+	fields      map[string]string
+	description string
+}
+
+func relationshipMutation(operation v1.RelationshipUpdate_Operation, rel *v1.Relationship) mutation {
+	return mutation{
+		fields: map[string]string{
+			"operation":        strings.TrimPrefix(operation.String(), "OPERATION_"),
+			"resource_type":    rel.GetResource().GetObjectType(),
+			"resource_id":      rel.GetResource().GetObjectId(),
+			"relation":         rel.GetRelation(),
+			"subject_type":     rel.GetSubject().GetObject().GetObjectType(),
+			"subject_id":       rel.GetSubject().GetObject().GetObjectId(),
+			"subject_relation": rel.GetSubject().GetOptionalRelation(),
+			"caveat":           rel.GetOptionalCaveat().GetCaveatName(),
+		},
+		description: tuple.MustStringRelationship(rel),
+	}
+}
+
+func filterMutation(filter *v1.RelationshipFilter) mutation {
+	return mutation{
+		fields: map[string]string{
+			"operation":        "DELETE",
+			"resource_type":    filter.GetResourceType(),
+			"resource_id":      filter.GetOptionalResourceId(),
+			"relation":         filter.GetOptionalRelation(),
+			"subject_type":     filter.GetOptionalSubjectFilter().GetSubjectType(),
+			"subject_id":       filter.GetOptionalSubjectFilter().GetOptionalSubjectId(),
+			"subject_relation": filter.GetOptionalSubjectFilter().GetOptionalRelation().GetRelation(),
+			"caveat":           "",
+		},
+		description: "relationships of `" + filter.GetResourceType() + "`",
+	}
+}
+
+// callerFields returns the fields describing the caller of the request.
+func callerFields(ctx context.Context) map[string]string {
+	fields := map[string]string{
+		"principal": auth.PrincipalFromContext(ctx),
+		"subject":   "",
+		"key":       "",
+		"tenant":    "",
+	}
+	if scope, ok := auth.ScopeFromContext(ctx); ok {
+		fields["subject"] = scope.Subject
+		fields["key"] = scope.KeyName
+		fields["tenant"] = scope.Tenant
+	}
+	return fields
+}
+
+// admit returns an error if any policy rejects any of the mutations. Policies which fail to
+// evaluate reject the mutation, so that a faulty policy cannot admit writes.
+func (p *Policies) admit(ctx context.Context, fullMethod string, mutations []mutation) error {
+	caller := callerFields(ctx)
+	method := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+
+	for _, m := range mutations {
+		for _, policy := range p.policies {
+			result, _, err := policy.program.Eval(map[string]any{
+				"caller":   caller,
+				"method":   method,
+				"mutation": m.fields,
+			})
+			if err == nil && result == types.True {
+				continue
+			}
+
+			rejectedCounter.WithLabelValues(policy.Name).Inc()
+			message := policy.Message
+			if message == "" {
+				message = "write is not admitted"
+			}
+			if err != nil {
+				message = fmt.Sprintf("admission policy failed to evaluate: %s", err)
+			}
+			return spiceerrors.WithCodeAndDetailsAsError(
+				fmt.Errorf("write of %s rejected by admission policy `%s`: %s", m.description, policy.Name, message),
+				codes.PermissionDenied,
+				&errdetails.ErrorInfo{
+					Reason: reasonRejectedByPolicy,
+					Domain: spiceerrors.Domain,
+					Metadata: map[string]string{
+						"policy":       policy.Name,
+						"relationship": m.description,
+					},
+				},
+			)
+		}
+	}
+	return nil
+}
+
+// UnaryServerInterceptor returns a new interceptor which rejects writes and deletions of
+// relationships not admitted by the policies. Nil policies admit all requests.
+func UnaryServerInterceptor(policies *Policies) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if policies == nil {
+			return handler(ctx, req)
+		}
+
+		var mutations []mutation
+		switch r := req.(type) {
+		case *v1.WriteRelationshipsRequest:
+			mutations = make([]mutation, 0, len(r.Updates))
+			for _, update := range r.Updates {
+				mutations = append(mutations, relationshipMutation(update.Operation, update.Relationship))
+			}
+
+		case *v1.DeleteRelationshipsRequest:
+			mutations = []mutation{filterMutation(r.RelationshipFilter)}
+		}
+
+		if err := policies.admit(ctx, info.FullMethod, mutations); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a new interceptor which rejects bulk imports of
+// relationships not admitted by the policies. Nil policies admit all streams.
+func StreamServerInterceptor(policies *Policies) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if policies == nil {
+			return handler(srv, stream)
+		}
+		return handler(srv, &admissionStream{ServerStream: stream, policies: policies, fullMethod: info.FullMethod})
+	}
+}
+
+// admissionStream checks the relationships of the bulk imports received on a stream.
+type admissionStream struct {
+	grpc.ServerStream
+
+	policies   *Policies
+	fullMethod string
+}
+
+func (s *admissionStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	if r, ok := m.(*v1.BulkImportRelationshipsRequest); ok {
+		mutations := make([]mutation, 0, len(r.Relationships))
+		for _, rel := range r.Relationships {
+			mutations = append(mutations, relationshipMutation(v1.RelationshipUpdate_OPERATION_CREATE, rel))
+		}
+		return s.policies.admit(s.Context(), s.fullMethod, mutations)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+package admission
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/auth"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const writeMethod = "/authzed.api.v1.PermissionsService/WriteRelationships"
+
+func TestNewPolicies(t *testing.T) {
+	policies, err := NewPolicies(nil)
+	require.NoError(t, err)
+	require.Nil(t, policies)
+
+	_, err = NewPolicies([]Policy{{Name: "invalid", Expression: "mutation.resource_type =="}})
+	require.ErrorContains(t, err, "invalid admission policy `invalid`")
+
+	_, err = NewPolicies([]Policy{{Name: "string", Expression: "mutation.resource_type"}})
+	require.ErrorContains(t, err, "must result in a bool value")
+
+	_, err = NewPolicies([]Policy{{Expression: "true"}})
+	require.ErrorContains(t, err, "must be named")
+
+	_, err = NewPolicies([]Policy{{Name: "twice", Expression: "true"}, {Name: "twice", Expression: "true"}})
+	require.ErrorContains(t, err, "duplicate admission policy `twice`")
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	policies, err := NewPolicies([]Policy{
+		{
+			Name:       "org-provisioning",
+			Expression: `mutation.resource_type != "organization" || caller.key == "provisioning"`,
+			Message:    "only provisioning may write organizations",
+		},
+		{
+			Name:       "no-wildcard-deletes",
+			Expression: `mutation.operation != "DELETE" || mutation.resource_id != ""`,
+		},
+	})
+	require.NoError(t, err)
+	interceptor := UnaryServerInterceptor(policies)
+
+	provisioning := auth.ContextWithScope(context.Background(), &auth.TokenScope{KeyName: "provisioning"})
+	other := auth.ContextWithScope(context.Background(), &auth.TokenScope{KeyName: "frontend"})
+
+	call := func(ctx context.Context, req any) error {
+		_, err := interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: writeMethod}, func(context.Context, any) (any, error) {
+			return nil, nil
+		})
+		return err
+	}
+	write := func(ctx context.Context, rels ...string) error {
+		req := &v1.WriteRelationshipsRequest{}
+		for _, rel := range rels {
+			req.Updates = append(req.Updates, &v1.RelationshipUpdate{
+				Operation:    v1.RelationshipUpdate_OPERATION_TOUCH,
+				Relationship: tuple.ParseRel(rel),
+			})
+		}
+		return call(ctx, req)
+	}
+
+	require.NoError(t, write(provisioning, "organization:acme#member@user:tom"))
+	require.NoError(t, write(other, "document:firstdoc#viewer@user:tom"))
+
+	err = write(other, "document:firstdoc#viewer@user:tom", "organization:acme#member@user:tom")
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	require.ErrorContains(t, err, "rejected by admission policy `org-provisioning`: only provisioning may write organizations")
+
+	err = call(provisioning, &v1.DeleteRelationshipsRequest{RelationshipFilter: &v1.RelationshipFilter{ResourceType: "document"}})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	require.ErrorContains(t, err, "rejected by admission policy `no-wildcard-deletes`: write is not admitted")
+
+	require.NoError(t, call(other, &v1.DeleteRelationshipsRequest{RelationshipFilter: &v1.RelationshipFilter{ResourceType: "document", OptionalResourceId: "firstdoc"}}))
+
+	// Requests which do not write relationships are admitted.
+	require.NoError(t, call(other, &v1.CheckPermissionRequest{}))
+
+	// Nil policies admit all writes.
+	_, err = UnaryServerInterceptor(nil)(other, &v1.DeleteRelationshipsRequest{RelationshipFilter: &v1.RelationshipFilter{ResourceType: "document"}}, &grpc.UnaryServerInfo{FullMethod: writeMethod}, func(context.Context, any) (any, error) {
+		return nil, nil
+	})
+	require.NoError(t, err)
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	policies, err := NewPolicies([]Policy{{
+		Name:       "bulk-import-method",
+		Expression: `method != "BulkImportRelationships" || mutation.subject_type == "user"`,
+	}})
+	require.NoError(t, err)
+
+	stream := &mockServerStream{
+		ctx: context.Background(),
+		req: &v1.BulkImportRelationshipsRequest{Relationships: []*v1.Relationship{
+			tuple.ParseRel("document:firstdoc#viewer@user:tom"),
+			tuple.ParseRel("document:firstdoc#viewer@group:eng#member"),
+		}},
+	}
+	err = StreamServerInterceptor(policies)(nil, stream, &grpc.StreamServerInfo{FullMethod: "/authzed.api.v1.ExperimentalService/BulkImportRelationships"}, func(_ any, stream grpc.ServerStream) error {
+		return stream.RecvMsg(&v1.BulkImportRelationshipsRequest{})
+	})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+	require.ErrorContains(t, err, "document:firstdoc#viewer@group:eng#member")
+}
+
+type mockServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+	req *v1.BulkImportRelationshipsRequest
+}
+
+func (m *mockServerStream) Context() context.Context { return m.ctx }
+
+func (m *mockServerStream) RecvMsg(msg any) error {
+	msg.(*v1.BulkImportRelationshipsRequest).Relationships = m.req.Relationships
+	return nil
+}
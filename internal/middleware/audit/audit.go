@@ -0,0 +1,159 @@
+// Package audit implements middleware which emits a structured record of every change
+// made to relationships and schema through the API.
+package audit
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	middleware "github.com/grpc-ecosystem/go-grpc-middleware/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/authzed/spicedb/internal/auth"
+	log "github.com/authzed/spicedb/internal/logging"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/pkg/middleware/requestid"
+)
+
+var emitErrorsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "audit",
+	Name:      "emit_errors_total",
+	Help:      "Count of the audit records which could not be emitted",
+}, []string{"method"})
+
+// Operations recorded for changes.
+const (
+	OperationCreate = "CREATE"
+	OperationTouch  = "TOUCH"
+	OperationDelete = "DELETE"
+	OperationWrite  = "WRITE"
+)
+
+// Kinds of schema definitions recorded for changes.
+const (
+	KindNamespace = "namespace"
+	KindCaveat    = "caveat"
+)
+
+// Record is the audit record of the changes committed by a single API request.
+type Record struct {
+	// Time is when the changes were committed.
+	Time time.Time `json:"time"`
+
+	// RequestID is the ID of the request which made the changes.
+	RequestID string `json:"request_id,omitempty"`
+
+	// Actor identifies the caller which made the changes.
+	Actor string `json:"actor"`
+
+	// Method is the full name of the gRPC method called, or the source of changes made by the
+	// server itself.
+	Method string `json:"method"`
+
+	// Revision is the revision at which the changes were committed.
+	Revision string `json:"revision"`
+
+	// Namespaces are the object definitions whose relationships or schema were changed.
+	Namespaces []string `json:"namespaces"`
+
+	// Relationships are the relationships which were changed.
+	Relationships []RelationshipChange `json:"relationships,omitempty"`
+
+	// Definitions are the schema definitions which were changed.
+	Definitions []DefinitionChange `json:"definitions,omitempty"`
+}
+
+// RelationshipChange is a change made to a single relationship.
+type RelationshipChange struct {
+	Operation    string `json:"operation"`
+	Relationship string `json:"relationship"`
+}
+
+// DefinitionChange is a change made to a single object or caveat definition.
+type DefinitionChange struct {
+	Operation string `json:"operation"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+}
+
+// Sink receives audit records.
+type Sink interface {
+	// Emit durably records the audit record, or returns an error.
+	Emit(ctx context.Context, record *Record) error
+
+	// Close releases the resources held by the sink.
+	Close() error
+}
+
+// requestMeta is the information about a request recorded alongside its changes.
+type requestMeta struct {
+	requestID string
+	actor     string
+	method    string
+}
+
+func requestMetaFor(ctx context.Context, fullMethod string) requestMeta {
+	meta := requestMeta{
+		actor:  auth.PrincipalFromContext(ctx),
+		method: fullMethod,
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if requestIDs := md.Get(requestid.MetadataKey); len(requestIDs) > 0 {
+			meta.requestID = requestIDs[0]
+		}
+	}
+	return meta
+}
+
+// emit sends the record to the sink. The changes have already been committed, so a failure
+// to emit the record cannot fail the request; it is logged and counted instead.
+func emit(ctx context.Context, sink Sink, record *Record) {
+	sort.Strings(record.Namespaces)
+	if err := sink.Emit(ctx, record); err != nil {
+		emitErrorsCounter.WithLabelValues(record.Method).Inc()
+		log.Ctx(ctx).Error().Err(err).
+			Str("method", record.Method).
+			Str("revision", record.Revision).
+			Msg("failed to emit audit record")
+	}
+}
+
+// UnaryServerInterceptor returns a new unary server interceptor which emits an audit record
+// to the sink for every read-write transaction committed by the request. A nil sink
+// disables auditing.
+func UnaryServerInterceptor(sink Sink) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if sink == nil {
+			return handler(ctx, req)
+		}
+
+		ds := newAuditingDatastore(datastoremw.MustFromContext(ctx), sink, requestMetaFor(ctx, info.FullMethod))
+		if err := datastoremw.SetInContext(ctx, ds); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a new stream server interceptor which emits an audit
+// record to the sink for every read-write transaction committed by the stream. A nil sink
+// disables auditing.
+func StreamServerInterceptor(sink Sink) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if sink == nil {
+			return handler(srv, stream)
+		}
+
+		wrapped := middleware.WrapServerStream(stream)
+		ds := newAuditingDatastore(datastoremw.MustFromContext(stream.Context()), sink, requestMetaFor(stream.Context(), info.FullMethod))
+		if err := datastoremw.SetInContext(wrapped.WrappedContext, ds); err != nil {
+			return err
+		}
+		return handler(srv, wrapped)
+	}
+}
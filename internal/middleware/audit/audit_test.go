@@ -0,0 +1,213 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/authzed/spicedb/internal/auth"
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const writeMethod = "/authzed.api.v1.PermissionsService/WriteRelationships"
+
+type memorySink struct {
+	lock    sync.Mutex
+	records []*Record
+}
+
+func (ms *memorySink) Emit(_ context.Context, record *Record) error {
+	ms.lock.Lock()
+	defer ms.lock.Unlock()
+	ms.records = append(ms.records, record)
+	return nil
+}
+
+func (ms *memorySink) Close() error {
+	return nil
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	sink := &memorySink{}
+	ctx := datastoremw.ContextWithDatastore(context.Background(), ds)
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-request-id", "some-request"))
+	ctx = auth.ContextWithScope(ctx, &auth.TokenScope{Subject: "some-service"})
+
+	var revision datastore.Revision
+	interceptor := UnaryServerInterceptor(sink)
+	_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: writeMethod}, func(ctx context.Context, req any) (any, error) {
+		revision, err = datastoremw.MustFromContext(ctx).ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+			return rwt.WriteRelationships(ctx, []*core.RelationTupleUpdate{
+				tuple.Create(tuple.MustParse("document:first#viewer@user:tom")),
+				tuple.Touch(tuple.MustParse("folder:root#viewer@user:sarah")),
+			})
+		})
+		return nil, err
+	})
+	require.NoError(t, err)
+
+	require.Len(t, sink.records, 1)
+	record := sink.records[0]
+	require.Equal(t, "some-request", record.RequestID)
+	require.Equal(t, "sub:some-service", record.Actor)
+	require.Equal(t, writeMethod, record.Method)
+	require.Equal(t, revision.String(), record.Revision)
+	require.Equal(t, []string{"document", "folder"}, record.Namespaces)
+	require.Equal(t, []RelationshipChange{
+		{Operation: OperationCreate, Relationship: "document:first#viewer@user:tom"},
+		{Operation: OperationTouch, Relationship: "folder:root#viewer@user:sarah"},
+	}, record.Relationships)
+
+	// Requests which do not commit changes are not recorded.
+	_, err = interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: writeMethod}, func(ctx context.Context, req any) (any, error) {
+		_, err := datastoremw.MustFromContext(ctx).ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+			return nil
+		})
+		return nil, err
+	})
+	require.NoError(t, err)
+	require.Len(t, sink.records, 1)
+}
+
+func TestAuditingDatastore(t *testing.T) {
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	_, err = ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteRelationships(ctx, []*core.RelationTupleUpdate{
+			tuple.Create(tuple.MustParse("document:first#viewer@user:tom")),
+			tuple.Create(tuple.MustParse("document:second#viewer@user:tom")),
+			tuple.Create(tuple.MustParse("document:third#viewer@user:tom")),
+			tuple.Create(tuple.MustParse("folder:root#viewer@user:tom")),
+		})
+	})
+	require.NoError(t, err)
+
+	sink := &memorySink{}
+	audited := newAuditingDatastore(ds, sink, requestMeta{method: writeMethod})
+
+	// Limited deletions record exactly the relationships deleted.
+	_, err = audited.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		limit := uint64(2)
+		reachedLimit, err := rwt.DeleteRelationships(ctx, &v1.RelationshipFilter{ResourceType: "document"}, options.WithDeleteLimit(&limit))
+		require.True(t, reachedLimit)
+		return err
+	})
+	require.NoError(t, err)
+	require.Len(t, sink.records, 1)
+	require.Len(t, sink.records[0].Relationships, 2)
+
+	remaining := queryAll(t, ds, "document")
+	require.Len(t, remaining, 1)
+	for _, change := range sink.records[0].Relationships {
+		require.Equal(t, OperationDelete, change.Operation)
+		require.NotContains(t, remaining, change.Relationship)
+	}
+
+	// Unlimited deletions record all of the relationships matching the filter.
+	_, err = audited.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		_, err := rwt.DeleteRelationships(ctx, &v1.RelationshipFilter{ResourceType: "document"})
+		return err
+	})
+	require.NoError(t, err)
+	require.Len(t, sink.records, 2)
+	require.Equal(t, []RelationshipChange{{Operation: OperationDelete, Relationship: remaining[0]}}, sink.records[1].Relationships)
+	require.Empty(t, queryAll(t, ds, "document"))
+
+	// Schema changes are recorded.
+	_, err = audited.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		if err := rwt.WriteNamespaces(ctx, &core.NamespaceDefinition{Name: "document"}); err != nil {
+			return err
+		}
+		return rwt.WriteCaveats(ctx, []*core.CaveatDefinition{{Name: "somecaveat"}})
+	})
+	require.NoError(t, err)
+	require.Len(t, sink.records, 3)
+	require.Equal(t, []string{"document"}, sink.records[2].Namespaces)
+	require.Equal(t, []DefinitionChange{
+		{Operation: OperationWrite, Kind: KindNamespace, Name: "document"},
+		{Operation: OperationWrite, Kind: KindCaveat, Name: "somecaveat"},
+	}, sink.records[2].Definitions)
+}
+
+func queryAll(t *testing.T, ds datastore.Datastore, resourceType string) []string {
+	revision, err := ds.HeadRevision(context.Background())
+	require.NoError(t, err)
+
+	it, err := ds.SnapshotReader(revision).QueryRelationships(context.Background(), datastore.RelationshipsFilter{ResourceType: resourceType})
+	require.NoError(t, err)
+	defer it.Close()
+
+	var rels []string
+	for rel := it.Next(); rel != nil; rel = it.Next() {
+		rels = append(rels, tuple.MustString(rel))
+	}
+	require.NoError(t, it.Err())
+	return rels
+}
+
+func TestFileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.log")
+	record := &Record{Method: writeMethod, Revision: "1"}
+	line, err := marshalLine(record)
+	require.NoError(t, err)
+
+	// Each file holds two records before it is rotated.
+	sink, err := NewFileSink(path, int64(2*len(line)), 2)
+	require.NoError(t, err)
+
+	for i := 0; i < 7; i++ {
+		require.NoError(t, sink.Emit(context.Background(), record))
+	}
+	require.NoError(t, sink.Close())
+
+	for file, count := range map[string]int{path: 1, path + ".1": 2, path + ".2": 2} {
+		contents, err := os.ReadFile(file)
+		require.NoError(t, err)
+		require.Equal(t, count, strings.Count(string(contents), "\n"), file)
+	}
+	require.NoFileExists(t, path+".3")
+}
+
+func TestHTTPSink(t *testing.T) {
+	var received []Record
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var record Record
+		if err := json.NewDecoder(r.Body).Decode(&record); err != nil || record.Revision == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		received = append(received, record)
+	}))
+	defer server.Close()
+
+	sink := NewHTTPSink(server.URL, time.Second)
+	defer sink.Close()
+
+	require.NoError(t, sink.Emit(context.Background(), &Record{Method: writeMethod, Revision: "1"}))
+	require.Len(t, received, 1)
+	require.Equal(t, writeMethod, received[0].Method)
+
+	require.ErrorContains(t, sink.Emit(context.Background(), &Record{Method: writeMethod}), "400 Bad Request")
+}
@@ -0,0 +1,224 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+type auditingDatastore struct {
+	datastore.Datastore
+
+	sink Sink
+	meta requestMeta
+}
+
+// newAuditingDatastore creates a proxy which records the changes made in each read-write
+// transaction and emits them to the sink once the transaction has been committed.
+func newAuditingDatastore(delegate datastore.Datastore, sink Sink, meta requestMeta) datastore.Datastore {
+	return auditingDatastore{Datastore: delegate, sink: sink, meta: meta}
+}
+
+// NewDatastore creates a proxy which emits an audit record to the sink for every read-write
+// transaction committed through it, for changes made by the server itself rather than through
+// the API. The actor and method identify the source of the changes in the records.
+func NewDatastore(delegate datastore.Datastore, sink Sink, actor, method string) datastore.Datastore {
+	return newAuditingDatastore(delegate, sink, requestMeta{actor: actor, method: method})
+}
+
+func (ad auditingDatastore) Unwrap() datastore.Datastore {
+	return ad.Datastore
+}
+
+func (ad auditingDatastore) ReadWriteTx(
+	ctx context.Context,
+	fn datastore.TxUserFunc,
+	opts ...options.RWTOptionsOption,
+) (datastore.Revision, error) {
+	var record *Record
+	revision, err := ad.Datastore.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		// The function is retried on conflicts, so only the changes of the final attempt,
+		// which is committed, are recorded.
+		record = &Record{
+			RequestID: ad.meta.requestID,
+			Actor:     ad.meta.actor,
+			Method:    ad.meta.method,
+		}
+		return fn(ctx, &auditingTransaction{
+			ReadWriteTransaction: rwt,
+			record:               record,
+			namespaces:           map[string]struct{}{},
+		})
+	}, opts...)
+	if err != nil {
+		return revision, err
+	}
+
+	if len(record.Relationships) > 0 || len(record.Definitions) > 0 {
+		record.Time = time.Now().UTC()
+		record.Revision = revision.String()
+		emit(ctx, ad.sink, record)
+	}
+	return revision, nil
+}
+
+type auditingTransaction struct {
+	datastore.ReadWriteTransaction
+
+	record     *Record
+	namespaces map[string]struct{}
+}
+
+func (at *auditingTransaction) touchNamespace(namespace string) {
+	if _, ok := at.namespaces[namespace]; ok {
+		return
+	}
+	at.namespaces[namespace] = struct{}{}
+	at.record.Namespaces = append(at.record.Namespaces, namespace)
+}
+
+func (at *auditingTransaction) recordRelationship(operation string, rel *core.RelationTuple) {
+	at.touchNamespace(rel.ResourceAndRelation.Namespace)
+	at.record.Relationships = append(at.record.Relationships, RelationshipChange{
+		Operation:    operation,
+		Relationship: tuple.MustString(rel),
+	})
+}
+
+func (at *auditingTransaction) recordDefinition(operation, kind, name string) {
+	if kind == KindNamespace {
+		at.touchNamespace(name)
+	}
+	at.record.Definitions = append(at.record.Definitions, DefinitionChange{
+		Operation: operation,
+		Kind:      kind,
+		Name:      name,
+	})
+}
+
+func (at *auditingTransaction) WriteRelationships(ctx context.Context, mutations []*core.RelationTupleUpdate) error {
+	if err := at.ReadWriteTransaction.WriteRelationships(ctx, mutations); err != nil {
+		return err
+	}
+
+	for _, mutation := range mutations {
+		at.recordRelationship(mutation.Operation.String(), mutation.Tuple)
+	}
+	return nil
+}
+
+// DeleteRelationships records each of the relationships deleted by the filter, reading them
+// in the transaction before they are deleted. Limited deletions delete exactly the
+// relationships read, so that the record matches what was deleted.
+func (at *auditingTransaction) DeleteRelationships(ctx context.Context, filter *v1.RelationshipFilter, opts ...options.DeleteOptionsOption) (bool, error) {
+	deleteOpts := options.NewDeleteOptionsWithOptions(opts...)
+
+	var queryOpts []options.QueryOptionsOption
+	if deleteOpts.DeleteLimit != nil {
+		queryOpts = append(queryOpts, options.WithLimit(deleteOpts.DeleteLimit))
+	}
+
+	it, err := at.QueryRelationships(ctx, datastore.RelationshipsFilterFromPublicFilter(filter), queryOpts...)
+	if err != nil {
+		return false, err
+	}
+	defer it.Close()
+
+	var deleted []*core.RelationTuple
+	for rel := it.Next(); rel != nil; rel = it.Next() {
+		deleted = append(deleted, rel.CloneVT())
+	}
+	if it.Err() != nil {
+		return false, it.Err()
+	}
+	it.Close()
+
+	reachedLimit := false
+	if deleteOpts.DeleteLimit != nil {
+		mutations := make([]*core.RelationTupleUpdate, 0, len(deleted))
+		for _, rel := range deleted {
+			mutations = append(mutations, tuple.Delete(rel))
+		}
+		if len(mutations) > 0 {
+			if err := at.ReadWriteTransaction.WriteRelationships(ctx, mutations); err != nil {
+				return false, err
+			}
+		}
+		reachedLimit = uint64(len(deleted)) == *deleteOpts.DeleteLimit
+	} else if _, err := at.ReadWriteTransaction.DeleteRelationships(ctx, filter); err != nil {
+		return false, err
+	}
+
+	for _, rel := range deleted {
+		at.recordRelationship(OperationDelete, rel)
+	}
+	return reachedLimit, nil
+}
+
+func (at *auditingTransaction) WriteNamespaces(ctx context.Context, newConfigs ...*core.NamespaceDefinition) error {
+	if err := at.ReadWriteTransaction.WriteNamespaces(ctx, newConfigs...); err != nil {
+		return err
+	}
+
+	for _, config := range newConfigs {
+		at.recordDefinition(OperationWrite, KindNamespace, config.Name)
+	}
+	return nil
+}
+
+func (at *auditingTransaction) DeleteNamespaces(ctx context.Context, nsNames ...string) error {
+	if err := at.ReadWriteTransaction.DeleteNamespaces(ctx, nsNames...); err != nil {
+		return err
+	}
+
+	for _, name := range nsNames {
+		at.recordDefinition(OperationDelete, KindNamespace, name)
+	}
+	return nil
+}
+
+func (at *auditingTransaction) WriteCaveats(ctx context.Context, caveats []*core.CaveatDefinition) error {
+	if err := at.ReadWriteTransaction.WriteCaveats(ctx, caveats); err != nil {
+		return err
+	}
+
+	for _, caveat := range caveats {
+		at.recordDefinition(OperationWrite, KindCaveat, caveat.Name)
+	}
+	return nil
+}
+
+func (at *auditingTransaction) DeleteCaveats(ctx context.Context, names []string) error {
+	if err := at.ReadWriteTransaction.DeleteCaveats(ctx, names); err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		at.recordDefinition(OperationDelete, KindCaveat, name)
+	}
+	return nil
+}
+
+func (at *auditingTransaction) BulkLoad(ctx context.Context, iter datastore.BulkWriteRelationshipSource) (uint64, error) {
+	return at.ReadWriteTransaction.BulkLoad(ctx, &recordingSource{source: iter, at: at})
+}
+
+// recordingSource records each of the relationships read from a bulk load source.
+type recordingSource struct {
+	source datastore.BulkWriteRelationshipSource
+	at     *auditingTransaction
+}
+
+func (rs *recordingSource) Next(ctx context.Context) (*core.RelationTuple, error) {
+	rel, err := rs.source.Next(ctx)
+	if rel != nil {
+		rs.at.recordRelationship(OperationCreate, rel)
+	}
+	return rel, err
+}
@@ -0,0 +1,195 @@
+package audit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+)
+
+// NewWriterSink returns a sink which writes each record as a line of JSON to the writer,
+// such as stdout.
+func NewWriterSink(w io.Writer) Sink {
+	return &writerSink{w: w}
+}
+
+type writerSink struct {
+	lock sync.Mutex
+	w    io.Writer
+}
+
+func (ws *writerSink) Emit(_ context.Context, record *Record) error {
+	line, err := marshalLine(record)
+	if err != nil {
+		return err
+	}
+
+	ws.lock.Lock()
+	defer ws.lock.Unlock()
+	_, err = ws.w.Write(line)
+	return err
+}
+
+func (ws *writerSink) Close() error {
+	return nil
+}
+
+func marshalLine(record *Record) ([]byte, error) {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding audit record: %w", err)
+	}
+	return append(line, '\n'), nil
+}
+
+// NewFileSink returns a sink which appends each record as a line of JSON to the file at the
+// path. Once the file exceeds the maximum size in bytes, it is rotated to `path.1`, with
+// older files shifted up to the maximum number of backups kept.
+func NewFileSink(path string, maxSize int64, maxBackups int) (Sink, error) {
+	if maxSize <= 0 {
+		return nil, errors.New("audit log file maximum size must be positive")
+	}
+	if maxBackups < 0 {
+		return nil, errors.New("audit log file maximum backups must not be negative")
+	}
+
+	fs := &fileSink{path: path, maxSize: maxSize, maxBackups: maxBackups}
+	if err := fs.open(); err != nil {
+		return nil, err
+	}
+	return fs, nil
+}
+
+type fileSink struct {
+	path       string
+	maxSize    int64
+	maxBackups int
+
+	lock sync.Mutex
+	file *os.File
+	size int64
+}
+
+func (fs *fileSink) open() error {
+	file, err := os.OpenFile(fs.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600)
+	if err != nil {
+		return fmt.Errorf("error opening audit log file: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("error opening audit log file: %w", err)
+	}
+
+	fs.file = file
+	fs.size = info.Size()
+	return nil
+}
+
+func (fs *fileSink) Emit(_ context.Context, record *Record) error {
+	line, err := marshalLine(record)
+	if err != nil {
+		return err
+	}
+
+	fs.lock.Lock()
+	defer fs.lock.Unlock()
+
+	if fs.size > 0 && fs.size+int64(len(line)) > fs.maxSize {
+		if err := fs.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := fs.file.Write(line)
+	fs.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("error writing audit log file: %w", err)
+	}
+	return nil
+}
+
+// rotate shifts the current and backup files up by one, discarding the oldest, and opens a
+// new file. Must be called with the lock held.
+func (fs *fileSink) rotate() error {
+	if err := fs.file.Close(); err != nil {
+		return fmt.Errorf("error rotating audit log file: %w", err)
+	}
+
+	if fs.maxBackups == 0 {
+		if err := os.Remove(fs.path); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("error rotating audit log file: %w", err)
+		}
+		return fs.open()
+	}
+
+	for i := fs.maxBackups - 1; i >= 1; i-- {
+		err := os.Rename(fmt.Sprintf("%s.%d", fs.path, i), fmt.Sprintf("%s.%d", fs.path, i+1))
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("error rotating audit log file: %w", err)
+		}
+	}
+	if err := os.Rename(fs.path, fs.path+".1"); err != nil {
+		return fmt.Errorf("error rotating audit log file: %w", err)
+	}
+	return fs.open()
+}
+
+func (fs *fileSink) Close() error {
+	fs.lock.Lock()
+	defer fs.lock.Unlock()
+	return fs.file.Close()
+}
+
+// NewHTTPSink returns a sink which forwards each record as JSON in the body of a POST
+// request to the endpoint, treating any response other than a 2xx as a failure.
+func NewHTTPSink(endpoint string, timeout time.Duration) Sink {
+	return &httpSink{
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: timeout},
+	}
+}
+
+type httpSink struct {
+	endpoint string
+	client   *http.Client
+}
+
+func (hs *httpSink) Emit(ctx context.Context, record *Record) error {
+	body, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("error encoding audit record: %w", err)
+	}
+
+	// The record must be forwarded even if the request which made the changes has since
+	// been canceled.
+	req, err := http.NewRequestWithContext(context.WithoutCancel(ctx), http.MethodPost, hs.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error forwarding audit record: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := hs.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error forwarding audit record: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("error forwarding audit record: unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+func (hs *httpSink) Close() error {
+	hs.client.CloseIdleConnections()
+	return nil
+}
@@ -0,0 +1,72 @@
+// Package compression chooses the compressor with which responses are sent, so that
+// responses are compressed even for clients which send uncompressed requests.
+package compression
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+
+	// The compressors which may be preferred are registered, so that requests compressed
+	// with them are also accepted.
+	_ "github.com/mostynb/go-grpc-compression/snappy"
+	_ "github.com/mostynb/go-grpc-compression/zstd"
+	_ "google.golang.org/grpc/encoding/gzip"
+)
+
+// ValidateCompressors returns an error if any of the compressors is not registered.
+func ValidateCompressors(names []string) error {
+	for _, name := range names {
+		if encoding.GetCompressor(name) == nil {
+			return fmt.Errorf("unknown compressor `%s`", name)
+		}
+	}
+	return nil
+}
+
+// chooseCompressor sends the response with the first of the preferred compressors which
+// the client accepts. If the client accepts none of them, the response is sent with the
+// compressor of the request, as by default.
+func chooseCompressor(ctx context.Context, preferred []string) {
+	supported, err := grpc.ClientSupportedCompressors(ctx)
+	if err != nil {
+		return
+	}
+
+	for _, name := range preferred {
+		for _, candidate := range supported {
+			if candidate == name {
+				// The compressor is registered and accepted by the client, so it can
+				// be set.
+				_ = grpc.SetSendCompressor(ctx, name)
+				return
+			}
+		}
+	}
+}
+
+// UnaryServerInterceptor returns a new interceptor which compresses responses with the first
+// of the preferred compressors accepted by the client. No preferred compressors leaves the
+// choice to gRPC.
+func UnaryServerInterceptor(preferred []string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if len(preferred) > 0 {
+			chooseCompressor(ctx, preferred)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a new interceptor which compresses the messages of
+// streams with the first of the preferred compressors accepted by the client. No preferred
+// compressors leaves the choice to gRPC.
+func StreamServerInterceptor(preferred []string) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if len(preferred) > 0 {
+			chooseCompressor(stream.Context(), preferred)
+		}
+		return handler(srv, stream)
+	}
+}
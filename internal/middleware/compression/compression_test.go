@@ -0,0 +1,74 @@
+package compression
+
+import (
+	"context"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/grpc/encoding/gzip"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// countingCompressor counts the messages it compresses, so that the compressor chosen for
+// responses can be observed.
+type countingCompressor struct {
+	encoding.Compressor
+	compressed atomic.Int32
+}
+
+func (c *countingCompressor) Name() string { return "counting" }
+
+func (c *countingCompressor) Compress(w io.Writer) (io.WriteCloser, error) {
+	c.compressed.Add(1)
+	return c.Compressor.Compress(w)
+}
+
+var counting = &countingCompressor{Compressor: encoding.GetCompressor(gzip.Name)}
+
+func init() {
+	encoding.RegisterCompressor(counting)
+}
+
+func TestValidateCompressors(t *testing.T) {
+	require.NoError(t, ValidateCompressors([]string{"gzip", "zstd", "snappy"}))
+	require.ErrorContains(t, ValidateCompressors([]string{"gzip", "brotli"}), "unknown compressor `brotli`")
+}
+
+func TestInterceptors(t *testing.T) {
+	listener := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(UnaryServerInterceptor([]string{"unknown", counting.Name()})),
+		grpc.ChainStreamInterceptor(StreamServerInterceptor([]string{counting.Name()})),
+	)
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.Dial("bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	client := healthpb.NewHealthClient(conn)
+
+	// The request is uncompressed, yet the response is compressed with the first preferred
+	// compressor which the client accepts.
+	_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	require.Equal(t, int32(1), counting.compressed.Load())
+
+	stream, err := client.Watch(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, int32(2), counting.compressed.Load())
+}
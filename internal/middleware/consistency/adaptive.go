@@ -0,0 +1,176 @@
+package consistency
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/authzed/authzed-go/pkg/responsemeta"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/zedtoken"
+)
+
+// ConsistencyHeaderKey is the request metadata key which, when set to AdaptiveConsistency,
+// lets the server choose the revision at which reads are evaluated: the optimized revision of
+// the datastore, which is shared between requests and so likely cached, for as long as it has
+// been observed to lag behind the head revision by no more than the maximum staleness
+// configured on the server, and otherwise the head revision. It may only be combined with the
+// default, minimize latency, consistency.
+const ConsistencyHeaderKey = "io.spicedb.consistency"
+
+// AdaptiveConsistency is the value of ConsistencyHeaderKey requesting adaptive consistency.
+const AdaptiveConsistency = "adaptive"
+
+const (
+	// RevisionHeaderKey is the response metadata key holding the ZedToken of the revision
+	// chosen for a request with adaptive consistency.
+	RevisionHeaderKey responsemeta.ResponseMetadataHeaderKey = "io.spicedb.respmeta.revision"
+
+	// RevisionSourceHeaderKey is the response metadata key holding whether the revision chosen
+	// for a request with adaptive consistency was the `optimized` or `head` revision.
+	RevisionSourceHeaderKey responsemeta.ResponseMetadataHeaderKey = "io.spicedb.respmeta.revisionsource"
+)
+
+const (
+	revisionSourceOptimized = "optimized"
+	revisionSourceHead      = "head"
+
+	// maxHeadSamples bounds the head revisions remembered for each datastore. Forgetting
+	// samples only makes the staleness of revisions unknown, so that the head is used.
+	maxHeadSamples = 64
+)
+
+// WithAdaptiveConsistency enables adaptive consistency, requested through
+// ConsistencyHeaderKey, under which reads are evaluated at a revision stale by no more than
+// maxStaleness.
+func WithAdaptiveConsistency(maxStaleness time.Duration) Option {
+	adaptive := &adaptiveConsistency{maxStaleness: maxStaleness}
+	return func(o *options) {
+		o.adaptive = adaptive
+	}
+}
+
+// adaptiveConsistency chooses revisions for requests with adaptive consistency, tracking the
+// lag of each datastore separately.
+type adaptiveConsistency struct {
+	maxStaleness time.Duration
+	trackers     sync.Map // datastore.Datastore -> *lagTracker
+}
+
+// revision returns the optimized revision of the datastore if it is known to be stale by no
+// more than the maximum staleness, and otherwise its head revision, with the source of the
+// revision.
+func (a *adaptiveConsistency) revision(ctx context.Context, ds datastore.Datastore) (datastore.Revision, string, error) {
+	loaded, _ := a.trackers.LoadOrStore(ds, &lagTracker{})
+	tracker := loaded.(*lagTracker)
+
+	optimizedRev, err := ds.OptimizedRevision(ctx)
+	if err != nil {
+		return datastore.NoRevision, "", err
+	}
+
+	if staleness, ok := tracker.staleness(optimizedRev, time.Now()); ok && staleness <= a.maxStaleness {
+		return optimizedRev, revisionSourceOptimized, nil
+	}
+
+	// The head is sampled as of before it is read, since it may only have advanced since.
+	sampledAt := time.Now()
+	headRev, err := ds.HeadRevision(ctx)
+	if err != nil {
+		return datastore.NoRevision, "", err
+	}
+	tracker.observe(headRev, sampledAt, a.maxStaleness)
+	return headRev, revisionSourceHead, nil
+}
+
+// lagTracker measures how far revisions lag behind the head revision of a datastore, from
+// samples of the head revision taken whenever it is read.
+type lagTracker struct {
+	sync.Mutex
+	samples []headSample // ordered by time
+}
+
+type headSample struct {
+	revision datastore.Revision
+	at       time.Time
+}
+
+// staleness returns a bound on how stale the revision is: the time since the last sample at
+// which the head had not yet moved past it. Returns false if the head has moved past it in
+// every remembered sample.
+func (t *lagTracker) staleness(revision datastore.Revision, now time.Time) (time.Duration, bool) {
+	t.Lock()
+	defer t.Unlock()
+
+	for i := len(t.samples) - 1; i >= 0; i-- {
+		if !t.samples[i].revision.GreaterThan(revision) {
+			return now.Sub(t.samples[i].at), true
+		}
+	}
+	return 0, false
+}
+
+// observe records that the head revision was at least headRev at the time, forgetting the
+// samples too old to bound any staleness within maxAge.
+func (t *lagTracker) observe(headRev datastore.Revision, at time.Time, maxAge time.Duration) {
+	t.Lock()
+	defer t.Unlock()
+
+	if last := len(t.samples) - 1; last >= 0 {
+		if at.Before(t.samples[last].at) {
+			// A later sample has already been recorded by a concurrent request.
+			return
+		}
+
+		// The later of two samples of the same revision bounds staleness more tightly.
+		if t.samples[last].revision.Equal(headRev) {
+			t.samples[last].at = at
+			return
+		}
+	}
+	t.samples = append(t.samples, headSample{headRev, at})
+
+	expired := 0
+	for expired < len(t.samples)-1 && at.Sub(t.samples[expired].at) > maxAge {
+		expired++
+	}
+	expired = max(expired, len(t.samples)-maxHeadSamples)
+	t.samples = t.samples[expired:]
+}
+
+// requestedAdaptive returns whether adaptive consistency was requested via the
+// ConsistencyHeaderKey header.
+func requestedAdaptive(ctx context.Context) (bool, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return false, nil
+	}
+
+	values := md.Get(ConsistencyHeaderKey)
+	if len(values) == 0 {
+		return false, nil
+	}
+
+	if values[0] != AdaptiveConsistency {
+		return false, status.Errorf(codes.InvalidArgument, "invalid value for %s: expected `%s`", ConsistencyHeaderKey, AdaptiveConsistency)
+	}
+	return true, nil
+}
+
+// setRevisionHeaders reports the revision chosen for a request with adaptive consistency in
+// the headers of the response, if the request is being served over gRPC.
+func setRevisionHeaders(ctx context.Context, revision datastore.Revision, source string) error {
+	if grpc.ServerTransportStreamFromContext(ctx) == nil {
+		return nil
+	}
+
+	return responsemeta.SetResponseHeaderMetadata(ctx, map[responsemeta.ResponseMetadataHeaderKey]string{
+		RevisionHeaderKey:       zedtoken.MustNewFromRevision(revision).Token,
+		RevisionSourceHeaderKey: source,
+	})
+}
@@ -5,12 +5,15 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	log "github.com/authzed/spicedb/internal/logging"
@@ -18,6 +21,7 @@ import (
 	"github.com/authzed/spicedb/internal/services/shared"
 	"github.com/authzed/spicedb/pkg/cursor"
 	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/spiceerrors"
 	"github.com/authzed/spicedb/pkg/zedtoken"
 )
 
@@ -28,6 +32,12 @@ var ConsistentyCounter = promauto.NewCounterVec(prometheus.CounterOpts{
 	Help:      "Count of the consistencies used per request",
 }, []string{"method", "source"})
 
+// AtTimeHeaderKey is the request metadata key which, when present, causes reads to be evaluated
+// at the revision of the datastore at a point in time, given in RFC 3339 format, rather than at
+// a revision chosen by the consistency of the request. The time must fall within the GC window
+// of the datastore, and may only be combined with the default, minimize latency, consistency.
+const AtTimeHeaderKey = "io.spicedb.attime"
+
 type hasConsistency interface{ GetConsistency() *v1.Consistency }
 
 type hasOptionalCursor interface{ GetOptionalCursor() *v1.Cursor }
@@ -38,6 +48,33 @@ var revisionKey ctxKeyType = struct{}{}
 
 var errInvalidZedToken = errors.New("invalid revision requested")
 
+// Option configures the consistency middleware.
+type Option func(*options)
+
+type options struct {
+	enforceRevisionTokens bool
+	revisionTokenTimeout  time.Duration
+	adaptive              *adaptiveConsistency
+}
+
+// WithEnforcedRevisionTokens guarantees that requests which must be at least as fresh as a
+// ZedToken are evaluated at or after the revision of the token, by waiting up to the timeout
+// for the datastore to reach that revision, such as when replication or quantization has yet
+// to catch up, and failing the request if it does not. Without it, a request may be evaluated
+// at a revision the datastore has not yet reached, so that changes to relationships can be
+// observed out of order relative to the changes they protect.
+func WithEnforcedRevisionTokens(timeout time.Duration) Option {
+	return func(o *options) {
+		o.enforceRevisionTokens = true
+		o.revisionTokenTimeout = timeout
+	}
+}
+
+const (
+	initialRevisionWaitDelay = 5 * time.Millisecond
+	maxRevisionWaitDelay     = 100 * time.Millisecond
+)
+
 type revisionHandle struct {
 	revision datastore.Revision
 }
@@ -64,10 +101,14 @@ func RevisionFromContext(ctx context.Context) (datastore.Revision, *v1.ZedToken,
 
 // AddRevisionToContext adds a revision to the given context, based on the consistency block found
 // in the given request (if applicable).
-func AddRevisionToContext(ctx context.Context, req interface{}, ds datastore.Datastore) error {
+func AddRevisionToContext(ctx context.Context, req interface{}, ds datastore.Datastore, opts ...Option) error {
 	switch req := req.(type) {
 	case hasConsistency:
-		return addRevisionToContextFromConsistency(ctx, req, ds)
+		var o options
+		for _, opt := range opts {
+			opt(&o)
+		}
+		return addRevisionToContextFromConsistency(ctx, req, ds, o)
 	default:
 		return nil
 	}
@@ -75,7 +116,7 @@ func AddRevisionToContext(ctx context.Context, req interface{}, ds datastore.Dat
 
 // addRevisionToContextFromConsistency adds a revision to the given context, based on the consistency block found
 // in the given request (if applicable).
-func addRevisionToContextFromConsistency(ctx context.Context, req hasConsistency, ds datastore.Datastore) error {
+func addRevisionToContextFromConsistency(ctx context.Context, req hasConsistency, ds datastore.Datastore, opts options) error {
 	handle := ctx.Value(revisionKey)
 	if handle == nil {
 		return nil
@@ -86,6 +127,29 @@ func addRevisionToContextFromConsistency(ctx context.Context, req hasConsistency
 
 	withOptionalCursor, hasOptionalCursor := req.(hasOptionalCursor)
 
+	at, atTimeRequested, err := requestedTime(ctx)
+	if err != nil {
+		return err
+	}
+	if atTimeRequested && consistency != nil && !consistency.GetMinimizeLatency() {
+		return status.Errorf(codes.InvalidArgument, "%s cannot be combined with a consistency other than minimize latency", AtTimeHeaderKey)
+	}
+
+	adaptiveRequested, err := requestedAdaptive(ctx)
+	if err != nil {
+		return err
+	}
+	if adaptiveRequested {
+		switch {
+		case atTimeRequested:
+			return status.Errorf(codes.InvalidArgument, "%s cannot be combined with %s", ConsistencyHeaderKey, AtTimeHeaderKey)
+		case consistency != nil && !consistency.GetMinimizeLatency():
+			return status.Errorf(codes.InvalidArgument, "%s cannot be combined with a consistency other than minimize latency", ConsistencyHeaderKey)
+		case opts.adaptive == nil:
+			return status.Errorf(codes.FailedPrecondition, "adaptive consistency is not enabled on this server")
+		}
+	}
+
 	switch {
 	case hasOptionalCursor && withOptionalCursor.GetOptionalCursor() != nil:
 		// Always use the revision encoded in the cursor.
@@ -103,6 +167,30 @@ func addRevisionToContextFromConsistency(ctx context.Context, req hasConsistency
 
 		revision = requestedRev
 
+	case atTimeRequested:
+		// At time: Use the datastore's revision at the requested point in time.
+		ConsistentyCounter.WithLabelValues("attime", "request").Inc()
+
+		requestedRev, err := revisionAtTime(ctx, at, ds)
+		if err != nil {
+			return rewriteDatastoreError(ctx, err)
+		}
+
+		revision = requestedRev
+
+	case adaptiveRequested:
+		// Adaptive: Use the datastore's optimized revision unless it lags too far behind.
+		adaptiveRev, source, err := opts.adaptive.revision(ctx, ds)
+		if err != nil {
+			return rewriteDatastoreError(ctx, err)
+		}
+		ConsistentyCounter.WithLabelValues("adaptive", source).Inc()
+
+		if err := setRevisionHeaders(ctx, adaptiveRev, source); err != nil {
+			return err
+		}
+		revision = adaptiveRev
+
 	case consistency == nil || consistency.GetMinimizeLatency():
 		// Minimize Latency: Use the datastore's current revision, whatever it may be.
 		source := "request"
@@ -141,6 +229,12 @@ func addRevisionToContextFromConsistency(ctx context.Context, req hasConsistency
 		}
 		ConsistentyCounter.WithLabelValues("atleast", source).Inc()
 
+		if pickedRequest && opts.enforceRevisionTokens {
+			if err := waitForRevision(ctx, picked, ds, opts.revisionTokenTimeout); err != nil {
+				return rewriteDatastoreError(ctx, err)
+			}
+		}
+
 		revision = picked
 
 	case consistency.GetAtExactSnapshot() != nil:
@@ -149,7 +243,7 @@ func addRevisionToContextFromConsistency(ctx context.Context, req hasConsistency
 
 		requestedRev, err := zedtoken.DecodeRevision(consistency.GetAtExactSnapshot(), ds)
 		if err != nil {
-			return errInvalidZedToken
+			return invalidZedTokenError(err)
 		}
 
 		err = ds.CheckRevision(ctx, requestedRev)
@@ -175,7 +269,7 @@ var bypassServiceWhitelist = map[string]struct{}{
 
 // UnaryServerInterceptor returns a new unary server interceptor that performs per-request exchange of
 // the specified consistency configuration for the revision at which to perform the request.
-func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+func UnaryServerInterceptor(opts ...Option) grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 		for bypass := range bypassServiceWhitelist {
 			if strings.HasPrefix(info.FullMethod, bypass) {
@@ -184,7 +278,7 @@ func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
 		}
 		ds := datastoremw.MustFromContext(ctx)
 		newCtx := ContextWithHandle(ctx)
-		if err := AddRevisionToContext(newCtx, req, ds); err != nil {
+		if err := AddRevisionToContext(newCtx, req, ds, opts...); err != nil {
 			return nil, err
 		}
 
@@ -194,21 +288,22 @@ func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
 
 // StreamServerInterceptor returns a new stream server interceptor that performs per-request exchange of
 // the specified consistency configuration for the revision at which to perform the request.
-func StreamServerInterceptor() grpc.StreamServerInterceptor {
+func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
 	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
 		for bypass := range bypassServiceWhitelist {
 			if strings.HasPrefix(info.FullMethod, bypass) {
 				return handler(srv, stream)
 			}
 		}
-		wrapper := &recvWrapper{stream, ContextWithHandle(stream.Context())}
+		wrapper := &recvWrapper{stream, ContextWithHandle(stream.Context()), opts}
 		return handler(srv, wrapper)
 	}
 }
 
 type recvWrapper struct {
 	grpc.ServerStream
-	ctx context.Context
+	ctx  context.Context
+	opts []Option
 }
 
 func (s *recvWrapper) Context() context.Context { return s.ctx }
@@ -219,7 +314,39 @@ func (s *recvWrapper) RecvMsg(m interface{}) error {
 	}
 	ds := datastoremw.MustFromContext(s.ctx)
 
-	return AddRevisionToContext(s.ctx, m, ds)
+	return AddRevisionToContext(s.ctx, m, ds, s.opts...)
+}
+
+// requestedTime returns the point in time requested via the AtTimeHeaderKey header, if any.
+func requestedTime(ctx context.Context) (time.Time, bool, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return time.Time{}, false, nil
+	}
+
+	values := md.Get(AtTimeHeaderKey)
+	if len(values) == 0 {
+		return time.Time{}, false, nil
+	}
+
+	at, err := time.Parse(time.RFC3339Nano, values[0])
+	if err != nil {
+		return time.Time{}, false, status.Errorf(codes.InvalidArgument, "invalid value for %s: %s", AtTimeHeaderKey, err)
+	}
+	return at, true, nil
+}
+
+// revisionAtTime returns the revision of the datastore at the point in time.
+func revisionAtTime(ctx context.Context, at time.Time, ds datastore.Datastore) (datastore.Revision, error) {
+	if at.After(time.Now()) {
+		return datastore.NoRevision, status.Errorf(codes.InvalidArgument, "the time requested via %s must not be in the future", AtTimeHeaderKey)
+	}
+
+	pit := datastore.UnwrapAs[datastore.PointInTimeDatastore](ds)
+	if pit == nil {
+		return datastore.NoRevision, datastore.NewPointInTimeUnsupportedErr()
+	}
+	return pit.RevisionAtTime(ctx, at)
 }
 
 // pickBestRevision compares the provided ZedToken with the optimized revision of the datastore, and returns the most
@@ -234,7 +361,7 @@ func pickBestRevision(ctx context.Context, requested *v1.ZedToken, ds datastore.
 	if requested != nil {
 		requestedRev, err := zedtoken.DecodeRevision(requested, ds)
 		if err != nil {
-			return datastore.NoRevision, false, errInvalidZedToken
+			return datastore.NoRevision, false, invalidZedTokenError(err)
 		}
 
 		if databaseRev.GreaterThan(requestedRev) {
@@ -247,6 +374,55 @@ func pickBestRevision(ctx context.Context, requested *v1.ZedToken, ds datastore.
 	return databaseRev, false, nil
 }
 
+// invalidZedTokenError returns the error for a requested zedtoken which could not be decoded:
+// that of its signature if it was not signed by the server, which is reported to the caller.
+func invalidZedTokenError(err error) error {
+	var sigErr zedtoken.ErrInvalidSignature
+	if errors.As(err, &sigErr) {
+		return sigErr
+	}
+	return errInvalidZedToken
+}
+
+// waitForRevision waits until the head revision of the datastore has reached the revision,
+// returning an Unavailable error if it has not within the timeout.
+func waitForRevision(ctx context.Context, revision datastore.Revision, ds datastore.Datastore, timeout time.Duration) error {
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	delay := initialRevisionWaitDelay
+	for {
+		headRev, err := ds.HeadRevision(waitCtx)
+		switch {
+		case err == nil && !revision.GreaterThan(headRev):
+			return nil
+		case err != nil && waitCtx.Err() == nil:
+			return err
+		}
+
+		select {
+		case <-waitCtx.Done():
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return spiceerrors.WithCodeAndDetailsAsError(
+				fmt.Errorf("the revision of the ZedToken was not reached by the datastore within %s", timeout),
+				codes.Unavailable,
+				&errdetails.ErrorInfo{
+					Reason: "ERROR_REASON_REVISION_NOT_REACHED",
+					Domain: spiceerrors.Domain,
+					Metadata: map[string]string{
+						"revision": revision.String(),
+					},
+				},
+				spiceerrors.ForRetry(maxRevisionWaitDelay),
+			)
+		case <-time.After(delay):
+			delay = min(delay*2, maxRevisionWaitDelay)
+		}
+	}
+}
+
 func rewriteDatastoreError(ctx context.Context, err error) error {
 	// Check if the error can be directly used.
 	if _, ok := status.FromError(err); ok {
@@ -260,6 +436,9 @@ func rewriteDatastoreError(ctx context.Context, err error) error {
 	case errors.As(err, &datastore.ErrReadOnly{}):
 		return shared.ErrServiceReadOnly
 
+	case errors.As(err, &datastore.ErrPointInTimeUnsupported{}):
+		return status.Errorf(codes.Unimplemented, "%s", err)
+
 	default:
 		log.Ctx(ctx).Err(err).Msg("unexpected consistency middleware error")
 		return err
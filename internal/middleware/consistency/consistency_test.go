@@ -4,13 +4,20 @@ import (
 	"context"
 	"errors"
 	"testing"
+	"time"
 
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 
 	"github.com/authzed/spicedb/internal/datastore/proxy/proxy_test"
 	"github.com/authzed/spicedb/internal/datastore/revisions"
 	"github.com/authzed/spicedb/pkg/cursor"
+	"github.com/authzed/spicedb/pkg/datastore"
 	dispatch "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
 	"github.com/authzed/spicedb/pkg/zedtoken"
 )
@@ -191,3 +198,303 @@ func TestAddRevisionToContextWithCursor(t *testing.T) {
 	require.True(optimized.Equal(rev))
 	ds.AssertExpectations(t)
 }
+
+func TestAddRevisionToContextAtLeastAsFreshEnforced(t *testing.T) {
+	require := require.New(t)
+
+	ds := &proxy_test.MockDatastore{}
+	ds.On("OptimizedRevision").Return(optimized, nil).Once()
+	ds.On("RevisionFromString", exact.String()).Return(exact, nil).Once()
+
+	// The datastore reaches the revision of the token on the second attempt.
+	ds.On("HeadRevision").Return(optimized, nil).Once()
+	ds.On("HeadRevision").Return(head, nil).Once()
+
+	updated := ContextWithHandle(context.Background())
+	err := AddRevisionToContext(updated, &v1.CheckPermissionRequest{
+		Consistency: &v1.Consistency{
+			Requirement: &v1.Consistency_AtLeastAsFresh{
+				AtLeastAsFresh: zedtoken.MustNewFromRevision(exact),
+			},
+		},
+	}, ds, WithEnforcedRevisionTokens(time.Second))
+	require.NoError(err)
+
+	rev, _, err := RevisionFromContext(updated)
+	require.NoError(err)
+
+	require.True(exact.Equal(rev))
+	ds.AssertExpectations(t)
+}
+
+func TestAddRevisionToContextAtLeastAsFreshEnforcedTimeout(t *testing.T) {
+	require := require.New(t)
+
+	ds := &proxy_test.MockDatastore{}
+	ds.On("OptimizedRevision").Return(optimized, nil).Once()
+	ds.On("RevisionFromString", exact.String()).Return(exact, nil).Once()
+	ds.On("HeadRevision").Return(optimized, nil)
+
+	updated := ContextWithHandle(context.Background())
+	err := AddRevisionToContext(updated, &v1.CheckPermissionRequest{
+		Consistency: &v1.Consistency{
+			Requirement: &v1.Consistency_AtLeastAsFresh{
+				AtLeastAsFresh: zedtoken.MustNewFromRevision(exact),
+			},
+		},
+	}, ds, WithEnforcedRevisionTokens(20*time.Millisecond))
+	require.Equal(codes.Unavailable, status.Code(err))
+
+	details := status.Convert(err).Details()
+	require.Len(details, 2)
+	require.Equal("ERROR_REASON_REVISION_NOT_REACHED", details[0].(*errdetails.ErrorInfo).Reason)
+	require.Equal(maxRevisionWaitDelay, details[1].(*errdetails.RetryInfo).RetryDelay.AsDuration())
+	ds.AssertExpectations(t)
+}
+
+func TestAddRevisionToContextAtLeastAsFreshEnforcedAlreadyReached(t *testing.T) {
+	require := require.New(t)
+
+	// The optimized revision is newer than the token, so there is nothing to wait for.
+	ds := &proxy_test.MockDatastore{}
+	ds.On("OptimizedRevision").Return(exact, nil).Once()
+	ds.On("RevisionFromString", optimized.String()).Return(optimized, nil).Once()
+
+	updated := ContextWithHandle(context.Background())
+	err := AddRevisionToContext(updated, &v1.CheckPermissionRequest{
+		Consistency: &v1.Consistency{
+			Requirement: &v1.Consistency_AtLeastAsFresh{
+				AtLeastAsFresh: zedtoken.MustNewFromRevision(optimized),
+			},
+		},
+	}, ds, WithEnforcedRevisionTokens(time.Second))
+	require.NoError(err)
+
+	rev, _, err := RevisionFromContext(updated)
+	require.NoError(err)
+
+	require.True(exact.Equal(rev))
+	ds.AssertExpectations(t)
+}
+
+func TestAddRevisionToContextAtTime(t *testing.T) {
+	require := require.New(t)
+
+	at := time.Now().Add(-time.Hour).UTC()
+	ds := &proxy_test.MockDatastore{}
+	ds.On("RevisionAtTime", at).Return(exact, nil).Once()
+
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(AtTimeHeaderKey, at.Format(time.RFC3339Nano)))
+	updated := ContextWithHandle(ctx)
+	err := AddRevisionToContext(updated, &v1.CheckPermissionRequest{}, ds)
+	require.NoError(err)
+
+	rev, _, err := RevisionFromContext(updated)
+	require.NoError(err)
+
+	require.True(exact.Equal(rev))
+	ds.AssertExpectations(t)
+}
+
+func TestAddRevisionToContextAtTimeInvalid(t *testing.T) {
+	testCases := []struct {
+		name        string
+		at          string
+		consistency *v1.Consistency
+		expectedErr string
+	}{
+		{
+			"malformed time",
+			"last friday",
+			nil,
+			"invalid value for io.spicedb.attime",
+		},
+		{
+			"future time",
+			time.Now().Add(time.Hour).Format(time.RFC3339),
+			nil,
+			"must not be in the future",
+		},
+		{
+			"combined with a ZedToken",
+			time.Now().Add(-time.Hour).Format(time.RFC3339),
+			&v1.Consistency{Requirement: &v1.Consistency_AtExactSnapshot{AtExactSnapshot: zedtoken.MustNewFromRevision(exact)}},
+			"cannot be combined with a consistency other than minimize latency",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ds := &proxy_test.MockDatastore{}
+
+			ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(AtTimeHeaderKey, tc.at))
+			err := AddRevisionToContext(ContextWithHandle(ctx), &v1.CheckPermissionRequest{Consistency: tc.consistency}, ds)
+			require.Equal(t, codes.InvalidArgument, status.Code(err))
+			require.ErrorContains(t, err, tc.expectedErr)
+			ds.AssertExpectations(t)
+		})
+	}
+}
+
+// fakeTransportStream captures the headers set on a response.
+type fakeTransportStream struct {
+	grpc.ServerTransportStream
+	header metadata.MD
+}
+
+func (s *fakeTransportStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+	return nil
+}
+
+func addAdaptiveRevision(t *testing.T, ds *proxy_test.MockDatastore, adaptive Option) (rev datastore.Revision, source string) {
+	stream := &fakeTransportStream{}
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(ConsistencyHeaderKey, AdaptiveConsistency))
+	updated := ContextWithHandle(grpc.NewContextWithServerTransportStream(ctx, stream))
+	require.NoError(t, AddRevisionToContext(updated, &v1.CheckPermissionRequest{}, ds, adaptive))
+
+	rev, token, err := RevisionFromContext(updated)
+	require.NoError(t, err)
+	require.Equal(t, []string{token.Token}, stream.header.Get(string(RevisionHeaderKey)))
+	return rev, stream.header.Get(string(RevisionSourceHeaderKey))[0]
+}
+
+func TestAddRevisionToContextAdaptive(t *testing.T) {
+	adaptive := WithAdaptiveConsistency(time.Hour)
+
+	// Without any sample of the head, the staleness of the optimized revision is unknown.
+	ds := &proxy_test.MockDatastore{}
+	ds.On("OptimizedRevision").Return(optimized, nil).Once()
+	ds.On("HeadRevision").Return(head, nil).Once()
+	rev, source := addAdaptiveRevision(t, ds, adaptive)
+	require.True(t, head.Equal(rev))
+	require.Equal(t, "head", source)
+	ds.AssertExpectations(t)
+
+	// The optimized revision lags behind the sampled head.
+	ds.On("OptimizedRevision").Return(optimized, nil).Once()
+	ds.On("HeadRevision").Return(head, nil).Once()
+	rev, source = addAdaptiveRevision(t, ds, adaptive)
+	require.True(t, head.Equal(rev))
+	require.Equal(t, "head", source)
+	ds.AssertExpectations(t)
+
+	// Once the optimized revision has caught up with the sampled head, it is used.
+	ds.On("OptimizedRevision").Return(head, nil).Twice()
+	for i := 0; i < 2; i++ {
+		rev, source = addAdaptiveRevision(t, ds, adaptive)
+		require.True(t, head.Equal(rev))
+		require.Equal(t, "optimized", source)
+	}
+	ds.AssertExpectations(t)
+
+	// Datastores are tracked separately.
+	other := &proxy_test.MockDatastore{}
+	other.On("OptimizedRevision").Return(head, nil).Once()
+	other.On("HeadRevision").Return(head, nil).Once()
+	_, source = addAdaptiveRevision(t, other, adaptive)
+	require.Equal(t, "head", source)
+	other.AssertExpectations(t)
+}
+
+func TestAddRevisionToContextAdaptiveMaxStaleness(t *testing.T) {
+	adaptive := WithAdaptiveConsistency(10 * time.Millisecond)
+
+	ds := &proxy_test.MockDatastore{}
+	ds.On("OptimizedRevision").Return(optimized, nil).Times(3)
+	ds.On("HeadRevision").Return(optimized, nil).Twice()
+
+	_, source := addAdaptiveRevision(t, ds, adaptive)
+	require.Equal(t, "head", source)
+	_, source = addAdaptiveRevision(t, ds, adaptive)
+	require.Equal(t, "optimized", source)
+
+	// Without a recent sample, the optimized revision may have fallen too far behind.
+	time.Sleep(20 * time.Millisecond)
+	_, source = addAdaptiveRevision(t, ds, adaptive)
+	require.Equal(t, "head", source)
+	ds.AssertExpectations(t)
+}
+
+func TestAddRevisionToContextAdaptiveInvalid(t *testing.T) {
+	testCases := []struct {
+		name         string
+		md           metadata.MD
+		consistency  *v1.Consistency
+		opts         []Option
+		expectedCode codes.Code
+		expectedErr  string
+	}{
+		{
+			"unknown value",
+			metadata.Pairs(ConsistencyHeaderKey, "eventual"),
+			nil,
+			[]Option{WithAdaptiveConsistency(time.Second)},
+			codes.InvalidArgument,
+			"invalid value for io.spicedb.consistency",
+		},
+		{
+			"combined with a ZedToken",
+			metadata.Pairs(ConsistencyHeaderKey, AdaptiveConsistency),
+			&v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+			[]Option{WithAdaptiveConsistency(time.Second)},
+			codes.InvalidArgument,
+			"cannot be combined with a consistency other than minimize latency",
+		},
+		{
+			"combined with a point in time",
+			metadata.Pairs(ConsistencyHeaderKey, AdaptiveConsistency, AtTimeHeaderKey, time.Now().Add(-time.Hour).Format(time.RFC3339)),
+			nil,
+			[]Option{WithAdaptiveConsistency(time.Second)},
+			codes.InvalidArgument,
+			"cannot be combined with io.spicedb.attime",
+		},
+		{
+			"not enabled",
+			metadata.Pairs(ConsistencyHeaderKey, AdaptiveConsistency),
+			nil,
+			nil,
+			codes.FailedPrecondition,
+			"adaptive consistency is not enabled",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ds := &proxy_test.MockDatastore{}
+
+			ctx := metadata.NewIncomingContext(context.Background(), tc.md)
+			err := AddRevisionToContext(ContextWithHandle(ctx), &v1.CheckPermissionRequest{Consistency: tc.consistency}, ds, tc.opts...)
+			require.Equal(t, tc.expectedCode, status.Code(err))
+			require.ErrorContains(t, err, tc.expectedErr)
+			ds.AssertExpectations(t)
+		})
+	}
+}
+
+func TestLagTrackerObserve(t *testing.T) {
+	tracker := &lagTracker{}
+	start := time.Now()
+
+	// Samples of the same revision are merged, keeping the latest.
+	tracker.observe(optimized, start, time.Hour)
+	tracker.observe(optimized, start.Add(time.Second), time.Hour)
+	require.Len(t, tracker.samples, 1)
+	staleness, ok := tracker.staleness(optimized, start.Add(3*time.Second))
+	require.True(t, ok)
+	require.Equal(t, 2*time.Second, staleness)
+
+	_, ok = tracker.staleness(zero, start.Add(3*time.Second))
+	require.False(t, ok)
+
+	// Samples too old to bound any staleness within the maximum are forgotten.
+	tracker.observe(exact, start.Add(2*time.Hour), time.Hour)
+	require.Len(t, tracker.samples, 1)
+
+	for i := 0; i < 2*maxHeadSamples; i++ {
+		tracker.observe(revisions.NewForTransactionID(uint64(200+i)), start.Add(2*time.Hour+time.Duration(i)), time.Hour)
+	}
+	require.Len(t, tracker.samples, maxHeadSamples)
+}
@@ -0,0 +1,141 @@
+package deadline
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	middleware "github.com/grpc-ecosystem/go-grpc-middleware/v2"
+	"google.golang.org/grpc"
+)
+
+// Class is a class of API methods which share their timeouts.
+type Class string
+
+const (
+	// ClassRead is the class of expansions, reads of the schema and other reads which are not
+	// of another class.
+	ClassRead Class = "read"
+
+	// ClassCheck is the class of permission checks. Checks are given the timeouts of the read
+	// class unless given their own.
+	ClassCheck Class = "check"
+
+	// ClassWrite is the class of writes and deletes of relationships and of the schema.
+	ClassWrite Class = "write"
+
+	// ClassLookup is the class of lookups, reads of relationships and exports, which stream
+	// their results and so take longer than other reads.
+	ClassLookup Class = "lookup"
+
+	// ClassWatch is the class of watches, which are long-lived and so are only given a
+	// deadline when given their own timeouts.
+	ClassWatch Class = "watch"
+)
+
+// checkMethodPrefixes are the prefixes of the names of API methods in the check class.
+var checkMethodPrefixes = []string{"Check", "BulkCheck"}
+
+// writeMethodPrefixes are the prefixes of the names of API methods in the write class.
+var writeMethodPrefixes = []string{"Write", "Delete", "BulkImport", "ImportBulk"}
+
+// lookupMethodPrefixes are the prefixes of the names of API methods in the lookup class.
+var lookupMethodPrefixes = []string{"Lookup", "ReadRelationships", "BulkExport", "ExportBulk"}
+
+// Timeouts are the timeouts of a class of API methods.
+type Timeouts struct {
+	// Default is the timeout of calls made without a deadline. Zero leaves such calls
+	// without a deadline.
+	Default time.Duration
+
+	// Max is the longest timeout of calls, which shortens the deadline of calls made with a
+	// later one. Zero does not limit deadlines.
+	Max time.Duration
+}
+
+// ParseClass returns the class of the given name, such as "read".
+func ParseClass(name string) (Class, error) {
+	switch class := Class(name); class {
+	case ClassRead, ClassCheck, ClassWrite, ClassLookup, ClassWatch:
+		return class, nil
+	default:
+		return "", fmt.Errorf("unknown API method class `%s`: expected %s, %s, %s, %s or %s", name, ClassRead, ClassCheck, ClassWrite, ClassLookup, ClassWatch)
+	}
+}
+
+// ClassFor returns the class of the API method.
+func ClassFor(fullMethod string) Class {
+	method := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+	if strings.HasPrefix(method, "Watch") {
+		return ClassWatch
+	}
+
+	for _, prefix := range checkMethodPrefixes {
+		if strings.HasPrefix(method, prefix) {
+			return ClassCheck
+		}
+	}
+	for _, prefix := range writeMethodPrefixes {
+		if strings.HasPrefix(method, prefix) {
+			return ClassWrite
+		}
+	}
+	for _, prefix := range lookupMethodPrefixes {
+		if strings.HasPrefix(method, prefix) {
+			return ClassLookup
+		}
+	}
+	return ClassRead
+}
+
+// withTimeout returns the context of a call of the method, bounded by the timeouts of the
+// class of the method.
+func withTimeout(ctx context.Context, fullMethod string, timeouts map[Class]Timeouts) (context.Context, context.CancelFunc) {
+	class := ClassFor(fullMethod)
+	classTimeouts, ok := timeouts[class]
+	if !ok && class == ClassCheck {
+		classTimeouts = timeouts[ClassRead]
+	}
+
+	timeout := classTimeouts.Default
+	if deadline, ok := ctx.Deadline(); ok {
+		// The deadline of the caller is kept, unless it is later than the maximum.
+		timeout = 0
+		if classTimeouts.Max > 0 && time.Until(deadline) > classTimeouts.Max {
+			timeout = classTimeouts.Max
+		}
+	} else if classTimeouts.Max > 0 && (timeout <= 0 || timeout > classTimeouts.Max) {
+		timeout = classTimeouts.Max
+	}
+
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// UnaryServerInterceptor returns a new interceptor which bounds each call by the timeouts of
+// the class of its method: calls without a deadline are given the default timeout, and the
+// deadline of calls is shortened to the maximum timeout.
+func UnaryServerInterceptor(timeouts map[Class]Timeouts) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, cancel := withTimeout(ctx, info.FullMethod, timeouts)
+		defer cancel()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a new interceptor which bounds each stream by the timeouts
+// of the class of its method: streams without a deadline are given the default timeout, and
+// the deadline of streams is shortened to the maximum timeout.
+func StreamServerInterceptor(timeouts map[Class]Timeouts) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, cancel := withTimeout(stream.Context(), info.FullMethod, timeouts)
+		defer cancel()
+
+		wrapped := middleware.WrapServerStream(stream)
+		wrapped.WrappedContext = ctx
+		return handler(srv, wrapped)
+	}
+}
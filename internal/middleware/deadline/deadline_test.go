@@ -0,0 +1,140 @@
+package deadline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+const (
+	checkMethod  = "/authzed.api.v1.PermissionsService/CheckPermission"
+	lookupMethod = "/authzed.api.v1.PermissionsService/LookupResources"
+	watchMethod  = "/authzed.api.v1.WatchService/Watch"
+)
+
+func TestClassFor(t *testing.T) {
+	for method, expected := range map[string]Class{
+		"/authzed.api.v1.PermissionsService/CheckPermission":          ClassCheck,
+		"/authzed.api.v1.PermissionsService/CheckBulkPermissions":     ClassCheck,
+		"/authzed.api.v1.ExperimentalService/BulkCheckPermission":     ClassCheck,
+		"/authzed.api.v1.PermissionsService/ExpandPermissionTree":     ClassRead,
+		"/authzed.api.v1.SchemaService/ReadSchema":                    ClassRead,
+		"/authzed.api.v1.PermissionsService/WriteRelationships":       ClassWrite,
+		"/authzed.api.v1.PermissionsService/DeleteRelationships":      ClassWrite,
+		"/authzed.api.v1.SchemaService/WriteSchema":                   ClassWrite,
+		"/authzed.api.v1.PermissionsService/ReadRelationships":        ClassLookup,
+		"/authzed.api.v1.PermissionsService/LookupResources":          ClassLookup,
+		"/authzed.api.v1.PermissionsService/LookupSubjects":           ClassLookup,
+		"/authzed.api.v1.ExperimentalService/BulkExportRelationships": ClassLookup,
+		"/authzed.api.v1.ExperimentalService/BulkImportRelationships": ClassWrite,
+		"/authzed.api.v1.WatchService/Watch":                          ClassWatch,
+	} {
+		t.Run(method, func(t *testing.T) {
+			require.Equal(t, expected, ClassFor(method))
+		})
+	}
+}
+
+func TestParseClass(t *testing.T) {
+	class, err := ParseClass("lookup")
+	require.NoError(t, err)
+	require.Equal(t, ClassLookup, class)
+
+	_, err = ParseClass("export")
+	require.ErrorContains(t, err, "unknown API method class `export`")
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	timeouts := map[Class]Timeouts{
+		ClassRead:   {Default: time.Second, Max: time.Minute},
+		ClassLookup: {Max: time.Minute},
+	}
+
+	tcs := []struct {
+		name             string
+		method           string
+		callerTimeout    time.Duration
+		expectedDeadline bool
+		expectedTimeout  time.Duration
+	}{
+		{"default timeout without deadline", checkMethod, 0, true, time.Second},
+		{"deadline of the caller is kept", checkMethod, 10 * time.Second, true, 10 * time.Second},
+		{"deadline of the caller is shortened", checkMethod, time.Hour, true, time.Minute},
+		{"maximum timeout without deadline", lookupMethod, 0, true, time.Minute},
+		{"class without timeouts", "/authzed.api.v1.PermissionsService/WriteRelationships", 0, false, 0},
+		{"watch without timeouts", watchMethod, 0, false, 0},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			requireDeadline(t, timeouts, tc.method, tc.callerTimeout, tc.expectedDeadline, tc.expectedTimeout)
+		})
+	}
+}
+
+func TestUnaryServerInterceptorClassTimeouts(t *testing.T) {
+	timeouts := map[Class]Timeouts{
+		ClassRead:  {Default: time.Second},
+		ClassWatch: {Default: time.Hour},
+	}
+
+	// Checks are given the timeouts of the read class unless given their own.
+	requireDeadline(t, timeouts, checkMethod, 0, true, time.Second)
+	requireDeadline(t, timeouts, watchMethod, 0, true, time.Hour)
+
+	timeouts[ClassCheck] = Timeouts{Max: time.Minute}
+	requireDeadline(t, timeouts, checkMethod, 0, true, time.Minute)
+}
+
+func requireDeadline(t *testing.T, timeouts map[Class]Timeouts, method string, callerTimeout time.Duration, expectedDeadline bool, expectedTimeout time.Duration) {
+	t.Helper()
+
+	ctx := context.Background()
+	if callerTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, callerTimeout)
+		defer cancel()
+	}
+
+	var handlerCtx context.Context
+	_, err := UnaryServerInterceptor(timeouts)(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, _ any) (any, error) {
+		handlerCtx = ctx
+		return nil, nil
+	})
+	require.NoError(t, err)
+
+	deadline, ok := handlerCtx.Deadline()
+	require.Equal(t, expectedDeadline, ok)
+	if expectedDeadline {
+		require.WithinDuration(t, time.Now().Add(expectedTimeout), deadline, time.Second)
+	}
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	timeouts := map[Class]Timeouts{ClassLookup: {Default: time.Second}}
+
+	stream := &mockServerStream{ctx: context.Background()}
+	var handlerCtx context.Context
+	err := StreamServerInterceptor(timeouts)(nil, stream, &grpc.StreamServerInfo{FullMethod: lookupMethod}, func(_ any, stream grpc.ServerStream) error {
+		handlerCtx = stream.Context()
+		return nil
+	})
+	require.NoError(t, err)
+
+	deadline, ok := handlerCtx.Deadline()
+	require.True(t, ok)
+	require.WithinDuration(t, time.Now().Add(time.Second), deadline, time.Second)
+
+	// The context is canceled once the stream is done.
+	require.ErrorIs(t, handlerCtx.Err(), context.Canceled)
+}
+
+type mockServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (m *mockServerStream) Context() context.Context { return m.ctx }
@@ -0,0 +1,367 @@
+// Package debuglog changes the level of logging while the server runs, either globally or to
+// debug the API calls of given methods or namespaces, until the change expires, so that
+// incidents can be diagnosed in production without redeploying.
+//
+// Logs below the global level of zerolog are dropped by every logger, so debugging targeted
+// calls lowers the global level to debug while raising the level of the global logger, from
+// which the loggers of other calls are derived, to the level otherwise in effect. Loggers
+// derived from the global logger before the change keep their own level, and may log at debug
+// while targeted debugging is in effect.
+package debuglog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/authzed/grpcutil"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/middleware/namespacemetrics"
+	logmw "github.com/authzed/spicedb/pkg/middleware/logging"
+)
+
+// DefaultDuration is the duration of overrides for which none is given.
+const DefaultDuration = 10 * time.Minute
+
+// Target selects the API calls logged at debug level: those of the method, named in full,
+// such as `/authzed.api.v1.PermissionsService/CheckPermission`, or by its name alone, such as
+// `CheckPermission`, and those whose requests are for objects of the namespace. When both are
+// given, calls must match both.
+type Target struct {
+	Method    string `json:"method,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+func (t Target) matches(fullMethod string, req any) bool {
+	if t.Method != "" && t.Method != fullMethod {
+		if _, method := grpcutil.SplitMethodName(fullMethod); t.Method != method {
+			return false
+		}
+	}
+	if t.Namespace != "" {
+		namespace, ok := namespacemetrics.RequestNamespace(req)
+		if !ok || namespace != t.Namespace {
+			return false
+		}
+	}
+	return true
+}
+
+type expiringTarget struct {
+	Target
+	until time.Time
+}
+
+// Overrides are the changes to the level of logging in effect, each until it expires.
+type Overrides struct {
+	maxDuration time.Duration
+	now         func() time.Time
+
+	mu sync.Mutex
+
+	// logger and level are those in effect when no override is, which are restored once
+	// every override has expired.
+	logger zerolog.Logger
+	level  zerolog.Level
+
+	// applied is the global level set by the overrides, if any are in effect.
+	active  bool
+	applied zerolog.Level
+
+	levelOverride *LevelOverride
+	targets       map[Target]time.Time
+	timer         *time.Timer
+
+	// current holds the targets in effect, read by the interceptors without locking.
+	current atomic.Pointer[[]expiringTarget]
+}
+
+// LevelOverride is a change to the global level of logging.
+type LevelOverride struct {
+	Level zerolog.Level `json:"level"`
+	Until time.Time     `json:"until"`
+}
+
+// NewOverrides creates the overrides of the level of logging, each in effect for at most the
+// given duration.
+func NewOverrides(maxDuration time.Duration) *Overrides {
+	return &Overrides{
+		maxDuration: maxDuration,
+		now:         time.Now,
+		targets:     make(map[Target]time.Time),
+	}
+}
+
+func (o *Overrides) validDuration(duration time.Duration) (time.Duration, error) {
+	if duration == 0 {
+		duration = min(DefaultDuration, o.maxDuration)
+	}
+	if duration <= 0 {
+		return 0, errors.New("duration must be positive")
+	}
+	if duration > o.maxDuration {
+		return 0, fmt.Errorf("duration must be at most %s", o.maxDuration)
+	}
+	return duration, nil
+}
+
+// SetLevel changes the global level of logging for the given duration, or DefaultDuration if
+// zero, replacing any previous change.
+func (o *Overrides) SetLevel(level zerolog.Level, duration time.Duration) (*LevelOverride, error) {
+	duration, err := o.validDuration(duration)
+	if err != nil {
+		return nil, err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.capture()
+	o.levelOverride = &LevelOverride{Level: level, Until: o.now().Add(duration)}
+	o.apply()
+
+	log.Info().Stringer("level", level).Dur("duration", duration).Msg("log level overridden")
+	return o.levelOverride, nil
+}
+
+// AddTarget logs the API calls selected by the target at debug level for the given duration,
+// or DefaultDuration if zero.
+func (o *Overrides) AddTarget(target Target, duration time.Duration) (time.Time, error) {
+	if target.Method == "" && target.Namespace == "" {
+		return time.Time{}, errors.New("a method or namespace is required")
+	}
+	duration, err := o.validDuration(duration)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.capture()
+	until := o.now().Add(duration)
+	o.targets[target] = until
+	o.apply()
+
+	log.Info().Str("method", target.Method).Str("namespace", target.Namespace).Dur("duration", duration).Msg("debug logging enabled for API calls")
+	return until, nil
+}
+
+// Reset removes every override, restoring the level of logging in effect before them.
+func (o *Overrides) Reset() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.levelOverride = nil
+	clear(o.targets)
+	o.apply()
+}
+
+// capture records the logger and level in effect, to be restored once the overrides expire,
+// when none is in effect yet. A level changed since the overrides applied theirs, such as by
+// reloading the configuration, becomes the level restored.
+func (o *Overrides) capture() {
+	if !o.active {
+		o.logger = log.Logger
+		o.level = zerolog.GlobalLevel()
+		return
+	}
+	if level := zerolog.GlobalLevel(); level != o.applied {
+		o.level = level
+	}
+}
+
+// apply removes the expired overrides, and applies the level of logging of those remaining.
+func (o *Overrides) apply() {
+	now := o.now()
+	if o.levelOverride != nil && !now.Before(o.levelOverride.Until) {
+		o.levelOverride = nil
+	}
+
+	var current []expiringTarget
+	for target, until := range o.targets {
+		if !now.Before(until) {
+			delete(o.targets, target)
+			continue
+		}
+		current = append(current, expiringTarget{target, until})
+	}
+	o.current.Store(&current)
+
+	if o.timer != nil {
+		o.timer.Stop()
+		o.timer = nil
+	}
+
+	if o.levelOverride == nil && len(current) == 0 {
+		if o.active {
+			o.active = false
+			zerolog.SetGlobalLevel(o.level)
+			log.SetGlobalLogger(o.logger)
+			log.Info().Stringer("level", o.level).Msg("log level overrides expired")
+		}
+		return
+	}
+
+	untargeted := o.level
+	next := time.Time{}
+	if o.levelOverride != nil {
+		untargeted = o.levelOverride.Level
+		next = o.levelOverride.Until
+	}
+	for _, target := range current {
+		if next.IsZero() || target.until.Before(next) {
+			next = target.until
+		}
+	}
+
+	global := untargeted
+	if len(current) > 0 {
+		global = min(global, zerolog.DebugLevel)
+	}
+
+	o.active = true
+	o.applied = global
+	zerolog.SetGlobalLevel(global)
+	log.SetGlobalLogger(o.logger.Level(untargeted))
+
+	o.timer = time.AfterFunc(next.Sub(now), func() {
+		o.mu.Lock()
+		defer o.mu.Unlock()
+		o.apply()
+	})
+}
+
+// State is the state of the overrides.
+type State struct {
+	// Level is the global level of logging in effect.
+	Level zerolog.Level `json:"level"`
+
+	// LevelOverride is the change to the global level in effect, if any.
+	LevelOverride *LevelOverride `json:"level_override,omitempty"`
+
+	// Targets are the API calls logged at debug level.
+	Targets []TargetState `json:"targets"`
+}
+
+// TargetState is a target of debug logging in effect.
+type TargetState struct {
+	Target
+	Until time.Time `json:"until"`
+}
+
+// State returns the state of the overrides.
+func (o *Overrides) State() State {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	state := State{Level: zerolog.GlobalLevel(), LevelOverride: o.levelOverride, Targets: []TargetState{}}
+	if o.active && o.levelOverride == nil {
+		state.Level = o.level
+	}
+	for target, until := range o.targets {
+		state.Targets = append(state.Targets, TargetState{target, until})
+	}
+	sort.Slice(state.Targets, func(i, j int) bool {
+		return state.Targets[i].Until.Before(state.Targets[j].Until)
+	})
+	return state
+}
+
+// targeted returns whether the call is selected by a target in effect.
+func (o *Overrides) targeted(fullMethod string, req any) bool {
+	current := o.current.Load()
+	if current == nil || len(*current) == 0 {
+		return false
+	}
+
+	now := o.now()
+	for _, target := range *current {
+		if now.Before(target.until) && target.matches(fullMethod, req) {
+			return true
+		}
+	}
+	return false
+}
+
+// debugContext returns the context of a targeted call, whose logger logs at debug level, and
+// which is logged regardless of sampling.
+func debugContext(ctx context.Context) context.Context {
+	ctx = log.Ctx(ctx).Level(zerolog.DebugLevel).WithContext(ctx)
+	return logmw.WithSampled(ctx)
+}
+
+// UnaryServerInterceptor returns an interceptor logging the calls selected by the targets of
+// the overrides at debug level. Nil overrides log no calls at debug level.
+func UnaryServerInterceptor(o *Overrides) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if o != nil && o.targeted(info.FullMethod, req) {
+			ctx = debugContext(ctx)
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns an interceptor logging the streams selected by the targets
+// of the overrides at debug level. Streams are selected by namespace once their first request
+// is received. Nil overrides log no streams at debug level.
+func StreamServerInterceptor(o *Overrides) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if o == nil {
+			return handler(srv, stream)
+		}
+		if o.targeted(info.FullMethod, nil) {
+			return handler(srv, &debugStream{ServerStream: stream, ctx: debugContext(stream.Context())})
+		}
+		return handler(srv, &targetingStream{ServerStream: stream, ctx: stream.Context(), overrides: o, fullMethod: info.FullMethod})
+	}
+}
+
+type debugStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *debugStream) Context() context.Context { return s.ctx }
+
+// targetingStream selects the stream by its first request, after which its context logs at
+// debug level if the request is targeted.
+type targetingStream struct {
+	grpc.ServerStream
+	overrides  *Overrides
+	fullMethod string
+
+	mu       sync.Mutex
+	ctx      context.Context
+	received bool
+}
+
+func (s *targetingStream) Context() context.Context {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.ctx
+}
+
+func (s *targetingStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.received {
+		s.received = true
+		if s.overrides.targeted(s.fullMethod, m) {
+			s.ctx = debugContext(s.ctx)
+		}
+	}
+	return nil
+}
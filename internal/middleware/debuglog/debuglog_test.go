@@ -0,0 +1,213 @@
+package debuglog
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	logmw "github.com/authzed/spicedb/pkg/middleware/logging"
+)
+
+const checkMethod = "/authzed.api.v1.PermissionsService/CheckPermission"
+
+// withInfoLevel sets the global level to info for the test, restoring the global level and
+// logger after it.
+func withInfoLevel(t *testing.T) {
+	level, logger := zerolog.GlobalLevel(), log.Logger
+	t.Cleanup(func() {
+		zerolog.SetGlobalLevel(level)
+		log.SetGlobalLogger(logger)
+	})
+	zerolog.SetGlobalLevel(zerolog.InfoLevel)
+	log.SetGlobalLogger(zerolog.New(nil))
+}
+
+func checkRequest(namespace string) *v1.CheckPermissionRequest {
+	return &v1.CheckPermissionRequest{Resource: &v1.ObjectReference{ObjectType: namespace, ObjectId: "1"}}
+}
+
+// callLevel returns the level of the logger of a unary call, and whether it is logged
+// regardless of sampling.
+func callLevel(t *testing.T, o *Overrides, fullMethod string, req any) (zerolog.Level, bool) {
+	var level zerolog.Level
+	var sampled bool
+	_, err := UnaryServerInterceptor(o)(context.Background(), req, &grpc.UnaryServerInfo{FullMethod: fullMethod}, func(ctx context.Context, _ any) (any, error) {
+		level = log.Ctx(ctx).GetLevel()
+		sampled = logmw.IsSampled(ctx)
+		return nil, nil
+	})
+	require.NoError(t, err)
+	return level, sampled
+}
+
+func TestSetLevel(t *testing.T) {
+	withInfoLevel(t)
+
+	o := NewOverrides(time.Hour)
+	_, err := o.SetLevel(zerolog.DebugLevel, 2*time.Hour)
+	require.ErrorContains(t, err, "at most 1h0m0s")
+
+	override, err := o.SetLevel(zerolog.DebugLevel, 50*time.Millisecond)
+	require.NoError(t, err)
+	require.Equal(t, zerolog.DebugLevel, override.Level)
+	require.Equal(t, zerolog.DebugLevel, zerolog.GlobalLevel())
+	require.Equal(t, zerolog.DebugLevel, o.State().Level)
+
+	require.Eventually(t, func() bool { return zerolog.GlobalLevel() == zerolog.InfoLevel }, time.Second, 5*time.Millisecond)
+	require.Nil(t, o.State().LevelOverride)
+}
+
+func TestAddTarget(t *testing.T) {
+	withInfoLevel(t)
+
+	o := NewOverrides(time.Hour)
+	_, err := o.AddTarget(Target{}, time.Minute)
+	require.Error(t, err)
+
+	_, err = o.AddTarget(Target{Method: "CheckPermission"}, time.Minute)
+	require.NoError(t, err)
+	_, err = o.AddTarget(Target{Method: "/authzed.api.v1.PermissionsService/ExpandPermissionTree", Namespace: "folder"}, time.Minute)
+	require.NoError(t, err)
+	_, err = o.AddTarget(Target{Namespace: "document"}, time.Minute)
+	require.NoError(t, err)
+
+	// Only targeted calls log at debug level, while others log at the level otherwise in effect.
+	require.Equal(t, zerolog.DebugLevel, zerolog.GlobalLevel())
+	require.Equal(t, zerolog.InfoLevel, log.Logger.GetLevel())
+	require.Equal(t, zerolog.InfoLevel, o.State().Level)
+	require.Len(t, o.State().Targets, 3)
+
+	tcs := []struct {
+		name          string
+		fullMethod    string
+		req           any
+		expectedLevel zerolog.Level
+	}{
+		{"method", checkMethod, checkRequest("user"), zerolog.DebugLevel},
+		{"method and namespace", "/authzed.api.v1.PermissionsService/ExpandPermissionTree", &v1.ExpandPermissionTreeRequest{Resource: &v1.ObjectReference{ObjectType: "folder"}}, zerolog.DebugLevel},
+		{"method without namespace", "/authzed.api.v1.PermissionsService/ExpandPermissionTree", &v1.ExpandPermissionTreeRequest{Resource: &v1.ObjectReference{ObjectType: "user"}}, zerolog.InfoLevel},
+		{"namespace", "/authzed.api.v1.PermissionsService/ExpandPermissionTree", &v1.ExpandPermissionTreeRequest{Resource: &v1.ObjectReference{ObjectType: "document"}}, zerolog.DebugLevel},
+		{"untargeted", "/authzed.api.v1.SchemaService/ReadSchema", &v1.ReadSchemaRequest{}, zerolog.InfoLevel},
+	}
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			level, sampled := callLevel(t, o, tc.fullMethod, tc.req)
+			require.Equal(t, tc.expectedLevel, level)
+			require.True(t, sampled)
+		})
+	}
+
+	o.Reset()
+	require.Equal(t, zerolog.InfoLevel, zerolog.GlobalLevel())
+	require.Equal(t, zerolog.TraceLevel, log.Logger.GetLevel())
+	level, _ := callLevel(t, o, checkMethod, checkRequest("document"))
+	require.Equal(t, zerolog.TraceLevel, level)
+}
+
+func TestTargetExpires(t *testing.T) {
+	withInfoLevel(t)
+
+	o := NewOverrides(time.Hour)
+	_, err := o.SetLevel(zerolog.WarnLevel, time.Minute)
+	require.NoError(t, err)
+	_, err = o.AddTarget(Target{Method: "CheckPermission"}, 50*time.Millisecond)
+	require.NoError(t, err)
+	require.Equal(t, zerolog.DebugLevel, zerolog.GlobalLevel())
+	require.Equal(t, zerolog.WarnLevel, log.Logger.GetLevel())
+
+	// Once the target expires, the level override remains in effect.
+	require.Eventually(t, func() bool { return zerolog.GlobalLevel() == zerolog.WarnLevel }, time.Second, 5*time.Millisecond)
+	require.Empty(t, o.State().Targets)
+	level, _ := callLevel(t, o, checkMethod, checkRequest("document"))
+	require.Equal(t, zerolog.WarnLevel, level)
+
+	o.Reset()
+	require.Equal(t, zerolog.InfoLevel, zerolog.GlobalLevel())
+}
+
+type recvStream struct {
+	grpc.ServerStream
+	req *v1.LookupResourcesRequest
+}
+
+func (s *recvStream) Context() context.Context { return context.Background() }
+
+func (s *recvStream) RecvMsg(m any) error {
+	proto.Merge(m.(*v1.LookupResourcesRequest), s.req)
+	return nil
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	withInfoLevel(t)
+
+	o := NewOverrides(time.Hour)
+	_, err := o.AddTarget(Target{Namespace: "document"}, time.Minute)
+	require.NoError(t, err)
+
+	for namespace, expectedLevel := range map[string]zerolog.Level{"document": zerolog.DebugLevel, "folder": zerolog.InfoLevel} {
+		stream := &recvStream{req: &v1.LookupResourcesRequest{ResourceObjectType: namespace}}
+		err := StreamServerInterceptor(o)(nil, stream, &grpc.StreamServerInfo{FullMethod: "/authzed.api.v1.PermissionsService/LookupResources"}, func(_ any, stream grpc.ServerStream) error {
+			require.Equal(t, zerolog.InfoLevel, log.Ctx(stream.Context()).GetLevel())
+
+			var req v1.LookupResourcesRequest
+			require.NoError(t, stream.RecvMsg(&req))
+			require.Equal(t, expectedLevel, log.Ctx(stream.Context()).GetLevel())
+			return nil
+		})
+		require.NoError(t, err)
+	}
+}
+
+func TestHandler(t *testing.T) {
+	withInfoLevel(t)
+
+	mux := http.NewServeMux()
+	NewOverrides(time.Hour).RegisterHandlers(mux)
+
+	request := func(method, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(method, handlerPath, strings.NewReader(body)))
+		return recorder
+	}
+
+	tcs := []struct {
+		name         string
+		body         string
+		expectedCode int
+	}{
+		{"invalid body", `{`, http.StatusBadRequest},
+		{"missing level", `{}`, http.StatusBadRequest},
+		{"invalid level", `{"level": "loud"}`, http.StatusBadRequest},
+		{"invalid duration", `{"level": "debug", "duration": "soon"}`, http.StatusBadRequest},
+		{"duration over maximum", `{"level": "debug", "duration": "2h"}`, http.StatusBadRequest},
+		{"targeted level", `{"method": "CheckPermission", "level": "warn"}`, http.StatusBadRequest},
+		{"level", `{"level": "debug", "duration": "5m"}`, http.StatusOK},
+		{"target", `{"namespace": "document"}`, http.StatusOK},
+	}
+	for _, tc := range tcs {
+		require.Equal(t, tc.expectedCode, request(http.MethodPost, tc.body).Code, tc.name)
+	}
+
+	recorder := request(http.MethodGet, "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Contains(t, recorder.Body.String(), `"namespace": "document"`)
+	require.Contains(t, recorder.Body.String(), `"level": "debug"`)
+
+	require.Equal(t, http.StatusOK, request(http.MethodDelete, "").Code)
+	require.Equal(t, zerolog.InfoLevel, zerolog.GlobalLevel())
+
+	recorder = request(http.MethodPut, "")
+	require.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+	require.Equal(t, "GET, POST, DELETE", recorder.Header().Get("Allow"))
+}
@@ -0,0 +1,101 @@
+package debuglog
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+const handlerPath = "/debug/loglevel"
+
+// Request is the body of a request changing the level of logging. A request with a method or
+// namespace logs the API calls it selects at debug level; otherwise, it changes the global
+// level of logging.
+type Request struct {
+	Target
+
+	// Level is the global level of logging, such as `debug`.
+	Level string `json:"level,omitempty"`
+
+	// Duration is the duration of the change, such as `15m`, after which it is reverted.
+	Duration string `json:"duration,omitempty"`
+}
+
+// RegisterHandlers registers the handler of the overrides on the mux: a GET of the path
+// returns their state, a POST of a Request adds one, and a DELETE removes them all.
+func (o *Overrides) RegisterHandlers(mux *http.ServeMux) {
+	if o == nil {
+		return
+	}
+
+	mux.HandleFunc(handlerPath, func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			writeJSON(w, http.StatusOK, o.State())
+
+		case http.MethodPost:
+			var req Request
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				http.Error(w, fmt.Sprintf("invalid log level request: %s", err), http.StatusBadRequest)
+				return
+			}
+			if err := o.handle(req); err != nil {
+				http.Error(w, fmt.Sprintf("invalid log level request: %s", err), http.StatusBadRequest)
+				return
+			}
+			writeJSON(w, http.StatusOK, o.State())
+
+		case http.MethodDelete:
+			o.Reset()
+			writeJSON(w, http.StatusOK, o.State())
+
+		default:
+			w.Header().Set("Allow", "GET, POST, DELETE")
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func (o *Overrides) handle(req Request) error {
+	var duration time.Duration
+	if req.Duration != "" {
+		var err error
+		duration, err = time.ParseDuration(req.Duration)
+		if err != nil {
+			return err
+		}
+	}
+
+	if req.Method != "" || req.Namespace != "" {
+		if req.Level != "" && req.Level != zerolog.LevelDebugValue {
+			return fmt.Errorf("targeted calls are logged at debug level, got `%s`", req.Level)
+		}
+		_, err := o.AddTarget(req.Target, duration)
+		return err
+	}
+
+	if req.Level == "" {
+		return fmt.Errorf("a level, method or namespace is required")
+	}
+	level, err := zerolog.ParseLevel(req.Level)
+	if err != nil {
+		return err
+	}
+	_, err = o.SetLevel(level, duration)
+	return err
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	encoded, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "%s\n", encoded)
+}
@@ -0,0 +1,183 @@
+// Package decisionlog implements middleware which logs the decisions of permission checks as
+// events in the format of Open Policy Agent's decision logs, so that they can be consumed by
+// pipelines built for OPA.
+package decisionlog
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/google/uuid"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	log "github.com/authzed/spicedb/internal/logging"
+)
+
+// RevisionBundle is the name of the bundle whose revision is the revision at which a check
+// was evaluated.
+const RevisionBundle = "spicedb"
+
+// checkMethods are the methods whose decisions are logged.
+var checkMethods = map[string]struct{}{
+	v1.PermissionsService_CheckPermission_FullMethodName:      {},
+	v1.ExperimentalService_BulkCheckPermission_FullMethodName: {},
+}
+
+// Decision is the log event of a single check, in the format of OPA's decision log events.
+type Decision struct {
+	// Labels identify the SpiceDB instance which made the decision.
+	Labels map[string]string `json:"labels"`
+
+	// DecisionID uniquely identifies the decision.
+	DecisionID string `json:"decision_id"`
+
+	// TraceID and SpanID identify the span of the request, if it was traced.
+	TraceID string `json:"trace_id,omitempty"`
+	SpanID  string `json:"span_id,omitempty"`
+
+	// Path is the gRPC method called, with its components separated by slashes.
+	Path string `json:"path"`
+
+	// Input is the request, as JSON.
+	Input json.RawMessage `json:"input"`
+
+	// Result is the response, as JSON, if the check succeeded.
+	Result json.RawMessage `json:"result,omitempty"`
+
+	// Error is the error of the check, if it failed.
+	Error *Error `json:"error,omitempty"`
+
+	// Bundles holds the revision at which the check was evaluated, as the revision of the
+	// RevisionBundle bundle.
+	Bundles map[string]Bundle `json:"bundles,omitempty"`
+
+	// RequestedBy is the address of the caller.
+	RequestedBy string `json:"requested_by"`
+
+	// Timestamp is when the decision was made.
+	Timestamp time.Time `json:"timestamp"`
+
+	// Metrics holds the latency of the check as `timer_server_handler_ns`.
+	Metrics map[string]int64 `json:"metrics"`
+}
+
+// Error is the error of a failed check.
+type Error struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Bundle holds the revision at which a check was evaluated.
+type Bundle struct {
+	Revision string `json:"revision"`
+}
+
+// Sink receives decisions.
+type Sink interface {
+	// Log records the decision. It must not block the request.
+	Log(decision *Decision)
+
+	// Close sends the decisions which have not been sent yet and releases the resources
+	// held by the sink.
+	Close() error
+}
+
+// Logger logs the decisions of checks to a sink.
+type Logger struct {
+	sink   Sink
+	labels map[string]string
+}
+
+// NewLogger returns a logger sending decisions, labeled with the labels, to the sink. OPA
+// labels decisions with the `id` of the instance and its `version`.
+func NewLogger(sink Sink, labels map[string]string) *Logger {
+	return &Logger{sink: sink, labels: labels}
+}
+
+// Close closes the sink of the logger.
+func (l *Logger) Close() error {
+	return l.sink.Close()
+}
+
+// UnaryServerInterceptor returns a new unary server interceptor which logs the decision of
+// every check. A nil logger disables decision logging.
+func UnaryServerInterceptor(logger *Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if logger == nil {
+			return handler(ctx, req)
+		}
+		if _, ok := checkMethods[info.FullMethod]; !ok {
+			return handler(ctx, req)
+		}
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logger.log(ctx, info.FullMethod, req, resp, err, start)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a new stream server interceptor. Checks are unary, so no
+// decisions are logged for streams.
+func StreamServerInterceptor(_ *Logger) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, stream)
+	}
+}
+
+func (l *Logger) log(ctx context.Context, method string, req, resp any, err error, start time.Time) {
+	decision := &Decision{
+		Labels:     l.labels,
+		DecisionID: uuid.NewString(),
+		Path:       strings.ReplaceAll(strings.TrimPrefix(method, "/"), ".", "/"),
+		Timestamp:  start.UTC(),
+		Metrics:    map[string]int64{"timer_server_handler_ns": time.Since(start).Nanoseconds()},
+	}
+
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+		decision.TraceID = spanContext.TraceID().String()
+		decision.SpanID = spanContext.SpanID().String()
+	}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		decision.RequestedBy = p.Addr.String()
+	}
+
+	input, merr := marshal(req)
+	if merr != nil {
+		log.Ctx(ctx).Warn().Err(merr).Str("method", method).Msg("failed to encode the input of a decision")
+		return
+	}
+	decision.Input = input
+
+	if err != nil {
+		decision.Error = &Error{Code: status.Code(err).String(), Message: status.Convert(err).Message()}
+	} else {
+		result, merr := marshal(resp)
+		if merr != nil {
+			log.Ctx(ctx).Warn().Err(merr).Str("method", method).Msg("failed to encode the result of a decision")
+			return
+		}
+		decision.Result = result
+
+		if checked, ok := resp.(interface{ GetCheckedAt() *v1.ZedToken }); ok && checked.GetCheckedAt() != nil {
+			decision.Bundles = map[string]Bundle{RevisionBundle: {Revision: checked.GetCheckedAt().Token}}
+		}
+	}
+
+	l.sink.Log(decision)
+}
+
+func marshal(msg any) (json.RawMessage, error) {
+	if msg, ok := msg.(proto.Message); ok {
+		return protojson.Marshal(msg)
+	}
+	return json.Marshal(msg)
+}
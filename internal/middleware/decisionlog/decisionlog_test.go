@@ -0,0 +1,158 @@
+package decisionlog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+type memorySink struct {
+	sync.Mutex
+	decisions []*Decision
+}
+
+func (ms *memorySink) Log(decision *Decision) {
+	ms.Lock()
+	defer ms.Unlock()
+	ms.decisions = append(ms.decisions, decision)
+}
+
+func (ms *memorySink) Close() error { return nil }
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	sink := &memorySink{}
+	interceptor := UnaryServerInterceptor(NewLogger(sink, map[string]string{"id": "node-1"}))
+
+	traceID, _ := trace.TraceIDFromHex("0102030405060708090a0b0c0d0e0f10")
+	spanID, _ := trace.SpanIDFromHex("0102030405060708")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{TraceID: traceID, SpanID: spanID}))
+	ctx = peer.NewContext(ctx, &peer.Peer{Addr: &net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 1234}})
+
+	req := &v1.CheckPermissionRequest{
+		Resource:   &v1.ObjectReference{ObjectType: "document", ObjectId: "readme"},
+		Permission: "view",
+		Subject:    &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "anne"}},
+	}
+	info := &grpc.UnaryServerInfo{FullMethod: v1.PermissionsService_CheckPermission_FullMethodName}
+
+	_, err := interceptor(ctx, req, info, func(context.Context, any) (any, error) {
+		return &v1.CheckPermissionResponse{
+			CheckedAt:      &v1.ZedToken{Token: "some-token"},
+			Permissionship: v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION,
+		}, nil
+	})
+	require.NoError(t, err)
+
+	_, err = interceptor(ctx, req, info, func(context.Context, any) (any, error) {
+		return nil, status.Error(codes.FailedPrecondition, "unknown definition")
+	})
+	require.Error(t, err)
+
+	// Other methods are not logged.
+	_, err = interceptor(ctx, &v1.ReadSchemaRequest{}, &grpc.UnaryServerInfo{FullMethod: v1.SchemaService_ReadSchema_FullMethodName}, func(context.Context, any) (any, error) {
+		return &v1.ReadSchemaResponse{}, nil
+	})
+	require.NoError(t, err)
+
+	require.Len(t, sink.decisions, 2)
+
+	allowed := sink.decisions[0]
+	require.NotEmpty(t, allowed.DecisionID)
+	require.Equal(t, map[string]string{"id": "node-1"}, allowed.Labels)
+	require.Equal(t, "0102030405060708090a0b0c0d0e0f10", allowed.TraceID)
+	require.Equal(t, "0102030405060708", allowed.SpanID)
+	require.Equal(t, "authzed/api/v1/PermissionsService/CheckPermission", allowed.Path)
+	require.Equal(t, "10.0.0.1:1234", allowed.RequestedBy)
+	require.JSONEq(t, `{"resource": {"objectType": "document", "objectId": "readme"}, "permission": "view", "subject": {"object": {"objectType": "user", "objectId": "anne"}}}`, string(allowed.Input))
+	require.JSONEq(t, `{"checkedAt": {"token": "some-token"}, "permissionship": "PERMISSIONSHIP_HAS_PERMISSION"}`, string(allowed.Result))
+	require.Equal(t, map[string]Bundle{RevisionBundle: {Revision: "some-token"}}, allowed.Bundles)
+	require.Contains(t, allowed.Metrics, "timer_server_handler_ns")
+	require.Nil(t, allowed.Error)
+
+	failed := sink.decisions[1]
+	require.NotEqual(t, allowed.DecisionID, failed.DecisionID)
+	require.Nil(t, failed.Result)
+	require.Equal(t, &Error{Code: "FailedPrecondition", Message: "unknown definition"}, failed.Error)
+
+	// The events are encoded with the field names of OPA's decision logs.
+	var buf bytes.Buffer
+	NewWriterSink(&buf).Log(allowed)
+	var encoded map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &encoded))
+	for _, field := range []string{"labels", "decision_id", "trace_id", "span_id", "path", "input", "result", "bundles", "requested_by", "timestamp", "metrics"} {
+		require.Contains(t, encoded, field)
+	}
+}
+
+func TestHTTPSink(t *testing.T) {
+	var lock sync.Mutex
+	var batches [][]*Decision
+	failing := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lock.Lock()
+		defer lock.Unlock()
+		if failing {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		require.Equal(t, "gzip", r.Header.Get("Content-Encoding"))
+		gz, err := gzip.NewReader(r.Body)
+		require.NoError(t, err)
+		var batch []*Decision
+		require.NoError(t, json.NewDecoder(gz).Decode(&batch))
+		batches = append(batches, batch)
+	}))
+	defer server.Close()
+
+	_, err := NewHTTPSink(server.URL, time.Second, 2, 1, time.Second)
+	require.ErrorContains(t, err, "buffer size must be at least the batch size")
+
+	sink, err := NewHTTPSink(server.URL, 10*time.Millisecond, 2, 4, time.Second)
+	require.NoError(t, err)
+
+	// Decisions are kept while uploads fail, up to the buffer size.
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		sink.Log(&Decision{DecisionID: id})
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	lock.Lock()
+	failing = false
+	lock.Unlock()
+
+	require.Eventually(t, func() bool {
+		lock.Lock()
+		defer lock.Unlock()
+		return len(batches) == 2
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Buffered decisions are uploaded on close.
+	sink.Log(&Decision{DecisionID: "6"})
+	require.NoError(t, sink.Close())
+
+	var ids [][]string
+	for _, batch := range batches {
+		var batchIDs []string
+		for _, decision := range batch {
+			batchIDs = append(batchIDs, decision.DecisionID)
+		}
+		ids = append(ids, batchIDs)
+	}
+	require.Equal(t, [][]string{{"1", "2"}, {"3", "4"}, {"6"}}, ids)
+}
@@ -0,0 +1,214 @@
+package decisionlog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	log "github.com/authzed/spicedb/internal/logging"
+)
+
+var droppedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "decisionlog",
+	Name:      "dropped_total",
+	Help:      "total number of decisions which could not be logged",
+}, []string{"reason"})
+
+// NewWriterSink returns a sink which writes each decision as a line of JSON to the writer,
+// such as stdout.
+func NewWriterSink(w io.Writer) Sink {
+	return &writerSink{w: w}
+}
+
+type writerSink struct {
+	lock sync.Mutex
+	w    io.Writer
+}
+
+func (ws *writerSink) Log(decision *Decision) {
+	line, err := json.Marshal(decision)
+	if err != nil {
+		droppedCounter.WithLabelValues("encoding").Inc()
+		log.Error().Err(err).Str("decision_id", decision.DecisionID).Msg("error encoding decision")
+		return
+	}
+
+	ws.lock.Lock()
+	defer ws.lock.Unlock()
+	if _, err := ws.w.Write(append(line, '\n')); err != nil {
+		droppedCounter.WithLabelValues("delivery").Inc()
+		log.Error().Err(err).Str("decision_id", decision.DecisionID).Msg("error writing decision")
+	}
+}
+
+func (ws *writerSink) Close() error {
+	return nil
+}
+
+// NewHTTPSink returns a sink which uploads the decisions in batches, as OPA uploads them to
+// the `/logs` endpoint of its decision log service: each batch is a gzip-compressed JSON
+// array POSTed to the endpoint. Batches are uploaded at the interval, or once they reach the
+// batch size. Up to the buffer size of decisions are kept while the endpoint fails, after
+// which new decisions are dropped.
+func NewHTTPSink(endpoint string, interval time.Duration, batchSize, bufferSize int, timeout time.Duration) (Sink, error) {
+	parsed, err := url.Parse(endpoint)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return nil, fmt.Errorf("invalid decision log endpoint `%s`", endpoint)
+	}
+	if interval <= 0 {
+		return nil, errors.New("decision log upload interval must be positive")
+	}
+	if batchSize <= 0 {
+		return nil, errors.New("decision log batch size must be positive")
+	}
+	if bufferSize < batchSize {
+		return nil, errors.New("decision log buffer size must be at least the batch size")
+	}
+	if timeout <= 0 {
+		return nil, errors.New("decision log upload timeout must be positive")
+	}
+
+	hs := &httpSink{
+		endpoint:   endpoint,
+		interval:   interval,
+		batchSize:  batchSize,
+		bufferSize: bufferSize,
+		client:     &http.Client{Timeout: timeout},
+		full:       make(chan struct{}, 1),
+		closed:     make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	go hs.run()
+	return hs, nil
+}
+
+type httpSink struct {
+	endpoint   string
+	interval   time.Duration
+	batchSize  int
+	bufferSize int
+	client     *http.Client
+
+	lock     sync.Mutex
+	buffered []*Decision
+
+	full   chan struct{}
+	closed chan struct{}
+	done   chan struct{}
+	once   sync.Once
+}
+
+func (hs *httpSink) Log(decision *Decision) {
+	hs.lock.Lock()
+	defer hs.lock.Unlock()
+
+	if len(hs.buffered) >= hs.bufferSize {
+		droppedCounter.WithLabelValues("buffer_full").Inc()
+		log.Warn().Str("decision_id", decision.DecisionID).Msg("decision log buffer is full; dropping decision")
+		return
+	}
+
+	hs.buffered = append(hs.buffered, decision)
+	if len(hs.buffered) >= hs.batchSize {
+		select {
+		case hs.full <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Close stops the uploads after uploading the buffered decisions.
+func (hs *httpSink) Close() error {
+	hs.once.Do(func() {
+		close(hs.closed)
+	})
+	<-hs.done
+	hs.client.CloseIdleConnections()
+	return nil
+}
+
+func (hs *httpSink) run() {
+	defer close(hs.done)
+
+	ticker := time.NewTicker(hs.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-hs.full:
+		case <-hs.closed:
+			for hs.uploadBatch() {
+			}
+			return
+		}
+
+		for hs.uploadBatch() {
+		}
+	}
+}
+
+// uploadBatch uploads the oldest batch of buffered decisions, and returns whether a full
+// batch was uploaded, meaning that more decisions may be waiting. Decisions which fail to
+// upload remain buffered until the next attempt.
+func (hs *httpSink) uploadBatch() bool {
+	hs.lock.Lock()
+	batch := hs.buffered[:min(len(hs.buffered), hs.batchSize)]
+	hs.lock.Unlock()
+
+	if len(batch) == 0 {
+		return false
+	}
+
+	if err := hs.upload(batch); err != nil {
+		log.Error().Err(err).Int("decisions", len(batch)).Msg("error uploading decision logs")
+		return false
+	}
+
+	hs.lock.Lock()
+	hs.buffered = hs.buffered[len(batch):]
+	hs.lock.Unlock()
+	return len(batch) == hs.batchSize
+}
+
+func (hs *httpSink) upload(batch []*Decision) error {
+	var body bytes.Buffer
+	gz := gzip.NewWriter(&body)
+	if err := json.NewEncoder(gz).Encode(batch); err != nil {
+		return fmt.Errorf("error encoding decisions: %w", err)
+	}
+	if err := gz.Close(); err != nil {
+		return fmt.Errorf("error compressing decisions: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, hs.endpoint, &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+
+	resp, err := hs.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
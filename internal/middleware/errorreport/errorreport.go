@@ -0,0 +1,256 @@
+// Package errorreport reports panics and internal errors raised while handling gRPC requests
+// to Sentry, along with the context of the request, so that correctness bugs are noticed
+// before they are reported by users.
+//
+// Only the method, the namespaces and the shape of the filters of a request are reported:
+// object IDs are redacted, and request payloads are never sent.
+package errorreport
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/getsentry/sentry-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/middleware/requestid"
+)
+
+// redacted replaces the object IDs found in reported filters.
+const redacted = "<redacted>"
+
+// flushTimeout is how long Close waits for errors to be sent.
+const flushTimeout = 2 * time.Second
+
+// reportedCodes are the codes of the errors which indicate a bug in SpiceDB rather than in
+// the request.
+var reportedCodes = map[codes.Code]struct{}{
+	codes.Internal: {},
+	codes.Unknown:  {},
+	codes.DataLoss: {},
+}
+
+// Config configures the reporting of errors.
+type Config struct {
+	// DSN is the Sentry DSN to which errors are reported.
+	DSN string
+
+	// Environment is the environment reported with each error, e.g. `production`.
+	Environment string
+
+	// Release is the version of SpiceDB reported with each error.
+	Release string
+
+	// SampleRate is the fraction, greater than 0 and at most 1, of errors which are reported.
+	SampleRate float64
+}
+
+// Reporter reports errors to Sentry.
+type Reporter struct {
+	client *sentry.Client
+}
+
+// NewReporter returns a Reporter sending errors to the configured DSN.
+func NewReporter(config Config) (*Reporter, error) {
+	// Sentry reports every error if the sample rate is zero.
+	if config.SampleRate <= 0 || config.SampleRate > 1 {
+		return nil, fmt.Errorf("error reporting sample rate must be greater than 0 and at most 1, got %v", config.SampleRate)
+	}
+
+	return newReporter(sentry.ClientOptions{
+		Dsn:              config.DSN,
+		Environment:      config.Environment,
+		Release:          config.Release,
+		SampleRate:       config.SampleRate,
+		AttachStacktrace: true,
+	})
+}
+
+func newReporter(options sentry.ClientOptions) (*Reporter, error) {
+	client, err := sentry.NewClient(options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create error reporting client: %w", err)
+	}
+	return &Reporter{client: client}, nil
+}
+
+// Close sends the errors which have not been reported yet.
+func (r *Reporter) Close() {
+	if !r.client.Flush(flushTimeout) {
+		log.Warn().Msg("timed out sending errors to the error reporting service")
+	}
+}
+
+// UnaryServerInterceptor returns a new unary server interceptor that reports the panics and
+// internal errors of calls. A nil reporter disables reporting.
+//
+// The interceptor must run within the recovery interceptor, which recovers from the panics
+// after they are reported.
+func UnaryServerInterceptor(reporter *Reporter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		if reporter == nil {
+			return handler(ctx, req)
+		}
+
+		defer reporter.recoverAndRepanic(ctx, info.FullMethod, func() any { return req })
+		resp, err = handler(ctx, req)
+		reporter.reportError(ctx, info.FullMethod, req, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a new stream server interceptor that reports the panics and
+// internal errors of streams. A nil reporter disables reporting.
+//
+// The interceptor must run within the recovery interceptor, which recovers from the panics
+// after they are reported.
+func StreamServerInterceptor(reporter *Reporter) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		if reporter == nil {
+			return handler(srv, stream)
+		}
+
+		wrapper := &recvWrapper{ServerStream: stream}
+		defer reporter.recoverAndRepanic(stream.Context(), info.FullMethod, func() any { return wrapper.req })
+		err = handler(srv, wrapper)
+		reporter.reportError(stream.Context(), info.FullMethod, wrapper.req, err)
+		return err
+	}
+}
+
+// recvWrapper keeps the first message received on the stream, which is the request of
+// server-streaming calls.
+type recvWrapper struct {
+	grpc.ServerStream
+
+	req any
+}
+
+func (s *recvWrapper) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil && s.req == nil {
+		s.req = m
+	}
+	return err
+}
+
+func (r *Reporter) recoverAndRepanic(ctx context.Context, method string, req func() any) {
+	p := recover()
+	if p == nil {
+		return
+	}
+
+	r.client.Recover(p, &sentry.EventHint{Context: ctx}, requestScope(ctx, method, req()))
+	panic(p)
+}
+
+func (r *Reporter) reportError(ctx context.Context, method string, req any, err error) {
+	if err == nil {
+		return
+	}
+	if _, ok := reportedCodes[status.Code(err)]; !ok {
+		return
+	}
+
+	scope := requestScope(ctx, method, req)
+	scope.SetTag("grpc.code", status.Code(err).String())
+	r.client.CaptureException(err, &sentry.EventHint{Context: ctx, OriginalException: err}, scope)
+}
+
+// requestScope returns a scope describing the request, without any object IDs.
+func requestScope(ctx context.Context, method string, req any) *sentry.Scope {
+	scope := sentry.NewScope()
+	scope.SetTag("grpc.method", method)
+
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(requestid.MetadataKey); len(ids) > 0 {
+			scope.SetTag("request_id", ids[0])
+		}
+	}
+
+	details := requestDetails(req)
+	if namespace, ok := details["namespace"].(string); ok {
+		scope.SetTag("namespace", namespace)
+	}
+	if len(details) > 0 {
+		scope.SetContext("request", details)
+	}
+	return scope
+}
+
+// requestDetails returns the namespaces and the redacted filters of the request.
+func requestDetails(req any) sentry.Context {
+	details := sentry.Context{}
+
+	switch req := req.(type) {
+	case interface{ GetResource() *v1.ObjectReference }:
+		if resource := req.GetResource(); resource != nil {
+			details["namespace"] = resource.ObjectType
+		}
+	case interface{ GetResourceObjectType() string }:
+		details["namespace"] = req.GetResourceObjectType()
+	case interface {
+		GetRelationshipFilter() *v1.RelationshipFilter
+	}:
+		if filter := req.GetRelationshipFilter(); filter != nil {
+			details["namespace"] = filter.ResourceType
+			details["filter"] = redactedFilter(filter)
+		}
+	case interface {
+		GetUpdates() []*v1.RelationshipUpdate
+	}:
+		namespaces := map[string]struct{}{}
+		for _, update := range req.GetUpdates() {
+			if rel := update.GetRelationship(); rel != nil && rel.Resource != nil {
+				namespaces[rel.Resource.ObjectType] = struct{}{}
+			}
+		}
+		if len(namespaces) == 1 {
+			for namespace := range namespaces {
+				details["namespace"] = namespace
+			}
+		}
+		details["updates"] = len(req.GetUpdates())
+	}
+
+	if withPermission, ok := req.(interface{ GetPermission() string }); ok && withPermission.GetPermission() != "" {
+		details["permission"] = withPermission.GetPermission()
+	}
+	if withSubject, ok := req.(interface{ GetSubject() *v1.SubjectReference }); ok && withSubject.GetSubject() != nil {
+		subject := withSubject.GetSubject()
+		details["subject"] = redactedSubject(subject.Object.GetObjectType(), subject.Object.GetObjectId(), subject.OptionalRelation)
+	}
+
+	return details
+}
+
+// redactedFilter formats the filter as `type:id#relation@type:id#relation`, with the IDs it
+// specifies redacted.
+func redactedFilter(filter *v1.RelationshipFilter) string {
+	var sb strings.Builder
+	sb.WriteString(redactedSubject(filter.ResourceType, filter.OptionalResourceId, filter.OptionalRelation))
+
+	if subjectFilter := filter.OptionalSubjectFilter; subjectFilter != nil {
+		sb.WriteString("@")
+		sb.WriteString(redactedSubject(subjectFilter.SubjectType, subjectFilter.OptionalSubjectId, subjectFilter.GetOptionalRelation().GetRelation()))
+	}
+	return sb.String()
+}
+
+func redactedSubject(objectType, objectID, relation string) string {
+	formatted := objectType
+	if objectID != "" {
+		formatted += ":" + redacted
+	}
+	if relation != "" {
+		formatted += "#" + relation
+	}
+	return formatted
+}
@@ -0,0 +1,148 @@
+package errorreport
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/getsentry/sentry-go"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/pkg/middleware/requestid"
+)
+
+type fakeTransport struct {
+	sync.Mutex
+	events []*sentry.Event
+}
+
+func (ft *fakeTransport) Flush(time.Duration) bool       { return true }
+func (ft *fakeTransport) Configure(sentry.ClientOptions) {}
+func (ft *fakeTransport) SendEvent(event *sentry.Event) {
+	ft.Lock()
+	defer ft.Unlock()
+	ft.events = append(ft.events, event)
+}
+
+func (ft *fakeTransport) take() []*sentry.Event {
+	ft.Lock()
+	defer ft.Unlock()
+	events := ft.events
+	ft.events = nil
+	return events
+}
+
+type fakeStream struct {
+	grpc.ServerStream
+	req *v1.ReadRelationshipsRequest
+}
+
+func (fs *fakeStream) Context() context.Context { return context.Background() }
+
+func (fs *fakeStream) RecvMsg(m any) error {
+	m.(*v1.ReadRelationshipsRequest).RelationshipFilter = fs.req.RelationshipFilter
+	return nil
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	transport := &fakeTransport{}
+	reporter, err := newReporter(sentry.ClientOptions{Transport: transport})
+	require.NoError(t, err)
+
+	interceptor := UnaryServerInterceptor(reporter)
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(requestid.MetadataKey, "some-request"))
+	info := &grpc.UnaryServerInfo{FullMethod: "/authzed.api.v1.PermissionsService/CheckPermission"}
+	req := &v1.CheckPermissionRequest{
+		Resource:   &v1.ObjectReference{ObjectType: "document", ObjectId: "secret-doc"},
+		Permission: "view",
+		Subject:    &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "secret-user"}},
+	}
+
+	// Errors caused by the request are not reported.
+	_, err = interceptor(ctx, req, info, func(context.Context, any) (any, error) {
+		return nil, status.Error(codes.InvalidArgument, "bad request")
+	})
+	require.Error(t, err)
+	require.Empty(t, transport.take())
+
+	_, err = interceptor(ctx, req, info, func(context.Context, any) (any, error) {
+		return nil, status.Error(codes.Internal, "something broke")
+	})
+	require.Equal(t, codes.Internal, status.Code(err))
+
+	events := transport.take()
+	require.Len(t, events, 1)
+	require.Equal(t, map[string]string{
+		"grpc.method": "/authzed.api.v1.PermissionsService/CheckPermission",
+		"grpc.code":   "Internal",
+		"namespace":   "document",
+		"request_id":  "some-request",
+	}, events[0].Tags)
+	require.Equal(t, sentry.Context{
+		"namespace":  "document",
+		"permission": "view",
+		"subject":    "user:<redacted>",
+	}, events[0].Contexts["request"])
+
+	// Panics are reported and then passed on to the recovery interceptor.
+	require.PanicsWithValue(t, "handler panicked", func() {
+		_, _ = interceptor(ctx, req, info, func(context.Context, any) (any, error) {
+			panic("handler panicked")
+		})
+	})
+	events = transport.take()
+	require.Len(t, events, 1)
+	require.Equal(t, "handler panicked", events[0].Message)
+	require.Equal(t, "document", events[0].Tags["namespace"])
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	transport := &fakeTransport{}
+	reporter, err := newReporter(sentry.ClientOptions{Transport: transport})
+	require.NoError(t, err)
+
+	stream := &fakeStream{req: &v1.ReadRelationshipsRequest{
+		RelationshipFilter: &v1.RelationshipFilter{
+			ResourceType:       "document",
+			OptionalResourceId: "secret-doc",
+			OptionalRelation:   "viewer",
+			OptionalSubjectFilter: &v1.SubjectFilter{
+				SubjectType:       "group",
+				OptionalSubjectId: "secret-group",
+				OptionalRelation:  &v1.SubjectFilter_RelationFilter{Relation: "member"},
+			},
+		},
+	}}
+
+	err = StreamServerInterceptor(reporter)(nil, stream, &grpc.StreamServerInfo{FullMethod: "/authzed.api.v1.PermissionsService/ReadRelationships"}, func(_ any, stream grpc.ServerStream) error {
+		if err := stream.RecvMsg(&v1.ReadRelationshipsRequest{}); err != nil {
+			return err
+		}
+		return status.Error(codes.Unknown, "something broke")
+	})
+	require.Equal(t, codes.Unknown, status.Code(err))
+
+	events := transport.take()
+	require.Len(t, events, 1)
+	require.Equal(t, sentry.Context{
+		"namespace": "document",
+		"filter":    "document:<redacted>#viewer@group:<redacted>#member",
+	}, events[0].Contexts["request"])
+}
+
+func TestDisabled(t *testing.T) {
+	resp, err := UnaryServerInterceptor(nil)(context.Background(), nil, &grpc.UnaryServerInfo{}, func(context.Context, any) (any, error) {
+		return "ok", nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "ok", resp)
+
+	_, err = NewReporter(Config{SampleRate: 2})
+	require.ErrorContains(t, err, "sample rate must be greater than 0 and at most 1")
+}
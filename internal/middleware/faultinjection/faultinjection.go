@@ -0,0 +1,210 @@
+// Package faultinjection injects latency, errors and stream resets into API calls, so that
+// the retry, timeout and reconnection logic of clients can be tested against a real server.
+// It must never be enabled in production.
+package faultinjection
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	middleware "github.com/grpc-ecosystem/go-grpc-middleware/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var injectedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "middleware",
+	Name:      "injected_faults_total",
+	Help:      "Count of the faults injected into API calls for resilience testing",
+}, []string{"method", "fault"})
+
+const (
+	faultLatency     = "latency"
+	faultUnavailable = "unavailable"
+	faultStreamReset = "stream_reset"
+)
+
+// AllMethods is the method name of the faults injected into the calls of every method
+// without faults of its own.
+const AllMethods = "*"
+
+// maxMessagesBeforeReset bounds the number of messages sent on a stream before it is reset.
+const maxMessagesBeforeReset = 10
+
+// Fault is the faults injected into the calls of a method, each with its own probability
+// between 0 and 1.
+type Fault struct {
+	// Latency delays the call before it is handled.
+	Latency            time.Duration
+	LatencyProbability float64
+
+	// UnavailableProbability is the probability of failing the call with UNAVAILABLE, as
+	// when the server is restarting, before it is handled.
+	UnavailableProbability float64
+
+	// StreamResetProbability is the probability of failing a streaming call with UNAVAILABLE
+	// after some of its responses have been sent, as when a connection is reset. It does not
+	// apply to unary calls.
+	StreamResetProbability float64
+}
+
+func (f Fault) validate() error {
+	for _, probability := range []struct {
+		name  string
+		value float64
+	}{
+		{"latency", f.LatencyProbability},
+		{"unavailable", f.UnavailableProbability},
+		{"stream reset", f.StreamResetProbability},
+	} {
+		if probability.value < 0 || probability.value > 1 {
+			return fmt.Errorf("%s probability must be between 0 and 1, got %v", probability.name, probability.value)
+		}
+	}
+	if f.Latency < 0 {
+		return fmt.Errorf("latency must not be negative, got %s", f.Latency)
+	}
+	if f.LatencyProbability > 0 && f.Latency == 0 {
+		return errors.New("latency must be set with a latency probability")
+	}
+	return nil
+}
+
+// Injector injects the faults of each method into its calls.
+type Injector struct {
+	faults map[string]Fault
+	random func() float64
+	intn   func(int) int
+}
+
+// NewInjector creates an injector of the faults of each method, keyed by the method's full
+// name (e.g. "/authzed.api.v1.PermissionsService/CheckPermission"), its name alone
+// (e.g. "CheckPermission") or AllMethods.
+func NewInjector(faults map[string]Fault) (*Injector, error) {
+	if len(faults) == 0 {
+		return nil, errors.New("no faults to inject")
+	}
+	for method, fault := range faults {
+		if err := fault.validate(); err != nil {
+			return nil, fmt.Errorf("invalid faults for method `%s`: %w", method, err)
+		}
+	}
+	return &Injector{faults: faults, random: rand.Float64, intn: rand.Intn}, nil
+}
+
+// faultFor returns the faults of the method, if any.
+func (i *Injector) faultFor(fullMethod string) (Fault, bool) {
+	if fault, ok := i.faults[fullMethod]; ok {
+		return fault, true
+	}
+	if fault, ok := i.faults[fullMethod[strings.LastIndex(fullMethod, "/")+1:]]; ok {
+		return fault, true
+	}
+	fault, ok := i.faults[AllMethods]
+	return fault, ok
+}
+
+func (i *Injector) occurs(probability float64) bool {
+	return probability > 0 && i.random() < probability
+}
+
+// beforeCall injects the latency and errors of the method, returning the error to fail the
+// call with, if any.
+func (i *Injector) beforeCall(ctx context.Context, fullMethod string, fault Fault) error {
+	if i.occurs(fault.LatencyProbability) {
+		injectedCounter.WithLabelValues(fullMethod, faultLatency).Inc()
+
+		timer := time.NewTimer(fault.Latency)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			return status.FromContextError(ctx.Err()).Err()
+		case <-timer.C:
+		}
+	}
+
+	if i.occurs(fault.UnavailableProbability) {
+		injectedCounter.WithLabelValues(fullMethod, faultUnavailable).Inc()
+		return status.Error(codes.Unavailable, "fault injected: service unavailable")
+	}
+	return nil
+}
+
+// UnaryServerInterceptor returns a new interceptor which injects the faults of the method
+// into each call. A nil injector injects no faults.
+func UnaryServerInterceptor(injector *Injector) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if injector == nil {
+			return handler(ctx, req)
+		}
+
+		fault, ok := injector.faultFor(info.FullMethod)
+		if !ok {
+			return handler(ctx, req)
+		}
+		if err := injector.beforeCall(ctx, info.FullMethod, fault); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a new interceptor which injects the faults of the method
+// into each stream. A nil injector injects no faults.
+func StreamServerInterceptor(injector *Injector) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if injector == nil {
+			return handler(srv, stream)
+		}
+
+		fault, ok := injector.faultFor(info.FullMethod)
+		if !ok {
+			return handler(srv, stream)
+		}
+		if err := injector.beforeCall(stream.Context(), info.FullMethod, fault); err != nil {
+			return err
+		}
+		if !info.IsServerStream || !injector.occurs(fault.StreamResetProbability) {
+			return handler(srv, stream)
+		}
+
+		injectedCounter.WithLabelValues(info.FullMethod, faultStreamReset).Inc()
+		resetting := &resettingStream{
+			WrappedServerStream: middleware.WrapServerStream(stream),
+			remaining:           injector.intn(maxMessagesBeforeReset),
+		}
+		if err := handler(srv, resetting); err != nil && !resetting.reset {
+			return err
+		}
+
+		// Streams ending before the reset are reset at their end, so that the client never
+		// sees them complete.
+		return errStreamReset
+	}
+}
+
+var errStreamReset = status.Error(codes.Unavailable, "fault injected: stream reset")
+
+// resettingStream fails once the given number of messages have been sent.
+type resettingStream struct {
+	*middleware.WrappedServerStream
+	remaining int
+	reset     bool
+}
+
+func (s *resettingStream) SendMsg(m any) error {
+	if s.remaining <= 0 {
+		s.reset = true
+		return errStreamReset
+	}
+	s.remaining--
+	return s.WrappedServerStream.SendMsg(m)
+}
@@ -0,0 +1,152 @@
+package faultinjection
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	checkMethod  = "/authzed.api.v1.PermissionsService/CheckPermission"
+	lookupMethod = "/authzed.api.v1.PermissionsService/LookupResources"
+	writeMethod  = "/authzed.api.v1.PermissionsService/WriteRelationships"
+)
+
+// newTestInjector creates an injector whose faults always occur, or never do.
+func newTestInjector(t *testing.T, faults map[string]Fault, occur bool, messagesBeforeReset int) *Injector {
+	injector, err := NewInjector(faults)
+	require.NoError(t, err)
+
+	injector.random = func() float64 {
+		if occur {
+			return 0
+		}
+		return 1
+	}
+	injector.intn = func(int) int { return messagesBeforeReset }
+	return injector
+}
+
+func callUnary(ctx context.Context, injector *Injector, method string) error {
+	_, err := UnaryServerInterceptor(injector)(ctx, nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req any) (any, error) {
+		return nil, nil
+	})
+	return err
+}
+
+type testStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	sent int
+}
+
+func (s *testStream) Context() context.Context { return s.ctx }
+
+func (s *testStream) SendMsg(_ any) error {
+	s.sent++
+	return nil
+}
+
+// callStream calls a server streaming method which sends the given number of messages,
+// returning the number actually sent.
+func callStream(injector *Injector, method string, messages int) (int, error) {
+	stream := &testStream{ctx: context.Background()}
+	err := StreamServerInterceptor(injector)(nil, stream, &grpc.StreamServerInfo{FullMethod: method, IsServerStream: true}, func(_ any, stream grpc.ServerStream) error {
+		for i := 0; i < messages; i++ {
+			if err := stream.SendMsg(i); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return stream.sent, err
+}
+
+func TestNewInjector(t *testing.T) {
+	_, err := NewInjector(nil)
+	require.ErrorContains(t, err, "no faults")
+
+	_, err = NewInjector(map[string]Fault{"CheckPermission": {UnavailableProbability: 2}})
+	require.ErrorContains(t, err, "invalid faults for method `CheckPermission`: unavailable probability must be between 0 and 1")
+
+	_, err = NewInjector(map[string]Fault{"CheckPermission": {LatencyProbability: 0.5}})
+	require.ErrorContains(t, err, "latency must be set")
+
+	_, err = NewInjector(map[string]Fault{"CheckPermission": {Latency: -time.Second}})
+	require.ErrorContains(t, err, "latency must not be negative")
+}
+
+func TestFaultFor(t *testing.T) {
+	injector := newTestInjector(t, map[string]Fault{
+		checkMethod:       {UnavailableProbability: 0.1},
+		"LookupResources": {UnavailableProbability: 0.2},
+		AllMethods:        {UnavailableProbability: 0.3},
+	}, true, 0)
+
+	for method, expected := range map[string]float64{
+		checkMethod:  0.1,
+		lookupMethod: 0.2,
+		writeMethod:  0.3,
+	} {
+		fault, ok := injector.faultFor(method)
+		require.True(t, ok)
+		require.Equal(t, expected, fault.UnavailableProbability, method)
+	}
+
+	injector = newTestInjector(t, map[string]Fault{"CheckPermission": {UnavailableProbability: 0.1}}, true, 0)
+	_, ok := injector.faultFor(writeMethod)
+	require.False(t, ok)
+}
+
+func TestUnavailable(t *testing.T) {
+	faults := map[string]Fault{"CheckPermission": {UnavailableProbability: 0.5}}
+
+	require.NoError(t, callUnary(context.Background(), nil, checkMethod))
+	require.NoError(t, callUnary(context.Background(), newTestInjector(t, faults, false, 0), checkMethod))
+	require.NoError(t, callUnary(context.Background(), newTestInjector(t, faults, true, 0), writeMethod))
+
+	before := testutil.ToFloat64(injectedCounter.WithLabelValues(checkMethod, faultUnavailable))
+	err := callUnary(context.Background(), newTestInjector(t, faults, true, 0), checkMethod)
+	require.Equal(t, codes.Unavailable, status.Code(err))
+	require.Equal(t, before+1, testutil.ToFloat64(injectedCounter.WithLabelValues(checkMethod, faultUnavailable)))
+}
+
+func TestLatency(t *testing.T) {
+	injector := newTestInjector(t, map[string]Fault{AllMethods: {Latency: 50 * time.Millisecond, LatencyProbability: 0.5}}, true, 0)
+
+	start := time.Now()
+	require.NoError(t, callUnary(context.Background(), injector, checkMethod))
+	require.GreaterOrEqual(t, time.Since(start), 50*time.Millisecond)
+
+	// Injected latency is bounded by the deadline of the call.
+	injector = newTestInjector(t, map[string]Fault{AllMethods: {Latency: time.Hour, LatencyProbability: 0.5}}, true, 0)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	err := callUnary(ctx, injector, checkMethod)
+	require.Equal(t, codes.DeadlineExceeded, status.Code(err))
+}
+
+func TestStreamReset(t *testing.T) {
+	faults := map[string]Fault{"LookupResources": {StreamResetProbability: 0.5}}
+
+	sent, err := callStream(newTestInjector(t, faults, false, 3), lookupMethod, 5)
+	require.NoError(t, err)
+	require.Equal(t, 5, sent)
+
+	before := testutil.ToFloat64(injectedCounter.WithLabelValues(lookupMethod, faultStreamReset))
+	sent, err = callStream(newTestInjector(t, faults, true, 3), lookupMethod, 5)
+	require.Equal(t, codes.Unavailable, status.Code(err))
+	require.Equal(t, 3, sent)
+	require.Equal(t, before+1, testutil.ToFloat64(injectedCounter.WithLabelValues(lookupMethod, faultStreamReset)))
+
+	// Streams ending before they are reset are reset at their end.
+	sent, err = callStream(newTestInjector(t, faults, true, 3), lookupMethod, 2)
+	require.Equal(t, codes.Unavailable, status.Code(err))
+	require.Equal(t, 2, sent)
+}
@@ -0,0 +1,269 @@
+// Package namespacemetrics records request, latency, dispatch and update metrics labeled
+// by the object namespace of each API request, so that load can be attributed to the
+// definitions of the schema. The namespace label is bounded by the namespaces known to
+// be defined in the schema; requests for other namespaces are labeled `_other`.
+package namespacemetrics
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/authzed/grpcutil"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/middleware/usagemetrics"
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+const (
+	// OtherNamespace labels requests for namespaces which are not known to be defined in
+	// the schema.
+	OtherNamespace = "_other"
+
+	// MultipleNamespaces labels requests, such as writes and bulk checks, spanning more
+	// than one namespace.
+	MultipleNamespaces = "_multiple"
+)
+
+var (
+	requestsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "spicedb",
+		Subsystem: "services",
+		Name:      "namespace_requests_total",
+		Help:      "total number of API requests by method, object namespace and result code",
+	}, []string{"method", "namespace", "code"})
+
+	requestDurationHistogram = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "spicedb",
+		Subsystem: "services",
+		Name:      "namespace_request_duration_seconds",
+		Help:      "duration of API requests by method and object namespace",
+		Buckets:   []float64{.001, .003, .006, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+	}, []string{"method", "namespace"})
+
+	dispatchesHistogram = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "spicedb",
+		Subsystem: "services",
+		Name:      "namespace_dispatches",
+		Help:      "Histogram of cluster dispatches performed by the instance by method and object namespace.",
+		Buckets:   []float64{1, 5, 10, 25, 50, 100, 250},
+	}, []string{"method", "namespace", "cached"})
+
+	updatesCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "spicedb",
+		Subsystem: "services",
+		Name:      "namespace_relationship_updates_total",
+		Help:      "total number of relationship updates requested by object namespace and operation",
+	}, []string{"namespace", "operation"})
+)
+
+// KnownNamespaces is the set of namespaces defined in the schema, which bounds the values of
+// the namespace label.
+type KnownNamespaces struct {
+	names atomic.Pointer[map[string]struct{}]
+}
+
+// NewKnownNamespaces returns an empty set of known namespaces, under which every namespace
+// is labeled `_other` until the set has been refreshed.
+func NewKnownNamespaces() *KnownNamespaces {
+	return &KnownNamespaces{}
+}
+
+// Refresh replaces the known namespaces with those defined at the head revision of the
+// datastore, which are served from the schema cache when the datastore is wrapped by it.
+func (kn *KnownNamespaces) Refresh(ctx context.Context, ds datastore.Datastore) error {
+	headRevision, err := ds.HeadRevision(ctx)
+	if err != nil {
+		return fmt.Errorf("error reading head revision: %w", err)
+	}
+
+	namespaces, err := ds.SnapshotReader(headRevision).ListAllNamespaces(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing namespaces: %w", err)
+	}
+
+	names := make(map[string]struct{}, len(namespaces))
+	for _, ns := range namespaces {
+		names[ns.Definition.Name] = struct{}{}
+	}
+	kn.names.Store(&names)
+	return nil
+}
+
+// Run refreshes the known namespaces immediately and then at the interval, until the
+// context is canceled. Failed refreshes are logged and the previous namespaces are kept.
+func (kn *KnownNamespaces) Run(ctx context.Context, ds datastore.Datastore, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := kn.Refresh(ctx, ds); err != nil && ctx.Err() == nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("could not refresh the namespaces labeling namespace metrics")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// Label returns the label value of the namespace: the namespace itself if it is known, and
+// `_other` otherwise.
+func (kn *KnownNamespaces) Label(namespace string) string {
+	if namespace == MultipleNamespaces {
+		return namespace
+	}
+
+	names := kn.names.Load()
+	if names == nil {
+		return OtherNamespace
+	}
+	if _, ok := (*names)[namespace]; ok {
+		return namespace
+	}
+	return OtherNamespace
+}
+
+// UnaryServerInterceptor returns a new unary server interceptor that records the namespace
+// metrics of each request. A nil set of known namespaces disables the metrics.
+//
+// The interceptor must run within the usagemetrics interceptor for the dispatches of the
+// call to be recorded.
+func UnaryServerInterceptor(known *KnownNamespaces) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if known == nil {
+			return handler(ctx, req)
+		}
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		known.report(ctx, info.FullMethod, req, time.Since(start), err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a new stream server interceptor that records the namespace
+// metrics of each stream. A nil set of known namespaces disables the metrics.
+//
+// The interceptor must run within the usagemetrics interceptor for the dispatches of the
+// stream to be recorded.
+func StreamServerInterceptor(known *KnownNamespaces) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if known == nil {
+			return handler(srv, stream)
+		}
+
+		wrapper := &recvWrapper{ServerStream: stream}
+		start := time.Now()
+		err := handler(srv, wrapper)
+		known.report(stream.Context(), info.FullMethod, wrapper.req, time.Since(start), err)
+		return err
+	}
+}
+
+// recvWrapper keeps the first message received on the stream, which is the request of
+// server-streaming calls.
+type recvWrapper struct {
+	grpc.ServerStream
+
+	req any
+}
+
+func (s *recvWrapper) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil && s.req == nil {
+		s.req = m
+	}
+	return err
+}
+
+func (kn *KnownNamespaces) report(ctx context.Context, fullMethod string, req any, duration time.Duration, err error) {
+	namespace, ok := RequestNamespace(req)
+	if !ok {
+		return
+	}
+
+	_, method := grpcutil.SplitMethodName(fullMethod)
+	label := kn.Label(namespace)
+
+	requestsCounter.WithLabelValues(method, label, status.Code(err).String()).Inc()
+	requestDurationHistogram.WithLabelValues(method, label).Observe(duration.Seconds())
+
+	if meta := usagemetrics.FromContext(ctx); meta != nil {
+		dispatchesHistogram.WithLabelValues(method, label, "false").Observe(float64(meta.DispatchCount))
+		dispatchesHistogram.WithLabelValues(method, label, "true").Observe(float64(meta.CachedDispatchCount))
+	}
+
+	if write, ok := req.(*v1.WriteRelationshipsRequest); ok && err == nil {
+		for _, update := range write.Updates {
+			updatesCounter.WithLabelValues(kn.Label(update.GetRelationship().GetResource().GetObjectType()), updateOperation(update.Operation)).Inc()
+		}
+	}
+}
+
+// RequestNamespace returns the object namespace of the request, or `_multiple` if the
+// request spans several namespaces. It returns false for requests without a namespace,
+// such as those of the schema service.
+func RequestNamespace(req any) (string, bool) {
+	switch r := req.(type) {
+	case *v1.WriteRelationshipsRequest:
+		return commonNamespace(len(r.Updates), func(i int) string {
+			return r.Updates[i].GetRelationship().GetResource().GetObjectType()
+		})
+
+	case *v1.BulkCheckPermissionRequest:
+		return commonNamespace(len(r.Items), func(i int) string {
+			return r.Items[i].GetResource().GetObjectType()
+		})
+
+	case *v1.LookupResourcesRequest:
+		return r.ResourceObjectType, true
+
+	case interface{ GetRelationshipFilter() *v1.RelationshipFilter }:
+		filter := r.GetRelationshipFilter()
+		return filter.GetResourceType(), filter != nil
+
+	case interface{ GetResource() *v1.ObjectReference }:
+		resource := r.GetResource()
+		return resource.GetObjectType(), resource != nil
+
+	default:
+		return "", false
+	}
+}
+
+func commonNamespace(count int, namespaceAt func(int) string) (string, bool) {
+	if count == 0 {
+		return "", false
+	}
+
+	namespace := namespaceAt(0)
+	for i := 1; i < count; i++ {
+		if namespaceAt(i) != namespace {
+			return MultipleNamespaces, true
+		}
+	}
+	return namespace, true
+}
+
+func updateOperation(operation v1.RelationshipUpdate_Operation) string {
+	switch operation {
+	case v1.RelationshipUpdate_OPERATION_CREATE:
+		return "create"
+	case v1.RelationshipUpdate_OPERATION_TOUCH:
+		return "touch"
+	case v1.RelationshipUpdate_OPERATION_DELETE:
+		return "delete"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,131 @@
+package namespacemetrics
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/middleware/usagemetrics"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+)
+
+func TestRefreshAndLabel(t *testing.T) {
+	require := require.New(t)
+
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(err)
+	ds, _ := testfixtures.StandardDatastoreWithSchema(rawDS, require)
+
+	known := NewKnownNamespaces()
+	require.Equal(OtherNamespace, known.Label("document"))
+
+	require.NoError(known.Refresh(context.Background(), ds))
+	require.Equal("document", known.Label("document"))
+	require.Equal(OtherNamespace, known.Label("unknown"))
+	require.Equal(MultipleNamespaces, known.Label(MultipleNamespaces))
+}
+
+func TestRequestNamespace(t *testing.T) {
+	doc := &v1.ObjectReference{ObjectType: "document", ObjectId: "first"}
+	folder := &v1.ObjectReference{ObjectType: "folder", ObjectId: "root"}
+	user := &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "tom"}}
+
+	for _, tc := range []struct {
+		name      string
+		req       any
+		namespace string
+		ok        bool
+	}{
+		{"check", &v1.CheckPermissionRequest{Resource: doc}, "document", true},
+		{"expand", &v1.ExpandPermissionTreeRequest{Resource: folder}, "folder", true},
+		{"lookup resources", &v1.LookupResourcesRequest{ResourceObjectType: "document"}, "document", true},
+		{"lookup subjects", &v1.LookupSubjectsRequest{Resource: doc}, "document", true},
+		{"read", &v1.ReadRelationshipsRequest{RelationshipFilter: &v1.RelationshipFilter{ResourceType: "folder"}}, "folder", true},
+		{"delete", &v1.DeleteRelationshipsRequest{RelationshipFilter: &v1.RelationshipFilter{ResourceType: "document"}}, "document", true},
+		{"write", &v1.WriteRelationshipsRequest{Updates: []*v1.RelationshipUpdate{
+			{Relationship: &v1.Relationship{Resource: doc, Subject: user}},
+			{Relationship: &v1.Relationship{Resource: doc, Subject: user}},
+		}}, "document", true},
+		{"mixed write", &v1.WriteRelationshipsRequest{Updates: []*v1.RelationshipUpdate{
+			{Relationship: &v1.Relationship{Resource: doc, Subject: user}},
+			{Relationship: &v1.Relationship{Resource: folder, Subject: user}},
+		}}, MultipleNamespaces, true},
+		{"mixed bulk check", &v1.BulkCheckPermissionRequest{Items: []*v1.BulkCheckPermissionRequestItem{
+			{Resource: doc}, {Resource: folder},
+		}}, MultipleNamespaces, true},
+		{"schema", &v1.ReadSchemaRequest{}, "", false},
+		{"empty write", &v1.WriteRelationshipsRequest{}, "", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			namespace, ok := RequestNamespace(tc.req)
+			require.Equal(t, tc.ok, ok)
+			require.Equal(t, tc.namespace, namespace)
+		})
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	require := require.New(t)
+
+	known := NewKnownNamespaces()
+	known.names.Store(&map[string]struct{}{"document": {}})
+
+	info := &grpc.UnaryServerInfo{FullMethod: v1.PermissionsService_CheckPermission_FullMethodName}
+	interceptor := UnaryServerInterceptor(known)
+	handler := func(ctx context.Context, _ any) (any, error) {
+		usagemetrics.SetInContext(ctx, &dispatchv1.ResponseMeta{DispatchCount: 3, CachedDispatchCount: 1})
+		return nil, nil
+	}
+
+	requests := testutil.ToFloat64(requestsCounter.WithLabelValues("CheckPermission", "document", "OK"))
+	others := testutil.ToFloat64(requestsCounter.WithLabelValues("CheckPermission", OtherNamespace, "NotFound"))
+
+	ctx := usagemetrics.ContextWithHandle(context.Background())
+	_, err := interceptor(ctx, &v1.CheckPermissionRequest{Resource: &v1.ObjectReference{ObjectType: "document"}}, info, handler)
+	require.NoError(err)
+
+	_, err = interceptor(ctx, &v1.CheckPermissionRequest{Resource: &v1.ObjectReference{ObjectType: "secret"}}, info, func(context.Context, any) (any, error) {
+		return nil, status.Error(codes.NotFound, "not found")
+	})
+	require.Error(err)
+
+	require.InDelta(requests+1, testutil.ToFloat64(requestsCounter.WithLabelValues("CheckPermission", "document", "OK")), 0)
+	require.InDelta(others+1, testutil.ToFloat64(requestsCounter.WithLabelValues("CheckPermission", OtherNamespace, "NotFound")), 0)
+
+	// Unknown namespaces never appear as label values.
+	metrics := make(chan prometheus.Metric, 100)
+	requestsCounter.Collect(metrics)
+	close(metrics)
+	for metric := range metrics {
+		var written dto.Metric
+		require.NoError(metric.Write(&written))
+		for _, label := range written.Label {
+			require.NotEqual("secret", label.GetValue())
+		}
+	}
+
+	// Updates are counted by namespace and operation on successful writes.
+	writeInfo := &grpc.UnaryServerInfo{FullMethod: v1.PermissionsService_WriteRelationships_FullMethodName}
+	touches := testutil.ToFloat64(updatesCounter.WithLabelValues("document", "touch"))
+	_, err = interceptor(ctx, &v1.WriteRelationshipsRequest{Updates: []*v1.RelationshipUpdate{
+		{Operation: v1.RelationshipUpdate_OPERATION_TOUCH, Relationship: &v1.Relationship{Resource: &v1.ObjectReference{ObjectType: "document"}}},
+		{Operation: v1.RelationshipUpdate_OPERATION_TOUCH, Relationship: &v1.Relationship{Resource: &v1.ObjectReference{ObjectType: "document"}}},
+	}}, writeInfo, handler)
+	require.NoError(err)
+	require.InDelta(touches+2, testutil.ToFloat64(updatesCounter.WithLabelValues("document", "touch")), 0)
+
+	// A nil set of known namespaces disables the metrics.
+	_, err = UnaryServerInterceptor(nil)(ctx, &v1.CheckPermissionRequest{Resource: &v1.ObjectReference{ObjectType: "document"}}, info, handler)
+	require.NoError(err)
+	require.InDelta(requests+1, testutil.ToFloat64(requestsCounter.WithLabelValues("CheckPermission", "document", "OK")), 0)
+}
@@ -0,0 +1,380 @@
+// Package namespacequota enforces per-namespace limits on the number of relationships stored,
+// the rate at which relationships are written, and the number of results returned by
+// lookups, so that a single namespace cannot exhaust a shared cluster.
+package namespacequota
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/time/rate"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	"github.com/authzed/spicedb/pkg/spiceerrors"
+)
+
+var exceededCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "middleware",
+	Name:      "namespace_quota_exceeded_total",
+	Help:      "Count of the requests rejected for exceeding a per-namespace quota",
+}, []string{"namespace", "quota"})
+
+const (
+	quotaRelationships  = "relationships"
+	quotaWriteRate      = "write_rate"
+	quotaLookupResults  = "lookup_results"
+	reasonQuotaExceeded = "ERROR_REASON_NAMESPACE_QUOTA_EXCEEDED"
+)
+
+// Limits are the quotas of a namespace. A zero value places no limit.
+type Limits struct {
+	// MaxRelationships is the maximum number of relationships whose resource is in the
+	// namespace. It is enforced against a count refreshed periodically from the datastore,
+	// plus the relationships created or touched since, so it may be briefly exceeded by
+	// writes made through other nodes.
+	MaxRelationships uint64
+
+	// MaxWritesPerSecond is the maximum sustained rate of relationship updates to the
+	// namespace, with a burst of one second of updates.
+	MaxWritesPerSecond float64
+
+	// MaxLookupResults is the maximum number of results streamed by a single lookup of
+	// objects of the namespace.
+	MaxLookupResults uint64
+}
+
+// Quotas enforces the limits of each namespace.
+type Quotas struct {
+	limits   map[string]Limits
+	limiters map[string]*rate.Limiter
+	now      func() time.Time
+
+	mu     sync.Mutex
+	counts map[string]uint64
+}
+
+// NewQuotas returns the quotas enforcing the limits of each namespace, or nil if no limits
+// are configured.
+func NewQuotas(limits map[string]Limits) *Quotas {
+	if len(limits) == 0 {
+		return nil
+	}
+
+	limiters := make(map[string]*rate.Limiter, len(limits))
+	for namespace, nsLimits := range limits {
+		if nsLimits.MaxWritesPerSecond > 0 {
+			burst := int(math.Ceil(nsLimits.MaxWritesPerSecond))
+			limiters[namespace] = rate.NewLimiter(rate.Limit(nsLimits.MaxWritesPerSecond), burst)
+		}
+	}
+
+	return &Quotas{
+		limits:   limits,
+		limiters: limiters,
+		now:      time.Now,
+		counts:   map[string]uint64{},
+	}
+}
+
+// ParseLimits builds the limits of each namespace from flag values mapping namespaces to
+// their maximum relationship count, write rate and lookup result count.
+func ParseLimits(maxRelationships, maxWriteRate, maxLookupResults map[string]string) (map[string]Limits, error) {
+	limits := map[string]Limits{}
+	for namespace, value := range maxRelationships {
+		count, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid maximum relationship count for namespace `%s`: %w", namespace, err)
+		}
+		nsLimits := limits[namespace]
+		nsLimits.MaxRelationships = count
+		limits[namespace] = nsLimits
+	}
+
+	for namespace, value := range maxWriteRate {
+		perSecond, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid maximum write rate for namespace `%s`: %w", namespace, err)
+		}
+		if perSecond < 0 || math.IsInf(perSecond, 0) || math.IsNaN(perSecond) {
+			return nil, fmt.Errorf("invalid maximum write rate for namespace `%s`: must be a non-negative number", namespace)
+		}
+		nsLimits := limits[namespace]
+		nsLimits.MaxWritesPerSecond = perSecond
+		limits[namespace] = nsLimits
+	}
+
+	for namespace, value := range maxLookupResults {
+		count, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid maximum lookup result count for namespace `%s`: %w", namespace, err)
+		}
+		nsLimits := limits[namespace]
+		nsLimits.MaxLookupResults = count
+		limits[namespace] = nsLimits
+	}
+
+	for namespace, nsLimits := range limits {
+		if nsLimits == (Limits{}) {
+			delete(limits, namespace)
+		}
+	}
+	return limits, nil
+}
+
+// Run refreshes the relationship counts of the namespaces with a maximum relationship count
+// immediately and then at the interval, until the context is canceled. Until the first
+// refresh, only the relationships written through this node are counted.
+func (q *Quotas) Run(ctx context.Context, ds datastore.Datastore, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := q.refresh(ctx, ds); err != nil && ctx.Err() == nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("could not refresh the relationship counts of namespace quotas")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// refresh counts the relationships of each namespace with a maximum relationship count, up
+// to one more than the maximum so that the cost of counting is bounded by the quota.
+func (q *Quotas) refresh(ctx context.Context, ds datastore.Datastore) error {
+	headRevision, err := ds.HeadRevision(ctx)
+	if err != nil {
+		return fmt.Errorf("error reading head revision: %w", err)
+	}
+
+	reader := ds.SnapshotReader(headRevision)
+	for namespace, limits := range q.limits {
+		if limits.MaxRelationships == 0 {
+			continue
+		}
+
+		count, err := countRelationships(ctx, reader, namespace, limits.MaxRelationships+1)
+		if err != nil {
+			return fmt.Errorf("error counting relationships of namespace `%s`: %w", namespace, err)
+		}
+
+		q.mu.Lock()
+		q.counts[namespace] = count
+		q.mu.Unlock()
+	}
+	return nil
+}
+
+func countRelationships(ctx context.Context, reader datastore.Reader, namespace string, limit uint64) (uint64, error) {
+	it, err := reader.QueryRelationships(ctx, datastore.RelationshipsFilter{ResourceType: namespace}, options.WithLimit(&limit))
+	if err != nil {
+		return 0, err
+	}
+	defer it.Close()
+
+	var count uint64
+	for tpl := it.Next(); tpl != nil; tpl = it.Next() {
+		count++
+	}
+	return count, it.Err()
+}
+
+// admitWrites checks that the updates, counted by namespace, are within the write rate and
+// relationship count of each namespace, and records the relationships they may create.
+func (q *Quotas) admitWrites(updates, creates map[string]uint64) error {
+	now := q.now()
+	var reservations []*rate.Reservation
+	cancel := func() {
+		for _, reservation := range reservations {
+			reservation.CancelAt(now)
+		}
+	}
+
+	for namespace, count := range updates {
+		limiter, ok := q.limiters[namespace]
+		if !ok {
+			continue
+		}
+
+		if count > uint64(limiter.Burst()) {
+			cancel()
+			return quotaError(namespace, quotaWriteRate,
+				fmt.Errorf("request writes %d relationships of namespace `%s`, more than its limit of %d updates per second", count, namespace, limiter.Burst()),
+				strconv.FormatFloat(q.limits[namespace].MaxWritesPerSecond, 'f', -1, 64), 0)
+		}
+
+		reservation := limiter.ReserveN(now, int(count))
+		if delay := reservation.DelayFrom(now); delay > 0 {
+			reservation.CancelAt(now)
+			cancel()
+			return quotaError(namespace, quotaWriteRate,
+				fmt.Errorf("write rate limit exceeded for namespace `%s`", namespace),
+				strconv.FormatFloat(q.limits[namespace].MaxWritesPerSecond, 'f', -1, 64), delay)
+		}
+		reservations = append(reservations, reservation)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for namespace, count := range creates {
+		maxRelationships := q.limits[namespace].MaxRelationships
+		if maxRelationships == 0 {
+			continue
+		}
+		if q.counts[namespace]+count > maxRelationships {
+			cancel()
+			return quotaError(namespace, quotaRelationships,
+				fmt.Errorf("namespace `%s` has reached its maximum of %d relationships", namespace, maxRelationships),
+				strconv.FormatUint(maxRelationships, 10), 0)
+		}
+	}
+
+	for namespace, count := range creates {
+		if q.limits[namespace].MaxRelationships > 0 {
+			q.counts[namespace] += count
+		}
+	}
+	return nil
+}
+
+func quotaError(namespace, quota string, err error, limit string, retryAfter time.Duration) error {
+	exceededCounter.WithLabelValues(namespace, quota).Inc()
+
+	errorInfo := &errdetails.ErrorInfo{
+		Reason: reasonQuotaExceeded,
+		Domain: spiceerrors.Domain,
+		Metadata: map[string]string{
+			"namespace": namespace,
+			"quota":     quota,
+			"limit":     limit,
+		},
+	}
+	if retryAfter > 0 {
+		return spiceerrors.WithCodeAndDetailsAsError(err, codes.ResourceExhausted, errorInfo, &errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)})
+	}
+	return spiceerrors.WithCodeAndDetailsAsError(err, codes.ResourceExhausted, errorInfo)
+}
+
+// countUpdates returns the number of relationships updated, and of those which may be
+// created, by namespace.
+func countUpdates(rels []*v1.Relationship, operations []v1.RelationshipUpdate_Operation) (updates, creates map[string]uint64) {
+	updates = map[string]uint64{}
+	creates = map[string]uint64{}
+	for i, rel := range rels {
+		namespace := rel.GetResource().GetObjectType()
+		updates[namespace]++
+		if operations[i] != v1.RelationshipUpdate_OPERATION_DELETE {
+			creates[namespace]++
+		}
+	}
+	return updates, creates
+}
+
+// UnaryServerInterceptor returns a new interceptor which rejects writes exceeding the write
+// rate or relationship count of a namespace. A nil set of quotas allows all requests.
+func UnaryServerInterceptor(quotas *Quotas) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if quotas == nil {
+			return handler(ctx, req)
+		}
+
+		switch r := req.(type) {
+		case *v1.WriteRelationshipsRequest:
+			rels := make([]*v1.Relationship, 0, len(r.Updates))
+			operations := make([]v1.RelationshipUpdate_Operation, 0, len(r.Updates))
+			for _, update := range r.Updates {
+				rels = append(rels, update.Relationship)
+				operations = append(operations, update.Operation)
+			}
+			if err := quotas.admitWrites(countUpdates(rels, operations)); err != nil {
+				return nil, err
+			}
+
+		case *v1.DeleteRelationshipsRequest:
+			namespace := r.GetRelationshipFilter().GetResourceType()
+			if err := quotas.admitWrites(map[string]uint64{namespace: 1}, nil); err != nil {
+				return nil, err
+			}
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a new interceptor which rejects bulk imports exceeding the
+// write rate or relationship count of a namespace, and fails lookups once they have returned
+// the maximum number of results of their namespace. A nil set of quotas allows all streams.
+func StreamServerInterceptor(quotas *Quotas) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if quotas == nil {
+			return handler(srv, stream)
+		}
+		return handler(srv, &quotaStream{ServerStream: stream, quotas: quotas})
+	}
+}
+
+// quotaStream checks the quotas of the requests received and the results sent on a stream.
+type quotaStream struct {
+	grpc.ServerStream
+
+	quotas *Quotas
+
+	lookupNamespace  string
+	maxLookupResults uint64
+	sent             uint64
+}
+
+func (s *quotaStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	switch r := m.(type) {
+	case *v1.BulkImportRelationshipsRequest:
+		operations := make([]v1.RelationshipUpdate_Operation, len(r.Relationships))
+		for i := range operations {
+			operations[i] = v1.RelationshipUpdate_OPERATION_CREATE
+		}
+		return s.quotas.admitWrites(countUpdates(r.Relationships, operations))
+
+	case *v1.LookupResourcesRequest:
+		s.setLookupNamespace(r.ResourceObjectType)
+
+	case *v1.LookupSubjectsRequest:
+		s.setLookupNamespace(r.SubjectObjectType)
+	}
+	return nil
+}
+
+func (s *quotaStream) setLookupNamespace(namespace string) {
+	s.lookupNamespace = namespace
+	s.maxLookupResults = s.quotas.limits[namespace].MaxLookupResults
+}
+
+func (s *quotaStream) SendMsg(m any) error {
+	if s.maxLookupResults > 0 {
+		if s.sent >= s.maxLookupResults {
+			return quotaError(s.lookupNamespace, quotaLookupResults,
+				fmt.Errorf("lookups of namespace `%s` may return at most %d results", s.lookupNamespace, s.maxLookupResults),
+				strconv.FormatUint(s.maxLookupResults, 10), 0)
+		}
+		s.sent++
+	}
+	return s.ServerStream.SendMsg(m)
+}
@@ -0,0 +1,177 @@
+package namespacequota
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/testfixtures"
+)
+
+func TestParseLimits(t *testing.T) {
+	limits, err := ParseLimits(
+		map[string]string{"document": "1000"},
+		map[string]string{"document": "2.5", "folder": "10"},
+		map[string]string{"folder": "50", "user": "0"},
+	)
+	require.NoError(t, err)
+	require.Equal(t, map[string]Limits{
+		"document": {MaxRelationships: 1000, MaxWritesPerSecond: 2.5},
+		"folder":   {MaxWritesPerSecond: 10, MaxLookupResults: 50},
+	}, limits)
+
+	_, err = ParseLimits(map[string]string{"document": "lots"}, nil, nil)
+	require.ErrorContains(t, err, "invalid maximum relationship count for namespace `document`")
+
+	_, err = ParseLimits(nil, map[string]string{"document": "-1"}, nil)
+	require.ErrorContains(t, err, "must be a non-negative number")
+
+	require.Nil(t, NewQuotas(map[string]Limits{}))
+}
+
+func TestWriteRate(t *testing.T) {
+	quotas := NewQuotas(map[string]Limits{"document": {MaxWritesPerSecond: 2}})
+	now := time.Now()
+	quotas.now = func() time.Time { return now }
+	interceptor := UnaryServerInterceptor(quotas)
+
+	write := func(namespaces ...string) error {
+		req := &v1.WriteRelationshipsRequest{}
+		for _, namespace := range namespaces {
+			req.Updates = append(req.Updates, &v1.RelationshipUpdate{
+				Operation:    v1.RelationshipUpdate_OPERATION_TOUCH,
+				Relationship: &v1.Relationship{Resource: &v1.ObjectReference{ObjectType: namespace, ObjectId: "first"}},
+			})
+		}
+		_, err := interceptor(context.Background(), req, &grpc.UnaryServerInfo{}, func(context.Context, any) (any, error) {
+			return nil, nil
+		})
+		return err
+	}
+
+	require.NoError(t, write("document", "document", "folder", "folder", "folder"))
+
+	err := write("document")
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	require.ErrorContains(t, err, "write rate limit exceeded for namespace `document`")
+
+	// Other namespaces are not limited.
+	require.NoError(t, write("folder"))
+
+	// Requests larger than the burst can never be admitted.
+	now = now.Add(time.Minute)
+	err = write("document", "document", "document")
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	require.ErrorContains(t, err, "more than its limit of 2 updates per second")
+
+	require.NoError(t, write("document"))
+}
+
+func TestMaxRelationships(t *testing.T) {
+	require := require.New(t)
+
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(err)
+	ds, _ := testfixtures.StandardDatastoreWithData(rawDS, require)
+
+	quotas := NewQuotas(map[string]Limits{"folder": {MaxRelationships: 5}})
+	require.NoError(quotas.refresh(context.Background(), ds))
+	existing := quotas.counts["folder"]
+	require.Positive(existing)
+	require.LessOrEqual(existing, uint64(6))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.NoError(quotas.Run(ctx, ds, time.Minute))
+
+	quotas.counts["folder"] = 4
+	touch := func(operation v1.RelationshipUpdate_Operation) error {
+		_, err := UnaryServerInterceptor(quotas)(context.Background(), &v1.WriteRelationshipsRequest{Updates: []*v1.RelationshipUpdate{{
+			Operation:    operation,
+			Relationship: &v1.Relationship{Resource: &v1.ObjectReference{ObjectType: "folder", ObjectId: "new"}},
+		}}}, &grpc.UnaryServerInfo{}, func(context.Context, any) (any, error) {
+			return nil, nil
+		})
+		return err
+	}
+
+	require.NoError(touch(v1.RelationshipUpdate_OPERATION_CREATE))
+	require.Equal(uint64(5), quotas.counts["folder"])
+
+	err = touch(v1.RelationshipUpdate_OPERATION_TOUCH)
+	require.Equal(codes.ResourceExhausted, status.Code(err))
+	require.ErrorContains(err, "namespace `folder` has reached its maximum of 5 relationships")
+
+	// Deletes are allowed at the limit.
+	require.NoError(touch(v1.RelationshipUpdate_OPERATION_DELETE))
+}
+
+type fakeStream struct {
+	grpc.ServerStream
+
+	req  any
+	sent int
+}
+
+func (fs *fakeStream) Context() context.Context { return context.Background() }
+
+func (fs *fakeStream) RecvMsg(m any) error {
+	proto.Merge(m.(proto.Message), fs.req.(proto.Message))
+	return nil
+}
+
+func (fs *fakeStream) SendMsg(any) error {
+	fs.sent++
+	return nil
+}
+
+func TestLookupResults(t *testing.T) {
+	interceptor := StreamServerInterceptor(NewQuotas(map[string]Limits{"document": {MaxLookupResults: 2}}))
+
+	lookup := func(resourceType string) (*fakeStream, error) {
+		stream := &fakeStream{req: &v1.LookupResourcesRequest{ResourceObjectType: resourceType}}
+		err := interceptor(nil, stream, &grpc.StreamServerInfo{}, func(_ any, stream grpc.ServerStream) error {
+			if err := stream.RecvMsg(&v1.LookupResourcesRequest{}); err != nil {
+				return err
+			}
+			for i := 0; i < 3; i++ {
+				if err := stream.SendMsg(&v1.LookupResourcesResponse{}); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		return stream, err
+	}
+
+	stream, err := lookup("document")
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	require.ErrorContains(t, err, "lookups of namespace `document` may return at most 2 results")
+	require.Equal(t, 2, stream.sent)
+
+	stream, err = lookup("folder")
+	require.NoError(t, err)
+	require.Equal(t, 3, stream.sent)
+}
+
+func TestBulkImport(t *testing.T) {
+	interceptor := StreamServerInterceptor(NewQuotas(map[string]Limits{"document": {MaxRelationships: 1}}))
+
+	stream := &fakeStream{req: &v1.BulkImportRelationshipsRequest{Relationships: []*v1.Relationship{
+		{Resource: &v1.ObjectReference{ObjectType: "document", ObjectId: "first"}},
+		{Resource: &v1.ObjectReference{ObjectType: "document", ObjectId: "second"}},
+	}}}
+	err := interceptor(nil, stream, &grpc.StreamServerInfo{}, func(_ any, stream grpc.ServerStream) error {
+		return stream.RecvMsg(&v1.BulkImportRelationshipsRequest{})
+	})
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	require.ErrorContains(t, err, "namespace `document` has reached its maximum of 1 relationships")
+}
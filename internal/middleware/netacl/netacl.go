@@ -0,0 +1,158 @@
+// Package netacl rejects calls from peers outside of the networks allowed to reach a listener,
+// as a defense in depth for servers exposed on shared networks.
+package netacl
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	log "github.com/authzed/spicedb/internal/logging"
+)
+
+var rejectedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "middleware",
+	Name:      "network_acl_rejected_total",
+	Help:      "Count of the requests rejected because their peer address is not allowed to reach the listener",
+}, []string{"listener"})
+
+// ACL is the networks allowed and denied to reach a listener.
+type ACL struct {
+	listener string
+	allowed  []netip.Prefix
+	denied   []netip.Prefix
+}
+
+// New parses the CIDRs allowed and denied to reach the named listener. It returns nil if both
+// are empty, in which case every peer is allowed.
+func New(listener string, allowedCIDRs, deniedCIDRs []string) (*ACL, error) {
+	if len(allowedCIDRs) == 0 && len(deniedCIDRs) == 0 {
+		return nil, nil
+	}
+
+	allowed, err := parsePrefixes(allowedCIDRs)
+	if err != nil {
+		return nil, err
+	}
+	denied, err := parsePrefixes(deniedCIDRs)
+	if err != nil {
+		return nil, err
+	}
+	return &ACL{listener: listener, allowed: allowed, denied: denied}, nil
+}
+
+func parsePrefixes(cidrs []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid CIDR `%s`: %w", cidr, err)
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes, nil
+}
+
+// Allows returns whether the address may reach the listener: it must not be within a denied
+// network and, if any networks are allowed, must be within one of them. Addresses other than
+// IP addresses, such as those of unix sockets and in-memory connections, are always allowed,
+// since their peers are local.
+func (a *ACL) Allows(addr netip.Addr) bool {
+	if a == nil || !addr.IsValid() {
+		return true
+	}
+
+	addr = addr.Unmap()
+	for _, prefix := range a.denied {
+		if prefix.Contains(addr) {
+			return false
+		}
+	}
+
+	if len(a.allowed) == 0 {
+		return true
+	}
+	for _, prefix := range a.allowed {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// check returns a PermissionDenied error if the peer of the call is not allowed.
+func (a *ACL) check(ctx context.Context) error {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return nil
+	}
+
+	var addr netip.Addr
+	if tcpAddr, ok := p.Addr.(*net.TCPAddr); ok {
+		addr = tcpAddr.AddrPort().Addr()
+	}
+	return a.reject(ctx, addr)
+}
+
+func (a *ACL) reject(ctx context.Context, addr netip.Addr) error {
+	if a.Allows(addr) {
+		return nil
+	}
+
+	rejectedCounter.WithLabelValues(a.listener).Inc()
+	log.Ctx(ctx).Warn().Str("listener", a.listener).Stringer("peer", addr).Msg("rejected request from peer not allowed to reach the listener")
+	return status.Errorf(codes.PermissionDenied, "peer address %s is not allowed", addr)
+}
+
+// UnaryServerInterceptor returns a new interceptor which rejects calls from peers not allowed
+// by the ACL. A nil ACL allows every call.
+func UnaryServerInterceptor(acl *ACL) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if err := acl.check(ctx); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a new interceptor which rejects streams from peers not
+// allowed by the ACL. A nil ACL allows every stream.
+func StreamServerInterceptor(acl *ACL) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := acl.check(stream.Context()); err != nil {
+			return err
+		}
+		return handler(srv, stream)
+	}
+}
+
+// HTTPHandler wraps the handler to respond with 403 Forbidden to requests from peers not
+// allowed by the ACL. A nil ACL allows every request.
+func HTTPHandler(acl *ACL, next http.Handler) http.Handler {
+	if acl == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var addr netip.Addr
+		if addrPort, err := netip.ParseAddrPort(r.RemoteAddr); err == nil {
+			addr = addrPort.Addr()
+		}
+
+		if err := acl.reject(r.Context(), addr); err != nil {
+			http.Error(w, status.Convert(err).Message(), http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
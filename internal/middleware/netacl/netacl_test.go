@@ -0,0 +1,150 @@
+package netacl
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func TestNew(t *testing.T) {
+	acl, err := New("grpc", nil, nil)
+	require.NoError(t, err)
+	require.Nil(t, acl)
+	require.True(t, acl.Allows(netip.MustParseAddr("10.0.0.1")))
+
+	_, err = New("grpc", []string{"10.0.0.0/8", "10.0.0.1"}, nil)
+	require.ErrorContains(t, err, "invalid CIDR `10.0.0.1`")
+
+	_, err = New("grpc", nil, []string{"not a cidr"})
+	require.ErrorContains(t, err, "invalid CIDR `not a cidr`")
+}
+
+func TestAllows(t *testing.T) {
+	tcs := []struct {
+		name     string
+		allowed  []string
+		denied   []string
+		addr     string
+		expected bool
+	}{
+		{"allowed", []string{"10.0.0.0/8"}, nil, "10.1.2.3", true},
+		{"not allowed", []string{"10.0.0.0/8"}, nil, "192.168.1.1", false},
+		{"denied", nil, []string{"192.168.0.0/16"}, "192.168.1.1", false},
+		{"not denied", nil, []string{"192.168.0.0/16"}, "10.1.2.3", true},
+		{"denied within allowed", []string{"10.0.0.0/8"}, []string{"10.1.0.0/16"}, "10.1.2.3", false},
+		{"unmasked CIDR", []string{"10.1.2.3/8"}, nil, "10.200.0.1", true},
+		{"IPv4-mapped IPv6", []string{"10.0.0.0/8"}, nil, "::ffff:10.1.2.3", true},
+		{"IPv6", []string{"fd00::/8"}, nil, "fd12::1", true},
+		{"IPv6 not allowed", []string{"10.0.0.0/8"}, nil, "fd12::1", false},
+		{"not an IP address", []string{"10.0.0.0/8"}, nil, "", true},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			acl, err := New("grpc", tc.allowed, tc.denied)
+			require.NoError(t, err)
+
+			var addr netip.Addr
+			if tc.addr != "" {
+				addr = netip.MustParseAddr(tc.addr)
+			}
+			require.Equal(t, tc.expected, acl.Allows(addr))
+		})
+	}
+}
+
+func TestInterceptors(t *testing.T) {
+	tcs := []struct {
+		name         string
+		listener     string
+		allowed      []string
+		expectedCode codes.Code
+	}{
+		{"allowed", "test-allowed", []string{"127.0.0.0/8", "::1/128"}, codes.OK},
+		{"rejected", "test-rejected", []string{"10.0.0.0/8"}, codes.PermissionDenied},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			acl, err := New(tc.listener, tc.allowed, nil)
+			require.NoError(t, err)
+
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			require.NoError(t, err)
+
+			server := grpc.NewServer(
+				grpc.ChainUnaryInterceptor(UnaryServerInterceptor(acl)),
+				grpc.ChainStreamInterceptor(StreamServerInterceptor(acl)),
+			)
+			healthpb.RegisterHealthServer(server, health.NewServer())
+			go func() {
+				_ = server.Serve(listener)
+			}()
+			t.Cleanup(server.Stop)
+
+			conn, err := grpc.Dial(listener.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+			require.NoError(t, err)
+			t.Cleanup(func() { _ = conn.Close() })
+			client := healthpb.NewHealthClient(conn)
+
+			_, err = client.Check(context.Background(), &healthpb.HealthCheckRequest{})
+			require.Equal(t, tc.expectedCode, status.Code(err))
+
+			stream, err := client.Watch(context.Background(), &healthpb.HealthCheckRequest{})
+			require.NoError(t, err)
+			_, err = stream.Recv()
+			require.Equal(t, tc.expectedCode, status.Code(err))
+
+			rejected := 0.0
+			if tc.expectedCode != codes.OK {
+				rejected = 2
+			}
+			require.Equal(t, rejected, testutil.ToFloat64(rejectedCounter.WithLabelValues(tc.listener)))
+		})
+	}
+}
+
+func TestHTTPHandler(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	acl, err := New("test-http", []string{"10.0.0.0/8"}, nil)
+	require.NoError(t, err)
+	handler := HTTPHandler(acl, ok)
+
+	tcs := []struct {
+		remoteAddr     string
+		expectedStatus int
+	}{
+		{"10.1.2.3:1234", http.StatusOK},
+		{"192.168.1.1:1234", http.StatusForbidden},
+		{"[::ffff:10.1.2.3]:1234", http.StatusOK},
+		{"@", http.StatusOK},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.remoteAddr, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			req.RemoteAddr = tc.remoteAddr
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, req)
+			require.Equal(t, tc.expectedStatus, recorder.Code)
+		})
+	}
+	require.Equal(t, 1.0, testutil.ToFloat64(rejectedCounter.WithLabelValues("test-http")))
+}
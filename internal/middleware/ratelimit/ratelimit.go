@@ -0,0 +1,300 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"golang.org/x/time/rate"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/authzed/spicedb/internal/auth"
+	"github.com/authzed/spicedb/pkg/spiceerrors"
+)
+
+var throttledCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "middleware",
+	Name:      "ratelimit_throttled_total",
+	Help:      "Count of the requests rejected for exceeding the per-caller rate limit",
+}, []string{"method", "kind"})
+
+var inFlightRejectedCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "middleware",
+	Name:      "inflight_rejected_total",
+	Help:      "Count of the requests rejected for exceeding the maximum number of in-flight requests",
+}, []string{"method"})
+
+const (
+	kindRead  = "read"
+	kindWrite = "write"
+
+	reasonRateLimited     = "ERROR_REASON_RATE_LIMITED"
+	reasonTooManyInFlight = "ERROR_REASON_TOO_MANY_REQUESTS_IN_FLIGHT"
+
+	priorityHigh = "high"
+	priorityLow  = "low"
+
+	// idleTimeout is how long a caller's limiter is retained after its last request.
+	idleTimeout = 10 * time.Minute
+
+	// defaultInFlightRetryAfter is the retry hint returned for requests shed because too
+	// many requests are in flight, when none is configured.
+	defaultInFlightRetryAfter = time.Second
+)
+
+// writeMethodPrefixes are the prefixes of the names of API methods which are rate limited as writes.
+var writeMethodPrefixes = []string{"Write", "Delete", "BulkImport"}
+
+// lowPriorityMethodPrefixes are the prefixes of the names of API methods which are shed first
+// when the node is saturated, as they are long-running and hold more memory than checks and writes.
+var lowPriorityMethodPrefixes = []string{"Lookup", "Watch", "ReadRelationships", "BulkExport"}
+
+// Config configures the rate limits applied to each caller and the cap on concurrent
+// requests. A rate of zero disables limiting of that kind of request.
+type Config struct {
+	ReadsPerSecond  float64
+	ReadBurst       int
+	WritesPerSecond float64
+	WriteBurst      int
+
+	// MaxInFlightRequests caps the number of requests, across all callers, being
+	// handled at once. Zero places no cap.
+	MaxInFlightRequests uint32
+
+	// LowPriorityInFlightPercent is the percentage of MaxInFlightRequests beyond which
+	// low priority requests, such as lookups and watches, are shed so that the remaining
+	// capacity is kept for checks and writes. Zero or 100 gives every request the same priority.
+	LowPriorityInFlightPercent uint32
+
+	// InFlightRetryAfter is the delay suggested to clients whose requests are shed
+	// because too many requests are in flight. Defaults to one second.
+	InFlightRetryAfter time.Duration
+}
+
+// Limiter applies token-bucket rate limits to requests, keyed by the calling principal, and
+// caps the number of requests in flight, shedding low priority requests first.
+type Limiter struct {
+	limits atomic.Pointer[limits]
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+	now       func() time.Time
+
+	inFlight atomic.Int64
+}
+
+// limits is a Config completed with its defaults and the derived low priority cap.
+type limits struct {
+	Config
+	lowPriorityInFlight int64
+}
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// NewLimiter returns a Limiter with the given configuration, or nil if no limits are configured.
+func NewLimiter(config Config) *Limiter {
+	if config.ReadsPerSecond <= 0 && config.WritesPerSecond <= 0 && config.MaxInFlightRequests == 0 {
+		return nil
+	}
+	return NewUpdatableLimiter(config)
+}
+
+// NewUpdatableLimiter returns a Limiter with the given configuration even if no limits are
+// configured, so that limits can later be applied with Update.
+func NewUpdatableLimiter(config Config) *Limiter {
+	l := &Limiter{
+		buckets: map[string]*bucket{},
+		now:     time.Now,
+	}
+	l.limits.Store(newLimits(config))
+	return l
+}
+
+// Update replaces the configuration of the limiter. Requests in flight are unaffected, and
+// callers start again with a full bucket under the new rates.
+func (l *Limiter) Update(config Config) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.limits.Store(newLimits(config))
+	clear(l.buckets)
+}
+
+func newLimits(config Config) *limits {
+	if config.InFlightRetryAfter <= 0 {
+		config.InFlightRetryAfter = defaultInFlightRetryAfter
+	}
+
+	lowPriorityInFlight := int64(config.MaxInFlightRequests)
+	if config.LowPriorityInFlightPercent > 0 && config.LowPriorityInFlightPercent < 100 {
+		lowPriorityInFlight = max(lowPriorityInFlight*int64(config.LowPriorityInFlightPercent)/100, 1)
+	}
+	return &limits{Config: config, lowPriorityInFlight: lowPriorityInFlight}
+}
+
+// allow returns whether a request of the given kind from the principal is within its limit
+// and, if not, how long until it would be.
+func (l *Limiter) allow(principal, kind string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	limits := l.limits.Load()
+	limit, burst := rate.Limit(limits.ReadsPerSecond), limits.ReadBurst
+	if kind == kindWrite {
+		limit, burst = rate.Limit(limits.WritesPerSecond), limits.WriteBurst
+	}
+	if limit <= 0 {
+		return true, 0
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	now := l.now()
+
+	if now.Sub(l.lastSweep) > idleTimeout {
+		for key, b := range l.buckets {
+			if now.Sub(b.lastSeen) > idleTimeout {
+				delete(l.buckets, key)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	key := kind + "/" + principal
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{limiter: rate.NewLimiter(limit, burst)}
+		l.buckets[key] = b
+	}
+	b.lastSeen = now
+
+	reservation := b.limiter.ReserveN(now, 1)
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+		return false, delay
+	}
+	return true, 0
+}
+
+// acquire admits the request, returning a function to be called once it has been handled,
+// or an error if the caller is over its rate limit or too many requests are in flight.
+func (l *Limiter) acquire(ctx context.Context, fullMethod string) (func(), error) {
+	kind := kindFor(fullMethod)
+	if ok, retryAfter := l.allow(auth.PrincipalFromContext(ctx), kind); !ok {
+		throttledCounter.WithLabelValues(fullMethod, kind).Inc()
+		return nil, spiceerrors.WithCodeAndDetailsAsError(
+			fmt.Errorf("rate limit exceeded for %s requests", kind),
+			codes.ResourceExhausted,
+			&errdetails.ErrorInfo{
+				Reason: reasonRateLimited,
+				Domain: spiceerrors.Domain,
+				Metadata: map[string]string{
+					"request_kind": kind,
+				},
+			},
+			&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)},
+		)
+	}
+
+	limits := l.limits.Load()
+	if limits.MaxInFlightRequests == 0 {
+		return func() {}, nil
+	}
+
+	priority := priorityFor(fullMethod)
+	limit := int64(limits.MaxInFlightRequests)
+	if priority == priorityLow {
+		limit = limits.lowPriorityInFlight
+	}
+
+	if l.inFlight.Add(1) > limit {
+		l.inFlight.Add(-1)
+		inFlightRejectedCounter.WithLabelValues(fullMethod).Inc()
+		return nil, spiceerrors.WithCodeAndDetailsAsError(
+			fmt.Errorf("too many requests in flight; maximum allowed for %s priority requests is %d", priority, limit),
+			codes.ResourceExhausted,
+			&errdetails.ErrorInfo{
+				Reason: reasonTooManyInFlight,
+				Domain: spiceerrors.Domain,
+				Metadata: map[string]string{
+					"maximum_in_flight_requests": strconv.FormatInt(limit, 10),
+					"request_priority":           priority,
+				},
+			},
+			&errdetails.RetryInfo{RetryDelay: durationpb.New(limits.InFlightRetryAfter)},
+		)
+	}
+	return func() { l.inFlight.Add(-1) }, nil
+}
+
+func kindFor(fullMethod string) string {
+	method := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+	for _, prefix := range writeMethodPrefixes {
+		if strings.HasPrefix(method, prefix) {
+			return kindWrite
+		}
+	}
+	return kindRead
+}
+
+func priorityFor(fullMethod string) string {
+	method := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+	for _, prefix := range lowPriorityMethodPrefixes {
+		if strings.HasPrefix(method, prefix) {
+			return priorityLow
+		}
+	}
+	return priorityHigh
+}
+
+// UnaryServerInterceptor returns a new interceptor which rejects requests from callers
+// that have exceeded their rate limit, or when too many requests are in flight. A nil
+// limiter allows all requests.
+func UnaryServerInterceptor(limiter *Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if limiter == nil {
+			return handler(ctx, req)
+		}
+
+		release, err := limiter.acquire(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a new interceptor which rejects streams from callers
+// that have exceeded their rate limit, or when too many requests are in flight. A nil
+// limiter allows all streams.
+func StreamServerInterceptor(limiter *Limiter) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if limiter == nil {
+			return handler(srv, stream)
+		}
+
+		release, err := limiter.acquire(stream.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		defer release()
+		return handler(srv, stream)
+	}
+}
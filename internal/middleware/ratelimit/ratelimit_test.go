@@ -0,0 +1,226 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/authzed/grpcutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	checkMethod = "/authzed.api.v1.PermissionsService/CheckPermission"
+	writeMethod = "/authzed.api.v1.PermissionsService/WriteRelationships"
+)
+
+func TestNewLimiterDisabled(t *testing.T) {
+	require.Nil(t, NewLimiter(Config{}))
+
+	interceptor := UnaryServerInterceptor(nil)
+	_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: checkMethod}, func(ctx context.Context, req any) (any, error) {
+		return nil, nil
+	})
+	require.NoError(t, err)
+}
+
+func TestKindFor(t *testing.T) {
+	require.Equal(t, kindRead, kindFor(checkMethod))
+	require.Equal(t, kindRead, kindFor("/authzed.api.v1.SchemaService/ReadSchema"))
+	require.Equal(t, kindWrite, kindFor(writeMethod))
+	require.Equal(t, kindWrite, kindFor("/authzed.api.v1.PermissionsService/DeleteRelationships"))
+	require.Equal(t, kindWrite, kindFor("/authzed.api.v1.SchemaService/WriteSchema"))
+	require.Equal(t, kindWrite, kindFor("/authzed.api.v1.ExperimentalService/BulkImportRelationships"))
+}
+
+func TestLimiter(t *testing.T) {
+	now := time.Now()
+	limiter := NewLimiter(Config{ReadsPerSecond: 1, ReadBurst: 2, WritesPerSecond: 1})
+	limiter.now = func() time.Time { return now }
+	allowed := func(principal, kind string) bool {
+		ok, _ := limiter.allow(principal, kind)
+		return ok
+	}
+
+	// Reads are allowed up to the burst, independently per principal.
+	require.True(t, allowed("first", kindRead))
+	require.True(t, allowed("first", kindRead))
+	require.False(t, allowed("first", kindRead))
+	require.True(t, allowed("second", kindRead))
+
+	// Rejected requests are told when they may be retried.
+	_, retryAfter := limiter.allow("first", kindRead)
+	require.Equal(t, time.Second, retryAfter)
+
+	// Writes have their own limit, with a burst of at least one.
+	require.True(t, allowed("first", kindWrite))
+	require.False(t, allowed("first", kindWrite))
+
+	// Tokens are replenished over time.
+	now = now.Add(time.Second)
+	require.True(t, allowed("first", kindRead))
+	require.True(t, allowed("first", kindWrite))
+
+	// Idle callers are forgotten.
+	require.Len(t, limiter.buckets, 3)
+	now = now.Add(2 * idleTimeout)
+	require.True(t, allowed("third", kindRead))
+	require.Len(t, limiter.buckets, 1)
+}
+
+func TestLimiterUnlimitedKind(t *testing.T) {
+	limiter := NewLimiter(Config{WritesPerSecond: 1})
+	for i := 0; i < 10; i++ {
+		ok, _ := limiter.allow("first", kindRead)
+		require.True(t, ok)
+	}
+}
+
+func TestLimiterUpdate(t *testing.T) {
+	limiter := NewUpdatableLimiter(Config{})
+	require.NotNil(t, limiter)
+	allowed := func() bool {
+		ok, _ := limiter.allow("first", kindRead)
+		return ok
+	}
+
+	require.True(t, allowed())
+	require.True(t, allowed())
+
+	limiter.Update(Config{ReadsPerSecond: 0.001, ReadBurst: 1})
+	require.True(t, allowed())
+	require.False(t, allowed())
+
+	// Raising the limit starts callers again with a full bucket.
+	limiter.Update(Config{ReadsPerSecond: 0.001, ReadBurst: 2})
+	require.True(t, allowed())
+	require.True(t, allowed())
+	require.False(t, allowed())
+
+	limiter.Update(Config{})
+	require.True(t, allowed())
+	require.Empty(t, limiter.buckets)
+}
+
+func TestInterceptors(t *testing.T) {
+	limiter := NewLimiter(Config{ReadsPerSecond: 0.001, ReadBurst: 1})
+	ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs("authorization", "bearer somekey"))
+
+	unary := UnaryServerInterceptor(limiter)
+	handler := func(ctx context.Context, req any) (any, error) { return "ok", nil }
+	info := &grpc.UnaryServerInfo{FullMethod: checkMethod}
+
+	resp, err := unary(ctx, nil, info, handler)
+	require.NoError(t, err)
+	require.Equal(t, "ok", resp)
+
+	_, err = unary(ctx, nil, info, handler)
+	grpcutil.RequireStatus(t, codes.ResourceExhausted, err)
+
+	stream := StreamServerInterceptor(limiter)
+	err = stream(nil, &testServerStream{ctx: ctx}, &grpc.StreamServerInfo{FullMethod: "/authzed.api.v1.PermissionsService/LookupResources"}, func(srv any, stream grpc.ServerStream) error {
+		return nil
+	})
+	grpcutil.RequireStatus(t, codes.ResourceExhausted, err)
+}
+
+func TestInFlightLimit(t *testing.T) {
+	limiter := NewLimiter(Config{MaxInFlightRequests: 1})
+	require.NotNil(t, limiter)
+
+	unary := UnaryServerInterceptor(limiter)
+	info := &grpc.UnaryServerInfo{FullMethod: checkMethod}
+
+	// While one request is being handled, others are rejected.
+	resp, err := unary(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		_, err := unary(ctx, nil, info, func(ctx context.Context, req any) (any, error) {
+			return "nested", nil
+		})
+		grpcutil.RequireStatus(t, codes.ResourceExhausted, err)
+
+		st, ok := status.FromError(err)
+		require.True(t, ok)
+		require.Len(t, st.Details(), 2)
+		info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+		require.True(t, ok)
+		require.Equal(t, reasonTooManyInFlight, info.Reason)
+		require.Equal(t, "1", info.Metadata["maximum_in_flight_requests"])
+		retryInfo, ok := st.Details()[1].(*errdetails.RetryInfo)
+		require.True(t, ok)
+		require.Equal(t, time.Second, retryInfo.RetryDelay.AsDuration())
+
+		return "ok", nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "ok", resp)
+
+	// Once it completes, its slot is released.
+	resp, err = unary(context.Background(), nil, info, func(ctx context.Context, req any) (any, error) {
+		return "ok", nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "ok", resp)
+}
+
+func TestInFlightPriority(t *testing.T) {
+	limiter := NewLimiter(Config{MaxInFlightRequests: 4, LowPriorityInFlightPercent: 50, InFlightRetryAfter: 5 * time.Second})
+	require.Equal(t, int64(2), limiter.limits.Load().lowPriorityInFlight)
+
+	lookupMethod := "/authzed.api.v1.PermissionsService/LookupResources"
+	var releases []func()
+	acquire := func(method string) error {
+		release, err := limiter.acquire(context.Background(), method)
+		if err == nil {
+			releases = append(releases, release)
+		}
+		return err
+	}
+
+	// Low priority requests are shed once half of the capacity is in use.
+	require.NoError(t, acquire(lookupMethod))
+	require.NoError(t, acquire(checkMethod))
+	err := acquire(lookupMethod)
+	grpcutil.RequireStatus(t, codes.ResourceExhausted, err)
+
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	require.Equal(t, "2", info.Metadata["maximum_in_flight_requests"])
+	require.Equal(t, priorityLow, info.Metadata["request_priority"])
+	retryInfo, ok := st.Details()[1].(*errdetails.RetryInfo)
+	require.True(t, ok)
+	require.Equal(t, 5*time.Second, retryInfo.RetryDelay.AsDuration())
+
+	// High priority requests may use the remaining capacity.
+	require.NoError(t, acquire(checkMethod))
+	require.NoError(t, acquire(writeMethod))
+	grpcutil.RequireStatus(t, codes.ResourceExhausted, acquire(checkMethod))
+
+	for _, release := range releases {
+		release()
+	}
+	require.NoError(t, acquire(lookupMethod))
+}
+
+func TestPriorityFor(t *testing.T) {
+	require.Equal(t, priorityHigh, priorityFor(checkMethod))
+	require.Equal(t, priorityHigh, priorityFor(writeMethod))
+	require.Equal(t, priorityLow, priorityFor("/authzed.api.v1.PermissionsService/LookupSubjects"))
+	require.Equal(t, priorityLow, priorityFor("/authzed.api.v1.WatchService/Watch"))
+	require.Equal(t, priorityLow, priorityFor("/authzed.api.v1.PermissionsService/ReadRelationships"))
+}
+
+type testServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *testServerStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,46 @@
+package recovery
+
+import (
+	"context"
+	"runtime/debug"
+
+	grpcrecovery "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/recovery"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	log "github.com/authzed/spicedb/internal/logging"
+)
+
+var panicsCounter = promauto.NewCounter(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Name:      "panics_total",
+	Help:      "Count of the panics recovered while handling gRPC requests",
+})
+
+func recoverPanic(ctx context.Context, p any) error {
+	panicsCounter.Inc()
+
+	method, _ := grpc.Method(ctx)
+	log.Ctx(ctx).Error().
+		Str("method", method).
+		Interface("panic", p).
+		Bytes("stack", debug.Stack()).
+		Msg("recovered from panic while handling request")
+
+	return status.Error(codes.Internal, "internal error")
+}
+
+// UnaryServerInterceptor returns a new unary server interceptor that recovers from panics
+// in the handler, failing the request with INTERNAL rather than crashing the process.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return grpcrecovery.UnaryServerInterceptor(grpcrecovery.WithRecoveryHandlerContext(recoverPanic))
+}
+
+// StreamServerInterceptor returns a new stream server interceptor that recovers from panics
+// in the handler, failing the stream with INTERNAL rather than crashing the process.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return grpcrecovery.StreamServerInterceptor(grpcrecovery.WithRecoveryHandlerContext(recoverPanic))
+}
@@ -0,0 +1,41 @@
+package recovery
+
+import (
+	"context"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type fakeStream struct {
+	grpc.ServerStream
+}
+
+func (fs *fakeStream) Context() context.Context { return context.Background() }
+
+func TestRecovery(t *testing.T) {
+	before := testutil.ToFloat64(panicsCounter)
+
+	_, err := UnaryServerInterceptor()(context.Background(), nil, &grpc.UnaryServerInfo{}, func(context.Context, any) (any, error) {
+		panic("handler panicked")
+	})
+	require.Equal(t, codes.Internal, status.Code(err))
+
+	err = StreamServerInterceptor()(nil, &fakeStream{}, &grpc.StreamServerInfo{}, func(any, grpc.ServerStream) error {
+		panic("handler panicked")
+	})
+	require.Equal(t, codes.Internal, status.Code(err))
+
+	require.InDelta(t, before+2, testutil.ToFloat64(panicsCounter), 0)
+
+	// Requests that do not panic are unaffected.
+	resp, err := UnaryServerInterceptor()(context.Background(), nil, &grpc.UnaryServerInfo{}, func(context.Context, any) (any, error) {
+		return "ok", nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "ok", resp)
+}
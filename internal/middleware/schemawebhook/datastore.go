@@ -0,0 +1,307 @@
+package schemawebhook
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	caveatdiff "github.com/authzed/spicedb/pkg/diff/caveats"
+	nsdiff "github.com/authzed/spicedb/pkg/diff/namespace"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/generator"
+)
+
+type notifyingDatastore struct {
+	datastore.Datastore
+
+	notifier *Notifier
+	meta     requestMeta
+}
+
+// newNotifyingDatastore creates a proxy which records the definitions written and deleted in
+// each read-write transaction, along with their previous versions, and notifies the webhooks
+// of the changes once the transaction has been committed.
+func newNotifyingDatastore(delegate datastore.Datastore, notifier *Notifier, meta requestMeta) datastore.Datastore {
+	return notifyingDatastore{Datastore: delegate, notifier: notifier, meta: meta}
+}
+
+func (nd notifyingDatastore) Unwrap() datastore.Datastore {
+	return nd.Datastore
+}
+
+func (nd notifyingDatastore) ReadWriteTx(
+	ctx context.Context,
+	fn datastore.TxUserFunc,
+	opts ...options.RWTOptionsOption,
+) (datastore.Revision, error) {
+	var tx *notifyingTransaction
+	revision, err := nd.Datastore.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		// The function is retried on conflicts, so only the changes of the final attempt,
+		// which is committed, are recorded.
+		tx = &notifyingTransaction{
+			ReadWriteTransaction: rwt,
+			namespaces:           map[string]*definitionVersions[*core.NamespaceDefinition]{},
+			caveats:              map[string]*definitionVersions[*core.CaveatDefinition]{},
+		}
+		return fn(ctx, tx)
+	}, opts...)
+	if err != nil {
+		return revision, err
+	}
+
+	changes, err := tx.changes()
+	if err != nil {
+		return revision, err
+	}
+
+	if len(changes) > 0 {
+		nd.notifier.Notify(&Event{
+			Time:        time.Now().UTC(),
+			RequestID:   nd.meta.requestID,
+			Actor:       nd.meta.actor,
+			Method:      nd.meta.method,
+			Revision:    revision.String(),
+			Definitions: changes,
+		})
+	}
+	return revision, nil
+}
+
+// definitionVersions are the versions of a definition before and after a transaction, either
+// of which is nil if the definition did not exist.
+type definitionVersions[T datastore.SchemaDefinition] struct {
+	previous T
+	current  T
+}
+
+type notifyingTransaction struct {
+	datastore.ReadWriteTransaction
+
+	namespaces map[string]*definitionVersions[*core.NamespaceDefinition]
+	caveats    map[string]*definitionVersions[*core.CaveatDefinition]
+}
+
+// recordNamespaces records the versions of the namespaces before they are first written or
+// deleted in the transaction.
+func (nt *notifyingTransaction) recordNamespaces(ctx context.Context, names []string) error {
+	var unrecorded []string
+	for _, name := range names {
+		if _, ok := nt.namespaces[name]; !ok {
+			unrecorded = append(unrecorded, name)
+			nt.namespaces[name] = &definitionVersions[*core.NamespaceDefinition]{}
+		}
+	}
+	if len(unrecorded) == 0 {
+		return nil
+	}
+
+	existing, err := nt.LookupNamespacesWithNames(ctx, unrecorded)
+	if err != nil {
+		return err
+	}
+	for _, def := range existing {
+		nt.namespaces[def.Definition.Name].previous = def.Definition
+	}
+	return nil
+}
+
+// recordCaveats records the versions of the caveats before they are first written or deleted
+// in the transaction.
+func (nt *notifyingTransaction) recordCaveats(ctx context.Context, names []string) error {
+	var unrecorded []string
+	for _, name := range names {
+		if _, ok := nt.caveats[name]; !ok {
+			unrecorded = append(unrecorded, name)
+			nt.caveats[name] = &definitionVersions[*core.CaveatDefinition]{}
+		}
+	}
+	if len(unrecorded) == 0 {
+		return nil
+	}
+
+	existing, err := nt.LookupCaveatsWithNames(ctx, unrecorded)
+	if err != nil {
+		return err
+	}
+	for _, def := range existing {
+		nt.caveats[def.Definition.Name].previous = def.Definition
+	}
+	return nil
+}
+
+func (nt *notifyingTransaction) WriteNamespaces(ctx context.Context, newConfigs ...*core.NamespaceDefinition) error {
+	names := make([]string, 0, len(newConfigs))
+	for _, config := range newConfigs {
+		names = append(names, config.Name)
+	}
+	if err := nt.recordNamespaces(ctx, names); err != nil {
+		return err
+	}
+
+	if err := nt.ReadWriteTransaction.WriteNamespaces(ctx, newConfigs...); err != nil {
+		return err
+	}
+
+	for _, config := range newConfigs {
+		nt.namespaces[config.Name].current = config
+	}
+	return nil
+}
+
+func (nt *notifyingTransaction) DeleteNamespaces(ctx context.Context, nsNames ...string) error {
+	if err := nt.recordNamespaces(ctx, nsNames); err != nil {
+		return err
+	}
+
+	if err := nt.ReadWriteTransaction.DeleteNamespaces(ctx, nsNames...); err != nil {
+		return err
+	}
+
+	for _, name := range nsNames {
+		nt.namespaces[name].current = nil
+	}
+	return nil
+}
+
+func (nt *notifyingTransaction) WriteCaveats(ctx context.Context, caveats []*core.CaveatDefinition) error {
+	names := make([]string, 0, len(caveats))
+	for _, caveat := range caveats {
+		names = append(names, caveat.Name)
+	}
+	if err := nt.recordCaveats(ctx, names); err != nil {
+		return err
+	}
+
+	if err := nt.ReadWriteTransaction.WriteCaveats(ctx, caveats); err != nil {
+		return err
+	}
+
+	for _, caveat := range caveats {
+		nt.caveats[caveat.Name].current = caveat
+	}
+	return nil
+}
+
+func (nt *notifyingTransaction) DeleteCaveats(ctx context.Context, names []string) error {
+	if err := nt.recordCaveats(ctx, names); err != nil {
+		return err
+	}
+
+	if err := nt.ReadWriteTransaction.DeleteCaveats(ctx, names); err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		nt.caveats[name].current = nil
+	}
+	return nil
+}
+
+// changes returns the changes to the definitions recorded in the transaction, sorted by kind
+// and name. Definitions written without being changed, as WriteSchema does for every
+// definition of the schema, are omitted.
+func (nt *notifyingTransaction) changes() ([]DefinitionChange, error) {
+	var changes []DefinitionChange
+
+	for name, versions := range nt.namespaces {
+		diff, err := nsdiff.DiffNamespaces(versions.previous, versions.current)
+		if err != nil {
+			return nil, fmt.Errorf("error computing the diff of `%s`: %w", name, err)
+		}
+
+		change, err := definitionChange(KindNamespace, name, versions.previous, versions.current, generateNamespaceSource)
+		if err != nil {
+			return nil, err
+		}
+		for _, delta := range diff.Deltas() {
+			if delta.Type == nsdiff.NamespaceAdded || delta.Type == nsdiff.NamespaceRemoved {
+				continue
+			}
+			change.Deltas = append(change.Deltas, Delta{Type: string(delta.Type), Name: delta.RelationName})
+		}
+
+		if change.PreviousSchema != change.Schema {
+			changes = append(changes, change)
+		}
+	}
+
+	for name, versions := range nt.caveats {
+		diff, err := caveatdiff.DiffCaveats(versions.previous, versions.current)
+		if err != nil {
+			return nil, fmt.Errorf("error computing the diff of `%s`: %w", name, err)
+		}
+
+		change, err := definitionChange(KindCaveat, name, versions.previous, versions.current, generateCaveatSource)
+		if err != nil {
+			return nil, err
+		}
+		for _, delta := range diff.Deltas() {
+			if delta.Type == caveatdiff.CaveatAdded || delta.Type == caveatdiff.CaveatRemoved {
+				continue
+			}
+			change.Deltas = append(change.Deltas, Delta{Type: string(delta.Type), Name: delta.ParameterName})
+		}
+
+		if change.PreviousSchema != change.Schema {
+			changes = append(changes, change)
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].Kind != changes[j].Kind {
+			return changes[i].Kind > changes[j].Kind
+		}
+		return changes[i].Name < changes[j].Name
+	})
+	return changes, nil
+}
+
+// definitionChange returns the change between the previous and current versions of a
+// definition, without its deltas.
+func definitionChange[T interface {
+	datastore.SchemaDefinition
+	comparable
+}](kind, name string, previous, current T, generate func(T) (string, error)) (DefinitionChange, error) {
+	var zero T
+	change := DefinitionChange{Kind: kind, Name: name, Operation: OperationChanged}
+
+	if previous == zero {
+		change.Operation = OperationAdded
+	} else {
+		source, err := generate(previous)
+		if err != nil {
+			return change, err
+		}
+		change.PreviousSchema = source
+	}
+
+	if current == zero {
+		change.Operation = OperationRemoved
+	} else {
+		source, err := generate(current)
+		if err != nil {
+			return change, err
+		}
+		change.Schema = source
+	}
+	return change, nil
+}
+
+func generateNamespaceSource(def *core.NamespaceDefinition) (string, error) {
+	source, _, err := generator.GenerateSource(def)
+	if err != nil {
+		return "", fmt.Errorf("error generating the schema of `%s`: %w", def.Name, err)
+	}
+	return source, nil
+}
+
+func generateCaveatSource(def *core.CaveatDefinition) (string, error) {
+	source, _, err := generator.GenerateCaveatSource(def)
+	if err != nil {
+		return "", fmt.Errorf("error generating the schema of `%s`: %w", def.Name, err)
+	}
+	return source, nil
+}
@@ -0,0 +1,172 @@
+package schemawebhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	log "github.com/authzed/spicedb/internal/logging"
+)
+
+// SignatureHeader is the header holding the HMAC-SHA256 signature of the body of each
+// notification, as `sha256=<hex digest>`, when a secret is configured.
+const SignatureHeader = "X-SpiceDB-Signature"
+
+const (
+	queueSize        = 1024
+	maxRetryInterval = 30 * time.Second
+)
+
+var deliveryErrorsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "schemawebhook",
+	Name:      "delivery_errors_total",
+	Help:      "total number of schema change notifications which could not be delivered",
+}, []string{"reason"})
+
+// Notifier delivers the notifications of schema changes to the webhooks in the background,
+// in the order the changes were committed, retrying failed deliveries.
+type Notifier struct {
+	urls        []string
+	secret      []byte
+	maxAttempts int
+	client      *http.Client
+
+	events chan *Event
+	done   chan struct{}
+	once   sync.Once
+}
+
+// NewNotifier creates a notifier which POSTs each event as JSON to the webhook URLs. Each
+// delivery is attempted up to the maximum number of attempts, with exponential backoff,
+// when the webhook cannot be reached or responds with a 429 or 5xx status. If the secret
+// is not empty, the body is signed with it.
+func NewNotifier(urls []string, secret string, timeout time.Duration, maxAttempts int) (*Notifier, error) {
+	if len(urls) == 0 {
+		return nil, errors.New("at least one schema webhook URL is required")
+	}
+	for _, webhookURL := range urls {
+		parsed, err := url.Parse(webhookURL)
+		if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return nil, fmt.Errorf("invalid schema webhook URL `%s`", webhookURL)
+		}
+	}
+	if timeout <= 0 {
+		return nil, errors.New("schema webhook timeout must be positive")
+	}
+	if maxAttempts <= 0 {
+		return nil, errors.New("schema webhook maximum attempts must be positive")
+	}
+
+	n := &Notifier{
+		urls:        urls,
+		secret:      []byte(secret),
+		maxAttempts: maxAttempts,
+		client:      &http.Client{Timeout: timeout},
+		events:      make(chan *Event, queueSize),
+		done:        make(chan struct{}),
+	}
+	go n.run()
+	return n, nil
+}
+
+// Notify queues the event for delivery. If the queue is full, because the webhooks are
+// failing or too slow, the event is dropped rather than blocking the request.
+func (n *Notifier) Notify(event *Event) {
+	select {
+	case n.events <- event:
+	default:
+		deliveryErrorsCounter.WithLabelValues("queue_full").Inc()
+		log.Warn().Str("revision", event.Revision).Msg("schema webhook queue is full; dropping notification")
+	}
+}
+
+// Close stops accepting events and waits for the queued events to be delivered.
+func (n *Notifier) Close() error {
+	n.once.Do(func() {
+		close(n.events)
+	})
+	<-n.done
+	n.client.CloseIdleConnections()
+	return nil
+}
+
+func (n *Notifier) run() {
+	defer close(n.done)
+
+	for event := range n.events {
+		body, err := json.Marshal(event)
+		if err != nil {
+			deliveryErrorsCounter.WithLabelValues("encoding").Inc()
+			log.Error().Err(err).Str("revision", event.Revision).Msg("error encoding schema webhook notification")
+			continue
+		}
+
+		for _, webhookURL := range n.urls {
+			if err := n.deliver(webhookURL, body); err != nil {
+				deliveryErrorsCounter.WithLabelValues("delivery").Inc()
+				log.Error().Err(err).Str("url", webhookURL).Str("revision", event.Revision).Msg("error delivering schema webhook notification")
+			}
+		}
+	}
+}
+
+// deliver POSTs the body to the webhook, retrying on retryable failures.
+func (n *Notifier) deliver(webhookURL string, body []byte) error {
+	backoffInterval := backoff.NewExponentialBackOff()
+	backoffInterval.MaxInterval = maxRetryInterval
+	backoffInterval.MaxElapsedTime = 0
+
+	return backoff.Retry(func() error {
+		return n.post(webhookURL, body)
+	}, backoff.WithMaxRetries(backoffInterval, uint64(n.maxAttempts-1)))
+}
+
+func (n *Notifier) post(webhookURL string, body []byte) error {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, webhookURL, bytes.NewReader(body))
+	if err != nil {
+		return backoff.Permanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(n.secret) > 0 {
+		req.Header.Set(SignatureHeader, Sign(n.secret, body))
+	}
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode <= 299:
+		return nil
+	case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	default:
+		return backoff.Permanent(fmt.Errorf("unexpected status %s", resp.Status))
+	}
+}
+
+// Sign returns the value of the signature header for the body signed with the secret, which
+// webhooks can compare against the header received to verify the notification.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
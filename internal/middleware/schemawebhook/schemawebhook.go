@@ -0,0 +1,132 @@
+// Package schemawebhook implements middleware which notifies HTTP webhooks of every change
+// made to the schema through the API, with the diff of each changed definition.
+package schemawebhook
+
+import (
+	"context"
+	"time"
+
+	middleware "github.com/grpc-ecosystem/go-grpc-middleware/v2"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/authzed/spicedb/internal/auth"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/pkg/middleware/requestid"
+)
+
+// Operations of changed definitions.
+const (
+	OperationAdded   = "ADDED"
+	OperationChanged = "CHANGED"
+	OperationRemoved = "REMOVED"
+)
+
+// Kinds of changed definitions.
+const (
+	KindNamespace = "namespace"
+	KindCaveat    = "caveat"
+)
+
+// Event is the body of the notification of the schema changes committed by a single API
+// request.
+type Event struct {
+	// Time is when the changes were committed.
+	Time time.Time `json:"time"`
+
+	// RequestID is the ID of the request which made the changes.
+	RequestID string `json:"request_id,omitempty"`
+
+	// Actor identifies the caller which made the changes.
+	Actor string `json:"actor"`
+
+	// Method is the full name of the gRPC method called.
+	Method string `json:"method"`
+
+	// Revision is the revision at which the changes were committed.
+	Revision string `json:"revision"`
+
+	// Definitions are the object and caveat definitions which were added, changed or removed.
+	Definitions []DefinitionChange `json:"definitions"`
+}
+
+// DefinitionChange is the change made to a single object or caveat definition.
+type DefinitionChange struct {
+	Operation string `json:"operation"`
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+
+	// PreviousSchema is the schema of the definition before the change, if it existed.
+	PreviousSchema string `json:"previous_schema,omitempty"`
+
+	// Schema is the schema of the definition after the change, unless it was removed.
+	Schema string `json:"schema,omitempty"`
+
+	// Deltas are the individual changes to the relations, permissions or parameters of the
+	// definition.
+	Deltas []Delta `json:"deltas,omitempty"`
+}
+
+// Delta is a single change to a definition, such as an added relation.
+type Delta struct {
+	// Type is the type of the change, such as `added-relation`.
+	Type string `json:"type"`
+
+	// Name is the name of the relation, permission or parameter changed, if any.
+	Name string `json:"name,omitempty"`
+}
+
+// requestMeta is the information about a request sent alongside its changes.
+type requestMeta struct {
+	requestID string
+	actor     string
+	method    string
+}
+
+func requestMetaFor(ctx context.Context, fullMethod string) requestMeta {
+	meta := requestMeta{
+		actor:  auth.PrincipalFromContext(ctx),
+		method: fullMethod,
+	}
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if requestIDs := md.Get(requestid.MetadataKey); len(requestIDs) > 0 {
+			meta.requestID = requestIDs[0]
+		}
+	}
+	return meta
+}
+
+// UnaryServerInterceptor returns a new unary server interceptor which notifies the webhooks
+// of the notifier of the schema changes of every read-write transaction committed by the
+// request. A nil notifier disables notifications.
+func UnaryServerInterceptor(notifier *Notifier) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if notifier == nil {
+			return handler(ctx, req)
+		}
+
+		ds := newNotifyingDatastore(datastoremw.MustFromContext(ctx), notifier, requestMetaFor(ctx, info.FullMethod))
+		if err := datastoremw.SetInContext(ctx, ds); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a new stream server interceptor which notifies the webhooks
+// of the notifier of the schema changes of every read-write transaction committed by the
+// stream. A nil notifier disables notifications.
+func StreamServerInterceptor(notifier *Notifier) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if notifier == nil {
+			return handler(srv, stream)
+		}
+
+		wrapped := middleware.WrapServerStream(stream)
+		ds := newNotifyingDatastore(datastoremw.MustFromContext(stream.Context()), notifier, requestMetaFor(stream.Context(), info.FullMethod))
+		if err := datastoremw.SetInContext(wrapped.WrappedContext, ds); err != nil {
+			return err
+		}
+		return handler(srv, wrapped)
+	}
+}
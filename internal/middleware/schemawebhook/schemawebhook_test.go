@@ -0,0 +1,191 @@
+package schemawebhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/authzed/spicedb/internal/auth"
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/pkg/caveats"
+	"github.com/authzed/spicedb/pkg/datastore"
+	ns "github.com/authzed/spicedb/pkg/namespace"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+const writeSchemaMethod = "/authzed.api.v1.SchemaService/WriteSchema"
+
+type webhook struct {
+	*httptest.Server
+
+	lock     sync.Mutex
+	failures int
+	events   []Event
+}
+
+// newWebhook starts a webhook which verifies the signature of each notification, if any, and
+// responds with a 503 to the given number of deliveries before accepting them.
+func newWebhook(t *testing.T, secret string, failures int) *webhook {
+	wh := &webhook{failures: failures}
+	wh.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		if secret == "" {
+			require.Empty(t, r.Header.Get(SignatureHeader))
+		} else {
+			require.Equal(t, Sign([]byte(secret), body), r.Header.Get(SignatureHeader))
+		}
+
+		wh.lock.Lock()
+		defer wh.lock.Unlock()
+		if wh.failures > 0 {
+			wh.failures--
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		var event Event
+		require.NoError(t, json.Unmarshal(body, &event))
+		wh.events = append(wh.events, event)
+	}))
+	t.Cleanup(wh.Close)
+	return wh
+}
+
+func (wh *webhook) received() []Event {
+	wh.lock.Lock()
+	defer wh.lock.Unlock()
+	return append([]Event(nil), wh.events...)
+}
+
+func TestNewNotifier(t *testing.T) {
+	_, err := NewNotifier(nil, "", time.Second, 1)
+	require.ErrorContains(t, err, "at least one schema webhook URL is required")
+
+	_, err = NewNotifier([]string{"localhost:8080"}, "", time.Second, 1)
+	require.ErrorContains(t, err, "invalid schema webhook URL")
+
+	_, err = NewNotifier([]string{"http://localhost:8080"}, "", 0, 1)
+	require.ErrorContains(t, err, "timeout must be positive")
+
+	_, err = NewNotifier([]string{"http://localhost:8080"}, "", time.Second, 0)
+	require.ErrorContains(t, err, "maximum attempts must be positive")
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	// The first delivery fails and is retried.
+	wh := newWebhook(t, "somesecret", 1)
+	notifier, err := NewNotifier([]string{wh.URL}, "somesecret", time.Second, 3)
+	require.NoError(t, err)
+
+	interceptor := UnaryServerInterceptor(notifier)
+	writeSchema := func(fn datastore.TxUserFunc) datastore.Revision {
+		ctx := datastoremw.ContextWithDatastore(context.Background(), ds)
+		ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-request-id", "some-request"))
+		ctx = auth.ContextWithScope(ctx, &auth.TokenScope{Subject: "some-service"})
+
+		var revision datastore.Revision
+		_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: writeSchemaMethod}, func(ctx context.Context, req any) (any, error) {
+			var err error
+			revision, err = datastoremw.MustFromContext(ctx).ReadWriteTx(ctx, fn)
+			return nil, err
+		})
+		require.NoError(t, err)
+		return revision
+	}
+
+	writeSchema(func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteNamespaces(ctx,
+			ns.Namespace("user"),
+			ns.Namespace("document", ns.MustRelation("viewer", nil, ns.AllowedRelation("user", "..."))),
+		)
+	})
+
+	// Definitions written without changes are not notified.
+	revision := writeSchema(func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		if err := rwt.WriteNamespaces(ctx,
+			ns.Namespace("user"),
+			ns.Namespace("document",
+				ns.MustRelation("viewer", nil, ns.AllowedRelation("user", "...")),
+				ns.MustRelation("editor", nil, ns.AllowedRelation("user", "...")),
+			),
+		); err != nil {
+			return err
+		}
+		return rwt.WriteCaveats(ctx, []*core.CaveatDefinition{ns.MustCaveatDefinition(
+			caveats.NewEnvironment(), "somecaveat", "1 == 1",
+		)})
+	})
+
+	// Schema writes which change nothing are not notified at all.
+	writeSchema(func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteNamespaces(ctx, ns.Namespace("user"))
+	})
+
+	require.NoError(t, notifier.Close())
+
+	events := wh.received()
+	require.Len(t, events, 2)
+	require.Equal(t, []DefinitionChange{
+		{Operation: OperationAdded, Kind: KindNamespace, Name: "document", Schema: "definition document {\n\trelation viewer: user\n}"},
+		{Operation: OperationAdded, Kind: KindNamespace, Name: "user", Schema: "definition user {}"},
+	}, events[0].Definitions)
+
+	event := events[1]
+	require.Equal(t, "some-request", event.RequestID)
+	require.Equal(t, "sub:some-service", event.Actor)
+	require.Equal(t, writeSchemaMethod, event.Method)
+	require.Equal(t, revision.String(), event.Revision)
+	require.Equal(t, []DefinitionChange{
+		{
+			Operation:      OperationChanged,
+			Kind:           KindNamespace,
+			Name:           "document",
+			PreviousSchema: "definition document {\n\trelation viewer: user\n}",
+			Schema:         "definition document {\n\trelation viewer: user\n\trelation editor: user\n}",
+			Deltas:         []Delta{{Type: "added-relation", Name: "editor"}},
+		},
+		{Operation: OperationAdded, Kind: KindCaveat, Name: "somecaveat", Schema: "caveat somecaveat() {\n\t1 == 1\n}"},
+	}, event.Definitions)
+}
+
+func TestDeletedDefinitions(t *testing.T) {
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	_, err = ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteNamespaces(ctx, ns.Namespace("user"), ns.Namespace("document"))
+	})
+	require.NoError(t, err)
+
+	wh := newWebhook(t, "", 0)
+	notifier, err := NewNotifier([]string{wh.URL}, "", time.Second, 1)
+	require.NoError(t, err)
+
+	notifying := newNotifyingDatastore(ds, notifier, requestMeta{method: writeSchemaMethod})
+	_, err = notifying.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return rwt.DeleteNamespaces(ctx, "document")
+	})
+	require.NoError(t, err)
+	require.NoError(t, notifier.Close())
+
+	events := wh.received()
+	require.Len(t, events, 1)
+	require.Equal(t, []DefinitionChange{
+		{Operation: OperationRemoved, Kind: KindNamespace, Name: "document", PreviousSchema: "definition document {}"},
+	}, events[0].Definitions)
+}
@@ -0,0 +1,166 @@
+// Package shadowcheck implements middleware which mirrors a sample of CheckPermission requests
+// to a shadow target, such as a candidate schema or another cluster, and records whether the
+// shadow agrees with the response served, without affecting it.
+package shadowcheck
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/grpc"
+
+	log "github.com/authzed/spicedb/internal/logging"
+)
+
+// The results of shadowed checks, as recorded by shadowChecks.
+const (
+	resultMatch    = "match"
+	resultDiverged = "diverged"
+	resultError    = "error"
+	resultDropped  = "dropped"
+)
+
+var shadowChecks = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "shadow_check",
+	Name:      "results_total",
+	Help:      "Number of checks mirrored to the shadow target, by result: `match` and `diverged` compare the permissionship of the shadow with that served, `error` counts checks which failed on the shadow and `dropped` those skipped because too many were in flight.",
+}, []string{"target", "result"})
+
+var shadowCheckDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: "spicedb",
+	Subsystem: "shadow_check",
+	Name:      "duration_seconds",
+	Help:      "Time taken by the shadow target to answer a mirrored check.",
+	Buckets:   []float64{.001, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+}, []string{"target"})
+
+// Target answers the checks mirrored to it.
+type Target interface {
+	// Name identifies the target in metrics and logs.
+	Name() string
+
+	// Check returns the permissionship of the request on the target. The primary answered the
+	// request at checkedAt.
+	Check(ctx context.Context, req *v1.CheckPermissionRequest, checkedAt *v1.ZedToken) (v1.CheckPermissionResponse_Permissionship, error)
+
+	// Close releases the resources held by the target.
+	Close() error
+}
+
+// Shadower mirrors a sample of checks to a target.
+type Shadower struct {
+	target     Target
+	sampleRate float64
+	timeout    time.Duration
+	inFlight   chan struct{}
+	random     func() float64
+}
+
+// NewShadower returns a shadower mirroring the fraction sampleRate of checks to the target, with
+// at most maxInFlight mirrored checks running at once, each bounded by the timeout.
+func NewShadower(target Target, sampleRate float64, maxInFlight uint32, timeout time.Duration) (*Shadower, error) {
+	if sampleRate <= 0 || sampleRate > 1 {
+		return nil, errors.New("invalid shadow check sample rate: must be greater than 0 and at most 1")
+	}
+	if maxInFlight == 0 {
+		return nil, errors.New("invalid shadow check in-flight limit: must be positive")
+	}
+	if timeout <= 0 {
+		return nil, errors.New("invalid shadow check timeout: must be positive")
+	}
+
+	return &Shadower{
+		target:     target,
+		sampleRate: sampleRate,
+		timeout:    timeout,
+		inFlight:   make(chan struct{}, maxInFlight),
+		random:     rand.Float64,
+	}, nil
+}
+
+// Close closes the target of the shadower.
+func (s *Shadower) Close() error {
+	return s.target.Close()
+}
+
+// UnaryServerInterceptor returns a new unary server interceptor which mirrors a sample of the
+// checks answered successfully to the target of the shadower, once they have been answered. A
+// nil shadower disables shadow checks.
+func UnaryServerInterceptor(s *Shadower) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if s == nil || err != nil || info.FullMethod != v1.PermissionsService_CheckPermission_FullMethodName {
+			return resp, err
+		}
+
+		checkReq, ok := req.(*v1.CheckPermissionRequest)
+		if !ok {
+			return resp, err
+		}
+		checkResp, ok := resp.(*v1.CheckPermissionResponse)
+		if !ok {
+			return resp, err
+		}
+
+		if s.random() < s.sampleRate {
+			s.shadow(ctx, checkReq, checkResp)
+		}
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a new stream server interceptor. Checks are unary, so no
+// streams are mirrored.
+func StreamServerInterceptor(_ *Shadower) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return handler(srv, stream)
+	}
+}
+
+// shadow mirrors the check in the background, unless too many are already in flight.
+func (s *Shadower) shadow(ctx context.Context, req *v1.CheckPermissionRequest, resp *v1.CheckPermissionResponse) {
+	name := s.target.Name()
+	select {
+	case s.inFlight <- struct{}{}:
+	default:
+		shadowChecks.WithLabelValues(name, resultDropped).Inc()
+		return
+	}
+
+	// The mirrored check outlives the request, but keeps its values, such as its datastore.
+	shadowCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), s.timeout)
+	go func() {
+		defer func() { <-s.inFlight }()
+		defer cancel()
+
+		start := time.Now()
+		permissionship, err := s.target.Check(shadowCtx, req, resp.CheckedAt)
+		shadowCheckDuration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+
+		switch {
+		case err != nil:
+			shadowChecks.WithLabelValues(name, resultError).Inc()
+			log.Ctx(ctx).Debug().Err(err).Str("target", name).Msg("shadow check failed")
+
+		case permissionship != resp.Permissionship:
+			shadowChecks.WithLabelValues(name, resultDiverged).Inc()
+			log.Ctx(ctx).Info().
+				Str("target", name).
+				Str("resource", req.Resource.ObjectType+":"+req.Resource.ObjectId).
+				Str("permission", req.Permission).
+				Str("subject", req.Subject.Object.ObjectType+":"+req.Subject.Object.ObjectId).
+				Str("served", resp.Permissionship.String()).
+				Str("shadow", permissionship.String()).
+				Msg("shadow check diverged")
+
+		default:
+			shadowChecks.WithLabelValues(name, resultMatch).Inc()
+		}
+	}()
+}
@@ -0,0 +1,181 @@
+package shadowcheck
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/dispatch/graph"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/zedtoken"
+)
+
+var (
+	checkInfo = &grpc.UnaryServerInfo{FullMethod: v1.PermissionsService_CheckPermission_FullMethodName}
+
+	checkRequest = &v1.CheckPermissionRequest{
+		Resource:   &v1.ObjectReference{ObjectType: "document", ObjectId: "readme"},
+		Permission: "view",
+		Subject:    &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "anne"}},
+	}
+)
+
+type fakeTarget struct {
+	permissionship v1.CheckPermissionResponse_Permissionship
+	err            error
+	release        chan struct{}
+}
+
+func (ft *fakeTarget) Name() string { return "fake" }
+
+func (ft *fakeTarget) Check(context.Context, *v1.CheckPermissionRequest, *v1.ZedToken) (v1.CheckPermissionResponse_Permissionship, error) {
+	if ft.release != nil {
+		<-ft.release
+	}
+	return ft.permissionship, ft.err
+}
+
+func (ft *fakeTarget) Close() error { return nil }
+
+func allowed(context.Context, any) (any, error) {
+	return &v1.CheckPermissionResponse{
+		CheckedAt:      &v1.ZedToken{Token: "some-token"},
+		Permissionship: v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION,
+	}, nil
+}
+
+func requireResultEventually(t *testing.T, result string, expected float64) {
+	require.Eventually(t, func() bool {
+		return testutil.ToFloat64(shadowChecks.WithLabelValues("fake", result)) == expected
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	target := &fakeTarget{permissionship: v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION}
+	shadower, err := NewShadower(target, 1, 10, time.Second)
+	require.NoError(t, err)
+	interceptor := UnaryServerInterceptor(shadower)
+
+	matched := testutil.ToFloat64(shadowChecks.WithLabelValues("fake", resultMatch))
+	resp, err := interceptor(context.Background(), checkRequest, checkInfo, allowed)
+	require.NoError(t, err)
+	require.Equal(t, v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, resp.(*v1.CheckPermissionResponse).Permissionship)
+	requireResultEventually(t, resultMatch, matched+1)
+
+	// A divergence is recorded, without changing the response served.
+	target.permissionship = v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION
+	diverged := testutil.ToFloat64(shadowChecks.WithLabelValues("fake", resultDiverged))
+	resp, err = interceptor(context.Background(), checkRequest, checkInfo, allowed)
+	require.NoError(t, err)
+	require.Equal(t, v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, resp.(*v1.CheckPermissionResponse).Permissionship)
+	requireResultEventually(t, resultDiverged, diverged+1)
+
+	target.err = errors.New("shadow unavailable")
+	failed := testutil.ToFloat64(shadowChecks.WithLabelValues("fake", resultError))
+	_, err = interceptor(context.Background(), checkRequest, checkInfo, allowed)
+	require.NoError(t, err)
+	requireResultEventually(t, resultError, failed+1)
+}
+
+func TestUnaryServerInterceptorSampling(t *testing.T) {
+	target := &fakeTarget{release: make(chan struct{})}
+	shadower, err := NewShadower(target, 0.5, 1, time.Second)
+	require.NoError(t, err)
+	interceptor := UnaryServerInterceptor(shadower)
+
+	// Checks beyond the sample are not mirrored.
+	shadower.random = func() float64 { return 0.7 }
+	_, err = interceptor(context.Background(), checkRequest, checkInfo, allowed)
+	require.NoError(t, err)
+	require.Empty(t, shadower.inFlight)
+
+	// Checks beyond the in-flight limit are dropped.
+	shadower.random = func() float64 { return 0.2 }
+	dropped := testutil.ToFloat64(shadowChecks.WithLabelValues("fake", resultDropped))
+	for i := 0; i < 2; i++ {
+		_, err = interceptor(context.Background(), checkRequest, checkInfo, allowed)
+		require.NoError(t, err)
+	}
+	require.Equal(t, dropped+1, testutil.ToFloat64(shadowChecks.WithLabelValues("fake", resultDropped)))
+	close(target.release)
+
+	// Failed checks and other methods are not mirrored.
+	_, err = interceptor(context.Background(), checkRequest, checkInfo, func(context.Context, any) (any, error) {
+		return nil, errors.New("failed")
+	})
+	require.Error(t, err)
+	_, err = interceptor(context.Background(), &v1.ReadSchemaRequest{}, &grpc.UnaryServerInfo{FullMethod: v1.SchemaService_ReadSchema_FullMethodName}, func(context.Context, any) (any, error) {
+		return &v1.ReadSchemaResponse{}, nil
+	})
+	require.NoError(t, err)
+}
+
+func TestNewShadower(t *testing.T) {
+	_, err := NewShadower(&fakeTarget{}, 0, 1, time.Second)
+	require.Error(t, err)
+	_, err = NewShadower(&fakeTarget{}, 1.5, 1, time.Second)
+	require.Error(t, err)
+	_, err = NewShadower(&fakeTarget{}, 1, 0, time.Second)
+	require.Error(t, err)
+	_, err = NewShadower(&fakeTarget{}, 1, 1, 0)
+	require.Error(t, err)
+}
+
+func TestSchemaTarget(t *testing.T) {
+	require := require.New(t)
+
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(err)
+	ds, rev := testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, `
+		definition user {}
+
+		definition document {
+			relation viewer: user
+			relation editor: user
+			permission view = viewer + editor
+		}`,
+		[]*core.RelationTuple{
+			tuple.MustParse("document:readme#viewer@user:anne"),
+			tuple.MustParse("document:readme#editor@user:bob"),
+		}, require)
+
+	target, err := NewSchemaTarget(context.Background(), `
+		definition user {}
+
+		definition document {
+			relation viewer: user
+			relation editor: user
+			permission view = editor
+		}`, graph.NewLocalOnlyDispatcher(10), 50)
+	require.NoError(err)
+	defer target.Close()
+
+	ctx := datastoremw.ContextWithDatastore(context.Background(), ds)
+	checkedAt := zedtoken.MustNewFromRevision(rev)
+
+	permissionship, err := target.Check(ctx, checkRequest, checkedAt)
+	require.NoError(err)
+	require.Equal(v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION, permissionship)
+
+	bobRequest := &v1.CheckPermissionRequest{
+		Resource:   checkRequest.Resource,
+		Permission: "view",
+		Subject:    &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "bob"}},
+	}
+	permissionship, err = target.Check(ctx, bobRequest, checkedAt)
+	require.NoError(err)
+	require.Equal(v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, permissionship)
+
+	_, err = NewSchemaTarget(context.Background(), `definition document { permission view = missing }`, graph.NewLocalOnlyDispatcher(10), 50)
+	require.Error(err)
+}
@@ -0,0 +1,252 @@
+package shadowcheck
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/authzed/spicedb/internal/dispatch"
+	"github.com/authzed/spicedb/internal/graph/computed"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/namespace"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/typesystem"
+	"github.com/authzed/spicedb/pkg/zedtoken"
+)
+
+// clusterTarget mirrors checks to the API of another cluster.
+type clusterTarget struct {
+	endpoint      string
+	client        v1.PermissionsServiceClient
+	closer        func() error
+	exactSnapshot bool
+}
+
+// NewClusterTarget returns a target mirroring checks to the permissions service of another
+// cluster, served at the endpoint. If exactSnapshot is true, which requires that the cluster
+// shares the datastore of this one, checks are mirrored at the exact revision at which they
+// were answered, so that only differences in their evaluation diverge; otherwise they are
+// mirrored with their own consistency. The target closes the connection with closer.
+func NewClusterTarget(endpoint string, client v1.PermissionsServiceClient, closer func() error, exactSnapshot bool) Target {
+	return &clusterTarget{endpoint: endpoint, client: client, closer: closer, exactSnapshot: exactSnapshot}
+}
+
+func (t *clusterTarget) Name() string {
+	return "cluster"
+}
+
+func (t *clusterTarget) Check(ctx context.Context, req *v1.CheckPermissionRequest, checkedAt *v1.ZedToken) (v1.CheckPermissionResponse_Permissionship, error) {
+	if t.exactSnapshot {
+		req = proto.Clone(req).(*v1.CheckPermissionRequest)
+		req.Consistency = &v1.Consistency{Requirement: &v1.Consistency_AtExactSnapshot{AtExactSnapshot: checkedAt}}
+	}
+
+	resp, err := t.client.CheckPermission(ctx, req)
+	if err != nil {
+		return v1.CheckPermissionResponse_PERMISSIONSHIP_UNSPECIFIED, fmt.Errorf("failed to check on %s: %w", t.endpoint, err)
+	}
+	return resp.Permissionship, nil
+}
+
+func (t *clusterTarget) Close() error {
+	return t.closer()
+}
+
+// schemaTarget evaluates checks against a candidate schema, over the relationships of the
+// datastore of this node.
+type schemaTarget struct {
+	dispatcher   dispatch.Dispatcher
+	maximumDepth uint32
+	namespaces   map[string]*core.NamespaceDefinition
+	caveats      map[string]*core.CaveatDefinition
+}
+
+// NewSchemaTarget returns a target evaluating checks with the candidate schema, rather than the
+// schema stored in the datastore, through the dispatcher, at the revision at which they were
+// answered. The dispatcher must not be shared with the primary, whose cached results would
+// otherwise be mixed with those of the candidate schema.
+func NewSchemaTarget(ctx context.Context, schema string, dispatcher dispatch.Dispatcher, maximumDepth uint32) (Target, error) {
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source("shadow"),
+		SchemaString: schema,
+	}, compiler.AllowUnprefixedObjectType())
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile shadow schema: %w", err)
+	}
+
+	t := &schemaTarget{
+		dispatcher:   dispatcher,
+		maximumDepth: maximumDepth,
+		namespaces:   make(map[string]*core.NamespaceDefinition, len(compiled.ObjectDefinitions)),
+		caveats:      make(map[string]*core.CaveatDefinition, len(compiled.CaveatDefinitions)),
+	}
+
+	for _, caveatDef := range compiled.CaveatDefinitions {
+		if err := namespace.ValidateCaveatDefinition(caveatDef); err != nil {
+			return nil, fmt.Errorf("invalid shadow schema: %w", err)
+		}
+		t.caveats[caveatDef.Name] = caveatDef
+	}
+
+	resolver := typesystem.ResolverForPredefinedDefinitions(typesystem.PredefinedElements{
+		Namespaces: compiled.ObjectDefinitions,
+		Caveats:    compiled.CaveatDefinitions,
+	})
+	for _, nsDef := range compiled.ObjectDefinitions {
+		ts, err := typesystem.NewNamespaceTypeSystem(nsDef, resolver)
+		if err != nil {
+			return nil, fmt.Errorf("invalid shadow schema: %w", err)
+		}
+		vts, err := ts.Validate(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("invalid shadow schema: %w", err)
+		}
+		if err := namespace.AnnotateNamespace(vts); err != nil {
+			return nil, fmt.Errorf("invalid shadow schema: %w", err)
+		}
+		t.namespaces[nsDef.Name] = nsDef
+	}
+
+	return t, nil
+}
+
+func (t *schemaTarget) Name() string {
+	return "schema"
+}
+
+func (t *schemaTarget) Check(ctx context.Context, req *v1.CheckPermissionRequest, checkedAt *v1.ZedToken) (v1.CheckPermissionResponse_Permissionship, error) {
+	ds := datastoremw.FromContext(ctx)
+	if ds == nil {
+		return v1.CheckPermissionResponse_PERMISSIONSHIP_UNSPECIFIED, errors.New("no datastore in the context of the shadow check")
+	}
+
+	atRevision, err := zedtoken.DecodeRevision(checkedAt, ds)
+	if err != nil {
+		return v1.CheckPermissionResponse_PERMISSIONSHIP_UNSPECIFIED, err
+	}
+
+	subjectRelation := req.Subject.OptionalRelation
+	if subjectRelation == "" {
+		subjectRelation = tuple.Ellipsis
+	}
+
+	var caveatContext map[string]any
+	if req.Context != nil {
+		caveatContext = req.Context.AsMap()
+	}
+
+	ctx = datastoremw.ContextWithDatastore(ctx, &schemaOverlay{Datastore: ds, target: t})
+	cr, _, err := computed.ComputeCheck(ctx, t.dispatcher,
+		computed.CheckParameters{
+			ResourceType: &core.RelationReference{
+				Namespace: req.Resource.ObjectType,
+				Relation:  req.Permission,
+			},
+			Subject: &core.ObjectAndRelation{
+				Namespace: req.Subject.Object.ObjectType,
+				ObjectId:  req.Subject.Object.ObjectId,
+				Relation:  subjectRelation,
+			},
+			CaveatContext: caveatContext,
+			AtRevision:    atRevision,
+			MaximumDepth:  t.maximumDepth,
+		},
+		req.Resource.ObjectId,
+	)
+	if err != nil {
+		return v1.CheckPermissionResponse_PERMISSIONSHIP_UNSPECIFIED, err
+	}
+
+	switch cr.Membership {
+	case dispatchv1.ResourceCheckResult_MEMBER:
+		return v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, nil
+	case dispatchv1.ResourceCheckResult_CAVEATED_MEMBER:
+		return v1.CheckPermissionResponse_PERMISSIONSHIP_CONDITIONAL_PERMISSION, nil
+	default:
+		return v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION, nil
+	}
+}
+
+func (t *schemaTarget) Close() error {
+	return t.dispatcher.Close()
+}
+
+// schemaOverlay serves the definitions of the candidate schema in place of those stored in the
+// datastore.
+type schemaOverlay struct {
+	datastore.Datastore
+	target *schemaTarget
+}
+
+func (o *schemaOverlay) SnapshotReader(rev datastore.Revision) datastore.Reader {
+	return &schemaOverlayReader{Reader: o.Datastore.SnapshotReader(rev), target: o.target, revision: rev}
+}
+
+func (o *schemaOverlay) Unwrap() datastore.Datastore {
+	return o.Datastore
+}
+
+type schemaOverlayReader struct {
+	datastore.Reader
+	target   *schemaTarget
+	revision datastore.Revision
+}
+
+func (r *schemaOverlayReader) ReadNamespaceByName(_ context.Context, nsName string) (*core.NamespaceDefinition, datastore.Revision, error) {
+	nsDef, ok := r.target.namespaces[nsName]
+	if !ok {
+		return nil, datastore.NoRevision, datastore.NewNamespaceNotFoundErr(nsName)
+	}
+	return nsDef, r.revision, nil
+}
+
+func (r *schemaOverlayReader) ListAllNamespaces(_ context.Context) ([]datastore.RevisionedNamespace, error) {
+	return revisioned(r.target.namespaces, nil, r.revision), nil
+}
+
+func (r *schemaOverlayReader) LookupNamespacesWithNames(_ context.Context, nsNames []string) ([]datastore.RevisionedNamespace, error) {
+	return revisioned(r.target.namespaces, nsNames, r.revision), nil
+}
+
+func (r *schemaOverlayReader) ReadCaveatByName(_ context.Context, name string) (*core.CaveatDefinition, datastore.Revision, error) {
+	caveatDef, ok := r.target.caveats[name]
+	if !ok {
+		return nil, datastore.NoRevision, datastore.NewCaveatNameNotFoundErr(name)
+	}
+	return caveatDef, r.revision, nil
+}
+
+func (r *schemaOverlayReader) ListAllCaveats(_ context.Context) ([]datastore.RevisionedCaveat, error) {
+	return revisioned(r.target.caveats, nil, r.revision), nil
+}
+
+func (r *schemaOverlayReader) LookupCaveatsWithNames(_ context.Context, names []string) ([]datastore.RevisionedCaveat, error) {
+	return revisioned(r.target.caveats, names, r.revision), nil
+}
+
+// revisioned returns the definitions with the given names, or all of them if names is nil, as
+// written at the revision.
+func revisioned[T datastore.SchemaDefinition](defs map[string]T, names []string, rev datastore.Revision) []datastore.RevisionedDefinition[T] {
+	if names == nil {
+		names = make([]string, 0, len(defs))
+		for name := range defs {
+			names = append(names, name)
+		}
+	}
+
+	found := make([]datastore.RevisionedDefinition[T], 0, len(names))
+	for _, name := range names {
+		if def, ok := defs[name]; ok {
+			found = append(found, datastore.RevisionedDefinition[T]{Definition: def, LastWrittenRevision: rev})
+		}
+	}
+	return found
+}
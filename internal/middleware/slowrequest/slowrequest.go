@@ -0,0 +1,93 @@
+package slowrequest
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/middleware/usagemetrics"
+)
+
+// UnaryServerInterceptor returns a new unary server interceptor that logs, at warning
+// level, each call taking longer than the threshold. A threshold of zero disables logging.
+//
+// The interceptor must run within the usagemetrics interceptor for the dispatch and cache
+// statistics of the call to be logged.
+func UnaryServerInterceptor(threshold time.Duration) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if threshold <= 0 {
+			return handler(ctx, req)
+		}
+
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logIfSlow(ctx, info.FullMethod, req, time.Since(start), threshold, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor returns a new stream server interceptor that logs, at warning
+// level, each stream taking longer than the threshold. A threshold of zero disables logging.
+//
+// The interceptor must run within the usagemetrics interceptor for the dispatch and cache
+// statistics of the stream to be logged.
+func StreamServerInterceptor(threshold time.Duration) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if threshold <= 0 {
+			return handler(srv, stream)
+		}
+
+		wrapper := &recvWrapper{ServerStream: stream}
+		start := time.Now()
+		err := handler(srv, wrapper)
+		logIfSlow(stream.Context(), info.FullMethod, wrapper.req, time.Since(start), threshold, err)
+		return err
+	}
+}
+
+// recvWrapper keeps the first message received on the stream, which is the request of
+// server-streaming calls.
+type recvWrapper struct {
+	grpc.ServerStream
+
+	req any
+}
+
+func (s *recvWrapper) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil && s.req == nil {
+		s.req = m
+	}
+	return err
+}
+
+func logIfSlow(ctx context.Context, method string, req any, duration, threshold time.Duration, err error) {
+	if duration <= threshold {
+		return
+	}
+
+	event := log.Ctx(ctx).Warn().
+		Str("method", method).
+		Dur("duration", duration).
+		Dur("threshold", threshold).
+		Err(err)
+
+	if msg, ok := req.(proto.Message); ok {
+		if marshaled, merr := protojson.Marshal(msg); merr == nil {
+			event = event.RawJSON("request", marshaled)
+		}
+	}
+
+	if meta := usagemetrics.FromContext(ctx); meta != nil {
+		event = event.
+			Uint32("dispatch_count", meta.DispatchCount).
+			Uint32("cached_dispatch_count", meta.CachedDispatchCount).
+			Uint32("depth_required", meta.DepthRequired)
+	}
+
+	event.Msg("slow request")
+}
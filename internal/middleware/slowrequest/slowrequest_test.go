@@ -0,0 +1,106 @@
+package slowrequest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/authzed/spicedb/internal/middleware/usagemetrics"
+	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+)
+
+const checkMethod = "/authzed.api.v1.PermissionsService/CheckPermission"
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	req := &v1.CheckPermissionRequest{
+		Resource:   &v1.ObjectReference{ObjectType: "document", ObjectId: "first"},
+		Permission: "view",
+		Subject:    &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "tom"}},
+	}
+
+	for _, tc := range []struct {
+		name       string
+		threshold  time.Duration
+		delay      time.Duration
+		expectsLog bool
+	}{
+		{"disabled", 0, 10 * time.Millisecond, false},
+		{"fast", time.Minute, 0, false},
+		{"slow", time.Millisecond, 10 * time.Millisecond, true},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			ctx := zerolog.New(&buf).WithContext(context.Background())
+			ctx = usagemetrics.ContextWithHandle(ctx)
+
+			interceptor := UnaryServerInterceptor(tc.threshold)
+			_, err := interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: checkMethod}, func(ctx context.Context, _ any) (any, error) {
+				time.Sleep(tc.delay)
+				usagemetrics.SetInContext(ctx, &dispatchv1.ResponseMeta{
+					DispatchCount:       5,
+					CachedDispatchCount: 2,
+					DepthRequired:       3,
+				})
+				return nil, nil
+			})
+			require.NoError(t, err)
+
+			if !tc.expectsLog {
+				require.Empty(t, buf.String())
+				return
+			}
+
+			var entry map[string]any
+			require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+			require.Equal(t, "warn", entry["level"])
+			require.Equal(t, "slow request", entry["message"])
+			require.Equal(t, checkMethod, entry["method"])
+			require.Equal(t, "document", entry["request"].(map[string]any)["resource"].(map[string]any)["objectType"])
+			require.InDelta(t, 5, entry["dispatch_count"], 0)
+			require.InDelta(t, 2, entry["cached_dispatch_count"], 0)
+			require.InDelta(t, 3, entry["depth_required"], 0)
+		})
+	}
+}
+
+type fakeStream struct {
+	grpc.ServerStream
+
+	ctx context.Context
+	req *v1.LookupResourcesRequest
+}
+
+func (fs *fakeStream) Context() context.Context { return fs.ctx }
+
+func (fs *fakeStream) RecvMsg(m any) error {
+	m.(*v1.LookupResourcesRequest).ResourceObjectType = fs.req.ResourceObjectType
+	return nil
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	var buf bytes.Buffer
+	ctx := zerolog.New(&buf).WithContext(context.Background())
+	stream := &fakeStream{ctx: ctx, req: &v1.LookupResourcesRequest{ResourceObjectType: "document"}}
+
+	interceptor := StreamServerInterceptor(time.Millisecond)
+	err := interceptor(nil, stream, &grpc.StreamServerInfo{FullMethod: "/authzed.api.v1.PermissionsService/LookupResources"}, func(_ any, stream grpc.ServerStream) error {
+		if err := stream.RecvMsg(&v1.LookupResourcesRequest{}); err != nil {
+			return err
+		}
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	})
+	require.NoError(t, err)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	require.Equal(t, "slow request", entry["message"])
+	require.Equal(t, "document", entry["request"].(map[string]any)["resourceObjectType"])
+}
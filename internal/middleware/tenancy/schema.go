@@ -0,0 +1,66 @@
+package tenancy
+
+import (
+	"fmt"
+	"strings"
+
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+// PrefixDefinitions adds the tenant's prefix to the names of the definitions, and to their
+// references to other definitions, in place. Definitions and references which are already
+// prefixed are rejected, as they could reach the definitions of another tenant.
+func PrefixDefinitions(tenant string, objectDefs []*core.NamespaceDefinition, caveatDefs []*core.CaveatDefinition) error {
+	return renameDefinitions(objectDefs, caveatDefs, func(name string) (string, error) {
+		if strings.Contains(name, "/") {
+			return "", fmt.Errorf("definition `%s` must not be prefixed when tenant isolation is enabled", name)
+		}
+		return Prefix(tenant) + name, nil
+	})
+}
+
+// StripDefinitions removes the tenant's prefix from the names of the definitions, and from
+// their references to other definitions, in place.
+func StripDefinitions(tenant string, objectDefs []*core.NamespaceDefinition, caveatDefs []*core.CaveatDefinition) error {
+	return renameDefinitions(objectDefs, caveatDefs, func(name string) (string, error) {
+		stripped, ok := strings.CutPrefix(name, Prefix(tenant))
+		if !ok {
+			return "", fmt.Errorf("definition `%s` does not belong to the tenant", name)
+		}
+		return stripped, nil
+	})
+}
+
+// OwnsDefinition returns whether the named definition belongs to the tenant.
+func OwnsDefinition(tenant, name string) bool {
+	return strings.HasPrefix(name, Prefix(tenant))
+}
+
+func renameDefinitions(objectDefs []*core.NamespaceDefinition, caveatDefs []*core.CaveatDefinition, rename func(string) (string, error)) error {
+	var err error
+	for _, caveatDef := range caveatDefs {
+		if caveatDef.Name, err = rename(caveatDef.Name); err != nil {
+			return err
+		}
+	}
+
+	for _, objectDef := range objectDefs {
+		if objectDef.Name, err = rename(objectDef.Name); err != nil {
+			return err
+		}
+
+		for _, relation := range objectDef.Relation {
+			for _, allowed := range relation.GetTypeInformation().GetAllowedDirectRelations() {
+				if allowed.Namespace, err = rename(allowed.Namespace); err != nil {
+					return err
+				}
+				if allowed.RequiredCaveat != nil {
+					if allowed.RequiredCaveat.CaveatName, err = rename(allowed.RequiredCaveat.CaveatName); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
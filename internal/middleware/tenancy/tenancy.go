@@ -0,0 +1,383 @@
+// Package tenancy isolates the schema and relationships of tenants sharing a server. Each
+// tenant's object and caveat definitions are stored under a prefix naming the tenant, so
+// that the datastore, the schema caches and the dispatch keys, all of which are keyed by
+// definition name, never mix the data of two tenants. The middleware adds the tenant's
+// prefix to the definitions referenced by requests and removes it from responses, so that
+// tenants see only their own unprefixed definitions.
+package tenancy
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/authzed/spicedb/internal/auth"
+)
+
+// isolatedServices are the API services available to tenants. Other services of the API are
+// denied when tenant isolation is enabled, as they may expose data across tenants; services
+// outside the API, such as health checks, are unaffected.
+var isolatedServices = map[string]struct{}{
+	"authzed.api.v1.PermissionsService":  {},
+	"authzed.api.v1.SchemaService":       {},
+	"authzed.api.v1.WatchService":        {},
+	"authzed.api.v1.ExperimentalService": {},
+}
+
+const apiServicePrefix = "authzed.api."
+
+// tenantPattern matches valid tenant identifiers, which must be valid definition prefixes.
+var tenantPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,61}[a-z0-9]$`)
+
+// definitionFieldNames are the names of the message fields which reference object or caveat
+// definitions.
+var definitionFieldNames = map[protoreflect.Name]struct{}{
+	"object_type":           {},
+	"resource_type":         {},
+	"subject_type":          {},
+	"resource_object_type":  {},
+	"subject_object_type":   {},
+	"optional_object_types": {},
+	"caveat_name":           {},
+}
+
+// schemaFieldName is the name of the field of debug information holding schema text.
+const schemaFieldName protoreflect.Name = "schema_used"
+
+// definitionHeaders are the request metadata keys, defined by the services, whose values
+// reference definitions, with the rewrite adding the tenant's prefix to the definitions of a
+// value. Values which cannot be parsed are left as they are, for the services to reject.
+var definitionHeaders = map[string]func(value string, prefix func(string) (string, error)) (string, error){
+	"io.spicedb.watchrelationfilter": prefixTypeAndRelation, // WatchRelationFilterHeaderKey
+	"io.spicedb.expandsubjectfilter": prefixTypeAndRelation, // ExpandSubjectFilterHeaderKey
+	"io.spicedb.watchsubjectfilter":  prefixSubjectFilter,   // WatchSubjectFilterHeaderKey
+}
+
+// prefixSubjectFilter prefixes the definitions of a SubjectFilter encoded as JSON.
+func prefixSubjectFilter(value string, prefix func(string) (string, error)) (string, error) {
+	filter := &v1.SubjectFilter{}
+	if err := protojson.Unmarshal([]byte(value), filter); err != nil {
+		return value, nil
+	}
+
+	if filter.SubjectType != "" {
+		prefixed, err := prefix(filter.SubjectType)
+		if err != nil {
+			return "", err
+		}
+		filter.SubjectType = prefixed
+	}
+
+	encoded, err := protojson.Marshal(filter)
+	if err != nil {
+		return "", err
+	}
+	return string(encoded), nil
+}
+
+// prefixTypeAndRelation prefixes the type of a value of the form `type` or `type#relation`.
+func prefixTypeAndRelation(value string, prefix func(string) (string, error)) (string, error) {
+	definition, relation, hasRelation := strings.Cut(value, "#")
+	if definition == "" {
+		return value, nil
+	}
+
+	prefixed, err := prefix(definition)
+	if err != nil {
+		return "", err
+	}
+	if hasRelation {
+		prefixed += "#" + relation
+	}
+	return prefixed, nil
+}
+
+type tenantKey struct{}
+
+// ContextWithTenant returns a context carrying the tenant of the request.
+func ContextWithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// FromContext returns the tenant of the request, if tenant isolation is enabled.
+func FromContext(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(tenantKey{}).(string)
+	return tenant, ok && tenant != ""
+}
+
+// Prefix returns the prefix of the definitions of the tenant.
+func Prefix(tenant string) string {
+	return tenant + "/"
+}
+
+// UnaryServerInterceptor returns a new interceptor which confines each request to the
+// definitions of the tenant of its token. It must run after authentication and the token
+// scope checks, which apply to the unprefixed definitions. If isolation is disabled, all
+// requests are passed through.
+func UnaryServerInterceptor(enabled bool) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if !enabled || !isAPIMethod(info.FullMethod) {
+			return handler(ctx, req)
+		}
+
+		tenant, err := tenantFor(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := prefixMessage(req, tenant); err != nil {
+			return nil, err
+		}
+
+		ctx, err = prefixHeaders(ctx, tenant)
+		if err != nil {
+			return nil, err
+		}
+
+		resp, err := handler(ContextWithTenant(ctx, tenant), req)
+		if err != nil {
+			return nil, stripError(err, tenant)
+		}
+
+		if msg, ok := resp.(proto.Message); ok && stripMessage(msg.ProtoReflect(), tenant) {
+			return nil, status.Errorf(codes.Internal, "response referenced definitions outside of the tenant")
+		}
+		return resp, nil
+	}
+}
+
+// StreamServerInterceptor returns a new interceptor which confines each stream to the
+// definitions of the tenant of its token, leaving out the results of other tenants from
+// streams such as Watch and BulkExportRelationships. It must run after authentication and the
+// token scope checks. If isolation is disabled, all streams are passed through.
+func StreamServerInterceptor(enabled bool) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if !enabled || !isAPIMethod(info.FullMethod) {
+			return handler(srv, stream)
+		}
+
+		tenant, err := tenantFor(stream.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		ctx, err := prefixHeaders(stream.Context(), tenant)
+		if err != nil {
+			return err
+		}
+
+		wrapped := &tenantServerStream{ServerStream: stream, ctx: ContextWithTenant(ctx, tenant), tenant: tenant}
+		if err := handler(srv, wrapped); err != nil {
+			return stripError(err, tenant)
+		}
+		return nil
+	}
+}
+
+type tenantServerStream struct {
+	grpc.ServerStream
+
+	ctx    context.Context
+	tenant string
+}
+
+func (s *tenantServerStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *tenantServerStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	return prefixMessage(m, s.tenant)
+}
+
+func (s *tenantServerStream) SendMsg(m any) error {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return s.ServerStream.SendMsg(m)
+	}
+
+	stripped := proto.Clone(msg)
+	if stripMessage(stripped.ProtoReflect(), s.tenant) {
+		// Results belonging entirely to other tenants are left out of the stream.
+		return nil
+	}
+	return s.ServerStream.SendMsg(stripped)
+}
+
+func isAPIMethod(fullMethod string) bool {
+	return strings.HasPrefix(strings.TrimPrefix(fullMethod, "/"), apiServicePrefix)
+}
+
+// tenantFor returns the tenant of the request, ensuring that the method is available to
+// tenants.
+func tenantFor(ctx context.Context, fullMethod string) (string, error) {
+	service, _, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	if _, ok := isolatedServices[service]; !ok {
+		return "", status.Errorf(codes.PermissionDenied, "%s is not available when tenant isolation is enabled", fullMethod)
+	}
+
+	scope, ok := auth.ScopeFromContext(ctx)
+	if !ok || scope.Tenant == "" {
+		return "", status.Errorf(codes.PermissionDenied, "tenant isolation is enabled: the token must name a tenant in its `%s` claim", auth.TenantClaim)
+	}
+
+	if !tenantPattern.MatchString(scope.Tenant) {
+		return "", status.Errorf(codes.PermissionDenied, "invalid tenant `%s`: tenants must be valid definition prefixes", scope.Tenant)
+	}
+	return scope.Tenant, nil
+}
+
+// prefixDefinition adds the tenant's prefix to the name of a definition referenced by a
+// request, rejecting names which are already prefixed, as they could reach the definitions of
+// another tenant.
+func prefixDefinition(tenant, name string) (string, error) {
+	if strings.Contains(name, "/") {
+		return "", status.Errorf(codes.InvalidArgument, "definition `%s` must not be prefixed when tenant isolation is enabled", name)
+	}
+	return Prefix(tenant) + name, nil
+}
+
+// prefixMessage adds the tenant's prefix to the definitions referenced by the message,
+// rejecting references which are already prefixed.
+func prefixMessage(m any, tenant string) error {
+	msg, ok := m.(proto.Message)
+	if !ok {
+		return nil
+	}
+
+	var err error
+	rewriteDefinitionFields(msg.ProtoReflect(), func(name string) (string, bool) {
+		prefixed, prefixErr := prefixDefinition(tenant, name)
+		if prefixErr != nil {
+			err = prefixErr
+			return name, false
+		}
+		return prefixed, true
+	}, func(schema string) string {
+		return schema
+	})
+	return err
+}
+
+// prefixHeaders adds the tenant's prefix to the definitions referenced by the request
+// metadata, returning a context with the rewritten metadata.
+func prefixHeaders(ctx context.Context, tenant string) (context.Context, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx, nil
+	}
+
+	prefix := func(name string) (string, error) {
+		return prefixDefinition(tenant, name)
+	}
+
+	var rewritten metadata.MD
+	for key, rewrite := range definitionHeaders {
+		values := md.Get(key)
+		if len(values) == 0 {
+			continue
+		}
+
+		if rewritten == nil {
+			rewritten = md.Copy()
+		}
+
+		prefixed := make([]string, 0, len(values))
+		for _, value := range values {
+			p, err := rewrite(value, prefix)
+			if err != nil {
+				return nil, err
+			}
+			prefixed = append(prefixed, p)
+		}
+		rewritten.Set(key, prefixed...)
+	}
+
+	if rewritten == nil {
+		return ctx, nil
+	}
+	return metadata.NewIncomingContext(ctx, rewritten), nil
+}
+
+// stripMessage removes the tenant's prefix from the definitions referenced by the message,
+// leaving out the elements of lists which reference the definitions of other tenants. It
+// returns whether the message itself references definitions of other tenants.
+func stripMessage(msg protoreflect.Message, tenant string) bool {
+	prefix := Prefix(tenant)
+	return !rewriteDefinitionFields(msg, func(name string) (string, bool) {
+		return strings.CutPrefix(name, prefix)
+	}, func(schema string) string {
+		return strings.ReplaceAll(schema, prefix, "")
+	})
+}
+
+// rewriteDefinitionFields rewrites the non-empty definition names referenced by the message,
+// and the schema text of its debug information. List elements for which the rewrite fails
+// are removed. It returns false if the rewrite failed for a field of the message, outside of
+// a list.
+func rewriteDefinitionFields(msg protoreflect.Message, rewrite func(string) (string, bool), rewriteSchema func(string) string) bool {
+	succeeded := true
+	msg.Range(func(fd protoreflect.FieldDescriptor, value protoreflect.Value) bool {
+		switch {
+		case fd.Kind() == protoreflect.StringKind && fd.Name() == schemaFieldName:
+			msg.Set(fd, protoreflect.ValueOfString(rewriteSchema(value.String())))
+
+		case fd.Kind() == protoreflect.StringKind:
+			if _, ok := definitionFieldNames[fd.Name()]; !ok {
+				return true
+			}
+
+			if fd.IsList() {
+				list := value.List()
+				for i := 0; i < list.Len(); i++ {
+					rewritten, ok := rewrite(list.Get(i).String())
+					succeeded = succeeded && ok
+					list.Set(i, protoreflect.ValueOfString(rewritten))
+				}
+			} else if value.String() != "" {
+				rewritten, ok := rewrite(value.String())
+				succeeded = succeeded && ok
+				msg.Set(fd, protoreflect.ValueOfString(rewritten))
+			}
+
+		case fd.Kind() == protoreflect.MessageKind && fd.IsList():
+			list := value.List()
+			kept := 0
+			for i := 0; i < list.Len(); i++ {
+				if rewriteDefinitionFields(list.Get(i).Message(), rewrite, rewriteSchema) {
+					list.Set(kept, list.Get(i))
+					kept++
+				}
+			}
+			list.Truncate(kept)
+
+		case fd.Kind() == protoreflect.MessageKind && !fd.IsMap():
+			succeeded = rewriteDefinitionFields(value.Message(), rewrite, rewriteSchema) && succeeded
+		}
+		return true
+	})
+	return succeeded
+}
+
+// stripError removes the tenant's prefix from the message of the error.
+func stripError(err error, tenant string) error {
+	s, ok := status.FromError(err)
+	if !ok || !strings.Contains(s.Message(), Prefix(tenant)) {
+		return err
+	}
+
+	p := s.Proto()
+	p.Message = strings.ReplaceAll(p.Message, Prefix(tenant), "")
+	return status.ErrorProto(p)
+}
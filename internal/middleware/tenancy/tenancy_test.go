@@ -0,0 +1,261 @@
+package tenancy
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/authzed/spicedb/internal/auth"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+const checkMethod = "/authzed.api.v1.PermissionsService/CheckPermission"
+
+func tenantContext(tenant string) context.Context {
+	return auth.ContextWithScope(context.Background(), &auth.TokenScope{Tenant: tenant})
+}
+
+func TestUnaryInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor(true)
+	info := &grpc.UnaryServerInfo{FullMethod: checkMethod}
+
+	req := &v1.CheckPermissionRequest{
+		Resource:   &v1.ObjectReference{ObjectType: "document", ObjectId: "first"},
+		Permission: "view",
+		Subject:    &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "tom"}},
+	}
+	resp, err := interceptor(tenantContext("acme"), req.CloneVT(), info, func(ctx context.Context, req any) (any, error) {
+		tenant, ok := FromContext(ctx)
+		require.True(t, ok)
+		require.Equal(t, "acme", tenant)
+
+		check := req.(*v1.CheckPermissionRequest)
+		require.Equal(t, "acme/document", check.Resource.ObjectType)
+		require.Equal(t, "acme/user", check.Subject.Object.ObjectType)
+		return &v1.DebugInformation{SchemaUsed: "definition acme/user {}"}, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "definition user {}", resp.(*v1.DebugInformation).SchemaUsed)
+
+	_, err = interceptor(tenantContext("acme"), req.CloneVT(), info, func(context.Context, any) (any, error) {
+		return nil, status.Errorf(codes.FailedPrecondition, "object definition `acme/document` not found")
+	})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+	require.ErrorContains(t, err, "object definition `document` not found")
+
+	_, err = interceptor(tenantContext("acme"), &v1.CheckPermissionRequest{
+		Resource: &v1.ObjectReference{ObjectType: "other/document", ObjectId: "first"},
+	}, info, func(context.Context, any) (any, error) {
+		require.Fail(t, "handler must not be called")
+		return nil, nil
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestUnaryInterceptorDenials(t *testing.T) {
+	interceptor := UnaryServerInterceptor(true)
+	handler := func(context.Context, any) (any, error) {
+		return &v1.CheckPermissionResponse{}, nil
+	}
+
+	testCases := []struct {
+		name         string
+		ctx          context.Context
+		method       string
+		expectedCode codes.Code
+	}{
+		{"no scope", context.Background(), checkMethod, codes.PermissionDenied},
+		{"no tenant", auth.ContextWithScope(context.Background(), &auth.TokenScope{Subject: "svc"}), checkMethod, codes.PermissionDenied},
+		{"invalid tenant", tenantContext("Acme/Corp"), checkMethod, codes.PermissionDenied},
+		{"unisolated service", tenantContext("acme"), "/authzed.api.materialize.v0.WatchPermissionsService/WatchPermissions", codes.PermissionDenied},
+		{"non-API service", context.Background(), "/grpc.health.v1.Health/Check", codes.OK},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := interceptor(tc.ctx, &v1.CheckPermissionRequest{}, &grpc.UnaryServerInfo{FullMethod: tc.method}, handler)
+			require.Equal(t, tc.expectedCode, status.Code(err))
+		})
+	}
+
+	resp, err := UnaryServerInterceptor(false)(context.Background(), &v1.CheckPermissionRequest{}, &grpc.UnaryServerInfo{FullMethod: checkMethod}, handler)
+	require.NoError(t, err)
+	require.NotNil(t, resp)
+}
+
+type fakeStream struct {
+	grpc.ServerStream
+
+	ctx  context.Context
+	req  proto.Message
+	sent []proto.Message
+}
+
+func (fs *fakeStream) Context() context.Context { return fs.ctx }
+
+func (fs *fakeStream) RecvMsg(m any) error {
+	proto.Merge(m.(proto.Message), fs.req)
+	return nil
+}
+
+func (fs *fakeStream) SendMsg(m any) error {
+	fs.sent = append(fs.sent, m.(proto.Message))
+	return nil
+}
+
+func TestStreamInterceptorFiltersOtherTenants(t *testing.T) {
+	interceptor := StreamServerInterceptor(true)
+	stream := &fakeStream{
+		ctx: tenantContext("acme"),
+		req: &v1.WatchRequest{OptionalObjectTypes: []string{"document"}},
+	}
+
+	update := func(resourceType string) *v1.RelationshipUpdate {
+		return &v1.RelationshipUpdate{
+			Operation: v1.RelationshipUpdate_OPERATION_TOUCH,
+			Relationship: &v1.Relationship{
+				Resource: &v1.ObjectReference{ObjectType: resourceType, ObjectId: "first"},
+				Relation: "viewer",
+				Subject:  &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "acme/user", ObjectId: "tom"}},
+			},
+		}
+	}
+
+	err := interceptor(nil, stream, &grpc.StreamServerInfo{FullMethod: "/authzed.api.v1.WatchService/Watch"}, func(_ any, stream grpc.ServerStream) error {
+		req := &v1.WatchRequest{}
+		require.NoError(t, stream.RecvMsg(req))
+		require.Equal(t, []string{"acme/document"}, req.OptionalObjectTypes)
+
+		if err := stream.SendMsg(&v1.WatchResponse{Updates: []*v1.RelationshipUpdate{update("acme/document"), update("other/document")}}); err != nil {
+			return err
+		}
+		return stream.SendMsg(&v1.DebugInformation{Check: &v1.CheckDebugTrace{
+			Resource: &v1.ObjectReference{ObjectType: "other/document", ObjectId: "first"},
+		}})
+	})
+	require.NoError(t, err)
+
+	require.Len(t, stream.sent, 1)
+	updates := stream.sent[0].(*v1.WatchResponse).Updates
+	require.Len(t, updates, 1)
+	require.Equal(t, "document", updates[0].Relationship.Resource.ObjectType)
+	require.Equal(t, "user", updates[0].Relationship.Subject.Object.ObjectType)
+}
+
+func TestDefinitionHeaders(t *testing.T) {
+	testCases := []struct {
+		name         string
+		header       string
+		value        string
+		expected     string
+		expectedCode codes.Code
+	}{
+		{"watch relation filter", "io.spicedb.watchrelationfilter", "document#viewer", "acme/document#viewer", codes.OK},
+		{"prefixed watch relation filter", "io.spicedb.watchrelationfilter", "other/document#viewer", "", codes.InvalidArgument},
+		{"invalid watch relation filter", "io.spicedb.watchrelationfilter", "#viewer", "#viewer", codes.OK},
+		{"expand subject filter", "io.spicedb.expandsubjectfilter", "user", "acme/user", codes.OK},
+		{"expand subject filter with relation", "io.spicedb.expandsubjectfilter", "group#member", "acme/group#member", codes.OK},
+		{"prefixed expand subject filter", "io.spicedb.expandsubjectfilter", "other/user", "", codes.InvalidArgument},
+		{"invalid watch subject filter", "io.spicedb.watchsubjectfilter", "not json", "not json", codes.OK},
+		{"unrelated header", "io.spicedb.watchcheckpoints", "other/document", "other/document", codes.OK},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := metadata.NewIncomingContext(tenantContext("acme"), metadata.Pairs(tc.header, tc.value))
+			requireHeader := func(ctx context.Context) {
+				md, _ := metadata.FromIncomingContext(ctx)
+				require.Equal(t, []string{tc.expected}, md.Get(tc.header))
+			}
+
+			_, err := UnaryServerInterceptor(true)(ctx, &v1.CheckPermissionRequest{}, &grpc.UnaryServerInfo{FullMethod: checkMethod}, func(ctx context.Context, _ any) (any, error) {
+				requireHeader(ctx)
+				return &v1.CheckPermissionResponse{}, nil
+			})
+			require.Equal(t, tc.expectedCode, status.Code(err))
+
+			stream := &fakeStream{ctx: ctx, req: &v1.WatchRequest{}}
+			err = StreamServerInterceptor(true)(nil, stream, &grpc.StreamServerInfo{FullMethod: "/authzed.api.v1.WatchService/Watch"}, func(_ any, stream grpc.ServerStream) error {
+				requireHeader(stream.Context())
+				return nil
+			})
+			require.Equal(t, tc.expectedCode, status.Code(err))
+		})
+	}
+}
+
+func TestWatchSubjectFilterHeader(t *testing.T) {
+	const header = "io.spicedb.watchsubjectfilter"
+	interceptor := StreamServerInterceptor(true)
+	info := &grpc.StreamServerInfo{FullMethod: "/authzed.api.v1.WatchService/Watch"}
+
+	watch := func(value string, handler func(*v1.SubjectFilter)) error {
+		ctx := metadata.NewIncomingContext(tenantContext("acme"), metadata.Pairs(header, value))
+		return interceptor(nil, &fakeStream{ctx: ctx, req: &v1.WatchRequest{}}, info, func(_ any, stream grpc.ServerStream) error {
+			md, _ := metadata.FromIncomingContext(stream.Context())
+			filter := &v1.SubjectFilter{}
+			require.NoError(t, protojson.Unmarshal([]byte(md.Get(header)[0]), filter))
+			handler(filter)
+			return nil
+		})
+	}
+
+	err := watch(`{"subjectType": "group", "optionalSubjectId": "eng", "optionalRelation": {"relation": "member"}}`, func(filter *v1.SubjectFilter) {
+		require.True(t, proto.Equal(&v1.SubjectFilter{
+			SubjectType:       "acme/group",
+			OptionalSubjectId: "eng",
+			OptionalRelation:  &v1.SubjectFilter_RelationFilter{Relation: "member"},
+		}, filter))
+	})
+	require.NoError(t, err)
+
+	err = watch(`{"subjectType": "other/group"}`, func(*v1.SubjectFilter) {
+		require.Fail(t, "handler must not be called")
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestDefinitions(t *testing.T) {
+	objectDefs := []*core.NamespaceDefinition{{
+		Name: "document",
+		Relation: []*core.Relation{{
+			Name: "viewer",
+			TypeInformation: &core.TypeInformation{AllowedDirectRelations: []*core.AllowedRelation{{
+				Namespace:      "user",
+				RequiredCaveat: &core.AllowedCaveat{CaveatName: "unexpired"},
+			}}},
+		}},
+	}}
+	caveatDefs := []*core.CaveatDefinition{{Name: "unexpired"}}
+
+	require.NoError(t, PrefixDefinitions("acme", objectDefs, caveatDefs))
+	require.Equal(t, "acme/document", objectDefs[0].Name)
+	allowed := objectDefs[0].Relation[0].TypeInformation.AllowedDirectRelations[0]
+	require.Equal(t, "acme/user", allowed.Namespace)
+	require.Equal(t, "acme/unexpired", allowed.RequiredCaveat.CaveatName)
+	require.Equal(t, "acme/unexpired", caveatDefs[0].Name)
+
+	require.True(t, OwnsDefinition("acme", objectDefs[0].Name))
+	require.False(t, OwnsDefinition("acmecorp", objectDefs[0].Name))
+
+	require.NoError(t, StripDefinitions("acme", objectDefs, caveatDefs))
+	require.Equal(t, "document", objectDefs[0].Name)
+	require.Equal(t, "user", allowed.Namespace)
+	require.Equal(t, "unexpired", caveatDefs[0].Name)
+
+	err := PrefixDefinitions("acme", []*core.NamespaceDefinition{{Name: "other/document"}}, nil)
+	require.ErrorContains(t, err, "must not be prefixed")
+
+	err = StripDefinitions("acme", []*core.NamespaceDefinition{{Name: "other/document"}}, nil)
+	require.ErrorContains(t, err, "does not belong to the tenant")
+}
@@ -0,0 +1,435 @@
+// Package usage accounts for the API requests and relationship mutations of each caller, and
+// the relationships stored by each tenant, so that the teams sharing a cluster can be billed
+// by usage. Callers are accounted for by tenant when tenant isolation is enabled, and by their
+// principal otherwise. Usage is counted per calendar month, in UTC, and may be limited by
+// monthly quotas.
+package usage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	"github.com/authzed/spicedb/internal/auth"
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/middleware/tenancy"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/spiceerrors"
+)
+
+var (
+	requestsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "spicedb",
+		Subsystem: "usage",
+		Name:      "requests_total",
+		Help:      "Count of the API requests made by each account",
+	}, []string{"account"})
+
+	mutationsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "spicedb",
+		Subsystem: "usage",
+		Name:      "mutations_total",
+		Help:      "Count of the relationship mutations made by each account",
+	}, []string{"account"})
+
+	storedRelationshipsGauge = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "spicedb",
+		Subsystem: "usage",
+		Name:      "stored_relationships",
+		Help:      "Number of relationships stored by each tenant, as of the last refresh",
+	}, []string{"tenant"})
+
+	quotaExceededCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "spicedb",
+		Subsystem: "usage",
+		Name:      "quota_exceeded_total",
+		Help:      "Count of the requests rejected for exceeding a monthly usage quota",
+	}, []string{"account", "quota"})
+)
+
+const (
+	quotaRequests       = "requests"
+	quotaMutations      = "mutations"
+	reasonQuotaExceeded = "ERROR_REASON_USAGE_QUOTA_EXCEEDED"
+
+	// DefaultAccount is the account name under which a quota applies to every account
+	// without a quota of its own.
+	DefaultAccount = "*"
+
+	apiServicePrefix = "/authzed.api."
+	monthFormat      = "2006-01"
+)
+
+// Quotas are the monthly limits of the usage of an account. A zero value places no limit.
+type Quotas struct {
+	// MaxRequests is the maximum number of API requests per month.
+	MaxRequests uint64
+
+	// MaxMutations is the maximum number of relationship mutations per month. Each
+	// relationship written or imported is a mutation, as is each DeleteRelationships call.
+	MaxMutations uint64
+}
+
+// ParseQuotas builds the quotas of each account from flag values mapping accounts, or
+// DefaultAccount, to their maximum monthly requests and mutations.
+func ParseQuotas(maxRequests, maxMutations map[string]string) (map[string]Quotas, error) {
+	quotas := map[string]Quotas{}
+	for account, value := range maxRequests {
+		count, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid monthly request quota for account `%s`: %w", account, err)
+		}
+		accountQuotas := quotas[account]
+		accountQuotas.MaxRequests = count
+		quotas[account] = accountQuotas
+	}
+
+	for account, value := range maxMutations {
+		count, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid monthly mutation quota for account `%s`: %w", account, err)
+		}
+		accountQuotas := quotas[account]
+		accountQuotas.MaxMutations = count
+		quotas[account] = accountQuotas
+	}
+	return quotas, nil
+}
+
+// Counts are the usage of an account in a month.
+type Counts struct {
+	Requests  uint64 `json:"requests"`
+	Mutations uint64 `json:"mutations"`
+}
+
+// Accountant counts the usage of each account for the current month, and enforces their
+// quotas. Usage is counted by each node, so quotas are enforced per node and the usage of a
+// cluster is the sum of that of its nodes, as reported by the metrics. Counts restart at zero
+// when the node restarts.
+type Accountant struct {
+	quotas map[string]Quotas
+	now    func() time.Time
+
+	mu        sync.Mutex
+	month     string
+	counts    map[string]*Counts
+	tenants   map[string]uint64
+	refreshed time.Time
+}
+
+// NewAccountant returns an accountant enforcing the quotas of each account.
+func NewAccountant(quotas map[string]Quotas) *Accountant {
+	return &Accountant{
+		quotas:  quotas,
+		now:     time.Now,
+		counts:  map[string]*Counts{},
+		tenants: map[string]uint64{},
+	}
+}
+
+// AccountFromContext returns the account of the request: its tenant, if tenant isolation is
+// enabled, or otherwise its principal.
+func AccountFromContext(ctx context.Context) string {
+	if tenant, ok := tenancy.FromContext(ctx); ok {
+		return "tenant:" + tenant
+	}
+	return auth.PrincipalFromContext(ctx)
+}
+
+func (a *Accountant) quotasOf(account string) Quotas {
+	if quotas, ok := a.quotas[account]; ok {
+		return quotas
+	}
+	return a.quotas[DefaultAccount]
+}
+
+// rollover restarts the counts when a new month has started. It must be called with the lock
+// held.
+func (a *Accountant) rollover() {
+	if month := a.now().UTC().Format(monthFormat); month != a.month {
+		a.month = month
+		a.counts = map[string]*Counts{}
+	}
+}
+
+// countsOf returns the counts of the account for the current month. It must be called with the
+// lock held.
+func (a *Accountant) countsOf(account string) *Counts {
+	a.rollover()
+	counts, ok := a.counts[account]
+	if !ok {
+		counts = &Counts{}
+		a.counts[account] = counts
+	}
+	return counts
+}
+
+// admit counts a request of the account making the mutations, unless it would exceed one of
+// the quotas of the account.
+func (a *Accountant) admit(account string, requests, mutations uint64) error {
+	quotas := a.quotasOf(account)
+
+	a.mu.Lock()
+	counts := a.countsOf(account)
+	switch {
+	case quotas.MaxRequests > 0 && counts.Requests+requests > quotas.MaxRequests:
+		a.mu.Unlock()
+		return a.quotaError(account, quotaRequests, quotas.MaxRequests)
+	case quotas.MaxMutations > 0 && counts.Mutations+mutations > quotas.MaxMutations:
+		a.mu.Unlock()
+		return a.quotaError(account, quotaMutations, quotas.MaxMutations)
+	}
+	counts.Requests += requests
+	counts.Mutations += mutations
+	a.mu.Unlock()
+
+	if requests > 0 {
+		requestsCounter.WithLabelValues(account).Add(float64(requests))
+	}
+	if mutations > 0 {
+		mutationsCounter.WithLabelValues(account).Add(float64(mutations))
+	}
+	return nil
+}
+
+func (a *Accountant) quotaError(account, quota string, limit uint64) error {
+	quotaExceededCounter.WithLabelValues(account, quota).Inc()
+
+	now := a.now().UTC()
+	nextMonth := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+	return spiceerrors.WithCodeAndDetailsAsError(
+		fmt.Errorf("account `%s` has reached its monthly quota of %d %s", account, limit, quota),
+		codes.ResourceExhausted,
+		&errdetails.ErrorInfo{
+			Reason: reasonQuotaExceeded,
+			Domain: spiceerrors.Domain,
+			Metadata: map[string]string{
+				"account": account,
+				"quota":   quota,
+				"limit":   strconv.FormatUint(limit, 10),
+			},
+		},
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(nextMonth.Sub(now))},
+	)
+}
+
+// Run refreshes the number of relationships stored by each tenant immediately and then at the
+// interval, until the context is canceled. Relationships are attributed to tenants by the
+// prefix of their resource type, so the counts are only meaningful with tenant isolation.
+func (a *Accountant) Run(ctx context.Context, ds datastore.Datastore, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := a.refresh(ctx, ds); err != nil && ctx.Err() == nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("could not refresh the stored relationship counts of tenants")
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (a *Accountant) refresh(ctx context.Context, ds datastore.Datastore) error {
+	headRevision, err := ds.HeadRevision(ctx)
+	if err != nil {
+		return fmt.Errorf("error reading head revision: %w", err)
+	}
+
+	reader := ds.SnapshotReader(headRevision)
+	namespaces, err := reader.ListAllNamespaces(ctx)
+	if err != nil {
+		return fmt.Errorf("error listing namespaces: %w", err)
+	}
+
+	tenants := map[string]uint64{}
+	for _, namespace := range namespaces {
+		tenant, _, ok := strings.Cut(namespace.Definition.Name, "/")
+		if !ok {
+			continue
+		}
+
+		count, err := countRelationships(ctx, reader, namespace.Definition.Name)
+		if err != nil {
+			return fmt.Errorf("error counting relationships of namespace `%s`: %w", namespace.Definition.Name, err)
+		}
+		tenants[tenant] += count
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	for tenant := range a.tenants {
+		if _, ok := tenants[tenant]; !ok {
+			storedRelationshipsGauge.DeleteLabelValues(tenant)
+		}
+	}
+	for tenant, count := range tenants {
+		storedRelationshipsGauge.WithLabelValues(tenant).Set(float64(count))
+	}
+	a.tenants = tenants
+	a.refreshed = a.now()
+	return nil
+}
+
+func countRelationships(ctx context.Context, reader datastore.Reader, namespace string) (uint64, error) {
+	it, err := reader.QueryRelationships(ctx, datastore.RelationshipsFilter{ResourceType: namespace})
+	if err != nil {
+		return 0, err
+	}
+	defer it.Close()
+
+	var count uint64
+	for tpl := it.Next(); tpl != nil; tpl = it.Next() {
+		count++
+	}
+	return count, it.Err()
+}
+
+// AccountReport is the usage of an account in the current month, and its quotas.
+type AccountReport struct {
+	Account string `json:"account"`
+	Counts
+	RequestQuota  uint64 `json:"requestQuota,omitempty"`
+	MutationQuota uint64 `json:"mutationQuota,omitempty"`
+}
+
+// Report is the usage of the accounts in the current month, and the relationships stored by
+// each tenant as of the last refresh.
+type Report struct {
+	Month                 string            `json:"month"`
+	Accounts              []AccountReport   `json:"accounts"`
+	StoredRelationships   map[string]uint64 `json:"storedRelationships,omitempty"`
+	StoredRelationshipsAt *time.Time        `json:"storedRelationshipsAt,omitempty"`
+}
+
+// Report returns the usage of the accounts, ordered by account.
+func (a *Accountant) Report() Report {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.rollover()
+	report := Report{Month: a.month, Accounts: make([]AccountReport, 0, len(a.counts))}
+	for account, counts := range a.counts {
+		quotas := a.quotasOf(account)
+		report.Accounts = append(report.Accounts, AccountReport{
+			Account:       account,
+			Counts:        *counts,
+			RequestQuota:  quotas.MaxRequests,
+			MutationQuota: quotas.MaxMutations,
+		})
+	}
+	sort.Slice(report.Accounts, func(i, j int) bool { return report.Accounts[i].Account < report.Accounts[j].Account })
+
+	if !a.refreshed.IsZero() {
+		refreshed := a.refreshed
+		report.StoredRelationshipsAt = &refreshed
+		report.StoredRelationships = make(map[string]uint64, len(a.tenants))
+		for tenant, count := range a.tenants {
+			report.StoredRelationships[tenant] = count
+		}
+	}
+	return report
+}
+
+// RegisterHandlers adds the usage endpoint to the mux: GET /debug/usage reports the usage of
+// each account in the current month, and the relationships stored by each tenant. A nil
+// accountant registers nothing.
+func (a *Accountant) RegisterHandlers(mux *http.ServeMux) {
+	if a == nil {
+		return
+	}
+
+	mux.HandleFunc("/debug/usage", func(w http.ResponseWriter, r *http.Request) {
+		encoded, err := json.MarshalIndent(a.Report(), "", "  ")
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, "%s\n", encoded)
+	})
+}
+
+// mutations returns the number of relationship mutations made by the request.
+func mutations(req any) uint64 {
+	switch r := req.(type) {
+	case *v1.WriteRelationshipsRequest:
+		return uint64(len(r.Updates))
+	case *v1.DeleteRelationshipsRequest:
+		return 1
+	case *v1.BulkImportRelationshipsRequest:
+		return uint64(len(r.Relationships))
+	default:
+		return 0
+	}
+}
+
+// UnaryServerInterceptor returns a new interceptor which counts the API requests and
+// mutations of each account, and rejects those exceeding its quotas. It must run after
+// authentication and tenant isolation, so that the account is known. A nil accountant
+// allows all requests.
+func UnaryServerInterceptor(accountant *Accountant) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if accountant == nil || !strings.HasPrefix(info.FullMethod, apiServicePrefix) {
+			return handler(ctx, req)
+		}
+
+		if err := accountant.admit(AccountFromContext(ctx), 1, mutations(req)); err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a new interceptor which counts the API streams of each
+// account as requests, and the relationships they import as mutations, rejecting those
+// exceeding its quotas. A nil accountant allows all streams.
+func StreamServerInterceptor(accountant *Accountant) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if accountant == nil || !strings.HasPrefix(info.FullMethod, apiServicePrefix) {
+			return handler(srv, stream)
+		}
+
+		account := AccountFromContext(stream.Context())
+		if err := accountant.admit(account, 1, 0); err != nil {
+			return err
+		}
+		return handler(srv, &usageStream{ServerStream: stream, accountant: accountant, account: account})
+	}
+}
+
+// usageStream counts the mutations of the requests received on a stream.
+type usageStream struct {
+	grpc.ServerStream
+
+	accountant *Accountant
+	account    string
+}
+
+func (s *usageStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if count := mutations(m); count > 0 {
+		return s.accountant.admit(s.account, 0, count)
+	}
+	return nil
+}
@@ -0,0 +1,202 @@
+package usage
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/middleware/tenancy"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func TestParseQuotas(t *testing.T) {
+	quotas, err := ParseQuotas(
+		map[string]string{"tenant:acme": "1000", DefaultAccount: "10"},
+		map[string]string{"tenant:acme": "50"},
+	)
+	require.NoError(t, err)
+	require.Equal(t, map[string]Quotas{
+		"tenant:acme":  {MaxRequests: 1000, MaxMutations: 50},
+		DefaultAccount: {MaxRequests: 10},
+	}, quotas)
+
+	_, err = ParseQuotas(map[string]string{"tenant:acme": "lots"}, nil)
+	require.ErrorContains(t, err, "invalid monthly request quota for account `tenant:acme`")
+
+	_, err = ParseQuotas(nil, map[string]string{"tenant:acme": "-1"})
+	require.ErrorContains(t, err, "invalid monthly mutation quota for account `tenant:acme`")
+}
+
+func write(accountant *Accountant, tenant string, updates int) error {
+	req := &v1.WriteRelationshipsRequest{}
+	for i := 0; i < updates; i++ {
+		req.Updates = append(req.Updates, &v1.RelationshipUpdate{Operation: v1.RelationshipUpdate_OPERATION_TOUCH})
+	}
+
+	ctx := tenancy.ContextWithTenant(context.Background(), tenant)
+	info := &grpc.UnaryServerInfo{FullMethod: v1.PermissionsService_WriteRelationships_FullMethodName}
+	_, err := UnaryServerInterceptor(accountant)(ctx, req, info, func(context.Context, any) (any, error) {
+		return nil, nil
+	})
+	return err
+}
+
+func TestMonthlyQuotas(t *testing.T) {
+	accountant := NewAccountant(map[string]Quotas{
+		"tenant:acme":  {MaxRequests: 3, MaxMutations: 5},
+		DefaultAccount: {MaxRequests: 1},
+	})
+	now := time.Date(2024, time.January, 31, 12, 0, 0, 0, time.UTC)
+	accountant.now = func() time.Time { return now }
+
+	require.NoError(t, write(accountant, "acme", 2))
+	require.NoError(t, write(accountant, "acme", 3))
+
+	err := write(accountant, "acme", 1)
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	require.ErrorContains(t, err, "account `tenant:acme` has reached its monthly quota of 5 mutations")
+
+	// Other accounts have the default quota.
+	require.NoError(t, write(accountant, "globex", 0))
+	err = write(accountant, "globex", 0)
+	require.ErrorContains(t, err, "account `tenant:globex` has reached its monthly quota of 1 requests")
+
+	require.Equal(t, []AccountReport{
+		{Account: "tenant:acme", Counts: Counts{Requests: 2, Mutations: 5}, RequestQuota: 3, MutationQuota: 5},
+		{Account: "tenant:globex", Counts: Counts{Requests: 1}, RequestQuota: 1},
+	}, accountant.Report().Accounts)
+
+	// Usage is counted again from zero in the next month.
+	now = now.Add(24 * time.Hour)
+	report := accountant.Report()
+	require.Equal(t, "2024-02", report.Month)
+	require.Empty(t, report.Accounts)
+	require.NoError(t, write(accountant, "acme", 5))
+}
+
+func TestUnlimitedAccounting(t *testing.T) {
+	accountant := NewAccountant(nil)
+	for i := 0; i < 10; i++ {
+		require.NoError(t, write(accountant, "acme", 10))
+	}
+	require.Equal(t, Counts{Requests: 10, Mutations: 100}, accountant.Report().Accounts[0].Counts)
+
+	// Requests outside the API are not counted.
+	_, err := UnaryServerInterceptor(accountant)(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}, func(context.Context, any) (any, error) {
+		return nil, nil
+	})
+	require.NoError(t, err)
+	require.Len(t, accountant.Report().Accounts, 1)
+
+	// A nil accountant counts nothing.
+	require.NoError(t, write(nil, "acme", 1))
+}
+
+type fakeStream struct {
+	grpc.ServerStream
+
+	ctx  context.Context
+	reqs []proto.Message
+}
+
+func (fs *fakeStream) Context() context.Context { return fs.ctx }
+
+func (fs *fakeStream) RecvMsg(m any) error {
+	proto.Merge(m.(proto.Message), fs.reqs[0])
+	fs.reqs = fs.reqs[1:]
+	return nil
+}
+
+func TestStreamMutations(t *testing.T) {
+	accountant := NewAccountant(map[string]Quotas{"tenant:acme": {MaxMutations: 3}})
+	relationships := func(count int) *v1.BulkImportRelationshipsRequest {
+		return &v1.BulkImportRelationshipsRequest{Relationships: make([]*v1.Relationship, count)}
+	}
+
+	stream := &fakeStream{
+		ctx:  tenancy.ContextWithTenant(context.Background(), "acme"),
+		reqs: []proto.Message{relationships(2), relationships(2)},
+	}
+	info := &grpc.StreamServerInfo{FullMethod: v1.ExperimentalService_BulkImportRelationships_FullMethodName}
+	err := StreamServerInterceptor(accountant)(nil, stream, info, func(_ any, stream grpc.ServerStream) error {
+		for {
+			if err := stream.RecvMsg(&v1.BulkImportRelationshipsRequest{}); err != nil {
+				return err
+			}
+		}
+	})
+	require.Equal(t, codes.ResourceExhausted, status.Code(err))
+	require.Equal(t, Counts{Requests: 1, Mutations: 2}, accountant.Report().Accounts[0].Counts)
+}
+
+func TestStoredRelationships(t *testing.T) {
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	ctx := context.Background()
+	_, err = ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		if err := rwt.WriteNamespaces(ctx,
+			&core.NamespaceDefinition{Name: "acme/document"},
+			&core.NamespaceDefinition{Name: "acme/folder"},
+			&core.NamespaceDefinition{Name: "globex/document"},
+			&core.NamespaceDefinition{Name: "shared"},
+		); err != nil {
+			return err
+		}
+		return rwt.WriteRelationships(ctx, []*core.RelationTupleUpdate{
+			tuple.Create(tuple.MustParse("acme/document:readme#viewer@acme/user:alice")),
+			tuple.Create(tuple.MustParse("acme/document:readme#viewer@acme/user:bob")),
+			tuple.Create(tuple.MustParse("acme/folder:docs#viewer@acme/user:alice")),
+			tuple.Create(tuple.MustParse("shared:docs#viewer@shared:alice")),
+		})
+	})
+	require.NoError(t, err)
+
+	accountant := NewAccountant(nil)
+	require.Nil(t, accountant.Report().StoredRelationships)
+	require.NoError(t, accountant.refresh(ctx, ds))
+
+	report := accountant.Report()
+	require.Equal(t, map[string]uint64{"acme": 3, "globex": 0}, report.StoredRelationships)
+	require.NotNil(t, report.StoredRelationshipsAt)
+
+	canceled, cancel := context.WithCancel(ctx)
+	cancel()
+	require.NoError(t, accountant.Run(canceled, ds, time.Minute))
+}
+
+func TestUsageHandler(t *testing.T) {
+	accountant := NewAccountant(nil)
+	require.NoError(t, write(accountant, "acme", 2))
+
+	mux := http.NewServeMux()
+	accountant.RegisterHandlers(mux)
+
+	recorder := httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/usage", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var report Report
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &report))
+	require.Equal(t, []AccountReport{{Account: "tenant:acme", Counts: Counts{Requests: 1, Mutations: 2}}}, report.Accounts)
+
+	// A nil accountant registers no endpoint.
+	mux = http.NewServeMux()
+	(*Accountant)(nil).RegisterHandlers(mux)
+	recorder = httptest.NewRecorder()
+	mux.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/usage", nil))
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+}
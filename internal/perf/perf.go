@@ -0,0 +1,366 @@
+// Package perf generates a synthetic schema, relationships and load against a SpiceDB server,
+// for capacity testing datastore engines and dispatch configurations.
+package perf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	authzed "github.com/authzed/authzed-go/v1"
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const (
+	userType     = "spicedb_perf/user"
+	groupType    = "spicedb_perf/group"
+	documentType = "spicedb_perf/document"
+)
+
+// Schema is the schema of the synthetic definitions, which is added to the schema of the server.
+const Schema = `definition spicedb_perf/user {}
+
+definition spicedb_perf/group {
+	relation member: spicedb_perf/user | spicedb_perf/group#member
+}
+
+definition spicedb_perf/document {
+	relation viewer: spicedb_perf/user | spicedb_perf/group#member
+	relation editor: spicedb_perf/user
+	permission edit = editor
+	permission view = viewer + edit
+}`
+
+// writeBatchSize is the number of relationships written at once when setting up.
+const writeBatchSize = 500
+
+// Shape is the shape of the synthetic relationships.
+type Shape struct {
+	// Documents, Users and Groups are the number of each object.
+	Documents int
+	Users     int
+	Groups    int
+
+	// GroupMembers is the number of users which are members of each group.
+	GroupMembers int
+
+	// NestingDepth is the number of groups nested in each chain of groups, each a member of
+	// the one before, which check dispatches through.
+	NestingDepth int
+
+	// DocumentViewers is the number of users or groups which are viewers of each document.
+	DocumentViewers int
+}
+
+// Mix is the relative weight of each kind of operation in the load.
+type Mix struct {
+	Check int
+	Read  int
+	Write int
+}
+
+// Options configure the load generated by Run.
+type Options struct {
+	Shape Shape
+	Mix   Mix
+
+	// QPS is the rate of operations across all workers. If zero, the rate is not limited.
+	QPS float64
+
+	// Concurrency is the number of operations in flight at once.
+	Concurrency int
+
+	// Duration is how long load is generated for.
+	Duration time.Duration
+
+	// Seed seeds the choice of operations and objects.
+	Seed int64
+}
+
+// Setup adds the synthetic definitions to the schema of the server, if they are missing, and
+// writes the synthetic relationships of the shape.
+func Setup(ctx context.Context, client *authzed.Client, shape Shape, seed int64) (int, error) {
+	existing, err := client.ReadSchema(ctx, &v1.ReadSchemaRequest{})
+	switch {
+	case status.Code(err) == codes.NotFound:
+		existing = &v1.ReadSchemaResponse{}
+	case err != nil:
+		return 0, fmt.Errorf("failed to read schema: %w", err)
+	}
+
+	if !strings.Contains(existing.SchemaText, "definition "+documentType) {
+		schema := strings.TrimSpace(existing.SchemaText + "\n\n" + Schema)
+		if _, err := client.WriteSchema(ctx, &v1.WriteSchemaRequest{Schema: schema}); err != nil {
+			return 0, fmt.Errorf("failed to write schema: %w", err)
+		}
+	}
+
+	rels := relationships(shape, rand.New(rand.NewSource(seed)))
+	for start := 0; start < len(rels); start += writeBatchSize {
+		end := min(start+writeBatchSize, len(rels))
+		updates := make([]*v1.RelationshipUpdate, 0, end-start)
+		for _, rel := range rels[start:end] {
+			updates = append(updates, &v1.RelationshipUpdate{Operation: v1.RelationshipUpdate_OPERATION_TOUCH, Relationship: rel})
+		}
+
+		if _, err := client.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{Updates: updates}); err != nil {
+			return start, fmt.Errorf("failed to write relationships: %w", err)
+		}
+	}
+	return len(rels), nil
+}
+
+// relationships returns the synthetic relationships of the shape. Objects are chosen at
+// random, so a relationship chosen more than once is only returned once.
+func relationships(shape Shape, rnd *rand.Rand) []*v1.Relationship {
+	var rels []*v1.Relationship
+	if shape.Users == 0 {
+		return rels
+	}
+
+	seen := map[string]struct{}{}
+	add := func(rel *v1.Relationship) {
+		key := tuple.MustStringRelationship(rel)
+		if _, ok := seen[key]; !ok {
+			seen[key] = struct{}{}
+			rels = append(rels, rel)
+		}
+	}
+
+	for group := 0; group < shape.Groups; group++ {
+		for i := 0; i < shape.GroupMembers; i++ {
+			add(relationship(groupType, group, "member", userType, rnd.Intn(shape.Users), ""))
+		}
+
+		// Each group, except the first of each chain, is a member of the group before it.
+		if shape.NestingDepth > 0 && group%(shape.NestingDepth+1) != 0 {
+			add(relationship(groupType, group-1, "member", groupType, group, "member"))
+		}
+	}
+
+	for document := 0; document < shape.Documents; document++ {
+		add(relationship(documentType, document, "editor", userType, rnd.Intn(shape.Users), ""))
+		for i := 0; i < shape.DocumentViewers; i++ {
+			if shape.Groups > 0 && i%2 == 1 {
+				add(relationship(documentType, document, "viewer", groupType, rnd.Intn(shape.Groups), "member"))
+			} else {
+				add(relationship(documentType, document, "viewer", userType, rnd.Intn(shape.Users), ""))
+			}
+		}
+	}
+	return rels
+}
+
+func relationship(resourceType string, resourceID int, relation string, subjectType string, subjectID int, subjectRelation string) *v1.Relationship {
+	return &v1.Relationship{
+		Resource: object(resourceType, resourceID),
+		Relation: relation,
+		Subject:  &v1.SubjectReference{Object: object(subjectType, subjectID), OptionalRelation: subjectRelation},
+	}
+}
+
+func object(objectType string, id int) *v1.ObjectReference {
+	return &v1.ObjectReference{ObjectType: objectType, ObjectId: strconv.Itoa(id)}
+}
+
+// Report is the outcome of the load generated by Run.
+type Report struct {
+	Duration   time.Duration     `json:"duration"`
+	Operations []OperationReport `json:"operations"`
+}
+
+// OperationReport is the outcome of one kind of operation.
+type OperationReport struct {
+	Operation string        `json:"operation"`
+	Count     int           `json:"count"`
+	Errors    int           `json:"errors"`
+	P50       time.Duration `json:"p50"`
+	P90       time.Duration `json:"p90"`
+	P99       time.Duration `json:"p99"`
+	Max       time.Duration `json:"max"`
+}
+
+// Total returns the number of operations performed.
+func (r *Report) Total() int {
+	total := 0
+	for _, op := range r.Operations {
+		total += op.Count
+	}
+	return total
+}
+
+type operation struct {
+	name   string
+	weight int
+	run    func(ctx context.Context, client *authzed.Client, shape Shape, rnd *rand.Rand) error
+}
+
+type sample struct {
+	operation string
+	latency   time.Duration
+	err       error
+}
+
+// Run generates load of the mix of operations against the synthetic relationships of the
+// shape, which must have been set up, until the duration has elapsed or the context is canceled.
+func Run(ctx context.Context, client *authzed.Client, opts Options) (*Report, error) {
+	if opts.Shape.Documents == 0 || opts.Shape.Users == 0 {
+		return nil, errors.New("the shape must have at least one document and user")
+	}
+
+	operations := []operation{
+		{"check", opts.Mix.Check, check},
+		{"read", opts.Mix.Read, read},
+		{"write", opts.Mix.Write, write},
+	}
+	totalWeight := 0
+	for _, op := range operations {
+		if op.weight < 0 {
+			return nil, fmt.Errorf("the weight of %s must not be negative", op.name)
+		}
+		totalWeight += op.weight
+	}
+	if totalWeight == 0 {
+		return nil, errors.New("the mix must include at least one operation")
+	}
+
+	limit := rate.Inf
+	if opts.QPS > 0 {
+		limit = rate.Limit(opts.QPS)
+	}
+	limiter := rate.NewLimiter(limit, 1)
+
+	ctx, cancel := context.WithTimeout(ctx, opts.Duration)
+	defer cancel()
+
+	start := time.Now()
+	samples := make(chan sample, max(opts.Concurrency, 1)*16)
+	var wg sync.WaitGroup
+	for worker := 0; worker < max(opts.Concurrency, 1); worker++ {
+		wg.Add(1)
+		go func(rnd *rand.Rand) {
+			defer wg.Done()
+			for limiter.Wait(ctx) == nil {
+				op := pick(operations, totalWeight, rnd)
+				start := time.Now()
+				err := op.run(ctx, client, opts.Shape, rnd)
+				if ctx.Err() != nil {
+					// Operations cut short by the end of the run are not counted.
+					return
+				}
+				samples <- sample{op.name, time.Since(start), err}
+			}
+		}(rand.New(rand.NewSource(opts.Seed + int64(worker))))
+	}
+
+	go func() {
+		wg.Wait()
+		close(samples)
+	}()
+
+	latencies := map[string][]time.Duration{}
+	errorCounts := map[string]int{}
+	for s := range samples {
+		if s.err != nil {
+			errorCounts[s.operation]++
+			continue
+		}
+		latencies[s.operation] = append(latencies[s.operation], s.latency)
+	}
+
+	report := &Report{Duration: time.Since(start)}
+	for _, op := range operations {
+		if op.weight == 0 {
+			continue
+		}
+
+		opLatencies := latencies[op.name]
+		sort.Slice(opLatencies, func(i, j int) bool { return opLatencies[i] < opLatencies[j] })
+		report.Operations = append(report.Operations, OperationReport{
+			Operation: op.name,
+			Count:     len(opLatencies) + errorCounts[op.name],
+			Errors:    errorCounts[op.name],
+			P50:       percentile(opLatencies, 0.50),
+			P90:       percentile(opLatencies, 0.90),
+			P99:       percentile(opLatencies, 0.99),
+			Max:       percentile(opLatencies, 1),
+		})
+	}
+	return report, nil
+}
+
+func pick(operations []operation, totalWeight int, rnd *rand.Rand) operation {
+	n := rnd.Intn(totalWeight)
+	for _, op := range operations {
+		if n < op.weight {
+			return op
+		}
+		n -= op.weight
+	}
+	return operations[len(operations)-1]
+}
+
+// percentile returns the latency at the percentile of the sorted latencies.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	index := int(float64(len(sorted))*p+0.5) - 1
+	return sorted[max(0, min(index, len(sorted)-1))]
+}
+
+var minimizeLatency = &v1.Consistency{Requirement: &v1.Consistency_MinimizeLatency{MinimizeLatency: true}}
+
+func check(ctx context.Context, client *authzed.Client, shape Shape, rnd *rand.Rand) error {
+	_, err := client.CheckPermission(ctx, &v1.CheckPermissionRequest{
+		Consistency: minimizeLatency,
+		Resource:    object(documentType, rnd.Intn(shape.Documents)),
+		Permission:  "view",
+		Subject:     &v1.SubjectReference{Object: object(userType, rnd.Intn(shape.Users))},
+	})
+	return err
+}
+
+func read(ctx context.Context, client *authzed.Client, shape Shape, rnd *rand.Rand) error {
+	stream, err := client.ReadRelationships(ctx, &v1.ReadRelationshipsRequest{
+		Consistency: minimizeLatency,
+		RelationshipFilter: &v1.RelationshipFilter{
+			ResourceType:       documentType,
+			OptionalResourceId: strconv.Itoa(rnd.Intn(shape.Documents)),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	for {
+		if _, err := stream.Recv(); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+	}
+}
+
+func write(ctx context.Context, client *authzed.Client, shape Shape, rnd *rand.Rand) error {
+	_, err := client.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{{
+			Operation:    v1.RelationshipUpdate_OPERATION_TOUCH,
+			Relationship: relationship(documentType, rnd.Intn(shape.Documents), "viewer", userType, rnd.Intn(shape.Users), ""),
+		}},
+	})
+	return err
+}
@@ -0,0 +1,104 @@
+package perf
+
+import (
+	"context"
+	"math/rand"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/testserver"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+var testShape = Shape{
+	Documents:       20,
+	Users:           10,
+	Groups:          6,
+	GroupMembers:    2,
+	NestingDepth:    2,
+	DocumentViewers: 3,
+}
+
+func TestRelationships(t *testing.T) {
+	rels := relationships(testShape, rand.New(rand.NewSource(1)))
+	require.Equal(t, rels, relationships(testShape, rand.New(rand.NewSource(1))))
+
+	// Each group has its members, the non-first group of each chain of three is nested, and
+	// each document has an editor and its viewers, less any chosen twice.
+	require.LessOrEqual(t, len(rels), 6*2+4+20*(1+3))
+
+	nested := 0
+	seen := map[string]bool{}
+	for _, rel := range rels {
+		key := tuple.MustStringRelationship(rel)
+		require.False(t, seen[key], "duplicate relationship %s", key)
+		seen[key] = true
+
+		if rel.Subject.OptionalRelation != "" && rel.Resource.ObjectType == groupType {
+			nested++
+		}
+	}
+	require.Equal(t, 4, nested)
+}
+
+func TestSetupAndRun(t *testing.T) {
+	srv := testserver.NewTestServer(t, testserver.WithSchema(`definition existing {}`))
+	client := &srv.Client().Client
+	ctx := context.Background()
+
+	written, err := Setup(ctx, client, testShape, 1)
+	require.NoError(t, err)
+	require.Equal(t, len(relationships(testShape, rand.New(rand.NewSource(1)))), written)
+
+	// The existing schema is kept, and setting up again does not rewrite the schema.
+	_, err = Setup(ctx, client, testShape, 1)
+	require.NoError(t, err)
+
+	schema, err := client.ReadSchema(ctx, &v1.ReadSchemaRequest{})
+	require.NoError(t, err)
+	require.Contains(t, schema.SchemaText, "definition existing {}")
+	require.Contains(t, schema.SchemaText, "definition spicedb_perf/document")
+
+	report, err := Run(ctx, client, Options{
+		Shape:       testShape,
+		Mix:         Mix{Check: 8, Read: 1, Write: 1},
+		QPS:         200,
+		Concurrency: 4,
+		Duration:    500 * time.Millisecond,
+		Seed:        1,
+	})
+	require.NoError(t, err)
+	require.Len(t, report.Operations, 3)
+	require.Positive(t, report.Total())
+
+	for _, op := range report.Operations {
+		require.Zero(t, op.Errors, op.Operation)
+		if op.Count > 0 {
+			require.LessOrEqual(t, op.P50, op.P99)
+			require.LessOrEqual(t, op.P99, op.Max)
+		}
+	}
+}
+
+func TestRunInvalidOptions(t *testing.T) {
+	_, err := Run(context.Background(), nil, Options{Shape: testShape})
+	require.ErrorContains(t, err, "at least one operation")
+
+	_, err = Run(context.Background(), nil, Options{Mix: Mix{Check: 1}})
+	require.ErrorContains(t, err, "at least one document and user")
+}
+
+func TestPercentile(t *testing.T) {
+	latencies := make([]time.Duration, 0, 100)
+	for i := 1; i <= 100; i++ {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	require.Equal(t, 50*time.Millisecond, percentile(latencies, 0.5))
+	require.Equal(t, 99*time.Millisecond, percentile(latencies, 0.99))
+	require.Equal(t, 100*time.Millisecond, percentile(latencies, 1))
+	require.Zero(t, percentile(nil, 0.5))
+}
@@ -0,0 +1,364 @@
+// Package playground implements an interactive web playground for schemas, served by
+// serve-devtools. Each request holds the schema and relationships being edited, which are
+// loaded into an ephemeral in-memory datastore for the duration of the request, so the
+// playground holds no state between requests.
+package playground
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/development"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	devinterface "github.com/authzed/spicedb/pkg/proto/developer/v1"
+	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/generator"
+	"github.com/authzed/spicedb/pkg/schemautil"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const (
+	// maxRequestBytes bounds the size of the schema, relationships and parameters of a request.
+	maxRequestBytes = 1 << 20
+
+	// requestTimeout bounds the time spent running each request.
+	requestTimeout = 10 * time.Second
+
+	// maxGraphDepth and maxGraphEdges bound the graphs built by the playground.
+	maxGraphDepth = 10
+	maxGraphEdges = 500
+)
+
+//go:embed index.html
+var indexHTML []byte
+
+// NewHandler returns the handler of the playground: its page at `/`, and its API under `/api/`.
+// Each API call is a POST of a JSON object holding the `schema` and newline-separated
+// `relationships`, along with the parameters of the call, and returns a JSON object holding
+// either the `errors` found in the schema, relationships or parameters, or the `result`.
+func NewHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write(indexHTML)
+	})
+	mux.Handle("/api/validate", apiHandler(runValidate))
+	mux.Handle("/api/check", apiHandler(runCheck))
+	mux.Handle("/api/expand", apiHandler(runExpand))
+	mux.Handle("/api/graph", apiHandler(runGraph))
+	return mux
+}
+
+// Request is the body of a call to the API of the playground. The parameters used depend on
+// the call.
+type Request struct {
+	Schema        string `json:"schema"`
+	Relationships string `json:"relationships"`
+
+	// Resource is the resource of a check, such as `document:readme`, or the object from which
+	// a graph is built.
+	Resource string `json:"resource,omitempty"`
+
+	// Permission is the permission checked or expanded, or that through which a graph is built.
+	Permission string `json:"permission,omitempty"`
+
+	// Subject is the subject of a check, such as `user:alice` or `group:eng#member`.
+	Subject string `json:"subject,omitempty"`
+
+	// Context is the caveat context of a check.
+	Context map[string]any `json:"context,omitempty"`
+
+	// Depth is the maximum depth of a graph, defaulting to 3.
+	Depth int `json:"depth,omitempty"`
+}
+
+// Response is the body returned by a call to the API of the playground.
+type Response struct {
+	Errors []json.RawMessage `json:"errors,omitempty"`
+	Result json.RawMessage   `json:"result,omitempty"`
+}
+
+// apiFunc runs an API call against the development context populated with the schema and
+// relationships of the request, returning the result or the errors in the request.
+type apiFunc func(devContext *development.DevContext, req Request) (any, []*devinterface.DeveloperError, error)
+
+func apiHandler(fn apiFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req Request
+		if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBytes)).Decode(&req); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request: %s", err), http.StatusBadRequest)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), requestTimeout)
+		defer cancel()
+
+		result, devErrs, err := run(ctx, req, fn)
+		if err != nil {
+			log.Ctx(ctx).Warn().Err(err).Str("path", r.URL.Path).Msg("playground request failed")
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		resp, err := newResponse(result, devErrs)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			log.Ctx(ctx).Warn().Err(err).Msg("failed to write playground response")
+		}
+	})
+}
+
+// run populates an ephemeral development context with the schema and relationships of the
+// request, and runs the call against it.
+func run(ctx context.Context, req Request, fn apiFunc) (any, []*devinterface.DeveloperError, error) {
+	relationships, devErrs := parseRelationships(req.Relationships)
+	if len(devErrs) > 0 {
+		return nil, devErrs, nil
+	}
+
+	devContext, inputErrs, err := development.NewDevContext(ctx, &devinterface.RequestContext{
+		Schema:        req.Schema,
+		Relationships: relationships,
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if inputErrs != nil && len(inputErrs.InputErrors) > 0 {
+		return nil, inputErrs.InputErrors, nil
+	}
+	defer devContext.Dispose()
+
+	return fn(devContext, req)
+}
+
+// parseRelationships parses the newline-separated relationships, skipping empty lines and
+// comments.
+func parseRelationships(relationships string) ([]*core.RelationTuple, []*devinterface.DeveloperError) {
+	var parsed []*core.RelationTuple
+	var devErrs []*devinterface.DeveloperError
+	seen := make(map[string]struct{})
+	for index, line := range strings.Split(relationships, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "//") {
+			continue
+		}
+
+		tpl := tuple.Parse(trimmed)
+		if tpl == nil {
+			devErrs = append(devErrs, relationshipError(devinterface.DeveloperError_PARSE_ERROR, index, trimmed, "error parsing relationship `%s`", trimmed))
+			continue
+		}
+
+		key := tuple.StringWithoutCaveat(tpl)
+		if _, ok := seen[key]; ok {
+			devErrs = append(devErrs, relationshipError(devinterface.DeveloperError_DUPLICATE_RELATIONSHIP, index, trimmed, "found repeated relationship `%s`", trimmed))
+			continue
+		}
+		seen[key] = struct{}{}
+		parsed = append(parsed, tpl)
+	}
+	return parsed, devErrs
+}
+
+func relationshipError(kind devinterface.DeveloperError_ErrorKind, index int, context string, format string, args ...any) *devinterface.DeveloperError {
+	return &devinterface.DeveloperError{
+		Message: fmt.Sprintf(format, args...),
+		Kind:    kind,
+		Source:  devinterface.DeveloperError_RELATIONSHIP,
+		Line:    uint32(index + 1),
+		Context: context,
+	}
+}
+
+// parameterError returns the error for an invalid parameter of a call.
+func parameterError(format string, args ...any) []*devinterface.DeveloperError {
+	return []*devinterface.DeveloperError{{
+		Message: fmt.Sprintf(format, args...),
+		Kind:    devinterface.DeveloperError_PARSE_ERROR,
+		Source:  devinterface.DeveloperError_CHECK_WATCH,
+	}}
+}
+
+func newResponse(result any, devErrs []*devinterface.DeveloperError) (Response, error) {
+	var resp Response
+	for _, devErr := range devErrs {
+		encoded, err := protojson.Marshal(devErr)
+		if err != nil {
+			return Response{}, err
+		}
+		resp.Errors = append(resp.Errors, encoded)
+	}
+	if len(devErrs) > 0 {
+		return resp, nil
+	}
+
+	var err error
+	if message, ok := result.(proto.Message); ok {
+		resp.Result, err = protojson.Marshal(message)
+	} else {
+		resp.Result, err = json.Marshal(result)
+	}
+	return resp, err
+}
+
+// ValidateResult is the result of a validate call.
+type ValidateResult struct {
+	// FormattedSchema is the schema, formatted.
+	FormattedSchema string `json:"formatted_schema"`
+
+	// Warnings are the warnings of the linter on the schema.
+	Warnings []schemautil.LintWarning `json:"warnings,omitempty"`
+}
+
+func runValidate(devContext *development.DevContext, _ Request) (any, []*devinterface.DeveloperError, error) {
+	formatted, _, err := generator.GenerateSchema(devContext.CompiledSchema.OrderedDefinitions)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return ValidateResult{
+		FormattedSchema: strings.TrimSpace(formatted),
+		Warnings:        schemautil.Lint(devContext.CompiledSchema.ObjectDefinitions, schemautil.LintOptions{}),
+	}, nil, nil
+}
+
+func runCheck(devContext *development.DevContext, req Request) (any, []*devinterface.DeveloperError, error) {
+	resource := tuple.ParseONR(req.Resource + "#" + req.Permission)
+	if resource == nil {
+		return nil, parameterError("invalid resource `%s` or permission `%s`: expected `type:id` and a permission", req.Resource, req.Permission), nil
+	}
+	subject := tuple.ParseSubjectONR(req.Subject)
+	if subject == nil {
+		return nil, parameterError("invalid subject `%s`: expected `type:id` or `type:id#relation`", req.Subject), nil
+	}
+
+	cr, err := development.RunCheck(devContext, resource, subject, req.Context)
+	if err != nil {
+		devErr, wireErr := development.DistinguishGraphError(devContext, err, devinterface.DeveloperError_CHECK_WATCH, 0, 0,
+			tuple.MustString(&core.RelationTuple{ResourceAndRelation: resource, Subject: subject}))
+		if wireErr != nil {
+			return nil, nil, wireErr
+		}
+		return nil, []*devinterface.DeveloperError{devErr}, nil
+	}
+
+	membership := devinterface.CheckOperationsResult_NOT_MEMBER
+	switch cr.Permissionship {
+	case dispatchv1.ResourceCheckResult_MEMBER:
+		membership = devinterface.CheckOperationsResult_MEMBER
+	case dispatchv1.ResourceCheckResult_CAVEATED_MEMBER:
+		membership = devinterface.CheckOperationsResult_CAVEATED_MEMBER
+	}
+
+	return &devinterface.CheckOperationsResult{
+		Membership:               membership,
+		ResolvedDebugInformation: cr.V1DebugInfo,
+		PartialCaveatInfo: &devinterface.PartialCaveatInfo{
+			MissingRequiredContext: cr.MissingCaveatFields,
+		},
+	}, nil, nil
+}
+
+func runExpand(devContext *development.DevContext, req Request) (any, []*devinterface.DeveloperError, error) {
+	resource, err := parseObject(req.Resource)
+	if err != nil {
+		return nil, parameterError("%s", err), nil
+	}
+
+	conn, stop, err := devContext.RunV1InMemoryService()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer stop()
+
+	resp, err := v1.NewPermissionsServiceClient(conn).ExpandPermissionTree(devContext.Ctx, &v1.ExpandPermissionTreeRequest{
+		Consistency: &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+		Resource:    resource,
+		Permission:  req.Permission,
+	})
+	if err != nil {
+		return nil, parameterError("could not expand %s#%s: %s", req.Resource, req.Permission, err), nil
+	}
+	return resp.TreeRoot, nil, nil
+}
+
+// GraphResult is the result of a graph call.
+type GraphResult struct {
+	Graph   *schemautil.ObjectGraph `json:"graph"`
+	Mermaid string                  `json:"mermaid"`
+}
+
+func runGraph(devContext *development.DevContext, req Request) (any, []*devinterface.DeveloperError, error) {
+	root, err := parseObject(req.Resource)
+	if err != nil {
+		return nil, parameterError("%s", err), nil
+	}
+
+	depth := req.Depth
+	if depth <= 0 {
+		depth = 3
+	}
+
+	reader := devContext.Datastore.SnapshotReader(devContext.Revision)
+	read := func(ctx context.Context, object *v1.ObjectReference) ([]*v1.Relationship, error) {
+		iter, err := reader.QueryRelationships(ctx, datastore.RelationshipsFilter{
+			ResourceType:        object.ObjectType,
+			OptionalResourceIds: []string{object.ObjectId},
+		})
+		if err != nil {
+			return nil, err
+		}
+		defer iter.Close()
+
+		var rels []*v1.Relationship
+		for tpl := iter.Next(); tpl != nil; tpl = iter.Next() {
+			rels = append(rels, tuple.MustToRelationship(tpl))
+		}
+		return rels, iter.Err()
+	}
+
+	graph, err := schemautil.BuildObjectGraph(devContext.Ctx, devContext.CompiledSchema.ObjectDefinitions, root, read, schemautil.ObjectGraphOptions{
+		MaxDepth:   min(depth, maxGraphDepth),
+		MaxEdges:   maxGraphEdges,
+		Permission: req.Permission,
+	})
+	if err != nil {
+		return nil, parameterError("could not build the graph of %s: %s", req.Resource, err), nil
+	}
+	return GraphResult{Graph: graph, Mermaid: graph.Mermaid()}, nil, nil
+}
+
+func parseObject(ref string) (*v1.ObjectReference, error) {
+	objectType, objectID, ok := strings.Cut(ref, ":")
+	if !ok || objectType == "" || objectID == "" {
+		return nil, errors.New("invalid resource `" + ref + "`: expected `type:id`")
+	}
+	return &v1.ObjectReference{ObjectType: objectType, ObjectId: objectID}, nil
+}
@@ -0,0 +1,209 @@
+package playground
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testSchema = `definition user {}
+
+caveat only_on_tuesday(day string) {
+	day == "tuesday"
+}
+
+definition document {
+	relation viewer: user | user with only_on_tuesday
+	relation editor: user
+	permission edit = editor
+	permission view = viewer + edit
+}`
+
+const testRelationships = `document:readme#viewer@user:alice
+// a comment
+document:readme#editor@user:bob
+
+document:readme#viewer@user:carol[only_on_tuesday]`
+
+func call(t *testing.T, path string, req Request) (int, map[string]any) {
+	t.Helper()
+
+	body, err := json.Marshal(req)
+	require.NoError(t, err)
+
+	recorder := httptest.NewRecorder()
+	NewHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, path, bytes.NewReader(body)))
+	if recorder.Code != http.StatusOK {
+		return recorder.Code, nil
+	}
+
+	var resp map[string]any
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &resp))
+	return recorder.Code, resp
+}
+
+func TestIndex(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	NewHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Contains(t, recorder.Body.String(), "SpiceDB Playground")
+
+	recorder = httptest.NewRecorder()
+	NewHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	recorder := httptest.NewRecorder()
+	NewHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/api/check", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, recorder.Code)
+	require.Equal(t, http.MethodPost, recorder.Header().Get("Allow"))
+}
+
+func TestCheck(t *testing.T) {
+	tcs := []struct {
+		name               string
+		subject            string
+		context            map[string]any
+		expectedMembership string
+		expectedMissing    []any
+	}{
+		{"member", "user:alice", nil, "MEMBER", nil},
+		{"member through permission", "user:bob", nil, "MEMBER", nil},
+		{"not member", "user:dave", nil, "NOT_MEMBER", nil},
+		{"caveated member", "user:carol", nil, "CAVEATED_MEMBER", []any{"day"}},
+		{"member with context", "user:carol", map[string]any{"day": "tuesday"}, "MEMBER", nil},
+		{"not member with context", "user:carol", map[string]any{"day": "monday"}, "NOT_MEMBER", nil},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			code, resp := call(t, "/api/check", Request{
+				Schema:        testSchema,
+				Relationships: testRelationships,
+				Resource:      "document:readme",
+				Permission:    "view",
+				Subject:       tc.subject,
+				Context:       tc.context,
+			})
+			require.Equal(t, http.StatusOK, code)
+			require.Nil(t, resp["errors"])
+
+			result := resp["result"].(map[string]any)
+			require.Equal(t, tc.expectedMembership, result["membership"])
+			require.NotNil(t, result["resolvedDebugInformation"])
+			if tc.expectedMissing != nil {
+				require.Equal(t, tc.expectedMissing, result["partialCaveatInfo"].(map[string]any)["missingRequiredContext"])
+			}
+		})
+	}
+}
+
+func TestErrors(t *testing.T) {
+	tcs := []struct {
+		name          string
+		path          string
+		req           Request
+		expectedLine  float64
+		expectedError string
+	}{
+		{
+			"invalid relationship",
+			"/api/check",
+			Request{Schema: testSchema, Relationships: "document:readme#viewer@user:alice\ndocument:readme#viewer"},
+			2,
+			"error parsing relationship `document:readme#viewer`",
+		},
+		{
+			"duplicate relationship",
+			"/api/check",
+			Request{Schema: testSchema, Relationships: "document:readme#viewer@user:alice\n\ndocument:readme#viewer@user:alice"},
+			3,
+			"found repeated relationship `document:readme#viewer@user:alice`",
+		},
+		{
+			"invalid schema",
+			"/api/validate",
+			Request{Schema: "definition user {\n  relation foo: bar\n}"},
+			2,
+			"could not lookup definition `bar` for relation `foo`: object definition `bar` not found",
+		},
+		{
+			"invalid subject",
+			"/api/check",
+			Request{Schema: testSchema, Resource: "document:readme", Permission: "view", Subject: "alice"},
+			0,
+			"invalid subject `alice`: expected `type:id` or `type:id#relation`",
+		},
+		{
+			"unknown permission",
+			"/api/expand",
+			Request{Schema: testSchema, Resource: "document:readme", Permission: "unknown"},
+			0,
+			"could not expand document:readme#unknown",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			code, resp := call(t, tc.path, tc.req)
+			require.Equal(t, http.StatusOK, code)
+			require.Nil(t, resp["result"])
+
+			errs := resp["errors"].([]any)
+			require.Len(t, errs, 1)
+			devErr := errs[0].(map[string]any)
+			require.Contains(t, devErr["message"], tc.expectedError)
+			if tc.expectedLine > 0 {
+				require.Equal(t, tc.expectedLine, devErr["line"])
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	code, resp := call(t, "/api/validate", Request{
+		Schema: "definition user {}\n\ndefinition document {\n  relation viewer: user\n  permission view = viewer\n  permission unused = nil\n}",
+	})
+	require.Equal(t, http.StatusOK, code)
+
+	result := resp["result"].(map[string]any)
+	require.Contains(t, result["formatted_schema"], "definition document {")
+	require.NotEmpty(t, result["warnings"])
+}
+
+func TestExpand(t *testing.T) {
+	code, resp := call(t, "/api/expand", Request{
+		Schema:        testSchema,
+		Relationships: testRelationships,
+		Resource:      "document:readme",
+		Permission:    "view",
+	})
+	require.Equal(t, http.StatusOK, code)
+	require.Nil(t, resp["errors"])
+
+	result := resp["result"].(map[string]any)
+	require.Equal(t, "view", result["expandedRelation"])
+	require.NotNil(t, result["intermediate"])
+}
+
+func TestGraph(t *testing.T) {
+	code, resp := call(t, "/api/graph", Request{
+		Schema:        testSchema,
+		Relationships: testRelationships,
+		Resource:      "document:readme",
+	})
+	require.Equal(t, http.StatusOK, code)
+	require.Nil(t, resp["errors"])
+
+	result := resp["result"].(map[string]any)
+	require.Contains(t, result["mermaid"], "flowchart LR")
+	require.Contains(t, result["mermaid"], "user:alice")
+	require.NotNil(t, result["graph"])
+}
@@ -2,6 +2,7 @@ package relationships
 
 import (
 	"fmt"
+	"strings"
 
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -20,22 +21,41 @@ type ErrInvalidSubjectType struct {
 	error
 	tuple        *core.RelationTuple
 	relationType *core.AllowedRelation
+	allowedTypes []*core.AllowedRelation
 }
 
-// NewInvalidSubjectTypeError constructs a new error for attempting to write an invalid subject type.
-func NewInvalidSubjectTypeError(update *core.RelationTuple, relationType *core.AllowedRelation) ErrInvalidSubjectType {
+// NewInvalidSubjectTypeError constructs a new error for attempting to write an invalid subject type,
+// given the subject types which are allowed on the relation.
+func NewInvalidSubjectTypeError(update *core.RelationTuple, relationType *core.AllowedRelation, allowedTypes []*core.AllowedRelation) ErrInvalidSubjectType {
 	return ErrInvalidSubjectType{
 		error: fmt.Errorf(
-			"subjects of type `%s` are not allowed on relation `%s#%s`",
+			"subjects of type `%s` are not allowed on relation `%s#%s`, which allows %s: cannot write relationship `%s`",
 			typesystem.SourceForAllowedRelation(relationType),
 			update.ResourceAndRelation.Namespace,
 			update.ResourceAndRelation.Relation,
+			allowedTypesDescription(allowedTypes),
+			tuple.MustString(update),
 		),
 		tuple:        update,
 		relationType: relationType,
+		allowedTypes: allowedTypes,
 	}
 }
 
+// allowedTypesDescription describes the subject types allowed on a relation, as they are
+// written in the schema.
+func allowedTypesDescription(allowedTypes []*core.AllowedRelation) string {
+	if len(allowedTypes) == 0 {
+		return "no subject types"
+	}
+
+	sources := make([]string, 0, len(allowedTypes))
+	for _, allowedType := range allowedTypes {
+		sources = append(sources, "`"+typesystem.SourceForAllowedRelation(allowedType)+"`")
+	}
+	return strings.Join(sources, ", ")
+}
+
 // GRPCStatus implements retrieving the gRPC status for the error.
 func (err ErrInvalidSubjectType) GRPCStatus() *status.Status {
 	return spiceerrors.WithCodeAndDetails(
@@ -47,6 +67,7 @@ func (err ErrInvalidSubjectType) GRPCStatus() *status.Status {
 				"definition_name": err.tuple.ResourceAndRelation.Namespace,
 				"relation_name":   err.tuple.ResourceAndRelation.Relation,
 				"subject_type":    typesystem.SourceForAllowedRelation(err.relationType),
+				"relationship":    tuple.MustString(err.tuple),
 			},
 		),
 	)
@@ -62,9 +83,10 @@ type ErrCannotWriteToPermission struct {
 func NewCannotWriteToPermissionError(update *core.RelationTuple) ErrCannotWriteToPermission {
 	return ErrCannotWriteToPermission{
 		error: fmt.Errorf(
-			"cannot write a relationship to permission `%s` under definition `%s`",
+			"cannot write a relationship to permission `%s` under definition `%s`: cannot write relationship `%s`",
 			update.ResourceAndRelation.Relation,
 			update.ResourceAndRelation.Namespace,
+			tuple.MustString(update),
 		),
 		tuple: update,
 	}
@@ -80,6 +102,7 @@ func (err ErrCannotWriteToPermission) GRPCStatus() *status.Status {
 			map[string]string{
 				"definition_name": err.tuple.ResourceAndRelation.Namespace,
 				"permission_name": err.tuple.ResourceAndRelation.Relation,
+				"relationship":    tuple.MustString(err.tuple),
 			},
 		),
 	)
@@ -209,7 +209,7 @@ func ValidateOneRelationship(
 		}
 
 		if isAllowed != typesystem.AllowedRelationValid {
-			return NewInvalidSubjectTypeError(rel, relationToCheck)
+			return invalidSubjectTypeError(resourceTS, rel, relationToCheck)
 		}
 
 	case rule == ValidateRelationshipForDeletion && caveat == nil:
@@ -221,7 +221,7 @@ func ValidateOneRelationship(
 			}
 
 			if isAllowed != typesystem.PublicSubjectAllowed {
-				return NewInvalidSubjectTypeError(rel, relationToCheck)
+				return invalidSubjectTypeError(resourceTS, rel, relationToCheck)
 			}
 		} else {
 			isAllowed, err := resourceTS.IsAllowedDirectRelation(rel.ResourceAndRelation.Relation, rel.Subject.Namespace, rel.Subject.Relation)
@@ -230,7 +230,7 @@ func ValidateOneRelationship(
 			}
 
 			if isAllowed != typesystem.DirectRelationValid {
-				return NewInvalidSubjectTypeError(rel, relationToCheck)
+				return invalidSubjectTypeError(resourceTS, rel, relationToCheck)
 			}
 		}
 
@@ -260,6 +260,16 @@ func ValidateOneRelationship(
 	return nil
 }
 
+// invalidSubjectTypeError returns the error for a relationship whose subject type is not allowed
+// on its relation.
+func invalidSubjectTypeError(resourceTS *typesystem.TypeSystem, rel *core.RelationTuple, relationType *core.AllowedRelation) error {
+	allowedTypes, err := resourceTS.AllowedDirectRelationsAndWildcards(rel.ResourceAndRelation.Relation)
+	if err != nil {
+		return err
+	}
+	return NewInvalidSubjectTypeError(rel, relationType, allowedTypes)
+}
+
 func hasNonEmptyCaveatContext(update *core.RelationTuple) bool {
 	return update.Caveat != nil &&
 		update.Caveat.CaveatName != "" &&
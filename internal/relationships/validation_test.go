@@ -5,6 +5,9 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/authzed/spicedb/internal/datastore/memdb"
 	"github.com/authzed/spicedb/internal/testfixtures"
@@ -236,3 +239,29 @@ func TestValidateRelationshipOperations(t *testing.T) {
 		})
 	}
 }
+
+func TestInvalidSubjectTypeErrorDetails(t *testing.T) {
+	req := require.New(t)
+
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	req.NoError(err)
+
+	uds, rev := testfixtures.DatastoreFromSchemaAndTestRelationships(ds, basicSchema, nil, req)
+	err = ValidateRelationshipsForCreateOrTouch(context.Background(), uds.SnapshotReader(rev), []*core.RelationTuple{
+		tuple.MustParse("resource:foo#viewer2@user:tom"),
+	})
+	req.EqualError(err, "subjects of type `user` are not allowed on relation `resource#viewer2`, which allows `user:* with somecaveat`: cannot write relationship `resource:foo#viewer2@user:tom`")
+
+	st, ok := status.FromError(err)
+	req.True(ok)
+	req.Equal(codes.InvalidArgument, st.Code())
+
+	info, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	req.True(ok)
+	req.Equal(map[string]string{
+		"definition_name": "resource",
+		"relation_name":   "viewer2",
+		"subject_type":    "user",
+		"relationship":    "resource:foo#viewer2@user:tom",
+	}, info.Metadata)
+}
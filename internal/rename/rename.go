@@ -0,0 +1,485 @@
+// Package rename renames an object definition, or a relation or permission of one, in the schema
+// stored in a datastore, and rewrites the relationships referring to it in batched transactions.
+//
+// The schema is renamed first, in a single transaction, after which the relationships are moved
+// batch by batch; until every batch is written, checks do not observe the relationships not yet
+// moved. A rename which is interrupted is resumed by running it again.
+package rename
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/authzed/spicedb/internal/namespace"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/typesystem"
+)
+
+// Config configures a rename.
+type Config struct {
+	// From and To are the current and new names: either of object definitions, or of a
+	// relation or permission of the same object definition, in the form `definition#relation`.
+	From string
+	To   string
+
+	// BatchSize is the maximum number of relationships rewritten per transaction.
+	BatchSize int
+
+	// OnProgress, if set, is called after each batch of relationships is written, with the
+	// total number of relationships rewritten so far.
+	OnProgress func(rewritten int)
+}
+
+// Result is the outcome of a rename.
+type Result struct {
+	// SchemaRenamed is whether the schema was renamed, rather than having been renamed by a
+	// previous run which was interrupted.
+	SchemaRenamed bool
+
+	// Rewritten is the number of relationships rewritten.
+	Rewritten int
+}
+
+// Rename renames the object definition or relation in the schema and rewrites the relationships
+// referring to it.
+func Rename(ctx context.Context, ds datastore.Datastore, config Config) (*Result, error) {
+	if config.BatchSize <= 0 {
+		return nil, errors.New("batch size must be positive")
+	}
+
+	r, err := newRenaming(config.From, config.To)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Result{}
+	if _, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		result.SchemaRenamed, err = r.renameSchema(ctx, rwt)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+
+	for {
+		var rewritten int
+		if _, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+			rewritten, err = r.rewriteBatch(ctx, rwt, config.BatchSize)
+			return err
+		}); err != nil {
+			return nil, fmt.Errorf("failed to rewrite relationships after rewriting %d: %w", result.Rewritten, err)
+		}
+
+		if rewritten == 0 {
+			break
+		}
+
+		result.Rewritten += rewritten
+		if config.OnProgress != nil {
+			config.OnProgress(result.Rewritten)
+		}
+	}
+
+	// The renamed definition is kept until its relationships have been moved, as deleting a
+	// definition deletes its relationships.
+	if r.relation == "" {
+		if _, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+			if _, _, err := rwt.ReadNamespaceByName(ctx, r.from); err != nil {
+				if errors.As(err, &datastore.ErrNamespaceNotFound{}) {
+					return nil
+				}
+				return err
+			}
+			return rwt.DeleteNamespaces(ctx, r.from)
+		}); err != nil {
+			return nil, fmt.Errorf("failed to delete definition `%s`: %w", r.from, err)
+		}
+	}
+
+	return result, nil
+}
+
+// renaming is a rename of an object definition, or, if relation is set, of a relation or
+// permission of the definition from to the name to.
+type renaming struct {
+	from     string
+	to       string
+	relation string
+}
+
+func newRenaming(from, to string) (*renaming, error) {
+	fromDef, fromRelation, fromHasRelation := strings.Cut(from, "#")
+	toDef, toRelation, toHasRelation := strings.Cut(to, "#")
+	switch {
+	case fromDef == "" || toDef == "" || (fromHasRelation && fromRelation == "") || (toHasRelation && toRelation == ""):
+		return nil, fmt.Errorf("invalid rename of `%s` to `%s`: names must be of the form `definition` or `definition#relation`", from, to)
+
+	case fromHasRelation != toHasRelation:
+		return nil, fmt.Errorf("invalid rename of `%s` to `%s`: a definition can only be renamed to a definition, and a relation to a relation", from, to)
+
+	case fromHasRelation && fromDef != toDef:
+		return nil, fmt.Errorf("invalid rename of `%s` to `%s`: a relation can only be renamed within its definition", from, to)
+
+	case from == to:
+		return nil, fmt.Errorf("invalid rename of `%s` to itself", from)
+
+	case fromHasRelation:
+		return &renaming{from: fromRelation, to: toRelation, relation: fromDef}, nil
+
+	default:
+		return &renaming{from: fromDef, to: toDef}, nil
+	}
+}
+
+func (r *renaming) String() string {
+	if r.relation != "" {
+		return fmt.Sprintf("`%s` to `%s`", tuple.JoinRelRef(r.relation, r.from), tuple.JoinRelRef(r.relation, r.to))
+	}
+	return fmt.Sprintf("`%s` to `%s`", r.from, r.to)
+}
+
+// renameSchema renames the definition or relation in the schema, and every reference to it,
+// returning false if it was already renamed.
+func (r *renaming) renameSchema(ctx context.Context, rwt datastore.ReadWriteTransaction) (bool, error) {
+	revisioned, err := rwt.ListAllNamespaces(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	defs := make(map[string]*core.NamespaceDefinition, len(revisioned))
+	for _, def := range revisioned {
+		defs[def.Definition.Name] = def.Definition.CloneVT()
+	}
+
+	var changed map[string]*core.NamespaceDefinition
+	if r.relation == "" {
+		changed, err = r.renameDefinition(defs)
+	} else {
+		changed, err = r.renameRelation(defs)
+	}
+	if err != nil || len(changed) == 0 {
+		return false, err
+	}
+
+	caveats, err := rwt.ListAllCaveats(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	predefined := typesystem.PredefinedElements{}
+	for _, def := range defs {
+		predefined.Namespaces = append(predefined.Namespaces, def)
+	}
+	for _, caveat := range caveats {
+		predefined.Caveats = append(predefined.Caveats, caveat.Definition)
+	}
+
+	updated := make([]*core.NamespaceDefinition, 0, len(changed))
+	for _, def := range changed {
+		ts, err := typesystem.NewNamespaceTypeSystem(def, typesystem.ResolverForPredefinedDefinitions(predefined))
+		if err != nil {
+			return false, err
+		}
+
+		vts, err := ts.Validate(ctx)
+		if err != nil {
+			return false, fmt.Errorf("renamed schema is invalid: %w", err)
+		}
+
+		if err := namespace.AnnotateNamespace(vts); err != nil {
+			return false, err
+		}
+		updated = append(updated, def)
+	}
+
+	return true, rwt.WriteNamespaces(ctx, updated...)
+}
+
+// renameDefinition adds a copy of the definition under its new name to the definitions and
+// replaces the references to it, returning those changed. The definition itself is left to be
+// deleted once its relationships have been moved.
+func (r *renaming) renameDefinition(defs map[string]*core.NamespaceDefinition) (map[string]*core.NamespaceDefinition, error) {
+	from, hasFrom := defs[r.from]
+	_, hasTo := defs[r.to]
+	switch {
+	case !hasFrom && hasTo:
+		// Renamed by a previous run, which deleted the definition.
+		return nil, nil
+
+	case !hasFrom:
+		return nil, fmt.Errorf("object definition `%s` not found", r.from)
+
+	case hasTo:
+		// Renamed by a previous run, if the definition under the new name is its copy.
+		if !r.isRenamedCopy(from, defs[r.to]) {
+			return nil, fmt.Errorf("cannot rename %s: object definition `%s` already exists", r, r.to)
+		}
+		return nil, nil
+	}
+
+	// The definitions were cloned when read, so the definition is renamed in place.
+	from.Name = r.to
+	delete(defs, r.from)
+	defs[r.to] = from
+
+	changed := map[string]*core.NamespaceDefinition{r.to: from}
+	for _, def := range defs {
+		for _, rel := range def.Relation {
+			for _, allowed := range rel.GetTypeInformation().GetAllowedDirectRelations() {
+				if allowed.Namespace == r.from {
+					allowed.Namespace = r.to
+					changed[def.Name] = def
+				}
+			}
+		}
+	}
+	return changed, nil
+}
+
+// renameRelation renames the relation within its definition and replaces the references to it,
+// returning the definitions changed.
+func (r *renaming) renameRelation(defs map[string]*core.NamespaceDefinition) (map[string]*core.NamespaceDefinition, error) {
+	def, ok := defs[r.relation]
+	if !ok {
+		return nil, fmt.Errorf("object definition `%s` not found", r.relation)
+	}
+
+	from, to := findRelation(def, r.from), findRelation(def, r.to)
+	switch {
+	case from == nil && to != nil:
+		// Renamed by a previous run.
+		return nil, nil
+
+	case from == nil:
+		return nil, fmt.Errorf("relation or permission `%s` not found under definition `%s`", r.from, r.relation)
+
+	case to != nil:
+		return nil, fmt.Errorf("cannot rename %s: relation or permission `%s` already exists", r, r.to)
+	}
+
+	from.Name = r.to
+	changed := map[string]*core.NamespaceDefinition{def.Name: def}
+	for _, other := range defs {
+		for _, rel := range other.Relation {
+			for _, allowed := range rel.GetTypeInformation().GetAllowedDirectRelations() {
+				if allowed.Namespace == r.relation && allowed.GetRelation() == r.from {
+					allowed.RelationOrWildcard = &core.AllowedRelation_Relation{Relation: r.to}
+					changed[other.Name] = other
+				}
+			}
+
+			var err error
+			walkChildren(rel.UsersetRewrite, func(child *core.SetOperation_Child) {
+				switch child := child.ChildType.(type) {
+				case *core.SetOperation_Child_ComputedUserset:
+					if other == def && child.ComputedUserset.Relation == r.from {
+						child.ComputedUserset.Relation = r.to
+					}
+
+				case *core.SetOperation_Child_TupleToUserset:
+					if other == def && child.TupleToUserset.Tupleset.Relation == r.from {
+						child.TupleToUserset.Tupleset.Relation = r.to
+					}
+
+					if child.TupleToUserset.ComputedUserset.Relation == r.from {
+						renamed, aerr := r.renameArrow(defs, other, child.TupleToUserset)
+						if aerr != nil {
+							err = aerr
+						}
+						if renamed {
+							changed[other.Name] = other
+						}
+					}
+				}
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return changed, nil
+}
+
+// renameArrow renames the relation computed by the arrow if its tupleset allows subjects of
+// the relation's definition, returning whether it was renamed. Arrows which would also reach
+// a relation of the same name under other definitions cannot be renamed.
+func (r *renaming) renameArrow(defs map[string]*core.NamespaceDefinition, def *core.NamespaceDefinition, arrow *core.TupleToUserset) (bool, error) {
+	tupleset := findRelation(def, arrow.Tupleset.Relation)
+	if tupleset == nil {
+		return false, nil
+	}
+
+	reachesRenamed := false
+	for _, allowed := range tupleset.GetTypeInformation().GetAllowedDirectRelations() {
+		if allowed.Namespace == r.relation {
+			reachesRenamed = true
+			continue
+		}
+
+		if other, ok := defs[allowed.Namespace]; ok && findRelation(other, r.from) != nil {
+			return false, fmt.Errorf("cannot rename %s: the arrow `%s->%s` under definition `%s` also reaches `%s`",
+				r, arrow.Tupleset.Relation, r.from, def.Name, tuple.JoinRelRef(allowed.Namespace, r.from))
+		}
+	}
+
+	if reachesRenamed {
+		arrow.ComputedUserset.Relation = r.to
+	}
+	return reachesRenamed, nil
+}
+
+// rewriteBatch rewrites up to the given number of relationships referring to the renamed
+// definition or relation, returning how many were rewritten.
+func (r *renaming) rewriteBatch(ctx context.Context, rwt datastore.ReadWriteTransaction, batchSize int) (int, error) {
+	limit := uint64(batchSize)
+	resourceFilter := datastore.RelationshipsFilter{ResourceType: r.from}
+	subjectsFilter := datastore.SubjectsFilter{SubjectType: r.from}
+	if r.relation != "" {
+		resourceFilter = datastore.RelationshipsFilter{ResourceType: r.relation, OptionalResourceRelation: r.from}
+		subjectsFilter = datastore.SubjectsFilter{
+			SubjectType:    r.relation,
+			RelationFilter: datastore.SubjectRelationFilter{NonEllipsisRelation: r.from},
+		}
+	}
+
+	it, err := rwt.QueryRelationships(ctx, resourceFilter, options.WithLimit(&limit))
+	if err != nil {
+		return 0, err
+	}
+	rels, err := collect(it)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(rels) < batchSize {
+		remaining := limit - uint64(len(rels))
+		it, err := rwt.ReverseQueryRelationships(ctx, subjectsFilter, options.WithLimitForReverse(&remaining))
+		if err != nil {
+			return 0, err
+		}
+		subjectRels, err := collect(it)
+		if err != nil {
+			return 0, err
+		}
+		rels = append(rels, subjectRels...)
+	}
+
+	// Relationships whose resource and subject are both of a renamed definition are found by
+	// both queries, but are rewritten once.
+	seen := make(map[string]struct{}, len(rels))
+	updates := make([]*core.RelationTupleUpdate, 0, 2*len(rels))
+	for _, rel := range rels {
+		key := tuple.StringWithoutCaveat(rel)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		updates = append(updates, tuple.Delete(rel), tuple.Touch(r.rewrite(rel)))
+	}
+
+	if len(updates) == 0 {
+		return 0, nil
+	}
+	return len(seen), rwt.WriteRelationships(ctx, updates)
+}
+
+// rewrite returns the relationship with the renamed definition or relation replaced.
+func (r *renaming) rewrite(rel *core.RelationTuple) *core.RelationTuple {
+	rewritten := rel.CloneVT()
+	resource, subject := rewritten.ResourceAndRelation, rewritten.Subject
+	if r.relation == "" {
+		if resource.Namespace == r.from {
+			resource.Namespace = r.to
+		}
+		if subject.Namespace == r.from {
+			subject.Namespace = r.to
+		}
+		return rewritten
+	}
+
+	if resource.Namespace == r.relation && resource.Relation == r.from {
+		resource.Relation = r.to
+	}
+	if subject.Namespace == r.relation && subject.Relation == r.from {
+		subject.Relation = r.to
+	}
+	return rewritten
+}
+
+func collect(it datastore.RelationshipIterator) ([]*core.RelationTuple, error) {
+	defer it.Close()
+
+	var rels []*core.RelationTuple
+	for rel := it.Next(); rel != nil; rel = it.Next() {
+		rels = append(rels, rel)
+	}
+	return rels, it.Err()
+}
+
+// isRenamedCopy returns whether the definition is a copy of that being renamed, as written by a
+// previous run.
+func (r *renaming) isRenamedCopy(from, to *core.NamespaceDefinition) bool {
+	if len(from.Relation) != len(to.Relation) {
+		return false
+	}
+
+	for i, rel := range from.Relation {
+		copied := to.Relation[i]
+		if rel.Name != copied.Name || !proto.Equal(rel.UsersetRewrite, copied.UsersetRewrite) {
+			return false
+		}
+
+		fromAllowed := rel.GetTypeInformation().GetAllowedDirectRelations()
+		toAllowed := copied.GetTypeInformation().GetAllowedDirectRelations()
+		if len(fromAllowed) != len(toAllowed) {
+			return false
+		}
+
+		for j, allowed := range fromAllowed {
+			expected := allowed.CloneVT()
+			if expected.Namespace == r.from {
+				expected.Namespace = r.to
+			}
+			if !proto.Equal(expected, toAllowed[j]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func findRelation(def *core.NamespaceDefinition, name string) *core.Relation {
+	for _, rel := range def.Relation {
+		if rel.Name == name {
+			return rel
+		}
+	}
+	return nil
+}
+
+// walkChildren calls the function with every child of the rewrite, including those of nested
+// rewrites.
+func walkChildren(rewrite *core.UsersetRewrite, fn func(child *core.SetOperation_Child)) {
+	var children []*core.SetOperation_Child
+	switch op := rewrite.GetRewriteOperation().(type) {
+	case *core.UsersetRewrite_Union:
+		children = op.Union.Child
+	case *core.UsersetRewrite_Intersection:
+		children = op.Intersection.Child
+	case *core.UsersetRewrite_Exclusion:
+		children = op.Exclusion.Child
+	}
+
+	for _, child := range children {
+		fn(child)
+		if nested, ok := child.ChildType.(*core.SetOperation_Child_UsersetRewrite); ok {
+			walkChildren(nested.UsersetRewrite, fn)
+		}
+	}
+}
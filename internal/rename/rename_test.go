@@ -0,0 +1,231 @@
+package rename
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/generator"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const schema = `
+definition user {}
+
+definition team {
+	relation member: user | team#member
+}
+
+definition folder {
+	relation reader: user | team#member
+	permission read = reader
+}
+
+definition document {
+	relation parent: folder
+	relation reader: user | team#member
+	permission view = reader + parent->read
+}`
+
+func newDatastore(t *testing.T) datastore.Datastore {
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+	t.Cleanup(func() { rawDS.Close() })
+
+	ds, _ := testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, schema, []*core.RelationTuple{
+		tuple.MustParse("team:eng#member@user:alice"),
+		tuple.MustParse("team:eng#member@team:backend#member"),
+		tuple.MustParse("team:backend#member@user:bob"),
+		tuple.MustParse("folder:shared#reader@team:eng#member"),
+		tuple.MustParse("document:plan#parent@folder:shared"),
+		tuple.MustParse("document:plan#reader@team:backend#member"),
+		tuple.MustParse("document:plan#reader@user:carol"),
+	}, require.New(t))
+	return ds
+}
+
+func readState(t *testing.T, ds datastore.Datastore) (string, []string) {
+	ctx := context.Background()
+	headRevision, err := ds.HeadRevision(ctx)
+	require.NoError(t, err)
+	reader := ds.SnapshotReader(headRevision)
+
+	namespaces, err := reader.ListAllNamespaces(ctx)
+	require.NoError(t, err)
+
+	var rels []string
+	defs := make([]compiler.SchemaDefinition, 0, len(namespaces))
+	for _, ns := range namespaces {
+		defs = append(defs, ns.Definition)
+
+		it, err := reader.QueryRelationships(ctx, datastore.RelationshipsFilter{ResourceType: ns.Definition.Name})
+		require.NoError(t, err)
+		for rel := it.Next(); rel != nil; rel = it.Next() {
+			rels = append(rels, tuple.MustString(rel))
+		}
+		require.NoError(t, it.Err())
+		it.Close()
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].GetName() < defs[j].GetName() })
+	sort.Strings(rels)
+
+	generated, _, err := generator.GenerateSchema(defs)
+	require.NoError(t, err)
+	return generated, rels
+}
+
+func TestRenameDefinition(t *testing.T) {
+	ds := newDatastore(t)
+
+	var progress []int
+	result, err := Rename(context.Background(), ds, Config{
+		From:       "team",
+		To:         "group",
+		BatchSize:  2,
+		OnProgress: func(rewritten int) { progress = append(progress, rewritten) },
+	})
+	require.NoError(t, err)
+	require.True(t, result.SchemaRenamed)
+	require.Equal(t, 5, result.Rewritten)
+	require.Equal(t, []int{2, 4, 5}, progress)
+
+	generated, rels := readState(t, ds)
+	require.NotContains(t, generated, "team")
+	require.Contains(t, generated, "definition group {\n\trelation member: user | group#member\n}")
+	require.Contains(t, generated, "relation reader: user | group#member")
+	require.Equal(t, []string{
+		"document:plan#parent@folder:shared",
+		"document:plan#reader@group:backend#member",
+		"document:plan#reader@user:carol",
+		"folder:shared#reader@group:eng#member",
+		"group:backend#member@user:bob",
+		"group:eng#member@group:backend#member",
+		"group:eng#member@user:alice",
+	}, rels)
+}
+
+func TestRenameRelation(t *testing.T) {
+	ds := newDatastore(t)
+
+	result, err := Rename(context.Background(), ds, Config{From: "folder#reader", To: "folder#viewer", BatchSize: 10})
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Rewritten)
+
+	result, err = Rename(context.Background(), ds, Config{From: "folder#read", To: "folder#can_read", BatchSize: 10})
+	require.NoError(t, err)
+	require.Equal(t, 0, result.Rewritten)
+
+	result, err = Rename(context.Background(), ds, Config{From: "team#member", To: "team#participant", BatchSize: 10})
+	require.NoError(t, err)
+	require.Equal(t, 5, result.Rewritten)
+
+	generated, rels := readState(t, ds)
+	require.Contains(t, generated, "relation viewer: user | team#participant\n\tpermission can_read = viewer\n")
+	require.Contains(t, generated, "permission view = reader + parent->can_read")
+	require.Contains(t, generated, "relation participant: user | team#participant")
+	require.Equal(t, []string{
+		"document:plan#parent@folder:shared",
+		"document:plan#reader@team:backend#participant",
+		"document:plan#reader@user:carol",
+		"folder:shared#viewer@team:eng#participant",
+		"team:backend#participant@user:bob",
+		"team:eng#participant@team:backend#participant",
+		"team:eng#participant@user:alice",
+	}, rels)
+}
+
+func TestRenameAmbiguousArrow(t *testing.T) {
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+	t.Cleanup(func() { rawDS.Close() })
+
+	ds, _ := testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, `
+		definition user {}
+
+		definition folder {
+			relation reader: user
+			permission read = reader
+		}
+
+		definition drive {
+			relation reader: user
+			permission read = reader
+		}
+
+		definition document {
+			relation parent: folder | drive
+			permission view = parent->read
+		}`, nil, require.New(t))
+
+	// Renaming the permission under one definition would break the arrow for the other.
+	_, err = Rename(context.Background(), ds, Config{From: "folder#read", To: "folder#can_read", BatchSize: 10})
+	require.ErrorContains(t, err, "the arrow `parent->read` under definition `document` also reaches `drive#read`")
+}
+
+func TestRenameResumes(t *testing.T) {
+	ds := newDatastore(t)
+	ctx := context.Background()
+
+	// Simulate a rename interrupted after renaming the schema and rewriting a single batch.
+	r, err := newRenaming("team", "group")
+	require.NoError(t, err)
+	_, err = ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		renamed, err := r.renameSchema(ctx, rwt)
+		require.True(t, renamed)
+		return err
+	})
+	require.NoError(t, err)
+	_, err = ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		_, err := r.rewriteBatch(ctx, rwt, 2)
+		return err
+	})
+	require.NoError(t, err)
+
+	result, err := Rename(ctx, ds, Config{From: "team", To: "group", BatchSize: 10})
+	require.NoError(t, err)
+	require.False(t, result.SchemaRenamed)
+	require.Equal(t, 3, result.Rewritten)
+
+	generated, rels := readState(t, ds)
+	require.NotContains(t, generated, "team")
+	require.Len(t, rels, 7)
+
+	// Running it again once complete does nothing.
+	result, err = Rename(ctx, ds, Config{From: "team", To: "group", BatchSize: 10})
+	require.NoError(t, err)
+	require.False(t, result.SchemaRenamed)
+	require.Equal(t, 0, result.Rewritten)
+}
+
+func TestRenameErrors(t *testing.T) {
+	tcs := []struct {
+		from          string
+		to            string
+		expectedError string
+	}{
+		{"team", "team#member", "a definition can only be renamed to a definition"},
+		{"team#member", "folder#member", "a relation can only be renamed within its definition"},
+		{"team#", "team#member", "names must be of the form"},
+		{"team", "team", "to itself"},
+		{"missing", "other", "object definition `missing` not found"},
+		{"team", "folder", "object definition `folder` already exists"},
+		{"folder#missing", "folder#other", "relation or permission `missing` not found"},
+		{"folder#reader", "folder#read", "relation or permission `read` already exists"},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.from+" to "+tc.to, func(t *testing.T) {
+			ds := newDatastore(t)
+			_, err := Rename(context.Background(), ds, Config{From: tc.from, To: tc.to, BatchSize: 10})
+			require.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
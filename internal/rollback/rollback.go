@@ -0,0 +1,147 @@
+// Package rollback restores the relationships matching a filter to their state at a prior
+// revision by writing the difference as new relationships, undoing changes such as an
+// accidental mass deletion while keeping the history of the datastore intact.
+package rollback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/authzed/spicedb/internal/middleware/consistency"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// Config configures a rollback.
+type Config struct {
+	// Filter selects the relationships which are restored. Relationships not matching the
+	// filter are left alone.
+	Filter *v1.RelationshipFilter
+
+	// Revision is the ZedToken of the revision to which relationships are restored. Exactly
+	// one of Revision and At must be set.
+	Revision *v1.ZedToken
+
+	// At is the point in time to which relationships are restored.
+	At time.Time
+
+	// BatchSize is the maximum number of updates written per request. Each request is
+	// applied atomically, so a rollback fitting in a single batch is atomic.
+	BatchSize int
+
+	// DryRun computes the changes without writing them.
+	DryRun bool
+}
+
+// Result is the outcome of a rollback.
+type Result struct {
+	// Added are the relationships which existed at the revision and were written, including
+	// those whose caveat has changed since. Removed are the relationships created since the
+	// revision and deleted. In a dry run, they are the changes which would have been made.
+	Added   []*v1.Relationship
+	Removed []*v1.Relationship
+}
+
+// Rollback makes the relationships matching the filter match those which matched it at the
+// revision or point in time of the config. The revision must fall within the GC window of
+// the datastore.
+func Rollback(ctx context.Context, client v1.PermissionsServiceClient, config Config) (*Result, error) {
+	if config.Filter == nil || config.Filter.ResourceType == "" {
+		return nil, errors.New("a filter with a resource type is required")
+	}
+	if (config.Revision == nil) == config.At.IsZero() {
+		return nil, errors.New("exactly one of a revision and a point in time is required")
+	}
+	if config.BatchSize <= 0 {
+		return nil, errors.New("batch size must be positive")
+	}
+
+	pastCtx := ctx
+	pastConsistency := &v1.Consistency{Requirement: &v1.Consistency_MinimizeLatency{MinimizeLatency: true}}
+	if config.Revision != nil {
+		pastConsistency = &v1.Consistency{Requirement: &v1.Consistency_AtExactSnapshot{AtExactSnapshot: config.Revision}}
+	} else {
+		pastCtx = metadata.AppendToOutgoingContext(ctx, consistency.AtTimeHeaderKey, config.At.Format(time.RFC3339Nano))
+	}
+
+	past, err := readRelationships(pastCtx, client, config.Filter, pastConsistency)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read past relationships: %w", err)
+	}
+
+	current, err := readRelationships(ctx, client, config.Filter, &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read current relationships: %w", err)
+	}
+
+	result := &Result{}
+	for key, rel := range past {
+		existing, ok := current[key]
+		if !ok || !proto.Equal(existing.OptionalCaveat, rel.OptionalCaveat) {
+			result.Added = append(result.Added, rel)
+		}
+	}
+	for key, rel := range current {
+		if _, ok := past[key]; !ok {
+			result.Removed = append(result.Removed, rel)
+		}
+	}
+	sortRelationships(result.Added)
+	sortRelationships(result.Removed)
+
+	if config.DryRun {
+		return result, nil
+	}
+
+	var updates []*v1.RelationshipUpdate
+	for _, rel := range result.Added {
+		updates = append(updates, &v1.RelationshipUpdate{Operation: v1.RelationshipUpdate_OPERATION_TOUCH, Relationship: rel})
+	}
+	for _, rel := range result.Removed {
+		updates = append(updates, &v1.RelationshipUpdate{Operation: v1.RelationshipUpdate_OPERATION_DELETE, Relationship: rel})
+	}
+	for start := 0; start < len(updates); start += config.BatchSize {
+		batch := updates[start:min(start+config.BatchSize, len(updates))]
+		if _, err := client.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{Updates: batch}); err != nil {
+			return nil, fmt.Errorf("failed to write relationships: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// readRelationships returns the relationships matching the filter, keyed by their string
+// form without caveat, so that a relationship whose caveat has changed is found by its key.
+func readRelationships(ctx context.Context, client v1.PermissionsServiceClient, filter *v1.RelationshipFilter, consistency *v1.Consistency) (map[string]*v1.Relationship, error) {
+	stream, err := client.ReadRelationships(ctx, &v1.ReadRelationshipsRequest{
+		Consistency:        consistency,
+		RelationshipFilter: filter,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rels := map[string]*v1.Relationship{}
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return rels, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		rels[tuple.StringRelationshipWithoutCaveat(resp.Relationship)] = resp.Relationship
+	}
+}
+
+func sortRelationships(rels []*v1.Relationship) {
+	sort.Slice(rels, func(i, j int) bool {
+		return tuple.MustStringRelationship(rels[i]) < tuple.MustStringRelationship(rels[j])
+	})
+}
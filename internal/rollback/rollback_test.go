@@ -0,0 +1,140 @@
+package rollback
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const schema = `
+definition user {}
+definition folder {
+	relation viewer: user
+}
+definition document {
+	relation viewer: user
+}`
+
+func withRelationships(ds datastore.Datastore, require *require.Assertions) (datastore.Datastore, datastore.Revision) {
+	return testfixtures.DatastoreFromSchemaAndTestRelationships(ds, schema, []*core.RelationTuple{
+		tuple.MustParse("document:first#viewer@user:anne"),
+		tuple.MustParse("document:second#viewer@user:anne"),
+		tuple.MustParse("folder:root#viewer@user:anne"),
+	}, require)
+}
+
+func TestRollback(t *testing.T) {
+	require := require.New(t)
+	conn, cleanup, _, _ := testserver.NewTestServer(require, 0, memdb.DisableGC, false, withRelationships)
+	t.Cleanup(cleanup)
+	client := v1.NewPermissionsServiceClient(conn)
+	ctx := context.Background()
+
+	written, err := client.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{Updates: []*v1.RelationshipUpdate{
+		tuple.UpdateToRelationshipUpdate(tuple.Touch(tuple.MustParse("document:third#viewer@user:anne"))),
+	}})
+	require.NoError(err)
+	beforeDeletion := time.Now()
+
+	// An accidental mass deletion, followed by a write which is undone too.
+	_, err = client.DeleteRelationships(ctx, &v1.DeleteRelationshipsRequest{RelationshipFilter: &v1.RelationshipFilter{ResourceType: "document"}})
+	require.NoError(err)
+	_, err = client.DeleteRelationships(ctx, &v1.DeleteRelationshipsRequest{RelationshipFilter: &v1.RelationshipFilter{ResourceType: "folder"}})
+	require.NoError(err)
+	_, err = client.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{Updates: []*v1.RelationshipUpdate{
+		tuple.UpdateToRelationshipUpdate(tuple.Touch(tuple.MustParse("document:fourth#viewer@user:beth"))),
+	}})
+	require.NoError(err)
+
+	config := Config{
+		Filter:    &v1.RelationshipFilter{ResourceType: "document"},
+		Revision:  written.WrittenAt,
+		BatchSize: 1,
+		DryRun:    true,
+	}
+	result, err := Rollback(ctx, client, config)
+	require.NoError(err)
+	require.Equal([]string{
+		"document:first#viewer@user:anne",
+		"document:second#viewer@user:anne",
+		"document:third#viewer@user:anne",
+	}, relationshipStrings(result.Added))
+	require.Equal([]string{"document:fourth#viewer@user:beth"}, relationshipStrings(result.Removed))
+
+	// A dry run writes nothing.
+	require.Equal([]string{"document:fourth#viewer@user:beth"}, readAll(t, client, "document"))
+
+	// Restoring to a point in time is equivalent to restoring to its revision.
+	config.Revision = nil
+	config.At = beforeDeletion
+	config.DryRun = false
+	result, err = Rollback(ctx, client, config)
+	require.NoError(err)
+	require.Len(result.Added, 3)
+	require.Len(result.Removed, 1)
+
+	require.Equal([]string{
+		"document:first#viewer@user:anne",
+		"document:second#viewer@user:anne",
+		"document:third#viewer@user:anne",
+	}, readAll(t, client, "document"))
+
+	// Relationships outside of the filter are left alone.
+	require.Empty(readAll(t, client, "folder"))
+
+	result, err = Rollback(ctx, client, config)
+	require.NoError(err)
+	require.Empty(result.Added)
+	require.Empty(result.Removed)
+}
+
+func TestRollbackInvalidConfig(t *testing.T) {
+	testCases := []struct {
+		name          string
+		config        Config
+		expectedError string
+	}{
+		{"no filter", Config{At: time.Now(), BatchSize: 1}, "a filter with a resource type is required"},
+		{"no revision", Config{Filter: &v1.RelationshipFilter{ResourceType: "document"}, BatchSize: 1}, "exactly one of a revision and a point in time is required"},
+		{"revision and time", Config{Filter: &v1.RelationshipFilter{ResourceType: "document"}, Revision: &v1.ZedToken{Token: "token"}, At: time.Now(), BatchSize: 1}, "exactly one of a revision and a point in time is required"},
+		{"no batch size", Config{Filter: &v1.RelationshipFilter{ResourceType: "document"}, At: time.Now()}, "batch size must be positive"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Rollback(context.Background(), nil, tc.config)
+			require.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func relationshipStrings(rels []*v1.Relationship) []string {
+	strs := make([]string, 0, len(rels))
+	for _, rel := range rels {
+		strs = append(strs, tuple.MustStringRelationship(rel))
+	}
+	return strs
+}
+
+func readAll(t *testing.T, client v1.PermissionsServiceClient, resourceType string) []string {
+	rels, err := readRelationships(context.Background(), client, &v1.RelationshipFilter{ResourceType: resourceType}, &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}})
+	require.NoError(t, err)
+
+	sorted := make([]*v1.Relationship, 0, len(rels))
+	for _, rel := range rels {
+		sorted = append(sorted, rel)
+	}
+	sortRelationships(sorted)
+	return relationshipStrings(sorted)
+}
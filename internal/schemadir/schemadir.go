@@ -0,0 +1,237 @@
+// Package schemadir keeps the schema of a datastore in sync with the schema files of a
+// directory, such as one checked out from Git and mounted into the server, so that the schema
+// can be managed by a deployment pipeline rather than by calls to WriteSchema.
+//
+// The schema is the union of the definitions of the `.zed` files directly within the
+// directory. It is applied when syncing starts and whenever the files change, with the same
+// validation as WriteSchema: a schema which fails to compile or validate, or which removes
+// definitions or relations which still have relationships, is logged and not applied, leaving
+// the previous schema in place. Relationships are never deleted by a sync.
+package schemadir
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/middleware/audit"
+	"github.com/authzed/spicedb/internal/services/shared"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+)
+
+// AuditActor is the actor recorded in the audit records of the changes made by syncs.
+const AuditActor = "schema-directory"
+
+// fileExtension is the extension of the schema files within the directory.
+const fileExtension = ".zed"
+
+var syncsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "schema_directory",
+	Name:      "syncs_total",
+	Help:      "total number of syncs of the schema from the schema directory, by result",
+}, []string{"result"})
+
+// Syncer applies the schema files of a directory to a datastore.
+type Syncer struct {
+	dir          string
+	ds           datastore.Datastore
+	additiveOnly bool
+
+	lastContents []byte
+}
+
+// NewSyncer returns a syncer of the schema files of the directory to the datastore. Changes
+// are recorded to the audit sink, if any. If additiveOnly is set, definitions missing from the
+// files are kept rather than removed, as for WriteSchema on an additive-only server.
+func NewSyncer(dir string, ds datastore.Datastore, auditSink audit.Sink, additiveOnly bool) (*Syncer, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("schema directory `%s` is not a directory", dir)
+	}
+
+	if auditSink != nil {
+		ds = audit.NewDatastore(ds, auditSink, AuditActor, "schemadir:"+dir)
+	}
+	return &Syncer{dir: dir, ds: ds, additiveOnly: additiveOnly}, nil
+}
+
+// Sync applies the schema files of the directory to the datastore, unless they are unchanged
+// since the last sync.
+func (s *Syncer) Sync(ctx context.Context) error {
+	files, contents, err := s.read()
+	if err != nil {
+		return err
+	}
+	if s.lastContents != nil && bytes.Equal(contents, s.lastContents) {
+		return nil
+	}
+
+	if err := s.apply(ctx, files); err != nil {
+		syncsCounter.WithLabelValues("failed").Inc()
+		return err
+	}
+	syncsCounter.WithLabelValues("applied").Inc()
+	s.lastContents = contents
+	return nil
+}
+
+// read returns the contents of each schema file by name, along with their concatenation by
+// which changes are detected.
+func (s *Syncer) read() (map[string]string, []byte, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read schema directory: %w", err)
+	}
+
+	files := map[string]string{}
+	var contents bytes.Buffer
+	for _, entry := range entries {
+		// Hidden entries, such as the `..data` directory of a mounted Kubernetes ConfigMap, are
+		// skipped; the files within it are linked from the directory itself.
+		name := entry.Name()
+		if strings.HasPrefix(name, ".") || filepath.Ext(name) != fileExtension {
+			continue
+		}
+
+		path := filepath.Join(s.dir, name)
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read schema file `%s`: %w", name, err)
+		}
+		if info.IsDir() {
+			continue
+		}
+
+		file, err := os.ReadFile(path)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read schema file `%s`: %w", name, err)
+		}
+		files[name] = string(file)
+		fmt.Fprintf(&contents, "%s\x00%s\x00", name, file)
+	}
+	return files, contents.Bytes(), nil
+}
+
+// apply compiles and validates the schema files, and applies their definitions in a single
+// transaction.
+func (s *Syncer) apply(ctx context.Context, files map[string]string) error {
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// Each file is compiled on its own, so that errors refer to their position within it, and
+	// the definitions are validated together, so that they can refer to those of other files.
+	compiled := &compiler.CompiledSchema{}
+	definedIn := map[string]string{}
+	for _, name := range names {
+		fileCompiled, err := compiler.Compile(compiler.InputSchema{
+			Source:       input.Source(name),
+			SchemaString: files[name],
+		}, compiler.AllowUnprefixedObjectType())
+		if err != nil {
+			return fmt.Errorf("failed to compile schema file `%s`: %w", name, err)
+		}
+
+		for _, def := range fileCompiled.OrderedDefinitions {
+			if other, ok := definedIn[def.GetName()]; ok {
+				return fmt.Errorf("definition `%s` is defined in both `%s` and `%s`", def.GetName(), other, name)
+			}
+			definedIn[def.GetName()] = name
+		}
+		compiled.ObjectDefinitions = append(compiled.ObjectDefinitions, fileCompiled.ObjectDefinitions...)
+		compiled.CaveatDefinitions = append(compiled.CaveatDefinitions, fileCompiled.CaveatDefinitions...)
+		compiled.OrderedDefinitions = append(compiled.OrderedDefinitions, fileCompiled.OrderedDefinitions...)
+	}
+
+	// A directory without definitions is more likely to be mounted or checked out incorrectly
+	// than to be meant to remove the whole schema.
+	if len(compiled.ObjectDefinitions) == 0 {
+		return fmt.Errorf("schema directory `%s` defines no object definitions", s.dir)
+	}
+
+	validated, err := shared.ValidateSchemaChanges(ctx, compiled, s.additiveOnly)
+	if err != nil {
+		return fmt.Errorf("invalid schema: %w", err)
+	}
+
+	var applied *shared.AppliedSchemaChanges
+	revision, err := s.ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		applied, err = shared.ApplySchemaChanges(ctx, rwt, validated)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("failed to apply schema: %w", err)
+	}
+
+	log.Ctx(ctx).Info().
+		Str("dir", s.dir).
+		Strs("files", names).
+		Stringer("revision", revision).
+		Strs("added", append(applied.NewObjectDefNames, applied.NewCaveatDefNames...)).
+		Strs("removed", append(applied.RemovedObjectDefNames, applied.RemovedCaveatDefNames...)).
+		Msg("applied schema from schema directory")
+	return nil
+}
+
+// Run syncs the schema when called and again whenever the files of the directory change,
+// until the context is cancelled. A schema which fails to sync is logged, and the previous
+// schema is kept until the files change again.
+func (s *Syncer) Run(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to watch schema directory: %w", err)
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(s.dir); err != nil {
+		return fmt.Errorf("failed to watch schema directory: %w", err)
+	}
+
+	s.syncAndLog(ctx)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-watcher.Events:
+			// A single change produces several events, so the schema is only applied once the
+			// contents of the files differ from those last synced.
+			s.syncAndLog(ctx)
+
+		case err := <-watcher.Errors:
+			log.Ctx(ctx).Warn().Err(err).Msg("error watching schema directory")
+		}
+	}
+}
+
+func (s *Syncer) syncAndLog(ctx context.Context) {
+	err := s.Sync(ctx)
+	if err == nil || errors.Is(err, context.Canceled) {
+		return
+	}
+
+	log.Ctx(ctx).Error().Err(err).Str("dir", s.dir).Msg("failed to sync schema from schema directory")
+
+	// The failed contents are remembered, so that they are not retried until they change.
+	if _, contents, readErr := s.read(); readErr == nil {
+		s.lastContents = contents
+	}
+}
@@ -0,0 +1,156 @@
+package schemadir
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/middleware/audit"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+type memorySink struct {
+	lock    sync.Mutex
+	records []*audit.Record
+}
+
+func (ms *memorySink) Emit(_ context.Context, record *audit.Record) error {
+	ms.lock.Lock()
+	defer ms.lock.Unlock()
+	ms.records = append(ms.records, record)
+	return nil
+}
+
+func (ms *memorySink) Close() error {
+	return nil
+}
+
+func (ms *memorySink) count() int {
+	ms.lock.Lock()
+	defer ms.lock.Unlock()
+	return len(ms.records)
+}
+
+func writeFile(t *testing.T, dir, name, contents string) {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(contents), 0o600))
+}
+
+func definitionNames(t *testing.T, ds datastore.Datastore) []string {
+	t.Helper()
+
+	ctx := context.Background()
+	revision, err := ds.HeadRevision(ctx)
+	require.NoError(t, err)
+	namespaces, err := ds.SnapshotReader(revision).ListAllNamespaces(ctx)
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(namespaces))
+	for _, ns := range namespaces {
+		names = append(names, ns.Definition.Name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestSync(t *testing.T) {
+	ctx := context.Background()
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	writeFile(t, dir, "user.zed", `definition user {}`)
+	writeFile(t, dir, "document.zed", `definition document {
+		relation viewer: user
+		permission view = viewer
+	}`)
+	writeFile(t, dir, "README.md", `not a schema`)
+
+	sink := &memorySink{}
+	syncer, err := NewSyncer(dir, ds, sink, false)
+	require.NoError(t, err)
+
+	// The definitions of the files are applied together, and audited.
+	require.NoError(t, syncer.Sync(ctx))
+	require.Equal(t, []string{"document", "user"}, definitionNames(t, ds))
+	require.Len(t, sink.records, 1)
+	require.Equal(t, AuditActor, sink.records[0].Actor)
+	require.Len(t, sink.records[0].Definitions, 2)
+
+	// Unchanged files are not applied again.
+	require.NoError(t, syncer.Sync(ctx))
+	require.Len(t, sink.records, 1)
+
+	// Invalid files leave the schema unchanged.
+	writeFile(t, dir, "folder.zed", `definition folder {
+		relation parent: missing
+	}`)
+	require.ErrorContains(t, syncer.Sync(ctx), "invalid schema")
+	writeFile(t, dir, "folder.zed", `definition folder {`)
+	require.ErrorContains(t, syncer.Sync(ctx), "failed to compile schema file `folder.zed`")
+	writeFile(t, dir, "folder.zed", `definition user {}`)
+	require.ErrorContains(t, syncer.Sync(ctx), "definition `user` is defined in both `folder.zed` and `user.zed`")
+	require.Equal(t, []string{"document", "user"}, definitionNames(t, ds))
+
+	// Definitions with relationships cannot be removed.
+	_, err = ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteRelationships(ctx, []*core.RelationTupleUpdate{
+			tuple.Create(tuple.MustParse("document:firstdoc#viewer@user:tom")),
+		})
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.Remove(filepath.Join(dir, "folder.zed")))
+	require.NoError(t, os.Remove(filepath.Join(dir, "document.zed")))
+	require.ErrorContains(t, syncer.Sync(ctx), "failed to apply schema")
+	require.Equal(t, []string{"document", "user"}, definitionNames(t, ds))
+
+	// A directory without definitions is never applied.
+	require.NoError(t, os.Remove(filepath.Join(dir, "user.zed")))
+	require.ErrorContains(t, syncer.Sync(ctx), "defines no object definitions")
+	require.Equal(t, []string{"document", "user"}, definitionNames(t, ds))
+}
+
+func TestRun(t *testing.T) {
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	writeFile(t, dir, "schema.zed", `definition user {}`)
+
+	sink := &memorySink{}
+	syncer, err := NewSyncer(dir, ds, sink, false)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- syncer.Run(ctx) }()
+
+	require.Eventually(t, func() bool { return sink.count() == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	writeFile(t, dir, "schema.zed", `definition user {}
+	definition document {
+		relation viewer: user
+	}`)
+	require.Eventually(t, func() bool { return sink.count() == 2 }, 5*time.Second, 10*time.Millisecond)
+	require.Equal(t, []string{"document", "user"}, definitionNames(t, ds))
+
+	cancel()
+	require.NoError(t, <-done)
+}
+
+func TestNewSyncerRequiresDirectory(t *testing.T) {
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	_, err = NewSyncer(filepath.Join(t.TempDir(), "missing"), ds, nil, false)
+	require.ErrorContains(t, err, "failed to read schema directory")
+}
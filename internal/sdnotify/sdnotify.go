@@ -0,0 +1,140 @@
+// Package sdnotify implements the readiness notification and watchdog protocols of systemd,
+// so that services run with Type=notify are reported as started only once they can serve
+// requests, and are restarted by systemd if they stop doing so for longer than WatchdogSec.
+package sdnotify
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"time"
+
+	log "github.com/authzed/spicedb/internal/logging"
+)
+
+const (
+	// Ready tells systemd that the service has finished starting up.
+	Ready = "READY=1"
+
+	// Stopping tells systemd that the service is shutting down.
+	Stopping = "STOPPING=1"
+
+	// Watchdog pings the watchdog of the service.
+	Watchdog = "WATCHDOG=1"
+
+	// readinessPollInterval is how often readiness is polled while waiting for the service to
+	// become ready, when it is not already polled more often for the watchdog.
+	readinessPollInterval = time.Second
+)
+
+// Notifier sends notifications to the socket given to the process by systemd.
+type Notifier struct {
+	addr *net.UnixAddr
+
+	// watchdogInterval is the interval within which the watchdog must be pinged, or zero
+	// if the watchdog is disabled.
+	watchdogInterval time.Duration
+}
+
+// NewNotifier returns a Notifier for the socket named by the NOTIFY_SOCKET environment
+// variable, or nil if the process was not started by systemd with notifications enabled.
+func NewNotifier() (*Notifier, error) {
+	socket := os.Getenv("NOTIFY_SOCKET")
+	if socket == "" {
+		return nil, nil
+	}
+
+	watchdogInterval, err := watchdogIntervalFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return &Notifier{&net.UnixAddr{Name: socket, Net: "unixgram"}, watchdogInterval}, nil
+}
+
+// watchdogIntervalFromEnv returns the watchdog interval given by systemd in WATCHDOG_USEC,
+// or zero if the watchdog is disabled or meant for another process.
+func watchdogIntervalFromEnv() (time.Duration, error) {
+	usec := os.Getenv("WATCHDOG_USEC")
+	if usec == "" {
+		return 0, nil
+	}
+
+	if pid := os.Getenv("WATCHDOG_PID"); pid != "" && pid != strconv.Itoa(os.Getpid()) {
+		return 0, nil
+	}
+
+	parsed, err := strconv.ParseInt(usec, 10, 64)
+	if err != nil || parsed <= 0 {
+		return 0, fmt.Errorf("invalid WATCHDOG_USEC `%s`", usec)
+	}
+	return time.Duration(parsed) * time.Microsecond, nil
+}
+
+// Notify sends the state to systemd.
+func (n *Notifier) Notify(state string) error {
+	conn, err := net.DialUnix(n.addr.Net, nil, n.addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to systemd notify socket: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte(state)); err != nil {
+		return fmt.Errorf("failed to notify systemd: %w", err)
+	}
+	return nil
+}
+
+// Run notifies systemd that the service is ready once serving first returns true, and then
+// pings the watchdog, if enabled, whenever serving returns true, so that a service which stops
+// serving is restarted. Once the context is cancelled, systemd is told that the service is
+// stopping. A nil Notifier does nothing.
+func (n *Notifier) Run(ctx context.Context, serving func() bool) error {
+	if n == nil {
+		return nil
+	}
+
+	pollInterval := readinessPollInterval
+	if n.watchdogInterval > 0 {
+		// Pinging at half the interval, as recommended by systemd, tolerates a late ping.
+		pollInterval = min(pollInterval, n.watchdogInterval/2)
+		log.Ctx(ctx).Info().Dur("interval", n.watchdogInterval).Msg("pinging systemd watchdog while serving")
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	ready := false
+	for {
+		if serving() {
+			if !ready {
+				if err := n.Notify(Ready); err != nil {
+					return err
+				}
+				log.Ctx(ctx).Info().Msg("notified systemd that the server is ready")
+				ready = true
+			}
+
+			if n.watchdogInterval > 0 {
+				if err := n.Notify(Watchdog); err != nil {
+					log.Ctx(ctx).Warn().Err(err).Msg("failed to ping systemd watchdog")
+				}
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			if err := n.Notify(Stopping); err != nil {
+				log.Ctx(ctx).Warn().Err(err).Msg("failed to notify systemd that the server is stopping")
+			}
+			return nil
+
+		case <-ticker.C:
+			if ready && n.watchdogInterval == 0 {
+				// Without a watchdog, nothing remains to be sent until the server stops.
+				ticker.Stop()
+			}
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package sdnotify
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func listen(t *testing.T) (string, <-chan string) {
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	received := make(chan string, 100)
+	go func() {
+		buf := make([]byte, 1024)
+		for {
+			n, err := conn.Read(buf)
+			if err != nil {
+				return
+			}
+			received <- string(buf[:n])
+		}
+	}()
+	return path, received
+}
+
+func TestNewNotifier(t *testing.T) {
+	t.Setenv("NOTIFY_SOCKET", "")
+	notifier, err := NewNotifier()
+	require.NoError(t, err)
+	require.Nil(t, notifier)
+	require.NoError(t, notifier.Run(context.Background(), func() bool { return true }))
+
+	t.Setenv("NOTIFY_SOCKET", "/run/systemd/notify")
+	t.Setenv("WATCHDOG_USEC", "30000000")
+	notifier, err = NewNotifier()
+	require.NoError(t, err)
+	require.Equal(t, 30*time.Second, notifier.watchdogInterval)
+
+	// The watchdog of another process is ignored.
+	t.Setenv("WATCHDOG_PID", "1")
+	notifier, err = NewNotifier()
+	require.NoError(t, err)
+	require.Zero(t, notifier.watchdogInterval)
+
+	t.Setenv("WATCHDOG_PID", strconv.Itoa(1<<30))
+	t.Setenv("WATCHDOG_USEC", "soon")
+	_, err = NewNotifier()
+	require.NoError(t, err)
+
+	t.Setenv("WATCHDOG_PID", "")
+	_, err = NewNotifier()
+	require.ErrorContains(t, err, "invalid WATCHDOG_USEC")
+}
+
+func TestRun(t *testing.T) {
+	path, received := listen(t)
+	t.Setenv("NOTIFY_SOCKET", path)
+	t.Setenv("WATCHDOG_USEC", "40000")
+	notifier, err := NewNotifier()
+	require.NoError(t, err)
+
+	var serving atomic.Bool
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- notifier.Run(ctx, serving.Load) }()
+
+	// Nothing is sent until the server is serving.
+	select {
+	case state := <-received:
+		require.Fail(t, "unexpected notification", state)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	serving.Store(true)
+	require.Equal(t, Ready, <-received)
+	require.Equal(t, Watchdog, <-received)
+	require.Equal(t, Watchdog, <-received)
+
+	// The watchdog is no longer pinged once the server stops serving.
+	serving.Store(false)
+	time.Sleep(50 * time.Millisecond)
+	for len(received) > 0 {
+		<-received
+	}
+	select {
+	case state := <-received:
+		require.Fail(t, "unexpected notification", state)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	cancel()
+	require.NoError(t, <-done)
+	require.Equal(t, Stopping, <-received)
+}
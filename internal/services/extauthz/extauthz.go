@@ -0,0 +1,180 @@
+// Package extauthz implements Envoy's external authorization service, mapping the HTTP
+// requests received by the gateway to permission checks with a ruleset, so that permissions
+// can be enforced at the gateway without a separate shim service.
+package extauthz
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	rpcstatus "google.golang.org/genproto/googleapis/rpc/status"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/dispatch"
+	"github.com/authzed/spicedb/internal/graph/computed"
+	log "github.com/authzed/spicedb/internal/logging"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/namespace"
+	"github.com/authzed/spicedb/internal/services/shared"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// ServiceName is the name of Envoy's external authorization gRPC service.
+const ServiceName = "envoy.service.auth.v3.Authorization"
+
+type authorizationServer struct {
+	authv3.UnimplementedAuthorizationServer
+
+	dispatch        dispatch.Dispatcher
+	ruleset         *Ruleset
+	maximumAPIDepth uint32
+}
+
+// NewAuthorizationServer creates an Envoy external authorization server which allows the HTTP
+// requests for which the permission of the first matching rule of the ruleset is held.
+//
+// Requests are checked at the datastore's optimized revision, as with minimize_latency.
+// Conditional permissions are treated as not held, as the HTTP request provides no caveat
+// context.
+func NewAuthorizationServer(dispatch dispatch.Dispatcher, ruleset *Ruleset, maximumAPIDepth uint32) authv3.AuthorizationServer {
+	return &authorizationServer{
+		dispatch:        dispatch,
+		ruleset:         ruleset,
+		maximumAPIDepth: maximumAPIDepth,
+	}
+}
+
+func (as *authorizationServer) Check(ctx context.Context, req *authv3.CheckRequest) (*authv3.CheckResponse, error) {
+	httpReq := req.GetAttributes().GetRequest().GetHttp()
+	if httpReq == nil {
+		return nil, status.Errorf(codes.InvalidArgument, "missing HTTP request attributes")
+	}
+
+	path, _, _ := strings.Cut(httpReq.GetPath(), "?")
+	method := strings.ToUpper(httpReq.GetMethod())
+
+	for _, rule := range as.ruleset.Rules {
+		parameters, ok := rule.match(method, path)
+		if !ok {
+			continue
+		}
+		return as.checkRule(ctx, rule, parameters, httpReq.GetHeaders())
+	}
+
+	if as.ruleset.AllowUnmatched {
+		return allowed(), nil
+	}
+	return denied(typev3.StatusCode_Forbidden, "no rule matches the request"), nil
+}
+
+func (as *authorizationServer) checkRule(ctx context.Context, rule *Rule, parameters map[string]string, headers map[string]string) (*authv3.CheckResponse, error) {
+	resourceID, ok := expand(rule.ResourceID, parameters, headers)
+	if !ok {
+		return denied(typev3.StatusCode_BadRequest, "missing header for the resource"), nil
+	}
+	if unescaped, err := url.PathUnescape(resourceID); err == nil {
+		resourceID = unescaped
+	}
+	if err := tuple.ValidateResourceID(resourceID); err != nil {
+		return denied(typev3.StatusCode_BadRequest, "invalid resource ID"), nil
+	}
+
+	subjectID, ok := expand(rule.SubjectID, parameters, headers)
+	if !ok {
+		return denied(typev3.StatusCode_Unauthorized, "missing header for the subject"), nil
+	}
+	if err := tuple.ValidateSubjectID(subjectID); err != nil {
+		return denied(typev3.StatusCode_Unauthorized, "invalid subject ID"), nil
+	}
+
+	subjectRelation := rule.SubjectRelation
+	if subjectRelation == "" {
+		subjectRelation = tuple.Ellipsis
+	}
+
+	ds := datastoremw.MustFromContext(ctx)
+	revision, err := ds.OptimizedRevision(ctx)
+	if err != nil {
+		return nil, as.rewriteError(ctx, err)
+	}
+
+	if err := namespace.CheckNamespaceAndRelations(ctx,
+		[]namespace.TypeAndRelationToCheck{
+			{
+				NamespaceName: rule.ResourceType,
+				RelationName:  rule.Permission,
+				AllowEllipsis: false,
+			},
+			{
+				NamespaceName: rule.SubjectType,
+				RelationName:  subjectRelation,
+				AllowEllipsis: true,
+			},
+		}, ds.SnapshotReader(revision)); err != nil {
+		return nil, as.rewriteError(ctx, err)
+	}
+
+	cr, _, err := computed.ComputeCheck(ctx, as.dispatch,
+		computed.CheckParameters{
+			ResourceType: &core.RelationReference{
+				Namespace: rule.ResourceType,
+				Relation:  rule.Permission,
+			},
+			Subject: &core.ObjectAndRelation{
+				Namespace: rule.SubjectType,
+				ObjectId:  subjectID,
+				Relation:  subjectRelation,
+			},
+			AtRevision:   revision,
+			MaximumDepth: as.maximumAPIDepth,
+			DebugOption:  computed.NoDebugging,
+		},
+		resourceID,
+	)
+	if err != nil {
+		return nil, as.rewriteError(ctx, err)
+	}
+
+	if cr.Membership != dispatchv1.ResourceCheckResult_MEMBER {
+		log.Ctx(ctx).Debug().
+			Str("resource", tuple.StringONR(tuple.ObjectAndRelation(rule.ResourceType, resourceID, rule.Permission))).
+			Str("subject", tuple.StringONR(tuple.ObjectAndRelation(rule.SubjectType, subjectID, subjectRelation))).
+			Msg("ext_authz request denied")
+		return denied(typev3.StatusCode_Forbidden, fmt.Sprintf("missing permission `%s` on `%s`", rule.Permission, rule.ResourceType)), nil
+	}
+	return allowed(), nil
+}
+
+func (as *authorizationServer) rewriteError(ctx context.Context, err error) error {
+	return shared.RewriteError(ctx, err, &shared.ConfigForErrors{
+		MaximumAPIDepth: as.maximumAPIDepth,
+	})
+}
+
+func allowed() *authv3.CheckResponse {
+	return &authv3.CheckResponse{
+		Status: &rpcstatus.Status{Code: int32(codes.OK)},
+		HttpResponse: &authv3.CheckResponse_OkResponse{
+			OkResponse: &authv3.OkHttpResponse{},
+		},
+	}
+}
+
+func denied(code typev3.StatusCode, reason string) *authv3.CheckResponse {
+	return &authv3.CheckResponse{
+		Status: &rpcstatus.Status{Code: int32(codes.PermissionDenied), Message: reason},
+		HttpResponse: &authv3.CheckResponse_DeniedResponse{
+			DeniedResponse: &authv3.DeniedHttpResponse{
+				Status: &typev3.HttpStatus{Code: code},
+				Body:   reason,
+			},
+		},
+	}
+}
@@ -0,0 +1,136 @@
+package extauthz
+
+import (
+	"context"
+	"testing"
+
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
+	typev3 "github.com/envoyproxy/go-control-plane/envoy/type/v3"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/dispatch/graph"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const testRuleset = `
+rules:
+  - methods: [get]
+    path: /documents/{id}
+    resource_type: document
+    resource_id: "{id}"
+    permission: view
+    subject_type: user
+    subject_id: "{header:X-User-ID}"
+  - methods: [PUT, POST]
+    path: /documents/{id}/**
+    resource_type: document
+    resource_id: "{id}"
+    permission: edit
+    subject_type: user
+    subject_id: "{header:x-user-id}"
+  - path: /misconfigured
+    resource_type: unknown
+    resource_id: "some"
+    permission: view
+    subject_type: user
+    subject_id: "{header:x-user-id}"
+`
+
+const testSchema = `
+definition user {}
+
+definition document {
+	relation viewer: user
+	relation editor: user
+	permission view = viewer + editor
+	permission edit = editor
+}
+`
+
+func TestCheck(t *testing.T) {
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+
+	ds, _ := testfixtures.DatastoreFromSchemaAndTestRelationships(rawDS, testSchema, []*core.RelationTuple{
+		tuple.MustParse("document:readme#viewer@user:tom"),
+		tuple.MustParse("document:readme#editor@user:sarah"),
+	}, require.New(t))
+
+	ruleset, err := ParseRuleset([]byte(testRuleset))
+	require.NoError(t, err)
+
+	server := NewAuthorizationServer(graph.NewLocalOnlyDispatcher(10), ruleset, 50)
+	ctx := datastoremw.ContextWithDatastore(context.Background(), ds)
+
+	tcs := []struct {
+		name         string
+		method       string
+		path         string
+		user         string
+		expectedCode typev3.StatusCode
+	}{
+		{"viewer can view", "GET", "/documents/readme", "tom", typev3.StatusCode_OK},
+		{"editor can view", "GET", "/documents/readme?format=html", "sarah", typev3.StatusCode_OK},
+		{"viewer cannot edit", "PUT", "/documents/readme/content", "tom", typev3.StatusCode_Forbidden},
+		{"editor can edit", "POST", "/documents/readme/comments/1", "sarah", typev3.StatusCode_OK},
+		{"unrelated user", "GET", "/documents/readme", "fred", typev3.StatusCode_Forbidden},
+		{"missing subject header", "GET", "/documents/readme", "", typev3.StatusCode_Unauthorized},
+		{"unmatched method", "DELETE", "/documents/readme", "tom", typev3.StatusCode_Forbidden},
+		{"unmatched path", "GET", "/folders/root", "tom", typev3.StatusCode_Forbidden},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			resp, err := server.Check(ctx, checkRequest(tc.method, tc.path, tc.user))
+			require.NoError(t, err)
+
+			if tc.expectedCode == typev3.StatusCode_OK {
+				require.Equal(t, int32(codes.OK), resp.Status.Code)
+				require.NotNil(t, resp.GetOkResponse())
+				return
+			}
+
+			require.Equal(t, int32(codes.PermissionDenied), resp.Status.Code)
+			require.Equal(t, tc.expectedCode, resp.GetDeniedResponse().Status.Code)
+		})
+	}
+
+	// Rules referencing definitions missing from the schema fail the check, so that Envoy
+	// applies its failure mode.
+	_, err = server.Check(ctx, checkRequest("GET", "/misconfigured", "tom"))
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = server.Check(ctx, &authv3.CheckRequest{})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// Unmatched requests can be allowed.
+	ruleset.AllowUnmatched = true
+	resp, err := server.Check(ctx, checkRequest("GET", "/folders/root", "tom"))
+	require.NoError(t, err)
+	require.NotNil(t, resp.GetOkResponse())
+}
+
+func checkRequest(method, path, user string) *authv3.CheckRequest {
+	headers := map[string]string{}
+	if user != "" {
+		headers["x-user-id"] = user
+	}
+
+	return &authv3.CheckRequest{
+		Attributes: &authv3.AttributeContext{
+			Request: &authv3.AttributeContext_Request{
+				Http: &authv3.AttributeContext_HttpRequest{
+					Method:  method,
+					Path:    path,
+					Headers: headers,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,182 @@
+package extauthz
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// Ruleset maps HTTP requests to permission checks. Rules are matched in order and the first
+// matching rule decides the request.
+//
+// For example:
+//
+//	rules:
+//	  - methods: [GET]
+//	    path: /documents/{id}
+//	    resource_type: document
+//	    resource_id: "{id}"
+//	    permission: view
+//	    subject_type: user
+//	    subject_id: "{header:x-user-id}"
+type Ruleset struct {
+	// Rules are the rules matched against each request.
+	Rules []*Rule `yaml:"rules"`
+
+	// AllowUnmatched allows the requests which match no rule, which are denied otherwise.
+	AllowUnmatched bool `yaml:"allow_unmatched"`
+}
+
+// Rule maps the HTTP requests matching its methods and path to a check of a permission.
+//
+// The resource and subject IDs are templates which may reference the parameters of the path,
+// as `{name}`, and the headers of the request, as `{header:name}`.
+type Rule struct {
+	// Methods are the HTTP methods matched, or all methods if empty.
+	Methods []string `yaml:"methods"`
+
+	// Path is the path matched, whose segments are either literals, parameters such as
+	// `{id}`, or `*` for any single segment. A final `**` segment matches any remainder.
+	Path string `yaml:"path"`
+
+	ResourceType string `yaml:"resource_type"`
+	ResourceID   string `yaml:"resource_id"`
+	Permission   string `yaml:"permission"`
+
+	SubjectType     string `yaml:"subject_type"`
+	SubjectID       string `yaml:"subject_id"`
+	SubjectRelation string `yaml:"subject_relation"`
+
+	segments []string
+}
+
+var (
+	placeholderRegex = regexp.MustCompile(`\{([^{}]*)\}`)
+	parameterRegex   = regexp.MustCompile(`^\{([a-zA-Z_][a-zA-Z0-9_]*)\}$`)
+)
+
+// LoadRuleset reads and validates the ruleset in the YAML file at the path.
+func LoadRuleset(path string) (*Ruleset, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading ext_authz ruleset: %w", err)
+	}
+	return ParseRuleset(contents)
+}
+
+// ParseRuleset parses and validates a ruleset in YAML.
+func ParseRuleset(contents []byte) (*Ruleset, error) {
+	var ruleset Ruleset
+	if err := yamlv3.Unmarshal(contents, &ruleset); err != nil {
+		return nil, fmt.Errorf("error parsing ext_authz ruleset: %w", err)
+	}
+
+	for i, rule := range ruleset.Rules {
+		if err := rule.compile(); err != nil {
+			return nil, fmt.Errorf("invalid ext_authz rule %d: %w", i, err)
+		}
+	}
+	return &ruleset, nil
+}
+
+func (r *Rule) compile() error {
+	if !strings.HasPrefix(r.Path, "/") {
+		return fmt.Errorf("path `%s` must start with `/`", r.Path)
+	}
+	if r.ResourceType == "" || r.ResourceID == "" || r.Permission == "" {
+		return fmt.Errorf("resource_type, resource_id and permission are required")
+	}
+	if r.SubjectType == "" || r.SubjectID == "" {
+		return fmt.Errorf("subject_type and subject_id are required")
+	}
+
+	for i, method := range r.Methods {
+		r.Methods[i] = strings.ToUpper(method)
+	}
+
+	r.segments = strings.Split(strings.TrimPrefix(r.Path, "/"), "/")
+	parameters := map[string]struct{}{}
+	for i, segment := range r.segments {
+		if segment == "**" && i != len(r.segments)-1 {
+			return fmt.Errorf("`**` must be the last segment of path `%s`", r.Path)
+		}
+		if groups := parameterRegex.FindStringSubmatch(segment); groups != nil {
+			parameters[groups[1]] = struct{}{}
+		} else if strings.ContainsAny(segment, "{}") {
+			return fmt.Errorf("invalid segment `%s` in path `%s`", segment, r.Path)
+		}
+	}
+
+	for _, template := range []string{r.ResourceID, r.SubjectID} {
+		for _, groups := range placeholderRegex.FindAllStringSubmatch(template, -1) {
+			if strings.HasPrefix(groups[1], "header:") {
+				continue
+			}
+			if _, ok := parameters[groups[1]]; !ok {
+				return fmt.Errorf("`%s` references unknown path parameter `%s`", template, groups[1])
+			}
+		}
+	}
+	return nil
+}
+
+// match returns the parameters of the path if the rule matches the method and path.
+func (r *Rule) match(method, path string) (map[string]string, bool) {
+	if len(r.Methods) > 0 {
+		found := false
+		for _, allowed := range r.Methods {
+			if allowed == method {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, false
+		}
+	}
+
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	parameters := map[string]string{}
+	for i, segment := range r.segments {
+		if segment == "**" {
+			return parameters, true
+		}
+		if i >= len(segments) {
+			return nil, false
+		}
+
+		if groups := parameterRegex.FindStringSubmatch(segment); groups != nil {
+			if segments[i] == "" {
+				return nil, false
+			}
+			parameters[groups[1]] = segments[i]
+			continue
+		}
+		if segment != "*" && segment != segments[i] {
+			return nil, false
+		}
+	}
+	return parameters, len(segments) == len(r.segments)
+}
+
+// expand replaces the placeholders of the template with the parameters of the path and the
+// headers of the request, returning false if a referenced header is missing or empty.
+func expand(template string, parameters map[string]string, headers map[string]string) (string, bool) {
+	ok := true
+	expanded := placeholderRegex.ReplaceAllStringFunc(template, func(placeholder string) string {
+		name := placeholder[1 : len(placeholder)-1]
+		if header, isHeader := strings.CutPrefix(name, "header:"); isHeader {
+			// Envoy sends the names of headers in lowercase.
+			value := headers[strings.ToLower(header)]
+			if value == "" {
+				ok = false
+			}
+			return value
+		}
+		return parameters[name]
+	})
+	return expanded, ok
+}
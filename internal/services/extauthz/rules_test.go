@@ -0,0 +1,83 @@
+package extauthz
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseRulesetErrors(t *testing.T) {
+	tcs := []struct {
+		name          string
+		ruleset       string
+		expectedError string
+	}{
+		{
+			"relative path",
+			`rules: [{path: documents, resource_type: document, resource_id: x, permission: view, subject_type: user, subject_id: y}]`,
+			"must start with `/`",
+		},
+		{
+			"missing permission",
+			`rules: [{path: /documents, resource_type: document, resource_id: x, subject_type: user, subject_id: y}]`,
+			"resource_type, resource_id and permission are required",
+		},
+		{
+			"missing subject",
+			`rules: [{path: /documents, resource_type: document, resource_id: x, permission: view}]`,
+			"subject_type and subject_id are required",
+		},
+		{
+			"unknown parameter",
+			`rules: [{path: "/documents/{id}", resource_type: document, resource_id: "{name}", permission: view, subject_type: user, subject_id: y}]`,
+			"unknown path parameter `name`",
+		},
+		{
+			"misplaced wildcard",
+			`rules: [{path: "/documents/**/content", resource_type: document, resource_id: x, permission: view, subject_type: user, subject_id: y}]`,
+			"must be the last segment",
+		},
+		{
+			"invalid segment",
+			`rules: [{path: "/documents/doc-{id}", resource_type: document, resource_id: "{id}", permission: view, subject_type: user, subject_id: y}]`,
+			"invalid segment `doc-{id}`",
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseRuleset([]byte(tc.ruleset))
+			require.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestRuleMatch(t *testing.T) {
+	ruleset, err := ParseRuleset([]byte(`
+rules:
+  - path: /orgs/{org}/*/{id}
+    resource_type: document
+    resource_id: "{org}_{id}"
+    permission: view
+    subject_type: user
+    subject_id: "{header:x-user-id}"
+`))
+	require.NoError(t, err)
+	rule := ruleset.Rules[0]
+
+	parameters, ok := rule.match("GET", "/orgs/acme/docs/readme")
+	require.True(t, ok)
+	require.Equal(t, map[string]string{"org": "acme", "id": "readme"}, parameters)
+
+	resourceID, ok := expand(rule.ResourceID, parameters, nil)
+	require.True(t, ok)
+	require.Equal(t, "acme_readme", resourceID)
+
+	_, ok = expand(rule.SubjectID, parameters, map[string]string{})
+	require.False(t, ok)
+
+	for _, path := range []string{"/orgs/acme/docs", "/orgs/acme/docs/readme/more", "/orgs//docs/readme", "/teams/acme/docs/readme"} {
+		_, ok := rule.match("GET", path)
+		require.False(t, ok, path)
+	}
+}
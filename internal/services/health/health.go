@@ -2,6 +2,7 @@ package health
 
 import (
 	"context"
+	"sync/atomic"
 	"time"
 
 	"github.com/authzed/grpcutil"
@@ -13,14 +14,20 @@ import (
 	"github.com/authzed/spicedb/pkg/datastore"
 )
 
-const datastoreReadyTimeout = time.Millisecond * 500
+const (
+	datastoreReadyTimeout = time.Millisecond * 500
+
+	// recheckInterval is how often readiness is checked once the services are serving, and
+	// the longest interval between checks while they are not.
+	recheckInterval = time.Second * 5
+)
 
 // NewHealthManager creates and returns a new health manager that checks the IsReady
 // status of the given dispatcher and datastore checker and sets the health check to
-// return healthy once both have gone to true.
+// return healthy while both are true.
 func NewHealthManager(dispatcher dispatch.Dispatcher, dsc DatastoreChecker) Manager {
 	healthSvc := grpcutil.NewAuthlessHealthServer()
-	return &healthManager{healthSvc, dispatcher, dsc, map[string]struct{}{}}
+	return &healthManager{healthSvc: healthSvc, dispatcher: dispatcher, dsc: dsc, serviceNames: map[string]struct{}{}, recheckInterval: recheckInterval}
 }
 
 // DatastoreChecker is an interface for determining if the datastore is ready for
@@ -39,8 +46,16 @@ type Manager interface {
 	// HealthSvc is the health service this manager is managing.
 	HealthSvc() *grpcutil.AuthlessHealthServer
 
-	// Checker returns a function that can be run via an errgroup to perform the health checks.
+	// Checker returns a function that can be run via an errgroup to perform the health checks
+	// until the context is canceled.
 	Checker(ctx context.Context) func() error
+
+	// Shutdown marks all services as not serving, so that load balancers drain traffic
+	// away from the server, and ignores any subsequent status changes.
+	Shutdown()
+
+	// IsServing returns whether the services are reported as serving.
+	IsServing() bool
 }
 
 type healthManager struct {
@@ -48,6 +63,10 @@ type healthManager struct {
 	dispatcher   dispatch.Dispatcher
 	dsc          DatastoreChecker
 	serviceNames map[string]struct{}
+	serving      atomic.Bool
+	shutdown     atomic.Bool
+
+	recheckInterval time.Duration
 }
 
 func (hm *healthManager) HealthSvc() *grpcutil.AuthlessHealthServer {
@@ -59,13 +78,24 @@ func (hm *healthManager) RegisterReportedService(serviceName string) {
 	hm.healthSvc.Server.SetServingStatus(serviceName, healthpb.HealthCheckResponse_NOT_SERVING)
 }
 
+func (hm *healthManager) Shutdown() {
+	hm.shutdown.Store(true)
+	hm.healthSvc.Server.Shutdown()
+}
+
+func (hm *healthManager) IsServing() bool {
+	return hm.serving.Load() && !hm.shutdown.Load()
+}
+
 func (hm *healthManager) Checker(ctx context.Context) func() error {
 	return func() error {
 		// Run immediately for the initial check
 		backoffInterval := backoff.NewExponentialBackOff()
+		backoffInterval.MaxInterval = hm.recheckInterval
 		backoffInterval.MaxElapsedTime = 0
 
 		ticker := time.After(0)
+		serving := false
 
 		for {
 			select {
@@ -80,12 +110,18 @@ func (hm *healthManager) Checker(ctx context.Context) func() error {
 				return nil
 			}
 
+			// Once serving, readiness continues to be checked so that the services stop
+			// serving if the datastore or dispatcher become unavailable.
 			isReady := hm.checkIsReady(ctx)
+			if isReady != serving {
+				hm.setServingStatus(ctx, isReady)
+				serving = isReady
+			}
+
 			if isReady {
-				for serviceName := range hm.serviceNames {
-					hm.healthSvc.Server.SetServingStatus(serviceName, healthpb.HealthCheckResponse_SERVING)
-				}
-				return nil
+				backoffInterval.Reset()
+				ticker = time.After(hm.recheckInterval)
+				continue
 			}
 
 			nextPush := backoffInterval.NextBackOff()
@@ -98,6 +134,19 @@ func (hm *healthManager) Checker(ctx context.Context) func() error {
 	}
 }
 
+func (hm *healthManager) setServingStatus(ctx context.Context, serving bool) {
+	status := healthpb.HealthCheckResponse_NOT_SERVING
+	if serving {
+		status = healthpb.HealthCheckResponse_SERVING
+	}
+	log.Ctx(ctx).Info().Stringer("status", status).Msg("updating health status of services")
+	hm.serving.Store(serving)
+
+	for serviceName := range hm.serviceNames {
+		hm.healthSvc.Server.SetServingStatus(serviceName, status)
+	}
+}
+
 func (hm *healthManager) checkIsReady(ctx context.Context) bool {
 	log.Ctx(ctx).Debug().Msg("checking if datastore and dispatcher are ready")
 
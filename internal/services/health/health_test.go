@@ -0,0 +1,78 @@
+package health
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+
+	"github.com/authzed/spicedb/internal/dispatch"
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+type fakeDispatcher struct {
+	dispatch.Dispatcher
+}
+
+func (fakeDispatcher) ReadyState() dispatch.ReadyState {
+	return dispatch.ReadyState{IsReady: true}
+}
+
+type fakeDatastoreChecker struct {
+	ready atomic.Bool
+}
+
+func (f *fakeDatastoreChecker) ReadyState(_ context.Context) (datastore.ReadyState, error) {
+	return datastore.ReadyState{IsReady: f.ready.Load(), Message: "some message"}, nil
+}
+
+func TestChecker(t *testing.T) {
+	dsc := &fakeDatastoreChecker{}
+	hm := NewHealthManager(fakeDispatcher{}, dsc).(*healthManager)
+	hm.recheckInterval = 10 * time.Millisecond
+	hm.RegisterReportedService("someservice")
+
+	status := func() healthpb.HealthCheckResponse_ServingStatus {
+		resp, err := hm.HealthSvc().Check(context.Background(), &healthpb.HealthCheckRequest{Service: "someservice"})
+		require.NoError(t, err)
+		return resp.Status
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() {
+		done <- hm.Checker(ctx)()
+	}()
+
+	// The service is not serving until the datastore is ready.
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status())
+	require.False(t, hm.IsServing())
+
+	dsc.ready.Store(true)
+	require.Eventually(t, func() bool {
+		return status() == healthpb.HealthCheckResponse_SERVING
+	}, 5*time.Second, 10*time.Millisecond)
+	require.True(t, hm.IsServing())
+
+	// It stops serving if the datastore becomes unavailable, and serves again once it recovers.
+	dsc.ready.Store(false)
+	require.Eventually(t, func() bool {
+		return status() == healthpb.HealthCheckResponse_NOT_SERVING
+	}, 5*time.Second, 10*time.Millisecond)
+
+	dsc.ready.Store(true)
+	require.Eventually(t, func() bool {
+		return status() == healthpb.HealthCheckResponse_SERVING
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// Once shut down, the service is not serving regardless of readiness.
+	hm.Shutdown()
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, status())
+	require.False(t, hm.IsServing())
+
+	cancel()
+	require.NoError(t, <-done)
+}
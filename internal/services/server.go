@@ -1,8 +1,6 @@
 package services
 
 import (
-	"time"
-
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/authzed/grpcutil"
 	"google.golang.org/grpc"
@@ -12,6 +10,7 @@ import (
 	"github.com/authzed/spicedb/internal/dispatch"
 	"github.com/authzed/spicedb/internal/services/health"
 	v1svc "github.com/authzed/spicedb/internal/services/v1"
+	"github.com/authzed/spicedb/internal/services/v1/options"
 )
 
 // SchemaServiceOption defines the options for enabling or disabling the V1 Schema service.
@@ -23,6 +22,9 @@ type WatchServiceOption int
 // CaveatsOption defines the options for enabling or disabling caveats in the V1 services.
 type CaveatsOption int
 
+// ReflectionOption defines the options for enabling or disabling the gRPC reflection service.
+type ReflectionOption int
+
 const (
 	// V1SchemaServiceDisabled indicates that the V1 schema service is disabled.
 	V1SchemaServiceDisabled SchemaServiceOption = 0
@@ -34,11 +36,21 @@ const (
 	// mode for testing.
 	V1SchemaServiceAdditiveOnly SchemaServiceOption = 2
 
+	// V1SchemaServiceReadOnly indicates that the V1 schema service is enabled, but rejects
+	// writes of the schema.
+	V1SchemaServiceReadOnly SchemaServiceOption = 3
+
 	// WatchServiceDisabled indicates that the V1 watch service is disabled.
 	WatchServiceDisabled WatchServiceOption = 0
 
 	// WatchServiceEnabled indicates that the V1 watch service is enabled.
 	WatchServiceEnabled WatchServiceOption = 1
+
+	// ReflectionDisabled indicates that the gRPC reflection service is disabled.
+	ReflectionDisabled ReflectionOption = 0
+
+	// ReflectionEnabled indicates that the gRPC reflection service is enabled.
+	ReflectionEnabled ReflectionOption = 1
 )
 
 const (
@@ -53,25 +65,39 @@ func RegisterGrpcServices(
 	dispatch dispatch.Dispatcher,
 	schemaServiceOption SchemaServiceOption,
 	watchServiceOption WatchServiceOption,
+	reflectionOption ReflectionOption,
 	permSysConfig v1svc.PermissionsServerConfig,
-	watchHeartbeatDuration time.Duration,
+	watchConfig v1svc.WatchServerConfig,
 ) {
 	healthManager.RegisterReportedService(OverallServerHealthCheckKey)
 
 	v1.RegisterPermissionsServiceServer(srv, v1svc.NewPermissionsServer(dispatch, permSysConfig))
-	v1.RegisterExperimentalServiceServer(srv, v1svc.NewExperimentalServer(dispatch, permSysConfig))
+	var experimentalOpts []options.ExperimentalServerOptionsOption
+	if permSysConfig.DefaultExportBatchSize > 0 {
+		experimentalOpts = append(experimentalOpts, options.WithDefaultExportBatchSize(permSysConfig.DefaultExportBatchSize))
+	}
+	if permSysConfig.MaxExportBatchSize > 0 {
+		experimentalOpts = append(experimentalOpts, options.WithMaxExportBatchSize(permSysConfig.MaxExportBatchSize))
+	}
+	v1.RegisterExperimentalServiceServer(srv, v1svc.NewExperimentalServer(dispatch, permSysConfig, experimentalOpts...))
 	healthManager.RegisterReportedService(v1.PermissionsService_ServiceDesc.ServiceName)
 
 	if watchServiceOption == WatchServiceEnabled {
-		v1.RegisterWatchServiceServer(srv, v1svc.NewWatchServer(watchHeartbeatDuration))
+		v1.RegisterWatchServiceServer(srv, v1svc.NewWatchServer(watchConfig))
 		healthManager.RegisterReportedService(v1.WatchService_ServiceDesc.ServiceName)
 	}
 
-	if schemaServiceOption == V1SchemaServiceEnabled || schemaServiceOption == V1SchemaServiceAdditiveOnly {
+	switch schemaServiceOption {
+	case V1SchemaServiceEnabled, V1SchemaServiceAdditiveOnly:
 		v1.RegisterSchemaServiceServer(srv, v1svc.NewSchemaServer(schemaServiceOption == V1SchemaServiceAdditiveOnly))
 		healthManager.RegisterReportedService(v1.SchemaService_ServiceDesc.ServiceName)
+	case V1SchemaServiceReadOnly:
+		v1.RegisterSchemaServiceServer(srv, v1svc.NewReadOnlySchemaServer())
+		healthManager.RegisterReportedService(v1.SchemaService_ServiceDesc.ServiceName)
 	}
 
 	healthpb.RegisterHealthServer(srv, healthManager.HealthSvc())
-	reflection.Register(grpcutil.NewAuthlessReflectionInterceptor(srv))
+	if reflectionOption == ReflectionEnabled {
+		reflection.Register(grpcutil.NewAuthlessReflectionInterceptor(srv))
+	}
 }
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/rs/zerolog"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
@@ -25,6 +27,10 @@ import (
 	"github.com/authzed/spicedb/pkg/typesystem"
 )
 
+// reasonTransactionMetadataUnsupported is the reason of the error returned when transaction
+// metadata is written to a datastore which cannot store it.
+const reasonTransactionMetadataUnsupported = "ERROR_REASON_TRANSACTION_METADATA_UNSUPPORTED"
+
 // ErrServiceReadOnly is an extended GRPC error returned when a service is in read-only mode.
 var ErrServiceReadOnly = mustMakeStatusReadonly()
 
@@ -47,9 +53,20 @@ func NewSchemaWriteDataValidationError(message string, args ...any) ErrSchemaWri
 	}
 }
 
+// NewSchemaWriteDataValidationErrorWithDetails creates a new error representing that a schema write
+// cannot be completed due to existing data, with the given details (such as the offending definition
+// and relation) included in the error's metadata.
+func NewSchemaWriteDataValidationErrorWithDetails(details map[string]string, message string, args ...any) ErrSchemaWriteDataValidation {
+	return ErrSchemaWriteDataValidation{
+		error:   fmt.Errorf(message, args...),
+		details: details,
+	}
+}
+
 // ErrSchemaWriteDataValidation occurs when a schema cannot be applied due to leaving data unreferenced.
 type ErrSchemaWriteDataValidation struct {
 	error
+	details map[string]string
 }
 
 // MarshalZerologObject implements zerolog object marshalling.
@@ -59,12 +76,17 @@ func (err ErrSchemaWriteDataValidation) MarshalZerologObject(e *zerolog.Event) {
 
 // GRPCStatus implements retrieving the gRPC status for the error.
 func (err ErrSchemaWriteDataValidation) GRPCStatus() *status.Status {
+	metadata := make(map[string]string, len(err.details))
+	for key, value := range err.details {
+		metadata[key] = value
+	}
+
 	return spiceerrors.WithCodeAndDetails(
 		err,
 		codes.InvalidArgument,
 		spiceerrors.ForReason(
 			v1.ErrorReason_ERROR_REASON_SCHEMA_TYPE_ERROR,
-			map[string]string{},
+			metadata,
 		),
 	)
 }
@@ -75,18 +97,26 @@ type MaxDepthExceededError struct {
 
 	// AllowedMaximumDepth is the configured allowed maximum depth.
 	AllowedMaximumDepth uint32
+
+	// CyclePath is the chain of requests forming the cycle which exhausted the depth, if any.
+	CyclePath []string
 }
 
 // GRPCStatus implements retrieving the gRPC status for the error.
 func (err MaxDepthExceededError) GRPCStatus() *status.Status {
+	metadata := map[string]string{
+		"maximum_depth_allowed": strconv.Itoa(int(err.AllowedMaximumDepth)),
+	}
+	if len(err.CyclePath) > 0 {
+		metadata["cycle_path"] = strings.Join(err.CyclePath, " -> ")
+	}
+
 	return spiceerrors.WithCodeAndDetails(
 		err,
 		codes.ResourceExhausted,
 		spiceerrors.ForReason(
 			v1.ErrorReason_ERROR_REASON_MAXIMUM_DEPTH_EXCEEDED,
-			map[string]string{
-				"maximum_depth_allowed": strconv.Itoa(int(err.AllowedMaximumDepth)),
-			},
+			metadata,
 		),
 	)
 }
@@ -97,12 +127,24 @@ func NewMaxDepthExceededError(allowedMaximumDepth uint32, isCheckRequest bool) e
 		return MaxDepthExceededError{
 			fmt.Errorf("the check request has exceeded the allowable maximum depth of %d: this usually indicates a recursive or too deep data dependency. Try running zed with --explain to see the dependency. See: https://spicedb.dev/d/debug-max-depth-check", allowedMaximumDepth),
 			allowedMaximumDepth,
+			nil,
 		}
 	}
 
 	return MaxDepthExceededError{
 		fmt.Errorf("the request has exceeded the allowable maximum depth of %d: this usually indicates a recursive or too deep data dependency. See: https://spicedb.dev/d/debug-max-depth", allowedMaximumDepth),
 		allowedMaximumDepth,
+		nil,
+	}
+}
+
+// NewCycleDetectedError creates a new MaxDepthExceededError for a check which exhausted the
+// depth because it depends upon itself through the given cycle.
+func NewCycleDetectedError(allowedMaximumDepth uint32, cyclePath []string) error {
+	return MaxDepthExceededError{
+		fmt.Errorf("the check request has exceeded the allowable maximum depth of %d because it depends upon itself through the cycle %s: this indicates a recursive data dependency. Try running zed with --explain to see the dependency. See: https://spicedb.dev/d/debug-max-depth-check", allowedMaximumDepth, strings.Join(cyclePath, " -> ")),
+		allowedMaximumDepth,
+		cyclePath,
 	}
 }
 
@@ -114,6 +156,23 @@ func AsValidationError(err error) *ErrSchemaWriteDataValidation {
 	return nil
 }
 
+// deadlineExceededRetryDelay is the delay suggested to clients before retrying a request which
+// exceeded its deadline, such as one which timed out while the datastore was overloaded.
+const deadlineExceededRetryDelay = 100 * time.Millisecond
+
+// invalidRevisionReasonName returns the name of the reason a revision was invalid, as included
+// in the error details.
+func invalidRevisionReasonName(reason datastore.InvalidRevisionReason) string {
+	switch reason {
+	case datastore.RevisionStale:
+		return "stale"
+	case datastore.CouldNotDetermineRevision:
+		return "indeterminate"
+	default:
+		return "unknown"
+	}
+}
+
 type ConfigForErrors struct {
 	MaximumAPIDepth uint32
 }
@@ -132,6 +191,11 @@ func RewriteError(ctx context.Context, err error, config *ConfigForErrors) error
 	var sourceError spiceerrors.ErrorWithSource
 	var typeError typesystem.TypeError
 	var maxDepthError dispatch.MaxDepthExceededError
+	var cycleError dispatch.CycleDetectedError
+	var invalidRevisionError datastore.ErrInvalidRevision
+	var integrityError datastore.ErrRelationshipIntegrity
+	var clockSkewError datastore.ErrClockSkew
+	var txMetadataUnsupportedError datastore.ErrTransactionMetadataUnsupported
 
 	switch {
 	case errors.As(err, &typeError):
@@ -149,6 +213,13 @@ func RewriteError(ctx context.Context, err error, config *ConfigForErrors) error
 	case errors.As(err, &relationNotFoundError):
 		return spiceerrors.WithCodeAndReason(err, codes.FailedPrecondition, v1.ErrorReason_ERROR_REASON_UNKNOWN_RELATION_OR_PERMISSION)
 
+	case errors.As(err, &cycleError):
+		if config == nil {
+			return spiceerrors.MustBugf("missing config for API error")
+		}
+
+		return NewCycleDetectedError(config.MaximumAPIDepth, cycleError.Path)
+
 	case errors.As(err, &maxDepthError):
 		if config == nil {
 			return spiceerrors.MustBugf("missing config for API error")
@@ -159,26 +230,81 @@ func RewriteError(ctx context.Context, err error, config *ConfigForErrors) error
 
 	case errors.As(err, &datastore.ErrReadOnly{}):
 		return ErrServiceReadOnly
-	case errors.As(err, &datastore.ErrInvalidRevision{}):
-		return status.Errorf(codes.OutOfRange, "invalid zedtoken: %s", err)
-	case errors.As(err, &datastore.ErrReadOnly{}):
-		return ErrServiceReadOnly
+	case errors.As(err, &invalidRevisionError):
+		metadata := map[string]string{
+			"revision_reason": invalidRevisionReasonName(invalidRevisionError.Reason()),
+		}
+		if invalidRevisionError.InvalidRevision() != nil {
+			metadata["revision"] = invalidRevisionError.InvalidRevision().String()
+		}
+
+		return spiceerrors.WithCodeAndDetailsAsError(
+			fmt.Errorf("invalid zedtoken: %w", err),
+			codes.OutOfRange,
+			&errdetails.ErrorInfo{
+				Reason:   "ERROR_REASON_INVALID_REVISION",
+				Domain:   spiceerrors.Domain,
+				Metadata: metadata,
+			},
+		)
 	case errors.As(err, &datastore.ErrCaveatNameNotFound{}):
 		return spiceerrors.WithCodeAndReason(err, codes.FailedPrecondition, v1.ErrorReason_ERROR_REASON_UNKNOWN_CAVEAT)
 	case errors.As(err, &datastore.ErrWatchDisabled{}):
-		return status.Errorf(codes.FailedPrecondition, "%s", err)
+		return spiceerrors.WithCodeAndDetailsAsError(
+			err,
+			codes.FailedPrecondition,
+			&errdetails.ErrorInfo{
+				Reason: "ERROR_REASON_WATCH_DISABLED",
+				Domain: spiceerrors.Domain,
+			},
+		)
+
+	case errors.As(err, &txMetadataUnsupportedError):
+		return spiceerrors.WithCodeAndDetailsAsError(
+			err,
+			codes.FailedPrecondition,
+			&errdetails.ErrorInfo{
+				Reason:   reasonTransactionMetadataUnsupported,
+				Domain:   spiceerrors.Domain,
+				Metadata: txMetadataUnsupportedError.DetailsMetadata(),
+			},
+		)
+
+	case errors.As(err, &integrityError):
+		log.Ctx(ctx).Err(err).Msg("relationship failed integrity verification")
+		return spiceerrors.WithCodeAndDetailsAsError(
+			err,
+			codes.DataLoss,
+			&errdetails.ErrorInfo{
+				Reason:   "ERROR_REASON_RELATIONSHIP_INTEGRITY",
+				Domain:   spiceerrors.Domain,
+				Metadata: integrityError.DetailsMetadata(),
+			},
+		)
+
+	case errors.As(err, &clockSkewError):
+		log.Ctx(ctx).Err(err).Msg("rejected write due to clock skew")
+		return spiceerrors.WithCodeAndDetailsAsError(
+			err,
+			codes.Unavailable,
+			&errdetails.ErrorInfo{
+				Reason:   "ERROR_REASON_CLOCK_SKEW",
+				Domain:   spiceerrors.Domain,
+				Metadata: clockSkewError.DetailsMetadata(),
+			},
+		)
 
 	case errors.As(err, &graph.ErrInvalidArgument{}):
 		return status.Errorf(codes.InvalidArgument, "%s", err)
 	case errors.As(err, &graph.ErrRelationMissingTypeInfo{}):
-		return status.Errorf(codes.FailedPrecondition, "failed precondition: %s", err)
+		return spiceerrors.WithCodeAndReason(fmt.Errorf("failed precondition: %w", err), codes.FailedPrecondition, v1.ErrorReason_ERROR_REASON_SCHEMA_TYPE_ERROR)
 	case errors.As(err, &graph.ErrAlwaysFail{}):
 		log.Ctx(ctx).Err(err).Msg("received internal error")
 		return status.Errorf(codes.Internal, "internal error: %s", err)
 	case errors.As(err, &graph.ErrUnimplemented{}):
 		return status.Errorf(codes.Unimplemented, "%s", err)
 	case errors.Is(err, context.DeadlineExceeded):
-		return status.Errorf(codes.DeadlineExceeded, "%s", err)
+		return spiceerrors.WithCodeAndDetailsAsError(err, codes.DeadlineExceeded, spiceerrors.ForRetry(deadlineExceededRetryDelay))
 	case errors.Is(err, context.Canceled):
 		err := context.Cause(ctx)
 		if err != nil {
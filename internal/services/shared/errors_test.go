@@ -7,9 +7,15 @@ import (
 
 	"github.com/authzed/grpcutil"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
+	"github.com/authzed/spicedb/internal/datastore/revisions"
 	"github.com/authzed/spicedb/internal/dispatch"
+	"github.com/authzed/spicedb/internal/graph"
+	"github.com/authzed/spicedb/internal/namespace"
+	"github.com/authzed/spicedb/pkg/datastore"
 	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
 )
 
@@ -25,6 +31,9 @@ func TestRewriteDeadlineExceededError(t *testing.T) {
 	defer cancelFunc()
 	errorRewritten := RewriteError(ctx, ctx.Err(), nil)
 	grpcutil.RequireStatus(t, codes.DeadlineExceeded, errorRewritten)
+
+	retryInfo := requireDetail[*errdetails.RetryInfo](t, errorRewritten)
+	require.Equal(t, deadlineExceededRetryDelay, retryInfo.RetryDelay.AsDuration())
 }
 
 func TestRewriteMaximumDepthExceededError(t *testing.T) {
@@ -35,6 +44,19 @@ func TestRewriteMaximumDepthExceededError(t *testing.T) {
 	grpcutil.RequireStatus(t, codes.ResourceExhausted, errorRewritten)
 }
 
+func TestRewriteCycleDetectedError(t *testing.T) {
+	path := []string{"group:a#member@user:tom", "group:b#member@user:tom", "group:a#member@user:tom"}
+	errorRewritten := RewriteError(context.Background(), dispatch.NewCycleDetectedError(&dispatchv1.DispatchCheckRequest{}, path), &ConfigForErrors{
+		MaximumAPIDepth: 50,
+	})
+	require.ErrorContains(t, errorRewritten, "group:a#member@user:tom -> group:b#member@user:tom -> group:a#member@user:tom")
+	grpcutil.RequireStatus(t, codes.ResourceExhausted, errorRewritten)
+
+	var maxDepthErr MaxDepthExceededError
+	require.ErrorAs(t, errorRewritten, &maxDepthErr)
+	require.Equal(t, path, maxDepthErr.CyclePath)
+}
+
 func TestRewriteMaximumDepthExceededErrorForCheck(t *testing.T) {
 	errorRewritten := RewriteError(context.Background(), dispatch.NewMaxDepthExceededError(&dispatchv1.DispatchCheckRequest{}), &ConfigForErrors{
 		MaximumAPIDepth: 50,
@@ -43,3 +65,87 @@ func TestRewriteMaximumDepthExceededErrorForCheck(t *testing.T) {
 	require.ErrorContains(t, errorRewritten, "--explain")
 	grpcutil.RequireStatus(t, codes.ResourceExhausted, errorRewritten)
 }
+
+func TestRewriteErrorDetails(t *testing.T) {
+	tcs := []struct {
+		name             string
+		err              error
+		expectedCode     codes.Code
+		expectedReason   string
+		expectedMetadata map[string]string
+	}{
+		{
+			"unknown definition",
+			namespace.NewNamespaceNotFoundErr("document"),
+			codes.FailedPrecondition,
+			"ERROR_REASON_UNKNOWN_DEFINITION",
+			map[string]string{"definition_name": "document"},
+		},
+		{
+			"unknown relation",
+			namespace.NewRelationNotFoundErr("document", "viewer"),
+			codes.FailedPrecondition,
+			"ERROR_REASON_UNKNOWN_RELATION_OR_PERMISSION",
+			map[string]string{"definition_name": "document", "relation_or_permission_name": "viewer"},
+		},
+		{
+			"relation missing type information",
+			graph.NewRelationMissingTypeInfoErr("document", "viewer"),
+			codes.FailedPrecondition,
+			"ERROR_REASON_SCHEMA_TYPE_ERROR",
+			map[string]string{"definition_name": "document", "relation_name": "viewer"},
+		},
+		{
+			"stale revision",
+			datastore.NewInvalidRevisionErr(revisions.NewForTransactionID(42), datastore.RevisionStale),
+			codes.OutOfRange,
+			"ERROR_REASON_INVALID_REVISION",
+			map[string]string{"revision": "42", "revision_reason": "stale"},
+		},
+		{
+			"watch disabled",
+			datastore.NewWatchDisabledErr("not supported"),
+			codes.FailedPrecondition,
+			"ERROR_REASON_WATCH_DISABLED",
+			nil,
+		},
+		{
+			"transaction metadata unsupported",
+			datastore.NewTransactionMetadataUnsupportedErr("spanner"),
+			codes.FailedPrecondition,
+			"ERROR_REASON_TRANSACTION_METADATA_UNSUPPORTED",
+			map[string]string{"datastore_engine": "spanner"},
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			errorRewritten := RewriteError(context.Background(), tc.err, nil)
+			grpcutil.RequireStatus(t, tc.expectedCode, errorRewritten)
+
+			info := requireDetail[*errdetails.ErrorInfo](t, errorRewritten)
+			require.Equal(t, tc.expectedReason, info.Reason)
+			for key, value := range tc.expectedMetadata {
+				require.Equal(t, value, info.Metadata[key], "metadata %s", key)
+			}
+		})
+	}
+}
+
+// requireDetail returns the detail of the given type in the gRPC status of the error.
+func requireDetail[T any](t *testing.T, err error) T {
+	t.Helper()
+
+	s, ok := status.FromError(err)
+	require.True(t, ok)
+	for _, detail := range s.Details() {
+		if typed, ok := detail.(T); ok {
+			return typed
+		}
+	}
+
+	var empty T
+	require.Failf(t, "missing error detail", "expected a detail of type %T in %v", empty, s.Details())
+	return empty
+}
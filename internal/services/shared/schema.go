@@ -2,6 +2,9 @@ package shared
 
 import (
 	"context"
+	"strconv"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 
 	log "github.com/authzed/spicedb/internal/logging"
 	"github.com/authzed/spicedb/internal/namespace"
@@ -24,6 +27,16 @@ type ValidatedSchemaChanges struct {
 	newCaveatDefNames    *mapz.Set[string]
 	newObjectDefNames    *mapz.Set[string]
 	additiveOnly         bool
+	deleteRelationships  bool
+}
+
+// WithRelationshipDeletion returns the changes, such that applying them deletes the relationships
+// of object definitions being removed, along with the relationships referencing them, rather than
+// failing because relationships exist.
+func (vsc *ValidatedSchemaChanges) WithRelationshipDeletion() *ValidatedSchemaChanges {
+	withDeletion := *vsc
+	withDeletion.deleteRelationships = true
+	return &withDeletion
 }
 
 // ValidateSchemaChanges validates the schema found in the compiled schema and returns a
@@ -147,11 +160,20 @@ func ApplySchemaChangesOverExisting(
 		existingObjectDefNames.Insert(existingDef.Name)
 	}
 
+	removedObjectDefNames := existingObjectDefNames.Subtract(validated.newObjectDefNames)
+
+	// The relationships of removed definitions are deleted, if requested, so they need not
+	// be checked.
+	deletedObjectDefNames := mapz.NewSet[string]()
+	if validated.deleteRelationships && !validated.additiveOnly {
+		deletedObjectDefNames = removedObjectDefNames
+	}
+
 	// For each definition, perform a diff and ensure the changes will not result in any
 	// breaking changes.
 	objectDefsWithChanges := make([]*core.NamespaceDefinition, 0, len(validated.compiled.ObjectDefinitions))
 	for _, nsdef := range validated.compiled.ObjectDefinitions {
-		diff, err := sanityCheckNamespaceChanges(ctx, rwt, nsdef, existingObjectDefMap)
+		diff, err := sanityCheckNamespaceChanges(ctx, rwt, nsdef, existingObjectDefMap, deletedObjectDefNames)
 		if err != nil {
 			return nil, err
 		}
@@ -179,10 +201,12 @@ func ApplySchemaChangesOverExisting(
 		Msg("validated namespace definitions")
 
 	// Ensure that deleting namespaces will not result in any relationships left without associated
-	// schema.
-	removedObjectDefNames := existingObjectDefNames.Subtract(validated.newObjectDefNames)
+	// schema, by deleting those relationships if requested.
 	if !validated.additiveOnly {
 		if err := removedObjectDefNames.ForEach(func(nsdefName string) error {
+			if validated.deleteRelationships {
+				return deleteRelationshipsOfDefinition(ctx, rwt, nsdefName, existingObjectDefs)
+			}
 			return ensureNoRelationshipsExist(ctx, rwt, nsdefName)
 		}); err != nil {
 			return nil, err
@@ -255,28 +279,35 @@ func sanityCheckCaveatChanges(
 	for _, delta := range diff.Deltas() {
 		switch delta.Type {
 		case caveatdiff.RemovedParameter:
-			return diff, NewSchemaWriteDataValidationError("cannot remove parameter `%s` on caveat `%s`", delta.ParameterName, caveatDef.Name)
+			return diff, NewSchemaWriteDataValidationErrorWithDetails(
+				map[string]string{
+					"caveat_name":    caveatDef.Name,
+					"parameter_name": delta.ParameterName,
+				},
+				"cannot remove parameter `%s` on caveat `%s`", delta.ParameterName, caveatDef.Name)
 
 		case caveatdiff.ParameterTypeChanged:
-			return diff, NewSchemaWriteDataValidationError("cannot change the type of parameter `%s` on caveat `%s`", delta.ParameterName, caveatDef.Name)
+			return diff, NewSchemaWriteDataValidationErrorWithDetails(
+				map[string]string{
+					"caveat_name":    caveatDef.Name,
+					"parameter_name": delta.ParameterName,
+				},
+				"cannot change the type of parameter `%s` on caveat `%s`", delta.ParameterName, caveatDef.Name)
 		}
 	}
 
 	return diff, nil
 }
 
-// ensureNoRelationshipsExist ensures that no relationships exist within the namespace with the given name.
+// ensureNoRelationshipsExist ensures that no relationships exist within the namespace with the given
+// name, or reference it, returning an error with the number of those which do otherwise.
 func ensureNoRelationshipsExist(ctx context.Context, rwt datastore.ReadWriteTransaction, namespaceName string) error {
-	qy, qyErr := rwt.QueryRelationships(
-		ctx,
-		datastore.RelationshipsFilter{ResourceType: namespaceName},
-		options.WithLimit(options.LimitOne),
-	)
-	if err := errorIfTupleIteratorReturnsTuples(
-		ctx,
+	qy, qyErr := rwt.QueryRelationships(ctx, datastore.RelationshipsFilter{ResourceType: namespaceName})
+	if err := errorIfTupleIteratorReturnsTuplesWithCount(
 		qy,
 		qyErr,
-		"cannot delete object definition `%s`, as a relationship exists under it",
+		map[string]string{"definition_name": namespaceName},
+		"cannot delete object definition `%s`, as %d relationship(s) exist under it",
 		namespaceName,
 	); err != nil {
 		return err
@@ -284,19 +315,33 @@ func ensureNoRelationshipsExist(ctx context.Context, rwt datastore.ReadWriteTran
 
 	qy, qyErr = rwt.ReverseQueryRelationships(ctx, datastore.SubjectsFilter{
 		SubjectType: namespaceName,
-	}, options.WithLimitForReverse(options.LimitOne))
-	err := errorIfTupleIteratorReturnsTuples(
-		ctx,
+	})
+	return errorIfTupleIteratorReturnsTuplesWithCount(
 		qy,
 		qyErr,
-		"cannot delete object definition `%s`, as a relationship references it",
+		map[string]string{"definition_name": namespaceName},
+		"cannot delete object definition `%s`, as %d relationship(s) reference it",
 		namespaceName,
 	)
-	qy.Close()
-	if err != nil {
+}
+
+// deleteRelationshipsOfDefinition deletes the relationships within the namespace with the given
+// name, along with those of the existing definitions which reference it.
+func deleteRelationshipsOfDefinition(ctx context.Context, rwt datastore.ReadWriteTransaction, namespaceName string, existingObjectDefs []*core.NamespaceDefinition) error {
+	if _, err := rwt.DeleteRelationships(ctx, &v1.RelationshipFilter{ResourceType: namespaceName}); err != nil {
 		return err
 	}
 
+	for _, existingDef := range existingObjectDefs {
+		if _, err := rwt.DeleteRelationships(ctx, &v1.RelationshipFilter{
+			ResourceType:          existingDef.Name,
+			OptionalSubjectFilter: &v1.SubjectFilter{SubjectType: namespaceName},
+		}); err != nil {
+			return err
+		}
+	}
+
+	log.Ctx(ctx).Info().Str("definition", namespaceName).Msg("deleted relationships of removed object definition")
 	return nil
 }
 
@@ -308,6 +353,7 @@ func sanityCheckNamespaceChanges(
 	rwt datastore.ReadWriteTransaction,
 	nsdef *core.NamespaceDefinition,
 	existingDefs map[string]*core.NamespaceDefinition,
+	deletedDefNames *mapz.Set[string],
 ) (*nsdiff.Diff, error) {
 	// Ensure that the updated namespace does not break the existing tuple data.
 	existing := existingDefs[nsdef.Name]
@@ -322,12 +368,16 @@ func sanityCheckNamespaceChanges(
 			qy, qyErr := rwt.QueryRelationships(ctx, datastore.RelationshipsFilter{
 				ResourceType:             nsdef.Name,
 				OptionalResourceRelation: delta.RelationName,
-			})
+			}, options.WithLimit(options.LimitOne))
 
 			err = errorIfTupleIteratorReturnsTuples(
 				ctx,
 				qy,
 				qyErr,
+				map[string]string{
+					"definition_name": nsdef.Name,
+					"relation_name":   delta.RelationName,
+				},
 				"cannot delete relation `%s` in object definition `%s`, as a relationship exists under it", delta.RelationName, nsdef.Name)
 			if err != nil {
 				return diff, err
@@ -344,6 +394,10 @@ func sanityCheckNamespaceChanges(
 				ctx,
 				qy,
 				qyErr,
+				map[string]string{
+					"definition_name": nsdef.Name,
+					"relation_name":   delta.RelationName,
+				},
 				"cannot delete relation `%s` in object definition `%s`, as a relationship references it", delta.RelationName, nsdef.Name)
 			qy.Close()
 			if err != nil {
@@ -351,6 +405,11 @@ func sanityCheckNamespaceChanges(
 			}
 
 		case nsdiff.RelationAllowedTypeRemoved:
+			// Relationships with subjects of definitions being removed are deleted along with them.
+			if deletedDefNames.Has(delta.AllowedType.Namespace) {
+				continue
+			}
+
 			var optionalSubjectIds []string
 			var relationFilter datastore.SubjectRelationFilter
 			optionalCaveatName := ""
@@ -387,6 +446,11 @@ func sanityCheckNamespaceChanges(
 				ctx,
 				qyr,
 				qyrErr,
+				map[string]string{
+					"definition_name": nsdef.Name,
+					"relation_name":   delta.RelationName,
+					"allowed_type":    typesystem.SourceForAllowedRelation(delta.AllowedType),
+				},
 				"cannot remove allowed type `%s` from relation `%s` in object definition `%s`, as a relationship exists with it",
 				typesystem.SourceForAllowedRelation(delta.AllowedType), delta.RelationName, nsdef.Name)
 			qyr.Close()
@@ -398,9 +462,38 @@ func sanityCheckNamespaceChanges(
 	return diff, nil
 }
 
+// errorIfTupleIteratorReturnsTuplesWithCount is errorIfTupleIteratorReturnsTuples, but also counts
+// the tuples of the iterator, which are included in the message after the arguments given, and in
+// the error's details.
+func errorIfTupleIteratorReturnsTuplesWithCount(qy datastore.RelationshipIterator, qyErr error, details map[string]string, message string, args ...interface{}) error {
+	if qyErr != nil {
+		return qyErr
+	}
+	defer qy.Close()
+
+	count := 0
+	for rt := qy.Next(); rt != nil; rt = qy.Next() {
+		if count == 0 {
+			details["relationship"] = tuple.StringWithoutCaveat(rt)
+		}
+		count++
+	}
+	if qy.Err() != nil {
+		return qy.Err()
+	}
+
+	if count == 0 {
+		return nil
+	}
+
+	details["relationship_count"] = strconv.Itoa(count)
+	return NewSchemaWriteDataValidationErrorWithDetails(details, message, append(args, count)...)
+}
+
 // errorIfTupleIteratorReturnsTuples takes a tuple iterator and any error that was generated
 // when the original iterator was created, and returns an error if iterator contains any tuples.
-func errorIfTupleIteratorReturnsTuples(_ context.Context, qy datastore.RelationshipIterator, qyErr error, message string, args ...interface{}) error {
+// The first tuple found is included in the error's details, alongside those given.
+func errorIfTupleIteratorReturnsTuples(_ context.Context, qy datastore.RelationshipIterator, qyErr error, details map[string]string, message string, args ...interface{}) error {
 	if qyErr != nil {
 		return qyErr
 	}
@@ -411,7 +504,8 @@ func errorIfTupleIteratorReturnsTuples(_ context.Context, qy datastore.Relations
 			return qy.Err()
 		}
 
-		return NewSchemaWriteDataValidationError(message, args...)
+		details["relationship"] = tuple.StringWithoutCaveat(rt)
+		return NewSchemaWriteDataValidationErrorWithDetails(details, message, args...)
 	}
 	return nil
 }
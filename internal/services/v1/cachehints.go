@@ -0,0 +1,53 @@
+package v1
+
+import (
+	"context"
+	"time"
+
+	"github.com/authzed/authzed-go/pkg/responsemeta"
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+
+	"github.com/authzed/spicedb/internal/datastore/revisions"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+const (
+	// CacheRevisionTrailerKey is the response trailer holding the ZedToken of the revision at
+	// which a check with minimal latency was evaluated, returned along with CacheMaxAgeTrailerKey.
+	CacheRevisionTrailerKey responsemeta.ResponseMetadataTrailerKey = "io.spicedb.respmeta.cacherevision"
+
+	// CacheMaxAgeTrailerKey is the response trailer holding how much longer the revision at
+	// which a check with minimal latency was evaluated remains the revision at which such checks
+	// are evaluated. Until then, a client may reuse the result for identical checks with minimal
+	// latency, which would be answered the same.
+	CacheMaxAgeTrailerKey responsemeta.ResponseMetadataTrailerKey = "io.spicedb.respmeta.cachemaxage"
+)
+
+// setCacheHints returns the cacheability of the result of a check evaluated at the revision,
+// if it was evaluated with minimal latency at the current optimized revision of the datastore.
+func setCacheHints(ctx context.Context, consistency *v1.Consistency, atRevision datastore.Revision, checkedAt *v1.ZedToken) error {
+	if consistency != nil && !consistency.GetMinimizeLatency() {
+		return nil
+	}
+
+	validity := datastore.UnwrapAs[revisions.OptimizedRevisionValidity](datastoremw.MustFromContext(ctx))
+	if validity == nil {
+		return nil
+	}
+
+	validThrough, ok := validity.OptimizedRevisionValidThrough(atRevision)
+	if !ok {
+		return nil
+	}
+
+	maxAge := time.Until(validThrough).Truncate(time.Millisecond)
+	if maxAge <= 0 {
+		return nil
+	}
+
+	return responsemeta.SetResponseTrailerMetadata(ctx, map[responsemeta.ResponseMetadataTrailerKey]string{
+		CacheRevisionTrailerKey: checkedAt.Token,
+		CacheMaxAgeTrailerKey:   maxAge.String(),
+	})
+}
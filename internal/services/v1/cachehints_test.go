@@ -0,0 +1,58 @@
+package v1_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/authzed/authzed-go/pkg/responsemeta"
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	v1svc "github.com/authzed/spicedb/internal/services/v1"
+	tf "github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+)
+
+func TestCheckCacheHints(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), time.Hour, memdb.DisableGC, true, tf.StandardDatastoreWithData)
+	client := v1.NewPermissionsServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	check := func(consistency *v1.Consistency) (*v1.CheckPermissionResponse, metadata.MD) {
+		var trailer metadata.MD
+		resp, err := client.CheckPermission(context.Background(), &v1.CheckPermissionRequest{
+			Consistency: consistency,
+			Resource:    obj("document", "masterplan"),
+			Permission:  "view",
+			Subject:     sub("user", "eng_lead", ""),
+		}, grpc.Trailer(&trailer))
+		require.NoError(t, err)
+		return resp, trailer
+	}
+
+	// Checks with minimal latency are evaluated at the optimized revision, until the end of
+	// its quantization window.
+	for _, consistency := range []*v1.Consistency{nil, {Requirement: &v1.Consistency_MinimizeLatency{MinimizeLatency: true}}} {
+		resp, trailer := check(consistency)
+
+		revision, err := responsemeta.GetResponseTrailerMetadata(trailer, v1svc.CacheRevisionTrailerKey)
+		require.NoError(t, err)
+		require.Equal(t, resp.CheckedAt.Token, revision)
+
+		encoded, err := responsemeta.GetResponseTrailerMetadata(trailer, v1svc.CacheMaxAgeTrailerKey)
+		require.NoError(t, err)
+		maxAge, err := time.ParseDuration(encoded)
+		require.NoError(t, err)
+		require.Greater(t, maxAge, time.Duration(0))
+		require.LessOrEqual(t, maxAge, time.Hour)
+	}
+
+	// Fully consistent checks are not shared.
+	_, trailer := check(fullyConsistent)
+	require.Empty(t, trailer.Get(string(v1svc.CacheMaxAgeTrailerKey)))
+	require.Empty(t, trailer.Get(string(v1svc.CacheRevisionTrailerKey)))
+}
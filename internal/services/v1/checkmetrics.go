@@ -0,0 +1,69 @@
+package v1
+
+import (
+	"strconv"
+
+	"github.com/authzed/authzed-go/pkg/responsemeta"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/authzed/spicedb/internal/graph"
+	dispatch "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+)
+
+const (
+	// DepthRequiredTrailerKey is the response trailer holding the depth of the dispatched
+	// subproblems required to resolve a check, returned along with its debug information.
+	DepthRequiredTrailerKey responsemeta.ResponseMetadataTrailerKey = "io.spicedb.respmeta.depthrequired"
+
+	// RelationshipsScannedTrailerKey is the response trailer holding the number of
+	// relationships read to resolve a check, returned along with its debug information.
+	RelationshipsScannedTrailerKey responsemeta.ResponseMetadataTrailerKey = "io.spicedb.respmeta.relationshipsscanned"
+)
+
+var (
+	checkResolutionDepth = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "spicedb",
+		Subsystem: "v1",
+		Name:      "check_resolution_depth",
+		Help:      "Depth of the dispatched subproblems required to resolve a check request, by method.",
+		Buckets:   []float64{1, 2, 3, 5, 8, 13, 21, 34, 50},
+	}, []string{"method"})
+
+	checkRelationshipsScanned = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "spicedb",
+		Subsystem: "v1",
+		Name:      "check_relationships_scanned",
+		Help:      "Number of relationships read by this node to resolve a check request, by method.",
+		Buckets:   []float64{0, 1, 5, 10, 50, 100, 500, 1000, 5000, 10000},
+	}, []string{"method"})
+
+	checkCacheHitRatio = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "spicedb",
+		Subsystem: "v1",
+		Name:      "check_cache_hit_ratio",
+		Help:      "Fraction of the subproblems of a check request answered from the dispatch cache, by method.",
+		Buckets:   []float64{0, .1, .2, .3, .4, .5, .6, .7, .8, .9, 1},
+	}, []string{"method"})
+)
+
+// recordCheckResolution records the metrics of the resolution of a check request. The number
+// of subproblems dispatched is recorded for every method by the usage metrics.
+func recordCheckResolution(method string, metadata *dispatch.ResponseMeta, stats *graph.CheckStats) {
+	if metadata != nil {
+		checkResolutionDepth.WithLabelValues(method).Observe(float64(metadata.DepthRequired))
+		if total := metadata.DispatchCount + metadata.CachedDispatchCount; total > 0 {
+			checkCacheHitRatio.WithLabelValues(method).Observe(float64(metadata.CachedDispatchCount) / float64(total))
+		}
+	}
+	checkRelationshipsScanned.WithLabelValues(method).Observe(float64(stats.RelationshipsScanned()))
+}
+
+// checkResolutionTrailer returns the statistics of the resolution of a check request, to be
+// returned along with its debug information.
+func checkResolutionTrailer(metadata *dispatch.ResponseMeta, stats *graph.CheckStats) map[responsemeta.ResponseMetadataTrailerKey]string {
+	return map[responsemeta.ResponseMetadataTrailerKey]string{
+		DepthRequiredTrailerKey:        strconv.FormatUint(uint64(metadata.DepthRequired), 10),
+		RelationshipsScannedTrailerKey: strconv.FormatUint(stats.RelationshipsScanned(), 10),
+	}
+}
@@ -0,0 +1,185 @@
+package v1
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/dispatch"
+	"github.com/authzed/spicedb/internal/dispatch/keys"
+	"github.com/authzed/spicedb/pkg/datastore"
+	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+)
+
+// CheckSessionHeaderKey is the request metadata key holding the ID of a check session. The
+// CheckPermission calls made over a connection with the same session ID are evaluated at the
+// revision chosen for the first of them, and share the results of their dispatches, so that
+// the many checks made to serve a single page, for example, are consistent with each other
+// and do not recompute the subproblems they have in common. Calls after the first may only use
+// the default, minimize latency, consistency. A session ends once it has not been used for the
+// configured idle timeout.
+const CheckSessionHeaderKey = "io.spicedb.checksession"
+
+const (
+	// maxCheckSessions is the maximum number of check sessions held at once; further sessions
+	// are rejected until idle ones expire.
+	maxCheckSessions = 10_000
+
+	// maxCheckSessionResults is the maximum number of dispatch results memoized by a session;
+	// once reached, further results are not memoized.
+	maxCheckSessionResults = 10_000
+)
+
+type checkSessionKey struct {
+	peer string
+	id   string
+}
+
+// checkSessions holds the check sessions of a server.
+type checkSessions struct {
+	idleTimeout time.Duration
+
+	sync.Mutex
+	sessions    map[checkSessionKey]*checkSession
+	lastExpired time.Time
+}
+
+func newCheckSessions(idleTimeout time.Duration) *checkSessions {
+	return &checkSessions{
+		idleTimeout: idleTimeout,
+		sessions:    make(map[checkSessionKey]*checkSession),
+	}
+}
+
+// checkSession is the revision and memoized dispatch results of a check session.
+type checkSession struct {
+	revision   datastore.Revision
+	dispatcher *memoizingCheckDispatcher
+	lastUsed   time.Time
+}
+
+// checkSession returns the session named by the CheckSessionHeaderKey header of the request,
+// starting it at the revision chosen for the request if it does not exist, or nil if the
+// request is not part of a session.
+func (ps *permissionServer) checkSession(ctx context.Context, req *v1.CheckPermissionRequest, atRevision datastore.Revision) (*checkSession, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, nil
+	}
+	ids := md.Get(CheckSessionHeaderKey)
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	if ps.checkSessions == nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "check sessions are not enabled on this server")
+	}
+	if ids[0] == "" {
+		return nil, status.Errorf(codes.InvalidArgument, "%s must not be empty", CheckSessionHeaderKey)
+	}
+
+	key := checkSessionKey{id: ids[0]}
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		key.peer = p.Addr.String()
+	}
+	return ps.checkSessions.get(key, req.Consistency, atRevision, ps.dispatch)
+}
+
+func (cs *checkSessions) get(key checkSessionKey, consistency *v1.Consistency, atRevision datastore.Revision, d dispatch.Check) (*checkSession, error) {
+	cs.Lock()
+	defer cs.Unlock()
+
+	now := time.Now()
+	if session, ok := cs.sessions[key]; ok && now.Sub(session.lastUsed) < cs.idleTimeout {
+		if consistency != nil && !consistency.GetMinimizeLatency() {
+			return nil, status.Errorf(codes.InvalidArgument, "only the first check of a session may specify a consistency other than minimize latency")
+		}
+		session.lastUsed = now
+		return session, nil
+	}
+
+	cs.expire(now)
+	if len(cs.sessions) >= maxCheckSessions {
+		return nil, status.Errorf(codes.ResourceExhausted, "too many check sessions are open")
+	}
+
+	session := &checkSession{
+		revision:   atRevision,
+		dispatcher: newMemoizingCheckDispatcher(d),
+		lastUsed:   now,
+	}
+	cs.sessions[key] = session
+	return session, nil
+}
+
+// expire removes the sessions which have been idle for longer than the timeout, at most once
+// per timeout.
+func (cs *checkSessions) expire(now time.Time) {
+	if now.Sub(cs.lastExpired) < cs.idleTimeout {
+		return
+	}
+	cs.lastExpired = now
+
+	for key, session := range cs.sessions {
+		if now.Sub(session.lastUsed) >= cs.idleTimeout {
+			delete(cs.sessions, key)
+		}
+	}
+}
+
+// memoizingCheckDispatcher memoizes the results of the check dispatches of a session, which
+// are all at the same revision.
+type memoizingCheckDispatcher struct {
+	delegate   dispatch.Check
+	keyHandler keys.DirectKeyHandler
+
+	sync.Mutex
+	results map[keys.DispatchCacheKey]*dispatchv1.DispatchCheckResponse
+}
+
+func newMemoizingCheckDispatcher(delegate dispatch.Check) *memoizingCheckDispatcher {
+	return &memoizingCheckDispatcher{
+		delegate: delegate,
+		results:  make(map[keys.DispatchCacheKey]*dispatchv1.DispatchCheckResponse),
+	}
+}
+
+func (md *memoizingCheckDispatcher) DispatchCheck(ctx context.Context, req *dispatchv1.DispatchCheckRequest) (*dispatchv1.DispatchCheckResponse, error) {
+	if req.Debug != dispatchv1.DispatchCheckRequest_NO_DEBUG {
+		return md.delegate.DispatchCheck(ctx, req)
+	}
+
+	key, err := md.keyHandler.CheckCacheKey(ctx, req)
+	if err != nil {
+		return &dispatchv1.DispatchCheckResponse{Metadata: &dispatchv1.ResponseMeta{}}, err
+	}
+
+	md.Lock()
+	memoized, ok := md.results[key]
+	md.Unlock()
+	if ok && req.Metadata.DepthRemaining >= memoized.Metadata.DepthRequired {
+		return memoized.CloneVT(), nil
+	}
+
+	resp, err := md.delegate.DispatchCheck(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+
+	memoized = resp.CloneVT()
+	memoized.Metadata.CachedDispatchCount = memoized.Metadata.DispatchCount
+	memoized.Metadata.DispatchCount = 0
+	memoized.Metadata.DebugInfo = nil
+
+	md.Lock()
+	if len(md.results) < maxCheckSessionResults {
+		md.results[key] = memoized
+	}
+	md.Unlock()
+	return resp, nil
+}
@@ -0,0 +1,96 @@
+package v1
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/datastore/revisions"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+)
+
+type countingCheckDispatcher struct {
+	calls atomic.Int32
+}
+
+func (d *countingCheckDispatcher) DispatchCheck(_ context.Context, req *dispatchv1.DispatchCheckRequest) (*dispatchv1.DispatchCheckResponse, error) {
+	d.calls.Add(1)
+	return &dispatchv1.DispatchCheckResponse{
+		Metadata: &dispatchv1.ResponseMeta{DispatchCount: 3, DepthRequired: 2},
+		ResultsByResourceId: map[string]*dispatchv1.ResourceCheckResult{
+			req.ResourceIds[0]: {Membership: dispatchv1.ResourceCheckResult_MEMBER},
+		},
+	}, nil
+}
+
+func checkRequest(resourceID string, depthRemaining uint32) *dispatchv1.DispatchCheckRequest {
+	return &dispatchv1.DispatchCheckRequest{
+		ResourceRelation: &core.RelationReference{Namespace: "document", Relation: "view"},
+		ResourceIds:      []string{resourceID},
+		Subject:          &core.ObjectAndRelation{Namespace: "user", ObjectId: "tom", Relation: "..."},
+		Metadata:         &dispatchv1.ResolverMeta{AtRevision: "1", DepthRemaining: depthRemaining},
+	}
+}
+
+func TestMemoizingCheckDispatcher(t *testing.T) {
+	delegate := &countingCheckDispatcher{}
+	md := newMemoizingCheckDispatcher(delegate)
+
+	resp, err := md.DispatchCheck(context.Background(), checkRequest("masterplan", 50))
+	require.NoError(t, err)
+	require.Equal(t, uint32(3), resp.Metadata.DispatchCount)
+
+	// The same check is answered from the session, and counted as cached.
+	resp, err = md.DispatchCheck(context.Background(), checkRequest("masterplan", 50))
+	require.NoError(t, err)
+	require.Equal(t, int32(1), delegate.calls.Load())
+	require.Zero(t, resp.Metadata.DispatchCount)
+	require.Equal(t, uint32(3), resp.Metadata.CachedDispatchCount)
+	require.Equal(t, dispatchv1.ResourceCheckResult_MEMBER, resp.ResultsByResourceId["masterplan"].Membership)
+
+	// Other checks, and those with less depth remaining than the result required, are dispatched.
+	_, err = md.DispatchCheck(context.Background(), checkRequest("otherplan", 50))
+	require.NoError(t, err)
+	_, err = md.DispatchCheck(context.Background(), checkRequest("masterplan", 1))
+	require.NoError(t, err)
+	require.Equal(t, int32(3), delegate.calls.Load())
+}
+
+func TestCheckSessions(t *testing.T) {
+	sessions := newCheckSessions(time.Minute)
+	delegate := &countingCheckDispatcher{}
+	key := checkSessionKey{peer: "127.0.0.1:1234", id: "page"}
+	first := revisions.NewForTransactionID(1)
+	second := revisions.NewForTransactionID(2)
+
+	session, err := sessions.get(key, nil, first, delegate)
+	require.NoError(t, err)
+	require.Equal(t, first, session.revision)
+
+	// Later checks in the session share its revision and dispatcher.
+	again, err := sessions.get(key, &v1.Consistency{Requirement: &v1.Consistency_MinimizeLatency{MinimizeLatency: true}}, second, delegate)
+	require.NoError(t, err)
+	require.Same(t, session, again)
+
+	_, err = sessions.get(key, &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}}, second, delegate)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// Sessions are scoped to the connection.
+	other, err := sessions.get(checkSessionKey{peer: "127.0.0.1:5678", id: "page"}, nil, second, delegate)
+	require.NoError(t, err)
+	require.Equal(t, second, other.revision)
+
+	// An idle session ends, and is started again by its next check.
+	session.lastUsed = time.Now().Add(-2 * time.Minute)
+	restarted, err := sessions.get(key, nil, second, delegate)
+	require.NoError(t, err)
+	require.NotSame(t, session, restarted)
+	require.Equal(t, second, restarted.revision)
+}
@@ -0,0 +1,55 @@
+package v1
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/authzed/authzed-go/pkg/responsemeta"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// CountOnlyHeaderKey is the request metadata key which, when `true`, makes ReadRelationships
+	// and LookupResources count their results rather than streaming them. No responses are sent;
+	// the count is returned in the CountTrailerKey response trailer.
+	//
+	// Counting relationships reads each matching relationship from the datastore, but does not
+	// send them. Counting resources costs as much as the full LookupResources call: every
+	// accessible resource is computed, and its ID is held in memory to remove duplicates.
+	CountOnlyHeaderKey = "io.spicedb.countonly"
+
+	// CountTrailerKey is the key in the response trailer metadata holding the number of results
+	// counted, when requested via the CountOnlyHeaderKey header.
+	CountTrailerKey responsemeta.ResponseMetadataTrailerKey = "io.spicedb.respmeta.count"
+
+	// ConditionalCountTrailerKey is the key in the response trailer metadata holding the number of
+	// resources counted by LookupResources on which the subject only conditionally has the
+	// permission, because of missing caveat context. These are included in CountTrailerKey.
+	ConditionalCountTrailerKey responsemeta.ResponseMetadataTrailerKey = "io.spicedb.respmeta.conditionalcount"
+)
+
+// countOnly returns whether results were requested to be counted rather than streamed, via the
+// CountOnlyHeaderKey header.
+func countOnly(ctx context.Context) (bool, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(CountOnlyHeaderKey); len(values) > 0 {
+			countOnly, err := strconv.ParseBool(values[0])
+			if err != nil {
+				return false, status.Errorf(codes.InvalidArgument, "invalid value for %s: %s", CountOnlyHeaderKey, err)
+			}
+			return countOnly, nil
+		}
+	}
+	return false, nil
+}
+
+// countOnlyWithPagination returns an error if a count was requested along with a limit or
+// cursor, as counts are always of all the results.
+func countOnlyWithPagination(hasLimit bool, hasCursor bool) error {
+	if hasLimit || hasCursor {
+		return status.Errorf(codes.InvalidArgument, "a limit or cursor cannot be given when counting results via %s", CountOnlyHeaderKey)
+	}
+	return nil
+}
@@ -0,0 +1,166 @@
+package v1_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/authzed/authzed-go/pkg/responsemeta"
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/authzed/grpcutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	v1svc "github.com/authzed/spicedb/internal/services/v1"
+	tf "github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+)
+
+var fullyConsistent = &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}}
+
+// receiveAll receives the responses of the stream until it ends, returning their number and its trailer.
+func receiveAll(t *testing.T, stream grpc.ClientStream, newResponse func() any) (int, metadata.MD) {
+	count := 0
+	for {
+		err := stream.RecvMsg(newResponse())
+		if errors.Is(err, io.EOF) {
+			return count, stream.Trailer()
+		}
+		require.NoError(t, err)
+		count++
+	}
+}
+
+func requireCountTrailer(t *testing.T, trailer metadata.MD, key responsemeta.ResponseMetadataTrailerKey, expected int) {
+	count, err := responsemeta.GetIntResponseTrailerMetadata(trailer, key)
+	require.NoError(t, err)
+	require.Equal(t, expected, count, "trailer %s", key)
+}
+
+func TestCountRelationships(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.StandardDatastoreWithData)
+	client := v1.NewPermissionsServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	for _, filter := range []*v1.RelationshipFilter{
+		{ResourceType: "document"},
+		{ResourceType: "document", OptionalRelation: "viewer"},
+		{ResourceType: "folder", OptionalSubjectFilter: &v1.SubjectFilter{SubjectType: "user"}},
+		{ResourceType: "document", OptionalResourceId: "doesnotexist"},
+	} {
+		filter := filter
+		t.Run(filter.String(), func(t *testing.T) {
+			req := &v1.ReadRelationshipsRequest{Consistency: fullyConsistent, RelationshipFilter: filter}
+
+			stream, err := client.ReadRelationships(context.Background(), req)
+			require.NoError(t, err)
+			expected, _ := receiveAll(t, stream, func() any { return &v1.ReadRelationshipsResponse{} })
+
+			ctx := metadata.AppendToOutgoingContext(context.Background(), v1svc.CountOnlyHeaderKey, "true")
+			stream, err = client.ReadRelationships(ctx, req)
+			require.NoError(t, err)
+			sent, trailer := receiveAll(t, stream, func() any { return &v1.ReadRelationshipsResponse{} })
+			require.Zero(t, sent)
+			requireCountTrailer(t, trailer, v1svc.CountTrailerKey, expected)
+		})
+	}
+}
+
+func TestCountAccessibleResources(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.StandardDatastoreWithCaveatedData)
+	client := v1.NewPermissionsServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	// Every relationship is caveated, so without context each resource is conditionally accessible.
+	for _, tc := range []struct {
+		subjectID     string
+		expectedCount int
+	}{
+		{"owner", 3},
+		{"eng_lead", 1},
+		{"villain", 0},
+		{"nobody", 0},
+	} {
+		tc := tc
+		t.Run(tc.subjectID, func(t *testing.T) {
+			req := &v1.LookupResourcesRequest{
+				Consistency:        fullyConsistent,
+				ResourceObjectType: "document",
+				Permission:         "view",
+				Subject:            &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: tc.subjectID}},
+			}
+
+			stream, err := client.LookupResources(context.Background(), req)
+			require.NoError(t, err)
+
+			resources := map[string]v1.LookupPermissionship{}
+			for {
+				resp, err := stream.Recv()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				require.NoError(t, err)
+				if resources[resp.ResourceObjectId] != v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_HAS_PERMISSION {
+					resources[resp.ResourceObjectId] = resp.Permissionship
+				}
+			}
+
+			expectedConditional := 0
+			for _, permissionship := range resources {
+				if permissionship == v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_CONDITIONAL_PERMISSION {
+					expectedConditional++
+				}
+			}
+			require.Len(t, resources, tc.expectedCount)
+			require.Equal(t, tc.expectedCount, expectedConditional)
+
+			ctx := metadata.AppendToOutgoingContext(context.Background(), v1svc.CountOnlyHeaderKey, "true")
+			stream, err = client.LookupResources(ctx, req)
+			require.NoError(t, err)
+			sent, trailer := receiveAll(t, stream, func() any { return &v1.LookupResourcesResponse{} })
+			require.Zero(t, sent)
+			requireCountTrailer(t, trailer, v1svc.CountTrailerKey, len(resources))
+			requireCountTrailer(t, trailer, v1svc.ConditionalCountTrailerKey, expectedConditional)
+		})
+	}
+}
+
+func TestCountOnlyRejectsPagination(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.StandardDatastoreWithData)
+	client := v1.NewPermissionsServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), v1svc.CountOnlyHeaderKey, "true")
+	stream, err := client.ReadRelationships(ctx, &v1.ReadRelationshipsRequest{
+		Consistency:        fullyConsistent,
+		RelationshipFilter: &v1.RelationshipFilter{ResourceType: "document"},
+		OptionalLimit:      10,
+	})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+
+	lrStream, err := client.LookupResources(ctx, &v1.LookupResourcesRequest{
+		Consistency:        fullyConsistent,
+		ResourceObjectType: "document",
+		Permission:         "view",
+		Subject:            &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "owner"}},
+		OptionalLimit:      10,
+	})
+	require.NoError(t, err)
+	_, err = lrStream.Recv()
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+
+	invalidCtx := metadata.AppendToOutgoingContext(context.Background(), v1svc.CountOnlyHeaderKey, "maybe")
+	stream, err = client.ReadRelationships(invalidCtx, &v1.ReadRelationshipsRequest{
+		Consistency:        fullyConsistent,
+		RelationshipFilter: &v1.RelationshipFilter{ResourceType: "document"},
+	})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+}
@@ -0,0 +1,151 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/authzed/authzed-go/pkg/responsemeta"
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/services/shared"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+)
+
+const (
+	// DryRunHeaderKey is the request metadata key which, when `true`, makes WriteRelationships,
+	// DeleteRelationships and WriteSchema run all of their validation, including preconditions and
+	// schema safety checks, in a transaction which is then rolled back rather than committed.
+	// Nothing is written; the changes which would have been made are returned in the response
+	// trailer, and the returned ZedToken is that of the head revision.
+	DryRunHeaderKey = "io.spicedb.dryrun"
+
+	// DryRunRelationshipsWrittenTrailerKey is the key in the response trailer metadata holding the
+	// number of relationships which would have been created or touched by a dry run write.
+	DryRunRelationshipsWrittenTrailerKey responsemeta.ResponseMetadataTrailerKey = "io.spicedb.respmeta.dryrunrelationshipswritten"
+
+	// DryRunRelationshipsDeletedTrailerKey is the key in the response trailer metadata holding the
+	// number of existing relationships which would have been deleted by a dry run write or delete.
+	DryRunRelationshipsDeletedTrailerKey responsemeta.ResponseMetadataTrailerKey = "io.spicedb.respmeta.dryrunrelationshipsdeleted"
+
+	// DryRunDefinitionsAddedTrailerKey is the key in the response trailer metadata holding the
+	// comma-separated names of the object and caveat definitions which would have been added by a
+	// dry run schema write.
+	DryRunDefinitionsAddedTrailerKey responsemeta.ResponseMetadataTrailerKey = "io.spicedb.respmeta.dryrundefinitionsadded"
+
+	// DryRunDefinitionsRemovedTrailerKey is the key in the response trailer metadata holding the
+	// comma-separated names of the object and caveat definitions which would have been removed by
+	// a dry run schema write.
+	DryRunDefinitionsRemovedTrailerKey responsemeta.ResponseMetadataTrailerKey = "io.spicedb.respmeta.dryrundefinitionsremoved"
+)
+
+// errDryRun is returned from the transaction of a dry run to roll it back once it has succeeded.
+var errDryRun = errors.New("dry run")
+
+// dryRun returns whether the write was requested to be validated but not committed, via the
+// DryRunHeaderKey header.
+func dryRun(ctx context.Context) (bool, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(DryRunHeaderKey); len(values) > 0 {
+			dryRun, err := strconv.ParseBool(values[0])
+			if err != nil {
+				return false, status.Errorf(codes.InvalidArgument, "invalid value for %s: %s", DryRunHeaderKey, err)
+			}
+			return dryRun, nil
+		}
+	}
+	return false, nil
+}
+
+// dryRunReadWriteTx runs the function in a read-write transaction which is always rolled back,
+// returning the head revision if the function succeeded.
+func dryRunReadWriteTx(ctx context.Context, ds datastore.Datastore, fn datastore.TxUserFunc) (datastore.Revision, error) {
+	_, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		if err := fn(ctx, rwt); err != nil {
+			return err
+		}
+		return errDryRun
+	})
+	if !errors.Is(err, errDryRun) {
+		return nil, err
+	}
+
+	return ds.HeadRevision(ctx)
+}
+
+// countRelationshipUpdates returns the number of relationships the updates would create or touch,
+// and the number of existing relationships they would delete.
+func countRelationshipUpdates(ctx context.Context, rwt datastore.ReadWriteTransaction, updates []*v1.RelationshipUpdate) (written uint64, deleted uint64, err error) {
+	for _, update := range updates {
+		if update.Operation != v1.RelationshipUpdate_OPERATION_DELETE {
+			written++
+			continue
+		}
+
+		exists, err := relationshipExists(ctx, rwt, update.Relationship)
+		if err != nil {
+			return 0, 0, err
+		}
+		if exists {
+			deleted++
+		}
+	}
+	return written, deleted, nil
+}
+
+// countFilteredRelationships returns the number of relationships matching the filter, up to the
+// limit if one is given.
+func countFilteredRelationships(ctx context.Context, rwt datastore.ReadWriteTransaction, filter datastore.RelationshipsFilter, limit uint32) (uint64, error) {
+	var opts []options.QueryOptionsOption
+	if limit > 0 {
+		queryLimit := uint64(limit)
+		opts = append(opts, options.WithLimit(&queryLimit))
+	}
+
+	iter, err := rwt.QueryRelationships(ctx, filter, opts...)
+	if err != nil {
+		return 0, err
+	}
+	defer iter.Close()
+
+	var count uint64
+	for tpl := iter.Next(); tpl != nil; tpl = iter.Next() {
+		count++
+	}
+	if err := iter.Err(); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// setDryRunRelationshipsTrailer returns the number of relationships a dry run would have written
+// and deleted in the response trailer.
+func setDryRunRelationshipsTrailer(ctx context.Context, written, deleted uint64) error {
+	return responsemeta.SetResponseTrailerMetadata(ctx, map[responsemeta.ResponseMetadataTrailerKey]string{
+		DryRunRelationshipsWrittenTrailerKey: strconv.FormatUint(written, 10),
+		DryRunRelationshipsDeletedTrailerKey: strconv.FormatUint(deleted, 10),
+	})
+}
+
+// setDryRunSchemaTrailer returns the definitions a dry run schema write would have added and
+// removed in the response trailer.
+func setDryRunSchemaTrailer(ctx context.Context, applied *shared.AppliedSchemaChanges) error {
+	return responsemeta.SetResponseTrailerMetadata(ctx, map[responsemeta.ResponseMetadataTrailerKey]string{
+		DryRunDefinitionsAddedTrailerKey:   joinSortedNames(applied.NewObjectDefNames, applied.NewCaveatDefNames),
+		DryRunDefinitionsRemovedTrailerKey: joinSortedNames(applied.RemovedObjectDefNames, applied.RemovedCaveatDefNames),
+	})
+}
+
+func joinSortedNames(objectDefNames, caveatDefNames []string) string {
+	names := make([]string, 0, len(objectDefNames)+len(caveatDefNames))
+	names = append(names, objectDefNames...)
+	names = append(names, caveatDefNames...)
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
@@ -0,0 +1,135 @@
+package v1_test
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/authzed/grpcutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	v1svc "github.com/authzed/spicedb/internal/services/v1"
+	tf "github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+var dryRunCtx = metadata.AppendToOutgoingContext(context.Background(), v1svc.DryRunHeaderKey, "true")
+
+func countDocumentRelationships(t *testing.T, client v1.PermissionsServiceClient) int {
+	stream, err := client.ReadRelationships(context.Background(), &v1.ReadRelationshipsRequest{
+		Consistency:        fullyConsistent,
+		RelationshipFilter: &v1.RelationshipFilter{ResourceType: "document"},
+	})
+	require.NoError(t, err)
+	count, _ := receiveAll(t, stream, func() any { return &v1.ReadRelationshipsResponse{} })
+	return count
+}
+
+func TestDryRunWriteRelationships(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.StandardDatastoreWithData)
+	client := v1.NewPermissionsServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	before := countDocumentRelationships(t, client)
+
+	var trailer metadata.MD
+	resp, err := client.WriteRelationships(dryRunCtx, &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{
+			tuple.UpdateToRelationshipUpdate(tuple.Create(tuple.MustParse("document:newplan#viewer@user:tom"))),
+			tuple.UpdateToRelationshipUpdate(tuple.Touch(tuple.MustParse("document:masterplan#viewer@user:eng_lead"))),
+			tuple.UpdateToRelationshipUpdate(tuple.Delete(tuple.MustParse("document:masterplan#owner@user:product_manager"))),
+			tuple.UpdateToRelationshipUpdate(tuple.Delete(tuple.MustParse("document:masterplan#owner@user:nobody"))),
+		},
+	}, grpc.Trailer(&trailer))
+	require.NoError(t, err)
+	require.NotEmpty(t, resp.WrittenAt.Token)
+	requireCountTrailer(t, trailer, v1svc.DryRunRelationshipsWrittenTrailerKey, 2)
+	requireCountTrailer(t, trailer, v1svc.DryRunRelationshipsDeletedTrailerKey, 1)
+	require.Equal(t, before, countDocumentRelationships(t, client))
+
+	// Validation still runs: creating an existing relationship fails.
+	_, err = client.WriteRelationships(dryRunCtx, &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{
+			tuple.UpdateToRelationshipUpdate(tuple.Create(tuple.MustParse("document:masterplan#viewer@user:eng_lead"))),
+		},
+	})
+	grpcutil.RequireStatus(t, codes.AlreadyExists, err)
+
+	// As do preconditions.
+	_, err = client.WriteRelationships(dryRunCtx, &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{
+			tuple.UpdateToRelationshipUpdate(tuple.Touch(tuple.MustParse("document:newplan#viewer@user:tom"))),
+		},
+		OptionalPreconditions: []*v1.Precondition{{
+			Operation: v1.Precondition_OPERATION_MUST_MATCH,
+			Filter:    &v1.RelationshipFilter{ResourceType: "document", OptionalResourceId: "newplan"},
+		}},
+	})
+	grpcutil.RequireStatus(t, codes.FailedPrecondition, err)
+
+	invalidCtx := metadata.AppendToOutgoingContext(context.Background(), v1svc.DryRunHeaderKey, "maybe")
+	_, err = client.WriteRelationships(invalidCtx, &v1.WriteRelationshipsRequest{})
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+}
+
+func TestDryRunDeleteRelationships(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.StandardDatastoreWithData)
+	client := v1.NewPermissionsServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	before := countDocumentRelationships(t, client)
+
+	var trailer metadata.MD
+	_, err := client.DeleteRelationships(dryRunCtx, &v1.DeleteRelationshipsRequest{
+		RelationshipFilter: &v1.RelationshipFilter{ResourceType: "document"},
+	}, grpc.Trailer(&trailer))
+	require.NoError(t, err)
+	requireCountTrailer(t, trailer, v1svc.DryRunRelationshipsWrittenTrailerKey, 0)
+	requireCountTrailer(t, trailer, v1svc.DryRunRelationshipsDeletedTrailerKey, before)
+	require.Equal(t, before, countDocumentRelationships(t, client))
+
+	resp, err := client.DeleteRelationships(dryRunCtx, &v1.DeleteRelationshipsRequest{
+		RelationshipFilter:            &v1.RelationshipFilter{ResourceType: "document"},
+		OptionalLimit:                 1,
+		OptionalAllowPartialDeletions: true,
+	}, grpc.Trailer(&trailer))
+	require.NoError(t, err)
+	require.Equal(t, v1.DeleteRelationshipsResponse_DELETION_PROGRESS_PARTIAL, resp.DeletionProgress)
+	requireCountTrailer(t, trailer, v1svc.DryRunRelationshipsDeletedTrailerKey, 1)
+	require.Equal(t, before, countDocumentRelationships(t, client))
+}
+
+func TestDryRunWriteSchema(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.EmptyDatastore)
+	t.Cleanup(cleanup)
+	client := v1.NewSchemaServiceClient(conn)
+
+	_, err := client.WriteSchema(context.Background(), &v1.WriteSchemaRequest{
+		Schema: "definition example/user {}\n\ndefinition example/document {\n\trelation viewer: example/user\n}",
+	})
+	require.NoError(t, err)
+
+	before, err := client.ReadSchema(context.Background(), &v1.ReadSchemaRequest{})
+	require.NoError(t, err)
+
+	var trailer metadata.MD
+	_, err = client.WriteSchema(dryRunCtx, &v1.WriteSchemaRequest{
+		Schema: "definition example/user {}\n\ndefinition example/folder {}\n\ncaveat example/only_weekdays(weekday int) {\n\tweekday < 6\n}",
+	}, grpc.Trailer(&trailer))
+	require.NoError(t, err)
+	require.Equal(t, []string{"example/folder,example/only_weekdays"}, trailer.Get(string(v1svc.DryRunDefinitionsAddedTrailerKey)))
+	require.Equal(t, []string{"example/document"}, trailer.Get(string(v1svc.DryRunDefinitionsRemovedTrailerKey)))
+
+	after, err := client.ReadSchema(context.Background(), &v1.ReadSchemaRequest{})
+	require.NoError(t, err)
+	require.Equal(t, before.SchemaText, after.SchemaText)
+
+	// Validation still runs.
+	_, err = client.WriteSchema(dryRunCtx, &v1.WriteSchemaRequest{Schema: "definition example/user {}\n\ndefinition example/document {\n\trelation viewer: example/missing\n}"})
+	grpcutil.RequireStatus(t, codes.FailedPrecondition, err)
+}
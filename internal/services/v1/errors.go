@@ -5,14 +5,17 @@ import (
 	"strconv"
 
 	"github.com/rs/zerolog"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 
+	"github.com/authzed/spicedb/pkg/datastore"
 	"github.com/authzed/spicedb/pkg/spiceerrors"
 	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/zedtoken"
 )
 
 // ErrExceedsMaximumUpdates occurs when too many updates are given to a call.
@@ -31,7 +34,7 @@ func (err ErrExceedsMaximumUpdates) MarshalZerologObject(e *zerolog.Event) {
 func (err ErrExceedsMaximumUpdates) GRPCStatus() *status.Status {
 	return spiceerrors.WithCodeAndDetails(
 		err,
-		codes.InvalidArgument,
+		codes.ResourceExhausted,
 		spiceerrors.ForReason(
 			v1.ErrorReason_ERROR_REASON_TOO_MANY_UPDATES_IN_REQUEST,
 			map[string]string{
@@ -51,6 +54,43 @@ func NewExceedsMaximumUpdatesErr(updateCount uint16, maxCountAllowed uint16) Err
 	}
 }
 
+// ErrExceedsMaximumLimit occurs when a limit greater than that allowed is given to a call.
+type ErrExceedsMaximumLimit struct {
+	error
+	providedLimit   uint32
+	maxLimitAllowed uint32
+}
+
+// MarshalZerologObject implements zerolog object marshalling.
+func (err ErrExceedsMaximumLimit) MarshalZerologObject(e *zerolog.Event) {
+	e.Err(err.error).Uint32("providedLimit", err.providedLimit).Uint32("maxLimitAllowed", err.maxLimitAllowed)
+}
+
+// GRPCStatus implements retrieving the gRPC status for the error.
+func (err ErrExceedsMaximumLimit) GRPCStatus() *status.Status {
+	return spiceerrors.WithCodeAndDetails(
+		err,
+		codes.ResourceExhausted,
+		&errdetails.ErrorInfo{
+			Reason: "ERROR_REASON_EXCEEDS_MAXIMUM_ALLOWABLE_LIMIT",
+			Domain: spiceerrors.Domain,
+			Metadata: map[string]string{
+				"limit_provided":        strconv.FormatUint(uint64(err.providedLimit), 10),
+				"maximum_limit_allowed": strconv.FormatUint(uint64(err.maxLimitAllowed), 10),
+			},
+		},
+	)
+}
+
+// NewExceedsMaximumLimitErr creates a new error representing that the limit given to a read call is too large.
+func NewExceedsMaximumLimitErr(providedLimit uint32, maxLimitAllowed uint32) ErrExceedsMaximumLimit {
+	return ErrExceedsMaximumLimit{
+		error:           fmt.Errorf("provided limit %d is greater than maximum allowed of %d", providedLimit, maxLimitAllowed),
+		providedLimit:   providedLimit,
+		maxLimitAllowed: maxLimitAllowed,
+	}
+}
+
 // ErrExceedsMaximumPreconditions occurs when too many preconditions are given to a call.
 type ErrExceedsMaximumPreconditions struct {
 	error
@@ -178,6 +218,45 @@ func (err ErrDuplicateRelationshipError) GRPCStatus() *status.Status {
 	)
 }
 
+// ErrDeletedRelationshipNotFound indicates that a DELETE update was made on a relationship which does
+// not exist, when deletes of missing relationships were requested to fail.
+type ErrDeletedRelationshipNotFound struct {
+	error
+	update *v1.RelationshipUpdate
+}
+
+// NewDeletedRelationshipNotFoundErr constructs a new deleted relationship not found error.
+func NewDeletedRelationshipNotFoundErr(update *v1.RelationshipUpdate) ErrDeletedRelationshipNotFound {
+	return ErrDeletedRelationshipNotFound{
+		error: fmt.Errorf(
+			"cannot delete relationship `%s`, as it does not exist",
+			tuple.StringRelationshipWithoutCaveat(update.Relationship),
+		),
+		update: update,
+	}
+}
+
+// MarshalZerologObject implements zerolog object marshalling.
+func (err ErrDeletedRelationshipNotFound) MarshalZerologObject(e *zerolog.Event) {
+	e.Err(err.error).Str("relationship", tuple.StringRelationshipWithoutCaveat(err.update.Relationship))
+}
+
+// GRPCStatus implements retrieving the gRPC status for the error.
+func (err ErrDeletedRelationshipNotFound) GRPCStatus() *status.Status {
+	return spiceerrors.WithCodeAndDetails(
+		err,
+		codes.NotFound,
+		&errdetails.ErrorInfo{
+			Reason: "ERROR_REASON_DELETED_RELATIONSHIP_NOT_FOUND",
+			Domain: spiceerrors.Domain,
+			Metadata: map[string]string{
+				"definition_name": err.update.Relationship.Resource.ObjectType,
+				"relationship":    tuple.StringRelationshipWithoutCaveat(err.update.Relationship),
+			},
+		},
+	)
+}
+
 // ErrMaxRelationshipContextError indicates an attempt to write a relationship that exceeded the maximum
 // configured context size.
 type ErrMaxRelationshipContextError struct {
@@ -301,6 +380,52 @@ func (err ErrInvalidCursor) GRPCStatus() *status.Status {
 	)
 }
 
+// ErrSchemaChanged occurs when a schema write expected the schema to be unchanged since a revision,
+// but a definition has been written or deleted since.
+type ErrSchemaChanged struct {
+	error
+	definitionName      string
+	expectedRevision    datastore.Revision
+	conflictingRevision datastore.Revision
+}
+
+// NewSchemaChangedErr constructs a new error for a schema write which expected the schema to be
+// unchanged since a revision, but whose definition was changed at the conflicting revision.
+func NewSchemaChangedErr(definitionName string, expectedRevision datastore.Revision, conflictingRevision datastore.Revision) ErrSchemaChanged {
+	return ErrSchemaChanged{
+		error: fmt.Errorf(
+			"the schema has changed since the expected revision: definition `%s` was changed at or before revision `%s`",
+			definitionName,
+			zedtoken.MustNewFromRevision(conflictingRevision).Token,
+		),
+		definitionName:      definitionName,
+		expectedRevision:    expectedRevision,
+		conflictingRevision: conflictingRevision,
+	}
+}
+
+// MarshalZerologObject implements zerolog object marshalling.
+func (err ErrSchemaChanged) MarshalZerologObject(e *zerolog.Event) {
+	e.Err(err.error).Str("definition", err.definitionName).Stringer("expectedRevision", err.expectedRevision).Stringer("conflictingRevision", err.conflictingRevision)
+}
+
+// GRPCStatus implements retrieving the gRPC status for the error.
+func (err ErrSchemaChanged) GRPCStatus() *status.Status {
+	return spiceerrors.WithCodeAndDetails(
+		err,
+		codes.FailedPrecondition,
+		&errdetails.ErrorInfo{
+			Reason: "ERROR_REASON_SCHEMA_CHANGED",
+			Domain: spiceerrors.Domain,
+			Metadata: map[string]string{
+				"definition_name":      err.definitionName,
+				"expected_revision":    zedtoken.MustNewFromRevision(err.expectedRevision).Token,
+				"conflicting_revision": zedtoken.MustNewFromRevision(err.conflictingRevision).Token,
+			},
+		},
+	)
+}
+
 func defaultIfZero[T comparable](value T, defaultValue T) T {
 	var zero T
 	if value == zero {
@@ -0,0 +1,257 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/authzed/authzed-go/pkg/responsemeta"
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/pkg/cursor"
+	"github.com/authzed/spicedb/pkg/datastore"
+	impl "github.com/authzed/spicedb/pkg/proto/impl/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const (
+	// ExpandLimitHeaderKey is the request metadata key holding the maximum number of subjects
+	// returned in each leaf of the tree by ExpandPermissionTree. If any leaf holds more, the
+	// cursor from which to read the next page is returned in the ExpandCursorTrailerKey
+	// response trailer.
+	ExpandLimitHeaderKey = "io.spicedb.expandlimit"
+
+	// ExpandCursorHeaderKey is the request metadata key holding the cursor returned by a previous
+	// page of ExpandPermissionTree, from which to continue. The request must otherwise be the
+	// same as that of the first page. Pages are expanded at the revision of the first, and
+	// leaves which were complete in the previous page hold no subjects.
+	ExpandCursorHeaderKey = "io.spicedb.expandcursor"
+
+	// ExpandCursorTrailerKey is the key in the response trailer metadata holding the cursor of
+	// the next page of ExpandPermissionTree, if any leaf holds more subjects than were returned.
+	ExpandCursorTrailerKey responsemeta.ResponseMetadataTrailerKey = "io.spicedb.respmeta.expandcursor"
+
+	// ExpandSummarizeHeaderKey is the request metadata key which, when `true`, makes
+	// ExpandPermissionTree return the tree without the subjects of its leaves. Instead, the
+	// ExpandCountsTrailerKey response trailer holds the number of subjects beneath each node.
+	ExpandSummarizeHeaderKey = "io.spicedb.expandsummarize"
+
+	// ExpandCountsTrailerKey is the key in the response trailer metadata holding, when requested
+	// via the ExpandSummarizeHeaderKey header, a JSON object mapping the path of each node of
+	// the tree to the number of subjects in the leaves beneath it. The path of the root is `0`,
+	// and that of each child is the path of its parent followed by `.` and its index, such as
+	// `0.2.1`. Subjects found in several leaves are counted once per leaf.
+	ExpandCountsTrailerKey responsemeta.ResponseMetadataTrailerKey = "io.spicedb.respmeta.expandcounts"
+)
+
+// expandTreeRootPath is the path of the root of an expanded tree.
+const expandTreeRootPath = "0"
+
+// expandPaging is the paging or summary of an expanded tree requested via headers.
+type expandPaging struct {
+	limit     uint32
+	cursor    string
+	summarize bool
+}
+
+// expandPagingFromContext returns the paging or summary of the expanded tree requested in the
+// request metadata, if any.
+func expandPagingFromContext(ctx context.Context) (expandPaging, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return expandPaging{}, nil
+	}
+
+	var paging expandPaging
+	if values := md.Get(ExpandLimitHeaderKey); len(values) > 0 {
+		limit, err := strconv.ParseUint(values[0], 10, 32)
+		if err != nil || limit == 0 {
+			return expandPaging{}, status.Errorf(codes.InvalidArgument, "invalid value for %s: must be a positive integer", ExpandLimitHeaderKey)
+		}
+		paging.limit = uint32(limit)
+	}
+
+	if values := md.Get(ExpandCursorHeaderKey); len(values) > 0 {
+		paging.cursor = values[0]
+	}
+
+	if values := md.Get(ExpandSummarizeHeaderKey); len(values) > 0 {
+		summarize, err := strconv.ParseBool(values[0])
+		if err != nil {
+			return expandPaging{}, status.Errorf(codes.InvalidArgument, "invalid value for %s: %s", ExpandSummarizeHeaderKey, err)
+		}
+		paging.summarize = summarize
+	}
+
+	if paging.summarize && (paging.limit > 0 || paging.cursor != "") {
+		return expandPaging{}, status.Errorf(codes.InvalidArgument, "a limit or cursor cannot be given when summarizing the tree via %s", ExpandSummarizeHeaderKey)
+	}
+	return paging, nil
+}
+
+func computeExpandRequestHash(req *v1.ExpandPermissionTreeRequest, subjectFilters map[string]struct{}, limit uint32) (string, error) {
+	filters := make([]string, 0, len(subjectFilters))
+	for filter := range subjectFilters {
+		filters = append(filters, filter)
+	}
+	sort.Strings(filters)
+
+	return computeCallHash("v1.expandpermissiontree", req.Consistency, map[string]any{
+		"resource":        tuple.StringObjectRef(req.Resource),
+		"permission":      req.Permission,
+		"subject-filters": strings.Join(filters, ","),
+		"limit":           limit,
+	})
+}
+
+// decodeExpandCursor returns the revision of the first page of the expanded tree, and the number
+// of subjects already returned for each leaf which had more.
+func decodeExpandCursor(encoded string, requestHash string, ds datastore.Datastore) (datastore.Revision, map[string]int, error) {
+	decoded, err := cursor.Decode(&v1.Cursor{Token: encoded})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	v1decoded := decoded.GetV1()
+	if v1decoded == nil {
+		return nil, nil, cursor.NewInvalidCursorErr(cursor.ErrNilCursor)
+	}
+	if v1decoded.CallAndParametersHash != requestHash {
+		return nil, nil, cursor.NewInvalidCursorErr(cursor.ErrHashMismatch)
+	}
+
+	revision, err := ds.RevisionFromString(v1decoded.Revision)
+	if err != nil {
+		return nil, nil, cursor.NewInvalidCursorErr(err)
+	}
+
+	offsets := make(map[string]int, len(v1decoded.Sections))
+	for _, section := range v1decoded.Sections {
+		path, offsetString, ok := strings.Cut(section, ":")
+		offset, err := strconv.Atoi(offsetString)
+		if !ok || err != nil || offset < 0 {
+			return nil, nil, cursor.NewInvalidCursorErr(fmt.Errorf("invalid section `%s` of expand cursor", section))
+		}
+		offsets[path] = offset
+	}
+	return revision, offsets, nil
+}
+
+// encodeExpandCursor returns the cursor of the next page of the expanded tree, given the
+// sections of the leaves which have more subjects.
+func encodeExpandCursor(sections []string, requestHash string, revision datastore.Revision) (string, error) {
+	encoded, err := cursor.Encode(&impl.DecodedCursor{
+		VersionOneof: &impl.DecodedCursor_V1{
+			V1: &impl.V1Cursor{
+				Revision:              revision.String(),
+				Sections:              sections,
+				CallAndParametersHash: requestHash,
+			},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	return encoded.Token, nil
+}
+
+// pageExpansionTree limits the subjects of each leaf of the tree to those of the page, returning
+// the cursor sections of the leaves which have more. The offsets are those decoded from the
+// cursor of the previous page, or nil for the first page, in which case all leaves start from
+// their first subject. The subjects of each leaf are sorted, so that pages are stable.
+func pageExpansionTree(tree *v1.PermissionRelationshipTree, path string, limit uint32, offsets map[string]int) []string {
+	switch t := tree.TreeType.(type) {
+	case *v1.PermissionRelationshipTree_Intermediate:
+		var sections []string
+		for index, child := range t.Intermediate.Children {
+			sections = append(sections, pageExpansionTree(child, path+"."+strconv.Itoa(index), limit, offsets)...)
+		}
+		return sections
+
+	case *v1.PermissionRelationshipTree_Leaf:
+		subjects := t.Leaf.Subjects
+		slices.SortFunc(subjects, func(a, b *v1.SubjectReference) int {
+			return strings.Compare(tuple.StringSubjectRef(a), tuple.StringSubjectRef(b))
+		})
+
+		start := 0
+		if offsets != nil {
+			// Leaves missing from the cursor were complete in the previous page.
+			start = len(subjects)
+			if offset, ok := offsets[path]; ok {
+				start = min(offset, len(subjects))
+			}
+		}
+
+		end := len(subjects)
+		if limit > 0 {
+			end = min(start+int(limit), len(subjects))
+		}
+		t.Leaf.Subjects = subjects[start:end]
+
+		if end < len(subjects) {
+			return []string{path + ":" + strconv.Itoa(end)}
+		}
+	}
+	return nil
+}
+
+// summarizeExpansionTree removes the subjects from the leaves of the tree, adding to the counts
+// the number of subjects beneath each node and returning that of the tree.
+func summarizeExpansionTree(tree *v1.PermissionRelationshipTree, path string, counts map[string]int) int {
+	count := 0
+	switch t := tree.TreeType.(type) {
+	case *v1.PermissionRelationshipTree_Intermediate:
+		for index, child := range t.Intermediate.Children {
+			count += summarizeExpansionTree(child, path+"."+strconv.Itoa(index), counts)
+		}
+
+	case *v1.PermissionRelationshipTree_Leaf:
+		count = len(t.Leaf.Subjects)
+		t.Leaf.Subjects = nil
+	}
+
+	counts[path] = count
+	return count
+}
+
+// pageOrSummarizeExpansionTree applies the requested paging or summary to the tree, setting the
+// response trailer of the cursor of the next page or the counts of the summary.
+func pageOrSummarizeExpansionTree(ctx context.Context, tree *v1.PermissionRelationshipTree, paging expandPaging, offsets map[string]int, requestHash string, revision datastore.Revision) error {
+	if paging.summarize {
+		counts := make(map[string]int)
+		summarizeExpansionTree(tree, expandTreeRootPath, counts)
+
+		marshaled, err := json.Marshal(counts)
+		if err != nil {
+			return err
+		}
+		return responsemeta.SetResponseTrailerMetadata(ctx, map[responsemeta.ResponseMetadataTrailerKey]string{
+			ExpandCountsTrailerKey: string(marshaled),
+		})
+	}
+
+	if paging.limit == 0 && offsets == nil {
+		return nil
+	}
+
+	sections := pageExpansionTree(tree, expandTreeRootPath, paging.limit, offsets)
+	if len(sections) == 0 {
+		return nil
+	}
+
+	encoded, err := encodeExpandCursor(sections, requestHash, revision)
+	if err != nil {
+		return err
+	}
+	return responsemeta.SetResponseTrailerMetadata(ctx, map[responsemeta.ResponseMetadataTrailerKey]string{
+		ExpandCursorTrailerKey: encoded,
+	})
+}
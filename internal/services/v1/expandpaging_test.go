@@ -0,0 +1,131 @@
+package v1_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/authzed/grpcutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	v1svc "github.com/authzed/spicedb/internal/services/v1"
+	tf "github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+	"github.com/authzed/spicedb/pkg/genutil/mapz"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func newExpandPagingClient(t *testing.T, viewers int) v1.PermissionsServiceClient {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.StandardDatastoreWithData)
+	t.Cleanup(cleanup)
+
+	updates := make([]*v1.RelationshipUpdate, 0, viewers)
+	for i := 0; i < viewers; i++ {
+		updates = append(updates, &v1.RelationshipUpdate{
+			Operation:    v1.RelationshipUpdate_OPERATION_CREATE,
+			Relationship: tuple.MustToRelationship(tuple.MustParse(fmt.Sprintf("document:big#viewer@user:viewer%d", i))),
+		})
+	}
+	_, err := v1.NewPermissionsServiceClient(conn).WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{Updates: updates})
+	require.NoError(t, err)
+
+	return v1.NewPermissionsServiceClient(conn)
+}
+
+var expandBigViewers = &v1.ExpandPermissionTreeRequest{
+	Resource:    &v1.ObjectReference{ObjectType: "document", ObjectId: "big"},
+	Permission:  "viewer",
+	Consistency: fullyConsistent,
+}
+
+func TestExpandPagination(t *testing.T) {
+	client := newExpandPagingClient(t, 7)
+
+	subjects := mapz.NewSet[string]()
+	pages := 0
+	cursor := ""
+	for {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), v1svc.ExpandLimitHeaderKey, "3")
+		if cursor != "" {
+			ctx = metadata.AppendToOutgoingContext(ctx, v1svc.ExpandCursorHeaderKey, cursor)
+		}
+
+		var trailer metadata.MD
+		expanded, err := client.ExpandPermissionTree(ctx, expandBigViewers, grpc.Trailer(&trailer))
+		require.NoError(t, err)
+		pages++
+
+		page := mapz.NewSet[string]()
+		collectLeafSubjects(expanded.TreeRoot, page)
+		require.LessOrEqual(t, page.Len(), 3)
+		for _, subject := range page.AsSlice() {
+			require.True(t, subjects.Add(subject), "subject %s returned twice", subject)
+		}
+
+		cursors := trailer.Get(string(v1svc.ExpandCursorTrailerKey))
+		if len(cursors) == 0 {
+			break
+		}
+		cursor = cursors[0]
+	}
+
+	require.Equal(t, 3, pages)
+	require.Equal(t, 7, subjects.Len())
+
+	// A cursor is only valid for the same request.
+	ctx := metadata.AppendToOutgoingContext(context.Background(), v1svc.ExpandLimitHeaderKey, "3")
+	var trailer metadata.MD
+	_, err := client.ExpandPermissionTree(ctx, expandBigViewers, grpc.Trailer(&trailer))
+	require.NoError(t, err)
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(),
+		v1svc.ExpandLimitHeaderKey, "4",
+		v1svc.ExpandCursorHeaderKey, trailer.Get(string(v1svc.ExpandCursorTrailerKey))[0],
+	)
+	_, err = client.ExpandPermissionTree(ctx, expandBigViewers)
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+}
+
+func TestExpandSummary(t *testing.T) {
+	client := newExpandPagingClient(t, 5)
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), v1svc.ExpandSummarizeHeaderKey, "true")
+	var trailer metadata.MD
+	expanded, err := client.ExpandPermissionTree(ctx, &v1.ExpandPermissionTreeRequest{
+		Resource:    &v1.ObjectReference{ObjectType: "document", ObjectId: "big"},
+		Permission:  "view",
+		Consistency: fullyConsistent,
+	}, grpc.Trailer(&trailer))
+	require.NoError(t, err)
+	require.Equal(t, 0, countLeafs(expanded.TreeRoot))
+
+	values := trailer.Get(string(v1svc.ExpandCountsTrailerKey))
+	require.Len(t, values, 1)
+
+	var counts map[string]int
+	require.NoError(t, json.Unmarshal([]byte(values[0]), &counts))
+	require.Equal(t, 5, counts["0"])
+	require.Greater(t, len(counts), 1)
+}
+
+func TestExpandPagingInvalidHeaders(t *testing.T) {
+	client := newExpandPagingClient(t, 1)
+
+	for _, headers := range [][]string{
+		{v1svc.ExpandLimitHeaderKey, "0"},
+		{v1svc.ExpandLimitHeaderKey, "many"},
+		{v1svc.ExpandSummarizeHeaderKey, "maybe"},
+		{v1svc.ExpandSummarizeHeaderKey, "true", v1svc.ExpandLimitHeaderKey, "10"},
+		{v1svc.ExpandCursorHeaderKey, "notacursor"},
+	} {
+		ctx := metadata.AppendToOutgoingContext(context.Background(), headers...)
+		_, err := client.ExpandPermissionTree(ctx, expandBigViewers)
+		grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+	}
+}
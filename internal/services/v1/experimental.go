@@ -22,8 +22,12 @@ import (
 	"github.com/authzed/spicedb/internal/graph/computed"
 	log "github.com/authzed/spicedb/internal/logging"
 	"github.com/authzed/spicedb/internal/middleware"
+	"github.com/authzed/spicedb/internal/middleware/admission"
 	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
 	"github.com/authzed/spicedb/internal/middleware/handwrittenvalidation"
+	"github.com/authzed/spicedb/internal/middleware/namespacemetrics"
+	"github.com/authzed/spicedb/internal/middleware/namespacequota"
+	"github.com/authzed/spicedb/internal/middleware/slowrequest"
 	"github.com/authzed/spicedb/internal/middleware/streamtimeout"
 	"github.com/authzed/spicedb/internal/middleware/usagemetrics"
 	"github.com/authzed/spicedb/internal/namespace"
@@ -86,11 +90,19 @@ func NewExperimentalServer(dispatch dispatch.Dispatcher, permServerConfig Permis
 				grpcvalidate.UnaryServerInterceptor(),
 				handwrittenvalidation.UnaryServerInterceptor,
 				usagemetrics.UnaryServerInterceptor(),
+				slowrequest.UnaryServerInterceptor(permServerConfig.SlowRequestThreshold),
+				namespacemetrics.UnaryServerInterceptor(permServerConfig.NamespaceMetrics),
+				namespacequota.UnaryServerInterceptor(permServerConfig.NamespaceQuotas),
+				admission.UnaryServerInterceptor(permServerConfig.AdmissionPolicies),
 			),
 			Stream: middleware.ChainStreamServer(
 				grpcvalidate.StreamServerInterceptor(),
 				handwrittenvalidation.StreamServerInterceptor,
 				usagemetrics.StreamServerInterceptor(),
+				slowrequest.StreamServerInterceptor(permServerConfig.SlowRequestThreshold),
+				namespacemetrics.StreamServerInterceptor(permServerConfig.NamespaceMetrics),
+				namespacequota.StreamServerInterceptor(permServerConfig.NamespaceQuotas),
+				admission.StreamServerInterceptor(permServerConfig.AdmissionPolicies),
 				streamtimeout.MustStreamServerInterceptor(config.StreamReadTimeout),
 			),
 		},
@@ -302,6 +314,18 @@ func (es *experimentalServer) BulkExportRelationships(
 		}
 	}
 
+	// Exports of large datasets can outlive the GC window, so the revision is kept from being
+	// collected until the export completes. An export resumed from a cursor is checked again,
+	// since its revision may have been collected in between.
+	if pinner := datastore.UnwrapAs[datastore.RevisionPinningDatastore](ds); pinner != nil {
+		defer pinner.PinRevision(atRevision)()
+	}
+	if req.OptionalCursor != nil {
+		if err := ds.CheckRevision(ctx, atRevision); err != nil {
+			return es.rewriteError(ctx, err)
+		}
+	}
+
 	reader := ds.SnapshotReader(atRevision)
 
 	namespaces, err := reader.ListAllNamespaces(ctx)
@@ -439,6 +463,7 @@ func (es *experimentalServer) BulkCheckPermission(ctx context.Context, req *v1.B
 
 	bulkResponseMutex := sync.Mutex{}
 
+	ctx, stats := graph.ContextWithCheckStats(ctx)
 	tr := taskrunner.NewPreloadedTaskRunner(ctx, es.bulkCheckMaxConcurrency, len(groupedItems))
 
 	respMetadata := &dispatchv1.ResponseMeta{
@@ -519,6 +544,7 @@ func (es *experimentalServer) BulkCheckPermission(ctx context.Context, req *v1.B
 
 		respMetadata.DispatchCount += metadata.DispatchCount
 		respMetadata.CachedDispatchCount += metadata.CachedDispatchCount
+		respMetadata.DepthRequired = max(respMetadata.DepthRequired, metadata.DepthRequired)
 		return nil
 	}
 
@@ -562,6 +588,7 @@ func (es *experimentalServer) BulkCheckPermission(ctx context.Context, req *v1.B
 	if err := tr.StartAndWait(); err != nil {
 		return nil, es.rewriteError(ctx, err)
 	}
+	recordCheckResolution("BulkCheckPermission", respMetadata, stats)
 
 	return &v1.BulkCheckPermissionResponse{CheckedAt: checkedAt, Pairs: orderedPairs}, nil
 }
@@ -0,0 +1,122 @@
+package v1
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/datastore/options"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const (
+	// RelationshipLabelHeaderPrefix is the prefix of request metadata keys whose values are
+	// attached as labels to the relationships created or touched by a WriteRelationships request.
+	// For example, `io.spicedb.relationshiplabel.source: crm` labels each relationship written
+	// with `source=crm`. Touching a relationship replaces its labels with those of the request.
+	// Labels are stored with the relationships and included in the changefeed, if supported by
+	// the datastore.
+	RelationshipLabelHeaderPrefix = "io.spicedb.relationshiplabel."
+
+	// RelationshipLabelFilterHeaderPrefix is the prefix of request metadata keys which limit
+	// ReadRelationships and DeleteRelationships requests to the relationships with the label.
+	// For example, `io.spicedb.relationshiplabelfilter.source: crm` only reads or deletes the
+	// relationships labeled `source=crm`. Relationships must have every label filtered by.
+	RelationshipLabelFilterHeaderPrefix = "io.spicedb.relationshiplabelfilter."
+
+	// maxRelationshipLabels is the maximum number of labels of a single relationship.
+	maxRelationshipLabels = 8
+
+	// maxRelationshipLabelValueLength is the maximum length, in bytes, of the value of a label.
+	maxRelationshipLabelValueLength = 128
+)
+
+var relationshipLabelKeyRegex = regexp.MustCompile(`^[a-z0-9][a-z0-9_.\-]{0,62}$`)
+
+// relationshipLabelsFromContext returns the labels found in the incoming request metadata under
+// the prefix, if any, and ensures that the datastore supports them.
+func relationshipLabelsFromContext(ctx context.Context, ds datastore.Datastore, prefix string) (map[string]string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, nil
+	}
+
+	var labels map[string]string
+	for key, values := range md {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+
+		name := strings.TrimPrefix(key, prefix)
+		if !relationshipLabelKeyRegex.MatchString(name) {
+			return nil, status.Errorf(codes.InvalidArgument, "relationship label key `%s` must match %s", name, relationshipLabelKeyRegex)
+		}
+
+		if len(values) != 1 {
+			return nil, status.Errorf(codes.InvalidArgument, "relationship label `%s` must have exactly one value", name)
+		}
+
+		if values[0] == "" || len(values[0]) > maxRelationshipLabelValueLength {
+			return nil, status.Errorf(codes.InvalidArgument, "value of relationship label `%s` must be between 1 and %d bytes", name, maxRelationshipLabelValueLength)
+		}
+
+		if labels == nil {
+			labels = make(map[string]string)
+		}
+		labels[name] = values[0]
+	}
+
+	if len(labels) > maxRelationshipLabels {
+		return nil, status.Errorf(codes.InvalidArgument, "relationships can have at most %d labels", maxRelationshipLabels)
+	}
+
+	if len(labels) > 0 {
+		features, err := ds.Features(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if !features.RelationshipLabels.Enabled {
+			return nil, status.Errorf(codes.FailedPrecondition, "the datastore does not support relationship labels")
+		}
+	}
+
+	return labels, nil
+}
+
+// deleteLabeledRelationships deletes the relationships matching the filter, including its labels,
+// up to the limit if non-zero, returning whether relationships beyond the limit remain.
+func deleteLabeledRelationships(ctx context.Context, rwt datastore.ReadWriteTransaction, filter datastore.RelationshipsFilter, limit uint32) (bool, error) {
+	var opts []options.QueryOptionsOption
+	if limit > 0 {
+		limitPlusOne := uint64(limit) + 1
+		opts = append(opts, options.WithLimit(&limitPlusOne))
+	}
+
+	iter, err := rwt.QueryRelationships(ctx, filter, opts...)
+	if err != nil {
+		return false, err
+	}
+	defer iter.Close()
+
+	var deletes []*core.RelationTupleUpdate
+	reachedLimit := false
+	for tpl := iter.Next(); tpl != nil; tpl = iter.Next() {
+		if limit > 0 && len(deletes) == int(limit) {
+			reachedLimit = true
+			break
+		}
+		deletes = append(deletes, tuple.Delete(tpl))
+	}
+	if err := iter.Err(); err != nil {
+		return false, err
+	}
+	iter.Close()
+
+	return reachedLimit, rwt.WriteRelationships(ctx, deletes)
+}
@@ -0,0 +1,56 @@
+package v1
+
+import (
+	"context"
+
+	"github.com/authzed/authzed-go/pkg/responsemeta"
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+
+	"github.com/authzed/spicedb/internal/middleware/usagemetrics"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	dispatch "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/zedtoken"
+)
+
+// MaterializedMaxStalenessTrailerKey is the response trailer holding the maximum time by which
+// the changes reflected in a check answered from a materialized permission set may be newer
+// than the revision at which it was checked.
+const MaterializedMaxStalenessTrailerKey responsemeta.ResponseMetadataTrailerKey = "io.spicedb.respmeta.materializedmaxstaleness"
+
+// checkMaterialized answers the check from the materialized permission sets, if they can.
+func (ps *permissionServer) checkMaterialized(ctx context.Context, req *v1.CheckPermissionRequest, atRevision datastore.Revision) (*v1.CheckPermissionResponse, bool, error) {
+	allowed, materializedAt, ok := ps.config.MaterializedPermissions.Check(
+		&core.ObjectAndRelation{
+			Namespace: req.Resource.ObjectType,
+			ObjectId:  req.Resource.ObjectId,
+			Relation:  req.Permission,
+		},
+		&core.ObjectAndRelation{
+			Namespace: req.Subject.Object.ObjectType,
+			ObjectId:  req.Subject.Object.ObjectId,
+			Relation:  normalizeSubjectRelation(req.Subject),
+		},
+		atRevision,
+	)
+	if !ok {
+		return nil, false, nil
+	}
+
+	usagemetrics.SetInContext(ctx, &dispatch.ResponseMeta{})
+	if err := responsemeta.SetResponseTrailerMetadata(ctx, map[responsemeta.ResponseMetadataTrailerKey]string{
+		MaterializedMaxStalenessTrailerKey: ps.config.MaterializedPermissions.MaxStaleness().String(),
+	}); err != nil {
+		return nil, false, err
+	}
+
+	permissionship := v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION
+	if allowed {
+		permissionship = v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION
+	}
+
+	return &v1.CheckPermissionResponse{
+		CheckedAt:      zedtoken.MustNewFromRevision(materializedAt),
+		Permissionship: permissionship,
+	}, true, nil
+}
@@ -0,0 +1,104 @@
+package v1
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/authzed/spicedb/pkg/genutil/mapz"
+	dispatch "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+)
+
+// overflowPermissionLabel is the label value used for both the definition and permission
+// once the configured cardinality limit for permission metrics has been reached.
+const overflowPermissionLabel = "__overflow__"
+
+var permissionMetricsLabels = []string{"method", "definition", "permission"}
+
+var (
+	permissionEvaluationLatency = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "spicedb",
+		Subsystem: "v1",
+		Name:      "permission_evaluation_duration_seconds",
+		Help:      "Time spent evaluating a permission, by definition and permission.",
+		Buckets:   []float64{.001, .003, .006, .010, .018, .024, .032, .042, .056, .075, .100, .178, .316, .562, 1, 5},
+	}, permissionMetricsLabels)
+
+	permissionEvaluationFanout = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "spicedb",
+		Subsystem: "v1",
+		Name:      "permission_evaluation_fanout",
+		Help:      "Distribution of the number of dispatched subproblems required to evaluate a permission, by definition and permission.",
+		Buckets:   []float64{1, 5, 10, 25, 50, 100, 250, 1000},
+	}, permissionMetricsLabels)
+
+	permissionEvaluationDispatchCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "spicedb",
+		Subsystem: "v1",
+		Name:      "permission_evaluation_dispatches_total",
+		Help:      "Total number of dispatched subproblems when evaluating a permission, by definition, permission and whether the result was cached.",
+	}, append(permissionMetricsLabels, "cached"))
+)
+
+// permissionMetricsTracker records evaluation metrics per (definition, permission) pair,
+// bounding the number of distinct label pairs to avoid unbounded metric cardinality.
+type permissionMetricsTracker struct {
+	sync.RWMutex
+	maxCardinality uint32
+	seen           *mapz.Set[string]
+}
+
+func newPermissionMetricsTracker(maxCardinality uint32) *permissionMetricsTracker {
+	return &permissionMetricsTracker{
+		maxCardinality: maxCardinality,
+		seen:           mapz.NewSet[string](),
+	}
+}
+
+// labelsFor returns the definition and permission labels to use for the given pair. If the
+// pair has not been seen before and the cardinality limit has been reached, the overflow
+// label is returned for both.
+func (pmt *permissionMetricsTracker) labelsFor(definition, permission string) (string, string) {
+	key := definition + "#" + permission
+
+	pmt.RLock()
+	found := pmt.seen.Has(key)
+	pmt.RUnlock()
+	if found {
+		return definition, permission
+	}
+
+	pmt.Lock()
+	defer pmt.Unlock()
+	if pmt.seen.Has(key) {
+		return definition, permission
+	}
+
+	if uint32(pmt.seen.Len()) >= pmt.maxCardinality {
+		return overflowPermissionLabel, overflowPermissionLabel
+	}
+
+	pmt.seen.Add(key)
+	return definition, permission
+}
+
+// record records the metrics for evaluating the given permission, which began at the
+// given start time.
+func (pmt *permissionMetricsTracker) record(method, definition, permission string, start time.Time, metadata *dispatch.ResponseMeta) {
+	if pmt == nil || pmt.maxCardinality == 0 {
+		return
+	}
+
+	definition, permission = pmt.labelsFor(definition, permission)
+	permissionEvaluationLatency.WithLabelValues(method, definition, permission).Observe(time.Since(start).Seconds())
+
+	if metadata == nil {
+		return
+	}
+
+	permissionEvaluationFanout.WithLabelValues(method, definition, permission).Observe(float64(metadata.DispatchCount))
+	permissionEvaluationDispatchCounter.WithLabelValues(method, definition, permission, "false").Add(float64(metadata.DispatchCount))
+	permissionEvaluationDispatchCounter.WithLabelValues(method, definition, permission, "true").Add(float64(metadata.CachedDispatchCount))
+}
@@ -0,0 +1,68 @@
+package v1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	dispatch "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+)
+
+func TestPermissionMetricsTrackerCardinality(t *testing.T) {
+	pmt := newPermissionMetricsTracker(2)
+
+	def, perm := pmt.labelsFor("document", "view")
+	require.Equal(t, "document", def)
+	require.Equal(t, "view", perm)
+
+	def, perm = pmt.labelsFor("document", "edit")
+	require.Equal(t, "document", def)
+	require.Equal(t, "edit", perm)
+
+	// A new pair beyond the limit is reported under the overflow label.
+	def, perm = pmt.labelsFor("folder", "view")
+	require.Equal(t, overflowPermissionLabel, def)
+	require.Equal(t, overflowPermissionLabel, perm)
+
+	// Previously seen pairs continue to use their own labels.
+	def, perm = pmt.labelsFor("document", "view")
+	require.Equal(t, "document", def)
+	require.Equal(t, "view", perm)
+}
+
+func TestPermissionMetricsTrackerDisabled(t *testing.T) {
+	var nilTracker *permissionMetricsTracker
+	nilTracker.record("CheckPermission", "document", "view", time.Now(), &dispatch.ResponseMeta{DispatchCount: 1})
+
+	disabled := newPermissionMetricsTracker(0)
+	disabled.record("CheckPermission", "document", "view", time.Now(), &dispatch.ResponseMeta{DispatchCount: 1})
+	require.Equal(t, 0, disabled.seen.Len())
+}
+
+func TestPermissionMetricsNames(t *testing.T) {
+	pmt := newPermissionMetricsTracker(1)
+	pmt.record("CheckPermission", "document", "view", time.Now(), &dispatch.ResponseMeta{DispatchCount: 3, CachedDispatchCount: 1})
+
+	registry := prometheus.NewPedanticRegistry()
+	registry.MustRegister(permissionEvaluationLatency, permissionEvaluationFanout, permissionEvaluationDispatchCounter)
+
+	problems, err := testutil.GatherAndLint(registry)
+	require.NoError(t, err)
+	require.Empty(t, problems)
+
+	families, err := registry.Gather()
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(families))
+	for _, family := range families {
+		names = append(names, family.GetName())
+	}
+	require.ElementsMatch(t, []string{
+		"spicedb_v1_permission_evaluation_duration_seconds",
+		"spicedb_v1_permission_evaluation_fanout",
+		"spicedb_v1_permission_evaluation_dispatches_total",
+	}, names)
+}
@@ -3,6 +3,9 @@ package v1
 import (
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/authzed/authzed-go/pkg/requestmeta"
 	"github.com/authzed/authzed-go/pkg/responsemeta"
@@ -29,6 +32,7 @@ import (
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 	dispatch "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
 	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/zedtoken"
 )
 
 func (ps *permissionServer) rewriteError(ctx context.Context, err error) error {
@@ -38,11 +42,22 @@ func (ps *permissionServer) rewriteError(ctx context.Context, err error) error {
 }
 
 func (ps *permissionServer) CheckPermission(ctx context.Context, req *v1.CheckPermissionRequest) (*v1.CheckPermissionResponse, error) {
+	start := time.Now()
 	atRevision, checkedAt, err := consistency.RevisionFromContext(ctx)
 	if err != nil {
 		return nil, ps.rewriteError(ctx, err)
 	}
 
+	var dispatcher dispatchpkg.Check = ps.dispatch
+	session, err := ps.checkSession(ctx, req, atRevision)
+	if err != nil {
+		return nil, ps.rewriteError(ctx, err)
+	}
+	if session != nil {
+		atRevision, checkedAt = session.revision, zedtoken.MustNewFromRevision(session.revision)
+		dispatcher = session.dispatcher
+	}
+
 	ds := datastoremw.MustFromContext(ctx).SnapshotReader(atRevision)
 
 	caveatContext, err := GetCaveatContext(ctx, req.Context, ps.config.MaxCaveatContextSize)
@@ -74,7 +89,21 @@ func (ps *permissionServer) CheckPermission(ctx context.Context, req *v1.CheckPe
 		}
 	}
 
-	cr, metadata, err := computed.ComputeCheck(ctx, ps.dispatch,
+	// Checks which are being debugged must be resolved through the schema to explain them, and
+	// those at an exact snapshot, or in a session, cannot be answered at the newer revision of the
+	// materialized sets.
+	if debugOption == computed.NoDebugging && req.Consistency.GetAtExactSnapshot() == nil && session == nil {
+		resp, ok, err := ps.checkMaterialized(ctx, req, atRevision)
+		if err != nil {
+			return nil, ps.rewriteError(ctx, err)
+		}
+		if ok {
+			return resp, nil
+		}
+	}
+
+	checkCtx, stats := graph.ContextWithCheckStats(ctx)
+	cr, metadata, err := computed.ComputeCheck(checkCtx, dispatcher,
 		computed.CheckParameters{
 			ResourceType: &core.RelationReference{
 				Namespace: req.Resource.ObjectType,
@@ -93,6 +122,8 @@ func (ps *permissionServer) CheckPermission(ctx context.Context, req *v1.CheckPe
 		req.Resource.ObjectId,
 	)
 	usagemetrics.SetInContext(ctx, metadata)
+	ps.permissionMetrics.record("CheckPermission", req.Resource.ObjectType, req.Permission, start, metadata)
+	recordCheckResolution("CheckPermission", metadata, stats)
 
 	if debugOption != computed.NoDebugging && metadata.DebugInfo != nil {
 		// Convert the dispatch debug information into API debug information and marshal into
@@ -107,9 +138,9 @@ func (ps *permissionServer) CheckPermission(ctx context.Context, req *v1.CheckPe
 			return nil, ps.rewriteError(ctx, merr)
 		}
 
-		serr := responsemeta.SetResponseTrailerMetadata(ctx, map[responsemeta.ResponseMetadataTrailerKey]string{
-			responsemeta.DebugInformation: string(marshaled),
-		})
+		trailer := checkResolutionTrailer(metadata, stats)
+		trailer[responsemeta.DebugInformation] = string(marshaled)
+		serr := responsemeta.SetResponseTrailerMetadata(ctx, trailer)
 		if serr != nil {
 			return nil, ps.rewriteError(ctx, serr)
 		}
@@ -121,6 +152,14 @@ func (ps *permissionServer) CheckPermission(ctx context.Context, req *v1.CheckPe
 
 	permissionship, partialCaveat := checkResultToAPITypes(cr)
 
+	// Results of checks in a session are evaluated at the revision of the session, which is
+	// not shared with other checks.
+	if session == nil {
+		if err := setCacheHints(ctx, req.Consistency, atRevision, checkedAt); err != nil {
+			return nil, ps.rewriteError(ctx, err)
+		}
+	}
+
 	return &v1.CheckPermissionResponse{
 		CheckedAt:         checkedAt,
 		Permissionship:    permissionship,
@@ -142,12 +181,48 @@ func checkResultToAPITypes(cr *dispatch.ResourceCheckResult) (v1.CheckPermission
 	return permissionship, partialCaveat
 }
 
+// ExpandSubjectFilterHeaderKey is the request metadata key holding one or more filters, of the
+// form `subject_type` or `subject_type#relation`, restricting the subjects returned by
+// ExpandPermissionTree to those given. When present, the tree is expanded recursively, so that
+// subjects reachable through subject sets, such as the members of a group, are included.
+const ExpandSubjectFilterHeaderKey = "io.spicedb.expandsubjectfilter"
+
 func (ps *permissionServer) ExpandPermissionTree(ctx context.Context, req *v1.ExpandPermissionTreeRequest) (*v1.ExpandPermissionTreeResponse, error) {
 	atRevision, expandedAt, err := consistency.RevisionFromContext(ctx)
 	if err != nil {
 		return nil, ps.rewriteError(ctx, err)
 	}
 
+	subjectFilters, err := expandSubjectFiltersFromContext(ctx)
+	if err != nil {
+		return nil, ps.rewriteError(ctx, err)
+	}
+
+	expansionMode := dispatch.DispatchExpandRequest_SHALLOW
+	if len(subjectFilters) > 0 {
+		expansionMode = dispatch.DispatchExpandRequest_RECURSIVE
+	}
+
+	paging, err := expandPagingFromContext(ctx)
+	if err != nil {
+		return nil, ps.rewriteError(ctx, err)
+	}
+
+	requestHash, err := computeExpandRequestHash(req, subjectFilters, paging.limit)
+	if err != nil {
+		return nil, ps.rewriteError(ctx, err)
+	}
+
+	// Later pages are expanded at the revision of the first.
+	var offsets map[string]int
+	if paging.cursor != "" {
+		atRevision, offsets, err = decodeExpandCursor(paging.cursor, requestHash, datastoremw.MustFromContext(ctx))
+		if err != nil {
+			return nil, ps.rewriteError(ctx, err)
+		}
+		expandedAt = zedtoken.MustNewFromRevision(atRevision)
+	}
+
 	ds := datastoremw.MustFromContext(ctx).SnapshotReader(atRevision)
 
 	err = namespace.CheckNamespaceAndRelation(ctx, req.Resource.ObjectType, req.Permission, false, ds)
@@ -171,7 +246,7 @@ func (ps *permissionServer) ExpandPermissionTree(ctx context.Context, req *v1.Ex
 			ObjectId:  req.Resource.ObjectId,
 			Relation:  req.Permission,
 		},
-		ExpansionMode: dispatch.DispatchExpandRequest_SHALLOW,
+		ExpansionMode: expansionMode,
 	})
 	usagemetrics.SetInContext(ctx, resp.Metadata)
 	if err != nil {
@@ -180,12 +255,70 @@ func (ps *permissionServer) ExpandPermissionTree(ctx context.Context, req *v1.Ex
 
 	// TODO(jschorr): Change to either using shared interfaces for nodes, or switch the internal
 	// dispatched expand to return V1 node types.
+	treeRoot := TranslateExpansionTree(resp.TreeNode)
+	if len(subjectFilters) > 0 {
+		filterExpansionTreeSubjects(treeRoot, subjectFilters)
+	}
+
+	if err := pageOrSummarizeExpansionTree(ctx, treeRoot, paging, offsets, requestHash, atRevision); err != nil {
+		return nil, ps.rewriteError(ctx, err)
+	}
+
 	return &v1.ExpandPermissionTreeResponse{
-		TreeRoot:   TranslateExpansionTree(resp.TreeNode),
+		TreeRoot:   treeRoot,
 		ExpandedAt: expandedAt,
 	}, nil
 }
 
+// expandSubjectFiltersFromContext returns the set of subject filters given in the request
+// metadata under ExpandSubjectFilterHeaderKey, if any.
+func expandSubjectFiltersFromContext(ctx context.Context) (map[string]struct{}, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, nil
+	}
+
+	values := md.Get(ExpandSubjectFilterHeaderKey)
+	if len(values) == 0 {
+		return nil, nil
+	}
+
+	filters := make(map[string]struct{}, len(values))
+	for _, value := range values {
+		subjectType, relation, hasRelation := strings.Cut(value, "#")
+		if subjectType == "" || (hasRelation && relation == "") {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid subject filter `%s`: must be of the form `subject_type` or `subject_type#relation`", value)
+		}
+		filters[value] = struct{}{}
+	}
+	return filters, nil
+}
+
+// filterExpansionTreeSubjects removes from the leaves of the tree all subjects not matching one
+// of the filters. The structure of the tree is left as-is, so that the operations through which
+// the remaining subjects were found can still be read from it.
+func filterExpansionTreeSubjects(tree *v1.PermissionRelationshipTree, filters map[string]struct{}) {
+	switch t := tree.TreeType.(type) {
+	case *v1.PermissionRelationshipTree_Intermediate:
+		for _, child := range t.Intermediate.Children {
+			filterExpansionTreeSubjects(child, filters)
+		}
+
+	case *v1.PermissionRelationshipTree_Leaf:
+		filtered := make([]*v1.SubjectReference, 0, len(t.Leaf.Subjects))
+		for _, subject := range t.Leaf.Subjects {
+			key := subject.Object.ObjectType
+			if subject.OptionalRelation != "" {
+				key += "#" + subject.OptionalRelation
+			}
+			if _, ok := filters[key]; ok {
+				filtered = append(filtered, subject)
+			}
+		}
+		t.Leaf.Subjects = filtered
+	}
+}
+
 // TranslateRelationshipTree translates a V1 PermissionRelationshipTree into a RelationTupleTreeNode.
 func TranslateRelationshipTree(tree *v1.PermissionRelationshipTree) *core.RelationTupleTreeNode {
 	var expanded *core.ObjectAndRelation
@@ -332,6 +465,7 @@ func TranslateExpansionTree(node *core.RelationTupleTreeNode) *v1.PermissionRela
 }
 
 func (ps *permissionServer) LookupResources(req *v1.LookupResourcesRequest, resp v1.PermissionsService_LookupResourcesServer) error {
+	start := time.Now()
 	ctx := resp.Context()
 
 	atRevision, revisionReadAt, err := consistency.RevisionFromContext(ctx)
@@ -357,6 +491,23 @@ func (ps *permissionServer) LookupResources(req *v1.LookupResourcesRequest, resp
 		return ps.rewriteError(ctx, err)
 	}
 
+	counting, err := countOnly(ctx)
+	if err != nil {
+		return ps.rewriteError(ctx, err)
+	}
+
+	limit, err := pageLimit(req.OptionalLimit, ps.config.DefaultLookupResourcesLimit, ps.config.MaxLookupResourcesLimit)
+	if err != nil {
+		return ps.rewriteError(ctx, err)
+	}
+
+	if counting {
+		if err := countOnlyWithPagination(req.OptionalLimit > 0, req.OptionalCursor != nil); err != nil {
+			return ps.rewriteError(ctx, err)
+		}
+		limit = 0
+	}
+
 	respMetadata := &dispatch.ResponseMeta{
 		DispatchCount:       1,
 		CachedDispatchCount: 0,
@@ -382,12 +533,23 @@ func (ps *permissionServer) LookupResources(req *v1.LookupResourcesRequest, resp
 
 	alreadyPublishedPermissionedResourceIds := map[string]struct{}{}
 
+	// When counting, the permissionship of each resource found, where a resource found both
+	// conditionally and unconditionally is counted as unconditional.
+	countedPermissionships := map[string]dispatch.ResolvedResource_Permissionship{}
+
 	stream := dispatchpkg.NewHandlingDispatchStream(ctx, func(result *dispatch.DispatchLookupResourcesResponse) error {
 		found := result.ResolvedResource
 
 		dispatchpkg.AddResponseMetadata(respMetadata, result.Metadata)
 		currentCursor = result.AfterResponseCursor
 
+		if counting {
+			if existing, ok := countedPermissionships[found.ResourceId]; !ok || existing == dispatch.ResolvedResource_CONDITIONALLY_HAS_PERMISSION {
+				countedPermissionships[found.ResourceId] = found.Permissionship
+			}
+			return nil
+		}
+
 		var partial *v1.PartialCaveatInfo
 		permissionship := v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_HAS_PERMISSION
 		if found.Permissionship == dispatch.ResolvedResource_CONDITIONALLY_HAS_PERMISSION {
@@ -395,7 +557,7 @@ func (ps *permissionServer) LookupResources(req *v1.LookupResourcesRequest, resp
 			partial = &v1.PartialCaveatInfo{
 				MissingRequiredContext: found.MissingRequiredContext,
 			}
-		} else if req.OptionalLimit == 0 {
+		} else if limit == 0 {
 			if _, ok := alreadyPublishedPermissionedResourceIds[found.ResourceId]; ok {
 				// Skip publishing the duplicate.
 				return nil
@@ -445,13 +607,28 @@ func (ps *permissionServer) LookupResources(req *v1.LookupResourcesRequest, resp
 			},
 			Context:        req.Context,
 			OptionalCursor: currentCursor,
-			OptionalLimit:  req.OptionalLimit,
+			OptionalLimit:  limit,
 		},
 		stream)
+	ps.permissionMetrics.record("LookupResources", req.ResourceObjectType, req.Permission, start, respMetadata)
 	if err != nil {
 		return ps.rewriteError(ctx, err)
 	}
 
+	if counting {
+		conditionalCount := 0
+		for _, permissionship := range countedPermissionships {
+			if permissionship == dispatch.ResolvedResource_CONDITIONALLY_HAS_PERMISSION {
+				conditionalCount++
+			}
+		}
+
+		return responsemeta.SetResponseTrailerMetadata(ctx, map[responsemeta.ResponseMetadataTrailerKey]string{
+			CountTrailerKey:            strconv.Itoa(len(countedPermissionships)),
+			ConditionalCountTrailerKey: strconv.Itoa(conditionalCount),
+		})
+	}
+
 	return nil
 }
 
@@ -350,6 +350,15 @@ func TestCheckPermissionWithDebugInfo(t *testing.T) {
 	require.GreaterOrEqual(len(debugInfo.Check.GetSubProblems().Traces), 1)
 	require.NotEmpty(debugInfo.SchemaUsed)
 
+	// The statistics of the resolution are returned along with the debug information.
+	depthRequired, err := responsemeta.GetIntResponseTrailerMetadata(trailer, v1svc.DepthRequiredTrailerKey)
+	require.NoError(err)
+	require.GreaterOrEqual(depthRequired, 2)
+
+	relationshipsScanned, err := responsemeta.GetIntResponseTrailerMetadata(trailer, v1svc.RelationshipsScannedTrailerKey)
+	require.NoError(err)
+	require.GreaterOrEqual(relationshipsScanned, 1)
+
 	// Compile the schema into the namespace definitions.
 	compiled, err := compiler.Compile(compiler.InputSchema{
 		Source:       input.Source("schema"),
@@ -359,6 +368,140 @@ func TestCheckPermissionWithDebugInfo(t *testing.T) {
 	require.Equal(4, len(compiled.OrderedDefinitions))
 }
 
+func TestCheckPermissionWithMaterializedPermissions(t *testing.T) {
+	require := require.New(t)
+	conn, cleanup, _, revision := testserver.NewTestServerWithConfig(
+		require,
+		testTimedeltas[0],
+		memdb.DisableGC,
+		true,
+		testserver.ServerConfig{
+			MaxUpdatesPerWrite:      1000,
+			MaxPreconditionsCount:   1000,
+			StreamingAPITimeout:     30 * time.Second,
+			MaterializedPermissions: []string{"document#view@user"},
+		},
+		tf.StandardDatastoreWithData,
+	)
+	client := v1.NewPermissionsServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	check := func(ctx context.Context, consistency *v1.Consistency, subject string, trailer *metadata.MD) *v1.CheckPermissionResponse {
+		checkResp, err := client.CheckPermission(ctx, &v1.CheckPermissionRequest{
+			Consistency: consistency,
+			Resource:    obj("document", "masterplan"),
+			Permission:  "view",
+			Subject:     sub("user", subject, ""),
+		}, grpc.Trailer(trailer))
+		require.NoError(err)
+		return checkResp
+	}
+
+	// The materialized sets answer for the revisions they have reached, which for this
+	// datastore is its head revision.
+	fullyConsistent := &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}}
+
+	// Once the permission is materialized, checks are answered from it along with its staleness.
+	var trailer metadata.MD
+	require.Eventually(func() bool {
+		trailer = nil
+		check(context.Background(), fullyConsistent, "auditor", &trailer)
+		return len(trailer.Get(string(v1svc.MaterializedMaxStalenessTrailerKey))) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+
+	checkResp := check(context.Background(), fullyConsistent, "auditor", &trailer)
+	require.Equal(v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, checkResp.Permissionship)
+	require.Equal([]string{time.Minute.String()}, trailer.Get(string(v1svc.MaterializedMaxStalenessTrailerKey)))
+
+	checkResp = check(context.Background(), fullyConsistent, "villain", &trailer)
+	require.Equal(v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION, checkResp.Permissionship)
+
+	// Checks being debugged are resolved through the schema.
+	trailer = nil
+	ctx := requestmeta.AddRequestHeaders(context.Background(), requestmeta.RequestDebugInformation)
+	checkResp = check(ctx, fullyConsistent, "auditor", &trailer)
+	require.Equal(v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, checkResp.Permissionship)
+	require.Empty(trailer.Get(string(v1svc.MaterializedMaxStalenessTrailerKey)))
+
+	// As are checks at an exact snapshot, which the sets have moved past.
+	trailer = nil
+	checkResp = check(context.Background(), &v1.Consistency{
+		Requirement: &v1.Consistency_AtExactSnapshot{
+			AtExactSnapshot: zedtoken.MustNewFromRevision(revision),
+		},
+	}, "auditor", &trailer)
+	require.Equal(v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, checkResp.Permissionship)
+	require.Empty(trailer.Get(string(v1svc.MaterializedMaxStalenessTrailerKey)))
+}
+
+func TestCheckPermissionInSession(t *testing.T) {
+	require := require.New(t)
+	conn, cleanup, _, _ := testserver.NewTestServerWithConfig(
+		require,
+		testTimedeltas[0],
+		memdb.DisableGC,
+		true,
+		testserver.ServerConfig{
+			MaxUpdatesPerWrite:      1000,
+			MaxPreconditionsCount:   1000,
+			StreamingAPITimeout:     30 * time.Second,
+			CheckSessionIdleTimeout: time.Minute,
+		},
+		tf.StandardDatastoreWithData,
+	)
+	client := v1.NewPermissionsServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	check := func(ctx context.Context, consistency *v1.Consistency) (*v1.CheckPermissionResponse, error) {
+		return client.CheckPermission(ctx, &v1.CheckPermissionRequest{
+			Consistency: consistency,
+			Resource:    obj("document", "masterplan"),
+			Permission:  "view",
+			Subject:     sub("user", "tom", ""),
+		})
+	}
+
+	sessionCtx := metadata.AppendToOutgoingContext(context.Background(), v1svc.CheckSessionHeaderKey, "page")
+	first, err := check(sessionCtx, nil)
+	require.NoError(err)
+	require.Equal(v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION, first.Permissionship)
+
+	_, err = client.WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{
+			tuple.UpdateToRelationshipUpdate(tuple.Touch(tuple.MustParse("document:masterplan#viewer@user:tom"))),
+		},
+	})
+	require.NoError(err)
+
+	// Later checks in the session are evaluated at its revision, before the write.
+	again, err := check(sessionCtx, nil)
+	require.NoError(err)
+	require.Equal(v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION, again.Permissionship)
+	require.Equal(first.CheckedAt.Token, again.CheckedAt.Token)
+
+	_, err = check(sessionCtx, &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}})
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+
+	// Other sessions start at their own revision.
+	otherCtx := metadata.AppendToOutgoingContext(context.Background(), v1svc.CheckSessionHeaderKey, "otherpage")
+	other, err := check(otherCtx, &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}})
+	require.NoError(err)
+	require.Equal(v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, other.Permissionship)
+}
+
+func TestCheckPermissionInSessionDisabled(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.StandardDatastoreWithData)
+	client := v1.NewPermissionsServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	_, err := client.CheckPermission(metadata.AppendToOutgoingContext(context.Background(), v1svc.CheckSessionHeaderKey, "page"), &v1.CheckPermissionRequest{
+		Resource:   obj("document", "masterplan"),
+		Permission: "view",
+		Subject:    sub("user", "tom", ""),
+	})
+	grpcutil.RequireStatus(t, codes.FailedPrecondition, err)
+}
+
 func TestLookupResources(t *testing.T) {
 	testCases := []struct {
 		objectType           string
@@ -639,6 +782,69 @@ func TestExpand(t *testing.T) {
 	}
 }
 
+func TestExpandWithSubjectFilter(t *testing.T) {
+	testCases := []struct {
+		filters           []string
+		expectedSubjects  []string
+		expectedErrorCode codes.Code
+	}{
+		{nil, []string{"folder:auditors#viewer", "user:legal", "user:owner"}, codes.OK},
+		{[]string{"user"}, []string{"user:auditor", "user:legal", "user:owner"}, codes.OK},
+		{[]string{"folder#viewer"}, []string{"folder:auditors#viewer"}, codes.OK},
+		{[]string{"user", "folder#viewer"}, []string{"folder:auditors#viewer", "user:auditor", "user:legal", "user:owner"}, codes.OK},
+		{[]string{"document"}, nil, codes.OK},
+		{[]string{"folder#"}, nil, codes.InvalidArgument},
+		{[]string{"#viewer"}, nil, codes.InvalidArgument},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(strings.Join(tc.filters, ","), func(t *testing.T) {
+			require := require.New(t)
+			conn, cleanup, _, revision := testserver.NewTestServer(require, 0, memdb.DisableGC, true, tf.StandardDatastoreWithData)
+			client := v1.NewPermissionsServiceClient(conn)
+			t.Cleanup(cleanup)
+
+			ctx := context.Background()
+			for _, filter := range tc.filters {
+				ctx = metadata.AppendToOutgoingContext(ctx, v1svc.ExpandSubjectFilterHeaderKey, filter)
+			}
+
+			expanded, err := client.ExpandPermissionTree(ctx, &v1.ExpandPermissionTreeRequest{
+				Resource:   &v1.ObjectReference{ObjectType: "folder", ObjectId: "company"},
+				Permission: "view",
+				Consistency: &v1.Consistency{
+					Requirement: &v1.Consistency_AtLeastAsFresh{
+						AtLeastAsFresh: zedtoken.MustNewFromRevision(revision),
+					},
+				},
+			})
+			if tc.expectedErrorCode != codes.OK {
+				grpcutil.RequireStatus(t, tc.expectedErrorCode, err)
+				return
+			}
+			require.NoError(err)
+
+			subjects := mapz.NewSet[string]()
+			collectLeafSubjects(expanded.TreeRoot, subjects)
+			require.ElementsMatch(tc.expectedSubjects, subjects.AsSlice())
+		})
+	}
+}
+
+func collectLeafSubjects(node *v1.PermissionRelationshipTree, subjects *mapz.Set[string]) {
+	switch t := node.TreeType.(type) {
+	case *v1.PermissionRelationshipTree_Leaf:
+		for _, subject := range t.Leaf.Subjects {
+			subjects.Add(tuple.StringSubjectRef(subject))
+		}
+	case *v1.PermissionRelationshipTree_Intermediate:
+		for _, child := range t.Intermediate.Children {
+			collectLeafSubjects(child, subjects)
+		}
+	}
+}
+
 func countLeafs(node *v1.PermissionRelationshipTree) int {
 	switch t := node.TreeType.(type) {
 	case *v1.PermissionRelationshipTree_Leaf:
@@ -1631,3 +1837,67 @@ func TestLookupResourcesDeduplication(t *testing.T) {
 
 	require.Equal(t, []string{"first"}, foundObjectIds.AsSlice())
 }
+
+func TestLookupResourcesLimits(t *testing.T) {
+	req := require.New(t)
+	conn, cleanup, _, _ := testserver.NewTestServerWithConfig(req, testTimedeltas[0], memdb.DisableGC, true,
+		testserver.ServerConfig{
+			MaxUpdatesPerWrite:          1000,
+			MaxPreconditionsCount:       1000,
+			StreamingAPITimeout:         30 * time.Second,
+			MaxLookupResourcesLimit:     3,
+			DefaultLookupResourcesLimit: 2,
+		},
+		func(ds datastore.Datastore, require *require.Assertions) (datastore.Datastore, datastore.Revision) {
+			return tf.DatastoreFromSchemaAndTestRelationships(ds, `
+				definition user {}
+
+				definition document {
+					relation viewer: user
+					permission view = viewer
+				}
+			`, []*core.RelationTuple{
+				tuple.MustParse("document:first#viewer@user:tom"),
+				tuple.MustParse("document:second#viewer@user:tom"),
+				tuple.MustParse("document:third#viewer@user:tom"),
+				tuple.MustParse("document:fourth#viewer@user:tom"),
+			}, require)
+		})
+
+	client := v1.NewPermissionsServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	lookup := func(limit uint32) (int, error) {
+		lookupClient, err := client.LookupResources(context.Background(), &v1.LookupResourcesRequest{
+			ResourceObjectType: "document",
+			Permission:         "view",
+			Subject:            sub("user", "tom", ""),
+			OptionalLimit:      limit,
+		})
+		require.NoError(t, err)
+
+		count := 0
+		for {
+			_, err := lookupClient.Recv()
+			if errors.Is(err, io.EOF) {
+				return count, nil
+			}
+			if err != nil {
+				return count, err
+			}
+			count++
+		}
+	}
+
+	// Calls without a limit are limited by the default, and those with one by their own.
+	count, err := lookup(0)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+
+	count, err = lookup(3)
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+
+	_, err = lookup(4)
+	grpcutil.RequireStatus(t, codes.ResourceExhausted, err)
+}
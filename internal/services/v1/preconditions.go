@@ -48,3 +48,56 @@ func checkPreconditions(
 
 	return nil
 }
+
+// checkDeletedRelationshipsExist checks, in the context of a datastore read-write transaction,
+// that the relationship of each DELETE update exists, and returns an error for the first which
+// does not.
+func checkDeletedRelationshipsExist(
+	ctx context.Context,
+	rwt datastore.ReadWriteTransaction,
+	updates []*v1.RelationshipUpdate,
+) error {
+	for _, update := range updates {
+		if update.Operation != v1.RelationshipUpdate_OPERATION_DELETE {
+			continue
+		}
+
+		exists, err := relationshipExists(ctx, rwt, update.Relationship)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			return NewDeletedRelationshipNotFoundErr(update)
+		}
+	}
+
+	return nil
+}
+
+// relationshipExists returns whether the relationship, ignoring its caveat, exists in the reader,
+// such as a datastore read-write transaction.
+func relationshipExists(ctx context.Context, reader datastore.Reader, rel *v1.Relationship) (bool, error) {
+	iter, err := reader.QueryRelationships(ctx, datastore.RelationshipsFilterFromPublicFilter(&v1.RelationshipFilter{
+		ResourceType:       rel.Resource.ObjectType,
+		OptionalResourceId: rel.Resource.ObjectId,
+		OptionalRelation:   rel.Relation,
+		OptionalSubjectFilter: &v1.SubjectFilter{
+			SubjectType:       rel.Subject.Object.ObjectType,
+			OptionalSubjectId: rel.Subject.Object.ObjectId,
+			OptionalRelation:  &v1.SubjectFilter_RelationFilter{Relation: rel.Subject.OptionalRelation},
+		},
+	}), options.WithLimit(&limitOne))
+	if err != nil {
+		return false, fmt.Errorf("error reading relationships: %w", err)
+	}
+
+	first := iter.Next()
+	iterErr := iter.Err()
+	iter.Close()
+	if first == nil && iterErr != nil {
+		return false, fmt.Errorf("error reading relationships from iterator: %w", iterErr)
+	}
+
+	return first != nil, nil
+}
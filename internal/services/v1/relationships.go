@@ -3,20 +3,30 @@ package v1
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
 
+	"github.com/authzed/authzed-go/pkg/responsemeta"
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	grpcvalidate "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/validator"
 	"github.com/jzelinskie/stringz"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promauto"
 	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 
 	"github.com/authzed/spicedb/internal/dispatch"
+	"github.com/authzed/spicedb/internal/graph/materialized"
 	"github.com/authzed/spicedb/internal/middleware"
+	"github.com/authzed/spicedb/internal/middleware/admission"
 	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
 	"github.com/authzed/spicedb/internal/middleware/handwrittenvalidation"
+	"github.com/authzed/spicedb/internal/middleware/namespacemetrics"
+	"github.com/authzed/spicedb/internal/middleware/namespacequota"
+	"github.com/authzed/spicedb/internal/middleware/slowrequest"
 	"github.com/authzed/spicedb/internal/middleware/streamtimeout"
 	"github.com/authzed/spicedb/internal/middleware/usagemetrics"
 	"github.com/authzed/spicedb/internal/namespace"
@@ -28,6 +38,7 @@ import (
 	"github.com/authzed/spicedb/pkg/datastore/pagination"
 	"github.com/authzed/spicedb/pkg/genutil/mapz"
 	"github.com/authzed/spicedb/pkg/middleware/consistency"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
 	"github.com/authzed/spicedb/pkg/tuple"
 	"github.com/authzed/spicedb/pkg/zedtoken"
@@ -68,6 +79,64 @@ type PermissionsServerConfig struct {
 	// MaxDatastoreReadPageSize defines the maximum number of relationships loaded from the
 	// datastore in one query.
 	MaxDatastoreReadPageSize uint64
+
+	// MaxReadRelationshipsLimit holds the maximum limit allowed on a ReadRelationships
+	// call. Zero places no maximum.
+	MaxReadRelationshipsLimit uint32
+
+	// DefaultReadRelationshipsLimit is the limit applied to ReadRelationships calls which do
+	// not specify one. Zero places no limit.
+	DefaultReadRelationshipsLimit uint32
+
+	// MaxLookupResourcesLimit holds the maximum limit allowed on a LookupResources call. Zero
+	// places no maximum.
+	MaxLookupResourcesLimit uint32
+
+	// DefaultLookupResourcesLimit is the limit applied to LookupResources calls which do not
+	// specify one. Zero places no limit.
+	DefaultLookupResourcesLimit uint32
+
+	// DefaultExportBatchSize is the number of relationships returned per response by
+	// BulkExportRelationships calls which do not specify a limit. Zero uses the default of the
+	// experimental service.
+	DefaultExportBatchSize uint32
+
+	// MaxExportBatchSize is the maximum number of relationships returned per response by
+	// BulkExportRelationships; larger limits are reduced to it. Zero uses the default of the
+	// experimental service.
+	MaxExportBatchSize uint32
+
+	// PermissionMetricsMaxCardinality is the maximum number of distinct (definition, permission)
+	// pairs for which evaluation metrics are recorded. Zero disables per-permission metrics.
+	PermissionMetricsMaxCardinality uint32
+
+	// SlowRequestThreshold is the duration after which a request is logged as slow, along
+	// with its dispatch statistics. Zero disables logging of slow requests.
+	SlowRequestThreshold time.Duration
+
+	// NamespaceMetrics is the set of known namespaces labeling the per-namespace request
+	// metrics. Nil disables per-namespace metrics.
+	NamespaceMetrics *namespacemetrics.KnownNamespaces
+
+	// NamespaceQuotas are the per-namespace limits on relationship counts, write rates and
+	// lookup results. Nil places no per-namespace limits.
+	NamespaceQuotas *namespacequota.Quotas
+
+	// AdmissionPolicies are the policies which writes of relationships must satisfy. Nil
+	// admits all writes.
+	AdmissionPolicies *admission.Policies
+
+	// WriteBatchWindow is the time for which WriteRelationships calls are held so that those
+	// made concurrently are committed in a single transaction. Zero disables batching.
+	WriteBatchWindow time.Duration
+
+	// MaterializedPermissions are the materialized permission sets which answer checks of
+	// their permissions when fresh enough. Nil answers every check through the schema.
+	MaterializedPermissions *materialized.Sets
+
+	// CheckSessionIdleTimeout is the time after which a check session which has not been used
+	// ends. Zero disables check sessions.
+	CheckSessionIdleTimeout time.Duration
 }
 
 // NewPermissionsServer creates a PermissionsServiceServer instance.
@@ -83,21 +152,56 @@ func NewPermissionsServer(
 		MaxCaveatContextSize:       defaultIfZero(config.MaxCaveatContextSize, 4096),
 		MaxRelationshipContextSize: defaultIfZero(config.MaxRelationshipContextSize, 25_000),
 		MaxDatastoreReadPageSize:   defaultIfZero(config.MaxDatastoreReadPageSize, 1_000),
+		MaxReadRelationshipsLimit:  config.MaxReadRelationshipsLimit,
+
+		DefaultReadRelationshipsLimit: config.DefaultReadRelationshipsLimit,
+		MaxLookupResourcesLimit:       config.MaxLookupResourcesLimit,
+		DefaultLookupResourcesLimit:   config.DefaultLookupResourcesLimit,
+
+		PermissionMetricsMaxCardinality: config.PermissionMetricsMaxCardinality,
+		SlowRequestThreshold:            config.SlowRequestThreshold,
+		NamespaceMetrics:                config.NamespaceMetrics,
+		NamespaceQuotas:                 config.NamespaceQuotas,
+		AdmissionPolicies:               config.AdmissionPolicies,
+		WriteBatchWindow:                config.WriteBatchWindow,
+		MaterializedPermissions:         config.MaterializedPermissions,
+		CheckSessionIdleTimeout:         config.CheckSessionIdleTimeout,
+	}
+
+	var batcher *writeBatcher
+	if configWithDefaults.WriteBatchWindow > 0 {
+		batcher = newWriteBatcher(context.Background(), configWithDefaults.WriteBatchWindow, int(configWithDefaults.MaxUpdatesPerWrite))
+	}
+
+	var sessions *checkSessions
+	if configWithDefaults.CheckSessionIdleTimeout > 0 {
+		sessions = newCheckSessions(configWithDefaults.CheckSessionIdleTimeout)
 	}
 
 	return &permissionServer{
-		dispatch: dispatch,
-		config:   configWithDefaults,
+		dispatch:          dispatch,
+		config:            configWithDefaults,
+		permissionMetrics: newPermissionMetricsTracker(configWithDefaults.PermissionMetricsMaxCardinality),
+		writeBatcher:      batcher,
+		checkSessions:     sessions,
 		WithServiceSpecificInterceptors: shared.WithServiceSpecificInterceptors{
 			Unary: middleware.ChainUnaryServer(
 				grpcvalidate.UnaryServerInterceptor(),
 				handwrittenvalidation.UnaryServerInterceptor,
 				usagemetrics.UnaryServerInterceptor(),
+				slowrequest.UnaryServerInterceptor(configWithDefaults.SlowRequestThreshold),
+				namespacemetrics.UnaryServerInterceptor(configWithDefaults.NamespaceMetrics),
+				namespacequota.UnaryServerInterceptor(configWithDefaults.NamespaceQuotas),
+				admission.UnaryServerInterceptor(configWithDefaults.AdmissionPolicies),
 			),
 			Stream: middleware.ChainStreamServer(
 				grpcvalidate.StreamServerInterceptor(),
 				handwrittenvalidation.StreamServerInterceptor,
 				usagemetrics.StreamServerInterceptor(),
+				slowrequest.StreamServerInterceptor(configWithDefaults.SlowRequestThreshold),
+				namespacemetrics.StreamServerInterceptor(configWithDefaults.NamespaceMetrics),
+				namespacequota.StreamServerInterceptor(configWithDefaults.NamespaceQuotas),
+				admission.StreamServerInterceptor(configWithDefaults.AdmissionPolicies),
 				streamtimeout.MustStreamServerInterceptor(configWithDefaults.StreamingAPITimeout),
 			),
 		},
@@ -108,8 +212,11 @@ type permissionServer struct {
 	v1.UnimplementedPermissionsServiceServer
 	shared.WithServiceSpecificInterceptors
 
-	dispatch dispatch.Dispatcher
-	config   PermissionsServerConfig
+	dispatch          dispatch.Dispatcher
+	config            PermissionsServerConfig
+	permissionMetrics *permissionMetricsTracker
+	writeBatcher      *writeBatcher
+	checkSessions     *checkSessions
 }
 
 func (ps *permissionServer) checkFilterComponent(ctx context.Context, objectType, optionalRelation string, ds datastore.Reader) error {
@@ -145,10 +252,37 @@ func (ps *permissionServer) ReadRelationships(req *v1.ReadRelationshipsRequest,
 
 	ds := datastoremw.MustFromContext(ctx).SnapshotReader(atRevision)
 
+	readLimit, err := pageLimit(req.OptionalLimit, ps.config.DefaultReadRelationshipsLimit, ps.config.MaxReadRelationshipsLimit)
+	if err != nil {
+		return ps.rewriteError(ctx, err)
+	}
+
 	if err := ps.checkFilterNamespaces(ctx, req.RelationshipFilter, ds); err != nil {
 		return ps.rewriteError(ctx, err)
 	}
 
+	labelFilter, err := relationshipLabelsFromContext(ctx, datastoremw.MustFromContext(ctx), RelationshipLabelFilterHeaderPrefix)
+	if err != nil {
+		return ps.rewriteError(ctx, err)
+	}
+
+	idPrefix, err := resourceIDPrefix(ctx, req.RelationshipFilter)
+	if err != nil {
+		return ps.rewriteError(ctx, err)
+	}
+
+	counting, err := countOnly(ctx)
+	if err != nil {
+		return ps.rewriteError(ctx, err)
+	}
+
+	if counting {
+		if err := countOnlyWithPagination(req.OptionalLimit > 0, req.OptionalCursor != nil); err != nil {
+			return ps.rewriteError(ctx, err)
+		}
+		readLimit = 0
+	}
+
 	usagemetrics.SetInContext(ctx, &dispatchv1.ResponseMeta{
 		DispatchCount: 1,
 	})
@@ -180,17 +314,21 @@ func (ps *permissionServer) ReadRelationships(req *v1.ReadRelationshipsRequest,
 	}
 
 	pageSize := ps.config.MaxDatastoreReadPageSize
-	if req.OptionalLimit > 0 {
-		limit = int(req.OptionalLimit)
+	if readLimit > 0 {
+		limit = int(readLimit)
 		if uint64(limit) < pageSize {
 			pageSize = uint64(limit)
 		}
 	}
 
+	filter := datastore.RelationshipsFilterFromPublicFilter(req.RelationshipFilter)
+	filter.OptionalLabels = labelFilter
+	filter.OptionalResourceIDPrefix = idPrefix
+
 	tupleIterator, err := pagination.NewPaginatedIterator(
 		ctx,
 		ds,
-		datastore.RelationshipsFilterFromPublicFilter(req.RelationshipFilter),
+		filter,
 		pageSize,
 		options.ByResource,
 		startCursor,
@@ -200,6 +338,20 @@ func (ps *permissionServer) ReadRelationships(req *v1.ReadRelationshipsRequest,
 	}
 	defer tupleIterator.Close()
 
+	if counting {
+		count := 0
+		for tpl := tupleIterator.Next(); tpl != nil; tpl = tupleIterator.Next() {
+			count++
+		}
+		if tupleIterator.Err() != nil {
+			return ps.rewriteError(ctx, fmt.Errorf("error when reading tuples: %w", tupleIterator.Err()))
+		}
+
+		return responsemeta.SetResponseTrailerMetadata(ctx, map[responsemeta.ResponseMetadataTrailerKey]string{
+			CountTrailerKey: strconv.Itoa(count),
+		})
+	}
+
 	response := &v1.ReadRelationshipsResponse{
 		ReadAt: revisionReadAt,
 	}
@@ -245,6 +397,38 @@ func (ps *permissionServer) ReadRelationships(req *v1.ReadRelationshipsRequest,
 	return nil
 }
 
+// pageLimit returns the limit of a paginated call which requested the given limit: the default
+// if none was requested, or an error if it exceeds the maximum. Zero places no limit.
+func pageLimit(requested, defaultLimit, maxLimit uint32) (uint32, error) {
+	if maxLimit > 0 && requested > maxLimit {
+		return 0, NewExceedsMaximumLimitErr(requested, maxLimit)
+	}
+	if requested == 0 {
+		return defaultLimit, nil
+	}
+	return requested, nil
+}
+
+// WriteRelationshipsFailOnMissingDeleteHeaderKey is the request metadata key which, when `true`,
+// makes WriteRelationships fail if any of its DELETE updates is of a relationship which does not
+// exist, rather than treating the delete as a no-op.
+const WriteRelationshipsFailOnMissingDeleteHeaderKey = "io.spicedb.failonmissingdelete"
+
+// failOnMissingDelete returns whether DELETE updates of missing relationships were requested to
+// fail, via the WriteRelationshipsFailOnMissingDeleteHeaderKey header.
+func failOnMissingDelete(ctx context.Context) (bool, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(WriteRelationshipsFailOnMissingDeleteHeaderKey); len(values) > 0 {
+			failOnMissing, err := strconv.ParseBool(values[0])
+			if err != nil {
+				return false, status.Errorf(codes.InvalidArgument, "invalid value for %s: %s", WriteRelationshipsFailOnMissingDeleteHeaderKey, err)
+			}
+			return failOnMissing, nil
+		}
+	}
+	return false, nil
+}
+
 func (ps *permissionServer) WriteRelationships(ctx context.Context, req *v1.WriteRelationshipsRequest) (*v1.WriteRelationshipsResponse, error) {
 	ds := datastoremw.MustFromContext(ctx)
 
@@ -283,10 +467,43 @@ func (ps *permissionServer) WriteRelationships(ctx context.Context, req *v1.Writ
 		}
 	}
 
+	txMetadata, err := transactionMetadataFromContext(ctx)
+	if err != nil {
+		return nil, ps.rewriteError(ctx, err)
+	}
+
+	labels, err := relationshipLabelsFromContext(ctx, ds, RelationshipLabelHeaderPrefix)
+	if err != nil {
+		return nil, ps.rewriteError(ctx, err)
+	}
+
+	failOnMissing, err := failOnMissingDelete(ctx)
+	if err != nil {
+		return nil, ps.rewriteError(ctx, err)
+	}
+
+	isDryRun, err := dryRun(ctx)
+	if err != nil {
+		return nil, ps.rewriteError(ctx, err)
+	}
+
+	usagemetrics.SetInContext(ctx, &dispatchv1.ResponseMeta{
+		// One request per precondition and one request for the actual writes.
+		DispatchCount: uint32(len(req.OptionalPreconditions)) + 1,
+	})
+
 	// Execute the write operation(s).
 	span.AddEvent("read write transaction")
 	tupleUpdates := tuple.UpdateFromRelationshipUpdates(req.Updates)
-	revision, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+	if encodedLabels := tuple.LabelsFromMap(labels); encodedLabels != nil {
+		for _, update := range tupleUpdates {
+			if update.Operation != core.RelationTupleUpdate_DELETE {
+				update.Tuple.Labels = encodedLabels
+			}
+		}
+	}
+	var written, deleted uint64
+	writeFn := func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
 		span.AddEvent("preconditions")
 		// Validate the preconditions.
 		for _, precond := range req.OptionalPreconditions {
@@ -302,19 +519,53 @@ func (ps *permissionServer) WriteRelationships(ctx context.Context, req *v1.Writ
 			return ps.rewriteError(ctx, err)
 		}
 
-		usagemetrics.SetInContext(ctx, &dispatchv1.ResponseMeta{
-			// One request per precondition and one request for the actual writes.
-			DispatchCount: uint32(len(req.OptionalPreconditions)) + 1,
-		})
-
 		span.AddEvent("preconditions")
 		if err := checkPreconditions(ctx, rwt, req.OptionalPreconditions); err != nil {
 			return err
 		}
 
+		if failOnMissing {
+			span.AddEvent("check deleted relationships exist")
+			if err := checkDeletedRelationshipsExist(ctx, rwt, req.Updates); err != nil {
+				return err
+			}
+		}
+
+		if isDryRun {
+			span.AddEvent("count dry run changes")
+			written, deleted, err = countRelationshipUpdates(ctx, rwt, req.Updates)
+			if err != nil {
+				return err
+			}
+		}
+
 		span.AddEvent("write relationships")
 		return rwt.WriteRelationships(ctx, tupleUpdates)
-	})
+	}
+
+	// Dry runs are never batched, as they must be rolled back alone.
+	if isDryRun {
+		revision, err := dryRunReadWriteTx(ctx, ds, writeFn)
+		if err != nil {
+			return nil, ps.rewriteError(ctx, err)
+		}
+
+		if err := setDryRunRelationshipsTrailer(ctx, written, deleted); err != nil {
+			return nil, ps.rewriteError(ctx, err)
+		}
+
+		return &v1.WriteRelationshipsResponse{
+			WrittenAt: zedtoken.MustNewFromRevision(revision),
+		}, nil
+	}
+
+	// Writes with transaction metadata are never batched, as it is attached to the transaction.
+	var revision datastore.Revision
+	if ps.writeBatcher != nil && len(txMetadata) == 0 {
+		revision, err = ps.writeBatcher.write(ctx, ds, len(req.Updates), writeFn)
+	} else {
+		revision, err = ds.ReadWriteTx(ctx, writeFn, options.SetMetadata(txMetadata))
+	}
 	if err != nil {
 		return nil, ps.rewriteError(ctx, err)
 	}
@@ -342,10 +593,35 @@ func (ps *permissionServer) DeleteRelationships(ctx context.Context, req *v1.Del
 		)
 	}
 
+	txMetadata, err := transactionMetadataFromContext(ctx)
+	if err != nil {
+		return nil, ps.rewriteError(ctx, err)
+	}
+
+	isDryRun, err := dryRun(ctx)
+	if err != nil {
+		return nil, ps.rewriteError(ctx, err)
+	}
+
 	ds := datastoremw.MustFromContext(ctx)
+	labelFilter, err := relationshipLabelsFromContext(ctx, ds, RelationshipLabelFilterHeaderPrefix)
+	if err != nil {
+		return nil, ps.rewriteError(ctx, err)
+	}
+
+	idPrefix, err := resourceIDPrefix(ctx, req.RelationshipFilter)
+	if err != nil {
+		return nil, ps.rewriteError(ctx, err)
+	}
+
+	filter := datastore.RelationshipsFilterFromPublicFilter(req.RelationshipFilter)
+	filter.OptionalLabels = labelFilter
+	filter.OptionalResourceIDPrefix = idPrefix
+
 	deletionProgress := v1.DeleteRelationshipsResponse_DELETION_PROGRESS_COMPLETE
 
-	revision, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+	var deleted uint64
+	deleteFn := func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
 		if err := ps.checkFilterNamespaces(ctx, req.RelationshipFilter, rwt); err != nil {
 			return err
 		}
@@ -364,7 +640,6 @@ func (ps *permissionServer) DeleteRelationships(ctx context.Context, req *v1.Del
 		if req.OptionalLimit > 0 && !req.OptionalAllowPartialDeletions {
 			limit := uint64(req.OptionalLimit)
 			limitPlusOne := limit + 1
-			filter := datastore.RelationshipsFilterFromPublicFilter(req.RelationshipFilter)
 
 			iter, err := rwt.QueryRelationships(ctx, filter, options.WithLimit(&limitPlusOne))
 			if err != nil {
@@ -387,6 +662,29 @@ func (ps *permissionServer) DeleteRelationships(ctx context.Context, req *v1.Del
 			iter.Close()
 		}
 
+		if isDryRun {
+			var err error
+			deleted, err = countFilteredRelationships(ctx, rwt, filter, req.OptionalLimit)
+			if err != nil {
+				return ps.rewriteError(ctx, err)
+			}
+		}
+
+		// Relationships filtered by label or resource ID prefix are read and deleted individually,
+		// as the datastores delete by the fields of the public filter alone.
+		if len(labelFilter) > 0 || idPrefix != "" {
+			reachedLimit, err := deleteLabeledRelationships(ctx, rwt, filter, req.OptionalLimit)
+			if err != nil {
+				return err
+			}
+
+			if reachedLimit {
+				deletionProgress = v1.DeleteRelationshipsResponse_DELETION_PROGRESS_PARTIAL
+			}
+
+			return nil
+		}
+
 		// Delete with the specified limit.
 		if req.OptionalLimit > 0 {
 			deleteLimit := uint64(req.OptionalLimit)
@@ -405,7 +703,17 @@ func (ps *permissionServer) DeleteRelationships(ctx context.Context, req *v1.Del
 		// Otherwise, kick off an unlimited deletion.
 		_, err := rwt.DeleteRelationships(ctx, req.RelationshipFilter)
 		return err
-	})
+	}
+
+	var revision datastore.Revision
+	if isDryRun {
+		revision, err = dryRunReadWriteTx(ctx, ds, deleteFn)
+		if err == nil {
+			err = setDryRunRelationshipsTrailer(ctx, 0, deleted)
+		}
+	} else {
+		revision, err = ds.ReadWriteTx(ctx, deleteFn, options.SetMetadata(txMetadata))
+	}
 	if err != nil {
 		return nil, ps.rewriteError(ctx, err)
 	}
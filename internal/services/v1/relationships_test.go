@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"io"
 	"maps"
+	"sort"
+	"strings"
 	"testing"
 	"time"
 
@@ -14,13 +16,16 @@ import (
 	"github.com/stretchr/testify/require"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/structpb"
 
 	"github.com/authzed/spicedb/internal/datastore/memdb"
+	v1svc "github.com/authzed/spicedb/internal/services/v1"
 	tf "github.com/authzed/spicedb/internal/testfixtures"
 	"github.com/authzed/spicedb/internal/testserver"
+	"github.com/authzed/spicedb/pkg/datastore"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 	"github.com/authzed/spicedb/pkg/spiceerrors"
 	"github.com/authzed/spicedb/pkg/tuple"
@@ -404,6 +409,53 @@ func TestDeleteRelationshipViaWriteNoop(t *testing.T) {
 	require.NoError(err)
 }
 
+func TestDeleteRelationshipViaWriteFailOnMissing(t *testing.T) {
+	require := require.New(t)
+
+	conn, cleanup, _, _ := testserver.NewTestServer(require, 0, memdb.DisableGC, true, tf.StandardDatastoreWithData)
+	client := v1.NewPermissionsServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	toTouch := tuple.MustParse("document:totallynew#viewer@user:tom")
+	toDelete := tuple.MustParse("document:totallynew#parent@folder:plans")
+	ctx := metadata.AppendToOutgoingContext(context.Background(), v1svc.WriteRelationshipsFailOnMissingDeleteHeaderKey, "true")
+
+	// Deleting the non-existent relationship fails, and the other updates are not applied.
+	_, err := client.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{
+			tuple.UpdateToRelationshipUpdate(tuple.Touch(toTouch)),
+			tuple.UpdateToRelationshipUpdate(tuple.Delete(toDelete)),
+		},
+	})
+	grpcutil.RequireStatus(t, codes.NotFound, err)
+	require.ErrorContains(err, "cannot delete relationship `document:totallynew#parent@folder:plans`, as it does not exist")
+
+	stream, err := client.ReadRelationships(context.Background(), &v1.ReadRelationshipsRequest{
+		Consistency:        &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+		RelationshipFilter: &v1.RelationshipFilter{ResourceType: "document", OptionalResourceId: "totallynew"},
+	})
+	require.NoError(err)
+	_, err = stream.Recv()
+	require.ErrorIs(err, io.EOF)
+
+	// Once it exists, deleting it succeeds.
+	_, err = client.WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{tuple.UpdateToRelationshipUpdate(tuple.Touch(toDelete))},
+	})
+	require.NoError(err)
+
+	_, err = client.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{tuple.UpdateToRelationshipUpdate(tuple.Delete(toDelete))},
+	})
+	require.NoError(err)
+
+	invalidCtx := metadata.AppendToOutgoingContext(context.Background(), v1svc.WriteRelationshipsFailOnMissingDeleteHeaderKey, "maybe")
+	_, err = client.WriteRelationships(invalidCtx, &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{tuple.UpdateToRelationshipUpdate(tuple.Delete(toDelete))},
+	})
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+}
+
 func TestWriteCaveatedRelationships(t *testing.T) {
 	for _, deleteWithCaveat := range []bool{true, false} {
 		t.Run(fmt.Sprintf("with-caveat-%v", deleteWithCaveat), func(t *testing.T) {
@@ -1284,6 +1336,164 @@ func TestWriteRelationshipsUpdatesOverLimit(t *testing.T) {
 
 	require.Error(err)
 	require.Contains(err.Error(), "update count of 2 is greater than maximum allowed of 1")
+	grpcutil.RequireStatus(t, codes.ResourceExhausted, err)
+	spiceerrors.RequireReason(t, v1.ErrorReason_ERROR_REASON_TOO_MANY_UPDATES_IN_REQUEST, err, "update_count", "maximum_updates_allowed")
+}
+
+func TestWriteRelationshipsAtMaximumIsAtomic(t *testing.T) {
+	require := require.New(t)
+	conn, cleanup, _, _ := testserver.NewTestServer(require, 0, memdb.DisableGC, true, tf.StandardDatastoreWithData)
+	client := v1.NewPermissionsServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	const maxUpdates = 1000
+	updates := func(resourceID string, operation v1.RelationshipUpdate_Operation) []*v1.RelationshipUpdate {
+		updates := make([]*v1.RelationshipUpdate, 0, maxUpdates)
+		for i := 0; i < maxUpdates; i++ {
+			updates = append(updates, &v1.RelationshipUpdate{
+				Operation:    operation,
+				Relationship: rel("document", resourceID, "viewer", "user", fmt.Sprintf("user%d", i), ""),
+			})
+		}
+		return updates
+	}
+
+	countViewers := func(resourceID string, consistency *v1.Consistency) int {
+		stream, err := client.ReadRelationships(context.Background(), &v1.ReadRelationshipsRequest{
+			Consistency:        consistency,
+			RelationshipFilter: &v1.RelationshipFilter{ResourceType: "document", OptionalResourceId: resourceID},
+		})
+		require.NoError(err)
+
+		count := 0
+		for {
+			_, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return count
+			}
+			require.NoError(err)
+			count++
+		}
+	}
+
+	// All of the updates are visible at the returned revision.
+	resp, err := client.WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{
+		Updates: updates("atomic", v1.RelationshipUpdate_OPERATION_CREATE),
+	})
+	require.NoError(err)
+	require.Equal(maxUpdates, countViewers("atomic", &v1.Consistency{
+		Requirement: &v1.Consistency_AtExactSnapshot{AtExactSnapshot: resp.WrittenAt},
+	}))
+
+	fullyConsistent := &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}}
+
+	// A write whose last update fails in the datastore applies none of the others.
+	failingInDatastore := updates("failed", v1.RelationshipUpdate_OPERATION_CREATE)
+	failingInDatastore[maxUpdates-1].Relationship = rel("document", "atomic", "viewer", "user", "user0", "")
+	_, err = client.WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{Updates: failingInDatastore})
+	grpcutil.RequireStatus(t, codes.AlreadyExists, err)
+	require.Equal(0, countViewers("failed", fullyConsistent))
+
+	// A write whose last update fails validation applies none of the others.
+	failingValidation := updates("failed", v1.RelationshipUpdate_OPERATION_TOUCH)
+	failingValidation[maxUpdates-1].Relationship = rel("document", "failed", "viewer", "folder", "somefolder", "")
+	_, err = client.WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{Updates: failingValidation})
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+	require.Equal(0, countViewers("failed", fullyConsistent))
+
+	// A write which deletes the relationships and then fails leaves them all in place.
+	failingDelete := updates("atomic", v1.RelationshipUpdate_OPERATION_DELETE)
+	failingDelete[maxUpdates-1] = &v1.RelationshipUpdate{
+		Operation:    v1.RelationshipUpdate_OPERATION_CREATE,
+		Relationship: rel("document", "masterplan", "parent", "folder", "plans", ""),
+	}
+	_, err = client.WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{Updates: failingDelete})
+	grpcutil.RequireStatus(t, codes.AlreadyExists, err)
+	require.Equal(maxUpdates, countViewers("atomic", fullyConsistent))
+}
+
+func TestReadRelationshipsLimitOverMaximum(t *testing.T) {
+	require := require.New(t)
+	conn, cleanup, _, _ := testserver.NewTestServerWithConfig(
+		require,
+		testTimedeltas[0],
+		memdb.DisableGC,
+		true,
+		testserver.ServerConfig{
+			MaxPreconditionsCount:     1000,
+			MaxUpdatesPerWrite:        1000,
+			MaxReadRelationshipsLimit: 5,
+		},
+		tf.StandardDatastoreWithData,
+	)
+	client := v1.NewPermissionsServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	readLimited := func(limit uint32) error {
+		stream, err := client.ReadRelationships(context.Background(), &v1.ReadRelationshipsRequest{
+			RelationshipFilter: &v1.RelationshipFilter{ResourceType: "document"},
+			OptionalLimit:      limit,
+		})
+		require.NoError(err)
+
+		for {
+			_, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	require.NoError(readLimited(5))
+
+	err := readLimited(6)
+	require.Error(err)
+	require.Contains(err.Error(), "provided limit 6 is greater than maximum allowed of 5")
+	grpcutil.RequireStatus(t, codes.ResourceExhausted, err)
+}
+
+func TestReadRelationshipsDefaultLimit(t *testing.T) {
+	require := require.New(t)
+	conn, cleanup, _, _ := testserver.NewTestServerWithConfig(
+		require,
+		testTimedeltas[0],
+		memdb.DisableGC,
+		true,
+		testserver.ServerConfig{
+			MaxPreconditionsCount:         1000,
+			MaxUpdatesPerWrite:            1000,
+			MaxReadRelationshipsLimit:     5,
+			DefaultReadRelationshipsLimit: 2,
+		},
+		tf.StandardDatastoreWithData,
+	)
+	client := v1.NewPermissionsServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	readCount := func(limit uint32) int {
+		stream, err := client.ReadRelationships(context.Background(), &v1.ReadRelationshipsRequest{
+			RelationshipFilter: &v1.RelationshipFilter{ResourceType: "document"},
+			OptionalLimit:      limit,
+		})
+		require.NoError(err)
+
+		count := 0
+		for {
+			_, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				return count
+			}
+			require.NoError(err)
+			count++
+		}
+	}
+
+	// Calls without a limit are limited by the default, and those with one by their own.
+	require.Equal(2, readCount(0))
+	require.Equal(4, readCount(4))
 }
 
 func TestWriteRelationshipsCaveatExceedsMaxSize(t *testing.T) {
@@ -1320,6 +1530,121 @@ func TestWriteRelationshipsCaveatExceedsMaxSize(t *testing.T) {
 	require.ErrorContains(err, "exceeded maximum allowed caveat size of 1")
 }
 
+func TestWriteRelationshipsWithTransactionMetadata(t *testing.T) {
+	require := require.New(t)
+	conn, cleanup, ds, revision := testserver.NewTestServer(require, 0, memdb.DisableGC, true, tf.StandardDatastoreWithData)
+	client := v1.NewPermissionsServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes, errchan := ds.Watch(ctx, revision, datastore.WatchJustRelationships())
+
+	writeCtx := metadata.AppendToOutgoingContext(ctx,
+		v1svc.TransactionMetadataHeaderPrefix+"actor", "alice",
+		v1svc.TransactionMetadataHeaderPrefix+"reason", "onboarding",
+	)
+	_, err := client.WriteRelationships(writeCtx, &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{
+			tuple.UpdateToRelationshipUpdate(tuple.Touch(tuple.MustParse("document:newdoc#viewer@user:tom"))),
+		},
+	})
+	require.NoError(err)
+
+	select {
+	case change := <-changes:
+		require.Equal(map[string]string{"actor": "alice", "reason": "onboarding"}, change.Metadata)
+	case err := <-errchan:
+		require.FailNow("unexpected watch error", err)
+	case <-time.After(5 * time.Second):
+		require.FailNow("timed out waiting for change")
+	}
+
+	// Metadata exceeding the maximum size is rejected.
+	tooLargeCtx := metadata.AppendToOutgoingContext(ctx,
+		v1svc.TransactionMetadataHeaderPrefix+"actor", strings.Repeat("a", 5000),
+	)
+	_, err = client.WriteRelationships(tooLargeCtx, &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{
+			tuple.UpdateToRelationshipUpdate(tuple.Touch(tuple.MustParse("document:newdoc#viewer@user:sarah"))),
+		},
+	})
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+}
+
+func TestRelationshipLabels(t *testing.T) {
+	require := require.New(t)
+	conn, cleanup, _, _ := testserver.NewTestServer(require, 0, memdb.DisableGC, true, tf.StandardDatastoreWithData)
+	client := v1.NewPermissionsServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	ctx := context.Background()
+	write := func(ctx context.Context, rels ...string) error {
+		updates := make([]*v1.RelationshipUpdate, 0, len(rels))
+		for _, rel := range rels {
+			updates = append(updates, tuple.UpdateToRelationshipUpdate(tuple.Touch(tuple.MustParse(rel))))
+		}
+		_, err := client.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{Updates: updates})
+		return err
+	}
+	read := func(ctx context.Context) []string {
+		stream, err := client.ReadRelationships(ctx, &v1.ReadRelationshipsRequest{
+			Consistency:        &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+			RelationshipFilter: &v1.RelationshipFilter{ResourceType: "document", OptionalRelation: "viewer"},
+		})
+		require.NoError(err)
+
+		var found []string
+		for {
+			resp, err := stream.Recv()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			require.NoError(err)
+			found = append(found, tuple.MustStringRelationship(resp.Relationship))
+		}
+		sort.Strings(found)
+		return found
+	}
+
+	crmCtx := metadata.AppendToOutgoingContext(ctx,
+		v1svc.RelationshipLabelHeaderPrefix+"source", "crm",
+		v1svc.RelationshipLabelHeaderPrefix+"batch", "1",
+	)
+	require.NoError(write(crmCtx, "document:labeled1#viewer@user:tom", "document:labeled2#viewer@user:tom"))
+	require.NoError(write(metadata.AppendToOutgoingContext(ctx, v1svc.RelationshipLabelHeaderPrefix+"source", "erp"), "document:labeled3#viewer@user:tom"))
+
+	filterCtx := metadata.AppendToOutgoingContext(ctx, v1svc.RelationshipLabelFilterHeaderPrefix+"source", "crm")
+	require.Equal([]string{
+		"document:labeled1#viewer@user:tom",
+		"document:labeled2#viewer@user:tom",
+	}, read(filterCtx))
+	require.Greater(len(read(ctx)), 3)
+
+	// Only the relationships with the labels are deleted.
+	_, err := client.DeleteRelationships(filterCtx, &v1.DeleteRelationshipsRequest{
+		RelationshipFilter:            &v1.RelationshipFilter{ResourceType: "document"},
+		OptionalLimit:                 1,
+		OptionalAllowPartialDeletions: true,
+	})
+	require.NoError(err)
+	require.Len(read(filterCtx), 1)
+
+	_, err = client.DeleteRelationships(filterCtx, &v1.DeleteRelationshipsRequest{
+		RelationshipFilter: &v1.RelationshipFilter{ResourceType: "document"},
+	})
+	require.NoError(err)
+	require.Empty(read(filterCtx))
+	require.Equal([]string{"document:labeled3#viewer@user:tom"}, read(metadata.AppendToOutgoingContext(ctx, v1svc.RelationshipLabelFilterHeaderPrefix+"source", "erp")))
+
+	// Invalid labels are rejected.
+	err = write(metadata.AppendToOutgoingContext(ctx, v1svc.RelationshipLabelHeaderPrefix+"_source", "crm"), "document:labeled4#viewer@user:tom")
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+	err = write(metadata.AppendToOutgoingContext(ctx, v1svc.RelationshipLabelHeaderPrefix+"source", strings.Repeat("a", 200)), "document:labeled4#viewer@user:tom")
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+}
+
 func TestReadRelationshipsWithTimeout(t *testing.T) {
 	require := require.New(t)
 
@@ -0,0 +1,41 @@
+package v1
+
+import (
+	"context"
+	"strings"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// ResourceIDPrefixHeaderKey is the request metadata key holding a prefix which limits
+// ReadRelationships and DeleteRelationships requests to the relationships whose resource ID
+// starts with it. It cannot be combined with the `optional_resource_id` of the filter.
+const ResourceIDPrefixHeaderKey = "io.spicedb.resourceidprefix"
+
+// resourceIDPrefix returns the resource ID prefix requested via the ResourceIDPrefixHeaderKey
+// header, if any, ensuring that it can be applied to the filter.
+func resourceIDPrefix(ctx context.Context, filter *v1.RelationshipFilter) (string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", nil
+	}
+
+	values := md.Get(ResourceIDPrefixHeaderKey)
+	if len(values) == 0 {
+		return "", nil
+	}
+
+	prefix := values[0]
+	if prefix == "" || strings.Contains(prefix, "%") {
+		return "", status.Errorf(codes.InvalidArgument, "invalid value for %s: must be non-empty and must not contain `%%`", ResourceIDPrefixHeaderKey)
+	}
+
+	if filter.GetOptionalResourceId() != "" {
+		return "", status.Errorf(codes.InvalidArgument, "a resource ID cannot be given when filtering by %s", ResourceIDPrefixHeaderKey)
+	}
+
+	return prefix, nil
+}
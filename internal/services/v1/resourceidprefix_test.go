@@ -0,0 +1,90 @@
+package v1_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/authzed/grpcutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	v1svc "github.com/authzed/spicedb/internal/services/v1"
+	tf "github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+)
+
+func readResourceIDs(t *testing.T, ctx context.Context, client v1.PermissionsServiceClient, filter *v1.RelationshipFilter) []string {
+	stream, err := client.ReadRelationships(ctx, &v1.ReadRelationshipsRequest{Consistency: fullyConsistent, RelationshipFilter: filter})
+	require.NoError(t, err)
+
+	var ids []string
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return ids
+		}
+		require.NoError(t, err)
+		ids = append(ids, resp.Relationship.Resource.ObjectId)
+	}
+}
+
+func TestResourceIDPrefix(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.StandardDatastoreWithData)
+	client := v1.NewPermissionsServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	filter := &v1.RelationshipFilter{ResourceType: "document"}
+	prefixCtx := metadata.AppendToOutgoingContext(context.Background(), v1svc.ResourceIDPrefixHeaderKey, "ma")
+
+	var expected, remaining []string
+	for _, id := range readResourceIDs(t, context.Background(), client, filter) {
+		if strings.HasPrefix(id, "ma") {
+			expected = append(expected, id)
+		} else {
+			remaining = append(remaining, id)
+		}
+	}
+	require.NotEmpty(t, expected)
+	require.NotEmpty(t, remaining)
+
+	require.ElementsMatch(t, expected, readResourceIDs(t, prefixCtx, client, filter))
+
+	_, err := client.DeleteRelationships(prefixCtx, &v1.DeleteRelationshipsRequest{RelationshipFilter: filter})
+	require.NoError(t, err)
+	require.ElementsMatch(t, remaining, readResourceIDs(t, context.Background(), client, filter))
+}
+
+func TestResourceIDPrefixErrors(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.StandardDatastoreWithData)
+	client := v1.NewPermissionsServiceClient(conn)
+	t.Cleanup(cleanup)
+
+	for _, tc := range []struct {
+		name   string
+		prefix string
+		filter *v1.RelationshipFilter
+	}{
+		{"empty prefix", "", &v1.RelationshipFilter{ResourceType: "document"}},
+		{"wildcard prefix", "ma%", &v1.RelationshipFilter{ResourceType: "document"}},
+		{"with resource ID", "ma", &v1.RelationshipFilter{ResourceType: "document", OptionalResourceId: "masterplan"}},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := metadata.AppendToOutgoingContext(context.Background(), v1svc.ResourceIDPrefixHeaderKey, tc.prefix)
+
+			stream, err := client.ReadRelationships(ctx, &v1.ReadRelationshipsRequest{Consistency: fullyConsistent, RelationshipFilter: tc.filter})
+			require.NoError(t, err)
+			_, err = stream.Recv()
+			grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+
+			_, err = client.DeleteRelationships(ctx, &v1.DeleteRelationshipsRequest{RelationshipFilter: tc.filter})
+			grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+		})
+	}
+}
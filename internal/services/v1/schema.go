@@ -2,27 +2,90 @@ package v1
 
 import (
 	"context"
+	"encoding/json"
+	"slices"
+	"strconv"
+	"strings"
 
+	"github.com/authzed/authzed-go/pkg/responsemeta"
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	grpcvalidate "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/validator"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
 
 	log "github.com/authzed/spicedb/internal/logging"
 	"github.com/authzed/spicedb/internal/middleware"
 	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/middleware/tenancy"
 	"github.com/authzed/spicedb/internal/middleware/usagemetrics"
 	"github.com/authzed/spicedb/internal/services/shared"
 	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
 	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
 	"github.com/authzed/spicedb/pkg/schemadsl/generator"
 	"github.com/authzed/spicedb/pkg/schemadsl/input"
+	"github.com/authzed/spicedb/pkg/schemautil"
 	"github.com/authzed/spicedb/pkg/zedtoken"
 )
 
+// ReadSchemaAtRevisionHeaderKey is the request metadata key holding a ZedToken at which ReadSchema
+// should read the schema, rather than the head revision. This allows for reading prior versions
+// of the schema, so long as the revision falls within the datastore's garbage collection window.
+const ReadSchemaAtRevisionHeaderKey = "io.spicedb.readschemaatrevision"
+
+// ReadSchemaLimitHeaderKey is the request metadata key holding the maximum number of object
+// definitions returned by ReadSchema, in order of name. When given, the schema text holds only the
+// object definitions of the page, without caveats, and if more remain, the cursor from which to
+// read the next page is returned in the ReadSchemaCursorTrailerKey response trailer. To list the
+// definitions of a single revision, pass the ReadAt of the first page via the
+// ReadSchemaAtRevisionHeaderKey header when reading the following pages.
+const ReadSchemaLimitHeaderKey = "io.spicedb.schemalimit"
+
+// ReadSchemaCursorHeaderKey is the request metadata key holding the cursor returned by a previous
+// page of ReadSchema, from which to continue. It requires the ReadSchemaLimitHeaderKey header.
+const ReadSchemaCursorHeaderKey = "io.spicedb.schemacursor"
+
+// ReadSchemaCursorTrailerKey is the key in the response trailer metadata holding the cursor from
+// which to read the next page of object definitions, if any remain.
+const ReadSchemaCursorTrailerKey responsemeta.ResponseMetadataTrailerKey = "io.spicedb.respmeta.schemacursor"
+
+// ReadSchemaReflectionHeaderKey is the request metadata key which, when `true`, makes ReadSchema
+// also return a structured view of the schema read, as JSON in the SchemaReflectionTrailerKey
+// response trailer. It lists each definition with its relations, their allowed subject types and
+// its permissions, along with each caveat and its parameters, for tooling which would otherwise
+// have to parse the schema text.
+const ReadSchemaReflectionHeaderKey = "io.spicedb.schemareflection"
+
+// SchemaReflectionTrailerKey is the key in the response trailer metadata holding the reflection of
+// the schema, when requested via the ReadSchemaReflectionHeaderKey header.
+const SchemaReflectionTrailerKey responsemeta.ResponseMetadataTrailerKey = "io.spicedb.respmeta.schemareflection"
+
+// WriteSchemaDeleteRelationshipsHeaderKey is the request metadata key which, when `true`, makes
+// WriteSchema delete the relationships of object definitions removed from the schema, along with
+// the relationships referencing them, in the same transaction. Otherwise, object definitions with
+// relationships cannot be removed.
+const WriteSchemaDeleteRelationshipsHeaderKey = "io.spicedb.deleterelationships"
+
+// WriteSchemaExpectedRevisionHeaderKey is the request metadata key holding a ZedToken, such as that
+// returned by ReadSchema, at which the schema being replaced was read. If any definition has been
+// written or deleted since that revision, WriteSchema fails with FAILED_PRECONDITION rather than
+// overwriting the changes.
+const WriteSchemaExpectedRevisionHeaderKey = "io.spicedb.schemaexpectedrevision"
+
 // NewSchemaServer creates a SchemaServiceServer instance.
 func NewSchemaServer(additiveOnly bool) v1.SchemaServiceServer {
+	return newSchemaServer(additiveOnly, false)
+}
+
+// NewReadOnlySchemaServer creates a SchemaServiceServer instance which serves reads of the
+// schema, but rejects writes with UNIMPLEMENTED.
+func NewReadOnlySchemaServer() v1.SchemaServiceServer {
+	return newSchemaServer(false, true)
+}
+
+func newSchemaServer(additiveOnly, readOnly bool) v1.SchemaServiceServer {
 	return &schemaServer{
 		WithServiceSpecificInterceptors: shared.WithServiceSpecificInterceptors{
 			Unary: middleware.ChainUnaryServer(
@@ -35,6 +98,7 @@ func NewSchemaServer(additiveOnly bool) v1.SchemaServiceServer {
 			),
 		},
 		additiveOnly: additiveOnly,
+		readOnly:     readOnly,
 	}
 }
 
@@ -43,6 +107,7 @@ type schemaServer struct {
 	shared.WithServiceSpecificInterceptors
 
 	additiveOnly bool
+	readOnly     bool
 }
 
 func (ss *schemaServer) rewriteError(ctx context.Context, err error) error {
@@ -50,14 +115,14 @@ func (ss *schemaServer) rewriteError(ctx context.Context, err error) error {
 }
 
 func (ss *schemaServer) ReadSchema(ctx context.Context, _ *v1.ReadSchemaRequest) (*v1.ReadSchemaResponse, error) {
-	// Schema is always read from the head revision.
+	// Schema is read from the head revision, unless a prior revision was requested.
 	ds := datastoremw.MustFromContext(ctx)
-	headRevision, err := ds.HeadRevision(ctx)
+	readRevision, err := schemaReadRevision(ctx, ds)
 	if err != nil {
 		return nil, ss.rewriteError(ctx, err)
 	}
 
-	reader := ds.SnapshotReader(headRevision)
+	reader := ds.SnapshotReader(readRevision)
 
 	nsDefs, err := reader.ListAllNamespaces(ctx)
 	if err != nil {
@@ -69,17 +134,34 @@ func (ss *schemaServer) ReadSchema(ctx context.Context, _ *v1.ReadSchemaRequest)
 		return nil, ss.rewriteError(ctx, err)
 	}
 
-	if len(nsDefs) == 0 {
+	objectDefinitions := datastore.DefinitionsOf(nsDefs)
+	caveatDefinitions := datastore.DefinitionsOf(caveatDefs)
+	if tenant, ok := tenancy.FromContext(ctx); ok {
+		objectDefinitions, caveatDefinitions, err = tenantDefinitions(tenant, objectDefinitions, caveatDefinitions)
+		if err != nil {
+			return nil, ss.rewriteError(ctx, err)
+		}
+	}
+
+	if len(objectDefinitions) == 0 {
 		return nil, status.Errorf(codes.NotFound, "No schema has been defined; please call WriteSchema to start")
 	}
 
-	schemaDefinitions := make([]compiler.SchemaDefinition, 0, len(nsDefs)+len(caveatDefs))
-	for _, caveatDef := range caveatDefs {
-		schemaDefinitions = append(schemaDefinitions, caveatDef.Definition)
+	objectDefinitions, paged, err := schemaPage(ctx, objectDefinitions)
+	if err != nil {
+		return nil, ss.rewriteError(ctx, err)
+	}
+	if paged {
+		caveatDefinitions = nil
+	}
+
+	schemaDefinitions := make([]compiler.SchemaDefinition, 0, len(objectDefinitions)+len(caveatDefinitions))
+	for _, caveatDef := range caveatDefinitions {
+		schemaDefinitions = append(schemaDefinitions, caveatDef)
 	}
 
-	for _, nsDef := range nsDefs {
-		schemaDefinitions = append(schemaDefinitions, nsDef.Definition)
+	for _, nsDef := range objectDefinitions {
+		schemaDefinitions = append(schemaDefinitions, nsDef)
 	}
 
 	schemaText, _, err := generator.GenerateSchema(schemaDefinitions)
@@ -87,19 +169,279 @@ func (ss *schemaServer) ReadSchema(ctx context.Context, _ *v1.ReadSchemaRequest)
 		return nil, ss.rewriteError(ctx, err)
 	}
 
+	if err := setSchemaReflection(ctx, objectDefinitions, caveatDefinitions); err != nil {
+		return nil, ss.rewriteError(ctx, err)
+	}
+
 	usagemetrics.SetInContext(ctx, &dispatchv1.ResponseMeta{
-		DispatchCount: uint32(len(nsDefs) + len(caveatDefs)),
+		DispatchCount: uint32(len(objectDefinitions) + len(caveatDefinitions)),
 	})
 
 	return &v1.ReadSchemaResponse{
 		SchemaText: schemaText,
-		ReadAt:     zedtoken.MustNewFromRevision(headRevision),
+		ReadAt:     zedtoken.MustNewFromRevision(readRevision),
 	}, nil
 }
 
+// tenantDefinitions returns copies of the definitions of the tenant, without its prefix.
+func tenantDefinitions(tenant string, objectDefs []*core.NamespaceDefinition, caveatDefs []*core.CaveatDefinition) ([]*core.NamespaceDefinition, []*core.CaveatDefinition, error) {
+	ownedObjectDefs := make([]*core.NamespaceDefinition, 0, len(objectDefs))
+	for _, def := range ownedDefinitions(tenant, objectDefs) {
+		ownedObjectDefs = append(ownedObjectDefs, def.CloneVT())
+	}
+
+	ownedCaveatDefs := make([]*core.CaveatDefinition, 0, len(caveatDefs))
+	for _, def := range ownedDefinitions(tenant, caveatDefs) {
+		ownedCaveatDefs = append(ownedCaveatDefs, def.CloneVT())
+	}
+
+	if err := tenancy.StripDefinitions(tenant, ownedObjectDefs, ownedCaveatDefs); err != nil {
+		return nil, nil, err
+	}
+	return ownedObjectDefs, ownedCaveatDefs, nil
+}
+
+// ownedDefinitions returns the definitions which belong to the tenant.
+func ownedDefinitions[T datastore.SchemaDefinition](tenant string, defs []T) []T {
+	owned := make([]T, 0, len(defs))
+	for _, def := range defs {
+		if tenancy.OwnsDefinition(tenant, def.GetName()) {
+			owned = append(owned, def)
+		}
+	}
+	return owned
+}
+
+// schemaPage returns the page of the object definitions requested via the ReadSchemaLimitHeaderKey
+// and ReadSchemaCursorHeaderKey headers, setting the ReadSchemaCursorTrailerKey response trailer if
+// more remain, and whether a page was requested at all.
+func schemaPage(ctx context.Context, objectDefs []*core.NamespaceDefinition) ([]*core.NamespaceDefinition, bool, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return objectDefs, false, nil
+	}
+
+	var afterName string
+	if values := md.Get(ReadSchemaCursorHeaderKey); len(values) > 0 {
+		afterName = values[0]
+	}
+
+	values := md.Get(ReadSchemaLimitHeaderKey)
+	if len(values) == 0 {
+		if afterName != "" {
+			return nil, false, status.Errorf(codes.InvalidArgument, "a cursor cannot be given without %s", ReadSchemaLimitHeaderKey)
+		}
+		return objectDefs, false, nil
+	}
+
+	limit, err := strconv.ParseUint(values[0], 10, 32)
+	if err != nil || limit == 0 {
+		return nil, false, status.Errorf(codes.InvalidArgument, "invalid value for %s: must be a positive integer", ReadSchemaLimitHeaderKey)
+	}
+
+	sorted := slices.Clone(objectDefs)
+	slices.SortFunc(sorted, func(a, b *core.NamespaceDefinition) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	start, _ := slices.BinarySearchFunc(sorted, afterName, func(def *core.NamespaceDefinition, name string) int {
+		if def.Name <= name {
+			return -1
+		}
+		return 1
+	})
+	page := sorted[start:]
+	if uint64(len(page)) <= limit {
+		return page, true, nil
+	}
+
+	page = page[:limit]
+	return page, true, responsemeta.SetResponseTrailerMetadata(ctx, map[responsemeta.ResponseMetadataTrailerKey]string{
+		ReadSchemaCursorTrailerKey: page[len(page)-1].Name,
+	})
+}
+
+// setSchemaReflection sets the SchemaReflectionTrailerKey response trailer to the reflection of the
+// definitions, if requested via the ReadSchemaReflectionHeaderKey header.
+func setSchemaReflection(ctx context.Context, objectDefs []*core.NamespaceDefinition, caveatDefs []*core.CaveatDefinition) error {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil
+	}
+
+	values := md.Get(ReadSchemaReflectionHeaderKey)
+	if len(values) == 0 {
+		return nil
+	}
+
+	reflect, err := strconv.ParseBool(values[0])
+	if err != nil {
+		return status.Errorf(codes.InvalidArgument, "invalid value for %s: %s", ReadSchemaReflectionHeaderKey, err)
+	}
+	if !reflect {
+		return nil
+	}
+
+	encoded, err := json.Marshal(schemautil.ReflectSchema(objectDefs, caveatDefs))
+	if err != nil {
+		return err
+	}
+
+	return responsemeta.SetResponseTrailerMetadata(ctx, map[responsemeta.ResponseMetadataTrailerKey]string{
+		SchemaReflectionTrailerKey: string(encoded),
+	})
+}
+
+// schemaReadRevision returns the revision at which to read the schema: either that requested
+// via the ReadSchemaAtRevisionHeaderKey header or the head revision.
+func schemaReadRevision(ctx context.Context, ds datastore.Datastore) (datastore.Revision, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(ReadSchemaAtRevisionHeaderKey); len(values) > 0 {
+			requestedRev, err := zedtoken.DecodeRevision(&v1.ZedToken{Token: values[0]}, ds)
+			if err != nil {
+				return datastore.NoRevision, status.Errorf(codes.InvalidArgument, "invalid revision requested for schema read: %s", err)
+			}
+
+			if err := ds.CheckRevision(ctx, requestedRev); err != nil {
+				return datastore.NoRevision, err
+			}
+
+			return requestedRev, nil
+		}
+	}
+
+	return ds.HeadRevision(ctx)
+}
+
+// schemaExpectedRevision returns the revision at which the schema being replaced was read, as given
+// via the WriteSchemaExpectedRevisionHeaderKey header, or nil if none was given.
+func schemaExpectedRevision(ctx context.Context, ds datastore.Datastore) (datastore.Revision, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(WriteSchemaExpectedRevisionHeaderKey); len(values) > 0 {
+			expectedRev, err := zedtoken.DecodeRevision(&v1.ZedToken{Token: values[0]}, ds)
+			if err != nil {
+				return nil, status.Errorf(codes.InvalidArgument, "invalid expected revision for schema write: %s", err)
+			}
+
+			if err := ds.CheckRevision(ctx, expectedRev); err != nil {
+				return nil, err
+			}
+
+			return expectedRev, nil
+		}
+	}
+	return nil, nil
+}
+
+// ensureSchemaUnchangedSince returns an ErrSchemaChanged if any definition of the schema has been
+// written or deleted since the revision.
+func ensureSchemaUnchangedSince(ctx context.Context, ds datastore.Datastore, rwt datastore.ReadWriteTransaction, expectedRev datastore.Revision) error {
+	currentNamespaces, err := rwt.ListAllNamespaces(ctx)
+	if err != nil {
+		return err
+	}
+
+	currentCaveats, err := rwt.ListAllCaveats(ctx)
+	if err != nil {
+		return err
+	}
+
+	reader := ds.SnapshotReader(expectedRev)
+	expectedNamespaces, err := reader.ListAllNamespaces(ctx)
+	if err != nil {
+		return err
+	}
+
+	expectedCaveats, err := reader.ListAllCaveats(ctx)
+	if err != nil {
+		return err
+	}
+
+	if tenant, ok := tenancy.FromContext(ctx); ok {
+		currentNamespaces = ownedRevisionedDefinitions(tenant, currentNamespaces)
+		currentCaveats = ownedRevisionedDefinitions(tenant, currentCaveats)
+		expectedNamespaces = ownedRevisionedDefinitions(tenant, expectedNamespaces)
+		expectedCaveats = ownedRevisionedDefinitions(tenant, expectedCaveats)
+	}
+
+	name, conflictingRev, changed := changedDefinition(currentNamespaces, expectedNamespaces, expectedRev)
+	if !changed {
+		name, conflictingRev, changed = changedDefinition(currentCaveats, expectedCaveats, expectedRev)
+	}
+	if !changed {
+		return nil
+	}
+
+	// Deletions do not record their revision, so they are reported at the head revision.
+	if conflictingRev == nil {
+		conflictingRev, err = ds.HeadRevision(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return NewSchemaChangedErr(name, expectedRev, conflictingRev)
+}
+
+// ownedRevisionedDefinitions returns the definitions which belong to the tenant.
+func ownedRevisionedDefinitions[T datastore.SchemaDefinition](tenant string, defs []datastore.RevisionedDefinition[T]) []datastore.RevisionedDefinition[T] {
+	owned := make([]datastore.RevisionedDefinition[T], 0, len(defs))
+	for _, def := range defs {
+		if tenancy.OwnsDefinition(tenant, def.Definition.GetName()) {
+			owned = append(owned, def)
+		}
+	}
+	return owned
+}
+
+// changedDefinition returns the name of a definition which has been written since the revision,
+// along with the revision at which it was written, or the name of a definition which has been
+// deleted since, with no revision.
+func changedDefinition[T datastore.SchemaDefinition](current, expected []datastore.RevisionedDefinition[T], expectedRev datastore.Revision) (string, datastore.Revision, bool) {
+	var changedName string
+	var changedRev datastore.Revision
+	currentNames := make(map[string]struct{}, len(current))
+	for _, def := range current {
+		currentNames[def.Definition.GetName()] = struct{}{}
+		if def.LastWrittenRevision.GreaterThan(expectedRev) && (changedRev == nil || def.LastWrittenRevision.GreaterThan(changedRev)) {
+			changedName = def.Definition.GetName()
+			changedRev = def.LastWrittenRevision
+		}
+	}
+	if changedRev != nil {
+		return changedName, changedRev, true
+	}
+
+	for _, def := range expected {
+		if _, ok := currentNames[def.Definition.GetName()]; !ok {
+			return def.Definition.GetName(), nil, true
+		}
+	}
+	return "", nil, false
+}
+
+// schemaDeleteRelationships returns whether the relationships of removed object definitions were
+// requested to be deleted, via the WriteSchemaDeleteRelationshipsHeaderKey header.
+func schemaDeleteRelationships(ctx context.Context) (bool, error) {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(WriteSchemaDeleteRelationshipsHeaderKey); len(values) > 0 {
+			deleteRelationships, err := strconv.ParseBool(values[0])
+			if err != nil {
+				return false, status.Errorf(codes.InvalidArgument, "invalid value for %s: %s", WriteSchemaDeleteRelationshipsHeaderKey, err)
+			}
+			return deleteRelationships, nil
+		}
+	}
+	return false, nil
+}
+
 func (ss *schemaServer) WriteSchema(ctx context.Context, in *v1.WriteSchemaRequest) (*v1.WriteSchemaResponse, error) {
 	log.Ctx(ctx).Trace().Str("schema", in.GetSchema()).Msg("requested Schema to be written")
 
+	if ss.readOnly {
+		return nil, status.Error(codes.Unimplemented, "writes of the schema are disabled on this server")
+	}
+
 	ds := datastoremw.MustFromContext(ctx)
 
 	// Compile the schema into the namespace definitions.
@@ -110,17 +452,62 @@ func (ss *schemaServer) WriteSchema(ctx context.Context, in *v1.WriteSchemaReque
 	if err != nil {
 		return nil, ss.rewriteError(ctx, err)
 	}
+	tenant, isolated := tenancy.FromContext(ctx)
+	if isolated {
+		if err := tenancy.PrefixDefinitions(tenant, compiled.ObjectDefinitions, compiled.CaveatDefinitions); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
 	log.Ctx(ctx).Trace().Int("objectDefinitions", len(compiled.ObjectDefinitions)).Int("caveatDefinitions", len(compiled.CaveatDefinitions)).Msg("compiled namespace definitions")
 
+	for _, warning := range schemautil.Lint(compiled.ObjectDefinitions, schemautil.LintOptions{}) {
+		log.Ctx(ctx).Info().
+			Str("rule", string(warning.Rule)).
+			Str("definition", warning.Definition).
+			Str("relation", warning.Relation).
+			Str("warning", warning.Message).
+			Msg("schema lint warning")
+	}
+
 	// Do as much validation as we can before talking to the datastore.
 	validated, err := shared.ValidateSchemaChanges(ctx, compiled, ss.additiveOnly)
 	if err != nil {
 		return nil, ss.rewriteError(ctx, err)
 	}
 
+	expectedRevision, err := schemaExpectedRevision(ctx, ds)
+	if err != nil {
+		return nil, ss.rewriteError(ctx, err)
+	}
+
+	deleteRelationships, err := schemaDeleteRelationships(ctx)
+	if err != nil {
+		return nil, ss.rewriteError(ctx, err)
+	}
+	if deleteRelationships {
+		validated = validated.WithRelationshipDeletion()
+	}
+
+	isDryRun, err := dryRun(ctx)
+	if err != nil {
+		return nil, ss.rewriteError(ctx, err)
+	}
+
 	// Update the schema.
-	revision, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
-		applied, err := shared.ApplySchemaChanges(ctx, rwt, validated)
+	var applied *shared.AppliedSchemaChanges
+	writeFn := func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		if expectedRevision != nil {
+			if err := ensureSchemaUnchangedSince(ctx, ds, rwt, expectedRevision); err != nil {
+				return err
+			}
+		}
+
+		var err error
+		if isolated {
+			applied, err = applyTenantSchemaChanges(ctx, rwt, validated, tenant)
+		} else {
+			applied, err = shared.ApplySchemaChanges(ctx, rwt, validated)
+		}
 		if err != nil {
 			return err
 		}
@@ -128,7 +515,17 @@ func (ss *schemaServer) WriteSchema(ctx context.Context, in *v1.WriteSchemaReque
 			DispatchCount: applied.TotalOperationCount,
 		})
 		return nil
-	})
+	}
+
+	var revision datastore.Revision
+	if isDryRun {
+		revision, err = dryRunReadWriteTx(ctx, ds, writeFn)
+		if err == nil {
+			err = setDryRunSchemaTrailer(ctx, applied)
+		}
+	} else {
+		revision, err = ds.ReadWriteTx(ctx, writeFn)
+	}
 	if err != nil {
 		return nil, ss.rewriteError(ctx, err)
 	}
@@ -137,3 +534,25 @@ func (ss *schemaServer) WriteSchema(ctx context.Context, in *v1.WriteSchemaReque
 		WrittenAt: zedtoken.MustNewFromRevision(revision),
 	}, nil
 }
+
+// applyTenantSchemaChanges applies the schema changes of the tenant, replacing only the
+// definitions which belong to it.
+func applyTenantSchemaChanges(ctx context.Context, rwt datastore.ReadWriteTransaction, validated *shared.ValidatedSchemaChanges, tenant string) (*shared.AppliedSchemaChanges, error) {
+	existingCaveats, err := rwt.ListAllCaveats(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	existingObjectDefs, err := rwt.ListAllNamespaces(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return shared.ApplySchemaChangesOverExisting(
+		ctx,
+		rwt,
+		validated,
+		ownedDefinitions(tenant, datastore.DefinitionsOf(existingCaveats)),
+		ownedDefinitions(tenant, datastore.DefinitionsOf(existingObjectDefs)),
+	)
+}
@@ -2,6 +2,8 @@ package v1_test
 
 import (
 	"context"
+	"encoding/json"
+	"regexp"
 	"testing"
 
 	"google.golang.org/protobuf/types/known/structpb"
@@ -9,12 +11,16 @@ import (
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/authzed/grpcutil"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 
 	"github.com/authzed/spicedb/internal/datastore/memdb"
+	v1svc "github.com/authzed/spicedb/internal/services/v1"
 	tf "github.com/authzed/spicedb/internal/testfixtures"
 	"github.com/authzed/spicedb/internal/testserver"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/schemautil"
 	"github.com/authzed/spicedb/pkg/spiceerrors"
 	"github.com/authzed/spicedb/pkg/tuple"
 )
@@ -85,6 +91,215 @@ func TestSchemaWriteAndReadBack(t *testing.T) {
 	require.NotEmpty(t, readback.ReadAt.Token)
 }
 
+func TestSchemaReadAtRevision(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.EmptyDatastore)
+	t.Cleanup(cleanup)
+	client := v1.NewSchemaServiceClient(conn)
+
+	originalSchema := "definition example/document {\n\trelation viewer: example/user\n}\n\ndefinition example/user {}"
+	originalResp, err := client.WriteSchema(context.Background(), &v1.WriteSchemaRequest{
+		Schema: originalSchema,
+	})
+	require.NoError(t, err)
+
+	updatedSchema := "definition example/document {\n\trelation viewer: example/user\n\trelation editor: example/user\n}\n\ndefinition example/user {}"
+	_, err = client.WriteSchema(context.Background(), &v1.WriteSchemaRequest{
+		Schema: updatedSchema,
+	})
+	require.NoError(t, err)
+
+	readback, err := client.ReadSchema(context.Background(), &v1.ReadSchemaRequest{})
+	require.NoError(t, err)
+	require.Equal(t, updatedSchema, readback.SchemaText)
+
+	// Read the schema as it was at the first write.
+	ctx := metadata.AppendToOutgoingContext(context.Background(), v1svc.ReadSchemaAtRevisionHeaderKey, originalResp.WrittenAt.Token)
+	readback, err = client.ReadSchema(ctx, &v1.ReadSchemaRequest{})
+	require.NoError(t, err)
+	require.Equal(t, originalSchema, readback.SchemaText)
+	require.Equal(t, originalResp.WrittenAt.Token, readback.ReadAt.Token)
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), v1svc.ReadSchemaAtRevisionHeaderKey, "invalid")
+	_, err = client.ReadSchema(ctx, &v1.ReadSchemaRequest{})
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+}
+
+func TestSchemaReadReflection(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.EmptyDatastore)
+	t.Cleanup(cleanup)
+	client := v1.NewSchemaServiceClient(conn)
+
+	_, err := client.WriteSchema(context.Background(), &v1.WriteSchemaRequest{
+		Schema: `caveat only_on_tuesday(day_of_week string) {
+			day_of_week == 'tuesday'
+		}
+
+		definition example/user {}
+
+		definition example/document {
+			/** viewer can read the document */
+			relation viewer: example/user | example/user:* | example/user with only_on_tuesday
+			permission view = viewer
+		}`,
+	})
+	require.NoError(t, err)
+
+	var trailer metadata.MD
+	_, err = client.ReadSchema(context.Background(), &v1.ReadSchemaRequest{}, grpc.Trailer(&trailer))
+	require.NoError(t, err)
+	require.Empty(t, trailer.Get(string(v1svc.SchemaReflectionTrailerKey)))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), v1svc.ReadSchemaReflectionHeaderKey, "true")
+	_, err = client.ReadSchema(ctx, &v1.ReadSchemaRequest{}, grpc.Trailer(&trailer))
+	require.NoError(t, err)
+
+	encoded := trailer.Get(string(v1svc.SchemaReflectionTrailerKey))
+	require.Len(t, encoded, 1)
+
+	var reflection schemautil.SchemaReflection
+	require.NoError(t, json.Unmarshal([]byte(encoded[0]), &reflection))
+	require.Equal(t, schemautil.SchemaReflection{
+		Definitions: []schemautil.DefinitionReflection{
+			{
+				Name: "example/document",
+				Relations: []schemautil.RelationReflection{{
+					Name:    "viewer",
+					Comment: "/** viewer can read the document */",
+					SubjectTypes: []schemautil.SubjectTypeReflection{
+						{SubjectDefinitionName: "example/user"},
+						{SubjectDefinitionName: "example/user", IsPublicWildcard: true},
+						{SubjectDefinitionName: "example/user", OptionalCaveatName: "only_on_tuesday"},
+					},
+				}},
+				Permissions: []schemautil.PermissionReflection{{Name: "view", Expression: "viewer"}},
+			},
+			{Name: "example/user", Relations: []schemautil.RelationReflection{}, Permissions: []schemautil.PermissionReflection{}},
+		},
+		Caveats: []schemautil.CaveatReflection{{
+			Name:       "only_on_tuesday",
+			Parameters: []schemautil.CaveatParameterReflection{{Name: "day_of_week", TypeName: "string"}},
+		}},
+	}, reflection)
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), v1svc.ReadSchemaReflectionHeaderKey, "invalid")
+	_, err = client.ReadSchema(ctx, &v1.ReadSchemaRequest{})
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+}
+
+func TestSchemaReadPaginated(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.EmptyDatastore)
+	t.Cleanup(cleanup)
+	client := v1.NewSchemaServiceClient(conn)
+
+	_, err := client.WriteSchema(context.Background(), &v1.WriteSchemaRequest{
+		Schema: `caveat only_on_tuesday(day_of_week string) {
+			day_of_week == 'tuesday'
+		}
+
+		definition example/user {}
+		definition example/group {}
+		definition example/folder {}
+		definition example/document {}
+		definition example/organization {}`,
+	})
+	require.NoError(t, err)
+
+	definitionNames := regexp.MustCompile(`definition (\S+) \{`)
+
+	var names []string
+	var cursor, readAt string
+	for {
+		pairs := []string{v1svc.ReadSchemaLimitHeaderKey, "2"}
+		if cursor != "" {
+			pairs = append(pairs, v1svc.ReadSchemaCursorHeaderKey, cursor, v1svc.ReadSchemaAtRevisionHeaderKey, readAt)
+		}
+
+		var trailer metadata.MD
+		resp, err := client.ReadSchema(metadata.AppendToOutgoingContext(context.Background(), pairs...), &v1.ReadSchemaRequest{}, grpc.Trailer(&trailer))
+		require.NoError(t, err)
+		require.NotContains(t, resp.SchemaText, "caveat")
+
+		page := definitionNames.FindAllStringSubmatch(resp.SchemaText, -1)
+		require.LessOrEqual(t, len(page), 2)
+		for _, match := range page {
+			names = append(names, match[1])
+		}
+
+		if readAt == "" {
+			readAt = resp.ReadAt.Token
+		}
+		require.Equal(t, readAt, resp.ReadAt.Token)
+
+		cursors := trailer.Get(string(v1svc.ReadSchemaCursorTrailerKey))
+		if len(cursors) == 0 {
+			break
+		}
+		cursor = cursors[0]
+	}
+
+	require.Equal(t, []string{
+		"example/document",
+		"example/folder",
+		"example/group",
+		"example/organization",
+		"example/user",
+	}, names)
+
+	for _, pairs := range [][]string{
+		{v1svc.ReadSchemaLimitHeaderKey, "0"},
+		{v1svc.ReadSchemaLimitHeaderKey, "invalid"},
+		{v1svc.ReadSchemaCursorHeaderKey, "example/folder"},
+	} {
+		_, err = client.ReadSchema(metadata.AppendToOutgoingContext(context.Background(), pairs...), &v1.ReadSchemaRequest{})
+		grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+	}
+}
+
+func TestSchemaWriteExpectedRevision(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.EmptyDatastore)
+	t.Cleanup(cleanup)
+	client := v1.NewSchemaServiceClient(conn)
+
+	_, err := client.WriteSchema(context.Background(), &v1.WriteSchemaRequest{
+		Schema: "definition example/user {}\n\ndefinition example/document {\n\trelation viewer: example/user\n}",
+	})
+	require.NoError(t, err)
+
+	read, err := client.ReadSchema(context.Background(), &v1.ReadSchemaRequest{})
+	require.NoError(t, err)
+
+	// A write expecting the schema as it was read succeeds.
+	ctx := metadata.AppendToOutgoingContext(context.Background(), v1svc.WriteSchemaExpectedRevisionHeaderKey, read.ReadAt.Token)
+	written, err := client.WriteSchema(ctx, &v1.WriteSchemaRequest{
+		Schema: "definition example/user {}\n\ndefinition example/document {\n\trelation viewer: example/user\n\trelation editor: example/user\n}",
+	})
+	require.NoError(t, err)
+
+	// A second write expecting the same schema fails, as it has since changed.
+	_, err = client.WriteSchema(ctx, &v1.WriteSchemaRequest{
+		Schema: "definition example/user {}\n\ndefinition example/document {\n\trelation viewer: example/user\n\trelation owner: example/user\n}",
+	})
+	grpcutil.RequireStatus(t, codes.FailedPrecondition, err)
+	require.ErrorContains(t, err, "definition `example/document` was changed at or before revision `"+written.WrittenAt.Token+"`")
+
+	// As does one after a definition has been deleted.
+	ctx = metadata.AppendToOutgoingContext(context.Background(), v1svc.WriteSchemaExpectedRevisionHeaderKey, written.WrittenAt.Token)
+	_, err = client.WriteSchema(context.Background(), &v1.WriteSchemaRequest{
+		Schema: "definition example/user {}",
+	})
+	require.NoError(t, err)
+
+	_, err = client.WriteSchema(ctx, &v1.WriteSchemaRequest{
+		Schema: "definition example/user {}\n\ndefinition example/folder {}",
+	})
+	grpcutil.RequireStatus(t, codes.FailedPrecondition, err)
+	require.ErrorContains(t, err, "definition `example/document` was changed")
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), v1svc.WriteSchemaExpectedRevisionHeaderKey, "invalid")
+	_, err = client.WriteSchema(ctx, &v1.WriteSchemaRequest{Schema: "definition example/user {}"})
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+}
+
 func TestSchemaDeleteRelation(t *testing.T) {
 	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.EmptyDatastore)
 	t.Cleanup(cleanup)
@@ -121,6 +336,7 @@ func TestSchemaDeleteRelation(t *testing.T) {
 		}`,
 	})
 	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+	spiceerrors.RequireReason(t, v1.ErrorReason_ERROR_REASON_SCHEMA_TYPE_ERROR, err, "definition_name", "relation_name", "relationship")
 
 	// Attempt to delete the `anotherrelation` relation, which should succeed.
 	updateResp, err := client.WriteSchema(context.Background(), &v1.WriteSchemaRequest{
@@ -301,6 +517,108 @@ func TestSchemaDeleteDefinition(t *testing.T) {
 	require.Equal(t, `definition example/user {}`, readback.SchemaText)
 }
 
+func TestSchemaDeleteDefinitionWithRelationships(t *testing.T) {
+	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.EmptyDatastore)
+	t.Cleanup(cleanup)
+	client := v1.NewSchemaServiceClient(conn)
+	v1client := v1.NewPermissionsServiceClient(conn)
+
+	_, err := client.WriteSchema(context.Background(), &v1.WriteSchemaRequest{
+		Schema: `definition example/user {}
+
+		definition example/group {
+			relation member: example/user
+		}
+
+		definition example/document {
+			relation viewer: example/user | example/group#member
+		}`,
+	})
+	require.NoError(t, err)
+
+	updates := make([]*v1.RelationshipUpdate, 0, 4)
+	for _, rel := range []string{
+		"example/group:first#member@example/user:someuser",
+		"example/group:second#member@example/user:anotheruser",
+		"example/document:somedoc#viewer@example/group:first#member",
+		"example/document:somedoc#viewer@example/user:someuser",
+	} {
+		updates = append(updates, tuple.UpdateToRelationshipUpdate(tuple.Create(tuple.MustParse(rel))))
+	}
+	_, err = v1client.WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{Updates: updates})
+	require.NoError(t, err)
+
+	withoutGroup := `definition example/user {}
+
+		definition example/document {
+			relation viewer: example/user
+		}`
+
+	// Removing the `group` type fails while relationships reference it.
+	_, err = client.WriteSchema(context.Background(), &v1.WriteSchemaRequest{Schema: withoutGroup})
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+	require.ErrorContains(t, err, "cannot remove allowed type `example/group#member`")
+
+	// As does removing only the allowed type.
+	_, err = client.WriteSchema(context.Background(), &v1.WriteSchemaRequest{
+		Schema: `definition example/user {}
+
+		definition example/group {
+			relation member: example/user
+		}
+
+		definition example/document {
+			relation viewer: example/user
+		}`,
+	})
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+
+	// Once nothing references it, removing it fails with the number of its relationships.
+	_, err = v1client.WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{tuple.UpdateToRelationshipUpdate(tuple.Delete(
+			tuple.MustParse("example/document:somedoc#viewer@example/group:first#member"),
+		))},
+	})
+	require.NoError(t, err)
+
+	_, err = client.WriteSchema(context.Background(), &v1.WriteSchemaRequest{Schema: withoutGroup})
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+	require.ErrorContains(t, err, "cannot delete object definition `example/group`, as 2 relationship(s) exist under it")
+
+	// An invalid value for the header is rejected.
+	ctx := metadata.AppendToOutgoingContext(context.Background(), v1svc.WriteSchemaDeleteRelationshipsHeaderKey, "maybe")
+	_, err = client.WriteSchema(ctx, &v1.WriteSchemaRequest{Schema: withoutGroup})
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+
+	// Restore the reference, and remove the `group` type along with its relationships.
+	_, err = v1client.WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{tuple.UpdateToRelationshipUpdate(tuple.Create(
+			tuple.MustParse("example/document:somedoc#viewer@example/group:first#member"),
+		))},
+	})
+	require.NoError(t, err)
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), v1svc.WriteSchemaDeleteRelationshipsHeaderKey, "true")
+	_, err = client.WriteSchema(ctx, &v1.WriteSchemaRequest{Schema: withoutGroup})
+	require.NoError(t, err)
+
+	stream, err := v1client.ReadRelationships(context.Background(), &v1.ReadRelationshipsRequest{
+		Consistency:        &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+		RelationshipFilter: &v1.RelationshipFilter{ResourceType: "example/document"},
+	})
+	require.NoError(t, err)
+
+	var remaining []string
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			break
+		}
+		remaining = append(remaining, tuple.MustStringRelationship(resp.Relationship))
+	}
+	require.Equal(t, []string{"example/document:somedoc#viewer@example/user:someuser"}, remaining)
+}
+
 func TestSchemaRemoveWildcard(t *testing.T) {
 	conn, cleanup, _, _ := testserver.NewTestServer(require.New(t), 0, memdb.DisableGC, true, tf.EmptyDatastore)
 	t.Cleanup(cleanup)
@@ -341,6 +659,7 @@ definition example/user {}`
 	})
 	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
 	require.Equal(t, "rpc error: code = InvalidArgument desc = cannot remove allowed type `example/user:*` from relation `somerelation` in object definition `example/document`, as a relationship exists with it", err.Error())
+	spiceerrors.RequireReason(t, v1.ErrorReason_ERROR_REASON_SCHEMA_TYPE_ERROR, err, "definition_name", "relation_name", "allowed_type", "relationship")
 
 	// Delete the relationship.
 	_, err = v1client.WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{
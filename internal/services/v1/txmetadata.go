@@ -0,0 +1,99 @@
+package v1
+
+import (
+	"context"
+	"strings"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const (
+	// TransactionMetadataHeaderPrefix is the prefix of request metadata keys whose values are
+	// attached as metadata to the transaction performed by a write or delete request. For example,
+	// `io.spicedb.txmetadata.actor: alice` attaches `actor: alice` to the transaction, where it is
+	// persisted with the revision and sent by Watch along with the changes of the transaction, if
+	// supported by the datastore.
+	TransactionMetadataHeaderPrefix = "io.spicedb.txmetadata."
+
+	// watchTransactionMetadataField is the number of the `optional_transaction_metadata` field of
+	// WatchResponse, a google.protobuf.Struct holding the metadata of the transaction which
+	// produced the updates. It is not yet part of the version of the API built against, so it is
+	// encoded as an unknown field, which clients of that version ignore.
+	watchTransactionMetadataField protowire.Number = 3
+
+	// maxTransactionMetadataSize is the maximum combined size, in bytes, of the keys and values
+	// of the metadata attached to a single transaction.
+	maxTransactionMetadataSize = 4096
+)
+
+// transactionMetadataFromContext returns the transaction metadata found in the incoming request
+// metadata, if any.
+func transactionMetadataFromContext(ctx context.Context) (map[string]string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, nil
+	}
+
+	var txMetadata map[string]string
+	size := 0
+	for key, values := range md {
+		if !strings.HasPrefix(key, TransactionMetadataHeaderPrefix) {
+			continue
+		}
+
+		name := strings.TrimPrefix(key, TransactionMetadataHeaderPrefix)
+		if name == "" {
+			return nil, status.Errorf(codes.InvalidArgument, "transaction metadata key `%s` is missing a name", key)
+		}
+
+		if len(values) != 1 {
+			return nil, status.Errorf(codes.InvalidArgument, "transaction metadata key `%s` must have exactly one value", name)
+		}
+
+		size += len(name) + len(values[0])
+		if size > maxTransactionMetadataSize {
+			return nil, status.Errorf(codes.InvalidArgument, "transaction metadata exceeds the maximum allowed size of %d bytes", maxTransactionMetadataSize)
+		}
+
+		if txMetadata == nil {
+			txMetadata = make(map[string]string)
+		}
+		txMetadata[name] = values[0]
+	}
+
+	return txMetadata, nil
+}
+
+// setWatchTransactionMetadata encodes the transaction metadata into the response, as its
+// `optional_transaction_metadata` field.
+func setWatchTransactionMetadata(resp *v1.WatchResponse, txMetadata map[string]string) error {
+	if len(txMetadata) == 0 {
+		return nil
+	}
+
+	fields := make(map[string]any, len(txMetadata))
+	for key, value := range txMetadata {
+		fields[key] = value
+	}
+
+	encodedStruct, err := structpb.NewStruct(fields)
+	if err != nil {
+		return err
+	}
+
+	encoded, err := proto.Marshal(encodedStruct)
+	if err != nil {
+		return err
+	}
+
+	unknown := protowire.AppendTag(nil, watchTransactionMetadataField, protowire.BytesType)
+	unknown = protowire.AppendBytes(unknown, encoded)
+	resp.ProtoReflect().SetUnknown(unknown)
+	return nil
+}
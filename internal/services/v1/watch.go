@@ -1,38 +1,114 @@
 package v1
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"slices"
+	"strings"
 	"time"
 
+	"github.com/authzed/authzed-go/pkg/responsemeta"
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	grpcvalidate "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/validator"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
 
+	"github.com/authzed/spicedb/internal/leaderelection"
+	log "github.com/authzed/spicedb/internal/logging"
 	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/middleware/tenancy"
 	"github.com/authzed/spicedb/internal/middleware/usagemetrics"
 	"github.com/authzed/spicedb/internal/services/shared"
 	"github.com/authzed/spicedb/pkg/datastore"
 	core "github.com/authzed/spicedb/pkg/proto/core/v1"
 	dispatchv1 "github.com/authzed/spicedb/pkg/proto/dispatch/v1"
+	"github.com/authzed/spicedb/pkg/spiceerrors"
 	"github.com/authzed/spicedb/pkg/tuple"
 	"github.com/authzed/spicedb/pkg/zedtoken"
 )
 
+const (
+	// WatchCheckpointsHeaderKey is the request metadata key which, when present, causes the
+	// Watch API to send a response with no updates whenever the datastore reports a checkpoint,
+	// allowing the caller to advance its cursor even when no matching changes occur.
+	WatchCheckpointsHeaderKey = "io.spicedb.watchcheckpoints"
+
+	// WatchRelationFilterHeaderKey is the request metadata key holding one or more filters, of
+	// the form `resource_type#relation`, restricting the updates returned by the Watch API to
+	// the given relations.
+	WatchRelationFilterHeaderKey = "io.spicedb.watchrelationfilter"
+
+	// WatchSubjectFilterHeaderKey is the request metadata key holding one or more
+	// SubjectFilters, encoded as JSON such as
+	// `{"subjectType": "group", "optionalSubjectId": "eng", "optionalRelation": {"relation": "member"}}`,
+	// restricting the updates returned by the Watch API to those whose subject matches any of
+	// them. As in ReadRelationships, a filter with an empty `optionalRelation` matches only
+	// subjects without a relation, and one without `optionalRelation` matches any.
+	WatchSubjectFilterHeaderKey = "io.spicedb.watchsubjectfilter"
+
+	// WatchSchemaChangesHeaderKey is the request metadata key which, when present, causes the
+	// Watch API to end with a FAILED_PRECONDITION error, with the reason
+	// ERROR_REASON_WATCH_SCHEMA_CHANGED, after sending the updates of a revision which changed
+	// the schema. The ZedToken of the revision is in the `revision` metadata of the error, so
+	// consumers which derive state from the schema can re-read it and resume watching from there.
+	WatchSchemaChangesHeaderKey = "io.spicedb.watchschemachanges"
+
+	// WatchEarliestRevisionHeaderKey is the key in the response header metadata holding a
+	// ZedToken for the earliest revision from which a watch can currently be started. Changes
+	// before it have been garbage collected, so a consumer whose cursor is older must read a new
+	// snapshot of the relationships before watching again.
+	WatchEarliestRevisionHeaderKey responsemeta.ResponseMetadataHeaderKey = "io.spicedb.respmeta.watchearliestrevision"
+)
+
+// reasonWatchCursorExpired is the reason of the error returned when a watch is started from a
+// cursor whose revision has fallen outside of the datastore's GC window.
+const reasonWatchCursorExpired = "ERROR_REASON_WATCH_CURSOR_EXPIRED"
+
+// reasonWatchSchemaChanged is the reason of the error ending a watch on a schema change, when
+// requested via the WatchSchemaChangesHeaderKey header.
+const reasonWatchSchemaChanged = "ERROR_REASON_WATCH_SCHEMA_CHANGED"
+
+// watchRetryDelay is the delay suggested to clients before restarting a watch which failed with
+// a temporary condition, from the last revision they received.
+const watchRetryDelay = 1 * time.Second
+
 type watchServer struct {
 	v1.UnimplementedWatchServiceServer
 	shared.WithStreamServiceSpecificInterceptor
 
 	heartbeatDuration time.Duration
+	fanout            *watchFanout
+}
+
+// WatchServerConfig is the configuration of the watch server.
+type WatchServerConfig struct {
+	// HeartbeatDuration is the interval at which the datastore is asked to report checkpoints.
+	HeartbeatDuration time.Duration
+
+	// BufferLength is the number of revisions buffered for each stream when the changes of the
+	// datastore are read once for all streams. Zero disables the shared read, so that each
+	// stream reads the changes itself.
+	BufferLength int
+
+	// Overflow is the behavior of a stream whose buffer is full, defaulting to
+	// WatchOverflowDisconnect.
+	Overflow WatchOverflowBehavior
 }
 
 // NewWatchServer creates an instance of the watch server.
-func NewWatchServer(heartbeatDuration time.Duration) v1.WatchServiceServer {
+func NewWatchServer(config WatchServerConfig) v1.WatchServiceServer {
 	s := &watchServer{
 		WithStreamServiceSpecificInterceptor: shared.WithStreamServiceSpecificInterceptor{
 			Stream: grpcvalidate.StreamServerInterceptor(),
 		},
-		heartbeatDuration: heartbeatDuration,
+		heartbeatDuration: config.HeartbeatDuration,
+	}
+	if config.BufferLength > 0 {
+		s.fanout = newWatchFanout(config.HeartbeatDuration, config.BufferLength, config.Overflow)
 	}
 	return s
 }
@@ -41,66 +117,353 @@ func (ws *watchServer) Watch(req *v1.WatchRequest, stream v1.WatchService_WatchS
 	ctx := stream.Context()
 	ds := datastoremw.MustFromContext(ctx)
 
-	objectTypesMap := make(map[string]struct{})
+	filter := watchFilter{
+		objectTypes: make(map[string]struct{}),
+		relations:   make(map[string]struct{}),
+	}
 	for _, objectType := range req.GetOptionalObjectTypes() {
-		objectTypesMap[objectType] = struct{}{}
+		filter.objectTypes[objectType] = struct{}{}
+	}
+
+	var sendCheckpoints, endOnSchemaChange bool
+	var diffThroughValue string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		_, sendCheckpoints = md[WatchCheckpointsHeaderKey]
+		_, endOnSchemaChange = md[WatchSchemaChangesHeaderKey]
+		if values := md.Get(WatchDiffThroughHeaderKey); len(values) > 0 {
+			diffThroughValue = values[0]
+		}
+
+		for _, relationFilter := range md.Get(WatchRelationFilterHeaderKey) {
+			resourceType, relation, ok := strings.Cut(relationFilter, "#")
+			if !ok || resourceType == "" || relation == "" {
+				return status.Errorf(codes.InvalidArgument, "invalid relation filter `%s`: must be of the form `resource_type#relation`", relationFilter)
+			}
+			filter.relations[relationFilter] = struct{}{}
+		}
+
+		for _, encoded := range md.Get(WatchSubjectFilterHeaderKey) {
+			subjectFilter := &v1.SubjectFilter{}
+			if err := protojson.Unmarshal([]byte(encoded), subjectFilter); err != nil {
+				return status.Errorf(codes.InvalidArgument, "invalid subject filter `%s`: %s", encoded, err)
+			}
+			if err := subjectFilter.Validate(); err != nil {
+				return status.Errorf(codes.InvalidArgument, "invalid subject filter `%s`: %s", encoded, err)
+			}
+			filter.subjects = append(filter.subjects, subjectFilter)
+		}
 	}
 
 	var afterRevision datastore.Revision
-	if req.OptionalStartCursor != nil && req.OptionalStartCursor.Token != "" {
+	fromCursor := req.OptionalStartCursor != nil && req.OptionalStartCursor.Token != ""
+	if diffThroughValue != "" && !fromCursor {
+		return status.Errorf(codes.InvalidArgument, "a start cursor is required when requesting a diff via %s", WatchDiffThroughHeaderKey)
+	}
+	if fromCursor {
 		decodedRevision, err := zedtoken.DecodeRevision(req.OptionalStartCursor, ds)
 		if err != nil {
 			return status.Errorf(codes.InvalidArgument, "failed to decode start revision: %s", err)
 		}
 
+		if err := ds.CheckRevision(ctx, decodedRevision); err != nil {
+			var invalidRevisionErr datastore.ErrInvalidRevision
+			if errors.As(err, &invalidRevisionErr) && invalidRevisionErr.Reason() == datastore.RevisionStale {
+				return watchCursorExpiredError(ctx, ds, err)
+			}
+			return shared.RewriteError(ctx, err, nil)
+		}
+
 		afterRevision = decodedRevision
 	} else {
 		var err error
 		afterRevision, err = ds.OptimizedRevision(ctx)
 		if err != nil {
-			return status.Errorf(codes.Unavailable, "failed to start watch: %s", err)
+			return spiceerrors.WithCodeAndDetailsAsError(fmt.Errorf("failed to start watch: %w", err), codes.Unavailable, spiceerrors.ForRetry(watchRetryDelay))
 		}
 	}
 
+	if err := sendEarliestRevision(ctx, ds, stream); err != nil {
+		return err
+	}
+
 	usagemetrics.SetInContext(ctx, &dispatchv1.ResponseMeta{
 		DispatchCount: 1,
 	})
 
+	if diffThroughValue != "" {
+		through, err := watchDiffThrough(ctx, ds, diffThroughValue, afterRevision)
+		if err != nil {
+			return err
+		}
+		return ws.watchDiff(ctx, ds, afterRevision, through, filter, stream)
+	}
+
+	// send sends the changes of a revision matching the filter, returning an error once the
+	// stream has ended.
+	send := func(update *datastore.RevisionChanges) error {
+		if update.IsCheckpoint && !sendCheckpoints {
+			return nil
+		}
+
+		filtered := filter.filterUpdates(update.RelationshipChanges)
+		if len(filtered) > 0 || sendCheckpoints {
+			resp := &v1.WatchResponse{
+				Updates:        filtered,
+				ChangesThrough: zedtoken.MustNewFromRevision(update.Revision),
+			}
+
+			// The metadata of a transaction is only sent with its changes, so that checkpoints
+			// do not reveal that of transactions filtered out.
+			if len(filtered) > 0 {
+				if err := setWatchTransactionMetadata(resp, update.Metadata); err != nil {
+					return err
+				}
+			}
+
+			sendStart := time.Now()
+			if err := stream.Send(resp); err != nil {
+				watchStreamsEnded.WithLabelValues(watchEndCanceled).Inc()
+				return status.Errorf(codes.Canceled, "watch canceled by user: %s", err)
+			}
+			recordWatchDelivery(update.Revision, time.Since(sendStart))
+		}
+
+		if endOnSchemaChange && changesSchema(ctx, update) {
+			watchStreamsEnded.WithLabelValues(watchEndSchemaChanged).Inc()
+			return watchSchemaChangedError(update.Revision)
+		}
+		return nil
+	}
+
+	content := datastore.WatchRelationships
+	if sendCheckpoints {
+		content |= datastore.WatchCheckpoints
+	}
+	if endOnSchemaChange {
+		content |= datastore.WatchSchema
+	}
+
+	if ws.fanout != nil {
+		consumer, catchUpThrough, ok := ws.fanout.subscribe(ds, afterRevision, !fromCursor, sendCheckpoints)
+		if ok {
+			defer ws.fanout.unsubscribe(consumer)
+			return ws.watchShared(ctx, ds, consumer, afterRevision, catchUpThrough, content, stream, send)
+		}
+	}
+
 	updates, errchan := ds.Watch(ctx, afterRevision, datastore.WatchOptions{
-		Content:            datastore.WatchRelationships,
+		Content:            content,
 		CheckpointInterval: ws.heartbeatDuration,
 	})
 	for {
 		select {
 		case update, ok := <-updates:
 			if ok {
-				filtered := filterUpdates(objectTypesMap, update.RelationshipChanges)
-				if len(filtered) > 0 {
-					if err := stream.Send(&v1.WatchResponse{
-						Updates:        filtered,
-						ChangesThrough: zedtoken.MustNewFromRevision(update.Revision),
-					}); err != nil {
-						return status.Errorf(codes.Canceled, "watch canceled by user: %s", err)
-					}
+				if err := send(update); err != nil {
+					return err
 				}
 			}
 		case err := <-errchan:
-			switch {
-			case errors.As(err, &datastore.ErrWatchCanceled{}):
+			return watchEnded(err)
+		}
+	}
+}
+
+// watchShared sends the changes read for all streams by the fanout to the stream, after those
+// through catchUpThrough, if any, which it reads itself.
+func (ws *watchServer) watchShared(
+	ctx context.Context,
+	ds datastore.Datastore,
+	consumer *watchConsumer,
+	afterRevision datastore.Revision,
+	catchUpThrough datastore.Revision,
+	content datastore.WatchContent,
+	stream v1.WatchService_WatchServer,
+	send func(*datastore.RevisionChanges) error,
+) error {
+	if catchUpThrough != nil {
+		// Checkpoints are required to learn when the stream has caught up.
+		catchUpCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+
+		updates, errchan := ds.Watch(catchUpCtx, afterRevision, datastore.WatchOptions{
+			Content:            content | datastore.WatchCheckpoints,
+			CheckpointInterval: ws.heartbeatDuration,
+		})
+
+	catchUp:
+		for {
+			select {
+			case update, ok := <-updates:
+				if !ok {
+					updates = nil
+					continue
+				}
+				if update.Revision.GreaterThan(catchUpThrough) {
+					break catchUp
+				}
+				if err := send(update); err != nil {
+					return err
+				}
+				if update.Revision.Equal(catchUpThrough) {
+					break catchUp
+				}
+			case err := <-errchan:
+				return watchEnded(err)
+			}
+		}
+		cancel()
+	}
+
+	for {
+		update, droppedThrough, err := consumer.next(ctx)
+		if err != nil {
+			return watchEnded(err)
+		}
+
+		if droppedThrough != nil {
+			if err := stream.Send(&v1.WatchResponse{ChangesThrough: zedtoken.MustNewFromRevision(droppedThrough)}); err != nil {
+				watchStreamsEnded.WithLabelValues(watchEndCanceled).Inc()
 				return status.Errorf(codes.Canceled, "watch canceled by user: %s", err)
-			case errors.As(err, &datastore.ErrWatchDisconnected{}):
-				return status.Errorf(codes.ResourceExhausted, "watch disconnected: %s", err)
-			default:
-				return status.Errorf(codes.Internal, "watch error: %s", err)
 			}
+			continue
+		}
+
+		if err := send(update); err != nil {
+			return err
+		}
+	}
+}
+
+// watchEnded returns the error ending a watch on the error of the datastore.
+func watchEnded(err error) error {
+	switch {
+	case errors.As(err, &datastore.ErrWatchCanceled{}):
+		watchStreamsEnded.WithLabelValues(watchEndCanceled).Inc()
+		return status.Errorf(codes.Canceled, "watch canceled by user: %s", err)
+	case errors.As(err, &datastore.ErrWatchDisconnected{}), errors.Is(err, errWatchBufferOverflow):
+		watchStreamsEnded.WithLabelValues(watchEndSlowConsumer).Inc()
+		return spiceerrors.WithCodeAndDetailsAsError(fmt.Errorf("watch disconnected: %w", err), codes.ResourceExhausted, spiceerrors.ForRetry(watchRetryDelay))
+	case errors.As(err, &datastore.ErrWatchRetryable{}):
+		watchStreamsEnded.WithLabelValues(watchEndRetryable).Inc()
+		return spiceerrors.WithCodeAndDetailsAsError(err, codes.Unavailable, spiceerrors.ForRetry(watchRetryDelay))
+	default:
+		watchStreamsEnded.WithLabelValues(watchEndError).Inc()
+		return status.Errorf(codes.Internal, "watch error: %s", err)
+	}
+}
+
+// earliestRevision returns the earliest revision from which a watch can be started, if known to
+// the datastore.
+func earliestRevision(ctx context.Context, ds datastore.Datastore) (datastore.Revision, bool) {
+	pit := datastore.UnwrapAs[datastore.PointInTimeDatastore](ds)
+	if pit == nil {
+		return datastore.NoRevision, false
+	}
+
+	revision, err := pit.EarliestRevision(ctx)
+	if err != nil {
+		log.Ctx(ctx).Warn().Err(err).Msg("unable to determine the earliest revision of the datastore")
+		return datastore.NoRevision, false
+	}
+	return revision, revision != datastore.NoRevision
+}
+
+// sendEarliestRevision sends the response header holding the earliest revision from which a
+// watch can be started, so that consumers learn of it as soon as the watch begins.
+func sendEarliestRevision(ctx context.Context, ds datastore.Datastore, stream v1.WatchService_WatchServer) error {
+	revision, ok := earliestRevision(ctx, ds)
+	if !ok {
+		return nil
+	}
+
+	if err := responsemeta.SetResponseHeaderMetadata(ctx, map[responsemeta.ResponseMetadataHeaderKey]string{
+		WatchEarliestRevisionHeaderKey: zedtoken.MustNewFromRevision(revision).Token,
+	}); err != nil {
+		return err
+	}
+	return stream.SendHeader(nil)
+}
+
+// watchCursorExpiredError returns the error for a watch started from a cursor which has fallen
+// outside of the GC window, including the earliest revision from which a watch can be started.
+func watchCursorExpiredError(ctx context.Context, ds datastore.Datastore, err error) error {
+	metadata := map[string]string{}
+	if revision, ok := earliestRevision(ctx, ds); ok {
+		metadata["earliest_revision"] = zedtoken.MustNewFromRevision(revision).Token
+	}
+
+	return spiceerrors.WithCodeAndDetailsAsError(
+		fmt.Errorf("the start cursor of the watch has expired, as its changes have been garbage collected; read a new snapshot and watch from its revision: %w", err),
+		codes.FailedPrecondition,
+		&errdetails.ErrorInfo{
+			Reason:   reasonWatchCursorExpired,
+			Domain:   spiceerrors.Domain,
+			Metadata: metadata,
+		},
+	)
+}
+
+// changesSchema returns whether the changes of a revision include changes to the schema, or,
+// under tenant isolation, to the definitions of the tenant of the request.
+func changesSchema(ctx context.Context, update *datastore.RevisionChanges) bool {
+	tenant, isolated := tenancy.FromContext(ctx)
+	if !isolated {
+		return len(update.ChangedDefinitions) > 0 || len(update.DeletedNamespaces) > 0 || len(update.DeletedCaveats) > 0
+	}
+
+	// Tenants are only told of changes to their own definitions.
+	for _, def := range update.ChangedDefinitions {
+		if tenancy.OwnsDefinition(tenant, def.GetName()) {
+			return true
 		}
 	}
+	for _, name := range update.DeletedNamespaces {
+		if tenancy.OwnsDefinition(tenant, name) {
+			return true
+		}
+	}
+	for _, name := range update.DeletedCaveats {
+		if tenancy.OwnsDefinition(tenant, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// watchSchemaChangedError returns the error ending a watch after a revision which changed the
+// schema, including the revision from which to resume watching.
+func watchSchemaChangedError(revision datastore.Revision) error {
+	return spiceerrors.WithCodeAndDetailsAsError(
+		fmt.Errorf("the schema was changed at revision %s; re-read the schema and resume watching from its revision", revision),
+		codes.FailedPrecondition,
+		&errdetails.ErrorInfo{
+			Reason:   reasonWatchSchemaChanged,
+			Domain:   spiceerrors.Domain,
+			Metadata: map[string]string{"revision": zedtoken.MustNewFromRevision(revision).Token},
+		},
+	)
 }
 
-func filterUpdates(objectTypes map[string]struct{}, candidates []*core.RelationTupleUpdate) []*v1.RelationshipUpdate {
+// watchFilter filters the relationship updates returned by the Watch API. An update matches
+// if its resource type is in objectTypes (when specified), its resource type and relation
+// are in relations (when specified) and its subject matches any of subjects (when specified).
+type watchFilter struct {
+	objectTypes map[string]struct{}
+	relations   map[string]struct{}
+	subjects    []*v1.SubjectFilter
+}
+
+func (wf watchFilter) filterUpdates(candidates []*core.RelationTupleUpdate) []*v1.RelationshipUpdate {
+	// Leases are renewed every few seconds by clusters running leader election, and are not
+	// part of the schema, so they are never returned.
+	candidates = slices.DeleteFunc(slices.Clone(candidates), func(update *core.RelationTupleUpdate) bool {
+		return update.Tuple.ResourceAndRelation.Namespace == leaderelection.LeaseNamespace
+	})
+
 	updates := tuple.UpdatesToRelationshipUpdates(candidates)
 
-	if len(objectTypes) == 0 {
+	if len(wf.objectTypes) == 0 && len(wf.relations) == 0 && len(wf.subjects) == 0 {
 		return updates
 	}
 
@@ -108,10 +471,38 @@ func filterUpdates(objectTypes map[string]struct{}, candidates []*core.RelationT
 	for _, update := range updates {
 		objectType := update.GetRelationship().GetResource().GetObjectType()
 
-		if _, ok := objectTypes[objectType]; ok {
-			filtered = append(filtered, update)
+		if len(wf.objectTypes) > 0 {
+			if _, ok := wf.objectTypes[objectType]; !ok {
+				continue
+			}
+		}
+
+		if len(wf.relations) > 0 {
+			if _, ok := wf.relations[objectType+"#"+update.GetRelationship().GetRelation()]; !ok {
+				continue
+			}
 		}
+
+		if len(wf.subjects) > 0 && !slices.ContainsFunc(wf.subjects, func(subjectFilter *v1.SubjectFilter) bool {
+			return subjectMatches(update.GetRelationship().GetSubject(), subjectFilter)
+		}) {
+			continue
+		}
+
+		filtered = append(filtered, update)
 	}
 
 	return filtered
 }
+
+// subjectMatches returns whether the subject matches the filter, as it would in a
+// RelationshipFilter.
+func subjectMatches(subject *v1.SubjectReference, filter *v1.SubjectFilter) bool {
+	if subject.GetObject().GetObjectType() != filter.SubjectType {
+		return false
+	}
+	if filter.OptionalSubjectId != "" && subject.GetObject().GetObjectId() != filter.OptionalSubjectId {
+		return false
+	}
+	return filter.OptionalRelation == nil || subject.GetOptionalRelation() == filter.OptionalRelation.Relation
+}
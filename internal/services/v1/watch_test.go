@@ -13,10 +13,17 @@ import (
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/authzed/grpcutil"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
 
 	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/datastore/revisions"
+	v1svc "github.com/authzed/spicedb/internal/services/v1"
 	"github.com/authzed/spicedb/internal/testfixtures"
 	"github.com/authzed/spicedb/internal/testserver"
 	"github.com/authzed/spicedb/pkg/tuple"
@@ -49,10 +56,17 @@ func update(
 	}
 }
 
+func usersetUpdate(update *v1.RelationshipUpdate, subjectRelation string) *v1.RelationshipUpdate {
+	update.Relationship.Subject.OptionalRelation = subjectRelation
+	return update
+}
+
 func TestWatch(t *testing.T) {
 	testCases := []struct {
 		name              string
 		objectTypesFilter []string
+		relationFilters   []string
+		subjectFilters    []string
 		startCursor       *v1.ZedToken
 		mutations         []*v1.RelationshipUpdate
 		expectedCode      codes.Code
@@ -86,6 +100,68 @@ func TestWatch(t *testing.T) {
 				update(v1.RelationshipUpdate_OPERATION_TOUCH, "document", "document2", "viewer", "user", "user1"),
 			},
 		},
+		{
+			name:            "watch with relation filter",
+			expectedCode:    codes.OK,
+			relationFilters: []string{"document#viewer"},
+			mutations: []*v1.RelationshipUpdate{
+				update(v1.RelationshipUpdate_OPERATION_CREATE, "document", "document1", "viewer", "user", "user1"),
+				update(v1.RelationshipUpdate_OPERATION_TOUCH, "document", "document2", "owner", "user", "user1"),
+				update(v1.RelationshipUpdate_OPERATION_TOUCH, "folder", "folder2", "viewer", "user", "user1"),
+			},
+			expectedUpdates: []*v1.RelationshipUpdate{
+				update(v1.RelationshipUpdate_OPERATION_TOUCH, "document", "document1", "viewer", "user", "user1"),
+			},
+		},
+		{
+			name:              "watch with objectType and relation filters",
+			expectedCode:      codes.OK,
+			objectTypesFilter: []string{"document"},
+			relationFilters:   []string{"document#owner", "folder#viewer"},
+			mutations: []*v1.RelationshipUpdate{
+				update(v1.RelationshipUpdate_OPERATION_CREATE, "document", "document1", "viewer", "user", "user1"),
+				update(v1.RelationshipUpdate_OPERATION_TOUCH, "document", "document2", "owner", "user", "user1"),
+				update(v1.RelationshipUpdate_OPERATION_TOUCH, "folder", "folder2", "viewer", "user", "user1"),
+			},
+			expectedUpdates: []*v1.RelationshipUpdate{
+				update(v1.RelationshipUpdate_OPERATION_TOUCH, "document", "document2", "owner", "user", "user1"),
+			},
+		},
+		{
+			name:           "watch with userset subject filter",
+			expectedCode:   codes.OK,
+			subjectFilters: []string{`{"subjectType": "folder", "optionalRelation": {"relation": "viewer"}}`},
+			mutations: []*v1.RelationshipUpdate{
+				update(v1.RelationshipUpdate_OPERATION_CREATE, "folder", "folder2", "viewer", "user", "user1"),
+				usersetUpdate(update(v1.RelationshipUpdate_OPERATION_CREATE, "folder", "folder2", "viewer", "folder", "auditors"), "viewer"),
+			},
+			expectedUpdates: []*v1.RelationshipUpdate{
+				usersetUpdate(update(v1.RelationshipUpdate_OPERATION_TOUCH, "folder", "folder2", "viewer", "folder", "auditors"), "viewer"),
+			},
+		},
+		{
+			name:           "watch with direct subject filter",
+			expectedCode:   codes.OK,
+			subjectFilters: []string{`{"subjectType": "folder", "optionalRelation": {}}`, `{"subjectType": "user", "optionalSubjectId": "user1"}`},
+			mutations: []*v1.RelationshipUpdate{
+				update(v1.RelationshipUpdate_OPERATION_CREATE, "folder", "folder2", "viewer", "user", "user1"),
+				update(v1.RelationshipUpdate_OPERATION_CREATE, "folder", "folder2", "viewer", "user", "user2"),
+				usersetUpdate(update(v1.RelationshipUpdate_OPERATION_CREATE, "folder", "folder2", "viewer", "folder", "auditors"), "viewer"),
+			},
+			expectedUpdates: []*v1.RelationshipUpdate{
+				update(v1.RelationshipUpdate_OPERATION_TOUCH, "folder", "folder2", "viewer", "user", "user1"),
+			},
+		},
+		{
+			name:           "invalid subject filter",
+			subjectFilters: []string{`{"optionalSubjectId": "user1"}`},
+			expectedCode:   codes.InvalidArgument,
+		},
+		{
+			name:            "invalid relation filter",
+			relationFilters: []string{"document"},
+			expectedCode:    codes.InvalidArgument,
+		},
 		{
 			name:         "invalid zedtoken",
 			startCursor:  &v1.ZedToken{Token: "bad-token"},
@@ -115,6 +191,13 @@ func TestWatch(t *testing.T) {
 			ctx, cancel := context.WithCancel(context.Background())
 			defer cancel()
 
+			for _, relationFilter := range tc.relationFilters {
+				ctx = metadata.AppendToOutgoingContext(ctx, v1svc.WatchRelationFilterHeaderKey, relationFilter)
+			}
+			for _, subjectFilter := range tc.subjectFilters {
+				ctx = metadata.AppendToOutgoingContext(ctx, v1svc.WatchSubjectFilterHeaderKey, subjectFilter)
+			}
+
 			stream, err := client.Watch(ctx, &v1.WatchRequest{
 				OptionalObjectTypes: tc.objectTypesFilter,
 				OptionalStartCursor: cursor,
@@ -175,6 +258,207 @@ func TestWatch(t *testing.T) {
 	}
 }
 
+func TestWatchCheckpoints(t *testing.T) {
+	require := require.New(t)
+
+	conn, cleanup, _, revision := testserver.NewTestServer(require, 0, memdb.DisableGC, true, testfixtures.StandardDatastoreWithData)
+	t.Cleanup(cleanup)
+	client := v1.NewWatchServiceClient(conn)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctx = metadata.AppendToOutgoingContext(ctx, v1svc.WatchCheckpointsHeaderKey, "1")
+	stream, err := client.Watch(ctx, &v1.WatchRequest{
+		OptionalObjectTypes: []string{"folder"},
+		OptionalStartCursor: zedtoken.MustNewFromRevision(revision),
+	})
+	require.NoError(err)
+
+	resp, err := v1.NewPermissionsServiceClient(conn).WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{
+			update(v1.RelationshipUpdate_OPERATION_CREATE, "document", "document1", "viewer", "user", "user1"),
+		},
+	})
+	require.NoError(err)
+
+	// The write does not match the filter, so only a checkpoint should be received.
+	for {
+		watchResp, err := stream.Recv()
+		require.NoError(err)
+		require.Empty(watchResp.Updates)
+		require.NotNil(watchResp.ChangesThrough)
+
+		if watchResp.ChangesThrough.Token == resp.WrittenAt.Token {
+			return
+		}
+	}
+}
+
+// watchTransactionMetadata decodes the `optional_transaction_metadata` field of the response.
+func watchTransactionMetadata(t *testing.T, resp *v1.WatchResponse) map[string]any {
+	unknown := resp.ProtoReflect().GetUnknown()
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	number, wireType, n := protowire.ConsumeTag(unknown)
+	require.Positive(t, n)
+	require.Equal(t, protowire.Number(3), number)
+	require.Equal(t, protowire.BytesType, wireType)
+
+	encoded, n := protowire.ConsumeBytes(unknown[n:])
+	require.Positive(t, n)
+
+	decoded := &structpb.Struct{}
+	require.NoError(t, proto.Unmarshal(encoded, decoded))
+	return decoded.AsMap()
+}
+
+func TestWatchTransactionMetadata(t *testing.T) {
+	require := require.New(t)
+
+	conn, cleanup, _, revision := testserver.NewTestServer(require, 0, memdb.DisableGC, true, testfixtures.StandardDatastoreWithData)
+	t.Cleanup(cleanup)
+	client := v1.NewWatchServiceClient(conn)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.Watch(metadata.AppendToOutgoingContext(ctx, v1svc.WatchCheckpointsHeaderKey, "1"), &v1.WatchRequest{
+		OptionalObjectTypes: []string{"folder"},
+		OptionalStartCursor: zedtoken.MustNewFromRevision(revision),
+	})
+	require.NoError(err)
+
+	write := func(actor string, relationship *v1.RelationshipUpdate) string {
+		writeCtx := metadata.AppendToOutgoingContext(context.Background(), v1svc.TransactionMetadataHeaderPrefix+"actor", actor)
+		resp, err := v1.NewPermissionsServiceClient(conn).WriteRelationships(writeCtx, &v1.WriteRelationshipsRequest{
+			Updates: []*v1.RelationshipUpdate{relationship},
+		})
+		require.NoError(err)
+		return resp.WrittenAt.Token
+	}
+
+	// The metadata of a write filtered out is not sent with its checkpoint.
+	filteredOut := write("mallory", update(v1.RelationshipUpdate_OPERATION_CREATE, "document", "document1", "viewer", "user", "user1"))
+	matching := write("alice", update(v1.RelationshipUpdate_OPERATION_CREATE, "folder", "folder1", "viewer", "user", "user1"))
+
+	for {
+		watchResp, err := stream.Recv()
+		require.NoError(err)
+
+		switch watchResp.ChangesThrough.Token {
+		case filteredOut:
+			require.Empty(watchResp.Updates)
+			require.Nil(watchTransactionMetadata(t, watchResp))
+
+		case matching:
+			if len(watchResp.Updates) == 0 {
+				continue
+			}
+			require.Equal(map[string]any{"actor": "alice"}, watchTransactionMetadata(t, watchResp))
+			return
+
+		default:
+			require.Nil(watchTransactionMetadata(t, watchResp))
+		}
+	}
+}
+
+func TestWatchExpiredCursor(t *testing.T) {
+	require := require.New(t)
+
+	conn, cleanup, _, _ := testserver.NewTestServer(require, 0, time.Minute, true, testfixtures.StandardDatastoreWithData)
+	t.Cleanup(cleanup)
+	client := v1.NewWatchServiceClient(conn)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream, err := client.Watch(ctx, &v1.WatchRequest{
+		OptionalStartCursor: zedtoken.MustNewFromRevision(revisions.NewForTime(time.Now().Add(-time.Hour))),
+	})
+	require.NoError(err)
+
+	_, err = stream.Recv()
+	grpcutil.RequireStatus(t, codes.FailedPrecondition, err)
+	require.ErrorContains(err, "the start cursor of the watch has expired")
+
+	var errorInfo *errdetails.ErrorInfo
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			errorInfo = info
+		}
+	}
+	require.NotNil(errorInfo)
+	require.Equal("ERROR_REASON_WATCH_CURSOR_EXPIRED", errorInfo.Reason)
+	earliest := errorInfo.Metadata["earliest_revision"]
+	require.NotEmpty(earliest)
+
+	// Watching from the earliest revision succeeds, and reports it in the response header.
+	stream, err = client.Watch(ctx, &v1.WatchRequest{
+		OptionalStartCursor: &v1.ZedToken{Token: earliest},
+	})
+	require.NoError(err)
+
+	header, err := stream.Header()
+	require.NoError(err)
+	require.Equal([]string{earliest}, header.Get(string(v1svc.WatchEarliestRevisionHeaderKey)))
+}
+
+func TestWatchSchemaChanges(t *testing.T) {
+	require := require.New(t)
+
+	conn, cleanup, _, revision := testserver.NewTestServer(require, 0, memdb.DisableGC, true, testfixtures.StandardDatastoreWithData)
+	t.Cleanup(cleanup)
+	client := v1.NewWatchServiceClient(conn)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ctx = metadata.AppendToOutgoingContext(ctx, v1svc.WatchSchemaChangesHeaderKey, "1")
+	stream, err := client.Watch(ctx, &v1.WatchRequest{
+		OptionalStartCursor: zedtoken.MustNewFromRevision(revision),
+	})
+	require.NoError(err)
+
+	// Relationship changes are sent as usual.
+	written, err := v1.NewPermissionsServiceClient(conn).WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{
+			update(v1.RelationshipUpdate_OPERATION_CREATE, "document", "document1", "viewer", "user", "user1"),
+		},
+	})
+	require.NoError(err)
+
+	watchResp, err := stream.Recv()
+	require.NoError(err)
+	require.Len(watchResp.Updates, 1)
+	require.Equal(written.WrittenAt.Token, watchResp.ChangesThrough.Token)
+
+	// A schema change ends the watch, naming its revision.
+	schemaClient := v1.NewSchemaServiceClient(conn)
+	schema, err := schemaClient.ReadSchema(context.Background(), &v1.ReadSchemaRequest{})
+	require.NoError(err)
+	schemaWritten, err := schemaClient.WriteSchema(context.Background(), &v1.WriteSchemaRequest{
+		Schema: schema.SchemaText + "\n\ndefinition newtype {}",
+	})
+	require.NoError(err)
+
+	_, err = stream.Recv()
+	grpcutil.RequireStatus(t, codes.FailedPrecondition, err)
+
+	var errorInfo *errdetails.ErrorInfo
+	for _, detail := range status.Convert(err).Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok {
+			errorInfo = info
+		}
+	}
+	require.NotNil(errorInfo)
+	require.Equal("ERROR_REASON_WATCH_SCHEMA_CHANGED", errorInfo.Reason)
+	require.Equal(schemaWritten.WrittenAt.Token, errorInfo.Metadata["revision"])
+}
+
 func sortUpdates(in []*v1.RelationshipUpdate) []*v1.RelationshipUpdate {
 	out := make([]*v1.RelationshipUpdate, 0, len(in))
 	out = append(out, in...)
@@ -0,0 +1,225 @@
+package v1
+
+import (
+	"context"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/services/shared"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/zedtoken"
+)
+
+const (
+	// WatchDiffThroughHeaderKey is the request metadata key which, when present, causes the
+	// Watch API to return the net changes to relationships between its start cursor, which is
+	// then required, and the revision of the ZedToken given as its value, or the current head
+	// revision if the value is `now`, and then end. Relationships created and then deleted
+	// between the revisions are omitted, and those changed several times are returned once, with
+	// their state at the end revision. The changes are sent in responses of at most
+	// watchDiffMaxUpdatesPerResponse updates, each with the end revision as ChangesThrough.
+	WatchDiffThroughHeaderKey = "io.spicedb.watchdiffthrough"
+
+	// watchDiffThroughHead is the value of the WatchDiffThroughHeaderKey header requesting the
+	// changes through the current head revision.
+	watchDiffThroughHead = "now"
+)
+
+// watchDiffMaxUpdatesPerResponse is the maximum number of updates in each response of a diff.
+const watchDiffMaxUpdatesPerResponse = 1000
+
+// watchDiffThrough returns the revision through which the diff requested via the
+// WatchDiffThroughHeaderKey header is computed.
+func watchDiffThrough(ctx context.Context, ds datastore.Datastore, value string, afterRevision datastore.Revision) (datastore.Revision, error) {
+	if value == watchDiffThroughHead {
+		head, err := ds.HeadRevision(ctx)
+		if err != nil {
+			return nil, shared.RewriteError(ctx, err, nil)
+		}
+		return head, nil
+	}
+
+	through, err := zedtoken.DecodeRevision(&v1.ZedToken{Token: value}, ds)
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid value for %s: must be a ZedToken or `%s`: %s", WatchDiffThroughHeaderKey, watchDiffThroughHead, err)
+	}
+	if through.LessThan(afterRevision) {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid value for %s: the revision is before the start cursor", WatchDiffThroughHeaderKey)
+	}
+	if err := ds.CheckRevision(ctx, through); err != nil {
+		return nil, shared.RewriteError(ctx, err, nil)
+	}
+	return through, nil
+}
+
+// watchDiff sends the net changes to relationships matching the filter after afterRevision and
+// through the given revision, compacted by revisionDiff.
+func (ws *watchServer) watchDiff(
+	ctx context.Context,
+	ds datastore.Datastore,
+	afterRevision datastore.Revision,
+	through datastore.Revision,
+	filter watchFilter,
+	stream v1.WatchService_WatchServer,
+) error {
+	diff := newRevisionDiff()
+	if through.GreaterThan(afterRevision) {
+		if err := ws.readDiff(ctx, ds, afterRevision, through, diff); err != nil {
+			return err
+		}
+	}
+
+	changes, err := diff.compacted(ctx, ds.SnapshotReader(afterRevision))
+	if err != nil {
+		return shared.RewriteError(ctx, err, nil)
+	}
+
+	updates := filter.filterUpdates(changes)
+	changesThrough := zedtoken.MustNewFromRevision(through)
+	for {
+		chunk := updates[:min(len(updates), watchDiffMaxUpdatesPerResponse)]
+		updates = updates[len(chunk):]
+		if err := stream.Send(&v1.WatchResponse{Updates: chunk, ChangesThrough: changesThrough}); err != nil {
+			watchStreamsEnded.WithLabelValues(watchEndCanceled).Inc()
+			return status.Errorf(codes.Canceled, "watch canceled by user: %s", err)
+		}
+		if len(updates) == 0 {
+			break
+		}
+	}
+
+	watchStreamsEnded.WithLabelValues(watchEndDiffCompleted).Inc()
+	return nil
+}
+
+// readDiff adds the changes to relationships after afterRevision and through the given revision
+// to the diff. Datastores which only report checkpoints on changes report none once the watch
+// has caught up, so if no change arrives for the heartbeat interval and the head revision is
+// not after the end revision, all of its changes have been read.
+func (ws *watchServer) readDiff(
+	ctx context.Context,
+	ds datastore.Datastore,
+	afterRevision datastore.Revision,
+	through datastore.Revision,
+	diff *revisionDiff,
+) error {
+	watchCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	updates, errchan := ds.Watch(watchCtx, afterRevision, datastore.WatchOptions{
+		Content:            datastore.WatchRelationships | datastore.WatchCheckpoints,
+		CheckpointInterval: ws.heartbeatDuration,
+	})
+
+	idleInterval := ws.heartbeatDuration
+	if idleInterval <= 0 {
+		idleInterval = time.Second
+	}
+	idle := time.NewTimer(idleInterval)
+	defer idle.Stop()
+
+	for {
+		select {
+		case update, ok := <-updates:
+			if !ok {
+				updates = nil
+				continue
+			}
+			if update.Revision.GreaterThan(through) {
+				return nil
+			}
+			diff.add(update.RelationshipChanges)
+			if update.Revision.Equal(through) {
+				return nil
+			}
+			if !idle.Stop() {
+				select {
+				case <-idle.C:
+				default:
+				}
+			}
+			idle.Reset(idleInterval)
+
+		case <-idle.C:
+			head, err := ds.HeadRevision(ctx)
+			if err != nil {
+				return shared.RewriteError(ctx, err, nil)
+			}
+			if !head.GreaterThan(through) {
+				return nil
+			}
+			idle.Reset(idleInterval)
+
+		case err := <-errchan:
+			return watchEnded(err)
+		}
+	}
+}
+
+// revisionDiff accumulates the changes to relationships across revisions, keeping the first and
+// last operation on each relationship, along with its last state.
+type revisionDiff struct {
+	order   []string
+	changes map[string]*relationshipChange
+}
+
+type relationshipChange struct {
+	first core.RelationTupleUpdate_Operation
+	last  *core.RelationTupleUpdate
+}
+
+func newRevisionDiff() *revisionDiff {
+	return &revisionDiff{changes: make(map[string]*relationshipChange)}
+}
+
+func (rd *revisionDiff) add(updates []*core.RelationTupleUpdate) {
+	for _, update := range updates {
+		key := tuple.StringWithoutCaveat(update.Tuple)
+		change, ok := rd.changes[key]
+		if !ok {
+			change = &relationshipChange{first: update.Operation}
+			rd.changes[key] = change
+			rd.order = append(rd.order, key)
+		}
+		change.last = update
+	}
+}
+
+// compacted returns the net change to each relationship, given the reader at the revision
+// before the first change. A relationship last deleted is omitted if it did not exist before:
+// if it was first created, or, if first touched, when it is not found by the reader.
+func (rd *revisionDiff) compacted(ctx context.Context, before datastore.Reader) ([]*core.RelationTupleUpdate, error) {
+	compacted := make([]*core.RelationTupleUpdate, 0, len(rd.order))
+	for _, key := range rd.order {
+		change := rd.changes[key]
+		if change.last.Operation != core.RelationTupleUpdate_DELETE {
+			operation := core.RelationTupleUpdate_TOUCH
+			if change.first == core.RelationTupleUpdate_CREATE {
+				operation = core.RelationTupleUpdate_CREATE
+			}
+			compacted = append(compacted, &core.RelationTupleUpdate{Operation: operation, Tuple: change.last.Tuple})
+			continue
+		}
+
+		switch change.first {
+		case core.RelationTupleUpdate_CREATE:
+			continue
+
+		case core.RelationTupleUpdate_TOUCH:
+			existed, err := relationshipExists(ctx, before, tuple.MustToRelationship(change.last.Tuple))
+			if err != nil {
+				return nil, err
+			}
+			if !existed {
+				continue
+			}
+		}
+		compacted = append(compacted, change.last)
+	}
+	return compacted, nil
+}
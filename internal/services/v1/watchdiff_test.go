@@ -0,0 +1,93 @@
+package v1_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/authzed/grpcutil"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	v1svc "github.com/authzed/spicedb/internal/services/v1"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/internal/testserver"
+	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/zedtoken"
+)
+
+// readDiff reads the updates of a diff through the revision, until the stream ends.
+func readDiff(t *testing.T, client v1.WatchServiceClient, start *v1.ZedToken, through string) ([]string, error) {
+	ctx := metadata.AppendToOutgoingContext(context.Background(), v1svc.WatchDiffThroughHeaderKey, through)
+	stream, err := client.Watch(ctx, &v1.WatchRequest{OptionalStartCursor: start})
+	require.NoError(t, err)
+
+	var updates []string
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return updates, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		for _, update := range resp.Updates {
+			updates = append(updates, update.Operation.String()+" "+tuple.MustStringRelationship(update.Relationship))
+		}
+	}
+}
+
+func TestWatchDiff(t *testing.T) {
+	require := require.New(t)
+
+	conn, cleanup, _, revision := testserver.NewTestServer(require, 0, memdb.DisableGC, true, testfixtures.StandardDatastoreWithData)
+	t.Cleanup(cleanup)
+	client := v1.NewWatchServiceClient(conn)
+	permissionsClient := v1.NewPermissionsServiceClient(conn)
+	start := zedtoken.MustNewFromRevision(revision)
+
+	write := func(updates ...*v1.RelationshipUpdate) *v1.ZedToken {
+		resp, err := permissionsClient.WriteRelationships(context.Background(), &v1.WriteRelationshipsRequest{Updates: updates})
+		require.NoError(err)
+		return resp.WrittenAt
+	}
+
+	afterCreate := write(update(v1.RelationshipUpdate_OPERATION_CREATE, "document", "diff1", "viewer", "user", "alice"))
+	write(update(v1.RelationshipUpdate_OPERATION_TOUCH, "document", "diff2", "viewer", "user", "bob"))
+	write(update(v1.RelationshipUpdate_OPERATION_DELETE, "document", "diff1", "viewer", "user", "alice"))
+	write(
+		update(v1.RelationshipUpdate_OPERATION_TOUCH, "document", "diff2", "viewer", "user", "bob"),
+		update(v1.RelationshipUpdate_OPERATION_DELETE, "folder", "auditors", "viewer", "user", "auditor"),
+	)
+
+	// The relationship created and then deleted is omitted, and that touched twice is returned
+	// once.
+	updates, err := readDiff(t, client, start, "now")
+	require.NoError(err)
+	require.ElementsMatch([]string{
+		"OPERATION_TOUCH document:diff2#viewer@user:bob",
+		"OPERATION_DELETE folder:auditors#viewer@user:auditor",
+	}, updates)
+
+	updates, err = readDiff(t, client, start, afterCreate.Token)
+	require.NoError(err)
+	require.Equal([]string{"OPERATION_TOUCH document:diff1#viewer@user:alice"}, updates)
+
+	// A diff through the start revision is empty.
+	updates, err = readDiff(t, client, afterCreate, afterCreate.Token)
+	require.NoError(err)
+	require.Empty(updates)
+
+	_, err = readDiff(t, client, afterCreate, start.Token)
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+
+	_, err = readDiff(t, client, start, "yesterday")
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+
+	_, err = readDiff(t, client, nil, "now")
+	grpcutil.RequireStatus(t, codes.InvalidArgument, err)
+}
@@ -0,0 +1,275 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+// WatchOverflowBehavior is what happens to a Watch stream whose consumer has fallen so far
+// behind the changes of the datastore that its buffer is full.
+type WatchOverflowBehavior string
+
+const (
+	// WatchOverflowDisconnect ends the stream with RESOURCE_EXHAUSTED, after which the consumer
+	// can resume watching from the last cursor it received.
+	WatchOverflowDisconnect WatchOverflowBehavior = "disconnect"
+
+	// WatchOverflowDropWithCheckpoint discards the changes buffered for the consumer and sends
+	// it a response without updates, whose cursor is the revision through which changes were
+	// discarded, before continuing with the changes that follow. As such responses are
+	// otherwise only sent to consumers requesting checkpoints, those consumers are always
+	// disconnected instead.
+	WatchOverflowDropWithCheckpoint WatchOverflowBehavior = "drop-with-checkpoint"
+)
+
+// errWatchBufferOverflow ends a Watch stream whose buffer is full, when it is disconnected.
+var errWatchBufferOverflow = errors.New("watch fell too far behind the changes of the datastore and was disconnected; consider increasing the buffer of each stream via the flag --watch-api-buffer-length")
+
+// WatchOverflowBehaviors are the supported behaviors of Watch streams whose buffer is full.
+var WatchOverflowBehaviors = []WatchOverflowBehavior{WatchOverflowDisconnect, WatchOverflowDropWithCheckpoint}
+
+// watchFanout reads the changes of the datastore once for all Watch streams, buffering a bounded
+// number of revisions for each, so that a slow consumer neither grows memory without bound
+// nor delays the changes sent to the others.
+type watchFanout struct {
+	heartbeat    time.Duration
+	bufferLength int
+	overflow     WatchOverflowBehavior
+
+	sync.Mutex
+	ds        datastore.Datastore
+	run       *watchFanoutRun
+	consumers map[*watchConsumer]struct{}
+}
+
+// watchFanoutRun is a read of the changes of the datastore, which runs for as long as any
+// stream is open.
+type watchFanoutRun struct {
+	cancel context.CancelFunc
+
+	// head is the revision through which changes have been sent to the consumers.
+	head datastore.Revision
+}
+
+func newWatchFanout(heartbeat time.Duration, bufferLength int, overflow WatchOverflowBehavior) *watchFanout {
+	if overflow == "" {
+		overflow = WatchOverflowDisconnect
+	}
+	return &watchFanout{
+		heartbeat:    heartbeat,
+		bufferLength: bufferLength,
+		overflow:     overflow,
+		consumers:    map[*watchConsumer]struct{}{},
+	}
+}
+
+// subscribe adds a consumer of the changes after the revision, or of those after the most
+// recent revision read if fromNow is set and changes are already being read. If the consumer
+// is behind the changes being read, the returned revision is that through which it must read
+// the changes itself before those of the consumer. Only the changes of the first datastore
+// subscribed to are read, so subscribe returns false for any other.
+func (f *watchFanout) subscribe(ds datastore.Datastore, after datastore.Revision, fromNow bool, sendsCheckpoints bool) (*watchConsumer, datastore.Revision, bool) {
+	f.Lock()
+	defer f.Unlock()
+
+	if f.ds == nil {
+		f.ds = ds
+	}
+	if f.ds != ds {
+		return nil, nil, false
+	}
+
+	overflow := f.overflow
+	if sendsCheckpoints {
+		overflow = WatchOverflowDisconnect
+	}
+	consumer := &watchConsumer{
+		bufferLength: f.bufferLength,
+		overflow:     overflow,
+		notify:       make(chan struct{}, 1),
+		skipThrough:  after,
+	}
+	f.consumers[consumer] = struct{}{}
+
+	if f.run == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		f.run = &watchFanoutRun{cancel: cancel, head: after}
+		go f.read(ctx, f.run)
+		return consumer, nil, true
+	}
+
+	head := f.run.head
+	if fromNow || !after.LessThan(head) {
+		if fromNow {
+			consumer.skipThrough = head
+		}
+		return consumer, nil, true
+	}
+
+	consumer.skipThrough = head
+	return consumer, head, true
+}
+
+// unsubscribe removes the consumer, ending the read of the changes of the datastore if it
+// was the last.
+func (f *watchFanout) unsubscribe(consumer *watchConsumer) {
+	f.Lock()
+	defer f.Unlock()
+
+	delete(f.consumers, consumer)
+	if len(f.consumers) == 0 && f.run != nil {
+		f.run.cancel()
+		f.run = nil
+	}
+}
+
+// read sends the changes of the datastore to the consumers until the run is canceled or the
+// datastore fails, in which case the error is sent to the consumers.
+func (f *watchFanout) read(ctx context.Context, run *watchFanoutRun) {
+	updates, errchan := f.ds.Watch(ctx, run.head, datastore.WatchOptions{
+		Content:            datastore.WatchRelationships | datastore.WatchCheckpoints | datastore.WatchSchema,
+		CheckpointInterval: f.heartbeat,
+	})
+
+	for {
+		select {
+		case change, ok := <-updates:
+			if !ok {
+				// The error, if any, follows.
+				updates = nil
+				continue
+			}
+
+			f.Lock()
+			if f.run != run {
+				f.Unlock()
+				return
+			}
+			run.head = change.Revision
+			for consumer := range f.consumers {
+				if !consumer.enqueue(change) {
+					delete(f.consumers, consumer)
+				}
+			}
+			f.Unlock()
+
+		case err, ok := <-errchan:
+			if !ok || err == nil {
+				err = datastore.NewWatchTemporaryErr(errors.New("shared watch of the datastore ended"))
+			}
+
+			f.Lock()
+			defer f.Unlock()
+			if f.run != run {
+				return
+			}
+
+			log.Warn().Err(err).Int("consumers", len(f.consumers)).Msg("shared watch of the datastore failed")
+			for consumer := range f.consumers {
+				consumer.fail(err)
+			}
+			f.consumers = map[*watchConsumer]struct{}{}
+			run.cancel()
+			f.run = nil
+			return
+		}
+	}
+}
+
+// watchConsumer is the buffer of the changes for a Watch stream.
+type watchConsumer struct {
+	bufferLength int
+	overflow     WatchOverflowBehavior
+	notify       chan struct{}
+
+	// skipThrough is the revision through which changes have been or will be sent to the
+	// stream by other means.
+	skipThrough datastore.Revision
+
+	sync.Mutex
+	buffer         []*datastore.RevisionChanges
+	droppedThrough datastore.Revision
+	err            error
+}
+
+// enqueue adds the change to the buffer, returning false if the consumer is disconnected for
+// having overflowed it.
+func (c *watchConsumer) enqueue(change *datastore.RevisionChanges) bool {
+	if !change.Revision.GreaterThan(c.skipThrough) {
+		return true
+	}
+
+	c.Lock()
+	defer c.Unlock()
+	defer c.signal()
+
+	if len(c.buffer) < c.bufferLength {
+		c.buffer = append(c.buffer, change)
+		return true
+	}
+
+	watchConsumerOverflows.WithLabelValues(string(c.overflow)).Inc()
+	if c.overflow == WatchOverflowDropWithCheckpoint {
+		clear(c.buffer)
+		c.buffer = c.buffer[:0]
+		c.droppedThrough = change.Revision
+		return true
+	}
+
+	c.err = errWatchBufferOverflow
+	return false
+}
+
+// fail ends the stream with the error.
+func (c *watchConsumer) fail(err error) {
+	c.Lock()
+	defer c.Unlock()
+	defer c.signal()
+	c.err = err
+}
+
+func (c *watchConsumer) signal() {
+	select {
+	case c.notify <- struct{}{}:
+	default:
+	}
+}
+
+// next returns the next change to send to the stream or, if changes were dropped, the revision
+// through which they were, waiting until either is available.
+func (c *watchConsumer) next(ctx context.Context) (*datastore.RevisionChanges, datastore.Revision, error) {
+	for {
+		c.Lock()
+		switch {
+		case c.droppedThrough != nil:
+			droppedThrough := c.droppedThrough
+			c.droppedThrough = nil
+			c.Unlock()
+			return nil, droppedThrough, nil
+
+		case len(c.buffer) > 0:
+			change := c.buffer[0]
+			c.buffer[0] = nil
+			c.buffer = c.buffer[1:]
+			c.Unlock()
+			return change, nil, nil
+
+		case c.err != nil:
+			err := c.err
+			c.Unlock()
+			return nil, nil, err
+		}
+		c.Unlock()
+
+		select {
+		case <-c.notify:
+		case <-ctx.Done():
+			return nil, nil, datastore.NewWatchCanceledErr()
+		}
+	}
+}
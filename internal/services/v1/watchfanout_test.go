@@ -0,0 +1,163 @@
+package v1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/internal/testfixtures"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func newFanoutDatastore(t *testing.T) (datastore.Datastore, datastore.Revision) {
+	rawDS, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+	return testfixtures.StandardDatastoreWithData(rawDS, require.New(t))
+}
+
+func touch(t *testing.T, ds datastore.Datastore, relationship string) datastore.Revision {
+	revision, err := common.WriteTuples(context.Background(), ds, core.RelationTupleUpdate_TOUCH, tuple.MustParse(relationship))
+	require.NoError(t, err)
+	return revision
+}
+
+// nextChange returns the next change of the consumer which is not a checkpoint.
+func nextChange(t *testing.T, consumer *watchConsumer) *datastore.RevisionChanges {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	for {
+		change, droppedThrough, err := consumer.next(ctx)
+		require.NoError(t, err)
+		require.Nil(t, droppedThrough)
+		if !change.IsCheckpoint {
+			return change
+		}
+	}
+}
+
+// waitForHead waits until the fanout has read the changes through the revision.
+func waitForHead(t *testing.T, fanout *watchFanout, revision datastore.Revision) {
+	require.Eventually(t, func() bool {
+		fanout.Lock()
+		defer fanout.Unlock()
+		return fanout.run != nil && !fanout.run.head.LessThan(revision)
+	}, 5*time.Second, time.Millisecond)
+}
+
+func TestWatchFanoutDisconnectsSlowConsumer(t *testing.T) {
+	ds, revision := newFanoutDatastore(t)
+	fanout := newWatchFanout(0, 4, WatchOverflowDisconnect)
+
+	fast, _, ok := fanout.subscribe(ds, revision, false, false)
+	require.True(t, ok)
+	slow, _, ok := fanout.subscribe(ds, revision, false, false)
+	require.True(t, ok)
+
+	// The fast consumer receives every change, however far behind the slow consumer falls.
+	var last datastore.Revision
+	for i := 0; i < 5; i++ {
+		last = touch(t, ds, "document:doc"+string(rune('a'+i))+"#viewer@user:tom")
+		require.True(t, nextChange(t, fast).Revision.Equal(last))
+	}
+
+	// The slow consumer is sent the changes it had buffered before being disconnected.
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		change, _, err := slow.next(ctx)
+		require.NoError(t, err)
+		require.NotNil(t, change)
+	}
+	_, _, err := slow.next(ctx)
+	require.ErrorIs(t, err, errWatchBufferOverflow)
+
+	fanout.Lock()
+	require.Len(t, fanout.consumers, 1)
+	fanout.Unlock()
+
+	// The read ends with the last stream.
+	fanout.unsubscribe(fast)
+	fanout.Lock()
+	require.Nil(t, fanout.run)
+	fanout.Unlock()
+}
+
+func TestWatchFanoutDropsWithCheckpoint(t *testing.T) {
+	ds, revision := newFanoutDatastore(t)
+	fanout := newWatchFanout(0, 2, WatchOverflowDropWithCheckpoint)
+
+	consumer, _, ok := fanout.subscribe(ds, revision, false, false)
+	require.True(t, ok)
+	t.Cleanup(func() { fanout.unsubscribe(consumer) })
+
+	var last datastore.Revision
+	for i := 0; i < 4; i++ {
+		last = touch(t, ds, "document:doc"+string(rune('a'+i))+"#viewer@user:tom")
+	}
+	waitForHead(t, fanout, last)
+
+	// The consumer learns of the revision through which changes were dropped...
+	_, droppedThrough, err := consumer.next(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, droppedThrough)
+	require.False(t, droppedThrough.GreaterThan(last))
+
+	// ...and continues with the changes that follow, once it has read those still buffered.
+	for {
+		consumer.Lock()
+		buffered := len(consumer.buffer)
+		consumer.Unlock()
+		if buffered == 0 {
+			break
+		}
+		change, _, err := consumer.next(context.Background())
+		require.NoError(t, err)
+		require.False(t, change.Revision.GreaterThan(last))
+	}
+
+	next := touch(t, ds, "document:later#viewer@user:tom")
+	require.True(t, nextChange(t, consumer).Revision.Equal(next))
+}
+
+func TestWatchFanoutConsumersBehindCatchUp(t *testing.T) {
+	ds, revision := newFanoutDatastore(t)
+	fanout := newWatchFanout(0, 10, WatchOverflowDisconnect)
+
+	first, catchUpThrough, ok := fanout.subscribe(ds, revision, false, false)
+	require.True(t, ok)
+	require.Nil(t, catchUpThrough)
+	t.Cleanup(func() { fanout.unsubscribe(first) })
+
+	written := touch(t, ds, "document:doca#viewer@user:tom")
+	waitForHead(t, fanout, written)
+
+	// A consumer from an earlier revision reads the changes through the head itself.
+	behind, catchUpThrough, ok := fanout.subscribe(ds, revision, false, false)
+	require.True(t, ok)
+	require.NotNil(t, catchUpThrough)
+	require.False(t, catchUpThrough.LessThan(written))
+	t.Cleanup(func() { fanout.unsubscribe(behind) })
+
+	// While a consumer from now, or from the head, does not.
+	current, catchUpThrough, ok := fanout.subscribe(ds, revision, true, false)
+	require.True(t, ok)
+	require.Nil(t, catchUpThrough)
+	t.Cleanup(func() { fanout.unsubscribe(current) })
+
+	// All receive the changes after the head.
+	later := touch(t, ds, "document:docb#viewer@user:tom")
+	for _, consumer := range []*watchConsumer{behind, current} {
+		require.True(t, nextChange(t, consumer).Revision.Equal(later))
+	}
+
+	// Only the changes of the first datastore are shared.
+	other, _ := newFanoutDatastore(t)
+	_, _, ok = fanout.subscribe(other, revision, false, false)
+	require.False(t, ok)
+}
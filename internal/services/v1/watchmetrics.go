@@ -0,0 +1,69 @@
+package v1
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	"github.com/authzed/spicedb/internal/datastore/revisions"
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+// The reasons for which watch streams end, as recorded by watchStreamsEnded.
+const (
+	watchEndCanceled      = "canceled"
+	watchEndSlowConsumer  = "slow_consumer"
+	watchEndSchemaChanged = "schema_changed"
+	watchEndRetryable     = "retryable"
+	watchEndError         = "error"
+	watchEndDiffCompleted = "diff_completed"
+)
+
+var (
+	watchStreamsOpen = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "spicedb",
+		Subsystem: "v1",
+		Name:      "watch_streams_open",
+		Help:      "Number of Watch streams currently open.",
+	})
+
+	watchStreamsEnded = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "spicedb",
+		Subsystem: "v1",
+		Name:      "watch_streams_ended_total",
+		Help:      "Number of Watch streams ended, by reason; `slow_consumer` counts streams disconnected for falling behind the changes of the datastore.",
+	}, []string{"reason"})
+
+	watchConsumerOverflows = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "spicedb",
+		Subsystem: "v1",
+		Name:      "watch_consumer_overflows_total",
+		Help:      "Number of times the buffer of a Watch stream was full when a change arrived, by the resulting behavior.",
+	}, []string{"behavior"})
+
+	watchDeliveryLag = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "spicedb",
+		Subsystem: "v1",
+		Name:      "watch_delivery_lag_seconds",
+		Help:      "Time between the commit of a revision and the delivery of its changes to a Watch stream, for datastores whose revisions are timestamped.",
+		Buckets:   []float64{.01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 300},
+	})
+
+	watchSendDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "spicedb",
+		Subsystem: "v1",
+		Name:      "watch_send_duration_seconds",
+		Help:      "Time spent sending a response to a Watch stream, which grows as its consumer falls behind.",
+		Buckets:   []float64{.0005, .001, .005, .01, .05, .1, .5, 1, 5},
+	})
+)
+
+// recordWatchDelivery records the metrics of the delivery of the changes of a revision to a
+// Watch stream, which took the given time to send.
+func recordWatchDelivery(revision datastore.Revision, sendDuration time.Duration) {
+	watchSendDuration.Observe(sendDuration.Seconds())
+	if lag, ok := revisions.Age(revision); ok {
+		watchDeliveryLag.Observe(lag.Seconds())
+	}
+}
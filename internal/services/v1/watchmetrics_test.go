@@ -0,0 +1,31 @@
+package v1
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/revisions"
+)
+
+func sampleCount(t *testing.T, histogram prometheus.Histogram) uint64 {
+	t.Helper()
+
+	metric := &dto.Metric{}
+	require.NoError(t, histogram.Write(metric))
+	return metric.GetHistogram().GetSampleCount()
+}
+
+func TestRecordWatchDelivery(t *testing.T) {
+	lags, sends := sampleCount(t, watchDeliveryLag), sampleCount(t, watchSendDuration)
+
+	// Only the lag of revisions with timestamps is known.
+	recordWatchDelivery(revisions.NewForTime(time.Now().Add(-time.Second)), time.Millisecond)
+	recordWatchDelivery(revisions.NewForTransactionID(1), time.Millisecond)
+
+	require.Equal(t, lags+1, sampleCount(t, watchDeliveryLag))
+	require.Equal(t, sends+2, sampleCount(t, watchSendDuration))
+}
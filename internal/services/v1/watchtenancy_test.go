@@ -0,0 +1,38 @@
+package v1
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/middleware/tenancy"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+func TestChangesSchemaOfTenant(t *testing.T) {
+	acme := tenancy.ContextWithTenant(context.Background(), "acme")
+
+	testCases := []struct {
+		name     string
+		update   *datastore.RevisionChanges
+		expected bool
+	}{
+		{"no changes", &datastore.RevisionChanges{}, false},
+		{"changed definition", &datastore.RevisionChanges{ChangedDefinitions: []datastore.SchemaDefinition{&core.NamespaceDefinition{Name: "acme/document"}}}, true},
+		{"changed definition of another tenant", &datastore.RevisionChanges{ChangedDefinitions: []datastore.SchemaDefinition{&core.NamespaceDefinition{Name: "other/document"}}}, false},
+		{"deleted definition", &datastore.RevisionChanges{DeletedNamespaces: []string{"acme/document"}}, true},
+		{"deleted caveat of another tenant", &datastore.RevisionChanges{DeletedCaveats: []string{"acmecorp/unexpired"}}, false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, changesSchema(acme, tc.update))
+		})
+	}
+
+	// Without tenant isolation, any change to the schema counts.
+	require.True(t, changesSchema(context.Background(), &datastore.RevisionChanges{DeletedCaveats: []string{"other/unexpired"}}))
+}
@@ -0,0 +1,184 @@
+package v1
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+var (
+	writeBatchSizeHistogram = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "spicedb",
+		Subsystem: "v1",
+		Name:      "write_relationships_batch_size",
+		Help:      "The number of WriteRelationships calls committed in each batched transaction",
+		Buckets:   []float64{1, 2, 5, 10, 25, 50, 100, 250, 500, 1000},
+	})
+
+	writeBatchFallbackCounter = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "spicedb",
+		Subsystem: "v1",
+		Name:      "write_relationships_batch_fallbacks_total",
+		Help:      "Count of the batched transactions which failed, after which their writes were committed separately",
+	})
+)
+
+// writeBatcher groups the WriteRelationships calls made within a window into a single datastore
+// transaction, trading a few milliseconds of latency for fewer, larger commits under heavy write
+// load. Each write is applied in turn within the transaction, as if the writes were made one
+// after another; if any of them fails, the transaction is aborted and each write is retried in
+// its own transaction, so that one caller's failure never fails another's write.
+//
+// Batched transactions are made on behalf of all of their callers, so they run on the base
+// context of the batcher rather than on that of any caller, and are not canceled with them. A
+// caller canceled while its write is batched returns immediately; its write is skipped unless the
+// transaction has already applied it, as a write canceled during its commit may also be applied.
+type writeBatcher struct {
+	baseCtx    context.Context
+	window     time.Duration
+	maxUpdates int
+
+	mu      sync.Mutex
+	pending map[datastore.Datastore]*writeBatch
+}
+
+// writeBatch is the set of writes to a datastore waiting to be committed together.
+type writeBatch struct {
+	ctx     context.Context
+	ds      datastore.Datastore
+	writes  []*batchedWrite
+	updates int
+	full    chan struct{}
+}
+
+type batchedWrite struct {
+	ctx  context.Context
+	fn   datastore.TxUserFunc
+	done chan batchedWriteResult
+}
+
+type batchedWriteResult struct {
+	revision datastore.Revision
+	err      error
+}
+
+// newWriteBatcher creates a batcher which commits the writes made within the window together,
+// unless their combined updates reach maxUpdates, in which case they are committed immediately.
+// Batched transactions run on the base context.
+func newWriteBatcher(baseCtx context.Context, window time.Duration, maxUpdates int) *writeBatcher {
+	return &writeBatcher{
+		baseCtx:    baseCtx,
+		window:     window,
+		maxUpdates: maxUpdates,
+		pending:    make(map[datastore.Datastore]*writeBatch),
+	}
+}
+
+// write applies the transaction function, making the given number of updates, to the datastore
+// along with the other writes made within the window, returning the revision at which they were
+// committed.
+func (wb *writeBatcher) write(ctx context.Context, ds datastore.Datastore, updates int, fn datastore.TxUserFunc) (datastore.Revision, error) {
+	w := &batchedWrite{ctx: ctx, fn: fn, done: make(chan batchedWriteResult, 1)}
+
+	wb.mu.Lock()
+	batch := wb.pending[ds]
+	if batch != nil && batch.updates+updates > wb.maxUpdates {
+		// The write does not fit in the pending batch, so it is committed now.
+		delete(wb.pending, ds)
+		close(batch.full)
+		batch = nil
+	}
+	if batch == nil {
+		batch = &writeBatch{ctx: wb.baseCtx, ds: ds, full: make(chan struct{})}
+		wb.pending[ds] = batch
+		go wb.commitAfterWindow(batch)
+	}
+	batch.writes = append(batch.writes, w)
+	batch.updates += updates
+	if batch.updates >= wb.maxUpdates {
+		delete(wb.pending, ds)
+		close(batch.full)
+	}
+	wb.mu.Unlock()
+
+	select {
+	case result := <-w.done:
+		return result.revision, result.err
+	case <-ctx.Done():
+		return datastore.NoRevision, ctx.Err()
+	}
+}
+
+func (wb *writeBatcher) commitAfterWindow(batch *writeBatch) {
+	timer := time.NewTimer(wb.window)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		wb.mu.Lock()
+		if wb.pending[batch.ds] == batch {
+			delete(wb.pending, batch.ds)
+		}
+		wb.mu.Unlock()
+	case <-batch.full:
+	}
+
+	batch.commit()
+}
+
+func (batch *writeBatch) commit() {
+	// Writes whose callers have gone away are not committed.
+	writes := make([]*batchedWrite, 0, len(batch.writes))
+	for _, w := range batch.writes {
+		if err := w.ctx.Err(); err != nil {
+			w.done <- batchedWriteResult{datastore.NoRevision, err}
+			continue
+		}
+		writes = append(writes, w)
+	}
+
+	switch len(writes) {
+	case 0:
+		return
+	case 1:
+		writes[0].commitAlone(batch.ds)
+		return
+	}
+
+	writeBatchSizeHistogram.Observe(float64(len(writes)))
+
+	revision, err := batch.ds.ReadWriteTx(batch.ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		for _, w := range writes {
+			if w.ctx.Err() != nil {
+				continue
+			}
+			if err := w.fn(ctx, rwt); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		for _, w := range writes {
+			w.done <- batchedWriteResult{revision, nil}
+		}
+		return
+	}
+
+	log.Ctx(batch.ctx).Debug().Err(err).Int("writes", len(writes)).Msg("batched write failed, committing writes separately")
+	writeBatchFallbackCounter.Inc()
+	for _, w := range writes {
+		go w.commitAlone(batch.ds)
+	}
+}
+
+func (w *batchedWrite) commitAlone(ds datastore.Datastore) {
+	revision, err := ds.ReadWriteTx(w.ctx, w.fn)
+	w.done <- batchedWriteResult{revision, err}
+}
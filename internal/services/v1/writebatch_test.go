@@ -0,0 +1,177 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/pkg/datastore"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func touchFn(rel string) datastore.TxUserFunc {
+	return func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteRelationships(ctx, []*core.RelationTupleUpdate{tuple.Touch(tuple.MustParse(rel))})
+	}
+}
+
+func newBatchTestDatastore(t *testing.T) datastore.Datastore {
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+	t.Cleanup(func() { ds.Close() })
+	return ds
+}
+
+func TestWriteBatcherCommitsConcurrentWritesTogether(t *testing.T) {
+	ds := newBatchTestDatastore(t)
+	batcher := newWriteBatcher(context.Background(), 50*time.Millisecond, 1000)
+
+	revisions := make([]datastore.Revision, 5)
+	var wg sync.WaitGroup
+	for i := range revisions {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rev, err := batcher.write(context.Background(), ds, 1, touchFn(fmt.Sprintf("document:doc%d#viewer@user:tom", i)))
+			require.NoError(t, err)
+			revisions[i] = rev
+		}()
+	}
+	wg.Wait()
+
+	for _, rev := range revisions[1:] {
+		require.True(t, revisions[0].Equal(rev))
+	}
+
+	it, err := ds.SnapshotReader(revisions[0]).QueryRelationships(context.Background(), datastore.RelationshipsFilter{ResourceType: "document"})
+	require.NoError(t, err)
+	defer it.Close()
+
+	count := 0
+	for rel := it.Next(); rel != nil; rel = it.Next() {
+		count++
+	}
+	require.NoError(t, it.Err())
+	require.Equal(t, 5, count)
+}
+
+func TestWriteBatcherIsolatesFailures(t *testing.T) {
+	ds := newBatchTestDatastore(t)
+	batcher := newWriteBatcher(context.Background(), 50*time.Millisecond, 1000)
+	before := testutil.ToFloat64(writeBatchFallbackCounter)
+
+	errInvalid := errors.New("invalid write")
+	fns := []datastore.TxUserFunc{
+		touchFn("document:first#viewer@user:tom"),
+		func(context.Context, datastore.ReadWriteTransaction) error { return errInvalid },
+		touchFn("document:second#viewer@user:tom"),
+	}
+
+	errs := make([]error, len(fns))
+	var wg sync.WaitGroup
+	for i, fn := range fns {
+		i, fn := i, fn
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, errs[i] = batcher.write(context.Background(), ds, 1, fn)
+		}()
+	}
+	wg.Wait()
+
+	require.NoError(t, errs[0])
+	require.ErrorIs(t, errs[1], errInvalid)
+	require.NoError(t, errs[2])
+	require.Equal(t, before+1, testutil.ToFloat64(writeBatchFallbackCounter))
+}
+
+func TestWriteBatcherCommitsFullBatches(t *testing.T) {
+	ds := newBatchTestDatastore(t)
+
+	// The window is long enough that the writes are only committed early because the batch
+	// reached its maximum size.
+	batcher := newWriteBatcher(context.Background(), time.Hour, 2)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := batcher.write(context.Background(), ds, 1, touchFn(fmt.Sprintf("document:doc%d#viewer@user:tom", i)))
+			require.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+}
+
+func TestWriteBatcherSkipsCanceledWrites(t *testing.T) {
+	ds := newBatchTestDatastore(t)
+	batcher := newWriteBatcher(context.Background(), 20*time.Millisecond, 1000)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := batcher.write(ctx, ds, 1, func(context.Context, datastore.ReadWriteTransaction) error {
+		require.Fail(t, "canceled write should not be applied")
+		return nil
+	})
+	require.ErrorIs(t, err, context.Canceled)
+}
+
+type batchTestContextKey string
+
+func TestWriteBatcherReturnsCallersCanceledMidBatch(t *testing.T) {
+	ds := newBatchTestDatastore(t)
+
+	// The batch is only committed once the third write is made, so that the first caller is
+	// canceled while its write is batched.
+	baseCtx := context.WithValue(context.Background(), batchTestContextKey("base"), "server")
+	batcher := newWriteBatcher(baseCtx, time.Hour, 3)
+
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), batchTestContextKey("caller"), "canceled"))
+	canceled := make(chan error, 1)
+	go func() {
+		_, err := batcher.write(ctx, ds, 1, func(context.Context, datastore.ReadWriteTransaction) error {
+			require.Fail(t, "canceled write should not be applied")
+			return nil
+		})
+		canceled <- err
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-canceled:
+		require.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		require.FailNow(t, "canceled write did not return before its batch was committed")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 2; i++ {
+		i := i
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			callerCtx := context.WithValue(context.Background(), batchTestContextKey("caller"), "writer")
+			_, err := batcher.write(callerCtx, ds, 1, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+				// The batch runs on the base context of the batcher, not on that of a caller.
+				require.Equal(t, "server", ctx.Value(batchTestContextKey("base")))
+				require.Nil(t, ctx.Value(batchTestContextKey("caller")))
+				return touchFn(fmt.Sprintf("document:doc%d#viewer@user:tom", i))(ctx, rwt)
+			})
+			require.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+}
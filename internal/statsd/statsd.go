@@ -0,0 +1,218 @@
+// Package statsd implements an exporter which periodically sends the metrics of a
+// Prometheus registry to a StatsD server, for observability stacks which do not scrape
+// Prometheus.
+package statsd
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	log "github.com/authzed/spicedb/internal/logging"
+)
+
+// maxPacketSize is the maximum size of a single UDP packet sent to the server, chosen to
+// fit within the MTU of most networks.
+const maxPacketSize = 1432
+
+// Exporter sends the metrics gathered from a Prometheus registry to a StatsD server, with
+// labels and the configured tags in the DogStatsD format.
+//
+// Gauges are sent as gauges. Counters, and the counts, sums and buckets of histograms and
+// summaries, are sent as counters of their increase since the previous flush. Quantiles of
+// summaries are sent as gauges.
+type Exporter struct {
+	gatherer prometheus.Gatherer
+	conn     net.Conn
+	prefix   string
+	tags     []string
+	interval time.Duration
+
+	previous map[string]float64
+}
+
+// NewExporter creates an exporter which sends the metrics of the gatherer to the StatsD
+// server at the address every interval. The prefix is prepended to the name of each metric
+// and the tags, given as `key:value`, are added to each metric.
+func NewExporter(gatherer prometheus.Gatherer, addr, prefix string, tags []string, interval time.Duration) (*Exporter, error) {
+	if interval <= 0 {
+		return nil, errors.New("statsd flush interval must be positive")
+	}
+
+	for _, tag := range tags {
+		if strings.ContainsAny(tag, ",|#\n") {
+			return nil, fmt.Errorf("invalid statsd tag `%s`", tag)
+		}
+	}
+
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to statsd server: %w", err)
+	}
+
+	return &Exporter{
+		gatherer: gatherer,
+		conn:     conn,
+		prefix:   prefix,
+		tags:     tags,
+		interval: interval,
+		previous: map[string]float64{},
+	}, nil
+}
+
+// Run flushes the metrics every interval until the context is canceled, at which point the
+// metrics are flushed a final time and the connection is closed.
+func (e *Exporter) Run(ctx context.Context) error {
+	defer e.conn.Close()
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := e.Flush(); err != nil {
+				log.Ctx(ctx).Warn().Err(err).Msg("failed to flush metrics to statsd")
+			}
+			return nil
+
+		case <-ticker.C:
+			if err := e.Flush(); err != nil {
+				log.Ctx(ctx).Warn().Err(err).Msg("failed to flush metrics to statsd")
+			}
+		}
+	}
+}
+
+// Flush gathers the metrics and sends them to the server.
+func (e *Exporter) Flush() error {
+	families, err := e.gatherer.Gather()
+	if err != nil {
+		return fmt.Errorf("failed to gather metrics: %w", err)
+	}
+
+	w := &packetWriter{conn: e.conn}
+	for _, family := range families {
+		name := e.prefix + family.GetName()
+		for _, metric := range family.GetMetric() {
+			tags := e.tagsFor(metric.GetLabel())
+
+			switch family.GetType() {
+			case dto.MetricType_COUNTER:
+				e.writeCount(w, name, tags, metric.GetCounter().GetValue())
+
+			case dto.MetricType_GAUGE:
+				w.write(name, formatFloat(metric.GetGauge().GetValue()), "g", tags)
+
+			case dto.MetricType_UNTYPED:
+				w.write(name, formatFloat(metric.GetUntyped().GetValue()), "g", tags)
+
+			case dto.MetricType_HISTOGRAM, dto.MetricType_GAUGE_HISTOGRAM:
+				histogram := metric.GetHistogram()
+				e.writeCount(w, name+"_count", tags, float64(histogram.GetSampleCount()))
+				e.writeCount(w, name+"_sum", tags, histogram.GetSampleSum())
+				for _, bucket := range histogram.GetBucket() {
+					le := "le:" + formatFloat(bucket.GetUpperBound())
+					e.writeCount(w, name+"_bucket", append(tags, le), float64(bucket.GetCumulativeCount()))
+				}
+
+			case dto.MetricType_SUMMARY:
+				summary := metric.GetSummary()
+				e.writeCount(w, name+"_count", tags, float64(summary.GetSampleCount()))
+				e.writeCount(w, name+"_sum", tags, summary.GetSampleSum())
+				for _, quantile := range summary.GetQuantile() {
+					q := "quantile:" + formatFloat(quantile.GetQuantile())
+					w.write(name, formatFloat(quantile.GetValue()), "g", append(tags, q))
+				}
+			}
+		}
+	}
+	return w.flush()
+}
+
+// writeCount writes the increase of a cumulative value since the previous flush. A value
+// lower than the previous one has been reset, so its increase is the value itself.
+func (e *Exporter) writeCount(w *packetWriter, name string, tags []string, value float64) {
+	key := name + "|" + strings.Join(tags, ",")
+	increase := value - e.previous[key]
+	if increase < 0 {
+		increase = value
+	}
+	e.previous[key] = value
+
+	if increase == 0 {
+		return
+	}
+	w.write(name, formatFloat(increase), "c", tags)
+}
+
+func (e *Exporter) tagsFor(labels []*dto.LabelPair) []string {
+	tags := make([]string, 0, len(labels)+len(e.tags)+1)
+	for _, label := range labels {
+		tags = append(tags, label.GetName()+":"+sanitizeTagValue(label.GetValue()))
+	}
+	sort.Strings(tags)
+	return append(tags, e.tags...)
+}
+
+var tagValueReplacer = strings.NewReplacer(",", "_", "|", "_", "#", "_", "\n", "_")
+
+func sanitizeTagValue(value string) string {
+	return tagValueReplacer.Replace(value)
+}
+
+func formatFloat(value float64) string {
+	if math.IsInf(value, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+// packetWriter batches lines into packets no larger than maxPacketSize.
+type packetWriter struct {
+	conn net.Conn
+	buf  bytes.Buffer
+	errs []error
+}
+
+func (w *packetWriter) write(name, value, kind string, tags []string) {
+	line := name + ":" + value + "|" + kind
+	if len(tags) > 0 {
+		line += "|#" + strings.Join(tags, ",")
+	}
+
+	if w.buf.Len() > 0 && w.buf.Len()+1+len(line) > maxPacketSize {
+		w.send()
+	}
+	if w.buf.Len() > 0 {
+		w.buf.WriteByte('\n')
+	}
+	w.buf.WriteString(line)
+}
+
+func (w *packetWriter) send() {
+	if _, err := w.conn.Write(w.buf.Bytes()); err != nil {
+		w.errs = append(w.errs, err)
+	}
+	w.buf.Reset()
+}
+
+func (w *packetWriter) flush() error {
+	if w.buf.Len() > 0 {
+		w.send()
+	}
+	if len(w.errs) > 0 {
+		return fmt.Errorf("failed to send metrics to statsd server: %w", errors.Join(w.errs...))
+	}
+	return nil
+}
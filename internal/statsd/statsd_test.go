@@ -0,0 +1,61 @@
+package statsd
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExporterFlush(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer server.Close()
+
+	registry := prometheus.NewRegistry()
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{Name: "requests_total"}, []string{"method"})
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{Name: "connections"})
+	histogram := prometheus.NewHistogram(prometheus.HistogramOpts{Name: "latency", Buckets: []float64{0.1, 1}})
+	registry.MustRegister(counter, gauge, histogram)
+
+	exporter, err := NewExporter(registry, server.LocalAddr().String(), "spicedb.", []string{"env:test"}, time.Second)
+	require.NoError(t, err)
+
+	counter.WithLabelValues("Check").Add(3)
+	gauge.Set(7)
+	histogram.Observe(0.5)
+
+	require.NoError(t, exporter.Flush())
+	require.ElementsMatch(t, []string{
+		"spicedb.connections:7|g|#env:test",
+		"spicedb.latency_count:1|c|#env:test",
+		"spicedb.latency_sum:0.5|c|#env:test",
+		"spicedb.latency_bucket:1|c|#env:test,le:1",
+		"spicedb.requests_total:3|c|#method:Check,env:test",
+	}, receive(t, server))
+
+	// Counters are sent as their increase since the previous flush, and unchanged counters
+	// are not sent.
+	counter.WithLabelValues("Check").Add(2)
+	require.NoError(t, exporter.Flush())
+	require.ElementsMatch(t, []string{
+		"spicedb.connections:7|g|#env:test",
+		"spicedb.requests_total:2|c|#method:Check,env:test",
+	}, receive(t, server))
+}
+
+func TestNewExporterInvalidTag(t *testing.T) {
+	_, err := NewExporter(prometheus.NewRegistry(), "127.0.0.1:8125", "", []string{"env:a,b"}, time.Second)
+	require.ErrorContains(t, err, "invalid statsd tag")
+}
+
+func receive(t *testing.T, conn net.PacketConn) []string {
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(time.Second)))
+	buf := make([]byte, maxPacketSize)
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	return strings.Split(string(buf[:n]), "\n")
+}
@@ -18,6 +18,10 @@ type PreloadedTaskRunner struct {
 	// not exceed the concurrencyLimit with spawned goroutines.
 	sem chan struct{}
 
+	// sharedLimit, if not nil, bounds the goroutines spawned beyond the first across
+	// all the task runners sharing it.
+	sharedLimit *SharedLimit
+
 	wg    sync.WaitGroup
 	err   error
 	lock  sync.Mutex
@@ -25,6 +29,13 @@ type PreloadedTaskRunner struct {
 }
 
 func NewPreloadedTaskRunner(ctx context.Context, concurrencyLimit uint16, initialCapacity int) *PreloadedTaskRunner {
+	return NewPreloadedTaskRunnerWithSharedLimit(ctx, concurrencyLimit, nil, initialCapacity)
+}
+
+// NewPreloadedTaskRunnerWithSharedLimit creates a PreloadedTaskRunner whose goroutines, beyond
+// the first, are also bounded by the given SharedLimit. The first goroutine is always spawned,
+// so that task runners waiting on one another cannot exhaust the shared limit and deadlock.
+func NewPreloadedTaskRunnerWithSharedLimit(ctx context.Context, concurrencyLimit uint16, sharedLimit *SharedLimit, initialCapacity int) *PreloadedTaskRunner {
 	// Ensure a concurrency level of at least 1.
 	if concurrencyLimit <= 0 {
 		concurrencyLimit = 1
@@ -32,10 +43,11 @@ func NewPreloadedTaskRunner(ctx context.Context, concurrencyLimit uint16, initia
 
 	ctxWithCancel, cancel := context.WithCancel(ctx)
 	return &PreloadedTaskRunner{
-		ctx:    ctxWithCancel,
-		cancel: cancel,
-		sem:    make(chan struct{}, concurrencyLimit),
-		tasks:  make([]TaskFunc, 0, initialCapacity),
+		ctx:         ctxWithCancel,
+		cancel:      cancel,
+		sem:         make(chan struct{}, concurrencyLimit),
+		sharedLimit: sharedLimit,
+		tasks:       make([]TaskFunc, 0, initialCapacity),
 	}
 }
 
@@ -71,7 +83,18 @@ func (tr *PreloadedTaskRunner) spawnIfAvailable() {
 	// been canceled, in which case nothing needs to be done.
 	select {
 	case tr.sem <- struct{}{}:
-		go tr.runner()
+		// Runners are only spawned from Start, so the first runner is the only one
+		// holding the sem.
+		if tr.sharedLimit == nil || len(tr.sem) == 1 {
+			go tr.runner(false)
+			return
+		}
+
+		if !tr.sharedLimit.tryAcquire() {
+			<-tr.sem
+			return
+		}
+		go tr.runner(true)
 
 	case <-tr.ctx.Done():
 		// If the context was canceled, nothing more to do.
@@ -83,7 +106,11 @@ func (tr *PreloadedTaskRunner) spawnIfAvailable() {
 	}
 }
 
-func (tr *PreloadedTaskRunner) runner() {
+func (tr *PreloadedTaskRunner) runner(holdsSharedLimit bool) {
+	if holdsSharedLimit {
+		defer tr.sharedLimit.release()
+	}
+
 	for {
 		select {
 		case <-tr.ctx.Done():
@@ -150,3 +177,30 @@ func (tr *PreloadedTaskRunner) emptyForCancel() {
 		tr.wg.Done()
 	}
 }
+
+// SharedLimit bounds the number of goroutines spawned across multiple task runners.
+type SharedLimit struct {
+	sem chan struct{}
+}
+
+// NewSharedLimit returns a SharedLimit of the given number of goroutines, or nil, placing
+// no limit, if zero.
+func NewSharedLimit(limit uint32) *SharedLimit {
+	if limit == 0 {
+		return nil
+	}
+	return &SharedLimit{sem: make(chan struct{}, limit)}
+}
+
+func (sl *SharedLimit) tryAcquire() bool {
+	select {
+	case sl.sem <- struct{}{}:
+		return true
+	default:
+		return false
+	}
+}
+
+func (sl *SharedLimit) release() {
+	<-sl.sem
+}
@@ -152,3 +152,49 @@ func TestPreloadedTaskRunnerReturnsError(t *testing.T) {
 	require.GreaterOrEqual(t, count, 1)
 	require.Less(t, count, 9)
 }
+
+func TestPreloadedTaskRunnerSharedLimit(t *testing.T) {
+	defer goleak.VerifyNone(t)
+
+	sharedLimit := NewSharedLimit(1)
+
+	var lock sync.Mutex
+	running, maxRunning := 0, 0
+	task := func(ctx context.Context) error {
+		lock.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		lock.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+
+		lock.Lock()
+		running--
+		lock.Unlock()
+		return nil
+	}
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < 2; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			tr := NewPreloadedTaskRunnerWithSharedLimit(context.Background(), 5, sharedLimit, 5)
+			for j := 0; j < 5; j++ {
+				tr.Add(task)
+			}
+			require.NoError(t, tr.StartAndWait())
+		}()
+	}
+
+	testutil.RequireWithin(t, func(t *testing.T) {
+		wg.Wait()
+	}, 5*time.Second)
+
+	// Each task runner always has its first goroutine, and the two share one more.
+	require.LessOrEqual(t, maxRunning, 3)
+	require.Nil(t, NewSharedLimit(0))
+}
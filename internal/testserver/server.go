@@ -21,10 +21,16 @@ import (
 
 // ServerConfig is configuration for the test server.
 type ServerConfig struct {
-	MaxUpdatesPerWrite         uint16
-	MaxPreconditionsCount      uint16
-	MaxRelationshipContextSize int
-	StreamingAPITimeout        time.Duration
+	MaxUpdatesPerWrite            uint16
+	MaxPreconditionsCount         uint16
+	MaxRelationshipContextSize    int
+	MaxReadRelationshipsLimit     uint32
+	DefaultReadRelationshipsLimit uint32
+	MaxLookupResourcesLimit       uint32
+	DefaultLookupResourcesLimit   uint32
+	StreamingAPITimeout           time.Duration
+	MaterializedPermissions       []string
+	CheckSessionIdleTimeout       time.Duration
 }
 
 // NewTestServer creates a new test server, using defaults for the config.
@@ -65,6 +71,14 @@ func NewTestServerWithConfig(require *require.Assertions,
 		server.WithStreamingAPITimeout(config.StreamingAPITimeout),
 		server.WithMaxCaveatContextSize(4096),
 		server.WithMaxRelationshipContextSize(config.MaxRelationshipContextSize),
+		server.WithMaxReadRelationshipsLimit(config.MaxReadRelationshipsLimit),
+		server.WithDefaultReadRelationshipsLimit(config.DefaultReadRelationshipsLimit),
+		server.WithMaxLookupResourcesLimit(config.MaxLookupResourcesLimit),
+		server.WithDefaultLookupResourcesLimit(config.DefaultLookupResourcesLimit),
+		server.SetMaterializedPermissions(config.MaterializedPermissions),
+		server.WithMaterializedPermissionsMaxStaleness(time.Minute),
+		server.WithCheckSessionIdleTimeout(config.CheckSessionIdleTimeout),
+		server.WithWatchBufferLength(1024),
 		server.WithGRPCServer(util.GRPCServerConfig{
 			Network: util.BufferedNetwork,
 			Enabled: true,
@@ -0,0 +1,419 @@
+// Package vault sources the TLS certificate and preshared keys of the server from HashiCorp
+// Vault, keeping them in memory and refreshing them before their leases expire, so that they
+// are rotated without being written to disk or restarting the server.
+package vault
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+
+	log "github.com/authzed/spicedb/internal/logging"
+)
+
+const (
+	// AuthMethodToken authenticates with a token given to the server.
+	AuthMethodToken = "token"
+
+	// AuthMethodAppRole authenticates with an AppRole role ID and secret ID.
+	AuthMethodAppRole = "approle"
+
+	// AuthMethodKubernetes authenticates with the Kubernetes service account token of the pod.
+	AuthMethodKubernetes = "kubernetes"
+
+	// DefaultKubernetesTokenPath is where Kubernetes mounts the service account token of a pod.
+	DefaultKubernetesTokenPath = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+
+	// Fields of the secrets read from Vault. The TLS fields are those of the PKI secrets
+	// engine, and are expected to be used in KV secrets as well.
+	certificateField   = "certificate"
+	privateKeyField    = "private_key"
+	caChainField       = "ca_chain"
+	presharedKeysField = "preshared_keys"
+
+	// retryInterval is how long to wait before retrying a failed refresh.
+	retryInterval = 10 * time.Second
+)
+
+var refreshErrorsCounter = promauto.NewCounterVec(prometheus.CounterOpts{
+	Namespace: "spicedb",
+	Subsystem: "vault",
+	Name:      "refresh_errors_total",
+	Help:      "total number of failures to refresh a Vault token or secret, by secret",
+}, []string{"secret"})
+
+// Config configures how the server authenticates with Vault, and where its secrets are read.
+type Config struct {
+	// Address is the address of Vault. If empty, VAULT_ADDR is used.
+	Address string
+
+	// AuthMethod is one of AuthMethodToken, AuthMethodAppRole or AuthMethodKubernetes.
+	AuthMethod string
+
+	// AuthMount is the path at which the auth method is mounted. If empty, the name of the
+	// method is used.
+	AuthMount string
+
+	// Token is the token used by AuthMethodToken. If empty, VAULT_TOKEN is used.
+	Token string
+
+	// Role is the role ID for AuthMethodAppRole, or the role for AuthMethodKubernetes.
+	Role string
+
+	// SecretID is the secret ID for AuthMethodAppRole.
+	SecretID string
+
+	// KubernetesTokenPath is the path of the service account token for AuthMethodKubernetes.
+	KubernetesTokenPath string
+
+	// TLSPath is the path of the secret with the TLS certificate and private key, if any.
+	TLSPath string
+
+	// TLSCommonName, if set, issues the certificate by writing to TLSPath with the common
+	// name, as with the issue endpoint of the PKI secrets engine, rather than reading it.
+	TLSCommonName string
+
+	// PresharedKeysPath is the path of the secret with the comma-separated preshared keys, if
+	// any.
+	PresharedKeysPath string
+
+	// RefreshInterval is how often secrets without a lease, such as KV secrets, are read
+	// again to pick up rotations.
+	RefreshInterval time.Duration
+}
+
+// Secrets holds the secrets read from Vault.
+type Secrets struct {
+	client *api.Client
+	config Config
+
+	certificate   atomic.Pointer[tls.Certificate]
+	presharedKeys atomic.Pointer[[]string]
+
+	// tokenRenewAt, certificateRenewAt and presharedKeysRenewAt are when each should next
+	// be renewed or read again. They are only accessed by the goroutine running Run.
+	tokenRenewAt         time.Time
+	tokenRenewable       bool
+	certificateRenewAt   time.Time
+	presharedKeysRenewAt time.Time
+}
+
+// NewSecrets authenticates with Vault and reads the configured secrets, failing if any
+// cannot be read.
+func NewSecrets(ctx context.Context, config Config) (*Secrets, error) {
+	if config.TLSPath == "" && config.PresharedKeysPath == "" {
+		return nil, errors.New("no Vault secret paths were configured")
+	}
+	if config.RefreshInterval <= 0 {
+		return nil, errors.New("refresh interval of Vault secrets must be positive")
+	}
+	if config.AuthMount == "" {
+		config.AuthMount = config.AuthMethod
+	}
+	if config.KubernetesTokenPath == "" {
+		config.KubernetesTokenPath = DefaultKubernetesTokenPath
+	}
+
+	apiConfig := api.DefaultConfig()
+	if apiConfig.Error != nil {
+		return nil, fmt.Errorf("failed to configure Vault client: %w", apiConfig.Error)
+	}
+	if config.Address != "" {
+		apiConfig.Address = config.Address
+	}
+	// Renewals are scheduled by Run, which retries failures itself.
+	apiConfig.MaxRetries = 0
+
+	client, err := api.NewClient(apiConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Vault client: %w", err)
+	}
+
+	s := &Secrets{client: client, config: config}
+	now := time.Now()
+	if err := s.login(ctx, now); err != nil {
+		return nil, err
+	}
+	if config.TLSPath != "" {
+		if err := s.readCertificate(ctx, now); err != nil {
+			return nil, err
+		}
+	}
+	if config.PresharedKeysPath != "" {
+		if err := s.readPresharedKeys(ctx, now); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// GetCertificate returns the current TLS certificate, for use in a tls.Config.
+func (s *Secrets) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	certificate := s.certificate.Load()
+	if certificate == nil {
+		return nil, errors.New("no TLS certificate was read from Vault")
+	}
+	return certificate, nil
+}
+
+// PresharedKeys returns the current preshared keys.
+func (s *Secrets) PresharedKeys() []string {
+	keys := s.presharedKeys.Load()
+	if keys == nil {
+		return nil
+	}
+	return *keys
+}
+
+// Run renews the token and secrets before their leases expire, until the context is
+// canceled. Failures are logged and retried, while the previous secrets remain in use.
+func (s *Secrets) Run(ctx context.Context) error {
+	for {
+		next := s.tokenRenewAt
+		for _, renewAt := range []time.Time{s.certificateRenewAt, s.presharedKeysRenewAt} {
+			if !renewAt.IsZero() && (next.IsZero() || renewAt.Before(next)) {
+				next = renewAt
+			}
+		}
+		if next.IsZero() {
+			return nil
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			log.Ctx(ctx).Info().Msg("shutting down Vault secret renewal")
+			return nil
+		case <-timer.C:
+		}
+
+		s.refresh(ctx, time.Now())
+	}
+}
+
+// refresh renews the token and reads the secrets which are due.
+func (s *Secrets) refresh(ctx context.Context, now time.Time) {
+	if !s.tokenRenewAt.IsZero() && !now.Before(s.tokenRenewAt) {
+		if err := s.renewToken(ctx, now); err != nil {
+			refreshErrorsCounter.WithLabelValues("token").Inc()
+			log.Ctx(ctx).Error().Err(err).Msg("failed to renew Vault token")
+			s.tokenRenewAt = now.Add(retryInterval)
+		}
+	}
+
+	if !s.certificateRenewAt.IsZero() && !now.Before(s.certificateRenewAt) {
+		if err := s.readCertificate(ctx, now); err != nil {
+			refreshErrorsCounter.WithLabelValues("tls").Inc()
+			log.Ctx(ctx).Error().Err(err).Msg("failed to refresh TLS certificate from Vault")
+			s.certificateRenewAt = now.Add(retryInterval)
+		} else {
+			log.Ctx(ctx).Info().Time("renew-at", s.certificateRenewAt).Msg("refreshed TLS certificate from Vault")
+		}
+	}
+
+	if !s.presharedKeysRenewAt.IsZero() && !now.Before(s.presharedKeysRenewAt) {
+		if err := s.readPresharedKeys(ctx, now); err != nil {
+			refreshErrorsCounter.WithLabelValues("preshared_keys").Inc()
+			log.Ctx(ctx).Error().Err(err).Msg("failed to refresh preshared keys from Vault")
+			s.presharedKeysRenewAt = now.Add(retryInterval)
+		}
+	}
+}
+
+// login authenticates with the configured auth method.
+func (s *Secrets) login(ctx context.Context, now time.Time) error {
+	var data map[string]any
+	switch s.config.AuthMethod {
+	case AuthMethodToken:
+		if s.config.Token != "" {
+			s.client.SetToken(s.config.Token)
+		}
+		if s.client.Token() == "" {
+			return errors.New("no Vault token was provided")
+		}
+
+		secret, err := s.client.Auth().Token().LookupSelfWithContext(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to look up Vault token: %w", err)
+		}
+		ttl, err := secret.TokenTTL()
+		if err != nil {
+			return fmt.Errorf("failed to look up Vault token: %w", err)
+		}
+		renewable, err := secret.TokenIsRenewable()
+		if err != nil {
+			return fmt.Errorf("failed to look up Vault token: %w", err)
+		}
+		if !renewable && ttl > 0 {
+			// The token cannot be replaced without a restart, so it is only reported.
+			log.Ctx(ctx).Warn().Time("expires-at", now.Add(ttl)).Msg("the Vault token is not renewable and will expire")
+			return nil
+		}
+		s.scheduleToken(now, ttl, renewable)
+		return nil
+
+	case AuthMethodAppRole:
+		data = map[string]any{"role_id": s.config.Role, "secret_id": s.config.SecretID}
+
+	case AuthMethodKubernetes:
+		jwt, err := os.ReadFile(s.config.KubernetesTokenPath)
+		if err != nil {
+			return fmt.Errorf("failed to read Kubernetes service account token: %w", err)
+		}
+		data = map[string]any{"role": s.config.Role, "jwt": strings.TrimSpace(string(jwt))}
+
+	default:
+		return fmt.Errorf("unknown Vault auth method `%s`: expected %s, %s or %s", s.config.AuthMethod, AuthMethodToken, AuthMethodAppRole, AuthMethodKubernetes)
+	}
+
+	secret, err := s.client.Logical().WriteWithContext(ctx, "auth/"+s.config.AuthMount+"/login", data)
+	if err != nil {
+		return fmt.Errorf("failed to log in to Vault: %w", err)
+	}
+	if secret == nil || secret.Auth == nil {
+		return errors.New("failed to log in to Vault: no token was returned")
+	}
+
+	s.client.SetToken(secret.Auth.ClientToken)
+	s.scheduleToken(now, time.Duration(secret.Auth.LeaseDuration)*time.Second, secret.Auth.Renewable)
+	return nil
+}
+
+// renewToken renews the token, logging in again if it cannot be renewed.
+func (s *Secrets) renewToken(ctx context.Context, now time.Time) error {
+	if s.tokenRenewable {
+		secret, err := s.client.Auth().Token().RenewSelfWithContext(ctx, 0)
+		if err == nil && secret != nil && secret.Auth != nil {
+			s.scheduleToken(now, time.Duration(secret.Auth.LeaseDuration)*time.Second, secret.Auth.Renewable)
+			return nil
+		}
+		if s.config.AuthMethod == AuthMethodToken {
+			return fmt.Errorf("failed to renew Vault token: %w", err)
+		}
+		log.Ctx(ctx).Warn().Err(err).Msg("failed to renew Vault token; logging in again")
+	}
+	return s.login(ctx, now)
+}
+
+func (s *Secrets) scheduleToken(now time.Time, ttl time.Duration, renewable bool) {
+	s.tokenRenewable = renewable
+	s.tokenRenewAt = time.Time{}
+	if ttl > 0 {
+		s.tokenRenewAt = renewAt(now, ttl)
+	}
+}
+
+// readCertificate reads or issues the TLS certificate, renewing it when two thirds of its
+// lease or validity, whichever is shorter, have elapsed.
+func (s *Secrets) readCertificate(ctx context.Context, now time.Time) error {
+	var secret *api.Secret
+	var err error
+	if s.config.TLSCommonName != "" {
+		secret, err = s.client.Logical().WriteWithContext(ctx, s.config.TLSPath, map[string]any{"common_name": s.config.TLSCommonName})
+	} else {
+		secret, err = s.client.Logical().ReadWithContext(ctx, s.config.TLSPath)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read TLS certificate from Vault: %w", err)
+	}
+
+	data, err := secretData(secret, s.config.TLSPath)
+	if err != nil {
+		return err
+	}
+	certificatePEM, _ := data[certificateField].(string)
+	privateKeyPEM, _ := data[privateKeyField].(string)
+	if certificatePEM == "" || privateKeyPEM == "" {
+		return fmt.Errorf("secret %s in Vault must have the fields `%s` and `%s`", s.config.TLSPath, certificateField, privateKeyField)
+	}
+	if chain, ok := data[caChainField].([]any); ok {
+		for _, ca := range chain {
+			if ca, ok := ca.(string); ok {
+				certificatePEM += "\n" + ca
+			}
+		}
+	}
+
+	certificate, err := tls.X509KeyPair([]byte(certificatePEM), []byte(privateKeyPEM))
+	if err != nil {
+		return fmt.Errorf("invalid TLS certificate in secret %s in Vault: %w", s.config.TLSPath, err)
+	}
+	certificate.Leaf, err = x509.ParseCertificate(certificate.Certificate[0])
+	if err != nil {
+		return fmt.Errorf("invalid TLS certificate in secret %s in Vault: %w", s.config.TLSPath, err)
+	}
+
+	s.certificate.Store(&certificate)
+	s.certificateRenewAt = s.secretRenewAt(now, secret)
+	if validity := certificate.Leaf.NotAfter.Sub(certificate.Leaf.NotBefore); validity > 0 {
+		if expiresAt := certificate.Leaf.NotBefore.Add(validity * 2 / 3); expiresAt.Before(s.certificateRenewAt) {
+			s.certificateRenewAt = expiresAt
+		}
+	}
+	return nil
+}
+
+// readPresharedKeys reads the preshared keys.
+func (s *Secrets) readPresharedKeys(ctx context.Context, now time.Time) error {
+	secret, err := s.client.Logical().ReadWithContext(ctx, s.config.PresharedKeysPath)
+	if err != nil {
+		return fmt.Errorf("failed to read preshared keys from Vault: %w", err)
+	}
+
+	data, err := secretData(secret, s.config.PresharedKeysPath)
+	if err != nil {
+		return err
+	}
+	value, _ := data[presharedKeysField].(string)
+
+	var keys []string
+	for _, key := range strings.Split(value, ",") {
+		if key = strings.TrimSpace(key); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) == 0 {
+		return fmt.Errorf("secret %s in Vault must have the field `%s` with one or more comma-separated keys", s.config.PresharedKeysPath, presharedKeysField)
+	}
+
+	s.presharedKeys.Store(&keys)
+	s.presharedKeysRenewAt = s.secretRenewAt(now, secret)
+	return nil
+}
+
+// secretRenewAt returns when the secret should be read again: when two thirds of its lease
+// have elapsed or, for secrets without a lease, after the refresh interval.
+func (s *Secrets) secretRenewAt(now time.Time, secret *api.Secret) time.Time {
+	if secret.LeaseDuration > 0 {
+		return renewAt(now, time.Duration(secret.LeaseDuration)*time.Second)
+	}
+	return now.Add(s.config.RefreshInterval)
+}
+
+// secretData returns the data of the secret, unwrapping the data of KV version 2 secrets.
+func secretData(secret *api.Secret, path string) (map[string]any, error) {
+	if secret == nil || secret.Data == nil {
+		return nil, fmt.Errorf("secret %s was not found in Vault", path)
+	}
+	if data, ok := secret.Data["data"].(map[string]any); ok {
+		if _, ok := secret.Data["metadata"]; ok {
+			return data, nil
+		}
+	}
+	return secret.Data, nil
+}
+
+func renewAt(now time.Time, ttl time.Duration) time.Time {
+	return now.Add(ttl * 2 / 3)
+}
@@ -0,0 +1,220 @@
+package vault
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// fakeVault serves the Vault endpoints used by Secrets.
+type fakeVault struct {
+	t *testing.T
+
+	sync.Mutex
+	presharedKeys string
+	commonNames   []string
+	logins        []map[string]any
+	renewals      int
+}
+
+func (fv *fakeVault) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	fv.Lock()
+	defer fv.Unlock()
+
+	var body map[string]any
+	if r.Body != nil {
+		_ = json.NewDecoder(r.Body).Decode(&body)
+	}
+
+	var response map[string]any
+	switch r.URL.Path {
+	case "/v1/auth/approle/login", "/v1/auth/k8s/login":
+		fv.logins = append(fv.logins, body)
+		response = map[string]any{"auth": map[string]any{"client_token": "login-token", "lease_duration": 3600, "renewable": true}}
+
+	case "/v1/auth/token/lookup-self":
+		if r.Header.Get("X-Vault-Token") != "static-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		response = map[string]any{"data": map[string]any{"ttl": 3600, "renewable": true}}
+
+	case "/v1/auth/token/renew-self":
+		fv.renewals++
+		response = map[string]any{"auth": map[string]any{"client_token": r.Header.Get("X-Vault-Token"), "lease_duration": 3600, "renewable": true}}
+
+	case "/v1/secret/data/spicedb":
+		if r.Header.Get("X-Vault-Token") == "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		response = map[string]any{"data": map[string]any{
+			"data":     map[string]any{"preshared_keys": fv.presharedKeys},
+			"metadata": map[string]any{"version": 1},
+		}}
+
+	case "/v1/pki/issue/spicedb":
+		commonName, _ := body["common_name"].(string)
+		fv.commonNames = append(fv.commonNames, commonName)
+		certificate, privateKey := selfSignedCertificate(fv.t, commonName, time.Hour)
+		response = map[string]any{
+			"lease_duration": 3600,
+			"data":           map[string]any{"certificate": certificate, "private_key": privateKey},
+		}
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	require.NoError(fv.t, json.NewEncoder(w).Encode(response))
+}
+
+func TestSecrets(t *testing.T) {
+	fv := &fakeVault{t: t, presharedKeys: "first, second"}
+	server := httptest.NewServer(fv)
+	defer server.Close()
+
+	ctx := context.Background()
+	secrets, err := NewSecrets(ctx, Config{
+		Address:           server.URL,
+		AuthMethod:        AuthMethodAppRole,
+		Role:              "some-role",
+		SecretID:          "some-secret",
+		TLSPath:           "pki/issue/spicedb",
+		TLSCommonName:     "spicedb.example.com",
+		PresharedKeysPath: "secret/data/spicedb",
+		RefreshInterval:   time.Minute,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, []map[string]any{{"role_id": "some-role", "secret_id": "some-secret"}}, fv.logins)
+	require.Equal(t, []string{"first", "second"}, secrets.PresharedKeys())
+
+	certificate, err := secrets.GetCertificate(nil)
+	require.NoError(t, err)
+	require.Equal(t, "spicedb.example.com", certificate.Leaf.Subject.CommonName)
+
+	// Secrets and the token are renewed after two thirds of their lease, while secrets
+	// without a lease are read again after the refresh interval.
+	now := time.Now()
+	require.WithinDuration(t, now.Add(40*time.Minute), secrets.tokenRenewAt, time.Minute)
+	require.WithinDuration(t, now.Add(40*time.Minute), secrets.certificateRenewAt, time.Minute)
+	require.WithinDuration(t, now.Add(time.Minute), secrets.presharedKeysRenewAt, time.Minute)
+
+	fv.presharedKeys = "second,third"
+	secrets.refresh(ctx, now.Add(2*time.Minute))
+	require.Equal(t, []string{"second", "third"}, secrets.PresharedKeys())
+	require.Len(t, fv.commonNames, 1)
+	require.Zero(t, fv.renewals)
+
+	secrets.refresh(ctx, now.Add(time.Hour))
+	require.Equal(t, 1, fv.renewals)
+	require.Len(t, fv.commonNames, 2)
+	renewed, err := secrets.GetCertificate(nil)
+	require.NoError(t, err)
+	require.NotSame(t, certificate, renewed)
+
+	// Failed refreshes keep the previous secrets and are retried.
+	fv.presharedKeys = ""
+	secrets.refresh(ctx, now.Add(2*time.Hour))
+	require.Equal(t, []string{"second", "third"}, secrets.PresharedKeys())
+	require.WithinDuration(t, now.Add(2*time.Hour+retryInterval), secrets.presharedKeysRenewAt, time.Second)
+}
+
+func TestSecretsAuthMethods(t *testing.T) {
+	fv := &fakeVault{t: t, presharedKeys: "key"}
+	server := httptest.NewServer(fv)
+	defer server.Close()
+
+	ctx := context.Background()
+	config := Config{
+		Address:           server.URL,
+		PresharedKeysPath: "secret/data/spicedb",
+		RefreshInterval:   time.Minute,
+	}
+
+	t.Run("token", func(t *testing.T) {
+		config := config
+		config.AuthMethod = AuthMethodToken
+		config.Token = "static-token"
+		secrets, err := NewSecrets(ctx, config)
+		require.NoError(t, err)
+		require.Equal(t, []string{"key"}, secrets.PresharedKeys())
+
+		config.Token = "wrong-token"
+		_, err = NewSecrets(ctx, config)
+		require.ErrorContains(t, err, "failed to look up Vault token")
+	})
+
+	t.Run("kubernetes", func(t *testing.T) {
+		tokenPath := filepath.Join(t.TempDir(), "token")
+		require.NoError(t, os.WriteFile(tokenPath, []byte("service-account-jwt\n"), 0o600))
+
+		config := config
+		config.AuthMethod = AuthMethodKubernetes
+		config.AuthMount = "k8s"
+		config.Role = "spicedb"
+		config.KubernetesTokenPath = tokenPath
+		_, err := NewSecrets(ctx, config)
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"role": "spicedb", "jwt": "service-account-jwt"}, fv.logins[len(fv.logins)-1])
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		config := config
+		config.AuthMethod = "ldap"
+		_, err := NewSecrets(ctx, config)
+		require.ErrorContains(t, err, "unknown Vault auth method `ldap`")
+	})
+
+	t.Run("missing secret", func(t *testing.T) {
+		config := config
+		config.AuthMethod = AuthMethodAppRole
+		config.TLSPath = "secret/data/missing"
+		_, err := NewSecrets(ctx, config)
+		require.ErrorContains(t, err, "secret secret/data/missing was not found in Vault")
+	})
+
+	t.Run("no paths", func(t *testing.T) {
+		_, err := NewSecrets(ctx, Config{AuthMethod: AuthMethodAppRole, RefreshInterval: time.Minute})
+		require.ErrorContains(t, err, "no Vault secret paths were configured")
+	})
+}
+
+func selfSignedCertificate(t *testing.T, commonName string, validity time.Duration) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Minute),
+		NotAfter:     time.Now().Add(validity),
+		DNSNames:     []string{commonName},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}))
+}
@@ -0,0 +1,51 @@
+// Package xds allows gRPC connections to resolve and balance their upstreams using the
+// xDS APIs of a service mesh control plane, by dialing `xds:///` targets.
+//
+// The connection reads the control plane's address from the bootstrap file named by the
+// GRPC_XDS_BOOTSTRAP environment variable (or its contents from GRPC_XDS_BOOTSTRAP_CONFIG).
+package xds
+
+import (
+	"context"
+	"encoding/hex"
+	"strings"
+
+	"github.com/authzed/consistent"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	// Registers the xds resolver and the balancers used by xDS clusters.
+	_ "google.golang.org/grpc/xds"
+)
+
+// Scheme is the gRPC target scheme resolved through xDS.
+const Scheme = "xds"
+
+// HashKeyHeader carries the hex-encoded dispatch key of each request made through an xDS
+// connection. The hashring balancer cannot be used when the control plane picks the
+// balancing policy, so routes should hash on this header (for example with Envoy's
+// `ring_hash` policy) to keep requests for the same subproblem on the same node.
+const HashKeyHeader = "io.spicedb.dispatch-hash-key"
+
+// IsTarget returns whether the given gRPC target is resolved through xDS.
+func IsTarget(target string) bool {
+	return strings.HasPrefix(target, Scheme+":")
+}
+
+// UnaryHashKeyInterceptor adds the request's dispatch key to its metadata as HashKeyHeader.
+func UnaryHashKeyInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return invoker(withHashKey(ctx), method, req, reply, cc, opts...)
+}
+
+// StreamHashKeyInterceptor adds the stream's dispatch key to its metadata as HashKeyHeader.
+func StreamHashKeyInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return streamer(withHashKey(ctx), desc, cc, method, opts...)
+}
+
+func withHashKey(ctx context.Context) context.Context {
+	key, ok := ctx.Value(consistent.CtxKey).([]byte)
+	if !ok {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, HashKeyHeader, hex.EncodeToString(key))
+}
@@ -0,0 +1,40 @@
+package xds
+
+import (
+	"context"
+	"testing"
+
+	"github.com/authzed/consistent"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/resolver"
+)
+
+func TestIsTarget(t *testing.T) {
+	require.True(t, IsTarget("xds:///spicedb-dispatch"))
+	require.True(t, IsTarget("xds://trafficdirector.googleapis.com/spicedb"))
+	require.False(t, IsTarget("dns:///spicedb:50053"))
+	require.False(t, IsTarget("xds-dispatch:50053"))
+	require.NotNil(t, resolver.Get(Scheme))
+}
+
+func TestHashKeyInterceptors(t *testing.T) {
+	ctx := context.WithValue(context.Background(), consistent.CtxKey, []byte{0x01, 0xab})
+
+	var sent metadata.MD
+	err := UnaryHashKeyInterceptor(ctx, "/dispatch", nil, nil, nil, func(ctx context.Context, _ string, _, _ any, _ *grpc.ClientConn, _ ...grpc.CallOption) error {
+		sent, _ = metadata.FromOutgoingContext(ctx)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, []string{"01ab"}, sent.Get(HashKeyHeader))
+
+	sent = nil
+	_, err = StreamHashKeyInterceptor(context.Background(), nil, nil, "/dispatch", func(ctx context.Context, _ *grpc.StreamDesc, _ *grpc.ClientConn, _ string, _ ...grpc.CallOption) (grpc.ClientStream, error) {
+		sent, _ = metadata.FromOutgoingContext(ctx)
+		return nil, nil
+	})
+	require.NoError(t, err)
+	require.Empty(t, sent.Get(HashKeyHeader))
+}
@@ -0,0 +1,106 @@
+package cache
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// reportedBudget is the budget whose size and usage are reported as metrics.
+var reportedBudget atomic.Pointer[Budget]
+
+// Budget shares a maximum cost between caches. Each cache requests a maximum cost of its own
+// and, while the requests fit within the budget, is given it; otherwise every cache is given a
+// share of the budget in proportion to its request, so that they shrink, and evict entries,
+// together.
+type Budget struct {
+	maxCost int64
+
+	mu     sync.Mutex
+	caches map[string]*budgetedCache
+}
+
+type budgetedCache struct {
+	cache     Cache
+	resizable Resizable
+	requested int64
+}
+
+// NewBudget creates a budget of the given maximum cost, which is reported as metrics until it
+// is closed or another budget is created.
+func NewBudget(maxCost int64) *Budget {
+	b := &Budget{
+		maxCost: maxCost,
+		caches:  map[string]*budgetedCache{},
+	}
+	reportedBudget.Store(b)
+	return b
+}
+
+// MaxCost returns the maximum cost shared by the caches.
+func (b *Budget) MaxCost() int64 {
+	return b.maxCost
+}
+
+// Add places the cache of the given name within the budget, requesting the given maximum cost.
+// Caches which cannot be resized, such as those which are disabled, are ignored.
+func (b *Budget) Add(name string, c Cache, requested int64) {
+	resizable, ok := c.(Resizable)
+	if !ok {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.caches[name] = &budgetedCache{c, resizable, requested}
+	b.rebalance()
+}
+
+// Request changes the maximum cost requested by the cache of the given name, resizing every
+// cache in the budget as needed.
+func (b *Budget) Request(name string, requested int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c, ok := b.caches[name]
+	if !ok {
+		return
+	}
+	c.requested = requested
+	b.rebalance()
+}
+
+func (b *Budget) rebalance() {
+	var total int64
+	for _, c := range b.caches {
+		total += c.requested
+	}
+
+	for _, c := range b.caches {
+		maxCost := c.requested
+		if total > b.maxCost {
+			maxCost = int64(float64(c.requested) / float64(total) * float64(b.maxCost))
+		}
+		c.resizable.UpdateMaxCost(max(maxCost, 1))
+	}
+}
+
+// Usage returns the cost of the entries in the caches. Only caches created with metrics
+// contribute to it.
+func (b *Budget) Usage() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var usage int64
+	for _, c := range b.caches {
+		metrics := c.cache.GetMetrics()
+		if added, evicted := metrics.CostAdded(), metrics.CostEvicted(); added > evicted {
+			usage += int64(added - evicted)
+		}
+	}
+	return usage
+}
+
+// Close stops reporting the budget as metrics.
+func (b *Budget) Close() {
+	reportedBudget.CompareAndSwap(b, nil)
+}
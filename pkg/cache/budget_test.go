@@ -0,0 +1,60 @@
+package cache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestCache(t *testing.T, maxCost int64) Cache {
+	c, err := NewCache(&Config{MaxCost: maxCost, NumCounters: 1000})
+	require.NoError(t, err)
+	t.Cleanup(c.Close)
+	return c
+}
+
+func maxCostOf(c Cache) int64 {
+	return c.(Resizable).MaxCost()
+}
+
+func TestBudget(t *testing.T) {
+	budget := NewBudget(1000)
+	t.Cleanup(budget.Close)
+
+	first := newTestCache(t, 600)
+	budget.Add("first", first, 600)
+	require.Equal(t, int64(600), maxCostOf(first))
+
+	// Once the caches request more than the budget, each is given a share in proportion to
+	// its request.
+	second := newTestCache(t, 1800)
+	budget.Add("second", second, 1800)
+	require.Equal(t, int64(250), maxCostOf(first))
+	require.Equal(t, int64(750), maxCostOf(second))
+
+	budget.Request("second", 400)
+	require.Equal(t, int64(600), maxCostOf(first))
+	require.Equal(t, int64(400), maxCostOf(second))
+
+	// Caches which cannot be resized are ignored.
+	budget.Add("disabled", NoopCache(), 1000)
+	require.Equal(t, int64(600), maxCostOf(first))
+}
+
+func TestBudgetUsage(t *testing.T) {
+	budget := NewBudget(1 << 20)
+	t.Cleanup(budget.Close)
+
+	c, err := NewCacheWithMetrics("budget_usage", &Config{MaxCost: 1 << 20, NumCounters: 1000})
+	require.NoError(t, err)
+	t.Cleanup(c.Close)
+	budget.Add("cache", c, 1<<20)
+
+	require.True(t, c.Set("key", "value", 100))
+	c.Wait()
+	require.GreaterOrEqual(t, budget.Usage(), int64(100))
+	require.Equal(t, budget, reportedBudget.Load())
+
+	budget.Close()
+	require.Nil(t, reportedBudget.Load())
+}
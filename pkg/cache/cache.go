@@ -65,6 +65,25 @@ type Cache interface {
 	zerolog.LogObjectMarshaler
 }
 
+// Resizable is implemented by caches whose maximum cost can be changed while in use.
+type Resizable interface {
+	// MaxCost returns the maximum cost of the cache.
+	MaxCost() int64
+
+	// UpdateMaxCost changes the maximum cost of the cache. When shrunk, entries are evicted
+	// as new ones are added until the cache is within its new maximum.
+	UpdateMaxCost(maxCost int64)
+}
+
+// Flushable is implemented by caches whose entries can be removed while in use.
+type Flushable interface {
+	// Del removes the entry for the key, if any.
+	Del(key any)
+
+	// Clear removes all entries.
+	Clear()
+}
+
 // Metrics defines metrics exported by the cache.
 type Metrics interface {
 	// Hits is the number of cache hits.
@@ -73,11 +92,17 @@ type Metrics interface {
 	// Misses is the number of cache misses.
 	Misses() uint64
 
+	// KeysAdded returns the total number of added items.
+	KeysAdded() uint64
+
 	// CostAdded returns the total cost of added items.
 	CostAdded() uint64
 
 	// CostEvicted returns the total cost of evicted items.
 	CostEvicted() uint64
+
+	// KeysEvicted returns the total number of evicted items.
+	KeysEvicted() uint64
 }
 
 // NoopCache returns a cache that does nothing.
@@ -102,5 +127,7 @@ var _ Metrics = (*noopMetrics)(nil)
 
 func (no *noopMetrics) Hits() uint64        { return 0 }
 func (no *noopMetrics) Misses() uint64      { return 0 }
+func (no *noopMetrics) KeysAdded() uint64   { return 0 }
 func (no *noopMetrics) CostAdded() uint64   { return 0 }
 func (no *noopMetrics) CostEvicted() uint64 { return 0 }
+func (no *noopMetrics) KeysEvicted() uint64 { return 0 }
@@ -64,7 +64,11 @@ func (w wrapped) Set(key, entry any, cost int64) bool {
 	return w.Cache.SetWithTTL(key, entry, cost, w.defaultTTL)
 }
 
-var _ Cache = (*wrapped)(nil)
+var (
+	_ Cache     = (*wrapped)(nil)
+	_ Resizable = (*wrapped)(nil)
+	_ Flushable = (*wrapped)(nil)
+)
 
 func (w wrapped) GetMetrics() Metrics                   { return w.Cache.Metrics }
 func (w wrapped) MarshalZerologObject(e *zerolog.Event) { e.EmbedObject(w.config) }
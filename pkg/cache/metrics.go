@@ -44,6 +44,34 @@ var (
 		[]string{"cache"},
 		nil,
 	)
+
+	descEntries = prometheus.NewDesc(
+		stringz.Join("_", promNamespace, promSubsystem, "entries"),
+		"Number of entries in the cache",
+		[]string{"cache"},
+		nil,
+	)
+
+	descEvictionsTotal = prometheus.NewDesc(
+		stringz.Join("_", promNamespace, promSubsystem, "evictions_total"),
+		"Number of entries evicted from the cache",
+		[]string{"cache"},
+		nil,
+	)
+
+	descMemoryBudgetBytes = prometheus.NewDesc(
+		stringz.Join("_", promNamespace, promSubsystem, "memory_budget_bytes"),
+		"Maximum cost shared by the caches within the memory budget",
+		nil,
+		nil,
+	)
+
+	descMemoryUsageBytes = prometheus.NewDesc(
+		stringz.Join("_", promNamespace, promSubsystem, "memory_usage_bytes"),
+		"Cost of the entries in the caches within the memory budget",
+		nil,
+		nil,
+	)
 )
 
 var caches sync.Map
@@ -58,6 +86,16 @@ func unregisterCache(name string) {
 	caches.Delete(name)
 }
 
+// Registered returns the caches created with metrics, by name.
+func Registered() map[string]Cache {
+	registered := map[string]Cache{}
+	caches.Range(func(name, cache any) bool {
+		registered[name.(string)] = cache.(Cache)
+		return true
+	})
+	return registered
+}
+
 var (
 	defaultCollector collector
 
@@ -78,6 +116,22 @@ func (c collector) Collect(ch chan<- prometheus.Metric) {
 		ch <- prometheus.MustNewConstMetric(descCacheMissesTotal, prometheus.CounterValue, float64(metrics.Misses()), cacheName)
 		ch <- prometheus.MustNewConstMetric(descCostAddedBytes, prometheus.CounterValue, float64(metrics.CostAdded()), cacheName)
 		ch <- prometheus.MustNewConstMetric(descCostEvictedBytes, prometheus.CounterValue, float64(metrics.CostEvicted()), cacheName)
+		ch <- prometheus.MustNewConstMetric(descEvictionsTotal, prometheus.CounterValue, float64(metrics.KeysEvicted()), cacheName)
+		ch <- prometheus.MustNewConstMetric(descEntries, prometheus.GaugeValue, float64(Entries(metrics)), cacheName)
 		return true
 	})
+
+	if budget := reportedBudget.Load(); budget != nil {
+		ch <- prometheus.MustNewConstMetric(descMemoryBudgetBytes, prometheus.GaugeValue, float64(budget.MaxCost()))
+		ch <- prometheus.MustNewConstMetric(descMemoryUsageBytes, prometheus.GaugeValue, float64(budget.Usage()))
+	}
+}
+
+// Entries returns the number of entries in the cache, as the number added less those evicted.
+func Entries(metrics Metrics) uint64 {
+	added, evicted := metrics.KeysAdded(), metrics.KeysEvicted()
+	if evicted > added {
+		return 0
+	}
+	return added - evicted
 }
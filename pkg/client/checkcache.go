@@ -0,0 +1,325 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const (
+	defaultCheckCacheMaxEntries = 10_000
+
+	checkCacheMinBackoff = 100 * time.Millisecond
+	checkCacheMaxBackoff = 10 * time.Second
+
+	// The request metadata keys with which the Watch API sends checkpoints and ends on schema
+	// changes, and the reason of the error with which it ends.
+	watchCheckpointsHeaderKey   = "io.spicedb.watchcheckpoints"
+	watchSchemaChangesHeaderKey = "io.spicedb.watchschemachanges"
+	reasonWatchSchemaChanged    = "ERROR_REASON_WATCH_SCHEMA_CHANGED"
+)
+
+// CheckCacheOption configures a CheckCache.
+type CheckCacheOption func(*checkCacheOptions)
+
+type checkCacheOptions struct {
+	maxEntries int
+}
+
+// WithCheckCacheMaxEntries limits the number of check results held by the cache. Once full,
+// arbitrary results are evicted to make room. By default, 10000 results are held.
+func WithCheckCacheMaxEntries(maxEntries int) CheckCacheOption {
+	return func(o *checkCacheOptions) { o.maxEntries = maxEntries }
+}
+
+// CheckCache holds the results of permission checks locally, both those which have the
+// permission and those which do not, so that repeated checks are answered without a request
+// to the server. The cache watches the changes to relationships, and evicts the results of
+// the permissions which a change can affect, as determined from the schema. A change to the
+// schema clears the cache.
+//
+// Results may lag changes by the latency of the Watch API; in particular, a write made through
+// the client is not observed by the cache until it is received from the watch. Only checks
+// whose consistency minimizes latency, or which do not specify a consistency, are cached;
+// others are always sent to the server. Whenever the watch fails, the cache is cleared and
+// checks are sent to the server until it is reestablished.
+//
+// A CheckCache is safe for concurrent use.
+type CheckCache struct {
+	client     *Client
+	maxEntries int
+	cancel     context.CancelFunc
+	done       chan struct{}
+
+	sync.Mutex
+	entries       map[checkCacheKey]*v1.CheckPermissionResponse
+	byPermission  map[string]map[checkCacheKey]struct{}
+	invalidations invalidations
+	revision      *v1.ZedToken
+	epoch         uint64
+}
+
+type checkCacheKey struct {
+	resourceType    string
+	resourceID      string
+	permission      string
+	subjectType     string
+	subjectID       string
+	subjectRelation string
+	context         string
+}
+
+// NewCheckCache starts a cache of the checks made through the client, which watches for
+// changes until it is closed.
+func (c *Client) NewCheckCache(ctx context.Context, opts ...CheckCacheOption) (*CheckCache, error) {
+	o := checkCacheOptions{maxEntries: defaultCheckCacheMaxEntries}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.maxEntries <= 0 {
+		return nil, errors.New("the check cache must hold at least one entry")
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	cache := &CheckCache{
+		client:     c,
+		maxEntries: o.maxEntries,
+		cancel:     cancel,
+		done:       make(chan struct{}),
+	}
+	go cache.run(ctx)
+	return cache, nil
+}
+
+// Close stops watching for changes. Checks made after the cache is closed are sent to the
+// server.
+func (cc *CheckCache) Close() {
+	cc.cancel()
+	<-cc.done
+}
+
+// CheckPermission checks the permission, returning the cached result if there is one.
+func (cc *CheckCache) CheckPermission(ctx context.Context, req *v1.CheckPermissionRequest, opts ...grpc.CallOption) (*v1.CheckPermissionResponse, error) {
+	if req.GetConsistency().GetRequirement() != nil && !req.GetConsistency().GetMinimizeLatency() {
+		return cc.client.CheckPermission(ctx, req, opts...)
+	}
+
+	key, err := newCheckCacheKey(req)
+	if err != nil {
+		return nil, err
+	}
+
+	cc.Lock()
+	cached, ok := cc.entries[key]
+	revision, epoch := cc.revision, cc.epoch
+	cc.Unlock()
+	if ok {
+		return proto.Clone(cached).(*v1.CheckPermissionResponse), nil
+	}
+	if revision == nil {
+		return cc.client.CheckPermission(ctx, req, opts...)
+	}
+
+	// The result must be at least as fresh as the changes which have been applied to the cache,
+	// so that it is evicted by any later change.
+	fresh := proto.Clone(req).(*v1.CheckPermissionRequest)
+	fresh.Consistency = &v1.Consistency{Requirement: &v1.Consistency_AtLeastAsFresh{AtLeastAsFresh: revision}}
+	resp, err := cc.client.CheckPermission(ctx, fresh, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	cc.store(key, epoch, resp)
+	return resp, nil
+}
+
+// HasPermission returns whether the subject has the permission on the resource, as
+// Client.HasPermission does, returning the cached result if there is one.
+func (cc *CheckCache) HasPermission(ctx context.Context, resource, permission, subject string) (bool, error) {
+	req, err := CheckRequest(resource, permission, subject)
+	if err != nil {
+		return false, err
+	}
+
+	resp, err := cc.CheckPermission(ctx, req)
+	if err != nil {
+		return false, err
+	}
+	return hasPermission(resp)
+}
+
+func newCheckCacheKey(req *v1.CheckPermissionRequest) (checkCacheKey, error) {
+	var caveatContext []byte
+	if req.Context != nil {
+		var err error
+		caveatContext, err = proto.MarshalOptions{Deterministic: true}.Marshal(req.Context)
+		if err != nil {
+			return checkCacheKey{}, fmt.Errorf("failed to marshal caveat context: %w", err)
+		}
+	}
+
+	return checkCacheKey{
+		resourceType:    req.GetResource().GetObjectType(),
+		resourceID:      req.GetResource().GetObjectId(),
+		permission:      req.Permission,
+		subjectType:     req.GetSubject().GetObject().GetObjectType(),
+		subjectID:       req.GetSubject().GetObject().GetObjectId(),
+		subjectRelation: req.GetSubject().GetOptionalRelation(),
+		context:         string(caveatContext),
+	}, nil
+}
+
+// store caches the result of a check, unless a change was applied to the cache since it was
+// made, which it might not reflect.
+func (cc *CheckCache) store(key checkCacheKey, epoch uint64, resp *v1.CheckPermissionResponse) {
+	cc.Lock()
+	defer cc.Unlock()
+	if cc.epoch != epoch || cc.revision == nil {
+		return
+	}
+
+	if len(cc.entries) >= cc.maxEntries {
+		for evicted := range cc.entries {
+			cc.evict(evicted)
+			break
+		}
+	}
+
+	permission := tuple.JoinRelRef(key.resourceType, key.permission)
+	if cc.byPermission[permission] == nil {
+		cc.byPermission[permission] = make(map[checkCacheKey]struct{})
+	}
+	cc.byPermission[permission][key] = struct{}{}
+	cc.entries[key] = proto.Clone(resp).(*v1.CheckPermissionResponse)
+}
+
+func (cc *CheckCache) evict(key checkCacheKey) {
+	delete(cc.entries, key)
+	permission := tuple.JoinRelRef(key.resourceType, key.permission)
+	delete(cc.byPermission[permission], key)
+	if len(cc.byPermission[permission]) == 0 {
+		delete(cc.byPermission, permission)
+	}
+}
+
+// reset clears the cache, which holds no results until it is started again from the revision
+// with the invalidations of the schema read at it.
+func (cc *CheckCache) reset(revision *v1.ZedToken, inv invalidations) {
+	cc.Lock()
+	defer cc.Unlock()
+	cc.entries = make(map[checkCacheKey]*v1.CheckPermissionResponse)
+	cc.byPermission = make(map[string]map[checkCacheKey]struct{})
+	cc.invalidations = inv
+	cc.revision = revision
+	cc.epoch++
+}
+
+// apply evicts the results affected by the changes, and advances the cache to their revision.
+func (cc *CheckCache) apply(resp *v1.WatchResponse) {
+	cc.Lock()
+	defer cc.Unlock()
+	if cc.revision == nil {
+		return
+	}
+
+	if len(resp.Updates) > 0 {
+		cc.epoch++
+	}
+	for _, update := range resp.Updates {
+		relation := tuple.JoinRelRef(update.GetRelationship().GetResource().GetObjectType(), update.GetRelationship().GetRelation())
+		for _, permission := range cc.invalidations[relation] {
+			for key := range cc.byPermission[permission] {
+				delete(cc.entries, key)
+			}
+			delete(cc.byPermission, permission)
+		}
+	}
+	if resp.ChangesThrough != nil {
+		cc.revision = resp.ChangesThrough
+	}
+}
+
+// run watches for changes until the context is canceled, restarting the watch with an empty
+// cache whenever it fails.
+func (cc *CheckCache) run(ctx context.Context) {
+	defer close(cc.done)
+
+	backoff := checkCacheMinBackoff
+	for {
+		err := cc.watch(ctx)
+		cc.reset(nil, nil)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if schemaChanged(err) {
+			backoff = checkCacheMinBackoff
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, checkCacheMaxBackoff)
+	}
+}
+
+// watch reads the schema and applies the changes made since until the watch fails.
+func (cc *CheckCache) watch(ctx context.Context) error {
+	schema, err := cc.client.ReadSchema(ctx, &v1.ReadSchemaRequest{})
+	if err != nil {
+		return err
+	}
+
+	inv, err := newInvalidations(schema.SchemaText)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ctx = metadata.AppendToOutgoingContext(ctx, watchCheckpointsHeaderKey, "true", watchSchemaChangesHeaderKey, "true")
+	stream, err := cc.client.Watch(ctx, &v1.WatchRequest{OptionalStartCursor: schema.ReadAt})
+	if err != nil {
+		return err
+	}
+
+	cc.reset(schema.ReadAt, inv)
+	for {
+		resp, err := stream.Recv()
+		if err != nil {
+			return err
+		}
+		cc.apply(resp)
+	}
+}
+
+// schemaChanged returns whether the watch ended because the schema changed.
+func schemaChanged(err error) bool {
+	s, ok := status.FromError(err)
+	if !ok || s.Code() != codes.FailedPrecondition {
+		return false
+	}
+
+	for _, detail := range s.Details() {
+		if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Reason == reasonWatchSchemaChanged {
+			return true
+		}
+	}
+	return false
+}
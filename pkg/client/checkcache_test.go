@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/authzed/spicedb/pkg/testserver"
+)
+
+const checkCacheSchema = `
+definition user {}
+
+definition group {
+	relation member: user | group#member
+}
+
+definition folder {
+	relation reader: user
+	permission read = reader
+}
+
+definition document {
+	relation parent: folder
+	relation viewer: user | group#member
+	permission view = viewer + parent->read
+}`
+
+func TestInvalidations(t *testing.T) {
+	inv, err := newInvalidations(checkCacheSchema)
+	require.NoError(t, err)
+
+	require.ElementsMatch(t, []string{"group#member", "document#viewer", "document#view"}, inv["group#member"])
+	require.ElementsMatch(t, []string{"folder#reader", "folder#read", "document#view"}, inv["folder#reader"])
+	require.ElementsMatch(t, []string{"document#parent", "document#view"}, inv["document#parent"])
+	require.ElementsMatch(t, []string{"document#view"}, inv["document#view"])
+
+	_, err = newInvalidations("definition document { relation viewer: missing }")
+	require.Error(t, err)
+}
+
+func cachedEntries(cache *CheckCache) int {
+	cache.Lock()
+	defer cache.Unlock()
+	return len(cache.entries)
+}
+
+func cacheStarted(cache *CheckCache) bool {
+	cache.Lock()
+	defer cache.Unlock()
+	return cache.revision != nil
+}
+
+func TestCheckCache(t *testing.T) {
+	server := testserver.NewTestServer(t,
+		testserver.WithSchema(checkCacheSchema),
+		testserver.WithRelationships("document:readme#viewer@user:alice", "folder:docs#reader@user:alice"),
+	)
+	client := &Client{ClientWithExperimental: *server.Client(), conns: []*grpc.ClientConn{server.Conn()}}
+
+	cache, err := client.NewCheckCache(context.Background())
+	require.NoError(t, err)
+	t.Cleanup(cache.Close)
+	require.Eventually(t, func() bool { return cacheStarted(cache) }, 5*time.Second, 10*time.Millisecond)
+
+	requireHasPermission := func(expected bool, resource, permission, subject string) {
+		t.Helper()
+		has, err := cache.HasPermission(context.Background(), resource, permission, subject)
+		require.NoError(t, err)
+		require.Equal(t, expected, has)
+	}
+
+	// Both positive and negative results are cached.
+	requireHasPermission(true, "document:readme", "view", "user:alice")
+	requireHasPermission(false, "document:readme", "view", "user:bob")
+	requireHasPermission(true, "folder:docs", "read", "user:alice")
+	require.Equal(t, 3, cachedEntries(cache))
+
+	// Checks which require fresher results are not cached.
+	req, err := CheckRequest("folder:docs", "read", "user:bob")
+	require.NoError(t, err)
+	req.Consistency = &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}}
+	_, err = cache.CheckPermission(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, 3, cachedEntries(cache))
+
+	// A change evicts the results of the permissions it can affect, and only those.
+	write, err := WriteRequest(v1.RelationshipUpdate_OPERATION_TOUCH, "group:eng#member@user:bob", "document:readme#viewer@group:eng#member")
+	require.NoError(t, err)
+	_, err = client.WriteRelationships(context.Background(), write)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return cachedEntries(cache) == 1 }, 5*time.Second, 10*time.Millisecond)
+	requireHasPermission(true, "document:readme", "view", "user:bob")
+
+	write, err = WriteRequest(v1.RelationshipUpdate_OPERATION_DELETE, "group:eng#member@user:bob")
+	require.NoError(t, err)
+	_, err = client.WriteRelationships(context.Background(), write)
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return cachedEntries(cache) == 1 }, 5*time.Second, 10*time.Millisecond)
+	requireHasPermission(false, "document:readme", "view", "user:bob")
+
+	// A schema change clears the cache, which is then restarted.
+	_, err = client.WriteSchema(context.Background(), &v1.WriteSchemaRequest{Schema: checkCacheSchema + "\n\ndefinition team {}"})
+	require.NoError(t, err)
+	require.Eventually(t, func() bool { return cachedEntries(cache) == 0 }, 5*time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool { return cacheStarted(cache) }, 5*time.Second, 10*time.Millisecond)
+	requireHasPermission(true, "folder:docs", "read", "user:alice")
+}
@@ -0,0 +1,291 @@
+// Package client connects Go programs to the API of SpiceDB. It dials the server with TLS
+// and preshared key or JWT credentials, retries requests which fail transiently, hedges slow
+// checks, fails over between endpoints, can make reads observe the writes made through the
+// client, and builds requests from the string forms of objects, subjects and relationships.
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	authzed "github.com/authzed/authzed-go/v1"
+	"github.com/authzed/grpcutil"
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/retry"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+const (
+	defaultMaxRetries   = 3
+	defaultRetryBackoff = 100 * time.Millisecond
+)
+
+// Client is a connection to the API of a server. It is safe for concurrent use.
+type Client struct {
+	authzed.ClientWithExperimental
+
+	conns  []*grpc.ClientConn
+	tokens *tokenTracker
+}
+
+// Option configures a Client.
+type Option func(*options)
+
+type options struct {
+	token               string
+	insecure            bool
+	caPath              string
+	skipVerifyCA        bool
+	maxRetries          uint
+	retryBackoff        time.Duration
+	methodRetryPolicies map[string]RetryPolicy
+	hedgingDelay        time.Duration
+	hedgingBudgetRatio  float64
+	readYourWrites      bool
+	dialOptions         []grpc.DialOption
+}
+
+// WithToken authenticates requests with the preshared key or JWT.
+func WithToken(token string) Option {
+	return func(o *options) { o.token = token }
+}
+
+// WithInsecure connects to the server without TLS.
+func WithInsecure() Option {
+	return func(o *options) { o.insecure = true }
+}
+
+// WithCACert verifies the certificate of the server against the CA certificate at path,
+// rather than against the system certificates.
+func WithCACert(path string) Option {
+	return func(o *options) { o.caPath = path }
+}
+
+// WithSkipVerifyCA does not verify the certificate of the server.
+func WithSkipVerifyCA() Option {
+	return func(o *options) { o.skipVerifyCA = true }
+}
+
+// WithRetries retries requests which fail because the server is unavailable or overloaded
+// up to maxRetries times, waiting an exponentially increasing, jittered delay starting at
+// backoff between attempts. Writes creating relationships, which may have been applied when
+// the server became unavailable, are only retried when the server was overloaded. Streams are
+// only retried before their first response, and only if the client sends a single request on
+// them. Zero retries disables retrying. By default, requests are retried 3 times, starting at
+// 100ms.
+func WithRetries(maxRetries uint, backoff time.Duration) Option {
+	return func(o *options) {
+		o.maxRetries = maxRetries
+		o.retryBackoff = backoff
+	}
+}
+
+// WithMethodRetryPolicy retries the requests of the method, named as in `CheckPermission`,
+// with the policy rather than as configured WithRetries.
+func WithMethodRetryPolicy(method string, policy RetryPolicy) Option {
+	return func(o *options) {
+		if o.methodRetryPolicies == nil {
+			o.methodRetryPolicies = map[string]RetryPolicy{}
+		}
+		o.methodRetryPolicies[method] = policy
+	}
+}
+
+// WithCheckHedging sends a check again if it has not been answered after delay, and uses
+// whichever answer arrives first, so that a slow server or connection does not delay checks.
+// Hedged checks are limited to budgetRatio of the checks sent, beyond an initial burst, so
+// that hedging does not overload a server which is slow because it is overloaded.
+func WithCheckHedging(delay time.Duration, budgetRatio float64) Option {
+	return func(o *options) {
+		o.hedgingDelay = delay
+		o.hedgingBudgetRatio = budgetRatio
+	}
+}
+
+// WithReadYourWrites makes requests which would otherwise minimize latency, or which do not
+// specify a consistency, at least as fresh as the most recent write made through the client,
+// so that they observe it.
+func WithReadYourWrites() Option {
+	return func(o *options) { o.readYourWrites = true }
+}
+
+// WithDialOptions adds options with which the connection is dialed.
+func WithDialOptions(opts ...grpc.DialOption) Option {
+	return func(o *options) { o.dialOptions = append(o.dialOptions, opts...) }
+}
+
+// New connects to the server at the endpoint.
+func New(endpoint string, opts ...Option) (*Client, error) {
+	return NewWithFailover([]string{endpoint}, opts...)
+}
+
+// NewWithFailover connects to the servers at the endpoints, such as the regions of a cluster,
+// and sends requests to the first of them which is available. A request failing because its
+// server is unavailable, after any retries, is sent to the next endpoint, which then receives
+// the requests until it fails in turn or the first endpoint is tried again 30s later. Requests
+// sent to another endpoint than the first, which would otherwise minimize latency, are made at
+// least as fresh as the most recent write made through the client, so that they observe it
+// even if the endpoint lags behind. Writes creating relationships do not fail over, since they
+// may have been applied, and streams only fail over when they are opened.
+func NewWithFailover(endpoints []string, opts ...Option) (*Client, error) {
+	if len(endpoints) == 0 {
+		return nil, errors.New("at least one endpoint is required")
+	}
+
+	o := options{maxRetries: defaultMaxRetries, retryBackoff: defaultRetryBackoff}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	dialOptions, err := o.credentials()
+	if err != nil {
+		return nil, err
+	}
+
+	var tokens *tokenTracker
+	var unary []grpc.UnaryClientInterceptor
+	var stream []grpc.StreamClientInterceptor
+	if o.readYourWrites {
+		tokens = &tokenTracker{}
+		unary = append(unary, tokens.unaryInterceptor)
+		stream = append(stream, tokens.streamInterceptor)
+	}
+	if o.retries() {
+		unary = append(unary, o.retryPolicyUnaryInterceptor, retry.UnaryClientInterceptor())
+		stream = append(stream, serverStreamsOnly(o.retryPolicyStreamInterceptor), serverStreamsOnly(retry.StreamClientInterceptor()))
+	}
+	if o.hedgingDelay > 0 {
+		unary = append(unary, newHedger(o.hedgingDelay, o.hedgingBudgetRatio).unaryInterceptor)
+	}
+	dialOptions = append(dialOptions, grpc.WithChainUnaryInterceptor(unary...), grpc.WithChainStreamInterceptor(stream...))
+
+	conns := make([]*grpc.ClientConn, 0, len(endpoints))
+	for _, endpoint := range endpoints {
+		conn, err := grpc.Dial(endpoint, append(dialOptions, o.dialOptions...)...)
+		if err != nil {
+			closeAll(conns)
+			return nil, fmt.Errorf("failed to connect to server %s: %w", endpoint, err)
+		}
+		conns = append(conns, conn)
+	}
+
+	var cc grpc.ClientConnInterface = conns[0]
+	if len(conns) > 1 {
+		failoverTokens := tokens
+		if failoverTokens == nil {
+			failoverTokens = &tokenTracker{}
+		}
+		cc = newFailoverConn(conns, failoverTokens)
+	}
+
+	return &Client{
+		ClientWithExperimental: authzed.ClientWithExperimental{
+			Client: authzed.Client{
+				SchemaServiceClient:      v1.NewSchemaServiceClient(cc),
+				PermissionsServiceClient: v1.NewPermissionsServiceClient(cc),
+				WatchServiceClient:       v1.NewWatchServiceClient(cc),
+			},
+			ExperimentalServiceClient: v1.NewExperimentalServiceClient(cc),
+		},
+		conns:  conns,
+		tokens: tokens,
+	}, nil
+}
+
+func closeAll(conns []*grpc.ClientConn) error {
+	var errs []error
+	for _, conn := range conns {
+		errs = append(errs, conn.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// serverStreamsOnly applies the interceptor only to streams on which the client sends a single
+// request, since the messages of others cannot be replayed on retry.
+func serverStreamsOnly(interceptor grpc.StreamClientInterceptor) grpc.StreamClientInterceptor {
+	return func(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+		if desc.ClientStreams {
+			return streamer(ctx, desc, cc, method, opts...)
+		}
+		return interceptor(ctx, desc, cc, method, streamer, opts...)
+	}
+}
+
+func (o options) credentials() ([]grpc.DialOption, error) {
+	if o.insecure {
+		opts := []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())}
+		if o.token != "" {
+			opts = append(opts, grpcutil.WithInsecureBearerToken(o.token))
+		}
+		return opts, nil
+	}
+
+	verification := grpcutil.VerifyCA
+	if o.skipVerifyCA {
+		verification = grpcutil.SkipVerifyCA
+	}
+
+	var certsOpt grpc.DialOption
+	var err error
+	if o.caPath != "" {
+		certsOpt, err = grpcutil.WithCustomCerts(verification, o.caPath)
+	} else {
+		certsOpt, err = grpcutil.WithSystemCerts(verification)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load certificates: %w", err)
+	}
+
+	opts := []grpc.DialOption{certsOpt}
+	if o.token != "" {
+		opts = append(opts, grpcutil.WithBearerToken(o.token))
+	}
+	return opts, nil
+}
+
+// Close closes the connections to the servers.
+func (c *Client) Close() error {
+	return closeAll(c.conns)
+}
+
+// ZedToken returns the ZedToken of the most recent write made through the client, or nil if
+// the client was not configured WithReadYourWrites or has not written.
+func (c *Client) ZedToken() *v1.ZedToken {
+	if c.tokens == nil {
+		return nil
+	}
+	return c.tokens.get()
+}
+
+// HasPermission returns whether the subject has the permission on the resource, given as
+// `type:id` and `type:id` or `type:id#relation`. Permissions which depend on caveats
+// missing context are an error.
+func (c *Client) HasPermission(ctx context.Context, resource, permission, subject string) (bool, error) {
+	req, err := CheckRequest(resource, permission, subject)
+	if err != nil {
+		return false, err
+	}
+
+	resp, err := c.CheckPermission(ctx, req)
+	if err != nil {
+		return false, err
+	}
+	return hasPermission(resp)
+}
+
+// hasPermission returns whether the response to a check has the permission, or an error if it
+// is conditional on missing caveat context.
+func hasPermission(resp *v1.CheckPermissionResponse) (bool, error) {
+	switch resp.Permissionship {
+	case v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION:
+		return true, nil
+	case v1.CheckPermissionResponse_PERMISSIONSHIP_CONDITIONAL_PERMISSION:
+		return false, fmt.Errorf("permission is conditional on missing caveat context: %s", strings.Join(resp.PartialCaveatInfo.GetMissingRequiredContext(), ", "))
+	default:
+		return false, nil
+	}
+}
@@ -0,0 +1,327 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/proto"
+)
+
+// fakePermissionsServer records the requests it receives, fails the first of them with
+// Unavailable, and does not answer the first checks until they are cancelled.
+type fakePermissionsServer struct {
+	v1.UnimplementedPermissionsServiceServer
+
+	sync.Mutex
+	failures int
+	stalls   int
+	requests []proto.Message
+}
+
+func (s *fakePermissionsServer) receive(req proto.Message) error {
+	s.Lock()
+	defer s.Unlock()
+	s.requests = append(s.requests, req)
+	if s.failures > 0 {
+		s.failures--
+		return status.Error(codes.Unavailable, "try again")
+	}
+	return nil
+}
+
+func (s *fakePermissionsServer) stall() bool {
+	s.Lock()
+	defer s.Unlock()
+	if s.stalls > 0 {
+		s.stalls--
+		return true
+	}
+	return false
+}
+
+func (s *fakePermissionsServer) CheckPermission(ctx context.Context, req *v1.CheckPermissionRequest) (*v1.CheckPermissionResponse, error) {
+	if err := s.receive(req); err != nil {
+		return nil, err
+	}
+	if s.stall() {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return &v1.CheckPermissionResponse{Permissionship: v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION}, nil
+}
+
+func (s *fakePermissionsServer) WriteRelationships(_ context.Context, req *v1.WriteRelationshipsRequest) (*v1.WriteRelationshipsResponse, error) {
+	if err := s.receive(req); err != nil {
+		return nil, err
+	}
+	return &v1.WriteRelationshipsResponse{WrittenAt: &v1.ZedToken{Token: "written"}}, nil
+}
+
+func (s *fakePermissionsServer) LookupResources(req *v1.LookupResourcesRequest, stream v1.PermissionsService_LookupResourcesServer) error {
+	if err := s.receive(req); err != nil {
+		return err
+	}
+	return stream.Send(&v1.LookupResourcesResponse{ResourceObjectId: "readme"})
+}
+
+func (s *fakePermissionsServer) lastRequest() proto.Message {
+	s.Lock()
+	defer s.Unlock()
+	return s.requests[len(s.requests)-1]
+}
+
+func (s *fakePermissionsServer) requestCount() int {
+	s.Lock()
+	defer s.Unlock()
+	return len(s.requests)
+}
+
+func newTestClient(t *testing.T, server *fakePermissionsServer, opts ...Option) *Client {
+	return newFailoverTestClient(t, []*fakePermissionsServer{server}, opts...)
+}
+
+// newFailoverTestClient returns a client failing over between the servers, in order.
+func newFailoverTestClient(t *testing.T, servers []*fakePermissionsServer, opts ...Option) *Client {
+	listeners := map[string]*bufconn.Listener{}
+	endpoints := make([]string, 0, len(servers))
+	for i, server := range servers {
+		listener := bufconn.Listen(1024 * 1024)
+		grpcServer := grpc.NewServer()
+		v1.RegisterPermissionsServiceServer(grpcServer, server)
+		go func() { _ = grpcServer.Serve(listener) }()
+		t.Cleanup(grpcServer.Stop)
+
+		endpoint := fmt.Sprintf("bufnet%d", i)
+		listeners[endpoint] = listener
+		endpoints = append(endpoints, endpoint)
+	}
+
+	opts = append(opts, WithInsecure(), WithToken("somekey"), WithDialOptions(
+		grpc.WithContextDialer(func(ctx context.Context, endpoint string) (net.Conn, error) {
+			return listeners[endpoint].DialContext(ctx)
+		}),
+	))
+	client, err := NewWithFailover(endpoints, opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestRetries(t *testing.T) {
+	server := &fakePermissionsServer{failures: 2}
+	client := newTestClient(t, server, WithRetries(2, time.Millisecond))
+
+	allowed, err := client.HasPermission(context.Background(), "document:readme", "view", "user:alice")
+	require.NoError(t, err)
+	require.True(t, allowed)
+	require.Equal(t, 3, server.requestCount())
+
+	// Once the retries are exhausted, the error is returned.
+	server.failures = 3
+	_, err = client.HasPermission(context.Background(), "document:readme", "view", "user:alice")
+	require.Equal(t, codes.Unavailable, status.Code(err))
+
+	server = &fakePermissionsServer{failures: 1}
+	client = newTestClient(t, server, WithRetries(0, 0))
+	_, err = client.HasPermission(context.Background(), "document:readme", "view", "user:alice")
+	require.Equal(t, codes.Unavailable, status.Code(err))
+	require.Len(t, server.requests, 1)
+}
+
+func TestMethodRetryPolicy(t *testing.T) {
+	server := &fakePermissionsServer{failures: 2}
+	client := newTestClient(t, server, WithRetries(0, 0), WithMethodRetryPolicy("CheckPermission", RetryPolicy{MaxRetries: 2, Backoff: time.Millisecond}))
+
+	allowed, err := client.HasPermission(context.Background(), "document:readme", "view", "user:alice")
+	require.NoError(t, err)
+	require.True(t, allowed)
+	require.Equal(t, 3, server.requestCount())
+
+	// Other methods keep the default policy, which does not retry.
+	touch, err := WriteRequest(v1.RelationshipUpdate_OPERATION_TOUCH, "document:readme#viewer@user:alice")
+	require.NoError(t, err)
+	server.failures = 1
+	_, err = client.WriteRelationships(context.Background(), touch)
+	require.Equal(t, codes.Unavailable, status.Code(err))
+	require.Equal(t, 4, server.requestCount())
+}
+
+func TestRetriesOfWrites(t *testing.T) {
+	server := &fakePermissionsServer{failures: 1}
+	client := newTestClient(t, server, WithRetries(2, time.Millisecond))
+
+	// Touching relationships is idempotent, so it is retried.
+	touch, err := WriteRequest(v1.RelationshipUpdate_OPERATION_TOUCH, "document:readme#viewer@user:alice")
+	require.NoError(t, err)
+	_, err = client.WriteRelationships(context.Background(), touch)
+	require.NoError(t, err)
+	require.Equal(t, 2, server.requestCount())
+
+	// Creating them is not, since the write may have been applied before the server became
+	// unavailable.
+	create, err := WriteRequest(v1.RelationshipUpdate_OPERATION_CREATE, "document:readme#viewer@user:bob")
+	require.NoError(t, err)
+	server.failures = 1
+	_, err = client.WriteRelationships(context.Background(), create)
+	require.Equal(t, codes.Unavailable, status.Code(err))
+	require.Equal(t, 3, server.requestCount())
+}
+
+func TestCheckHedging(t *testing.T) {
+	server := &fakePermissionsServer{stalls: 1}
+	client := newTestClient(t, server, WithRetries(0, 0), WithCheckHedging(10*time.Millisecond, 0.1))
+
+	// The first check is never answered, so the hedged check answers it.
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	allowed, err := client.HasPermission(ctx, "document:readme", "view", "user:alice")
+	require.NoError(t, err)
+	require.True(t, allowed)
+	require.Equal(t, 2, server.requestCount())
+
+	// Checks answered before the delay are not hedged.
+	_, err = client.HasPermission(ctx, "document:readme", "view", "user:alice")
+	require.NoError(t, err)
+	require.Equal(t, 3, server.requestCount())
+}
+
+func TestHedgingBudget(t *testing.T) {
+	h := newHedger(time.Millisecond, 0.5)
+	for i := 0; i < hedgingBurst; i++ {
+		require.True(t, h.spend())
+	}
+	require.False(t, h.spend())
+
+	// Each check earns half of a hedged check.
+	h.earn()
+	require.False(t, h.spend())
+	h.earn()
+	require.True(t, h.spend())
+}
+
+func TestFailover(t *testing.T) {
+	servers := []*fakePermissionsServer{{}, {}}
+	client := newFailoverTestClient(t, servers, WithRetries(0, 0))
+
+	touch, err := WriteRequest(v1.RelationshipUpdate_OPERATION_TOUCH, "document:readme#viewer@user:alice")
+	require.NoError(t, err)
+	_, err = client.WriteRelationships(context.Background(), touch)
+	require.NoError(t, err)
+
+	// Once the first endpoint is unavailable, checks fail over to the second, at least as
+	// fresh as the write made through the first.
+	servers[0].failures = 1
+	allowed, err := client.HasPermission(context.Background(), "document:readme", "view", "user:alice")
+	require.NoError(t, err)
+	require.True(t, allowed)
+	require.Equal(t, 2, servers[0].requestCount())
+	require.Equal(t, "written", servers[1].lastRequest().(*v1.CheckPermissionRequest).Consistency.GetAtLeastAsFresh().GetToken())
+
+	// The following requests are sent to the second endpoint.
+	_, err = client.HasPermission(context.Background(), "document:readme", "view", "user:alice")
+	require.NoError(t, err)
+	require.Equal(t, 2, servers[0].requestCount())
+	require.Equal(t, 2, servers[1].requestCount())
+
+	// Writes creating relationships do not fail over.
+	create, err := WriteRequest(v1.RelationshipUpdate_OPERATION_CREATE, "document:readme#viewer@user:bob")
+	require.NoError(t, err)
+	servers[1].failures = 1
+	_, err = client.WriteRelationships(context.Background(), create)
+	require.Equal(t, codes.Unavailable, status.Code(err))
+	require.Equal(t, 2, servers[0].requestCount())
+}
+
+func TestFailback(t *testing.T) {
+	now := time.Now()
+	f := newFailoverConn(make([]*grpc.ClientConn, 3), &tokenTracker{})
+	f.now = func() time.Time { return now }
+	require.Equal(t, 0, f.preferred())
+
+	f.failed(0)
+	require.Equal(t, 1, f.preferred())
+	f.failed(1)
+	require.Equal(t, 2, f.preferred())
+
+	now = now.Add(failbackInterval)
+	require.Equal(t, 0, f.preferred())
+}
+
+func TestReadYourWrites(t *testing.T) {
+	server := &fakePermissionsServer{}
+	client := newTestClient(t, server, WithReadYourWrites())
+	require.Nil(t, client.ZedToken())
+
+	// Before any write, requests are sent as is.
+	_, err := client.HasPermission(context.Background(), "document:readme", "view", "user:alice")
+	require.NoError(t, err)
+	require.Nil(t, server.lastRequest().(*v1.CheckPermissionRequest).Consistency)
+
+	write, err := WriteRequest(v1.RelationshipUpdate_OPERATION_TOUCH, "document:readme#viewer@user:alice")
+	require.NoError(t, err)
+	_, err = client.WriteRelationships(context.Background(), write)
+	require.NoError(t, err)
+	require.Equal(t, "written", client.ZedToken().Token)
+
+	atLeastAsFresh := &v1.Consistency{Requirement: &v1.Consistency_AtLeastAsFresh{AtLeastAsFresh: &v1.ZedToken{Token: "written"}}}
+
+	check, err := CheckRequest("document:readme", "view", "user:alice")
+	require.NoError(t, err)
+	check.Consistency = &v1.Consistency{Requirement: &v1.Consistency_MinimizeLatency{MinimizeLatency: true}}
+	_, err = client.CheckPermission(context.Background(), check)
+	require.NoError(t, err)
+	require.True(t, proto.Equal(atLeastAsFresh, server.lastRequest().(*v1.CheckPermissionRequest).Consistency))
+
+	// The request of the caller is not modified.
+	require.True(t, check.Consistency.GetMinimizeLatency())
+
+	// Stricter consistencies are kept.
+	check.Consistency = &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}}
+	_, err = client.CheckPermission(context.Background(), check)
+	require.NoError(t, err)
+	require.True(t, server.lastRequest().(*v1.CheckPermissionRequest).Consistency.GetFullyConsistent())
+
+	lookup, err := LookupResourcesRequest("document", "view", "user:alice")
+	require.NoError(t, err)
+	stream, err := client.LookupResources(context.Background(), lookup)
+	require.NoError(t, err)
+	resp, err := stream.Recv()
+	require.NoError(t, err)
+	require.Equal(t, "readme", resp.ResourceObjectId)
+	require.True(t, proto.Equal(atLeastAsFresh, server.lastRequest().(*v1.LookupResourcesRequest).Consistency))
+}
+
+func TestRequests(t *testing.T) {
+	_, err := CheckRequest("document", "view", "user:alice")
+	require.ErrorContains(t, err, "invalid object `document`")
+
+	_, err = CheckRequest("document:readme", "view", "user")
+	require.ErrorContains(t, err, "invalid subject `user`")
+
+	lookup, err := LookupSubjectsRequest("document:readme", "view", "group#member")
+	require.NoError(t, err)
+	require.Equal(t, "group", lookup.SubjectObjectType)
+	require.Equal(t, "member", lookup.OptionalSubjectRelation)
+
+	_, err = LookupSubjectsRequest("document:readme", "view", "")
+	require.ErrorContains(t, err, "invalid subject type")
+
+	write, err := WriteRequest(v1.RelationshipUpdate_OPERATION_DELETE, "document:readme#viewer@group:eng#member", "document:readme#owner@user:alice")
+	require.NoError(t, err)
+	require.Len(t, write.Updates, 2)
+	require.Equal(t, "member", write.Updates[0].Relationship.Subject.OptionalRelation)
+	require.Equal(t, v1.RelationshipUpdate_OPERATION_DELETE, write.Updates[1].Operation)
+
+	_, err = WriteRequest(v1.RelationshipUpdate_OPERATION_TOUCH, "document:readme#viewer")
+	require.ErrorContains(t, err, "invalid relationship `document:readme#viewer`")
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// failbackInterval is how long after failing over the first endpoint is tried again.
+const failbackInterval = 30 * time.Second
+
+// failoverConn sends requests to the first available of several connections.
+type failoverConn struct {
+	conns  []*grpc.ClientConn
+	tokens *tokenTracker
+	now    func() time.Time
+
+	sync.Mutex
+	current      int
+	failedOverAt time.Time
+}
+
+var _ grpc.ClientConnInterface = (*failoverConn)(nil)
+
+func newFailoverConn(conns []*grpc.ClientConn, tokens *tokenTracker) *failoverConn {
+	return &failoverConn{conns: conns, tokens: tokens, now: time.Now}
+}
+
+// preferred returns the index of the connection to which requests are sent first.
+func (f *failoverConn) preferred() int {
+	f.Lock()
+	defer f.Unlock()
+	if f.current != 0 && f.now().Sub(f.failedOverAt) >= failbackInterval {
+		return 0
+	}
+	return f.current
+}
+
+// failed records that the connection is unavailable, so that requests are sent to the next.
+func (f *failoverConn) failed(index int) {
+	f.Lock()
+	defer f.Unlock()
+	f.current = (index + 1) % len(f.conns)
+	f.failedOverAt = f.now()
+}
+
+// succeeded records that the connection is available.
+func (f *failoverConn) succeeded(index int) {
+	f.Lock()
+	defer f.Unlock()
+	f.current = index
+}
+
+// request returns the request sent to the connection, at least as fresh as the most recent
+// write if it is not the first.
+func (f *failoverConn) request(index int, req any) any {
+	if index == 0 {
+		return req
+	}
+	return f.tokens.withConsistency(req)
+}
+
+func (f *failoverConn) Invoke(ctx context.Context, method string, args, reply any, opts ...grpc.CallOption) error {
+	first := f.preferred()
+
+	var err error
+	for i := range f.conns {
+		index := (first + i) % len(f.conns)
+		err = f.conns[index].Invoke(ctx, method, f.request(index, args), reply, opts...)
+		if err == nil {
+			f.tokens.record(reply)
+			f.succeeded(index)
+			return nil
+		}
+		if status.Code(err) != codes.Unavailable || ctx.Err() != nil {
+			return err
+		}
+
+		f.failed(index)
+		if !idempotent(args) {
+			return err
+		}
+	}
+	return err
+}
+
+func (f *failoverConn) NewStream(ctx context.Context, desc *grpc.StreamDesc, method string, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	first := f.preferred()
+
+	var err error
+	for i := range f.conns {
+		index := (first + i) % len(f.conns)
+
+		var stream grpc.ClientStream
+		stream, err = f.conns[index].NewStream(ctx, desc, method, opts...)
+		if err == nil {
+			if index == 0 {
+				return stream, nil
+			}
+			return &trackedStream{stream, f.tokens}, nil
+		}
+		if status.Code(err) != codes.Unavailable || ctx.Err() != nil {
+			return nil, err
+		}
+		f.failed(index)
+	}
+	return nil, err
+}
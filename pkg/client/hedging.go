@@ -0,0 +1,102 @@
+package client
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// hedgingBurst is the number of hedged checks allowed before the budget is earned by checks.
+const hedgingBurst = 10
+
+// hedgedMethods are the methods whose requests are hedged.
+var hedgedMethods = map[string]struct{}{
+	v1.PermissionsService_CheckPermission_FullMethodName:      {},
+	v1.ExperimentalService_BulkCheckPermission_FullMethodName: {},
+}
+
+// hedger sends a check again when it is slow to be answered, within a budget of hedged
+// checks earned by each check sent.
+type hedger struct {
+	delay time.Duration
+	ratio float64
+
+	sync.Mutex
+	budget float64
+}
+
+func newHedger(delay time.Duration, ratio float64) *hedger {
+	return &hedger{delay: delay, ratio: ratio, budget: hedgingBurst}
+}
+
+// earn adds the share of a hedged check earned by sending a check.
+func (h *hedger) earn() {
+	h.Lock()
+	defer h.Unlock()
+	h.budget = min(h.budget+h.ratio, hedgingBurst)
+}
+
+// spend returns whether a hedged check can be sent, taking it from the budget if so.
+func (h *hedger) spend() bool {
+	h.Lock()
+	defer h.Unlock()
+	if h.budget < 1 {
+		return false
+	}
+	h.budget--
+	return true
+}
+
+type hedgedResult struct {
+	reply proto.Message
+	err   error
+}
+
+func (h *hedger) unaryInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	replyMsg, ok := reply.(proto.Message)
+	if _, hedged := hedgedMethods[method]; !hedged || !ok {
+		return invoker(ctx, method, req, reply, cc, opts...)
+	}
+	h.earn()
+
+	// The slower of the checks is cancelled once the other is answered.
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan hedgedResult, 2)
+	send := func() {
+		attemptReply := replyMsg.ProtoReflect().New().Interface()
+		err := invoker(ctx, method, req, attemptReply, cc, opts...)
+		results <- hedgedResult{attemptReply, err}
+	}
+
+	go send()
+	pending := 1
+
+	timer := time.NewTimer(h.delay)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-timer.C:
+			if h.spend() {
+				go send()
+				pending++
+			}
+
+		case result := <-results:
+			pending--
+			if result.err != nil && pending > 0 {
+				continue
+			}
+			if result.err == nil {
+				proto.Merge(replyMsg, result.reply)
+			}
+			return result.err
+		}
+	}
+}
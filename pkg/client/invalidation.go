@@ -0,0 +1,111 @@
+package client
+
+import (
+	"fmt"
+
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// invalidations maps each relation of a schema, as `type#relation`, to the relations and
+// permissions whose results can be changed by writing a relationship to it, including itself.
+type invalidations map[string][]string
+
+// newInvalidations computes the invalidations of the schema.
+func newInvalidations(schemaText string) (invalidations, error) {
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source("schema"),
+		SchemaString: schemaText,
+	}, compiler.AllowUnprefixedObjectType())
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile schema: %w", err)
+	}
+
+	relations := make(map[string]*core.Relation)
+	for _, def := range compiled.ObjectDefinitions {
+		for _, rel := range def.Relation {
+			relations[tuple.JoinRelRef(def.Name, rel.Name)] = rel
+		}
+	}
+
+	// dependents maps each relation to the relations and permissions which directly depend on it.
+	dependents := make(map[string][]string)
+	for _, def := range compiled.ObjectDefinitions {
+		for _, rel := range def.Relation {
+			key := tuple.JoinRelRef(def.Name, rel.Name)
+			for _, dependency := range dependencies(def.Name, rel, relations) {
+				dependents[dependency] = append(dependents[dependency], key)
+			}
+		}
+	}
+
+	inv := make(invalidations, len(relations))
+	for key := range relations {
+		seen := map[string]struct{}{key: {}}
+		affected := []string{key}
+		for i := 0; i < len(affected); i++ {
+			for _, dependent := range dependents[affected[i]] {
+				if _, ok := seen[dependent]; !ok {
+					seen[dependent] = struct{}{}
+					affected = append(affected, dependent)
+				}
+			}
+		}
+		inv[key] = affected
+	}
+	return inv, nil
+}
+
+// dependencies returns the relations and permissions which the relation or permission reads,
+// as `type#relation`.
+func dependencies(namespace string, rel *core.Relation, relations map[string]*core.Relation) []string {
+	if rel.UsersetRewrite == nil {
+		return directDependencies(rel)
+	}
+	return rewriteDependencies(namespace, rel, rel.UsersetRewrite, relations)
+}
+
+// directDependencies returns the subject relations allowed on a relation, since the members of
+// the relation include theirs.
+func directDependencies(rel *core.Relation) []string {
+	var deps []string
+	for _, allowed := range rel.GetTypeInformation().GetAllowedDirectRelations() {
+		if subjectRelation := allowed.GetRelation(); subjectRelation != "" && subjectRelation != tuple.Ellipsis {
+			deps = append(deps, tuple.JoinRelRef(allowed.Namespace, subjectRelation))
+		}
+	}
+	return deps
+}
+
+func rewriteDependencies(namespace string, rel *core.Relation, rewrite *core.UsersetRewrite, relations map[string]*core.Relation) []string {
+	var operation *core.SetOperation
+	switch rewrite := rewrite.RewriteOperation.(type) {
+	case *core.UsersetRewrite_Union:
+		operation = rewrite.Union
+	case *core.UsersetRewrite_Intersection:
+		operation = rewrite.Intersection
+	case *core.UsersetRewrite_Exclusion:
+		operation = rewrite.Exclusion
+	}
+
+	var deps []string
+	for _, child := range operation.GetChild() {
+		switch child := child.ChildType.(type) {
+		case *core.SetOperation_Child_XThis:
+			deps = append(deps, directDependencies(rel)...)
+		case *core.SetOperation_Child_ComputedUserset:
+			deps = append(deps, tuple.JoinRelRef(namespace, child.ComputedUserset.Relation))
+		case *core.SetOperation_Child_TupleToUserset:
+			tupleset := tuple.JoinRelRef(namespace, child.TupleToUserset.Tupleset.Relation)
+			deps = append(deps, tupleset)
+			for _, allowed := range relations[tupleset].GetTypeInformation().GetAllowedDirectRelations() {
+				deps = append(deps, tuple.JoinRelRef(allowed.Namespace, child.TupleToUserset.ComputedUserset.Relation))
+			}
+		case *core.SetOperation_Child_UsersetRewrite:
+			deps = append(deps, rewriteDependencies(namespace, rel, child.UsersetRewrite, relations)...)
+		}
+	}
+	return deps
+}
@@ -0,0 +1,115 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// ParseObject parses an object of the form `type:id`.
+func ParseObject(ref string) (*v1.ObjectReference, error) {
+	objectType, objectID, ok := strings.Cut(ref, ":")
+	if !ok || objectType == "" || objectID == "" {
+		return nil, fmt.Errorf("invalid object `%s`: expected `type:id`", ref)
+	}
+	return &v1.ObjectReference{ObjectType: objectType, ObjectId: objectID}, nil
+}
+
+// ParseSubject parses a subject of the form `type:id` or `type:id#relation`.
+func ParseSubject(ref string) (*v1.SubjectReference, error) {
+	onr := tuple.ParseSubjectONR(ref)
+	if onr == nil {
+		return nil, fmt.Errorf("invalid subject `%s`: expected `type:id` or `type:id#relation`", ref)
+	}
+
+	subject := &v1.SubjectReference{
+		Object: &v1.ObjectReference{ObjectType: onr.Namespace, ObjectId: onr.ObjectId},
+	}
+	if onr.Relation != tuple.Ellipsis {
+		subject.OptionalRelation = onr.Relation
+	}
+	return subject, nil
+}
+
+// ParseRelationship parses a relationship of the form `type:id#relation@type:id`, optionally
+// with a subject relation and a caveat, as in
+// `document:readme#viewer@group:eng#member[only_weekdays:{"tz":"UTC"}]`.
+func ParseRelationship(rel string) (*v1.Relationship, error) {
+	parsed := tuple.ParseRel(rel)
+	if parsed == nil {
+		return nil, fmt.Errorf("invalid relationship `%s`: expected `type:id#relation@type:id`", rel)
+	}
+	return parsed, nil
+}
+
+// CheckRequest returns a request checking whether the subject has the permission on the
+// resource.
+func CheckRequest(resource, permission, subject string) (*v1.CheckPermissionRequest, error) {
+	resourceRef, err := ParseObject(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	subjectRef, err := ParseSubject(subject)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.CheckPermissionRequest{
+		Resource:   resourceRef,
+		Permission: permission,
+		Subject:    subjectRef,
+	}, nil
+}
+
+// WriteRequest returns a request applying the operation to each of the relationships.
+func WriteRequest(operation v1.RelationshipUpdate_Operation, rels ...string) (*v1.WriteRelationshipsRequest, error) {
+	req := &v1.WriteRelationshipsRequest{Updates: make([]*v1.RelationshipUpdate, 0, len(rels))}
+	for _, rel := range rels {
+		parsed, err := ParseRelationship(rel)
+		if err != nil {
+			return nil, err
+		}
+		req.Updates = append(req.Updates, &v1.RelationshipUpdate{Operation: operation, Relationship: parsed})
+	}
+	return req, nil
+}
+
+// LookupResourcesRequest returns a request looking up the resources of the type on which the
+// subject has the permission.
+func LookupResourcesRequest(resourceType, permission, subject string) (*v1.LookupResourcesRequest, error) {
+	subjectRef, err := ParseSubject(subject)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v1.LookupResourcesRequest{
+		ResourceObjectType: resourceType,
+		Permission:         permission,
+		Subject:            subjectRef,
+	}, nil
+}
+
+// LookupSubjectsRequest returns a request looking up the subjects of the type, optionally
+// of the form `type#relation`, which have the permission on the resource.
+func LookupSubjectsRequest(resource, permission, subjectType string) (*v1.LookupSubjectsRequest, error) {
+	resourceRef, err := ParseObject(resource)
+	if err != nil {
+		return nil, err
+	}
+
+	objectType, relation, _ := strings.Cut(subjectType, "#")
+	if objectType == "" {
+		return nil, fmt.Errorf("invalid subject type `%s`: expected `type` or `type#relation`", subjectType)
+	}
+
+	return &v1.LookupSubjectsRequest{
+		Resource:                resourceRef,
+		Permission:              permission,
+		SubjectObjectType:       objectType,
+		OptionalSubjectRelation: relation,
+	}, nil
+}
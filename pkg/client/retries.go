@@ -0,0 +1,83 @@
+package client
+
+import (
+	"context"
+	"path"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/retry"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// RetryPolicy configures how the requests of a method are retried.
+type RetryPolicy struct {
+	// MaxRetries is the number of times a request is retried. Zero disables retrying.
+	MaxRetries uint
+
+	// Backoff is the delay before the first retry, which increases exponentially, with
+	// jitter, for each of the following retries.
+	Backoff time.Duration
+}
+
+// retries returns whether any request is retried.
+func (o options) retries() bool {
+	if o.maxRetries > 0 {
+		return true
+	}
+	for _, policy := range o.methodRetryPolicies {
+		if policy.MaxRetries > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// retryCallOptions returns the options with which the retry interceptor retries the request
+// to the method.
+func (o options) retryCallOptions(method string, req any) []grpc.CallOption {
+	policy, ok := o.methodRetryPolicies[path.Base(method)]
+	if !ok {
+		policy = RetryPolicy{MaxRetries: o.maxRetries, Backoff: o.retryBackoff}
+	}
+	if policy.MaxRetries == 0 {
+		return []grpc.CallOption{retry.Disable()}
+	}
+
+	// An overloaded server rejects requests before applying them, while an unavailable one
+	// may have applied them before the connection was lost.
+	retryCodes := []codes.Code{codes.Unavailable, codes.ResourceExhausted}
+	if !idempotent(req) {
+		retryCodes = []codes.Code{codes.ResourceExhausted}
+	}
+
+	return []grpc.CallOption{
+		retry.WithMax(policy.MaxRetries + 1),
+		retry.WithBackoff(retry.BackoffExponentialWithJitter(policy.Backoff, 0.1)),
+		retry.WithCodes(retryCodes...),
+	}
+}
+
+func (o options) retryPolicyUnaryInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	return invoker(ctx, method, req, reply, cc, append(o.retryCallOptions(method, req), opts...)...)
+}
+
+func (o options) retryPolicyStreamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	return streamer(ctx, desc, cc, method, append(o.retryCallOptions(method, nil), opts...)...)
+}
+
+// idempotent returns whether the request has the same effect when applied again. All requests
+// are, except writes creating relationships, which fail if the relationships already exist.
+func idempotent(req any) bool {
+	write, ok := req.(*v1.WriteRelationshipsRequest)
+	if !ok {
+		return true
+	}
+	for _, update := range write.Updates {
+		if update.Operation == v1.RelationshipUpdate_OPERATION_CREATE {
+			return false
+		}
+	}
+	return true
+}
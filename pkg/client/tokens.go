@@ -0,0 +1,121 @@
+package client
+
+import (
+	"context"
+	"sync"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// tokenTracker records the ZedToken of the most recent write made through a client, and
+// makes requests at least as fresh as it.
+type tokenTracker struct {
+	sync.RWMutex
+	token *v1.ZedToken
+}
+
+func (t *tokenTracker) get() *v1.ZedToken {
+	t.RLock()
+	defer t.RUnlock()
+	return t.token
+}
+
+func (t *tokenTracker) set(token *v1.ZedToken) {
+	t.Lock()
+	defer t.Unlock()
+	t.token = token
+}
+
+// written is implemented by the responses of the requests which write relationships or
+// schema.
+type written interface {
+	GetWrittenAt() *v1.ZedToken
+}
+
+// deleted is implemented by the response to DeleteRelationships.
+type deleted interface {
+	GetDeletedAt() *v1.ZedToken
+}
+
+// record remembers the ZedToken in the response, if it is the response to a write.
+func (t *tokenTracker) record(resp any) {
+	var token *v1.ZedToken
+	switch resp := resp.(type) {
+	case written:
+		token = resp.GetWrittenAt()
+	case deleted:
+		token = resp.GetDeletedAt()
+	}
+	if token != nil {
+		t.set(token)
+	}
+}
+
+// withConsistency returns the request with its consistency, if it has one which is unset or
+// minimizes latency, replaced by at least as fresh as the most recent write. Otherwise, the
+// request is returned as is. The request of the caller is not modified.
+func (t *tokenTracker) withConsistency(req any) any {
+	token := t.get()
+	if token == nil {
+		return req
+	}
+
+	msg, ok := req.(proto.Message)
+	if !ok {
+		return req
+	}
+
+	field := msg.ProtoReflect().Descriptor().Fields().ByName("consistency")
+	if field == nil || field.Message() == nil || field.Message().FullName() != "authzed.api.v1.Consistency" {
+		return req
+	}
+
+	current, _ := msg.ProtoReflect().Get(field).Message().Interface().(*v1.Consistency)
+	if current.GetRequirement() != nil && !current.GetMinimizeLatency() {
+		return req
+	}
+
+	cloned := proto.Clone(msg)
+	cloned.ProtoReflect().Set(field, protoreflect.ValueOfMessage((&v1.Consistency{
+		Requirement: &v1.Consistency_AtLeastAsFresh{AtLeastAsFresh: token},
+	}).ProtoReflect()))
+	return cloned
+}
+
+func (t *tokenTracker) unaryInterceptor(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	if err := invoker(ctx, method, t.withConsistency(req), reply, cc, opts...); err != nil {
+		return err
+	}
+	t.record(reply)
+	return nil
+}
+
+func (t *tokenTracker) streamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+	stream, err := streamer(ctx, desc, cc, method, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return &trackedStream{stream, t}, nil
+}
+
+// trackedStream applies the consistency of the tracker to the requests sent on a stream, and
+// records the writes in its responses.
+type trackedStream struct {
+	grpc.ClientStream
+	tokens *tokenTracker
+}
+
+func (s *trackedStream) SendMsg(m any) error {
+	return s.ClientStream.SendMsg(s.tokens.withConsistency(m))
+}
+
+func (s *trackedStream) RecvMsg(m any) error {
+	if err := s.ClientStream.RecvMsg(m); err != nil {
+		return err
+	}
+	s.tokens.record(m)
+	return nil
+}
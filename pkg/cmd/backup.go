@@ -0,0 +1,200 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/jzelinskie/cobrautil/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/authzed/spicedb/internal/backup"
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/cmd/datastore"
+	"github.com/authzed/spicedb/pkg/cmd/server"
+	"github.com/authzed/spicedb/pkg/cmd/termination"
+	dspkg "github.com/authzed/spicedb/pkg/datastore"
+)
+
+func RegisterBackupFlags(cmd *cobra.Command, cfg *datastore.Config) error {
+	cmd.Flags().Bool("compress", true, "compress the backup with gzip")
+	cmd.Flags().String("encryption-key-file", "", "file holding the key with which the backup is encrypted; if empty, the backup is not encrypted")
+	return datastore.RegisterDatastoreFlags(cmd, cfg)
+}
+
+func NewBackupCommand(programName string, cfg *datastore.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:     "backup <file>",
+		Short:   "backs up the schema and relationships of the datastore",
+		Long:    "Writes a consistent export of the schema and relationships of the datastore to a file, to stdout if the file is `-`, or to an object in storage if the file is an `s3://`, `gs://` or `file://` URL, which can be restored into any datastore engine.",
+		PreRunE: server.DefaultPreRunE(programName),
+		Args:    cobra.ExactArgs(1),
+		RunE: termination.PublishError(func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			opts, err := backupOptions(cmd)
+			if err != nil {
+				return err
+			}
+			opts.Compress = cobrautil.MustGetBool(cmd, "compress")
+
+			ds, err := newBackupDatastore(ctx, cfg)
+			if err != nil {
+				return err
+			}
+			defer ds.Close()
+
+			if backup.IsBucketURL(args[0]) {
+				bucketURL, name, err := backup.SplitObjectURL(args[0])
+				if err != nil {
+					return err
+				}
+
+				bucket, err := backup.OpenBucket(ctx, bucketURL)
+				if err != nil {
+					return err
+				}
+				defer bucket.Close()
+
+				checksum, stats, err := backup.Upload(ctx, ds, bucket, name, opts)
+				if err != nil {
+					return err
+				}
+				if err := backup.UploadChecksum(ctx, bucket, name, checksum); err != nil {
+					return err
+				}
+				logBackup(ctx, stats)
+				return nil
+			}
+
+			if args[0] == "-" {
+				stats, err := backup.Write(ctx, ds, cmd.OutOrStdout(), opts)
+				if err != nil {
+					return err
+				}
+				logBackup(ctx, stats)
+				return nil
+			}
+
+			file, err := os.OpenFile(args[0], os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+			if err != nil {
+				return fmt.Errorf("failed to create backup file: %w", err)
+			}
+
+			stats, err := backup.Write(ctx, ds, file, opts)
+			if cerr := file.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				// Partial backups are removed, so that they are not mistaken for complete ones.
+				_ = os.Remove(args[0])
+				return err
+			}
+			logBackup(ctx, stats)
+			return nil
+		}),
+	}
+}
+
+func logBackup(ctx context.Context, stats backup.Stats) {
+	log.Ctx(ctx).Info().
+		Str("revision", stats.Revision).
+		Uint64("relationships", stats.Relationships).
+		Msg("backup completed")
+}
+
+func RegisterRestoreFlags(cmd *cobra.Command, cfg *datastore.Config) error {
+	cmd.Flags().String("encryption-key-file", "", "file holding the key with which the backup was encrypted")
+	return datastore.RegisterDatastoreFlags(cmd, cfg)
+}
+
+func NewRestoreCommand(programName string, cfg *datastore.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:     "restore <file>",
+		Short:   "restores a backup into the datastore",
+		Long:    "Writes the schema and relationships of a backup, read from a file, from stdin if the file is `-`, or from an object in storage if the file is an `s3://`, `gs://` or `file://` URL, into a datastore without a schema. Backups in storage are verified against their checksum before being restored.",
+		PreRunE: server.DefaultPreRunE(programName),
+		Args:    cobra.ExactArgs(1),
+		RunE: termination.PublishError(func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			opts, err := backupOptions(cmd)
+			if err != nil {
+				return err
+			}
+
+			ds, err := newBackupDatastore(ctx, cfg)
+			if err != nil {
+				return err
+			}
+			defer ds.Close()
+
+			var in io.Reader = cmd.InOrStdin()
+			if backup.IsBucketURL(args[0]) {
+				bucketURL, name, err := backup.SplitObjectURL(args[0])
+				if err != nil {
+					return err
+				}
+
+				bucket, err := backup.OpenBucket(ctx, bucketURL)
+				if err != nil {
+					return err
+				}
+				defer bucket.Close()
+
+				file, err := backup.Download(ctx, bucket, name)
+				if err != nil {
+					return err
+				}
+				defer os.Remove(file.Name())
+				defer file.Close()
+				in = file
+			} else if args[0] != "-" {
+				file, err := os.Open(args[0])
+				if err != nil {
+					return fmt.Errorf("failed to open backup file: %w", err)
+				}
+				defer file.Close()
+				in = file
+			}
+
+			stats, err := backup.Restore(ctx, ds, in, opts)
+			if err != nil {
+				return err
+			}
+
+			log.Ctx(ctx).Info().
+				Str("backupRevision", stats.Revision).
+				Uint64("relationships", stats.Relationships).
+				Msg("restore completed")
+			return nil
+		}),
+	}
+}
+
+func backupOptions(cmd *cobra.Command) (backup.Options, error) {
+	keyFile := cobrautil.MustGetString(cmd, "encryption-key-file")
+	if keyFile == "" {
+		return backup.Options{}, nil
+	}
+
+	key, err := backup.ReadEncryptionKeyFile(keyFile)
+	if err != nil {
+		return backup.Options{}, err
+	}
+	return backup.Options{EncryptionKey: key}, nil
+}
+
+func newBackupDatastore(ctx context.Context, cfg *datastore.Config) (dspkg.Datastore, error) {
+	// Disable background GC and hedging.
+	cfg.GCInterval = -1 * time.Hour
+	cfg.RequestHedgingEnabled = false
+
+	ds, err := datastore.NewDatastore(ctx, cfg.ToOption())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create datastore: %w", err)
+	}
+	return ds, nil
+}
@@ -0,0 +1,288 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/jzelinskie/cobrautil/v2"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	_ "github.com/authzed/spicedb/internal/xds" // resolves xds:/// endpoints
+	"github.com/authzed/spicedb/pkg/client"
+	"github.com/authzed/spicedb/pkg/cmd/server"
+	"github.com/authzed/spicedb/pkg/cmd/termination"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// RegisterClientFlags adds the flags used to connect to a running server, and to format its
+// responses, to a command.
+func RegisterClientFlags(cmd *cobra.Command) {
+	registerConnectionFlags(cmd)
+	cmd.Flags().Bool("fully-consistent", false, "evaluate the request at the newest revision of the datastore, rather than minimizing latency")
+	cmd.Flags().Bool("json", false, "print the response as JSON")
+}
+
+// registerConnectionFlags adds the flags used by newClient to connect to a running server.
+func registerConnectionFlags(cmd *cobra.Command) {
+	cmd.Flags().String("endpoint", "localhost:50051", "address of the gRPC API of the server; `xds:///` addresses are resolved through the xDS control plane named by GRPC_XDS_BOOTSTRAP")
+	cmd.Flags().String("token", "", "preshared key with which to authenticate to the server")
+	cmd.Flags().Bool("insecure", false, "connect to the server without TLS")
+	cmd.Flags().String("ca-path", "", "path to the CA certificate with which to verify the server; if empty, the system certificates are used")
+	cmd.Flags().Bool("no-verify-ca", false, "do not verify the certificate of the server")
+}
+
+func RegisterCheckFlags(cmd *cobra.Command) {
+	RegisterClientFlags(cmd)
+	cmd.Flags().String("caveat-context", "", "JSON object of the context with which caveats are evaluated")
+}
+
+func NewCheckCommand(programName string) *cobra.Command {
+	return &cobra.Command{
+		Use:     "check <resource-type:resource-id> <permission> <subject-type:subject-id[#relation]>",
+		Short:   "checks a permission against a running server",
+		Long:    "Connects to a running server and checks whether the subject has the permission on the resource.",
+		PreRunE: server.DefaultPreRunE(programName),
+		Args:    cobra.ExactArgs(3),
+		RunE:    termination.PublishError(checkRun),
+	}
+}
+
+func checkRun(cmd *cobra.Command, args []string) error {
+	resource, err := client.ParseObject(args[0])
+	if err != nil {
+		return err
+	}
+
+	subject, err := client.ParseSubject(args[2])
+	if err != nil {
+		return err
+	}
+
+	var caveatContext *structpb.Struct
+	if contextJSON := cobrautil.MustGetString(cmd, "caveat-context"); contextJSON != "" {
+		var fields map[string]any
+		if err := json.Unmarshal([]byte(contextJSON), &fields); err != nil {
+			return fmt.Errorf("invalid caveat context: %w", err)
+		}
+
+		caveatContext, err = structpb.NewStruct(fields)
+		if err != nil {
+			return fmt.Errorf("invalid caveat context: %w", err)
+		}
+	}
+
+	client, err := newClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.CheckPermission(cmd.Context(), &v1.CheckPermissionRequest{
+		Consistency: consistency(cmd),
+		Resource:    resource,
+		Permission:  args[1],
+		Subject:     subject,
+		Context:     caveatContext,
+	})
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if cobrautil.MustGetBool(cmd, "json") {
+		return printJSON(out, resp)
+	}
+
+	switch resp.Permissionship {
+	case v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION:
+		fmt.Fprintln(out, "true")
+	case v1.CheckPermissionResponse_PERMISSIONSHIP_CONDITIONAL_PERMISSION:
+		fmt.Fprintf(out, "caveated: missing context %s\n", strings.Join(resp.PartialCaveatInfo.GetMissingRequiredContext(), ", "))
+	default:
+		fmt.Fprintln(out, "false")
+	}
+	return nil
+}
+
+func NewReadCommand(programName string) *cobra.Command {
+	return &cobra.Command{
+		Use:     "read <resource-type[:resource-id]> [relation] [subject-type[:subject-id[#relation]]]",
+		Short:   "reads relationships from a running server",
+		Long:    "Connects to a running server and prints the relationships matching the filter, one per line.",
+		PreRunE: server.DefaultPreRunE(programName),
+		Args:    cobra.RangeArgs(1, 3),
+		RunE:    termination.PublishError(readRun),
+	}
+}
+
+func readRun(cmd *cobra.Command, args []string) error {
+	filter := relationshipFilterFromArgs(args)
+	client, err := newClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	stream, err := client.ReadRelationships(cmd.Context(), &v1.ReadRelationshipsRequest{
+		Consistency:        consistency(cmd),
+		RelationshipFilter: filter,
+	})
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	printAsJSON := cobrautil.MustGetBool(cmd, "json")
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if printAsJSON {
+			// Each relationship is printed as a single line of JSON.
+			line, err := protojson.Marshal(resp)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(out, string(line))
+			continue
+		}
+
+		line, err := tuple.StringRelationship(resp.Relationship)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(out, line)
+	}
+}
+
+// relationshipFilterFromArgs builds a filter from the
+// `<resource-type[:resource-id]> [relation] [subject-type[:subject-id[#relation]]]` arguments.
+func relationshipFilterFromArgs(args []string) *v1.RelationshipFilter {
+	resourceType, resourceID, _ := strings.Cut(args[0], ":")
+	filter := &v1.RelationshipFilter{
+		ResourceType:       resourceType,
+		OptionalResourceId: resourceID,
+	}
+
+	if len(args) > 1 {
+		filter.OptionalRelation = args[1]
+	}
+
+	if len(args) > 2 {
+		subjectType, subjectIDAndRelation, _ := strings.Cut(args[2], ":")
+		subjectID, subjectRelation, hasRelation := strings.Cut(subjectIDAndRelation, "#")
+		filter.OptionalSubjectFilter = &v1.SubjectFilter{
+			SubjectType:       subjectType,
+			OptionalSubjectId: subjectID,
+		}
+		if hasRelation {
+			filter.OptionalSubjectFilter.OptionalRelation = &v1.SubjectFilter_RelationFilter{Relation: subjectRelation}
+		}
+	}
+	return filter
+}
+
+func NewExpandCommand(programName string) *cobra.Command {
+	return &cobra.Command{
+		Use:     "expand <resource-type:resource-id> <permission>",
+		Short:   "expands a permission against a running server",
+		Long:    "Connects to a running server and prints the tree of subjects reachable from the permission on the resource.",
+		PreRunE: server.DefaultPreRunE(programName),
+		Args:    cobra.ExactArgs(2),
+		RunE:    termination.PublishError(expandRun),
+	}
+}
+
+func expandRun(cmd *cobra.Command, args []string) error {
+	resource, err := client.ParseObject(args[0])
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	resp, err := client.ExpandPermissionTree(cmd.Context(), &v1.ExpandPermissionTreeRequest{
+		Consistency: consistency(cmd),
+		Resource:    resource,
+		Permission:  args[1],
+	})
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if cobrautil.MustGetBool(cmd, "json") {
+		return printJSON(out, resp)
+	}
+
+	printTree(out, resp.TreeRoot, 0)
+	return nil
+}
+
+// printTree prints an expanded permission tree, indenting each node below its parent.
+func printTree(out io.Writer, node *v1.PermissionRelationshipTree, depth int) {
+	indent := strings.Repeat("  ", depth)
+	name := tuple.StringObjectRef(node.ExpandedObject) + "#" + node.ExpandedRelation
+
+	switch tree := node.TreeType.(type) {
+	case *v1.PermissionRelationshipTree_Intermediate:
+		operation := strings.ToLower(strings.TrimPrefix(tree.Intermediate.Operation.String(), "OPERATION_"))
+		fmt.Fprintf(out, "%s%s (%s)\n", indent, name, operation)
+		for _, child := range tree.Intermediate.Children {
+			printTree(out, child, depth+1)
+		}
+
+	case *v1.PermissionRelationshipTree_Leaf:
+		fmt.Fprintf(out, "%s%s\n", indent, name)
+		for _, subject := range tree.Leaf.Subjects {
+			fmt.Fprintf(out, "%s  %s\n", indent, tuple.StringSubjectRef(subject))
+		}
+	}
+}
+
+// newClient connects to the server named by the client flags of the command.
+func newClient(cmd *cobra.Command) (*client.Client, error) {
+	var opts []client.Option
+	if token := cobrautil.MustGetString(cmd, "token"); token != "" {
+		opts = append(opts, client.WithToken(token))
+	}
+	if cobrautil.MustGetBool(cmd, "insecure") {
+		opts = append(opts, client.WithInsecure())
+	}
+	if cobrautil.MustGetBool(cmd, "no-verify-ca") {
+		opts = append(opts, client.WithSkipVerifyCA())
+	}
+	if caPath := cobrautil.MustGetString(cmd, "ca-path"); caPath != "" {
+		opts = append(opts, client.WithCACert(caPath))
+	}
+	return client.New(cobrautil.MustGetString(cmd, "endpoint"), opts...)
+}
+
+func consistency(cmd *cobra.Command) *v1.Consistency {
+	if cobrautil.MustGetBool(cmd, "fully-consistent") {
+		return &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}}
+	}
+	return &v1.Consistency{Requirement: &v1.Consistency_MinimizeLatency{MinimizeLatency: true}}
+}
+
+func printJSON(out io.Writer, msg proto.Message) error {
+	encoded, err := protojson.MarshalOptions{Multiline: true}.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode response: %w", err)
+	}
+	fmt.Fprintln(out, string(encoded))
+	return nil
+}
@@ -0,0 +1,139 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+type fakePermissionsServer struct {
+	v1.UnimplementedPermissionsServiceServer
+
+	checkRequest *v1.CheckPermissionRequest
+	readRequest  *v1.ReadRelationshipsRequest
+}
+
+func (fps *fakePermissionsServer) CheckPermission(_ context.Context, req *v1.CheckPermissionRequest) (*v1.CheckPermissionResponse, error) {
+	fps.checkRequest = req
+	return &v1.CheckPermissionResponse{Permissionship: v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION}, nil
+}
+
+func (fps *fakePermissionsServer) ReadRelationships(req *v1.ReadRelationshipsRequest, stream v1.PermissionsService_ReadRelationshipsServer) error {
+	fps.readRequest = req
+	for _, rel := range []string{"document:first#viewer@user:tom", "document:second#viewer@group:eng#member"} {
+		if err := stream.Send(&v1.ReadRelationshipsResponse{Relationship: tuple.MustToRelationship(tuple.MustParse(rel))}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (fps *fakePermissionsServer) ExpandPermissionTree(_ context.Context, _ *v1.ExpandPermissionTreeRequest) (*v1.ExpandPermissionTreeResponse, error) {
+	document := &v1.ObjectReference{ObjectType: "document", ObjectId: "first"}
+	return &v1.ExpandPermissionTreeResponse{
+		TreeRoot: &v1.PermissionRelationshipTree{
+			ExpandedObject:   document,
+			ExpandedRelation: "view",
+			TreeType: &v1.PermissionRelationshipTree_Intermediate{Intermediate: &v1.AlgebraicSubjectSet{
+				Operation: v1.AlgebraicSubjectSet_OPERATION_UNION,
+				Children: []*v1.PermissionRelationshipTree{{
+					ExpandedObject:   document,
+					ExpandedRelation: "viewer",
+					TreeType: &v1.PermissionRelationshipTree_Leaf{Leaf: &v1.DirectSubjectSet{
+						Subjects: []*v1.SubjectReference{{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "tom"}}},
+					}},
+				}},
+			}},
+		},
+	}, nil
+}
+
+func startFakeServer(t *testing.T) (*fakePermissionsServer, string) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	fake := &fakePermissionsServer{}
+	srv := grpc.NewServer()
+	v1.RegisterPermissionsServiceServer(srv, fake)
+	go func() {
+		_ = srv.Serve(lis)
+	}()
+	t.Cleanup(srv.Stop)
+
+	return fake, lis.Addr().String()
+}
+
+func runClientCommand(t *testing.T, cmd *cobra.Command, register func(*cobra.Command), addr string, args ...string) string {
+	register(cmd)
+
+	var out bytes.Buffer
+	cmd.SetOut(&out)
+	cmd.PreRunE = nil
+	cmd.SetArgs(append([]string{"--endpoint", addr, "--insecure"}, args...))
+	require.NoError(t, cmd.Execute())
+	return out.String()
+}
+
+func TestCheckCommand(t *testing.T) {
+	fake, addr := startFakeServer(t)
+
+	out := runClientCommand(t, NewCheckCommand("spicedb"), RegisterCheckFlags, addr,
+		"document:first", "view", "group:eng#member", "--fully-consistent", "--caveat-context", `{"ip": "10.0.0.1"}`)
+	require.Equal(t, "true\n", out)
+
+	require.Equal(t, "document:first", tuple.StringObjectRef(fake.checkRequest.Resource))
+	require.Equal(t, "view", fake.checkRequest.Permission)
+	require.Equal(t, "group:eng#member", tuple.StringSubjectRef(fake.checkRequest.Subject))
+	require.True(t, fake.checkRequest.Consistency.GetFullyConsistent())
+	require.Equal(t, "10.0.0.1", fake.checkRequest.Context.Fields["ip"].GetStringValue())
+}
+
+func TestCheckCommandInvalidArgs(t *testing.T) {
+	cmd := NewCheckCommand("spicedb")
+	RegisterCheckFlags(cmd)
+	cmd.PreRunE = nil
+	cmd.SetOut(&bytes.Buffer{})
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"document", "view", "user:tom"})
+	require.ErrorContains(t, cmd.Execute(), "invalid object `document`")
+}
+
+func TestReadCommand(t *testing.T) {
+	fake, addr := startFakeServer(t)
+
+	out := runClientCommand(t, NewReadCommand("spicedb"), RegisterClientFlags, addr, "document", "viewer", "group:eng#member")
+	require.Equal(t, "document:first#viewer@user:tom\ndocument:second#viewer@group:eng#member\n", out)
+
+	filter := fake.readRequest.RelationshipFilter
+	require.Equal(t, "document", filter.ResourceType)
+	require.Empty(t, filter.OptionalResourceId)
+	require.Equal(t, "viewer", filter.OptionalRelation)
+	require.Equal(t, "group", filter.OptionalSubjectFilter.SubjectType)
+	require.Equal(t, "eng", filter.OptionalSubjectFilter.OptionalSubjectId)
+	require.Equal(t, "member", filter.OptionalSubjectFilter.OptionalRelation.Relation)
+	require.True(t, fake.readRequest.Consistency.GetMinimizeLatency())
+}
+
+func TestReadCommandJSON(t *testing.T) {
+	_, addr := startFakeServer(t)
+
+	out := runClientCommand(t, NewReadCommand("spicedb"), RegisterClientFlags, addr, "document", "--json")
+	lines := bytes.Split(bytes.TrimSpace([]byte(out)), []byte("\n"))
+	require.Len(t, lines, 2)
+	require.Contains(t, string(lines[0]), `"objectId":"first"`)
+}
+
+func TestExpandCommand(t *testing.T) {
+	_, addr := startFakeServer(t)
+
+	out := runClientCommand(t, NewExpandCommand("spicedb"), RegisterClientFlags, addr, "document:first", "view")
+	require.Equal(t, "document:first#view (union)\n  document:first#viewer\n    user:tom\n", out)
+}
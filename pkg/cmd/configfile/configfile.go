@@ -0,0 +1,128 @@
+package configfile
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jzelinskie/cobrautil/v2"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+const configFlagName = "config"
+
+// RegisterFlags adds the flag for loading configuration from a YAML file.
+//
+// The following flags are added:
+// - "config"
+func RegisterFlags(flags *pflag.FlagSet) {
+	flags.String(configFlagName, "", "path to a YAML file of flag values; values given as flags or environment variables take precedence")
+}
+
+// RunE returns a Cobra RunFunc that sets the flags of the command from the YAML file
+// given by the config flag.
+//
+// The file is a mapping from flag names to values, for example:
+//
+//	grpc-preshared-key: [somekey, otherkey]
+//	datastore-engine: postgres
+//	datastore-gc-window: 2h
+//	dispatch-cluster-enabled: true
+//
+// Only flags that have not already been set are changed, so when this runs after the
+// environment variables have been synchronized, flags take precedence over environment
+// variables, which take precedence over the file.
+//
+// The required flags can be added to a command by using RegisterFlags().
+func RunE() cobrautil.CobraRunFunc {
+	return func(cmd *cobra.Command, args []string) error {
+		if cobrautil.IsBuiltinCommand(cmd) {
+			return nil // No-op for builtins
+		}
+
+		configFlag := cmd.Flags().Lookup(configFlagName)
+		if configFlag == nil || configFlag.Value.String() == "" {
+			return nil
+		}
+
+		contents, err := os.ReadFile(configFlag.Value.String())
+		if err != nil {
+			return fmt.Errorf("failed to read config file: %w", err)
+		}
+
+		return applyConfig(cmd.Flags(), contents)
+	}
+}
+
+func applyConfig(flags *pflag.FlagSet, contents []byte) error {
+	values := map[string]any{}
+	if err := yamlv3.Unmarshal(contents, &values); err != nil {
+		return fmt.Errorf("failed to parse config file: %w", err)
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		if name == configFlagName {
+			return fmt.Errorf("config file cannot set `%s`", configFlagName)
+		}
+
+		flag := flags.Lookup(name)
+		if flag == nil {
+			return fmt.Errorf("unknown flag `%s` in config file", name)
+		}
+		if flag.Changed {
+			continue
+		}
+
+		if err := setFlag(flags, flag, values[name]); err != nil {
+			return fmt.Errorf("invalid value for `%s` in config file: %w", name, err)
+		}
+	}
+	return nil
+}
+
+func setFlag(flags *pflag.FlagSet, flag *pflag.Flag, value any) error {
+	switch value := value.(type) {
+	case []any:
+		items := make([]string, 0, len(value))
+		for _, item := range value {
+			items = append(items, fmt.Sprintf("%v", item))
+		}
+
+		if sliceValue, ok := flag.Value.(pflag.SliceValue); ok {
+			if err := sliceValue.Replace(items); err != nil {
+				return err
+			}
+			flag.Changed = true
+			return nil
+		}
+		return flags.Set(flag.Name, strings.Join(items, ","))
+
+	case map[string]any:
+		keys := make([]string, 0, len(value))
+		for key := range value {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		pairs := make([]string, 0, len(value))
+		for _, key := range keys {
+			pairs = append(pairs, fmt.Sprintf("%s=%v", key, value[key]))
+		}
+		return flags.Set(flag.Name, strings.Join(pairs, ","))
+
+	case nil:
+		return nil
+
+	default:
+		return flags.Set(flag.Name, fmt.Sprintf("%v", value))
+	}
+}
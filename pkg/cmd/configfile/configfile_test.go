@@ -0,0 +1,105 @@
+package configfile
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyConfig(t *testing.T) {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	keys := flags.StringSlice("keys", []string{"default"}, "")
+	timeout := flags.Duration("timeout", time.Second, "")
+	enabled := flags.Bool("enabled", false, "")
+	upstreams := flags.StringToString("upstreams", nil, "")
+	name := flags.String("name", "", "")
+	require.NoError(t, flags.Parse([]string{"--name", "fromflag"}))
+
+	err := applyConfig(flags, []byte(`
+keys: [first, second]
+timeout: 5s
+enabled: true
+upstreams:
+  us: us.example.com:50053
+  eu: eu.example.com:50053
+name: fromfile
+`))
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"first", "second"}, *keys)
+	require.Equal(t, 5*time.Second, *timeout)
+	require.True(t, *enabled)
+	require.Equal(t, map[string]string{"us": "us.example.com:50053", "eu": "eu.example.com:50053"}, *upstreams)
+	require.Equal(t, "fromflag", *name)
+}
+
+func TestApplyConfigErrors(t *testing.T) {
+	testCases := []struct {
+		name          string
+		contents      string
+		expectedError string
+	}{
+		{"unknown flag", "unknown: value", "unknown flag `unknown`"},
+		{"invalid value", "timeout: forever", "invalid value for `timeout`"},
+		{"config flag", "config: other.yaml", "cannot set `config`"},
+		{"not a mapping", "- timeout", "failed to parse config file"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+			flags.Duration("timeout", time.Second, "")
+			RegisterFlags(flags)
+
+			err := applyConfig(flags, []byte(tc.contents))
+			require.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestWriteEffective(t *testing.T) {
+	newFlags := func() *pflag.FlagSet {
+		flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+		RegisterFlags(flags)
+		flags.StringSlice("keys", nil, "")
+		flags.Duration("timeout", time.Second, "")
+		flags.Bool("enabled", false, "")
+		flags.StringToString("upstreams", nil, "")
+		flags.String("name", "", "")
+		flags.String("engine", "memory", "")
+		return flags
+	}
+
+	flags := newFlags()
+	require.NoError(t, flags.Parse([]string{"--keys", "secret", "--name", "fromflag"}))
+	commandLine := CommandLineFlags(flags)
+
+	t.Setenv("TEST_ENABLED", "true")
+	require.NoError(t, flags.Set("enabled", "true"))
+	require.NoError(t, applyConfig(flags, []byte(`
+timeout: 5s
+upstreams:
+  us: us.example.com:50053
+`)))
+
+	var out strings.Builder
+	require.NoError(t, WriteEffective(&out, flags, commandLine, "test", "keys"))
+	require.Equal(t, `enabled: true # set by TEST_ENABLED
+engine: memory
+keys: (sensitive) # set by command line
+name: fromflag # set by command line
+timeout: 5s # set by config file
+upstreams: {us: 'us.example.com:50053'} # set by config file
+`, out.String())
+
+	// The written config can be given back as a config file.
+	roundTripped := newFlags()
+	require.NoError(t, applyConfig(roundTripped, []byte(out.String())))
+	require.Equal(t, "fromflag", roundTripped.Lookup("name").Value.String())
+	require.Equal(t, "5s", roundTripped.Lookup("timeout").Value.String())
+	require.Equal(t, "[us=us.example.com:50053]", roundTripped.Lookup("upstreams").Value.String())
+}
@@ -0,0 +1,128 @@
+package configfile
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/spf13/pflag"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+const redactedValue = "(sensitive)"
+
+// CommandLineFlags returns the names of the flags that have been changed, which, before the
+// environment variables and config file are applied, are the flags given on the command line.
+func CommandLineFlags(flags *pflag.FlagSet) map[string]bool {
+	changed := map[string]bool{}
+	flags.Visit(func(flag *pflag.Flag) {
+		changed[flag.Name] = true
+	})
+	return changed
+}
+
+// WriteEffective writes the value of every flag as a YAML config file, which can be given back
+// as the config flag.
+//
+// Each value that was not defaulted is commented with where it was set: the command line, if
+// it is one of the commandLine flags, an environment variable with the envPrefix, or the config
+// file. The values of the sensitive flags are redacted.
+func WriteEffective(w io.Writer, flags *pflag.FlagSet, commandLine map[string]bool, envPrefix string, sensitive ...string) error {
+	var all []*pflag.Flag
+	flags.VisitAll(func(flag *pflag.Flag) {
+		// Flags which only change what the command does are not configuration.
+		if flag.Name == configFlagName || flag.Name == "help" || flag.Name == "dry-run" {
+			return
+		}
+		all = append(all, flag)
+	})
+	sort.Slice(all, func(i, j int) bool { return all[i].Name < all[j].Name })
+
+	doc := &yamlv3.Node{Kind: yamlv3.MappingNode}
+	for _, flag := range all {
+		key := &yamlv3.Node{Kind: yamlv3.ScalarNode, Value: flag.Name}
+		value, err := flagValueNode(flags, flag)
+		if err != nil {
+			return fmt.Errorf("failed to encode `%s`: %w", flag.Name, err)
+		}
+
+		if flag.Changed && isSensitive(flag.Name, sensitive) {
+			value = &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: redactedValue}
+		}
+
+		if flag.Changed {
+			value.LineComment = "set by " + flagSource(flag.Name, commandLine, envPrefix)
+		}
+		doc.Content = append(doc.Content, key, value)
+	}
+
+	encoder := yamlv3.NewEncoder(w)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return encoder.Close()
+}
+
+func flagSource(name string, commandLine map[string]bool, envPrefix string) string {
+	if commandLine[name] {
+		return "command line"
+	}
+
+	// This matches the environment variables synchronized by cobrautil.
+	envVar := strings.ToUpper(strings.ReplaceAll(envPrefix+"_"+name, "-", "_"))
+	if _, ok := os.LookupEnv(envVar); ok {
+		return envVar
+	}
+	return "config file"
+}
+
+func isSensitive(name string, sensitive []string) bool {
+	for _, sensitiveName := range sensitive {
+		if name == sensitiveName {
+			return true
+		}
+	}
+	return false
+}
+
+func flagValueNode(flags *pflag.FlagSet, flag *pflag.Flag) (*yamlv3.Node, error) {
+	if sliceValue, ok := flag.Value.(pflag.SliceValue); ok {
+		node := &yamlv3.Node{Kind: yamlv3.SequenceNode, Style: yamlv3.FlowStyle}
+		for _, item := range sliceValue.GetSlice() {
+			node.Content = append(node.Content, &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: item})
+		}
+		return node, nil
+	}
+
+	switch flag.Value.Type() {
+	case "stringToString":
+		values, err := flags.GetStringToString(flag.Name)
+		if err != nil {
+			return nil, err
+		}
+
+		keys := make([]string, 0, len(values))
+		for key := range values {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		node := &yamlv3.Node{Kind: yamlv3.MappingNode, Style: yamlv3.FlowStyle}
+		for _, key := range keys {
+			node.Content = append(node.Content,
+				&yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: key},
+				&yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: values[key]},
+			)
+		}
+		return node, nil
+
+	case "bool", "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64", "float32", "float64":
+		return &yamlv3.Node{Kind: yamlv3.ScalarNode, Value: flag.Value.String()}, nil
+
+	default:
+		return &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: flag.Value.String()}, nil
+	}
+}
@@ -5,14 +5,18 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/jzelinskie/cobrautil/v2"
 	"github.com/spf13/cobra"
 
 	"github.com/authzed/spicedb/internal/datastore/common"
+	"github.com/authzed/spicedb/internal/integrity"
 	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/rename"
 	"github.com/authzed/spicedb/pkg/cmd/datastore"
 	"github.com/authzed/spicedb/pkg/cmd/server"
 	"github.com/authzed/spicedb/pkg/cmd/termination"
 	dspkg "github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/tuple"
 )
 
 func RegisterDatastoreRootFlags(_ *cobra.Command) {
@@ -32,17 +36,23 @@ func NewDatastoreCommand(programName string) (*cobra.Command, error) {
 	cfg := datastore.Config{}
 
 	gcCmd := NewGCDatastoreCommand(programName, &cfg)
-	if err := datastore.RegisterDatastoreFlagsWithPrefix(gcCmd.Flags(), "", &cfg); err != nil {
+	if err := datastore.RegisterDatastoreFlags(gcCmd, &cfg); err != nil {
 		return nil, err
 	}
 	datastoreCmd.AddCommand(gcCmd)
 
 	repairCmd := NewRepairDatastoreCommand(programName, &cfg)
-	if err := datastore.RegisterDatastoreFlagsWithPrefix(repairCmd.Flags(), "", &cfg); err != nil {
+	if err := RegisterRepairFlags(repairCmd, &cfg); err != nil {
 		return nil, err
 	}
 	datastoreCmd.AddCommand(repairCmd)
 
+	renameCmd := NewRenameDatastoreCommand(programName, &cfg)
+	if err := RegisterRenameFlags(renameCmd, &cfg); err != nil {
+		return nil, err
+	}
+	datastoreCmd.AddCommand(renameCmd)
+
 	headCmd := NewHeadCommand(programName)
 	RegisterHeadFlags(headCmd)
 	datastoreCmd.AddCommand(headCmd)
@@ -87,11 +97,18 @@ func NewGCDatastoreCommand(programName string, cfg *datastore.Config) *cobra.Com
 	}
 }
 
+func RegisterRepairFlags(cmd *cobra.Command, cfg *datastore.Config) error {
+	cmd.Flags().Bool("check", false, "check the integrity of the datastore, reporting orphaned relationships referring to definitions or relations not in the schema, duplicated relationships and gaps in the changelog, instead of running a repair operation")
+	cmd.Flags().Bool("fix", false, "with --check, delete the orphaned relationships and rewrite the duplicated relationships found")
+	cmd.Flags().Int("batch-size", 1000, "with --check, maximum number of relationships read per query and fixed per transaction")
+	return datastore.RegisterDatastoreFlags(cmd, cfg)
+}
+
 func NewRepairDatastoreCommand(programName string, cfg *datastore.Config) *cobra.Command {
 	return &cobra.Command{
-		Use:     "repair",
+		Use:     "repair [operation]",
 		Short:   "executes datastore repair",
-		Long:    "Executes a repair operation for the datastore",
+		Long:    "Executes a repair operation for the datastore, or, with --check, checks the integrity of the datastore",
 		PreRunE: server.DefaultPreRunE(programName),
 		RunE: termination.PublishError(func(cmd *cobra.Command, args []string) error {
 			ctx := context.Background()
@@ -105,6 +122,11 @@ func NewRepairDatastoreCommand(programName string, cfg *datastore.Config) *cobra
 				return fmt.Errorf("failed to create datastore: %w", err)
 			}
 
+			if cobrautil.MustGetBool(cmd, "check") {
+				defer ds.Close()
+				return checkIntegrity(ctx, ds, cobrautil.MustGetBool(cmd, "fix"), cobrautil.MustGetInt(cmd, "batch-size"))
+			}
+
 			repairable := dspkg.UnwrapAs[dspkg.RepairableDatastore](ds)
 			if repairable == nil {
 				return fmt.Errorf("datastore of type %T does not support the repair operation", ds)
@@ -132,3 +154,87 @@ func NewRepairDatastoreCommand(programName string, cfg *datastore.Config) *cobra
 		}),
 	}
 }
+
+// checkIntegrity checks the integrity of the datastore, logging each problem found, and returns
+// an error if any remain.
+func checkIntegrity(ctx context.Context, ds dspkg.Datastore, fix bool, batchSize int) error {
+	log.Ctx(ctx).Info().Bool("fix", fix).Msg("Checking datastore integrity...")
+	result, err := integrity.Check(ctx, ds, integrity.Config{
+		Fix:       fix,
+		BatchSize: batchSize,
+		OnFinding: func(finding integrity.Finding) {
+			event := log.Ctx(ctx).Warn().Str("kind", string(finding.Kind))
+			if finding.Relationship != nil {
+				event = event.Str("relationship", tuple.MustString(finding.Relationship))
+			}
+			event.Msg(finding.Reason)
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Ctx(ctx).Info().
+		Int("scanned", result.Scanned).
+		Int("findings", len(result.Findings)).
+		Int("fixed", result.Fixed).
+		Msg("Datastore integrity check completed")
+
+	unfixed := 0
+	for _, finding := range result.Findings {
+		if !fix || finding.Kind == integrity.ChangelogGap {
+			unfixed++
+		}
+	}
+	if unfixed > 0 {
+		return fmt.Errorf("found %d integrity problems which were not fixed", unfixed)
+	}
+	return nil
+}
+
+func RegisterRenameFlags(cmd *cobra.Command, cfg *datastore.Config) error {
+	cmd.Flags().Int("batch-size", 1000, "maximum number of relationships rewritten per transaction")
+	return datastore.RegisterDatastoreFlags(cmd, cfg)
+}
+
+func NewRenameDatastoreCommand(programName string, cfg *datastore.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:   "rename <definition[#relation]> <new-definition[#new-relation]>",
+		Short: "renames an object definition or relation and rewrites its relationships",
+		Long: "Renames an object definition, or a relation or permission within one, in the schema, along with every reference to it, and then rewrites the relationships referring to it in batched transactions. " +
+			"Until every batch is written, checks do not observe the relationships not yet rewritten. An interrupted rename is resumed by running it again.",
+		PreRunE: server.DefaultPreRunE(programName),
+		Args:    cobra.ExactArgs(2),
+		RunE: termination.PublishError(func(cmd *cobra.Command, args []string) error {
+			ctx := context.Background()
+
+			// Disable background GC and hedging.
+			cfg.GCInterval = -1 * time.Hour
+			cfg.RequestHedgingEnabled = false
+
+			ds, err := datastore.NewDatastore(ctx, cfg.ToOption())
+			if err != nil {
+				return fmt.Errorf("failed to create datastore: %w", err)
+			}
+			defer ds.Close()
+
+			result, err := rename.Rename(ctx, ds, rename.Config{
+				From:      args[0],
+				To:        args[1],
+				BatchSize: cobrautil.MustGetInt(cmd, "batch-size"),
+				OnProgress: func(rewritten int) {
+					log.Ctx(ctx).Info().Int("rewritten", rewritten).Msg("rewrote relationships")
+				},
+			})
+			if err != nil {
+				return err
+			}
+
+			log.Ctx(ctx).Info().
+				Bool("schema_renamed", result.SchemaRenamed).
+				Int("rewritten", result.Rewritten).
+				Msgf("renamed %s to %s", args[0], args[1])
+			return nil
+		}),
+	}
+}
@@ -2,8 +2,10 @@ package datastore
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
@@ -119,6 +121,21 @@ type Config struct {
 	RequestHedgingMaxRequests      uint64        `debugmap:"visible"`
 	RequestHedgingQuantile         float64       `debugmap:"visible"`
 
+	// Retries
+	RetryMaxRetries     uint8         `debugmap:"visible"`
+	RetryInitialBackoff time.Duration `debugmap:"visible"`
+	RetryMaxBackoff     time.Duration `debugmap:"visible"`
+	RetryBudgetRatio    float64       `debugmap:"visible"`
+
+	// Circuit breaking
+	CircuitBreakerFailureThreshold uint16        `debugmap:"visible"`
+	CircuitBreakerOpenDuration     time.Duration `debugmap:"visible"`
+	CircuitBreakerServeStaleReads  bool          `debugmap:"visible"`
+
+	// Clock skew
+	MaxClockSkew           time.Duration `debugmap:"visible"`
+	ClockSkewCheckInterval time.Duration `debugmap:"visible"`
+
 	// CRDB
 	FollowerReadDelay         time.Duration `debugmap:"visible"`
 	MaxRetries                int           `debugmap:"visible"`
@@ -128,8 +145,12 @@ type Config struct {
 	ConnectRate               time.Duration `debugmap:"visible"`
 
 	// Postgres
-	GCInterval         time.Duration `debugmap:"visible"`
-	GCMaxOperationTime time.Duration `debugmap:"visible"`
+	GCInterval         time.Duration     `debugmap:"visible"`
+	GCMaxOperationTime time.Duration     `debugmap:"visible"`
+	GCWindowOverrides  map[string]string `debugmap:"visible"`
+
+	// Postgres and MySQL
+	QueryPlanLogInterval time.Duration `debugmap:"visible"`
 
 	// Spanner
 	SpannerCredentialsFile string `debugmap:"visible"`
@@ -140,6 +161,15 @@ type Config struct {
 	// MySQL
 	TablePrefix string `debugmap:"visible"`
 
+	// Memory
+	MemoryDataPath string `debugmap:"visible"`
+
+	// Relationship Integrity
+	RelationshipIntegrityEnabled            bool     `debugmap:"visible"`
+	RelationshipIntegrityCurrentKeyID       string   `debugmap:"visible"`
+	RelationshipIntegrityCurrentKeyFilename string   `debugmap:"visible"`
+	RelationshipIntegrityExpiredKeys        []string `debugmap:"visible-format"`
+
 	// Internal
 	WatchBufferLength       uint16        `debugmap:"visible"`
 	WatchBufferWriteTimeout time.Duration `debugmap:"visible"`
@@ -148,9 +178,18 @@ type Config struct {
 	MigrationPhase string `debugmap:"visible"`
 }
 
-// RegisterDatastoreFlags adds datastore flags to a cobra command.
+// RegisterDatastoreFlags adds datastore flags to a cobra command, along with shell
+// completion of the datastore engine.
 func RegisterDatastoreFlags(cmd *cobra.Command, opts *Config) error {
-	return RegisterDatastoreFlagsWithPrefix(cmd.Flags(), "", opts)
+	if err := RegisterDatastoreFlagsWithPrefix(cmd.Flags(), "", opts); err != nil {
+		return err
+	}
+	return cmd.RegisterFlagCompletionFunc("datastore-engine", CompleteEngine)
+}
+
+// CompleteEngine is a shell completion function for flags naming a datastore engine.
+func CompleteEngine(_ *cobra.Command, _ []string, _ string) ([]string, cobra.ShellCompDirective) {
+	return datastore.SortedEngineIDs(), cobra.ShellCompDirectiveNoFileComp
 }
 
 // RegisterDatastoreFlagsWithPrefix adds datastore flags to a cobra command, with each flag prefixed with the provided
@@ -189,6 +228,8 @@ func RegisterDatastoreFlagsWithPrefix(flagSet *pflag.FlagSet, prefix string, opt
 	flagSet.DurationVar(&opts.GCWindow, flagName("datastore-gc-window"), defaults.GCWindow, "amount of time before revisions are garbage collected")
 	flagSet.DurationVar(&opts.GCInterval, flagName("datastore-gc-interval"), defaults.GCInterval, "amount of time between passes of garbage collection (postgres driver only)")
 	flagSet.DurationVar(&opts.GCMaxOperationTime, flagName("datastore-gc-max-operation-time"), defaults.GCMaxOperationTime, "maximum amount of time a garbage collection pass can operate before timing out (postgres driver only)")
+	flagSet.StringToStringVar(&opts.GCWindowOverrides, flagName("datastore-gc-window-overrides"), defaults.GCWindowOverrides, `GC windows of namespaces whose deleted relationships are kept for longer or shorter than the GC window, such as "audit_log=2160h,session=1h"; reads at revisions older than the window of a namespace may miss its deleted relationships (postgres and mysql drivers only)`)
+	flagSet.DurationVar(&opts.QueryPlanLogInterval, flagName("datastore-query-plan-log-interval"), defaults.QueryPlanLogInterval, "interval at which the plan chosen by the database for each shape of relationship query made by lookup and expand requests is logged, to diagnose slow reverse queries; 0 disables logging query plans (postgres and mysql drivers only)")
 	flagSet.DurationVar(&opts.RevisionQuantization, flagName("datastore-revision-quantization-interval"), defaults.RevisionQuantization, "boundary interval to which to round the quantized revision")
 	flagSet.Float64Var(&opts.MaxRevisionStalenessPercent, flagName("datastore-revision-quantization-max-staleness-percent"), defaults.MaxRevisionStalenessPercent, "float percentage (where 1 = 100%) of the revision quantization interval where we may opt to select a stale revision for performance reasons. Defaults to 0.1 (representing 10%)")
 	flagSet.BoolVar(&opts.ReadOnly, flagName("datastore-readonly"), defaults.ReadOnly, "set the service to read-only mode")
@@ -199,6 +240,15 @@ func RegisterDatastoreFlagsWithPrefix(flagSet *pflag.FlagSet, prefix string, opt
 	flagSet.DurationVar(&opts.RequestHedgingInitialSlowValue, flagName("datastore-request-hedging-initial-slow-value"), defaults.RequestHedgingInitialSlowValue, "initial value to use for slow datastore requests, before statistics have been collected")
 	flagSet.Uint64Var(&opts.RequestHedgingMaxRequests, flagName("datastore-request-hedging-max-requests"), defaults.RequestHedgingMaxRequests, "maximum number of historical requests to consider")
 	flagSet.Float64Var(&opts.RequestHedgingQuantile, flagName("datastore-request-hedging-quantile"), defaults.RequestHedgingQuantile, "quantile of historical datastore request time over which a request will be considered slow")
+	flagSet.Uint8Var(&opts.RetryMaxRetries, flagName("datastore-retry-max-retries"), defaults.RetryMaxRetries, "number of times a transaction or revision request failing with a serialization failure or transient connection error is retried, after any retries of the datastore driver, before the error is returned to callers; 0 disables these retries")
+	flagSet.DurationVar(&opts.RetryInitialBackoff, flagName("datastore-retry-initial-backoff"), defaults.RetryInitialBackoff, "maximum delay before the first retry of a datastore operation, which doubles with each retry; the actual delay is chosen at random below it")
+	flagSet.DurationVar(&opts.RetryMaxBackoff, flagName("datastore-retry-max-backoff"), defaults.RetryMaxBackoff, "maximum delay between retries of a datastore operation")
+	flagSet.Float64Var(&opts.RetryBudgetRatio, flagName("datastore-retry-budget-ratio"), defaults.RetryBudgetRatio, "number of retries allowed for each successful datastore operation, beyond an initial burst, so that retries do not overload a failing datastore")
+	flagSet.Uint16Var(&opts.CircuitBreakerFailureThreshold, flagName("datastore-circuit-breaker-failure-threshold"), defaults.CircuitBreakerFailureThreshold, "number of consecutive datastore operations failing to connect, such as during the failover of its primary, after which operations fail fast with an Unavailable error until a probe succeeds; 0 disables circuit breaking")
+	flagSet.DurationVar(&opts.CircuitBreakerOpenDuration, flagName("datastore-circuit-breaker-open-duration"), defaults.CircuitBreakerOpenDuration, "how long datastore operations fail fast before one is let through to probe whether the datastore has recovered")
+	flagSet.BoolVar(&opts.CircuitBreakerServeStaleReads, flagName("datastore-circuit-breaker-serve-stale-reads"), defaults.CircuitBreakerServeStaleReads, "while datastore operations fail fast, serve requests at the last known revision from the caches and report the server as ready in a degraded state, rather than as not ready")
+	flagSet.DurationVar(&opts.MaxClockSkew, flagName("datastore-max-clock-skew"), defaults.MaxClockSkew, "maximum difference between the clocks of the server and datastore, or for the memory driver how far the clock may move back behind the latest revision, beyond which writes are rejected with an Unavailable error; 0 disables the check (memory, postgres and mysql drivers only)")
+	flagSet.DurationVar(&opts.ClockSkewCheckInterval, flagName("datastore-clock-skew-check-interval"), defaults.ClockSkewCheckInterval, "how often the difference between the clocks of the server and datastore is measured, when a maximum clock skew is configured")
 	flagSet.BoolVar(&opts.EnableDatastoreMetrics, flagName("datastore-prometheus-metrics"), defaults.EnableDatastoreMetrics, "set to false to disabled prometheus metrics from the datastore")
 	// See crdb doc for info about follower reads and how it is configured: https://www.cockroachlabs.com/docs/stable/follower-reads.html
 	flagSet.DurationVar(&opts.FollowerReadDelay, flagName("datastore-follower-read-delay-duration"), 4_800*time.Millisecond, "amount of time to subtract from non-sync revision timestamps to ensure they are sufficiently in the past to enable follower reads (cockroach driver only)")
@@ -212,6 +262,11 @@ func RegisterDatastoreFlagsWithPrefix(flagSet *pflag.FlagSet, prefix string, opt
 	flagSet.Uint64Var(&opts.SpannerMinSessions, flagName("datastore-spanner-min-sessions"), 100, "minimum number of sessions across all Spanner gRPC connections the client can have at a given time")
 	flagSet.Uint64Var(&opts.SpannerMaxSessions, flagName("datastore-spanner-max-sessions"), 400, "maximum number of sessions across all Spanner gRPC connections the client can have at a given time")
 	flagSet.StringVar(&opts.TablePrefix, flagName("datastore-mysql-table-prefix"), "", "prefix to add to the name of all SpiceDB database tables")
+	flagSet.StringVar(&opts.MemoryDataPath, flagName("datastore-memory-data-path"), defaults.MemoryDataPath, "path of a file to which the schema and relationships are saved when the server stops, and from which they are restored when it starts, so that they persist across restarts (memory driver only)")
+	flagSet.BoolVar(&opts.RelationshipIntegrityEnabled, flagName("datastore-relationship-integrity-enabled"), false, "enable computing and verifying an integrity hash of each relationship, to detect relationships written or modified in the datastore other than by SpiceDB (cockroach and memory drivers only)")
+	flagSet.StringVar(&opts.RelationshipIntegrityCurrentKeyID, flagName("datastore-relationship-integrity-current-key-id"), "", "ID of the key with which the integrity of relationships is computed")
+	flagSet.StringVar(&opts.RelationshipIntegrityCurrentKeyFilename, flagName("datastore-relationship-integrity-current-key-filename"), "", "path to the file containing the key with which the integrity of relationships is computed")
+	flagSet.StringArrayVar(&opts.RelationshipIntegrityExpiredKeys, flagName("datastore-relationship-integrity-expired-keys"), nil, `keys previously used to compute the integrity of relationships, each given as JSON (e.g. {"key_id": "old", "key_filename": "/path/to/key", "expired_at": "2024-01-02T15:04:05Z"})`)
 	flagSet.StringVar(&opts.MigrationPhase, flagName("datastore-migration-phase"), "", "datastore-specific flag that should be used to signal to a datastore which phase of a multi-step migration it is in")
 	flagSet.Uint16Var(&opts.WatchBufferLength, flagName("datastore-watch-buffer-length"), 1024, "how large the watch buffer should be before blocking")
 	flagSet.DurationVar(&opts.WatchBufferWriteTimeout, flagName("datastore-watch-buffer-write-timeout"), 1*time.Second, "how long the watch buffer should queue before forcefully disconnecting the reader")
@@ -252,6 +307,8 @@ func DefaultDatastoreConfig() *Config {
 		EnableConnectionBalancing:      true,
 		GCInterval:                     3 * time.Minute,
 		GCMaxOperationTime:             1 * time.Minute,
+		GCWindowOverrides:              map[string]string{},
+		QueryPlanLogInterval:           0,
 		WatchBufferLength:              1024,
 		WatchBufferWriteTimeout:        1 * time.Second,
 		EnableDatastoreMetrics:         true,
@@ -263,6 +320,15 @@ func DefaultDatastoreConfig() *Config {
 		RequestHedgingInitialSlowValue: 10000000,
 		RequestHedgingMaxRequests:      1_000_000,
 		RequestHedgingQuantile:         0.95,
+		RetryMaxRetries:                3,
+		RetryInitialBackoff:            20 * time.Millisecond,
+		RetryMaxBackoff:                1 * time.Second,
+		RetryBudgetRatio:               0.1,
+		CircuitBreakerFailureThreshold: 0,
+		CircuitBreakerOpenDuration:     5 * time.Second,
+		CircuitBreakerServeStaleReads:  false,
+		MaxClockSkew:                   0,
+		ClockSkewCheckInterval:         30 * time.Second,
 		SpannerCredentialsFile:         "",
 		SpannerEmulatorHost:            "",
 		TablePrefix:                    "",
@@ -273,6 +339,33 @@ func DefaultDatastoreConfig() *Config {
 	}
 }
 
+// ValidateRevisionTokenGuarantees returns an error if the configured datastore cannot guarantee
+// that a request evaluated at or after the revision of a ZedToken observes every write committed
+// before the token was issued, which enforced revision tokens rely upon for protection against
+// the new enemy problem. The guarantees of each engine are:
+//
+//   - memory: revisions are assigned by a single process, in the order in which writes commit.
+//   - postgres: revisions are snapshots of committed transaction IDs, which are only reached
+//     once every transaction they include is visible.
+//   - mysql: revisions are transaction IDs assigned in commit order by the primary.
+//   - spanner: revisions are commit timestamps, which TrueTime orders consistently with commits.
+//   - cockroachdb: revisions are commit timestamps, which are only ordered consistently with
+//     commits across ranges when writes share an overlap key, so the "insecure" overlap strategy
+//     is rejected.
+func (o *Config) ValidateRevisionTokenGuarantees() error {
+	switch o.Engine {
+	case MemoryEngine, PostgresEngine, MySQLEngine, SpannerEngine:
+		return nil
+	case CockroachEngine:
+		if o.OverlapStrategy == "insecure" {
+			return errors.New("the cockroachdb datastore cannot enforce revision tokens with the insecure transaction overlap strategy")
+		}
+		return nil
+	default:
+		return fmt.Errorf("the guarantees of datastore engine %q for enforcing revision tokens are unknown", o.Engine)
+	}
+}
+
 // NewDatastore initializes a datastore given the options
 func NewDatastore(ctx context.Context, options ...ConfigOption) (datastore.Datastore, error) {
 	opts := DefaultDatastoreConfig()
@@ -289,6 +382,10 @@ func NewDatastore(ctx context.Context, options ...ConfigOption) (datastore.Datas
 	if !ok {
 		return nil, fmt.Errorf("unknown datastore engine type: %s", opts.Engine)
 	}
+
+	if len(opts.GCWindowOverrides) > 0 && opts.Engine != PostgresEngine && opts.Engine != MySQLEngine {
+		return nil, fmt.Errorf("GC window overrides are not supported by the %s datastore engine", opts.Engine)
+	}
 	log.Ctx(ctx).Info().Msgf("using %s datastore engine", opts.Engine)
 
 	ds, err := dsBuilder(ctx, *opts)
@@ -296,6 +393,38 @@ func NewDatastore(ctx context.Context, options ...ConfigOption) (datastore.Datas
 		return nil, err
 	}
 
+	if opts.RelationshipIntegrityEnabled {
+		currentKey, expiredKeys, err := opts.relationshipIntegrityKeys()
+		if err != nil {
+			return nil, err
+		}
+
+		log.Ctx(ctx).Info().
+			Str("currentKeyID", currentKey.ID).
+			Int("expiredKeys", len(expiredKeys)).
+			Msg("relationship integrity enabled")
+
+		ds, err = proxy.NewRelationshipIntegrityProxy(ctx, ds, currentKey, expiredKeys)
+		if err != nil {
+			return nil, fmt.Errorf("error in configuring relationship integrity: %w", err)
+		}
+	}
+
+	if opts.MaxClockSkew > 0 && datastore.UnwrapAs[proxy.DatastoreClock](ds) != nil {
+		log.Ctx(ctx).Info().
+			Stringer("maxClockSkew", opts.MaxClockSkew).
+			Stringer("checkInterval", opts.ClockSkewCheckInterval).
+			Msg("datastore clock skew guard enabled")
+
+		sds, err := proxy.NewClockSkewGuardingDatastoreProxy(ds, opts.MaxClockSkew, opts.ClockSkewCheckInterval)
+		if err != nil {
+			return nil, fmt.Errorf("error in configuring the datastore clock skew guard: %w", err)
+		}
+		ds = sds
+	} else if opts.MaxClockSkew > 0 && opts.Engine != MemoryEngine {
+		log.Ctx(ctx).Warn().Msgf("the %s datastore orders revisions by its own clock; ignoring --datastore-max-clock-skew", opts.Engine)
+	}
+
 	if len(opts.BootstrapFiles) > 0 || len(opts.BootstrapFileContents) > 0 {
 		ctx, cancel := context.WithTimeout(ctx, opts.BootstrapTimeout)
 		defer cancel()
@@ -350,6 +479,44 @@ func NewDatastore(ctx context.Context, options ...ConfigOption) (datastore.Datas
 		ds = hds
 	}
 
+	if opts.RetryMaxRetries > 0 {
+		log.Ctx(ctx).Info().
+			Uint8("maxRetries", opts.RetryMaxRetries).
+			Stringer("initialBackoff", opts.RetryInitialBackoff).
+			Stringer("maxBackoff", opts.RetryMaxBackoff).
+			Float64("budgetRatio", opts.RetryBudgetRatio).
+			Msg("datastore retries enabled")
+
+		rds, err := proxy.NewRetryingDatastoreProxy(ds, proxy.RetryPolicy{
+			MaxRetries:     opts.RetryMaxRetries,
+			InitialBackoff: opts.RetryInitialBackoff,
+			MaxBackoff:     opts.RetryMaxBackoff,
+			BudgetRatio:    opts.RetryBudgetRatio,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error in configuring datastore retries: %w", err)
+		}
+		ds = rds
+	}
+
+	if opts.CircuitBreakerFailureThreshold > 0 {
+		log.Ctx(ctx).Info().
+			Uint16("failureThreshold", opts.CircuitBreakerFailureThreshold).
+			Stringer("openDuration", opts.CircuitBreakerOpenDuration).
+			Bool("serveStaleReads", opts.CircuitBreakerServeStaleReads).
+			Msg("datastore circuit breaking enabled")
+
+		cds, err := proxy.NewCircuitBreakingDatastoreProxy(ds, proxy.CircuitBreakerPolicy{
+			FailureThreshold: opts.CircuitBreakerFailureThreshold,
+			OpenDuration:     opts.CircuitBreakerOpenDuration,
+			ServeStaleReads:  opts.CircuitBreakerServeStaleReads,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error in configuring datastore circuit breaking: %w", err)
+		}
+		ds = cds
+	}
+
 	if opts.ReadOnly {
 		log.Ctx(ctx).Warn().Msg("setting the datastore to read-only")
 		ds = proxy.NewReadonlyDatastore(ds)
@@ -357,6 +524,55 @@ func NewDatastore(ctx context.Context, options ...ConfigOption) (datastore.Datas
 	return ds, nil
 }
 
+// expiredKeyConfig is the JSON form of a key given with
+// --datastore-relationship-integrity-expired-keys.
+type expiredKeyConfig struct {
+	KeyID       string    `json:"key_id"`
+	KeyFilename string    `json:"key_filename"`
+	ExpiredAt   time.Time `json:"expired_at"`
+}
+
+// relationshipIntegrityKeys reads the configured relationship integrity keys from their files.
+func (o *Config) relationshipIntegrityKeys() (proxy.KeyConfig, []proxy.KeyConfig, error) {
+	if o.RelationshipIntegrityCurrentKeyFilename == "" {
+		return proxy.KeyConfig{}, nil, errors.New("a current key file must be given to enable relationship integrity")
+	}
+
+	currentKeyBytes, err := os.ReadFile(o.RelationshipIntegrityCurrentKeyFilename)
+	if err != nil {
+		return proxy.KeyConfig{}, nil, fmt.Errorf("unable to read current relationship integrity key: %w", err)
+	}
+	currentKey := proxy.KeyConfig{
+		ID:    o.RelationshipIntegrityCurrentKeyID,
+		Bytes: currentKeyBytes,
+	}
+
+	expiredKeys := make([]proxy.KeyConfig, 0, len(o.RelationshipIntegrityExpiredKeys))
+	for _, encoded := range o.RelationshipIntegrityExpiredKeys {
+		var config expiredKeyConfig
+		if err := json.Unmarshal([]byte(encoded), &config); err != nil {
+			return proxy.KeyConfig{}, nil, fmt.Errorf("invalid expired relationship integrity key `%s`: %w", encoded, err)
+		}
+		if config.ExpiredAt.IsZero() {
+			return proxy.KeyConfig{}, nil, fmt.Errorf("expired relationship integrity key `%s` must have an expiration time", config.KeyID)
+		}
+
+		keyBytes, err := os.ReadFile(config.KeyFilename)
+		if err != nil {
+			return proxy.KeyConfig{}, nil, fmt.Errorf("unable to read expired relationship integrity key `%s`: %w", config.KeyID, err)
+		}
+
+		expiredAt := config.ExpiredAt
+		expiredKeys = append(expiredKeys, proxy.KeyConfig{
+			ID:        config.KeyID,
+			Bytes:     keyBytes,
+			ExpiredAt: &expiredAt,
+		})
+	}
+
+	return currentKey, expiredKeys, nil
+}
+
 func newCRDBDatastore(ctx context.Context, opts Config) (datastore.Datastore, error) {
 	return crdb.NewCRDBDatastore(
 		ctx,
@@ -388,9 +604,36 @@ func newCRDBDatastore(ctx context.Context, opts Config) (datastore.Datastore, er
 	)
 }
 
+// gcWindowOverrides parses the GC windows of the namespaces given with
+// --datastore-gc-window-overrides.
+func (o *Config) gcWindowOverrides() (map[string]time.Duration, error) {
+	if len(o.GCWindowOverrides) == 0 {
+		return nil, nil
+	}
+
+	overrides := make(map[string]time.Duration, len(o.GCWindowOverrides))
+	for namespace, value := range o.GCWindowOverrides {
+		window, err := time.ParseDuration(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid GC window for namespace %s: %w", namespace, err)
+		}
+		if window <= 0 {
+			return nil, fmt.Errorf("invalid GC window for namespace %s: must be positive", namespace)
+		}
+		overrides[namespace] = window
+	}
+	return overrides, nil
+}
+
 func newPostgresDatastore(ctx context.Context, opts Config) (datastore.Datastore, error) {
+	gcWindowOverrides, err := opts.gcWindowOverrides()
+	if err != nil {
+		return nil, err
+	}
+
 	pgOpts := []postgres.Option{
 		postgres.GCWindow(opts.GCWindow),
+		postgres.GCWindowOverrides(gcWindowOverrides),
 		postgres.GCEnabled(!opts.ReadOnly),
 		postgres.RevisionQuantization(opts.RevisionQuantization),
 		postgres.MaxRevisionStalenessPercent(opts.MaxRevisionStalenessPercent),
@@ -414,6 +657,7 @@ func newPostgresDatastore(ctx context.Context, opts Config) (datastore.Datastore
 		postgres.WithEnablePrometheusStats(opts.EnableDatastoreMetrics),
 		postgres.MaxRetries(uint8(opts.MaxRetries)),
 		postgres.MigrationPhase(opts.MigrationPhase),
+		postgres.QueryPlanLogInterval(opts.QueryPlanLogInterval),
 	}
 	return postgres.NewPostgresDatastore(ctx, opts.URI, pgOpts...)
 }
@@ -439,9 +683,15 @@ func newSpannerDatastore(ctx context.Context, opts Config) (datastore.Datastore,
 }
 
 func newMySQLDatastore(ctx context.Context, opts Config) (datastore.Datastore, error) {
+	gcWindowOverrides, err := opts.gcWindowOverrides()
+	if err != nil {
+		return nil, err
+	}
+
 	mysqlOpts := []mysql.Option{
 		mysql.GCInterval(opts.GCInterval),
 		mysql.GCWindow(opts.GCWindow),
+		mysql.GCWindowOverrides(gcWindowOverrides),
 		mysql.GCInterval(opts.GCInterval),
 		mysql.GCEnabled(!opts.ReadOnly),
 		mysql.GCMaxOperationTime(opts.GCMaxOperationTime),
@@ -456,11 +706,21 @@ func newMySQLDatastore(ctx context.Context, opts Config) (datastore.Datastore, e
 		mysql.WithEnablePrometheusStats(opts.EnableDatastoreMetrics),
 		mysql.MaxRetries(uint8(opts.MaxRetries)),
 		mysql.OverrideLockWaitTimeout(1),
+		mysql.QueryPlanLogInterval(opts.QueryPlanLogInterval),
 	}
 	return mysql.NewMySQLDatastore(ctx, opts.URI, mysqlOpts...)
 }
 
-func newMemoryDatstore(_ context.Context, opts Config) (datastore.Datastore, error) {
-	log.Warn().Msg("in-memory datastore is not persistent and not feasible to run in a high availability fashion")
-	return memdb.NewMemdbDatastore(opts.WatchBufferLength, opts.RevisionQuantization, opts.GCWindow)
+func newMemoryDatstore(ctx context.Context, opts Config) (datastore.Datastore, error) {
+	ds, err := memdb.NewMemdbDatastore(opts.WatchBufferLength, opts.RevisionQuantization, opts.GCWindow, memdb.MaxClockSkew(opts.MaxClockSkew))
+	if err != nil {
+		return nil, err
+	}
+	if opts.MemoryDataPath == "" {
+		log.Warn().Msg("in-memory datastore is not persistent and not feasible to run in a high availability fashion")
+		return ds, nil
+	}
+
+	log.Warn().Str("path", opts.MemoryDataPath).Msg("in-memory datastore is only persisted when the server stops and is not feasible to run in a high availability fashion")
+	return newPersistentMemoryDatastore(ctx, ds, opts.MemoryDataPath)
 }
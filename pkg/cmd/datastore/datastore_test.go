@@ -1,12 +1,19 @@
 package datastore
 
 import (
+	"bytes"
 	"context"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/datastore"
 )
 
 func TestDefaults(t *testing.T) {
@@ -18,6 +25,45 @@ func TestDefaults(t *testing.T) {
 	require.Equal(t, expected, *received)
 }
 
+func TestEngineCompletion(t *testing.T) {
+	rootCmd := &cobra.Command{Use: "spicedb"}
+	cmd := &cobra.Command{Use: "serve", Run: func(*cobra.Command, []string) {}}
+	require.NoError(t, RegisterDatastoreFlags(cmd, &Config{}))
+	rootCmd.AddCommand(cmd)
+
+	var out bytes.Buffer
+	rootCmd.SetOut(&out)
+	rootCmd.SetArgs([]string{cobra.ShellCompRequestCmd, "serve", "--datastore-engine", ""})
+	require.NoError(t, rootCmd.Execute())
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Equal(t, append(datastore.SortedEngineIDs(), ":4"), lines)
+}
+
+func TestValidateRevisionTokenGuarantees(t *testing.T) {
+	for _, engine := range []string{MemoryEngine, PostgresEngine, MySQLEngine, SpannerEngine, CockroachEngine} {
+		require.NoError(t, (&Config{Engine: engine, OverlapStrategy: "static"}).ValidateRevisionTokenGuarantees(), engine)
+	}
+
+	require.ErrorContains(t, (&Config{Engine: CockroachEngine, OverlapStrategy: "insecure"}).ValidateRevisionTokenGuarantees(), "insecure transaction overlap strategy")
+	require.ErrorContains(t, (&Config{Engine: "unknown"}).ValidateRevisionTokenGuarantees(), "are unknown")
+}
+
+func TestGCWindowOverrides(t *testing.T) {
+	overrides, err := (&Config{GCWindowOverrides: map[string]string{"audit": "2160h", "session": "1h"}}).gcWindowOverrides()
+	require.NoError(t, err)
+	require.Equal(t, map[string]time.Duration{"audit": 2160 * time.Hour, "session": time.Hour}, overrides)
+
+	_, err = (&Config{GCWindowOverrides: map[string]string{"session": "soon"}}).gcWindowOverrides()
+	require.ErrorContains(t, err, "invalid GC window for namespace session")
+
+	_, err = (&Config{GCWindowOverrides: map[string]string{"session": "-1h"}}).gcWindowOverrides()
+	require.ErrorContains(t, err, "must be positive")
+
+	_, err = NewDatastore(context.Background(), WithEngine(MemoryEngine), WithGCWindowOverrides("session", "1h"))
+	require.ErrorContains(t, err, "not supported by the memory datastore engine")
+}
+
 func TestLoadDatastoreFromFileContents(t *testing.T) {
 	ctx := context.Background()
 	ds, err := NewDatastore(ctx,
@@ -78,3 +124,66 @@ func TestLoadDatastoreFromFileAndContents(t *testing.T) {
 	require.Contains(t, namespaceNames, "user")
 	require.Contains(t, namespaceNames, "repository")
 }
+
+func TestRelationshipIntegrityKeys(t *testing.T) {
+	dir := t.TempDir()
+	currentKeyFile := filepath.Join(dir, "current")
+	require.NoError(t, os.WriteFile(currentKeyFile, []byte("current secret"), 0o600))
+	expiredKeyFile := filepath.Join(dir, "expired")
+	require.NoError(t, os.WriteFile(expiredKeyFile, []byte("expired secret"), 0o600))
+
+	ctx := context.Background()
+	_, err := NewDatastore(ctx,
+		WithEngine(MemoryEngine),
+		WithRelationshipIntegrityEnabled(true),
+		WithRelationshipIntegrityCurrentKeyID("current"),
+		WithRelationshipIntegrityCurrentKeyFilename(currentKeyFile),
+		WithRelationshipIntegrityExpiredKeys(`{"key_id": "expired", "key_filename": "`+expiredKeyFile+`", "expired_at": "2024-01-02T15:04:05Z"}`),
+	)
+	require.NoError(t, err)
+
+	_, err = NewDatastore(ctx,
+		WithEngine(MemoryEngine),
+		WithRelationshipIntegrityEnabled(true),
+		WithRelationshipIntegrityCurrentKeyID("current"),
+	)
+	require.ErrorContains(t, err, "a current key file must be given")
+
+	_, err = NewDatastore(ctx,
+		WithEngine(MemoryEngine),
+		WithRelationshipIntegrityEnabled(true),
+		WithRelationshipIntegrityCurrentKeyID("current"),
+		WithRelationshipIntegrityCurrentKeyFilename(currentKeyFile),
+		WithRelationshipIntegrityExpiredKeys(`{"key_id": "expired", "key_filename": "`+expiredKeyFile+`"}`),
+	)
+	require.ErrorContains(t, err, "must have an expiration time")
+}
+
+func TestMemoryDataPath(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spicedb", "memory.backup")
+	ctx := context.Background()
+
+	// An empty datastore is saved and restored.
+	ds, err := NewDatastore(ctx, WithEngine(MemoryEngine), WithMemoryDataPath(path))
+	require.NoError(t, err)
+	require.NoError(t, ds.Close())
+
+	ds, err = NewDatastore(ctx,
+		WithEngine(MemoryEngine),
+		WithMemoryDataPath(path),
+		SetBootstrapFileContents(map[string][]byte{"test": []byte("schema: definition user{}")}))
+	require.NoError(t, err)
+	require.NoError(t, ds.Close())
+
+	ds, err = NewDatastore(ctx, WithEngine(MemoryEngine), WithMemoryDataPath(path))
+	require.NoError(t, err)
+	defer ds.Close()
+
+	revision, err := ds.HeadRevision(ctx)
+	require.NoError(t, err)
+
+	namespaces, err := ds.SnapshotReader(revision).ListAllNamespaces(ctx)
+	require.NoError(t, err)
+	require.Len(t, namespaces, 1)
+	require.Equal(t, "user", namespaces[0].Definition.Name)
+}
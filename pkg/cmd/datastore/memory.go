@@ -0,0 +1,106 @@
+package datastore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/authzed/spicedb/internal/backup"
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+// DefaultMemoryDataPath returns the platform's conventional location for the data of the
+// memory datastore of the current user: under %LocalAppData% on Windows, Application Support
+// on macOS, and $XDG_DATA_HOME (defaulting to ~/.local/share) elsewhere.
+func DefaultMemoryDataPath() (string, error) {
+	var dir string
+	switch runtime.GOOS {
+	case "windows":
+		dir = os.Getenv("LocalAppData")
+		if dir == "" {
+			return "", errors.New("%LocalAppData% is not defined")
+		}
+	case "darwin", "ios":
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", err
+		}
+		dir = filepath.Join(home, "Library", "Application Support")
+	default:
+		dir = os.Getenv("XDG_DATA_HOME")
+		if dir == "" {
+			home, err := os.UserHomeDir()
+			if err != nil {
+				return "", err
+			}
+			dir = filepath.Join(home, ".local", "share")
+		}
+	}
+	return filepath.Join(dir, "spicedb", "memory.backup"), nil
+}
+
+// persistentMemoryDatastore saves the contents of a memory datastore to a backup file when it
+// is closed.
+type persistentMemoryDatastore struct {
+	datastore.Datastore
+	path string
+}
+
+// newPersistentMemoryDatastore restores the memory datastore from the backup file at the path,
+// if it exists, and returns it wrapped so that it is saved there when closed.
+func newPersistentMemoryDatastore(ctx context.Context, ds datastore.Datastore, path string) (datastore.Datastore, error) {
+	f, err := os.Open(path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		log.Ctx(ctx).Info().Str("path", path).Msg("no in-memory datastore data to restore")
+	case err != nil:
+		return nil, fmt.Errorf("failed to open in-memory datastore data: %w", err)
+	default:
+		defer f.Close()
+		stats, err := backup.Restore(ctx, ds, f, backup.Options{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to restore in-memory datastore data from %s: %w", path, err)
+		}
+		log.Ctx(ctx).Info().Str("path", path).Uint64("relationships", stats.Relationships).Msg("restored in-memory datastore data")
+	}
+
+	return &persistentMemoryDatastore{Datastore: ds, path: path}, nil
+}
+
+func (pds *persistentMemoryDatastore) Unwrap() datastore.Datastore {
+	return pds.Datastore
+}
+
+func (pds *persistentMemoryDatastore) Close() error {
+	return errors.Join(pds.save(), pds.Datastore.Close())
+}
+
+// save writes the backup to a temporary file which then replaces the previous one, so that an
+// interrupted save does not lose the data.
+func (pds *persistentMemoryDatastore) save() error {
+	if err := os.MkdirAll(filepath.Dir(pds.path), 0o700); err != nil {
+		return fmt.Errorf("failed to create directory of in-memory datastore data: %w", err)
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(pds.path), filepath.Base(pds.path)+".*")
+	if err != nil {
+		return fmt.Errorf("failed to save in-memory datastore data: %w", err)
+	}
+	defer os.Remove(f.Name())
+
+	stats, err := backup.Write(context.Background(), pds.Datastore, f, backup.Options{Compress: true})
+	if err := errors.Join(err, f.Close()); err != nil {
+		return fmt.Errorf("failed to save in-memory datastore data: %w", err)
+	}
+	if err := os.Rename(f.Name(), pds.path); err != nil {
+		return fmt.Errorf("failed to save in-memory datastore data: %w", err)
+	}
+
+	log.Info().Str("path", pds.path).Uint64("relationships", stats.Relationships).Msg("saved in-memory datastore data")
+	return nil
+}
@@ -50,6 +50,15 @@ func (c *Config) ToOption() ConfigOption {
 		to.RequestHedgingInitialSlowValue = c.RequestHedgingInitialSlowValue
 		to.RequestHedgingMaxRequests = c.RequestHedgingMaxRequests
 		to.RequestHedgingQuantile = c.RequestHedgingQuantile
+		to.RetryMaxRetries = c.RetryMaxRetries
+		to.RetryInitialBackoff = c.RetryInitialBackoff
+		to.RetryMaxBackoff = c.RetryMaxBackoff
+		to.RetryBudgetRatio = c.RetryBudgetRatio
+		to.CircuitBreakerFailureThreshold = c.CircuitBreakerFailureThreshold
+		to.CircuitBreakerOpenDuration = c.CircuitBreakerOpenDuration
+		to.CircuitBreakerServeStaleReads = c.CircuitBreakerServeStaleReads
+		to.MaxClockSkew = c.MaxClockSkew
+		to.ClockSkewCheckInterval = c.ClockSkewCheckInterval
 		to.FollowerReadDelay = c.FollowerReadDelay
 		to.MaxRetries = c.MaxRetries
 		to.OverlapKey = c.OverlapKey
@@ -58,11 +67,18 @@ func (c *Config) ToOption() ConfigOption {
 		to.ConnectRate = c.ConnectRate
 		to.GCInterval = c.GCInterval
 		to.GCMaxOperationTime = c.GCMaxOperationTime
+		to.GCWindowOverrides = c.GCWindowOverrides
+		to.QueryPlanLogInterval = c.QueryPlanLogInterval
 		to.SpannerCredentialsFile = c.SpannerCredentialsFile
 		to.SpannerEmulatorHost = c.SpannerEmulatorHost
 		to.SpannerMinSessions = c.SpannerMinSessions
 		to.SpannerMaxSessions = c.SpannerMaxSessions
 		to.TablePrefix = c.TablePrefix
+		to.MemoryDataPath = c.MemoryDataPath
+		to.RelationshipIntegrityEnabled = c.RelationshipIntegrityEnabled
+		to.RelationshipIntegrityCurrentKeyID = c.RelationshipIntegrityCurrentKeyID
+		to.RelationshipIntegrityCurrentKeyFilename = c.RelationshipIntegrityCurrentKeyFilename
+		to.RelationshipIntegrityExpiredKeys = c.RelationshipIntegrityExpiredKeys
 		to.WatchBufferLength = c.WatchBufferLength
 		to.WatchBufferWriteTimeout = c.WatchBufferWriteTimeout
 		to.MigrationPhase = c.MigrationPhase
@@ -91,6 +107,15 @@ func (c Config) DebugMap() map[string]any {
 	debugMap["RequestHedgingInitialSlowValue"] = helpers.DebugValue(c.RequestHedgingInitialSlowValue, false)
 	debugMap["RequestHedgingMaxRequests"] = helpers.DebugValue(c.RequestHedgingMaxRequests, false)
 	debugMap["RequestHedgingQuantile"] = helpers.DebugValue(c.RequestHedgingQuantile, false)
+	debugMap["RetryMaxRetries"] = helpers.DebugValue(c.RetryMaxRetries, false)
+	debugMap["RetryInitialBackoff"] = helpers.DebugValue(c.RetryInitialBackoff, false)
+	debugMap["RetryMaxBackoff"] = helpers.DebugValue(c.RetryMaxBackoff, false)
+	debugMap["RetryBudgetRatio"] = helpers.DebugValue(c.RetryBudgetRatio, false)
+	debugMap["CircuitBreakerFailureThreshold"] = helpers.DebugValue(c.CircuitBreakerFailureThreshold, false)
+	debugMap["CircuitBreakerOpenDuration"] = helpers.DebugValue(c.CircuitBreakerOpenDuration, false)
+	debugMap["CircuitBreakerServeStaleReads"] = helpers.DebugValue(c.CircuitBreakerServeStaleReads, false)
+	debugMap["MaxClockSkew"] = helpers.DebugValue(c.MaxClockSkew, false)
+	debugMap["ClockSkewCheckInterval"] = helpers.DebugValue(c.ClockSkewCheckInterval, false)
 	debugMap["FollowerReadDelay"] = helpers.DebugValue(c.FollowerReadDelay, false)
 	debugMap["MaxRetries"] = helpers.DebugValue(c.MaxRetries, false)
 	debugMap["OverlapKey"] = helpers.DebugValue(c.OverlapKey, false)
@@ -99,11 +124,18 @@ func (c Config) DebugMap() map[string]any {
 	debugMap["ConnectRate"] = helpers.DebugValue(c.ConnectRate, false)
 	debugMap["GCInterval"] = helpers.DebugValue(c.GCInterval, false)
 	debugMap["GCMaxOperationTime"] = helpers.DebugValue(c.GCMaxOperationTime, false)
+	debugMap["GCWindowOverrides"] = helpers.DebugValue(c.GCWindowOverrides, false)
+	debugMap["QueryPlanLogInterval"] = helpers.DebugValue(c.QueryPlanLogInterval, false)
 	debugMap["SpannerCredentialsFile"] = helpers.DebugValue(c.SpannerCredentialsFile, false)
 	debugMap["SpannerEmulatorHost"] = helpers.DebugValue(c.SpannerEmulatorHost, false)
 	debugMap["SpannerMinSessions"] = helpers.DebugValue(c.SpannerMinSessions, false)
 	debugMap["SpannerMaxSessions"] = helpers.DebugValue(c.SpannerMaxSessions, false)
 	debugMap["TablePrefix"] = helpers.DebugValue(c.TablePrefix, false)
+	debugMap["MemoryDataPath"] = helpers.DebugValue(c.MemoryDataPath, false)
+	debugMap["RelationshipIntegrityEnabled"] = helpers.DebugValue(c.RelationshipIntegrityEnabled, false)
+	debugMap["RelationshipIntegrityCurrentKeyID"] = helpers.DebugValue(c.RelationshipIntegrityCurrentKeyID, false)
+	debugMap["RelationshipIntegrityCurrentKeyFilename"] = helpers.DebugValue(c.RelationshipIntegrityCurrentKeyFilename, false)
+	debugMap["RelationshipIntegrityExpiredKeys"] = helpers.DebugValue(c.RelationshipIntegrityExpiredKeys, true)
 	debugMap["WatchBufferLength"] = helpers.DebugValue(c.WatchBufferLength, false)
 	debugMap["WatchBufferWriteTimeout"] = helpers.DebugValue(c.WatchBufferWriteTimeout, false)
 	debugMap["MigrationPhase"] = helpers.DebugValue(c.MigrationPhase, false)
@@ -273,6 +305,69 @@ func WithRequestHedgingQuantile(requestHedgingQuantile float64) ConfigOption {
 	}
 }
 
+// WithRetryMaxRetries returns an option that can set RetryMaxRetries on a Config
+func WithRetryMaxRetries(retryMaxRetries uint8) ConfigOption {
+	return func(c *Config) {
+		c.RetryMaxRetries = retryMaxRetries
+	}
+}
+
+// WithRetryInitialBackoff returns an option that can set RetryInitialBackoff on a Config
+func WithRetryInitialBackoff(retryInitialBackoff time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.RetryInitialBackoff = retryInitialBackoff
+	}
+}
+
+// WithRetryMaxBackoff returns an option that can set RetryMaxBackoff on a Config
+func WithRetryMaxBackoff(retryMaxBackoff time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.RetryMaxBackoff = retryMaxBackoff
+	}
+}
+
+// WithRetryBudgetRatio returns an option that can set RetryBudgetRatio on a Config
+func WithRetryBudgetRatio(retryBudgetRatio float64) ConfigOption {
+	return func(c *Config) {
+		c.RetryBudgetRatio = retryBudgetRatio
+	}
+}
+
+// WithCircuitBreakerFailureThreshold returns an option that can set CircuitBreakerFailureThreshold on a Config
+func WithCircuitBreakerFailureThreshold(circuitBreakerFailureThreshold uint16) ConfigOption {
+	return func(c *Config) {
+		c.CircuitBreakerFailureThreshold = circuitBreakerFailureThreshold
+	}
+}
+
+// WithCircuitBreakerOpenDuration returns an option that can set CircuitBreakerOpenDuration on a Config
+func WithCircuitBreakerOpenDuration(circuitBreakerOpenDuration time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.CircuitBreakerOpenDuration = circuitBreakerOpenDuration
+	}
+}
+
+// WithCircuitBreakerServeStaleReads returns an option that can set CircuitBreakerServeStaleReads on a Config
+func WithCircuitBreakerServeStaleReads(circuitBreakerServeStaleReads bool) ConfigOption {
+	return func(c *Config) {
+		c.CircuitBreakerServeStaleReads = circuitBreakerServeStaleReads
+	}
+}
+
+// WithMaxClockSkew returns an option that can set MaxClockSkew on a Config
+func WithMaxClockSkew(maxClockSkew time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.MaxClockSkew = maxClockSkew
+	}
+}
+
+// WithClockSkewCheckInterval returns an option that can set ClockSkewCheckInterval on a Config
+func WithClockSkewCheckInterval(clockSkewCheckInterval time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.ClockSkewCheckInterval = clockSkewCheckInterval
+	}
+}
+
 // WithFollowerReadDelay returns an option that can set FollowerReadDelay on a Config
 func WithFollowerReadDelay(followerReadDelay time.Duration) ConfigOption {
 	return func(c *Config) {
@@ -329,6 +424,27 @@ func WithGCMaxOperationTime(gCMaxOperationTime time.Duration) ConfigOption {
 	}
 }
 
+// WithGCWindowOverrides returns an option that can append GCWindowOverridess to Config.GCWindowOverrides
+func WithGCWindowOverrides(key string, value string) ConfigOption {
+	return func(c *Config) {
+		c.GCWindowOverrides[key] = value
+	}
+}
+
+// SetGCWindowOverrides returns an option that can set GCWindowOverrides on a Config
+func SetGCWindowOverrides(gCWindowOverrides map[string]string) ConfigOption {
+	return func(c *Config) {
+		c.GCWindowOverrides = gCWindowOverrides
+	}
+}
+
+// WithQueryPlanLogInterval returns an option that can set QueryPlanLogInterval on a Config
+func WithQueryPlanLogInterval(queryPlanLogInterval time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.QueryPlanLogInterval = queryPlanLogInterval
+	}
+}
+
 // WithSpannerCredentialsFile returns an option that can set SpannerCredentialsFile on a Config
 func WithSpannerCredentialsFile(spannerCredentialsFile string) ConfigOption {
 	return func(c *Config) {
@@ -364,6 +480,48 @@ func WithTablePrefix(tablePrefix string) ConfigOption {
 	}
 }
 
+// WithMemoryDataPath returns an option that can set MemoryDataPath on a Config
+func WithMemoryDataPath(memoryDataPath string) ConfigOption {
+	return func(c *Config) {
+		c.MemoryDataPath = memoryDataPath
+	}
+}
+
+// WithRelationshipIntegrityEnabled returns an option that can set RelationshipIntegrityEnabled on a Config
+func WithRelationshipIntegrityEnabled(relationshipIntegrityEnabled bool) ConfigOption {
+	return func(c *Config) {
+		c.RelationshipIntegrityEnabled = relationshipIntegrityEnabled
+	}
+}
+
+// WithRelationshipIntegrityCurrentKeyID returns an option that can set RelationshipIntegrityCurrentKeyID on a Config
+func WithRelationshipIntegrityCurrentKeyID(relationshipIntegrityCurrentKeyID string) ConfigOption {
+	return func(c *Config) {
+		c.RelationshipIntegrityCurrentKeyID = relationshipIntegrityCurrentKeyID
+	}
+}
+
+// WithRelationshipIntegrityCurrentKeyFilename returns an option that can set RelationshipIntegrityCurrentKeyFilename on a Config
+func WithRelationshipIntegrityCurrentKeyFilename(relationshipIntegrityCurrentKeyFilename string) ConfigOption {
+	return func(c *Config) {
+		c.RelationshipIntegrityCurrentKeyFilename = relationshipIntegrityCurrentKeyFilename
+	}
+}
+
+// WithRelationshipIntegrityExpiredKeys returns an option that can append RelationshipIntegrityExpiredKeyss to Config.RelationshipIntegrityExpiredKeys
+func WithRelationshipIntegrityExpiredKeys(relationshipIntegrityExpiredKeys string) ConfigOption {
+	return func(c *Config) {
+		c.RelationshipIntegrityExpiredKeys = append(c.RelationshipIntegrityExpiredKeys, relationshipIntegrityExpiredKeys)
+	}
+}
+
+// SetRelationshipIntegrityExpiredKeys returns an option that can set RelationshipIntegrityExpiredKeys on a Config
+func SetRelationshipIntegrityExpiredKeys(relationshipIntegrityExpiredKeys []string) ConfigOption {
+	return func(c *Config) {
+		c.RelationshipIntegrityExpiredKeys = relationshipIntegrityExpiredKeys
+	}
+}
+
 // WithWatchBufferLength returns an option that can set WatchBufferLength on a Config
 func WithWatchBufferLength(watchBufferLength uint16) ConfigOption {
 	return func(c *Config) {
@@ -25,6 +25,7 @@ import (
 	"google.golang.org/grpc/reflection"
 
 	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/playground"
 	v0svc "github.com/authzed/spicedb/internal/services/v0"
 	"github.com/authzed/spicedb/pkg/cmd/server"
 	"github.com/authzed/spicedb/pkg/cmd/termination"
@@ -34,6 +35,7 @@ func RegisterDevtoolsFlags(cmd *cobra.Command) {
 	grpcServiceBuilder().RegisterFlags(cmd.Flags())
 	httpMetricsServiceBuilder().RegisterFlags(cmd.Flags())
 	httpDownloadServiceBuilder().RegisterFlags(cmd.Flags())
+	httpPlaygroundServiceBuilder().RegisterFlags(cmd.Flags())
 
 	cmd.Flags().String("share-store", "inmemory", "kind of share store to use")
 	cmd.Flags().String("share-store-salt", "", "salt for share store hashing")
@@ -48,7 +50,7 @@ func NewDevtoolsCommand(programName string) *cobra.Command {
 	return &cobra.Command{
 		Use:     "serve-devtools",
 		Short:   "runs the developer tools service",
-		Long:    "Serves the authzed.api.v0.DeveloperService which is used for development tooling such as the Authzed Playground",
+		Long:    "Serves the authzed.api.v0.DeveloperService which is used for development tooling such as the Authzed Playground, and, with --playground-enabled, an interactive playground for editing a schema and relationships and running checks, expansions and graphs against them in an ephemeral in-memory datastore",
 		PreRunE: server.DefaultPreRunE(programName),
 		RunE:    termination.PublishError(runfunc),
 		Args:    cobra.ExactArgs(0),
@@ -98,6 +100,17 @@ func runfunc(cmd *cobra.Command, _ []string) error {
 			log.Ctx(cmd.Context()).Fatal().Err(err).Msg("failed while serving download http api")
 		}
 	}()
+
+	// start the interactive playground
+	playgroundHTTP := httpPlaygroundServiceBuilder()
+	playgroundSrv := playgroundHTTP.ServerFromFlags(cmd)
+	playgroundSrv.ReadHeaderTimeout = 5 * time.Second
+	go func() {
+		if err := playgroundHTTP.ListenFromFlags(cmd, playgroundSrv); err != nil {
+			log.Ctx(cmd.Context()).Warn().Err(err).Msg("playground http server did not shutdown cleanly")
+		}
+	}()
+
 	signalctx, _ := signal.NotifyContext(context.Background(), os.Interrupt)
 	<-signalctx.Done()
 	log.Ctx(cmd.Context()).Info().Msg("received interrupt")
@@ -110,6 +123,10 @@ func runfunc(cmd *cobra.Command, _ []string) error {
 		log.Ctx(cmd.Context()).Err(err).Msg("failed while shutting down download server")
 		return err
 	}
+	if err := playgroundSrv.Close(); err != nil {
+		log.Ctx(cmd.Context()).Err(err).Msg("failed while shutting down playground server")
+		return err
+	}
 
 	return nil
 }
@@ -121,11 +138,20 @@ func httpDownloadServiceBuilder(option ...cobrahttp.Option) *cobrahttp.Builder {
 	return cobrahttp.New("download", option...)
 }
 
+func httpPlaygroundServiceBuilder() *cobrahttp.Builder {
+	return cobrahttp.New("playground",
+		cobrahttp.WithLogger(zerologr.New(&log.Logger)),
+		cobrahttp.WithFlagPrefix("playground"),
+		cobrahttp.WithDefaultAddress(":8444"),
+		cobrahttp.WithHandler(playground.NewHandler()),
+	)
+}
+
 func httpMetricsServiceBuilder() *cobrahttp.Builder {
 	return cobrahttp.New("metrics",
 		cobrahttp.WithLogger(zerologr.New(&log.Logger)),
 		cobrahttp.WithFlagPrefix("metrics"),
-		cobrahttp.WithHandler(server.MetricsHandler(server.DisableTelemetryHandler, nil)),
+		cobrahttp.WithHandler(server.MetricsHandler(server.DisableTelemetryHandler, nil, nil)),
 	)
 }
 
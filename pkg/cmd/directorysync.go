@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/jzelinskie/cobrautil/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/authzed/spicedb/internal/directorysync"
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/cmd/server"
+	"github.com/authzed/spicedb/pkg/cmd/termination"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+func RegisterDirectorySyncFlags(cmd *cobra.Command) {
+	registerConnectionFlags(cmd)
+	cmd.Flags().String("group-type", "group", "object type of groups")
+	cmd.Flags().String("relation", "member", "relation of groups to their members, including nested groups")
+	cmd.Flags().String("user-type", "user", "object type of users")
+	cmd.Flags().Bool("dry-run", false, "print the changes without writing them")
+	cmd.Flags().Int("batch-size", 1000, "maximum number of relationships written per request")
+	cmd.Flags().String("scim-token", "", "bearer token with which to authenticate to the SCIM API")
+	cmd.Flags().Duration("scim-timeout", 30*time.Second, "timeout of each request to the SCIM API")
+	cmd.Flags().Duration("interval", 0, "interval at which the directory is synced again; zero syncs once and exits")
+}
+
+func NewDirectorySyncCommand(programName string) *cobra.Command {
+	return &cobra.Command{
+		Use:   "directory-sync <scim|csv|jsonl> <url|file>",
+		Short: "syncs group memberships from a directory to a running server",
+		Long: "Reads the group memberships of a directory, from the base URL of a SCIM 2.0 API or from a CSV (`group,member`) or JSONL (`{\"group\": ..., \"member\": ...}`) file, and reconciles them into relationships of the group type and relation on a running server. " +
+			"Members prefixed with `group:` in CSV and JSONL files are nested groups. Relationships without a membership are deleted, except those whose subject is neither a user nor a group member. " +
+			"The changes are printed as `+` and `-` lines.",
+		PreRunE:   server.DefaultPreRunE(programName),
+		Args:      cobra.ExactArgs(2),
+		ValidArgs: []string{"scim", "csv", "jsonl"},
+		RunE:      termination.PublishError(directorySyncRun),
+	}
+}
+
+func directorySyncRun(cmd *cobra.Command, args []string) error {
+	client, err := newClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	config := directorysync.Config{
+		GroupType: cobrautil.MustGetString(cmd, "group-type"),
+		Relation:  cobrautil.MustGetString(cmd, "relation"),
+		UserType:  cobrautil.MustGetString(cmd, "user-type"),
+		BatchSize: cobrautil.MustGetInt(cmd, "batch-size"),
+		DryRun:    cobrautil.MustGetBool(cmd, "dry-run"),
+	}
+
+	interval := cobrautil.MustGetDuration(cmd, "interval")
+	for {
+		err := syncDirectory(cmd, client, args[0], args[1], config)
+		if interval <= 0 {
+			return err
+		}
+		if err != nil {
+			// Syncing periodically, failures are retried at the next interval.
+			log.Ctx(cmd.Context()).Error().Err(err).Msg("failed to sync directory")
+		}
+
+		select {
+		case <-cmd.Context().Done():
+			return nil
+		case <-time.After(interval):
+		}
+	}
+}
+
+func syncDirectory(cmd *cobra.Command, client v1.PermissionsServiceClient, source, location string, config directorysync.Config) error {
+	memberships, err := readDirectory(cmd, source, location)
+	if err != nil {
+		return err
+	}
+
+	result, err := directorysync.Reconcile(cmd.Context(), client, memberships, config)
+	if err != nil {
+		return err
+	}
+	printDirectorySyncResult(cmd, result, config.DryRun)
+	return nil
+}
+
+func readDirectory(cmd *cobra.Command, source, location string) ([]directorysync.Membership, error) {
+	switch source {
+	case "scim":
+		httpClient := &http.Client{Timeout: cobrautil.MustGetDuration(cmd, "scim-timeout")}
+		return directorysync.ReadSCIM(cmd.Context(), httpClient, location, cobrautil.MustGetString(cmd, "scim-token"))
+
+	case "csv", "jsonl":
+		file, err := os.Open(location)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+
+		if source == "csv" {
+			return directorysync.ReadCSV(file)
+		}
+		return directorysync.ReadJSONL(file)
+
+	default:
+		return nil, fmt.Errorf("unknown directory source `%s`: expected scim, csv or jsonl", source)
+	}
+}
+
+func printDirectorySyncResult(cmd *cobra.Command, result *directorysync.Result, dryRun bool) {
+	for _, skipped := range result.Skipped {
+		fmt.Fprintln(cmd.ErrOrStderr(), skipped)
+	}
+
+	out := cmd.OutOrStdout()
+	for _, rel := range result.Added {
+		fmt.Fprintf(out, "+ %s\n", tuple.MustStringRelationship(rel))
+	}
+	for _, rel := range result.Removed {
+		fmt.Fprintf(out, "- %s\n", tuple.MustStringRelationship(rel))
+	}
+
+	summary := fmt.Sprintf("%d added, %d removed, %d skipped", len(result.Added), len(result.Removed), len(result.Skipped))
+	if dryRun {
+		summary += " (dry run; nothing was written)"
+	}
+	fmt.Fprintln(cmd.ErrOrStderr(), summary)
+}
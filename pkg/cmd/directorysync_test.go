@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDirectorySyncCommand(t *testing.T) {
+	fake, addr := startFakeServer(t)
+
+	csvPath := filepath.Join(t.TempDir(), "groups.csv")
+	require.NoError(t, os.WriteFile(csvPath, []byte("first,anne\n"), 0o600))
+
+	cmd := NewDirectorySyncCommand("spicedb")
+	var summary bytes.Buffer
+	cmd.SetErr(&summary)
+	out := runClientCommand(t, cmd, RegisterDirectorySyncFlags, addr,
+		"csv", csvPath, "--group-type", "document", "--relation", "viewer", "--dry-run")
+
+	// Relationships whose subject is neither a user nor a group member are left alone.
+	require.Equal(t, "+ document:first#viewer@user:anne\n- document:first#viewer@user:tom\n", out)
+	require.Equal(t, "1 added, 1 removed, 0 skipped (dry run; nothing was written)\n", summary.String())
+	require.Equal(t, "document", fake.readRequest.RelationshipFilter.ResourceType)
+	require.Equal(t, "viewer", fake.readRequest.RelationshipFilter.OptionalRelation)
+}
@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/jzelinskie/cobrautil/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/authzed/spicedb/pkg/cmd/server"
+	"github.com/authzed/spicedb/pkg/cmd/termination"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+	"github.com/authzed/spicedb/pkg/schemautil"
+)
+
+func RegisterDocsFlags(cmd *cobra.Command) {
+	registerConnectionFlags(cmd)
+	cmd.Flags().String("format", "markdown", `format of the documentation ("markdown", "html")`)
+}
+
+func NewDocsCommand(programName string) *cobra.Command {
+	return &cobra.Command{
+		Use:     "docs [schema-or-validation-file]",
+		Short:   "generates documentation of a schema",
+		Long:    "Generates documentation of the definitions, relations, permissions, allowed subject types and caveats of a schema, along with a graph of the relationships between definitions. The schema is read from the file, if given, and otherwise from a running server. Files with a .yaml or .yml extension are read as validation files.",
+		PreRunE: server.DefaultPreRunE(programName),
+		Args:    cobra.MaximumNArgs(1),
+		RunE:    termination.PublishError(docsRun),
+	}
+}
+
+func docsRun(cmd *cobra.Command, args []string) error {
+	format := cobrautil.MustGetString(cmd, "format")
+	if format != "markdown" && format != "html" {
+		return fmt.Errorf("unknown format `%s`: expected markdown or html", format)
+	}
+
+	var compiled *compiler.CompiledSchema
+	if len(args) == 1 {
+		var err error
+		compiled, err = compileSchemaFile(args[0])
+		if err != nil {
+			return fmt.Errorf("failed to read schema from %s: %w", args[0], err)
+		}
+	} else {
+		client, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		resp, err := client.ReadSchema(cmd.Context(), &v1.ReadSchemaRequest{})
+		if err != nil {
+			return fmt.Errorf("failed to read schema: %w", err)
+		}
+
+		compiled, err = compiler.Compile(compiler.InputSchema{
+			Source:       input.Source("schema"),
+			SchemaString: resp.SchemaText,
+		}, compiler.AllowUnprefixedObjectType())
+		if err != nil {
+			return fmt.Errorf("failed to compile schema: %w", err)
+		}
+	}
+
+	reflection := schemautil.ReflectCompiledSchema(compiled)
+	out := cmd.OutOrStdout()
+	if format == "html" {
+		html, err := schemautil.GenerateHTMLDocs(reflection)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprint(out, html)
+		return err
+	}
+
+	_, err := fmt.Fprintln(out, schemautil.GenerateMarkdownDocs(reflection))
+	return err
+}
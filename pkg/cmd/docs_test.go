@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocsCommand(t *testing.T) {
+	dir := t.TempDir()
+	schemaPath := filepath.Join(dir, "schema.zed")
+	require.NoError(t, os.WriteFile(schemaPath, []byte(`definition user {}
+
+definition document {
+  relation viewer: user
+  permission view = viewer
+}`), 0o600))
+
+	validationPath := filepath.Join(dir, "validation.yaml")
+	require.NoError(t, os.WriteFile(validationPath, []byte(`schema: |-
+  definition user {}
+
+  definition document {
+    relation viewer: user:*
+    permission view = viewer
+  }
+`), 0o600))
+
+	tcs := []struct {
+		name           string
+		args           []string
+		expectedError  string
+		expectedOutput string
+	}{
+		{"markdown", []string{schemaPath}, "", "| `view` | `viewer` |  |\n"},
+		{"validation file", []string{validationPath}, "", "| `viewer` | `user:*` |  |\n"},
+		{"graph", []string{schemaPath}, "", "document -->|\"viewer\"| user\n"},
+		{"html", []string{"--format", "html", schemaPath}, "", "<tr><td><code>view</code></td><td><code>viewer</code></td>"},
+		{"unknown format", []string{"--format", "pdf", schemaPath}, "unknown format `pdf`", ""},
+		{"missing file", []string{filepath.Join(dir, "missing.zed")}, "failed to read schema", ""},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := NewDocsCommand("spicedb")
+			RegisterRootFlags(cmd)
+			RegisterDocsFlags(cmd)
+			cmd.SilenceUsage = true
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+			cmd.SetArgs(tc.args)
+
+			err := cmd.Execute()
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+
+			if tc.expectedOutput == "" {
+				require.Empty(t, out.String())
+			} else {
+				require.Contains(t, out.String(), tc.expectedOutput)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/authzed/spicedb/pkg/cmd/server"
+	"github.com/authzed/spicedb/pkg/cmd/termination"
+)
+
+// RegisterDoctorFlags registers the flags of the serve command, whose configuration the doctor
+// command verifies.
+func RegisterDoctorFlags(cmd *cobra.Command, config *server.Config) error {
+	if err := RegisterServeFlags(cmd, config); err != nil {
+		return err
+	}
+	for _, flag := range []string{"dry-run", "selftest"} {
+		if err := cmd.Flags().MarkHidden(flag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func NewDoctorCommand(programName string, config *server.Config) *cobra.Command {
+	return &cobra.Command{
+		Use:     "doctor",
+		Short:   "verify the configuration of the server",
+		Long:    "Verifies that the server can start with the given serve flags: datastore connectivity and migrations, clock skew against the datastore, TLS certificates and the reachability of dispatch peers.",
+		PreRunE: server.DefaultPreRunE(programName),
+		RunE: termination.PublishError(func(cmd *cobra.Command, args []string) error {
+			return runSelfTest(cmd.Context(), cmd.OutOrStdout(), config)
+		}),
+	}
+}
+
+// runSelfTest writes the report of the self-test of the configuration, returning an error if
+// any check failed.
+func runSelfTest(ctx context.Context, w io.Writer, config *server.Config) error {
+	report := config.SelfTest(ctx)
+	if err := report.Write(w); err != nil {
+		return err
+	}
+	if report.Failed() {
+		return errors.New("self-test failed")
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/jzelinskie/cobrautil/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/authzed/spicedb/pkg/client"
+	"github.com/authzed/spicedb/pkg/cmd/server"
+	"github.com/authzed/spicedb/pkg/cmd/termination"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+	"github.com/authzed/spicedb/pkg/schemautil"
+)
+
+func RegisterExportGraphFlags(cmd *cobra.Command) {
+	registerConnectionFlags(cmd)
+	cmd.Flags().Bool("fully-consistent", false, "read the relationships at the newest revision of the datastore, rather than minimizing latency")
+	cmd.Flags().String("format", "dot", `format of the graph ("dot", "json")`)
+	cmd.Flags().Int("depth", 3, "maximum number of relationships between the object and any subject in the graph")
+	cmd.Flags().Int("max-edges", 10_000, "maximum number of relationships in the graph, beyond which it is truncated; 0 for no limit")
+	cmd.Flags().String("permission", "", "if set, only follow the relationships through which this permission of the object could be computed")
+}
+
+func NewExportGraphCommand(programName string) *cobra.Command {
+	return &cobra.Command{
+		Use:     "export-graph <resource-type:resource-id>",
+		Short:   "exports the relationship graph of an object from a running server",
+		Long:    "Connects to a running server and exports the graph of the relationships reachable from the object, in the DOT language of Graphviz or as JSON. Each relationship is annotated with the permissions of its resource computed from its relation, and with --permission only those through which the permission could be computed are followed, to show why a subject has access.",
+		PreRunE: server.DefaultPreRunE(programName),
+		Args:    cobra.ExactArgs(1),
+		RunE:    termination.PublishError(exportGraphRun),
+	}
+}
+
+func exportGraphRun(cmd *cobra.Command, args []string) error {
+	format := cobrautil.MustGetString(cmd, "format")
+	if format != "dot" && format != "json" {
+		return fmt.Errorf("unknown format `%s`: expected dot or json", format)
+	}
+
+	root, err := client.ParseObject(args[0])
+	if err != nil {
+		return err
+	}
+
+	client, err := newClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	schemaResp, err := client.ReadSchema(cmd.Context(), &v1.ReadSchemaRequest{})
+	if err != nil {
+		return fmt.Errorf("failed to read schema: %w", err)
+	}
+
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source("schema"),
+		SchemaString: schemaResp.SchemaText,
+	}, compiler.AllowUnprefixedObjectType())
+	if err != nil {
+		return fmt.Errorf("failed to compile schema: %w", err)
+	}
+
+	reader := &snapshotRelationshipReader{client: client, consistency: consistency(cmd)}
+	graph, err := schemautil.BuildObjectGraph(cmd.Context(), compiled.ObjectDefinitions, root, reader.read, schemautil.ObjectGraphOptions{
+		MaxDepth:   cobrautil.MustGetInt(cmd, "depth"),
+		MaxEdges:   cobrautil.MustGetInt(cmd, "max-edges"),
+		Permission: cobrautil.MustGetString(cmd, "permission"),
+	})
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if format == "json" {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(graph)
+	}
+
+	_, err = fmt.Fprint(out, graph.DOT())
+	return err
+}
+
+// snapshotRelationshipReader reads the relationships of objects at the revision of the first
+// read, so that the graph is that of a single snapshot.
+type snapshotRelationshipReader struct {
+	client      *client.Client
+	consistency *v1.Consistency
+}
+
+func (r *snapshotRelationshipReader) read(ctx context.Context, object *v1.ObjectReference) ([]*v1.Relationship, error) {
+	stream, err := r.client.ReadRelationships(ctx, &v1.ReadRelationshipsRequest{
+		Consistency: r.consistency,
+		RelationshipFilter: &v1.RelationshipFilter{
+			ResourceType:       object.ObjectType,
+			OptionalResourceId: object.ObjectId,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var rels []*v1.Relationship
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return rels, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if r.consistency.GetAtExactSnapshot() == nil {
+			r.consistency = &v1.Consistency{Requirement: &v1.Consistency_AtExactSnapshot{AtExactSnapshot: resp.ReadAt}}
+		}
+		rels = append(rels, resp.Relationship)
+	}
+}
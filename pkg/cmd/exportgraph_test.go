@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+
+	"github.com/authzed/spicedb/internal/datastore/revisions"
+	"github.com/authzed/spicedb/pkg/schemautil"
+	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/zedtoken"
+)
+
+type fakeGraphServer struct {
+	v1.UnimplementedPermissionsServiceServer
+	v1.UnimplementedSchemaServiceServer
+
+	readRequests []*v1.ReadRelationshipsRequest
+}
+
+func (fgs *fakeGraphServer) ReadSchema(_ context.Context, _ *v1.ReadSchemaRequest) (*v1.ReadSchemaResponse, error) {
+	return &v1.ReadSchemaResponse{SchemaText: `definition user {}
+
+definition group {
+	relation member: user | group#member
+}
+
+definition document {
+	relation viewer: user | group#member
+	permission view = viewer
+}`}, nil
+}
+
+func (fgs *fakeGraphServer) ReadRelationships(req *v1.ReadRelationshipsRequest, stream v1.PermissionsService_ReadRelationshipsServer) error {
+	fgs.readRequests = append(fgs.readRequests, req)
+	for _, rel := range []string{"document:plan#viewer@group:eng#member", "group:eng#member@user:tom"} {
+		parsed := tuple.ParseRel(rel)
+		if parsed.Resource.ObjectType != req.RelationshipFilter.ResourceType || parsed.Resource.ObjectId != req.RelationshipFilter.OptionalResourceId {
+			continue
+		}
+
+		if err := stream.Send(&v1.ReadRelationshipsResponse{
+			ReadAt:       zedtoken.MustNewFromRevision(revisions.NewForTransactionID(42)),
+			Relationship: parsed,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func startFakeGraphServer(t *testing.T) (*fakeGraphServer, string) {
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	fake := &fakeGraphServer{}
+	srv := grpc.NewServer()
+	v1.RegisterPermissionsServiceServer(srv, fake)
+	v1.RegisterSchemaServiceServer(srv, fake)
+	go func() {
+		_ = srv.Serve(lis)
+	}()
+	t.Cleanup(srv.Stop)
+
+	return fake, lis.Addr().String()
+}
+
+func TestExportGraphCommand(t *testing.T) {
+	fake, addr := startFakeGraphServer(t)
+
+	out := runClientCommand(t, NewExportGraphCommand("spicedb"), RegisterExportGraphFlags, addr, "document:plan", "--permission", "view")
+	require.Contains(t, out, "\"document:plan\" -> \"group:eng\" [label=\"viewer (#member)\\n→ view\"];\n")
+	require.Contains(t, out, "\"group:eng\" -> \"user:tom\" [label=\"member\"];\n")
+
+	// The relationships after the first read are read at the same snapshot.
+	require.Len(t, fake.readRequests, 2)
+	require.True(t, fake.readRequests[0].Consistency.GetMinimizeLatency())
+	require.NotNil(t, fake.readRequests[1].Consistency.GetAtExactSnapshot())
+}
+
+func TestExportGraphCommandJSON(t *testing.T) {
+	_, addr := startFakeGraphServer(t)
+
+	out := runClientCommand(t, NewExportGraphCommand("spicedb"), RegisterExportGraphFlags, addr, "document:plan", "--format", "json", "--depth", "1")
+
+	var graph schemautil.ObjectGraph
+	require.NoError(t, json.Unmarshal([]byte(out), &graph))
+	require.Equal(t, "document:plan", graph.Root)
+	require.Equal(t, []string{"document:plan", "group:eng"}, graph.Nodes)
+	require.Len(t, graph.Edges, 1)
+	require.Equal(t, []string{"view"}, graph.Edges[0].Permissions)
+}
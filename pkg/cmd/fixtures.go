@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jzelinskie/cobrautil/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/authzed/spicedb/internal/fixtures"
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/cmd/server"
+	"github.com/authzed/spicedb/pkg/cmd/termination"
+)
+
+func RegisterFixturesFlags(cmd *cobra.Command) {
+	registerConnectionFlags(cmd)
+
+	// Flags for the shape of the relationships
+	cmd.Flags().Int("objects", 1_000, "number of objects of each definition")
+	cmd.Flags().StringToInt("objects-by-type", nil, "number of objects of the given definitions, such as 'document=100000'")
+	cmd.Flags().Int("fanout", 3, "mean number of subjects of each relation of each object")
+	cmd.Flags().StringToInt("fanout-by-relation", nil, "mean number of subjects of the given relations, such as 'document#viewer=20'")
+	cmd.Flags().Float64("skew", 1.5, "exponent of the Zipf distribution with which subjects are chosen, so that a few are related to many objects (at most 1 chooses uniformly)")
+	cmd.Flags().Float64("wildcard-rate", 0.01, "probability that an object has a public wildcard subject for each relation allowing one")
+	cmd.Flags().Int64("seed", 1, "seed of the relationships; the same seed, schema and shape always generate the same relationships")
+
+	// Flags for the destination
+	cmd.Flags().StringP("output", "o", "", "file to which the validation file is written (default stdout)")
+	cmd.Flags().Bool("write", false, "write the schema and relationships to the server at --endpoint instead of a validation file, replacing its schema")
+}
+
+func NewFixturesCommand(programName string) *cobra.Command {
+	return &cobra.Command{
+		Use:     "fixtures <schema-file>",
+		Short:   "generates randomized relationships for a schema",
+		Long:    "Generates randomized relationships for the definitions of a schema, of a configurable number of objects and subjects per relation, and writes them with the schema as a validation file, which can be loaded with --datastore-bootstrap-files, or to a running server. The same seed always generates the same relationships, so that large datasets can be reproduced to test query plans and caching.",
+		PreRunE: server.DefaultPreRunE(programName),
+		Args:    cobra.ExactArgs(1),
+		RunE:    termination.PublishError(fixturesRun),
+	}
+}
+
+func fixturesRun(cmd *cobra.Command, args []string) error {
+	schema, err := os.ReadFile(args[0])
+	if err != nil {
+		return err
+	}
+
+	generated, err := fixtures.Generate(string(schema), fixtures.Options{
+		Objects:          cobrautil.MustGetInt(cmd, "objects"),
+		ObjectsByType:    cobrautil.MustGetStringToInt(cmd, "objects-by-type"),
+		Fanout:           cobrautil.MustGetInt(cmd, "fanout"),
+		FanoutByRelation: cobrautil.MustGetStringToInt(cmd, "fanout-by-relation"),
+		Skew:             cobrautil.MustGetFloat64(cmd, "skew"),
+		WildcardRate:     cobrautil.MustGetFloat64(cmd, "wildcard-rate"),
+		Seed:             cobrautil.MustGetInt64(cmd, "seed"),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to generate fixtures for %s: %w", args[0], err)
+	}
+
+	ctx := cmd.Context()
+	log.Ctx(ctx).Info().Int("relationships", len(generated.Relationships)).Msg("generated relationships")
+
+	if cobrautil.MustGetBool(cmd, "write") {
+		client, err := newClient(cmd)
+		if err != nil {
+			return err
+		}
+
+		return generated.Write(ctx, &client.Client, func(written int) {
+			log.Ctx(ctx).Debug().Int("written", written).Int("total", len(generated.Relationships)).Msg("wrote relationships")
+		})
+	}
+
+	contents, err := generated.ValidationFile()
+	if err != nil {
+		return err
+	}
+
+	if output := cobrautil.MustGetString(cmd, "output"); output != "" {
+		return os.WriteFile(output, contents, 0o600)
+	}
+	_, err = cmd.OutOrStdout().Write(contents)
+	return err
+}
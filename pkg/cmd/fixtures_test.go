@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixturesCommand(t *testing.T) {
+	dir := t.TempDir()
+	schemaPath := filepath.Join(dir, "schema.zed")
+	require.NoError(t, os.WriteFile(schemaPath, []byte(`definition user {}
+
+definition document {
+	relation viewer: user
+	permission view = viewer
+}`), 0o600))
+
+	run := func(args ...string) string {
+		cmd := NewFixturesCommand("spicedb")
+		RegisterRootFlags(cmd)
+		RegisterFixturesFlags(cmd)
+		cmd.SilenceUsage = true
+
+		var out bytes.Buffer
+		cmd.SetOut(&out)
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs(append([]string{schemaPath}, args...))
+		require.NoError(t, cmd.Execute())
+		return out.String()
+	}
+
+	output := run("--objects", "10", "--objects-by-type", "document=5", "--seed", "3")
+	require.Contains(t, output, "definition document")
+	require.Contains(t, output, "document:4#viewer@user:")
+	require.NotContains(t, output, "document:5#")
+	require.Equal(t, output, run("--objects", "10", "--objects-by-type", "document=5", "--seed", "3"))
+
+	outputPath := filepath.Join(dir, "fixtures.yaml")
+	require.Empty(t, run("--objects", "10", "--objects-by-type", "document=5", "--seed", "3", "-o", outputPath))
+	written, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	require.Equal(t, output, string(written))
+}
@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/jzelinskie/cobrautil/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/authzed/spicedb/internal/importer"
+	"github.com/authzed/spicedb/pkg/cmd/server"
+	"github.com/authzed/spicedb/pkg/cmd/termination"
+)
+
+func RegisterImportFlags(cmd *cobra.Command) {
+	cmd.Flags().StringP("output", "o", "", "file to which the validation file is written (default stdout)")
+}
+
+func NewImportCommand(programName string) *cobra.Command {
+	return &cobra.Command{
+		Use:       "import <openfga|tuples> <file>",
+		Short:     "converts models and tuples from other Zanzibar-style systems",
+		Long:      "Converts an OpenFGA store file, as written by `fga store export`, or a dump of Zanzibar-style tuples, one `type:id#relation@type:id` per line, into a validation file with a SpiceDB schema and relationships, which can be loaded with --datastore-bootstrap-files. Constructs which are converted differently than written, or not at all, are reported.",
+		PreRunE:   server.DefaultPreRunE(programName),
+		RunE:      termination.PublishError(importRun),
+		Args:      cobra.ExactArgs(2),
+		ValidArgs: []string{"openfga", "tuples"},
+	}
+}
+
+func importRun(cmd *cobra.Command, args []string) error {
+	format, filePath := args[0], args[1]
+
+	var result *importer.Result
+	var convertErr error
+	switch format {
+	case "openfga":
+		contents, err := os.ReadFile(filePath)
+		if err != nil {
+			return err
+		}
+		result, convertErr = importer.ConvertOpenFGA(cmd.Context(), contents, filepath.Dir(filePath))
+
+	case "tuples":
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		result, convertErr = importer.ConvertTuples(cmd.Context(), file)
+
+	default:
+		return fmt.Errorf("unknown format `%s`: expected openfga or tuples", format)
+	}
+
+	if result != nil {
+		for _, issue := range result.Issues {
+			fmt.Fprintf(cmd.ErrOrStderr(), "%s: %s\n", filePath, issue)
+		}
+	}
+	if convertErr != nil {
+		return fmt.Errorf("failed to convert %s: %w", filePath, convertErr)
+	}
+
+	contents, err := result.ValidationFile()
+	if err != nil {
+		return err
+	}
+
+	if output := cobrautil.MustGetString(cmd, "output"); output != "" {
+		return os.WriteFile(output, contents, 0o600)
+	}
+	_, err = cmd.OutOrStdout().Write(contents)
+	return err
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportCommand(t *testing.T) {
+	dir := t.TempDir()
+	storePath := filepath.Join(dir, "store.fga.yaml")
+	require.NoError(t, os.WriteFile(storePath, []byte(`model: |
+  model
+    schema 1.1
+  type user
+  type document
+    relations
+      define viewer: [user]
+tuples:
+  - user: user:anne
+    relation: viewer
+    object: document:readme
+  - user: user:anne
+    relation: editor
+    object: document:readme
+`), 0o600))
+
+	tuplesPath := filepath.Join(dir, "tuples.txt")
+	require.NoError(t, os.WriteFile(tuplesPath, []byte("document:readme#viewer@user:anne\n"), 0o600))
+
+	tcs := []struct {
+		name           string
+		args           []string
+		expectedError  string
+		expectedOutput string
+		expectedIssues string
+	}{
+		{"openfga", []string{"openfga", storePath}, "", "document:readme#viewer@user:anne", "relation `editor` is not in the model"},
+		{"tuples", []string{"tuples", tuplesPath}, "", "relation viewer: user", "permissions cannot be inferred"},
+		{"unknown format", []string{"ldap", tuplesPath}, "unknown format `ldap`", "", ""},
+		{"not a store", []string{"openfga", tuplesPath}, "failed to convert", "", ""},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := NewImportCommand("spicedb")
+			RegisterRootFlags(cmd)
+			RegisterImportFlags(cmd)
+			cmd.SilenceUsage = true
+
+			var out, issues bytes.Buffer
+			cmd.SetOut(&out)
+			cmd.SetErr(&issues)
+			cmd.SetArgs(tc.args)
+
+			err := cmd.Execute()
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+
+			require.Contains(t, out.String(), tc.expectedOutput)
+			require.Contains(t, issues.String(), tc.expectedIssues)
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/jzelinskie/cobrautil/v2"
+	"github.com/spf13/cobra"
+
+	"github.com/authzed/spicedb/pkg/cmd/server"
+	"github.com/authzed/spicedb/pkg/cmd/termination"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+	"github.com/authzed/spicedb/pkg/schemautil"
+	"github.com/authzed/spicedb/pkg/validationfile"
+)
+
+func RegisterLintFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("json", false, "print the warnings as JSON")
+	cmd.Flags().Int("max-nesting-depth", schemautil.DefaultMaxNestingDepth, "depth of nested operations allowed in a permission")
+	cmd.Flags().StringSlice("disable", nil, fmt.Sprintf("rules which are not checked (%s)", lintRuleNames()))
+}
+
+func NewLintCommand(programName string) *cobra.Command {
+	return &cobra.Command{
+		Use:     "lint <schema-or-validation-file>...",
+		Short:   "checks schemas for common smells",
+		Long:    "Checks the schemas of one or more files for unused relations, permissions which can never be granted, wildcard subject types, deeply nested permissions and naming convention violations, exiting with an error if any are found. Files with a .yaml or .yml extension are read as validation files.",
+		PreRunE: server.DefaultPreRunE(programName),
+		RunE:    termination.PublishError(lintRun),
+		Args:    cobra.MinimumNArgs(1),
+	}
+}
+
+// lintFileWarning is a lint warning found in a file, as printed with --json.
+type lintFileWarning struct {
+	File string `json:"file"`
+	schemautil.LintWarning
+}
+
+func lintRun(cmd *cobra.Command, args []string) error {
+	opts := schemautil.LintOptions{
+		MaxNestingDepth: cobrautil.MustGetInt(cmd, "max-nesting-depth"),
+	}
+	for _, name := range cobrautil.MustGetStringSlice(cmd, "disable") {
+		rule := schemautil.LintRule(name)
+		if !slices.Contains(schemautil.AllLintRules, rule) {
+			return fmt.Errorf("unknown lint rule `%s`: expected one of %s", name, lintRuleNames())
+		}
+		opts.DisabledRules = append(opts.DisabledRules, rule)
+	}
+
+	warnings := []lintFileWarning{}
+	for _, filePath := range args {
+		fileWarnings, err := lintFile(filePath, opts)
+		if err != nil {
+			return fmt.Errorf("failed to lint %s: %w", filePath, err)
+		}
+
+		for _, warning := range fileWarnings {
+			warnings = append(warnings, lintFileWarning{File: filePath, LintWarning: warning})
+		}
+	}
+
+	out := cmd.OutOrStdout()
+	if cobrautil.MustGetBool(cmd, "json") {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(warnings); err != nil {
+			return err
+		}
+	} else {
+		for _, warning := range warnings {
+			fmt.Fprintf(out, "%s:%d:%d: %s [%s]\n", warning.File, warning.Line, warning.Column, warning.Message, warning.Rule)
+		}
+	}
+
+	if len(warnings) > 0 {
+		return fmt.Errorf("found %d lint warning(s)", len(warnings))
+	}
+	return nil
+}
+
+// lintFile lints the schema of the schema or validation file.
+func lintFile(filePath string, opts schemautil.LintOptions) ([]schemautil.LintWarning, error) {
+	compiled, err := compileSchemaFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return schemautil.Lint(compiled.ObjectDefinitions, opts), nil
+}
+
+// compileSchemaFile compiles the schema of the schema or validation file.
+func compileSchemaFile(filePath string) (*compiler.CompiledSchema, error) {
+	contents, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	schema := string(contents)
+	if isValidationFile(filePath) {
+		parsed, err := validationfile.DecodeValidationFile(contents)
+		if err != nil {
+			return nil, err
+		}
+		schema = parsed.Schema.Schema
+	}
+
+	return compiler.Compile(compiler.InputSchema{
+		Source:       input.Source(filePath),
+		SchemaString: schema,
+	}, compiler.AllowUnprefixedObjectType())
+}
+
+func lintRuleNames() string {
+	names := make([]string, 0, len(schemautil.AllLintRules))
+	for _, rule := range schemautil.AllLintRules {
+		names = append(names, `"`+string(rule)+`"`)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintCommand(t *testing.T) {
+	dir := t.TempDir()
+	cleanPath := filepath.Join(dir, "clean.zed")
+	require.NoError(t, os.WriteFile(cleanPath, []byte(`definition user {}
+
+definition document {
+  relation viewer: user
+  permission view = viewer
+}`), 0o600))
+
+	smellyPath := filepath.Join(dir, "smelly.zed")
+	require.NoError(t, os.WriteFile(smellyPath, []byte(`definition user {}
+
+definition document {
+  relation viewer: user
+  relation unused: user
+  permission view = viewer
+}`), 0o600))
+
+	validationPath := filepath.Join(dir, "validation.yaml")
+	require.NoError(t, os.WriteFile(validationPath, []byte(`schema: |-
+  definition user {}
+
+  definition document {
+    relation viewer: user:*
+    permission view = viewer
+  }
+`), 0o600))
+
+	tcs := []struct {
+		name           string
+		args           []string
+		expectedError  string
+		expectedOutput string
+	}{
+		{"clean schema", []string{cleanPath}, "", ""},
+		{"smelly schema", []string{smellyPath}, "found 1 lint warning(s)", "smelly.zed:5:3: relation `unused` is not used by any permission, arrow or subject type [unused-relation]\n"},
+		{"validation file", []string{validationPath}, "found 1 lint warning(s)", "validation.yaml:4:3: relation `viewer` allows every subject of type `user` through `user:*` [wildcard-subject-type]\n"},
+		{"disabled rule", []string{"--disable", "unused-relation", smellyPath}, "", ""},
+		{"unknown rule", []string{"--disable", "unknown", smellyPath}, "unknown lint rule `unknown`", ""},
+		{"json", []string{"--json", smellyPath}, "found 1 lint warning(s)", `"rule": "unused-relation"`},
+		{"json without warnings", []string{"--json", cleanPath}, "", "[]\n"},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := NewLintCommand("spicedb")
+			RegisterRootFlags(cmd)
+			RegisterLintFlags(cmd)
+			cmd.SilenceUsage = true
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+			cmd.SetArgs(tc.args)
+
+			err := cmd.Execute()
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+
+			if tc.expectedOutput == "" {
+				require.Empty(t, out.String())
+			} else {
+				require.Contains(t, out.String(), tc.expectedOutput)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jzelinskie/cobrautil/v2"
+	"github.com/spf13/cobra"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/perf"
+	"github.com/authzed/spicedb/pkg/cmd/server"
+	"github.com/authzed/spicedb/pkg/cmd/termination"
+)
+
+func RegisterPerfFlags(cmd *cobra.Command) {
+	registerConnectionFlags(cmd)
+
+	// Flags for the shape of the synthetic relationships
+	cmd.Flags().Int("documents", 1_000, "number of synthetic documents")
+	cmd.Flags().Int("users", 1_000, "number of synthetic users")
+	cmd.Flags().Int("groups", 100, "number of synthetic groups")
+	cmd.Flags().Int("group-members", 10, "number of users which are members of each group")
+	cmd.Flags().Int("group-nesting-depth", 2, "number of groups nested in each chain of groups, which checks dispatch through")
+	cmd.Flags().Int("document-viewers", 5, "number of users or groups which are viewers of each document")
+	cmd.Flags().Bool("skip-setup", false, "generate load without writing the synthetic schema and relationships, which must already have been written")
+
+	// Flags for the load
+	cmd.Flags().Float64("qps", 100, "rate of operations across all workers (0 means unlimited)")
+	cmd.Flags().Int("concurrency", 10, "number of operations in flight at once")
+	cmd.Flags().Duration("duration", 30*time.Second, "how long load is generated for")
+	cmd.Flags().StringToInt("mix", map[string]int{"check": 80, "read": 10, "write": 10}, `relative weight of each operation ("check", "read", "write")`)
+	cmd.Flags().Int64("seed", 0, "seed of the synthetic relationships and the choice of operations (0 uses the current time)")
+	cmd.Flags().Bool("json", false, "print the report as JSON")
+}
+
+func NewPerfCommand(programName string) *cobra.Command {
+	return &cobra.Command{
+		Use:     "perf",
+		Short:   "generates load against a running server",
+		Long:    "Adds synthetic definitions to the schema of a running server, writes synthetic relationships, and then generates a mix of check, read and write load against them, reporting the latency percentiles of each operation.",
+		PreRunE: server.DefaultPreRunE(programName),
+		Args:    cobra.NoArgs,
+		RunE:    termination.PublishError(perfRun),
+	}
+}
+
+func perfRun(cmd *cobra.Command, _ []string) error {
+	mix := perf.Mix{}
+	for name, weight := range cobrautil.MustGetStringToInt(cmd, "mix") {
+		switch name {
+		case "check":
+			mix.Check = weight
+		case "read":
+			mix.Read = weight
+		case "write":
+			mix.Write = weight
+		default:
+			return fmt.Errorf("unknown operation `%s` in --mix: expected check, read or write", name)
+		}
+	}
+
+	seed := cobrautil.MustGetInt64(cmd, "seed")
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	opts := perf.Options{
+		Shape: perf.Shape{
+			Documents:       cobrautil.MustGetInt(cmd, "documents"),
+			Users:           cobrautil.MustGetInt(cmd, "users"),
+			Groups:          cobrautil.MustGetInt(cmd, "groups"),
+			GroupMembers:    cobrautil.MustGetInt(cmd, "group-members"),
+			NestingDepth:    cobrautil.MustGetInt(cmd, "group-nesting-depth"),
+			DocumentViewers: cobrautil.MustGetInt(cmd, "document-viewers"),
+		},
+		Mix:         mix,
+		QPS:         cobrautil.MustGetFloat64(cmd, "qps"),
+		Concurrency: cobrautil.MustGetInt(cmd, "concurrency"),
+		Duration:    cobrautil.MustGetDuration(cmd, "duration"),
+		Seed:        seed,
+	}
+
+	client, err := newClient(cmd)
+	if err != nil {
+		return err
+	}
+
+	ctx := cmd.Context()
+	if !cobrautil.MustGetBool(cmd, "skip-setup") {
+		written, err := perf.Setup(ctx, &client.Client, opts.Shape, seed)
+		if err != nil {
+			return err
+		}
+		log.Ctx(ctx).Info().Int("relationships", written).Msg("wrote synthetic relationships")
+	}
+
+	log.Ctx(ctx).Info().Dur("duration", opts.Duration).Float64("qps", opts.QPS).Int("concurrency", opts.Concurrency).Msg("generating load")
+	report, err := perf.Run(ctx, &client.Client, opts)
+	if err != nil {
+		return err
+	}
+
+	out := cmd.OutOrStdout()
+	if cobrautil.MustGetBool(cmd, "json") {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	table := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(table, "OPERATION\tCOUNT\tERRORS\tP50\tP90\tP99\tMAX")
+	for _, op := range report.Operations {
+		fmt.Fprintf(table, "%s\t%d\t%d\t%s\t%s\t%s\t%s\n", op.Operation, op.Count, op.Errors, op.P50, op.P90, op.P99, op.Max)
+	}
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(out, "\n%d operations in %s (%.1f/s)\n", report.Total(), report.Duration.Round(time.Millisecond), float64(report.Total())/report.Duration.Seconds())
+	return nil
+}
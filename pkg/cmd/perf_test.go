@@ -0,0 +1,18 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPerfCommandInvalidMix(t *testing.T) {
+	cmd := NewPerfCommand("spicedb")
+	RegisterPerfFlags(cmd)
+	cmd.PreRunE = nil
+	cmd.SilenceUsage = true
+	cmd.SetErr(&bytes.Buffer{})
+	cmd.SetArgs([]string{"--mix", "check=1,lookup=1"})
+	require.ErrorContains(t, cmd.Execute(), "unknown operation `lookup` in --mix")
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/spf13/cobra"
 
 	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/cmd/configfile"
 	"github.com/authzed/spicedb/pkg/cmd/server"
 	"github.com/authzed/spicedb/pkg/cmd/termination"
 	"github.com/authzed/spicedb/pkg/releases"
@@ -19,6 +20,7 @@ func RegisterRootFlags(cmd *cobra.Command) {
 	releases.RegisterFlags(cmd.PersistentFlags())
 	termination.RegisterFlags(cmd.PersistentFlags())
 	runtime.RegisterFlags(cmd.PersistentFlags())
+	configfile.RegisterFlags(cmd.PersistentFlags())
 }
 
 // DeprecatedRunE wraps the RunFunc with a warning log statement.
@@ -3,19 +3,33 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
+	"github.com/jzelinskie/cobrautil/v2"
 	"github.com/spf13/cobra"
 
+	"github.com/authzed/spicedb/internal/dispatch/discovery"
+	"github.com/authzed/spicedb/internal/dispatch/federation"
+	"github.com/authzed/spicedb/internal/lifecycle"
+	v1svc "github.com/authzed/spicedb/internal/services/v1"
 	"github.com/authzed/spicedb/internal/telemetry"
+	"github.com/authzed/spicedb/internal/vault"
+	"github.com/authzed/spicedb/pkg/cmd/configfile"
 	"github.com/authzed/spicedb/pkg/cmd/datastore"
 	"github.com/authzed/spicedb/pkg/cmd/server"
 	"github.com/authzed/spicedb/pkg/cmd/termination"
 	"github.com/authzed/spicedb/pkg/cmd/util"
+	"github.com/authzed/spicedb/pkg/middleware/transform"
 )
 
 const PresharedKeyFlag = "grpc-preshared-key"
 
+const scopedPresharedKeyFlag = "grpc-scoped-preshared-key"
+
+// sensitiveServeFlags are the flags of the serve command whose values are redacted when printed.
+var sensitiveServeFlags = []string{PresharedKeyFlag, scopedPresharedKeyFlag, "dispatch-cluster-preshared-key", "datastore-conn-uri", "zedtoken-signing-key", "shadow-check-token", "federation-token"}
+
 var (
 	namespaceCacheDefaults = &server.CacheConfig{
 		Name:        "namespace",
@@ -40,6 +54,24 @@ var (
 		NumCounters: 100_000,
 		MaxCost:     "70%",
 	}
+
+	// The denied check results caches are disabled by default, in which case denied results
+	// are held in the dispatch caches along with all others.
+	dispatchDeniedCacheDefaults = &server.CacheConfig{
+		Name:        "dispatch_denied",
+		Enabled:     false,
+		Metrics:     true,
+		NumCounters: 10_000,
+		MaxCost:     "5%",
+	}
+
+	dispatchClusterDeniedCacheDefaults = &server.CacheConfig{
+		Name:        "cluster_dispatch_denied",
+		Enabled:     false,
+		Metrics:     true,
+		NumCounters: 100_000,
+		MaxCost:     "10%",
+	}
 )
 
 func RegisterServeFlags(cmd *cobra.Command, config *server.Config) error {
@@ -50,14 +82,97 @@ func RegisterServeFlags(cmd *cobra.Command, config *server.Config) error {
 	// Flags for logging
 	cmd.Flags().BoolVar(&config.EnableRequestLogs, "grpc-log-requests-enabled", false, "logs API request payloads")
 	cmd.Flags().BoolVar(&config.EnableResponseLogs, "grpc-log-responses-enabled", false, "logs API response payloads")
+	cmd.Flags().StringToStringVar(&config.LogSampleRates, "grpc-log-sample-rates", map[string]string{}, `fraction of successful API calls logged per method, such as "*=0.01,WriteRelationships=1" ("*" sets the rate of other methods; failed calls are always logged)`)
+
+	// Flags for rate limiting
+	cmd.Flags().Float64Var(&config.RateLimitReadsPerSecond, "grpc-ratelimit-reads-per-second", 0, "maximum sustained rate of read requests per caller (0 means unlimited)")
+	cmd.Flags().IntVar(&config.RateLimitReadBurst, "grpc-ratelimit-read-burst", 100, "maximum burst of read requests per caller")
+	cmd.Flags().Float64Var(&config.RateLimitWritesPerSecond, "grpc-ratelimit-writes-per-second", 0, "maximum sustained rate of write requests per caller (0 means unlimited)")
+	cmd.Flags().IntVar(&config.RateLimitWriteBurst, "grpc-ratelimit-write-burst", 10, "maximum burst of write requests per caller")
+	cmd.Flags().Uint32Var(&config.MaxInFlightRequests, "grpc-max-inflight-requests", 0, "maximum number of requests handled at once across all callers (0 means unlimited)")
+	cmd.Flags().Uint32Var(&config.MaxInFlightLowPriorityPercent, "grpc-max-inflight-low-priority-percent", 80, "percentage of --grpc-max-inflight-requests beyond which lookup, watch and read relationships requests are shed to keep capacity for checks and writes (100 gives all requests the same priority)")
+	cmd.Flags().DurationVar(&config.MaxInFlightRetryAfter, "grpc-max-inflight-retry-after", time.Second, "delay suggested to clients whose requests are shed because too many requests are in flight")
+
+	// Flags for the audit log
+	cmd.Flags().StringVar(&config.AuditLogSink, "audit-log-sink", "", `sink to which a record of every change to relationships and schema is emitted ("stdout", "file" or "http"; empty disables the audit log)`)
+	cmd.Flags().StringVar(&config.AuditLogFilePath, "audit-log-file-path", "", "path of the file to which audit records are appended by the file sink")
+	cmd.Flags().IntVar(&config.AuditLogFileMaxSizeMB, "audit-log-file-max-size-mb", 100, "size in megabytes beyond which the audit log file is rotated")
+	cmd.Flags().IntVar(&config.AuditLogFileMaxBackups, "audit-log-file-max-backups", 10, "number of rotated audit log files kept")
+	cmd.Flags().StringVar(&config.AuditLogHTTPEndpoint, "audit-log-http-endpoint", "", "URL to which audit records are POSTed as JSON by the http sink")
+	cmd.Flags().DurationVar(&config.AuditLogHTTPTimeout, "audit-log-http-timeout", 5*time.Second, "timeout for forwarding an audit record by the http sink")
+
+	// Flags for decision logs
+	cmd.Flags().StringVar(&config.DecisionLogSink, "decision-log-sink", "", `sink to which the decisions of checks are logged in the format of OPA's decision logs ("stdout" or "http"; empty disables decision logs)`)
+	cmd.Flags().StringVar(&config.DecisionLogHTTPEndpoint, "decision-log-http-endpoint", "", "URL to which batches of decisions are POSTed by the http sink, as OPA does to the `/logs` endpoint of its decision log service")
+	cmd.Flags().DurationVar(&config.DecisionLogUploadInterval, "decision-log-upload-interval", 10*time.Second, "interval at which batches of decisions are uploaded by the http sink")
+	cmd.Flags().IntVar(&config.DecisionLogBatchSize, "decision-log-batch-size", 1000, "maximum number of decisions uploaded in a single batch by the http sink")
+	cmd.Flags().IntVar(&config.DecisionLogBufferSize, "decision-log-buffer-size", 100000, "maximum number of decisions buffered by the http sink while they cannot be uploaded, beyond which decisions are dropped")
+	cmd.Flags().DurationVar(&config.DecisionLogHTTPTimeout, "decision-log-http-timeout", 5*time.Second, "timeout for uploading a batch of decisions by the http sink")
+
+	// Flags for shadow checks
+	cmd.Flags().Float64Var(&config.ShadowCheckSampleRate, "shadow-check-sample-rate", 0.01, "fraction of CheckPermission requests mirrored to the shadow schema file or endpoint")
+	cmd.Flags().StringVar(&config.ShadowCheckSchemaFile, "shadow-check-schema-file", "", "path of a candidate schema against which sampled checks are evaluated, over the relationships of the datastore, to record their divergence from the schema being served")
+	cmd.Flags().StringVar(&config.ShadowCheckEndpoint, "shadow-check-endpoint", "", "address of another cluster to which sampled checks are mirrored, to record their divergence from the responses of this one")
+	cmd.Flags().StringVar(&config.ShadowCheckToken, "shadow-check-token", "", "preshared key or JWT with which checks are mirrored to the shadow check endpoint")
+	cmd.Flags().BoolVar(&config.ShadowCheckInsecure, "shadow-check-insecure", false, "connect to the shadow check endpoint without TLS")
+	cmd.Flags().StringVar(&config.ShadowCheckCAPath, "shadow-check-ca-path", "", "path of the CA certificate of the shadow check endpoint, rather than the system certificates")
+	cmd.Flags().BoolVar(&config.ShadowCheckExactSnapshot, "shadow-check-exact-snapshot", false, "mirror checks to the shadow check endpoint at the revision at which they were answered, which requires that the clusters share a datastore")
+	cmd.Flags().Uint32Var(&config.ShadowCheckMaxInFlight, "shadow-check-max-inflight", 100, "maximum number of mirrored checks running at once, beyond which sampled checks are not mirrored")
+	cmd.Flags().DurationVar(&config.ShadowCheckTimeout, "shadow-check-timeout", 5*time.Second, "timeout for each mirrored check")
+	cmd.Flags().StringToStringVar(&config.FederationRemoteNamespaces, "federation-remote-namespaces", map[string]string{}, "namespaces whose relationships are stored by other clusters, as namespace=endpoint, to whose APIs checks of them are delegated")
+	cmd.Flags().StringVar(&config.FederationToken, "federation-token", "", "preshared key or JWT with which checks are delegated to the clusters of remote namespaces")
+	cmd.Flags().BoolVar(&config.FederationInsecure, "federation-insecure", false, "connect to the clusters of remote namespaces without TLS")
+	cmd.Flags().StringVar(&config.FederationCAPath, "federation-ca-path", "", "path of the CA certificate of the clusters of remote namespaces, rather than the system certificates")
+	cmd.Flags().DurationVar(&config.FederationMaxStaleness, "federation-max-staleness", 10*time.Second, "longest time for which cached results are used when checks are delegated to the clusters of remote namespaces")
+	cmd.Flags().DurationVar(&config.FederationTimeout, "federation-timeout", federation.DefaultTimeout, "timeout for each check delegated to the cluster of a remote namespace")
+
+	// Flags for relationship transforms
+	cmd.Flags().StringToStringVar(&config.RelationshipTransforms, "relationship-transforms", map[string]string{}, fmt.Sprintf(`transform applied to the relationships of each namespace as they are written and read, such as "document=lowercase-ids" (registered transforms: %s)`, strings.Join(transform.Registered(), ", ")))
+
+	// Flags for schema webhooks
+	cmd.Flags().StringSliceVar(&config.SchemaWebhookURLs, "schema-webhook-urls", nil, "URLs to which a notification with the diff of every schema change is POSTed as JSON")
+	cmd.Flags().StringVar(&config.SchemaWebhookSecret, "schema-webhook-secret", "", "secret with which schema webhook notifications are signed in the X-SpiceDB-Signature header (HMAC-SHA256)")
+	cmd.Flags().DurationVar(&config.SchemaWebhookTimeout, "schema-webhook-timeout", 5*time.Second, "timeout for each attempt to deliver a schema webhook notification")
+	cmd.Flags().IntVar(&config.SchemaWebhookMaxAttempts, "schema-webhook-max-attempts", 5, "maximum number of attempts to deliver each schema webhook notification")
+
+	// Flags for the Envoy external authorization service
+	cmd.Flags().StringVar(&config.ExtAuthzRulesetPath, "extauthz-ruleset-path", "", "path of a YAML ruleset mapping HTTP requests to permission checks; when set, Envoy's ext_authz Authorization service is served on the gRPC API")
 
 	// Flags for the gRPC API server
 	util.RegisterGRPCServerFlags(cmd.Flags(), &config.GRPCServer, "grpc", "gRPC", ":50051", true)
 	cmd.Flags().StringSliceVar(&config.PresharedSecureKey, PresharedKeyFlag, []string{}, "preshared key(s) to require for authenticated requests")
-	cmd.Flags().DurationVar(&config.ShutdownGracePeriod, "grpc-shutdown-grace-period", 0*time.Second, "amount of time after receiving sigint to continue serving")
-	if err := cmd.MarkFlagRequired(PresharedKeyFlag); err != nil {
-		return fmt.Errorf("failed to mark flag as required: %w", err)
-	}
+	cmd.Flags().StringArrayVar(&config.ScopedPresharedKeys, scopedPresharedKeyFlag, nil, `named preshared key restricted to API methods and object definitions, as JSON such as '{"name": "frontend", "key": "somerandomkeyhere", "methods": ["CheckPermission", "authzed.api.v1.SchemaService/ReadSchema"], "namespaces": ["document"]}', in addition to the unrestricted --grpc-preshared-key; empty lists place no restriction (repeatable)`)
+	cmd.Flags().DurationVar(&config.ShutdownGracePeriod, "shutdown-grace-period", 0*time.Second, "amount of time after receiving SIGTERM to wait for in-flight requests to complete before forcibly stopping (0 waits indefinitely)")
+	cmd.Flags().DurationVar(&config.ShutdownGracePeriod, "grpc-shutdown-grace-period", 0*time.Second, "amount of time after receiving SIGTERM to wait for in-flight requests to complete before forcibly stopping (0 waits indefinitely)")
+	_ = cmd.Flags().MarkDeprecated("grpc-shutdown-grace-period", "use --shutdown-grace-period instead")
+	cmd.Flags().DurationVar(&config.ShutdownStopTimeout, "shutdown-stop-timeout", lifecycle.DefaultStopTimeout, "amount of time given to each subsystem, such as a cache or background job, to stop on shutdown before moving on to the next, in addition to the grace period of servers (0 waits indefinitely)")
+	cmd.Flags().StringVar(&config.JWTIssuer, "grpc-jwt-issuer", "", "issuer of JWTs to accept for authenticated requests, in addition to any preshared keys")
+	cmd.Flags().StringVar(&config.JWTJWKSURL, "grpc-jwt-jwks-url", "", "URL of the JWT issuer's key set (discovered from the issuer's OpenID configuration if unset)")
+	cmd.Flags().StringVar(&config.JWTAudience, "grpc-jwt-audience", "", "audience required in JWTs used for authenticated requests")
+	cmd.Flags().BoolVar(&config.TenancyEnabled, "grpc-tenancy-enabled", false, "isolate the schema and relationships of each tenant, named by the spicedb_tenant claim of the JWT of each request; requests without a tenant are denied")
+	cmd.Flags().StringSliceVar(&config.ZedTokenSigningKeys, "zedtoken-signing-key", []string{}, "key(s) with which to sign the ZedTokens issued by the server, so that tokens it did not issue are rejected; tokens are signed with the first key and accepted if signed with any, so that keys can be rotated")
+	cmd.Flags().BoolVar(&config.ZedTokenAllowUnsigned, "zedtoken-allow-unsigned", false, "accept unsigned ZedTokens while --zedtoken-signing-key is rolled out")
+
+	// Flags for secrets sourced from HashiCorp Vault
+	cmd.Flags().StringVar(&config.VaultAddress, "vault-addr", "", "address of the Vault server from which secrets are read (defaults to VAULT_ADDR)")
+	cmd.Flags().StringVar(&config.VaultAuthMethod, "vault-auth-method", vault.AuthMethodToken, `method with which to authenticate with Vault ("token", "approle", "kubernetes")`)
+	cmd.Flags().StringVar(&config.VaultAuthMount, "vault-auth-mount", "", "path at which the Vault auth method is mounted (defaults to the name of the method)")
+	cmd.Flags().StringVar(&config.VaultToken, "vault-token", "", "token with which to authenticate with the token auth method (defaults to VAULT_TOKEN)")
+	cmd.Flags().StringVar(&config.VaultRole, "vault-role", "", "role ID for the approle auth method, or role for the kubernetes auth method")
+	cmd.Flags().StringVar(&config.VaultSecretID, "vault-secret-id", "", "secret ID for the approle auth method")
+	cmd.Flags().StringVar(&config.VaultKubernetesTokenPath, "vault-kubernetes-token-path", vault.DefaultKubernetesTokenPath, "path of the service account token for the kubernetes auth method")
+	cmd.Flags().StringVar(&config.VaultTLSPath, "vault-tls-path", "", "path of the Vault secret with the `certificate` and `private_key` used to serve the gRPC API, in place of --grpc-tls-cert-path and --grpc-tls-key-path")
+	cmd.Flags().StringVar(&config.VaultTLSCommonName, "vault-tls-common-name", "", "common name with which to issue the certificate from the PKI issue endpoint at --vault-tls-path, rather than reading it")
+	cmd.Flags().StringVar(&config.VaultPresharedKeysPath, "vault-preshared-keys-path", "", "path of the Vault secret with the comma-separated `preshared_keys` to require for authenticated requests, in place of --grpc-preshared-key")
+	cmd.Flags().DurationVar(&config.VaultRefreshInterval, "vault-refresh-interval", 5*time.Minute, "interval at which Vault secrets without a lease, such as KV secrets, are read again; leased secrets are renewed when two thirds of their lease have elapsed")
+
+	// Flags for error reporting
+	cmd.Flags().StringVar(&config.ErrorReportingDSN, "error-reporting-dsn", "", "Sentry DSN to which panics and internal errors are reported, with the method, namespaces and redacted filters of their request (empty disables error reporting)")
+	cmd.Flags().StringVar(&config.ErrorReportingEnvironment, "error-reporting-environment", "", "environment reported with each error, e.g. `production`")
+	cmd.Flags().Float64Var(&config.ErrorReportingSampleRate, "error-reporting-sample-rate", 1, "fraction, greater than 0 and at most 1, of errors which are reported")
+
+	// Flags for reloading configuration
+	cmd.Flags().StringVar(&config.ReloadConfigPath, "reload-config-path", "", "path of a YAML file of settings, keyed by flag name, which override their flags and are reloaded on SIGHUP or when the file changes; supports log-level, grpc-preshared-key, the grpc-ratelimit and grpc-max-inflight-requests flags and the cache max-cost flags")
 
 	// Flags for the datastore
 	if err := datastore.RegisterDatastoreFlags(cmd, &config.DatastoreConfig); err != nil {
@@ -99,47 +214,155 @@ func RegisterServeFlags(cmd *cobra.Command, config *server.Config) error {
 		return fmt.Errorf("failed to mark flag as hidden: %w", err)
 	}
 
+	// Flags for gRPC-Web
+	util.RegisterHTTPServerFlags(cmd.Flags(), &config.GRPCWeb, "grpc-web", "grpc-web", ":8444", false)
+	cmd.Flags().BoolVar(&config.GRPCWebCorsEnabled, "grpc-web-cors-enabled", false, "enable CORS on the grpc-web server, for browser clients served from other origins")
+	cmd.Flags().StringSliceVar(&config.GRPCWebCorsAllowedOrigins, "grpc-web-cors-allowed-origins", []string{"*"}, "set CORS allowed origins for the grpc-web server, defaults to all origins")
+
 	// Flags for configuring the dispatch server
 	util.RegisterGRPCServerFlags(cmd.Flags(), &config.DispatchServer, "dispatch-cluster", "dispatch", ":50053", false)
+	cmd.Flags().StringSliceVar(&config.DispatchPresharedKey, "dispatch-cluster-preshared-key", []string{}, "preshared key(s) to require for internal dispatch requests, separately from the public API (defaults to the gRPC preshared keys); the first is used when dispatching to the cluster")
 	server.RegisterCacheFlags(cmd.Flags(), "dispatch-cache", &config.DispatchCacheConfig, dispatchCacheDefaults)
 	server.RegisterCacheFlags(cmd.Flags(), "dispatch-cluster-cache", &config.ClusterDispatchCacheConfig, dispatchClusterCacheDefaults)
+	server.RegisterCacheFlags(cmd.Flags(), "dispatch-denied-cache", &config.DispatchDeniedCacheConfig, dispatchDeniedCacheDefaults)
+	server.RegisterCacheFlags(cmd.Flags(), "dispatch-cluster-denied-cache", &config.ClusterDispatchDeniedCacheConfig, dispatchClusterDeniedCacheDefaults)
+	cmd.Flags().StringVar(&config.MaxCacheMemory, "max-cache-memory", "", "upper bound of the combined size of the namespace, dispatch and cluster dispatch caches in bytes or percent of available memory; when their max costs exceed it, each cache is shrunk in proportion to its max cost (empty means no bound)")
+
+	cmd.Flags().BoolVar(&config.CacheWarmupEnabled, "cache-warmup-enabled", false, "preload namespaces and replay frequent subproblems before reporting the server as healthy")
+	cmd.Flags().StringVar(&config.CacheWarmupFile, "cache-warmup-file", "", "path to which the most frequent check subproblems are persisted, and from which they are replayed on startup")
+	cmd.Flags().IntVar(&config.CacheWarmupMaxSubproblems, "cache-warmup-max-subproblems", 1000, "maximum number of check subproblems persisted and replayed for cache warm-up")
+	cmd.Flags().Float64Var(&config.CacheWarmupSampleRate, "cache-warmup-sample-rate", 0.01, "fraction of check subproblems sampled to find the most frequent for cache warm-up")
+	cmd.Flags().DurationVar(&config.CacheWarmupTimeout, "cache-warmup-timeout", 30*time.Second, "maximum duration of cache warm-up, after which the server reports as healthy regardless")
 
 	// Flags for configuring dispatch requests
 	cmd.Flags().Uint32Var(&config.DispatchMaxDepth, "dispatch-max-depth", 50, "maximum recursion depth for nested calls")
-	cmd.Flags().StringVar(&config.DispatchUpstreamAddr, "dispatch-upstream-addr", "", "upstream grpc address to dispatch to")
+	cmd.Flags().Uint64Var(&config.DispatchCheckMaxRelationships, "dispatch-check-max-relationships", 0, "maximum number of relationships a single check, or subproblem received from another node, may read before it is aborted with RESOURCE_EXHAUSTED (0 for no limit)")
+	cmd.Flags().Uint64Var(&config.DispatchCheckMaxSubproblems, "dispatch-check-max-subproblems", 0, "maximum number of subproblems a single check, or subproblem received from another node, may resolve before it is aborted with RESOURCE_EXHAUSTED (0 for no limit)")
+	cmd.Flags().StringVar(&config.DispatchUpstreamAddr, "dispatch-upstream-addr", "", "upstream grpc address to dispatch to; `xds:///` addresses are resolved and balanced through the xDS control plane named by GRPC_XDS_BOOTSTRAP")
+	cmd.Flags().StringVar(&config.DispatchUpstreamKubernetesService, "dispatch-upstream-kubernetes-service", "", "Kubernetes service (as `service.namespace:port`) whose endpoints are watched to discover the dispatch cluster; an alternative to --dispatch-upstream-addr")
+	cmd.Flags().StringVar(&config.DispatchUpstreamDiscovery, "dispatch-upstream-discovery", "", `backend used to discover the members of the dispatch cluster, as an alternative to --dispatch-upstream-addr ("dns-srv", "consul" or "etcd")`)
+	cmd.Flags().StringVar(&config.DispatchUpstreamDiscoveryTarget, "dispatch-upstream-discovery-target", "", "SRV record name, Consul service name or etcd key prefix identifying the members of the dispatch cluster")
+	cmd.Flags().StringVar(&config.DispatchUpstreamDiscoveryEndpoint, "dispatch-upstream-discovery-endpoint", "", "HTTP address of the Consul agent or etcd server used for dispatch cluster discovery")
+	cmd.Flags().DurationVar(&config.DispatchUpstreamDiscoveryInterval, "dispatch-upstream-discovery-interval", discovery.DefaultRefreshInterval, "how often the members of the dispatch cluster are refreshed from the discovery backend")
 	cmd.Flags().StringVar(&config.DispatchUpstreamCAPath, "dispatch-upstream-ca-path", "", "local path to the TLS CA used when connecting to the dispatch cluster")
+	cmd.Flags().StringVar(&config.DispatchUpstreamTLSCertPath, "dispatch-upstream-tls-cert-path", "", "local path to the TLS certificate presented when connecting to a dispatch cluster that requires mutual TLS")
+	cmd.Flags().StringVar(&config.DispatchUpstreamTLSKeyPath, "dispatch-upstream-tls-key-path", "", "local path to the TLS key presented when connecting to a dispatch cluster that requires mutual TLS")
 	cmd.Flags().DurationVar(&config.DispatchUpstreamTimeout, "dispatch-upstream-timeout", 60*time.Second, "maximum duration of a dispatch call an upstream cluster before it times out")
+	cmd.Flags().BoolVar(&config.DispatchLocalFallbackEnabled, "dispatch-local-fallback-enabled", true, "evaluate dispatched requests locally when the upstream dispatch cluster is unavailable")
+	cmd.Flags().BoolVar(&config.DispatchHedgingEnabled, "dispatch-hedging-enabled", false, "also evaluate check and expand requests locally when the upstream dispatch cluster is slow to answer them, using whichever answer arrives first")
+	cmd.Flags().DurationVar(&config.DispatchHedgingInitialSlowValue, "dispatch-hedging-initial-slow-value", 10*time.Millisecond, "initial delay before hedging a dispatch request, used before latency statistics have been collected")
+	cmd.Flags().Uint64Var(&config.DispatchHedgingMaxRequests, "dispatch-hedging-max-requests", 1_000_000, "maximum number of historical dispatch requests to consider when computing the hedging delay")
+	cmd.Flags().Float64Var(&config.DispatchHedgingQuantile, "dispatch-hedging-quantile", 0.95, "quantile of historical dispatch request latency after which a request will be hedged")
 
 	cmd.Flags().Uint16Var(&config.GlobalDispatchConcurrencyLimit, "dispatch-concurrency-limit", 50, "maximum number of parallel goroutines to create for each request or subrequest")
 
 	cmd.Flags().Uint16Var(&config.DispatchConcurrencyLimits.Check, "dispatch-check-permission-concurrency-limit", 0, "maximum number of parallel goroutines to create for each check request or subrequest. defaults to --dispatch-concurrency-limit")
+	cmd.Flags().Uint32Var(&config.DispatchConcurrencyLimits.CheckPerNode, "dispatch-check-permission-node-concurrency-limit", 0, "maximum number of additional parallel goroutines to create across all check requests being resolved by the node. 0 places no limit")
 	cmd.Flags().Uint16Var(&config.DispatchConcurrencyLimits.LookupResources, "dispatch-lookup-resources-concurrency-limit", 0, "maximum number of parallel goroutines to create for each lookup resources request or subrequest. defaults to --dispatch-concurrency-limit")
 	cmd.Flags().Uint16Var(&config.DispatchConcurrencyLimits.LookupSubjects, "dispatch-lookup-subjects-concurrency-limit", 0, "maximum number of parallel goroutines to create for each lookup subjects request or subrequest. defaults to --dispatch-concurrency-limit")
 	cmd.Flags().Uint16Var(&config.DispatchConcurrencyLimits.ReachableResources, "dispatch-reachable-resources-concurrency-limit", 0, "maximum number of parallel goroutines to create for each reachable resources request or subrequest. defaults to --dispatch-concurrency-limit")
 
 	cmd.Flags().Uint16Var(&config.DispatchHashringReplicationFactor, "dispatch-hashring-replication-factor", 100, "set the replication factor of the consistent hasher used for the dispatcher")
 	cmd.Flags().Uint8Var(&config.DispatchHashringSpread, "dispatch-hashring-spread", 1, "set the spread of the consistent hasher used for the dispatcher")
+	cmd.Flags().Float64Var(&config.DispatchHashringLoadFactor, "dispatch-hashring-load-factor", 1.25, "bound the in-flight dispatches of each node to this multiple of the average, routing dispatches over the bound to the next node of the hashring. 0 disables the bound")
+	cmd.Flags().DurationVar(&config.DispatchHashringDualDispatchWindow, "dispatch-hashring-dual-dispatch-window", 30*time.Second, "how long after the dispatch cluster membership changes to send check and expand dispatches whose key moved to both its previous and new owners, to preserve cache hits while the new owner warms up. 0 disables dual dispatch")
 
 	cmd.Flags().StringToStringVar(&config.DispatchSecondaryUpstreamAddrs, "experimental-dispatch-secondary-upstream-addrs", nil, "secondary upstream addresses for dispatches, each with a name")
 	cmd.Flags().StringToStringVar(&config.DispatchSecondaryUpstreamExprs, "experimental-dispatch-secondary-upstream-exprs", nil, "map from request type (currently supported: `check`) to its associated CEL expression, which returns the secondary upstream(s) to be used for the request")
 
+	cmd.Flags().StringSliceVar(&config.GroupIndexRelations, "experimental-group-index-relations", nil, "relations (as `namespace#relation`) of nested groups whose flattened membership is indexed from the changelog to answer checks")
+	cmd.Flags().DurationVar(&config.GroupIndexMaxStaleness, "experimental-group-index-max-staleness", 5*time.Second, "maximum time by which changes answered from the group index may be newer than the revision of a check")
+	cmd.Flags().StringSliceVar(&config.MaterializedPermissions, "experimental-materialized-permissions", nil, "permissions (as `resource_type#permission@subject_type`) whose flattened members are materialized from the changelog to answer checks with a lookup")
+	cmd.Flags().DurationVar(&config.MaterializedPermissionsMaxStaleness, "experimental-materialized-permissions-max-staleness", 5*time.Second, "maximum time by which changes answered from materialized permissions may be newer than the revision of a check")
+
 	// Flags for configuring API behavior
 	cmd.Flags().BoolVar(&config.DisableV1SchemaAPI, "disable-v1-schema-api", false, "disables the V1 schema API")
+	cmd.Flags().BoolVar(&config.DisableSchemaWrites, "disable-namespace-writes", false, "reject writes of the schema with UNIMPLEMENTED, while still serving reads of it, so that the schema can only be changed through other deployments")
+	cmd.Flags().StringVar(&config.SchemaDirectory, "schema-directory", "", "directory of .zed schema files, such as one checked out from Git, whose definitions are validated and applied as the schema when the server starts and whenever the files change, with each change audited; writes of the schema through the API are then rejected")
+	cmd.Flags().BoolVar(&config.DisableWatchAPI, "disable-watch", false, "disables the Watch API")
+	cmd.Flags().BoolVar(&config.EnableReflection, "grpc-enable-reflection", false, "serve the gRPC reflection service, which describes the API to anyone who can reach the gRPC port")
+	cmd.Flags().BoolVar(&config.DisableReflection, "disable-reflection", false, "disables the gRPC reflection service")
+	if err := cmd.Flags().MarkDeprecated("disable-reflection", "the gRPC reflection service is now disabled unless --grpc-enable-reflection is set"); err != nil {
+		return fmt.Errorf("failed to mark flag as deprecated: %w", err)
+	}
+	cmd.Flags().BoolVar(&config.DisableWrites, "disable-writes", false, "reject all writes of relationships and of the schema, so that the node only serves reads such as checks and lookups")
 	cmd.Flags().BoolVar(&config.DisableVersionResponse, "disable-version-response", false, "disables version response support in the API")
-	cmd.Flags().Uint16Var(&config.MaximumUpdatesPerWrite, "write-relationships-max-updates-per-call", 1000, "maximum number of updates allowed for WriteRelationships calls")
+	cmd.Flags().Uint16Var(&config.MaximumUpdatesPerWrite, "write-relationships-max-updates-per-call", 1000, "maximum number of updates allowed for WriteRelationships calls, each of which is applied atomically at a single revision")
 	cmd.Flags().Uint16Var(&config.MaximumPreconditionCount, "update-relationships-max-preconditions-per-call", 1000, "maximum number of preconditions allowed for WriteRelationships and DeleteRelationships calls")
+	cmd.Flags().DurationVar(&config.WriteBatchWindow, "write-relationships-batch-window", 0, "time for which WriteRelationships calls are held so that those made concurrently are committed in a single transaction, up to the maximum updates per call, increasing write throughput at the cost of latency (0 disables batching)")
+	cmd.Flags().DurationVar(&config.CheckSessionIdleTimeout, "check-session-idle-timeout", 0, "time after which an unused check session, named by the io.spicedb.checksession header, ends; the checks of a session share its revision and dispatch results (0 disables check sessions)")
+	cmd.Flags().Uint32Var(&config.MaxReadRelationshipsLimit, "read-relationships-max-limit-per-call", 0, "maximum limit allowed for ReadRelationships calls (0 means unlimited)")
+	cmd.Flags().Uint32Var(&config.DefaultReadRelationshipsLimit, "read-relationships-default-limit-per-call", 0, "limit applied to ReadRelationships calls which do not specify one, which must then use the cursor of the last result to read more (0 means unlimited)")
+	cmd.Flags().Uint32Var(&config.MaxLookupResourcesLimit, "lookup-resources-max-limit-per-call", 0, "maximum limit allowed for LookupResources calls (0 means unlimited)")
+	cmd.Flags().Uint32Var(&config.DefaultLookupResourcesLimit, "lookup-resources-default-limit-per-call", 0, "limit applied to LookupResources calls which do not specify one, which must then use the cursor of the last result to look up more (0 means unlimited)")
+	cmd.Flags().Uint32Var(&config.DefaultExportBatchSize, "bulk-export-default-batch-size", 1_000, "number of relationships returned per response by BulkExportRelationships calls which do not specify a limit")
+	cmd.Flags().Uint32Var(&config.MaxExportBatchSize, "bulk-export-max-batch-size", 100_000, "maximum number of relationships returned per response by BulkExportRelationships; larger limits are reduced to it")
 	cmd.Flags().IntVar(&config.MaxCaveatContextSize, "max-caveat-context-size", 4096, "maximum allowed size of request caveat context in bytes. A value of zero or less means no limit")
 	cmd.Flags().IntVar(&config.MaxRelationshipContextSize, "max-relationship-context-size", 25000, "maximum allowed size of the context to be stored in a relationship")
 	cmd.Flags().DurationVar(&config.StreamingAPITimeout, "streaming-api-response-delay-timeout", 30*time.Second, "max duration time elapsed between messages sent by the server-side to the client (responses) before the stream times out")
 	cmd.Flags().DurationVar(&config.WatchHeartbeat, "watch-api-heartbeat", 1*time.Second, "heartbeat time on the watch in the API. 0 means to default to the datastore's minimum.")
+	cmd.Flags().IntVar(&config.WatchBufferLength, "watch-api-buffer-length", 1024, "number of revisions buffered for each watch stream, when the changes of the datastore are read once for all streams. 0 means that each stream reads the changes itself.")
+	cmd.Flags().StringVar(&config.WatchOverflow, "watch-api-overflow", string(v1svc.WatchOverflowDisconnect), `behavior of a watch stream whose buffer is full: "disconnect" ends it, to be resumed from its last cursor, while "drop-with-checkpoint" discards the buffered changes and sends a response without updates at the revision through which they were discarded`)
+	cmd.Flags().BoolVar(&config.EnforceRevisionTokens, "enforce-revision-tokens", false, "guarantee that requests which are at least as fresh as a ZedToken are evaluated at or after its revision, waiting for the datastore to reach it if needed; fails at startup if the datastore cannot guarantee the ordering of revisions")
+	cmd.Flags().DurationVar(&config.RevisionTokenTimeout, "enforce-revision-tokens-timeout", 5*time.Second, "maximum time a request waits for the datastore to reach the revision of its ZedToken when revision tokens are enforced, after which it fails as unavailable")
+	cmd.Flags().DurationVar(&config.AdaptiveConsistencyMaxStaleness, "adaptive-consistency-max-staleness", 1*time.Second, "maximum staleness of the revision chosen for requests with the `io.spicedb.consistency: adaptive` header, which are evaluated at the shared, likely cached, optimized revision unless it has lagged behind the head revision by longer; 0 disables adaptive consistency")
+	cmd.Flags().DurationVar(&config.SlowRequestThreshold, "slow-request-threshold", 0, "duration after which Check, Lookup and other API requests, and datastore queries, are logged as slow, along with their filters and dispatch statistics; 0 disables logging of slow requests")
+	cmd.Flags().StringToStringVar(&config.DefaultRequestTimeouts, "grpc-default-timeouts", map[string]string{}, `timeout of API calls made without a deadline, per class of methods, such as "read=5s,lookup=1m" (classes are "check", "read", "write", "lookup" and "watch"; checks use the timeouts of "read" unless given their own)`)
+	cmd.Flags().StringToStringVar(&config.MaxRequestTimeouts, "grpc-max-timeouts", map[string]string{}, `maximum timeout of API calls, per class of methods, such as "write=10s"; later deadlines of callers are shortened to it (classes are "check", "read", "write", "lookup" and "watch"; checks use the timeouts of "read" unless given their own)`)
+	cmd.Flags().BoolVar(&config.FaultInjectionEnabled, "grpc-fault-injection-enabled", false, "inject the faults given with --grpc-fault-injection into API calls, to test the resilience of clients; never enable in production")
+	cmd.Flags().StringArrayVar(&config.FaultInjectionFaults, "grpc-fault-injection", nil, `faults injected into the calls of an API method, as JSON such as '{"method": "CheckPermission", "latency": "200ms", "latency_probability": 0.1, "unavailable_probability": 0.05, "stream_reset_probability": 0.01}'; the method may be "*" for all methods without faults of their own, and stream resets only apply to streaming methods (repeatable)`)
 
 	cmd.Flags().BoolVar(&config.V1SchemaAdditiveOnly, "testing-only-schema-additive-writes", false, "append new definitions to the existing schema, rather than overwriting it")
 	if err := cmd.Flags().MarkHidden("testing-only-schema-additive-writes"); err != nil {
 		return fmt.Errorf("failed to mark flag as required: %w", err)
 	}
 
+	// Flags for per-permission evaluation metrics
+	cmd.Flags().Uint32Var(&config.PermissionMetricsMaxCardinality, "metrics-permission-max-cardinality", 500, "maximum number of distinct (definition, permission) pairs for which evaluation metrics are recorded; 0 disables per-permission metrics")
+	cmd.Flags().BoolVar(&config.NamespaceMetricsEnabled, "metrics-namespace-enabled", false, "record request, latency, dispatch and relationship update metrics labeled by object namespace; namespaces not defined in the schema are labeled _other")
+	cmd.Flags().DurationVar(&config.NamespaceMetricsRefreshInterval, "metrics-namespace-refresh-interval", time.Minute, "interval at which the namespaces labeling namespace metrics are refreshed from the schema")
+	cmd.Flags().StringToStringVar(&config.NamespaceMaxRelationships, "namespace-max-relationships", map[string]string{}, `maximum number of relationships per resource namespace, such as "document=1000000"; writes which may create relationships beyond it fail with RESOURCE_EXHAUSTED`)
+	cmd.Flags().StringToStringVar(&config.NamespaceMaxWriteRate, "namespace-max-write-rate", map[string]string{}, `maximum sustained rate of relationship updates per second per resource namespace, such as "document=500"; writes beyond it fail with RESOURCE_EXHAUSTED`)
+	cmd.Flags().StringToStringVar(&config.NamespaceMaxLookupResults, "namespace-max-lookup-results", map[string]string{}, `maximum number of results of a single LookupResources or LookupSubjects call per looked up namespace, such as "document=10000"; lookups fail with RESOURCE_EXHAUSTED once it is reached`)
+	cmd.Flags().StringArrayVar(&config.WriteAdmissionPolicies, "write-admission-policy", nil, `policy which each relationship written, deleted or imported must satisfy, as JSON such as '{"name": "org-provisioning", "expression": "mutation.resource_type != \"organization\" || caller.principal == \"key:provisioning\"", "message": "only provisioning may write organizations"}'; the CEL expression is given caller.principal, caller.subject, caller.key, caller.tenant, method and the operation, resource_type, resource_id, relation, subject_type, subject_id, subject_relation and caveat of the mutation, and writes it rejects fail with PERMISSION_DENIED (repeatable)`)
+	cmd.Flags().DurationVar(&config.NamespaceQuotaRefreshInterval, "namespace-quota-refresh-interval", 30*time.Second, "interval at which the relationship counts of namespaces with a maximum number of relationships are refreshed from the datastore")
+
+	// Flags for usage accounting
+	cmd.Flags().BoolVar(&config.UsageAccountingEnabled, "usage-accounting-enabled", false, "count the API requests and relationship mutations of each account, the tenant of requests with tenant isolation or otherwise the caller's principal such as \"key:<name>\", reported as metrics and by the /debug/usage endpoint of the debug listener")
+	cmd.Flags().StringToStringVar(&config.UsageMonthlyRequestQuota, "usage-monthly-request-quota", map[string]string{}, `maximum number of API requests per calendar month (UTC) per account, such as "tenant:acme=1000000", or "*" for every other account; requests beyond it fail with RESOURCE_EXHAUSTED. Enforced by each node separately`)
+	cmd.Flags().StringToStringVar(&config.UsageMonthlyMutationQuota, "usage-monthly-mutation-quota", map[string]string{}, `maximum number of relationship mutations per calendar month (UTC) per account, such as "tenant:acme=100000", or "*" for every other account; writes beyond it fail with RESOURCE_EXHAUSTED. Enforced by each node separately`)
+	cmd.Flags().DurationVar(&config.UsageStoredRelationshipsRefreshInterval, "usage-stored-relationships-refresh-interval", time.Hour, "interval at which the number of relationships stored by each tenant is counted from the datastore, when usage accounting and tenant isolation are enabled; 0 disables counting")
+
 	// Flags for misc services
 	util.RegisterHTTPServerFlags(cmd.Flags(), &config.MetricsAPI, "metrics", "metrics", ":9090", true)
+	util.RegisterHTTPServerFlags(cmd.Flags(), &config.DebugAPI, "debug", "debug", "localhost:9091", false)
+	cmd.Flags().DurationVar(&config.DebugLogOverrideMaxDuration, "debug-log-override-max-duration", time.Hour, "longest duration for which the log level can be changed, or calls of given methods or namespaces logged at debug level, through the /debug/loglevel endpoint of the debug server; 0 disables the endpoint")
+	cmd.Flags().Float64SliceVar(&config.GRPCMetricsLatencyBuckets, "metrics-grpc-latency-buckets", server.DefaultGRPCMetricsLatencyBuckets, "buckets, in seconds, of the histogram of gRPC request handling time per method")
+	cmd.Flags().StringVar(&config.StatsDAddr, "metrics-statsd-addr", "", "address of a StatsD server, such as a DogStatsD agent, to which metrics are sent; empty disables the StatsD exporter")
+	cmd.Flags().StringVar(&config.StatsDPrefix, "metrics-statsd-prefix", "", "prefix prepended to the name of each metric sent to StatsD")
+	cmd.Flags().StringSliceVar(&config.StatsDTags, "metrics-statsd-tags", nil, "tags, as `key:value`, added to each metric sent to StatsD")
+	cmd.Flags().DurationVar(&config.StatsDInterval, "metrics-statsd-interval", 10*time.Second, "interval at which metrics are sent to StatsD")
+
+	// Flags for the changefeed
+	cmd.Flags().StringSliceVar(&config.ChangefeedKafkaBrokers, "changefeed-kafka-brokers", nil, "seed brokers of a Kafka cluster to which every change to relationships and the schema is published; empty disables the changefeed, which should only be enabled on one node as each node publishes all changes, unless leader election is enabled")
+	cmd.Flags().StringVar(&config.ChangefeedKafkaTopic, "changefeed-kafka-topic", "spicedb-changes", "Kafka topic to which changes are published")
+	cmd.Flags().StringVar(&config.ChangefeedName, "changefeed-name", "default", "name of the changefeed, identifying the checkpoint stored in the datastore from which it resumes")
+	cmd.Flags().DurationVar(&config.ChangefeedCheckpointInterval, "changefeed-checkpoint-interval", 5*time.Second, "interval at which the revision of the last change acknowledged by Kafka is checkpointed in the datastore; changes since the checkpoint are published again after a restart")
+
+	// Flags for scheduled backups
+	cmd.Flags().StringVar(&config.BackupBucketURL, "backup-bucket-url", "", "URL of the bucket (`s3://bucket/prefix`, `gs://bucket/prefix` or `file:///path`) to which compressed backups, restorable with the restore command, are periodically written; empty disables scheduled backups, which should only be enabled on one node unless leader election is enabled")
+	cmd.Flags().DurationVar(&config.BackupInterval, "backup-interval", 24*time.Hour, "interval at which scheduled backups are written")
+	cmd.Flags().IntVar(&config.BackupRetention, "backup-retention", 7, "number of most recent scheduled backups kept in the bucket")
+	cmd.Flags().StringVar(&config.BackupEncryptionKeyFile, "backup-encryption-key-file", "", "file holding the key with which scheduled backups are encrypted; if empty, they are not encrypted")
+
+	// Flags for access reports
+	cmd.Flags().StringVar(&config.AccessReportBucketURL, "access-report-bucket-url", "", "URL of the bucket (`s3://bucket/prefix`, `gs://bucket/prefix` or `file:///path`) to which access reports requested via POST /debug/access-reports on the debug endpoint are written; empty disables access reports")
+	cmd.Flags().IntVar(&config.AccessReportMaxRunning, "access-report-max-running", 2, "maximum number of access reports generated at a time by each node")
+
+	// Flags for leader election
+	cmd.Flags().BoolVar(&config.LeaderElectionEnabled, "leader-election-enabled", false, "elect, through a lease in the datastore, a single node of those sharing the datastore to run garbage collection, the changefeed and scheduled backups, failing over to another when it stops")
+	cmd.Flags().StringVar(&config.LeaderElectionNodeName, "leader-election-node-name", "", "name of the node in leader election, which must be unique across the cluster; defaults to the hostname")
+	cmd.Flags().DurationVar(&config.LeaderElectionLeaseDuration, "leader-election-lease-duration", 15*time.Second, "duration of the lease held by the leader, which is renewed three times per duration; another node takes over at most this long after the leader stops renewing it")
 
 	if err := util.RegisterDeprecatedHTTPServerFlags(cmd, "dashboard", "dashboard"); err != nil {
 		return err
@@ -150,23 +373,51 @@ func RegisterServeFlags(cmd *cobra.Command, config *server.Config) error {
 	cmd.Flags().StringVar(&config.TelemetryCAOverridePath, "telemetry-ca-override-path", "", "TODO")
 	cmd.Flags().DurationVar(&config.TelemetryInterval, "telemetry-interval", telemetry.DefaultInterval, "approximate period between telemetry reports, minimum 1 minute")
 
+	cmd.Flags().Bool(skipTLSFlag, false, "DEVELOPMENT ONLY: serve every endpoint without TLS, bound to localhost unless its address is given, and persist the memory datastore to the platform's default data path unless --datastore-memory-data-path is given")
+	cmd.Flags().Bool("dry-run", false, "print the effective configuration, merged from flags, environment variables and the config file, as YAML instead of starting the server")
+	cmd.Flags().Bool("selftest", false, "before starting the server, verify datastore connectivity and migrations, clock skew against the datastore, TLS certificates and the reachability of dispatch peers, printing a report and exiting if any check fails")
+
 	return nil
 }
 
 func NewServeCommand(programName string, config *server.Config) *cobra.Command {
+	var commandLineFlags map[string]bool
 	return &cobra.Command{
-		Use:     "serve",
-		Short:   "serve the permissions database",
-		Long:    "A database that stores, computes, and validates application permissions",
-		PreRunE: server.DefaultPreRunE(programName),
+		Use:   "serve",
+		Short: "serve the permissions database",
+		Long:  "A database that stores, computes, and validates application permissions",
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			// The flags given on the command line are recorded before the environment and
+			// config file set the others, so that --dry-run can tell them apart.
+			commandLineFlags = configfile.CommandLineFlags(cmd.Flags())
+			return server.DefaultPreRunE(programName)(cmd, args)
+		},
 		RunE: termination.PublishError(func(cmd *cobra.Command, args []string) error {
+			if cobrautil.MustGetBool(cmd, "dry-run") {
+				return configfile.WriteEffective(cmd.OutOrStdout(), cmd.Flags(), commandLineFlags, programName, sensitiveServeFlags...)
+			}
+
+			if cobrautil.MustGetBool(cmd, skipTLSFlag) {
+				if err := applySkipTLSPreset(cmd, config, cmd.ErrOrStderr()); err != nil {
+					return err
+				}
+			}
+
+			if cobrautil.MustGetBool(cmd, "selftest") {
+				if err := runSelfTest(cmd.Context(), cmd.ErrOrStderr(), config); err != nil {
+					return err
+				}
+			}
+
 			server, err := config.Complete(cmd.Context())
 			if err != nil {
 				return err
 			}
+			// The grace period is applied by the server once it begins draining, so the
+			// context is cancelled as soon as the signal is received.
 			signalctx := SignalContextWithGracePeriod(
 				context.Background(),
-				config.ShutdownGracePeriod,
+				0,
 			)
 			return server.Run(signalctx)
 		}),
@@ -4,8 +4,10 @@ import (
 	"context"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/require"
@@ -206,3 +208,82 @@ func TestConfigPrecedence(t *testing.T) {
 		require.Equal(t, uint16(23000), mergedConfig.DatastoreConfig.WatchBufferLength)
 	})
 }
+
+func TestYAMLConfigPrecedence(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "spicedb.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+grpc-preshared-key: [some_key, other_key]
+grpc-addr: 127.0.0.1:31051
+http-enabled: true
+http-addr: 127.0.0.1:31443
+datastore-engine: memory
+datastore-watch-buffer-length: 31000
+`), 0o600))
+
+	// Env variables override the config file
+	t.Setenv("SPICEDB_HTTP_ADDR", "127.0.0.1:32443")
+	t.Setenv("SPICEDB_DATASTORE_WATCH_BUFFER_LENGTH", "32000")
+
+	// command line flags override everything
+	flags := []string{
+		"--config", configPath,
+		"--datastore-watch-buffer-length", "33000",
+	}
+	RunServeTest(t, flags, func(t *testing.T, mergedConfig *server.Config) {
+		require.Equal(t, []string{"some_key", "other_key"}, mergedConfig.PresharedSecureKey)
+		require.Equal(t, "127.0.0.1:31051", mergedConfig.GRPCServer.Address)
+		require.Equal(t, true, mergedConfig.HTTPGateway.HTTPEnabled)
+		require.Equal(t, "127.0.0.1:32443", mergedConfig.HTTPGateway.HTTPAddress)
+		require.Equal(t, "memory", mergedConfig.DatastoreConfig.Engine)
+		require.Equal(t, uint16(33000), mergedConfig.DatastoreConfig.WatchBufferLength)
+	})
+}
+
+func TestGRPCConnectionManagementFlags(t *testing.T) {
+	flags := []string{
+		"--grpc-preshared-key", "some_key",
+		"--grpc-addr", "127.0.0.1:0",
+		"--grpc-max-conn-age", "5m",
+		"--grpc-max-conn-age-grace", "30s",
+		"--grpc-max-conn-idle", "1m",
+		"--grpc-keepalive-time", "20s",
+		"--grpc-keepalive-timeout", "5s",
+		"--grpc-keepalive-min-time", "10s",
+		"--grpc-keepalive-permit-without-call",
+	}
+	RunServeTest(t, flags, func(t *testing.T, mergedConfig *server.Config) {
+		require.Equal(t, 5*time.Minute, mergedConfig.GRPCServer.MaxConnAge)
+		require.Equal(t, 30*time.Second, mergedConfig.GRPCServer.MaxConnAgeGrace)
+		require.Equal(t, time.Minute, mergedConfig.GRPCServer.MaxConnIdle)
+		require.Equal(t, 20*time.Second, mergedConfig.GRPCServer.KeepaliveTime)
+		require.Equal(t, 5*time.Second, mergedConfig.GRPCServer.KeepaliveTimeout)
+		require.Equal(t, 10*time.Second, mergedConfig.GRPCServer.KeepaliveMinTime)
+		require.True(t, mergedConfig.GRPCServer.KeepalivePermitWithoutCall)
+	})
+}
+
+func TestSkipTLSPreset(t *testing.T) {
+	t.Setenv("XDG_DATA_HOME", t.TempDir())
+
+	newCommand := func(args ...string) (*cobra.Command, *server.Config) {
+		config := server.NewConfigWithOptionsAndDefaults()
+		cmd := NewServeCommand("spicedb", config)
+		require.NoError(t, RegisterServeFlags(cmd, config))
+		require.NoError(t, cmd.ParseFlags(args))
+		return cmd, config
+	}
+
+	var warnings strings.Builder
+	cmd, config := newCommand("--skip-tls", "--http-addr", ":8080")
+	require.NoError(t, applySkipTLSPreset(cmd, config, &warnings))
+	require.Contains(t, warnings.String(), "NEVER USE IT IN PRODUCTION")
+	require.Equal(t, "localhost:50051", config.GRPCServer.Address)
+	require.Equal(t, "localhost:9090", config.MetricsAPI.HTTPAddress)
+	require.Equal(t, ":8080", config.HTTPGateway.HTTPAddress)
+	if runtime.GOOS != "windows" && runtime.GOOS != "darwin" {
+		require.Equal(t, filepath.Join(os.Getenv("XDG_DATA_HOME"), "spicedb", "memory.backup"), config.DatastoreConfig.MemoryDataPath)
+	}
+
+	cmd, config = newCommand("--skip-tls", "--grpc-tls-cert-path", "cert.pem", "--grpc-tls-key-path", "key.pem")
+	require.ErrorContains(t, applySkipTLSPreset(cmd, config, &warnings), "--skip-tls cannot be combined with --grpc-tls-cert-path")
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strconv"
@@ -12,6 +13,7 @@ import (
 	"github.com/pbnjay/memory"
 	"github.com/spf13/pflag"
 
+	log "github.com/authzed/spicedb/internal/logging"
 	"github.com/authzed/spicedb/pkg/cache"
 )
 
@@ -38,6 +40,7 @@ type CacheConfig struct {
 	NumCounters int64         `debugmap:"visible"`
 	Metrics     bool          `debugmap:"visible"`
 	Enabled     bool          `debugmap:"visible"`
+	TTL         time.Duration `debugmap:"visible"`
 	defaultTTL  time.Duration `debugmap:"visible"`
 }
 
@@ -59,35 +62,69 @@ func (cc *CacheConfig) Complete() (cache.Cache, error) {
 		return cache.NoopCache(), nil
 	}
 
-	var (
-		maxCost uint64
-		err     error
-	)
-
-	if strings.HasSuffix(cc.MaxCost, "%") {
-		maxCost, err = parsePercent(cc.MaxCost, freeMemory)
-	} else {
-		maxCost, err = humanize.ParseBytes(cc.MaxCost)
-	}
+	maxCost, err := parseMaxCost(cc.MaxCost)
 	if err != nil {
-		return nil, fmt.Errorf("error parsing cache max memory: `%s`: %w", cc.MaxCost, err)
+		return nil, err
+	}
+
+	ttl := cc.defaultTTL
+	if cc.TTL > 0 && (ttl <= 0 || cc.TTL < ttl) {
+		ttl = cc.TTL
 	}
 
 	if cc.Metrics {
 		return cache.NewCacheWithMetrics(cc.Name, &cache.Config{
 			MaxCost:     int64(maxCost),
 			NumCounters: cc.NumCounters,
-			DefaultTTL:  cc.defaultTTL,
+			DefaultTTL:  ttl,
 		})
 	}
 
 	return cache.NewCache(&cache.Config{
 		MaxCost:     int64(maxCost),
 		NumCounters: cc.NumCounters,
-		DefaultTTL:  cc.defaultTTL,
+		DefaultTTL:  ttl,
 	})
 }
 
+// completeDeniedCache returns the cache configured to hold the denied results of checks apart
+// from the dispatch cache, or nil if it is disabled, in which case they are held along with all
+// others.
+func (c *Config) completeDeniedCache(ctx context.Context, name string, cc CacheConfig) (cache.Cache, error) {
+	if !cc.Enabled {
+		return nil, nil
+	}
+
+	dcc, err := cc.WithRevisionParameters(
+		c.DatastoreConfig.RevisionQuantization,
+		c.DatastoreConfig.FollowerReadDelay,
+		c.DatastoreConfig.MaxRevisionStalenessPercent,
+	).Complete()
+	if err != nil {
+		return nil, err
+	}
+	log.Ctx(ctx).Info().EmbedObject(dcc).Str("cache", name).Msg("configured cache for denied check results")
+	return dcc, nil
+}
+
+// parseMaxCost parses a cache size given in bytes or as a percent of available memory.
+func parseMaxCost(str string) (uint64, error) {
+	var (
+		maxCost uint64
+		err     error
+	)
+
+	if strings.HasSuffix(str, "%") {
+		maxCost, err = parsePercent(str, freeMemory)
+	} else {
+		maxCost, err = humanize.ParseBytes(str)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("error parsing cache max memory: `%s`: %w", str, err)
+	}
+	return maxCost, nil
+}
+
 func parsePercent(str string, freeMem uint64) (uint64, error) {
 	percent := strings.TrimSuffix(str, "%")
 	parsedPercent, err := strconv.ParseUint(percent, 10, 64)
@@ -102,6 +139,22 @@ func parsePercent(str string, freeMem uint64) (uint64, error) {
 	return freeMem / 100 * parsedPercent, nil
 }
 
+// cacheBudget returns the memory budget shared by the caches, if any.
+func (c *Config) cacheBudget() (*cache.Budget, error) {
+	if c.MaxCacheMemory == "" {
+		return nil, nil
+	}
+
+	maxCost, err := parseMaxCost(c.MaxCacheMemory)
+	if err != nil {
+		return nil, fmt.Errorf("invalid max cache memory: %w", err)
+	}
+	if maxCost == 0 {
+		return nil, fmt.Errorf("invalid max cache memory `%s`: must be greater than zero", c.MaxCacheMemory)
+	}
+	return cache.NewBudget(int64(maxCost)), nil
+}
+
 // RegisterCacheFlags registers flags used to configure SpiceDB's various
 // caches.
 func RegisterCacheFlags(flags *pflag.FlagSet, flagPrefix string, config, defaults *CacheConfig) {
@@ -111,4 +164,5 @@ func RegisterCacheFlags(flags *pflag.FlagSet, flagPrefix string, config, default
 	flags.Int64Var(&config.NumCounters, flagPrefix+"-num-counters", defaults.NumCounters, "number of TinyLFU samples to track")
 	flags.BoolVar(&config.Metrics, flagPrefix+"-metrics", defaults.Metrics, "enable cache metrics")
 	flags.BoolVar(&config.Enabled, flagPrefix+"-enabled", defaults.Enabled, "enable caching")
+	flags.DurationVar(&config.TTL, flagPrefix+"-ttl", defaults.TTL, "maximum lifetime of cached entries, when shorter than that implied by the revision quantization (0 means no maximum)")
 }
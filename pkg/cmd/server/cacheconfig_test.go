@@ -30,3 +30,20 @@ func TestParsePercent(t *testing.T) {
 		require.Equal(t, tt.expected, v)
 	}
 }
+
+func TestCacheBudget(t *testing.T) {
+	budget, err := (&Config{}).cacheBudget()
+	require.NoError(t, err)
+	require.Nil(t, budget)
+
+	budget, err = (&Config{MaxCacheMemory: "64MiB"}).cacheBudget()
+	require.NoError(t, err)
+	t.Cleanup(budget.Close)
+	require.Equal(t, int64(64<<20), budget.MaxCost())
+
+	_, err = (&Config{MaxCacheMemory: "0"}).cacheBudget()
+	require.ErrorContains(t, err, "must be greater than zero")
+
+	_, err = (&Config{MaxCacheMemory: "lots"}).cacheBudget()
+	require.ErrorContains(t, err, "invalid max cache memory")
+}
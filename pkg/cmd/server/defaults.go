@@ -2,10 +2,15 @@ package server
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/pprof"
+	"slices"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/fatih/color"
@@ -23,17 +28,37 @@ import (
 	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 
+	"github.com/authzed/spicedb/internal/accessreport"
+	"github.com/authzed/spicedb/internal/auth"
 	"github.com/authzed/spicedb/internal/dispatch"
+	"github.com/authzed/spicedb/internal/introspection"
 	"github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/middleware/audit"
 	consistencymw "github.com/authzed/spicedb/internal/middleware/consistency"
 	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/middleware/deadline"
+	"github.com/authzed/spicedb/internal/middleware/debuglog"
+	"github.com/authzed/spicedb/internal/middleware/decisionlog"
+	"github.com/authzed/spicedb/internal/middleware/errorreport"
+	"github.com/authzed/spicedb/internal/middleware/faultinjection"
 	dispatchmw "github.com/authzed/spicedb/internal/middleware/dispatcher"
+	"github.com/authzed/spicedb/internal/middleware/ratelimit"
+	"github.com/authzed/spicedb/internal/middleware/recovery"
+	"github.com/authzed/spicedb/internal/middleware/schemawebhook"
 	"github.com/authzed/spicedb/internal/middleware/servicespecific"
+	"github.com/authzed/spicedb/internal/middleware/shadowcheck"
+	"github.com/authzed/spicedb/internal/middleware/tenancy"
+	"github.com/authzed/spicedb/internal/middleware/usage"
+	"github.com/authzed/spicedb/pkg/cmd/configfile"
 	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/middleware/clientidentity"
 	logmw "github.com/authzed/spicedb/pkg/middleware/logging"
 	"github.com/authzed/spicedb/pkg/middleware/requestid"
 	"github.com/authzed/spicedb/pkg/middleware/serverversion"
+	"github.com/authzed/spicedb/pkg/middleware/transform"
 	"github.com/authzed/spicedb/pkg/releases"
 	"github.com/authzed/spicedb/pkg/runtime"
 )
@@ -56,11 +81,12 @@ func ServeExample(programName string) string {
 	)
 }
 
-// DefaultPreRunE sets up viper, zerolog, and OpenTelemetry flag handling for a
-// command.
+// DefaultPreRunE sets up viper, config file, zerolog, and OpenTelemetry flag
+// handling for a command.
 func DefaultPreRunE(programName string) cobrautil.CobraRunFunc {
 	return cobrautil.CommandStack(
 		cobrautil.SyncViperDotEnvPreRunE(programName, "spicedb.env", zerologr.New(&logging.Logger)),
+		configfile.RunE(),
 		cobrazerolog.New(
 			cobrazerolog.WithTarget(func(logger zerolog.Logger) {
 				logging.SetGlobalLogger(logger)
@@ -75,8 +101,9 @@ func DefaultPreRunE(programName string) cobrautil.CobraRunFunc {
 }
 
 // MetricsHandler sets up an HTTP server that handles serving Prometheus
-// metrics and pprof endpoints.
-func MetricsHandler(telemetryRegistry *prometheus.Registry, c *Config) http.Handler {
+// metrics, and liveness and readiness probes mirroring the gRPC health service
+// if one is given.
+func MetricsHandler(telemetryRegistry *prometheus.Registry, c *Config, healthSvc healthpb.HealthServer) http.Handler {
 	mux := http.NewServeMux()
 
 	mux.Handle("/metrics", promhttp.HandlerFor(prometheus.DefaultGatherer, promhttp.HandlerOpts{
@@ -87,6 +114,24 @@ func MetricsHandler(telemetryRegistry *prometheus.Registry, c *Config) http.Hand
 		mux.Handle("/telemetry", promhttp.HandlerFor(telemetryRegistry, promhttp.HandlerOpts{}))
 	}
 
+	if healthSvc != nil {
+		// The server is live as long as it can respond; only readiness depends on the
+		// health of its dependencies.
+		mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+			fmt.Fprint(w, "ok")
+		})
+		mux.HandleFunc("/readyz", readinessHandler(healthSvc))
+	}
+
+	return mux
+}
+
+// DebugHandler sets up an HTTP server that handles serving the pprof, configuration,
+// introspection, usage, access report and log level endpoints. Requests are only served from loopback
+// addresses, or with one of the preshared keys as a bearer token.
+func DebugHandler(c *Config, presharedKeys func() []string, usageAccountant *usage.Accountant, accessReports *accessreport.Manager, logOverrides *debuglog.Overrides) http.Handler {
+	mux := http.NewServeMux()
+
 	mux.HandleFunc("/debug/pprof/", pprof.Index)
 	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
 	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
@@ -109,8 +154,65 @@ func MetricsHandler(telemetryRegistry *prometheus.Registry, c *Config) http.Hand
 
 		fmt.Fprintf(w, "%s", string(json))
 	})
+	introspection.RegisterHandlers(mux)
+	usageAccountant.RegisterHandlers(mux)
+	accessReports.RegisterHandlers(mux)
+	logOverrides.RegisterHandlers(mux)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !isLoopbackRequest(r) && !hasPresharedKey(r, presharedKeys()) {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "debug endpoints require a preshared key as bearer token when not accessed from localhost", http.StatusUnauthorized)
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
 
-	return mux
+func isLoopbackRequest(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func hasPresharedKey(r *http.Request, presharedKeys []string) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		return false
+	}
+	for _, key := range presharedKeys {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(key)) == 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// readinessHandler reports the status of the service named by the `service` query
+// parameter, or of the overall server if absent, as reported by the gRPC health service.
+func readinessHandler(healthSvc healthpb.HealthServer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		resp, err := healthSvc.Check(r.Context(), &healthpb.HealthCheckRequest{
+			Service: r.URL.Query().Get("service"),
+		})
+		if status.Code(err) == codes.NotFound {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "unknown service")
+			return
+		} else if err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, err.Error())
+			return
+		}
+
+		if resp.Status != healthpb.HealthCheckResponse_SERVING {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		fmt.Fprint(w, strings.ToLower(resp.Status.String()))
+	}
 }
 
 var defaultGRPCLogOptions = []grpclog.Option{
@@ -128,17 +230,33 @@ var defaultGRPCLogOptions = []grpclog.Option{
 }
 
 const (
-	DefaultMiddlewareRequestID     = "requestid"
-	DefaultMiddlewareLog           = "log"
-	DefaultMiddlewareGRPCLog       = "grpclog"
-	DefaultMiddlewareOTelGRPC      = "otelgrpc"
-	DefaultMiddlewareGRPCAuth      = "grpcauth"
-	DefaultMiddlewareGRPCProm      = "grpcprom"
-	DefaultMiddlewareServerVersion = "serverversion"
+	DefaultMiddlewareRequestID      = "requestid"
+	DefaultMiddlewareLog            = "log"
+	DefaultMiddlewareClientIdentity = "clientidentity"
+	DefaultMiddlewareLogSampling    = "logsampling"
+	DefaultMiddlewareDebugLog       = "debuglog"
+	DefaultMiddlewareGRPCLog        = "grpclog"
+	DefaultMiddlewareOTelGRPC       = "otelgrpc"
+	DefaultMiddlewareGRPCAuth       = "grpcauth"
+	DefaultMiddlewareTokenScope     = "tokenscope"
+	DefaultMiddlewareTenancy        = "tenancy"
+	DefaultMiddlewareRateLimit      = "ratelimit"
+	DefaultMiddlewareUsage          = "usage"
+	DefaultMiddlewareDeadline       = "deadline"
+	DefaultMiddlewareFaultInjection = "faultinjection"
+	DefaultMiddlewareGRPCProm       = "grpcprom"
+	DefaultMiddlewareRecovery       = "recovery"
+	DefaultMiddlewareErrorReport    = "errorreport"
+	DefaultMiddlewareServerVersion  = "serverversion"
 
 	DefaultInternalMiddlewareDispatch       = "dispatch"
 	DefaultInternalMiddlewareDatastore      = "datastore"
+	DefaultInternalMiddlewareAudit          = "audit"
+	DefaultInternalMiddlewareDecisionLog    = "decisionlog"
+	DefaultInternalMiddlewareSchemaWebhook  = "schemawebhook"
 	DefaultInternalMiddlewareConsistency    = "consistency"
+	DefaultInternalMiddlewareShadowCheck    = "shadowcheck"
+	DefaultInternalMiddlewareTransform      = "transform"
 	DefaultInternalMiddlewareServerSpecific = "servicespecific"
 )
 
@@ -150,6 +268,20 @@ type MiddlewareOption struct {
 	ds                    datastore.Datastore
 	enableRequestLog      bool
 	enableResponseLog     bool
+	rateLimiter           *ratelimit.Limiter
+	auditSink             audit.Sink
+	logSampler            *logmw.Sampler
+	consistencyOptions    []consistencymw.Option
+	requestTimeouts       map[deadline.Class]deadline.Timeouts
+	schemaWebhookNotifier *schemawebhook.Notifier
+	errorReporter         *errorreport.Reporter
+	decisionLogger        *decisionlog.Logger
+	tenancyEnabled        bool
+	faultInjector         *faultinjection.Injector
+	shadower              *shadowcheck.Shadower
+	transforms            transform.Transforms
+	usageAccountant       *usage.Accountant
+	logOverrides          *debuglog.Overrides
 }
 
 // GRPCMetricsUnaryInterceptor creates the default prometheus metrics interceptor for unary gRPCs
@@ -158,13 +290,43 @@ var GRPCMetricsUnaryInterceptor grpc.UnaryServerInterceptor
 // GRPCMetricsStreamingInterceptor creates the default prometheus metrics interceptor for streaming gRPCs
 var GRPCMetricsStreamingInterceptor grpc.StreamServerInterceptor
 
+// DefaultGRPCMetricsLatencyBuckets are the buckets, in seconds, of the histogram of gRPC
+// handling time.
+var DefaultGRPCMetricsLatencyBuckets = []float64{.001, .003, .006, .010, .018, .024, .032, .042, .056, .075, .100, .178, .316, .562, 1, 5}
+
+var (
+	grpcMetricsLock    sync.Mutex
+	grpcMetrics        *grpcprom.ServerMetrics
+	grpcMetricsBuckets []float64
+)
+
 func init() {
-	GRPCMetricsUnaryInterceptor, GRPCMetricsStreamingInterceptor = createServerMetrics()
+	GRPCMetricsUnaryInterceptor, GRPCMetricsStreamingInterceptor = createServerMetrics(DefaultGRPCMetricsLatencyBuckets)
+}
+
+// ConfigureGRPCMetricsLatencyBuckets replaces the gRPC server metrics with ones whose
+// histogram of handling time has the given buckets. It must be called before the
+// middleware is built.
+func ConfigureGRPCMetricsLatencyBuckets(buckets []float64) {
+	grpcMetricsLock.Lock()
+	equal := slices.Equal(buckets, grpcMetricsBuckets)
+	grpcMetricsLock.Unlock()
+
+	if !equal {
+		GRPCMetricsUnaryInterceptor, GRPCMetricsStreamingInterceptor = createServerMetrics(buckets)
+	}
 }
 
 // DefaultUnaryMiddleware generates the default middleware chain used for the public SpiceDB Unary gRPC methods
 func DefaultUnaryMiddleware(opts MiddlewareOption) (*MiddlewareChain[grpc.UnaryServerInterceptor], error) {
 	chain, err := NewMiddlewareChain([]ReferenceableMiddleware[grpc.UnaryServerInterceptor]{
+		// Tracing comes first, so that the trace ID is known to the request ID and logging
+		// middleware.
+		NewUnaryMiddleware().
+			WithName(DefaultMiddlewareOTelGRPC).
+			WithInterceptor(otelgrpc.UnaryServerInterceptor()). // nolint: staticcheck
+			Done(),
+
 		NewUnaryMiddleware().
 			WithName(DefaultMiddlewareRequestID).
 			WithInterceptor(requestid.UnaryServerInterceptor(requestid.GenerateIfMissing(true))).
@@ -176,13 +338,23 @@ func DefaultUnaryMiddleware(opts MiddlewareOption) (*MiddlewareChain[grpc.UnaryS
 			Done(),
 
 		NewUnaryMiddleware().
-			WithName(DefaultMiddlewareGRPCLog).
-			WithInterceptor(grpclog.UnaryServerInterceptor(InterceptorLogger(opts.logger), determineEventsToLog(opts)...)).
+			WithName(DefaultMiddlewareClientIdentity).
+			WithInterceptor(clientidentity.UnaryServerInterceptor()).
 			Done(),
 
 		NewUnaryMiddleware().
-			WithName(DefaultMiddlewareOTelGRPC).
-			WithInterceptor(otelgrpc.UnaryServerInterceptor()). // nolint: staticcheck
+			WithName(DefaultMiddlewareLogSampling).
+			WithInterceptor(logmw.UnarySamplingServerInterceptor(opts.logSampler)).
+			Done(),
+
+		NewUnaryMiddleware().
+			WithName(DefaultMiddlewareDebugLog).
+			WithInterceptor(debuglog.UnaryServerInterceptor(opts.logOverrides)).
+			Done(),
+
+		NewUnaryMiddleware().
+			WithName(DefaultMiddlewareGRPCLog).
+			WithInterceptor(grpclog.UnaryServerInterceptor(InterceptorLogger(opts.logger), determineEventsToLog(opts)...)).
 			Done(),
 
 		NewUnaryMiddleware().
@@ -190,12 +362,57 @@ func DefaultUnaryMiddleware(opts MiddlewareOption) (*MiddlewareChain[grpc.UnaryS
 			WithInterceptor(GRPCMetricsUnaryInterceptor).
 			Done(),
 
+		NewUnaryMiddleware().
+			WithName(DefaultMiddlewareRecovery).
+			WithInterceptor(recovery.UnaryServerInterceptor()).
+			Done(),
+
+		NewUnaryMiddleware().
+			WithName(DefaultMiddlewareErrorReport).
+			WithInterceptor(errorreport.UnaryServerInterceptor(opts.errorReporter)).
+			Done(),
+
 		NewUnaryMiddleware().
 			WithName(DefaultMiddlewareGRPCAuth).
 			WithInterceptor(grpcauth.UnaryServerInterceptor(opts.authFunc)).
 			EnsureAlreadyExecuted(DefaultMiddlewareGRPCProm). // so that prom middleware reports auth failures
 			Done(),
 
+		NewUnaryMiddleware().
+			WithName(DefaultMiddlewareTokenScope).
+			WithInterceptor(auth.ScopeUnaryServerInterceptor()).
+			EnsureAlreadyExecuted(DefaultMiddlewareGRPCAuth). // so that the token's scope is known
+			Done(),
+
+		NewUnaryMiddleware().
+			WithName(DefaultMiddlewareTenancy).
+			WithInterceptor(tenancy.UnaryServerInterceptor(opts.tenancyEnabled)).
+			EnsureAlreadyExecuted(DefaultMiddlewareTokenScope). // so that scopes apply to the tenant's unprefixed definitions
+			Done(),
+
+		NewUnaryMiddleware().
+			WithName(DefaultMiddlewareRateLimit).
+			WithInterceptor(ratelimit.UnaryServerInterceptor(opts.rateLimiter)).
+			EnsureAlreadyExecuted(DefaultMiddlewareGRPCAuth). // so that callers are identified
+			Done(),
+
+		NewUnaryMiddleware().
+			WithName(DefaultMiddlewareUsage).
+			WithInterceptor(usage.UnaryServerInterceptor(opts.usageAccountant)).
+			EnsureAlreadyExecuted(DefaultMiddlewareTenancy). // so that requests are accounted to their tenant
+			Done(),
+
+		NewUnaryMiddleware().
+			WithName(DefaultMiddlewareDeadline).
+			WithInterceptor(deadline.UnaryServerInterceptor(opts.requestTimeouts)).
+			Done(),
+
+		NewUnaryMiddleware().
+			WithName(DefaultMiddlewareFaultInjection).
+			WithInterceptor(faultinjection.UnaryServerInterceptor(opts.faultInjector)).
+			EnsureAlreadyExecuted(DefaultMiddlewareDeadline). // so that injected latency is bounded by deadlines
+			Done(),
+
 		NewUnaryMiddleware().
 			WithName(DefaultMiddlewareServerVersion).
 			WithInterceptor(serverversion.UnaryServerInterceptor(opts.enableVersionResponse)).
@@ -213,10 +430,41 @@ func DefaultUnaryMiddleware(opts MiddlewareOption) (*MiddlewareChain[grpc.UnaryS
 			WithInterceptor(datastoremw.UnaryServerInterceptor(opts.ds)).
 			Done(),
 
+		NewUnaryMiddleware().
+			WithName(DefaultInternalMiddlewareAudit).
+			WithInternal(true).
+			WithInterceptor(audit.UnaryServerInterceptor(opts.auditSink)).
+			Done(),
+
+		NewUnaryMiddleware().
+			WithName(DefaultInternalMiddlewareDecisionLog).
+			WithInternal(true).
+			WithInterceptor(decisionlog.UnaryServerInterceptor(opts.decisionLogger)).
+			Done(),
+
+		NewUnaryMiddleware().
+			WithName(DefaultInternalMiddlewareSchemaWebhook).
+			WithInternal(true).
+			WithInterceptor(schemawebhook.UnaryServerInterceptor(opts.schemaWebhookNotifier)).
+			Done(),
+
 		NewUnaryMiddleware().
 			WithName(DefaultInternalMiddlewareConsistency).
 			WithInternal(true).
-			WithInterceptor(consistencymw.UnaryServerInterceptor()).
+			WithInterceptor(consistencymw.UnaryServerInterceptor(opts.consistencyOptions...)).
+			Done(),
+
+		NewUnaryMiddleware().
+			WithName(DefaultInternalMiddlewareShadowCheck).
+			WithInternal(true).
+			WithInterceptor(shadowcheck.UnaryServerInterceptor(opts.shadower)).
+			EnsureAlreadyExecuted(DefaultInternalMiddlewareDatastore). // so that mirrored checks can read the datastore
+			Done(),
+
+		NewUnaryMiddleware().
+			WithName(DefaultInternalMiddlewareTransform).
+			WithInternal(true).
+			WithInterceptor(transform.UnaryServerInterceptor(opts.transforms)).
 			Done(),
 
 		NewUnaryMiddleware().
@@ -231,6 +479,13 @@ func DefaultUnaryMiddleware(opts MiddlewareOption) (*MiddlewareChain[grpc.UnaryS
 // DefaultStreamingMiddleware generates the default middleware chain used for the public SpiceDB Streaming gRPC methods
 func DefaultStreamingMiddleware(opts MiddlewareOption) (*MiddlewareChain[grpc.StreamServerInterceptor], error) {
 	chain, err := NewMiddlewareChain([]ReferenceableMiddleware[grpc.StreamServerInterceptor]{
+		// Tracing comes first, so that the trace ID is known to the request ID and logging
+		// middleware.
+		NewStreamMiddleware().
+			WithName(DefaultMiddlewareOTelGRPC).
+			WithInterceptor(otelgrpc.StreamServerInterceptor()). // nolint: staticcheck
+			Done(),
+
 		NewStreamMiddleware().
 			WithName(DefaultMiddlewareRequestID).
 			WithInterceptor(requestid.StreamServerInterceptor(requestid.GenerateIfMissing(true))).
@@ -242,13 +497,23 @@ func DefaultStreamingMiddleware(opts MiddlewareOption) (*MiddlewareChain[grpc.St
 			Done(),
 
 		NewStreamMiddleware().
-			WithName(DefaultMiddlewareGRPCLog).
-			WithInterceptor(grpclog.StreamServerInterceptor(InterceptorLogger(opts.logger), determineEventsToLog(opts)...)).
+			WithName(DefaultMiddlewareClientIdentity).
+			WithInterceptor(clientidentity.StreamServerInterceptor()).
 			Done(),
 
 		NewStreamMiddleware().
-			WithName(DefaultMiddlewareOTelGRPC).
-			WithInterceptor(otelgrpc.StreamServerInterceptor()). // nolint: staticcheck
+			WithName(DefaultMiddlewareLogSampling).
+			WithInterceptor(logmw.StreamSamplingServerInterceptor(opts.logSampler)).
+			Done(),
+
+		NewStreamMiddleware().
+			WithName(DefaultMiddlewareDebugLog).
+			WithInterceptor(debuglog.StreamServerInterceptor(opts.logOverrides)).
+			Done(),
+
+		NewStreamMiddleware().
+			WithName(DefaultMiddlewareGRPCLog).
+			WithInterceptor(grpclog.StreamServerInterceptor(InterceptorLogger(opts.logger), determineEventsToLog(opts)...)).
 			Done(),
 
 		NewStreamMiddleware().
@@ -256,12 +521,57 @@ func DefaultStreamingMiddleware(opts MiddlewareOption) (*MiddlewareChain[grpc.St
 			WithInterceptor(GRPCMetricsStreamingInterceptor).
 			Done(),
 
+		NewStreamMiddleware().
+			WithName(DefaultMiddlewareRecovery).
+			WithInterceptor(recovery.StreamServerInterceptor()).
+			Done(),
+
+		NewStreamMiddleware().
+			WithName(DefaultMiddlewareErrorReport).
+			WithInterceptor(errorreport.StreamServerInterceptor(opts.errorReporter)).
+			Done(),
+
 		NewStreamMiddleware().
 			WithName(DefaultMiddlewareGRPCAuth).
 			WithInterceptor(grpcauth.StreamServerInterceptor(opts.authFunc)).
 			EnsureInterceptorAlreadyExecuted(DefaultMiddlewareGRPCProm). // so that prom middleware reports auth failures
 			Done(),
 
+		NewStreamMiddleware().
+			WithName(DefaultMiddlewareTokenScope).
+			WithInterceptor(auth.ScopeStreamServerInterceptor()).
+			EnsureInterceptorAlreadyExecuted(DefaultMiddlewareGRPCAuth). // so that the token's scope is known
+			Done(),
+
+		NewStreamMiddleware().
+			WithName(DefaultMiddlewareTenancy).
+			WithInterceptor(tenancy.StreamServerInterceptor(opts.tenancyEnabled)).
+			EnsureInterceptorAlreadyExecuted(DefaultMiddlewareTokenScope). // so that scopes apply to the tenant's unprefixed definitions
+			Done(),
+
+		NewStreamMiddleware().
+			WithName(DefaultMiddlewareRateLimit).
+			WithInterceptor(ratelimit.StreamServerInterceptor(opts.rateLimiter)).
+			EnsureInterceptorAlreadyExecuted(DefaultMiddlewareGRPCAuth). // so that callers are identified
+			Done(),
+
+		NewStreamMiddleware().
+			WithName(DefaultMiddlewareUsage).
+			WithInterceptor(usage.StreamServerInterceptor(opts.usageAccountant)).
+			EnsureInterceptorAlreadyExecuted(DefaultMiddlewareTenancy). // so that streams are accounted to their tenant
+			Done(),
+
+		NewStreamMiddleware().
+			WithName(DefaultMiddlewareDeadline).
+			WithInterceptor(deadline.StreamServerInterceptor(opts.requestTimeouts)).
+			Done(),
+
+		NewStreamMiddleware().
+			WithName(DefaultMiddlewareFaultInjection).
+			WithInterceptor(faultinjection.StreamServerInterceptor(opts.faultInjector)).
+			EnsureInterceptorAlreadyExecuted(DefaultMiddlewareDeadline). // so that injected latency is bounded by deadlines
+			Done(),
+
 		NewStreamMiddleware().
 			WithName(DefaultMiddlewareServerVersion).
 			WithInterceptor(serverversion.StreamServerInterceptor(opts.enableVersionResponse)).
@@ -279,10 +589,41 @@ func DefaultStreamingMiddleware(opts MiddlewareOption) (*MiddlewareChain[grpc.St
 			WithInterceptor(datastoremw.StreamServerInterceptor(opts.ds)).
 			Done(),
 
+		NewStreamMiddleware().
+			WithName(DefaultInternalMiddlewareAudit).
+			WithInternal(true).
+			WithInterceptor(audit.StreamServerInterceptor(opts.auditSink)).
+			Done(),
+
+		NewStreamMiddleware().
+			WithName(DefaultInternalMiddlewareDecisionLog).
+			WithInternal(true).
+			WithInterceptor(decisionlog.StreamServerInterceptor(opts.decisionLogger)).
+			Done(),
+
+		NewStreamMiddleware().
+			WithName(DefaultInternalMiddlewareSchemaWebhook).
+			WithInternal(true).
+			WithInterceptor(schemawebhook.StreamServerInterceptor(opts.schemaWebhookNotifier)).
+			Done(),
+
 		NewStreamMiddleware().
 			WithName(DefaultInternalMiddlewareConsistency).
 			WithInternal(true).
-			WithInterceptor(consistencymw.StreamServerInterceptor()).
+			WithInterceptor(consistencymw.StreamServerInterceptor(opts.consistencyOptions...)).
+			Done(),
+
+		NewStreamMiddleware().
+			WithName(DefaultInternalMiddlewareShadowCheck).
+			WithInternal(true).
+			WithInterceptor(shadowcheck.StreamServerInterceptor(opts.shadower)).
+			EnsureInterceptorAlreadyExecuted(DefaultInternalMiddlewareDatastore). // so that mirrored checks can read the datastore
+			Done(),
+
+		NewStreamMiddleware().
+			WithName(DefaultInternalMiddlewareTransform).
+			WithInternal(true).
+			WithInterceptor(transform.StreamServerInterceptor(opts.transforms)).
 			Done(),
 
 		NewStreamMiddleware().
@@ -305,7 +646,10 @@ func determineEventsToLog(opts MiddlewareOption) []grpclog.Option {
 	}
 
 	logOnEvents := grpclog.WithLogOnEvents(eventsToLog...)
-	grpcLogOptions := append(defaultGRPCLogOptions, logOnEvents)
+	callerField := grpclog.WithFieldsFromContext(func(ctx context.Context) grpclog.Fields {
+		return grpclog.Fields{"grpc.caller", auth.PrincipalFromContext(ctx)}
+	})
+	grpcLogOptions := append(defaultGRPCLogOptions, logOnEvents, callerField)
 
 	return grpcLogOptions
 }
@@ -313,20 +657,22 @@ func determineEventsToLog(opts MiddlewareOption) []grpclog.Option {
 // DefaultDispatchMiddleware generates the default middleware chain used for the internal dispatch SpiceDB gRPC API
 func DefaultDispatchMiddleware(logger zerolog.Logger, authFunc grpcauth.AuthFunc, ds datastore.Datastore) ([]grpc.UnaryServerInterceptor, []grpc.StreamServerInterceptor) {
 	return []grpc.UnaryServerInterceptor{
+			otelgrpc.UnaryServerInterceptor(), // nolint: staticcheck
 			requestid.UnaryServerInterceptor(requestid.GenerateIfMissing(true)),
 			logmw.UnaryServerInterceptor(logmw.ExtractMetadataField("x-request-id", "requestID")),
 			grpclog.UnaryServerInterceptor(InterceptorLogger(logger), defaultGRPCLogOptions...),
-			otelgrpc.UnaryServerInterceptor(), // nolint: staticcheck
 			GRPCMetricsUnaryInterceptor,
+			recovery.UnaryServerInterceptor(),
 			grpcauth.UnaryServerInterceptor(authFunc),
 			datastoremw.UnaryServerInterceptor(ds),
 			servicespecific.UnaryServerInterceptor,
 		}, []grpc.StreamServerInterceptor{
+			otelgrpc.StreamServerInterceptor(), // nolint: staticcheck
 			requestid.StreamServerInterceptor(requestid.GenerateIfMissing(true)),
 			logmw.StreamServerInterceptor(logmw.ExtractMetadataField("x-request-id", "requestID")),
 			grpclog.StreamServerInterceptor(InterceptorLogger(logger), defaultGRPCLogOptions...),
-			otelgrpc.StreamServerInterceptor(), // nolint: staticcheck
 			GRPCMetricsStreamingInterceptor,
+			recovery.StreamServerInterceptor(),
 			grpcauth.StreamServerInterceptor(authFunc),
 			datastoremw.StreamServerInterceptor(ds),
 			servicespecific.StreamServerInterceptor,
@@ -335,6 +681,11 @@ func DefaultDispatchMiddleware(logger zerolog.Logger, authFunc grpcauth.AuthFunc
 
 func InterceptorLogger(l zerolog.Logger) grpclog.Logger {
 	return grpclog.LoggerFunc(func(ctx context.Context, lvl grpclog.Level, msg string, fields ...any) {
+		// calls which were not sampled are only logged if they failed
+		if lvl < grpclog.LevelWarn && !logmw.IsSampled(ctx) {
+			return
+		}
+
 		l := l.With().Fields(fields).Logger()
 
 		switch lvl {
@@ -353,15 +704,23 @@ func InterceptorLogger(l zerolog.Logger) grpclog.Logger {
 	})
 }
 
-// initializes prometheus grpc interceptors with exemplar support enabled
-func createServerMetrics() (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
+// initializes prometheus grpc interceptors with exemplar support enabled, replacing those
+// previously registered
+func createServerMetrics(buckets []float64) (grpc.UnaryServerInterceptor, grpc.StreamServerInterceptor) {
 	srvMetrics := grpcprom.NewServerMetrics(
 		grpcprom.WithServerHandlingTimeHistogram(
-			grpcprom.WithHistogramBuckets([]float64{.001, .003, .006, .010, .018, .024, .032, .042, .056, .075, .100, .178, .316, .562, 1, 5}),
+			grpcprom.WithHistogramBuckets(buckets),
 		),
 	)
 
+	grpcMetricsLock.Lock()
+	if grpcMetrics != nil {
+		prometheus.DefaultRegisterer.Unregister(grpcMetrics)
+	}
 	prometheus.DefaultRegisterer.MustRegister(srvMetrics)
+	grpcMetrics, grpcMetricsBuckets = srvMetrics, buckets
+	grpcMetricsLock.Unlock()
+
 	exemplarFromContext := func(ctx context.Context) prometheus.Labels {
 		if span := trace.SpanContextFromContext(ctx); span.IsSampled() {
 			return prometheus.Labels{"traceID": span.TraceID().String()}
@@ -0,0 +1,262 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/rs/zerolog"
+	"gopkg.in/yaml.v3"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/middleware/ratelimit"
+	"github.com/authzed/spicedb/pkg/cache"
+)
+
+// reloadableConfig is the subset of the configuration which can be changed without
+// restarting the server, read from the file at Config.ReloadConfigPath. Its keys are the
+// names of the corresponding flags, and settings absent from the file keep their value
+// from flags.
+type reloadableConfig struct {
+	LogLevel      string   `yaml:"log-level"`
+	PresharedKeys []string `yaml:"grpc-preshared-key"`
+
+	RateLimitReadsPerSecond  *float64 `yaml:"grpc-ratelimit-reads-per-second"`
+	RateLimitReadBurst       *int     `yaml:"grpc-ratelimit-read-burst"`
+	RateLimitWritesPerSecond *float64 `yaml:"grpc-ratelimit-writes-per-second"`
+	RateLimitWriteBurst      *int     `yaml:"grpc-ratelimit-write-burst"`
+	MaxInFlightRequests      *uint32  `yaml:"grpc-max-inflight-requests"`
+
+	NamespaceCacheMaxCost       string `yaml:"ns-cache-max-cost"`
+	DispatchCacheMaxCost        string `yaml:"dispatch-cache-max-cost"`
+	ClusterDispatchCacheMaxCost string `yaml:"dispatch-cluster-cache-max-cost"`
+}
+
+// configReloader applies the reloadable configuration when the server starts, and again
+// whenever the process receives SIGHUP or the file changes. Connections and requests in
+// flight are unaffected; TLS certificates are reloaded separately by their own watchers.
+type configReloader struct {
+	path string
+
+	flagLogLevel       zerolog.Level
+	flagPresharedKeys  []string
+	flagRateLimits     ratelimit.Config
+	vaultPresharedKeys bool
+
+	presharedKeys atomic.Pointer[[]string]
+	limiter       *ratelimit.Limiter
+	caches        map[string]resizableCache
+	cacheMaxCosts map[string]int64
+	cacheBudget   *cache.Budget
+
+	lastContents []byte
+}
+
+// resizableCache is a cache whose size can be reloaded, with the size given by its flag.
+type resizableCache struct {
+	cache       cache.Resizable
+	flagMaxCost int64
+}
+
+// newConfigReloader returns a reloader of the file at path, with the configuration given by
+// flags for settings absent from the file, after applying the file for the first time. The
+// log level is enforced through the global level, so the global logger must not filter
+// events by level itself.
+func newConfigReloader(ctx context.Context, path string, logLevel zerolog.Level, presharedKeys []string, vaultPresharedKeys bool, rateLimits ratelimit.Config) (*configReloader, error) {
+	r := &configReloader{
+		path:               path,
+		flagLogLevel:       logLevel,
+		flagPresharedKeys:  presharedKeys,
+		flagRateLimits:     rateLimits,
+		vaultPresharedKeys: vaultPresharedKeys,
+		limiter:            ratelimit.NewUpdatableLimiter(rateLimits),
+		caches:             map[string]resizableCache{},
+	}
+	r.presharedKeys.Store(&presharedKeys)
+
+	zerolog.SetGlobalLevel(r.flagLogLevel)
+
+	if err := r.reload(ctx); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// PresharedKeys returns the preshared keys with which API requests are authenticated.
+func (r *configReloader) PresharedKeys() []string {
+	return *r.presharedKeys.Load()
+}
+
+// addCache registers a cache, under the prefix of its flags, to be resized, and applies its
+// size from the file. Caches which are disabled cannot be resized, and ignore their setting.
+func (r *configReloader) addCache(name string, c cache.Cache, config CacheConfig) {
+	resizable, ok := c.(cache.Resizable)
+	if !ok {
+		return
+	}
+
+	flagMaxCost, err := parseMaxCost(config.MaxCost)
+	if err != nil {
+		return
+	}
+	r.caches[name] = resizableCache{resizable, int64(flagMaxCost)}
+
+	if maxCost, ok := r.cacheMaxCosts[name]; ok {
+		r.resizeCache(name, resizable, maxCost)
+	}
+}
+
+// resizeCache applies the size of the cache of the given name, within the memory budget if any.
+func (r *configReloader) resizeCache(name string, c cache.Resizable, maxCost int64) {
+	if r.cacheBudget != nil {
+		r.cacheBudget.Request(name, maxCost)
+		return
+	}
+	c.UpdateMaxCost(maxCost)
+}
+
+// reload reads the file and applies its settings. The settings are all validated before any
+// is applied, so that an invalid file leaves the configuration unchanged.
+func (r *configReloader) reload(ctx context.Context) error {
+	contents, err := os.ReadFile(r.path)
+	if err != nil {
+		return fmt.Errorf("failed to read reloadable config: %w", err)
+	}
+
+	var config reloadableConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(contents))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("failed to parse reloadable config `%s`: %w", r.path, err)
+	}
+
+	logLevel := r.flagLogLevel
+	if config.LogLevel != "" {
+		logLevel, err = zerolog.ParseLevel(config.LogLevel)
+		if err != nil || logLevel == zerolog.NoLevel {
+			return fmt.Errorf("invalid log-level `%s`", config.LogLevel)
+		}
+	}
+
+	presharedKeys := r.flagPresharedKeys
+	if len(config.PresharedKeys) > 0 {
+		if r.vaultPresharedKeys {
+			return errors.New("preshared keys cannot be given both by the reloadable config and from Vault")
+		}
+		for index, key := range config.PresharedKeys {
+			if key == "" {
+				return fmt.Errorf("preshared key #%d is empty", index+1)
+			}
+		}
+		presharedKeys = config.PresharedKeys
+	}
+
+	rateLimits := r.flagRateLimits
+	setIfPresent(&rateLimits.ReadsPerSecond, config.RateLimitReadsPerSecond)
+	setIfPresent(&rateLimits.ReadBurst, config.RateLimitReadBurst)
+	setIfPresent(&rateLimits.WritesPerSecond, config.RateLimitWritesPerSecond)
+	setIfPresent(&rateLimits.WriteBurst, config.RateLimitWriteBurst)
+	setIfPresent(&rateLimits.MaxInFlightRequests, config.MaxInFlightRequests)
+
+	cacheMaxCosts := map[string]int64{}
+	for name, maxCost := range map[string]string{
+		"ns-cache":               config.NamespaceCacheMaxCost,
+		"dispatch-cache":         config.DispatchCacheMaxCost,
+		"dispatch-cluster-cache": config.ClusterDispatchCacheMaxCost,
+	} {
+		if maxCost == "" {
+			continue
+		}
+
+		parsed, err := parseMaxCost(maxCost)
+		if err != nil {
+			return fmt.Errorf("invalid %s-max-cost: %w", name, err)
+		}
+		if parsed == 0 {
+			return fmt.Errorf("invalid %s-max-cost: caches cannot be disabled while running", name)
+		}
+		cacheMaxCosts[name] = int64(parsed)
+	}
+
+	zerolog.SetGlobalLevel(logLevel)
+	r.presharedKeys.Store(&presharedKeys)
+	r.limiter.Update(rateLimits)
+	for name, c := range r.caches {
+		maxCost, ok := cacheMaxCosts[name]
+		if !ok {
+			maxCost = c.flagMaxCost
+		}
+		r.resizeCache(name, c.cache, maxCost)
+	}
+	r.cacheMaxCosts = cacheMaxCosts
+	r.lastContents = contents
+
+	log.Ctx(ctx).Info().
+		Str("path", r.path).
+		Stringer("log-level", logLevel).
+		Int("preshared-keys-count", len(presharedKeys)).
+		Msg("applied reloadable config")
+	return nil
+}
+
+// Run reloads the configuration whenever the process receives SIGHUP or the file changes,
+// until the context is cancelled. A configuration which fails to reload is logged, and the
+// previous configuration is kept.
+func (r *configReloader) Run(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return fmt.Errorf("failed to watch reloadable config: %w", err)
+	}
+	defer watcher.Close()
+
+	// The directory is watched, rather than the file, so that files which are replaced
+	// rather than written, such as those of mounted Kubernetes ConfigMaps, are followed.
+	if err := watcher.Add(filepath.Dir(r.path)); err != nil {
+		return fmt.Errorf("failed to watch reloadable config: %w", err)
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+
+		case <-signals:
+			if err := r.reload(ctx); err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("failed to reload config after SIGHUP")
+			}
+
+		case <-watcher.Events:
+			// Editors and ConfigMap updates produce several events for a single change, so
+			// the file is only reloaded once its contents differ.
+			contents, err := os.ReadFile(r.path)
+			if err != nil || bytes.Equal(contents, r.lastContents) {
+				continue
+			}
+			if err := r.reload(ctx); err != nil {
+				log.Ctx(ctx).Error().Err(err).Msg("failed to reload config after it changed")
+				r.lastContents = contents
+			}
+
+		case err := <-watcher.Errors:
+			log.Ctx(ctx).Warn().Err(err).Msg("error watching reloadable config")
+		}
+	}
+}
+
+func setIfPresent[T any](target *T, value *T) {
+	if value != nil {
+		*target = *value
+	}
+}
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/middleware/ratelimit"
+	"github.com/authzed/spicedb/pkg/cache"
+)
+
+func newTestConfigReloader(t *testing.T, contents string) (*configReloader, string) {
+	level := zerolog.GlobalLevel()
+	t.Cleanup(func() { zerolog.SetGlobalLevel(level) })
+
+	path := filepath.Join(t.TempDir(), "reload.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+
+	reloader, err := newConfigReloader(context.Background(), path, zerolog.InfoLevel, []string{"flagkey"}, false, ratelimit.Config{ReadBurst: 100})
+	require.NoError(t, err)
+	return reloader, path
+}
+
+func TestConfigReloader(t *testing.T) {
+	reloader, path := newTestConfigReloader(t, `
+log-level: debug
+grpc-preshared-key: [firstkey, secondkey]
+grpc-ratelimit-reads-per-second: 10
+dispatch-cache-max-cost: 2MiB
+`)
+	require.Equal(t, zerolog.DebugLevel, zerolog.GlobalLevel())
+	require.Equal(t, []string{"firstkey", "secondkey"}, reloader.PresharedKeys())
+
+	// Caches registered after startup are sized by the file.
+	dispatchCache, err := cache.NewCache(&cache.Config{MaxCost: 1 << 20, NumCounters: 1000})
+	require.NoError(t, err)
+	t.Cleanup(dispatchCache.Close)
+	reloader.addCache("dispatch-cache", dispatchCache, CacheConfig{MaxCost: "1MiB"})
+	require.Equal(t, int64(2<<20), maxCost(dispatchCache))
+
+	// Settings removed from the file revert to their flags.
+	require.NoError(t, os.WriteFile(path, []byte("grpc-ratelimit-reads-per-second: 20\n"), 0o600))
+	require.NoError(t, reloader.reload(context.Background()))
+	require.Equal(t, zerolog.InfoLevel, zerolog.GlobalLevel())
+	require.Equal(t, []string{"flagkey"}, reloader.PresharedKeys())
+	require.Equal(t, int64(1<<20), maxCost(dispatchCache))
+}
+
+func TestConfigReloaderWithCacheBudget(t *testing.T) {
+	reloader, path := newTestConfigReloader(t, "dispatch-cache-max-cost: 3MiB\n")
+	reloader.cacheBudget = cache.NewBudget(2 << 20)
+	t.Cleanup(reloader.cacheBudget.Close)
+
+	nsCache, err := cache.NewCache(&cache.Config{MaxCost: 1 << 20, NumCounters: 1000})
+	require.NoError(t, err)
+	t.Cleanup(nsCache.Close)
+	reloader.cacheBudget.Add("ns-cache", nsCache, 1<<20)
+	reloader.addCache("ns-cache", nsCache, CacheConfig{MaxCost: "1MiB"})
+
+	dispatchCache, err := cache.NewCache(&cache.Config{MaxCost: 1 << 20, NumCounters: 1000})
+	require.NoError(t, err)
+	t.Cleanup(dispatchCache.Close)
+	reloader.cacheBudget.Add("dispatch-cache", dispatchCache, 1<<20)
+	reloader.addCache("dispatch-cache", dispatchCache, CacheConfig{MaxCost: "1MiB"})
+
+	// The size from the file is requested within the budget, so the caches share it.
+	require.Equal(t, int64(512<<10), maxCost(nsCache))
+	require.Equal(t, int64(1536<<10), maxCost(dispatchCache))
+
+	// Settings removed from the file revert to their flags, which fit within the budget.
+	require.NoError(t, os.WriteFile(path, []byte(""), 0o600))
+	require.NoError(t, reloader.reload(context.Background()))
+	require.Equal(t, int64(1<<20), maxCost(nsCache))
+	require.Equal(t, int64(1<<20), maxCost(dispatchCache))
+}
+
+func TestConfigReloaderInvalid(t *testing.T) {
+	reloader, path := newTestConfigReloader(t, "grpc-preshared-key: [firstkey]\n")
+
+	for _, contents := range []string{
+		"log-level: loud\n",
+		"grpc-preshared-key: [firstkey, '']\n",
+		"ns-cache-max-cost: 0%\n",
+		"ns-cache-max-cost: 200%\n",
+		"grpc-ratelimit-read-burst: many\n",
+		"datastore-engine: memory\n",
+	} {
+		require.NoError(t, os.WriteFile(path, []byte(contents), 0o600))
+		require.Error(t, reloader.reload(context.Background()), contents)
+
+		// An invalid file leaves the configuration unchanged.
+		require.Equal(t, []string{"firstkey"}, reloader.PresharedKeys())
+	}
+
+	_, err := newConfigReloader(context.Background(), path, zerolog.InfoLevel, nil, false, ratelimit.Config{})
+	require.ErrorContains(t, err, "field datastore-engine not found")
+
+	require.NoError(t, os.WriteFile(path, []byte("grpc-preshared-key: [firstkey]\n"), 0o600))
+	_, err = newConfigReloader(context.Background(), path, zerolog.InfoLevel, nil, true, ratelimit.Config{})
+	require.ErrorContains(t, err, "cannot be given both by the reloadable config and from Vault")
+}
+
+func TestConfigReloaderWatchesFile(t *testing.T) {
+	reloader, path := newTestConfigReloader(t, "")
+	require.Equal(t, []string{"flagkey"}, reloader.PresharedKeys())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- reloader.Run(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		require.NoError(t, <-done)
+	})
+
+	// The file is replaced by renaming, as when a Kubernetes ConfigMap is updated.
+	require.Eventually(t, func() bool {
+		replacement := filepath.Join(filepath.Dir(path), "reload.yaml.tmp")
+		require.NoError(t, os.WriteFile(replacement, []byte("grpc-preshared-key: [newkey]\n"), 0o600))
+		require.NoError(t, os.Rename(replacement, path))
+		return len(reloader.PresharedKeys()) == 1 && reloader.PresharedKeys()[0] == "newkey"
+	}, 5*time.Second, 50*time.Millisecond)
+}
+
+func maxCost(c cache.Cache) int64 {
+	return c.(interface{ MaxCost() int64 }).MaxCost()
+}
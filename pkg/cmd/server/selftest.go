@@ -0,0 +1,323 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/authzed/spicedb/internal/datastore/proxy"
+	datastorecfg "github.com/authzed/spicedb/pkg/cmd/datastore"
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+const (
+	// selfTestTimeout bounds each check of the self-test which waits on the network.
+	selfTestTimeout = 5 * time.Second
+
+	// selfTestClockSkewWarning and selfTestClockSkewFailure are the differences between the
+	// clocks of the server and of the datastore beyond which the self-test warns and fails.
+	selfTestClockSkewWarning = 500 * time.Millisecond
+	selfTestClockSkewFailure = 5 * time.Second
+
+	// selfTestCertificateExpiryWarning is how long before the expiry of a certificate the
+	// self-test warns about it.
+	selfTestCertificateExpiryWarning = 30 * 24 * time.Hour
+)
+
+// SelfTestStatus is the outcome of a check of the self-test.
+type SelfTestStatus string
+
+const (
+	SelfTestPassed  SelfTestStatus = "ok"
+	SelfTestWarning SelfTestStatus = "warn"
+	SelfTestFailed  SelfTestStatus = "fail"
+	SelfTestSkipped SelfTestStatus = "skip"
+)
+
+// SelfTestResult is the outcome of a check of the self-test, with a message explaining it.
+type SelfTestResult struct {
+	Check   string
+	Status  SelfTestStatus
+	Message string
+}
+
+// SelfTestReport is the outcome of each check of the self-test.
+type SelfTestReport []SelfTestResult
+
+// Failed returns whether any check failed.
+func (r SelfTestReport) Failed() bool {
+	for _, result := range r {
+		if result.Status == SelfTestFailed {
+			return true
+		}
+	}
+	return false
+}
+
+// Write writes the report as one line per check.
+func (r SelfTestReport) Write(w io.Writer) error {
+	width := 0
+	for _, result := range r {
+		width = max(width, len(result.Check))
+	}
+
+	for _, result := range r {
+		if _, err := fmt.Fprintf(w, "[%-4s] %-*s  %s\n", result.Status, width, result.Check, result.Message); err != nil {
+			return err
+		}
+	}
+
+	summary := "all checks passed"
+	if r.Failed() {
+		summary = "some checks failed; the server cannot start until they are fixed"
+	}
+	_, err := fmt.Fprintln(w, summary)
+	return err
+}
+
+func (r *SelfTestReport) add(check string, status SelfTestStatus, format string, args ...any) {
+	*r = append(*r, SelfTestResult{Check: check, Status: status, Message: fmt.Sprintf(format, args...)})
+}
+
+// SelfTest verifies that the server can start with its configuration: that the datastore is
+// reachable and migrated, that its clock agrees with that of the server, that the configured
+// TLS material is valid and unexpired, and that the dispatch peers are reachable.
+func (c *Config) SelfTest(ctx context.Context) SelfTestReport {
+	var report SelfTestReport
+	c.selfTestDatastore(ctx, &report)
+	c.selfTestTLS(&report)
+	c.selfTestPeers(ctx, &report)
+	return report
+}
+
+func (c *Config) selfTestDatastore(ctx context.Context, report *SelfTestReport) {
+	ds := c.Datastore
+	if ds == nil {
+		// The datastore is only opened to check it: no background work or bootstrap data.
+		datastoreConfig := c.DatastoreConfig
+		datastoreConfig.GCInterval = -1 * time.Hour
+		datastoreConfig.RequestHedgingEnabled = false
+		datastoreConfig.BootstrapFiles = nil
+		datastoreConfig.BootstrapFileContents = nil
+
+		var err error
+		ds, err = datastorecfg.NewDatastore(ctx, datastoreConfig.ToOption())
+		if err != nil {
+			report.add("datastore connectivity", SelfTestFailed, "could not open the %s datastore: %s; check --datastore-engine and --datastore-conn-uri", c.DatastoreConfig.Engine, err)
+			report.add("datastore migrations", SelfTestSkipped, "the datastore could not be opened")
+			report.add("datastore clock skew", SelfTestSkipped, "the datastore could not be opened")
+			return
+		}
+		defer ds.Close()
+	}
+
+	readyCtx, cancel := context.WithTimeout(ctx, selfTestTimeout)
+	defer cancel()
+	state, err := ds.ReadyState(readyCtx)
+	switch {
+	case err != nil:
+		report.add("datastore connectivity", SelfTestFailed, "could not reach the datastore: %s; check --datastore-conn-uri and that the datastore accepts connections from this host", err)
+		report.add("datastore migrations", SelfTestSkipped, "the datastore could not be reached")
+		report.add("datastore clock skew", SelfTestSkipped, "the datastore could not be reached")
+		return
+	case !state.IsReady:
+		report.add("datastore connectivity", SelfTestPassed, "connected")
+		report.add("datastore migrations", SelfTestFailed, "%s", state.Message)
+	default:
+		report.add("datastore connectivity", SelfTestPassed, "connected")
+		report.add("datastore migrations", SelfTestPassed, "migrated to the revision required by this version")
+	}
+
+	clock := datastore.UnwrapAs[proxy.DatastoreClock](ds)
+	if clock == nil {
+		report.add("datastore clock skew", SelfTestSkipped, "the %s datastore does not report its clock", c.DatastoreConfig.Engine)
+		return
+	}
+
+	clockCtx, cancel := context.WithTimeout(ctx, selfTestTimeout)
+	defer cancel()
+	sent := time.Now()
+	datastoreNow, err := clock.Now(clockCtx)
+	if err != nil {
+		report.add("datastore clock skew", SelfTestFailed, "could not read the clock of the datastore: %s", err)
+		return
+	}
+
+	// The clock of the datastore is compared with the midpoint of the round trip.
+	received := time.Now()
+	localNow := sent.Add(received.Sub(sent) / 2)
+	skew := datastoreNow.Sub(localNow).Abs()
+	switch {
+	case c.DatastoreConfig.MaxClockSkew > 0 && skew > c.DatastoreConfig.MaxClockSkew:
+		report.add("datastore clock skew", SelfTestFailed, "the clocks of the server and datastore differ by %s, more than --datastore-max-clock-skew, so writes will be rejected; synchronize them with NTP", skew.Round(time.Millisecond))
+	case skew > selfTestClockSkewFailure:
+		report.add("datastore clock skew", SelfTestFailed, "the clocks of the server and datastore differ by %s; synchronize them with NTP", skew.Round(time.Millisecond))
+	case skew > selfTestClockSkewWarning:
+		report.add("datastore clock skew", SelfTestWarning, "the clocks of the server and datastore differ by %s; revisions and GC windows will be offset by it", skew.Round(time.Millisecond))
+	default:
+		report.add("datastore clock skew", SelfTestPassed, "%s", skew.Round(time.Millisecond))
+	}
+}
+
+func (c *Config) selfTestTLS(report *SelfTestReport) {
+	type keyPair struct{ flagPrefix, certPath, keyPath string }
+	keyPairs := []keyPair{
+		{"grpc", c.GRPCServer.TLSCertPath, c.GRPCServer.TLSKeyPath},
+		{"http", c.HTTPGateway.HTTPTLSCertPath, c.HTTPGateway.HTTPTLSKeyPath},
+		{"dispatch-cluster", c.DispatchServer.TLSCertPath, c.DispatchServer.TLSKeyPath},
+		{"dispatch-upstream", c.DispatchUpstreamTLSCertPath, c.DispatchUpstreamTLSKeyPath},
+		{"metrics", c.MetricsAPI.HTTPTLSCertPath, c.MetricsAPI.HTTPTLSKeyPath},
+	}
+	caPaths := []struct{ check, flag, path string }{
+		{"grpc client CA", "grpc-client-ca-path", c.GRPCServer.MutualTLSCAPath},
+		{"dispatch-cluster client CA", "dispatch-cluster-client-ca-path", c.DispatchServer.MutualTLSCAPath},
+		{"dispatch-upstream CA", "dispatch-upstream-ca-path", c.DispatchUpstreamCAPath},
+	}
+
+	checked := false
+	now := time.Now()
+	for _, pair := range keyPairs {
+		if pair.certPath == "" && pair.keyPath == "" {
+			continue
+		}
+		checked = true
+
+		check := pair.flagPrefix + " TLS certificate"
+		cert, err := tls.LoadX509KeyPair(pair.certPath, pair.keyPath)
+		if err != nil {
+			report.add(check, SelfTestFailed, "could not load the key pair: %s; check --%s-tls-cert-path and --%s-tls-key-path", err, pair.flagPrefix, pair.flagPrefix)
+			continue
+		}
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			report.add(check, SelfTestFailed, "could not parse the certificate: %s", err)
+			continue
+		}
+		reportCertificateValidity(report, check, leaf, now)
+	}
+
+	for _, ca := range caPaths {
+		if ca.path == "" {
+			continue
+		}
+		checked = true
+
+		check := ca.check + " certificates"
+		certs, err := readCertificates(ca.path)
+		if err != nil {
+			report.add(check, SelfTestFailed, "could not load the CA certificates: %s; check --%s", err, ca.flag)
+			continue
+		}
+		for _, cert := range certs {
+			reportCertificateValidity(report, check, cert, now)
+		}
+	}
+
+	if !checked {
+		report.add("TLS certificates", SelfTestSkipped, "no TLS certificates are configured")
+	}
+}
+
+func reportCertificateValidity(report *SelfTestReport, check string, cert *x509.Certificate, now time.Time) {
+	subject := cert.Subject.String()
+	switch {
+	case now.Before(cert.NotBefore):
+		report.add(check, SelfTestFailed, "%s is not valid until %s; check the clock of the server", subject, cert.NotBefore.Format(time.RFC3339))
+	case now.After(cert.NotAfter):
+		report.add(check, SelfTestFailed, "%s expired at %s; renew the certificate", subject, cert.NotAfter.Format(time.RFC3339))
+	case cert.NotAfter.Sub(now) < selfTestCertificateExpiryWarning:
+		report.add(check, SelfTestWarning, "%s expires at %s; renew the certificate soon", subject, cert.NotAfter.Format(time.RFC3339))
+	default:
+		report.add(check, SelfTestPassed, "%s is valid until %s", subject, cert.NotAfter.Format(time.RFC3339))
+	}
+}
+
+func readCertificates(path string) ([]*x509.Certificate, error) {
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var certs []*x509.Certificate
+	for block, rest := pem.Decode(contents); block != nil; block, rest = pem.Decode(rest) {
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no PEM certificates found in %s", path)
+	}
+	return certs, nil
+}
+
+func (c *Config) selfTestPeers(ctx context.Context, report *SelfTestReport) {
+	upstreamAddr, err := dispatchUpstreamAddr(c.DispatchUpstreamAddr, c.DispatchUpstreamKubernetesService)
+	if err != nil {
+		report.add("dispatch upstream", SelfTestFailed, "%s", err)
+		return
+	}
+
+	peers := map[string]string{}
+	if upstreamAddr != "" {
+		peers["dispatch upstream"] = upstreamAddr
+	}
+	for name, addr := range c.DispatchSecondaryUpstreamAddrs {
+		peers["dispatch secondary upstream "+name] = addr
+	}
+	if len(peers) == 0 {
+		report.add("dispatch peers", SelfTestSkipped, "no dispatch upstream is configured")
+		return
+	}
+
+	checks := make([]string, 0, len(peers))
+	for check := range peers {
+		checks = append(checks, check)
+	}
+	sort.Strings(checks)
+
+	for _, check := range checks {
+		target := peers[check]
+		addr, ok := dialableAddr(target)
+		if !ok {
+			report.add(check, SelfTestSkipped, "cannot check the reachability of target %s", target)
+			continue
+		}
+
+		dialCtx, cancel := context.WithTimeout(ctx, selfTestTimeout)
+		conn, err := (&net.Dialer{}).DialContext(dialCtx, "tcp", addr)
+		cancel()
+		if err != nil {
+			report.add(check, SelfTestFailed, "could not connect to %s: %s; check that the peers are running and reachable from this host", addr, err)
+			continue
+		}
+		conn.Close()
+		report.add(check, SelfTestPassed, "connected to %s", addr)
+	}
+}
+
+// dialableAddr returns the host and port of a gRPC target, if it names a single host.
+func dialableAddr(target string) (string, bool) {
+	for _, scheme := range []string{"dns:///", "kubernetes:///"} {
+		target = strings.TrimPrefix(target, scheme)
+	}
+	if strings.Contains(target, "://") {
+		return "", false
+	}
+	if _, _, err := net.SplitHostPort(target); err != nil {
+		return "", false
+	}
+	return target, true
+}
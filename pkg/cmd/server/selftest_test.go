@@ -0,0 +1,114 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+)
+
+// writeTestKeyPair writes a self-signed certificate valid until notAfter, and its key.
+func writeTestKeyPair(t *testing.T, notAfter time.Time) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "spicedb"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	certPath := filepath.Join(dir, "tls.crt")
+	keyPath := filepath.Join(dir, "tls.key")
+	require.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0o600))
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0o600))
+	return certPath, keyPath
+}
+
+func statusesByCheck(report SelfTestReport) map[string]SelfTestStatus {
+	statuses := make(map[string]SelfTestStatus, len(report))
+	for _, result := range report {
+		statuses[result.Check] = result.Status
+	}
+	return statuses
+}
+
+func TestSelfTest(t *testing.T) {
+	ds, err := memdb.NewMemdbDatastore(0, 1*time.Second, 10*time.Second)
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	closed, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	closedAddr := closed.Addr().String()
+	require.NoError(t, closed.Close())
+
+	grpcCert, grpcKey := writeTestKeyPair(t, time.Now().Add(365*24*time.Hour))
+	httpCert, httpKey := writeTestKeyPair(t, time.Now().Add(24*time.Hour))
+	metricsCert, metricsKey := writeTestKeyPair(t, time.Now().Add(-time.Minute))
+
+	c := &Config{
+		Datastore:            ds,
+		DispatchUpstreamAddr: "dns:///" + listener.Addr().String(),
+		DispatchSecondaryUpstreamAddrs: map[string]string{
+			"down": closedAddr,
+		},
+	}
+	c.DatastoreConfig.Engine = "memory"
+	c.GRPCServer.TLSCertPath, c.GRPCServer.TLSKeyPath = grpcCert, grpcKey
+	c.HTTPGateway.HTTPTLSCertPath, c.HTTPGateway.HTTPTLSKeyPath = httpCert, httpKey
+	c.MetricsAPI.HTTPTLSCertPath, c.MetricsAPI.HTTPTLSKeyPath = metricsCert, metricsKey
+	c.DispatchUpstreamCAPath = filepath.Join(t.TempDir(), "missing.pem")
+
+	report := c.SelfTest(context.Background())
+	require.Equal(t, map[string]SelfTestStatus{
+		"datastore connectivity":            SelfTestPassed,
+		"datastore migrations":              SelfTestPassed,
+		"datastore clock skew":              SelfTestSkipped,
+		"grpc TLS certificate":              SelfTestPassed,
+		"http TLS certificate":              SelfTestWarning,
+		"metrics TLS certificate":           SelfTestFailed,
+		"dispatch-upstream CA certificates": SelfTestFailed,
+		"dispatch upstream":                 SelfTestPassed,
+		"dispatch secondary upstream down":  SelfTestFailed,
+	}, statusesByCheck(report))
+	require.True(t, report.Failed())
+
+	var out bytes.Buffer
+	require.NoError(t, report.Write(&out))
+	require.Contains(t, out.String(), "[fail] metrics TLS certificate")
+	require.Contains(t, out.String(), "some checks failed")
+}
+
+func TestSelfTestWithoutOptionalChecks(t *testing.T) {
+	ds, err := memdb.NewMemdbDatastore(0, 1*time.Second, 10*time.Second)
+	require.NoError(t, err)
+
+	report := (&Config{Datastore: ds}).SelfTest(context.Background())
+	require.False(t, report.Failed())
+	require.Equal(t, SelfTestSkipped, statusesByCheck(report)["TLS certificates"])
+	require.Equal(t, SelfTestSkipped, statusesByCheck(report)["dispatch peers"])
+}
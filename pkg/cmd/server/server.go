@@ -2,51 +2,102 @@ package server
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
+	"os"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
-	"github.com/authzed/consistent"
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 	"github.com/authzed/grpcutil"
 	"github.com/cespare/xxhash/v2"
+	"github.com/dustin/go-humanize"
 	"github.com/ecordell/optgen/helpers"
+	authv3 "github.com/envoyproxy/go-control-plane/envoy/service/auth/v3"
 	grpc_auth "github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/auth"
-	"github.com/hashicorp/go-multierror"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/rs/cors"
 	"github.com/rs/zerolog"
 	"github.com/sean-/sysexits"
 	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
-	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
 	_ "google.golang.org/grpc/encoding/gzip" // enable gzip compression on all derivative servers
 
+	"github.com/authzed/spicedb/internal/accessreport"
 	"github.com/authzed/spicedb/internal/auth"
+	"github.com/authzed/spicedb/internal/backup"
+	"github.com/authzed/spicedb/internal/changefeed"
+	"github.com/authzed/spicedb/internal/datastore/common"
 	"github.com/authzed/spicedb/internal/datastore/proxy"
 	"github.com/authzed/spicedb/internal/datastore/proxy/schemacaching"
 	"github.com/authzed/spicedb/internal/dispatch"
+	dispatchbalancer "github.com/authzed/spicedb/internal/dispatch/balancer"
 	clusterdispatch "github.com/authzed/spicedb/internal/dispatch/cluster"
 	combineddispatch "github.com/authzed/spicedb/internal/dispatch/combined"
+	"github.com/authzed/spicedb/internal/dispatch/discovery"
+	"github.com/authzed/spicedb/internal/dispatch/federation"
 	"github.com/authzed/spicedb/internal/dispatch/graph"
+	"github.com/authzed/spicedb/internal/dispatch/warmup"
 	"github.com/authzed/spicedb/internal/gateway"
+	maingraph "github.com/authzed/spicedb/internal/graph"
+	"github.com/authzed/spicedb/internal/graph/groupindex"
+	"github.com/authzed/spicedb/internal/graph/materialized"
+	"github.com/authzed/spicedb/internal/grpcweb"
+	"github.com/authzed/spicedb/internal/introspection"
+	"github.com/authzed/spicedb/internal/leaderelection"
+	"github.com/authzed/spicedb/internal/lifecycle"
 	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/middleware/admission"
+	"github.com/authzed/spicedb/internal/middleware/audit"
+	consistencymw "github.com/authzed/spicedb/internal/middleware/consistency"
+	"github.com/authzed/spicedb/internal/middleware/deadline"
+	"github.com/authzed/spicedb/internal/middleware/debuglog"
+	"github.com/authzed/spicedb/internal/middleware/decisionlog"
+	"github.com/authzed/spicedb/internal/middleware/errorreport"
+	"github.com/authzed/spicedb/internal/middleware/faultinjection"
+	"github.com/authzed/spicedb/internal/middleware/namespacemetrics"
+	"github.com/authzed/spicedb/internal/middleware/namespacequota"
+	"github.com/authzed/spicedb/internal/middleware/ratelimit"
+	"github.com/authzed/spicedb/internal/middleware/readonly"
+	"github.com/authzed/spicedb/internal/middleware/schemawebhook"
+	"github.com/authzed/spicedb/internal/middleware/shadowcheck"
+	"github.com/authzed/spicedb/internal/middleware/usage"
+	"github.com/authzed/spicedb/internal/schemadir"
+	"github.com/authzed/spicedb/internal/sdnotify"
 	"github.com/authzed/spicedb/internal/services"
 	dispatchSvc "github.com/authzed/spicedb/internal/services/dispatch"
+	"github.com/authzed/spicedb/internal/services/extauthz"
 	"github.com/authzed/spicedb/internal/services/health"
 	v1svc "github.com/authzed/spicedb/internal/services/v1"
+	"github.com/authzed/spicedb/internal/statsd"
 	"github.com/authzed/spicedb/internal/telemetry"
+	"github.com/authzed/spicedb/internal/vault"
+	"github.com/authzed/spicedb/internal/xds"
+	"github.com/authzed/spicedb/pkg/cache"
 	datastorecfg "github.com/authzed/spicedb/pkg/cmd/datastore"
 	"github.com/authzed/spicedb/pkg/cmd/util"
 	"github.com/authzed/spicedb/pkg/datastore"
+	logmw "github.com/authzed/spicedb/pkg/middleware/logging"
+	"github.com/authzed/spicedb/pkg/middleware/transform"
+	"github.com/authzed/spicedb/pkg/releases"
 	"github.com/authzed/spicedb/pkg/spiceerrors"
+	"github.com/authzed/spicedb/pkg/zedtoken"
 )
 
 // ConsistentHashringBuilder is a balancer Builder that uses xxhash as the
-// underlying hash for the ConsistentHashringBalancers it creates.
-var ConsistentHashringBuilder = consistent.NewBuilder(xxhash.Sum64)
+// underlying hash for the ConsistentHashringBalancers it creates, whose members
+// are reported by the introspection endpoints.
+var ConsistentHashringBuilder = introspection.NewBalancerBuilder(dispatchbalancer.NewBuilder(xxhash.Sum64))
+
+// cacheWarmupSaveInterval is how often the subproblems sampled for cache warm-up are persisted.
+const cacheWarmupSaveInterval = time.Minute
 
 //go:generate go run github.com/ecordell/optgen -output zz_generated.options.go . Config
 type Config struct {
@@ -54,9 +105,23 @@ type Config struct {
 	GRPCServer             util.GRPCServerConfig `debugmap:"visible"`
 	GRPCAuthFunc           grpc_auth.AuthFunc    `debugmap:"visible"`
 	PresharedSecureKey     []string              `debugmap:"sensitive"`
+	ScopedPresharedKeys    []string              `debugmap:"sensitive"`
 	ShutdownGracePeriod    time.Duration         `debugmap:"visible"`
+	ShutdownStopTimeout    time.Duration         `debugmap:"visible"`
 	DisableVersionResponse bool                  `debugmap:"visible"`
 
+	// JWT authentication
+	JWTIssuer   string `debugmap:"visible"`
+	JWTJWKSURL  string `debugmap:"visible"`
+	JWTAudience string `debugmap:"visible"`
+
+	// Tenant isolation
+	TenancyEnabled bool `debugmap:"visible"`
+
+	// ZedToken signing
+	ZedTokenSigningKeys   []string `debugmap:"sensitive"`
+	ZedTokenAllowUnsigned bool     `debugmap:"visible"`
+
 	// GRPC Gateway config
 	HTTPGateway                    util.HTTPServerConfig `debugmap:"visible"`
 	HTTPGatewayUpstreamAddr        string                `debugmap:"visible"`
@@ -64,6 +129,11 @@ type Config struct {
 	HTTPGatewayCorsEnabled         bool                  `debugmap:"visible"`
 	HTTPGatewayCorsAllowedOrigins  []string              `debugmap:"visible-format"`
 
+	// gRPC-Web config
+	GRPCWeb                   util.HTTPServerConfig `debugmap:"visible"`
+	GRPCWebCorsEnabled        bool                  `debugmap:"visible"`
+	GRPCWebCorsAllowedOrigins []string              `debugmap:"visible-format"`
+
 	// Datastore
 	DatastoreConfig datastorecfg.Config `debugmap:"visible"`
 	Datastore       datastore.Datastore `debugmap:"visible"`
@@ -81,38 +151,148 @@ type Config struct {
 	SchemaPrefixesRequired bool `debugmap:"visible"`
 
 	// Dispatch options
-	DispatchServer                    util.GRPCServerConfig   `debugmap:"visible"`
-	DispatchMaxDepth                  uint32                  `debugmap:"visible"`
-	GlobalDispatchConcurrencyLimit    uint16                  `debugmap:"visible"`
-	DispatchConcurrencyLimits         graph.ConcurrencyLimits `debugmap:"visible"`
-	DispatchUpstreamAddr              string                  `debugmap:"visible"`
-	DispatchUpstreamCAPath            string                  `debugmap:"visible"`
-	DispatchUpstreamTimeout           time.Duration           `debugmap:"visible"`
-	DispatchClientMetricsEnabled      bool                    `debugmap:"visible"`
-	DispatchClientMetricsPrefix       string                  `debugmap:"visible"`
-	DispatchClusterMetricsEnabled     bool                    `debugmap:"visible"`
-	DispatchClusterMetricsPrefix      string                  `debugmap:"visible"`
-	Dispatcher                        dispatch.Dispatcher     `debugmap:"visible"`
-	DispatchHashringReplicationFactor uint16                  `debugmap:"visible"`
-	DispatchHashringSpread            uint8                   `debugmap:"visible"`
+	DispatchServer                     util.GRPCServerConfig   `debugmap:"visible"`
+	DispatchMaxDepth                   uint32                  `debugmap:"visible"`
+	DispatchCheckMaxRelationships      uint64                  `debugmap:"visible"`
+	DispatchCheckMaxSubproblems        uint64                  `debugmap:"visible"`
+	GlobalDispatchConcurrencyLimit     uint16                  `debugmap:"visible"`
+	DispatchConcurrencyLimits          graph.ConcurrencyLimits `debugmap:"visible"`
+	DispatchUpstreamAddr               string                  `debugmap:"visible"`
+	DispatchUpstreamKubernetesService  string                  `debugmap:"visible"`
+	DispatchUpstreamDiscovery          string                  `debugmap:"visible"`
+	DispatchUpstreamDiscoveryTarget    string                  `debugmap:"visible"`
+	DispatchUpstreamDiscoveryEndpoint  string                  `debugmap:"visible"`
+	DispatchUpstreamDiscoveryInterval  time.Duration           `debugmap:"visible"`
+	DispatchUpstreamCAPath             string                  `debugmap:"visible"`
+	DispatchUpstreamTLSCertPath        string                  `debugmap:"visible"`
+	DispatchUpstreamTLSKeyPath         string                  `debugmap:"visible"`
+	DispatchPresharedKey               []string                `debugmap:"sensitive"`
+	DispatchUpstreamTimeout            time.Duration           `debugmap:"visible"`
+	DispatchLocalFallbackEnabled       bool                    `debugmap:"visible"`
+	DispatchHedgingEnabled             bool                    `debugmap:"visible"`
+	DispatchHedgingInitialSlowValue    time.Duration           `debugmap:"visible"`
+	DispatchHedgingMaxRequests         uint64                  `debugmap:"visible"`
+	DispatchHedgingQuantile            float64                 `debugmap:"visible"`
+	DispatchClientMetricsEnabled       bool                    `debugmap:"visible"`
+	DispatchClientMetricsPrefix        string                  `debugmap:"visible"`
+	DispatchClusterMetricsEnabled      bool                    `debugmap:"visible"`
+	DispatchClusterMetricsPrefix       string                  `debugmap:"visible"`
+	Dispatcher                         dispatch.Dispatcher     `debugmap:"visible"`
+	DispatchHashringReplicationFactor  uint16                  `debugmap:"visible"`
+	DispatchHashringSpread             uint8                   `debugmap:"visible"`
+	DispatchHashringLoadFactor         float64                 `debugmap:"visible"`
+	DispatchHashringDualDispatchWindow time.Duration           `debugmap:"visible"`
 
 	DispatchSecondaryUpstreamAddrs map[string]string `debugmap:"visible"`
 	DispatchSecondaryUpstreamExprs map[string]string `debugmap:"visible"`
 
-	DispatchCacheConfig        CacheConfig `debugmap:"visible"`
-	ClusterDispatchCacheConfig CacheConfig `debugmap:"visible"`
+	GroupIndexRelations    []string      `debugmap:"visible"`
+	GroupIndexMaxStaleness time.Duration `debugmap:"visible"`
+
+	MaterializedPermissions             []string      `debugmap:"visible"`
+	MaterializedPermissionsMaxStaleness time.Duration `debugmap:"visible"`
+
+	DispatchCacheConfig              CacheConfig `debugmap:"visible"`
+	ClusterDispatchCacheConfig       CacheConfig `debugmap:"visible"`
+	DispatchDeniedCacheConfig        CacheConfig `debugmap:"visible"`
+	ClusterDispatchDeniedCacheConfig CacheConfig `debugmap:"visible"`
+	MaxCacheMemory                   string      `debugmap:"visible"`
+
+	CacheWarmupEnabled        bool          `debugmap:"visible"`
+	CacheWarmupFile           string        `debugmap:"visible"`
+	CacheWarmupMaxSubproblems int           `debugmap:"visible"`
+	CacheWarmupSampleRate     float64       `debugmap:"visible"`
+	CacheWarmupTimeout        time.Duration `debugmap:"visible"`
 
 	// API Behavior
-	DisableV1SchemaAPI       bool          `debugmap:"visible"`
-	V1SchemaAdditiveOnly     bool          `debugmap:"visible"`
-	MaximumUpdatesPerWrite   uint16        `debugmap:"visible"`
-	MaximumPreconditionCount uint16        `debugmap:"visible"`
-	MaxDatastoreReadPageSize uint64        `debugmap:"visible"`
-	StreamingAPITimeout      time.Duration `debugmap:"visible"`
-	WatchHeartbeat           time.Duration `debugmap:"visible"`
+	DisableV1SchemaAPI            bool              `debugmap:"visible"`
+	DisableSchemaWrites           bool              `debugmap:"visible"`
+	SchemaDirectory               string            `debugmap:"visible"`
+	DisableWatchAPI               bool              `debugmap:"visible"`
+	EnableReflection              bool              `debugmap:"visible"`
+	DisableReflection             bool              `debugmap:"visible"`
+	DisableWrites                 bool              `debugmap:"visible"`
+	V1SchemaAdditiveOnly          bool              `debugmap:"visible"`
+	MaximumUpdatesPerWrite        uint16            `debugmap:"visible"`
+	MaximumPreconditionCount      uint16            `debugmap:"visible"`
+	MaxDatastoreReadPageSize      uint64            `debugmap:"visible"`
+	MaxReadRelationshipsLimit     uint32            `debugmap:"visible"`
+	DefaultReadRelationshipsLimit uint32            `debugmap:"visible"`
+	MaxLookupResourcesLimit       uint32            `debugmap:"visible"`
+	DefaultLookupResourcesLimit   uint32            `debugmap:"visible"`
+	DefaultExportBatchSize        uint32            `debugmap:"visible"`
+	MaxExportBatchSize            uint32            `debugmap:"visible"`
+	WriteBatchWindow              time.Duration     `debugmap:"visible"`
+	CheckSessionIdleTimeout       time.Duration     `debugmap:"visible"`
+	StreamingAPITimeout           time.Duration     `debugmap:"visible"`
+	WatchHeartbeat                time.Duration     `debugmap:"visible"`
+	WatchBufferLength             int               `debugmap:"visible"`
+	WatchOverflow                 string            `debugmap:"visible"`
+	SlowRequestThreshold          time.Duration     `debugmap:"visible"`
+	DefaultRequestTimeouts        map[string]string `debugmap:"visible"`
+	MaxRequestTimeouts            map[string]string `debugmap:"visible"`
+
+	// Fault injection, for testing the resilience of clients. Never enabled by default.
+	FaultInjectionEnabled bool     `debugmap:"visible"`
+	FaultInjectionFaults  []string `debugmap:"visible"`
+
+	// Consistency
+	EnforceRevisionTokens           bool          `debugmap:"visible"`
+	RevisionTokenTimeout            time.Duration `debugmap:"visible"`
+	AdaptiveConsistencyMaxStaleness time.Duration `debugmap:"visible"`
+
+	// Permission metrics
+	PermissionMetricsMaxCardinality uint32 `debugmap:"visible"`
+
+	// Namespace metrics
+	NamespaceMetricsEnabled         bool          `debugmap:"visible"`
+	NamespaceMetricsRefreshInterval time.Duration `debugmap:"visible"`
+
+	// Namespace quotas
+	NamespaceMaxRelationships     map[string]string `debugmap:"visible"`
+	NamespaceMaxWriteRate         map[string]string `debugmap:"visible"`
+	NamespaceMaxLookupResults     map[string]string `debugmap:"visible"`
+	NamespaceQuotaRefreshInterval time.Duration     `debugmap:"visible"`
+
+	// Write admission policies
+	WriteAdmissionPolicies []string `debugmap:"visible"`
+
+	// Usage accounting
+	UsageAccountingEnabled                  bool              `debugmap:"visible"`
+	UsageMonthlyRequestQuota                map[string]string `debugmap:"visible"`
+	UsageMonthlyMutationQuota               map[string]string `debugmap:"visible"`
+	UsageStoredRelationshipsRefreshInterval time.Duration     `debugmap:"visible"`
 
 	// Additional Services
-	MetricsAPI util.HTTPServerConfig `debugmap:"visible"`
+	MetricsAPI                  util.HTTPServerConfig `debugmap:"visible"`
+	DebugAPI                    util.HTTPServerConfig `debugmap:"visible"`
+	DebugLogOverrideMaxDuration time.Duration         `debugmap:"visible"`
+	GRPCMetricsLatencyBuckets   []float64             `debugmap:"visible"`
+	StatsDAddr                  string                `debugmap:"visible"`
+	StatsDPrefix                string                `debugmap:"visible"`
+	StatsDTags                  []string              `debugmap:"visible"`
+	StatsDInterval              time.Duration         `debugmap:"visible"`
+
+	// Changefeed
+	ChangefeedKafkaBrokers       []string      `debugmap:"visible"`
+	ChangefeedKafkaTopic         string        `debugmap:"visible"`
+	ChangefeedName               string        `debugmap:"visible"`
+	ChangefeedCheckpointInterval time.Duration `debugmap:"visible"`
+
+	// Scheduled backups
+	BackupBucketURL         string        `debugmap:"visible"`
+	BackupInterval          time.Duration `debugmap:"visible"`
+	BackupRetention         int           `debugmap:"visible"`
+	BackupEncryptionKeyFile string        `debugmap:"visible"`
+
+	// Access reports
+	AccessReportBucketURL  string `debugmap:"visible"`
+	AccessReportMaxRunning int    `debugmap:"visible"`
+
+	// Leader election
+	LeaderElectionEnabled       bool          `debugmap:"visible"`
+	LeaderElectionNodeName      string        `debugmap:"visible"`
+	LeaderElectionLeaseDuration time.Duration `debugmap:"visible"`
 
 	// Middleware for grpc API
 	UnaryMiddlewareModification     []MiddlewareModification[grpc.UnaryServerInterceptor]  `debugmap:"hidden"`
@@ -129,47 +309,87 @@ type Config struct {
 	TelemetryInterval        time.Duration `debugmap:"visible"`
 
 	// Logs
-	EnableRequestLogs  bool `debugmap:"visible"`
-	EnableResponseLogs bool `debugmap:"visible"`
-}
-
-type closeableStack struct {
-	closers []func() error
-}
-
-func (c *closeableStack) AddWithError(closer func() error) {
-	c.closers = append(c.closers, closer)
-}
-
-func (c *closeableStack) AddCloser(closer io.Closer) {
-	if closer != nil {
-		c.closers = append(c.closers, closer.Close)
-	}
-}
-
-func (c *closeableStack) AddWithoutError(closer func()) {
-	c.closers = append(c.closers, func() error {
-		closer()
-		return nil
-	})
-}
-
-func (c *closeableStack) Close() error {
-	var err error
-	// closer in reverse order how it's expected in deferred funcs
-	for i := len(c.closers) - 1; i >= 0; i-- {
-		if closerErr := c.closers[i](); closerErr != nil {
-			err = multierror.Append(err, closerErr)
-		}
-	}
-	return err
-}
-
-func (c *closeableStack) CloseIfError(err error) error {
-	if err != nil {
-		return c.Close()
-	}
-	return nil
+	EnableRequestLogs  bool              `debugmap:"visible"`
+	EnableResponseLogs bool              `debugmap:"visible"`
+	LogSampleRates     map[string]string `debugmap:"visible"`
+
+	// Rate limiting
+	RateLimitReadsPerSecond  float64 `debugmap:"visible"`
+	RateLimitReadBurst       int     `debugmap:"visible"`
+	RateLimitWritesPerSecond float64 `debugmap:"visible"`
+	RateLimitWriteBurst      int     `debugmap:"visible"`
+	MaxInFlightRequests      uint32  `debugmap:"visible"`
+
+	MaxInFlightLowPriorityPercent uint32        `debugmap:"visible"`
+	MaxInFlightRetryAfter         time.Duration `debugmap:"visible"`
+
+	// Audit log
+	AuditLogSink           string        `debugmap:"visible"`
+	AuditLogFilePath       string        `debugmap:"visible"`
+	AuditLogFileMaxSizeMB  int           `debugmap:"visible"`
+	AuditLogFileMaxBackups int           `debugmap:"visible"`
+	AuditLogHTTPEndpoint   string        `debugmap:"visible"`
+	AuditLogHTTPTimeout    time.Duration `debugmap:"visible"`
+
+	// Decision logs
+	DecisionLogSink           string        `debugmap:"visible"`
+	DecisionLogHTTPEndpoint   string        `debugmap:"visible"`
+	DecisionLogUploadInterval time.Duration `debugmap:"visible"`
+	DecisionLogBatchSize      int           `debugmap:"visible"`
+	DecisionLogBufferSize     int           `debugmap:"visible"`
+	DecisionLogHTTPTimeout    time.Duration `debugmap:"visible"`
+
+	// Shadow checks
+	ShadowCheckSampleRate    float64       `debugmap:"visible"`
+	ShadowCheckSchemaFile    string        `debugmap:"visible"`
+	ShadowCheckEndpoint      string        `debugmap:"visible"`
+	ShadowCheckToken         string        `debugmap:"sensitive"`
+	ShadowCheckInsecure      bool          `debugmap:"visible"`
+	ShadowCheckCAPath        string        `debugmap:"visible"`
+	ShadowCheckExactSnapshot bool          `debugmap:"visible"`
+	ShadowCheckMaxInFlight   uint32        `debugmap:"visible"`
+	ShadowCheckTimeout       time.Duration `debugmap:"visible"`
+
+	// Federation
+	FederationRemoteNamespaces map[string]string `debugmap:"visible"`
+	FederationToken            string            `debugmap:"sensitive"`
+	FederationInsecure         bool              `debugmap:"visible"`
+	FederationCAPath           string            `debugmap:"visible"`
+	FederationMaxStaleness     time.Duration     `debugmap:"visible"`
+	FederationTimeout          time.Duration     `debugmap:"visible"`
+
+	// Relationship transforms
+	RelationshipTransforms map[string]string `debugmap:"visible"`
+
+	// Schema webhooks
+	SchemaWebhookURLs        []string      `debugmap:"visible"`
+	SchemaWebhookSecret      string        `debugmap:"sensitive"`
+	SchemaWebhookTimeout     time.Duration `debugmap:"visible"`
+	SchemaWebhookMaxAttempts int           `debugmap:"visible"`
+
+	// Envoy external authorization
+	ExtAuthzRulesetPath string `debugmap:"visible"`
+
+	// HashiCorp Vault secrets
+	VaultAddress             string        `debugmap:"visible"`
+	VaultAuthMethod          string        `debugmap:"visible"`
+	VaultAuthMount           string        `debugmap:"visible"`
+	VaultToken               string        `debugmap:"sensitive"`
+	VaultRole                string        `debugmap:"visible"`
+	VaultSecretID            string        `debugmap:"sensitive"`
+	VaultKubernetesTokenPath string        `debugmap:"visible"`
+	VaultTLSPath             string        `debugmap:"visible"`
+	VaultTLSCommonName       string        `debugmap:"visible"`
+	VaultPresharedKeysPath   string        `debugmap:"visible"`
+	VaultRefreshInterval     time.Duration `debugmap:"visible"`
+
+	// Error reporting
+	ErrorReportingDSN         string  `debugmap:"sensitive"`
+	ErrorReportingEnvironment string  `debugmap:"visible"`
+	ErrorReportingSampleRate  float64 `debugmap:"visible"`
+
+	// Configuration reloading
+	ReloadConfigPath string `debugmap:"visible"`
 }
 
 // Complete validates the config and fills out defaults.
@@ -178,17 +398,76 @@ func (c *closeableStack) CloseIfError(err error) error {
 func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 	log.Ctx(ctx).Info().Fields(helpers.Flatten(c.DebugMap())).Msg("configuration")
 
-	closeables := closeableStack{}
+	lc := lifecycle.NewManager(c.ShutdownStopTimeout)
 	var err error
 	defer func() {
 		// if an error happens during the execution of Complete, all resources are cleaned up
-		if closeableErr := closeables.CloseIfError(err); closeableErr != nil {
-			log.Ctx(ctx).Err(closeableErr).Msg("failed to clean up resources on Config.Complete")
+		if err == nil {
+			return
+		}
+		if stopErr := lc.Stop(ctx); stopErr != nil {
+			log.Ctx(ctx).Err(stopErr).Msg("failed to clean up resources on Config.Complete")
 		}
 	}()
 
-	if len(c.PresharedSecureKey) < 1 && c.GRPCAuthFunc == nil {
-		return nil, fmt.Errorf("a preshared key must be provided to authenticate API requests")
+	var vaultSecrets *vault.Secrets
+	if c.VaultTLSPath != "" || c.VaultPresharedKeysPath != "" {
+		vaultSecrets, err = c.vaultSecrets(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	presharedKeys := c.PresharedSecureKey
+	presharedKeysFunc := func() []string { return presharedKeys }
+	if vaultSecrets != nil && c.VaultPresharedKeysPath != "" {
+		presharedKeysFunc = vaultSecrets.PresharedKeys
+	}
+
+	rateLimits := ratelimit.Config{
+		ReadsPerSecond:  c.RateLimitReadsPerSecond,
+		ReadBurst:       c.RateLimitReadBurst,
+		WritesPerSecond: c.RateLimitWritesPerSecond,
+		WriteBurst:      c.RateLimitWriteBurst,
+
+		MaxInFlightRequests:        c.MaxInFlightRequests,
+		LowPriorityInFlightPercent: c.MaxInFlightLowPriorityPercent,
+		InFlightRetryAfter:         c.MaxInFlightRetryAfter,
+	}
+	limiter := ratelimit.NewLimiter(rateLimits)
+
+	var reloader *configReloader
+	if c.ReloadConfigPath != "" {
+		// The level of the global logger cannot be changed while it is in use, so it is opened
+		// up and the level is instead enforced through the global level, which can.
+		logLevel := log.Logger.GetLevel()
+		log.SetGlobalLogger(log.Logger.Level(zerolog.TraceLevel))
+
+		reloader, err = newConfigReloader(ctx, c.ReloadConfigPath, logLevel, c.PresharedSecureKey, c.VaultPresharedKeysPath != "", rateLimits)
+		if err != nil {
+			return nil, err
+		}
+		limiter = reloader.limiter
+
+		if c.VaultPresharedKeysPath == "" {
+			// As with keys from Vault, the keys read at startup are used by in-process
+			// clients and for dispatch, while API requests are authenticated against the
+			// current keys.
+			c.PresharedSecureKey = reloader.PresharedKeys()
+			presharedKeysFunc = reloader.PresharedKeys
+		}
+	}
+
+	if len(c.PresharedSecureKey) < 1 && c.GRPCAuthFunc == nil && c.JWTIssuer == "" {
+		return nil, fmt.Errorf("a preshared key or JWT issuer must be provided to authenticate API requests")
+	}
+
+	if err := zedtoken.SetSigningKeys(c.ZedTokenSigningKeys, c.ZedTokenAllowUnsigned); err != nil {
+		return nil, err
+	}
+
+	if c.TenancyEnabled && c.GRPCAuthFunc == nil && c.JWTIssuer == "" {
+		return nil, errors.New("tenant isolation requires a JWT issuer, whose tokens name the tenant of each request")
 	}
 
 	if c.GRPCAuthFunc == nil {
@@ -201,15 +480,56 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 			log.Ctx(ctx).Trace().Int("preshared-key-"+strconv.Itoa(index+1)+"-length", len(presharedKey)).Msg("preshared key configured")
 		}
 
-		c.GRPCAuthFunc = auth.MustRequirePresharedKey(c.PresharedSecureKey)
+		if c.JWTIssuer != "" {
+			log.Ctx(ctx).Info().Str("issuer", c.JWTIssuer).Msg("using gRPC auth with JWTs")
+			validator, err := auth.NewJWTValidator(ctx, auth.JWTConfig{
+				Issuer:   c.JWTIssuer,
+				JWKSURL:  c.JWTJWKSURL,
+				Audience: c.JWTAudience,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to initialize JWT auth: %w", err)
+			}
+			c.GRPCAuthFunc = auth.RequirePresharedKeyOrJWT(presharedKeysFunc, validator)
+		} else {
+			c.GRPCAuthFunc = auth.RequirePresharedKeyFunc(presharedKeysFunc)
+		}
 	} else {
 		log.Ctx(ctx).Trace().Msg("using preconfigured auth function")
 	}
 
+	scopedKeys, err := c.scopedPresharedKeys()
+	if err != nil {
+		return nil, err
+	}
+	if len(scopedKeys) > 0 {
+		log.Ctx(ctx).Info().Int("keys", len(scopedKeys)).Msg("using gRPC auth with scoped preshared keys")
+		c.GRPCAuthFunc = auth.RequireScopedKeyOr(scopedKeys, c.GRPCAuthFunc)
+	}
+
+	if len(c.GRPCMetricsLatencyBuckets) > 0 {
+		ConfigureGRPCMetricsLatencyBuckets(c.GRPCMetricsLatencyBuckets)
+	}
+
+	if c.EnforceRevisionTokens && c.Datastore == nil {
+		if err := c.DatastoreConfig.ValidateRevisionTokenGuarantees(); err != nil {
+			return nil, spiceerrors.NewTerminationErrorBuilder(fmt.Errorf("cannot enforce revision tokens: %w", err)).
+				Component("datastore").
+				ExitCode(sysexits.Config).
+				Error()
+		}
+	}
+
 	ds := c.Datastore
 	if ds == nil {
+		datastoreConfig := c.DatastoreConfig
+		if c.LeaderElectionEnabled {
+			// Garbage collection is run by the leader, rather than by the datastore of every node.
+			datastoreConfig.GCInterval = 0
+		}
+
 		var err error
-		ds, err = datastorecfg.NewDatastore(context.Background(), c.DatastoreConfig.ToOption())
+		ds, err = datastorecfg.NewDatastore(context.Background(), datastoreConfig.ToOption())
 		if err != nil {
 			return nil, spiceerrors.NewTerminationErrorBuilder(fmt.Errorf("failed to create datastore: %w", err)).
 				Component("datastore").
@@ -217,27 +537,67 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 				Error()
 		}
 	}
-	closeables.AddWithError(ds.Close)
+	lc.AddWithError("datastore", ds.Close)
+
+	cacheBudget, err := c.cacheBudget()
+	if err != nil {
+		return nil, err
+	}
+	if cacheBudget != nil {
+		lc.AddWithoutError("cache budget", cacheBudget.Close)
+		log.Ctx(ctx).Info().Str("maxCost", humanize.IBytes(uint64(cacheBudget.MaxCost()))).Msg("configured cache memory budget")
+		if reloader != nil {
+			reloader.cacheBudget = cacheBudget
+		}
+	}
+
+	// registerCache places the cache, under the prefix of its flags, within the memory budget
+	// and makes its size reloadable.
+	registerCache := func(name string, cc cache.Cache, config CacheConfig) {
+		if cacheBudget != nil {
+			if maxCost, err := parseMaxCost(config.MaxCost); err == nil {
+				cacheBudget.Add(name, cc, int64(maxCost))
+			}
+		}
+		if reloader != nil {
+			reloader.addCache(name, cc, config)
+		}
+	}
 
 	nscc, err := c.NamespaceCacheConfig.Complete()
 	if err != nil {
 		return nil, fmt.Errorf("failed to create namespace cache: %w", err)
 	}
 	log.Ctx(ctx).Info().EmbedObject(nscc).Msg("configured namespace cache")
+	registerCache("ns-cache", nscc, c.NamespaceCacheConfig)
 
 	cachingMode := schemacaching.JustInTimeCaching
 	if c.EnableExperimentalWatchableSchemaCache {
 		cachingMode = schemacaching.WatchIfSupported
 	}
 
-	ds = proxy.NewObservableDatastoreProxy(ds)
+	ds = proxy.NewObservableDatastoreProxy(ds, c.DatastoreConfig.Engine, c.SlowRequestThreshold)
 	ds = proxy.NewSingleflightDatastoreProxy(ds)
 	ds = schemacaching.NewCachingDatastoreProxy(ds, nscc, c.DatastoreConfig.GCWindow, cachingMode, c.SchemaWatchHeartbeat)
-	closeables.AddWithError(ds.Close)
+	lc.AddWithError("datastore proxies", ds.Close)
 
 	specificConcurrencyLimits := c.DispatchConcurrencyLimits
 	concurrencyLimits := specificConcurrencyLimits.WithOverallDefaultLimit(c.GlobalDispatchConcurrencyLimit)
 
+	traversalLimits := maingraph.CheckTraversalLimits{
+		MaxRelationships: c.DispatchCheckMaxRelationships,
+		MaxSubproblems:   c.DispatchCheckMaxSubproblems,
+	}
+
+	var groupIndex *groupindex.Index
+	if len(c.GroupIndexRelations) > 0 && c.Dispatcher == nil {
+		groupIndex, err = groupindex.NewIndex(c.GroupIndexRelations, c.GroupIndexMaxStaleness)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure group index: %w", err)
+		}
+		log.Ctx(ctx).Info().Strs("relations", c.GroupIndexRelations).Dur("max-staleness", c.GroupIndexMaxStaleness).Msg("group index enabled")
+	}
+
 	dispatcher := c.Dispatcher
 	if dispatcher == nil {
 		cc, err := c.DispatchCacheConfig.WithRevisionParameters(
@@ -248,36 +608,112 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 		if err != nil {
 			return nil, fmt.Errorf("failed to create dispatcher: %w", err)
 		}
-		closeables.AddWithoutError(cc.Close)
+		lc.AddWithoutError("dispatch cache", cc.Close)
 		log.Ctx(ctx).Info().EmbedObject(cc).Msg("configured dispatch cache")
+		registerCache("dispatch-cache", cc, c.DispatchCacheConfig)
+
+		dcc, err := c.completeDeniedCache(ctx, "dispatch-denied-cache", c.DispatchDeniedCacheConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create dispatcher: %w", err)
+		}
+		if dcc != nil {
+			lc.AddWithoutError("dispatch denied cache", dcc.Close)
+			registerCache("dispatch-denied-cache", dcc, c.DispatchDeniedCacheConfig)
+		}
+
+		upstreamAddr, err := dispatchUpstreamAddr(c.DispatchUpstreamAddr, c.DispatchUpstreamKubernetesService)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create dispatcher: %w", err)
+		}
 
 		dispatchPresharedKey := ""
-		if len(c.PresharedSecureKey) > 0 {
+		if len(c.DispatchPresharedKey) > 0 {
+			dispatchPresharedKey = c.DispatchPresharedKey[0]
+		} else if len(c.PresharedSecureKey) > 0 {
 			dispatchPresharedKey = c.PresharedSecureKey[0]
 		}
 
-		hashringConfigJSON, err := (&consistent.BalancerConfig{
-			ReplicationFactor: c.DispatchHashringReplicationFactor,
-			Spread:            c.DispatchHashringSpread,
+		hashringConfigJSON, err := (&dispatchbalancer.BalancerConfig{
+			ReplicationFactor:  c.DispatchHashringReplicationFactor,
+			Spread:             c.DispatchHashringSpread,
+			LoadFactor:         c.DispatchHashringLoadFactor,
+			DualDispatchWindow: c.DispatchHashringDualDispatchWindow,
 		}).ServiceConfigJSON()
 		if err != nil {
 			return nil, fmt.Errorf("failed to create gRPC hashring balancer config: %w", err)
 		}
 
+		dialOpts := []grpc.DialOption{
+			grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()),   // nolint: staticcheck
+			grpc.WithStreamInterceptor(otelgrpc.StreamClientInterceptor()), // nolint: staticcheck
+		}
+
+		// Upstreams resolved through xDS are balanced with the policy chosen by the control
+		// plane, so the dispatch key is sent as a header for its routes to hash on instead.
+		if xds.IsTarget(upstreamAddr) {
+			log.Ctx(ctx).Info().Str("upstream", upstreamAddr).Msg("dispatch upstream is resolved and balanced through xDS")
+			dialOpts = append(dialOpts,
+				grpc.WithChainUnaryInterceptor(xds.UnaryHashKeyInterceptor),
+				grpc.WithChainStreamInterceptor(xds.StreamHashKeyInterceptor),
+			)
+		} else {
+			dialOpts = append(dialOpts,
+				grpc.WithDefaultServiceConfig(hashringConfigJSON),
+				grpc.WithChainUnaryInterceptor(dispatchbalancer.UnaryDualDispatchInterceptor),
+			)
+		}
+
+		if c.DispatchUpstreamDiscovery != "" {
+			if upstreamAddr != "" {
+				return nil, fmt.Errorf("failed to create dispatcher: only one of a dispatch upstream address, Kubernetes service or discovery backend can be provided")
+			}
+
+			membership, err := discovery.NewMembership(discovery.Config{
+				Backend:  c.DispatchUpstreamDiscovery,
+				Target:   c.DispatchUpstreamDiscoveryTarget,
+				Endpoint: c.DispatchUpstreamDiscoveryEndpoint,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to create dispatcher: %w", err)
+			}
+
+			log.Ctx(ctx).Info().Str("backend", c.DispatchUpstreamDiscovery).Str("target", c.DispatchUpstreamDiscoveryTarget).Msg("discovering dispatch cluster members")
+			upstreamAddr = discovery.Scheme + ":///" + c.DispatchUpstreamDiscovery
+			dialOpts = append(dialOpts, grpc.WithResolvers(discovery.NewResolverBuilder(membership, c.DispatchUpstreamDiscoveryInterval)))
+		}
+
+		var hedgingConfig *combineddispatch.HedgingConfig
+		if c.DispatchHedgingEnabled {
+			hedgingConfig = &combineddispatch.HedgingConfig{
+				InitialSlowValue: c.DispatchHedgingInitialSlowValue,
+				MaxRequests:      c.DispatchHedgingMaxRequests,
+				Quantile:         c.DispatchHedgingQuantile,
+			}
+		}
+
+		federationConfig := c.federationConfig()
+		if federationConfig != nil {
+			log.Ctx(ctx).Info().Interface("remote-namespaces", c.FederationRemoteNamespaces).Dur("max-staleness", c.FederationMaxStaleness).Msg("federation enabled")
+		}
+
 		dispatcher, err = combineddispatch.NewDispatcher(
-			combineddispatch.UpstreamAddr(c.DispatchUpstreamAddr),
+			combineddispatch.UpstreamAddr(upstreamAddr),
 			combineddispatch.UpstreamCAPath(c.DispatchUpstreamCAPath),
+			combineddispatch.UpstreamClientCert(c.DispatchUpstreamTLSCertPath, c.DispatchUpstreamTLSKeyPath),
 			combineddispatch.SecondaryUpstreamAddrs(c.DispatchSecondaryUpstreamAddrs),
 			combineddispatch.SecondaryUpstreamExprs(c.DispatchSecondaryUpstreamExprs),
 			combineddispatch.GrpcPresharedKey(dispatchPresharedKey),
-			combineddispatch.GrpcDialOpts(
-				grpc.WithUnaryInterceptor(otelgrpc.UnaryClientInterceptor()), // nolint: staticcheck
-				grpc.WithDefaultServiceConfig(hashringConfigJSON),
-			),
+			combineddispatch.LocalFallback(c.DispatchLocalFallbackEnabled),
+			combineddispatch.Hedging(hedgingConfig),
+			combineddispatch.Federation(federationConfig),
+			combineddispatch.GrpcDialOpts(dialOpts...),
 			combineddispatch.MetricsEnabled(c.DispatchClientMetricsEnabled),
 			combineddispatch.PrometheusSubsystem(c.DispatchClientMetricsPrefix),
 			combineddispatch.Cache(cc),
+			combineddispatch.DeniedCheckCache(dcc),
 			combineddispatch.ConcurrencyLimits(concurrencyLimits),
+			combineddispatch.GroupIndex(groupIndex),
+			combineddispatch.CheckTraversalLimits(traversalLimits),
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create dispatcher: %w", err)
@@ -285,12 +721,38 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 
 		log.Ctx(ctx).Info().EmbedObject(concurrencyLimits).RawJSON("balancerconfig", []byte(hashringConfigJSON)).Msg("configured dispatcher")
 	}
-	closeables.AddWithError(dispatcher.Close)
+
+	var cacheWarmup *warmup.Dispatcher
+	if c.CacheWarmupEnabled {
+		cacheWarmup = warmup.NewDispatcher(dispatcher, warmup.Config{
+			File:           c.CacheWarmupFile,
+			MaxSubproblems: c.CacheWarmupMaxSubproblems,
+			SampleRate:     c.CacheWarmupSampleRate,
+			SaveInterval:   cacheWarmupSaveInterval,
+			Timeout:        c.CacheWarmupTimeout,
+			MaxDepth:       c.DispatchMaxDepth,
+		})
+		dispatcher = cacheWarmup
+	}
+	lc.AddWithError("dispatcher", dispatcher.Close)
+
+	var materializedPermissions *materialized.Sets
+	if len(c.MaterializedPermissions) > 0 {
+		materializedPermissions, err = materialized.NewSets(c.MaterializedPermissions, dispatcher, c.DispatchMaxDepth, c.MaterializedPermissionsMaxStaleness)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure materialized permissions: %w", err)
+		}
+		log.Ctx(ctx).Info().Strs("permissions", c.MaterializedPermissions).Dur("max-staleness", c.MaterializedPermissionsMaxStaleness).Msg("materialized permissions enabled")
+	}
 
 	if len(c.DispatchUnaryMiddleware) == 0 && len(c.DispatchStreamingMiddleware) == 0 {
-		if c.GRPCAuthFunc == nil {
+		switch {
+		case len(c.DispatchPresharedKey) > 0:
+			// Internal dispatch traffic is authenticated separately from the public API.
+			c.DispatchUnaryMiddleware, c.DispatchStreamingMiddleware = DefaultDispatchMiddleware(log.Logger, auth.MustRequirePresharedKey(c.DispatchPresharedKey), ds)
+		case c.GRPCAuthFunc == nil:
 			c.DispatchUnaryMiddleware, c.DispatchStreamingMiddleware = DefaultDispatchMiddleware(log.Logger, auth.MustRequirePresharedKey(c.PresharedSecureKey), ds)
-		} else {
+		default:
 			c.DispatchUnaryMiddleware, c.DispatchStreamingMiddleware = DefaultDispatchMiddleware(log.Logger, c.GRPCAuthFunc, ds)
 		}
 	}
@@ -306,20 +768,34 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 			return nil, fmt.Errorf("failed to configure cluster dispatch: %w", err)
 		}
 		log.Ctx(ctx).Info().EmbedObject(cdcc).Msg("configured cluster dispatch cache")
-		closeables.AddWithoutError(cdcc.Close)
+		registerCache("dispatch-cluster-cache", cdcc, c.ClusterDispatchCacheConfig)
+		lc.AddWithoutError("cluster dispatch cache", cdcc.Close)
+
+		cddcc, err := c.completeDeniedCache(ctx, "dispatch-cluster-denied-cache", c.ClusterDispatchDeniedCacheConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure cluster dispatch: %w", err)
+		}
+		if cddcc != nil {
+			lc.AddWithoutError("cluster dispatch denied cache", cddcc.Close)
+			registerCache("dispatch-cluster-denied-cache", cddcc, c.ClusterDispatchDeniedCacheConfig)
+		}
 
 		cachingClusterDispatch, err = clusterdispatch.NewClusterDispatcher(
 			dispatcher,
 			clusterdispatch.MetricsEnabled(c.DispatchClusterMetricsEnabled),
 			clusterdispatch.PrometheusSubsystem(c.DispatchClusterMetricsPrefix),
 			clusterdispatch.Cache(cdcc),
+			clusterdispatch.DeniedCheckCache(cddcc),
 			clusterdispatch.RemoteDispatchTimeout(c.DispatchUpstreamTimeout),
 			clusterdispatch.ConcurrencyLimits(concurrencyLimits),
+			clusterdispatch.GroupIndex(groupIndex),
+			clusterdispatch.CheckTraversalLimits(traversalLimits),
+			clusterdispatch.CacheMaxStaleness(c.federationCacheMaxStaleness()),
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to configure cluster dispatch: %w", err)
 		}
-		closeables.AddWithError(cachingClusterDispatch.Close)
+		lc.AddWithError("cluster dispatcher", cachingClusterDispatch.Close)
 	}
 
 	dispatchGrpcServer, err := c.DispatchServer.Complete(zerolog.InfoLevel,
@@ -332,7 +808,7 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create dispatch gRPC server: %w", err)
 	}
-	closeables.AddWithoutError(dispatchGrpcServer.GracefulStop)
+	lc.Add(c.grpcServerHook("dispatch gRPC server", dispatchGrpcServer))
 
 	datastoreFeatures, err := ds.Features(ctx)
 	if err != nil {
@@ -342,15 +818,137 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 	v1SchemaServiceOption := services.V1SchemaServiceEnabled
 	if c.DisableV1SchemaAPI {
 		v1SchemaServiceOption = services.V1SchemaServiceDisabled
+	} else if c.DisableSchemaWrites || c.SchemaDirectory != "" {
+		// The schema of a schema directory is only changed through its files.
+		v1SchemaServiceOption = services.V1SchemaServiceReadOnly
 	} else if c.V1SchemaAdditiveOnly {
 		v1SchemaServiceOption = services.V1SchemaServiceAdditiveOnly
 	}
 
 	watchServiceOption := services.WatchServiceEnabled
-	if !datastoreFeatures.Watch.Enabled {
+	if c.DisableWatchAPI {
+		watchServiceOption = services.WatchServiceDisabled
+	} else if !datastoreFeatures.Watch.Enabled {
 		log.Ctx(ctx).Warn().Str("reason", datastoreFeatures.Watch.Reason).Msg("watch api disabled; underlying datastore does not support it")
 		watchServiceOption = services.WatchServiceDisabled
 	}
+	if c.WatchOverflow != "" && !slices.Contains(v1svc.WatchOverflowBehaviors, v1svc.WatchOverflowBehavior(c.WatchOverflow)) {
+		return nil, fmt.Errorf("unknown watch overflow behavior `%s`", c.WatchOverflow)
+	}
+
+	reflectionOption := services.ReflectionDisabled
+	if c.EnableReflection && !c.DisableReflection {
+		reflectionOption = services.ReflectionEnabled
+	}
+
+	auditSink, err := c.auditSink()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create audit log sink: %w", err)
+	}
+	if auditSink != nil {
+		log.Ctx(ctx).Info().Str("sink", c.AuditLogSink).Msg("audit log enabled")
+		lc.AddWithError("audit log", auditSink.Close)
+	}
+
+	var schemaSyncer *schemadir.Syncer
+	if c.SchemaDirectory != "" {
+		if c.DisableWrites {
+			return nil, errors.New("a schema directory cannot be synced while writes are disabled")
+		}
+		schemaSyncer, err = schemadir.NewSyncer(c.SchemaDirectory, ds, auditSink, c.V1SchemaAdditiveOnly)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create schema directory syncer: %w", err)
+		}
+		log.Ctx(ctx).Info().Str("dir", c.SchemaDirectory).Msg("schema directory enabled")
+	}
+
+	decisionLogger, err := c.decisionLogger()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create decision logger: %w", err)
+	}
+	if decisionLogger != nil {
+		log.Ctx(ctx).Info().Str("sink", c.DecisionLogSink).Msg("decision logs enabled")
+		lc.AddWithError("decision log", decisionLogger.Close)
+	}
+
+	shadower, err := c.shadower(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create shadow checks: %w", err)
+	}
+	if shadower != nil {
+		log.Ctx(ctx).Info().Float64("sample-rate", c.ShadowCheckSampleRate).Str("schema-file", c.ShadowCheckSchemaFile).Str("endpoint", c.ShadowCheckEndpoint).Msg("shadow checks enabled")
+		lc.AddWithError("shadow checks", shadower.Close)
+	}
+
+	var schemaWebhookNotifier *schemawebhook.Notifier
+	if len(c.SchemaWebhookURLs) > 0 {
+		schemaWebhookNotifier, err = schemawebhook.NewNotifier(c.SchemaWebhookURLs, c.SchemaWebhookSecret, c.SchemaWebhookTimeout, c.SchemaWebhookMaxAttempts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create schema webhook notifier: %w", err)
+		}
+		log.Ctx(ctx).Info().Int("webhooks", len(c.SchemaWebhookURLs)).Msg("schema webhooks enabled")
+		lc.AddWithError("schema webhooks", schemaWebhookNotifier.Close)
+	}
+
+	var errorReporter *errorreport.Reporter
+	if c.ErrorReportingDSN != "" {
+		release, _ := releases.CurrentVersion()
+		errorReporter, err = errorreport.NewReporter(errorreport.Config{
+			DSN:         c.ErrorReportingDSN,
+			Environment: c.ErrorReportingEnvironment,
+			Release:     release,
+			SampleRate:  c.ErrorReportingSampleRate,
+		})
+		if err != nil {
+			return nil, err
+		}
+		log.Ctx(ctx).Info().Str("environment", c.ErrorReportingEnvironment).Msg("error reporting enabled")
+		lc.AddWithoutError("error reporting", errorReporter.Close)
+	}
+
+	requestTimeouts, err := c.requestTimeouts()
+	if err != nil {
+		return nil, err
+	}
+
+	logSampler, err := c.logSampler()
+	if err != nil {
+		return nil, err
+	}
+
+	var logOverrides *debuglog.Overrides
+	if c.DebugLogOverrideMaxDuration > 0 {
+		logOverrides = debuglog.NewOverrides(c.DebugLogOverrideMaxDuration)
+		lc.AddWithoutError("log level overrides", logOverrides.Reset)
+	}
+
+	faultInjector, err := c.faultInjector()
+	if err != nil {
+		return nil, err
+	}
+	if faultInjector != nil {
+		log.Ctx(ctx).Warn().Int("methods", len(c.FaultInjectionFaults)).Msg("fault injection enabled: API calls will be delayed and fail on purpose")
+	}
+
+	transforms, err := transform.FromConfig(c.RelationshipTransforms)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure relationship transforms: %w", err)
+	}
+	if len(transforms) > 0 {
+		log.Ctx(ctx).Info().Interface("transforms", c.RelationshipTransforms).Msg("relationship transforms enabled")
+	}
+
+	usageAccountant, err := c.usageAccountant()
+	if err != nil {
+		return nil, err
+	}
+
+	// Stored relationships are attributed to tenants by the prefix of their definitions, so
+	// they are only counted with tenant isolation.
+	var usageInterval time.Duration
+	if c.TenancyEnabled {
+		usageInterval = c.UsageStoredRelationshipsRefreshInterval
+	}
 
 	opts := MiddlewareOption{
 		log.Logger,
@@ -360,6 +958,20 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 		ds,
 		c.EnableRequestLogs,
 		c.EnableResponseLogs,
+		limiter,
+		auditSink,
+		logSampler,
+		c.consistencyOptions(),
+		requestTimeouts,
+		schemaWebhookNotifier,
+		errorReporter,
+		decisionLogger,
+		c.TenancyEnabled,
+		faultInjector,
+		shadower,
+		transforms,
+		usageAccountant,
+		logOverrides,
 	}
 	defaultUnaryMiddlewareChain, err := DefaultUnaryMiddleware(opts)
 	if err != nil {
@@ -389,6 +1001,46 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 		return nil, fmt.Errorf("error building streaming middlewares: %w", err)
 	}
 
+	if c.DisableWrites {
+		// The datastore is made read-only last, after it has been set by the middleware.
+		unaryMiddleware = append(unaryMiddleware, readonly.UnaryServerInterceptor())
+		streamingMiddleware = append(streamingMiddleware, readonly.StreamServerInterceptor())
+	}
+
+	var namespaceMetrics *namespacemetrics.KnownNamespaces
+	if c.NamespaceMetricsEnabled {
+		if c.NamespaceMetricsRefreshInterval <= 0 {
+			return nil, errors.New("namespace metrics refresh interval must be positive")
+		}
+		namespaceMetrics = namespacemetrics.NewKnownNamespaces()
+	}
+
+	namespaceQuotas, err := c.namespaceQuotas()
+	if err != nil {
+		return nil, err
+	}
+
+	admissionPolicies, err := c.admissionPolicies()
+	if err != nil {
+		return nil, err
+	}
+	if admissionPolicies != nil {
+		log.Ctx(ctx).Info().Int("policies", len(c.WriteAdmissionPolicies)).Msg("write admission policies enabled")
+	}
+
+	for _, limits := range []struct {
+		api                    string
+		defaultLimit, maxLimit uint32
+	}{
+		{"ReadRelationships", c.DefaultReadRelationshipsLimit, c.MaxReadRelationshipsLimit},
+		{"LookupResources", c.DefaultLookupResourcesLimit, c.MaxLookupResourcesLimit},
+		{"BulkExportRelationships", c.DefaultExportBatchSize, c.MaxExportBatchSize},
+	} {
+		if limits.maxLimit > 0 && limits.defaultLimit > limits.maxLimit {
+			return nil, fmt.Errorf("the default limit of %s calls, %d, exceeds their maximum of %d", limits.api, limits.defaultLimit, limits.maxLimit)
+		}
+	}
+
 	permSysConfig := v1svc.PermissionsServerConfig{
 		MaxPreconditionsCount:      c.MaximumPreconditionCount,
 		MaxUpdatesPerWrite:         c.MaximumUpdatesPerWrite,
@@ -396,7 +1048,33 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 		MaxCaveatContextSize:       c.MaxCaveatContextSize,
 		MaxRelationshipContextSize: c.MaxRelationshipContextSize,
 		MaxDatastoreReadPageSize:   c.MaxDatastoreReadPageSize,
+		MaxReadRelationshipsLimit:  c.MaxReadRelationshipsLimit,
 		StreamingAPITimeout:        c.StreamingAPITimeout,
+		WriteBatchWindow:           c.WriteBatchWindow,
+
+		PermissionMetricsMaxCardinality: c.PermissionMetricsMaxCardinality,
+		SlowRequestThreshold:            c.SlowRequestThreshold,
+		NamespaceMetrics:                namespaceMetrics,
+		NamespaceQuotas:                 namespaceQuotas,
+		AdmissionPolicies:               admissionPolicies,
+		MaterializedPermissions:         materializedPermissions,
+		CheckSessionIdleTimeout:         c.CheckSessionIdleTimeout,
+
+		DefaultReadRelationshipsLimit: c.DefaultReadRelationshipsLimit,
+		MaxLookupResourcesLimit:       c.MaxLookupResourcesLimit,
+		DefaultLookupResourcesLimit:   c.DefaultLookupResourcesLimit,
+		DefaultExportBatchSize:        c.DefaultExportBatchSize,
+		MaxExportBatchSize:            c.MaxExportBatchSize,
+	}
+
+	var extAuthzServer authv3.AuthorizationServer
+	if c.ExtAuthzRulesetPath != "" {
+		ruleset, err := extauthz.LoadRuleset(c.ExtAuthzRulesetPath)
+		if err != nil {
+			return nil, err
+		}
+		log.Ctx(ctx).Info().Int("rules", len(ruleset.Rules)).Msg("envoy external authorization service enabled")
+		extAuthzServer = extauthz.NewAuthorizationServer(dispatcher, ruleset, c.DispatchMaxDepth)
 	}
 
 	healthManager := health.NewHealthManager(dispatcher, ds)
@@ -408,22 +1086,43 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 				dispatcher,
 				v1SchemaServiceOption,
 				watchServiceOption,
+				reflectionOption,
 				permSysConfig,
-				c.WatchHeartbeat,
+				v1svc.WatchServerConfig{
+					HeartbeatDuration: c.WatchHeartbeat,
+					BufferLength:      c.WatchBufferLength,
+					Overflow:          v1svc.WatchOverflowBehavior(c.WatchOverflow),
+				},
 			)
+			if extAuthzServer != nil {
+				authv3.RegisterAuthorizationServer(server, extAuthzServer)
+				healthManager.RegisterReportedService(extauthz.ServiceName)
+			}
 		},
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create gRPC server: %w", err)
 	}
-	closeables.AddWithoutError(grpcServer.GracefulStop)
+	grpcServer = grpcServer.WithOpts(grpc.ChainUnaryInterceptor(unaryMiddleware...), grpc.ChainStreamInterceptor(streamingMiddleware...))
+	lc.Add(c.grpcServerHook("gRPC server", grpcServer))
 
 	gatewayServer, gatewayCloser, err := c.initializeGateway(ctx)
 	if err != nil {
 		return nil, err
 	}
-	closeables.AddCloser(gatewayCloser)
-	closeables.AddWithoutError(gatewayServer.Close)
+	if gatewayCloser != nil {
+		lc.AddWithError("gateway connection", gatewayCloser.Close)
+	}
+	lc.Add(httpServerHook("gateway", gatewayServer))
+
+	grpcWebServer, grpcWebCloser, err := c.initializeGRPCWeb(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if grpcWebCloser != nil {
+		lc.AddWithError("gRPC-Web connection", grpcWebCloser.Close)
+	}
+	lc.Add(httpServerHook("gRPC-Web server", grpcWebServer))
 
 	var telemetryRegistry *prometheus.Registry
 
@@ -450,27 +1149,531 @@ func (c *Config) Complete(ctx context.Context) (RunnableServer, error) {
 		}
 	}
 
-	metricsServer, err := c.MetricsAPI.Complete(zerolog.InfoLevel, MetricsHandler(telemetryRegistry, c))
+	metricsServer, err := c.MetricsAPI.Complete(zerolog.InfoLevel, MetricsHandler(telemetryRegistry, c, healthManager.HealthSvc()))
 	if err != nil {
 		return nil, fmt.Errorf("failed to initialize metrics server: %w", err)
 	}
-	closeables.AddWithoutError(metricsServer.Close)
+	lc.Add(httpServerHook("metrics server", metricsServer))
+
+	var accessReports *accessreport.Manager
+	if c.AccessReportBucketURL != "" {
+		bucket, err := backup.OpenBucket(ctx, c.AccessReportBucketURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize access reports: %w", err)
+		}
+
+		accessReports, err = accessreport.NewManager(bucket, ds, dispatcher, c.DispatchMaxDepth, c.AccessReportMaxRunning)
+		if err != nil {
+			_ = bucket.Close()
+			return nil, fmt.Errorf("failed to initialize access reports: %w", err)
+		}
+		lc.AddWithError("access reports", accessReports.Close)
+	}
+
+	debugServer, err := c.DebugAPI.Complete(zerolog.InfoLevel, DebugHandler(c, presharedKeysFunc, usageAccountant, accessReports, logOverrides))
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize debug server: %w", err)
+	}
+	lc.Add(httpServerHook("debug server", debugServer))
+
+	var statsdExporter *statsd.Exporter
+	if c.StatsDAddr != "" {
+		statsdExporter, err = statsd.NewExporter(prometheus.DefaultGatherer, c.StatsDAddr, c.StatsDPrefix, c.StatsDTags, c.StatsDInterval)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize statsd exporter: %w", err)
+		}
+	}
+
+	var changefeedExporter *changefeed.Exporter
+	if len(c.ChangefeedKafkaBrokers) > 0 {
+		publisher, err := changefeed.NewKafkaPublisher(c.ChangefeedKafkaBrokers, c.ChangefeedKafkaTopic)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize changefeed: %w", err)
+		}
+
+		changefeedExporter, err = changefeed.NewExporter(publisher, c.ChangefeedName, c.ChangefeedCheckpointInterval)
+		if err != nil {
+			publisher.Close()
+			return nil, fmt.Errorf("failed to initialize changefeed: %w", err)
+		}
+	}
+
+	var backupScheduler *backup.Scheduler
+	if c.BackupBucketURL != "" {
+		backupScheduler, err = c.backupScheduler(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize scheduled backups: %w", err)
+		}
+	}
+
+	var elector *leaderelection.Elector
+	var garbageCollector common.GarbageCollector
+	if c.LeaderElectionEnabled {
+		elector, err = c.leaderElector()
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize leader election: %w", err)
+		}
+
+		if !c.DatastoreConfig.ReadOnly && c.DatastoreConfig.GCInterval > 0 {
+			garbageCollector = datastore.UnwrapAs[common.GarbageCollector](ds)
+		}
+		log.Ctx(ctx).Info().Bool("garbageCollection", garbageCollector != nil).Bool("changefeed", changefeedExporter != nil).Bool("scheduledBackups", backupScheduler != nil).Msg("background jobs will run on the elected leader")
+	}
 
 	return &completedServerConfig{
-		ds:                  ds,
-		gRPCServer:          grpcServer,
-		dispatchGRPCServer:  dispatchGrpcServer,
-		gatewayServer:       gatewayServer,
-		metricsServer:       metricsServer,
-		unaryMiddleware:     unaryMiddleware,
-		streamingMiddleware: streamingMiddleware,
-		presharedKeys:       c.PresharedSecureKey,
-		telemetryReporter:   reporter,
-		healthManager:       healthManager,
-		closeFunc:           closeables.Close,
+		ds:                 ds,
+		gRPCServer:         grpcServer,
+		dispatchGRPCServer: dispatchGrpcServer,
+		statsdExporter:     statsdExporter,
+		changefeedExporter: changefeedExporter,
+		backupScheduler:    backupScheduler,
+		elector:            elector,
+		garbageCollector:   garbageCollector,
+		gcInterval:         c.DatastoreConfig.GCInterval,
+		gcWindow:           c.DatastoreConfig.GCWindow,
+		gcTimeout:          c.DatastoreConfig.GCMaxOperationTime,
+		vaultSecrets:       vaultSecrets,
+		configReloader:     reloader,
+		schemaSyncer:       schemaSyncer,
+		presharedKeys:      c.PresharedSecureKey,
+		telemetryReporter:  reporter,
+		healthManager:      healthManager,
+		groupIndex:         groupIndex,
+		materialized:       materializedPermissions,
+		cacheWarmup:        cacheWarmup,
+		namespaceMetrics:   namespaceMetrics,
+		namespaceInterval:  c.NamespaceMetricsRefreshInterval,
+		namespaceQuotas:    namespaceQuotas,
+		quotaInterval:      c.NamespaceQuotaRefreshInterval,
+		usageAccountant:    usageAccountant,
+		usageInterval:      usageInterval,
+		lifecycle:          lc,
 	}, nil
 }
 
+// logSampler returns the sampler deciding which API calls are logged, or nil if all are.
+func (c *Config) logSampler() (*logmw.Sampler, error) {
+	if len(c.LogSampleRates) == 0 {
+		return nil, nil
+	}
+
+	rates := make(map[string]float64, len(c.LogSampleRates))
+	for method, value := range c.LogSampleRates {
+		rate, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid log sample rate for `%s`: %w", method, err)
+		}
+		rates[method] = rate
+	}
+	return logmw.NewSampler(rates)
+}
+
+// usageAccountant returns the accountant of the usage of each account, or nil if usage
+// accounting is disabled.
+func (c *Config) usageAccountant() (*usage.Accountant, error) {
+	quotas, err := usage.ParseQuotas(c.UsageMonthlyRequestQuota, c.UsageMonthlyMutationQuota)
+	if err != nil {
+		return nil, err
+	}
+	if !c.UsageAccountingEnabled {
+		if len(quotas) > 0 {
+			return nil, errors.New("monthly usage quotas require usage accounting to be enabled")
+		}
+		return nil, nil
+	}
+	return usage.NewAccountant(quotas), nil
+}
+
+// namespaceQuotas returns the per-namespace quotas, or nil if none are configured.
+func (c *Config) namespaceQuotas() (*namespacequota.Quotas, error) {
+	limits, err := namespacequota.ParseLimits(c.NamespaceMaxRelationships, c.NamespaceMaxWriteRate, c.NamespaceMaxLookupResults)
+	if err != nil {
+		return nil, err
+	}
+	if len(limits) > 0 && c.NamespaceQuotaRefreshInterval <= 0 {
+		return nil, errors.New("namespace quota refresh interval must be positive")
+	}
+	return namespacequota.NewQuotas(limits), nil
+}
+
+// admissionPolicies returns the write admission policies, or nil if none are configured.
+func (c *Config) admissionPolicies() (*admission.Policies, error) {
+	policies := make([]admission.Policy, 0, len(c.WriteAdmissionPolicies))
+	for _, encoded := range c.WriteAdmissionPolicies {
+		var policy admission.Policy
+		decoder := json.NewDecoder(strings.NewReader(encoded))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&policy); err != nil {
+			return nil, fmt.Errorf("invalid write admission policy `%s`: %w", encoded, err)
+		}
+		policies = append(policies, policy)
+	}
+	return admission.NewPolicies(policies)
+}
+
+// requestTimeouts returns the default and maximum timeouts of each class of API methods.
+func (c *Config) requestTimeouts() (map[deadline.Class]deadline.Timeouts, error) {
+	timeouts := make(map[deadline.Class]deadline.Timeouts, len(c.DefaultRequestTimeouts)+len(c.MaxRequestTimeouts))
+	for name, value := range c.DefaultRequestTimeouts {
+		class, timeout, err := parseRequestTimeout(name, value)
+		if err != nil {
+			return nil, err
+		}
+		classTimeouts := timeouts[class]
+		classTimeouts.Default = timeout
+		timeouts[class] = classTimeouts
+	}
+
+	for name, value := range c.MaxRequestTimeouts {
+		class, timeout, err := parseRequestTimeout(name, value)
+		if err != nil {
+			return nil, err
+		}
+		classTimeouts := timeouts[class]
+		classTimeouts.Max = timeout
+		timeouts[class] = classTimeouts
+	}
+	return timeouts, nil
+}
+
+// scopedKeyConfig is the JSON form of a key given with --grpc-scoped-preshared-key.
+type scopedKeyConfig struct {
+	Name       string   `json:"name"`
+	Key        string   `json:"key"`
+	Methods    []string `json:"methods"`
+	Namespaces []string `json:"namespaces"`
+}
+
+// scopedPresharedKeys returns the configured scoped preshared keys.
+func (c *Config) scopedPresharedKeys() ([]auth.ScopedKey, error) {
+	keys := make([]auth.ScopedKey, 0, len(c.ScopedPresharedKeys))
+	for index, encoded := range c.ScopedPresharedKeys {
+		var config scopedKeyConfig
+		decoder := json.NewDecoder(strings.NewReader(encoded))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&config); err != nil {
+			// The key is not included in the error, as it may contain the secret.
+			return nil, fmt.Errorf("invalid scoped preshared key #%d: %w", index+1, err)
+		}
+
+		keys = append(keys, auth.ScopedKey{
+			Name:       config.Name,
+			Key:        config.Key,
+			Methods:    config.Methods,
+			Namespaces: config.Namespaces,
+		})
+	}
+
+	if err := auth.ValidateScopedKeys(keys); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// faultConfig is the JSON form of the faults of a method given with --grpc-fault-injection.
+type faultConfig struct {
+	Method                 string  `json:"method"`
+	Latency                string  `json:"latency"`
+	LatencyProbability     float64 `json:"latency_probability"`
+	UnavailableProbability float64 `json:"unavailable_probability"`
+	StreamResetProbability float64 `json:"stream_reset_probability"`
+}
+
+// faultInjector returns the injector of the configured faults, or nil if fault injection is
+// disabled.
+func (c *Config) faultInjector() (*faultinjection.Injector, error) {
+	if !c.FaultInjectionEnabled {
+		if len(c.FaultInjectionFaults) > 0 {
+			return nil, errors.New("faults are only injected if fault injection is explicitly enabled")
+		}
+		return nil, nil
+	}
+
+	faults := make(map[string]faultinjection.Fault, len(c.FaultInjectionFaults))
+	for _, encoded := range c.FaultInjectionFaults {
+		var config faultConfig
+		decoder := json.NewDecoder(strings.NewReader(encoded))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&config); err != nil {
+			return nil, fmt.Errorf("invalid fault injection `%s`: %w", encoded, err)
+		}
+		if config.Method == "" {
+			return nil, fmt.Errorf("invalid fault injection `%s`: a method, or `%s` for all methods, is required", encoded, faultinjection.AllMethods)
+		}
+		if _, ok := faults[config.Method]; ok {
+			return nil, fmt.Errorf("duplicate fault injection for method `%s`", config.Method)
+		}
+
+		fault := faultinjection.Fault{
+			LatencyProbability:     config.LatencyProbability,
+			UnavailableProbability: config.UnavailableProbability,
+			StreamResetProbability: config.StreamResetProbability,
+		}
+		if config.Latency != "" {
+			latency, err := time.ParseDuration(config.Latency)
+			if err != nil {
+				return nil, fmt.Errorf("invalid fault injection latency for method `%s`: %w", config.Method, err)
+			}
+			fault.Latency = latency
+		}
+		faults[config.Method] = fault
+	}
+	return faultinjection.NewInjector(faults)
+}
+
+func parseRequestTimeout(name, value string) (deadline.Class, time.Duration, error) {
+	class, err := deadline.ParseClass(name)
+	if err != nil {
+		return "", 0, err
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		return "", 0, fmt.Errorf("invalid timeout for `%s`: %w", name, err)
+	}
+	if timeout < 0 {
+		return "", 0, fmt.Errorf("timeout for `%s` must not be negative", name)
+	}
+	return class, timeout, nil
+}
+
+// consistencyOptions returns the options of the middleware selecting the revision at which
+// each API call is evaluated.
+func (c *Config) consistencyOptions() []consistencymw.Option {
+	var opts []consistencymw.Option
+	if c.EnforceRevisionTokens {
+		opts = append(opts, consistencymw.WithEnforcedRevisionTokens(c.RevisionTokenTimeout))
+	}
+	if c.AdaptiveConsistencyMaxStaleness > 0 {
+		opts = append(opts, consistencymw.WithAdaptiveConsistency(c.AdaptiveConsistencyMaxStaleness))
+	}
+	return opts
+}
+
+// leaderElectorLease is the name of the lease held by the node running the cluster-singleton
+// background jobs.
+const leaderElectorLease = "background-jobs"
+
+// leaderElector returns the elector of the node running the cluster-singleton background jobs,
+// named after the host if no name is configured.
+func (c *Config) leaderElector() (*leaderelection.Elector, error) {
+	node := c.LeaderElectionNodeName
+	if node == "" {
+		hostname, err := os.Hostname()
+		if err != nil {
+			return nil, fmt.Errorf("failed to determine node name: %w", err)
+		}
+		node = hostname
+	}
+	return leaderelection.NewElector(leaderElectorLease, node, c.LeaderElectionLeaseDuration)
+}
+
+// backupScheduler returns the scheduler writing compressed backups to the configured bucket.
+func (c *Config) backupScheduler(ctx context.Context) (*backup.Scheduler, error) {
+	opts := backup.Options{Compress: true}
+	if c.BackupEncryptionKeyFile != "" {
+		key, err := backup.ReadEncryptionKeyFile(c.BackupEncryptionKeyFile)
+		if err != nil {
+			return nil, err
+		}
+		opts.EncryptionKey = key
+	}
+
+	bucket, err := backup.OpenBucket(ctx, c.BackupBucketURL)
+	if err != nil {
+		return nil, err
+	}
+
+	scheduler, err := backup.NewScheduler(bucket, c.BackupInterval, c.BackupRetention, opts)
+	if err != nil {
+		_ = bucket.Close()
+		return nil, err
+	}
+	return scheduler, nil
+}
+
+// vaultSecrets reads the TLS certificate and preshared keys from Vault, in place of those
+// given by flags, keeping them in memory to be refreshed by Run.
+func (c *Config) vaultSecrets(ctx context.Context) (*vault.Secrets, error) {
+	if c.VaultTLSPath != "" && (c.GRPCServer.TLSCertPath != "" || c.GRPCServer.TLSKeyPath != "") {
+		return nil, errors.New("the gRPC TLS certificate cannot be read from both files and Vault")
+	}
+	if c.VaultPresharedKeysPath != "" && len(c.PresharedSecureKey) > 0 {
+		return nil, errors.New("preshared keys cannot be given both by flag and from Vault")
+	}
+
+	secrets, err := vault.NewSecrets(ctx, vault.Config{
+		Address:             c.VaultAddress,
+		AuthMethod:          c.VaultAuthMethod,
+		AuthMount:           c.VaultAuthMount,
+		Token:               c.VaultToken,
+		Role:                c.VaultRole,
+		SecretID:            c.VaultSecretID,
+		KubernetesTokenPath: c.VaultKubernetesTokenPath,
+		TLSPath:             c.VaultTLSPath,
+		TLSCommonName:       c.VaultTLSCommonName,
+		PresharedKeysPath:   c.VaultPresharedKeysPath,
+		RefreshInterval:     c.VaultRefreshInterval,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets from Vault: %w", err)
+	}
+
+	if c.VaultTLSPath != "" {
+		c.GRPCServer.GetCertificate = secrets.GetCertificate
+	}
+	if c.VaultPresharedKeysPath != "" {
+		// The keys read at startup are used by in-process clients and for dispatch, while
+		// API requests are authenticated against the current keys.
+		c.PresharedSecureKey = secrets.PresharedKeys()
+	}
+	log.Ctx(ctx).Info().
+		Str("tls-path", c.VaultTLSPath).
+		Str("preshared-keys-path", c.VaultPresharedKeysPath).
+		Msg("reading secrets from Vault")
+	return secrets, nil
+}
+
+// auditSink returns the sink to which audit records of changes are emitted, or nil if the
+// audit log is disabled.
+func (c *Config) auditSink() (audit.Sink, error) {
+	switch c.AuditLogSink {
+	case "":
+		return nil, nil
+	case "stdout":
+		return audit.NewWriterSink(os.Stdout), nil
+	case "file":
+		if c.AuditLogFilePath == "" {
+			return nil, errors.New("an audit log file path is required for the file sink")
+		}
+		return audit.NewFileSink(c.AuditLogFilePath, int64(c.AuditLogFileMaxSizeMB)*1024*1024, c.AuditLogFileMaxBackups)
+	case "http":
+		if c.AuditLogHTTPEndpoint == "" {
+			return nil, errors.New("an audit log HTTP endpoint is required for the http sink")
+		}
+		return audit.NewHTTPSink(c.AuditLogHTTPEndpoint, c.AuditLogHTTPTimeout), nil
+	default:
+		return nil, fmt.Errorf("unknown audit log sink `%s`", c.AuditLogSink)
+	}
+}
+
+// decisionLogger returns the logger of the decisions of checks, or nil if decision logs are
+// disabled.
+func (c *Config) decisionLogger() (*decisionlog.Logger, error) {
+	var sink decisionlog.Sink
+	switch c.DecisionLogSink {
+	case "":
+		return nil, nil
+	case "stdout":
+		sink = decisionlog.NewWriterSink(os.Stdout)
+	case "http":
+		if c.DecisionLogHTTPEndpoint == "" {
+			return nil, errors.New("a decision log HTTP endpoint is required for the http sink")
+		}
+		var err error
+		sink, err = decisionlog.NewHTTPSink(c.DecisionLogHTTPEndpoint, c.DecisionLogUploadInterval, c.DecisionLogBatchSize, c.DecisionLogBufferSize, c.DecisionLogHTTPTimeout)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown decision log sink `%s`", c.DecisionLogSink)
+	}
+
+	id, _ := os.Hostname()
+	version, _ := releases.CurrentVersion()
+	return decisionlog.NewLogger(sink, map[string]string{"id": id, "version": version}), nil
+}
+
+// shadower returns the shadower mirroring checks to a candidate schema or another cluster, or
+// nil if shadow checks are disabled.
+func (c *Config) shadower(ctx context.Context) (*shadowcheck.Shadower, error) {
+	if c.ShadowCheckSchemaFile == "" && c.ShadowCheckEndpoint == "" {
+		return nil, nil
+	}
+	if c.ShadowCheckSchemaFile != "" && c.ShadowCheckEndpoint != "" {
+		return nil, errors.New("checks can be shadowed to either a schema file or an endpoint, not both")
+	}
+
+	var target shadowcheck.Target
+	if c.ShadowCheckSchemaFile != "" {
+		schema, err := os.ReadFile(c.ShadowCheckSchemaFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read shadow check schema file: %w", err)
+		}
+
+		// The candidate schema is evaluated by its own dispatcher, whose cached results cannot
+		// be mixed with those of the schema being served.
+		dispatcher := graph.NewLocalOnlyDispatcher(c.GlobalDispatchConcurrencyLimit)
+		target, err = shadowcheck.NewSchemaTarget(ctx, string(schema), dispatcher, c.DispatchMaxDepth)
+		if err != nil {
+			return nil, errors.Join(err, dispatcher.Close())
+		}
+	} else {
+		var opts []grpc.DialOption
+		if c.ShadowCheckInsecure {
+			opts = append(opts, grpc.WithTransportCredentials(insecure.NewCredentials()))
+			if c.ShadowCheckToken != "" {
+				opts = append(opts, grpcutil.WithInsecureBearerToken(c.ShadowCheckToken))
+			}
+		} else {
+			certs, err := shadowCheckCerts(c.ShadowCheckCAPath)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load shadow check CA certificate: %w", err)
+			}
+			opts = append(opts, certs)
+			if c.ShadowCheckToken != "" {
+				opts = append(opts, grpcutil.WithBearerToken(c.ShadowCheckToken))
+			}
+		}
+
+		conn, err := grpc.Dial(c.ShadowCheckEndpoint, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to shadow check endpoint: %w", err)
+		}
+		target = shadowcheck.NewClusterTarget(c.ShadowCheckEndpoint, v1.NewPermissionsServiceClient(conn), conn.Close, c.ShadowCheckExactSnapshot)
+	}
+
+	shadower, err := shadowcheck.NewShadower(target, c.ShadowCheckSampleRate, c.ShadowCheckMaxInFlight, c.ShadowCheckTimeout)
+	if err != nil {
+		return nil, errors.Join(err, target.Close())
+	}
+	return shadower, nil
+}
+
+// federationConfig returns the configuration of the clusters to which checks of remote
+// namespaces are delegated, or nil if no namespace is remote.
+func (c *Config) federationConfig() *federation.Config {
+	if len(c.FederationRemoteNamespaces) == 0 {
+		return nil
+	}
+	return &federation.Config{
+		RemoteNamespaces: c.FederationRemoteNamespaces,
+		Token:            c.FederationToken,
+		Insecure:         c.FederationInsecure,
+		CAPath:           c.FederationCAPath,
+		MaxStaleness:     c.FederationMaxStaleness,
+		Timeout:          c.FederationTimeout,
+	}
+}
+
+// federationCacheMaxStaleness returns the longest time for which cached dispatch results
+// are used, which is bounded only when checks are delegated to federated clusters.
+func (c *Config) federationCacheMaxStaleness() time.Duration {
+	if len(c.FederationRemoteNamespaces) == 0 {
+		return 0
+	}
+	return c.FederationMaxStaleness
+}
+
+// shadowCheckCerts returns the credentials verifying the shadow check endpoint against the CA
+// certificate at the path, or against the system's certificates if none is given.
+func shadowCheckCerts(caPath string) (grpc.DialOption, error) {
+	if caPath == "" {
+		return grpcutil.WithSystemCerts(grpcutil.VerifyCA)
+	}
+	return grpcutil.WithCustomCerts(grpcutil.VerifyCA, caPath)
+}
+
 func (c *Config) buildUnaryMiddleware(defaultMiddleware *MiddlewareChain[grpc.UnaryServerInterceptor]) ([]grpc.UnaryServerInterceptor, error) {
 	chain := MiddlewareChain[grpc.UnaryServerInterceptor]{}
 	if defaultMiddleware != nil {
@@ -500,7 +1703,7 @@ func (c *Config) buildStreamingMiddleware(defaultMiddleware *MiddlewareChain[grp
 // initializeGateway Configures the gateway to serve HTTP
 func (c *Config) initializeGateway(ctx context.Context) (util.RunnableHTTPServer, io.Closer, error) {
 	if len(c.HTTPGatewayUpstreamAddr) == 0 {
-		c.HTTPGatewayUpstreamAddr = c.GRPCServer.Address
+		c.HTTPGatewayUpstreamAddr = c.GRPCServer.DialTarget()
 	} else {
 		log.Ctx(ctx).Info().Str("upstream", c.HTTPGatewayUpstreamAddr).Msg("Overriding REST gateway upstream")
 	}
@@ -521,8 +1724,14 @@ func (c *Config) initializeGateway(ctx context.Context) (util.RunnableHTTPServer
 		return gatewayServer, nil, nil
 	}
 
+	// The gateway authenticates to a gRPC server requiring client certificates with its own.
+	clientCert, err := c.GRPCServer.ClientCertificate()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize rest gateway: %w", err)
+	}
+
 	var gatewayHandler http.Handler
-	closeableGatewayHandler, err := gateway.NewHandler(ctx, c.HTTPGatewayUpstreamAddr, c.HTTPGatewayUpstreamTLSCertPath)
+	closeableGatewayHandler, err := gateway.NewHandler(ctx, c.HTTPGatewayUpstreamAddr, c.HTTPGatewayUpstreamTLSCertPath, c.GRPCServer.TLSEnabled(), clientCert)
 	if err != nil {
 		return nil, nil, fmt.Errorf("failed to initialize rest gateway: %w", err)
 	}
@@ -549,6 +1758,51 @@ func (c *Config) initializeGateway(ctx context.Context) (util.RunnableHTTPServer
 	return gatewayServer, closeableGatewayHandler, nil
 }
 
+// initializeGRPCWeb configures the gRPC-Web server, which translates requests from browser
+// clients into calls against the gRPC server
+func (c *Config) initializeGRPCWeb(ctx context.Context) (util.RunnableHTTPServer, io.Closer, error) {
+	// If the requested network is a buffered one, then disable gRPC-Web.
+	if c.GRPCServer.Network == util.BufferedNetwork || !c.GRPCWeb.HTTPEnabled {
+		c.GRPCWeb.HTTPEnabled = false
+		grpcWebServer, err := c.GRPCWeb.Complete(zerolog.InfoLevel, nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed skipping grpc-web initialization: %w", err)
+		}
+		return grpcWebServer, nil, nil
+	}
+
+	clientCert, err := c.GRPCServer.ClientCertificate()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize grpc-web: %w", err)
+	}
+
+	var grpcWebHandler http.Handler
+	closeableGRPCWebHandler, err := grpcweb.NewHandler(ctx, c.GRPCServer.DialTarget(), c.GRPCServer.TLSCertPath, c.GRPCServer.TLSEnabled(), clientCert)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize grpc-web: %w", err)
+	}
+	grpcWebHandler = closeableGRPCWebHandler
+
+	if c.GRPCWebCorsEnabled {
+		log.Ctx(ctx).Info().Strs("origins", c.GRPCWebCorsAllowedOrigins).Msg("Setting grpc-web CORS policy")
+		grpcWebHandler = cors.New(cors.Options{
+			AllowedOrigins:   c.GRPCWebCorsAllowedOrigins,
+			AllowCredentials: true,
+			AllowedHeaders:   grpcweb.AllowedRequestHeaders,
+			ExposedHeaders:   grpcweb.ExposedResponseHeaders,
+			Debug:            log.Debug().Enabled(),
+		}).Handler(grpcWebHandler)
+	}
+
+	log.Ctx(ctx).Info().Str("upstream", c.GRPCServer.DialTarget()).Msg("starting grpc-web server")
+
+	grpcWebServer, err := c.GRPCWeb.Complete(zerolog.InfoLevel, grpcWebHandler)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to initialize grpc-web: %w", err)
+	}
+	return grpcWebServer, closeableGRPCWebHandler, nil
+}
+
 // RunnableServer is a spicedb service set ready to run
 type RunnableServer interface {
 	Run(ctx context.Context) error
@@ -564,15 +1818,31 @@ type completedServerConfig struct {
 
 	gRPCServer         util.RunnableGRPCServer
 	dispatchGRPCServer util.RunnableGRPCServer
-	gatewayServer      util.RunnableHTTPServer
-	metricsServer      util.RunnableHTTPServer
+	statsdExporter     *statsd.Exporter
+	changefeedExporter *changefeed.Exporter
+	backupScheduler    *backup.Scheduler
+	elector            *leaderelection.Elector
+	garbageCollector   common.GarbageCollector
+	gcInterval         time.Duration
+	gcWindow           time.Duration
+	gcTimeout          time.Duration
+	vaultSecrets       *vault.Secrets
+	configReloader     *configReloader
+	schemaSyncer       *schemadir.Syncer
 	telemetryReporter  telemetry.Reporter
 	healthManager      health.Manager
-
-	unaryMiddleware     []grpc.UnaryServerInterceptor
-	streamingMiddleware []grpc.StreamServerInterceptor
-	presharedKeys       []string
-	closeFunc           func() error
+	groupIndex         *groupindex.Index
+	materialized       *materialized.Sets
+	cacheWarmup        *warmup.Dispatcher
+	namespaceMetrics   *namespacemetrics.KnownNamespaces
+	namespaceInterval  time.Duration
+	namespaceQuotas    *namespacequota.Quotas
+	quotaInterval      time.Duration
+	usageAccountant    *usage.Accountant
+	usageInterval      time.Duration
+
+	presharedKeys []string
+	lifecycle     *lifecycle.Manager
 }
 
 func (c *completedServerConfig) GRPCDialContext(ctx context.Context, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
@@ -601,29 +1871,177 @@ func (c *completedServerConfig) Run(ctx context.Context) error {
 		}
 	}
 
-	g, ctx := errgroup.WithContext(ctx)
+	// Under systemd, readiness is reported once the health service is serving, which
+	// requires the datastore to be reachable and migrated.
+	notifier, err := sdnotify.NewNotifier()
+	if err != nil {
+		return err
+	}
 
-	stopOnCancelWithErr := func(stopFn func() error) func() error {
-		return func() error {
-			<-ctx.Done()
-			return stopFn()
+	// Subsystems are started in the order in which they are registered, and stopped in the
+	// reverse order: background jobs stop before the servers and the datastore upon which they
+	// depend, and the health service reports not serving before anything stops.
+	lc := c.lifecycle
+	lc.Add(lifecycle.Hook{Name: "systemd notifier", Start: func(ctx context.Context) error {
+		return notifier.Run(ctx, c.healthManager.IsServing)
+	}})
+	lc.Add(lifecycle.Hook{Name: "telemetry", Start: c.telemetryReporter})
+
+	if c.groupIndex != nil {
+		lc.Add(lifecycle.Hook{Name: "group index", Start: func(ctx context.Context) error { return c.groupIndex.Run(ctx, c.ds) }})
+	}
+
+	if c.materialized != nil {
+		lc.Add(lifecycle.Hook{Name: "materialized permissions", Start: func(ctx context.Context) error { return c.materialized.Run(ctx, c.ds) }})
+	}
+
+	if c.cacheWarmup != nil {
+		lc.Add(lifecycle.Hook{Name: "cache warm-up", Start: func(ctx context.Context) error { return c.cacheWarmup.Run(ctx, c.ds) }})
+	}
+
+	if c.namespaceMetrics != nil {
+		lc.Add(lifecycle.Hook{Name: "namespace metrics", Start: func(ctx context.Context) error {
+			return c.namespaceMetrics.Run(ctx, c.ds, c.namespaceInterval)
+		}})
+	}
+
+	if c.namespaceQuotas != nil {
+		lc.Add(lifecycle.Hook{Name: "namespace quotas", Start: func(ctx context.Context) error {
+			return c.namespaceQuotas.Run(ctx, c.ds, c.quotaInterval)
+		}})
+	}
+
+	if c.usageAccountant != nil && c.usageInterval > 0 {
+		lc.Add(lifecycle.Hook{Name: "usage accounting", Start: func(ctx context.Context) error {
+			return c.usageAccountant.Run(ctx, c.ds, c.usageInterval)
+		}})
+	}
+
+	if c.statsdExporter != nil {
+		lc.Add(lifecycle.Hook{Name: "statsd exporter", Start: c.statsdExporter.Run})
+	}
+
+	// Jobs which must run on a single node of the cluster only run on the leader, when leader
+	// election is enabled.
+	runSingleton := func(name string, job func(context.Context) error) func(context.Context) error {
+		if c.elector == nil {
+			return job
 		}
+		return func(ctx context.Context) error { return c.elector.RunWhileLeader(ctx, name, job) }
 	}
 
-	grpcServer := c.gRPCServer.WithOpts(grpc.ChainUnaryInterceptor(c.unaryMiddleware...), grpc.ChainStreamInterceptor(c.streamingMiddleware...))
-	g.Go(c.healthManager.Checker(ctx))
-	g.Go(grpcServer.Listen(ctx))
-	g.Go(c.dispatchGRPCServer.Listen(ctx))
-	g.Go(c.gatewayServer.ListenAndServe)
-	g.Go(c.metricsServer.ListenAndServe)
-	g.Go(func() error { return c.telemetryReporter(ctx) })
+	if c.elector != nil {
+		lc.Add(lifecycle.Hook{Name: "leader election", Start: func(ctx context.Context) error { return c.elector.Run(ctx, c.ds) }})
+	}
 
-	g.Go(stopOnCancelWithErr(c.closeFunc))
+	if c.garbageCollector != nil {
+		lc.Add(lifecycle.Hook{Name: "garbage collection", Start: runSingleton("garbage collection", func(ctx context.Context) error {
+			return common.StartGarbageCollector(ctx, c.garbageCollector, c.gcInterval, c.gcWindow, c.gcTimeout)
+		})})
+	}
 
-	if err := g.Wait(); err != nil {
+	if c.changefeedExporter != nil {
+		runChangefeed := runSingleton("changefeed", func(ctx context.Context) error {
+			return c.changefeedExporter.Run(ctx, c.ds)
+		})
+		lc.Add(lifecycle.Hook{Name: "changefeed", Start: func(ctx context.Context) error {
+			defer c.changefeedExporter.Close()
+			return runChangefeed(ctx)
+		}})
+	}
+
+	if c.backupScheduler != nil {
+		runBackups := runSingleton("scheduled backups", func(ctx context.Context) error {
+			return c.backupScheduler.Run(ctx, c.ds)
+		})
+		lc.Add(lifecycle.Hook{Name: "scheduled backups", Start: func(ctx context.Context) error {
+			defer c.backupScheduler.Close()
+			return runBackups(ctx)
+		}})
+	}
+
+	if c.vaultSecrets != nil {
+		lc.Add(lifecycle.Hook{Name: "vault secrets", Start: c.vaultSecrets.Run})
+	}
+
+	if c.configReloader != nil {
+		lc.Add(lifecycle.Hook{Name: "configuration reloader", Start: c.configReloader.Run})
+	}
+
+	if c.schemaSyncer != nil {
+		lc.Add(lifecycle.Hook{Name: "schema directory", Start: runSingleton("schema directory", c.schemaSyncer.Run)})
+	}
+
+	lc.Add(lifecycle.Hook{
+		Name: "health",
+		Start: func(ctx context.Context) error {
+			return c.healthManager.Checker(ctx)()
+		},
+		Stop: func(ctx context.Context) error {
+			log.Ctx(ctx).Info().Msg("marking services as not serving and draining requests")
+			c.healthManager.Shutdown()
+			return nil
+		},
+	})
+
+	if err := lc.Run(ctx); err != nil {
 		log.Ctx(ctx).Warn().Err(err).Msg("error shutting down server")
 		return err
 	}
 
 	return nil
 }
+
+// grpcServerHook returns the hook serving the gRPC server until it is stopped, which waits
+// for in-flight requests to complete for the shutdown grace period.
+func (c *Config) grpcServerHook(name string, srv util.RunnableGRPCServer) lifecycle.Hook {
+	// Without a grace period, in-flight requests are waited for indefinitely.
+	stopTimeout := time.Duration(-1)
+	if c.ShutdownGracePeriod > 0 && c.ShutdownStopTimeout > 0 {
+		stopTimeout = c.ShutdownGracePeriod + c.ShutdownStopTimeout
+	}
+
+	return lifecycle.Hook{
+		Name: name,
+		Start: func(ctx context.Context) error {
+			return srv.Listen(ctx)()
+		},
+		Stop: func(context.Context) error {
+			srv.StopWithGracePeriod(c.ShutdownGracePeriod)
+			return nil
+		},
+		StopTimeout: stopTimeout,
+	}
+}
+
+// httpServerHook returns the hook serving the HTTP server until it is stopped.
+func httpServerHook(name string, srv util.RunnableHTTPServer) lifecycle.Hook {
+	return lifecycle.Hook{
+		Name: name,
+		Start: func(context.Context) error {
+			return srv.ListenAndServe()
+		},
+		Stop: func(context.Context) error {
+			srv.Close()
+			return nil
+		},
+	}
+}
+
+// dispatchUpstreamAddr returns the address to which requests are dispatched. When a
+// Kubernetes service is given, the address is resolved by watching the service's endpoints,
+// so that the hashring tracks pods as they are added and removed.
+func dispatchUpstreamAddr(addr, kubernetesService string) (string, error) {
+	switch {
+	case kubernetesService == "":
+		return addr, nil
+	case addr != "":
+		return "", fmt.Errorf("only one of a dispatch upstream address or Kubernetes service can be provided")
+	case strings.Contains(kubernetesService, "/"):
+		return "", fmt.Errorf("invalid dispatch upstream Kubernetes service `%s`: expected `service.namespace:port`", kubernetesService)
+	case !strings.Contains(kubernetesService, ":"):
+		return "", fmt.Errorf("dispatch upstream Kubernetes service `%s` must include a port", kubernetesService)
+	default:
+		return "kubernetes:///" + kubernetesService, nil
+	}
+}
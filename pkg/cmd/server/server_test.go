@@ -4,21 +4,32 @@ import (
 	"context"
 	"errors"
 	"log"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"github.com/authzed/spicedb/internal/datastore/memdb"
 	"github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/middleware/deadline"
 	"github.com/authzed/spicedb/pkg/cmd/datastore"
 	"github.com/authzed/spicedb/pkg/cmd/util"
+	"github.com/authzed/spicedb/pkg/tuple"
 
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/authzed/grpcutil"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/sdk/trace"
 	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 	"go.uber.org/goleak"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	reflectionpb "google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
+	"google.golang.org/grpc/status"
 )
 
 func TestServerGracefulTermination(t *testing.T) {
@@ -159,6 +170,160 @@ func setupSpanRecorder() (*tracetest.SpanRecorder, func()) {
 	}
 }
 
+// startTestServer starts a server with a memdb datastore and the given options, returning a
+// connection to its API.
+func startTestServer(t *testing.T, opts ...ConfigOption) *grpc.ClientConn {
+	conn, _ := startTestRunnableServer(t, opts...)
+	return conn
+}
+
+// startTestRunnableServer is startTestServer, also returning the server.
+func startTestRunnableServer(t *testing.T, opts ...ConfigOption) (*grpc.ClientConn, RunnableServer) {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	ds, err := datastore.NewDatastore(ctx,
+		datastore.DefaultDatastoreConfig().ToOption(),
+		datastore.WithRequestHedgingEnabled(false),
+	)
+	require.NoError(t, err)
+
+	configOpts := append([]ConfigOption{
+		WithGRPCServer(util.GRPCServerConfig{
+			Network: util.BufferedNetwork,
+			Enabled: true,
+		}),
+		WithGRPCAuthFunc(func(ctx context.Context) (context.Context, error) {
+			return ctx, nil
+		}),
+		WithHTTPGateway(util.HTTPServerConfig{HTTPEnabled: false}),
+		WithMetricsAPI(util.HTTPServerConfig{HTTPEnabled: false}),
+		WithDispatchCacheConfig(CacheConfig{Enabled: false, Metrics: false}),
+		WithNamespaceCacheConfig(CacheConfig{Enabled: false, Metrics: false}),
+		WithClusterDispatchCacheConfig(CacheConfig{Enabled: false, Metrics: false}),
+		WithDatastore(ds),
+	}, opts...)
+
+	srv, err := NewConfigWithOptionsAndDefaults(configOpts...).Complete(ctx)
+	require.NoError(t, err)
+
+	conn, err := srv.GRPCDialContext(ctx, grpc.WithDefaultCallOptions(grpc.WaitForReady(true)))
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = srv.Run(ctx)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return conn, srv
+}
+
+func TestDisabledServices(t *testing.T) {
+	ctx := context.Background()
+	conn := startTestServer(t,
+		WithDisableSchemaWrites(true),
+		WithDisableWatchAPI(true),
+	)
+
+	schemaSrv := v1.NewSchemaServiceClient(conn)
+	_, err := schemaSrv.WriteSchema(ctx, &v1.WriteSchemaRequest{Schema: `definition user {}`})
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+
+	_, err = schemaSrv.ReadSchema(ctx, &v1.ReadSchemaRequest{})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	watchCli, err := v1.NewWatchServiceClient(conn).Watch(ctx, &v1.WatchRequest{})
+	require.NoError(t, err)
+	_, err = watchCli.Recv()
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+
+	// Reflection is disabled unless enabled.
+	reflectionCli, err := reflectionpb.NewServerReflectionClient(conn).ServerReflectionInfo(ctx)
+	require.NoError(t, err)
+	_, err = reflectionCli.Recv()
+	require.Equal(t, codes.Unimplemented, status.Code(err))
+}
+
+func TestDisableWrites(t *testing.T) {
+	ctx := context.Background()
+	conn := startTestServer(t, WithDisableWrites(true))
+
+	_, err := v1.NewSchemaServiceClient(conn).WriteSchema(ctx, &v1.WriteSchemaRequest{Schema: `definition user {}`})
+	require.Equal(t, codes.Unavailable, status.Code(err))
+	require.ErrorContains(t, err, "service read-only")
+
+	_, err = v1.NewPermissionsServiceClient(conn).WriteRelationships(ctx, &v1.WriteRelationshipsRequest{})
+	require.Equal(t, codes.Unavailable, status.Code(err))
+}
+
+func TestScopedPresharedKeys(t *testing.T) {
+	conn := startTestServer(t, WithScopedPresharedKeys(`{"name":"frontend","key":"frontendkey","methods":["ReadSchema"]}`))
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "bearer frontendkey")
+
+	_, err := v1.NewSchemaServiceClient(conn).WriteSchema(ctx, &v1.WriteSchemaRequest{Schema: `definition user {}`})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// Requests without a scoped key are authenticated as before.
+	_, err = v1.NewSchemaServiceClient(conn).WriteSchema(context.Background(), &v1.WriteSchemaRequest{Schema: `definition user {}`})
+	require.NoError(t, err)
+
+	_, err = v1.NewSchemaServiceClient(conn).ReadSchema(ctx, &v1.ReadSchemaRequest{})
+	require.NoError(t, err)
+}
+
+func TestInvalidScopedPresharedKeys(t *testing.T) {
+	c := ConfigWithOptions(&Config{}, WithScopedPresharedKeys(`{"name":"frontend","key":"secretkey","unknown":true}`))
+	_, err := c.scopedPresharedKeys()
+	require.ErrorContains(t, err, "invalid scoped preshared key #1")
+	require.NotContains(t, err.Error(), "secretkey")
+
+	c = ConfigWithOptions(&Config{}, WithScopedPresharedKeys(`{"key":"secretkey"}`))
+	_, err = c.scopedPresharedKeys()
+	require.ErrorContains(t, err, "must have a name")
+}
+
+func TestLeaderElection(t *testing.T) {
+	conn, srv := startTestRunnableServer(t,
+		WithLeaderElectionEnabled(true),
+		WithLeaderElectionNodeName("node"),
+		WithLeaderElectionLeaseDuration(150*time.Millisecond),
+	)
+
+	elector := srv.(*completedServerConfig).elector
+	require.NotNil(t, elector)
+	require.Eventually(t, elector.IsLeader, 5*time.Second, 10*time.Millisecond)
+
+	// The renewals of the lease are not returned by the Watch API.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	_, err := v1.NewSchemaServiceClient(conn).WriteSchema(ctx, &v1.WriteSchemaRequest{Schema: `definition user {}
+definition document {
+	relation viewer: user
+}`})
+	require.NoError(t, err)
+
+	watch, err := v1.NewWatchServiceClient(conn).Watch(ctx, &v1.WatchRequest{})
+	require.NoError(t, err)
+
+	// Wait for a few renewals before writing.
+	time.Sleep(200 * time.Millisecond)
+	_, err = v1.NewPermissionsServiceClient(conn).WriteRelationships(ctx, &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{tuple.UpdateToRelationshipUpdate(tuple.Touch(tuple.MustParse("document:doc#viewer@user:tom")))},
+	})
+	require.NoError(t, err)
+
+	resp, err := watch.Recv()
+	require.NoError(t, err)
+	require.Len(t, resp.Updates, 1)
+	require.Equal(t, "document", resp.Updates[0].Relationship.Resource.ObjectType)
+}
+
 func TestServerGracefulTerminationOnError(t *testing.T) {
 	defer goleak.VerifyNone(t, goleak.IgnoreCurrent())
 
@@ -176,6 +341,86 @@ func TestServerGracefulTerminationOnError(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestEnforceRevisionTokensRequiresGuarantees(t *testing.T) {
+	c := ConfigWithOptions(&Config{
+		GRPCServer: util.GRPCServerConfig{
+			Network: util.BufferedNetwork,
+		},
+		DatastoreConfig: datastore.Config{
+			Engine:          datastore.CockroachEngine,
+			OverlapStrategy: "insecure",
+		},
+	}, WithPresharedSecureKey("psk"), WithEnforceRevisionTokens(true))
+
+	_, err := c.Complete(context.Background())
+	require.ErrorContains(t, err, "cannot enforce revision tokens")
+}
+
+func TestRequestTimeouts(t *testing.T) {
+	c := Config{
+		DefaultRequestTimeouts: map[string]string{"read": "5s", "lookup": "1m"},
+		MaxRequestTimeouts:     map[string]string{"read": "30s", "write": "10s"},
+	}
+	timeouts, err := c.requestTimeouts()
+	require.NoError(t, err)
+	require.Equal(t, map[deadline.Class]deadline.Timeouts{
+		deadline.ClassRead:   {Default: 5 * time.Second, Max: 30 * time.Second},
+		deadline.ClassLookup: {Default: time.Minute},
+		deadline.ClassWrite:  {Max: 10 * time.Second},
+	}, timeouts)
+
+	c = Config{DefaultRequestTimeouts: map[string]string{"export": "5s"}}
+	_, err = c.requestTimeouts()
+	require.ErrorContains(t, err, "unknown API method class `export`")
+
+	c = Config{MaxRequestTimeouts: map[string]string{"write": "soon"}}
+	_, err = c.requestTimeouts()
+	require.ErrorContains(t, err, "invalid timeout for `write`")
+}
+
+func TestFaultInjector(t *testing.T) {
+	c := Config{}
+	injector, err := c.faultInjector()
+	require.NoError(t, err)
+	require.Nil(t, injector)
+
+	c = Config{FaultInjectionFaults: []string{`{"method": "*", "unavailable_probability": 0.1}`}}
+	_, err = c.faultInjector()
+	require.ErrorContains(t, err, "explicitly enabled")
+
+	c = Config{
+		FaultInjectionEnabled: true,
+		FaultInjectionFaults: []string{
+			`{"method": "*", "unavailable_probability": 0.1}`,
+			`{"method": "CheckPermission", "latency": "200ms", "latency_probability": 0.5}`,
+		},
+	}
+	injector, err = c.faultInjector()
+	require.NoError(t, err)
+	require.NotNil(t, injector)
+
+	for faults, expectedError := range map[string]string{
+		`{"method": "*", "unavailable": 0.1}`:                            "unknown field",
+		`{"unavailable_probability": 0.1}`:                               "a method",
+		`{"method": "*", "latency": "soon", "latency_probability": 0.1}`: "invalid fault injection latency",
+		`{"method": "*", "unavailable_probability": 1.5}`:                "between 0 and 1",
+		`{"method": "*", "latency_probability": 0.1}`:                    "latency must be set",
+		`{"method": "*", "stream_reset_probability": -0.1}`:              "between 0 and 1",
+	} {
+		c = Config{FaultInjectionEnabled: true, FaultInjectionFaults: []string{faults}}
+		_, err = c.faultInjector()
+		require.ErrorContains(t, err, expectedError, faults)
+	}
+
+	c = Config{FaultInjectionEnabled: true}
+	_, err = c.faultInjector()
+	require.ErrorContains(t, err, "no faults")
+
+	c = Config{FaultInjectionEnabled: true, FaultInjectionFaults: []string{`{"method": "*"}`, `{"method": "*"}`}}
+	_, err = c.faultInjector()
+	require.ErrorContains(t, err, "duplicate fault injection for method `*`")
+}
+
 func TestReplaceUnaryMiddleware(t *testing.T) {
 	c := Config{UnaryMiddlewareModification: []MiddlewareModification[grpc.UnaryServerInterceptor]{
 		{
@@ -230,7 +475,7 @@ func TestModifyUnaryMiddleware(t *testing.T) {
 		},
 	}}
 
-	opt := MiddlewareOption{logging.Logger, nil, false, nil, nil, false, false}
+	opt := MiddlewareOption{logging.Logger, nil, false, nil, nil, false, false, nil, nil, nil, nil, nil, nil, nil, nil, false, nil, nil, nil, nil, nil}
 	defaultMw, err := DefaultUnaryMiddleware(opt)
 	require.NoError(t, err)
 
@@ -238,7 +483,7 @@ func TestModifyUnaryMiddleware(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, unary, len(defaultMw.chain)+1)
 
-	val, _ := unary[1](context.Background(), nil, nil, nil)
+	val, _ := unary[2](context.Background(), nil, nil, nil)
 	require.Equal(t, 1, val)
 }
 
@@ -256,7 +501,7 @@ func TestModifyStreamingMiddleware(t *testing.T) {
 		},
 	}}
 
-	opt := MiddlewareOption{logging.Logger, nil, false, nil, nil, false, false}
+	opt := MiddlewareOption{logging.Logger, nil, false, nil, nil, false, false, nil, nil, nil, nil, nil, nil, nil, nil, false, nil, nil, nil, nil, nil}
 	defaultMw, err := DefaultStreamingMiddleware(opt)
 	require.NoError(t, err)
 
@@ -264,6 +509,108 @@ func TestModifyStreamingMiddleware(t *testing.T) {
 	require.NoError(t, err)
 	require.Len(t, streaming, len(defaultMw.chain)+1)
 
-	err = streaming[1](context.Background(), nil, nil, nil)
+	err = streaming[2](context.Background(), nil, nil, nil)
 	require.ErrorContains(t, err, "hi")
 }
+
+func TestDispatchUpstreamAddr(t *testing.T) {
+	addr, err := dispatchUpstreamAddr("localhost:50053", "")
+	require.NoError(t, err)
+	require.Equal(t, "localhost:50053", addr)
+
+	addr, err = dispatchUpstreamAddr("", "spicedb.default:dispatch")
+	require.NoError(t, err)
+	require.Equal(t, "kubernetes:///spicedb.default:dispatch", addr)
+
+	_, err = dispatchUpstreamAddr("localhost:50053", "spicedb.default:dispatch")
+	require.ErrorContains(t, err, "only one of")
+
+	_, err = dispatchUpstreamAddr("", "spicedb.default")
+	require.ErrorContains(t, err, "must include a port")
+
+	_, err = dispatchUpstreamAddr("", "kubernetes:///spicedb.default:dispatch")
+	require.ErrorContains(t, err, "invalid dispatch upstream Kubernetes service")
+}
+
+func TestConfigureGRPCMetricsLatencyBuckets(t *testing.T) {
+	defer ConfigureGRPCMetricsLatencyBuckets(DefaultGRPCMetricsLatencyBuckets)
+
+	ConfigureGRPCMetricsLatencyBuckets([]float64{0.5, 2})
+	_, err := GRPCMetricsUnaryInterceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/some.Service/Method"}, func(ctx context.Context, req any) (any, error) {
+		return nil, nil
+	})
+	require.NoError(t, err)
+
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+
+	var buckets []float64
+	for _, family := range families {
+		if family.GetName() != "grpc_server_handling_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, bucket := range metric.GetHistogram().GetBucket() {
+				buckets = append(buckets, bucket.GetUpperBound())
+			}
+		}
+	}
+	require.Equal(t, []float64{0.5, 2}, buckets)
+}
+
+func TestMetricsHandlerHealth(t *testing.T) {
+	healthSvc := grpcutil.NewAuthlessHealthServer()
+	healthSvc.SetServingStatus("", healthpb.HealthCheckResponse_NOT_SERVING)
+	healthSvc.SetServingStatus("someservice", healthpb.HealthCheckResponse_SERVING)
+	handler := MetricsHandler(nil, nil, healthSvc)
+
+	get := func(path string) (int, string) {
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, nil))
+		return recorder.Code, recorder.Body.String()
+	}
+
+	code, _ := get("/healthz")
+	require.Equal(t, http.StatusOK, code)
+
+	code, body := get("/readyz")
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, "not_serving", body)
+
+	code, body = get("/readyz?service=someservice")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "serving", body)
+
+	code, _ = get("/readyz?service=unknown")
+	require.Equal(t, http.StatusNotFound, code)
+
+	healthSvc.SetServingStatus("", healthpb.HealthCheckResponse_SERVING)
+	code, _ = get("/readyz")
+	require.Equal(t, http.StatusOK, code)
+}
+
+func TestDebugHandler(t *testing.T) {
+	handler := DebugHandler(&Config{}, func() []string { return []string{"somekey"} }, nil, nil, nil)
+
+	get := func(remoteAddr, token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil)
+		req.RemoteAddr = remoteAddr
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, req)
+		return recorder.Code
+	}
+
+	require.Equal(t, http.StatusOK, get("127.0.0.1:1234", ""))
+	require.Equal(t, http.StatusOK, get("[::1]:1234", ""))
+	require.Equal(t, http.StatusUnauthorized, get("10.0.0.1:1234", ""))
+	require.Equal(t, http.StatusUnauthorized, get("10.0.0.1:1234", "wrongkey"))
+	require.Equal(t, http.StatusOK, get("10.0.0.1:1234", "somekey"))
+
+	// The metrics endpoint no longer serves the debug endpoints.
+	recorder := httptest.NewRecorder()
+	MetricsHandler(nil, nil, nil).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/debug/pprof/", nil))
+	require.Equal(t, http.StatusNotFound, recorder.Code)
+}
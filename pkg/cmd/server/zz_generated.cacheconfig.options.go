@@ -4,6 +4,7 @@ package server
 import (
 	defaults "github.com/creasty/defaults"
 	helpers "github.com/ecordell/optgen/helpers"
+	"time"
 )
 
 type CacheConfigOption func(c *CacheConfig)
@@ -35,6 +36,7 @@ func (c *CacheConfig) ToOption() CacheConfigOption {
 		to.NumCounters = c.NumCounters
 		to.Metrics = c.Metrics
 		to.Enabled = c.Enabled
+		to.TTL = c.TTL
 		to.defaultTTL = c.defaultTTL
 	}
 }
@@ -47,6 +49,7 @@ func (c CacheConfig) DebugMap() map[string]any {
 	debugMap["NumCounters"] = helpers.DebugValue(c.NumCounters, false)
 	debugMap["Metrics"] = helpers.DebugValue(c.Metrics, false)
 	debugMap["Enabled"] = helpers.DebugValue(c.Enabled, false)
+	debugMap["TTL"] = helpers.DebugValue(c.TTL, false)
 	return debugMap
 }
 
@@ -100,3 +103,10 @@ func WithEnabled(enabled bool) CacheConfigOption {
 		c.Enabled = enabled
 	}
 }
+
+// WithTTL returns an option that can set TTL on a CacheConfig
+func WithTTL(tTL time.Duration) CacheConfigOption {
+	return func(c *CacheConfig) {
+		c.TTL = tTL
+	}
+}
@@ -41,13 +41,24 @@ func (c *Config) ToOption() ConfigOption {
 		to.GRPCServer = c.GRPCServer
 		to.GRPCAuthFunc = c.GRPCAuthFunc
 		to.PresharedSecureKey = c.PresharedSecureKey
+		to.ScopedPresharedKeys = c.ScopedPresharedKeys
 		to.ShutdownGracePeriod = c.ShutdownGracePeriod
+		to.ShutdownStopTimeout = c.ShutdownStopTimeout
 		to.DisableVersionResponse = c.DisableVersionResponse
+		to.JWTIssuer = c.JWTIssuer
+		to.JWTJWKSURL = c.JWTJWKSURL
+		to.JWTAudience = c.JWTAudience
+		to.TenancyEnabled = c.TenancyEnabled
+		to.ZedTokenSigningKeys = c.ZedTokenSigningKeys
+		to.ZedTokenAllowUnsigned = c.ZedTokenAllowUnsigned
 		to.HTTPGateway = c.HTTPGateway
 		to.HTTPGatewayUpstreamAddr = c.HTTPGatewayUpstreamAddr
 		to.HTTPGatewayUpstreamTLSCertPath = c.HTTPGatewayUpstreamTLSCertPath
 		to.HTTPGatewayCorsEnabled = c.HTTPGatewayCorsEnabled
 		to.HTTPGatewayCorsAllowedOrigins = c.HTTPGatewayCorsAllowedOrigins
+		to.GRPCWeb = c.GRPCWeb
+		to.GRPCWebCorsEnabled = c.GRPCWebCorsEnabled
+		to.GRPCWebCorsAllowedOrigins = c.GRPCWebCorsAllowedOrigins
 		to.DatastoreConfig = c.DatastoreConfig
 		to.Datastore = c.Datastore
 		to.MaxCaveatContextSize = c.MaxCaveatContextSize
@@ -58,11 +69,26 @@ func (c *Config) ToOption() ConfigOption {
 		to.SchemaPrefixesRequired = c.SchemaPrefixesRequired
 		to.DispatchServer = c.DispatchServer
 		to.DispatchMaxDepth = c.DispatchMaxDepth
+		to.DispatchCheckMaxRelationships = c.DispatchCheckMaxRelationships
+		to.DispatchCheckMaxSubproblems = c.DispatchCheckMaxSubproblems
 		to.GlobalDispatchConcurrencyLimit = c.GlobalDispatchConcurrencyLimit
 		to.DispatchConcurrencyLimits = c.DispatchConcurrencyLimits
 		to.DispatchUpstreamAddr = c.DispatchUpstreamAddr
+		to.DispatchUpstreamKubernetesService = c.DispatchUpstreamKubernetesService
+		to.DispatchUpstreamDiscovery = c.DispatchUpstreamDiscovery
+		to.DispatchUpstreamDiscoveryTarget = c.DispatchUpstreamDiscoveryTarget
+		to.DispatchUpstreamDiscoveryEndpoint = c.DispatchUpstreamDiscoveryEndpoint
+		to.DispatchUpstreamDiscoveryInterval = c.DispatchUpstreamDiscoveryInterval
 		to.DispatchUpstreamCAPath = c.DispatchUpstreamCAPath
+		to.DispatchUpstreamTLSCertPath = c.DispatchUpstreamTLSCertPath
+		to.DispatchUpstreamTLSKeyPath = c.DispatchUpstreamTLSKeyPath
+		to.DispatchPresharedKey = c.DispatchPresharedKey
 		to.DispatchUpstreamTimeout = c.DispatchUpstreamTimeout
+		to.DispatchLocalFallbackEnabled = c.DispatchLocalFallbackEnabled
+		to.DispatchHedgingEnabled = c.DispatchHedgingEnabled
+		to.DispatchHedgingInitialSlowValue = c.DispatchHedgingInitialSlowValue
+		to.DispatchHedgingMaxRequests = c.DispatchHedgingMaxRequests
+		to.DispatchHedgingQuantile = c.DispatchHedgingQuantile
 		to.DispatchClientMetricsEnabled = c.DispatchClientMetricsEnabled
 		to.DispatchClientMetricsPrefix = c.DispatchClientMetricsPrefix
 		to.DispatchClusterMetricsEnabled = c.DispatchClusterMetricsEnabled
@@ -70,18 +96,88 @@ func (c *Config) ToOption() ConfigOption {
 		to.Dispatcher = c.Dispatcher
 		to.DispatchHashringReplicationFactor = c.DispatchHashringReplicationFactor
 		to.DispatchHashringSpread = c.DispatchHashringSpread
+		to.DispatchHashringLoadFactor = c.DispatchHashringLoadFactor
+		to.DispatchHashringDualDispatchWindow = c.DispatchHashringDualDispatchWindow
 		to.DispatchSecondaryUpstreamAddrs = c.DispatchSecondaryUpstreamAddrs
 		to.DispatchSecondaryUpstreamExprs = c.DispatchSecondaryUpstreamExprs
+		to.GroupIndexRelations = c.GroupIndexRelations
+		to.GroupIndexMaxStaleness = c.GroupIndexMaxStaleness
+		to.MaterializedPermissions = c.MaterializedPermissions
+		to.MaterializedPermissionsMaxStaleness = c.MaterializedPermissionsMaxStaleness
 		to.DispatchCacheConfig = c.DispatchCacheConfig
 		to.ClusterDispatchCacheConfig = c.ClusterDispatchCacheConfig
+		to.DispatchDeniedCacheConfig = c.DispatchDeniedCacheConfig
+		to.ClusterDispatchDeniedCacheConfig = c.ClusterDispatchDeniedCacheConfig
+		to.MaxCacheMemory = c.MaxCacheMemory
+		to.CacheWarmupEnabled = c.CacheWarmupEnabled
+		to.CacheWarmupFile = c.CacheWarmupFile
+		to.CacheWarmupMaxSubproblems = c.CacheWarmupMaxSubproblems
+		to.CacheWarmupSampleRate = c.CacheWarmupSampleRate
+		to.CacheWarmupTimeout = c.CacheWarmupTimeout
 		to.DisableV1SchemaAPI = c.DisableV1SchemaAPI
+		to.DisableSchemaWrites = c.DisableSchemaWrites
+		to.SchemaDirectory = c.SchemaDirectory
+		to.DisableWatchAPI = c.DisableWatchAPI
+		to.EnableReflection = c.EnableReflection
+		to.DisableReflection = c.DisableReflection
+		to.DisableWrites = c.DisableWrites
 		to.V1SchemaAdditiveOnly = c.V1SchemaAdditiveOnly
 		to.MaximumUpdatesPerWrite = c.MaximumUpdatesPerWrite
 		to.MaximumPreconditionCount = c.MaximumPreconditionCount
 		to.MaxDatastoreReadPageSize = c.MaxDatastoreReadPageSize
+		to.MaxReadRelationshipsLimit = c.MaxReadRelationshipsLimit
+		to.DefaultReadRelationshipsLimit = c.DefaultReadRelationshipsLimit
+		to.MaxLookupResourcesLimit = c.MaxLookupResourcesLimit
+		to.DefaultLookupResourcesLimit = c.DefaultLookupResourcesLimit
+		to.DefaultExportBatchSize = c.DefaultExportBatchSize
+		to.MaxExportBatchSize = c.MaxExportBatchSize
+		to.WriteBatchWindow = c.WriteBatchWindow
+		to.CheckSessionIdleTimeout = c.CheckSessionIdleTimeout
 		to.StreamingAPITimeout = c.StreamingAPITimeout
 		to.WatchHeartbeat = c.WatchHeartbeat
+		to.WatchBufferLength = c.WatchBufferLength
+		to.WatchOverflow = c.WatchOverflow
+		to.SlowRequestThreshold = c.SlowRequestThreshold
+		to.DefaultRequestTimeouts = c.DefaultRequestTimeouts
+		to.MaxRequestTimeouts = c.MaxRequestTimeouts
+		to.FaultInjectionEnabled = c.FaultInjectionEnabled
+		to.FaultInjectionFaults = c.FaultInjectionFaults
+		to.EnforceRevisionTokens = c.EnforceRevisionTokens
+		to.RevisionTokenTimeout = c.RevisionTokenTimeout
+		to.AdaptiveConsistencyMaxStaleness = c.AdaptiveConsistencyMaxStaleness
+		to.PermissionMetricsMaxCardinality = c.PermissionMetricsMaxCardinality
+		to.NamespaceMetricsEnabled = c.NamespaceMetricsEnabled
+		to.NamespaceMetricsRefreshInterval = c.NamespaceMetricsRefreshInterval
+		to.NamespaceMaxRelationships = c.NamespaceMaxRelationships
+		to.NamespaceMaxWriteRate = c.NamespaceMaxWriteRate
+		to.NamespaceMaxLookupResults = c.NamespaceMaxLookupResults
+		to.NamespaceQuotaRefreshInterval = c.NamespaceQuotaRefreshInterval
+		to.WriteAdmissionPolicies = c.WriteAdmissionPolicies
+		to.UsageAccountingEnabled = c.UsageAccountingEnabled
+		to.UsageMonthlyRequestQuota = c.UsageMonthlyRequestQuota
+		to.UsageMonthlyMutationQuota = c.UsageMonthlyMutationQuota
+		to.UsageStoredRelationshipsRefreshInterval = c.UsageStoredRelationshipsRefreshInterval
 		to.MetricsAPI = c.MetricsAPI
+		to.DebugAPI = c.DebugAPI
+		to.DebugLogOverrideMaxDuration = c.DebugLogOverrideMaxDuration
+		to.GRPCMetricsLatencyBuckets = c.GRPCMetricsLatencyBuckets
+		to.StatsDAddr = c.StatsDAddr
+		to.StatsDPrefix = c.StatsDPrefix
+		to.StatsDTags = c.StatsDTags
+		to.StatsDInterval = c.StatsDInterval
+		to.ChangefeedKafkaBrokers = c.ChangefeedKafkaBrokers
+		to.ChangefeedKafkaTopic = c.ChangefeedKafkaTopic
+		to.ChangefeedName = c.ChangefeedName
+		to.ChangefeedCheckpointInterval = c.ChangefeedCheckpointInterval
+		to.BackupBucketURL = c.BackupBucketURL
+		to.BackupInterval = c.BackupInterval
+		to.BackupRetention = c.BackupRetention
+		to.BackupEncryptionKeyFile = c.BackupEncryptionKeyFile
+		to.AccessReportBucketURL = c.AccessReportBucketURL
+		to.AccessReportMaxRunning = c.AccessReportMaxRunning
+		to.LeaderElectionEnabled = c.LeaderElectionEnabled
+		to.LeaderElectionNodeName = c.LeaderElectionNodeName
+		to.LeaderElectionLeaseDuration = c.LeaderElectionLeaseDuration
 		to.UnaryMiddlewareModification = c.UnaryMiddlewareModification
 		to.StreamingMiddlewareModification = c.StreamingMiddlewareModification
 		to.DispatchUnaryMiddleware = c.DispatchUnaryMiddleware
@@ -92,6 +188,62 @@ func (c *Config) ToOption() ConfigOption {
 		to.TelemetryInterval = c.TelemetryInterval
 		to.EnableRequestLogs = c.EnableRequestLogs
 		to.EnableResponseLogs = c.EnableResponseLogs
+		to.LogSampleRates = c.LogSampleRates
+		to.RateLimitReadsPerSecond = c.RateLimitReadsPerSecond
+		to.RateLimitReadBurst = c.RateLimitReadBurst
+		to.RateLimitWritesPerSecond = c.RateLimitWritesPerSecond
+		to.RateLimitWriteBurst = c.RateLimitWriteBurst
+		to.MaxInFlightRequests = c.MaxInFlightRequests
+		to.MaxInFlightLowPriorityPercent = c.MaxInFlightLowPriorityPercent
+		to.MaxInFlightRetryAfter = c.MaxInFlightRetryAfter
+		to.AuditLogSink = c.AuditLogSink
+		to.AuditLogFilePath = c.AuditLogFilePath
+		to.AuditLogFileMaxSizeMB = c.AuditLogFileMaxSizeMB
+		to.AuditLogFileMaxBackups = c.AuditLogFileMaxBackups
+		to.AuditLogHTTPEndpoint = c.AuditLogHTTPEndpoint
+		to.AuditLogHTTPTimeout = c.AuditLogHTTPTimeout
+		to.DecisionLogSink = c.DecisionLogSink
+		to.DecisionLogHTTPEndpoint = c.DecisionLogHTTPEndpoint
+		to.DecisionLogUploadInterval = c.DecisionLogUploadInterval
+		to.DecisionLogBatchSize = c.DecisionLogBatchSize
+		to.DecisionLogBufferSize = c.DecisionLogBufferSize
+		to.DecisionLogHTTPTimeout = c.DecisionLogHTTPTimeout
+		to.ShadowCheckSampleRate = c.ShadowCheckSampleRate
+		to.ShadowCheckSchemaFile = c.ShadowCheckSchemaFile
+		to.ShadowCheckEndpoint = c.ShadowCheckEndpoint
+		to.ShadowCheckToken = c.ShadowCheckToken
+		to.ShadowCheckInsecure = c.ShadowCheckInsecure
+		to.ShadowCheckCAPath = c.ShadowCheckCAPath
+		to.ShadowCheckExactSnapshot = c.ShadowCheckExactSnapshot
+		to.ShadowCheckMaxInFlight = c.ShadowCheckMaxInFlight
+		to.ShadowCheckTimeout = c.ShadowCheckTimeout
+		to.FederationRemoteNamespaces = c.FederationRemoteNamespaces
+		to.FederationToken = c.FederationToken
+		to.FederationInsecure = c.FederationInsecure
+		to.FederationCAPath = c.FederationCAPath
+		to.FederationMaxStaleness = c.FederationMaxStaleness
+		to.FederationTimeout = c.FederationTimeout
+		to.RelationshipTransforms = c.RelationshipTransforms
+		to.SchemaWebhookURLs = c.SchemaWebhookURLs
+		to.SchemaWebhookSecret = c.SchemaWebhookSecret
+		to.SchemaWebhookTimeout = c.SchemaWebhookTimeout
+		to.SchemaWebhookMaxAttempts = c.SchemaWebhookMaxAttempts
+		to.ExtAuthzRulesetPath = c.ExtAuthzRulesetPath
+		to.VaultAddress = c.VaultAddress
+		to.VaultAuthMethod = c.VaultAuthMethod
+		to.VaultAuthMount = c.VaultAuthMount
+		to.VaultToken = c.VaultToken
+		to.VaultRole = c.VaultRole
+		to.VaultSecretID = c.VaultSecretID
+		to.VaultKubernetesTokenPath = c.VaultKubernetesTokenPath
+		to.VaultTLSPath = c.VaultTLSPath
+		to.VaultTLSCommonName = c.VaultTLSCommonName
+		to.VaultPresharedKeysPath = c.VaultPresharedKeysPath
+		to.VaultRefreshInterval = c.VaultRefreshInterval
+		to.ErrorReportingDSN = c.ErrorReportingDSN
+		to.ErrorReportingEnvironment = c.ErrorReportingEnvironment
+		to.ErrorReportingSampleRate = c.ErrorReportingSampleRate
+		to.ReloadConfigPath = c.ReloadConfigPath
 	}
 }
 
@@ -101,13 +253,24 @@ func (c Config) DebugMap() map[string]any {
 	debugMap["GRPCServer"] = helpers.DebugValue(c.GRPCServer, false)
 	debugMap["GRPCAuthFunc"] = helpers.DebugValue(c.GRPCAuthFunc, false)
 	debugMap["PresharedSecureKey"] = helpers.SensitiveDebugValue(c.PresharedSecureKey)
+	debugMap["ScopedPresharedKeys"] = helpers.SensitiveDebugValue(c.ScopedPresharedKeys)
 	debugMap["ShutdownGracePeriod"] = helpers.DebugValue(c.ShutdownGracePeriod, false)
+	debugMap["ShutdownStopTimeout"] = helpers.DebugValue(c.ShutdownStopTimeout, false)
 	debugMap["DisableVersionResponse"] = helpers.DebugValue(c.DisableVersionResponse, false)
+	debugMap["JWTIssuer"] = helpers.DebugValue(c.JWTIssuer, false)
+	debugMap["JWTJWKSURL"] = helpers.DebugValue(c.JWTJWKSURL, false)
+	debugMap["JWTAudience"] = helpers.DebugValue(c.JWTAudience, false)
+	debugMap["TenancyEnabled"] = helpers.DebugValue(c.TenancyEnabled, false)
+	debugMap["ZedTokenSigningKeys"] = helpers.SensitiveDebugValue(c.ZedTokenSigningKeys)
+	debugMap["ZedTokenAllowUnsigned"] = helpers.DebugValue(c.ZedTokenAllowUnsigned, false)
 	debugMap["HTTPGateway"] = helpers.DebugValue(c.HTTPGateway, false)
 	debugMap["HTTPGatewayUpstreamAddr"] = helpers.DebugValue(c.HTTPGatewayUpstreamAddr, false)
 	debugMap["HTTPGatewayUpstreamTLSCertPath"] = helpers.DebugValue(c.HTTPGatewayUpstreamTLSCertPath, false)
 	debugMap["HTTPGatewayCorsEnabled"] = helpers.DebugValue(c.HTTPGatewayCorsEnabled, false)
 	debugMap["HTTPGatewayCorsAllowedOrigins"] = helpers.DebugValue(c.HTTPGatewayCorsAllowedOrigins, true)
+	debugMap["GRPCWeb"] = helpers.DebugValue(c.GRPCWeb, false)
+	debugMap["GRPCWebCorsEnabled"] = helpers.DebugValue(c.GRPCWebCorsEnabled, false)
+	debugMap["GRPCWebCorsAllowedOrigins"] = helpers.DebugValue(c.GRPCWebCorsAllowedOrigins, true)
 	debugMap["DatastoreConfig"] = helpers.DebugValue(c.DatastoreConfig, false)
 	debugMap["Datastore"] = helpers.DebugValue(c.Datastore, false)
 	debugMap["MaxCaveatContextSize"] = helpers.DebugValue(c.MaxCaveatContextSize, false)
@@ -118,11 +281,26 @@ func (c Config) DebugMap() map[string]any {
 	debugMap["SchemaPrefixesRequired"] = helpers.DebugValue(c.SchemaPrefixesRequired, false)
 	debugMap["DispatchServer"] = helpers.DebugValue(c.DispatchServer, false)
 	debugMap["DispatchMaxDepth"] = helpers.DebugValue(c.DispatchMaxDepth, false)
+	debugMap["DispatchCheckMaxRelationships"] = helpers.DebugValue(c.DispatchCheckMaxRelationships, false)
+	debugMap["DispatchCheckMaxSubproblems"] = helpers.DebugValue(c.DispatchCheckMaxSubproblems, false)
 	debugMap["GlobalDispatchConcurrencyLimit"] = helpers.DebugValue(c.GlobalDispatchConcurrencyLimit, false)
 	debugMap["DispatchConcurrencyLimits"] = helpers.DebugValue(c.DispatchConcurrencyLimits, false)
 	debugMap["DispatchUpstreamAddr"] = helpers.DebugValue(c.DispatchUpstreamAddr, false)
+	debugMap["DispatchUpstreamKubernetesService"] = helpers.DebugValue(c.DispatchUpstreamKubernetesService, false)
+	debugMap["DispatchUpstreamDiscovery"] = helpers.DebugValue(c.DispatchUpstreamDiscovery, false)
+	debugMap["DispatchUpstreamDiscoveryTarget"] = helpers.DebugValue(c.DispatchUpstreamDiscoveryTarget, false)
+	debugMap["DispatchUpstreamDiscoveryEndpoint"] = helpers.DebugValue(c.DispatchUpstreamDiscoveryEndpoint, false)
+	debugMap["DispatchUpstreamDiscoveryInterval"] = helpers.DebugValue(c.DispatchUpstreamDiscoveryInterval, false)
 	debugMap["DispatchUpstreamCAPath"] = helpers.DebugValue(c.DispatchUpstreamCAPath, false)
+	debugMap["DispatchUpstreamTLSCertPath"] = helpers.DebugValue(c.DispatchUpstreamTLSCertPath, false)
+	debugMap["DispatchUpstreamTLSKeyPath"] = helpers.DebugValue(c.DispatchUpstreamTLSKeyPath, false)
+	debugMap["DispatchPresharedKey"] = helpers.SensitiveDebugValue(c.DispatchPresharedKey)
 	debugMap["DispatchUpstreamTimeout"] = helpers.DebugValue(c.DispatchUpstreamTimeout, false)
+	debugMap["DispatchLocalFallbackEnabled"] = helpers.DebugValue(c.DispatchLocalFallbackEnabled, false)
+	debugMap["DispatchHedgingEnabled"] = helpers.DebugValue(c.DispatchHedgingEnabled, false)
+	debugMap["DispatchHedgingInitialSlowValue"] = helpers.DebugValue(c.DispatchHedgingInitialSlowValue, false)
+	debugMap["DispatchHedgingMaxRequests"] = helpers.DebugValue(c.DispatchHedgingMaxRequests, false)
+	debugMap["DispatchHedgingQuantile"] = helpers.DebugValue(c.DispatchHedgingQuantile, false)
 	debugMap["DispatchClientMetricsEnabled"] = helpers.DebugValue(c.DispatchClientMetricsEnabled, false)
 	debugMap["DispatchClientMetricsPrefix"] = helpers.DebugValue(c.DispatchClientMetricsPrefix, false)
 	debugMap["DispatchClusterMetricsEnabled"] = helpers.DebugValue(c.DispatchClusterMetricsEnabled, false)
@@ -130,24 +308,150 @@ func (c Config) DebugMap() map[string]any {
 	debugMap["Dispatcher"] = helpers.DebugValue(c.Dispatcher, false)
 	debugMap["DispatchHashringReplicationFactor"] = helpers.DebugValue(c.DispatchHashringReplicationFactor, false)
 	debugMap["DispatchHashringSpread"] = helpers.DebugValue(c.DispatchHashringSpread, false)
+	debugMap["DispatchHashringLoadFactor"] = helpers.DebugValue(c.DispatchHashringLoadFactor, false)
+	debugMap["DispatchHashringDualDispatchWindow"] = helpers.DebugValue(c.DispatchHashringDualDispatchWindow, false)
 	debugMap["DispatchSecondaryUpstreamAddrs"] = helpers.DebugValue(c.DispatchSecondaryUpstreamAddrs, false)
 	debugMap["DispatchSecondaryUpstreamExprs"] = helpers.DebugValue(c.DispatchSecondaryUpstreamExprs, false)
+	debugMap["GroupIndexRelations"] = helpers.DebugValue(c.GroupIndexRelations, false)
+	debugMap["GroupIndexMaxStaleness"] = helpers.DebugValue(c.GroupIndexMaxStaleness, false)
+	debugMap["MaterializedPermissions"] = helpers.DebugValue(c.MaterializedPermissions, false)
+	debugMap["MaterializedPermissionsMaxStaleness"] = helpers.DebugValue(c.MaterializedPermissionsMaxStaleness, false)
 	debugMap["DispatchCacheConfig"] = helpers.DebugValue(c.DispatchCacheConfig, false)
 	debugMap["ClusterDispatchCacheConfig"] = helpers.DebugValue(c.ClusterDispatchCacheConfig, false)
+	debugMap["DispatchDeniedCacheConfig"] = helpers.DebugValue(c.DispatchDeniedCacheConfig, false)
+	debugMap["ClusterDispatchDeniedCacheConfig"] = helpers.DebugValue(c.ClusterDispatchDeniedCacheConfig, false)
+	debugMap["MaxCacheMemory"] = helpers.DebugValue(c.MaxCacheMemory, false)
+	debugMap["CacheWarmupEnabled"] = helpers.DebugValue(c.CacheWarmupEnabled, false)
+	debugMap["CacheWarmupFile"] = helpers.DebugValue(c.CacheWarmupFile, false)
+	debugMap["CacheWarmupMaxSubproblems"] = helpers.DebugValue(c.CacheWarmupMaxSubproblems, false)
+	debugMap["CacheWarmupSampleRate"] = helpers.DebugValue(c.CacheWarmupSampleRate, false)
+	debugMap["CacheWarmupTimeout"] = helpers.DebugValue(c.CacheWarmupTimeout, false)
 	debugMap["DisableV1SchemaAPI"] = helpers.DebugValue(c.DisableV1SchemaAPI, false)
+	debugMap["DisableSchemaWrites"] = helpers.DebugValue(c.DisableSchemaWrites, false)
+	debugMap["SchemaDirectory"] = helpers.DebugValue(c.SchemaDirectory, false)
+	debugMap["DisableWatchAPI"] = helpers.DebugValue(c.DisableWatchAPI, false)
+	debugMap["EnableReflection"] = helpers.DebugValue(c.EnableReflection, false)
+	debugMap["DisableReflection"] = helpers.DebugValue(c.DisableReflection, false)
+	debugMap["DisableWrites"] = helpers.DebugValue(c.DisableWrites, false)
 	debugMap["V1SchemaAdditiveOnly"] = helpers.DebugValue(c.V1SchemaAdditiveOnly, false)
 	debugMap["MaximumUpdatesPerWrite"] = helpers.DebugValue(c.MaximumUpdatesPerWrite, false)
 	debugMap["MaximumPreconditionCount"] = helpers.DebugValue(c.MaximumPreconditionCount, false)
 	debugMap["MaxDatastoreReadPageSize"] = helpers.DebugValue(c.MaxDatastoreReadPageSize, false)
+	debugMap["MaxReadRelationshipsLimit"] = helpers.DebugValue(c.MaxReadRelationshipsLimit, false)
+	debugMap["DefaultReadRelationshipsLimit"] = helpers.DebugValue(c.DefaultReadRelationshipsLimit, false)
+	debugMap["MaxLookupResourcesLimit"] = helpers.DebugValue(c.MaxLookupResourcesLimit, false)
+	debugMap["DefaultLookupResourcesLimit"] = helpers.DebugValue(c.DefaultLookupResourcesLimit, false)
+	debugMap["DefaultExportBatchSize"] = helpers.DebugValue(c.DefaultExportBatchSize, false)
+	debugMap["MaxExportBatchSize"] = helpers.DebugValue(c.MaxExportBatchSize, false)
+	debugMap["WriteBatchWindow"] = helpers.DebugValue(c.WriteBatchWindow, false)
+	debugMap["CheckSessionIdleTimeout"] = helpers.DebugValue(c.CheckSessionIdleTimeout, false)
 	debugMap["StreamingAPITimeout"] = helpers.DebugValue(c.StreamingAPITimeout, false)
 	debugMap["WatchHeartbeat"] = helpers.DebugValue(c.WatchHeartbeat, false)
+	debugMap["WatchBufferLength"] = helpers.DebugValue(c.WatchBufferLength, false)
+	debugMap["WatchOverflow"] = helpers.DebugValue(c.WatchOverflow, false)
+	debugMap["SlowRequestThreshold"] = helpers.DebugValue(c.SlowRequestThreshold, false)
+	debugMap["DefaultRequestTimeouts"] = helpers.DebugValue(c.DefaultRequestTimeouts, false)
+	debugMap["MaxRequestTimeouts"] = helpers.DebugValue(c.MaxRequestTimeouts, false)
+	debugMap["FaultInjectionEnabled"] = helpers.DebugValue(c.FaultInjectionEnabled, false)
+	debugMap["FaultInjectionFaults"] = helpers.DebugValue(c.FaultInjectionFaults, false)
+	debugMap["EnforceRevisionTokens"] = helpers.DebugValue(c.EnforceRevisionTokens, false)
+	debugMap["RevisionTokenTimeout"] = helpers.DebugValue(c.RevisionTokenTimeout, false)
+	debugMap["AdaptiveConsistencyMaxStaleness"] = helpers.DebugValue(c.AdaptiveConsistencyMaxStaleness, false)
+	debugMap["PermissionMetricsMaxCardinality"] = helpers.DebugValue(c.PermissionMetricsMaxCardinality, false)
+	debugMap["NamespaceMetricsEnabled"] = helpers.DebugValue(c.NamespaceMetricsEnabled, false)
+	debugMap["NamespaceMetricsRefreshInterval"] = helpers.DebugValue(c.NamespaceMetricsRefreshInterval, false)
+	debugMap["NamespaceMaxRelationships"] = helpers.DebugValue(c.NamespaceMaxRelationships, false)
+	debugMap["NamespaceMaxWriteRate"] = helpers.DebugValue(c.NamespaceMaxWriteRate, false)
+	debugMap["NamespaceMaxLookupResults"] = helpers.DebugValue(c.NamespaceMaxLookupResults, false)
+	debugMap["NamespaceQuotaRefreshInterval"] = helpers.DebugValue(c.NamespaceQuotaRefreshInterval, false)
+	debugMap["WriteAdmissionPolicies"] = helpers.DebugValue(c.WriteAdmissionPolicies, false)
+	debugMap["UsageAccountingEnabled"] = helpers.DebugValue(c.UsageAccountingEnabled, false)
+	debugMap["UsageMonthlyRequestQuota"] = helpers.DebugValue(c.UsageMonthlyRequestQuota, false)
+	debugMap["UsageMonthlyMutationQuota"] = helpers.DebugValue(c.UsageMonthlyMutationQuota, false)
+	debugMap["UsageStoredRelationshipsRefreshInterval"] = helpers.DebugValue(c.UsageStoredRelationshipsRefreshInterval, false)
 	debugMap["MetricsAPI"] = helpers.DebugValue(c.MetricsAPI, false)
+	debugMap["DebugAPI"] = helpers.DebugValue(c.DebugAPI, false)
+	debugMap["DebugLogOverrideMaxDuration"] = helpers.DebugValue(c.DebugLogOverrideMaxDuration, false)
+	debugMap["GRPCMetricsLatencyBuckets"] = helpers.DebugValue(c.GRPCMetricsLatencyBuckets, false)
+	debugMap["StatsDAddr"] = helpers.DebugValue(c.StatsDAddr, false)
+	debugMap["StatsDPrefix"] = helpers.DebugValue(c.StatsDPrefix, false)
+	debugMap["StatsDTags"] = helpers.DebugValue(c.StatsDTags, false)
+	debugMap["StatsDInterval"] = helpers.DebugValue(c.StatsDInterval, false)
+	debugMap["ChangefeedKafkaBrokers"] = helpers.DebugValue(c.ChangefeedKafkaBrokers, false)
+	debugMap["ChangefeedKafkaTopic"] = helpers.DebugValue(c.ChangefeedKafkaTopic, false)
+	debugMap["ChangefeedName"] = helpers.DebugValue(c.ChangefeedName, false)
+	debugMap["ChangefeedCheckpointInterval"] = helpers.DebugValue(c.ChangefeedCheckpointInterval, false)
+	debugMap["BackupBucketURL"] = helpers.DebugValue(c.BackupBucketURL, false)
+	debugMap["BackupInterval"] = helpers.DebugValue(c.BackupInterval, false)
+	debugMap["BackupRetention"] = helpers.DebugValue(c.BackupRetention, false)
+	debugMap["BackupEncryptionKeyFile"] = helpers.DebugValue(c.BackupEncryptionKeyFile, false)
+	debugMap["AccessReportBucketURL"] = helpers.DebugValue(c.AccessReportBucketURL, false)
+	debugMap["AccessReportMaxRunning"] = helpers.DebugValue(c.AccessReportMaxRunning, false)
+	debugMap["LeaderElectionEnabled"] = helpers.DebugValue(c.LeaderElectionEnabled, false)
+	debugMap["LeaderElectionNodeName"] = helpers.DebugValue(c.LeaderElectionNodeName, false)
+	debugMap["LeaderElectionLeaseDuration"] = helpers.DebugValue(c.LeaderElectionLeaseDuration, false)
 	debugMap["SilentlyDisableTelemetry"] = helpers.DebugValue(c.SilentlyDisableTelemetry, false)
 	debugMap["TelemetryCAOverridePath"] = helpers.DebugValue(c.TelemetryCAOverridePath, false)
 	debugMap["TelemetryEndpoint"] = helpers.DebugValue(c.TelemetryEndpoint, false)
 	debugMap["TelemetryInterval"] = helpers.DebugValue(c.TelemetryInterval, false)
 	debugMap["EnableRequestLogs"] = helpers.DebugValue(c.EnableRequestLogs, false)
 	debugMap["EnableResponseLogs"] = helpers.DebugValue(c.EnableResponseLogs, false)
+	debugMap["LogSampleRates"] = helpers.DebugValue(c.LogSampleRates, false)
+	debugMap["RateLimitReadsPerSecond"] = helpers.DebugValue(c.RateLimitReadsPerSecond, false)
+	debugMap["RateLimitReadBurst"] = helpers.DebugValue(c.RateLimitReadBurst, false)
+	debugMap["RateLimitWritesPerSecond"] = helpers.DebugValue(c.RateLimitWritesPerSecond, false)
+	debugMap["RateLimitWriteBurst"] = helpers.DebugValue(c.RateLimitWriteBurst, false)
+	debugMap["MaxInFlightRequests"] = helpers.DebugValue(c.MaxInFlightRequests, false)
+	debugMap["MaxInFlightLowPriorityPercent"] = helpers.DebugValue(c.MaxInFlightLowPriorityPercent, false)
+	debugMap["MaxInFlightRetryAfter"] = helpers.DebugValue(c.MaxInFlightRetryAfter, false)
+	debugMap["AuditLogSink"] = helpers.DebugValue(c.AuditLogSink, false)
+	debugMap["AuditLogFilePath"] = helpers.DebugValue(c.AuditLogFilePath, false)
+	debugMap["AuditLogFileMaxSizeMB"] = helpers.DebugValue(c.AuditLogFileMaxSizeMB, false)
+	debugMap["AuditLogFileMaxBackups"] = helpers.DebugValue(c.AuditLogFileMaxBackups, false)
+	debugMap["AuditLogHTTPEndpoint"] = helpers.DebugValue(c.AuditLogHTTPEndpoint, false)
+	debugMap["AuditLogHTTPTimeout"] = helpers.DebugValue(c.AuditLogHTTPTimeout, false)
+	debugMap["DecisionLogSink"] = helpers.DebugValue(c.DecisionLogSink, false)
+	debugMap["DecisionLogHTTPEndpoint"] = helpers.DebugValue(c.DecisionLogHTTPEndpoint, false)
+	debugMap["DecisionLogUploadInterval"] = helpers.DebugValue(c.DecisionLogUploadInterval, false)
+	debugMap["DecisionLogBatchSize"] = helpers.DebugValue(c.DecisionLogBatchSize, false)
+	debugMap["DecisionLogBufferSize"] = helpers.DebugValue(c.DecisionLogBufferSize, false)
+	debugMap["DecisionLogHTTPTimeout"] = helpers.DebugValue(c.DecisionLogHTTPTimeout, false)
+	debugMap["ShadowCheckSampleRate"] = helpers.DebugValue(c.ShadowCheckSampleRate, false)
+	debugMap["ShadowCheckSchemaFile"] = helpers.DebugValue(c.ShadowCheckSchemaFile, false)
+	debugMap["ShadowCheckEndpoint"] = helpers.DebugValue(c.ShadowCheckEndpoint, false)
+	debugMap["ShadowCheckToken"] = helpers.SensitiveDebugValue(c.ShadowCheckToken)
+	debugMap["ShadowCheckInsecure"] = helpers.DebugValue(c.ShadowCheckInsecure, false)
+	debugMap["ShadowCheckCAPath"] = helpers.DebugValue(c.ShadowCheckCAPath, false)
+	debugMap["ShadowCheckExactSnapshot"] = helpers.DebugValue(c.ShadowCheckExactSnapshot, false)
+	debugMap["ShadowCheckMaxInFlight"] = helpers.DebugValue(c.ShadowCheckMaxInFlight, false)
+	debugMap["ShadowCheckTimeout"] = helpers.DebugValue(c.ShadowCheckTimeout, false)
+	debugMap["FederationRemoteNamespaces"] = helpers.DebugValue(c.FederationRemoteNamespaces, false)
+	debugMap["FederationToken"] = helpers.SensitiveDebugValue(c.FederationToken)
+	debugMap["FederationInsecure"] = helpers.DebugValue(c.FederationInsecure, false)
+	debugMap["FederationCAPath"] = helpers.DebugValue(c.FederationCAPath, false)
+	debugMap["FederationMaxStaleness"] = helpers.DebugValue(c.FederationMaxStaleness, false)
+	debugMap["FederationTimeout"] = helpers.DebugValue(c.FederationTimeout, false)
+	debugMap["RelationshipTransforms"] = helpers.DebugValue(c.RelationshipTransforms, false)
+	debugMap["SchemaWebhookURLs"] = helpers.DebugValue(c.SchemaWebhookURLs, false)
+	debugMap["SchemaWebhookSecret"] = helpers.SensitiveDebugValue(c.SchemaWebhookSecret)
+	debugMap["SchemaWebhookTimeout"] = helpers.DebugValue(c.SchemaWebhookTimeout, false)
+	debugMap["SchemaWebhookMaxAttempts"] = helpers.DebugValue(c.SchemaWebhookMaxAttempts, false)
+	debugMap["ExtAuthzRulesetPath"] = helpers.DebugValue(c.ExtAuthzRulesetPath, false)
+	debugMap["VaultAddress"] = helpers.DebugValue(c.VaultAddress, false)
+	debugMap["VaultAuthMethod"] = helpers.DebugValue(c.VaultAuthMethod, false)
+	debugMap["VaultAuthMount"] = helpers.DebugValue(c.VaultAuthMount, false)
+	debugMap["VaultToken"] = helpers.SensitiveDebugValue(c.VaultToken)
+	debugMap["VaultRole"] = helpers.DebugValue(c.VaultRole, false)
+	debugMap["VaultSecretID"] = helpers.SensitiveDebugValue(c.VaultSecretID)
+	debugMap["VaultKubernetesTokenPath"] = helpers.DebugValue(c.VaultKubernetesTokenPath, false)
+	debugMap["VaultTLSPath"] = helpers.DebugValue(c.VaultTLSPath, false)
+	debugMap["VaultTLSCommonName"] = helpers.DebugValue(c.VaultTLSCommonName, false)
+	debugMap["VaultPresharedKeysPath"] = helpers.DebugValue(c.VaultPresharedKeysPath, false)
+	debugMap["VaultRefreshInterval"] = helpers.DebugValue(c.VaultRefreshInterval, false)
+	debugMap["ErrorReportingDSN"] = helpers.SensitiveDebugValue(c.ErrorReportingDSN)
+	debugMap["ErrorReportingEnvironment"] = helpers.DebugValue(c.ErrorReportingEnvironment, false)
+	debugMap["ErrorReportingSampleRate"] = helpers.DebugValue(c.ErrorReportingSampleRate, false)
+	debugMap["ReloadConfigPath"] = helpers.DebugValue(c.ReloadConfigPath, false)
 	return debugMap
 }
 
@@ -195,6 +499,20 @@ func SetPresharedSecureKey(presharedSecureKey []string) ConfigOption {
 	}
 }
 
+// WithScopedPresharedKeys returns an option that can append ScopedPresharedKeyss to Config.ScopedPresharedKeys
+func WithScopedPresharedKeys(scopedPresharedKeys string) ConfigOption {
+	return func(c *Config) {
+		c.ScopedPresharedKeys = append(c.ScopedPresharedKeys, scopedPresharedKeys)
+	}
+}
+
+// SetScopedPresharedKeys returns an option that can set ScopedPresharedKeys on a Config
+func SetScopedPresharedKeys(scopedPresharedKeys []string) ConfigOption {
+	return func(c *Config) {
+		c.ScopedPresharedKeys = scopedPresharedKeys
+	}
+}
+
 // WithShutdownGracePeriod returns an option that can set ShutdownGracePeriod on a Config
 func WithShutdownGracePeriod(shutdownGracePeriod time.Duration) ConfigOption {
 	return func(c *Config) {
@@ -202,6 +520,13 @@ func WithShutdownGracePeriod(shutdownGracePeriod time.Duration) ConfigOption {
 	}
 }
 
+// WithShutdownStopTimeout returns an option that can set ShutdownStopTimeout on a Config
+func WithShutdownStopTimeout(shutdownStopTimeout time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.ShutdownStopTimeout = shutdownStopTimeout
+	}
+}
+
 // WithDisableVersionResponse returns an option that can set DisableVersionResponse on a Config
 func WithDisableVersionResponse(disableVersionResponse bool) ConfigOption {
 	return func(c *Config) {
@@ -209,6 +534,55 @@ func WithDisableVersionResponse(disableVersionResponse bool) ConfigOption {
 	}
 }
 
+// WithJWTIssuer returns an option that can set JWTIssuer on a Config
+func WithJWTIssuer(jWTIssuer string) ConfigOption {
+	return func(c *Config) {
+		c.JWTIssuer = jWTIssuer
+	}
+}
+
+// WithJWTJWKSURL returns an option that can set JWTJWKSURL on a Config
+func WithJWTJWKSURL(jWTJWKSURL string) ConfigOption {
+	return func(c *Config) {
+		c.JWTJWKSURL = jWTJWKSURL
+	}
+}
+
+// WithJWTAudience returns an option that can set JWTAudience on a Config
+func WithJWTAudience(jWTAudience string) ConfigOption {
+	return func(c *Config) {
+		c.JWTAudience = jWTAudience
+	}
+}
+
+// WithTenancyEnabled returns an option that can set TenancyEnabled on a Config
+func WithTenancyEnabled(tenancyEnabled bool) ConfigOption {
+	return func(c *Config) {
+		c.TenancyEnabled = tenancyEnabled
+	}
+}
+
+// WithZedTokenSigningKeys returns an option that can append ZedTokenSigningKeyss to Config.ZedTokenSigningKeys
+func WithZedTokenSigningKeys(zedTokenSigningKeys string) ConfigOption {
+	return func(c *Config) {
+		c.ZedTokenSigningKeys = append(c.ZedTokenSigningKeys, zedTokenSigningKeys)
+	}
+}
+
+// SetZedTokenSigningKeys returns an option that can set ZedTokenSigningKeys on a Config
+func SetZedTokenSigningKeys(zedTokenSigningKeys []string) ConfigOption {
+	return func(c *Config) {
+		c.ZedTokenSigningKeys = zedTokenSigningKeys
+	}
+}
+
+// WithZedTokenAllowUnsigned returns an option that can set ZedTokenAllowUnsigned on a Config
+func WithZedTokenAllowUnsigned(zedTokenAllowUnsigned bool) ConfigOption {
+	return func(c *Config) {
+		c.ZedTokenAllowUnsigned = zedTokenAllowUnsigned
+	}
+}
+
 // WithHTTPGateway returns an option that can set HTTPGateway on a Config
 func WithHTTPGateway(hTTPGateway util.HTTPServerConfig) ConfigOption {
 	return func(c *Config) {
@@ -251,6 +625,34 @@ func SetHTTPGatewayCorsAllowedOrigins(hTTPGatewayCorsAllowedOrigins []string) Co
 	}
 }
 
+// WithGRPCWeb returns an option that can set GRPCWeb on a Config
+func WithGRPCWeb(gRPCWeb util.HTTPServerConfig) ConfigOption {
+	return func(c *Config) {
+		c.GRPCWeb = gRPCWeb
+	}
+}
+
+// WithGRPCWebCorsEnabled returns an option that can set GRPCWebCorsEnabled on a Config
+func WithGRPCWebCorsEnabled(gRPCWebCorsEnabled bool) ConfigOption {
+	return func(c *Config) {
+		c.GRPCWebCorsEnabled = gRPCWebCorsEnabled
+	}
+}
+
+// WithGRPCWebCorsAllowedOrigins returns an option that can append GRPCWebCorsAllowedOriginss to Config.GRPCWebCorsAllowedOrigins
+func WithGRPCWebCorsAllowedOrigins(gRPCWebCorsAllowedOrigins string) ConfigOption {
+	return func(c *Config) {
+		c.GRPCWebCorsAllowedOrigins = append(c.GRPCWebCorsAllowedOrigins, gRPCWebCorsAllowedOrigins)
+	}
+}
+
+// SetGRPCWebCorsAllowedOrigins returns an option that can set GRPCWebCorsAllowedOrigins on a Config
+func SetGRPCWebCorsAllowedOrigins(gRPCWebCorsAllowedOrigins []string) ConfigOption {
+	return func(c *Config) {
+		c.GRPCWebCorsAllowedOrigins = gRPCWebCorsAllowedOrigins
+	}
+}
+
 // WithDatastoreConfig returns an option that can set DatastoreConfig on a Config
 func WithDatastoreConfig(datastoreConfig datastore.Config) ConfigOption {
 	return func(c *Config) {
@@ -321,6 +723,20 @@ func WithDispatchMaxDepth(dispatchMaxDepth uint32) ConfigOption {
 	}
 }
 
+// WithDispatchCheckMaxRelationships returns an option that can set DispatchCheckMaxRelationships on a Config
+func WithDispatchCheckMaxRelationships(dispatchCheckMaxRelationships uint64) ConfigOption {
+	return func(c *Config) {
+		c.DispatchCheckMaxRelationships = dispatchCheckMaxRelationships
+	}
+}
+
+// WithDispatchCheckMaxSubproblems returns an option that can set DispatchCheckMaxSubproblems on a Config
+func WithDispatchCheckMaxSubproblems(dispatchCheckMaxSubproblems uint64) ConfigOption {
+	return func(c *Config) {
+		c.DispatchCheckMaxSubproblems = dispatchCheckMaxSubproblems
+	}
+}
+
 // WithGlobalDispatchConcurrencyLimit returns an option that can set GlobalDispatchConcurrencyLimit on a Config
 func WithGlobalDispatchConcurrencyLimit(globalDispatchConcurrencyLimit uint16) ConfigOption {
 	return func(c *Config) {
@@ -342,6 +758,41 @@ func WithDispatchUpstreamAddr(dispatchUpstreamAddr string) ConfigOption {
 	}
 }
 
+// WithDispatchUpstreamKubernetesService returns an option that can set DispatchUpstreamKubernetesService on a Config
+func WithDispatchUpstreamKubernetesService(dispatchUpstreamKubernetesService string) ConfigOption {
+	return func(c *Config) {
+		c.DispatchUpstreamKubernetesService = dispatchUpstreamKubernetesService
+	}
+}
+
+// WithDispatchUpstreamDiscovery returns an option that can set DispatchUpstreamDiscovery on a Config
+func WithDispatchUpstreamDiscovery(dispatchUpstreamDiscovery string) ConfigOption {
+	return func(c *Config) {
+		c.DispatchUpstreamDiscovery = dispatchUpstreamDiscovery
+	}
+}
+
+// WithDispatchUpstreamDiscoveryTarget returns an option that can set DispatchUpstreamDiscoveryTarget on a Config
+func WithDispatchUpstreamDiscoveryTarget(dispatchUpstreamDiscoveryTarget string) ConfigOption {
+	return func(c *Config) {
+		c.DispatchUpstreamDiscoveryTarget = dispatchUpstreamDiscoveryTarget
+	}
+}
+
+// WithDispatchUpstreamDiscoveryEndpoint returns an option that can set DispatchUpstreamDiscoveryEndpoint on a Config
+func WithDispatchUpstreamDiscoveryEndpoint(dispatchUpstreamDiscoveryEndpoint string) ConfigOption {
+	return func(c *Config) {
+		c.DispatchUpstreamDiscoveryEndpoint = dispatchUpstreamDiscoveryEndpoint
+	}
+}
+
+// WithDispatchUpstreamDiscoveryInterval returns an option that can set DispatchUpstreamDiscoveryInterval on a Config
+func WithDispatchUpstreamDiscoveryInterval(dispatchUpstreamDiscoveryInterval time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.DispatchUpstreamDiscoveryInterval = dispatchUpstreamDiscoveryInterval
+	}
+}
+
 // WithDispatchUpstreamCAPath returns an option that can set DispatchUpstreamCAPath on a Config
 func WithDispatchUpstreamCAPath(dispatchUpstreamCAPath string) ConfigOption {
 	return func(c *Config) {
@@ -349,6 +800,34 @@ func WithDispatchUpstreamCAPath(dispatchUpstreamCAPath string) ConfigOption {
 	}
 }
 
+// WithDispatchUpstreamTLSCertPath returns an option that can set DispatchUpstreamTLSCertPath on a Config
+func WithDispatchUpstreamTLSCertPath(dispatchUpstreamTLSCertPath string) ConfigOption {
+	return func(c *Config) {
+		c.DispatchUpstreamTLSCertPath = dispatchUpstreamTLSCertPath
+	}
+}
+
+// WithDispatchUpstreamTLSKeyPath returns an option that can set DispatchUpstreamTLSKeyPath on a Config
+func WithDispatchUpstreamTLSKeyPath(dispatchUpstreamTLSKeyPath string) ConfigOption {
+	return func(c *Config) {
+		c.DispatchUpstreamTLSKeyPath = dispatchUpstreamTLSKeyPath
+	}
+}
+
+// WithDispatchPresharedKey returns an option that can append DispatchPresharedKeys to Config.DispatchPresharedKey
+func WithDispatchPresharedKey(dispatchPresharedKey string) ConfigOption {
+	return func(c *Config) {
+		c.DispatchPresharedKey = append(c.DispatchPresharedKey, dispatchPresharedKey)
+	}
+}
+
+// SetDispatchPresharedKey returns an option that can set DispatchPresharedKey on a Config
+func SetDispatchPresharedKey(dispatchPresharedKey []string) ConfigOption {
+	return func(c *Config) {
+		c.DispatchPresharedKey = dispatchPresharedKey
+	}
+}
+
 // WithDispatchUpstreamTimeout returns an option that can set DispatchUpstreamTimeout on a Config
 func WithDispatchUpstreamTimeout(dispatchUpstreamTimeout time.Duration) ConfigOption {
 	return func(c *Config) {
@@ -356,6 +835,41 @@ func WithDispatchUpstreamTimeout(dispatchUpstreamTimeout time.Duration) ConfigOp
 	}
 }
 
+// WithDispatchLocalFallbackEnabled returns an option that can set DispatchLocalFallbackEnabled on a Config
+func WithDispatchLocalFallbackEnabled(dispatchLocalFallbackEnabled bool) ConfigOption {
+	return func(c *Config) {
+		c.DispatchLocalFallbackEnabled = dispatchLocalFallbackEnabled
+	}
+}
+
+// WithDispatchHedgingEnabled returns an option that can set DispatchHedgingEnabled on a Config
+func WithDispatchHedgingEnabled(dispatchHedgingEnabled bool) ConfigOption {
+	return func(c *Config) {
+		c.DispatchHedgingEnabled = dispatchHedgingEnabled
+	}
+}
+
+// WithDispatchHedgingInitialSlowValue returns an option that can set DispatchHedgingInitialSlowValue on a Config
+func WithDispatchHedgingInitialSlowValue(dispatchHedgingInitialSlowValue time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.DispatchHedgingInitialSlowValue = dispatchHedgingInitialSlowValue
+	}
+}
+
+// WithDispatchHedgingMaxRequests returns an option that can set DispatchHedgingMaxRequests on a Config
+func WithDispatchHedgingMaxRequests(dispatchHedgingMaxRequests uint64) ConfigOption {
+	return func(c *Config) {
+		c.DispatchHedgingMaxRequests = dispatchHedgingMaxRequests
+	}
+}
+
+// WithDispatchHedgingQuantile returns an option that can set DispatchHedgingQuantile on a Config
+func WithDispatchHedgingQuantile(dispatchHedgingQuantile float64) ConfigOption {
+	return func(c *Config) {
+		c.DispatchHedgingQuantile = dispatchHedgingQuantile
+	}
+}
+
 // WithDispatchClientMetricsEnabled returns an option that can set DispatchClientMetricsEnabled on a Config
 func WithDispatchClientMetricsEnabled(dispatchClientMetricsEnabled bool) ConfigOption {
 	return func(c *Config) {
@@ -405,6 +919,20 @@ func WithDispatchHashringSpread(dispatchHashringSpread uint8) ConfigOption {
 	}
 }
 
+// WithDispatchHashringLoadFactor returns an option that can set DispatchHashringLoadFactor on a Config
+func WithDispatchHashringLoadFactor(dispatchHashringLoadFactor float64) ConfigOption {
+	return func(c *Config) {
+		c.DispatchHashringLoadFactor = dispatchHashringLoadFactor
+	}
+}
+
+// WithDispatchHashringDualDispatchWindow returns an option that can set DispatchHashringDualDispatchWindow on a Config
+func WithDispatchHashringDualDispatchWindow(dispatchHashringDualDispatchWindow time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.DispatchHashringDualDispatchWindow = dispatchHashringDualDispatchWindow
+	}
+}
+
 // WithDispatchSecondaryUpstreamAddrs returns an option that can append DispatchSecondaryUpstreamAddrss to Config.DispatchSecondaryUpstreamAddrs
 func WithDispatchSecondaryUpstreamAddrs(key string, value string) ConfigOption {
 	return func(c *Config) {
@@ -433,6 +961,48 @@ func SetDispatchSecondaryUpstreamExprs(dispatchSecondaryUpstreamExprs map[string
 	}
 }
 
+// WithGroupIndexRelations returns an option that can append GroupIndexRelationss to Config.GroupIndexRelations
+func WithGroupIndexRelations(groupIndexRelations string) ConfigOption {
+	return func(c *Config) {
+		c.GroupIndexRelations = append(c.GroupIndexRelations, groupIndexRelations)
+	}
+}
+
+// SetGroupIndexRelations returns an option that can set GroupIndexRelations on a Config
+func SetGroupIndexRelations(groupIndexRelations []string) ConfigOption {
+	return func(c *Config) {
+		c.GroupIndexRelations = groupIndexRelations
+	}
+}
+
+// WithGroupIndexMaxStaleness returns an option that can set GroupIndexMaxStaleness on a Config
+func WithGroupIndexMaxStaleness(groupIndexMaxStaleness time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.GroupIndexMaxStaleness = groupIndexMaxStaleness
+	}
+}
+
+// WithMaterializedPermissions returns an option that can append MaterializedPermissionss to Config.MaterializedPermissions
+func WithMaterializedPermissions(materializedPermissions string) ConfigOption {
+	return func(c *Config) {
+		c.MaterializedPermissions = append(c.MaterializedPermissions, materializedPermissions)
+	}
+}
+
+// SetMaterializedPermissions returns an option that can set MaterializedPermissions on a Config
+func SetMaterializedPermissions(materializedPermissions []string) ConfigOption {
+	return func(c *Config) {
+		c.MaterializedPermissions = materializedPermissions
+	}
+}
+
+// WithMaterializedPermissionsMaxStaleness returns an option that can set MaterializedPermissionsMaxStaleness on a Config
+func WithMaterializedPermissionsMaxStaleness(materializedPermissionsMaxStaleness time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.MaterializedPermissionsMaxStaleness = materializedPermissionsMaxStaleness
+	}
+}
+
 // WithDispatchCacheConfig returns an option that can set DispatchCacheConfig on a Config
 func WithDispatchCacheConfig(dispatchCacheConfig CacheConfig) ConfigOption {
 	return func(c *Config) {
@@ -447,6 +1017,62 @@ func WithClusterDispatchCacheConfig(clusterDispatchCacheConfig CacheConfig) Conf
 	}
 }
 
+// WithDispatchDeniedCacheConfig returns an option that can set DispatchDeniedCacheConfig on a Config
+func WithDispatchDeniedCacheConfig(dispatchDeniedCacheConfig CacheConfig) ConfigOption {
+	return func(c *Config) {
+		c.DispatchDeniedCacheConfig = dispatchDeniedCacheConfig
+	}
+}
+
+// WithClusterDispatchDeniedCacheConfig returns an option that can set ClusterDispatchDeniedCacheConfig on a Config
+func WithClusterDispatchDeniedCacheConfig(clusterDispatchDeniedCacheConfig CacheConfig) ConfigOption {
+	return func(c *Config) {
+		c.ClusterDispatchDeniedCacheConfig = clusterDispatchDeniedCacheConfig
+	}
+}
+
+// WithMaxCacheMemory returns an option that can set MaxCacheMemory on a Config
+func WithMaxCacheMemory(maxCacheMemory string) ConfigOption {
+	return func(c *Config) {
+		c.MaxCacheMemory = maxCacheMemory
+	}
+}
+
+// WithCacheWarmupEnabled returns an option that can set CacheWarmupEnabled on a Config
+func WithCacheWarmupEnabled(cacheWarmupEnabled bool) ConfigOption {
+	return func(c *Config) {
+		c.CacheWarmupEnabled = cacheWarmupEnabled
+	}
+}
+
+// WithCacheWarmupFile returns an option that can set CacheWarmupFile on a Config
+func WithCacheWarmupFile(cacheWarmupFile string) ConfigOption {
+	return func(c *Config) {
+		c.CacheWarmupFile = cacheWarmupFile
+	}
+}
+
+// WithCacheWarmupMaxSubproblems returns an option that can set CacheWarmupMaxSubproblems on a Config
+func WithCacheWarmupMaxSubproblems(cacheWarmupMaxSubproblems int) ConfigOption {
+	return func(c *Config) {
+		c.CacheWarmupMaxSubproblems = cacheWarmupMaxSubproblems
+	}
+}
+
+// WithCacheWarmupSampleRate returns an option that can set CacheWarmupSampleRate on a Config
+func WithCacheWarmupSampleRate(cacheWarmupSampleRate float64) ConfigOption {
+	return func(c *Config) {
+		c.CacheWarmupSampleRate = cacheWarmupSampleRate
+	}
+}
+
+// WithCacheWarmupTimeout returns an option that can set CacheWarmupTimeout on a Config
+func WithCacheWarmupTimeout(cacheWarmupTimeout time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.CacheWarmupTimeout = cacheWarmupTimeout
+	}
+}
+
 // WithDisableV1SchemaAPI returns an option that can set DisableV1SchemaAPI on a Config
 func WithDisableV1SchemaAPI(disableV1SchemaAPI bool) ConfigOption {
 	return func(c *Config) {
@@ -454,6 +1080,48 @@ func WithDisableV1SchemaAPI(disableV1SchemaAPI bool) ConfigOption {
 	}
 }
 
+// WithDisableSchemaWrites returns an option that can set DisableSchemaWrites on a Config
+func WithDisableSchemaWrites(disableSchemaWrites bool) ConfigOption {
+	return func(c *Config) {
+		c.DisableSchemaWrites = disableSchemaWrites
+	}
+}
+
+// WithSchemaDirectory returns an option that can set SchemaDirectory on a Config
+func WithSchemaDirectory(schemaDirectory string) ConfigOption {
+	return func(c *Config) {
+		c.SchemaDirectory = schemaDirectory
+	}
+}
+
+// WithDisableWatchAPI returns an option that can set DisableWatchAPI on a Config
+func WithDisableWatchAPI(disableWatchAPI bool) ConfigOption {
+	return func(c *Config) {
+		c.DisableWatchAPI = disableWatchAPI
+	}
+}
+
+// WithEnableReflection returns an option that can set EnableReflection on a Config
+func WithEnableReflection(enableReflection bool) ConfigOption {
+	return func(c *Config) {
+		c.EnableReflection = enableReflection
+	}
+}
+
+// WithDisableReflection returns an option that can set DisableReflection on a Config
+func WithDisableReflection(disableReflection bool) ConfigOption {
+	return func(c *Config) {
+		c.DisableReflection = disableReflection
+	}
+}
+
+// WithDisableWrites returns an option that can set DisableWrites on a Config
+func WithDisableWrites(disableWrites bool) ConfigOption {
+	return func(c *Config) {
+		c.DisableWrites = disableWrites
+	}
+}
+
 // WithV1SchemaAdditiveOnly returns an option that can set V1SchemaAdditiveOnly on a Config
 func WithV1SchemaAdditiveOnly(v1SchemaAdditiveOnly bool) ConfigOption {
 	return func(c *Config) {
@@ -482,101 +1150,535 @@ func WithMaxDatastoreReadPageSize(maxDatastoreReadPageSize uint64) ConfigOption
 	}
 }
 
-// WithStreamingAPITimeout returns an option that can set StreamingAPITimeout on a Config
-func WithStreamingAPITimeout(streamingAPITimeout time.Duration) ConfigOption {
+// WithMaxReadRelationshipsLimit returns an option that can set MaxReadRelationshipsLimit on a Config
+func WithMaxReadRelationshipsLimit(maxReadRelationshipsLimit uint32) ConfigOption {
 	return func(c *Config) {
-		c.StreamingAPITimeout = streamingAPITimeout
+		c.MaxReadRelationshipsLimit = maxReadRelationshipsLimit
 	}
 }
 
-// WithWatchHeartbeat returns an option that can set WatchHeartbeat on a Config
-func WithWatchHeartbeat(watchHeartbeat time.Duration) ConfigOption {
+// WithDefaultReadRelationshipsLimit returns an option that can set DefaultReadRelationshipsLimit on a Config
+func WithDefaultReadRelationshipsLimit(defaultReadRelationshipsLimit uint32) ConfigOption {
 	return func(c *Config) {
-		c.WatchHeartbeat = watchHeartbeat
+		c.DefaultReadRelationshipsLimit = defaultReadRelationshipsLimit
 	}
 }
 
-// WithMetricsAPI returns an option that can set MetricsAPI on a Config
-func WithMetricsAPI(metricsAPI util.HTTPServerConfig) ConfigOption {
+// WithMaxLookupResourcesLimit returns an option that can set MaxLookupResourcesLimit on a Config
+func WithMaxLookupResourcesLimit(maxLookupResourcesLimit uint32) ConfigOption {
 	return func(c *Config) {
-		c.MetricsAPI = metricsAPI
+		c.MaxLookupResourcesLimit = maxLookupResourcesLimit
 	}
 }
 
-// WithUnaryMiddlewareModification returns an option that can append UnaryMiddlewareModifications to Config.UnaryMiddlewareModification
-func WithUnaryMiddlewareModification(unaryMiddlewareModification MiddlewareModification[grpc.UnaryServerInterceptor]) ConfigOption {
+// WithDefaultLookupResourcesLimit returns an option that can set DefaultLookupResourcesLimit on a Config
+func WithDefaultLookupResourcesLimit(defaultLookupResourcesLimit uint32) ConfigOption {
 	return func(c *Config) {
-		c.UnaryMiddlewareModification = append(c.UnaryMiddlewareModification, unaryMiddlewareModification)
+		c.DefaultLookupResourcesLimit = defaultLookupResourcesLimit
 	}
 }
 
-// SetUnaryMiddlewareModification returns an option that can set UnaryMiddlewareModification on a Config
-func SetUnaryMiddlewareModification(unaryMiddlewareModification []MiddlewareModification[grpc.UnaryServerInterceptor]) ConfigOption {
+// WithDefaultExportBatchSize returns an option that can set DefaultExportBatchSize on a Config
+func WithDefaultExportBatchSize(defaultExportBatchSize uint32) ConfigOption {
 	return func(c *Config) {
-		c.UnaryMiddlewareModification = unaryMiddlewareModification
+		c.DefaultExportBatchSize = defaultExportBatchSize
 	}
 }
 
-// WithStreamingMiddlewareModification returns an option that can append StreamingMiddlewareModifications to Config.StreamingMiddlewareModification
-func WithStreamingMiddlewareModification(streamingMiddlewareModification MiddlewareModification[grpc.StreamServerInterceptor]) ConfigOption {
+// WithMaxExportBatchSize returns an option that can set MaxExportBatchSize on a Config
+func WithMaxExportBatchSize(maxExportBatchSize uint32) ConfigOption {
 	return func(c *Config) {
-		c.StreamingMiddlewareModification = append(c.StreamingMiddlewareModification, streamingMiddlewareModification)
+		c.MaxExportBatchSize = maxExportBatchSize
 	}
 }
 
-// SetStreamingMiddlewareModification returns an option that can set StreamingMiddlewareModification on a Config
-func SetStreamingMiddlewareModification(streamingMiddlewareModification []MiddlewareModification[grpc.StreamServerInterceptor]) ConfigOption {
+// WithWriteBatchWindow returns an option that can set WriteBatchWindow on a Config
+func WithWriteBatchWindow(writeBatchWindow time.Duration) ConfigOption {
 	return func(c *Config) {
-		c.StreamingMiddlewareModification = streamingMiddlewareModification
+		c.WriteBatchWindow = writeBatchWindow
 	}
 }
 
-// WithDispatchUnaryMiddleware returns an option that can append DispatchUnaryMiddlewares to Config.DispatchUnaryMiddleware
-func WithDispatchUnaryMiddleware(dispatchUnaryMiddleware grpc.UnaryServerInterceptor) ConfigOption {
+// WithCheckSessionIdleTimeout returns an option that can set CheckSessionIdleTimeout on a Config
+func WithCheckSessionIdleTimeout(checkSessionIdleTimeout time.Duration) ConfigOption {
 	return func(c *Config) {
-		c.DispatchUnaryMiddleware = append(c.DispatchUnaryMiddleware, dispatchUnaryMiddleware)
+		c.CheckSessionIdleTimeout = checkSessionIdleTimeout
 	}
 }
 
-// SetDispatchUnaryMiddleware returns an option that can set DispatchUnaryMiddleware on a Config
-func SetDispatchUnaryMiddleware(dispatchUnaryMiddleware []grpc.UnaryServerInterceptor) ConfigOption {
+// WithStreamingAPITimeout returns an option that can set StreamingAPITimeout on a Config
+func WithStreamingAPITimeout(streamingAPITimeout time.Duration) ConfigOption {
 	return func(c *Config) {
-		c.DispatchUnaryMiddleware = dispatchUnaryMiddleware
+		c.StreamingAPITimeout = streamingAPITimeout
 	}
 }
 
-// WithDispatchStreamingMiddleware returns an option that can append DispatchStreamingMiddlewares to Config.DispatchStreamingMiddleware
-func WithDispatchStreamingMiddleware(dispatchStreamingMiddleware grpc.StreamServerInterceptor) ConfigOption {
+// WithWatchHeartbeat returns an option that can set WatchHeartbeat on a Config
+func WithWatchHeartbeat(watchHeartbeat time.Duration) ConfigOption {
 	return func(c *Config) {
-		c.DispatchStreamingMiddleware = append(c.DispatchStreamingMiddleware, dispatchStreamingMiddleware)
+		c.WatchHeartbeat = watchHeartbeat
 	}
 }
 
-// SetDispatchStreamingMiddleware returns an option that can set DispatchStreamingMiddleware on a Config
-func SetDispatchStreamingMiddleware(dispatchStreamingMiddleware []grpc.StreamServerInterceptor) ConfigOption {
+// WithWatchBufferLength returns an option that can set WatchBufferLength on a Config
+func WithWatchBufferLength(watchBufferLength int) ConfigOption {
 	return func(c *Config) {
-		c.DispatchStreamingMiddleware = dispatchStreamingMiddleware
+		c.WatchBufferLength = watchBufferLength
 	}
 }
 
-// WithSilentlyDisableTelemetry returns an option that can set SilentlyDisableTelemetry on a Config
-func WithSilentlyDisableTelemetry(silentlyDisableTelemetry bool) ConfigOption {
+// WithWatchOverflow returns an option that can set WatchOverflow on a Config
+func WithWatchOverflow(watchOverflow string) ConfigOption {
 	return func(c *Config) {
-		c.SilentlyDisableTelemetry = silentlyDisableTelemetry
+		c.WatchOverflow = watchOverflow
 	}
 }
 
-// WithTelemetryCAOverridePath returns an option that can set TelemetryCAOverridePath on a Config
-func WithTelemetryCAOverridePath(telemetryCAOverridePath string) ConfigOption {
+// WithSlowRequestThreshold returns an option that can set SlowRequestThreshold on a Config
+func WithSlowRequestThreshold(slowRequestThreshold time.Duration) ConfigOption {
 	return func(c *Config) {
-		c.TelemetryCAOverridePath = telemetryCAOverridePath
+		c.SlowRequestThreshold = slowRequestThreshold
 	}
 }
 
-// WithTelemetryEndpoint returns an option that can set TelemetryEndpoint on a Config
-func WithTelemetryEndpoint(telemetryEndpoint string) ConfigOption {
+// WithDefaultRequestTimeouts returns an option that can append DefaultRequestTimeoutss to Config.DefaultRequestTimeouts
+func WithDefaultRequestTimeouts(key string, value string) ConfigOption {
 	return func(c *Config) {
-		c.TelemetryEndpoint = telemetryEndpoint
+		c.DefaultRequestTimeouts[key] = value
+	}
+}
+
+// SetDefaultRequestTimeouts returns an option that can set DefaultRequestTimeouts on a Config
+func SetDefaultRequestTimeouts(defaultRequestTimeouts map[string]string) ConfigOption {
+	return func(c *Config) {
+		c.DefaultRequestTimeouts = defaultRequestTimeouts
+	}
+}
+
+// WithMaxRequestTimeouts returns an option that can append MaxRequestTimeoutss to Config.MaxRequestTimeouts
+func WithMaxRequestTimeouts(key string, value string) ConfigOption {
+	return func(c *Config) {
+		c.MaxRequestTimeouts[key] = value
+	}
+}
+
+// SetMaxRequestTimeouts returns an option that can set MaxRequestTimeouts on a Config
+func SetMaxRequestTimeouts(maxRequestTimeouts map[string]string) ConfigOption {
+	return func(c *Config) {
+		c.MaxRequestTimeouts = maxRequestTimeouts
+	}
+}
+
+// WithFaultInjectionEnabled returns an option that can set FaultInjectionEnabled on a Config
+func WithFaultInjectionEnabled(faultInjectionEnabled bool) ConfigOption {
+	return func(c *Config) {
+		c.FaultInjectionEnabled = faultInjectionEnabled
+	}
+}
+
+// WithFaultInjectionFaults returns an option that can append FaultInjectionFaultss to Config.FaultInjectionFaults
+func WithFaultInjectionFaults(faultInjectionFaults string) ConfigOption {
+	return func(c *Config) {
+		c.FaultInjectionFaults = append(c.FaultInjectionFaults, faultInjectionFaults)
+	}
+}
+
+// SetFaultInjectionFaults returns an option that can set FaultInjectionFaults on a Config
+func SetFaultInjectionFaults(faultInjectionFaults []string) ConfigOption {
+	return func(c *Config) {
+		c.FaultInjectionFaults = faultInjectionFaults
+	}
+}
+
+// WithEnforceRevisionTokens returns an option that can set EnforceRevisionTokens on a Config
+func WithEnforceRevisionTokens(enforceRevisionTokens bool) ConfigOption {
+	return func(c *Config) {
+		c.EnforceRevisionTokens = enforceRevisionTokens
+	}
+}
+
+// WithRevisionTokenTimeout returns an option that can set RevisionTokenTimeout on a Config
+func WithRevisionTokenTimeout(revisionTokenTimeout time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.RevisionTokenTimeout = revisionTokenTimeout
+	}
+}
+
+// WithAdaptiveConsistencyMaxStaleness returns an option that can set AdaptiveConsistencyMaxStaleness on a Config
+func WithAdaptiveConsistencyMaxStaleness(adaptiveConsistencyMaxStaleness time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.AdaptiveConsistencyMaxStaleness = adaptiveConsistencyMaxStaleness
+	}
+}
+
+// WithPermissionMetricsMaxCardinality returns an option that can set PermissionMetricsMaxCardinality on a Config
+func WithPermissionMetricsMaxCardinality(permissionMetricsMaxCardinality uint32) ConfigOption {
+	return func(c *Config) {
+		c.PermissionMetricsMaxCardinality = permissionMetricsMaxCardinality
+	}
+}
+
+// WithNamespaceMetricsEnabled returns an option that can set NamespaceMetricsEnabled on a Config
+func WithNamespaceMetricsEnabled(namespaceMetricsEnabled bool) ConfigOption {
+	return func(c *Config) {
+		c.NamespaceMetricsEnabled = namespaceMetricsEnabled
+	}
+}
+
+// WithNamespaceMetricsRefreshInterval returns an option that can set NamespaceMetricsRefreshInterval on a Config
+func WithNamespaceMetricsRefreshInterval(namespaceMetricsRefreshInterval time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.NamespaceMetricsRefreshInterval = namespaceMetricsRefreshInterval
+	}
+}
+
+// WithNamespaceMaxRelationships returns an option that can append NamespaceMaxRelationshipss to Config.NamespaceMaxRelationships
+func WithNamespaceMaxRelationships(key string, value string) ConfigOption {
+	return func(c *Config) {
+		c.NamespaceMaxRelationships[key] = value
+	}
+}
+
+// SetNamespaceMaxRelationships returns an option that can set NamespaceMaxRelationships on a Config
+func SetNamespaceMaxRelationships(namespaceMaxRelationships map[string]string) ConfigOption {
+	return func(c *Config) {
+		c.NamespaceMaxRelationships = namespaceMaxRelationships
+	}
+}
+
+// WithNamespaceMaxWriteRate returns an option that can append NamespaceMaxWriteRates to Config.NamespaceMaxWriteRate
+func WithNamespaceMaxWriteRate(key string, value string) ConfigOption {
+	return func(c *Config) {
+		c.NamespaceMaxWriteRate[key] = value
+	}
+}
+
+// SetNamespaceMaxWriteRate returns an option that can set NamespaceMaxWriteRate on a Config
+func SetNamespaceMaxWriteRate(namespaceMaxWriteRate map[string]string) ConfigOption {
+	return func(c *Config) {
+		c.NamespaceMaxWriteRate = namespaceMaxWriteRate
+	}
+}
+
+// WithNamespaceMaxLookupResults returns an option that can append NamespaceMaxLookupResultss to Config.NamespaceMaxLookupResults
+func WithNamespaceMaxLookupResults(key string, value string) ConfigOption {
+	return func(c *Config) {
+		c.NamespaceMaxLookupResults[key] = value
+	}
+}
+
+// SetNamespaceMaxLookupResults returns an option that can set NamespaceMaxLookupResults on a Config
+func SetNamespaceMaxLookupResults(namespaceMaxLookupResults map[string]string) ConfigOption {
+	return func(c *Config) {
+		c.NamespaceMaxLookupResults = namespaceMaxLookupResults
+	}
+}
+
+// WithNamespaceQuotaRefreshInterval returns an option that can set NamespaceQuotaRefreshInterval on a Config
+func WithNamespaceQuotaRefreshInterval(namespaceQuotaRefreshInterval time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.NamespaceQuotaRefreshInterval = namespaceQuotaRefreshInterval
+	}
+}
+
+// WithWriteAdmissionPolicies returns an option that can append WriteAdmissionPoliciess to Config.WriteAdmissionPolicies
+func WithWriteAdmissionPolicies(writeAdmissionPolicies string) ConfigOption {
+	return func(c *Config) {
+		c.WriteAdmissionPolicies = append(c.WriteAdmissionPolicies, writeAdmissionPolicies)
+	}
+}
+
+// SetWriteAdmissionPolicies returns an option that can set WriteAdmissionPolicies on a Config
+func SetWriteAdmissionPolicies(writeAdmissionPolicies []string) ConfigOption {
+	return func(c *Config) {
+		c.WriteAdmissionPolicies = writeAdmissionPolicies
+	}
+}
+
+// WithUsageAccountingEnabled returns an option that can set UsageAccountingEnabled on a Config
+func WithUsageAccountingEnabled(usageAccountingEnabled bool) ConfigOption {
+	return func(c *Config) {
+		c.UsageAccountingEnabled = usageAccountingEnabled
+	}
+}
+
+// WithUsageMonthlyRequestQuota returns an option that can append UsageMonthlyRequestQuotas to Config.UsageMonthlyRequestQuota
+func WithUsageMonthlyRequestQuota(key string, value string) ConfigOption {
+	return func(c *Config) {
+		c.UsageMonthlyRequestQuota[key] = value
+	}
+}
+
+// SetUsageMonthlyRequestQuota returns an option that can set UsageMonthlyRequestQuota on a Config
+func SetUsageMonthlyRequestQuota(usageMonthlyRequestQuota map[string]string) ConfigOption {
+	return func(c *Config) {
+		c.UsageMonthlyRequestQuota = usageMonthlyRequestQuota
+	}
+}
+
+// WithUsageMonthlyMutationQuota returns an option that can append UsageMonthlyMutationQuotas to Config.UsageMonthlyMutationQuota
+func WithUsageMonthlyMutationQuota(key string, value string) ConfigOption {
+	return func(c *Config) {
+		c.UsageMonthlyMutationQuota[key] = value
+	}
+}
+
+// SetUsageMonthlyMutationQuota returns an option that can set UsageMonthlyMutationQuota on a Config
+func SetUsageMonthlyMutationQuota(usageMonthlyMutationQuota map[string]string) ConfigOption {
+	return func(c *Config) {
+		c.UsageMonthlyMutationQuota = usageMonthlyMutationQuota
+	}
+}
+
+// WithUsageStoredRelationshipsRefreshInterval returns an option that can set UsageStoredRelationshipsRefreshInterval on a Config
+func WithUsageStoredRelationshipsRefreshInterval(usageStoredRelationshipsRefreshInterval time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.UsageStoredRelationshipsRefreshInterval = usageStoredRelationshipsRefreshInterval
+	}
+}
+
+// WithMetricsAPI returns an option that can set MetricsAPI on a Config
+func WithMetricsAPI(metricsAPI util.HTTPServerConfig) ConfigOption {
+	return func(c *Config) {
+		c.MetricsAPI = metricsAPI
+	}
+}
+
+// WithDebugAPI returns an option that can set DebugAPI on a Config
+func WithDebugAPI(debugAPI util.HTTPServerConfig) ConfigOption {
+	return func(c *Config) {
+		c.DebugAPI = debugAPI
+	}
+}
+
+// WithDebugLogOverrideMaxDuration returns an option that can set DebugLogOverrideMaxDuration on a Config
+func WithDebugLogOverrideMaxDuration(debugLogOverrideMaxDuration time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.DebugLogOverrideMaxDuration = debugLogOverrideMaxDuration
+	}
+}
+
+// WithGRPCMetricsLatencyBuckets returns an option that can append GRPCMetricsLatencyBucketss to Config.GRPCMetricsLatencyBuckets
+func WithGRPCMetricsLatencyBuckets(gRPCMetricsLatencyBuckets float64) ConfigOption {
+	return func(c *Config) {
+		c.GRPCMetricsLatencyBuckets = append(c.GRPCMetricsLatencyBuckets, gRPCMetricsLatencyBuckets)
+	}
+}
+
+// SetGRPCMetricsLatencyBuckets returns an option that can set GRPCMetricsLatencyBuckets on a Config
+func SetGRPCMetricsLatencyBuckets(gRPCMetricsLatencyBuckets []float64) ConfigOption {
+	return func(c *Config) {
+		c.GRPCMetricsLatencyBuckets = gRPCMetricsLatencyBuckets
+	}
+}
+
+// WithStatsDAddr returns an option that can set StatsDAddr on a Config
+func WithStatsDAddr(statsDAddr string) ConfigOption {
+	return func(c *Config) {
+		c.StatsDAddr = statsDAddr
+	}
+}
+
+// WithStatsDPrefix returns an option that can set StatsDPrefix on a Config
+func WithStatsDPrefix(statsDPrefix string) ConfigOption {
+	return func(c *Config) {
+		c.StatsDPrefix = statsDPrefix
+	}
+}
+
+// WithStatsDTags returns an option that can append StatsDTagss to Config.StatsDTags
+func WithStatsDTags(statsDTags string) ConfigOption {
+	return func(c *Config) {
+		c.StatsDTags = append(c.StatsDTags, statsDTags)
+	}
+}
+
+// SetStatsDTags returns an option that can set StatsDTags on a Config
+func SetStatsDTags(statsDTags []string) ConfigOption {
+	return func(c *Config) {
+		c.StatsDTags = statsDTags
+	}
+}
+
+// WithStatsDInterval returns an option that can set StatsDInterval on a Config
+func WithStatsDInterval(statsDInterval time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.StatsDInterval = statsDInterval
+	}
+}
+
+// WithChangefeedKafkaBrokers returns an option that can append ChangefeedKafkaBrokerss to Config.ChangefeedKafkaBrokers
+func WithChangefeedKafkaBrokers(changefeedKafkaBrokers string) ConfigOption {
+	return func(c *Config) {
+		c.ChangefeedKafkaBrokers = append(c.ChangefeedKafkaBrokers, changefeedKafkaBrokers)
+	}
+}
+
+// SetChangefeedKafkaBrokers returns an option that can set ChangefeedKafkaBrokers on a Config
+func SetChangefeedKafkaBrokers(changefeedKafkaBrokers []string) ConfigOption {
+	return func(c *Config) {
+		c.ChangefeedKafkaBrokers = changefeedKafkaBrokers
+	}
+}
+
+// WithChangefeedKafkaTopic returns an option that can set ChangefeedKafkaTopic on a Config
+func WithChangefeedKafkaTopic(changefeedKafkaTopic string) ConfigOption {
+	return func(c *Config) {
+		c.ChangefeedKafkaTopic = changefeedKafkaTopic
+	}
+}
+
+// WithChangefeedName returns an option that can set ChangefeedName on a Config
+func WithChangefeedName(changefeedName string) ConfigOption {
+	return func(c *Config) {
+		c.ChangefeedName = changefeedName
+	}
+}
+
+// WithChangefeedCheckpointInterval returns an option that can set ChangefeedCheckpointInterval on a Config
+func WithChangefeedCheckpointInterval(changefeedCheckpointInterval time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.ChangefeedCheckpointInterval = changefeedCheckpointInterval
+	}
+}
+
+// WithBackupBucketURL returns an option that can set BackupBucketURL on a Config
+func WithBackupBucketURL(backupBucketURL string) ConfigOption {
+	return func(c *Config) {
+		c.BackupBucketURL = backupBucketURL
+	}
+}
+
+// WithBackupInterval returns an option that can set BackupInterval on a Config
+func WithBackupInterval(backupInterval time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.BackupInterval = backupInterval
+	}
+}
+
+// WithBackupRetention returns an option that can set BackupRetention on a Config
+func WithBackupRetention(backupRetention int) ConfigOption {
+	return func(c *Config) {
+		c.BackupRetention = backupRetention
+	}
+}
+
+// WithBackupEncryptionKeyFile returns an option that can set BackupEncryptionKeyFile on a Config
+func WithBackupEncryptionKeyFile(backupEncryptionKeyFile string) ConfigOption {
+	return func(c *Config) {
+		c.BackupEncryptionKeyFile = backupEncryptionKeyFile
+	}
+}
+
+// WithAccessReportBucketURL returns an option that can set AccessReportBucketURL on a Config
+func WithAccessReportBucketURL(accessReportBucketURL string) ConfigOption {
+	return func(c *Config) {
+		c.AccessReportBucketURL = accessReportBucketURL
+	}
+}
+
+// WithAccessReportMaxRunning returns an option that can set AccessReportMaxRunning on a Config
+func WithAccessReportMaxRunning(accessReportMaxRunning int) ConfigOption {
+	return func(c *Config) {
+		c.AccessReportMaxRunning = accessReportMaxRunning
+	}
+}
+
+// WithLeaderElectionEnabled returns an option that can set LeaderElectionEnabled on a Config
+func WithLeaderElectionEnabled(leaderElectionEnabled bool) ConfigOption {
+	return func(c *Config) {
+		c.LeaderElectionEnabled = leaderElectionEnabled
+	}
+}
+
+// WithLeaderElectionNodeName returns an option that can set LeaderElectionNodeName on a Config
+func WithLeaderElectionNodeName(leaderElectionNodeName string) ConfigOption {
+	return func(c *Config) {
+		c.LeaderElectionNodeName = leaderElectionNodeName
+	}
+}
+
+// WithLeaderElectionLeaseDuration returns an option that can set LeaderElectionLeaseDuration on a Config
+func WithLeaderElectionLeaseDuration(leaderElectionLeaseDuration time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.LeaderElectionLeaseDuration = leaderElectionLeaseDuration
+	}
+}
+
+// WithUnaryMiddlewareModification returns an option that can append UnaryMiddlewareModifications to Config.UnaryMiddlewareModification
+func WithUnaryMiddlewareModification(unaryMiddlewareModification MiddlewareModification[grpc.UnaryServerInterceptor]) ConfigOption {
+	return func(c *Config) {
+		c.UnaryMiddlewareModification = append(c.UnaryMiddlewareModification, unaryMiddlewareModification)
+	}
+}
+
+// SetUnaryMiddlewareModification returns an option that can set UnaryMiddlewareModification on a Config
+func SetUnaryMiddlewareModification(unaryMiddlewareModification []MiddlewareModification[grpc.UnaryServerInterceptor]) ConfigOption {
+	return func(c *Config) {
+		c.UnaryMiddlewareModification = unaryMiddlewareModification
+	}
+}
+
+// WithStreamingMiddlewareModification returns an option that can append StreamingMiddlewareModifications to Config.StreamingMiddlewareModification
+func WithStreamingMiddlewareModification(streamingMiddlewareModification MiddlewareModification[grpc.StreamServerInterceptor]) ConfigOption {
+	return func(c *Config) {
+		c.StreamingMiddlewareModification = append(c.StreamingMiddlewareModification, streamingMiddlewareModification)
+	}
+}
+
+// SetStreamingMiddlewareModification returns an option that can set StreamingMiddlewareModification on a Config
+func SetStreamingMiddlewareModification(streamingMiddlewareModification []MiddlewareModification[grpc.StreamServerInterceptor]) ConfigOption {
+	return func(c *Config) {
+		c.StreamingMiddlewareModification = streamingMiddlewareModification
+	}
+}
+
+// WithDispatchUnaryMiddleware returns an option that can append DispatchUnaryMiddlewares to Config.DispatchUnaryMiddleware
+func WithDispatchUnaryMiddleware(dispatchUnaryMiddleware grpc.UnaryServerInterceptor) ConfigOption {
+	return func(c *Config) {
+		c.DispatchUnaryMiddleware = append(c.DispatchUnaryMiddleware, dispatchUnaryMiddleware)
+	}
+}
+
+// SetDispatchUnaryMiddleware returns an option that can set DispatchUnaryMiddleware on a Config
+func SetDispatchUnaryMiddleware(dispatchUnaryMiddleware []grpc.UnaryServerInterceptor) ConfigOption {
+	return func(c *Config) {
+		c.DispatchUnaryMiddleware = dispatchUnaryMiddleware
+	}
+}
+
+// WithDispatchStreamingMiddleware returns an option that can append DispatchStreamingMiddlewares to Config.DispatchStreamingMiddleware
+func WithDispatchStreamingMiddleware(dispatchStreamingMiddleware grpc.StreamServerInterceptor) ConfigOption {
+	return func(c *Config) {
+		c.DispatchStreamingMiddleware = append(c.DispatchStreamingMiddleware, dispatchStreamingMiddleware)
+	}
+}
+
+// SetDispatchStreamingMiddleware returns an option that can set DispatchStreamingMiddleware on a Config
+func SetDispatchStreamingMiddleware(dispatchStreamingMiddleware []grpc.StreamServerInterceptor) ConfigOption {
+	return func(c *Config) {
+		c.DispatchStreamingMiddleware = dispatchStreamingMiddleware
+	}
+}
+
+// WithSilentlyDisableTelemetry returns an option that can set SilentlyDisableTelemetry on a Config
+func WithSilentlyDisableTelemetry(silentlyDisableTelemetry bool) ConfigOption {
+	return func(c *Config) {
+		c.SilentlyDisableTelemetry = silentlyDisableTelemetry
+	}
+}
+
+// WithTelemetryCAOverridePath returns an option that can set TelemetryCAOverridePath on a Config
+func WithTelemetryCAOverridePath(telemetryCAOverridePath string) ConfigOption {
+	return func(c *Config) {
+		c.TelemetryCAOverridePath = telemetryCAOverridePath
+	}
+}
+
+// WithTelemetryEndpoint returns an option that can set TelemetryEndpoint on a Config
+func WithTelemetryEndpoint(telemetryEndpoint string) ConfigOption {
+	return func(c *Config) {
+		c.TelemetryEndpoint = telemetryEndpoint
 	}
 }
 
@@ -600,3 +1702,423 @@ func WithEnableResponseLogs(enableResponseLogs bool) ConfigOption {
 		c.EnableResponseLogs = enableResponseLogs
 	}
 }
+
+// WithLogSampleRates returns an option that can append LogSampleRatess to Config.LogSampleRates
+func WithLogSampleRates(key string, value string) ConfigOption {
+	return func(c *Config) {
+		c.LogSampleRates[key] = value
+	}
+}
+
+// SetLogSampleRates returns an option that can set LogSampleRates on a Config
+func SetLogSampleRates(logSampleRates map[string]string) ConfigOption {
+	return func(c *Config) {
+		c.LogSampleRates = logSampleRates
+	}
+}
+
+// WithRateLimitReadsPerSecond returns an option that can set RateLimitReadsPerSecond on a Config
+func WithRateLimitReadsPerSecond(rateLimitReadsPerSecond float64) ConfigOption {
+	return func(c *Config) {
+		c.RateLimitReadsPerSecond = rateLimitReadsPerSecond
+	}
+}
+
+// WithRateLimitReadBurst returns an option that can set RateLimitReadBurst on a Config
+func WithRateLimitReadBurst(rateLimitReadBurst int) ConfigOption {
+	return func(c *Config) {
+		c.RateLimitReadBurst = rateLimitReadBurst
+	}
+}
+
+// WithRateLimitWritesPerSecond returns an option that can set RateLimitWritesPerSecond on a Config
+func WithRateLimitWritesPerSecond(rateLimitWritesPerSecond float64) ConfigOption {
+	return func(c *Config) {
+		c.RateLimitWritesPerSecond = rateLimitWritesPerSecond
+	}
+}
+
+// WithRateLimitWriteBurst returns an option that can set RateLimitWriteBurst on a Config
+func WithRateLimitWriteBurst(rateLimitWriteBurst int) ConfigOption {
+	return func(c *Config) {
+		c.RateLimitWriteBurst = rateLimitWriteBurst
+	}
+}
+
+// WithMaxInFlightRequests returns an option that can set MaxInFlightRequests on a Config
+func WithMaxInFlightRequests(maxInFlightRequests uint32) ConfigOption {
+	return func(c *Config) {
+		c.MaxInFlightRequests = maxInFlightRequests
+	}
+}
+
+// WithMaxInFlightLowPriorityPercent returns an option that can set MaxInFlightLowPriorityPercent on a Config
+func WithMaxInFlightLowPriorityPercent(maxInFlightLowPriorityPercent uint32) ConfigOption {
+	return func(c *Config) {
+		c.MaxInFlightLowPriorityPercent = maxInFlightLowPriorityPercent
+	}
+}
+
+// WithMaxInFlightRetryAfter returns an option that can set MaxInFlightRetryAfter on a Config
+func WithMaxInFlightRetryAfter(maxInFlightRetryAfter time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.MaxInFlightRetryAfter = maxInFlightRetryAfter
+	}
+}
+
+// WithAuditLogSink returns an option that can set AuditLogSink on a Config
+func WithAuditLogSink(auditLogSink string) ConfigOption {
+	return func(c *Config) {
+		c.AuditLogSink = auditLogSink
+	}
+}
+
+// WithAuditLogFilePath returns an option that can set AuditLogFilePath on a Config
+func WithAuditLogFilePath(auditLogFilePath string) ConfigOption {
+	return func(c *Config) {
+		c.AuditLogFilePath = auditLogFilePath
+	}
+}
+
+// WithAuditLogFileMaxSizeMB returns an option that can set AuditLogFileMaxSizeMB on a Config
+func WithAuditLogFileMaxSizeMB(auditLogFileMaxSizeMB int) ConfigOption {
+	return func(c *Config) {
+		c.AuditLogFileMaxSizeMB = auditLogFileMaxSizeMB
+	}
+}
+
+// WithAuditLogFileMaxBackups returns an option that can set AuditLogFileMaxBackups on a Config
+func WithAuditLogFileMaxBackups(auditLogFileMaxBackups int) ConfigOption {
+	return func(c *Config) {
+		c.AuditLogFileMaxBackups = auditLogFileMaxBackups
+	}
+}
+
+// WithAuditLogHTTPEndpoint returns an option that can set AuditLogHTTPEndpoint on a Config
+func WithAuditLogHTTPEndpoint(auditLogHTTPEndpoint string) ConfigOption {
+	return func(c *Config) {
+		c.AuditLogHTTPEndpoint = auditLogHTTPEndpoint
+	}
+}
+
+// WithAuditLogHTTPTimeout returns an option that can set AuditLogHTTPTimeout on a Config
+func WithAuditLogHTTPTimeout(auditLogHTTPTimeout time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.AuditLogHTTPTimeout = auditLogHTTPTimeout
+	}
+}
+
+// WithDecisionLogSink returns an option that can set DecisionLogSink on a Config
+func WithDecisionLogSink(decisionLogSink string) ConfigOption {
+	return func(c *Config) {
+		c.DecisionLogSink = decisionLogSink
+	}
+}
+
+// WithDecisionLogHTTPEndpoint returns an option that can set DecisionLogHTTPEndpoint on a Config
+func WithDecisionLogHTTPEndpoint(decisionLogHTTPEndpoint string) ConfigOption {
+	return func(c *Config) {
+		c.DecisionLogHTTPEndpoint = decisionLogHTTPEndpoint
+	}
+}
+
+// WithDecisionLogUploadInterval returns an option that can set DecisionLogUploadInterval on a Config
+func WithDecisionLogUploadInterval(decisionLogUploadInterval time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.DecisionLogUploadInterval = decisionLogUploadInterval
+	}
+}
+
+// WithDecisionLogBatchSize returns an option that can set DecisionLogBatchSize on a Config
+func WithDecisionLogBatchSize(decisionLogBatchSize int) ConfigOption {
+	return func(c *Config) {
+		c.DecisionLogBatchSize = decisionLogBatchSize
+	}
+}
+
+// WithDecisionLogBufferSize returns an option that can set DecisionLogBufferSize on a Config
+func WithDecisionLogBufferSize(decisionLogBufferSize int) ConfigOption {
+	return func(c *Config) {
+		c.DecisionLogBufferSize = decisionLogBufferSize
+	}
+}
+
+// WithDecisionLogHTTPTimeout returns an option that can set DecisionLogHTTPTimeout on a Config
+func WithDecisionLogHTTPTimeout(decisionLogHTTPTimeout time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.DecisionLogHTTPTimeout = decisionLogHTTPTimeout
+	}
+}
+
+// WithShadowCheckSampleRate returns an option that can set ShadowCheckSampleRate on a Config
+func WithShadowCheckSampleRate(shadowCheckSampleRate float64) ConfigOption {
+	return func(c *Config) {
+		c.ShadowCheckSampleRate = shadowCheckSampleRate
+	}
+}
+
+// WithShadowCheckSchemaFile returns an option that can set ShadowCheckSchemaFile on a Config
+func WithShadowCheckSchemaFile(shadowCheckSchemaFile string) ConfigOption {
+	return func(c *Config) {
+		c.ShadowCheckSchemaFile = shadowCheckSchemaFile
+	}
+}
+
+// WithShadowCheckEndpoint returns an option that can set ShadowCheckEndpoint on a Config
+func WithShadowCheckEndpoint(shadowCheckEndpoint string) ConfigOption {
+	return func(c *Config) {
+		c.ShadowCheckEndpoint = shadowCheckEndpoint
+	}
+}
+
+// WithShadowCheckToken returns an option that can set ShadowCheckToken on a Config
+func WithShadowCheckToken(shadowCheckToken string) ConfigOption {
+	return func(c *Config) {
+		c.ShadowCheckToken = shadowCheckToken
+	}
+}
+
+// WithShadowCheckInsecure returns an option that can set ShadowCheckInsecure on a Config
+func WithShadowCheckInsecure(shadowCheckInsecure bool) ConfigOption {
+	return func(c *Config) {
+		c.ShadowCheckInsecure = shadowCheckInsecure
+	}
+}
+
+// WithShadowCheckCAPath returns an option that can set ShadowCheckCAPath on a Config
+func WithShadowCheckCAPath(shadowCheckCAPath string) ConfigOption {
+	return func(c *Config) {
+		c.ShadowCheckCAPath = shadowCheckCAPath
+	}
+}
+
+// WithShadowCheckExactSnapshot returns an option that can set ShadowCheckExactSnapshot on a Config
+func WithShadowCheckExactSnapshot(shadowCheckExactSnapshot bool) ConfigOption {
+	return func(c *Config) {
+		c.ShadowCheckExactSnapshot = shadowCheckExactSnapshot
+	}
+}
+
+// WithShadowCheckMaxInFlight returns an option that can set ShadowCheckMaxInFlight on a Config
+func WithShadowCheckMaxInFlight(shadowCheckMaxInFlight uint32) ConfigOption {
+	return func(c *Config) {
+		c.ShadowCheckMaxInFlight = shadowCheckMaxInFlight
+	}
+}
+
+// WithShadowCheckTimeout returns an option that can set ShadowCheckTimeout on a Config
+func WithShadowCheckTimeout(shadowCheckTimeout time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.ShadowCheckTimeout = shadowCheckTimeout
+	}
+}
+
+// WithFederationRemoteNamespaces returns an option that can append FederationRemoteNamespacess to Config.FederationRemoteNamespaces
+func WithFederationRemoteNamespaces(key string, value string) ConfigOption {
+	return func(c *Config) {
+		c.FederationRemoteNamespaces[key] = value
+	}
+}
+
+// SetFederationRemoteNamespaces returns an option that can set FederationRemoteNamespaces on a Config
+func SetFederationRemoteNamespaces(federationRemoteNamespaces map[string]string) ConfigOption {
+	return func(c *Config) {
+		c.FederationRemoteNamespaces = federationRemoteNamespaces
+	}
+}
+
+// WithFederationToken returns an option that can set FederationToken on a Config
+func WithFederationToken(federationToken string) ConfigOption {
+	return func(c *Config) {
+		c.FederationToken = federationToken
+	}
+}
+
+// WithFederationInsecure returns an option that can set FederationInsecure on a Config
+func WithFederationInsecure(federationInsecure bool) ConfigOption {
+	return func(c *Config) {
+		c.FederationInsecure = federationInsecure
+	}
+}
+
+// WithFederationCAPath returns an option that can set FederationCAPath on a Config
+func WithFederationCAPath(federationCAPath string) ConfigOption {
+	return func(c *Config) {
+		c.FederationCAPath = federationCAPath
+	}
+}
+
+// WithFederationMaxStaleness returns an option that can set FederationMaxStaleness on a Config
+func WithFederationMaxStaleness(federationMaxStaleness time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.FederationMaxStaleness = federationMaxStaleness
+	}
+}
+
+// WithFederationTimeout returns an option that can set FederationTimeout on a Config
+func WithFederationTimeout(federationTimeout time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.FederationTimeout = federationTimeout
+	}
+}
+
+// WithRelationshipTransforms returns an option that can append RelationshipTransformss to Config.RelationshipTransforms
+func WithRelationshipTransforms(key string, value string) ConfigOption {
+	return func(c *Config) {
+		c.RelationshipTransforms[key] = value
+	}
+}
+
+// SetRelationshipTransforms returns an option that can set RelationshipTransforms on a Config
+func SetRelationshipTransforms(relationshipTransforms map[string]string) ConfigOption {
+	return func(c *Config) {
+		c.RelationshipTransforms = relationshipTransforms
+	}
+}
+
+// WithSchemaWebhookURLs returns an option that can append SchemaWebhookURLss to Config.SchemaWebhookURLs
+func WithSchemaWebhookURLs(schemaWebhookURLs string) ConfigOption {
+	return func(c *Config) {
+		c.SchemaWebhookURLs = append(c.SchemaWebhookURLs, schemaWebhookURLs)
+	}
+}
+
+// SetSchemaWebhookURLs returns an option that can set SchemaWebhookURLs on a Config
+func SetSchemaWebhookURLs(schemaWebhookURLs []string) ConfigOption {
+	return func(c *Config) {
+		c.SchemaWebhookURLs = schemaWebhookURLs
+	}
+}
+
+// WithSchemaWebhookSecret returns an option that can set SchemaWebhookSecret on a Config
+func WithSchemaWebhookSecret(schemaWebhookSecret string) ConfigOption {
+	return func(c *Config) {
+		c.SchemaWebhookSecret = schemaWebhookSecret
+	}
+}
+
+// WithSchemaWebhookTimeout returns an option that can set SchemaWebhookTimeout on a Config
+func WithSchemaWebhookTimeout(schemaWebhookTimeout time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.SchemaWebhookTimeout = schemaWebhookTimeout
+	}
+}
+
+// WithSchemaWebhookMaxAttempts returns an option that can set SchemaWebhookMaxAttempts on a Config
+func WithSchemaWebhookMaxAttempts(schemaWebhookMaxAttempts int) ConfigOption {
+	return func(c *Config) {
+		c.SchemaWebhookMaxAttempts = schemaWebhookMaxAttempts
+	}
+}
+
+// WithExtAuthzRulesetPath returns an option that can set ExtAuthzRulesetPath on a Config
+func WithExtAuthzRulesetPath(extAuthzRulesetPath string) ConfigOption {
+	return func(c *Config) {
+		c.ExtAuthzRulesetPath = extAuthzRulesetPath
+	}
+}
+
+// WithVaultAddress returns an option that can set VaultAddress on a Config
+func WithVaultAddress(vaultAddress string) ConfigOption {
+	return func(c *Config) {
+		c.VaultAddress = vaultAddress
+	}
+}
+
+// WithVaultAuthMethod returns an option that can set VaultAuthMethod on a Config
+func WithVaultAuthMethod(vaultAuthMethod string) ConfigOption {
+	return func(c *Config) {
+		c.VaultAuthMethod = vaultAuthMethod
+	}
+}
+
+// WithVaultAuthMount returns an option that can set VaultAuthMount on a Config
+func WithVaultAuthMount(vaultAuthMount string) ConfigOption {
+	return func(c *Config) {
+		c.VaultAuthMount = vaultAuthMount
+	}
+}
+
+// WithVaultToken returns an option that can set VaultToken on a Config
+func WithVaultToken(vaultToken string) ConfigOption {
+	return func(c *Config) {
+		c.VaultToken = vaultToken
+	}
+}
+
+// WithVaultRole returns an option that can set VaultRole on a Config
+func WithVaultRole(vaultRole string) ConfigOption {
+	return func(c *Config) {
+		c.VaultRole = vaultRole
+	}
+}
+
+// WithVaultSecretID returns an option that can set VaultSecretID on a Config
+func WithVaultSecretID(vaultSecretID string) ConfigOption {
+	return func(c *Config) {
+		c.VaultSecretID = vaultSecretID
+	}
+}
+
+// WithVaultKubernetesTokenPath returns an option that can set VaultKubernetesTokenPath on a Config
+func WithVaultKubernetesTokenPath(vaultKubernetesTokenPath string) ConfigOption {
+	return func(c *Config) {
+		c.VaultKubernetesTokenPath = vaultKubernetesTokenPath
+	}
+}
+
+// WithVaultTLSPath returns an option that can set VaultTLSPath on a Config
+func WithVaultTLSPath(vaultTLSPath string) ConfigOption {
+	return func(c *Config) {
+		c.VaultTLSPath = vaultTLSPath
+	}
+}
+
+// WithVaultTLSCommonName returns an option that can set VaultTLSCommonName on a Config
+func WithVaultTLSCommonName(vaultTLSCommonName string) ConfigOption {
+	return func(c *Config) {
+		c.VaultTLSCommonName = vaultTLSCommonName
+	}
+}
+
+// WithVaultPresharedKeysPath returns an option that can set VaultPresharedKeysPath on a Config
+func WithVaultPresharedKeysPath(vaultPresharedKeysPath string) ConfigOption {
+	return func(c *Config) {
+		c.VaultPresharedKeysPath = vaultPresharedKeysPath
+	}
+}
+
+// WithVaultRefreshInterval returns an option that can set VaultRefreshInterval on a Config
+func WithVaultRefreshInterval(vaultRefreshInterval time.Duration) ConfigOption {
+	return func(c *Config) {
+		c.VaultRefreshInterval = vaultRefreshInterval
+	}
+}
+
+// WithErrorReportingDSN returns an option that can set ErrorReportingDSN on a Config
+func WithErrorReportingDSN(errorReportingDSN string) ConfigOption {
+	return func(c *Config) {
+		c.ErrorReportingDSN = errorReportingDSN
+	}
+}
+
+// WithErrorReportingEnvironment returns an option that can set ErrorReportingEnvironment on a Config
+func WithErrorReportingEnvironment(errorReportingEnvironment string) ConfigOption {
+	return func(c *Config) {
+		c.ErrorReportingEnvironment = errorReportingEnvironment
+	}
+}
+
+// WithErrorReportingSampleRate returns an option that can set ErrorReportingSampleRate on a Config
+func WithErrorReportingSampleRate(errorReportingSampleRate float64) ConfigOption {
+	return func(c *Config) {
+		c.ErrorReportingSampleRate = errorReportingSampleRate
+	}
+}
+
+// WithReloadConfigPath returns an option that can set ReloadConfigPath on a Config
+func WithReloadConfigPath(reloadConfigPath string) ConfigOption {
+	return func(c *Config) {
+		c.ReloadConfigPath = reloadConfigPath
+	}
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/spf13/cobra"
+
+	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/pkg/cmd/datastore"
+	"github.com/authzed/spicedb/pkg/cmd/server"
+)
+
+const skipTLSFlag = "skip-tls"
+
+const skipTLSWarning = `
+!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!
+!!                                                                            !!
+!!  --skip-tls is a DEVELOPMENT preset. Every endpoint is served WITHOUT TLS, !!
+!!  so preshared keys and data cross the network in plain text.               !!
+!!  NEVER USE IT IN PRODUCTION.                                               !!
+!!                                                                            !!
+!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!!
+`
+
+// applySkipTLSPreset configures the server for local development as a single binary: TLS is
+// refused, the endpoints whose address was not given are bound to localhost rather than to
+// every interface, and the memory datastore is persisted to the platform's default data path
+// unless another is given.
+func applySkipTLSPreset(cmd *cobra.Command, config *server.Config, warnings io.Writer) error {
+	for flag, path := range map[string]string{
+		"grpc-tls-cert-path":             config.GRPCServer.TLSCertPath,
+		"dispatch-cluster-tls-cert-path": config.DispatchServer.TLSCertPath,
+		"http-tls-cert-path":             config.HTTPGateway.HTTPTLSCertPath,
+		"grpc-web-tls-cert-path":         config.GRPCWeb.HTTPTLSCertPath,
+		"metrics-tls-cert-path":          config.MetricsAPI.HTTPTLSCertPath,
+		"debug-tls-cert-path":            config.DebugAPI.HTTPTLSCertPath,
+		"vault-tls-path":                 config.VaultTLSPath,
+	} {
+		if path != "" {
+			return fmt.Errorf("--%s cannot be combined with --%s", skipTLSFlag, flag)
+		}
+	}
+
+	for flag, address := range map[string]*string{
+		"grpc-addr":             &config.GRPCServer.Address,
+		"dispatch-cluster-addr": &config.DispatchServer.Address,
+		"http-addr":             &config.HTTPGateway.HTTPAddress,
+		"grpc-web-addr":         &config.GRPCWeb.HTTPAddress,
+		"metrics-addr":          &config.MetricsAPI.HTTPAddress,
+	} {
+		if !cmd.Flags().Changed(flag) {
+			*address = localhostAddress(*address)
+		}
+	}
+
+	if config.DatastoreConfig.Engine == datastore.MemoryEngine && config.DatastoreConfig.MemoryDataPath == "" {
+		path, err := datastore.DefaultMemoryDataPath()
+		if err != nil {
+			return fmt.Errorf("failed to determine the default path of the memory datastore: %w", err)
+		}
+		config.DatastoreConfig.MemoryDataPath = path
+	}
+
+	fmt.Fprint(warnings, skipTLSWarning)
+	log.Warn().
+		Str("grpcAddress", config.GRPCServer.Address).
+		Str("memoryDataPath", config.DatastoreConfig.MemoryDataPath).
+		Msg("serving without TLS for development; never use --skip-tls in production")
+	return nil
+}
+
+// localhostAddress returns the address bound to localhost if it does not name a host.
+func localhostAddress(address string) string {
+	host, port, err := net.SplitHostPort(address)
+	if err != nil || host != "" {
+		return address
+	}
+	return net.JoinHostPort("localhost", port)
+}
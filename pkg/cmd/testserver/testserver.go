@@ -65,13 +65,14 @@ func (c *Config) Complete() (RunnableTestServer, error) {
 			dispatcher,
 			services.V1SchemaServiceEnabled,
 			services.WatchServiceEnabled,
+			services.ReflectionEnabled,
 			v1svc.PermissionsServerConfig{
 				MaxPreconditionsCount: c.MaximumPreconditionCount,
 				MaxUpdatesPerWrite:    c.MaximumUpdatesPerWrite,
 				MaximumAPIDepth:       maxDepth,
 				MaxCaveatContextSize:  c.MaxCaveatContextSize,
 			},
-			1*time.Second,
+			v1svc.WatchServerConfig{HeartbeatDuration: 1 * time.Second},
 		)
 	}
 	gRPCSrv, err := c.GRPCServer.Complete(zerolog.InfoLevel, registerServices,
@@ -112,7 +113,12 @@ func (c *Config) Complete() (RunnableTestServer, error) {
 		return nil, err
 	}
 
-	gatewayHandler, err := gateway.NewHandler(context.TODO(), c.GRPCServer.Address, c.GRPCServer.TLSCertPath)
+	clientCert, err := c.GRPCServer.ClientCertificate()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize rest gateway: %w", err)
+	}
+
+	gatewayHandler, err := gateway.NewHandler(context.TODO(), c.GRPCServer.DialTarget(), c.GRPCServer.TLSCertPath, false, clientCert)
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed to initialize rest gateway")
 	}
@@ -126,7 +132,12 @@ func (c *Config) Complete() (RunnableTestServer, error) {
 		return nil, fmt.Errorf("failed to initialize rest gateway: %w", err)
 	}
 
-	readOnlyGatewayHandler, err := gateway.NewHandler(context.TODO(), c.ReadOnlyGRPCServer.Address, c.ReadOnlyGRPCServer.TLSCertPath)
+	readOnlyClientCert, err := c.ReadOnlyGRPCServer.ClientCertificate()
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize rest gateway: %w", err)
+	}
+
+	readOnlyGatewayHandler, err := gateway.NewHandler(context.TODO(), c.ReadOnlyGRPCServer.DialTarget(), c.ReadOnlyGRPCServer.TLSCertPath, false, readOnlyClientCert)
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed to initialize rest gateway")
 	}
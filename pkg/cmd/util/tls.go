@@ -0,0 +1,87 @@
+package util
+
+import (
+	"crypto/tls"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DefaultTLSMinVersion is the minimum TLS version served when none is configured.
+const DefaultTLSMinVersion = "1.2"
+
+var tlsVersions = map[string]uint16{
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
+}
+
+var tlsCurves = map[string]tls.CurveID{
+	"X25519": tls.X25519,
+	"P256":   tls.CurveP256,
+	"P384":   tls.CurveP384,
+	"P521":   tls.CurveP521,
+}
+
+// tlsPolicy is the TLS versions, cipher suites and curves allowed by a server.
+type tlsPolicy struct {
+	minVersion   uint16
+	cipherSuites []uint16
+	curves       []tls.CurveID
+}
+
+// parseTLSPolicy parses the minimum TLS version, cipher suites and curve preferences configured
+// for the server with the given flag prefix. The cipher suites must be among the secure suites
+// implemented by Go, and can only be configured for TLS 1.2, since those of TLS 1.3 are fixed.
+func parseTLSPolicy(flagPrefix, minVersion string, cipherSuites, curves []string) (tlsPolicy, error) {
+	if minVersion == "" {
+		minVersion = DefaultTLSMinVersion
+	}
+
+	version, ok := tlsVersions[minVersion]
+	if !ok {
+		return tlsPolicy{}, fmt.Errorf("invalid --%s-tls-min-version `%s`: must be one of %s", flagPrefix, minVersion, strings.Join(sortedKeys(tlsVersions), ", "))
+	}
+	policy := tlsPolicy{minVersion: version}
+
+	if len(cipherSuites) > 0 && version == tls.VersionTLS13 {
+		return tlsPolicy{}, fmt.Errorf("--%s-tls-cipher-suites cannot be set with a minimum TLS version of 1.3, whose cipher suites are not configurable", flagPrefix)
+	}
+
+	suitesByName := map[string]uint16{}
+	for _, suite := range tls.CipherSuites() {
+		suitesByName[suite.Name] = suite.ID
+	}
+	for _, name := range cipherSuites {
+		id, ok := suitesByName[name]
+		if !ok {
+			return tlsPolicy{}, fmt.Errorf("invalid --%s-tls-cipher-suites `%s`: must be one of %s", flagPrefix, name, strings.Join(sortedKeys(suitesByName), ", "))
+		}
+		policy.cipherSuites = append(policy.cipherSuites, id)
+	}
+
+	for _, name := range curves {
+		id, ok := tlsCurves[strings.ToUpper(name)]
+		if !ok {
+			return tlsPolicy{}, fmt.Errorf("invalid --%s-tls-curve-preferences `%s`: must be one of %s", flagPrefix, name, strings.Join(sortedKeys(tlsCurves), ", "))
+		}
+		policy.curves = append(policy.curves, id)
+	}
+
+	return policy, nil
+}
+
+// apply restricts the TLS config to the policy.
+func (p tlsPolicy) apply(config *tls.Config) {
+	config.MinVersion = p.minVersion
+	config.CipherSuites = p.cipherSuites
+	config.CurvePreferences = p.curves
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
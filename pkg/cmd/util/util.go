@@ -8,8 +8,12 @@ import (
 	"crypto/x509"
 	"errors"
 	"fmt"
+	"io/fs"
 	"net"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/jzelinskie/stringz"
@@ -30,10 +34,15 @@ import (
 	_ "sigs.k8s.io/controller-runtime/pkg/certwatcher/metrics"
 
 	log "github.com/authzed/spicedb/internal/logging"
+	"github.com/authzed/spicedb/internal/middleware/compression"
+	"github.com/authzed/spicedb/internal/middleware/netacl"
 	"github.com/authzed/spicedb/pkg/x509util"
 )
 
-const BufferedNetwork string = "buffnet"
+const (
+	BufferedNetwork string = "buffnet"
+	UnixNetwork     string = "unix"
+)
 
 type GRPCServerConfig struct {
 	Address      string        `debugmap:"visible"`
@@ -46,6 +55,52 @@ type GRPCServerConfig struct {
 	ClientCAPath string        `debugmap:"visible"`
 	MaxWorkers   uint32        `debugmap:"visible"`
 
+	// Connection management. A zero duration uses the gRPC default.
+	MaxConnAgeGrace            time.Duration `debugmap:"visible"`
+	MaxConnIdle                time.Duration `debugmap:"visible"`
+	KeepaliveTime              time.Duration `debugmap:"visible"`
+	KeepaliveTimeout           time.Duration `debugmap:"visible"`
+	KeepaliveMinTime           time.Duration `debugmap:"visible"`
+	KeepalivePermitWithoutCall bool          `debugmap:"visible"`
+
+	// MaxConcurrentStreams caps the number of concurrent streams, and therefore calls,
+	// on each client connection. Zero uses the gRPC default.
+	MaxConcurrentStreams uint32 `debugmap:"visible"`
+
+	// MutualTLSCAPath is the path of a CA bundle against which client certificates are
+	// verified. If set, clients are required to present a valid certificate. It is unrelated
+	// to ClientCAPath, the bundle with which in-process clients verify the server.
+	MutualTLSCAPath string `debugmap:"visible"`
+
+	// ResponseCompression is the list of compressors, in order of preference, with which
+	// responses are sent to clients accepting them. If empty, responses are compressed with
+	// the compressor of their request.
+	ResponseCompression []string `debugmap:"visible"`
+
+	// TLSMinVersion is the minimum TLS version served ("1.2" or "1.3"). If empty, it is
+	// DefaultTLSMinVersion.
+	TLSMinVersion string `debugmap:"visible"`
+
+	// TLSCipherSuites are the names of the cipher suites allowed for TLS 1.2. If empty, Go's
+	// default suites are allowed.
+	TLSCipherSuites []string `debugmap:"visible"`
+
+	// TLSCurvePreferences are the names of the elliptic curves allowed for key exchange, in
+	// order of preference. If empty, Go's default curves are allowed.
+	TLSCurvePreferences []string `debugmap:"visible"`
+
+	// AllowedCIDRs are the networks from which calls are served. If empty, calls from every
+	// network not denied are served. Calls over unix sockets and in-memory connections are
+	// always served; those proxied by the HTTP gateway come from the gateway's own address.
+	AllowedCIDRs []string `debugmap:"visible"`
+
+	// DeniedCIDRs are the networks from which calls are rejected, even if they are allowed.
+	DeniedCIDRs []string `debugmap:"visible"`
+
+	// GetCertificate, if set, returns the TLS certificate used to serve, instead of the one
+	// at TLSCertPath and TLSKeyPath, so that it can be rotated in memory.
+	GetCertificate func(*tls.ClientHelloInfo) (*tls.Certificate, error) `debugmap:"hidden"`
+
 	flagPrefix string
 }
 
@@ -62,12 +117,34 @@ func RegisterGRPCServerFlags(flags *pflag.FlagSet, config *GRPCServerConfig, fla
 	config.flagPrefix = flagPrefix
 
 	flags.StringVar(&config.Address, flagPrefix+"-addr", defaultAddr, "address to listen on to serve "+serviceName)
-	flags.StringVar(&config.Network, flagPrefix+"-network", "tcp", "network type to serve "+serviceName+` ("tcp", "tcp4", "tcp6", "unix", "unixpacket")`)
+	flags.StringVar(&config.Network, flagPrefix+"-network", "tcp", "network type to serve "+serviceName+` ("tcp", "tcp4", "tcp6", "unix", "unixpacket"); with "unix", the address is the path of the socket`)
 	flags.StringVar(&config.TLSCertPath, flagPrefix+"-tls-cert-path", "", "local path to the TLS certificate used to serve "+serviceName)
 	flags.StringVar(&config.TLSKeyPath, flagPrefix+"-tls-key-path", "", "local path to the TLS key used to serve "+serviceName)
 	flags.DurationVar(&config.MaxConnAge, flagPrefix+"-max-conn-age", 30*time.Second, "how long a connection serving "+serviceName+" should be able to live")
+	flags.DurationVar(&config.MaxConnAgeGrace, flagPrefix+"-max-conn-age-grace", 0, "how long in-flight calls on a connection serving "+serviceName+" may run after it reaches its max age before it is forcibly closed (0 means indefinitely)")
+	flags.DurationVar(&config.MaxConnIdle, flagPrefix+"-max-conn-idle", 0, "how long a connection serving "+serviceName+" may be idle before it is closed (0 means indefinitely)")
+	flags.DurationVar(&config.KeepaliveTime, flagPrefix+"-keepalive-time", 0, "how long a connection serving "+serviceName+" may be inactive before the server pings the client (0 means the gRPC default of 2h)")
+	flags.DurationVar(&config.KeepaliveTimeout, flagPrefix+"-keepalive-timeout", 0, "how long the server waits for a keepalive ping to be acknowledged before closing the connection (0 means the gRPC default of 20s)")
+	flags.DurationVar(&config.KeepaliveMinTime, flagPrefix+"-keepalive-min-time", 0, "minimum time clients of "+serviceName+" should wait between keepalive pings; connections of clients pinging more often are closed (0 means the gRPC default of 5m)")
+	flags.BoolVar(&config.KeepalivePermitWithoutCall, flagPrefix+"-keepalive-permit-without-call", false, "allow clients of "+serviceName+" to send keepalive pings when there are no active calls")
 	flags.BoolVar(&config.Enabled, flagPrefix+"-enabled", defaultEnabled, "enable "+serviceName+" gRPC server")
 	flags.Uint32Var(&config.MaxWorkers, flagPrefix+"-max-workers", 0, "set the number of workers for this server (0 value means 1 worker per request)")
+	flags.Uint32Var(&config.MaxConcurrentStreams, flagPrefix+"-max-concurrent-streams", 0, "maximum number of concurrent streams on each client connection to "+serviceName+" (0 value means the gRPC default)")
+	flags.StringSliceVar(&config.ResponseCompression, flagPrefix+"-response-compression", nil, `compressors, in order of preference, with which responses of `+serviceName+` are sent to clients accepting them ("gzip", "zstd", "snappy", "s2"); if empty, responses are compressed only if their request is`)
+	flags.StringVar(&config.MutualTLSCAPath, flagPrefix+"-client-ca-path", "", "local path to a CA bundle used to verify client certificates; if set, "+serviceName+" requires clients to authenticate with mutual TLS")
+	registerTLSPolicyFlags(flags, &config.TLSMinVersion, &config.TLSCipherSuites, &config.TLSCurvePreferences, flagPrefix, serviceName)
+	registerNetworkACLFlags(flags, &config.AllowedCIDRs, &config.DeniedCIDRs, flagPrefix, serviceName)
+}
+
+func registerTLSPolicyFlags(flags *pflag.FlagSet, minVersion *string, cipherSuites, curves *[]string, flagPrefix, serviceName string) {
+	flags.StringVar(minVersion, flagPrefix+"-tls-min-version", DefaultTLSMinVersion, `minimum TLS version used to serve `+serviceName+` ("1.2", "1.3")`)
+	flags.StringSliceVar(cipherSuites, flagPrefix+"-tls-cipher-suites", nil, "cipher suites allowed for TLS 1.2 connections serving "+serviceName+", by their IANA names (e.g. TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256); if empty, Go's defaults are allowed")
+	flags.StringSliceVar(curves, flagPrefix+"-tls-curve-preferences", nil, `elliptic curves allowed for key exchange in TLS connections serving `+serviceName+`, in order of preference ("X25519", "P256", "P384", "P521"); if empty, Go's defaults are allowed`)
+}
+
+func registerNetworkACLFlags(flags *pflag.FlagSet, allowed, denied *[]string, flagPrefix, serviceName string) {
+	flags.StringSliceVar(allowed, flagPrefix+"-allowed-cidrs", nil, "networks, in CIDR notation, from which requests to "+serviceName+" are served; if empty, requests from every network not denied are served")
+	flags.StringSliceVar(denied, flagPrefix+"-denied-cidrs", nil, "networks, in CIDR notation, from which requests to "+serviceName+" are rejected, even if they are within an allowed network")
 }
 
 type (
@@ -84,8 +161,38 @@ func (c *GRPCServerConfig) Complete(level zerolog.Level, svcRegistrationFn func(
 		c.BufferSize = 1024 * 1024
 	}
 	opts = append(opts, grpc.KeepaliveParams(keepalive.ServerParameters{
-		MaxConnectionAge: c.MaxConnAge,
+		MaxConnectionAge:      c.MaxConnAge,
+		MaxConnectionAgeGrace: c.MaxConnAgeGrace,
+		MaxConnectionIdle:     c.MaxConnIdle,
+		Time:                  c.KeepaliveTime,
+		Timeout:               c.KeepaliveTimeout,
+	}), grpc.KeepaliveEnforcementPolicy(keepalive.EnforcementPolicy{
+		MinTime:             c.KeepaliveMinTime,
+		PermitWithoutStream: c.KeepalivePermitWithoutCall,
 	}), grpc.NumStreamWorkers(c.MaxWorkers))
+	acl, err := netacl.New(c.flagPrefix, c.AllowedCIDRs, c.DeniedCIDRs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid network access control for %s: %w", c.flagPrefix, err)
+	}
+	if acl != nil {
+		// Peers are checked before any other middleware, and so before they authenticate.
+		opts = append([]grpc.ServerOption{
+			grpc.ChainUnaryInterceptor(netacl.UnaryServerInterceptor(acl)),
+			grpc.ChainStreamInterceptor(netacl.StreamServerInterceptor(acl)),
+		}, opts...)
+	}
+	if c.MaxConcurrentStreams > 0 {
+		opts = append(opts, grpc.MaxConcurrentStreams(c.MaxConcurrentStreams))
+	}
+	if len(c.ResponseCompression) > 0 {
+		if err := compression.ValidateCompressors(c.ResponseCompression); err != nil {
+			return nil, fmt.Errorf("invalid response compression for %s: %w", c.flagPrefix, err)
+		}
+		opts = append(opts,
+			grpc.ChainUnaryInterceptor(compression.UnaryServerInterceptor(c.ResponseCompression)),
+			grpc.ChainStreamInterceptor(compression.StreamServerInterceptor(c.ResponseCompression)),
+		)
+	}
 
 	tlsOpts, certWatcher, err := c.tlsOpts()
 	if err != nil {
@@ -107,7 +214,7 @@ func (c *GRPCServerConfig) Complete(level zerolog.Level, svcRegistrationFn func(
 		Str("network", c.Network).
 		Str("service", c.flagPrefix).
 		Uint32("workers", c.MaxWorkers).
-		Bool("insecure", c.TLSCertPath == "" && c.TLSKeyPath == "").
+		Bool("insecure", !c.TLSEnabled()).
 		Msg("grpc server started serving")
 
 	srv := grpc.NewServer(opts...)
@@ -128,9 +235,10 @@ func (c *GRPCServerConfig) Complete(level zerolog.Level, svcRegistrationFn func(
 				Str("service", c.flagPrefix).
 				Msg("grpc server stopped serving")
 		},
-		stopFunc:    srv.GracefulStop,
-		creds:       clientCreds,
-		certWatcher: certWatcher,
+		stopFunc:      srv.GracefulStop,
+		forceStopFunc: srv.Stop,
+		creds:         clientCreds,
+		certWatcher:   certWatcher,
 	}, nil
 }
 
@@ -147,17 +255,69 @@ func (c *GRPCServerConfig) listenerAndDialer() (net.Listener, DialFunc, NetDialF
 				return bl.DialContext(ctx)
 			}, nil
 	}
+	if c.Network == UnixNetwork {
+		if err := removeStaleUnixSocket(c.Address); err != nil {
+			return nil, nil, nil, err
+		}
+	}
 	l, err := net.Listen(c.Network, c.Address)
 	if err != nil {
 		return nil, nil, nil, err
 	}
 	return l, func(ctx context.Context, opts ...grpc.DialOption) (*grpc.ClientConn, error) {
-		return grpc.DialContext(ctx, c.Address, opts...)
+		return grpc.DialContext(ctx, c.DialTarget(), opts...)
 	}, nil, nil
 }
 
+// DialTarget returns the target that gRPC clients should dial to reach the server.
+func (c *GRPCServerConfig) DialTarget() string {
+	if c.Network == UnixNetwork {
+		return "unix:" + c.Address
+	}
+	return c.Address
+}
+
+// removeStaleUnixSocket removes a socket file left behind by a server that did not shut
+// down cleanly. Sockets on which a server is still listening are left in place.
+func removeStaleUnixSocket(path string) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	if info.Mode()&fs.ModeSocket == 0 {
+		return fmt.Errorf("cannot listen on %s: file exists and is not a socket", path)
+	}
+
+	conn, err := net.Dial(UnixNetwork, path)
+	if err == nil {
+		conn.Close()
+		return nil
+	}
+
+	log.Info().Str("path", path).Msg("removing stale unix socket")
+	return os.Remove(path)
+}
+
+// TLSEnabled returns whether the server is served over TLS.
+func (c *GRPCServerConfig) TLSEnabled() bool {
+	return c.GetCertificate != nil || (c.TLSCertPath != "" && c.TLSKeyPath != "")
+}
+
 func (c *GRPCServerConfig) tlsOpts() ([]grpc.ServerOption, *certwatcher.CertWatcher, error) {
+	policy, err := parseTLSPolicy(c.flagPrefix, c.TLSMinVersion, c.TLSCipherSuites, c.TLSCurvePreferences)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	switch {
+	case c.MutualTLSCAPath != "" && !c.TLSEnabled():
+		return nil, nil, fmt.Errorf("%s-client-ca-path requires %[1]s-tls-cert-path and %[1]s-tls-key-path to be set", c.flagPrefix)
+	case c.GetCertificate != nil:
+		opts, err := c.tlsServerOpts(c.GetCertificate, policy)
+		return opts, nil, err
 	case c.TLSCertPath == "" && c.TLSKeyPath == "":
 		return nil, nil, nil
 	case c.TLSCertPath != "" && c.TLSKeyPath != "":
@@ -165,21 +325,32 @@ func (c *GRPCServerConfig) tlsOpts() ([]grpc.ServerOption, *certwatcher.CertWatc
 		if err != nil {
 			return nil, nil, err
 		}
-		creds := credentials.NewTLS(&tls.Config{
-			GetCertificate: watcher.GetCertificate,
-			MinVersion:     tls.VersionTLS12,
-		})
-		return []grpc.ServerOption{grpc.Creds(creds)}, watcher, nil
+		opts, err := c.tlsServerOpts(watcher.GetCertificate, policy)
+		return opts, watcher, err
 	default:
 		return nil, nil, nil
 	}
 }
 
+func (c *GRPCServerConfig) tlsServerOpts(getCertificate func(*tls.ClientHelloInfo) (*tls.Certificate, error), policy tlsPolicy) ([]grpc.ServerOption, error) {
+	tlsConfig := &tls.Config{GetCertificate: getCertificate}
+	policy.apply(tlsConfig)
+	if c.MutualTLSCAPath != "" {
+		pool, err := x509util.CustomCertPool(c.MutualTLSCAPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client CA bundle: %w", err)
+		}
+		tlsConfig.ClientCAs = pool
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return []grpc.ServerOption{grpc.Creds(credentials.NewTLS(tlsConfig))}, nil
+}
+
 func (c *GRPCServerConfig) clientCreds() (credentials.TransportCredentials, error) {
 	switch {
-	case c.TLSCertPath == "" && c.TLSKeyPath == "":
+	case c.GetCertificate == nil && c.TLSCertPath == "" && c.TLSKeyPath == "":
 		return insecure.NewCredentials(), nil
-	case c.TLSCertPath != "" && c.TLSKeyPath != "":
+	case c.TLSEnabled():
 		var err error
 		var pool *x509.CertPool
 		if c.ClientCAPath != "" {
@@ -191,12 +362,46 @@ func (c *GRPCServerConfig) clientCreds() (credentials.TransportCredentials, erro
 			return nil, err
 		}
 
-		return credentials.NewTLS(&tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12}), nil
+		policy, err := parseTLSPolicy(c.flagPrefix, c.TLSMinVersion, c.TLSCipherSuites, c.TLSCurvePreferences)
+		if err != nil {
+			return nil, err
+		}
+
+		clientCert, err := c.ClientCertificate()
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig := &tls.Config{RootCAs: pool, GetClientCertificate: clientCert}
+		policy.apply(tlsConfig)
+		return credentials.NewTLS(tlsConfig), nil
 	default:
 		return nil, nil
 	}
 }
 
+// ClientCertificate returns the certificate with which in-process clients, such as the HTTP
+// gateway, authenticate to the server when it requires client certificates: the server's own.
+// It returns nil if the server does not require client certificates.
+func (c *GRPCServerConfig) ClientCertificate() (func(*tls.CertificateRequestInfo) (*tls.Certificate, error), error) {
+	switch {
+	case c.MutualTLSCAPath == "" || !c.TLSEnabled():
+		return nil, nil
+	case c.GetCertificate != nil:
+		return func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return c.GetCertificate(&tls.ClientHelloInfo{})
+		}, nil
+	default:
+		cert, err := tls.LoadX509KeyPair(c.TLSCertPath, c.TLSKeyPath)
+		if err != nil {
+			return nil, err
+		}
+		return func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return &cert, nil
+		}, nil
+	}
+}
+
 type RunnableGRPCServer interface {
 	WithOpts(opts ...grpc.ServerOption) RunnableGRPCServer
 	Listen(ctx context.Context) func() error
@@ -204,6 +409,7 @@ type RunnableGRPCServer interface {
 	NetDialContext(ctx context.Context, s string) (net.Conn, error)
 	Insecure() bool
 	GracefulStop()
+	StopWithGracePeriod(gracePeriod time.Duration)
 }
 
 type completedGRPCServer struct {
@@ -213,6 +419,7 @@ type completedGRPCServer struct {
 	listenFunc        func() error
 	prestopFunc       func()
 	stopFunc          func()
+	forceStopFunc     func()
 	dial              func(context.Context, ...grpc.DialOption) (*grpc.ClientConn, error)
 	netDial           func(ctx context.Context, s string) (net.Conn, error)
 	creds             credentials.TransportCredentials
@@ -228,17 +435,14 @@ func (c *completedGRPCServer) WithOpts(opts ...grpc.ServerOption) RunnableGRPCSe
 		return srv.Serve(c.listener)
 	}
 	c.stopFunc = srv.GracefulStop
+	c.forceStopFunc = srv.Stop
 	return c
 }
 
 // Listen runs a configured server
 func (c *completedGRPCServer) Listen(ctx context.Context) func() error {
 	if c.certWatcher != nil {
-		go func() {
-			if err := c.certWatcher.Start(ctx); err != nil {
-				log.Ctx(ctx).Error().Err(err).Msg("error watching tls certs")
-			}
-		}()
+		watchCertificate(ctx, c.certWatcher)
 	}
 	return c.listenFunc
 }
@@ -265,6 +469,33 @@ func (c *completedGRPCServer) GracefulStop() {
 	c.stopFunc()
 }
 
+// StopWithGracePeriod stops the server from accepting new RPCs and waits up to the grace
+// period for in-flight RPCs to complete before forcibly closing them. A zero grace period
+// waits indefinitely.
+func (c *completedGRPCServer) StopWithGracePeriod(gracePeriod time.Duration) {
+	if gracePeriod <= 0 {
+		c.GracefulStop()
+		return
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		c.GracefulStop()
+		close(stopped)
+	}()
+
+	timer := time.NewTimer(gracePeriod)
+	defer timer.Stop()
+
+	select {
+	case <-stopped:
+	case <-timer.C:
+		log.Warn().Stringer("gracePeriod", gracePeriod).Msg("grpc server shutdown grace period elapsed; forcibly closing in-flight requests")
+		c.forceStopFunc()
+		<-stopped
+	}
+}
+
 type disabledGrpcServer struct{}
 
 // WithOpts adds to the options for running the server
@@ -297,12 +528,26 @@ func (d *disabledGrpcServer) NetDialContext(_ context.Context, _ string) (net.Co
 // GracefulStop stops a running server
 func (d *disabledGrpcServer) GracefulStop() {}
 
+// StopWithGracePeriod stops a running server
+func (d *disabledGrpcServer) StopWithGracePeriod(_ time.Duration) {}
+
 type HTTPServerConfig struct {
 	HTTPAddress     string `debugmap:"visible"`
 	HTTPTLSCertPath string `debugmap:"visible"`
 	HTTPTLSKeyPath  string `debugmap:"visible"`
 	HTTPEnabled     bool   `debugmap:"visible"`
 
+	// HTTPTLSMinVersion, HTTPTLSCipherSuites and HTTPTLSCurvePreferences restrict the TLS
+	// connections served, as for GRPCServerConfig.
+	HTTPTLSMinVersion       string   `debugmap:"visible"`
+	HTTPTLSCipherSuites     []string `debugmap:"visible"`
+	HTTPTLSCurvePreferences []string `debugmap:"visible"`
+
+	// HTTPAllowedCIDRs and HTTPDeniedCIDRs restrict the networks from which requests are
+	// served, as for GRPCServerConfig.
+	HTTPAllowedCIDRs []string `debugmap:"visible"`
+	HTTPDeniedCIDRs  []string `debugmap:"visible"`
+
 	flagPrefix string
 }
 
@@ -310,12 +555,19 @@ func (c *HTTPServerConfig) Complete(level zerolog.Level, handler http.Handler) (
 	if !c.HTTPEnabled {
 		return &disabledHTTPServer{}, nil
 	}
+	acl, err := netacl.New(c.flagPrefix, c.HTTPAllowedCIDRs, c.HTTPDeniedCIDRs)
+	if err != nil {
+		return nil, fmt.Errorf("invalid network access control for %s: %w", c.flagPrefix, err)
+	}
+	handler = netacl.HTTPHandler(acl, handler)
+
 	srv := &http.Server{
 		Addr:              c.HTTPAddress,
 		Handler:           handler,
 		ReadHeaderTimeout: 5 * time.Second,
 	}
 	var serveFunc func() error
+	stopWatchingFunc := func() {}
 	switch {
 	case c.HTTPTLSCertPath == "" && c.HTTPTLSKeyPath == "":
 		serveFunc = func() error {
@@ -333,14 +585,21 @@ func (c *HTTPServerConfig) Complete(level zerolog.Level, handler http.Handler) (
 			return nil, err
 		}
 
-		listener, err := tls.Listen("tcp", srv.Addr, &tls.Config{
-			GetCertificate: watcher.GetCertificate,
-			MinVersion:     tls.VersionTLS12,
-		})
+		policy, err := parseTLSPolicy(c.flagPrefix, c.HTTPTLSMinVersion, c.HTTPTLSCipherSuites, c.HTTPTLSCurvePreferences)
+		if err != nil {
+			return nil, err
+		}
+
+		tlsConfig := &tls.Config{GetCertificate: watcher.GetCertificate}
+		policy.apply(tlsConfig)
+		listener, err := tls.Listen("tcp", srv.Addr, tlsConfig)
 		if err != nil {
 			return nil, err
 		}
+		watchCtx, cancel := context.WithCancel(context.Background())
+		stopWatchingFunc = cancel
 		serveFunc = func() error {
+			watchCertificate(watchCtx, watcher)
 			log.WithLevel(level).
 				Str("addr", srv.Addr).
 				Str("prefix", c.flagPrefix).
@@ -363,6 +622,7 @@ func (c *HTTPServerConfig) Complete(level zerolog.Level, handler http.Handler) (
 			return nil
 		},
 		closeFunc: func() {
+			stopWatchingFunc()
 			if err := srv.Close(); err != nil {
 				log.Error().Str("addr", srv.Addr).Str("service", c.flagPrefix).Err(err).Msg("error stopping http server")
 			}
@@ -372,6 +632,36 @@ func (c *HTTPServerConfig) Complete(level zerolog.Level, handler http.Handler) (
 	}, nil
 }
 
+// watchCertificate reloads the watcher's certificate whenever its files change or the
+// process receives SIGHUP, until the context is cancelled. Existing connections are
+// unaffected; new connections are served with the reloaded certificate.
+func watchCertificate(ctx context.Context, watcher *certwatcher.CertWatcher) {
+	go func() {
+		if err := watcher.Start(ctx); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("error watching tls certs")
+		}
+	}()
+
+	go func() {
+		reload := make(chan os.Signal, 1)
+		signal.Notify(reload, syscall.SIGHUP)
+		defer signal.Stop(reload)
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-reload:
+				if err := watcher.ReadCertificate(); err != nil {
+					log.Ctx(ctx).Error().Err(err).Msg("error reloading tls certs")
+					continue
+				}
+				log.Ctx(ctx).Info().Msg("reloaded tls certs after SIGHUP")
+			}
+		}
+	}()
+}
+
 type RunnableHTTPServer interface {
 	ListenAndServe() error
 	Close()
@@ -409,6 +699,8 @@ func RegisterHTTPServerFlags(flags *pflag.FlagSet, config *HTTPServerConfig, fla
 	flags.StringVar(&config.HTTPTLSCertPath, flagPrefix+"-tls-cert-path", "", "local path to the TLS certificate used to serve "+serviceName)
 	flags.StringVar(&config.HTTPTLSKeyPath, flagPrefix+"-tls-key-path", "", "local path to the TLS key used to serve "+serviceName)
 	flags.BoolVar(&config.HTTPEnabled, flagPrefix+"-enabled", defaultEnabled, "enable http "+serviceName+" server")
+	registerTLSPolicyFlags(flags, &config.HTTPTLSMinVersion, &config.HTTPTLSCipherSuites, &config.HTTPTLSCurvePreferences, flagPrefix, serviceName)
+	registerNetworkACLFlags(flags, &config.HTTPAllowedCIDRs, &config.HTTPDeniedCIDRs, flagPrefix, serviceName)
 }
 
 // RegisterDeprecatedHTTPServerFlags registers a set of HTTP server flags as fully deprecated, for a removed HTTP service.
@@ -2,10 +2,33 @@ package util
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+
+	"github.com/authzed/spicedb/pkg/middleware/clientidentity"
+	"github.com/authzed/spicedb/pkg/x509util"
 )
 
 func TestDisabledGRPC(t *testing.T) {
@@ -22,3 +45,419 @@ func TestDisabledHTTP(t *testing.T) {
 	require.NoError(t, s.ListenAndServe())
 	s.Close()
 }
+
+func TestUnixSocketGRPC(t *testing.T) {
+	socketPath := filepath.Join(t.TempDir(), "spicedb.sock")
+
+	// Leave behind a socket file, as would a server that did not shut down cleanly.
+	stale, err := net.Listen(UnixNetwork, socketPath)
+	require.NoError(t, err)
+	stale.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, stale.Close())
+
+	config := &GRPCServerConfig{
+		Address: socketPath,
+		Network: UnixNetwork,
+		Enabled: true,
+	}
+	require.Equal(t, "unix:"+socketPath, config.DialTarget())
+
+	s, err := config.Complete(zerolog.InfoLevel, func(server *grpc.Server) {
+		healthpb.RegisterHealthServer(server, health.NewServer())
+	})
+	require.NoError(t, err)
+	t.Cleanup(s.GracefulStop)
+
+	go func() {
+		_ = s.Listen(context.Background())()
+	}()
+
+	conn, err := s.DialContext(context.Background())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	require.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+
+	// A socket with a live server must not be removed.
+	_, err = config.Complete(zerolog.InfoLevel, func(server *grpc.Server) {})
+	require.Error(t, err)
+}
+
+func TestStopWithGracePeriod(t *testing.T) {
+	config := &GRPCServerConfig{
+		Network: BufferedNetwork,
+		Enabled: true,
+	}
+	s, err := config.Complete(zerolog.InfoLevel, func(server *grpc.Server) {
+		healthpb.RegisterHealthServer(server, health.NewServer())
+	})
+	require.NoError(t, err)
+
+	go func() {
+		_ = s.Listen(context.Background())()
+	}()
+
+	conn, err := s.DialContext(context.Background())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	// A watch is in flight until the server closes it.
+	stream, err := healthpb.NewHealthClient(conn).Watch(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	_, err = stream.Recv()
+	require.NoError(t, err)
+
+	stopped := make(chan struct{})
+	go func() {
+		s.StopWithGracePeriod(50 * time.Millisecond)
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		require.Fail(t, "server did not stop after its grace period")
+	}
+
+	_, err = stream.Recv()
+	require.Error(t, err)
+}
+
+func TestMutualTLSGRPC(t *testing.T) {
+	certDir := t.TempDir()
+	caPath, certPath, keyPath := writeTestCerts(t, certDir, BufferedNetwork)
+
+	var identity *clientidentity.Identity
+	config := &GRPCServerConfig{
+		Network:         BufferedNetwork,
+		Enabled:         true,
+		TLSCertPath:     certPath,
+		TLSKeyPath:      keyPath,
+		ClientCAPath:    caPath,
+		MutualTLSCAPath: caPath,
+	}
+	s, err := config.Complete(zerolog.InfoLevel, func(server *grpc.Server) {
+		healthpb.RegisterHealthServer(server, health.NewServer())
+	}, grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		identity, _ = clientidentity.FromContext(ctx)
+		return handler(ctx, req)
+	}))
+	require.NoError(t, err)
+	require.False(t, s.Insecure())
+	t.Cleanup(s.GracefulStop)
+
+	go func() {
+		_ = s.Listen(context.Background())()
+	}()
+
+	// In-process clients authenticate with the server's own certificate.
+	conn, err := s.DialContext(context.Background())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	_, err = healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	require.NotNil(t, identity)
+	require.Equal(t, "spicedb", identity.CommonName)
+
+	// Clients without a certificate are rejected.
+	pool, err := x509util.CustomCertPool(caPath)
+	require.NoError(t, err)
+	unauthenticated, err := grpc.DialContext(
+		context.Background(),
+		BufferedNetwork,
+		grpc.WithContextDialer(s.NetDialContext),
+		grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{RootCAs: pool, MinVersion: tls.VersionTLS12})),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { unauthenticated.Close() })
+
+	_, err = healthpb.NewHealthClient(unauthenticated).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.Error(t, err)
+}
+
+func TestGetCertificateGRPC(t *testing.T) {
+	certDir := t.TempDir()
+	caPath, certPath, keyPath := writeTestCerts(t, certDir, BufferedNetwork)
+	cert, err := tls.LoadX509KeyPair(certPath, keyPath)
+	require.NoError(t, err)
+
+	var identity *clientidentity.Identity
+	config := &GRPCServerConfig{
+		Network:         BufferedNetwork,
+		Enabled:         true,
+		ClientCAPath:    caPath,
+		MutualTLSCAPath: caPath,
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return &cert, nil
+		},
+	}
+	require.True(t, config.TLSEnabled())
+
+	s, err := config.Complete(zerolog.InfoLevel, func(server *grpc.Server) {
+		healthpb.RegisterHealthServer(server, health.NewServer())
+	}, grpc.UnaryInterceptor(func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		identity, _ = clientidentity.FromContext(ctx)
+		return handler(ctx, req)
+	}))
+	require.NoError(t, err)
+	require.False(t, s.Insecure())
+	t.Cleanup(s.GracefulStop)
+
+	go func() {
+		_ = s.Listen(context.Background())()
+	}()
+
+	// In-process clients authenticate with the certificate served.
+	conn, err := s.DialContext(context.Background())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	_, err = healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	require.NotNil(t, identity)
+	require.Equal(t, "spicedb", identity.CommonName)
+}
+
+func TestClientAuthRequiresTLS(t *testing.T) {
+	_, err := (&GRPCServerConfig{
+		Network:         BufferedNetwork,
+		Enabled:         true,
+		MutualTLSCAPath: "ca.crt",
+		flagPrefix:      "grpc",
+	}).Complete(zerolog.InfoLevel, func(server *grpc.Server) {})
+	require.ErrorContains(t, err, "grpc-client-ca-path requires grpc-tls-cert-path and grpc-tls-key-path")
+}
+
+func TestTLSPolicyGRPC(t *testing.T) {
+	certDir := t.TempDir()
+	caPath, certPath, keyPath := writeTestCerts(t, certDir, BufferedNetwork)
+	pool, err := x509util.CustomCertPool(caPath)
+	require.NoError(t, err)
+
+	config := &GRPCServerConfig{
+		Network:             BufferedNetwork,
+		Enabled:             true,
+		TLSCertPath:         certPath,
+		TLSKeyPath:          keyPath,
+		ClientCAPath:        caPath,
+		TLSCipherSuites:     []string{"TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384"},
+		TLSCurvePreferences: []string{"P384"},
+	}
+	s, err := config.Complete(zerolog.InfoLevel, func(server *grpc.Server) {
+		healthpb.RegisterHealthServer(server, health.NewServer())
+	})
+	require.NoError(t, err)
+	t.Cleanup(s.GracefulStop)
+
+	go func() {
+		_ = s.Listen(context.Background())()
+	}()
+
+	check := func(tlsConfig *tls.Config) error {
+		conn, err := grpc.DialContext(
+			context.Background(),
+			BufferedNetwork,
+			grpc.WithContextDialer(s.NetDialContext),
+			grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig)),
+		)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		_, err = healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+		return err
+	}
+
+	// In-process clients are restricted to the same policy.
+	conn, err := s.DialContext(context.Background())
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+	_, err = healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+
+	require.NoError(t, check(&tls.Config{
+		RootCAs:          pool,
+		MaxVersion:       tls.VersionTLS12,
+		CipherSuites:     []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_256_GCM_SHA384},
+		CurvePreferences: []tls.CurveID{tls.CurveP384},
+	}))
+
+	// Clients offering only other cipher suites or curves are rejected.
+	require.Error(t, check(&tls.Config{
+		RootCAs:      pool,
+		MaxVersion:   tls.VersionTLS12,
+		CipherSuites: []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256},
+	}))
+	require.Error(t, check(&tls.Config{
+		RootCAs:          pool,
+		MaxVersion:       tls.VersionTLS12,
+		CurvePreferences: []tls.CurveID{tls.X25519},
+	}))
+}
+
+func TestTLSMinVersionGRPC(t *testing.T) {
+	certDir := t.TempDir()
+	caPath, certPath, keyPath := writeTestCerts(t, certDir, BufferedNetwork)
+	pool, err := x509util.CustomCertPool(caPath)
+	require.NoError(t, err)
+
+	config := &GRPCServerConfig{
+		Network:       BufferedNetwork,
+		Enabled:       true,
+		TLSCertPath:   certPath,
+		TLSKeyPath:    keyPath,
+		TLSMinVersion: "1.3",
+	}
+	s, err := config.Complete(zerolog.InfoLevel, func(server *grpc.Server) {
+		healthpb.RegisterHealthServer(server, health.NewServer())
+	})
+	require.NoError(t, err)
+	t.Cleanup(s.GracefulStop)
+
+	go func() {
+		_ = s.Listen(context.Background())()
+	}()
+
+	conn, err := grpc.DialContext(
+		context.Background(),
+		BufferedNetwork,
+		grpc.WithContextDialer(s.NetDialContext),
+		grpc.WithTransportCredentials(credentials.NewTLS(&tls.Config{RootCAs: pool, MaxVersion: tls.VersionTLS12})),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	_, err = healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.Error(t, err)
+}
+
+func TestNetworkACLGRPC(t *testing.T) {
+	config := &GRPCServerConfig{
+		Network:     "tcp",
+		Address:     "127.0.0.1:0",
+		Enabled:     true,
+		DeniedCIDRs: []string{"127.0.0.0/8"},
+	}
+	s, err := config.Complete(zerolog.InfoLevel, func(server *grpc.Server) {
+		healthpb.RegisterHealthServer(server, health.NewServer())
+	})
+	require.NoError(t, err)
+	t.Cleanup(s.GracefulStop)
+
+	go func() {
+		_ = s.Listen(context.Background())()
+	}()
+
+	conn, err := grpc.Dial(
+		s.(*completedGRPCServer).listener.Addr().String(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	_, err = healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	config.DeniedCIDRs = []string{"127.0.0.1"}
+	_, err = config.Complete(zerolog.InfoLevel, func(server *grpc.Server) {})
+	require.ErrorContains(t, err, "invalid CIDR `127.0.0.1`")
+}
+
+func TestParseTLSPolicy(t *testing.T) {
+	policy, err := parseTLSPolicy("grpc", "", nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, uint16(tls.VersionTLS12), policy.minVersion)
+
+	policy, err = parseTLSPolicy("grpc", "1.2", []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"}, []string{"x25519", "P256"})
+	require.NoError(t, err)
+	require.Equal(t, []uint16{tls.TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256}, policy.cipherSuites)
+	require.Equal(t, []tls.CurveID{tls.X25519, tls.CurveP256}, policy.curves)
+
+	_, err = parseTLSPolicy("grpc", "1.1", nil, nil)
+	require.ErrorContains(t, err, "invalid --grpc-tls-min-version `1.1`")
+
+	_, err = parseTLSPolicy("http", "1.2", []string{"TLS_RSA_WITH_RC4_128_SHA"}, nil)
+	require.ErrorContains(t, err, "invalid --http-tls-cipher-suites `TLS_RSA_WITH_RC4_128_SHA`")
+
+	_, err = parseTLSPolicy("grpc", "1.3", []string{"TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"}, nil)
+	require.ErrorContains(t, err, "cannot be set with a minimum TLS version of 1.3")
+
+	_, err = parseTLSPolicy("grpc", "1.2", nil, []string{"P224"})
+	require.ErrorContains(t, err, "invalid --grpc-tls-curve-preferences `P224`")
+}
+
+func TestWatchCertificateReloadsOnChange(t *testing.T) {
+	certDir := t.TempDir()
+	_, certPath, keyPath := writeTestCerts(t, certDir, "first")
+
+	watcher, err := certwatcher.New(certPath, keyPath)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	watchCertificate(ctx, watcher)
+
+	servedDNSName := func() string {
+		cert, err := watcher.GetCertificate(nil)
+		require.NoError(t, err)
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		require.NoError(t, err)
+		return leaf.DNSNames[0]
+	}
+	require.Equal(t, "first", servedDNSName())
+
+	// The watch is established asynchronously, so keep rewriting the files until the
+	// change is observed.
+	deadline := time.Now().Add(10 * time.Second)
+	for servedDNSName() != "second" {
+		require.True(t, time.Now().Before(deadline), "certificate was not reloaded")
+		writeTestCerts(t, certDir, "second")
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// writeTestCerts writes a CA and a certificate signed by it, usable for both server and
+// client authentication, returning the paths of the CA, certificate and key.
+func writeTestCerts(t *testing.T, dir, dnsName string) (string, string, string) {
+	caKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	ca := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "testCA"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		BasicConstraintsValid: true,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, ca, ca, &caKey.PublicKey, caKey)
+	require.NoError(t, err)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	cert := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{CommonName: "spicedb"},
+		DNSNames:     []string{dnsName},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, cert, ca, &key.PublicKey, caKey)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	writePEM := func(name, blockType string, der []byte) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0o600))
+		return path
+	}
+
+	return writePEM("ca.crt", "CERTIFICATE", caDER),
+		writePEM("tls.crt", "CERTIFICATE", certDER),
+		writePEM("tls.key", "EC PRIVATE KEY", keyDER)
+}
@@ -2,6 +2,7 @@
 package util
 
 import (
+	"crypto/tls"
 	defaults "github.com/creasty/defaults"
 	helpers "github.com/ecordell/optgen/helpers"
 	"time"
@@ -40,6 +41,21 @@ func (g *GRPCServerConfig) ToOption() GRPCServerConfigOption {
 		to.BufferSize = g.BufferSize
 		to.ClientCAPath = g.ClientCAPath
 		to.MaxWorkers = g.MaxWorkers
+		to.MaxConnAgeGrace = g.MaxConnAgeGrace
+		to.MaxConnIdle = g.MaxConnIdle
+		to.KeepaliveTime = g.KeepaliveTime
+		to.KeepaliveTimeout = g.KeepaliveTimeout
+		to.KeepaliveMinTime = g.KeepaliveMinTime
+		to.KeepalivePermitWithoutCall = g.KeepalivePermitWithoutCall
+		to.MaxConcurrentStreams = g.MaxConcurrentStreams
+		to.MutualTLSCAPath = g.MutualTLSCAPath
+		to.ResponseCompression = g.ResponseCompression
+		to.TLSMinVersion = g.TLSMinVersion
+		to.TLSCipherSuites = g.TLSCipherSuites
+		to.TLSCurvePreferences = g.TLSCurvePreferences
+		to.AllowedCIDRs = g.AllowedCIDRs
+		to.DeniedCIDRs = g.DeniedCIDRs
+		to.GetCertificate = g.GetCertificate
 		to.flagPrefix = g.flagPrefix
 	}
 }
@@ -56,6 +72,20 @@ func (g GRPCServerConfig) DebugMap() map[string]any {
 	debugMap["BufferSize"] = helpers.DebugValue(g.BufferSize, false)
 	debugMap["ClientCAPath"] = helpers.DebugValue(g.ClientCAPath, false)
 	debugMap["MaxWorkers"] = helpers.DebugValue(g.MaxWorkers, false)
+	debugMap["MaxConnAgeGrace"] = helpers.DebugValue(g.MaxConnAgeGrace, false)
+	debugMap["MaxConnIdle"] = helpers.DebugValue(g.MaxConnIdle, false)
+	debugMap["KeepaliveTime"] = helpers.DebugValue(g.KeepaliveTime, false)
+	debugMap["KeepaliveTimeout"] = helpers.DebugValue(g.KeepaliveTimeout, false)
+	debugMap["KeepaliveMinTime"] = helpers.DebugValue(g.KeepaliveMinTime, false)
+	debugMap["KeepalivePermitWithoutCall"] = helpers.DebugValue(g.KeepalivePermitWithoutCall, false)
+	debugMap["MaxConcurrentStreams"] = helpers.DebugValue(g.MaxConcurrentStreams, false)
+	debugMap["MutualTLSCAPath"] = helpers.DebugValue(g.MutualTLSCAPath, false)
+	debugMap["ResponseCompression"] = helpers.DebugValue(g.ResponseCompression, false)
+	debugMap["TLSMinVersion"] = helpers.DebugValue(g.TLSMinVersion, false)
+	debugMap["TLSCipherSuites"] = helpers.DebugValue(g.TLSCipherSuites, false)
+	debugMap["TLSCurvePreferences"] = helpers.DebugValue(g.TLSCurvePreferences, false)
+	debugMap["AllowedCIDRs"] = helpers.DebugValue(g.AllowedCIDRs, false)
+	debugMap["DeniedCIDRs"] = helpers.DebugValue(g.DeniedCIDRs, false)
 	return debugMap
 }
 
@@ -138,6 +168,146 @@ func WithMaxWorkers(maxWorkers uint32) GRPCServerConfigOption {
 	}
 }
 
+// WithMaxConnAgeGrace returns an option that can set MaxConnAgeGrace on a GRPCServerConfig
+func WithMaxConnAgeGrace(maxConnAgeGrace time.Duration) GRPCServerConfigOption {
+	return func(g *GRPCServerConfig) {
+		g.MaxConnAgeGrace = maxConnAgeGrace
+	}
+}
+
+// WithMaxConnIdle returns an option that can set MaxConnIdle on a GRPCServerConfig
+func WithMaxConnIdle(maxConnIdle time.Duration) GRPCServerConfigOption {
+	return func(g *GRPCServerConfig) {
+		g.MaxConnIdle = maxConnIdle
+	}
+}
+
+// WithKeepaliveTime returns an option that can set KeepaliveTime on a GRPCServerConfig
+func WithKeepaliveTime(keepaliveTime time.Duration) GRPCServerConfigOption {
+	return func(g *GRPCServerConfig) {
+		g.KeepaliveTime = keepaliveTime
+	}
+}
+
+// WithKeepaliveTimeout returns an option that can set KeepaliveTimeout on a GRPCServerConfig
+func WithKeepaliveTimeout(keepaliveTimeout time.Duration) GRPCServerConfigOption {
+	return func(g *GRPCServerConfig) {
+		g.KeepaliveTimeout = keepaliveTimeout
+	}
+}
+
+// WithKeepaliveMinTime returns an option that can set KeepaliveMinTime on a GRPCServerConfig
+func WithKeepaliveMinTime(keepaliveMinTime time.Duration) GRPCServerConfigOption {
+	return func(g *GRPCServerConfig) {
+		g.KeepaliveMinTime = keepaliveMinTime
+	}
+}
+
+// WithKeepalivePermitWithoutCall returns an option that can set KeepalivePermitWithoutCall on a GRPCServerConfig
+func WithKeepalivePermitWithoutCall(keepalivePermitWithoutCall bool) GRPCServerConfigOption {
+	return func(g *GRPCServerConfig) {
+		g.KeepalivePermitWithoutCall = keepalivePermitWithoutCall
+	}
+}
+
+// WithMaxConcurrentStreams returns an option that can set MaxConcurrentStreams on a GRPCServerConfig
+func WithMaxConcurrentStreams(maxConcurrentStreams uint32) GRPCServerConfigOption {
+	return func(g *GRPCServerConfig) {
+		g.MaxConcurrentStreams = maxConcurrentStreams
+	}
+}
+
+// WithMutualTLSCAPath returns an option that can set MutualTLSCAPath on a GRPCServerConfig
+func WithMutualTLSCAPath(mutualTLSCAPath string) GRPCServerConfigOption {
+	return func(g *GRPCServerConfig) {
+		g.MutualTLSCAPath = mutualTLSCAPath
+	}
+}
+
+// WithResponseCompression returns an option that can append ResponseCompressions to GRPCServerConfig.ResponseCompression
+func WithResponseCompression(responseCompression string) GRPCServerConfigOption {
+	return func(g *GRPCServerConfig) {
+		g.ResponseCompression = append(g.ResponseCompression, responseCompression)
+	}
+}
+
+// SetResponseCompression returns an option that can set ResponseCompression on a GRPCServerConfig
+func SetResponseCompression(responseCompression []string) GRPCServerConfigOption {
+	return func(g *GRPCServerConfig) {
+		g.ResponseCompression = responseCompression
+	}
+}
+
+// WithTLSMinVersion returns an option that can set TLSMinVersion on a GRPCServerConfig
+func WithTLSMinVersion(tLSMinVersion string) GRPCServerConfigOption {
+	return func(g *GRPCServerConfig) {
+		g.TLSMinVersion = tLSMinVersion
+	}
+}
+
+// WithTLSCipherSuites returns an option that can append TLSCipherSuitess to GRPCServerConfig.TLSCipherSuites
+func WithTLSCipherSuites(tLSCipherSuites string) GRPCServerConfigOption {
+	return func(g *GRPCServerConfig) {
+		g.TLSCipherSuites = append(g.TLSCipherSuites, tLSCipherSuites)
+	}
+}
+
+// SetTLSCipherSuites returns an option that can set TLSCipherSuites on a GRPCServerConfig
+func SetTLSCipherSuites(tLSCipherSuites []string) GRPCServerConfigOption {
+	return func(g *GRPCServerConfig) {
+		g.TLSCipherSuites = tLSCipherSuites
+	}
+}
+
+// WithTLSCurvePreferences returns an option that can append TLSCurvePreferencess to GRPCServerConfig.TLSCurvePreferences
+func WithTLSCurvePreferences(tLSCurvePreferences string) GRPCServerConfigOption {
+	return func(g *GRPCServerConfig) {
+		g.TLSCurvePreferences = append(g.TLSCurvePreferences, tLSCurvePreferences)
+	}
+}
+
+// SetTLSCurvePreferences returns an option that can set TLSCurvePreferences on a GRPCServerConfig
+func SetTLSCurvePreferences(tLSCurvePreferences []string) GRPCServerConfigOption {
+	return func(g *GRPCServerConfig) {
+		g.TLSCurvePreferences = tLSCurvePreferences
+	}
+}
+
+// WithAllowedCIDRs returns an option that can append AllowedCIDRss to GRPCServerConfig.AllowedCIDRs
+func WithAllowedCIDRs(allowedCIDRs string) GRPCServerConfigOption {
+	return func(g *GRPCServerConfig) {
+		g.AllowedCIDRs = append(g.AllowedCIDRs, allowedCIDRs)
+	}
+}
+
+// SetAllowedCIDRs returns an option that can set AllowedCIDRs on a GRPCServerConfig
+func SetAllowedCIDRs(allowedCIDRs []string) GRPCServerConfigOption {
+	return func(g *GRPCServerConfig) {
+		g.AllowedCIDRs = allowedCIDRs
+	}
+}
+
+// WithDeniedCIDRs returns an option that can append DeniedCIDRss to GRPCServerConfig.DeniedCIDRs
+func WithDeniedCIDRs(deniedCIDRs string) GRPCServerConfigOption {
+	return func(g *GRPCServerConfig) {
+		g.DeniedCIDRs = append(g.DeniedCIDRs, deniedCIDRs)
+	}
+}
+
+// SetDeniedCIDRs returns an option that can set DeniedCIDRs on a GRPCServerConfig
+func SetDeniedCIDRs(deniedCIDRs []string) GRPCServerConfigOption {
+	return func(g *GRPCServerConfig) {
+		g.DeniedCIDRs = deniedCIDRs
+	}
+}
+
+// WithGetCertificate returns an option that can set GetCertificate on a GRPCServerConfig
+func WithGetCertificate(getCertificate func(*tls.ClientHelloInfo) (*tls.Certificate, error)) GRPCServerConfigOption {
+	return func(g *GRPCServerConfig) {
+		g.GetCertificate = getCertificate
+	}
+}
+
 type HTTPServerConfigOption func(h *HTTPServerConfig)
 
 // NewHTTPServerConfigWithOptions creates a new HTTPServerConfig with the passed in options set
@@ -166,6 +336,11 @@ func (h *HTTPServerConfig) ToOption() HTTPServerConfigOption {
 		to.HTTPTLSCertPath = h.HTTPTLSCertPath
 		to.HTTPTLSKeyPath = h.HTTPTLSKeyPath
 		to.HTTPEnabled = h.HTTPEnabled
+		to.HTTPTLSMinVersion = h.HTTPTLSMinVersion
+		to.HTTPTLSCipherSuites = h.HTTPTLSCipherSuites
+		to.HTTPTLSCurvePreferences = h.HTTPTLSCurvePreferences
+		to.HTTPAllowedCIDRs = h.HTTPAllowedCIDRs
+		to.HTTPDeniedCIDRs = h.HTTPDeniedCIDRs
 		to.flagPrefix = h.flagPrefix
 	}
 }
@@ -177,6 +352,11 @@ func (h HTTPServerConfig) DebugMap() map[string]any {
 	debugMap["HTTPTLSCertPath"] = helpers.DebugValue(h.HTTPTLSCertPath, false)
 	debugMap["HTTPTLSKeyPath"] = helpers.DebugValue(h.HTTPTLSKeyPath, false)
 	debugMap["HTTPEnabled"] = helpers.DebugValue(h.HTTPEnabled, false)
+	debugMap["HTTPTLSMinVersion"] = helpers.DebugValue(h.HTTPTLSMinVersion, false)
+	debugMap["HTTPTLSCipherSuites"] = helpers.DebugValue(h.HTTPTLSCipherSuites, false)
+	debugMap["HTTPTLSCurvePreferences"] = helpers.DebugValue(h.HTTPTLSCurvePreferences, false)
+	debugMap["HTTPAllowedCIDRs"] = helpers.DebugValue(h.HTTPAllowedCIDRs, false)
+	debugMap["HTTPDeniedCIDRs"] = helpers.DebugValue(h.HTTPDeniedCIDRs, false)
 	return debugMap
 }
 
@@ -223,3 +403,66 @@ func WithHTTPEnabled(hTTPEnabled bool) HTTPServerConfigOption {
 		h.HTTPEnabled = hTTPEnabled
 	}
 }
+
+// WithHTTPTLSMinVersion returns an option that can set HTTPTLSMinVersion on a HTTPServerConfig
+func WithHTTPTLSMinVersion(hTTPTLSMinVersion string) HTTPServerConfigOption {
+	return func(h *HTTPServerConfig) {
+		h.HTTPTLSMinVersion = hTTPTLSMinVersion
+	}
+}
+
+// WithHTTPTLSCipherSuites returns an option that can append HTTPTLSCipherSuitess to HTTPServerConfig.HTTPTLSCipherSuites
+func WithHTTPTLSCipherSuites(hTTPTLSCipherSuites string) HTTPServerConfigOption {
+	return func(h *HTTPServerConfig) {
+		h.HTTPTLSCipherSuites = append(h.HTTPTLSCipherSuites, hTTPTLSCipherSuites)
+	}
+}
+
+// SetHTTPTLSCipherSuites returns an option that can set HTTPTLSCipherSuites on a HTTPServerConfig
+func SetHTTPTLSCipherSuites(hTTPTLSCipherSuites []string) HTTPServerConfigOption {
+	return func(h *HTTPServerConfig) {
+		h.HTTPTLSCipherSuites = hTTPTLSCipherSuites
+	}
+}
+
+// WithHTTPTLSCurvePreferences returns an option that can append HTTPTLSCurvePreferencess to HTTPServerConfig.HTTPTLSCurvePreferences
+func WithHTTPTLSCurvePreferences(hTTPTLSCurvePreferences string) HTTPServerConfigOption {
+	return func(h *HTTPServerConfig) {
+		h.HTTPTLSCurvePreferences = append(h.HTTPTLSCurvePreferences, hTTPTLSCurvePreferences)
+	}
+}
+
+// SetHTTPTLSCurvePreferences returns an option that can set HTTPTLSCurvePreferences on a HTTPServerConfig
+func SetHTTPTLSCurvePreferences(hTTPTLSCurvePreferences []string) HTTPServerConfigOption {
+	return func(h *HTTPServerConfig) {
+		h.HTTPTLSCurvePreferences = hTTPTLSCurvePreferences
+	}
+}
+
+// WithHTTPAllowedCIDRs returns an option that can append HTTPAllowedCIDRss to HTTPServerConfig.HTTPAllowedCIDRs
+func WithHTTPAllowedCIDRs(hTTPAllowedCIDRs string) HTTPServerConfigOption {
+	return func(h *HTTPServerConfig) {
+		h.HTTPAllowedCIDRs = append(h.HTTPAllowedCIDRs, hTTPAllowedCIDRs)
+	}
+}
+
+// SetHTTPAllowedCIDRs returns an option that can set HTTPAllowedCIDRs on a HTTPServerConfig
+func SetHTTPAllowedCIDRs(hTTPAllowedCIDRs []string) HTTPServerConfigOption {
+	return func(h *HTTPServerConfig) {
+		h.HTTPAllowedCIDRs = hTTPAllowedCIDRs
+	}
+}
+
+// WithHTTPDeniedCIDRs returns an option that can append HTTPDeniedCIDRss to HTTPServerConfig.HTTPDeniedCIDRs
+func WithHTTPDeniedCIDRs(hTTPDeniedCIDRs string) HTTPServerConfigOption {
+	return func(h *HTTPServerConfig) {
+		h.HTTPDeniedCIDRs = append(h.HTTPDeniedCIDRs, hTTPDeniedCIDRs)
+	}
+}
+
+// SetHTTPDeniedCIDRs returns an option that can set HTTPDeniedCIDRs on a HTTPServerConfig
+func SetHTTPDeniedCIDRs(hTTPDeniedCIDRs []string) HTTPServerConfigOption {
+	return func(h *HTTPServerConfig) {
+		h.HTTPDeniedCIDRs = hTTPDeniedCIDRs
+	}
+}
@@ -0,0 +1,157 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/jzelinskie/cobrautil/v2"
+	"github.com/spf13/cobra"
+	"google.golang.org/protobuf/encoding/protojson"
+
+	"github.com/authzed/spicedb/pkg/cmd/server"
+	"github.com/authzed/spicedb/pkg/cmd/termination"
+	"github.com/authzed/spicedb/pkg/development"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	devinterface "github.com/authzed/spicedb/pkg/proto/developer/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+	"github.com/authzed/spicedb/pkg/validationfile"
+)
+
+func RegisterValidateFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("show-traces", false, "print the resolution trace of each failed assertion")
+	cmd.Flags().String("schema-file", "", "schema file to validate the relationships and assertions of the validation files against, for validation files without a schema")
+}
+
+func NewValidateCommand(programName string) *cobra.Command {
+	return &cobra.Command{
+		Use:     "validate <validation-or-schema-file>...",
+		Short:   "validates schemas, relationships and assertions",
+		Long:    "Validates the schema, relationships, assertions and expected relations found in one or more validation files, exiting with an error if any fail. Files without a .yaml or .yml extension are validated as schema files.",
+		PreRunE: server.DefaultPreRunE(programName),
+		RunE:    termination.PublishError(validateRun),
+		Args:    cobra.MinimumNArgs(1),
+	}
+}
+
+func validateRun(cmd *cobra.Command, args []string) error {
+	showTraces := cobrautil.MustGetBool(cmd, "show-traces")
+	schemaFile := cobrautil.MustGetString(cmd, "schema-file")
+	out := cmd.OutOrStdout()
+
+	var schema string
+	if schemaFile != "" {
+		contents, err := os.ReadFile(schemaFile)
+		if err != nil {
+			return fmt.Errorf("failed to read schema file: %w", err)
+		}
+		schema = string(contents)
+	}
+
+	failedFiles := 0
+	for _, filePath := range args {
+		failures, err := validateFile(cmd, filePath, schema)
+		if err != nil {
+			return fmt.Errorf("failed to validate %s: %w", filePath, err)
+		}
+
+		if len(failures) == 0 {
+			fmt.Fprintf(out, "%s: success\n", filePath)
+			continue
+		}
+
+		failedFiles++
+		for _, failure := range failures {
+			// Errors in a schema given by flag are reported against the schema file.
+			errorPath := filePath
+			if schemaFile != "" && failure.Source == devinterface.DeveloperError_SCHEMA {
+				errorPath = schemaFile
+			}
+			printDeveloperError(out, errorPath, failure, showTraces)
+		}
+	}
+
+	if failedFiles > 0 {
+		return fmt.Errorf("validation failed for %d of %d file(s)", failedFiles, len(args))
+	}
+	return nil
+}
+
+// isValidationFile returns whether the file is a validation file, rather than a schema file.
+func isValidationFile(filePath string) bool {
+	ext := strings.ToLower(filepath.Ext(filePath))
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// validateFile runs the schema, relationships, assertions and expected relations found in
+// the validation file, or the schema found in the schema file, returning any failures found.
+// If a schema is given, it is used as the schema of the validation file.
+func validateFile(cmd *cobra.Command, filePath string, schema string) ([]*devinterface.DeveloperError, error) {
+	contents, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed := &validationfile.ValidationFile{}
+	if isValidationFile(filePath) {
+		parsed, err = validationfile.DecodeValidationFile(contents)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		parsed.Schema.Schema = string(contents)
+	}
+
+	if schema != "" {
+		if parsed.Schema.Schema != "" {
+			return nil, errors.New("a schema cannot be given by both the file and --schema-file")
+		}
+		parsed.Schema.Schema = schema
+	}
+
+	relationships := make([]*core.RelationTuple, 0, len(parsed.Relationships.Relationships))
+	for _, rel := range parsed.Relationships.Relationships {
+		relationships = append(relationships, tuple.MustFromRelationship[*v1.ObjectReference, *v1.SubjectReference, *v1.ContextualizedCaveat](rel))
+	}
+
+	devContext, devErrs, err := development.NewDevContext(cmd.Context(), &devinterface.RequestContext{
+		Schema:        parsed.Schema.Schema,
+		Relationships: relationships,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if devErrs != nil {
+		return devErrs.InputErrors, nil
+	}
+	defer devContext.Dispose()
+
+	assertionFailures, err := development.RunAllAssertions(devContext, &parsed.Assertions)
+	if err != nil {
+		return nil, err
+	}
+
+	_, validationFailures, err := development.RunValidation(devContext, &parsed.ExpectedRelations)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(assertionFailures, validationFailures...), nil
+}
+
+func printDeveloperError(out io.Writer, filePath string, devErr *devinterface.DeveloperError, showTrace bool) {
+	fmt.Fprintf(out, "%s:%d:%d: %s\n", filePath, devErr.Line, devErr.Column, devErr.Message)
+
+	if showTrace && devErr.CheckResolvedDebugInformation != nil {
+		trace, err := protojson.MarshalOptions{Multiline: true}.Marshal(devErr.CheckResolvedDebugInformation)
+		if err != nil {
+			fmt.Fprintf(out, "  unable to format trace: %s\n", err)
+			return
+		}
+		fmt.Fprintf(out, "%s\n", trace)
+	}
+}
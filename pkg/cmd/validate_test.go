@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const validateTestSchema = `schema: |-
+  definition user {}
+
+  definition document {
+    relation viewer: user
+    permission view = viewer
+  }
+relationships: |-
+  document:1#viewer@user:jake
+`
+
+func TestValidateCommand(t *testing.T) {
+	tcs := []struct {
+		name           string
+		contents       string
+		expectedError  string
+		expectedOutput string
+	}{
+		{
+			"passing assertions",
+			validateTestSchema + `assertions:
+  assertTrue:
+    - document:1#view@user:jake
+  assertFalse:
+    - document:1#view@user:sarah
+`,
+			"",
+			"success",
+		},
+		{
+			"failing assertion",
+			validateTestSchema + `assertions:
+  assertTrue:
+    - document:1#view@user:sarah
+`,
+			"validation failed for 1 of 1 file(s)",
+			"Expected relation or permission document:1#view@user:sarah to exist",
+		},
+		{
+			"failing expected relations",
+			validateTestSchema + `validation:
+  document:1#view:
+    - "[user:sarah] is <document:1#viewer>"
+`,
+			"validation failed for 1 of 1 file(s)",
+			"user:jake",
+		},
+		{
+			"invalid schema",
+			`schema: |-
+  definition document {
+    relation viewer: user
+  }
+`,
+			"validation failed for 1 of 1 file(s)",
+			"user",
+		},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			filePath := filepath.Join(t.TempDir(), "validation.yaml")
+			require.NoError(t, os.WriteFile(filePath, []byte(tc.contents), 0o600))
+
+			cmd := NewValidateCommand("spicedb")
+			RegisterRootFlags(cmd)
+			RegisterValidateFlags(cmd)
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+			cmd.SetArgs([]string{filePath})
+
+			err := cmd.Execute()
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Contains(t, out.String(), tc.expectedOutput)
+		})
+	}
+}
+
+func TestValidateCommandSchemaFiles(t *testing.T) {
+	dir := t.TempDir()
+	schemaPath := filepath.Join(dir, "schema.zed")
+	require.NoError(t, os.WriteFile(schemaPath, []byte(`definition user {}
+
+definition document {
+  relation viewer: user
+  permission view = viewer
+}`), 0o600))
+
+	invalidSchemaPath := filepath.Join(dir, "invalid.zed")
+	require.NoError(t, os.WriteFile(invalidSchemaPath, []byte(`definition document {
+  relation viewer: user
+}`), 0o600))
+
+	assertionsPath := filepath.Join(dir, "assertions.yaml")
+	require.NoError(t, os.WriteFile(assertionsPath, []byte(`relationships: |-
+  document:1#viewer@user:jake
+assertions:
+  assertTrue:
+    - document:1#view@user:jake
+`), 0o600))
+
+	tcs := []struct {
+		name           string
+		args           []string
+		expectedError  string
+		expectedOutput string
+	}{
+		{"valid schema file", []string{schemaPath}, "", "schema.zed: success"},
+		{"invalid schema file", []string{invalidSchemaPath}, "validation failed for 1 of 1 file(s)", "invalid.zed:"},
+		{"assertions against schema file", []string{"--schema-file", schemaPath, assertionsPath}, "", "assertions.yaml: success"},
+		{"assertions against invalid schema file", []string{"--schema-file", invalidSchemaPath, assertionsPath}, "validation failed for 1 of 1 file(s)", "invalid.zed:"},
+		{"schema given twice", []string{"--schema-file", schemaPath, schemaPath}, "cannot be given by both", ""},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cmd := NewValidateCommand("spicedb")
+			RegisterRootFlags(cmd)
+			RegisterValidateFlags(cmd)
+
+			var out bytes.Buffer
+			cmd.SetOut(&out)
+			cmd.SetArgs(tc.args)
+
+			err := cmd.Execute()
+			if tc.expectedError != "" {
+				require.ErrorContains(t, err, tc.expectedError)
+			} else {
+				require.NoError(t, err)
+			}
+			require.Contains(t, out.String(), tc.expectedOutput)
+		})
+	}
+}
@@ -1,18 +1,101 @@
 package cmd
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"runtime/debug"
+
 	"github.com/jzelinskie/cobrautil/v2"
 	"github.com/spf13/cobra"
 )
 
+// BuildDate is the date on which the binary was built. This should be set with the
+// following flag to the `go build` command, and otherwise falls back to the time of
+// the VCS revision:
+// -ldflags '-X github.com/authzed/spicedb/pkg/cmd.BuildDate=$DATE'
+var BuildDate string
+
+// VersionInfo describes the build of the running binary.
+type VersionInfo struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"buildDate"`
+	GoVersion string `json:"goVersion"`
+}
+
+// CurrentVersionInfo returns the version info of the running binary.
+func CurrentVersionInfo() VersionInfo {
+	info := VersionInfo{
+		Version:   cobrautil.Version,
+		BuildDate: BuildDate,
+		GoVersion: runtime.Version(),
+	}
+
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	info.Version = cobrautil.VersionWithFallbacks(bi)
+	for _, setting := range bi.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Commit = setting.Value
+		case "vcs.time":
+			if info.BuildDate == "" {
+				info.BuildDate = setting.Value
+			}
+		}
+	}
+	return info
+}
+
+func writeVersion(w io.Writer, programName string, includeDeps, asJSON bool) error {
+	if asJSON {
+		return json.NewEncoder(w).Encode(CurrentVersionInfo())
+	}
+
+	_, err := io.WriteString(w, formatVersion(programName, includeDeps))
+	return err
+}
+
+func formatVersion(programName string, includeDeps bool) string {
+	info := CurrentVersionInfo()
+	return fmt.Sprintf("%s\ncommit: %s\nbuild date: %s\ngo version: %s\n",
+		cobrautil.UsageVersion(programName, includeDeps),
+		valueOrUnknown(info.Commit),
+		valueOrUnknown(info.BuildDate),
+		info.GoVersion,
+	)
+}
+
+func valueOrUnknown(value string) string {
+	if value == "" {
+		return "unknown"
+	}
+	return value
+}
+
 func RegisterVersionFlags(cmd *cobra.Command) {
 	cobrautil.RegisterVersionFlags(cmd.Flags())
+	cmd.Flags().Bool("json", false, "output the version as JSON")
 }
 
 func NewVersionCommand(programName string) *cobra.Command {
 	return &cobra.Command{
 		Use:   "version",
 		Short: "displays the version of SpiceDB",
-		RunE:  cobrautil.VersionRunFunc(programName),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return writeVersion(cmd.OutOrStdout(), programName, cobrautil.MustGetBool(cmd, "include-deps"), cobrautil.MustGetBool(cmd, "json"))
+		},
 	}
 }
+
+// RegisterVersionFlag adds a `--version` flag to the root command, which displays the
+// version of SpiceDB as the version command does.
+func RegisterVersionFlag(rootCmd *cobra.Command) {
+	rootCmd.Version = CurrentVersionInfo().Version
+	rootCmd.SetVersionTemplate(formatVersion(rootCmd.Use, false))
+}
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionCommand(t *testing.T) {
+	versionCmd := NewVersionCommand("spicedb")
+	RegisterVersionFlags(versionCmd)
+
+	var out bytes.Buffer
+	versionCmd.SetOut(&out)
+	versionCmd.SetArgs([]string{"--json"})
+	require.NoError(t, versionCmd.Execute())
+
+	var info VersionInfo
+	require.NoError(t, json.Unmarshal(out.Bytes(), &info))
+	require.Equal(t, runtime.Version(), info.GoVersion)
+
+	versionCmd = NewVersionCommand("spicedb")
+	RegisterVersionFlags(versionCmd)
+
+	out.Reset()
+	versionCmd.SetOut(&out)
+	versionCmd.SetArgs([]string{})
+	require.NoError(t, versionCmd.Execute())
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 4)
+	require.True(t, strings.HasPrefix(lines[0], "spicedb "))
+	require.Equal(t, "go version: "+runtime.Version(), lines[3])
+}
@@ -60,6 +60,10 @@ type RevisionChanges struct {
 	// DeletedCaveats are any caveats that were deleted.
 	DeletedCaveats []string
 
+	// Metadata is the caller-supplied metadata attached to the transaction that produced
+	// the changes, if any and if supported by the datastore.
+	Metadata map[string]string
+
 	// IsCheckpoint, if true, indicates that the datastore has reported all changes
 	// up until and including the Revision and that no additional schema updates can
 	// have occurred before this point.
@@ -89,6 +93,10 @@ type RelationshipsFilter struct {
 	// OptionalResourceIds are the IDs of the resources to find. If nil empty, any resource ID will be allowed.
 	OptionalResourceIds []string
 
+	// OptionalResourceIDPrefix is the prefix to use for resource IDs. If empty, any prefix is allowed.
+	// Cannot be combined with OptionalResourceIds.
+	OptionalResourceIDPrefix string
+
 	// OptionalResourceRelation is the relation of the resource to find. If empty, any relation is allowed.
 	OptionalResourceRelation string
 
@@ -99,6 +107,11 @@ type RelationshipsFilter struct {
 	// OptionalCaveatName is the filter to use for caveated relationships, filtering by a specific caveat name.
 	// If nil, all caveated and non-caveated relationships are allowed
 	OptionalCaveatName string
+
+	// OptionalLabels are the labels, by key, which relationships must all have to be found. If
+	// empty, relationships with any labels are allowed. Only supported by datastores with the
+	// RelationshipLabels feature.
+	OptionalLabels map[string]string
 }
 
 // RelationshipsFilterFromPublicFilter constructs a datastore RelationshipsFilter from an API-defined RelationshipFilter.
@@ -467,6 +480,42 @@ type RepairableDatastore interface {
 	RepairOperations() []RepairOperation
 }
 
+// ChangelogCheckableDatastore is an optional extension to the datastore interface that, when
+// implemented, provides the ability for callers to check the changelog of the datastore for gaps.
+type ChangelogCheckableDatastore interface {
+	Datastore
+
+	// CheckChangelog returns a description of each gap found in the changelog retained by the
+	// datastore, such as changes made by transactions which it did not record.
+	CheckChangelog(ctx context.Context) ([]string, error)
+}
+
+// PointInTimeDatastore is an optional extension to the datastore interface that, when
+// implemented, provides the ability for callers to read the datastore as it was at a point in
+// time.
+type PointInTimeDatastore interface {
+	Datastore
+
+	// RevisionAtTime returns the latest revision of the datastore at or before the given time.
+	// If the revision has fallen outside of the GC window, an ErrInvalidRevision is returned.
+	RevisionAtTime(ctx context.Context, at time.Time) (Revision, error)
+
+	// EarliestRevision returns the earliest revision of the datastore which has not fallen
+	// outside of the GC window, and so can still be read or watched from.
+	EarliestRevision(ctx context.Context) (Revision, error)
+}
+
+// RevisionPinningDatastore is an optional extension to the datastore interface that, when
+// implemented, provides the ability for long-running reads, such as exports, to outlive the GC
+// window by holding back the garbage collection run by this node.
+type RevisionPinningDatastore interface {
+	Datastore
+
+	// PinRevision keeps the data visible at the revision from being garbage collected until the
+	// returned function is called.
+	PinRevision(revision Revision) (unpin func())
+}
+
 // UnwrappableDatastore represents a datastore that can be unwrapped into the underlying
 // datastore.
 type UnwrappableDatastore interface {
@@ -509,6 +558,14 @@ type Feature struct {
 type Features struct {
 	// Watch is enabled if the underlying datastore can support the Watch api.
 	Watch Feature
+
+	// IntegrityData is enabled if the underlying datastore can store and return the
+	// integrity data of relationships.
+	IntegrityData Feature
+
+	// RelationshipLabels is enabled if the underlying datastore can store, return and filter by
+	// the labels of relationships.
+	RelationshipLabels Feature
 }
 
 // ObjectTypeStat represents statistics for a single object type (namespace).
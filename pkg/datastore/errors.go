@@ -3,6 +3,7 @@ package datastore
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/rs/zerolog"
 )
@@ -55,6 +56,24 @@ type ErrWatchDisabled struct{ error }
 // read-only mode.
 type ErrReadOnly struct{ error }
 
+// ErrPointInTimeUnsupported is returned when the datastore cannot determine its revision at a
+// point in time.
+type ErrPointInTimeUnsupported struct{ error }
+
+// ErrTransactionMetadataUnsupported is returned when metadata is written with a transaction to
+// a datastore that cannot store it.
+type ErrTransactionMetadataUnsupported struct {
+	error
+	engine string
+}
+
+// DetailsMetadata returns the metadata for details for this error.
+func (err ErrTransactionMetadataUnsupported) DetailsMetadata() map[string]string {
+	return map[string]string{
+		"datastore_engine": err.engine,
+	}
+}
+
 // ErrWatchRetryable is returned when a transient/temporary error occurred in watch and indicates that
 // the caller *may* retry the watch after some backoff time.
 type ErrWatchRetryable struct{ error }
@@ -146,6 +165,23 @@ func NewReadonlyErr() error {
 	}
 }
 
+// NewPointInTimeUnsupportedErr constructs an error for when a request has failed because the
+// datastore cannot determine its revision at a point in time.
+func NewPointInTimeUnsupportedErr() error {
+	return ErrPointInTimeUnsupported{
+		error: fmt.Errorf("datastore does not support reads at a point in time"),
+	}
+}
+
+// NewTransactionMetadataUnsupportedErr constructs an error for when a request has failed because
+// the datastore cannot store the metadata written with the transaction.
+func NewTransactionMetadataUnsupportedErr(engine string) error {
+	return ErrTransactionMetadataUnsupported{
+		error:  fmt.Errorf("the %s datastore does not support transaction metadata", engine),
+		engine: engine,
+	}
+}
+
 // NewInvalidRevisionErr constructs a new invalid revision error.
 func NewInvalidRevisionErr(revision Revision, reason InvalidRevisionReason) error {
 	switch reason {
@@ -197,6 +233,65 @@ func (err ErrCaveatNameNotFound) DetailsMetadata() map[string]string {
 	}
 }
 
+// ErrRelationshipIntegrity is returned when a relationship read from the datastore is missing
+// its integrity data or does not match it, as happens when the relationship was written or
+// modified other than by SpiceDB.
+type ErrRelationshipIntegrity struct {
+	error
+	relationship string
+}
+
+// Relationship returns the relationship which failed verification.
+func (err ErrRelationshipIntegrity) Relationship() string {
+	return err.relationship
+}
+
+// NewRelationshipIntegrityErr constructs a new relationship integrity error.
+func NewRelationshipIntegrityErr(relationship string, reason string) error {
+	return ErrRelationshipIntegrity{
+		error:        fmt.Errorf("relationship `%s` failed integrity verification: %s", relationship, reason),
+		relationship: relationship,
+	}
+}
+
+// DetailsMetadata returns the metadata for details for this error.
+func (err ErrRelationshipIntegrity) DetailsMetadata() map[string]string {
+	return map[string]string{
+		"relationship": err.relationship,
+	}
+}
+
+// ErrClockSkew is returned when a write is rejected because the clock of the server differs from
+// that of the datastore, or has moved back, by more than the configured maximum skew, such that
+// the revision of the write could be ordered before those already committed.
+type ErrClockSkew struct {
+	error
+	skew    time.Duration
+	maxSkew time.Duration
+}
+
+// Skew returns the skew of the clock which was measured.
+func (err ErrClockSkew) Skew() time.Duration {
+	return err.skew
+}
+
+// NewClockSkewErr constructs a new clock skew error, with the description of the skew measured.
+func NewClockSkewErr(description string, skew, maxSkew time.Duration) error {
+	return ErrClockSkew{
+		error:   fmt.Errorf("write rejected: %s by %s, more than the maximum clock skew of %s; synchronize the clocks with NTP or raise --datastore-max-clock-skew", description, skew, maxSkew),
+		skew:    skew,
+		maxSkew: maxSkew,
+	}
+}
+
+// DetailsMetadata returns the metadata for details for this error.
+func (err ErrClockSkew) DetailsMetadata() map[string]string {
+	return map[string]string{
+		"clock_skew":     err.skew.String(),
+		"max_clock_skew": err.maxSkew.String(),
+	}
+}
+
 var (
 	ErrClosedIterator        = errors.New("unable to iterate: iterator closed")
 	ErrCursorsWithoutSorting = errors.New("cursors are disabled on unsorted results")
@@ -51,7 +51,8 @@ type ResourceRelation struct {
 // RWTOptions are options that can affect the way a read-write transaction is
 // executed.
 type RWTOptions struct {
-	DisableRetries bool `debugmap:"visible"`
+	DisableRetries bool              `debugmap:"visible"`
+	Metadata       map[string]string `debugmap:"visible"`
 }
 
 // DeleteOptions are the options that can affect the results of a delete relationships
@@ -192,6 +192,7 @@ func NewRWTOptionsWithOptionsAndDefaults(opts ...RWTOptionsOption) *RWTOptions {
 func (r *RWTOptions) ToOption() RWTOptionsOption {
 	return func(to *RWTOptions) {
 		to.DisableRetries = r.DisableRetries
+		to.Metadata = r.Metadata
 	}
 }
 
@@ -199,6 +200,7 @@ func (r *RWTOptions) ToOption() RWTOptionsOption {
 func (r RWTOptions) DebugMap() map[string]any {
 	debugMap := map[string]any{}
 	debugMap["DisableRetries"] = helpers.DebugValue(r.DisableRetries, false)
+	debugMap["Metadata"] = helpers.DebugValue(r.Metadata, false)
 	return debugMap
 }
 
@@ -224,3 +226,17 @@ func WithDisableRetries(disableRetries bool) RWTOptionsOption {
 		r.DisableRetries = disableRetries
 	}
 }
+
+// WithMetadata returns an option that can append Metadatas to RWTOptions.Metadata
+func WithMetadata(key string, value string) RWTOptionsOption {
+	return func(r *RWTOptions) {
+		r.Metadata[key] = value
+	}
+}
+
+// SetMetadata returns an option that can set Metadata on a RWTOptions
+func SetMetadata(metadata map[string]string) RWTOptionsOption {
+	return func(r *RWTOptions) {
+		r.Metadata = metadata
+	}
+}
@@ -92,12 +92,14 @@ func AllWithExceptions(t *testing.T, tester DatastoreTester, except Categories)
 
 	t.Run("TestSimple", func(t *testing.T) { SimpleTest(t, tester) })
 	t.Run("TestObjectIDs", func(t *testing.T) { ObjectIDsTest(t, tester) })
+	t.Run("TestResourceIDPrefix", func(t *testing.T) { ResourceIDPrefixTest(t, tester) })
 	t.Run("TestDeleteRelationships", func(t *testing.T) { DeleteRelationshipsTest(t, tester) })
 	t.Run("TestDeleteNonExistant", func(t *testing.T) { DeleteNotExistantTest(t, tester) })
 	t.Run("TestDeleteAlreadyDeleted", func(t *testing.T) { DeleteAlreadyDeletedTest(t, tester) })
 	t.Run("TestWriteDeleteWrite", func(t *testing.T) { WriteDeleteWriteTest(t, tester) })
 	t.Run("TestCreateAlreadyExisting", func(t *testing.T) { CreateAlreadyExistingTest(t, tester) })
 	t.Run("TestTouchAlreadyExisting", func(t *testing.T) { TouchAlreadyExistingTest(t, tester) })
+	t.Run("TestRelationshipLabels", func(t *testing.T) { RelationshipLabelsTest(t, tester) })
 	t.Run("TestCreateDeleteTouch", func(t *testing.T) { CreateDeleteTouchTest(t, tester) })
 	t.Run("TestDeleteOneThousandIndividualInOneCall", func(t *testing.T) { DeleteOneThousandIndividualInOneCallTest(t, tester) })
 	t.Run("TestCreateTouchDeleteTouch", func(t *testing.T) { CreateTouchDeleteTouchTest(t, tester) })
@@ -105,9 +107,12 @@ func AllWithExceptions(t *testing.T, tester DatastoreTester, except Categories)
 	t.Run("TestBulkDeleteRelationships", func(t *testing.T) { BulkDeleteRelationshipsTest(t, tester) })
 	t.Run("TestDeleteCaveatedTuple", func(t *testing.T) { DeleteCaveatedTupleTest(t, tester) })
 	t.Run("TestDeleteWithLimit", func(t *testing.T) { DeleteWithLimitTest(t, tester) })
+	t.Run("TestLargeWriteAtomicity", func(t *testing.T) { LargeWriteAtomicityTest(t, tester) })
 
 	t.Run("TestMultipleReadsInRWT", func(t *testing.T) { MultipleReadsInRWTTest(t, tester) })
 	t.Run("TestConcurrentWriteSerialization", func(t *testing.T) { ConcurrentWriteSerializationTest(t, tester) })
+	t.Run("TestSnapshotIsolation", func(t *testing.T) { SnapshotIsolationTest(t, tester) })
+	t.Run("TestPrecondition", func(t *testing.T) { PreconditionTest(t, tester) })
 
 	t.Run("TestOrdering", func(t *testing.T) { OrderingTest(t, tester) })
 	t.Run("TestLimit", func(t *testing.T) { LimitTest(t, tester) })
@@ -123,6 +128,7 @@ func AllWithExceptions(t *testing.T, tester DatastoreTester, except Categories)
 	if !except.GC() {
 		t.Run("TestRevisionGC", func(t *testing.T) { RevisionGCTest(t, tester) })
 		t.Run("TestInvalidReads", func(t *testing.T) { InvalidReadsTest(t, tester) })
+		t.Run("TestGCWindow", func(t *testing.T) { GCWindowTest(t, tester) })
 	}
 
 	t.Run("TestBulkUpload", func(t *testing.T) { BulkUploadTest(t, tester) })
@@ -141,6 +147,7 @@ func AllWithExceptions(t *testing.T, tester DatastoreTester, except Categories)
 	if !except.Watch() {
 		t.Run("TestWatchBasic", func(t *testing.T) { WatchTest(t, tester) })
 		t.Run("TestWatchCancel", func(t *testing.T) { WatchCancelTest(t, tester) })
+		t.Run("TestWatchOrdering", func(t *testing.T) { WatchOrderingTest(t, tester) })
 		t.Run("TestCaveatedRelationshipWatch", func(t *testing.T) { CaveatedRelationshipWatchTest(t, tester) })
 		t.Run("TestWatchWithTouch", func(t *testing.T) { WatchWithTouchTest(t, tester) })
 		t.Run("TestWatchWithDelete", func(t *testing.T) { WatchWithDeleteTest(t, tester) })
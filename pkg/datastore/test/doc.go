@@ -0,0 +1,17 @@
+// Package test implements the conformance suite of SpiceDB datastores. Every datastore engine,
+// including those implemented outside of SpiceDB, is expected to pass it to be compatible: it
+// covers the storage of namespaces, caveats and relationships, snapshot isolation, the semantics
+// of preconditions, pagination, revisions, garbage collection and the ordering of watched changes.
+//
+// To run the suite against an engine, call All, or AllWithExceptions to exclude the categories
+// of tests the engine does not support, from a test with a DatastoreTester creating a new, empty
+// datastore for each test:
+//
+//	func TestMyDatastore(t *testing.T) {
+//		test.All(t, test.DatastoreTesterFunc(func(revisionQuantization, gcInterval, gcWindow time.Duration, watchBufferLength uint16) (datastore.Datastore, error) {
+//			return mydatastore.New(revisionQuantization, gcInterval, gcWindow, watchBufferLength)
+//		}))
+//	}
+//
+// Datastores created by the tester must also implement TestableDatastore.
+package test
@@ -172,6 +172,37 @@ func RevisionGCTest(t *testing.T, tester DatastoreTester) {
 	require.Error(ds.CheckRevision(ctx, previousRev), "expected revision head-1 to be outside GC Window")
 }
 
+// GCWindowTest tests that garbage collection only removes the relationships deleted before the
+// GC window, so that every revision within it can still be read as it was written.
+func GCWindowTest(t *testing.T, tester DatastoreTester) {
+	require := require.New(t)
+
+	ds, err := tester.New(0, 10*time.Millisecond, veryLargeGCWindow, 1)
+	require.NoError(err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	setupDatastore(ds, require)
+
+	tpl := makeTestTuple("deleted", "user")
+	writtenRev, err := common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE, tpl)
+	require.NoError(err)
+
+	deletedRev, err := common.WriteTuples(ctx, ds, core.RelationTupleUpdate_DELETE, tpl)
+	require.NoError(err)
+
+	gcable, ok := ds.(common.GarbageCollector)
+	if ok {
+		gcable.ResetGCCompleted()
+		require.Eventually(func() bool { return gcable.HasGCRun() }, 5*time.Second, 50*time.Millisecond, "GC was never run as expected")
+	}
+
+	require.NoError(ds.CheckRevision(ctx, writtenRev), "expected revision within the GC window to be valid")
+	requireSnapshotTuples(ctx, require, ds.SnapshotReader(writtenRev), tpl)
+	requireSnapshotTuples(ctx, require, ds.SnapshotReader(deletedRev))
+}
+
 func SequentialRevisionsTest(t *testing.T, tester DatastoreTester) {
 	require := require.New(t)
 
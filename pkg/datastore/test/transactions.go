@@ -8,8 +8,11 @@ import (
 
 	"github.com/stretchr/testify/require"
 
+	"github.com/authzed/spicedb/internal/datastore/common"
 	"github.com/authzed/spicedb/pkg/datastore"
 	"github.com/authzed/spicedb/pkg/datastore/options"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
 )
 
 func RetryTest(t *testing.T, tester DatastoreTester) {
@@ -57,3 +60,106 @@ func RetryTest(t *testing.T, tester DatastoreTester) {
 		})
 	}
 }
+
+// SnapshotIsolationTest tests that snapshot readers observe the relationships as of their
+// revision, regardless of the transactions committed after it.
+func SnapshotIsolationTest(t *testing.T, tester DatastoreTester) {
+	require := require.New(t)
+
+	ds, err := tester.New(0, veryLargeGCInterval, veryLargeGCWindow, 1)
+	require.NoError(err)
+
+	setupDatastore(ds, require)
+	ctx := context.Background()
+
+	first := makeTestTuple("first", "user")
+	second := makeTestTuple("second", "user")
+
+	firstRev, err := common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE, first)
+	require.NoError(err)
+
+	// A reader obtained before the next transaction must not observe it either.
+	reader := ds.SnapshotReader(firstRev)
+
+	secondRev, err := common.UpdateTuplesInDatastore(ctx, ds, tuple.Delete(first), tuple.Create(second))
+	require.NoError(err)
+	require.True(secondRev.GreaterThan(firstRev))
+
+	requireSnapshotTuples(ctx, require, reader, first)
+	requireSnapshotTuples(ctx, require, ds.SnapshotReader(firstRev), first)
+	requireSnapshotTuples(ctx, require, ds.SnapshotReader(secondRev), second)
+}
+
+// PreconditionTest tests the semantics on which the preconditions of writes rely: relationships
+// queried within a read-write transaction reflect the transactions committed before it, and an
+// error returned by the transaction discards all of its writes.
+func PreconditionTest(t *testing.T, tester DatastoreTester) {
+	require := require.New(t)
+
+	ds, err := tester.New(0, veryLargeGCInterval, veryLargeGCWindow, 1)
+	require.NoError(err)
+
+	setupDatastore(ds, require)
+	ctx := context.Background()
+
+	existing := makeTestTuple("existing", "user")
+	_, err = common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE, existing)
+	require.NoError(err)
+
+	errPreconditionFailed := errors.New("precondition failed")
+	writeIfExists := func(resourceID string, tpl *core.RelationTuple) error {
+		_, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+			if err := rwt.WriteRelationships(ctx, []*core.RelationTupleUpdate{tuple.Create(tpl)}); err != nil {
+				return err
+			}
+
+			iter, err := rwt.QueryRelationships(ctx, datastore.RelationshipsFilter{
+				ResourceType:        testResourceNamespace,
+				OptionalResourceIds: []string{resourceID},
+			})
+			if err != nil {
+				return err
+			}
+			defer iter.Close()
+
+			if iter.Next() == nil {
+				if iter.Err() != nil {
+					return iter.Err()
+				}
+				return errPreconditionFailed
+			}
+			return nil
+		}, options.WithDisableRetries(true))
+		return err
+	}
+
+	failed := makeTestTuple("failed", "user")
+	require.ErrorIs(writeIfExists("missing", failed), errPreconditionFailed)
+	ensureNotTuples(ctx, require, ds, failed)
+
+	succeeded := makeTestTuple("succeeded", "user")
+	require.NoError(writeIfExists(existing.ResourceAndRelation.ObjectId, succeeded))
+	ensureTuples(ctx, require, ds, existing, succeeded)
+}
+
+// requireSnapshotTuples requires the test resource relationships read by the reader to be
+// exactly those expected.
+func requireSnapshotTuples(ctx context.Context, require *require.Assertions, reader datastore.Reader, expected ...*core.RelationTuple) {
+	iter, err := reader.QueryRelationships(ctx, datastore.RelationshipsFilter{
+		ResourceType: testResourceNamespace,
+	})
+	require.NoError(err)
+	defer iter.Close()
+
+	found := make([]string, 0, len(expected))
+	for tpl := iter.Next(); tpl != nil; tpl = iter.Next() {
+		found = append(found, tuple.MustString(tpl))
+	}
+	require.NoError(iter.Err())
+
+	expectedStrings := make([]string, 0, len(expected))
+	for _, tpl := range expected {
+		expectedStrings = append(expectedStrings, tuple.MustString(tpl))
+	}
+	require.ElementsMatch(expectedStrings, found)
+}
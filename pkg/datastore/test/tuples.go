@@ -302,6 +302,70 @@ func ObjectIDsTest(t *testing.T, tester DatastoreTester) {
 	}
 }
 
+// ResourceIDPrefixTest tests whether or not the requirements for filtering relationships by
+// a resource ID prefix hold for a particular datastore.
+func ResourceIDPrefixTest(t *testing.T, tester DatastoreTester) {
+	ctx := context.Background()
+	require := require.New(t)
+
+	ds, err := tester.New(0, veryLargeGCInterval, veryLargeGCWindow, 1)
+	require.NoError(err)
+	defer ds.Close()
+
+	resourceIDs := []string{
+		"org1_doc1",
+		"org1_doc2",
+		"org1-doc3",
+		"org10_doc1",
+		"orgAdoc1",
+		"org2_doc1",
+	}
+
+	updates := make([]*core.RelationTupleUpdate, 0, len(resourceIDs))
+	for _, resourceID := range resourceIDs {
+		updates = append(updates, &core.RelationTupleUpdate{
+			Operation: core.RelationTupleUpdate_CREATE,
+			Tuple:     makeTestTuple(resourceID, "someuser"),
+		})
+	}
+
+	rev, err := ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteRelationships(ctx, updates)
+	})
+	require.NoError(err)
+
+	testCases := []struct {
+		prefix      string
+		expectedIDs []string
+	}{
+		{"org1", []string{"org1_doc1", "org1_doc2", "org1-doc3", "org10_doc1"}},
+		{"org1_", []string{"org1_doc1", "org1_doc2"}},
+		{"org1-", []string{"org1-doc3"}},
+		{"org2", []string{"org2_doc1"}},
+		{"org3", nil},
+		{"rg1", nil},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.prefix, func(t *testing.T) {
+			iter, err := ds.SnapshotReader(rev).QueryRelationships(ctx, datastore.RelationshipsFilter{
+				ResourceType:             testResourceNamespace,
+				OptionalResourceIDPrefix: tc.prefix,
+			}, options.WithSort(options.ByResource))
+			require.NoError(err)
+			defer iter.Close()
+
+			foundIDs := make([]string, 0, len(tc.expectedIDs))
+			for found := iter.Next(); found != nil; found = iter.Next() {
+				foundIDs = append(foundIDs, found.ResourceAndRelation.ObjectId)
+			}
+			require.NoError(iter.Err())
+			require.ElementsMatch(tc.expectedIDs, foundIDs)
+		})
+	}
+}
+
 // DeleteRelationshipsTest tests whether or not the requirements for deleting
 // relationships hold for a particular datastore.
 func DeleteRelationshipsTest(t *testing.T, tester DatastoreTester) {
@@ -607,6 +671,64 @@ func TouchAlreadyExistingTest(t *testing.T, tester DatastoreTester) {
 	ensureTuples(ctx, require, ds, tpl1, tpl2, tpl3)
 }
 
+// RelationshipLabelsTest tests writing relationships with labels, and filtering by them.
+func RelationshipLabelsTest(t *testing.T, tester DatastoreTester) {
+	require := require.New(t)
+
+	rawDS, err := tester.New(0, veryLargeGCInterval, veryLargeGCWindow, 1)
+	require.NoError(err)
+
+	ctx := context.Background()
+	features, err := rawDS.Features(ctx)
+	require.NoError(err)
+	if !features.RelationshipLabels.Enabled {
+		t.Skip("datastore does not support relationship labels")
+	}
+
+	ds, _ := testfixtures.StandardDatastoreWithData(rawDS, require)
+
+	tpl1 := makeTestTuple("foo", "tom")
+	tpl1.Labels = tuple.LabelsFromMap(map[string]string{"source": "crm", "batch": "1"})
+	tpl2 := makeTestTuple("foo", "sarah")
+	tpl2.Labels = tuple.LabelsFromMap(map[string]string{"source": "erp"})
+	tpl3 := makeTestTuple("foo", "fred")
+
+	rev, err := common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE, tpl1, tpl2, tpl3)
+	require.NoError(err)
+
+	queryLabeled := func(rev datastore.Revision, labels map[string]string) []*core.RelationTuple {
+		iter, err := ds.SnapshotReader(rev).QueryRelationships(ctx, datastore.RelationshipsFilter{
+			ResourceType:   testResourceNamespace,
+			OptionalLabels: labels,
+		}, options.WithSort(options.ByResource))
+		require.NoError(err)
+		defer iter.Close()
+
+		var found []*core.RelationTuple
+		for tpl := iter.Next(); tpl != nil; tpl = iter.Next() {
+			found = append(found, tpl)
+		}
+		require.NoError(iter.Err())
+		return found
+	}
+
+	found := queryLabeled(rev, map[string]string{"source": "crm"})
+	require.Len(found, 1)
+	require.Equal(tuple.MustString(tpl1), tuple.MustString(found[0]))
+	require.Equal(tpl1.Labels, found[0].Labels)
+
+	require.Empty(queryLabeled(rev, map[string]string{"source": "crm", "batch": "2"}))
+	require.Len(queryLabeled(rev, nil), 3)
+
+	// Touching a relationship replaces its labels.
+	tpl1.Labels = tuple.LabelsFromMap(map[string]string{"source": "erp"})
+	rev, err = common.WriteTuples(ctx, ds, core.RelationTupleUpdate_TOUCH, tpl1)
+	require.NoError(err)
+
+	require.Empty(queryLabeled(rev, map[string]string{"source": "crm"}))
+	require.Len(queryLabeled(rev, map[string]string{"source": "erp"}), 2)
+}
+
 // CreateDeleteTouchTest tests writing a relationship, deleting it, and then touching it.
 func CreateDeleteTouchTest(t *testing.T, tester DatastoreTester) {
 	require := require.New(t)
@@ -671,6 +793,71 @@ func DeleteOneThousandIndividualInOneCallTest(t *testing.T, tester DatastoreTest
 	ensureTuples(ctx, require, ds, makeTestTuple("foo", "extra"))
 }
 
+// LargeWriteAtomicityTest tests that a write of many relationships is applied at a single revision,
+// and that a write which fails part way through applies none of its updates.
+func LargeWriteAtomicityTest(t *testing.T, tester DatastoreTester) {
+	require := require.New(t)
+
+	rawDS, err := tester.New(0, veryLargeGCInterval, veryLargeGCWindow, 1)
+	require.NoError(err)
+
+	ds, _ := testfixtures.StandardDatastoreWithSchema(rawDS, require)
+	ctx := context.Background()
+
+	tuples := make([]*core.RelationTuple, 0, 1000)
+	for i := 0; i < 1000; i++ {
+		tuples = append(tuples, makeTestTuple("atomic", fmt.Sprintf("user%d", i)))
+	}
+
+	// Write all of the relationships in one transaction.
+	beforeRev, err := ds.HeadRevision(ctx)
+	require.NoError(err)
+
+	writtenRev, err := common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE, tuples...)
+	require.NoError(err)
+	require.True(writtenRev.GreaterThan(beforeRev))
+
+	// None of the relationships are visible before the revision of the write, and all are
+	// visible at it.
+	require.Equal(0, countResourceTuples(ctx, require, ds.SnapshotReader(beforeRev), "atomic"))
+	require.Equal(len(tuples), countResourceTuples(ctx, require, ds.SnapshotReader(writtenRev), "atomic"))
+
+	// A write whose last update fails applies none of the others.
+	failing := make([]*core.RelationTupleUpdate, 0, len(tuples))
+	for i := 0; i < len(tuples)-1; i++ {
+		failing = append(failing, tuple.Create(makeTestTuple("atomic_failed", fmt.Sprintf("user%d", i))))
+	}
+	failing = append(failing, tuple.Create(tuples[0]))
+
+	_, err = ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		return rwt.WriteRelationships(ctx, failing)
+	})
+	require.ErrorAs(err, &common.CreateRelationshipExistsError{})
+
+	headRev, err := ds.HeadRevision(ctx)
+	require.NoError(err)
+	require.Equal(0, countResourceTuples(ctx, require, ds.SnapshotReader(headRev), "atomic_failed"))
+	require.Equal(len(tuples), countResourceTuples(ctx, require, ds.SnapshotReader(headRev), "atomic"))
+
+	// A transaction which fails after its relationships were written applies none of them.
+	errAfterWrite := errors.New("failed after writing")
+	_, err = ds.ReadWriteTx(ctx, func(ctx context.Context, rwt datastore.ReadWriteTransaction) error {
+		updates := make([]*core.RelationTupleUpdate, 0, len(tuples))
+		for _, tpl := range tuples {
+			updates = append(updates, tuple.Delete(tpl))
+		}
+		if err := rwt.WriteRelationships(ctx, updates); err != nil {
+			return err
+		}
+		return errAfterWrite
+	})
+	require.ErrorIs(err, errAfterWrite)
+
+	headRev, err = ds.HeadRevision(ctx)
+	require.NoError(err)
+	require.Equal(len(tuples), countResourceTuples(ctx, require, ds.SnapshotReader(headRev), "atomic"))
+}
+
 // DeleteWithLimitTest tests deleting relationships with a limit.
 func DeleteWithLimitTest(t *testing.T, tester DatastoreTester) {
 	require := require.New(t)
@@ -1026,3 +1213,19 @@ func countTuples(ctx context.Context, require *require.Assertions, ds datastore.
 
 	return counter
 }
+
+func countResourceTuples(ctx context.Context, require *require.Assertions, reader datastore.Reader, resourceID string) int {
+	iter, err := reader.QueryRelationships(ctx, datastore.RelationshipsFilter{
+		ResourceType:        testResourceNamespace,
+		OptionalResourceIds: []string{resourceID},
+	})
+	require.NoError(err)
+	defer iter.Close()
+
+	counter := 0
+	for rel := iter.Next(); rel != nil; rel = iter.Next() {
+		counter++
+	}
+	require.NoError(iter.Err())
+	return counter
+}
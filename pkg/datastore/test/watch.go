@@ -184,6 +184,64 @@ func setOfChanges(changes []*core.RelationTupleUpdate) *strset.Set {
 	return changeSet
 }
 
+// WatchOrderingTest tests that the changes of a datastore are watched in the order of their
+// revisions, one revision per transaction, both as they happen and when catching up.
+func WatchOrderingTest(t *testing.T, tester DatastoreTester) {
+	require := require.New(t)
+
+	ds, err := tester.New(0, veryLargeGCInterval, veryLargeGCWindow, 16)
+	require.NoError(err)
+
+	lowestRevision := setupDatastore(ds, require)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes, errchan := ds.Watch(ctx, lowestRevision, datastore.WatchJustRelationships())
+	require.Zero(len(errchan))
+
+	const numTransactions = 10
+	expected := make([]*core.RelationTuple, 0, numTransactions)
+	for i := 0; i < numTransactions; i++ {
+		tpl := makeTestTuple(fmt.Sprintf("ordered%d", i), "user")
+		_, err := common.WriteTuples(ctx, ds, core.RelationTupleUpdate_CREATE, tpl)
+		require.NoError(err)
+		expected = append(expected, tpl)
+	}
+
+	verifyOrderedUpdates(require, expected, changes, errchan)
+
+	changes, errchan = ds.Watch(ctx, lowestRevision, datastore.WatchJustRelationships())
+	verifyOrderedUpdates(require, expected, changes, errchan)
+}
+
+func verifyOrderedUpdates(
+	require *require.Assertions,
+	expected []*core.RelationTuple,
+	changes <-chan *datastore.RevisionChanges,
+	errchan <-chan error,
+) {
+	var lastRevision datastore.Revision
+	for _, tpl := range expected {
+		changeWait := time.NewTimer(waitForChangesTimeout)
+		select {
+		case change, ok := <-changes:
+			require.True(ok, "unexpected disconnect")
+			if lastRevision != nil {
+				require.True(change.Revision.GreaterThan(lastRevision), "expected revision %s to be after %s", change.Revision, lastRevision)
+			}
+			lastRevision = change.Revision
+
+			require.Len(change.RelationshipChanges, 1)
+			require.Equal(tuple.MustString(tpl), tuple.MustString(change.RelationshipChanges[0].Tuple))
+		case err := <-errchan:
+			require.Failf("Unexpected error", "%s", err)
+		case <-changeWait.C:
+			require.Fail("Timed out", "waited for change: %s", tuple.MustString(tpl))
+		}
+	}
+}
+
 // WatchCancelTest tests whether or not the requirements for cancelling watches
 // hold for a particular datastore.
 func WatchCancelTest(t *testing.T, tester DatastoreTester) {
@@ -221,7 +221,7 @@ func TestCheckOperation(t *testing.T) {
 			&editCheckResult{
 				Relationship: tuple.MustParse("document:someobj#viewer@user:foo"),
 				Error: &devinterface.DeveloperError{
-					Message: "max depth exceeded: this usually indicates a recursive or too deep data dependency",
+					Message: "max depth exceeded: cycle detected: document:someobj#viewer@user:foo -> document:someobj#viewer@user:foo: this indicates a recursive data dependency",
 					Kind:    devinterface.DeveloperError_MAXIMUM_RECURSION,
 					Source:  devinterface.DeveloperError_CHECK_WATCH,
 					Context: "document:someobj#viewer@user:foo",
@@ -310,7 +310,7 @@ func TestCheckOperation(t *testing.T) {
 			tuple.MustParse("resource:someobj#view@user:foo"),
 			nil,
 			&devinterface.DeveloperError{
-				Message: "subjects of type `resource` are not allowed on relation `resource#viewer`",
+				Message: "subjects of type `resource` are not allowed on relation `resource#viewer`, which allows `user`: cannot write relationship `resource:someobj#viewer@resource:foo`",
 				Kind:    devinterface.DeveloperError_INVALID_SUBJECT_TYPE,
 				Source:  devinterface.DeveloperError_RELATIONSHIP,
 				Context: "resource:someobj#viewer@resource:foo",
@@ -333,7 +333,7 @@ func TestCheckOperation(t *testing.T) {
 			tuple.MustParse("resource:someobj#view@user:foo"),
 			nil,
 			&devinterface.DeveloperError{
-				Message: "subjects of type `user` are not allowed on relation `resource#viewer`",
+				Message: "subjects of type `user` are not allowed on relation `resource#viewer`, which allows `user with somecaveat`: cannot write relationship `resource:someobj#viewer@user:foo`",
 				Kind:    devinterface.DeveloperError_INVALID_SUBJECT_TYPE,
 				Source:  devinterface.DeveloperError_RELATIONSHIP,
 				Context: "resource:someobj#viewer@user:foo",
@@ -0,0 +1,170 @@
+// Package embedded runs the permissions engine of SpiceDB in the process of a Go program, so
+// that services for which the latency of a network hop matters can check permissions and write
+// relationships with direct function calls, while sharing their schema and datastore with the
+// servers of a cluster.
+//
+// The engine evaluates requests exactly as the API does, through the same services and
+// validation, but without gRPC: requests and responses are neither serialized nor sent over a
+// connection. Errors are returned as gRPC statuses, as they would be by a server.
+package embedded
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/grpc"
+
+	"github.com/authzed/spicedb/internal/dispatch"
+	"github.com/authzed/spicedb/internal/dispatch/combined"
+	"github.com/authzed/spicedb/internal/dispatch/graph"
+	"github.com/authzed/spicedb/internal/middleware"
+	"github.com/authzed/spicedb/internal/middleware/consistency"
+	datastoremw "github.com/authzed/spicedb/internal/middleware/datastore"
+	"github.com/authzed/spicedb/internal/middleware/servicespecific"
+	v1svc "github.com/authzed/spicedb/internal/services/v1"
+	"github.com/authzed/spicedb/pkg/cache"
+	"github.com/authzed/spicedb/pkg/datastore"
+)
+
+const (
+	permissionsService = "/authzed.api.v1.PermissionsService/"
+	schemaService      = "/authzed.api.v1.SchemaService/"
+
+	defaultConcurrencyLimit = 50
+)
+
+// Option configures an Engine.
+type Option func(*options)
+
+type options struct {
+	concurrencyLimit   uint16
+	dispatchCache      cache.Cache
+	maxUpdatesPerWrite uint16
+	additiveOnlySchema bool
+}
+
+// WithConcurrencyLimit sets the maximum number of goroutines with which each request is
+// evaluated. By default, it is 50.
+func WithConcurrencyLimit(limit uint16) Option {
+	return func(o *options) { o.concurrencyLimit = limit }
+}
+
+// WithDispatchCache caches the results of the subproblems of checks, as created with
+// cache.NewCache. By default, results are not cached.
+func WithDispatchCache(c cache.Cache) Option {
+	return func(o *options) { o.dispatchCache = c }
+}
+
+// WithMaxUpdatesPerWrite sets the maximum number of updates allowed in a WriteRelationships
+// call. By default, it is 1000, as for the API.
+func WithMaxUpdatesPerWrite(limit uint16) Option {
+	return func(o *options) { o.maxUpdatesPerWrite = limit }
+}
+
+// WithAdditiveOnlySchema makes schema writes add and change definitions and caveats without
+// removing those missing from the written schema, so that the engine cannot remove those the
+// servers of the cluster rely upon.
+func WithAdditiveOnlySchema() Option {
+	return func(o *options) { o.additiveOnlySchema = true }
+}
+
+// Engine evaluates the requests of the permissions and schema services against a datastore.
+// It is safe for concurrent use.
+type Engine struct {
+	dispatcher  dispatch.Dispatcher
+	permissions v1.PermissionsServiceServer
+	schema      v1.SchemaServiceServer
+	interceptor grpc.UnaryServerInterceptor
+}
+
+// New creates an engine evaluating requests against the datastore, such as one opened with
+// the NewDatastore function of pkg/cmd/datastore with the engine and URI of the cluster. The
+// datastore remains owned by the caller, which must close it after closing the engine.
+func New(ds datastore.Datastore, opts ...Option) (*Engine, error) {
+	if ds == nil {
+		return nil, errors.New("a datastore is required")
+	}
+
+	o := options{concurrencyLimit: defaultConcurrencyLimit}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	dispatcher, err := combined.NewDispatcher(
+		combined.Cache(o.dispatchCache),
+		combined.ConcurrencyLimits(graph.SharedConcurrencyLimits(o.concurrencyLimit)),
+		combined.MetricsEnabled(false),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create dispatcher: %w", err)
+	}
+
+	return &Engine{
+		dispatcher: dispatcher,
+		permissions: v1svc.NewPermissionsServer(dispatcher, v1svc.PermissionsServerConfig{
+			MaxUpdatesPerWrite: o.maxUpdatesPerWrite,
+		}),
+		schema: v1svc.NewSchemaServer(o.additiveOnlySchema),
+		interceptor: middleware.ChainUnaryServer(
+			datastoremw.UnaryServerInterceptor(ds),
+			consistency.UnaryServerInterceptor(),
+			servicespecific.UnaryServerInterceptor,
+		),
+	}, nil
+}
+
+// Close stops the engine from dispatching requests. It does not close the datastore.
+func (e *Engine) Close() error {
+	return e.dispatcher.Close()
+}
+
+// CheckPermission checks whether the subject of the request has the permission on the
+// resource.
+func (e *Engine) CheckPermission(ctx context.Context, req *v1.CheckPermissionRequest) (*v1.CheckPermissionResponse, error) {
+	return call(ctx, e, e.permissions, permissionsService+"CheckPermission", req, e.permissions.CheckPermission)
+}
+
+// ExpandPermissionTree expands the relations and permissions reachable from the permission
+// of the resource into a tree.
+func (e *Engine) ExpandPermissionTree(ctx context.Context, req *v1.ExpandPermissionTreeRequest) (*v1.ExpandPermissionTreeResponse, error) {
+	return call(ctx, e, e.permissions, permissionsService+"ExpandPermissionTree", req, e.permissions.ExpandPermissionTree)
+}
+
+// WriteRelationships atomically writes the updates of the request, if its preconditions are
+// met.
+func (e *Engine) WriteRelationships(ctx context.Context, req *v1.WriteRelationshipsRequest) (*v1.WriteRelationshipsResponse, error) {
+	return call(ctx, e, e.permissions, permissionsService+"WriteRelationships", req, e.permissions.WriteRelationships)
+}
+
+// DeleteRelationships atomically deletes the relationships matching the filter of the
+// request, if its preconditions are met.
+func (e *Engine) DeleteRelationships(ctx context.Context, req *v1.DeleteRelationshipsRequest) (*v1.DeleteRelationshipsResponse, error) {
+	return call(ctx, e, e.permissions, permissionsService+"DeleteRelationships", req, e.permissions.DeleteRelationships)
+}
+
+// ReadSchema reads the schema stored in the datastore.
+func (e *Engine) ReadSchema(ctx context.Context, req *v1.ReadSchemaRequest) (*v1.ReadSchemaResponse, error) {
+	return call(ctx, e, e.schema, schemaService+"ReadSchema", req, e.schema.ReadSchema)
+}
+
+// WriteSchema replaces the schema stored in the datastore, which the servers of the cluster
+// share.
+func (e *Engine) WriteSchema(ctx context.Context, req *v1.WriteSchemaRequest) (*v1.WriteSchemaResponse, error) {
+	return call(ctx, e, e.schema, schemaService+"WriteSchema", req, e.schema.WriteSchema)
+}
+
+// call runs the method of the service through the interceptors a server would run before it,
+// which resolve the datastore and revision of the request and validate it.
+func call[Req, Resp any](ctx context.Context, e *Engine, service any, method string, req Req, handler func(context.Context, Req) (Resp, error)) (Resp, error) {
+	info := &grpc.UnaryServerInfo{Server: service, FullMethod: method}
+	resp, err := e.interceptor(ctx, req, info, func(ctx context.Context, req any) (any, error) {
+		return handler(ctx, req.(Req))
+	})
+	if err != nil {
+		var none Resp
+		return none, err
+	}
+	return resp.(Resp), nil
+}
@@ -0,0 +1,112 @@
+package embedded
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const testSchema = `definition user {}
+
+definition document {
+	relation viewer: user
+	permission view = viewer
+}`
+
+func newEngine(t *testing.T, opts ...Option) *Engine {
+	t.Helper()
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	require.NoError(t, err)
+	t.Cleanup(func() { ds.Close() })
+
+	engine, err := New(ds, opts...)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, engine.Close()) })
+	return engine
+}
+
+func check(t *testing.T, engine *Engine, document, permission, subject string, token *v1.ZedToken) v1.CheckPermissionResponse_Permissionship {
+	t.Helper()
+	resp, err := engine.CheckPermission(context.Background(), &v1.CheckPermissionRequest{
+		Consistency: &v1.Consistency{Requirement: &v1.Consistency_AtLeastAsFresh{AtLeastAsFresh: token}},
+		Resource:    &v1.ObjectReference{ObjectType: "document", ObjectId: document},
+		Permission:  permission,
+		Subject:     &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: subject}},
+	})
+	require.NoError(t, err)
+	return resp.Permissionship
+}
+
+func TestCheckAndWrite(t *testing.T) {
+	engine := newEngine(t)
+	ctx := context.Background()
+
+	_, err := engine.WriteSchema(ctx, &v1.WriteSchemaRequest{Schema: testSchema})
+	require.NoError(t, err)
+
+	schema, err := engine.ReadSchema(ctx, &v1.ReadSchemaRequest{})
+	require.NoError(t, err)
+	require.Contains(t, schema.SchemaText, "permission view = viewer")
+
+	written, err := engine.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{{
+			Operation:    v1.RelationshipUpdate_OPERATION_CREATE,
+			Relationship: tuple.MustToRelationship(tuple.MustParse("document:readme#viewer@user:tom")),
+		}},
+	})
+	require.NoError(t, err)
+	require.Equal(t, v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, check(t, engine, "readme", "view", "tom", written.WrittenAt))
+	require.Equal(t, v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION, check(t, engine, "readme", "view", "fred", written.WrittenAt))
+
+	expanded, err := engine.ExpandPermissionTree(ctx, &v1.ExpandPermissionTreeRequest{
+		Consistency: &v1.Consistency{Requirement: &v1.Consistency_AtLeastAsFresh{AtLeastAsFresh: written.WrittenAt}},
+		Resource:    &v1.ObjectReference{ObjectType: "document", ObjectId: "readme"},
+		Permission:  "view",
+	})
+	require.NoError(t, err)
+	require.NotNil(t, expanded.TreeRoot)
+
+	deleted, err := engine.DeleteRelationships(ctx, &v1.DeleteRelationshipsRequest{
+		RelationshipFilter: &v1.RelationshipFilter{ResourceType: "document"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION, check(t, engine, "readme", "view", "tom", deleted.DeletedAt))
+}
+
+func TestErrorsAndAdditiveSchema(t *testing.T) {
+	engine := newEngine(t, WithAdditiveOnlySchema())
+	ctx := context.Background()
+
+	// Requests are validated as they are by the API.
+	_, err := engine.CheckPermission(ctx, &v1.CheckPermissionRequest{Permission: "view"})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = engine.CheckPermission(ctx, &v1.CheckPermissionRequest{
+		Resource:   &v1.ObjectReference{ObjectType: "document", ObjectId: "readme"},
+		Permission: "view",
+		Subject:    &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: "tom"}},
+	})
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = engine.WriteSchema(ctx, &v1.WriteSchemaRequest{Schema: testSchema})
+	require.NoError(t, err)
+
+	// Definitions missing from the written schema are kept.
+	_, err = engine.WriteSchema(ctx, &v1.WriteSchemaRequest{Schema: "definition user {}"})
+	require.NoError(t, err)
+	schema, err := engine.ReadSchema(ctx, &v1.ReadSchemaRequest{})
+	require.NoError(t, err)
+	require.Contains(t, schema.SchemaText, "definition document")
+}
+
+func TestNewRequiresDatastore(t *testing.T) {
+	_, err := New(nil)
+	require.EqualError(t, err, "a datastore is required")
+}
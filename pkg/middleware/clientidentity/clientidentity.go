@@ -0,0 +1,99 @@
+package clientidentity
+
+import (
+	"context"
+	"crypto/x509"
+
+	log "github.com/authzed/spicedb/internal/logging"
+
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors"
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+// LogFieldKey is the log field under which the verified client identity is recorded.
+const LogFieldKey = "clientIdentity"
+
+// Identity is the identity of a client, as established by a certificate verified
+// during mutual TLS.
+type Identity struct {
+	// Subject is the distinguished name of the client certificate's subject.
+	Subject string
+
+	// CommonName is the common name of the client certificate's subject.
+	CommonName string
+
+	// DNSNames are the DNS subject alternative names of the client certificate.
+	DNSNames []string
+
+	// URIs are the URI subject alternative names of the client certificate, such as
+	// SPIFFE IDs.
+	URIs []string
+
+	// Certificate is the verified client certificate.
+	Certificate *x509.Certificate
+}
+
+// FromContext returns the identity of the client that made the request, if the client
+// presented a certificate that was verified by the server.
+func FromContext(ctx context.Context) (*Identity, bool) {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil, false
+	}
+
+	tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo)
+	if !ok || len(tlsInfo.State.VerifiedChains) == 0 || len(tlsInfo.State.VerifiedChains[0]) == 0 {
+		return nil, false
+	}
+
+	cert := tlsInfo.State.VerifiedChains[0][0]
+	uris := make([]string, 0, len(cert.URIs))
+	for _, uri := range cert.URIs {
+		uris = append(uris, uri.String())
+	}
+
+	return &Identity{
+		Subject:     cert.Subject.String(),
+		CommonName:  cert.Subject.CommonName,
+		DNSNames:    cert.DNSNames,
+		URIs:        uris,
+		Certificate: cert,
+	}, true
+}
+
+// String returns the name by which the client is identified in logs: its first URI SAN
+// if present, otherwise its subject.
+func (i *Identity) String() string {
+	if len(i.URIs) > 0 {
+		return i.URIs[0]
+	}
+	return i.Subject
+}
+
+type logClientIdentity struct{}
+
+func (r *logClientIdentity) ServerReporter(ctx context.Context, _ interceptors.CallMeta) (interceptors.Reporter, context.Context) {
+	identity, ok := FromContext(ctx)
+	if ok {
+		ctx = logging.InjectFields(ctx, logging.Fields{LogFieldKey, identity.String()})
+		loggerForContext := log.Ctx(ctx).With().Str(LogFieldKey, identity.String()).Logger()
+		ctx = loggerForContext.WithContext(ctx)
+	}
+
+	return interceptors.NoopReporter{}, ctx
+}
+
+// UnaryServerInterceptor returns a new interceptor which records the verified client
+// identity, if any, as a log field.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return interceptors.UnaryServerInterceptor(&logClientIdentity{})
+}
+
+// StreamServerInterceptor returns a new interceptor which records the verified client
+// identity, if any, as a log field.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return interceptors.StreamServerInterceptor(&logClientIdentity{})
+}
@@ -0,0 +1,78 @@
+package clientidentity
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/peer"
+)
+
+func TestFromContext(t *testing.T) {
+	spiffeID, err := url.Parse("spiffe://example.org/app")
+	require.NoError(t, err)
+
+	cert := &x509.Certificate{
+		Subject:  pkix.Name{CommonName: "app", Organization: []string{"example"}},
+		DNSNames: []string{"app.example.org"},
+		URIs:     []*url.URL{spiffeID},
+	}
+
+	testCases := []struct {
+		name             string
+		ctx              context.Context
+		expectedIdentity *Identity
+	}{
+		{
+			"no peer",
+			context.Background(),
+			nil,
+		},
+		{
+			"insecure peer",
+			peer.NewContext(context.Background(), &peer.Peer{}),
+			nil,
+		},
+		{
+			"tls without client certificate",
+			peer.NewContext(context.Background(), &peer.Peer{
+				AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{}},
+			}),
+			nil,
+		},
+		{
+			"verified client certificate",
+			peer.NewContext(context.Background(), &peer.Peer{
+				AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{
+					VerifiedChains: [][]*x509.Certificate{{cert}},
+				}},
+			}),
+			&Identity{
+				Subject:     "CN=app,O=example",
+				CommonName:  "app",
+				DNSNames:    []string{"app.example.org"},
+				URIs:        []string{"spiffe://example.org/app"},
+				Certificate: cert,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			identity, ok := FromContext(tc.ctx)
+			require.Equal(t, tc.expectedIdentity != nil, ok)
+			require.Equal(t, tc.expectedIdentity, identity)
+		})
+	}
+}
+
+func TestIdentityString(t *testing.T) {
+	require.Equal(t, "spiffe://example.org/app", (&Identity{Subject: "CN=app", URIs: []string{"spiffe://example.org/app"}}).String())
+	require.Equal(t, "CN=app", (&Identity{Subject: "CN=app"}).String())
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors"
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors/logging"
+	"go.opentelemetry.io/otel/trace"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
 )
@@ -28,11 +29,16 @@ type extractMetadata struct {
 	fields []FieldSpec
 }
 
+// TraceIDFieldKey is the log tag under which the OpenTelemetry trace ID of the request,
+// if any, is set.
+const TraceIDFieldKey = "traceID"
+
 func (r *extractMetadata) ServerReporter(ctx context.Context, _ interceptors.CallMeta) (interceptors.Reporter, context.Context) {
+	fields := logging.Fields{}
+	logContext := log.With()
+
 	md, ok := metadata.FromIncomingContext(ctx)
 	if ok {
-		fields := logging.Fields{}
-		logContext := log.With()
 		for _, field := range r.fields {
 			value, ok := md[field.metadataKey]
 			if ok {
@@ -42,7 +48,15 @@ func (r *extractMetadata) ServerReporter(ctx context.Context, _ interceptors.Cal
 				logContext = logContext.Str(field.tagKey, joinedValue)
 			}
 		}
+	}
+
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+		traceID := spanContext.TraceID().String()
+		fields = append(fields, TraceIDFieldKey, traceID)
+		logContext = logContext.Str(TraceIDFieldKey, traceID)
+	}
 
+	if len(fields) > 0 {
 		ctx = logging.InjectFields(ctx, fields)
 		loggerForContext := logContext.Logger()
 		ctx = loggerForContext.WithContext(ctx)
@@ -52,13 +66,13 @@ func (r *extractMetadata) ServerReporter(ctx context.Context, _ interceptors.Cal
 }
 
 // UnaryServerInterceptor creates an interceptor for extracting fields from requests
-// and setting them as log tags.
+// and setting them, along with the trace ID of the request, as log tags.
 func UnaryServerInterceptor(fields ...FieldSpec) grpc.UnaryServerInterceptor {
 	return interceptors.UnaryServerInterceptor(&extractMetadata{fields})
 }
 
 // StreamServerInterceptor creates an interceptor for extracting fields from requests
-// and setting them as log tags.
+// and setting them, along with the trace ID of the request, as log tags.
 func StreamServerInterceptor(fields ...FieldSpec) grpc.StreamServerInterceptor {
 	return interceptors.StreamServerInterceptor(&extractMetadata{fields})
 }
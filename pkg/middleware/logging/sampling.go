@@ -0,0 +1,108 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+
+	"github.com/authzed/grpcutil"
+	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors"
+	"google.golang.org/grpc"
+)
+
+// Sampler decides which calls are logged, at a rate configured per method.
+type Sampler struct {
+	defaultRate float64
+	rates       map[string]float64
+}
+
+// DefaultSampleRateKey is the key under which the rate of methods without their own rate
+// is given. If absent, such methods are always logged.
+const DefaultSampleRateKey = "*"
+
+// NewSampler creates a sampler which logs calls at the rate given for their method, or at
+// the default rate. Methods are named either in full, such as
+// `/authzed.api.v1.PermissionsService/CheckPermission`, or by their name alone, such as
+// `CheckPermission`.
+func NewSampler(rates map[string]float64) (*Sampler, error) {
+	sampler := &Sampler{defaultRate: 1, rates: make(map[string]float64, len(rates))}
+	for method, rate := range rates {
+		if rate < 0 || rate > 1 {
+			return nil, fmt.Errorf("log sample rate for `%s` must be between 0 and 1, got %v", method, rate)
+		}
+
+		if method == DefaultSampleRateKey {
+			sampler.defaultRate = rate
+			continue
+		}
+		sampler.rates[method] = rate
+	}
+	return sampler, nil
+}
+
+func (s *Sampler) rateFor(fullMethod string) float64 {
+	if rate, ok := s.rates[fullMethod]; ok {
+		return rate
+	}
+	if _, method := grpcutil.SplitMethodName(fullMethod); method != "" {
+		if rate, ok := s.rates[method]; ok {
+			return rate
+		}
+	}
+	return s.defaultRate
+}
+
+func (s *Sampler) sample(fullMethod string) bool {
+	rate := s.rateFor(fullMethod)
+	if rate >= 1 {
+		return true
+	}
+	// nolint:gosec
+	// G404 use of non cryptographically secure random number generator is not a concern here,
+	// as it is only used to sample logs.
+	return rand.Float64() < rate
+}
+
+type sampledKey struct{}
+
+// IsSampled returns whether the call of the context was sampled to be logged. Calls for
+// which no decision was made are logged.
+func IsSampled(ctx context.Context) bool {
+	sampled, ok := ctx.Value(sampledKey{}).(bool)
+	return !ok || sampled
+}
+
+// WithSampled returns a context whose call is logged regardless of the decision of the sampler.
+func WithSampled(ctx context.Context) context.Context {
+	return context.WithValue(ctx, sampledKey{}, true)
+}
+
+type sampleCalls struct {
+	sampler *Sampler
+}
+
+func (r *sampleCalls) ServerReporter(ctx context.Context, callMeta interceptors.CallMeta) (interceptors.Reporter, context.Context) {
+	return interceptors.NoopReporter{}, context.WithValue(ctx, sampledKey{}, r.sampler.sample(callMeta.FullMethod()))
+}
+
+// UnarySamplingServerInterceptor creates an interceptor which decides whether each call
+// is logged, according to the sampler. A nil sampler logs all calls.
+func UnarySamplingServerInterceptor(sampler *Sampler) grpc.UnaryServerInterceptor {
+	if sampler == nil {
+		return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+			return handler(ctx, req)
+		}
+	}
+	return interceptors.UnaryServerInterceptor(&sampleCalls{sampler})
+}
+
+// StreamSamplingServerInterceptor creates an interceptor which decides whether each
+// stream is logged, according to the sampler. A nil sampler logs all streams.
+func StreamSamplingServerInterceptor(sampler *Sampler) grpc.StreamServerInterceptor {
+	if sampler == nil {
+		return func(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			return handler(srv, stream)
+		}
+	}
+	return interceptors.StreamServerInterceptor(&sampleCalls{sampler})
+}
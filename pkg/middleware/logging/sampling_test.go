@@ -0,0 +1,55 @@
+package logging
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+const (
+	checkMethod = "/authzed.api.v1.PermissionsService/CheckPermission"
+	writeMethod = "/authzed.api.v1.PermissionsService/WriteRelationships"
+	readMethod  = "/authzed.api.v1.PermissionsService/ReadRelationships"
+)
+
+func TestNewSampler(t *testing.T) {
+	_, err := NewSampler(map[string]float64{"CheckPermission": 1.5})
+	require.ErrorContains(t, err, "must be between 0 and 1")
+
+	sampler, err := NewSampler(map[string]float64{
+		DefaultSampleRateKey: 0.5,
+		"CheckPermission":    0,
+		writeMethod:          1,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 0.0, sampler.rateFor(checkMethod))
+	require.Equal(t, 1.0, sampler.rateFor(writeMethod))
+	require.Equal(t, 0.5, sampler.rateFor(readMethod))
+
+	sampler, err = NewSampler(map[string]float64{"CheckPermission": 0})
+	require.NoError(t, err)
+	require.Equal(t, 1.0, sampler.rateFor(readMethod))
+}
+
+func TestSamplingServerInterceptor(t *testing.T) {
+	require.True(t, IsSampled(context.Background()))
+
+	sampler, err := NewSampler(map[string]float64{"CheckPermission": 0})
+	require.NoError(t, err)
+
+	sampled := func(interceptor grpc.UnaryServerInterceptor, method string) bool {
+		var result bool
+		_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: method}, func(ctx context.Context, req any) (any, error) {
+			result = IsSampled(ctx)
+			return nil, nil
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	require.False(t, sampled(UnarySamplingServerInterceptor(sampler), checkMethod))
+	require.True(t, sampled(UnarySamplingServerInterceptor(sampler), writeMethod))
+	require.True(t, sampled(UnarySamplingServerInterceptor(nil), checkMethod))
+}
@@ -8,8 +8,11 @@ import (
 	"github.com/authzed/authzed-go/pkg/responsemeta"
 	"github.com/grpc-ecosystem/go-grpc-middleware/v2/interceptors"
 	"github.com/rs/xid"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 )
 
 // MetadataKey is the key in which request IDs are passed to metadata.
@@ -83,16 +86,79 @@ func (r *handleRequestID) ServerReporter(ctx context.Context, _ interceptors.Cal
 	return interceptors.NoopReporter{}, ctx
 }
 
+// FromContext returns the request ID of the incoming request, if any.
+func FromContext(ctx context.Context) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+
+	requestIDs := md[MetadataKey]
+	if len(requestIDs) == 0 {
+		return ""
+	}
+	return requestIDs[0]
+}
+
+// withRequestInfo adds the request ID and, if the request is being traced, the trace ID to
+// the details of the error, so that failures reported by clients can be found in the logs.
+func withRequestInfo(ctx context.Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	info := &errdetails.RequestInfo{RequestId: FromContext(ctx)}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.HasTraceID() {
+		info.ServingData = spanContext.TraceID().String()
+	}
+	if info.RequestId == "" && info.ServingData == "" {
+		return err
+	}
+
+	st, ok := status.FromError(err)
+	if !ok {
+		st = status.FromContextError(err)
+	}
+	for _, detail := range st.Details() {
+		if _, ok := detail.(*errdetails.RequestInfo); ok {
+			return err
+		}
+	}
+
+	withDetails, derr := st.WithDetails(info)
+	if derr != nil {
+		return err
+	}
+	return withDetails.Err()
+}
+
 // UnaryServerInterceptor returns a new interceptor which handles request IDs according
 // to the provided options.
+//
+// Errors returned by the handler carry the request ID and, if traced, the trace ID of the
+// request in a RequestInfo detail, whose serving data holds the trace ID.
 func UnaryServerInterceptor(opts ...Option) grpc.UnaryServerInterceptor {
-	return interceptors.UnaryServerInterceptor(createReporter(opts))
+	interceptor := interceptors.UnaryServerInterceptor(createReporter(opts))
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		return interceptor(ctx, req, info, func(ctx context.Context, req any) (any, error) {
+			resp, err := handler(ctx, req)
+			return resp, withRequestInfo(ctx, err)
+		})
+	}
 }
 
 // StreamServerInterceptor returns a new interceptor which handles request IDs according
 // to the provided options.
+//
+// Errors returned by the handler carry the request ID and, if traced, the trace ID of the
+// request in a RequestInfo detail, whose serving data holds the trace ID.
 func StreamServerInterceptor(opts ...Option) grpc.StreamServerInterceptor {
-	return interceptors.StreamServerInterceptor(createReporter(opts))
+	interceptor := interceptors.StreamServerInterceptor(createReporter(opts))
+	return func(srv any, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return interceptor(srv, stream, info, func(srv any, stream grpc.ServerStream) error {
+			return withRequestInfo(stream.Context(), handler(srv, stream))
+		})
+	}
 }
 
 func createReporter(opts []Option) *handleRequestID {
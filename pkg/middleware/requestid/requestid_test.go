@@ -0,0 +1,72 @@
+package requestid
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func requestInfoOf(t *testing.T, err error) *errdetails.RequestInfo {
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.RequestInfo); ok {
+			return info
+		}
+	}
+	require.Fail(t, "missing request info")
+	return nil
+}
+
+func TestUnaryServerInterceptorErrorDetails(t *testing.T) {
+	traceID := trace.TraceID{0x01, 0x02, 0x03}
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  trace.SpanID{0x01},
+	}))
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(MetadataKey, "some-request"))
+
+	interceptor := UnaryServerInterceptor()
+	_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, _ any) (any, error) {
+		return nil, status.Error(codes.NotFound, "not found")
+	})
+	require.Equal(t, codes.NotFound, status.Code(err))
+
+	info := requestInfoOf(t, err)
+	require.Equal(t, "some-request", info.RequestId)
+	require.Equal(t, traceID.String(), info.ServingData)
+}
+
+func TestStreamServerInterceptorGeneratesRequestID(t *testing.T) {
+	var requestID string
+	interceptor := StreamServerInterceptor(GenerateIfMissing(true))
+	err := interceptor(nil, &fakeStream{ctx: context.Background()}, &grpc.StreamServerInfo{}, func(_ any, stream grpc.ServerStream) error {
+		requestID = FromContext(stream.Context())
+		return errors.New("some error")
+	})
+	require.NotEmpty(t, requestID)
+	require.Equal(t, codes.Unknown, status.Code(err))
+	require.Equal(t, "some error", status.Convert(err).Message())
+
+	info := requestInfoOf(t, err)
+	require.Equal(t, requestID, info.RequestId)
+	require.Empty(t, info.ServingData)
+}
+
+type fakeStream struct {
+	grpc.ServerStream
+
+	ctx context.Context
+}
+
+func (fs *fakeStream) Context() context.Context { return fs.ctx }
+
+func (fs *fakeStream) SetHeader(metadata.MD) error { return nil }
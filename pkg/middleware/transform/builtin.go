@@ -0,0 +1,33 @@
+package transform
+
+import (
+	"context"
+	"strings"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+)
+
+// LowercaseIDs is the name of the built-in transform which lowercases the ID of the resource of
+// the relationships written, so that IDs differing only by case refer to the same object.
+const LowercaseIDs = "lowercase-ids"
+
+// TrimIDs is the name of the built-in transform which removes the leading and trailing
+// whitespace of the ID of the resource of the relationships written.
+const TrimIDs = "trim-ids"
+
+func init() {
+	Register(LowercaseIDs, resourceIDTransform(strings.ToLower))
+	Register(TrimIDs, resourceIDTransform(strings.TrimSpace))
+}
+
+// resourceIDTransform normalizes the resource IDs of relationships as they are written.
+type resourceIDTransform func(string) string
+
+func (t resourceIDTransform) Write(_ context.Context, rel *v1.Relationship) error {
+	rel.Resource.ObjectId = t(rel.Resource.ObjectId)
+	return nil
+}
+
+func (t resourceIDTransform) Read(context.Context, *v1.Relationship) error {
+	return nil
+}
@@ -0,0 +1,109 @@
+package transform
+
+import (
+	"context"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+)
+
+// UnaryServerInterceptor returns a new unary server interceptor which applies the write
+// transforms to the relationships written by WriteRelationships. Empty transforms disable it.
+func UnaryServerInterceptor(transforms Transforms) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if write, ok := req.(*v1.WriteRelationshipsRequest); ok && len(transforms) > 0 {
+			for _, update := range write.Updates {
+				if err := transforms.write(ctx, update.Relationship); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor returns a new stream server interceptor which applies the write
+// transforms to the relationships bulk imported, and the read transforms to those read, bulk
+// exported and watched. Empty transforms disable it.
+func StreamServerInterceptor(transforms Transforms) grpc.StreamServerInterceptor {
+	return func(srv any, stream grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if len(transforms) == 0 {
+			return handler(srv, stream)
+		}
+		return handler(srv, &transformingStream{ServerStream: stream, transforms: transforms})
+	}
+}
+
+type transformingStream struct {
+	grpc.ServerStream
+	transforms Transforms
+}
+
+func (s *transformingStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+
+	if req, ok := m.(*v1.BulkImportRelationshipsRequest); ok {
+		for _, rel := range req.Relationships {
+			if err := s.transforms.write(s.Context(), rel); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *transformingStream) SendMsg(m any) error {
+	transformed, err := s.read(m)
+	if err != nil {
+		return err
+	}
+	return s.ServerStream.SendMsg(transformed)
+}
+
+// read returns the response with the read transforms applied to its relationships. Responses
+// are copied before they are transformed, since they may be shared between streams.
+func (s *transformingStream) read(m any) (any, error) {
+	ctx := s.Context()
+	switch resp := m.(type) {
+	case *v1.ReadRelationshipsResponse:
+		if !s.transforms.applies(resp.Relationship) {
+			return m, nil
+		}
+		resp = proto.Clone(resp).(*v1.ReadRelationshipsResponse)
+		return resp, s.transforms.read(ctx, resp.Relationship)
+
+	case *v1.BulkExportRelationshipsResponse:
+		if !s.transforms.applies(resp.Relationships...) {
+			return m, nil
+		}
+		resp = proto.Clone(resp).(*v1.BulkExportRelationshipsResponse)
+		for _, rel := range resp.Relationships {
+			if err := s.transforms.read(ctx, rel); err != nil {
+				return nil, err
+			}
+		}
+		return resp, nil
+
+	case *v1.WatchResponse:
+		rels := make([]*v1.Relationship, 0, len(resp.Updates))
+		for _, update := range resp.Updates {
+			rels = append(rels, update.Relationship)
+		}
+		if !s.transforms.applies(rels...) {
+			return m, nil
+		}
+		resp = proto.Clone(resp).(*v1.WatchResponse)
+		for _, update := range resp.Updates {
+			if err := s.transforms.read(ctx, update.Relationship); err != nil {
+				return nil, err
+			}
+		}
+		return resp, nil
+
+	default:
+		return m, nil
+	}
+}
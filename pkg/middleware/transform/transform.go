@@ -0,0 +1,108 @@
+// Package transform implements middleware which applies custom transforms to the relationships
+// of a namespace as they are written and read through the API, such as normalizing object IDs or
+// translating relationships to and from a legacy format. Transforms are implemented in Go and
+// registered by name, so that builds of SpiceDB can add their own without changing its services.
+package transform
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+)
+
+// Transform transforms the relationships of a namespace. Relationships are modified in place;
+// a transform returning an error fails the request.
+type Transform interface {
+	// Write transforms a relationship written, created, touched or deleted, or bulk imported,
+	// before it is validated and stored.
+	Write(ctx context.Context, rel *v1.Relationship) error
+
+	// Read transforms a relationship read, bulk exported or watched, before it is returned.
+	Read(ctx context.Context, rel *v1.Relationship) error
+}
+
+var (
+	registryLock sync.RWMutex
+	registry     = map[string]Transform{}
+)
+
+// Register makes a transform available to be configured by name. It is meant to be called from
+// the init function of the package implementing the transform, and panics if a transform is
+// already registered with the name.
+func Register(name string, transform Transform) {
+	registryLock.Lock()
+	defer registryLock.Unlock()
+
+	if _, ok := registry[name]; ok {
+		panic(fmt.Sprintf("transform `%s` is already registered", name))
+	}
+	registry[name] = transform
+}
+
+// Registered returns the names of the registered transforms, sorted.
+func Registered() []string {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	return registeredLocked()
+}
+
+// Transforms are the transforms applied to the relationships of each namespace, by the name of
+// the namespace as stored in the datastore. A relationship is transformed by the transform of
+// its resource type.
+type Transforms map[string]Transform
+
+// FromConfig returns the transforms configured by the name of the registered transform applied
+// to each namespace.
+func FromConfig(config map[string]string) (Transforms, error) {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+
+	transforms := make(Transforms, len(config))
+	for namespace, name := range config {
+		transform, ok := registry[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown transform `%s` for namespace `%s`; registered transforms are: %s", name, namespace, strings.Join(registeredLocked(), ", "))
+		}
+		transforms[namespace] = transform
+	}
+	return transforms, nil
+}
+
+func registeredLocked() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (t Transforms) write(ctx context.Context, rel *v1.Relationship) error {
+	transform, ok := t[rel.GetResource().GetObjectType()]
+	if !ok {
+		return nil
+	}
+	return transform.Write(ctx, rel)
+}
+
+func (t Transforms) read(ctx context.Context, rel *v1.Relationship) error {
+	transform, ok := t[rel.GetResource().GetObjectType()]
+	if !ok {
+		return nil
+	}
+	return transform.Read(ctx, rel)
+}
+
+// applies returns whether any of the relationships is transformed.
+func (t Transforms) applies(rels ...*v1.Relationship) bool {
+	for _, rel := range rels {
+		if _, ok := t[rel.GetResource().GetObjectType()]; ok {
+			return true
+		}
+	}
+	return false
+}
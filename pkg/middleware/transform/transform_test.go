@@ -0,0 +1,145 @@
+package transform
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// legacyTransform stores the IDs of relationships with a legacy prefix, which is removed when
+// they are read.
+type legacyTransform struct{}
+
+func (legacyTransform) Write(_ context.Context, rel *v1.Relationship) error {
+	if strings.HasPrefix(rel.Resource.ObjectId, "invalid") {
+		return errors.New("invalid ID")
+	}
+	rel.Resource.ObjectId = "legacy_" + rel.Resource.ObjectId
+	return nil
+}
+
+func (legacyTransform) Read(_ context.Context, rel *v1.Relationship) error {
+	rel.Resource.ObjectId = strings.TrimPrefix(rel.Resource.ObjectId, "legacy_")
+	return nil
+}
+
+func init() {
+	Register("legacy", legacyTransform{})
+}
+
+func mustRelationship(rel string) *v1.Relationship {
+	return tuple.MustToRelationship(tuple.MustParse(rel))
+}
+
+func TestFromConfig(t *testing.T) {
+	transforms, err := FromConfig(map[string]string{"document": LowercaseIDs, "folder": "legacy"})
+	require.NoError(t, err)
+	require.Len(t, transforms, 2)
+
+	_, err = FromConfig(map[string]string{"document": "unknown"})
+	require.ErrorContains(t, err, "legacy, lowercase-ids, trim-ids")
+
+	require.Panics(t, func() { Register(LowercaseIDs, legacyTransform{}) })
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	transforms, err := FromConfig(map[string]string{"document": LowercaseIDs, "folder": "legacy"})
+	require.NoError(t, err)
+	interceptor := UnaryServerInterceptor(transforms)
+
+	req := &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{
+			{Operation: v1.RelationshipUpdate_OPERATION_TOUCH, Relationship: mustRelationship("document:ReadMe#viewer@user:Anne")},
+			{Operation: v1.RelationshipUpdate_OPERATION_DELETE, Relationship: mustRelationship("folder:root#viewer@user:anne")},
+			{Operation: v1.RelationshipUpdate_OPERATION_TOUCH, Relationship: mustRelationship("organization:Acme#member@user:anne")},
+		},
+	}
+	var handled *v1.WriteRelationshipsRequest
+	_, err = interceptor(context.Background(), req, &grpc.UnaryServerInfo{}, func(_ context.Context, req any) (any, error) {
+		handled = req.(*v1.WriteRelationshipsRequest)
+		return &v1.WriteRelationshipsResponse{}, nil
+	})
+	require.NoError(t, err)
+	require.Equal(t, "readme", handled.Updates[0].Relationship.Resource.ObjectId)
+	require.Equal(t, "Anne", handled.Updates[0].Relationship.Subject.Object.ObjectId)
+	require.Equal(t, "legacy_root", handled.Updates[1].Relationship.Resource.ObjectId)
+	require.Equal(t, "Acme", handled.Updates[2].Relationship.Resource.ObjectId)
+
+	// A failing transform fails the write.
+	_, err = interceptor(context.Background(), &v1.WriteRelationshipsRequest{
+		Updates: []*v1.RelationshipUpdate{
+			{Operation: v1.RelationshipUpdate_OPERATION_TOUCH, Relationship: mustRelationship("folder:invalid#viewer@user:anne")},
+		},
+	}, &grpc.UnaryServerInfo{}, func(context.Context, any) (any, error) {
+		require.Fail(t, "the handler must not be called")
+		return nil, nil
+	})
+	require.Error(t, err)
+}
+
+type fakeStream struct {
+	grpc.ServerStream
+	received []proto.Message
+	sent     []any
+}
+
+func (fs *fakeStream) Context() context.Context { return context.Background() }
+
+func (fs *fakeStream) RecvMsg(m any) error {
+	proto.Merge(m.(proto.Message), fs.received[0])
+	fs.received = fs.received[1:]
+	return nil
+}
+
+func (fs *fakeStream) SendMsg(m any) error {
+	fs.sent = append(fs.sent, m)
+	return nil
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	transforms, err := FromConfig(map[string]string{"folder": "legacy"})
+	require.NoError(t, err)
+	interceptor := StreamServerInterceptor(transforms)
+
+	stream := &fakeStream{received: []proto.Message{
+		&v1.BulkImportRelationshipsRequest{Relationships: []*v1.Relationship{
+			mustRelationship("folder:root#viewer@user:anne"),
+			mustRelationship("document:readme#viewer@user:anne"),
+		}},
+	}}
+
+	read := &v1.ReadRelationshipsResponse{Relationship: mustRelationship("folder:legacy_root#viewer@user:anne")}
+	untransformed := &v1.ReadRelationshipsResponse{Relationship: mustRelationship("document:legacy_readme#viewer@user:anne")}
+	watched := &v1.WatchResponse{Updates: []*v1.RelationshipUpdate{
+		{Operation: v1.RelationshipUpdate_OPERATION_TOUCH, Relationship: mustRelationship("folder:legacy_root#viewer@user:anne")},
+	}}
+
+	err = interceptor(nil, stream, &grpc.StreamServerInfo{}, func(_ any, stream grpc.ServerStream) error {
+		req := &v1.BulkImportRelationshipsRequest{}
+		require.NoError(t, stream.RecvMsg(req))
+		require.Equal(t, "legacy_root", req.Relationships[0].Resource.ObjectId)
+		require.Equal(t, "readme", req.Relationships[1].Resource.ObjectId)
+
+		for _, resp := range []any{read, untransformed, watched} {
+			require.NoError(t, stream.SendMsg(resp))
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, "root", stream.sent[0].(*v1.ReadRelationshipsResponse).Relationship.Resource.ObjectId)
+	require.Same(t, untransformed, stream.sent[1])
+	require.Equal(t, "root", stream.sent[2].(*v1.WatchResponse).Updates[0].Relationship.Resource.ObjectId)
+
+	// The responses sent by the handler are not modified.
+	require.Equal(t, "legacy_root", read.Relationship.Resource.ObjectId)
+	require.Equal(t, "legacy_root", watched.Updates[0].Relationship.Resource.ObjectId)
+}
@@ -166,6 +166,35 @@ func (m *RelationTuple) validate(all bool) error {
 		}
 	}
 
+	if all {
+		switch v := interface{}(m.GetIntegrity()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, RelationTupleValidationError{
+					field:  "Integrity",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, RelationTupleValidationError{
+					field:  "Integrity",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetIntegrity()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return RelationTupleValidationError{
+				field:  "Integrity",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
 	if len(errors) > 0 {
 		return RelationTupleMultiError(errors)
 	}
@@ -244,6 +273,141 @@ var _ interface {
 	ErrorName() string
 } = RelationTupleValidationError{}
 
+// Validate checks the field values on RelationshipIntegrity with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the first error encountered is returned, or nil if there are no violations.
+func (m *RelationshipIntegrity) Validate() error {
+	return m.validate(false)
+}
+
+// ValidateAll checks the field values on RelationshipIntegrity with the rules
+// defined in the proto definition for this message. If any rules are
+// violated, the result is a list of violation errors wrapped in
+// RelationshipIntegrityMultiError, or nil if none found.
+func (m *RelationshipIntegrity) ValidateAll() error {
+	return m.validate(true)
+}
+
+func (m *RelationshipIntegrity) validate(all bool) error {
+	if m == nil {
+		return nil
+	}
+
+	var errors []error
+
+	// no validation rules for KeyId
+
+	// no validation rules for Hash
+
+	if all {
+		switch v := interface{}(m.GetHashedAt()).(type) {
+		case interface{ ValidateAll() error }:
+			if err := v.ValidateAll(); err != nil {
+				errors = append(errors, RelationshipIntegrityValidationError{
+					field:  "HashedAt",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		case interface{ Validate() error }:
+			if err := v.Validate(); err != nil {
+				errors = append(errors, RelationshipIntegrityValidationError{
+					field:  "HashedAt",
+					reason: "embedded message failed validation",
+					cause:  err,
+				})
+			}
+		}
+	} else if v, ok := interface{}(m.GetHashedAt()).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return RelationshipIntegrityValidationError{
+				field:  "HashedAt",
+				reason: "embedded message failed validation",
+				cause:  err,
+			}
+		}
+	}
+
+	if len(errors) > 0 {
+		return RelationshipIntegrityMultiError(errors)
+	}
+
+	return nil
+}
+
+// RelationshipIntegrityMultiError is an error wrapping multiple validation
+// errors returned by RelationshipIntegrity.ValidateAll() if the designated
+// constraints aren't met.
+type RelationshipIntegrityMultiError []error
+
+// Error returns a concatenation of all the error messages it wraps.
+func (m RelationshipIntegrityMultiError) Error() string {
+	var msgs []string
+	for _, err := range m {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// AllErrors returns a list of validation violation errors.
+func (m RelationshipIntegrityMultiError) AllErrors() []error { return m }
+
+// RelationshipIntegrityValidationError is the validation error returned by
+// RelationshipIntegrity.Validate if the designated constraints aren't met.
+type RelationshipIntegrityValidationError struct {
+	field  string
+	reason string
+	cause  error
+	key    bool
+}
+
+// Field function returns field value.
+func (e RelationshipIntegrityValidationError) Field() string { return e.field }
+
+// Reason function returns reason value.
+func (e RelationshipIntegrityValidationError) Reason() string { return e.reason }
+
+// Cause function returns cause value.
+func (e RelationshipIntegrityValidationError) Cause() error { return e.cause }
+
+// Key function returns key value.
+func (e RelationshipIntegrityValidationError) Key() bool { return e.key }
+
+// ErrorName returns error name.
+func (e RelationshipIntegrityValidationError) ErrorName() string {
+	return "RelationshipIntegrityValidationError"
+}
+
+// Error satisfies the builtin error interface
+func (e RelationshipIntegrityValidationError) Error() string {
+	cause := ""
+	if e.cause != nil {
+		cause = fmt.Sprintf(" | caused by: %v", e.cause)
+	}
+
+	key := ""
+	if e.key {
+		key = "key for "
+	}
+
+	return fmt.Sprintf(
+		"invalid %sRelationshipIntegrity.%s: %s%s",
+		key,
+		e.field,
+		e.reason,
+		cause)
+}
+
+var _ error = RelationshipIntegrityValidationError{}
+
+var _ interface {
+	Field() string
+	Reason() string
+	Key() bool
+	Cause() error
+	ErrorName() string
+} = RelationshipIntegrityValidationError{}
+
 // Validate checks the field values on ContextualizedCaveat with the rules
 // defined in the proto definition for this message. If any rules are
 // violated, the first error encountered is returned, or nil if there are no violations.
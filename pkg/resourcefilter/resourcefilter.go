@@ -0,0 +1,206 @@
+// Package resourcefilter turns the results of a LookupResources call into filters for the
+// queries of applications, so that endpoints listing objects return only those which the
+// caller can see. The IDs found can be used as chunked lists, for `IN` clauses of SQL or the
+// queries of ORMs, as a SQL condition built with squirrel, or as a bloom filter, which is small
+// enough to be handed to other services or used to filter rows once they are read.
+package resourcefilter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	sq "github.com/Masterminds/squirrel"
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/bits-and-blooms/bloom/v3"
+)
+
+// DefaultChunkSize is the number of IDs in each chunk by default, which is within the limits
+// on the number of parameters of a statement of the common SQL databases.
+const DefaultChunkSize = 1000
+
+// LookupResourcesStream is the stream of results of a LookupResources call.
+type LookupResourcesStream interface {
+	Recv() (*v1.LookupResourcesResponse, error)
+}
+
+// Resources are the resources found by a LookupResources call, in the order found.
+type Resources struct {
+	ids         []string
+	conditional []string
+	set         map[string]struct{}
+}
+
+// Collect reads the stream to its end, returning the resources found. Resources which have the
+// permission only conditionally, because the context of caveats was missing, are not included,
+// and are returned by ConditionalIDs to be checked individually.
+func Collect(stream LookupResourcesStream) (*Resources, error) {
+	r := &Resources{set: make(map[string]struct{})}
+	for {
+		resp, err := stream.Recv()
+		if errors.Is(err, io.EOF) {
+			return r, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		id := resp.ResourceObjectId
+		if resp.Permissionship == v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_CONDITIONAL_PERMISSION {
+			r.conditional = append(r.conditional, id)
+			continue
+		}
+		if _, ok := r.set[id]; ok {
+			continue
+		}
+		r.set[id] = struct{}{}
+		r.ids = append(r.ids, id)
+	}
+}
+
+// Lookup looks up the resources with the permission, and collects them.
+func Lookup(ctx context.Context, client v1.PermissionsServiceClient, req *v1.LookupResourcesRequest) (*Resources, error) {
+	stream, err := client.LookupResources(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return Collect(stream)
+}
+
+// IDs returns the IDs of the resources.
+func (r *Resources) IDs() []string {
+	return r.ids
+}
+
+// ConditionalIDs returns the IDs of the resources which have the permission only if the
+// context of their caveats allows it.
+func (r *Resources) ConditionalIDs() []string {
+	return r.conditional
+}
+
+// Len returns the number of resources.
+func (r *Resources) Len() int {
+	return len(r.ids)
+}
+
+// Contains returns whether the resource of the given ID was found.
+func (r *Resources) Contains(id string) bool {
+	_, ok := r.set[id]
+	return ok
+}
+
+// Chunks splits the IDs into lists of at most the given number of IDs, or DefaultChunkSize
+// if it is not positive, such as to be queried in turn or combined into a single query.
+func (r *Resources) Chunks(size int) [][]string {
+	if size <= 0 {
+		size = DefaultChunkSize
+	}
+
+	chunks := make([][]string, 0, (len(r.ids)+size-1)/size)
+	for ids := r.ids; len(ids) > 0; {
+		chunk := ids[:min(len(ids), size)]
+		ids = ids[len(chunk):]
+		chunks = append(chunks, chunk)
+	}
+	return chunks
+}
+
+// Where returns the SQL condition matching rows whose column holds the ID of one of the
+// resources, as the disjunction of an `IN` clause per chunk of IDs. When there are no
+// resources, the condition matches no rows.
+//
+// The condition is built with squirrel, whose placeholder format is used for its arguments:
+//
+//	sql, args, err := resourcefilter.Where("documents.id", resources, 0).ToSql()
+func Where(column string, r *Resources, chunkSize int) sq.Sqlizer {
+	if r.Len() == 0 {
+		return sq.Expr("1 = 0")
+	}
+
+	chunks := r.Chunks(chunkSize)
+	if len(chunks) == 1 {
+		return sq.Eq{column: chunks[0]}
+	}
+
+	clause := make(sq.Or, 0, len(chunks))
+	for _, chunk := range chunks {
+		clause = append(clause, sq.Eq{column: chunk})
+	}
+	return clause
+}
+
+// BloomFilter is a probabilistic set of resource IDs, which never reports a found resource
+// as missing, but may report a missing one as found, at the false positive rate it was built
+// with. It is much smaller than the IDs it holds, so it can be sent to other services, or
+// used to filter rows once read when the IDs are too many to be sent to the database.
+type BloomFilter struct {
+	bf *bloom.BloomFilter
+}
+
+// BloomFilter returns a bloom filter of the IDs of the resources, with the given false positive
+// rate, such as 0.001.
+func (r *Resources) BloomFilter(falsePositiveRate float64) (*BloomFilter, error) {
+	if falsePositiveRate <= 0 || falsePositiveRate >= 1 {
+		return nil, fmt.Errorf("invalid false positive rate %v: must be between 0 and 1", falsePositiveRate)
+	}
+
+	bf := bloom.NewWithEstimates(uint(max(r.Len(), 1)), falsePositiveRate)
+	for _, id := range r.ids {
+		bf.AddString(id)
+	}
+	return &BloomFilter{bf}, nil
+}
+
+// MightContain returns whether the resource of the given ID may have been found. A false
+// result is certain.
+func (b *BloomFilter) MightContain(id string) bool {
+	return b.bf.TestString(id)
+}
+
+// MarshalBinary encodes the bloom filter, to be decoded by UnmarshalBloomFilter.
+func (b *BloomFilter) MarshalBinary() ([]byte, error) {
+	return b.bf.MarshalBinary()
+}
+
+// UnmarshalBloomFilter decodes a bloom filter encoded by MarshalBinary.
+func UnmarshalBloomFilter(data []byte) (*BloomFilter, error) {
+	bf := &bloom.BloomFilter{}
+	if err := bf.UnmarshalBinary(data); err != nil {
+		return nil, fmt.Errorf("invalid bloom filter: %w", err)
+	}
+	return &BloomFilter{bf}, nil
+}
+
+type resourcesKey struct{}
+
+// LookupFunc looks up the resources which the caller of an HTTP request can see.
+type LookupFunc func(r *http.Request) (*Resources, error)
+
+// Middleware returns HTTP middleware which looks up the resources which the caller of each
+// request can see before handing the request to the next handler, which retrieves them with
+// FromContext. Requests for which the lookup fails are answered with an internal error.
+func Middleware(lookup LookupFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			resources, err := lookup(r)
+			if err != nil {
+				http.Error(w, "could not look up the accessible resources", http.StatusInternalServerError)
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(ContextWithResources(r.Context(), resources)))
+		})
+	}
+}
+
+// ContextWithResources returns a context holding the resources.
+func ContextWithResources(ctx context.Context, resources *Resources) context.Context {
+	return context.WithValue(ctx, resourcesKey{}, resources)
+}
+
+// FromContext returns the resources held by the context, if any.
+func FromContext(ctx context.Context) (*Resources, bool) {
+	resources, ok := ctx.Value(resourcesKey{}).(*Resources)
+	return resources, ok
+}
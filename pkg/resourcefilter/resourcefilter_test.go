@@ -0,0 +1,173 @@
+package resourcefilter
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	sq "github.com/Masterminds/squirrel"
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/testserver"
+)
+
+type fakeStream struct {
+	responses []*v1.LookupResourcesResponse
+	err       error
+}
+
+func (fs *fakeStream) Recv() (*v1.LookupResourcesResponse, error) {
+	if len(fs.responses) == 0 {
+		if fs.err != nil {
+			return nil, fs.err
+		}
+		return nil, io.EOF
+	}
+	resp := fs.responses[0]
+	fs.responses = fs.responses[1:]
+	return resp, nil
+}
+
+func resources(t *testing.T, ids ...string) *Resources {
+	t.Helper()
+
+	stream := &fakeStream{}
+	for _, id := range ids {
+		stream.responses = append(stream.responses, &v1.LookupResourcesResponse{
+			ResourceObjectId: id,
+			Permissionship:   v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_HAS_PERMISSION,
+		})
+	}
+	r, err := Collect(stream)
+	require.NoError(t, err)
+	return r
+}
+
+func TestCollect(t *testing.T) {
+	r, err := Collect(&fakeStream{responses: []*v1.LookupResourcesResponse{
+		{ResourceObjectId: "a", Permissionship: v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_HAS_PERMISSION},
+		{ResourceObjectId: "b", Permissionship: v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_CONDITIONAL_PERMISSION},
+		{ResourceObjectId: "c", Permissionship: v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_HAS_PERMISSION},
+		{ResourceObjectId: "a", Permissionship: v1.LookupPermissionship_LOOKUP_PERMISSIONSHIP_HAS_PERMISSION},
+	}})
+	require.NoError(t, err)
+	require.Equal(t, []string{"a", "c"}, r.IDs())
+	require.Equal(t, []string{"b"}, r.ConditionalIDs())
+	require.True(t, r.Contains("a"))
+	require.False(t, r.Contains("b"))
+
+	_, err = Collect(&fakeStream{err: fmt.Errorf("stream failed")})
+	require.ErrorContains(t, err, "stream failed")
+}
+
+func TestChunks(t *testing.T) {
+	r := resources(t, "a", "b", "c", "d", "e")
+	require.Equal(t, [][]string{{"a", "b"}, {"c", "d"}, {"e"}}, r.Chunks(2))
+	require.Equal(t, [][]string{{"a", "b", "c", "d", "e"}}, r.Chunks(0))
+	require.Empty(t, resources(t).Chunks(2))
+}
+
+func TestWhere(t *testing.T) {
+	tcs := []struct {
+		name         string
+		ids          []string
+		expectedSQL  string
+		expectedArgs []any
+	}{
+		{"no resources", nil, "1 = 0", nil},
+		{"single chunk", []string{"a", "b"}, "id IN ($1,$2)", []any{"a", "b"}},
+		{"several chunks", []string{"a", "b", "c"}, "(id IN ($1,$2) OR id IN ($3))", []any{"a", "b", "c"}},
+	}
+
+	for _, tc := range tcs {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			sql, args, err := sq.Select("*").From("documents").Where(Where("id", resources(t, tc.ids...), 2)).PlaceholderFormat(sq.Dollar).ToSql()
+			require.NoError(t, err)
+			require.Equal(t, "SELECT * FROM documents WHERE "+tc.expectedSQL, sql)
+			require.Equal(t, tc.expectedArgs, args)
+		})
+	}
+}
+
+func TestBloomFilter(t *testing.T) {
+	var ids []string
+	for i := 0; i < 1000; i++ {
+		ids = append(ids, fmt.Sprintf("found-%d", i))
+	}
+
+	bf, err := resources(t, ids...).BloomFilter(0.001)
+	require.NoError(t, err)
+
+	encoded, err := bf.MarshalBinary()
+	require.NoError(t, err)
+	decoded, err := UnmarshalBloomFilter(encoded)
+	require.NoError(t, err)
+
+	for _, id := range ids {
+		require.True(t, decoded.MightContain(id))
+	}
+
+	falsePositives := 0
+	for i := 0; i < 1000; i++ {
+		if decoded.MightContain(fmt.Sprintf("missing-%d", i)) {
+			falsePositives++
+		}
+	}
+	require.Less(t, falsePositives, 10)
+
+	_, err = resources(t).BloomFilter(0)
+	require.Error(t, err)
+	_, err = UnmarshalBloomFilter([]byte("invalid"))
+	require.Error(t, err)
+}
+
+func TestLookupMiddleware(t *testing.T) {
+	server := testserver.NewTestServer(t,
+		testserver.WithSchema(`
+definition user {}
+
+definition document {
+	relation viewer: user
+	permission view = viewer
+}`),
+		testserver.WithRelationships("document:readme#viewer@user:alice", "document:guide#viewer@user:alice", "document:secret#viewer@user:bob"),
+	)
+
+	lookup := func(r *http.Request) (*Resources, error) {
+		return Lookup(r.Context(), server.Client(), &v1.LookupResourcesRequest{
+			Consistency:        &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+			ResourceObjectType: "document",
+			Permission:         "view",
+			Subject:            &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: r.Header.Get("X-User")}},
+		})
+	}
+
+	var found []string
+	handler := Middleware(lookup)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resources, ok := FromContext(r.Context())
+		require.True(t, ok)
+		found = resources.IDs()
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/documents", nil)
+	req.Header.Set("X-User", "alice")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.ElementsMatch(t, []string{"readme", "guide"}, found)
+
+	// Failed lookups are not handed to the next handler.
+	found = nil
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/documents", nil))
+	require.Equal(t, http.StatusInternalServerError, recorder.Code)
+	require.Nil(t, found)
+
+	_, ok := FromContext(context.Background())
+	require.False(t, ok)
+}
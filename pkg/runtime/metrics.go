@@ -1,12 +1,17 @@
 package runtime
 
 import (
+	"runtime"
+	"runtime/debug"
+
+	"github.com/jzelinskie/cobrautil/v2"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
 )
 
 // prometheus client_golang by default registers a collector that collects all metrics, except scheduler metrics
-// this package unregisters the default collector and adds one that includes scheduler metrics
+// this package unregisters the default collector and adds one that includes scheduler metrics, along with
+// a metric describing the build of the running binary
 //
 // in order to register this, the package must be imported anonymously
 func init() {
@@ -14,4 +19,35 @@ func init() {
 	prometheus.MustRegister(collectors.NewGoCollector(
 		collectors.WithGoCollectorRuntimeMetrics(collectors.MetricsAll),
 	))
+	prometheus.MustRegister(newBuildInfoGauge())
+}
+
+// newBuildInfoGauge returns a gauge, always 1, labeled with the version and VCS revision
+// of the running binary and the version of Go with which it was built.
+func newBuildInfoGauge() prometheus.Gauge {
+	labels := prometheus.Labels{
+		"version":    "unknown",
+		"commit":     "unknown",
+		"go_version": runtime.Version(),
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		if version := cobrautil.VersionWithFallbacks(bi); version != "" {
+			labels["version"] = version
+		}
+		for _, setting := range bi.Settings {
+			if setting.Key == "vcs.revision" && setting.Value != "" {
+				labels["commit"] = setting.Value
+			}
+		}
+	}
+
+	gauge := prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace:   "spicedb",
+		Name:        "build_info",
+		Help:        "Information about the build of the running binary, with a constant value of 1.",
+		ConstLabels: labels,
+	})
+	gauge.Set(1)
+	return gauge
 }
@@ -0,0 +1,34 @@
+package runtime
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildInfoGauge(t *testing.T) {
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+
+	for _, family := range families {
+		if family.GetName() != "spicedb_build_info" {
+			continue
+		}
+
+		require.Len(t, family.GetMetric(), 1)
+		metric := family.GetMetric()[0]
+		require.Equal(t, 1.0, metric.GetGauge().GetValue())
+
+		labels := map[string]string{}
+		for _, label := range metric.GetLabel() {
+			labels[label.GetName()] = label.GetValue()
+		}
+		require.Equal(t, runtime.Version(), labels["go_version"])
+		require.NotEmpty(t, labels["version"])
+		require.NotEmpty(t, labels["commit"])
+		return
+	}
+	require.Fail(t, "spicedb_build_info was not registered")
+}
@@ -89,6 +89,20 @@ func GenerateSource(namespace *core.NamespaceDefinition) (string, bool, error) {
 	return generator.buf.String(), !generator.hasIssue, nil
 }
 
+// GenerateRewriteSource generates a DSL view of the given userset rewrite, such as the expression
+// of a permission.
+func GenerateRewriteSource(rewrite *core.UsersetRewrite) (string, bool) {
+	generator := &sourceGenerator{
+		indentationLevel: 0,
+		hasNewline:       true,
+		hasBlankline:     true,
+		hasNewScope:      true,
+	}
+
+	generator.emitRewrite(rewrite)
+	return generator.buf.String(), !generator.hasIssue
+}
+
 func (sg *sourceGenerator) emitCaveat(caveat *core.CaveatDefinition) error {
 	sg.emitComments(caveat.Metadata)
 	sg.append("caveat ")
@@ -371,3 +371,26 @@ definition foos/document {
 		})
 	}
 }
+
+func TestGenerateRewriteSource(t *testing.T) {
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source: input.Source("schema"),
+		SchemaString: `definition foos/test {
+			relation reader: foos/user
+			relation writer: foos/user
+			relation parent: foos/test
+			permission read = reader + writer + parent->read
+			permission minus = (reader - writer) & parent->read
+		}`,
+	}, compiler.AllowUnprefixedObjectType())
+	require.NoError(t, err)
+
+	relations := compiled.ObjectDefinitions[0].Relation
+	source, ok := GenerateRewriteSource(relations[3].UsersetRewrite)
+	require.True(t, ok)
+	require.Equal(t, "reader + writer + parent->read", source)
+
+	source, ok = GenerateRewriteSource(relations[4].UsersetRewrite)
+	require.True(t, ok)
+	require.Equal(t, "(reader - writer) & parent->read", source)
+}
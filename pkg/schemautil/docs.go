@@ -0,0 +1,237 @@
+package schemautil
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"regexp"
+	"strings"
+)
+
+// GenerateMarkdownDocs returns Markdown documentation of the reflected schema, describing each
+// definition with its relations, the subject types they allow and its permissions, each caveat
+// with its parameters, and a Mermaid graph of the relationships between definitions.
+func GenerateMarkdownDocs(reflection *SchemaReflection) string {
+	var sb strings.Builder
+	sb.WriteString("# Schema\n\n")
+
+	if len(reflection.Definitions) > 0 {
+		sb.WriteString("## Relationship graph\n\n")
+		sb.WriteString("```mermaid\n")
+		sb.WriteString(RelationshipGraph(reflection))
+		sb.WriteString("```\n\n")
+	}
+
+	sb.WriteString("## Definitions\n\n")
+	if len(reflection.Definitions) == 0 {
+		sb.WriteString("The schema has no definitions.\n\n")
+	}
+	for _, def := range reflection.Definitions {
+		fmt.Fprintf(&sb, "### `%s`\n\n", def.Name)
+		if comment := commentText(def.Comment); comment != "" {
+			sb.WriteString(comment + "\n\n")
+		}
+
+		if len(def.Relations) > 0 {
+			sb.WriteString("| Relation | Subject types | Description |\n")
+			sb.WriteString("| --- | --- | --- |\n")
+			for _, rel := range def.Relations {
+				subjectTypes := make([]string, 0, len(rel.SubjectTypes))
+				for _, subjectType := range rel.SubjectTypes {
+					subjectTypes = append(subjectTypes, "`"+subjectType.String()+"`")
+				}
+				fmt.Fprintf(&sb, "| `%s` | %s | %s |\n", rel.Name, strings.Join(subjectTypes, ", "), markdownCell(commentText(rel.Comment)))
+			}
+			sb.WriteString("\n")
+		}
+
+		if len(def.Permissions) > 0 {
+			sb.WriteString("| Permission | Expression | Description |\n")
+			sb.WriteString("| --- | --- | --- |\n")
+			for _, perm := range def.Permissions {
+				fmt.Fprintf(&sb, "| `%s` | `%s` | %s |\n", perm.Name, markdownCell(perm.Expression), markdownCell(commentText(perm.Comment)))
+			}
+			sb.WriteString("\n")
+		}
+
+		if len(def.Relations) == 0 && len(def.Permissions) == 0 {
+			sb.WriteString("This definition has no relations or permissions.\n\n")
+		}
+	}
+
+	if len(reflection.Caveats) > 0 {
+		sb.WriteString("## Caveats\n\n")
+		for _, caveat := range reflection.Caveats {
+			fmt.Fprintf(&sb, "### `%s`\n\n", caveat.Name)
+			if comment := commentText(caveat.Comment); comment != "" {
+				sb.WriteString(comment + "\n\n")
+			}
+
+			sb.WriteString("| Parameter | Type |\n")
+			sb.WriteString("| --- | --- |\n")
+			for _, param := range caveat.Parameters {
+				fmt.Fprintf(&sb, "| `%s` | `%s` |\n", param.Name, param.TypeName)
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// GenerateHTMLDocs returns a standalone HTML page documenting the reflected schema, with the
+// same content as GenerateMarkdownDocs. The relationship graph is rendered by Mermaid, which
+// the page loads from a CDN.
+func GenerateHTMLDocs(reflection *SchemaReflection) (string, error) {
+	var buf bytes.Buffer
+	err := htmlDocsTemplate.Execute(&buf, struct {
+		*SchemaReflection
+		Graph string
+	}{reflection, RelationshipGraph(reflection)})
+	if err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// RelationshipGraph returns a Mermaid flowchart of the reflected schema, with an edge from each
+// definition to the definitions of the subjects allowed on its relations.
+func RelationshipGraph(reflection *SchemaReflection) string {
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+	for _, def := range reflection.Definitions {
+		fmt.Fprintf(&sb, "    %s[\"%s\"]\n", graphNodeID(def.Name), def.Name)
+	}
+
+	for _, def := range reflection.Definitions {
+		for _, rel := range def.Relations {
+			for _, subjectType := range rel.SubjectTypes {
+				label := rel.Name
+				switch {
+				case subjectType.IsPublicWildcard:
+					label += " (*)"
+				case subjectType.OptionalRelationName != "":
+					label += " (#" + subjectType.OptionalRelationName + ")"
+				}
+				fmt.Fprintf(&sb, "    %s -->|\"%s\"| %s\n", graphNodeID(def.Name), label, graphNodeID(subjectType.SubjectDefinitionName))
+			}
+		}
+	}
+	return sb.String()
+}
+
+// String returns the subject type as it is written in the schema language, such as
+// `group#member` or `user:* with caveat`.
+func (st SubjectTypeReflection) String() string {
+	s := st.SubjectDefinitionName
+	switch {
+	case st.IsPublicWildcard:
+		s += ":*"
+	case st.OptionalRelationName != "":
+		s += "#" + st.OptionalRelationName
+	}
+
+	if st.OptionalCaveatName != "" {
+		s += " with " + st.OptionalCaveatName
+	}
+	return s
+}
+
+var nonIdentifierCharacters = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+// graphNodeID returns the ID of the node of a definition in the graph, as prefixed names
+// contain characters which cannot be used in IDs.
+func graphNodeID(definitionName string) string {
+	return nonIdentifierCharacters.ReplaceAllString(definitionName, "_")
+}
+
+// commentText returns the text of a comment, without the markers of the comment.
+func commentText(comment string) string {
+	lines := strings.Split(comment, "\n")
+	text := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		line = strings.TrimPrefix(line, "//")
+		line = strings.TrimPrefix(line, "/**")
+		line = strings.TrimPrefix(line, "/*")
+		line = strings.TrimSuffix(line, "*/")
+		line = strings.TrimPrefix(strings.TrimSpace(line), "*")
+		text = append(text, strings.TrimSpace(line))
+	}
+	return strings.TrimSpace(strings.Join(text, "\n"))
+}
+
+// markdownCell escapes text to be placed in a cell of a Markdown table.
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(text, "|", `\|`)
+	return strings.ReplaceAll(text, "\n", "<br>")
+}
+
+var htmlDocsTemplate = template.Must(template.New("docs").Funcs(template.FuncMap{
+	"comment": commentText,
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Schema</title>
+<style>
+body { font-family: sans-serif; max-width: 60em; margin: 2em auto; padding: 0 1em; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+.comment { white-space: pre-line; }
+</style>
+</head>
+<body>
+<h1>Schema</h1>
+{{- if .Definitions}}
+<h2>Relationship graph</h2>
+<pre class="mermaid">
+{{.Graph}}</pre>
+{{- end}}
+<h2>Definitions</h2>
+{{- range .Definitions}}
+<h3 id="{{.Name}}"><code>{{.Name}}</code></h3>
+{{- with comment .Comment}}
+<p class="comment">{{.}}</p>
+{{- end}}
+{{- if .Relations}}
+<table>
+<tr><th>Relation</th><th>Subject types</th><th>Description</th></tr>
+{{- range .Relations}}
+<tr><td><code>{{.Name}}</code></td><td>{{range $i, $st := .SubjectTypes}}{{if $i}}, {{end}}<code>{{$st}}</code>{{end}}</td><td class="comment">{{comment .Comment}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- if .Permissions}}
+<table>
+<tr><th>Permission</th><th>Expression</th><th>Description</th></tr>
+{{- range .Permissions}}
+<tr><td><code>{{.Name}}</code></td><td><code>{{.Expression}}</code></td><td class="comment">{{comment .Comment}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- else}}
+<p>The schema has no definitions.</p>
+{{- end}}
+{{- if .Caveats}}
+<h2>Caveats</h2>
+{{- range .Caveats}}
+<h3 id="caveat-{{.Name}}"><code>{{.Name}}</code></h3>
+{{- with comment .Comment}}
+<p class="comment">{{.}}</p>
+{{- end}}
+<table>
+<tr><th>Parameter</th><th>Type</th></tr>
+{{- range .Parameters}}
+<tr><td><code>{{.Name}}</code></td><td><code>{{.TypeName}}</code></td></tr>
+{{- end}}
+</table>
+{{- end}}
+{{- end}}
+<script type="module">
+import mermaid from "https://cdn.jsdelivr.net/npm/mermaid@10/dist/mermaid.esm.min.mjs";
+mermaid.initialize({ startOnLoad: true });
+</script>
+</body>
+</html>
+`))
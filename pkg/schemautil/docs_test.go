@@ -0,0 +1,102 @@
+package schemautil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+)
+
+const docsTestSchema = `
+caveat only_on_tuesday(day_of_week string) {
+	day_of_week == 'tuesday'
+}
+
+definition test/user {}
+
+/** group is a group of users */
+definition test/group {
+	relation member: test/user | test/group#member
+}
+
+/**
+ * document is a document
+ * which has viewers
+ */
+definition test/document {
+	// viewer can view the document
+	relation viewer: test/user | test/user:* | test/group#member | test/user with only_on_tuesday
+
+	/** view is granted to viewers | owners */
+	permission view = viewer
+}
+`
+
+func TestGenerateMarkdownDocs(t *testing.T) {
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source("schema"),
+		SchemaString: docsTestSchema,
+	}, compiler.AllowUnprefixedObjectType())
+	require.NoError(t, err)
+
+	require.Equal(t, "# Schema\n\n"+
+		"## Relationship graph\n\n"+
+		"```mermaid\n"+
+		"flowchart LR\n"+
+		"    test_document[\"test/document\"]\n"+
+		"    test_group[\"test/group\"]\n"+
+		"    test_user[\"test/user\"]\n"+
+		"    test_document -->|\"viewer\"| test_user\n"+
+		"    test_document -->|\"viewer (*)\"| test_user\n"+
+		"    test_document -->|\"viewer (#member)\"| test_group\n"+
+		"    test_document -->|\"viewer\"| test_user\n"+
+		"    test_group -->|\"member\"| test_user\n"+
+		"    test_group -->|\"member (#member)\"| test_group\n"+
+		"```\n\n"+
+		"## Definitions\n\n"+
+		"### `test/document`\n\n"+
+		"document is a document\nwhich has viewers\n\n"+
+		"| Relation | Subject types | Description |\n"+
+		"| --- | --- | --- |\n"+
+		"| `viewer` | `test/user`, `test/user:*`, `test/group#member`, `test/user with only_on_tuesday` | viewer can view the document |\n\n"+
+		"| Permission | Expression | Description |\n"+
+		"| --- | --- | --- |\n"+
+		"| `view` | `viewer` | view is granted to viewers \\| owners |\n\n"+
+		"### `test/group`\n\n"+
+		"group is a group of users\n\n"+
+		"| Relation | Subject types | Description |\n"+
+		"| --- | --- | --- |\n"+
+		"| `member` | `test/user`, `test/group#member` |  |\n\n"+
+		"### `test/user`\n\n"+
+		"This definition has no relations or permissions.\n\n"+
+		"## Caveats\n\n"+
+		"### `only_on_tuesday`\n\n"+
+		"| Parameter | Type |\n"+
+		"| --- | --- |\n"+
+		"| `day_of_week` | `string` |\n",
+		GenerateMarkdownDocs(ReflectCompiledSchema(compiled)))
+}
+
+func TestGenerateMarkdownDocsEmptySchema(t *testing.T) {
+	require.Equal(t, "# Schema\n\n## Definitions\n\nThe schema has no definitions.\n", GenerateMarkdownDocs(&SchemaReflection{}))
+}
+
+func TestGenerateHTMLDocs(t *testing.T) {
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source("schema"),
+		SchemaString: docsTestSchema,
+	}, compiler.AllowUnprefixedObjectType())
+	require.NoError(t, err)
+
+	html, err := GenerateHTMLDocs(ReflectCompiledSchema(compiled))
+	require.NoError(t, err)
+
+	require.Contains(t, html, `<h3 id="test/document"><code>test/document</code></h3>`)
+	require.Contains(t, html, `<p class="comment">document is a document`+"\n"+`which has viewers</p>`)
+	require.Contains(t, html, `<tr><td><code>viewer</code></td><td><code>test/user</code>, <code>test/user:*</code>, <code>test/group#member</code>, <code>test/user with only_on_tuesday</code></td><td class="comment">viewer can view the document</td></tr>`)
+	require.Contains(t, html, `<tr><td><code>view</code></td><td><code>viewer</code></td><td class="comment">view is granted to viewers | owners</td></tr>`)
+	require.Contains(t, html, `<tr><td><code>day_of_week</code></td><td><code>string</code></td></tr>`)
+	require.Contains(t, html, `test_document --&gt;|&#34;viewer (#member)&#34;| test_group`)
+}
@@ -0,0 +1,372 @@
+package schemautil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+
+	"github.com/authzed/spicedb/pkg/genutil/mapz"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// ObjectGraph is the graph of the relationships reachable from an object, annotated with the
+// parts of the schema through which they were reached.
+type ObjectGraph struct {
+	Root      string            `json:"root"`
+	Nodes     []string          `json:"nodes"`
+	Edges     []ObjectGraphEdge `json:"edges"`
+	Truncated bool              `json:"truncated,omitempty"`
+}
+
+// ObjectGraphEdge is a relationship in an object graph, from its resource to its subject.
+type ObjectGraphEdge struct {
+	Resource        string `json:"resource"`
+	Relation        string `json:"relation"`
+	Subject         string `json:"subject"`
+	SubjectRelation string `json:"subject_relation,omitempty"`
+	Caveat          string `json:"caveat,omitempty"`
+
+	// Permissions are the permissions of the resource's definition computed, directly or
+	// through an arrow, from the relation of the edge.
+	Permissions []string `json:"permissions,omitempty"`
+
+	// Depth is the number of edges between the root and the resource of the edge.
+	Depth int `json:"depth"`
+}
+
+// ObjectGraphOptions configure how much of the graph of an object is built.
+type ObjectGraphOptions struct {
+	// MaxDepth is the maximum number of edges between the root and any edge's subject.
+	MaxDepth int
+
+	// MaxEdges is the maximum number of edges in the graph, beyond which it is truncated.
+	// Zero places no limit.
+	MaxEdges int
+
+	// Permission, if set, restricts the graph to the relationships through which the
+	// permission of the root could be computed; otherwise, every relationship of each object
+	// is followed.
+	Permission string
+}
+
+// RelationshipReader returns the relationships whose resource is the object.
+type RelationshipReader func(ctx context.Context, object *v1.ObjectReference) ([]*v1.Relationship, error)
+
+// BuildObjectGraph builds the graph of the relationships reachable from the root object, reading
+// the relationships of each object it reaches, breadth first, with the reader.
+func BuildObjectGraph(ctx context.Context, objectDefs []*core.NamespaceDefinition, root *v1.ObjectReference, read RelationshipReader, opts ObjectGraphOptions) (*ObjectGraph, error) {
+	defs := make(map[string]*core.NamespaceDefinition, len(objectDefs))
+	for _, def := range objectDefs {
+		defs[def.Name] = def
+	}
+
+	if _, ok := defs[root.ObjectType]; !ok {
+		return nil, fmt.Errorf("object definition `%s` not found", root.ObjectType)
+	}
+
+	if opts.Permission != "" && findRelation(defs[root.ObjectType], opts.Permission) == nil {
+		return nil, fmt.Errorf("relation or permission `%s` not found under definition `%s`", opts.Permission, root.ObjectType)
+	}
+
+	b := &graphBuilder{
+		defs:        defs,
+		read:        read,
+		opts:        opts,
+		permissions: map[string]map[string][]string{},
+		graph:       &ObjectGraph{Root: tuple.StringObjectRef(root)},
+		nodes:       mapz.NewSet[string](),
+		edges:       mapz.NewSet[string](),
+		visited:     mapz.NewSet[string](),
+
+		relationships: map[string][]*v1.Relationship{},
+	}
+	if err := b.build(ctx, root); err != nil {
+		return nil, err
+	}
+
+	b.graph.Nodes = b.nodes.AsSlice()
+	sort.Strings(b.graph.Nodes)
+	return b.graph, nil
+}
+
+// graphTarget is an object reached while building a graph, and the relation or permission
+// computed on it; an empty relation follows all of its relationships.
+type graphTarget struct {
+	object   *v1.ObjectReference
+	relation string
+}
+
+func (t graphTarget) key() string {
+	return tuple.StringObjectRef(t.object) + "#" + t.relation
+}
+
+type graphBuilder struct {
+	defs        map[string]*core.NamespaceDefinition
+	read        RelationshipReader
+	opts        ObjectGraphOptions
+	permissions map[string]map[string][]string
+
+	graph   *ObjectGraph
+	nodes   *mapz.Set[string]
+	edges   *mapz.Set[string]
+	visited *mapz.Set[string]
+
+	// relationships caches those of each object, which may be reached for several relations.
+	relationships map[string][]*v1.Relationship
+}
+
+func (b *graphBuilder) build(ctx context.Context, root *v1.ObjectReference) error {
+	b.nodes.Add(tuple.StringObjectRef(root))
+	frontier := []graphTarget{{object: root, relation: b.opts.Permission}}
+	b.visited.Add(frontier[0].key())
+
+	for depth := 0; depth < b.opts.MaxDepth && len(frontier) > 0; depth++ {
+		var next []graphTarget
+		for _, target := range frontier {
+			reached, err := b.expand(ctx, target, depth)
+			if err != nil {
+				return err
+			}
+
+			for _, t := range reached {
+				if b.visited.Add(t.key()) {
+					next = append(next, t)
+				}
+			}
+
+			if b.graph.Truncated {
+				return nil
+			}
+		}
+		frontier = next
+	}
+	return nil
+}
+
+// expand adds the edges followed from the target to the graph, returning the targets on their
+// subjects.
+func (b *graphBuilder) expand(ctx context.Context, target graphTarget, depth int) ([]graphTarget, error) {
+	def, ok := b.defs[target.object.ObjectType]
+	if !ok {
+		return nil, nil
+	}
+
+	// Without a relation, every relationship is followed to every relation of its subject.
+	var followed map[string]*mapz.Set[string]
+	if target.relation != "" {
+		followed = relationsComputedBy(def, target.relation)
+		if len(followed) == 0 {
+			return nil, nil
+		}
+	}
+
+	resource := tuple.StringObjectRef(target.object)
+	rels, ok := b.relationships[resource]
+	if !ok {
+		var err error
+		rels, err = b.read(ctx, target.object)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read relationships of `%s`: %w", resource, err)
+		}
+		b.relationships[resource] = rels
+	}
+
+	var reached []graphTarget
+	for _, rel := range rels {
+		var continuations *mapz.Set[string]
+		if followed != nil {
+			if continuations, ok = followed[rel.Relation]; !ok {
+				continue
+			}
+		}
+
+		subject := rel.Subject.Object
+		if key := tuple.MustStringRelationship(rel); b.edges.Add(key) {
+			if b.opts.MaxEdges > 0 && len(b.graph.Edges) >= b.opts.MaxEdges {
+				b.graph.Truncated = true
+				return reached, nil
+			}
+			b.addEdge(def, rel, depth)
+		}
+
+		if subject.ObjectId == tuple.PublicWildcard {
+			continue
+		}
+
+		if continuations == nil {
+			reached = append(reached, graphTarget{object: subject})
+			continue
+		}
+
+		for _, continuation := range continuations.AsSlice() {
+			switch {
+			case continuation != "":
+				// An arrow computes the relation on the subject object itself.
+				reached = append(reached, graphTarget{object: subject, relation: continuation})
+			case rel.Subject.OptionalRelation != "":
+				reached = append(reached, graphTarget{object: subject, relation: rel.Subject.OptionalRelation})
+			}
+		}
+	}
+	return reached, nil
+}
+
+// addEdge adds the relationship to the graph as an edge from its resource, reached at the depth.
+func (b *graphBuilder) addEdge(def *core.NamespaceDefinition, rel *v1.Relationship, depth int) {
+	edge := ObjectGraphEdge{
+		Resource:        tuple.StringObjectRef(rel.Resource),
+		Relation:        rel.Relation,
+		Subject:         tuple.StringObjectRef(rel.Subject.Object),
+		SubjectRelation: rel.Subject.OptionalRelation,
+		Permissions:     b.permissionsUsing(def, rel.Relation),
+		Depth:           depth,
+	}
+	if rel.OptionalCaveat != nil {
+		edge.Caveat = rel.OptionalCaveat.CaveatName
+	}
+	b.graph.Edges = append(b.graph.Edges, edge)
+	b.nodes.Add(edge.Subject)
+}
+
+// permissionsUsing returns the permissions of the definition computed from the relation.
+func (b *graphBuilder) permissionsUsing(def *core.NamespaceDefinition, relation string) []string {
+	usages, ok := b.permissions[def.Name]
+	if !ok {
+		usages = map[string][]string{}
+		for _, rel := range def.Relation {
+			if rel.UsersetRewrite == nil {
+				continue
+			}
+
+			for used := range relationsComputedBy(def, rel.Name) {
+				usages[used] = append(usages[used], rel.Name)
+			}
+		}
+		b.permissions[def.Name] = usages
+	}
+	return usages[relation]
+}
+
+// relationsComputedBy returns the relations of the definition whose relationships the relation
+// or permission is computed from, each with the relations computed on their subjects: the
+// relation of an arrow, or an empty string for the subject's own relation.
+func relationsComputedBy(def *core.NamespaceDefinition, name string) map[string]*mapz.Set[string] {
+	followed := map[string]*mapz.Set[string]{}
+	follow := func(relation, continuation string) {
+		if _, ok := followed[relation]; !ok {
+			followed[relation] = mapz.NewSet[string]()
+		}
+		followed[relation].Add(continuation)
+	}
+
+	var visit func(name string, visited *mapz.Set[string])
+	visit = func(name string, visited *mapz.Set[string]) {
+		if !visited.Add(name) {
+			return
+		}
+
+		rel := findRelation(def, name)
+		if rel == nil {
+			return
+		}
+
+		if rel.UsersetRewrite == nil {
+			follow(name, "")
+			return
+		}
+
+		walkChildren(rel.UsersetRewrite, func(child *core.SetOperation_Child) {
+			switch child := child.ChildType.(type) {
+			case *core.SetOperation_Child_ComputedUserset:
+				visit(child.ComputedUserset.Relation, visited)
+			case *core.SetOperation_Child_TupleToUserset:
+				follow(child.TupleToUserset.Tupleset.Relation, child.TupleToUserset.ComputedUserset.Relation)
+			}
+		})
+	}
+	visit(name, mapz.NewSet[string]())
+	return followed
+}
+
+func findRelation(def *core.NamespaceDefinition, name string) *core.Relation {
+	for _, rel := range def.Relation {
+		if rel.Name == name {
+			return rel
+		}
+	}
+	return nil
+}
+
+// DOT returns the graph in the DOT language of Graphviz, with an edge from the resource of each
+// relationship to its subject, labeled with its relation and the permissions computed from it.
+func (g *ObjectGraph) DOT() string {
+	var sb strings.Builder
+	sb.WriteString("digraph {\n")
+	sb.WriteString("    rankdir=LR;\n")
+	for _, node := range g.Nodes {
+		attrs := ""
+		if node == g.Root {
+			attrs = ", style=bold"
+		}
+		fmt.Fprintf(&sb, "    %q [label=%q%s];\n", node, node, attrs)
+	}
+
+	for _, edge := range g.Edges {
+		label := edge.Relation
+		if edge.SubjectRelation != "" {
+			label += " (#" + edge.SubjectRelation + ")"
+		}
+		if edge.Caveat != "" {
+			label += " with " + edge.Caveat
+		}
+		if len(edge.Permissions) > 0 {
+			label += "\n→ " + strings.Join(edge.Permissions, ", ")
+		}
+		fmt.Fprintf(&sb, "    %q -> %q [label=%q];\n", edge.Resource, edge.Subject, label)
+	}
+
+	if g.Truncated {
+		sb.WriteString("    label=\"truncated\";\n")
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// Mermaid returns the graph as a Mermaid flowchart, with each edge labeled as in DOT.
+func (g *ObjectGraph) Mermaid() string {
+	nodeIDs := make(map[string]string, len(g.Nodes))
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+	for index, node := range g.Nodes {
+		nodeIDs[node] = fmt.Sprintf("n%d", index)
+		shape := "[\"%s\"]"
+		if node == g.Root {
+			shape = "[[\"%s\"]]"
+		}
+		fmt.Fprintf(&sb, "    %s"+shape+"\n", nodeIDs[node], mermaidText(node))
+	}
+
+	for _, edge := range g.Edges {
+		label := edge.Relation
+		if edge.SubjectRelation != "" {
+			label += " (#" + edge.SubjectRelation + ")"
+		}
+		if edge.Caveat != "" {
+			label += " with " + edge.Caveat
+		}
+		if len(edge.Permissions) > 0 {
+			label += "<br/>→ " + strings.Join(edge.Permissions, ", ")
+		}
+		fmt.Fprintf(&sb, "    %s -->|\"%s\"| %s\n", nodeIDs[edge.Resource], mermaidText(label), nodeIDs[edge.Subject])
+	}
+	return sb.String()
+}
+
+// mermaidText escapes the quotes of text within a quoted Mermaid label.
+func mermaidText(text string) string {
+	return strings.ReplaceAll(text, `"`, "#quot;")
+}
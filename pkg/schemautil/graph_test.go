@@ -0,0 +1,172 @@
+package schemautil
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+const graphTestSchema = `
+definition user {}
+
+definition group {
+	relation member: user | group#member
+}
+
+definition folder {
+	relation viewer: user | group#member
+	relation auditor: user
+}
+
+definition document {
+	relation parent: folder
+	relation owner: user
+	relation editor: user
+	permission edit = owner + editor
+	permission view = edit + parent->viewer
+}
+`
+
+var graphTestRelationships = []string{
+	"document:plan#parent@folder:shared",
+	"document:plan#owner@user:alice",
+	"document:plan#editor@user:bob",
+	"folder:shared#viewer@group:eng#member",
+	"folder:shared#auditor@user:carol",
+	"group:eng#member@group:backend#member",
+	"group:eng#member@user:dan",
+	"group:backend#member@user:erin",
+}
+
+func buildTestGraph(t *testing.T, root string, opts ObjectGraphOptions) *ObjectGraph {
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source("schema"),
+		SchemaString: graphTestSchema,
+	}, compiler.AllowUnprefixedObjectType())
+	require.NoError(t, err)
+
+	reads := map[string]int{}
+	read := func(_ context.Context, object *v1.ObjectReference) ([]*v1.Relationship, error) {
+		reads[tuple.StringObjectRef(object)]++
+		var rels []*v1.Relationship
+		for _, rel := range graphTestRelationships {
+			parsed := tuple.ParseRel(rel)
+			if tuple.StringObjectRef(parsed.Resource) == tuple.StringObjectRef(object) {
+				rels = append(rels, parsed)
+			}
+		}
+		return rels, nil
+	}
+
+	rootType, rootID, _ := strings.Cut(root, ":")
+	graph, err := BuildObjectGraph(context.Background(), compiled.ObjectDefinitions, &v1.ObjectReference{ObjectType: rootType, ObjectId: rootID}, read, opts)
+	require.NoError(t, err)
+
+	for object, count := range reads {
+		require.Equal(t, 1, count, "relationships of %s read more than once", object)
+	}
+	return graph
+}
+
+func edgeStrings(graph *ObjectGraph) []string {
+	edges := make([]string, 0, len(graph.Edges))
+	for _, edge := range graph.Edges {
+		s := edge.Resource + "#" + edge.Relation + "@" + edge.Subject
+		if edge.SubjectRelation != "" {
+			s += "#" + edge.SubjectRelation
+		}
+		edges = append(edges, s)
+	}
+	return edges
+}
+
+func TestBuildObjectGraph(t *testing.T) {
+	graph := buildTestGraph(t, "document:plan", ObjectGraphOptions{MaxDepth: 2})
+	require.Equal(t, "document:plan", graph.Root)
+	require.False(t, graph.Truncated)
+	require.ElementsMatch(t, []string{
+		"document:plan#parent@folder:shared",
+		"document:plan#owner@user:alice",
+		"document:plan#editor@user:bob",
+		"folder:shared#viewer@group:eng#member",
+		"folder:shared#auditor@user:carol",
+	}, edgeStrings(graph))
+	require.Equal(t, []string{"document:plan", "folder:shared", "group:eng", "user:alice", "user:bob", "user:carol"}, graph.Nodes)
+
+	// Edges are annotated with the permissions computed from their relations.
+	for _, edge := range graph.Edges {
+		switch edge.Relation {
+		case "owner", "editor":
+			require.ElementsMatch(t, []string{"edit", "view"}, edge.Permissions)
+			require.Equal(t, 0, edge.Depth)
+		case "parent":
+			require.Equal(t, []string{"view"}, edge.Permissions)
+		default:
+			require.Empty(t, edge.Permissions)
+			require.Equal(t, 1, edge.Depth)
+		}
+	}
+}
+
+func TestBuildObjectGraphForPermission(t *testing.T) {
+	// Only the relationships through which the permission could be computed are followed:
+	// the auditor of the folder is not reached through the arrow to its viewers.
+	graph := buildTestGraph(t, "document:plan", ObjectGraphOptions{MaxDepth: 10, Permission: "view"})
+	require.ElementsMatch(t, []string{
+		"document:plan#parent@folder:shared",
+		"document:plan#owner@user:alice",
+		"document:plan#editor@user:bob",
+		"folder:shared#viewer@group:eng#member",
+		"group:eng#member@group:backend#member",
+		"group:eng#member@user:dan",
+		"group:backend#member@user:erin",
+	}, edgeStrings(graph))
+
+	graph = buildTestGraph(t, "document:plan", ObjectGraphOptions{MaxDepth: 10, Permission: "edit"})
+	require.ElementsMatch(t, []string{
+		"document:plan#owner@user:alice",
+		"document:plan#editor@user:bob",
+	}, edgeStrings(graph))
+}
+
+func TestBuildObjectGraphTruncated(t *testing.T) {
+	graph := buildTestGraph(t, "document:plan", ObjectGraphOptions{MaxDepth: 10, MaxEdges: 2})
+	require.True(t, graph.Truncated)
+	require.Len(t, graph.Edges, 2)
+}
+
+func TestBuildObjectGraphErrors(t *testing.T) {
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source:       input.Source("schema"),
+		SchemaString: graphTestSchema,
+	}, compiler.AllowUnprefixedObjectType())
+	require.NoError(t, err)
+
+	_, err = BuildObjectGraph(context.Background(), compiled.ObjectDefinitions, &v1.ObjectReference{ObjectType: "missing", ObjectId: "id"}, nil, ObjectGraphOptions{MaxDepth: 1})
+	require.ErrorContains(t, err, "object definition `missing` not found")
+
+	_, err = BuildObjectGraph(context.Background(), compiled.ObjectDefinitions, &v1.ObjectReference{ObjectType: "document", ObjectId: "plan"}, nil, ObjectGraphOptions{MaxDepth: 1, Permission: "delete"})
+	require.ErrorContains(t, err, "relation or permission `delete` not found")
+}
+
+func TestObjectGraphDOT(t *testing.T) {
+	graph := buildTestGraph(t, "document:plan", ObjectGraphOptions{MaxDepth: 10, Permission: "edit"})
+	dot := graph.DOT()
+	require.Contains(t, dot, "\"document:plan\" [label=\"document:plan\", style=bold];\n")
+	require.Contains(t, dot, "\"document:plan\" -> \"user:alice\" [label=\"owner\\n→ edit, view\"];\n")
+}
+
+func TestObjectGraphMermaid(t *testing.T) {
+	graph := buildTestGraph(t, "document:plan", ObjectGraphOptions{MaxDepth: 10, Permission: "edit"})
+	mermaid := graph.Mermaid()
+	require.True(t, strings.HasPrefix(mermaid, "flowchart LR\n"))
+	require.Contains(t, mermaid, "    n0[[\"document:plan\"]]\n")
+	require.Regexp(t, `\n    n0 -->\|"owner<br/>→ edit, view"\| n\d+\n`, mermaid)
+}
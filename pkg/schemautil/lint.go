@@ -0,0 +1,361 @@
+package schemautil
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+)
+
+// LintRule identifies a check made by Lint.
+type LintRule string
+
+const (
+	// LintUnusedRelation reports relations which no permission, arrow or subject type refers to.
+	LintUnusedRelation LintRule = "unused-relation"
+
+	// LintUnreachablePermission reports permissions which can never be granted to any subject.
+	LintUnreachablePermission LintRule = "unreachable-permission"
+
+	// LintWildcardSubjectType reports relations which allow every subject of a type through a
+	// wildcard, rather than restricting the subjects which can be written.
+	LintWildcardSubjectType LintRule = "wildcard-subject-type"
+
+	// LintDeepNesting reports permissions whose expressions nest more deeply than allowed.
+	LintDeepNesting LintRule = "deep-nesting"
+
+	// LintNaming reports names which break naming conventions.
+	LintNaming LintRule = "naming"
+)
+
+// AllLintRules are the rules checked by Lint, unless disabled.
+var AllLintRules = []LintRule{
+	LintUnusedRelation,
+	LintUnreachablePermission,
+	LintWildcardSubjectType,
+	LintDeepNesting,
+	LintNaming,
+}
+
+// DefaultMaxNestingDepth is the default depth of nested operations allowed in a permission.
+const DefaultMaxNestingDepth = 3
+
+// LintOptions configure Lint.
+type LintOptions struct {
+	// MaxNestingDepth is the depth of nested operations allowed in a permission. If zero,
+	// DefaultMaxNestingDepth is used.
+	MaxNestingDepth int
+
+	// DisabledRules are the rules which are not checked.
+	DisabledRules []LintRule
+}
+
+// LintWarning is a schema smell found by Lint.
+type LintWarning struct {
+	Rule       LintRule `json:"rule"`
+	Definition string   `json:"definition"`
+	Relation   string   `json:"relation,omitempty"`
+	Message    string   `json:"message"`
+
+	// Line and Column are the one-indexed position of the definition or relation in the
+	// schema, or zero if it is not known.
+	Line   uint64 `json:"line"`
+	Column uint64 `json:"column"`
+}
+
+// Lint checks the object definitions of a schema for common smells, returning the warnings
+// found ordered by their position in the schema.
+func Lint(objectDefs []*core.NamespaceDefinition, opts LintOptions) []LintWarning {
+	if opts.MaxNestingDepth == 0 {
+		opts.MaxNestingDepth = DefaultMaxNestingDepth
+	}
+
+	l := &linter{
+		opts:      opts,
+		defs:      make(map[string]*core.NamespaceDefinition, len(objectDefs)),
+		relations: map[relationKey]*core.Relation{},
+	}
+	for _, def := range objectDefs {
+		l.defs[def.Name] = def
+		for _, rel := range def.Relation {
+			l.relations[relationKey{def.Name, rel.Name}] = rel
+		}
+	}
+
+	for _, rule := range AllLintRules {
+		if slices.Contains(opts.DisabledRules, rule) {
+			continue
+		}
+
+		switch rule {
+		case LintUnusedRelation:
+			l.lintUnusedRelations(objectDefs)
+		case LintUnreachablePermission:
+			l.lintUnreachablePermissions(objectDefs)
+		case LintWildcardSubjectType:
+			l.lintWildcardSubjectTypes(objectDefs)
+		case LintDeepNesting:
+			l.lintDeepNesting(objectDefs)
+		case LintNaming:
+			l.lintNaming(objectDefs)
+		}
+	}
+
+	sort.SliceStable(l.warnings, func(i, j int) bool {
+		if l.warnings[i].Line != l.warnings[j].Line {
+			return l.warnings[i].Line < l.warnings[j].Line
+		}
+		return l.warnings[i].Column < l.warnings[j].Column
+	})
+	return l.warnings
+}
+
+type relationKey struct {
+	definition string
+	relation   string
+}
+
+type linter struct {
+	opts      LintOptions
+	defs      map[string]*core.NamespaceDefinition
+	relations map[relationKey]*core.Relation
+	warnings  []LintWarning
+}
+
+func (l *linter) warn(rule LintRule, def *core.NamespaceDefinition, rel *core.Relation, format string, args ...any) {
+	warning := LintWarning{
+		Rule:       rule,
+		Definition: def.Name,
+		Message:    fmt.Sprintf(format, args...),
+	}
+
+	position := def.SourcePosition
+	if rel != nil {
+		warning.Relation = rel.Name
+		position = rel.SourcePosition
+	}
+	if position != nil {
+		warning.Line = position.ZeroIndexedLineNumber + 1
+		warning.Column = position.ZeroIndexedColumnPosition + 1
+	}
+
+	l.warnings = append(l.warnings, warning)
+}
+
+func (l *linter) lintUnusedRelations(objectDefs []*core.NamespaceDefinition) {
+	used := map[relationKey]bool{}
+	for _, def := range objectDefs {
+		for _, rel := range def.Relation {
+			for _, allowed := range allowedRelations(rel) {
+				if allowed.GetRelation() != "" {
+					used[relationKey{allowed.Namespace, allowed.GetRelation()}] = true
+				}
+			}
+
+			walkChildren(rel.UsersetRewrite, func(child *core.SetOperation_Child) {
+				switch child := child.ChildType.(type) {
+				case *core.SetOperation_Child_ComputedUserset:
+					used[relationKey{def.Name, child.ComputedUserset.Relation}] = true
+
+				case *core.SetOperation_Child_TupleToUserset:
+					tuplesetRelation := child.TupleToUserset.Tupleset.Relation
+					used[relationKey{def.Name, tuplesetRelation}] = true
+
+					// The computed relation of an arrow is used on every type the arrow walks to.
+					for _, allowed := range allowedRelations(l.relations[relationKey{def.Name, tuplesetRelation}]) {
+						used[relationKey{allowed.Namespace, child.TupleToUserset.ComputedUserset.Relation}] = true
+					}
+				}
+			})
+		}
+	}
+
+	for _, def := range objectDefs {
+		for _, rel := range def.Relation {
+			if !isPermission(rel) && !used[relationKey{def.Name, rel.Name}] {
+				l.warn(LintUnusedRelation, def, rel, "relation `%s` is not used by any permission, arrow or subject type", rel.Name)
+			}
+		}
+	}
+}
+
+func (l *linter) lintUnreachablePermissions(objectDefs []*core.NamespaceDefinition) {
+	// A relation can be granted if it allows any subject type, and a permission if its
+	// expression can be satisfied by relations which can be granted. As permissions may
+	// refer to each other, this is computed until nothing changes.
+	reachable := map[relationKey]bool{}
+	for key, rel := range l.relations {
+		if !isPermission(rel) {
+			reachable[key] = len(allowedRelations(rel)) > 0
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, def := range objectDefs {
+			for _, rel := range def.Relation {
+				key := relationKey{def.Name, rel.Name}
+				if !isPermission(rel) || reachable[key] {
+					continue
+				}
+
+				if l.isRewriteReachable(def.Name, rel, rel.UsersetRewrite, reachable) {
+					reachable[key] = true
+					changed = true
+				}
+			}
+		}
+	}
+
+	for _, def := range objectDefs {
+		for _, rel := range def.Relation {
+			if isPermission(rel) && !reachable[relationKey{def.Name, rel.Name}] {
+				l.warn(LintUnreachablePermission, def, rel, "permission `%s` can never be granted to any subject", rel.Name)
+			}
+		}
+	}
+}
+
+func (l *linter) isRewriteReachable(defName string, rel *core.Relation, rewrite *core.UsersetRewrite, reachable map[relationKey]bool) bool {
+	isChildReachable := func(child *core.SetOperation_Child) bool {
+		switch child := child.ChildType.(type) {
+		case *core.SetOperation_Child_XThis:
+			return len(allowedRelations(rel)) > 0
+
+		case *core.SetOperation_Child_ComputedUserset:
+			return reachable[relationKey{defName, child.ComputedUserset.Relation}]
+
+		case *core.SetOperation_Child_TupleToUserset:
+			tuplesetKey := relationKey{defName, child.TupleToUserset.Tupleset.Relation}
+			if !reachable[tuplesetKey] {
+				return false
+			}
+
+			for _, allowed := range allowedRelations(l.relations[tuplesetKey]) {
+				if allowed.GetPublicWildcard() == nil && reachable[relationKey{allowed.Namespace, child.TupleToUserset.ComputedUserset.Relation}] {
+					return true
+				}
+			}
+			return false
+
+		case *core.SetOperation_Child_UsersetRewrite:
+			return l.isRewriteReachable(defName, rel, child.UsersetRewrite, reachable)
+
+		default:
+			return false
+		}
+	}
+
+	switch op := rewrite.GetRewriteOperation().(type) {
+	case *core.UsersetRewrite_Union:
+		return slices.ContainsFunc(op.Union.Child, isChildReachable)
+
+	case *core.UsersetRewrite_Intersection:
+		for _, child := range op.Intersection.Child {
+			if !isChildReachable(child) {
+				return false
+			}
+		}
+		return len(op.Intersection.Child) > 0
+
+	case *core.UsersetRewrite_Exclusion:
+		// Only the base of an exclusion grants subjects.
+		return len(op.Exclusion.Child) > 0 && isChildReachable(op.Exclusion.Child[0])
+
+	default:
+		return false
+	}
+}
+
+func (l *linter) lintWildcardSubjectTypes(objectDefs []*core.NamespaceDefinition) {
+	for _, def := range objectDefs {
+		for _, rel := range def.Relation {
+			for _, allowed := range allowedRelations(rel) {
+				if allowed.GetPublicWildcard() != nil {
+					l.warn(LintWildcardSubjectType, def, rel, "relation `%s` allows every subject of type `%s` through `%s:*`", rel.Name, allowed.Namespace, allowed.Namespace)
+				}
+			}
+		}
+	}
+}
+
+func (l *linter) lintDeepNesting(objectDefs []*core.NamespaceDefinition) {
+	for _, def := range objectDefs {
+		for _, rel := range def.Relation {
+			if depth := nestingDepth(rel.UsersetRewrite); depth > l.opts.MaxNestingDepth {
+				l.warn(LintDeepNesting, def, rel, "permission `%s` nests %d levels of operations, more than the %d allowed", rel.Name, depth, l.opts.MaxNestingDepth)
+			}
+		}
+	}
+}
+
+func (l *linter) lintNaming(objectDefs []*core.NamespaceDefinition) {
+	for _, def := range objectDefs {
+		// The name of a definition may be prefixed, such as `tenant/document`.
+		defName := def.Name
+		if _, unprefixed, ok := strings.Cut(def.Name, "/"); ok {
+			defName = unprefixed
+		}
+
+		for _, rel := range def.Relation {
+			kind := "relation"
+			if isPermission(rel) {
+				kind = "permission"
+			}
+
+			if rel.Name == defName {
+				l.warn(LintNaming, def, rel, "%s `%s` has the name of its definition", kind, rel.Name)
+			}
+
+			if strings.Contains(rel.Name, "__") {
+				l.warn(LintNaming, def, rel, "%s `%s` contains consecutive underscores", kind, rel.Name)
+			}
+		}
+	}
+}
+
+func allowedRelations(rel *core.Relation) []*core.AllowedRelation {
+	return rel.GetTypeInformation().GetAllowedDirectRelations()
+}
+
+// walkChildren calls the function with every child of the rewrite, including those of
+// nested rewrites.
+func walkChildren(rewrite *core.UsersetRewrite, fn func(child *core.SetOperation_Child)) {
+	for _, child := range rewriteChildren(rewrite) {
+		fn(child)
+		if nested, ok := child.ChildType.(*core.SetOperation_Child_UsersetRewrite); ok {
+			walkChildren(nested.UsersetRewrite, fn)
+		}
+	}
+}
+
+// nestingDepth returns how deeply the operations of the rewrite are nested, which is zero
+// without a rewrite.
+func nestingDepth(rewrite *core.UsersetRewrite) int {
+	if rewrite == nil {
+		return 0
+	}
+
+	deepest := 0
+	for _, child := range rewriteChildren(rewrite) {
+		if nested, ok := child.ChildType.(*core.SetOperation_Child_UsersetRewrite); ok {
+			deepest = max(deepest, nestingDepth(nested.UsersetRewrite))
+		}
+	}
+	return deepest + 1
+}
+
+func rewriteChildren(rewrite *core.UsersetRewrite) []*core.SetOperation_Child {
+	switch op := rewrite.GetRewriteOperation().(type) {
+	case *core.UsersetRewrite_Union:
+		return op.Union.Child
+	case *core.UsersetRewrite_Intersection:
+		return op.Intersection.Child
+	case *core.UsersetRewrite_Exclusion:
+		return op.Exclusion.Child
+	default:
+		return nil
+	}
+}
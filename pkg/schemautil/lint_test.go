@@ -0,0 +1,178 @@
+package schemautil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+)
+
+func TestLint(t *testing.T) {
+	testCases := []struct {
+		name     string
+		schema   string
+		opts     LintOptions
+		expected []LintWarning
+	}{
+		{
+			name: "clean schema",
+			schema: `definition user {}
+
+definition group {
+	relation member: user | group#member
+}
+
+definition folder {
+	relation viewer: user | group#member
+	permission view = viewer
+}
+
+definition document {
+	relation parent: folder
+	relation viewer: user
+	permission view = viewer + parent->view
+}`,
+		},
+		{
+			name: "unused relation",
+			schema: `definition user {}
+
+definition document {
+	relation viewer: user
+	relation archived_by: user
+	permission view = viewer
+}`,
+			expected: []LintWarning{
+				{Rule: LintUnusedRelation, Definition: "document", Relation: "archived_by", Message: "relation `archived_by` is not used by any permission, arrow or subject type", Line: 5, Column: 2},
+			},
+		},
+		{
+			name: "relation used only through an arrow",
+			schema: `definition user {}
+
+definition folder {
+	relation viewer: user
+}
+
+definition document {
+	relation parent: folder
+	permission view = parent->viewer
+}`,
+		},
+		{
+			name: "unreachable permissions",
+			schema: `definition user {}
+
+definition folder {
+	relation owner: user
+}
+
+definition document {
+	relation parent: folder
+	relation viewer: user
+	permission missing = viewer & nil
+	permission through_missing = viewer & missing
+	permission view = viewer - missing
+	permission arrow = parent->viewer
+}`,
+			opts: LintOptions{DisabledRules: []LintRule{LintUnusedRelation}},
+			expected: []LintWarning{
+				{Rule: LintUnreachablePermission, Definition: "document", Relation: "missing", Message: "permission `missing` can never be granted to any subject", Line: 10, Column: 2},
+				{Rule: LintUnreachablePermission, Definition: "document", Relation: "through_missing", Message: "permission `through_missing` can never be granted to any subject", Line: 11, Column: 2},
+				{Rule: LintUnreachablePermission, Definition: "document", Relation: "arrow", Message: "permission `arrow` can never be granted to any subject", Line: 13, Column: 2},
+			},
+		},
+		{
+			name: "recursive permission",
+			schema: `definition user {}
+
+definition folder {
+	relation parent: folder
+	relation viewer: user
+	permission view = viewer + parent->view
+}`,
+		},
+		{
+			name: "wildcard subject type",
+			schema: `definition user {}
+
+definition document {
+	relation viewer: user | user:*
+	permission view = viewer
+}`,
+			expected: []LintWarning{
+				{Rule: LintWildcardSubjectType, Definition: "document", Relation: "viewer", Message: "relation `viewer` allows every subject of type `user` through `user:*`", Line: 4, Column: 2},
+			},
+		},
+		{
+			name: "deep nesting",
+			schema: `definition user {}
+
+definition document {
+	relation first: user
+	relation second: user
+	relation third: user
+	relation fourth: user
+	permission shallow = first + (second & (third - fourth))
+	permission deep = first + (second & (third - (fourth + (first & second))))
+}`,
+			expected: []LintWarning{
+				{Rule: LintDeepNesting, Definition: "document", Relation: "deep", Message: "permission `deep` nests 5 levels of operations, more than the 3 allowed", Line: 9, Column: 2},
+			},
+		},
+		{
+			name: "deep nesting with a custom depth",
+			schema: `definition user {}
+
+definition document {
+	relation first: user
+	relation second: user
+	permission view = first + (first & second)
+}`,
+			opts: LintOptions{MaxNestingDepth: 1},
+			expected: []LintWarning{
+				{Rule: LintDeepNesting, Definition: "document", Relation: "view", Message: "permission `view` nests 2 levels of operations, more than the 1 allowed", Line: 6, Column: 2},
+			},
+		},
+		{
+			name: "naming",
+			schema: `definition user {}
+
+definition group {
+	relation group: user
+	relation direct__member: user
+	permission member = group + direct__member
+}`,
+			expected: []LintWarning{
+				{Rule: LintNaming, Definition: "group", Relation: "group", Message: "relation `group` has the name of its definition", Line: 4, Column: 2},
+				{Rule: LintNaming, Definition: "group", Relation: "direct__member", Message: "relation `direct__member` contains consecutive underscores", Line: 5, Column: 2},
+			},
+		},
+		{
+			name: "disabled rules",
+			schema: `definition user {}
+
+definition document {
+	relation viewer: user:*
+	relation unused: user
+	permission view = viewer
+}`,
+			opts: LintOptions{DisabledRules: []LintRule{LintUnusedRelation, LintWildcardSubjectType}},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			compiled, err := compiler.Compile(compiler.InputSchema{
+				Source:       input.Source("schema"),
+				SchemaString: tc.schema,
+			}, compiler.AllowUnprefixedObjectType())
+			require.NoError(t, err)
+
+			require.Equal(t, tc.expected, Lint(compiled.ObjectDefinitions, tc.opts))
+		})
+	}
+}
@@ -0,0 +1,191 @@
+package schemautil
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/authzed/spicedb/pkg/namespace"
+	core "github.com/authzed/spicedb/pkg/proto/core/v1"
+	iv1 "github.com/authzed/spicedb/pkg/proto/impl/v1"
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/generator"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// SchemaReflection is a structured view of a schema, suitable for tooling that generates
+// code or middleware from the defined object definitions.
+type SchemaReflection struct {
+	Definitions []DefinitionReflection `json:"definitions"`
+	Caveats     []CaveatReflection     `json:"caveats"`
+}
+
+// DefinitionReflection describes a single object definition in a schema.
+type DefinitionReflection struct {
+	Name        string                 `json:"name"`
+	Comment     string                 `json:"comment,omitempty"`
+	Relations   []RelationReflection   `json:"relations"`
+	Permissions []PermissionReflection `json:"permissions"`
+}
+
+// RelationReflection describes a relation defined on an object definition.
+type RelationReflection struct {
+	Name         string                  `json:"name"`
+	Comment      string                  `json:"comment,omitempty"`
+	SubjectTypes []SubjectTypeReflection `json:"subject_types"`
+}
+
+// SubjectTypeReflection describes a type of subject allowed on a relation.
+type SubjectTypeReflection struct {
+	SubjectDefinitionName string `json:"subject_definition_name"`
+	OptionalRelationName  string `json:"optional_relation_name,omitempty"`
+	IsPublicWildcard      bool   `json:"is_public_wildcard,omitempty"`
+	OptionalCaveatName    string `json:"optional_caveat_name,omitempty"`
+}
+
+// PermissionReflection describes a permission defined on an object definition.
+type PermissionReflection struct {
+	Name       string `json:"name"`
+	Comment    string `json:"comment,omitempty"`
+	Expression string `json:"expression"`
+}
+
+// CaveatReflection describes a caveat defined in a schema.
+type CaveatReflection struct {
+	Name       string                      `json:"name"`
+	Comment    string                      `json:"comment,omitempty"`
+	Parameters []CaveatParameterReflection `json:"parameters"`
+}
+
+// CaveatParameterReflection describes a single parameter of a caveat.
+type CaveatParameterReflection struct {
+	Name     string `json:"name"`
+	TypeName string `json:"type_name"`
+}
+
+// ReflectCompiledSchema returns the reflection of the given compiled schema.
+func ReflectCompiledSchema(compiled *compiler.CompiledSchema) *SchemaReflection {
+	return ReflectSchema(compiled.ObjectDefinitions, compiled.CaveatDefinitions)
+}
+
+// ReflectSchema returns the reflection of the schema formed by the given object and caveat
+// definitions. Definitions and caveats are returned sorted by name, while relations and
+// permissions are returned in the order in which they were defined.
+func ReflectSchema(objectDefs []*core.NamespaceDefinition, caveatDefs []*core.CaveatDefinition) *SchemaReflection {
+	reflection := &SchemaReflection{
+		Definitions: make([]DefinitionReflection, 0, len(objectDefs)),
+		Caveats:     make([]CaveatReflection, 0, len(caveatDefs)),
+	}
+
+	for _, def := range objectDefs {
+		reflection.Definitions = append(reflection.Definitions, reflectDefinition(def))
+	}
+
+	for _, caveatDef := range caveatDefs {
+		reflection.Caveats = append(reflection.Caveats, reflectCaveat(caveatDef))
+	}
+
+	sort.Slice(reflection.Definitions, func(i, j int) bool {
+		return reflection.Definitions[i].Name < reflection.Definitions[j].Name
+	})
+	sort.Slice(reflection.Caveats, func(i, j int) bool {
+		return reflection.Caveats[i].Name < reflection.Caveats[j].Name
+	})
+	return reflection
+}
+
+func reflectDefinition(def *core.NamespaceDefinition) DefinitionReflection {
+	reflected := DefinitionReflection{
+		Name:        def.Name,
+		Comment:     commentFor(def.Metadata),
+		Relations:   []RelationReflection{},
+		Permissions: []PermissionReflection{},
+	}
+
+	for _, relation := range def.Relation {
+		if isPermission(relation) {
+			expression, _ := generator.GenerateRewriteSource(relation.UsersetRewrite)
+			reflected.Permissions = append(reflected.Permissions, PermissionReflection{
+				Name:       relation.Name,
+				Comment:    commentFor(relation.Metadata),
+				Expression: expression,
+			})
+			continue
+		}
+
+		subjectTypes := make([]SubjectTypeReflection, 0, len(relation.GetTypeInformation().GetAllowedDirectRelations()))
+		for _, allowed := range relation.GetTypeInformation().GetAllowedDirectRelations() {
+			subjectType := SubjectTypeReflection{
+				SubjectDefinitionName: allowed.Namespace,
+				IsPublicWildcard:      allowed.GetPublicWildcard() != nil,
+			}
+
+			if relationName := allowed.GetRelation(); relationName != "" && relationName != tuple.Ellipsis {
+				subjectType.OptionalRelationName = relationName
+			}
+
+			if allowed.GetRequiredCaveat() != nil {
+				subjectType.OptionalCaveatName = allowed.GetRequiredCaveat().CaveatName
+			}
+
+			subjectTypes = append(subjectTypes, subjectType)
+		}
+
+		reflected.Relations = append(reflected.Relations, RelationReflection{
+			Name:         relation.Name,
+			Comment:      commentFor(relation.Metadata),
+			SubjectTypes: subjectTypes,
+		})
+	}
+
+	return reflected
+}
+
+func reflectCaveat(caveatDef *core.CaveatDefinition) CaveatReflection {
+	parameters := make([]CaveatParameterReflection, 0, len(caveatDef.ParameterTypes))
+	for name, typeRef := range caveatDef.ParameterTypes {
+		parameters = append(parameters, CaveatParameterReflection{
+			Name:     name,
+			TypeName: caveatTypeName(typeRef),
+		})
+	}
+
+	sort.Slice(parameters, func(i, j int) bool {
+		return parameters[i].Name < parameters[j].Name
+	})
+
+	return CaveatReflection{
+		Name:       caveatDef.Name,
+		Comment:    commentFor(caveatDef.Metadata),
+		Parameters: parameters,
+	}
+}
+
+// isPermission returns whether the relation is a permission. Relations compiled from the
+// schema language carry their kind in metadata; otherwise, any relation with a rewrite is
+// considered a permission.
+func isPermission(relation *core.Relation) bool {
+	switch namespace.GetRelationKind(relation) {
+	case iv1.RelationMetadata_PERMISSION:
+		return true
+	case iv1.RelationMetadata_RELATION:
+		return false
+	default:
+		return relation.UsersetRewrite != nil
+	}
+}
+
+func caveatTypeName(typeRef *core.CaveatTypeReference) string {
+	if len(typeRef.ChildTypes) == 0 {
+		return typeRef.TypeName
+	}
+
+	childNames := make([]string, 0, len(typeRef.ChildTypes))
+	for _, child := range typeRef.ChildTypes {
+		childNames = append(childNames, caveatTypeName(child))
+	}
+	return typeRef.TypeName + "<" + strings.Join(childNames, ", ") + ">"
+}
+
+func commentFor(metadata *core.Metadata) string {
+	return strings.Join(namespace.GetComments(metadata), "\n")
+}
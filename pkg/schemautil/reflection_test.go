@@ -0,0 +1,95 @@
+package schemautil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/authzed/spicedb/pkg/schemadsl/compiler"
+	"github.com/authzed/spicedb/pkg/schemadsl/input"
+)
+
+func TestReflectCompiledSchema(t *testing.T) {
+	compiled, err := compiler.Compile(compiler.InputSchema{
+		Source: input.Source("schema"),
+		SchemaString: `
+			caveat only_on_tuesday(day_of_week string, allowed_days list<string>) {
+				day_of_week in allowed_days
+			}
+
+			definition user {}
+
+			definition group {
+				relation member: user | group#member
+			}
+
+			/** document is a document */
+			definition document {
+				/** viewer can view */
+				relation viewer: user | user:* | group#member | user with only_on_tuesday
+				relation owner: user
+
+				permission view = viewer + owner
+			}
+		`,
+	}, compiler.AllowUnprefixedObjectType())
+	require.NoError(t, err)
+
+	reflected := ReflectCompiledSchema(compiled)
+	require.Equal(t, &SchemaReflection{
+		Definitions: []DefinitionReflection{
+			{
+				Name:    "document",
+				Comment: "/** document is a document */",
+				Relations: []RelationReflection{
+					{
+						Name:    "viewer",
+						Comment: "/** viewer can view */",
+						SubjectTypes: []SubjectTypeReflection{
+							{SubjectDefinitionName: "user"},
+							{SubjectDefinitionName: "user", IsPublicWildcard: true},
+							{SubjectDefinitionName: "group", OptionalRelationName: "member"},
+							{SubjectDefinitionName: "user", OptionalCaveatName: "only_on_tuesday"},
+						},
+					},
+					{
+						Name: "owner",
+						SubjectTypes: []SubjectTypeReflection{
+							{SubjectDefinitionName: "user"},
+						},
+					},
+				},
+				Permissions: []PermissionReflection{
+					{Name: "view", Expression: "viewer + owner"},
+				},
+			},
+			{
+				Name: "group",
+				Relations: []RelationReflection{
+					{
+						Name: "member",
+						SubjectTypes: []SubjectTypeReflection{
+							{SubjectDefinitionName: "user"},
+							{SubjectDefinitionName: "group", OptionalRelationName: "member"},
+						},
+					},
+				},
+				Permissions: []PermissionReflection{},
+			},
+			{
+				Name:        "user",
+				Relations:   []RelationReflection{},
+				Permissions: []PermissionReflection{},
+			},
+		},
+		Caveats: []CaveatReflection{
+			{
+				Name: "only_on_tuesday",
+				Parameters: []CaveatParameterReflection{
+					{Name: "allowed_days", TypeName: "list<string>"},
+					{Name: "day_of_week", TypeName: "string"},
+				},
+			},
+		},
+	}, reflected)
+}
@@ -2,6 +2,7 @@ package spiceerrors
 
 import (
 	"errors"
+	"time"
 
 	log "github.com/authzed/spicedb/internal/logging"
 
@@ -9,6 +10,7 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/runtime/protoiface"
+	"google.golang.org/protobuf/types/known/durationpb"
 
 	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
 )
@@ -43,6 +45,14 @@ func ForReason(reason v1.ErrorReason, metadata map[string]string) *errdetails.Er
 	}
 }
 
+// ForRetry returns a RetryInfo block indicating that the request can be retried, after waiting
+// at least the given delay.
+func ForRetry(delay time.Duration) *errdetails.RetryInfo {
+	return &errdetails.RetryInfo{
+		RetryDelay: durationpb.New(delay),
+	}
+}
+
 // WithCodeAndReason returns a new error which wraps the existing error with a gRPC code and
 // a reason block.
 func WithCodeAndReason(err error, code codes.Code, reason v1.ErrorReason) error {
@@ -0,0 +1,204 @@
+// Package testserver runs a SpiceDB server in-process, backed by an in-memory datastore and
+// served over an in-memory connection, for testing applications against a real SpiceDB.
+package testserver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	authzed "github.com/authzed/authzed-go/v1"
+	"google.golang.org/grpc"
+
+	"github.com/authzed/spicedb/internal/datastore/memdb"
+	"github.com/authzed/spicedb/pkg/cmd/server"
+	"github.com/authzed/spicedb/pkg/cmd/util"
+	"github.com/authzed/spicedb/pkg/datastore"
+	"github.com/authzed/spicedb/pkg/tuple"
+)
+
+// presharedKey is the key with which the clients of the server authenticate.
+const presharedKey = "testserver"
+
+// Server is a SpiceDB server running in-process.
+type Server struct {
+	conn   *grpc.ClientConn
+	client *authzed.ClientWithExperimental
+	ds     datastore.Datastore
+	cancel context.CancelFunc
+	done   chan error
+}
+
+type options struct {
+	schema        string
+	relationships []string
+	serverOptions []server.ConfigOption
+}
+
+// Option configures a Server.
+type Option func(*options)
+
+// WithSchema writes the schema to the server once it has started.
+func WithSchema(schema string) Option {
+	return func(o *options) {
+		o.schema = schema
+	}
+}
+
+// WithRelationships writes the relationships, in their string form, to the server once it has
+// started and its schema has been written.
+func WithRelationships(relationships ...string) Option {
+	return func(o *options) {
+		o.relationships = append(o.relationships, relationships...)
+	}
+}
+
+// WithServerOptions applies the options to the configuration of the server, after its defaults.
+func WithServerOptions(serverOptions ...server.ConfigOption) Option {
+	return func(o *options) {
+		o.serverOptions = append(o.serverOptions, serverOptions...)
+	}
+}
+
+// Start starts a server, which runs until it is closed or the context is canceled.
+func Start(ctx context.Context, opts ...Option) (*Server, error) {
+	o := options{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	ds, err := memdb.NewMemdbDatastore(0, 0, memdb.DisableGC)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create datastore: %w", err)
+	}
+
+	configOpts := []server.ConfigOption{
+		server.WithDatastore(ds),
+		server.WithPresharedSecureKey(presharedKey),
+		server.WithGRPCServer(util.GRPCServerConfig{
+			Network: util.BufferedNetwork,
+			Enabled: true,
+		}),
+		server.WithHTTPGateway(util.HTTPServerConfig{HTTPEnabled: false}),
+		server.WithMetricsAPI(util.HTTPServerConfig{HTTPEnabled: false}),
+		server.WithDispatchServer(util.GRPCServerConfig{Enabled: false}),
+		server.WithDispatchMaxDepth(50),
+		server.WithMaximumUpdatesPerWrite(1000),
+		server.WithMaximumPreconditionCount(1000),
+		server.WithMaxDatastoreReadPageSize(1000),
+		server.WithStreamingAPITimeout(30 * time.Second),
+		server.WithWatchHeartbeat(time.Second),
+		server.WithSilentlyDisableTelemetry(true),
+	}
+	configOpts = append(configOpts, o.serverOptions...)
+
+	ctx, cancel := context.WithCancel(ctx)
+	srv, err := server.NewConfigWithOptionsAndDefaults(configOpts...).Complete(ctx)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to configure server: %w", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- srv.Run(ctx)
+	}()
+
+	conn, err := srv.GRPCDialContext(ctx, grpc.WithBlock())
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to connect to server: %w", err)
+	}
+
+	s := &Server{
+		conn: conn,
+		client: &authzed.ClientWithExperimental{
+			Client: authzed.Client{
+				SchemaServiceClient:      v1.NewSchemaServiceClient(conn),
+				PermissionsServiceClient: v1.NewPermissionsServiceClient(conn),
+				WatchServiceClient:       v1.NewWatchServiceClient(conn),
+			},
+			ExperimentalServiceClient: v1.NewExperimentalServiceClient(conn),
+		},
+		ds:     ds,
+		cancel: cancel,
+		done:   done,
+	}
+
+	if err := s.load(ctx, o); err != nil {
+		return nil, errors.Join(err, s.Close())
+	}
+	return s, nil
+}
+
+// NewTestServer starts a server which is closed when the test completes, failing the test if
+// it cannot be started.
+func NewTestServer(t testing.TB, opts ...Option) *Server {
+	t.Helper()
+
+	s, err := Start(context.Background(), opts...)
+	if err != nil {
+		t.Fatalf("failed to start test server: %s", err)
+	}
+
+	t.Cleanup(func() {
+		if err := s.Close(); err != nil {
+			t.Errorf("failed to close test server: %s", err)
+		}
+	})
+	return s
+}
+
+func (s *Server) load(ctx context.Context, o options) error {
+	if o.schema != "" {
+		if _, err := s.client.WriteSchema(ctx, &v1.WriteSchemaRequest{Schema: o.schema}); err != nil {
+			return fmt.Errorf("failed to write schema: %w", err)
+		}
+	}
+
+	if len(o.relationships) == 0 {
+		return nil
+	}
+
+	updates := make([]*v1.RelationshipUpdate, 0, len(o.relationships))
+	for _, rel := range o.relationships {
+		parsed := tuple.ParseRel(rel)
+		if parsed == nil {
+			return fmt.Errorf("invalid relationship `%s`", rel)
+		}
+		updates = append(updates, &v1.RelationshipUpdate{
+			Operation:    v1.RelationshipUpdate_OPERATION_TOUCH,
+			Relationship: parsed,
+		})
+	}
+
+	if _, err := s.client.WriteRelationships(ctx, &v1.WriteRelationshipsRequest{Updates: updates}); err != nil {
+		return fmt.Errorf("failed to write relationships: %w", err)
+	}
+	return nil
+}
+
+// Client returns a client of the server, authenticated with its preshared key.
+func (s *Server) Client() *authzed.ClientWithExperimental {
+	return s.client
+}
+
+// Conn returns the connection of the clients to the server.
+func (s *Server) Conn() *grpc.ClientConn {
+	return s.conn
+}
+
+// Datastore returns the datastore of the server.
+func (s *Server) Datastore() datastore.Datastore {
+	return s.ds
+}
+
+// Close stops the server and closes its connection.
+func (s *Server) Close() error {
+	connErr := s.conn.Close()
+	s.cancel()
+	return errors.Join(connErr, <-s.done)
+}
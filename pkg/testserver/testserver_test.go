@@ -0,0 +1,51 @@
+package testserver
+
+import (
+	"context"
+	"testing"
+
+	v1 "github.com/authzed/authzed-go/proto/authzed/api/v1"
+	"github.com/stretchr/testify/require"
+)
+
+const schema = `
+definition user {}
+
+definition document {
+	relation viewer: user
+	permission view = viewer
+}`
+
+func TestServer(t *testing.T) {
+	srv := NewTestServer(t, WithSchema(schema), WithRelationships("document:first#viewer@user:tom"))
+
+	check := func(userID string) v1.CheckPermissionResponse_Permissionship {
+		resp, err := srv.Client().CheckPermission(context.Background(), &v1.CheckPermissionRequest{
+			Consistency: &v1.Consistency{Requirement: &v1.Consistency_FullyConsistent{FullyConsistent: true}},
+			Resource:    &v1.ObjectReference{ObjectType: "document", ObjectId: "first"},
+			Permission:  "view",
+			Subject:     &v1.SubjectReference{Object: &v1.ObjectReference{ObjectType: "user", ObjectId: userID}},
+		})
+		require.NoError(t, err)
+		return resp.Permissionship
+	}
+
+	require.Equal(t, v1.CheckPermissionResponse_PERMISSIONSHIP_HAS_PERMISSION, check("tom"))
+	require.Equal(t, v1.CheckPermissionResponse_PERMISSIONSHIP_NO_PERMISSION, check("sarah"))
+}
+
+func TestServersAreIndependent(t *testing.T) {
+	first := NewTestServer(t, WithSchema(schema))
+	second := NewTestServer(t)
+
+	_, err := first.Client().ReadSchema(context.Background(), &v1.ReadSchemaRequest{})
+	require.NoError(t, err)
+
+	_, err = second.Client().ReadSchema(context.Background(), &v1.ReadSchemaRequest{})
+	require.Error(t, err)
+}
+
+func TestStartInvalidRelationship(t *testing.T) {
+	_, err := Start(context.Background(), WithSchema(schema), WithRelationships("document:first#viewer"))
+	require.ErrorContains(t, err, "invalid relationship")
+}
@@ -0,0 +1,54 @@
+package tuple
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// labelSeparator separates the key of a label from its value in the labels of a RelationTuple.
+const labelSeparator = "="
+
+// LabelsFromMap returns the labels of a RelationTuple for the labels by key: each as
+// `key=value`, sorted by key.
+func LabelsFromMap(labels map[string]string) []string {
+	if len(labels) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(labels))
+	for key := range labels {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	encoded := make([]string, 0, len(labels))
+	for _, key := range keys {
+		encoded = append(encoded, key+labelSeparator+labels[key])
+	}
+	return encoded
+}
+
+// LabelsToMap returns the labels of a RelationTuple by key.
+func LabelsToMap(labels []string) map[string]string {
+	if len(labels) == 0 {
+		return nil
+	}
+
+	decoded := make(map[string]string, len(labels))
+	for _, label := range labels {
+		key, value, _ := strings.Cut(label, labelSeparator)
+		decoded[key] = value
+	}
+	return decoded
+}
+
+// HasLabels returns whether the labels of a RelationTuple include all of the labels by key.
+func HasLabels(encoded []string, labels map[string]string) bool {
+	for key, value := range labels {
+		if !slices.Contains(encoded, key+labelSeparator+value) {
+			return false
+		}
+	}
+	return true
+}
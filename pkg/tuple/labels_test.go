@@ -0,0 +1,23 @@
+package tuple
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLabels(t *testing.T) {
+	require.Nil(t, LabelsFromMap(nil))
+	require.Nil(t, LabelsToMap(nil))
+
+	labels := LabelsFromMap(map[string]string{"source": "crm", "batch": "a=b", "batch-id": "1"})
+	require.Equal(t, []string{"batch=a=b", "batch-id=1", "source=crm"}, labels)
+	require.Equal(t, map[string]string{"source": "crm", "batch": "a=b", "batch-id": "1"}, LabelsToMap(labels))
+
+	require.True(t, HasLabels(labels, nil))
+	require.True(t, HasLabels(labels, map[string]string{"source": "crm"}))
+	require.True(t, HasLabels(labels, map[string]string{"source": "crm", "batch": "a=b"}))
+	require.False(t, HasLabels(labels, map[string]string{"source": "erp"}))
+	require.False(t, HasLabels(labels, map[string]string{"source": "crm", "owner": "sync"}))
+	require.False(t, HasLabels(nil, map[string]string{"source": "crm"}))
+}
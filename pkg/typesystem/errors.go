@@ -149,6 +149,31 @@ func (err ErrWildcardUsedInArrow) DetailsMetadata() map[string]string {
 	}
 }
 
+// ErrArrowTargetNotFound occurs when the relation or permission walked by an arrow exists on none of
+// the subject types of the relation on its left side.
+type ErrArrowTargetNotFound struct {
+	error
+	namespaceName        string
+	parentPermissionName string
+	tuplesetRelationName string
+	targetRelationName   string
+}
+
+// MarshalZerologObject implements zerolog object marshalling.
+func (err ErrArrowTargetNotFound) MarshalZerologObject(e *zerolog.Event) {
+	e.Err(err.error).Str("namespace", err.namespaceName).Str("parentPermissionName", err.parentPermissionName).Str("tuplesetRelationName", err.tuplesetRelationName).Str("targetRelationName", err.targetRelationName)
+}
+
+// DetailsMetadata returns the metadata for details for this error.
+func (err ErrArrowTargetNotFound) DetailsMetadata() map[string]string {
+	return map[string]string{
+		"definition_name":        err.namespaceName,
+		"permission_name":        err.parentPermissionName,
+		"accessed_relation_name": err.tuplesetRelationName,
+		"target_relation_name":   err.targetRelationName,
+	}
+}
+
 // ErrMissingAllowedRelations occurs when a relation is defined without any type information.
 type ErrMissingAllowedRelations struct {
 	error
@@ -316,6 +341,18 @@ func NewWildcardUsedInArrowErr(nsName string, parentPermissionName string, found
 	}
 }
 
+// NewArrowTargetNotFoundErr constructs an error indicating that the target of an arrow exists on none of the
+// subject types of the relation on its left side.
+func NewArrowTargetNotFoundErr(nsName string, parentPermissionName string, tuplesetRelationName string, targetRelationName string, subjectTypeNames []string) error {
+	return ErrArrowTargetNotFound{
+		error:                fmt.Errorf("for arrow `%s->%s` under permission `%s`: relation/permission `%s` does not exist on any of the subject types of relation `%s#%s` (%s)", tuplesetRelationName, targetRelationName, parentPermissionName, targetRelationName, nsName, tuplesetRelationName, strings.Join(subjectTypeNames, ", ")),
+		namespaceName:        nsName,
+		parentPermissionName: parentPermissionName,
+		tuplesetRelationName: tuplesetRelationName,
+		targetRelationName:   targetRelationName,
+	}
+}
+
 // NewMissingAllowedRelationsErr constructs an error indicating that type information is missing for a relation.
 func NewMissingAllowedRelationsErr(nsName string, relationName string) error {
 	return ErrMissingAllowedRelations{
@@ -3,6 +3,8 @@ package typesystem
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sort"
 
 	"github.com/authzed/spicedb/pkg/datastore"
 	"github.com/authzed/spicedb/pkg/genutil/mapz"
@@ -430,6 +432,16 @@ func (nts *TypeSystem) Validate(ctx context.Context) (*ValidatedNamespaceTypeSys
 						childOneof, relationName,
 					)
 				}
+
+				// Ensure the relation or permission walked by the arrow exists on at least one
+				// of the subject types of the tupleset relation.
+				targetRelationName := ttu.GetComputedUserset().GetRelation()
+				if targetFound, subjectTypeNames := nts.arrowTargetFound(ctx, found, targetRelationName); !targetFound {
+					return NewTypeErrorWithSource(
+						NewArrowTargetNotFoundErr(nts.nsDef.Name, relation.Name, relationName, targetRelationName, subjectTypeNames),
+						childOneof, targetRelationName,
+					)
+				}
 			}
 			return nil
 		})
@@ -559,9 +571,121 @@ func (nts *TypeSystem) Validate(ctx context.Context) (*ValidatedNamespaceTypeSys
 		}
 	}
 
+	// Ensure no permission refers to itself via computed usersets alone, as it could never be
+	// resolved. Recursion via an arrow walks relationships and is allowed.
+	cycle, err := nts.permissionsCycle()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(cycle) > 0 {
+		return nil, NewTypeErrorWithSource(
+			NewPermissionsCycleErr(nts.nsDef.Name, cycle),
+			nts.relationMap[cycle[0]], cycle[0],
+		)
+	}
+
 	return &ValidatedNamespaceTypeSystem{nts}, nil
 }
 
+// arrowTargetFound returns whether the relation or permission with the given name exists on any of
+// the subject types of the tupleset relation of an arrow and, if not, the names of those subject
+// types. Subject types which cannot be found are reported when validating the type information of
+// the tupleset relation, so the target is considered found if any cannot be looked up.
+func (nts *TypeSystem) arrowTargetFound(ctx context.Context, tuplesetRelation *core.Relation, targetRelationName string) (bool, []string) {
+	allowedRelations := tuplesetRelation.GetTypeInformation().GetAllowedDirectRelations()
+	if len(allowedRelations) == 0 {
+		return true, nil
+	}
+
+	subjectTypeNames := make([]string, 0, len(allowedRelations))
+	for _, allowedRelation := range allowedRelations {
+		if slices.Contains(subjectTypeNames, allowedRelation.GetNamespace()) {
+			continue
+		}
+		subjectTypeNames = append(subjectTypeNames, allowedRelation.GetNamespace())
+
+		subjectTS, err := nts.typeSystemForNamespace(ctx, allowedRelation.GetNamespace())
+		if err != nil || subjectTS.HasRelation(targetRelationName) {
+			return true, nil
+		}
+	}
+	return false, subjectTypeNames
+}
+
+// permissionsCycle returns the sorted names of the permissions forming a cycle via computed
+// usersets, if any.
+func (nts *TypeSystem) permissionsCycle() ([]string, error) {
+	const (
+		visiting = iota + 1
+		visited
+	)
+
+	state := map[string]int{}
+	var path []string
+
+	var visit func(relationName string) ([]string, error)
+	visit = func(relationName string) ([]string, error) {
+		switch state[relationName] {
+		case visited:
+			return nil, nil
+		case visiting:
+			cycle := slices.Clone(path[slices.Index(path, relationName):])
+			sort.Strings(cycle)
+			return cycle, nil
+		}
+
+		relation, ok := nts.relationMap[relationName]
+		if !ok || relation.GetUsersetRewrite() == nil {
+			state[relationName] = visited
+			return nil, nil
+		}
+
+		state[relationName] = visiting
+		path = append(path, relationName)
+
+		var visitErr error
+		found, err := graph.WalkRewrite(relation.GetUsersetRewrite(), func(childOneof *core.SetOperation_Child) interface{} {
+			computedUserset := childOneof.GetComputedUserset()
+			if computedUserset == nil {
+				return nil
+			}
+
+			cycle, err := visit(computedUserset.GetRelation())
+			if err != nil {
+				visitErr = err
+				return err
+			}
+			if cycle != nil {
+				return cycle
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+		if visitErr != nil {
+			return nil, visitErr
+		}
+
+		path = path[:len(path)-1]
+		state[relationName] = visited
+
+		if found != nil {
+			return found.([]string), nil
+		}
+		return nil, nil
+	}
+
+	for _, relation := range nts.nsDef.GetRelation() {
+		cycle, err := visit(relation.Name)
+		if err != nil || cycle != nil {
+			return cycle, err
+		}
+	}
+	return nil, nil
+}
+
 // SourceForAllowedRelation returns the source code representation of an allowed relation.
 func SourceForAllowedRelation(allowedRelation *core.AllowedRelation) string {
 	caveatStr := ""
@@ -170,11 +170,102 @@ func TestTypeSystem(t *testing.T) {
 					"folder",
 					ns.MustRelation("can_comment", nil, ns.AllowedRelation("user", "...")),
 					ns.MustRelation("parent", nil, ns.AllowedRelation("folder", "...")),
+					ns.MustRelation("view", ns.Union(
+						ns.TupleToUserset("parent", "view"),
+					)),
 				),
 			},
 			nil,
 			"",
 		},
+		{
+			"arrow target on none of the subject types",
+			ns.Namespace(
+				"document",
+				ns.MustRelation("parent", nil, ns.AllowedRelation("folder", "..."), ns.AllowedRelation("organization", "...")),
+				ns.MustRelation("view", ns.Union(
+					ns.TupleToUserset("parent", "view"),
+				)),
+			),
+			[]*core.NamespaceDefinition{
+				ns.Namespace("folder", ns.MustRelation("viewer", nil, ns.AllowedRelation("user", "..."))),
+				ns.Namespace("organization", ns.MustRelation("member", nil, ns.AllowedRelation("user", "..."))),
+				ns.Namespace("user"),
+			},
+			nil,
+			"for arrow `parent->view` under permission `view`: relation/permission `view` does not exist on any of the subject types of relation `document#parent` (folder, organization)",
+		},
+		{
+			"arrow target on some of the subject types",
+			ns.Namespace(
+				"document",
+				ns.MustRelation("parent", nil, ns.AllowedRelation("folder", "..."), ns.AllowedRelation("organization", "...")),
+				ns.MustRelation("view", ns.Union(
+					ns.TupleToUserset("parent", "viewer"),
+				)),
+			),
+			[]*core.NamespaceDefinition{
+				ns.Namespace("folder", ns.MustRelation("viewer", nil, ns.AllowedRelation("user", "..."))),
+				ns.Namespace("organization", ns.MustRelation("member", nil, ns.AllowedRelation("user", "..."))),
+				ns.Namespace("user"),
+			},
+			nil,
+			"",
+		},
+		{
+			"permission referring to itself",
+			ns.Namespace(
+				"document",
+				ns.MustRelation("viewer", nil, ns.AllowedRelation("user", "...")),
+				ns.MustRelation("view", ns.Union(
+					ns.ComputedUserset("viewer"),
+					ns.ComputedUserset("view"),
+				)),
+			),
+			[]*core.NamespaceDefinition{
+				ns.Namespace("user"),
+			},
+			nil,
+			"under definition `document`, there exists a cycle in permissions: view",
+		},
+		{
+			"permissions referring to each other",
+			ns.Namespace(
+				"document",
+				ns.MustRelation("viewer", nil, ns.AllowedRelation("user", "...")),
+				ns.MustRelation("banned", nil, ns.AllowedRelation("user", "...")),
+				ns.MustRelation("view", ns.Union(
+					ns.ComputedUserset("viewer"),
+					ns.ComputedUserset("edit"),
+				)),
+				ns.MustRelation("edit", ns.Exclusion(
+					ns.ComputedUserset("view"),
+					ns.ComputedUserset("banned"),
+				)),
+			),
+			[]*core.NamespaceDefinition{
+				ns.Namespace("user"),
+			},
+			nil,
+			"under definition `document`, there exists a cycle in permissions: edit, view",
+		},
+		{
+			"permission recursing via an arrow",
+			ns.Namespace(
+				"document",
+				ns.MustRelation("parent", nil, ns.AllowedRelation("document", "...")),
+				ns.MustRelation("viewer", nil, ns.AllowedRelation("user", "...")),
+				ns.MustRelation("view", ns.Union(
+					ns.ComputedUserset("viewer"),
+					ns.TupleToUserset("parent", "view"),
+				)),
+			),
+			[]*core.NamespaceDefinition{
+				ns.Namespace("user"),
+			},
+			nil,
+			"",
+		},
 		{
 			"transitive wildcard type check",
 			ns.Namespace(
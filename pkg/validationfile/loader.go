@@ -116,7 +116,8 @@ func PopulateFromFilesContents(ctx context.Context, ds datastore.Datastore, file
 			return err
 		}
 
-		// Validate and write the object definitions.
+		// Validate all of the object definitions against one another before writing any, so that
+		// they are written together as a whole schema.
 		for _, objectDef := range objectDefs {
 			ts, err := typesystem.NewNamespaceTypeSystem(objectDef,
 				typesystem.ResolverForDatastoreReader(rwt).WithPredefinedElements(typesystem.PredefinedElements{
@@ -136,13 +137,15 @@ func PopulateFromFilesContents(ctx context.Context, ds datastore.Datastore, file
 			if aerr != nil {
 				return aerr
 			}
-
-			if err := rwt.WriteNamespaces(ctx, objectDef); err != nil {
-				return fmt.Errorf("error when loading object definition %s: %w", objectDef.Name, err)
-			}
 		}
 
-		return err
+		if len(objectDefs) == 0 {
+			return nil
+		}
+		if err := rwt.WriteNamespaces(ctx, objectDefs...); err != nil {
+			return fmt.Errorf("error when loading object definitions: %w", err)
+		}
+		return nil
 	})
 
 	slicez.ForEachChunk(updates, 500, func(chunked []*core.RelationTupleUpdate) {
@@ -163,3 +163,19 @@ func (c *txCountingDatastore) ReadWriteTx(ctx context.Context, userFunc datastor
 	c.count++
 	return c.delegate.ReadWriteTx(ctx, userFunc, option...)
 }
+
+func TestPopulationWritesNoDefinitionsIfAnyIsInvalid(t *testing.T) {
+	require := require.New(t)
+
+	ds, err := memdb.NewMemdbDatastore(0, 0, 0)
+	require.NoError(err)
+
+	_, _, err = PopulateFromFiles(context.Background(), ds, []string{"testdata/invalid_definition.yaml"})
+	require.ErrorContains(err, "relation/permission `admin` not found under definition `group`")
+
+	revision, err := ds.HeadRevision(context.Background())
+	require.NoError(err)
+	namespaces, err := ds.SnapshotReader(revision).ListAllNamespaces(context.Background())
+	require.NoError(err)
+	require.Empty(namespaces)
+}
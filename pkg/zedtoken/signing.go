@@ -0,0 +1,120 @@
+package zedtoken
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/pkg/spiceerrors"
+)
+
+// signatureSeparator separates an encoded zedtoken from its signature. It is not part of the
+// base64 alphabet, so it cannot appear within the encoded token itself.
+const signatureSeparator = "."
+
+// signingKeys are the keys with which zedtokens are signed and verified, if any.
+var signingKeys atomic.Pointer[signingConfig]
+
+type signingConfig struct {
+	keys          [][]byte
+	allowUnsigned bool
+}
+
+// SetSigningKeys configures the keys with which zedtokens are signed, so that tokens which
+// were not issued by a server sharing the keys are rejected rather than interpreted as
+// arbitrary revisions. Tokens are signed with the first key, and are accepted if signed with
+// any of them, so that keys can be rotated by first adding the new key after the existing
+// ones, then moving it first, and finally removing the old key once the tokens it signed are
+// no longer in use.
+//
+// Unless allowUnsigned is set, tokens without a signature are rejected; it allows those issued
+// before signing was enabled to be accepted while it is rolled out. Calling SetSigningKeys
+// without keys disables signing, in which case signatures are ignored.
+func SetSigningKeys(keys []string, allowUnsigned bool) error {
+	if len(keys) == 0 {
+		signingKeys.Store(nil)
+		return nil
+	}
+
+	config := &signingConfig{allowUnsigned: allowUnsigned}
+	for index, key := range keys {
+		if key == "" {
+			return fmt.Errorf("zedtoken signing key #%d is empty", index+1)
+		}
+		config.keys = append(config.keys, []byte(key))
+	}
+	signingKeys.Store(config)
+	return nil
+}
+
+// ErrInvalidSignature is returned when a zedtoken is not signed by any of the signing keys.
+type ErrInvalidSignature struct {
+	unsigned bool
+}
+
+func (err ErrInvalidSignature) Error() string {
+	if err.unsigned {
+		return "zedtoken is not signed"
+	}
+	return "zedtoken signature is invalid"
+}
+
+// GRPCStatus implements retrieving the gRPC status for the error.
+func (err ErrInvalidSignature) GRPCStatus() *status.Status {
+	return spiceerrors.WithCodeAndDetails(
+		err,
+		codes.InvalidArgument,
+		&errdetails.ErrorInfo{
+			Reason:   "ERROR_REASON_INVALID_ZEDTOKEN_SIGNATURE",
+			Domain:   spiceerrors.Domain,
+			Metadata: map[string]string{"unsigned": strconv.FormatBool(err.unsigned)},
+		},
+	)
+}
+
+// sign appends the signature of the encoded token, if signing is enabled.
+func sign(encoded string) string {
+	config := signingKeys.Load()
+	if config == nil {
+		return encoded
+	}
+	return encoded + signatureSeparator + signature(config.keys[0], encoded)
+}
+
+// verify returns the encoded token without its signature, after verifying it if signing is
+// enabled.
+func verify(token string) (string, error) {
+	encoded, sig, signed := strings.Cut(token, signatureSeparator)
+
+	config := signingKeys.Load()
+	if config == nil {
+		return encoded, nil
+	}
+	if !signed {
+		if config.allowUnsigned {
+			return encoded, nil
+		}
+		return "", ErrInvalidSignature{unsigned: true}
+	}
+
+	for _, key := range config.keys {
+		if hmac.Equal([]byte(sig), []byte(signature(key, encoded))) {
+			return encoded, nil
+		}
+	}
+	return "", ErrInvalidSignature{}
+}
+
+func signature(key []byte, encoded string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(encoded))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,60 @@
+package zedtoken
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/authzed/spicedb/internal/datastore/revisions"
+)
+
+func TestSigning(t *testing.T) {
+	t.Cleanup(func() { require.NoError(t, SetSigningKeys(nil, false)) })
+
+	decoder := revisions.CommonDecoder{Kind: revisions.TransactionID}
+	revision := revisions.NewForTransactionID(42)
+	unsigned := MustNewFromRevision(revision)
+
+	require.NoError(t, SetSigningKeys([]string{"first"}, false))
+	signed := MustNewFromRevision(revision)
+	require.True(t, strings.HasPrefix(signed.Token, unsigned.Token+signatureSeparator))
+
+	decoded, err := DecodeRevision(signed, decoder)
+	require.NoError(t, err)
+	require.True(t, revision.Equal(decoded))
+
+	// Unsigned and forged tokens are rejected.
+	_, err = DecodeRevision(unsigned, decoder)
+	require.ErrorAs(t, err, &ErrInvalidSignature{})
+	require.Equal(t, codes.InvalidArgument, status.Code(ErrInvalidSignature{unsigned: true}))
+
+	forged := MustNewFromRevision(revisions.NewForTransactionID(43))
+	forged.Token = strings.Split(forged.Token, signatureSeparator)[0] + strings.TrimPrefix(signed.Token, unsigned.Token)
+	_, err = DecodeRevision(forged, decoder)
+	require.ErrorAs(t, err, &ErrInvalidSignature{})
+
+	// Unsigned tokens may be allowed while signing is rolled out.
+	require.NoError(t, SetSigningKeys([]string{"first"}, true))
+	_, err = DecodeRevision(unsigned, decoder)
+	require.NoError(t, err)
+
+	// Tokens signed with any of the keys are accepted, so that keys can be rotated.
+	require.NoError(t, SetSigningKeys([]string{"second", "first"}, false))
+	_, err = DecodeRevision(signed, decoder)
+	require.NoError(t, err)
+	require.NotEqual(t, signed.Token, MustNewFromRevision(revision).Token)
+
+	require.NoError(t, SetSigningKeys([]string{"second"}, false))
+	_, err = DecodeRevision(signed, decoder)
+	require.ErrorAs(t, err, &ErrInvalidSignature{})
+
+	// Without keys, signatures are ignored.
+	require.NoError(t, SetSigningKeys(nil, false))
+	_, err = DecodeRevision(signed, decoder)
+	require.NoError(t, err)
+
+	require.Error(t, SetSigningKeys([]string{"first", ""}, false))
+}
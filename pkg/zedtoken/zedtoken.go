@@ -48,24 +48,30 @@ func NewFromRevision(revision datastore.Revision) (*v1.ZedToken, error) {
 	return encoded, nil
 }
 
-// Encode converts a decoded zedtoken to its opaque version.
+// Encode converts a decoded zedtoken to its opaque version, signed if signing keys are set.
 func Encode(decoded *zedtoken.DecodedZedToken) (*v1.ZedToken, error) {
 	marshalled, err := decoded.MarshalVT()
 	if err != nil {
 		return nil, fmt.Errorf(errEncodeError, err)
 	}
 	return &v1.ZedToken{
-		Token: base64.StdEncoding.EncodeToString(marshalled),
+		Token: sign(base64.StdEncoding.EncodeToString(marshalled)),
 	}, nil
 }
 
-// Decode converts an encoded zedtoken to its decoded version.
+// Decode converts an encoded zedtoken to its decoded version, verifying its signature if
+// signing keys are set.
 func Decode(encoded *v1.ZedToken) (*zedtoken.DecodedZedToken, error) {
 	if encoded == nil {
 		return nil, fmt.Errorf(errDecodeError, ErrNilZedToken)
 	}
 
-	decodedBytes, err := base64.StdEncoding.DecodeString(encoded.Token)
+	token, err := verify(encoded.Token)
+	if err != nil {
+		return nil, fmt.Errorf(errDecodeError, err)
+	}
+
+	decodedBytes, err := base64.StdEncoding.DecodeString(token)
 	if err != nil {
 		return nil, fmt.Errorf(errDecodeError, err)
 	}